@@ -1,27 +1,30 @@
 module github.com/YASHIRAI/pismo-task/cmd/account-mgr
 
-go 1.21
+go 1.24.0
 
 require (
 	github.com/YASHIRAI/pismo-task/internal/account v0.0.0-00010101000000-000000000000
 	github.com/YASHIRAI/pismo-task/internal/common v0.0.0-00010101000000-000000000000
 	github.com/YASHIRAI/pismo-task/proto/account v0.0.0-00010101000000-000000000000
-	google.golang.org/grpc v1.64.0
+	google.golang.org/grpc v1.71.0
 )
 
 replace github.com/YASHIRAI/pismo-task/internal/account => ../../internal/account
 
 replace github.com/YASHIRAI/pismo-task/internal/common => ../../internal/common
 
+replace github.com/YASHIRAI/pismo-task/internal/validation => ../../internal/validation
+
 replace github.com/YASHIRAI/pismo-task/proto/account => ../../proto/account
 
 require (
+	github.com/YASHIRAI/pismo-task/internal/validation v0.0.0-00010101000000-000000000000 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/lib/pq v1.10.9 // indirect
-	golang.org/x/net v0.22.0 // indirect
-	golang.org/x/sys v0.18.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
-	google.golang.org/genproto/googleapis/api v0.0.0-20240318140521-94a12d6c2237 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
-	google.golang.org/protobuf v1.33.0 // indirect
+	golang.org/x/net v0.37.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20250922171735-9219d122eba9 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250908214217-97024824d090 // indirect
+	google.golang.org/protobuf v1.36.9 // indirect
 )
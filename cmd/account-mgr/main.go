@@ -3,11 +3,16 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/YASHIRAI/pismo-task/internal/account"
 	"github.com/YASHIRAI/pismo-task/internal/common"
@@ -18,18 +23,35 @@ import (
 // It initializes the database connection, sets up the schema, and starts the gRPC server on port 8081.
 // The service handles account-related operations including CRUD operations and balance management.
 func main() {
+	selfTest := flag.Bool("selftest", false, "check the configuration, the database and the pending migrations, then exit")
+	flag.Parse()
+
 	// Initialize logging
-	logLevel := common.ParseLogLevel(os.Getenv("LOG_LEVEL"))
-	logger, err := common.NewLogger("account-mgr", logLevel)
+	logger, err := common.NewLoggerFromEnv("account-mgr")
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
 	}
 	defer logger.Close()
 
+	// An init container can run the self-test to hold the rollout until the service can start
+	if *selfTest {
+		code := runSelfTest(logger)
+		logger.Close()
+		os.Exit(code)
+	}
+
 	logger.Info("Starting Account Manager service")
 
-	dbManager, err := common.NewDatabaseManager()
+	runtimeConfig, err := common.NewRuntimeConfigManager(os.Getenv("RUNTIME_CONFIG_FILE"), logger)
+	if err != nil {
+		logger.Fatal("Failed to load runtime config: %v", err)
+	}
+	go runtimeConfig.Watch(context.Background(), common.DefaultRuntimeConfigPollInterval)
+
+	// The database may still be starting on a cold start; wait for it rather than exit before binding the port
+	startup := common.NewStartupCoordinatorFromEnv(logger)
+	dbManager, err := startup.WaitForDatabase()
 	if err != nil {
 		logger.Fatal("Failed to initialize database: %v", err)
 	}
@@ -41,9 +63,29 @@ func main() {
 		logger.Fatal("Failed to initialize database schema: %v", err)
 	}
 
+	// InitSchema does not change existing columns, so a column of another type would go unnoticed
+	if err := dbManager.VerifySchema(context.Background(), logger, common.SchemaDriftModeFromEnv()); err != nil {
+		logger.Fatal("Database schema drift detected: %v", err)
+	}
+
 	logger.Info("Database schema initialized")
 
 	accountService := account.NewService(dbManager.GetDB(), logger)
+	accountService.ApplyRuntimeConfig(runtimeConfig.Current())
+	runtimeConfig.OnReload(accountService.ApplyRuntimeConfig)
+
+	// Tenant retention settings purge old transactions and anonymize closed accounts
+	retentionDryRun := common.RetentionDryRunFromEnv()
+	retentionInterval := common.RetentionIntervalFromEnv()
+	retention := common.NewRetentionWorker(dbManager.GetDB(), logger, nil, retentionDryRun)
+	retention.SetTenantSettings(common.NewTenantConfigStoreFromEnv(dbManager.GetDB()))
+	go retention.Run(context.Background(), retentionInterval)
+	logger.Info("Retention worker started: Interval=%s, DryRun=%t", retentionInterval, retentionDryRun)
+
+	// Month-end revaluation of balances held in a currency other than the tenant's reporting currency
+	fxInterval := account.FXRevaluationIntervalFromEnv()
+	go account.NewFXRevaluationJob(dbManager.GetDB(), logger).Run(context.Background(), fxInterval)
+	logger.Info("FX revaluation job started: Interval=%s", fxInterval)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -55,9 +97,141 @@ func main() {
 		logger.Fatal("Failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	compression := common.NewCompressionConfig()
+	logger.Info("gRPC compression: Enabled=%t, Threshold=%d bytes", compression.Enabled, compression.Threshold)
+
+	// Calls to the methods with an SLO target count against their error budget
+	sloTracker := common.NewSLOTracker()
+	sloTracker.ApplyRuntimeConfig(runtimeConfig.Current())
+	runtimeConfig.OnReload(sloTracker.ApplyRuntimeConfig)
+	accountService.SetSLOTracker(sloTracker)
+
+	rateLimiter := common.NewRateLimiterFromEnv()
+	rateLimiter.ApplyRuntimeConfig(runtimeConfig.Current())
+	runtimeConfig.OnReload(rateLimiter.ApplyRuntimeConfig)
+	globalRate, callerRate := rateLimiter.Rates()
+	logger.Info("Rate limits: Global=%.0f QPS, PerCaller=%.0f QPS", globalRate, callerRate)
+
+	// Interactive calls are admitted ahead of bulk ones when the replica is saturated
+	scheduler := common.NewPrioritySchedulerFromEnv()
+	scheduler.ApplyRuntimeConfig(runtimeConfig.Current())
+	runtimeConfig.OnReload(scheduler.ApplyRuntimeConfig)
+	maxConcurrent, interactiveWeight := scheduler.Limits()
+	logger.Info("Priority lanes: MaxConcurrentCalls=%d, InteractiveWeight=%d", maxConcurrent, interactiveWeight)
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			common.RequestIDUnaryServerInterceptor(),
+			common.SLOUnaryServerInterceptor(sloTracker),
+			common.CancellationUnaryServerInterceptor(logger),
+			common.RateLimitUnaryServerInterceptor(rateLimiter, logger),
+			common.PriorityUnaryServerInterceptor(scheduler, logger),
+			common.CompressionUnaryServerInterceptor(compression),
+			common.AccessAuditUnaryServerInterceptor(common.NewAccessAuditor(dbManager.GetDB(), logger, "account-mgr")),
+			common.AuthorizationUnaryServerInterceptor(runtimeConfig),
+			common.LegacyAmountsUnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			common.RequestIDStreamServerInterceptor(),
+			common.RateLimitStreamServerInterceptor(rateLimiter, logger),
+			common.PriorityStreamServerInterceptor(scheduler, logger),
+			common.CompressionStreamServerInterceptor(compression),
+			common.AuthorizationStreamServerInterceptor(runtimeConfig),
+			common.LegacyAmountsStreamServerInterceptor(),
+		),
+	)
 	pb.RegisterAccountServiceServer(grpcServer, accountService)
 
+	// Standard gRPC health service used by client-side load balancers to detect unhealthy replicas
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	// Replicas that lose the database report NOT_SERVING, and every readiness change is logged and counted
+	readiness := common.NewReadinessMonitor("account-mgr", logger, common.DatabaseReadinessCheck(dbManager.GetDB()))
+	readiness.OnChange(func(ready bool) {
+		servingStatus := healthpb.HealthCheckResponse_NOT_SERVING
+		if ready {
+			servingStatus = healthpb.HealthCheckResponse_SERVING
+		}
+		healthServer.SetServingStatus("", servingStatus)
+	})
+	go readiness.Run(context.Background(), common.ReadinessIntervalFromEnv())
+	if metricsPort := os.Getenv("READINESS_METRICS_PORT"); metricsPort != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", readiness)
+		go func() {
+			if err := http.ListenAndServe(":"+metricsPort, mux); err != nil {
+				logger.Error("Readiness metrics server stopped: %v", err)
+			}
+		}()
+		logger.Info("Readiness metrics listening on port %s", metricsPort)
+	}
+
+	// Internal endpoints for the platform's workers, on their own port and authenticated by service token
+	if internalPort := os.Getenv("INTERNAL_GRPC_PORT"); internalPort != "" {
+		tokens, err := common.ServiceTokensFromEnv()
+		if err != nil {
+			logger.Fatal("Invalid INTERNAL_SERVICE_TOKENS: %v", err)
+		}
+		if len(tokens) == 0 {
+			logger.Fatal("INTERNAL_GRPC_PORT is set but INTERNAL_SERVICE_TOKENS lists no services")
+		}
+		internalLis, err := net.Listen("tcp", ":"+internalPort)
+		if err != nil {
+			logger.Fatal("Failed to listen on internal port: %v", err)
+		}
+		internalServer := grpc.NewServer(
+			grpc.ChainUnaryInterceptor(
+				common.RequestIDUnaryServerInterceptor(),
+				common.CancellationUnaryServerInterceptor(logger),
+				common.InternalAuthUnaryServerInterceptor(tokens, common.NewAccessAuditor(dbManager.GetDB(), logger, "account-mgr-internal")),
+			),
+		)
+		pb.RegisterInternalAccountServiceServer(internalServer, account.NewInternalService(accountService))
+		go func() {
+			if err := internalServer.Serve(internalLis); err != nil {
+				logger.Error("Internal gRPC server stopped: %v", err)
+			}
+		}()
+		logger.Info("Internal account service listening on port %s: Services=%d", internalPort, len(tokens))
+	}
+
+	// Read-only streams for analytics workloads, on their own port and served from the read replica
+	if analyticsPort := os.Getenv("ANALYTICS_GRPC_PORT"); analyticsPort != "" {
+		replicaConfig, ok := common.ReplicaDatabaseConfigFromEnv()
+		if !ok {
+			logger.Fatal("ANALYTICS_GRPC_PORT is set but REPLICA_DB_HOST is not")
+		}
+		tokens, err := common.AnalyticsServiceTokensFromEnv()
+		if err != nil {
+			logger.Fatal("Invalid ANALYTICS_SERVICE_TOKENS: %v", err)
+		}
+		if len(tokens) == 0 {
+			logger.Fatal("ANALYTICS_GRPC_PORT is set but ANALYTICS_SERVICE_TOKENS lists no services")
+		}
+		replica, err := startup.WaitForReplica(replicaConfig)
+		if err != nil {
+			logger.Fatal("Failed to connect to read replica: %v", err)
+		}
+		defer replica.Close()
+		analyticsLis, err := net.Listen("tcp", ":"+analyticsPort)
+		if err != nil {
+			logger.Fatal("Failed to listen on analytics port: %v", err)
+		}
+		analyticsLimiter := common.NewAnalyticsRateLimiterFromEnv()
+		analyticsServer := common.NewAnalyticsServer(tokens, analyticsLimiter,
+			common.NewAccessAuditor(dbManager.GetDB(), logger, "account-mgr-analytics"), logger)
+		pb.RegisterAccountAnalyticsServiceServer(analyticsServer, account.NewAnalyticsService(replica, logger))
+		go func() {
+			if err := analyticsServer.Serve(analyticsLis); err != nil {
+				logger.Error("Analytics gRPC server stopped: %v", err)
+			}
+		}()
+		globalRate, callerRate := analyticsLimiter.Rates()
+		logger.Info("Analytics service listening on port %s: Replica=%s, Global=%.0f QPS, PerCaller=%.0f QPS",
+			analyticsPort, replicaConfig.Host, globalRate, callerRate)
+	}
+
 	logger.Info("Account service listening on port %s", port)
 	if err := grpcServer.Serve(lis); err != nil {
 		logger.Fatal("Failed to serve: %v", err)
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+)
+
+// runSelfTest checks what the service needs to start: its runtime config, the configuration of the internal and
+// analytics ports when they are enabled, the database and read replica, and the pending schema migrations.
+// Returns the exit code.
+func runSelfTest(logger *common.Logger) int {
+	selfTest := common.NewSelfTest(logger)
+	selfTest.CheckRuntimeConfig(os.Getenv("RUNTIME_CONFIG_FILE"))
+
+	if os.Getenv("INTERNAL_GRPC_PORT") != "" {
+		selfTest.Check("internal service tokens", func(ctx context.Context) error {
+			tokens, err := common.ServiceTokensFromEnv()
+			if err == nil && len(tokens) == 0 {
+				err = fmt.Errorf("INTERNAL_GRPC_PORT is set but INTERNAL_SERVICE_TOKENS lists no services")
+			}
+			return err
+		})
+	}
+
+	if os.Getenv("ANALYTICS_GRPC_PORT") != "" {
+		selfTest.Check("analytics service tokens", func(ctx context.Context) error {
+			tokens, err := common.AnalyticsServiceTokensFromEnv()
+			if err == nil && len(tokens) == 0 {
+				err = fmt.Errorf("ANALYTICS_GRPC_PORT is set but ANALYTICS_SERVICE_TOKENS lists no services")
+			}
+			return err
+		})
+		selfTest.Check("read replica", func(ctx context.Context) error {
+			replicaConfig, ok := common.ReplicaDatabaseConfigFromEnv()
+			if !ok {
+				return fmt.Errorf("ANALYTICS_GRPC_PORT is set but REPLICA_DB_HOST is not")
+			}
+			replica, err := common.OpenReplicaDatabase(ctx, replicaConfig)
+			if err != nil {
+				return err
+			}
+			return replica.Close()
+		})
+	}
+
+	selfTest.CheckDatabase()
+	return selfTest.ExitCode()
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+)
+
+// dateLayout is the format of the -from and -to dates.
+const dateLayout = "2006-01-02"
+
+// main writes or verifies an audit package for regulators.
+//
+//	audit-export -from 2025-10-01 -to 2025-10-31 [-out DIR]   export the records of the days from -from to -to, inclusive, in UTC
+//	audit-export -verify DIR [-public-key KEY]                check the signature and checksums of a package
+//	audit-export -print-public-key                            print the public key to hand to regulators
+//
+// Packages are signed with the Ed25519 key in AUDIT_EXPORT_SIGNING_KEY; verification uses -public-key,
+// or the public half of that key. The database is configured with the same DB_* variables as the services.
+func main() {
+	from := flag.String("from", "", "first day of the exported range, YYYY-MM-DD (UTC)")
+	to := flag.String("to", "", "last day of the exported range, YYYY-MM-DD (UTC), inclusive")
+	out := flag.String("out", "", "directory to write the package to; defaults to audit-export-FROM-TO")
+	verify := flag.String("verify", "", "verify the package in this directory instead of exporting")
+	publicKey := flag.String("public-key", "", "base64 Ed25519 public key to verify with")
+	printPublicKey := flag.Bool("print-public-key", false, "print the public key of AUDIT_EXPORT_SIGNING_KEY and exit")
+	flag.Parse()
+
+	switch {
+	case *printPublicKey:
+		key, err := common.AuditSigningKeyFromEnv()
+		if err != nil {
+			fail("%v", err)
+		}
+		fmt.Println(base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)))
+	case *verify != "":
+		verifyPackage(*verify, *publicKey)
+	default:
+		export(*from, *to, *out)
+	}
+}
+
+// export writes the package of the days from..to to out.
+func export(fromDate, toDate, out string) {
+	from, err := time.Parse(dateLayout, fromDate)
+	if err != nil {
+		fail("-from must be a date in YYYY-MM-DD format")
+	}
+	to, err := time.Parse(dateLayout, toDate)
+	if err != nil {
+		fail("-to must be a date in YYYY-MM-DD format")
+	}
+	if to.Before(from) {
+		fail("-to must not be before -from")
+	}
+	if out == "" {
+		out = fmt.Sprintf("audit-export-%s-%s", fromDate, toDate)
+	}
+
+	key, err := common.AuditSigningKeyFromEnv()
+	if err != nil {
+		fail("%v", err)
+	}
+
+	logger, err := common.NewLoggerFromEnv("audit-export")
+	if err != nil {
+		fail("failed to initialize logger: %v", err)
+	}
+	defer logger.Close()
+
+	dbManager, err := common.NewDatabaseManager()
+	if err != nil {
+		logger.Fatal("Failed to connect to the database: %v", err)
+	}
+	defer dbManager.Close()
+
+	// The range ends at the start of the day after -to, so the last day is included
+	manifest, err := common.NewAuditExporter(dbManager.GetDB(), logger, key).Export(context.Background(), out, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		logger.Fatal("Audit export failed: %v", err)
+	}
+
+	logger.Info("Audit package written to %s: From=%s, To=%s, Files=%d", out, manifest.From, manifest.To, len(manifest.Files))
+	for _, file := range manifest.Files {
+		fmt.Printf("%s  %s  %d rows\n", file.SHA256, file.Name, file.Rows)
+	}
+}
+
+// verifyPackage checks the package in dir with the given base64 public key, or that of the signing key.
+func verifyPackage(dir, encodedKey string) {
+	var publicKey ed25519.PublicKey
+	if encodedKey != "" {
+		decoded, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil || len(decoded) != ed25519.PublicKeySize {
+			fail("-public-key must be a base64-encoded %d-byte Ed25519 public key", ed25519.PublicKeySize)
+		}
+		publicKey = decoded
+	} else {
+		key, err := common.AuditSigningKeyFromEnv()
+		if err != nil {
+			fail("-public-key is required without AUDIT_EXPORT_SIGNING_KEY: %v", err)
+		}
+		publicKey = key.Public().(ed25519.PublicKey)
+	}
+
+	manifest, err := common.VerifyAuditPackage(dir, publicKey)
+	if err != nil {
+		fail("verification failed: %v", err)
+	}
+	fmt.Printf("OK: %s, %s to %s, %d files, generated %s\n", dir, manifest.From, manifest.To, len(manifest.Files), manifest.GeneratedAt)
+}
+
+// fail prints an error and exits with status 1.
+func fail(format string, v ...interface{}) {
+	fmt.Fprintf(os.Stderr, "audit-export: "+format+"\n", v...)
+	os.Exit(1)
+}
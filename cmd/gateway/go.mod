@@ -1,13 +1,16 @@
 module github.com/YASHIRAI/pismo-task/cmd/gateway
 
-go 1.21
+go 1.24.0
 
 require (
 	github.com/YASHIRAI/pismo-task/internal/common v0.0.0-00010101000000-000000000000
 	github.com/YASHIRAI/pismo-task/proto/account v0.0.0-00010101000000-000000000000
 	github.com/YASHIRAI/pismo-task/proto/transaction v0.0.0-00010101000000-000000000000
+	github.com/google/uuid v1.6.0
 	github.com/gorilla/mux v1.8.1
-	google.golang.org/grpc v1.64.0
+	github.com/stretchr/testify v1.8.4
+	google.golang.org/grpc v1.71.0
+	google.golang.org/protobuf v1.36.9
 )
 
 replace github.com/YASHIRAI/pismo-task/internal/common => ../../internal/common
@@ -17,11 +20,13 @@ replace github.com/YASHIRAI/pismo-task/proto/account => ../../proto/account
 replace github.com/YASHIRAI/pismo-task/proto/transaction => ../../proto/transaction
 
 require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/lib/pq v1.10.9 // indirect
-	golang.org/x/net v0.22.0 // indirect
-	golang.org/x/sys v0.23.0 // indirect
-	golang.org/x/text v0.17.0 // indirect
-	google.golang.org/genproto/googleapis/api v0.0.0-20240318140521-94a12d6c2237 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
-	google.golang.org/protobuf v1.33.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.37.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20250922171735-9219d122eba9 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250908214217-97024824d090 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
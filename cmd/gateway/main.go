@@ -1,18 +1,31 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
+	"math"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
+	"github.com/YASHIRAI/pismo-task/cmd/gateway/mockdownstreams"
 	"github.com/YASHIRAI/pismo-task/internal/common"
 	pbAccount "github.com/YASHIRAI/pismo-task/proto/account"
 	pbTransaction "github.com/YASHIRAI/pismo-task/proto/transaction"
@@ -24,6 +37,278 @@ type GatewayService struct {
 	accountClient     pbAccount.AccountServiceClient
 	transactionClient pbTransaction.TransactionServiceClient
 	logger            *common.Logger
+	// idempotentDeletes makes deleting an account that is already gone succeed, so clients can retry deletes
+	idempotentDeletes bool
+}
+
+// RequestIDMiddleware tags each request with an ID, reusing a valid X-Request-ID from the client or minting one.
+// The ID is echoed in the response and carried in the request context, from where it is logged and
+// forwarded to the backend services so a request can be followed across every hop.
+func RequestIDMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(common.RequestIDHeader)
+			if !common.ValidRequestID(requestID) {
+				requestID = common.NewRequestID()
+			}
+
+			w.Header().Set(common.RequestIDHeader, requestID)
+			next.ServeHTTP(w, r.WithContext(common.WithRequestID(r.Context(), requestID)))
+		})
+	}
+}
+
+// RateLimitMiddleware identifies the end client to the backend services, so their per-caller rate limits
+// apply per client rather than to the gateway as a whole, and reports the resulting limit to the client
+// in X-RateLimit-* headers. Clients are identified by their X-API-Key header, hashed so keys never reach
+// service logs, or else by their address.
+func RateLimitMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, tracker := common.WithRateLimitTracker(r.Context())
+			ctx = metadata.AppendToOutgoingContext(ctx, common.CallerIDMetadataKey, clientCallerID(r))
+
+			wrapped := &rateLimitHeaderWriter{ResponseWriter: w, tracker: tracker}
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+		})
+	}
+}
+
+// clientCallerID returns the identity the backend services rate limit the request's client by.
+func clientCallerID(r *http.Request) string {
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// rateLimitHeaderWriter adds the X-RateLimit-* headers collected from backend calls before the response is written.
+type rateLimitHeaderWriter struct {
+	http.ResponseWriter
+	tracker     *common.RateLimitTracker
+	wroteHeader bool
+}
+
+func (rw *rateLimitHeaderWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		if info, ok := rw.tracker.Info(); ok {
+			info.SetHTTPHeaders(rw.Header())
+		}
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *rateLimitHeaderWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+// Flush lets streamed responses reach the client as they are written.
+func (rw *rateLimitHeaderWriter) Flush() {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// writeServiceError writes the response for a failed backend call.
+// Rate limited calls get 429 Too Many Requests with a Retry-After header and a JSON body clients can
+// rely on for backoff; calls cancelled because the client disconnected are recorded as 499 Client
+// Closed Request, with no body since nobody is listening; calls rejected by a configured method policy
+// get 403 Forbidden; calls to a backend that cannot be reached get 503 Service Unavailable, see
+// writeServiceUnavailable; calls a backend does not implement, such as most operations of the fake backends
+// of MOCK_DOWNSTREAMS, get 501 Not Implemented; any other failure is reported as 500.
+func writeServiceError(w http.ResponseWriter, r *http.Request, service string, err error) {
+	if common.IsCancellation(err) && r.Context().Err() != nil {
+		w.WriteHeader(common.StatusClientClosedRequest)
+		return
+	}
+	if status.Code(err) == codes.PermissionDenied {
+		http.Error(w, "permission denied", http.StatusForbidden)
+		return
+	}
+	if status.Code(err) == codes.Unavailable {
+		writeServiceUnavailable(w, service)
+		return
+	}
+	if status.Code(err) == codes.Unimplemented {
+		http.Error(w, fmt.Sprintf("%s service does not implement this operation", service), http.StatusNotImplemented)
+		return
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		http.Error(w, fmt.Sprintf("%s service error: %v", service, err), http.StatusInternalServerError)
+		return
+	}
+
+	retryAfter := 1
+	if tracker := common.RateLimitTrackerFromContext(r.Context()); tracker != nil {
+		if info, ok := tracker.Info(); ok && info.Reset > retryAfter {
+			retryAfter = info.Reset
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":               "rate limit exceeded",
+		"code":                "RATE_LIMITED",
+		"retry_after_seconds": retryAfter,
+	})
+}
+
+// accountRateLimited is the error the transaction service refuses transactions with once their account
+// exceeds its transaction rate.
+const accountRateLimited = "account rate limit exceeded"
+
+// writeAccountRateLimited writes 429 Too Many Requests for a transaction refused because its account exceeds its
+// transaction rate. The code differs from that of rate limited callers, since retrying through other callers
+// does not help.
+func writeAccountRateLimited(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", "1")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":               accountRateLimited,
+		"code":                "ACCOUNT_RATE_LIMITED",
+		"retry_after_seconds": 1,
+	})
+}
+
+// serviceUnavailableRetryAfter is the Retry-After, in seconds, of responses for calls to an unreachable backend.
+const serviceUnavailableRetryAfter = 5
+
+// writeServiceUnavailable writes 503 Service Unavailable with a Retry-After header and a JSON body for a call
+// to a backend that could not be reached, so clients can queue writes and retry them once it is back.
+// Transactions sent with an external_id cannot be applied twice by such a retry.
+func writeServiceUnavailable(w http.ResponseWriter, service string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(serviceUnavailableRetryAfter))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":               fmt.Sprintf("%s service unavailable", strings.ToLower(service)),
+		"code":                "SERVICE_UNAVAILABLE",
+		"retry_after_seconds": serviceUnavailableRetryAfter,
+	})
+}
+
+// staleReadRoutes are the reads answered from the stale response cache while their backend is unavailable.
+var staleReadRoutes = map[string]bool{
+	"/accounts/{id}":                      true,
+	"/accounts/{id}/balance":              true,
+	"/accounts/{id}/balances":             true,
+	"/transactions/{id}":                  true,
+	"/accounts/{account_id}/transactions": true,
+}
+
+// StaleReadMiddleware keeps the latest successful response of the account, balance and transaction reads in
+// staleReadRoutes, and answers a read with it when the backend is unavailable instead of failing with 503.
+// A stale response is marked with "stale": true and the "stale_as_of" time in its body, and with the
+// X-Stale-Response and Age headers. Responses are kept per caller role and tenant, as both change their content.
+// It runs inside the other response rewriting middleware, so their rules apply to stale responses too.
+func StaleReadMiddleware(cache *common.StaleResponseCache, logger *common.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if template, err := route.GetPathTemplate(); err != nil || !staleReadRoutes[template] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := strings.Join([]string{
+				strings.ToLower(strings.TrimSpace(r.Header.Get("X-Caller-Role"))),
+				r.Header.Get("X-Tenant-ID"),
+				r.URL.RequestURI(),
+			}, "|")
+
+			reading := &jsonResponseBuffer{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(reading, r)
+			if !reading.buffering {
+				return
+			}
+
+			body := reading.body.Bytes()
+			switch reading.statusCode {
+			case http.StatusOK:
+				cache.Store(key, body)
+			case http.StatusServiceUnavailable:
+				if stale, storedAt, ok := cache.Load(key); ok {
+					age := time.Since(storedAt)
+					logger.WithContext(r.Context()).Warn("Serving stale response: Path=%s, Age=%s", r.URL.Path, age.Round(time.Second))
+					w.Header().Del("Retry-After")
+					w.Header().Set("X-Stale-Response", "true")
+					w.Header().Set("Age", strconv.Itoa(int(age.Seconds())))
+					reading.statusCode = http.StatusOK
+					body = common.MarkStaleResponseBody(stale, storedAt)
+				}
+			}
+
+			w.Header().Del("Content-Length")
+			w.WriteHeader(reading.statusCode)
+			w.Write(body)
+		})
+	}
+}
+
+// TenantMiddleware forwards the X-Tenant-ID header to the backend services as gRPC metadata,
+// so they apply that tenant's settings. Requests with a malformed tenant ID are rejected.
+func TenantMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID := r.Header.Get("X-Tenant-ID")
+			if tenantID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !common.ValidTenantID(tenantID) {
+				http.Error(w, "invalid tenant id", http.StatusBadRequest)
+				return
+			}
+
+			ctx := metadata.AppendToOutgoingContext(r.Context(), common.TenantIDMetadataKey, tenantID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// TrafficClassMiddleware forwards the X-Traffic-Class header to the backend services as gRPC metadata, so
+// bulk clients can mark their calls as batch traffic and leave priority to interactive ones. Requests with an
+// unknown class are rejected.
+func TrafficClassMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			class := strings.ToLower(r.Header.Get("X-Traffic-Class"))
+			if class == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !common.ValidTrafficClass(class) {
+				http.Error(w, "invalid traffic class", http.StatusBadRequest)
+				return
+			}
+
+			ctx := metadata.AppendToOutgoingContext(r.Context(), common.TrafficClassMetadataKey, class)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
 
 // LoggingMiddleware provides HTTP request logging functionality
@@ -45,220 +330,3230 @@ func LoggingMiddleware(logger *common.Logger) func(http.Handler) http.Handler {
 				clientIP = forwarded
 			}
 
-			logger.LogRequest(r.Method, r.URL.Path, clientIP, wrapped.statusCode, duration)
-		})
+			// The request context is only cancelled before the handler returns when the client went away
+			statusCode := wrapped.statusCode
+			if r.Context().Err() == context.Canceled {
+				statusCode = common.StatusClientClosedRequest
+			}
+
+			logger.WithContext(r.Context()).LogRequest(r.Method, r.URL.Path, clientIP, statusCode, duration)
+		})
+	}
+}
+
+// AuthorizationMiddleware rejects requests with 403 Forbidden when the authorization section of the runtime
+// config has a policy for the matched route and the caller, identified by the X-Caller-Role and X-Operator-ID
+// headers, does not pass it. Routes without a configured policy are left to the backend services' own checks.
+func AuthorizationMiddleware(runtimeConfig *common.RuntimeConfigManager, logger *common.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			template, err := route.GetPathTemplate()
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			policy, ok := runtimeConfig.Current().Authorization.RoutePolicy(r.Method, template)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			role := strings.ToLower(strings.TrimSpace(r.Header.Get("X-Caller-Role")))
+			operator := strings.TrimSpace(r.Header.Get("X-Operator-ID"))
+			if allowed, reason := policy.Check(role, operator); !allowed {
+				logger.WithContext(r.Context()).Warn("Rejected %s %s: %s", r.Method, template, reason)
+				http.Error(w, "permission denied", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// readOnlyFeatureFlag is the runtime config feature flag that switches the gateway into read-only mode.
+const readOnlyFeatureFlag = "read_only"
+
+// readOnlyPostRoutes are the path templates of the POST routes that never write, which stay available in
+// read-only mode: transaction simulations and message template previews.
+var readOnlyPostRoutes = map[string]bool{
+	"/transactions/simulate": true,
+	"/admin/tenants/{tenant_id}/message-templates/{event_type}/{channel}/preview": true,
+}
+
+// ReadOnlyMiddleware rejects mutating requests with 503 Service Unavailable while read-only mode is on.
+// Read-only mode is enabled by the READ_ONLY_MODE environment variable or the read_only runtime feature flag,
+// so it can be toggled during migrations and incidents without a restart. Reads and the POST routes listed in
+// readOnlyPostRoutes keep working.
+func ReadOnlyMiddleware(runtimeConfig *common.RuntimeConfigManager, readOnly bool, retryAfter time.Duration, logger *common.Logger) func(http.Handler) http.Handler {
+	retryAfterSeconds := strconv.Itoa(int(retryAfter.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+			if r.Method == http.MethodPost && readOnlyPostRoutes[routeTemplate(r)] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if readOnly || runtimeConfig.Current().FeatureEnabled(readOnlyFeatureFlag) {
+				logger.WithContext(r.Context()).Warn("Rejected %s %s: gateway is in read-only mode", r.Method, r.URL.Path)
+				w.Header().Set("Retry-After", retryAfterSeconds)
+				http.Error(w, "service is in read-only mode", http.StatusServiceUnavailable)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routeTemplate returns the path template of the route a request matched, or its path if it matched none.
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return r.URL.Path
+}
+
+// maxDebugCaptureBytes bounds how much of a body is buffered for debug logging, whatever the configured cap.
+const maxDebugCaptureBytes = 64 * 1024
+
+// DebugBodyLoggingMiddleware logs sanitized request and response bodies for the accounts and API keys
+// listed in the debug_logging section of the runtime config, so integrator issues can be troubleshot
+// in production without restarting the gateway or logging everyone's traffic.
+// A request matches when its path, request body or response body refers to a listed account, or when
+// its X-API-Key header is listed. Nothing is buffered while no targets are configured.
+func DebugBodyLoggingMiddleware(runtimeConfig *common.RuntimeConfigManager, logger *common.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			config := runtimeConfig.Current().DebugLogging
+			if !config.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var requestBody []byte
+			if r.Body != nil {
+				requestBody, _ = io.ReadAll(io.LimitReader(r.Body, maxDebugCaptureBytes))
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(requestBody), r.Body))
+			}
+
+			captured := &bodyCaptureWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(captured, r)
+
+			apiKey := r.Header.Get("X-API-Key")
+			accountID := debugAccountID(r, requestBody, captured.body.Bytes())
+			if !config.Matches(accountID, apiKey) {
+				return
+			}
+
+			limit := config.BodyLimit()
+			logger.WithContext(r.Context()).Info("Debug body log: %s %s AccountID=%s Status=%d Request=%s Response=%s",
+				r.Method, r.URL.Path, accountID, captured.statusCode,
+				common.SanitizeBody(requestBody, limit), common.SanitizeBody(captured.body.Bytes(), limit))
+		})
+	}
+}
+
+// debugAccountID determines which account a request concerns, from the route or either body.
+func debugAccountID(r *http.Request, requestBody, responseBody []byte) string {
+	vars := mux.Vars(r)
+	if id := vars["account_id"]; id != "" {
+		return id
+	}
+	if id := vars["id"]; id != "" && strings.HasPrefix(r.URL.Path, "/accounts/") {
+		return id
+	}
+
+	for _, body := range [][]byte{requestBody, responseBody} {
+		var payload struct {
+			AccountID string `json:"account_id"`
+			ID        string `json:"id"`
+		}
+		if json.Unmarshal(body, &payload) == nil {
+			if payload.AccountID != "" {
+				return payload.AccountID
+			}
+			if payload.ID != "" && r.URL.Path == "/accounts" {
+				return payload.ID
+			}
+		}
+	}
+	return ""
+}
+
+// bodyCaptureWriter wraps http.ResponseWriter to capture the status code and a bounded copy of the body.
+type bodyCaptureWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (bw *bodyCaptureWriter) WriteHeader(code int) {
+	bw.statusCode = code
+	bw.ResponseWriter.WriteHeader(code)
+}
+
+func (bw *bodyCaptureWriter) Write(b []byte) (int, error) {
+	if remaining := maxDebugCaptureBytes - bw.body.Len(); remaining > 0 {
+		bw.body.Write(b[:min(len(b), remaining)])
+	}
+	return bw.ResponseWriter.Write(b)
+}
+
+// Flush lets streamed responses reach the client as they are written.
+func (bw *bodyCaptureWriter) Flush() {
+	if flusher, ok := bw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// ResponseMaskingMiddleware hides response fields from callers according to the response_masking runtime config,
+// e.g. so read-only support operators see redacted document numbers and no balances. The rules are chosen by the
+// X-Caller-Role header. Only JSON responses are rewritten; other responses, such as CSV exports, pass through.
+func ResponseMaskingMiddleware(runtimeConfig *common.RuntimeConfigManager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := strings.ToLower(strings.TrimSpace(r.Header.Get("X-Caller-Role")))
+			rules := runtimeConfig.Current().ResponseMaskRulesFor(role)
+			if rules.Empty() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			masking := &jsonResponseBuffer{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(masking, r)
+			if !masking.buffering {
+				return
+			}
+
+			body := common.MaskResponseBody(masking.body.Bytes(), rules)
+			w.Header().Del("Content-Length")
+			w.WriteHeader(masking.statusCode)
+			w.Write(body)
+		})
+	}
+}
+
+// LocalizationMiddleware adds formatting metadata to JSON responses when the localize query parameter is set:
+// a locale-aware formatted_<field> next to each amount and balance, and an RFC 3339 <field>_iso next to each
+// unix timestamp. The locale is taken from the locale query parameter, then Accept-Language, then DefaultLocale,
+// and returned in Content-Language. It runs outside ResponseMaskingMiddleware, so hidden values are never formatted.
+func LocalizationMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if localize, _ := strconv.ParseBool(r.URL.Query().Get("localize")); !localize {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			locale, ok := common.ResolveLocale(r.URL.Query().Get("locale"))
+			if !ok {
+				if locale, ok = common.ResolveLocale(r.Header.Get("Accept-Language")); !ok {
+					locale = common.DefaultLocale
+				}
+			}
+
+			localizing := &jsonResponseBuffer{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(localizing, r)
+			if !localizing.buffering {
+				return
+			}
+
+			body := common.LocalizeResponseBody(localizing.body.Bytes(), locale)
+			w.Header().Del("Content-Length")
+			w.Header().Set("Content-Language", locale)
+			w.WriteHeader(localizing.statusCode)
+			w.Write(body)
+		})
+	}
+}
+
+// AmountMiddleware renders the amounts of JSON responses as decimals. The services exchange amounts as integer
+// cents in fields ending in _cents; clients receive them under the field name without the suffix, as decimal
+// numbers such as 10.50. It runs inside ResponseMaskingMiddleware and LocalizationMiddleware, so their rules
+// and formatting apply to the decimal fields.
+func AmountMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rendering := &jsonResponseBuffer{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(rendering, r)
+			if !rendering.buffering {
+				return
+			}
+
+			body := common.DecimalizeResponseBody(rendering.body.Bytes())
+			w.Header().Del("Content-Length")
+			w.WriteHeader(rendering.statusCode)
+			w.Write(body)
+		})
+	}
+}
+
+// jsonResponseBuffer holds back JSON responses so a middleware can rewrite them;
+// other responses are written through as they are produced.
+type jsonResponseBuffer struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (mw *jsonResponseBuffer) WriteHeader(code int) {
+	if mw.wroteHeader {
+		return
+	}
+	mw.wroteHeader = true
+	mw.statusCode = code
+	mw.buffering = strings.HasPrefix(mw.Header().Get("Content-Type"), "application/json")
+	if !mw.buffering {
+		mw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (mw *jsonResponseBuffer) Write(b []byte) (int, error) {
+	if !mw.wroteHeader {
+		mw.WriteHeader(http.StatusOK)
+	}
+	if mw.buffering {
+		return mw.body.Write(b)
+	}
+	return mw.ResponseWriter.Write(b)
+}
+
+// Flush lets streamed responses that are not rewritten reach the client as they are written.
+func (mw *jsonResponseBuffer) Flush() {
+	if !mw.wroteHeader {
+		mw.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := mw.ResponseWriter.(http.Flusher); ok && !mw.buffering {
+		flusher.Flush()
+	}
+}
+
+// responseWriter wraps http.ResponseWriter to capture status code
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Flush lets streamed responses reach the client as they are written.
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// NewGatewayService creates a new gateway service instance.
+// It takes gRPC client connections for account and transaction services and returns a configured GatewayService.
+// Repeated account deletes succeed unless IDEMPOTENT_DELETES is "false".
+func NewGatewayService(accountConn, transactionConn grpc.ClientConnInterface, logger *common.Logger) *GatewayService {
+	return &GatewayService{
+		accountClient:     pbAccount.NewAccountServiceClient(accountConn),
+		transactionClient: pbTransaction.NewTransactionServiceClient(transactionConn),
+		logger:            logger,
+		idempotentDeletes: os.Getenv("IDEMPOTENT_DELETES") != "false",
+	}
+}
+
+// CreateAccountHandler handles HTTP POST requests to create new accounts.
+// It accepts JSON input, converts it to gRPC format, and returns the created account or error.
+// A document number that already has an account gets 409 Conflict with the ALREADY_EXISTS code and the
+// existing account's ID, so clients can carry on with that account; if that account is closed the code is
+// ACCOUNT_CLOSED instead, as the document number cannot be reused until the account is anonymized.
+func (g *GatewayService) CreateAccountHandler(w http.ResponseWriter, r *http.Request) {
+	g.logger.Info("Creating new account")
+
+	var req struct {
+		DocumentNumber string       `json:"document_number"`
+		AccountType    string       `json:"account_type"`
+		InitialBalance common.Cents `json:"initial_balance"`
+		Draft          bool         `json:"draft"`
+		HolderName     string       `json:"holder_name"`
+		HolderEmail    string       `json:"holder_email"`
+		HolderPhone    string       `json:"holder_phone"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		g.logger.Error("Failed to decode JSON request: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	g.logger.Debug("Account creation request: DocumentNumber=%s, AccountType=%s, InitialBalance=%s",
+		req.DocumentNumber, req.AccountType, req.InitialBalance)
+
+	grpcReq := &pbAccount.CreateAccountRequest{
+		DocumentNumber:      req.DocumentNumber,
+		AccountType:         req.AccountType,
+		InitialBalanceCents: int64(req.InitialBalance),
+		Draft:               req.Draft,
+		HolderName:          req.HolderName,
+		HolderEmail:         req.HolderEmail,
+		HolderPhone:         req.HolderPhone,
+	}
+
+	start := time.Now()
+	resp, err := g.accountClient.CreateAccount(operatorContext(r), grpcReq)
+	duration := time.Since(start)
+
+	g.logger.WithContext(r.Context()).LogGRPC("CreateAccount", duration, err)
+
+	if err != nil {
+		g.logger.Error("Account service error: %v", err)
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error == "account quota exceeded" {
+		g.logger.Warn("Account creation rejected: %s", resp.Error)
+		http.Error(w, resp.Error, http.StatusConflict)
+		return
+	}
+	if resp.Error == "account already exists" || resp.Error == "account closed" {
+		g.logger.Warn("Account creation rejected: %s", resp.Error)
+		code := "ALREADY_EXISTS"
+		if resp.Error == "account closed" {
+			code = "ACCOUNT_CLOSED"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":      resp.Error,
+			"code":       code,
+			"account_id": resp.ExistingAccountId,
+		})
+		return
+	}
+	if resp.Error != "" {
+		g.logger.Error("Account creation failed: %s", resp.Error)
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	g.logger.Info("Account created successfully: ID=%s", resp.Account.Id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Account)
+}
+
+// GetAccountHandler handles HTTP GET requests to retrieve account details by ID.
+// It extracts the account ID from the URL path and returns the account information or error.
+// The account's version is returned as the ETag, for conditional updates with UpdateAccountHandler.
+func (g *GatewayService) GetAccountHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	accountID := vars["id"]
+
+	grpcReq := &pbAccount.GetAccountRequest{Id: accountID, MaxStalenessMs: maxStalenessMs(r)}
+	resp, err := g.accountClient.GetAccount(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", accountETag(resp.Account))
+	json.NewEncoder(w).Encode(resp.Account)
+}
+
+// GetAccountByDocumentHandler handles HTTP GET requests to retrieve an account by its holder's document number,
+// for clients that know the customer's document rather than the account ID. Like GetAccountHandler it returns
+// the account's version as the ETag.
+func (g *GatewayService) GetAccountByDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	grpcReq := &pbAccount.GetAccountByDocumentRequest{DocumentNumber: vars["document"], MaxStalenessMs: maxStalenessMs(r)}
+	resp, err := g.accountClient.GetAccountByDocument(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	case "database error":
+		http.Error(w, resp.Error, http.StatusInternalServerError)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", accountETag(resp.Account))
+	json.NewEncoder(w).Encode(resp.Account)
+}
+
+// maxStalenessMs returns the staleness the client accepts, in milliseconds, from the max-stale directive of
+// its Cache-Control header, for the max_staleness_ms hint of read RPCs. A bare max-stale accepts any staleness.
+// Without the directive it returns 0, so the read goes to the primary database.
+func maxStalenessMs(r *http.Request) int64 {
+	for _, directive := range strings.Split(r.Header.Get("Cache-Control"), ",") {
+		name, value, hasValue := strings.Cut(strings.TrimSpace(directive), "=")
+		if !strings.EqualFold(name, "max-stale") {
+			continue
+		}
+		if !hasValue {
+			return math.MaxInt64 / int64(time.Millisecond)
+		}
+		seconds, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+		if err != nil || seconds < 0 {
+			return 0
+		}
+		return min(seconds, math.MaxInt64/int64(time.Second)) * 1000
+	}
+	return 0
+}
+
+// accountETag returns the entity tag of an account, derived from its version.
+func accountETag(account *pbAccount.Account) string {
+	return strconv.Quote(strconv.FormatInt(account.GetVersion(), 10))
+}
+
+// parseIfMatch returns the account version required by an If-Match header, or 0 when any version
+// is accepted. Weak tags are compared by their value. ok is false when no version can match.
+func parseIfMatch(header string) (version int64, ok bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	tag, err := strconv.Unquote(strings.TrimPrefix(header, "W/"))
+	if err != nil {
+		return 0, false
+	}
+	version, err = strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// UpdateAccountHandler handles HTTP PUT requests that replace an account's document number and account type.
+// Both fields are required; holder_name, holder_email and holder_phone are optional and keep their current
+// value when omitted. With an If-Match header carrying the ETag from a previous read, the account
+// is only replaced if it has not changed since; otherwise the response is 412 Precondition Failed.
+// The updated account is returned with its new ETag.
+func (g *GatewayService) UpdateAccountHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		DocumentNumber string `json:"document_number"`
+		AccountType    string `json:"account_type"`
+		HolderName     string `json:"holder_name"`
+		HolderEmail    string `json:"holder_email"`
+		HolderPhone    string `json:"holder_phone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.DocumentNumber == "" || req.AccountType == "" {
+		http.Error(w, "document_number and account_type required", http.StatusBadRequest)
+		return
+	}
+
+	expectedVersion, ok := parseIfMatch(r.Header.Get("If-Match"))
+	if !ok {
+		http.Error(w, "version conflict", http.StatusPreconditionFailed)
+		return
+	}
+
+	resp, err := g.accountClient.UpdateAccount(operatorContext(r), &pbAccount.UpdateAccountRequest{
+		Id:              vars["id"],
+		DocumentNumber:  req.DocumentNumber,
+		AccountType:     req.AccountType,
+		ExpectedVersion: expectedVersion,
+		HolderName:      req.HolderName,
+		HolderEmail:     req.HolderEmail,
+		HolderPhone:     req.HolderPhone,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	case "version conflict":
+		http.Error(w, resp.Error, http.StatusPreconditionFailed)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", accountETag(resp.Account))
+	json.NewEncoder(w).Encode(resp.Account)
+}
+
+// DeleteAccountHandler handles HTTP DELETE requests for an account, returning 204 No Content with the ID of
+// the snapshot taken before the delete in the X-Account-Snapshot-ID header.
+// Deleting an account that is already deleted or closed also returns 204, so retried deletes are safe;
+// with idempotent deletes disabled it gets 404 Not Found, or 410 Gone with the ACCOUNT_CLOSED code.
+func (g *GatewayService) DeleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.accountClient.DeleteAccount(operatorContext(r), &pbAccount.DeleteAccountRequest{Id: vars["id"]})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "account not found", "account already closed":
+		if g.idempotentDeletes {
+			g.logger.WithContext(r.Context()).Info("Repeated account delete: ID=%s: %s", vars["id"], resp.Error)
+			break
+		}
+		if resp.Error == "account not found" {
+			http.Error(w, resp.Error, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusGone)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": resp.Error,
+			"code":  "ACCOUNT_CLOSED",
+		})
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	if resp.SnapshotId != "" {
+		w.Header().Set("X-Account-Snapshot-ID", resp.SnapshotId)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAccountsHandler handles HTTP GET requests to list accounts, newest first.
+// It accepts limit and offset, created_from/created_to Unix timestamps, min_balance/max_balance and
+// comma-separated tags/exclude_tags as query parameters and, like SearchAccountsHandler, forwards the
+// X-Caller-Role header.
+func (g *GatewayService) ListAccountsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	grpcReq := &pbAccount.ListAccountsRequest{}
+
+	for name, dest := range map[string]*int32{"limit": &grpcReq.Limit, "offset": &grpcReq.Offset} {
+		if value := query.Get(name); value != "" {
+			if parsed, err := strconv.Atoi(value); err == nil {
+				*dest = int32(parsed)
+			}
+		}
+	}
+	for name, dest := range map[string]*int64{"created_from": &grpcReq.CreatedFrom, "created_to": &grpcReq.CreatedTo} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid %s: must be a Unix timestamp", name), http.StatusBadRequest)
+			return
+		}
+		*dest = parsed
+	}
+	for name, dest := range map[string]**int64{"min_balance": &grpcReq.MinBalanceCents, "max_balance": &grpcReq.MaxBalanceCents} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := common.ParseCents(value)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid %s: must be an amount with at most two decimals", name), http.StatusBadRequest)
+			return
+		}
+		cents := int64(parsed)
+		*dest = &cents
+	}
+	for name, dest := range map[string]*[]string{"tags": &grpcReq.Tags, "exclude_tags": &grpcReq.ExcludeTags} {
+		if value := query.Get(name); value != "" {
+			*dest = strings.Split(value, ",")
+		}
+	}
+
+	ctx := r.Context()
+	if role := r.Header.Get("X-Caller-Role"); role != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, common.CallerRoleMetadataKey, role)
+	}
+
+	resp, err := g.accountClient.ListAccounts(ctx, grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	accounts := resp.Accounts
+	if accounts == nil {
+		accounts = []*pbAccount.Account{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"accounts": accounts,
+		"total":    resp.Total,
+	})
+}
+
+// SearchAccountsHandler handles HTTP GET requests to search accounts by partial document number.
+// It reads the q, match and limit query parameters and forwards the X-Caller-Role header, which is
+// expected to be set by the authenticating proxy in front of the gateway, so the account service can
+// decide whether document numbers are masked.
+func (g *GatewayService) SearchAccountsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := int32(0)
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = int32(l)
+		}
+	}
+
+	grpcReq := &pbAccount.SearchAccountsRequest{
+		Query: query.Get("q"),
+		Match: query.Get("match"),
+		Limit: limit,
+	}
+
+	ctx := r.Context()
+	if role := r.Header.Get("X-Caller-Role"); role != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, common.CallerRoleMetadataKey, role)
+	}
+
+	resp, err := g.accountClient.SearchAccounts(ctx, grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"accounts": resp.Accounts,
+	})
+}
+
+// GetTenantSettingsHandler handles HTTP GET requests for the settings of a tenant (card issuer).
+// It returns the settings stored for the environment the account service runs in.
+func (g *GatewayService) GetTenantSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.accountClient.GetTenantSettings(r.Context(), &pbAccount.GetTenantSettingsRequest{TenantId: vars["tenant_id"]})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id":   vars["tenant_id"],
+		"environment": resp.Environment,
+		"settings":    resp.Settings,
+	})
+}
+
+// UpdateTenantSettingsHandler handles HTTP PUT requests to replace the settings of a tenant.
+// Like SearchAccountsHandler it forwards the X-Caller-Role header; only admins may change settings.
+func (g *GatewayService) UpdateTenantSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var settings common.TenantSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	grpcReq := &pbAccount.UpdateTenantSettingsRequest{
+		TenantId: vars["tenant_id"],
+		Settings: &pbAccount.TenantSettings{
+			Currency:                  settings.Currency,
+			AllowedOperationTypes:     settings.AllowedOperationTypes,
+			MaxTransactionAmountCents: int64(settings.MaxTransactionAmount),
+			BalanceSource:             settings.BalanceSource,
+			ReportingCurrency:         settings.ReportingCurrency,
+			Sandbox:                   settings.Sandbox,
+			DocumentTypes:             settings.DocumentTypes,
+		},
+	}
+	if settings.Retention != nil {
+		grpcReq.Settings.Retention = &pbAccount.RetentionSettings{
+			TransactionDays:            int32(settings.Retention.TransactionDays),
+			AnonymizeClosedAccountDays: int32(settings.Retention.AnonymizeClosedAccountDays),
+		}
+	}
+	if len(settings.FeeSchedule) > 0 {
+		grpcReq.Settings.FeeSchedule = make(map[string]*pbAccount.FeeRule, len(settings.FeeSchedule))
+		for operationType, rule := range settings.FeeSchedule {
+			grpcReq.Settings.FeeSchedule[operationType] = &pbAccount.FeeRule{FixedCents: int64(rule.Fixed), Percent: rule.Percent}
+		}
+	}
+
+	ctx := r.Context()
+	if role := r.Header.Get("X-Caller-Role"); role != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, common.CallerRoleMetadataKey, role)
+	}
+
+	resp, err := g.accountClient.UpdateTenantSettings(ctx, grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id":   vars["tenant_id"],
+		"environment": resp.Environment,
+		"settings":    resp.Settings,
+	})
+}
+
+// operatorContext forwards the X-Caller-Role and X-Operator-ID headers, which are expected to be set by the
+// authenticating proxy in front of the gateway, as gRPC metadata for back-office operations.
+func operatorContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if role := r.Header.Get("X-Caller-Role"); role != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, common.CallerRoleMetadataKey, role)
+	}
+	if operator := r.Header.Get("X-Operator-ID"); operator != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, common.OperatorIDMetadataKey, operator)
+	}
+	return ctx
+}
+
+// writeAdjustmentResponse writes the result of a balance adjustment request or review.
+func writeAdjustmentResponse(w http.ResponseWriter, resp *pbAccount.BalanceAdjustmentResponse, successStatus int) {
+	switch resp.Error {
+	case "":
+	case "permission denied":
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	case "account not found", "adjustment not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	case "adjustment already reviewed", "insufficient balance":
+		http.Error(w, resp.Error, http.StatusConflict)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(successStatus)
+	json.NewEncoder(w).Encode(resp.Adjustment)
+}
+
+// RequestBalanceAdjustmentHandler handles HTTP POST requests to request a manual credit or debit for an account.
+// The adjustment is created as PENDING and only reaches the balance once another admin approves it.
+func (g *GatewayService) RequestBalanceAdjustmentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		Direction   string       `json:"direction"`
+		Amount      common.Cents `json:"amount"`
+		ReasonCode  string       `json:"reason_code"`
+		Description string       `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.accountClient.RequestBalanceAdjustment(operatorContext(r), &pbAccount.RequestBalanceAdjustmentRequest{
+		AccountId:   vars["id"],
+		Direction:   req.Direction,
+		AmountCents: int64(req.Amount),
+		ReasonCode:  req.ReasonCode,
+		Description: req.Description,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	writeAdjustmentResponse(w, resp, http.StatusCreated)
+}
+
+// ReviewBalanceAdjustmentHandler returns a handler for HTTP POST requests that approve or reject a pending
+// balance adjustment. An optional JSON body may carry a review note.
+func (g *GatewayService) ReviewBalanceAdjustmentHandler(approve bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		var req struct {
+			Note string `json:"note"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+				http.Error(w, "Invalid JSON", http.StatusBadRequest)
+				return
+			}
+		}
+
+		resp, err := g.accountClient.ReviewBalanceAdjustment(operatorContext(r), &pbAccount.ReviewBalanceAdjustmentRequest{
+			Id:      vars["id"],
+			Approve: approve,
+			Note:    req.Note,
+		})
+		if err != nil {
+			writeServiceError(w, r, "Account", err)
+			return
+		}
+
+		writeAdjustmentResponse(w, resp, http.StatusOK)
+	}
+}
+
+// ListBalanceAdjustmentsHandler handles HTTP GET requests for the balance adjustments of an account,
+// optionally filtered by the status query parameter.
+func (g *GatewayService) ListBalanceAdjustmentsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.accountClient.ListBalanceAdjustments(operatorContext(r), &pbAccount.ListBalanceAdjustmentsRequest{
+		AccountId: vars["id"],
+		Status:    r.URL.Query().Get("status"),
+	})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"adjustments": resp.Adjustments,
+	})
+}
+
+// writeDocumentChangeResponse writes the result of a document change request, verification or application.
+func writeDocumentChangeResponse(w http.ResponseWriter, resp *pbAccount.DocumentChangeResponse, successStatus int) {
+	switch resp.Error {
+	case "":
+	case "permission denied":
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	case "account not found", "document change not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	case "document number already in use", "document change already in progress", "document change already verified",
+		"document change not verified", "document change already applied", "document number changed since the request":
+		http.Error(w, resp.Error, http.StatusConflict)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(successStatus)
+	json.NewEncoder(w).Encode(resp.Change)
+}
+
+// RequestDocumentChangeHandler handles HTTP POST requests to change the document number of an account.
+// The change is created as PENDING and only reaches the account once another admin verifies it and it is applied.
+func (g *GatewayService) RequestDocumentChangeHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		DocumentNumber string `json:"document_number"`
+		Reason         string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.accountClient.RequestDocumentChange(operatorContext(r), &pbAccount.RequestDocumentChangeRequest{
+		AccountId:      vars["id"],
+		DocumentNumber: req.DocumentNumber,
+		Reason:         req.Reason,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	writeDocumentChangeResponse(w, resp, http.StatusCreated)
+}
+
+// VerifyDocumentChangeHandler returns a handler for HTTP POST requests that verify or reject a pending
+// document change. The JSON body carries the verification reference, required to verify, and an optional note.
+func (g *GatewayService) VerifyDocumentChangeHandler(approve bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		var req struct {
+			VerificationReference string `json:"verification_reference"`
+			Note                  string `json:"note"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+				http.Error(w, "Invalid JSON", http.StatusBadRequest)
+				return
+			}
+		}
+
+		resp, err := g.accountClient.VerifyDocumentChange(operatorContext(r), &pbAccount.VerifyDocumentChangeRequest{
+			Id:                    vars["id"],
+			Approve:               approve,
+			VerificationReference: req.VerificationReference,
+			Note:                  req.Note,
+		})
+		if err != nil {
+			writeServiceError(w, r, "Account", err)
+			return
+		}
+
+		writeDocumentChangeResponse(w, resp, http.StatusOK)
+	}
+}
+
+// ApplyDocumentChangeHandler handles HTTP POST requests that apply a verified document change to its account.
+func (g *GatewayService) ApplyDocumentChangeHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.accountClient.ApplyDocumentChange(operatorContext(r), &pbAccount.ApplyDocumentChangeRequest{Id: vars["id"]})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	writeDocumentChangeResponse(w, resp, http.StatusOK)
+}
+
+// ListDocumentChangesHandler handles HTTP GET requests for the document changes of an account,
+// optionally filtered by the status query parameter.
+func (g *GatewayService) ListDocumentChangesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.accountClient.ListDocumentChanges(operatorContext(r), &pbAccount.ListDocumentChangesRequest{
+		AccountId: vars["id"],
+		Status:    r.URL.Query().Get("status"),
+	})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"changes": resp.Changes,
+	})
+}
+
+// GetAccountAuditHandler handles HTTP GET requests for the audit log of an account, newest first, for
+// compliance review. The limit query parameter caps the number of entries. Only support and admin operators
+// may read it, identified by the X-Caller-Role header.
+func (g *GatewayService) GetAccountAuditHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	req := &pbAccount.GetAccountAuditRequest{AccountId: vars["id"]}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		l, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		req.Limit = int32(l)
+	}
+
+	resp, err := g.accountClient.GetAccountAudit(operatorContext(r), req)
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries": resp.Entries,
+	})
+}
+
+// ListAccountSnapshotsHandler handles HTTP GET requests for the snapshots taken of an account before it was
+// deleted or closed, newest first. Only admins may list them, identified by the X-Caller-Role header.
+func (g *GatewayService) ListAccountSnapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.accountClient.ListAccountSnapshots(operatorContext(r), &pbAccount.ListAccountSnapshotsRequest{AccountId: vars["id"]})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"snapshots": resp.Snapshots,
+	})
+}
+
+// RestoreAccountSnapshotHandler handles HTTP POST requests to undo the delete or close an account snapshot was
+// taken before. Only admins may restore snapshots, identified by the X-Caller-Role header.
+func (g *GatewayService) RestoreAccountSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.accountClient.RestoreAccountSnapshot(operatorContext(r), &pbAccount.RestoreAccountSnapshotRequest{Id: vars["id"]})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "permission denied":
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	case "snapshot not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	case "snapshot already restored", "account or document number exists", "account not closed":
+		http.Error(w, resp.Error, http.StatusConflict)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"account":               resp.Account,
+		"restored_transactions": resp.RestoredTransactions,
+	})
+}
+
+// ListAccessDecisionsHandler handles HTTP GET requests for the recorded authorization decisions, newest first.
+// The principal, method, decision, from and to (Unix seconds) query parameters filter the results; limit and
+// before_id page through them. Only admins may read the audit, identified by the X-Caller-Role header.
+func (g *GatewayService) ListAccessDecisionsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &pbAccount.ListAccessDecisionsRequest{
+		Principal: query.Get("principal"),
+		Method:    query.Get("method"),
+		Decision:  query.Get("decision"),
+	}
+	for name, field := range map[string]*int64{"from": &req.From, "to": &req.To, "before_id": &req.BeforeId} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid "+name, http.StatusBadRequest)
+				return
+			}
+			*field = parsed
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if l, err := strconv.Atoi(value); err == nil {
+			req.Limit = int32(l)
+		}
+	}
+
+	resp, err := g.accountClient.ListAccessDecisions(operatorContext(r), req)
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"decisions":      resp.Decisions,
+		"next_before_id": resp.NextBeforeId,
+	})
+}
+
+// SLOStatusHandler handles HTTP GET requests for the SLO status of the account and transaction services: the
+// burn rates of every method with a target in their runtime config. Support and admin callers may read it.
+func (g *GatewayService) SLOStatusHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := operatorContext(r)
+
+	accountResp, err := g.accountClient.GetSLOStatus(ctx, &pbAccount.GetSLOStatusRequest{})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+	transactionResp, err := g.transactionClient.GetSLOStatus(ctx, &pbTransaction.GetSLOStatusRequest{})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	for _, msg := range []string{accountResp.Error, transactionResp.Error} {
+		if msg == "permission denied" {
+			http.Error(w, msg, http.StatusForbidden)
+			return
+		}
+		if msg != "" {
+			http.Error(w, msg, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	accountMethods := make([]map[string]interface{}, 0, len(accountResp.Methods))
+	for _, method := range accountResp.Methods {
+		windows := make([]sloWindow, 0, len(method.Windows))
+		for _, window := range method.Windows {
+			windows = append(windows, window)
+		}
+		accountMethods = append(accountMethods, sloMethodJSON(method, windows))
+	}
+	transactionMethods := make([]map[string]interface{}, 0, len(transactionResp.Methods))
+	for _, method := range transactionResp.Methods {
+		windows := make([]sloWindow, 0, len(method.Windows))
+		for _, window := range method.Windows {
+			windows = append(windows, window)
+		}
+		transactionMethods = append(transactionMethods, sloMethodJSON(method, windows))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"services": map[string]interface{}{
+			"account-mgr":     accountMethods,
+			"transaction-mgr": transactionMethods,
+		},
+	})
+}
+
+// sloMethod and sloWindow are the SLO status messages, which the account and transaction protos both define.
+type sloMethod interface {
+	GetMethod() string
+	GetAvailabilityTarget() float64
+	GetLatencyTargetMs() int64
+	GetWithinBudget() bool
+}
+
+type sloWindow interface {
+	GetWindowSeconds() int64
+	GetTotal() int64
+	GetBad() int64
+	GetSuccessPercent() float64
+	GetBurnRate() float64
+}
+
+// sloMethodJSON converts the SLO status of a method to a JSON object. The protobuf structs omit zero values,
+// which would drop e.g. "within_budget": false and windows without bad calls.
+func sloMethodJSON(method sloMethod, windows []sloWindow) map[string]interface{} {
+	windowsJSON := make([]map[string]interface{}, 0, len(windows))
+	for _, window := range windows {
+		windowsJSON = append(windowsJSON, map[string]interface{}{
+			"window_seconds":  window.GetWindowSeconds(),
+			"total":           window.GetTotal(),
+			"bad":             window.GetBad(),
+			"success_percent": window.GetSuccessPercent(),
+			"burn_rate":       window.GetBurnRate(),
+		})
+	}
+	return map[string]interface{}{
+		"method":              method.GetMethod(),
+		"availability_target": method.GetAvailabilityTarget(),
+		"latency_target_ms":   method.GetLatencyTargetMs(),
+		"windows":             windowsJSON,
+		"within_budget":       method.GetWithinBudget(),
+	}
+}
+
+// GetFxRevaluationReportHandler handles HTTP GET requests for the month-end FX revaluation entries of a tenant
+// for one period (YYYY-MM), with their total unrealized gain or loss. Support and admin operators may read it.
+func (g *GatewayService) GetFxRevaluationReportHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.accountClient.GetFxRevaluationReport(operatorContext(r), &pbAccount.GetFxRevaluationReportRequest{
+		TenantId: vars["tenant_id"],
+		Period:   vars["period"],
+	})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tenant_id":             resp.TenantId,
+		"period":                resp.Period,
+		"currency":              resp.Currency,
+		"reporting_currency":    resp.ReportingCurrency,
+		"revaluations":          resp.Revaluations,
+		"total_unrealized_gain": common.Cents(resp.TotalUnrealizedGainCents),
+	})
+}
+
+// writeOnboardingResponse writes the result of an account onboarding operation.
+func writeOnboardingResponse(w http.ResponseWriter, account *pbAccount.Account, errMsg string) {
+	switch {
+	case errMsg == "":
+	case errMsg == "permission denied":
+		http.Error(w, errMsg, http.StatusForbidden)
+		return
+	case errMsg == "account not found":
+		http.Error(w, errMsg, http.StatusNotFound)
+		return
+	case strings.HasPrefix(errMsg, "cannot move account"), strings.HasPrefix(errMsg, "holder data can only be changed"):
+		http.Error(w, errMsg, http.StatusConflict)
+		return
+	default:
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
+
+// UpdateAccountHolderHandler handles HTTP PUT requests to set the holder and KYC data of a draft account.
+func (g *GatewayService) UpdateAccountHolderHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		HolderName   string `json:"holder_name"`
+		HolderEmail  string `json:"holder_email"`
+		HolderPhone  string `json:"holder_phone"`
+		KYCReference string `json:"kyc_reference"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.accountClient.UpdateAccountHolder(operatorContext(r), &pbAccount.UpdateAccountHolderRequest{
+		AccountId:    vars["id"],
+		HolderName:   req.HolderName,
+		HolderEmail:  req.HolderEmail,
+		HolderPhone:  req.HolderPhone,
+		KycReference: req.KYCReference,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	writeOnboardingResponse(w, resp.Account, resp.Error)
+}
+
+// AdvanceOnboardingHandler handles HTTP POST requests to move an account to another onboarding state.
+// It forwards the X-Caller-Role header, since approving or rejecting KYC requires a support or admin role.
+func (g *GatewayService) AdvanceOnboardingHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.accountClient.AdvanceOnboarding(operatorContext(r), &pbAccount.AdvanceOnboardingRequest{
+		AccountId: vars["id"],
+		Status:    req.Status,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	writeOnboardingResponse(w, resp.Account, resp.Error)
+}
+
+// SetOverdraftLimitHandler handles HTTP PUT requests to set how far below zero debits may take the balance of a
+// checking account. It forwards the X-Caller-Role and X-Operator-ID headers, since only admin operators may set it.
+func (g *GatewayService) SetOverdraftLimitHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		OverdraftLimit common.Cents `json:"overdraft_limit"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.accountClient.SetOverdraftLimit(operatorContext(r), &pbAccount.SetOverdraftLimitRequest{
+		AccountId:           vars["id"],
+		OverdraftLimitCents: int64(req.OverdraftLimit),
+	})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "permission denied":
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	case "account not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	case "overdraft is only available for checking accounts", "account is overdrawn by more than the overdraft limit":
+		http.Error(w, resp.Error, http.StatusConflict)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Account)
+}
+
+// UpdateAccountTagsHandler handles HTTP PATCH requests that add and remove tags of an account.
+// It forwards the X-Caller-Role and X-Operator-ID headers, since only admin operators may change tags.
+func (g *GatewayService) UpdateAccountTagsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		Add    []string `json:"add"`
+		Remove []string `json:"remove"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.accountClient.UpdateAccountTags(operatorContext(r), &pbAccount.UpdateAccountTagsRequest{
+		AccountId: vars["id"],
+		Add:       req.Add,
+		Remove:    req.Remove,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "permission denied":
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	case "account not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Account)
+}
+
+// GetBalanceHandler handles HTTP GET requests to retrieve account balance by ID.
+// It extracts the account ID from the URL path and returns the current balance or error,
+// or with an at query parameter the balance at that past point in time.
+func (g *GatewayService) GetBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	accountID := vars["id"]
+
+	if at := r.URL.Query().Get("at"); at != "" {
+		g.getBalanceAt(w, r, accountID, at)
+		return
+	}
+
+	grpcReq := &pbAccount.GetBalanceRequest{AccountId: accountID, MaxStalenessMs: maxStalenessMs(r)}
+	resp, err := g.accountClient.GetBalance(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]common.Cents{"balance": common.Cents(resp.BalanceCents)})
+}
+
+// getBalanceAt responds with the balance the account had at at, a Unix timestamp, derived from its ledger.
+func (g *GatewayService) getBalanceAt(w http.ResponseWriter, r *http.Request, accountID, at string) {
+	timestamp, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid at: must be a Unix timestamp", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.accountClient.GetBalanceAt(r.Context(), &pbAccount.GetBalanceAtRequest{AccountId: accountID, Timestamp: timestamp})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error == "account not found" {
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"balance": common.Cents(resp.BalanceCents),
+		"at":      resp.Timestamp,
+	})
+}
+
+// GetBalancesHandler handles HTTP GET requests for the balances of an account in every currency,
+// with the amounts held and available and, for credit accounts, the credit availability.
+func (g *GatewayService) GetBalancesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.accountClient.GetBalances(r.Context(), &pbAccount.GetBalancesRequest{AccountId: vars["id"], MaxStalenessMs: maxStalenessMs(r)})
+	if err != nil {
+		writeServiceError(w, r, "Account", err)
+		return
+	}
+
+	if resp.Error == "account not found" {
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"account_id": resp.AccountId,
+		"balances":   resp.Balances,
+		"credit":     resp.Credit,
+	})
+}
+
+// overviewRecentTransactions is how many of the latest transactions an account overview includes.
+const overviewRecentTransactions = 10
+
+// AccountOverviewHandler handles HTTP GET requests for an account together with its balances and latest
+// transactions, fetched from the backend services concurrently. The account is required; if the balances
+// or the transactions cannot be loaded the overview is returned without them, with the reason in errors.
+func (g *GatewayService) AccountOverviewHandler(w http.ResponseWriter, r *http.Request) {
+	accountID := mux.Vars(r)["id"]
+	maxStaleness := maxStalenessMs(r)
+
+	var (
+		account     *pbAccount.GetAccountResponse
+		balances    *pbAccount.GetBalancesResponse
+		history     *pbTransaction.GetTransactionHistoryResponse
+		accountErr  error
+		balancesErr error
+		historyErr  error
+	)
+	group, _ := common.NewWorkerGroup(r.Context(), 3, common.CollectErrors)
+	group.Go(func(ctx context.Context) error {
+		account, accountErr = g.accountClient.GetAccount(ctx, &pbAccount.GetAccountRequest{Id: accountID, MaxStalenessMs: maxStaleness})
+		return accountErr
+	})
+	group.Go(func(ctx context.Context) error {
+		balances, balancesErr = g.accountClient.GetBalances(ctx, &pbAccount.GetBalancesRequest{AccountId: accountID, MaxStalenessMs: maxStaleness})
+		return balancesErr
+	})
+	group.Go(func(ctx context.Context) error {
+		history, historyErr = g.transactionClient.GetTransactionHistory(ctx, &pbTransaction.GetTransactionHistoryRequest{
+			AccountId: accountID,
+			Limit:     overviewRecentTransactions,
+		})
+		return historyErr
+	})
+	if err := group.Wait(); err != nil {
+		g.logger.WithContext(r.Context()).Warn("Account overview incomplete: AccountID=%s, Error=%v", accountID, err)
+	}
+
+	if accountErr != nil {
+		writeServiceError(w, r, "Account", accountErr)
+		return
+	}
+	if account.Error != "" {
+		http.Error(w, account.Error, http.StatusNotFound)
+		return
+	}
+
+	overview := map[string]interface{}{"account": account.Account}
+	failures := map[string]string{}
+	switch {
+	case balancesErr != nil:
+		failures["balances"] = fmt.Sprintf("Account service error: %v", balancesErr)
+	case balances.Error != "":
+		failures["balances"] = balances.Error
+	default:
+		overview["balances"] = balances.Balances
+		overview["credit"] = balances.Credit
+	}
+	switch {
+	case status.Code(historyErr) == codes.Unavailable:
+		failures["recent_transactions"] = "transaction service unavailable"
+	case historyErr != nil:
+		failures["recent_transactions"] = fmt.Sprintf("Transaction service error: %v", historyErr)
+	case history.Error != "":
+		failures["recent_transactions"] = history.Error
+	default:
+		overview["recent_transactions"] = history.Transactions
+	}
+	if len(failures) > 0 {
+		overview["errors"] = failures
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", accountETag(account.Account))
+	json.NewEncoder(w).Encode(overview)
+}
+
+// decodeCreateTransactionRequest reads a transaction from a JSON request body.
+func decodeCreateTransactionRequest(r *http.Request) (*pbTransaction.CreateTransactionRequest, error) {
+	var req struct {
+		AccountID        string       `json:"account_id"`
+		OperationType    string       `json:"operation_type"`
+		Amount           common.Cents `json:"amount"`
+		Description      string       `json:"description"`
+		ExternalID       string       `json:"external_id"`
+		Category         string       `json:"category"`
+		BatchID          string       `json:"batch_id"`
+		BatchItem        int32        `json:"batch_item"`
+		InstallmentCount int32        `json:"installment_count"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+
+	return &pbTransaction.CreateTransactionRequest{
+		AccountId:        req.AccountID,
+		OperationType:    req.OperationType,
+		AmountCents:      int64(req.Amount),
+		Description:      req.Description,
+		ExternalId:       req.ExternalID,
+		Category:         req.Category,
+		BatchId:          req.BatchID,
+		BatchItem:        req.BatchItem,
+		InstallmentCount: req.InstallmentCount,
+	}, nil
+}
+
+// CreateTransactionHandler handles HTTP POST requests to create new transactions.
+// It accepts JSON input, converts it to gRPC format, and returns the created transaction or error.
+func (g *GatewayService) CreateTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	grpcReq, err := decodeCreateTransactionRequest(r)
+	if err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.transactionClient.CreateTransaction(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "batch item already processed", "external_id already used":
+		http.Error(w, resp.Error, http.StatusConflict)
+		return
+	case accountRateLimited:
+		writeAccountRateLimited(w)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Transaction)
+}
+
+// SimulateTransactionHandler handles HTTP POST requests to dry-run a transaction.
+// It accepts the same JSON input as CreateTransactionHandler and returns the transaction that would be
+// created and the resulting account balance, without storing anything.
+func (g *GatewayService) SimulateTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	grpcReq, err := decodeCreateTransactionRequest(r)
+	if err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	grpcReq.Simulate = true
+
+	resp, err := g.transactionClient.CreateTransaction(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"transaction":   resp.Transaction,
+		"balance_after": common.Cents(resp.BalanceAfterCents),
+		"simulated":     resp.Simulated,
+	})
+}
+
+// PaymentPreviewHandler handles HTTP GET requests showing how a payment would be applied to an account.
+// It simulates a PAYMENT of the amount query parameter, so the same checks as a real payment apply.
+// The allocations list the outstanding purchases and withdrawals the payment would discharge, oldest first,
+// followed by the remainder credited to the balance, if any.
+func (g *GatewayService) PaymentPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	accountID := vars["id"]
+
+	amount, err := common.ParseCents(r.URL.Query().Get("amount"))
+	if err != nil {
+		http.Error(w, "Invalid amount", http.StatusBadRequest)
+		return
+	}
+
+	grpcReq := &pbTransaction.CreateTransactionRequest{
+		AccountId:     accountID,
+		OperationType: "PAYMENT",
+		AmountCents:   int64(amount),
+		Simulate:      true,
+	}
+
+	resp, err := g.transactionClient.CreateTransaction(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	if resp.Error == "account not found" {
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	allocations := make([]map[string]interface{}, 0, len(resp.Discharges)+1)
+	for _, discharge := range resp.Discharges {
+		allocations = append(allocations, map[string]interface{}{
+			"target":         "transaction",
+			"transaction_id": discharge.TransactionId,
+			"amount":         common.Cents(discharge.AmountCents),
+			"balance_after":  common.Cents(discharge.BalanceAfterCents),
+		})
+	}
+	if remainder := common.Cents(resp.Transaction.GetBalanceCents()); remainder > 0 {
+		allocations = append(allocations, map[string]interface{}{"target": "balance", "amount": remainder})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	balanceAfter := common.Cents(resp.BalanceAfterCents)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"account_id":     accountID,
+		"amount":         amount,
+		"balance_before": balanceAfter - amount,
+		"balance_after":  balanceAfter,
+		"allocations":    allocations,
+	})
+}
+
+// GetTransactionHandler handles HTTP GET requests to retrieve transaction details by ID.
+// It extracts the transaction ID from the URL path, or the external ID from the external_id query parameter,
+// and returns the transaction information or error.
+func (g *GatewayService) GetTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	grpcReq := &pbTransaction.GetTransactionRequest{Id: vars["id"], ExternalId: vars["external_id"]}
+	resp, err := g.transactionClient.GetTransaction(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Transaction)
+}
+
+// UpdateTransactionHandler handles HTTP PATCH requests that edit the description, tags or metadata of a transaction.
+// Only the fields present in the JSON body are replaced; amounts and operation types cannot be edited.
+// Like the balance adjustment handlers it forwards the X-Caller-Role and X-Operator-ID headers.
+func (g *GatewayService) UpdateTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		Description *string            `json:"description"`
+		Tags        *[]string          `json:"tags"`
+		Metadata    *map[string]string `json:"metadata"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	grpcReq := &pbTransaction.UpdateTransactionRequest{Id: vars["id"]}
+	if req.Description != nil {
+		grpcReq.Description = *req.Description
+		grpcReq.UpdateMask = append(grpcReq.UpdateMask, "description")
+	}
+	if req.Tags != nil {
+		grpcReq.Tags = *req.Tags
+		grpcReq.UpdateMask = append(grpcReq.UpdateMask, "tags")
+	}
+	if req.Metadata != nil {
+		grpcReq.Metadata = *req.Metadata
+		grpcReq.UpdateMask = append(grpcReq.UpdateMask, "metadata")
+	}
+	if len(grpcReq.UpdateMask) == 0 {
+		http.Error(w, "nothing to update", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.transactionClient.UpdateTransaction(operatorContext(r), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "permission denied":
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	case "not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Transaction)
+}
+
+// ReverseTransactionHandler handles HTTP POST requests that reverse a completed transaction with a compensating
+// REVERSAL transaction. The JSON body carries the required reason. Support staff and admins may reverse
+// transactions, identified by the X-Caller-Role and X-Operator-ID headers.
+func (g *GatewayService) ReverseTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.transactionClient.ReverseTransaction(operatorContext(r), &pbTransaction.ReverseTransactionRequest{
+		Id:     vars["id"],
+		Reason: req.Reason,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "permission denied":
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	case "not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	case "transaction already reversed":
+		http.Error(w, resp.Error, http.StatusConflict)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"original": resp.Original,
+		"reversal": resp.Reversal,
+	})
+}
+
+// GetTransactionTimelineHandler handles HTTP GET requests for a transaction and its linked records in
+// chronological order. Only support and admin operators may read timelines, identified by the X-Caller-Role header.
+func (g *GatewayService) GetTransactionTimelineHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.transactionClient.GetTransactionTimeline(operatorContext(r), &pbTransaction.GetTransactionTimelineRequest{Id: vars["id"]})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "permission denied":
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	case "not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"transaction": resp.Transaction,
+		"events":      resp.Events,
+	})
+}
+
+// GetTransactionHistoryHandler handles HTTP GET requests to retrieve transaction history for an account.
+// It supports pagination with limit and page_token query parameters (offset is still accepted for older clients)
+// and returns the transaction list with total count and the token for the next page. The search query parameter
+// narrows the history to transactions whose description contains it.
+func (g *GatewayService) GetTransactionHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	accountID := vars["account_id"]
+
+	limitStr := r.URL.Query().Get("limit")
+	offsetStr := r.URL.Query().Get("offset")
+	pageToken := r.URL.Query().Get("page_token")
+
+	limit := int32(50)
+	offset := int32(0)
+
+	if limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil {
+			limit = int32(l)
+		}
+	}
+
+	if offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil {
+			offset = int32(o)
+		}
+	}
+
+	grpcReq := &pbTransaction.GetTransactionHistoryRequest{
+		AccountId: accountID,
+		Limit:     limit,
+		Offset:    offset,
+		PageToken: pageToken,
+		Search:    r.URL.Query().Get("search"),
+	}
+
+	resp, err := g.transactionClient.GetTransactionHistory(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"transactions":    resp.Transactions,
+		"total":           resp.Total,
+		"next_page_token": resp.NextPageToken,
+		"applied_limit":   resp.AppliedLimit,
+	})
+}
+
+// AggregateTransactionsHandler handles HTTP GET requests for per-bucket transaction totals of an account.
+// It accepts group_by (day, week or month) and from/to Unix timestamps as query parameters
+// and returns the count and amount of each operation type per bucket.
+func (g *GatewayService) AggregateTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+
+	grpcReq := &pbTransaction.AggregateTransactionsRequest{
+		AccountId: vars["account_id"],
+		GroupBy:   query.Get("group_by"),
+	}
+
+	for name, dest := range map[string]*int64{"from": &grpcReq.From, "to": &grpcReq.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid %s: must be a Unix timestamp", name), http.StatusBadRequest)
+			return
+		}
+		*dest = parsed
+	}
+
+	resp, err := g.transactionClient.AggregateTransactions(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	buckets := resp.Buckets
+	if buckets == nil {
+		buckets = []*pbTransaction.TransactionBucket{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"buckets": buckets,
+	})
+}
+
+// exportColumns renders the columns a transaction history export may contain, by name.
+var exportColumns = map[string]func(t *pbTransaction.Transaction) string{
+	"id":                      func(t *pbTransaction.Transaction) string { return t.Id },
+	"account_id":              func(t *pbTransaction.Transaction) string { return t.AccountId },
+	"operation_type":          func(t *pbTransaction.Transaction) string { return t.OperationType },
+	"amount":                  func(t *pbTransaction.Transaction) string { return common.Cents(t.AmountCents).String() },
+	"description":             func(t *pbTransaction.Transaction) string { return csvText(t.Description) },
+	"status":                  func(t *pbTransaction.Transaction) string { return t.Status },
+	"external_id":             func(t *pbTransaction.Transaction) string { return csvText(t.ExternalId) },
+	"tags":                    func(t *pbTransaction.Transaction) string { return csvText(strings.Join(t.Tags, ",")) },
+	"created_at":              func(t *pbTransaction.Transaction) string { return time.Unix(t.CreatedAt, 0).UTC().Format(time.RFC3339) },
+	"transfer_id":             func(t *pbTransaction.Transaction) string { return t.TransferId },
+	"original_transaction_id": func(t *pbTransaction.Transaction) string { return t.OriginalTransactionId },
+}
+
+// csvText neutralizes free text that spreadsheets would evaluate as a formula by prefixing it with a quote.
+// Quoting of separators, quotes and line breaks is left to the CSV writer.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// defaultExportColumns are the columns of an export that does not choose them.
+var defaultExportColumns = []string{"id", "account_id", "operation_type", "amount", "description", "status", "external_id", "tags", "created_at"}
+
+// parseExportColumns parses the comma-separated columns query parameter of an export, returning the default
+// columns when it is empty. Unknown, repeated and empty columns are refused.
+func parseExportColumns(value string) ([]string, error) {
+	if value == "" {
+		return defaultExportColumns, nil
+	}
+	var columns []string
+	seen := make(map[string]bool)
+	for _, column := range strings.Split(value, ",") {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			return nil, fmt.Errorf("empty column in %q", value)
+		}
+		if _, ok := exportColumns[column]; !ok {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("duplicate column %q", column)
+		}
+		seen[column] = true
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// ExportTransactionHistoryHandler handles HTTP GET requests to download an account's transaction history as
+// CSV, oldest first. It accepts format (csv, the default), columns, from/to Unix timestamps, status and
+// operation_type as query parameters. The file is rendered from the StreamTransactions stream, a row written and
+// flushed per transaction, so the gateway holds no more than one transaction whatever the size of the history;
+// a failure after the download has started aborts the response, so clients see a truncated transfer rather
+// than an incomplete file.
+func (g *GatewayService) ExportTransactionHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+
+	if format := query.Get("format"); format != "" && format != "csv" {
+		http.Error(w, "format must be csv", http.StatusBadRequest)
+		return
+	}
+	columns, err := parseExportColumns(query.Get("columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	grpcReq := &pbTransaction.StreamTransactionsRequest{
+		AccountId:     vars["account_id"],
+		Status:        query.Get("status"),
+		OperationType: query.Get("operation_type"),
+	}
+
+	for name, dest := range map[string]*int64{"from": &grpcReq.From, "to": &grpcReq.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid %s: must be a Unix timestamp", name), http.StatusBadRequest)
+			return
+		}
+		*dest = parsed
+	}
+
+	stream, err := g.transactionClient.StreamTransactions(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	// Request errors arrive with the first message, before anything is written
+	transaction, err := stream.Recv()
+	if status.Code(err) == codes.InvalidArgument {
+		http.Error(w, status.Convert(err).Message(), http.StatusBadRequest)
+		return
+	}
+	if err != nil && err != io.EOF {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"transactions-%s.csv\"", grpcReq.AccountId))
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	writer := csv.NewWriter(w)
+	row := make([]string, len(columns))
+	// The header is written even for an account without transactions
+	writer.Write(columns)
+	for ; err == nil; transaction, err = stream.Recv() {
+		for i, column := range columns {
+			row[i] = exportColumns[column](transaction)
+		}
+		writer.Write(row)
+		writer.Flush()
+		if writer.Error() != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	writer.Flush()
+	if err != io.EOF {
+		g.logger.WithContext(r.Context()).Error("Transaction history export aborted: AccountID=%s, Error=%v", grpcReq.AccountId, err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// StreamTransactionsHandler handles HTTP GET requests to download an account's full transaction history as
+// NDJSON, one transaction per line, oldest first. It accepts from/to Unix timestamps, status and operation_type
+// as query parameters. Lines are written and flushed as the transactions arrive, with amounts rendered as decimals
+// like the JSON responses; a failure after the download has started aborts the response, so clients see a
+// truncated transfer rather than an incomplete file.
+func (g *GatewayService) StreamTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+
+	grpcReq := &pbTransaction.StreamTransactionsRequest{
+		AccountId:     vars["account_id"],
+		Status:        query.Get("status"),
+		OperationType: query.Get("operation_type"),
+	}
+
+	for name, dest := range map[string]*int64{"from": &grpcReq.From, "to": &grpcReq.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid %s: must be a Unix timestamp", name), http.StatusBadRequest)
+			return
+		}
+		*dest = parsed
+	}
+
+	stream, err := g.transactionClient.StreamTransactions(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	// Request errors arrive with the first message, before anything is written
+	transaction, err := stream.Recv()
+	if status.Code(err) == codes.InvalidArgument {
+		http.Error(w, status.Convert(err).Message(), http.StatusBadRequest)
+		return
+	}
+	if err != nil && err != io.EOF {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"transactions-%s.ndjson\"", grpcReq.AccountId))
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	for ; err == nil; transaction, err = stream.Recv() {
+		line, marshalErr := json.Marshal(transaction)
+		if marshalErr != nil {
+			g.logger.WithContext(r.Context()).Error("Transaction stream aborted: AccountID=%s, Error=%v", grpcReq.AccountId, marshalErr)
+			panic(http.ErrAbortHandler)
+		}
+		if _, err := w.Write(append(common.DecimalizeResponseBody(line), '\n')); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if err != io.EOF {
+		g.logger.WithContext(r.Context()).Error("Transaction stream aborted: AccountID=%s, Error=%v", grpcReq.AccountId, err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// SetBudgetHandler handles HTTP PUT requests that create or replace the monthly spending budget of one
+// category of an account. The JSON body carries monthly_limit and optional thresholds, as percentages of the limit.
+func (g *GatewayService) SetBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		MonthlyLimit common.Cents `json:"monthly_limit"`
+		Thresholds   []int32      `json:"thresholds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.transactionClient.SetBudget(r.Context(), &pbTransaction.SetBudgetRequest{
+		AccountId:         vars["account_id"],
+		Category:          vars["category"],
+		MonthlyLimitCents: int64(req.MonthlyLimit),
+		Thresholds:        req.Thresholds,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "account not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Budget)
+}
+
+// DeleteBudgetHandler handles HTTP DELETE requests that remove the budget of one category of an account.
+func (g *GatewayService) DeleteBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.transactionClient.DeleteBudget(r.Context(), &pbTransaction.DeleteBudgetRequest{
+		AccountId: vars["account_id"],
+		Category:  vars["category"],
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "budget not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetBudgetStatusHandler handles HTTP GET requests for the spending against each budget of an account.
+// The month query parameter (YYYY-MM, UTC) selects the month; it defaults to the current one.
+func (g *GatewayService) GetBudgetStatusHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.transactionClient.GetBudgetStatus(r.Context(), &pbTransaction.GetBudgetStatusRequest{
+		AccountId: vars["account_id"],
+		Month:     r.URL.Query().Get("month"),
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	budgets := resp.Budgets
+	if budgets == nil {
+		budgets = []*pbTransaction.BudgetStatus{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"month":   resp.Month,
+		"budgets": budgets,
+	})
+}
+
+// GetStatementHandler handles HTTP GET requests for the statement of an account for a calendar month, given as
+// YYYY-MM in the path. The statement is generated on the first request for a month that has ended, and every
+// later request returns the same statement.
+func (g *GatewayService) GetStatementHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.transactionClient.GenerateStatement(r.Context(), &pbTransaction.GenerateStatementRequest{
+		AccountId: vars["account_id"],
+		Period:    vars["period"],
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "account not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Statement)
+}
+
+// GetBusinessDayStatusHandler handles HTTP GET requests for the status of a UTC business day, given as
+// YYYY-MM-DD in the path, or of the current day. With an account_id query parameter, the response includes the
+// balance the end-of-day close recorded for the account.
+func (g *GatewayService) GetBusinessDayStatusHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := g.transactionClient.GetBusinessDayStatus(r.Context(), &pbTransaction.GetBusinessDayStatusRequest{
+		Date:      mux.Vars(r)["date"],
+		AccountId: r.URL.Query().Get("account_id"),
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	if resp.Error != "" {
+		status := http.StatusBadRequest
+		if resp.Error == "database error" {
+			status = http.StatusInternalServerError
+		}
+		http.Error(w, resp.Error, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// CreateRecurringRuleHandler handles HTTP POST requests that create a recurring rule on an account. The JSON body
+// carries operation_type, amount, frequency (DAILY, WEEKLY or MONTHLY), an optional description and optional
+// start_at and end_at Unix times; the first run is made at start_at, or now.
+func (g *GatewayService) CreateRecurringRuleHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OperationType string       `json:"operation_type"`
+		Amount        common.Cents `json:"amount"`
+		Description   string       `json:"description"`
+		Frequency     string       `json:"frequency"`
+		StartAt       int64        `json:"start_at"`
+		EndAt         int64        `json:"end_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := g.transactionClient.CreateRecurringRule(r.Context(), &pbTransaction.CreateRecurringRuleRequest{
+		AccountId:     mux.Vars(r)["account_id"],
+		OperationType: req.OperationType,
+		AmountCents:   int64(req.Amount),
+		Description:   req.Description,
+		Frequency:     req.Frequency,
+		StartAt:       req.StartAt,
+		EndAt:         req.EndAt,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	if writeRecurringRuleError(w, resp.Error) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp.Rule)
+}
+
+// ListRecurringRulesHandler handles HTTP GET requests for the recurring rules of an account, newest first. The
+// status query parameter keeps only the ACTIVE, CANCELLED or COMPLETED ones.
+func (g *GatewayService) ListRecurringRulesHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := g.transactionClient.ListRecurringRules(r.Context(), &pbTransaction.ListRecurringRulesRequest{
+		AccountId: mux.Vars(r)["account_id"],
+		Status:    r.URL.Query().Get("status"),
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	if writeRecurringRuleError(w, resp.Error) {
+		return
+	}
+
+	rules := resp.Rules
+	if rules == nil {
+		rules = []*pbTransaction.RecurringRule{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rules": rules,
+	})
+}
+
+// CancelRecurringRuleHandler handles HTTP DELETE requests that cancel a recurring rule of an account. The rule is
+// kept, CANCELLED, with the transactions it made.
+func (g *GatewayService) CancelRecurringRuleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	resp, err := g.transactionClient.CancelRecurringRule(r.Context(), &pbTransaction.CancelRecurringRuleRequest{
+		AccountId: vars["account_id"],
+		Id:        vars["rule_id"],
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	if writeRecurringRuleError(w, resp.Error) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Rule)
+}
+
+// writeRecurringRuleError writes the HTTP error for the error of a recurring rule request, if any, and reports
+// whether it did.
+func writeRecurringRuleError(w http.ResponseWriter, msg string) bool {
+	switch msg {
+	case "":
+		return false
+	case "account not found", "recurring rule not found":
+		http.Error(w, msg, http.StatusNotFound)
+	case "recurring rule not active":
+		http.Error(w, msg, http.StatusConflict)
+	case "database error":
+		http.Error(w, msg, http.StatusInternalServerError)
+	default:
+		http.Error(w, msg, http.StatusBadRequest)
+	}
+	return true
+}
+
+// ListEventDeliveriesHandler handles HTTP GET requests for the event delivery history of an account.
+// It accepts event_type, status, limit and page_token query parameters and returns the account's
+// events, newest first, with their delivery status per subscriber.
+func (g *GatewayService) ListEventDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+
+	grpcReq := &pbTransaction.ListEventDeliveriesRequest{
+		AccountId: vars["account_id"],
+		EventType: query.Get("event_type"),
+		Status:    query.Get("status"),
+		PageToken: query.Get("page_token"),
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil {
+		grpcReq.Limit = int32(limit)
+	}
+
+	resp, err := g.transactionClient.ListEventDeliveries(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	events := resp.Events
+	if events == nil {
+		events = []*pbTransaction.AccountEvent{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"events":          events,
+		"next_page_token": resp.NextPageToken,
+	})
+}
+
+// writeWebhookSigningKeyResponse writes the result of a webhook signing key creation or retirement.
+func writeWebhookSigningKeyResponse(w http.ResponseWriter, resp *pbTransaction.WebhookSigningKeyResponse, successStatus int) {
+	switch resp.Error {
+	case "":
+	case "permission denied":
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	case "unknown subscriber", "signing key not found":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	case "signing key already retired", "cannot retire the last active signing key", "too many active signing keys":
+		http.Error(w, resp.Error, http.StatusConflict)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(successStatus)
+	json.NewEncoder(w).Encode(resp.Key)
+}
+
+// ListWebhookSigningKeysHandler handles HTTP GET requests for the signing keys of event deliveries to a subscriber.
+// Only admin operators, identified by the X-Caller-Role and X-Operator-ID headers, may list keys; secrets are never listed.
+func (g *GatewayService) ListWebhookSigningKeysHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := g.transactionClient.ListWebhookSigningKeys(operatorContext(r), &pbTransaction.ListWebhookSigningKeysRequest{
+		Subscriber: mux.Vars(r)["subscriber"],
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	switch resp.Error {
+	case "":
+	case "permission denied":
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	case "unknown subscriber":
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	keys := resp.Keys
+	if keys == nil {
+		keys = []*pbTransaction.WebhookSigningKey{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": keys,
+	})
+}
+
+// CreateWebhookSigningKeyHandler handles HTTP POST requests adding a signing key for a subscriber.
+// It returns 201 Created with the key and its secret, which is not shown again.
+func (g *GatewayService) CreateWebhookSigningKeyHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := g.transactionClient.CreateWebhookSigningKey(operatorContext(r), &pbTransaction.CreateWebhookSigningKeyRequest{
+		Subscriber: mux.Vars(r)["subscriber"],
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	writeWebhookSigningKeyResponse(w, resp, http.StatusCreated)
+}
+
+// RetireWebhookSigningKeyHandler handles HTTP POST requests retiring a signing key of a subscriber.
+func (g *GatewayService) RetireWebhookSigningKeyHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	resp, err := g.transactionClient.RetireWebhookSigningKey(operatorContext(r), &pbTransaction.RetireWebhookSigningKeyRequest{
+		Subscriber: vars["subscriber"],
+		KeyId:      vars["key_id"],
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	writeWebhookSigningKeyResponse(w, resp, http.StatusOK)
+}
+
+// ListMessageTemplatesHandler handles HTTP GET requests for the message templates in effect for a tenant, its own
+// or else the platform's. Only admin operators may list templates.
+func (g *GatewayService) ListMessageTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := g.transactionClient.ListMessageTemplates(operatorContext(r), &pbTransaction.ListMessageTemplatesRequest{
+		TenantId: mux.Vars(r)["tenant_id"],
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	if writeMessageTemplateError(w, resp.Error) {
+		return
+	}
+
+	templates := resp.Templates
+	if templates == nil {
+		templates = []*pbTransaction.MessageTemplate{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"templates": templates,
+	})
+}
+
+// messageTemplateBody is the JSON body of the message template requests: the template, and for previews and test
+// sends an optional event payload and the recipient of the test message.
+type messageTemplateBody struct {
+	Subject   string          `json:"subject"`
+	Body      string          `json:"body"`
+	Payload   json.RawMessage `json:"payload"`
+	Recipient string          `json:"recipient"`
+}
+
+// decodeMessageTemplateBody decodes the JSON body of a message template request; an empty body is allowed for
+// previews and test sends of the template in effect. It writes the HTTP error and returns false if the body is
+// not valid JSON.
+func decodeMessageTemplateBody(w http.ResponseWriter, r *http.Request) (messageTemplateBody, bool) {
+	var body messageTemplateBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return body, false
+	}
+	return body, true
+}
+
+// PutMessageTemplateHandler handles HTTP PUT requests setting a tenant's template of the messages of an event type
+// on a channel. The JSON body carries the body of the template and, for EMAIL, its subject.
+func (g *GatewayService) PutMessageTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	body, ok := decodeMessageTemplateBody(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := g.transactionClient.PutMessageTemplate(operatorContext(r), &pbTransaction.PutMessageTemplateRequest{
+		TenantId:  vars["tenant_id"],
+		EventType: vars["event_type"],
+		Channel:   vars["channel"],
+		Subject:   body.Subject,
+		Body:      body.Body,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	if writeMessageTemplateError(w, resp.Error) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Template)
+}
+
+// DeleteMessageTemplateHandler handles HTTP DELETE requests removing a tenant's template. It returns the platform
+// template that applies again, or 204 No Content if there is none.
+func (g *GatewayService) DeleteMessageTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	resp, err := g.transactionClient.DeleteMessageTemplate(operatorContext(r), &pbTransaction.DeleteMessageTemplateRequest{
+		TenantId:  vars["tenant_id"],
+		EventType: vars["event_type"],
+		Channel:   vars["channel"],
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	if writeMessageTemplateError(w, resp.Error) {
+		return
+	}
+	if resp.Template == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Template)
+}
+
+// PreviewMessageTemplateHandler handles HTTP POST requests rendering a template without sending it: the draft
+// subject and body of the JSON body, or the template in effect if it has no body, for its payload or a sample
+// event. It returns the message and the variables it was rendered with.
+func (g *GatewayService) PreviewMessageTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	body, ok := decodeMessageTemplateBody(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := g.transactionClient.PreviewMessageTemplate(operatorContext(r), &pbTransaction.PreviewMessageTemplateRequest{
+		TenantId:    vars["tenant_id"],
+		EventType:   vars["event_type"],
+		Channel:     vars["channel"],
+		Subject:     body.Subject,
+		Body:        body.Body,
+		PayloadJson: string(body.Payload),
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	if writeMessageTemplateError(w, resp.Error) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   resp.Message,
+		"variables": json.RawMessage(resp.VariablesJson),
+	})
+}
+
+// TestSendMessageTemplateHandler handles HTTP POST requests rendering a template like a preview and sending the
+// message to the recipient of the JSON body through notification consumers. It returns 202 Accepted with the
+// message and the ID of the event to follow its delivery.
+func (g *GatewayService) TestSendMessageTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	body, ok := decodeMessageTemplateBody(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := g.transactionClient.TestSendMessageTemplate(operatorContext(r), &pbTransaction.TestSendMessageTemplateRequest{
+		TenantId:    vars["tenant_id"],
+		EventType:   vars["event_type"],
+		Channel:     vars["channel"],
+		Subject:     body.Subject,
+		Body:        body.Body,
+		PayloadJson: string(body.Payload),
+		Recipient:   body.Recipient,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+	if writeMessageTemplateError(w, resp.Error) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  resp.Message,
+		"event_id": resp.EventId,
+	})
+}
+
+// writeMessageTemplateError writes the HTTP error for the error of a message template request, if any, and reports
+// whether it did.
+func writeMessageTemplateError(w http.ResponseWriter, msg string) bool {
+	switch msg {
+	case "":
+		return false
+	case "permission denied":
+		http.Error(w, msg, http.StatusForbidden)
+	case "message template not found", "no message template for event type and channel":
+		http.Error(w, msg, http.StatusNotFound)
+	case "event outbox disabled":
+		http.Error(w, msg, http.StatusServiceUnavailable)
+	case "database error":
+		http.Error(w, msg, http.StatusInternalServerError)
+	default:
+		http.Error(w, msg, http.StatusBadRequest)
+	}
+	return true
+}
+
+// ProcessPaymentHandler handles HTTP POST requests to process payment transactions.
+// It accepts JSON input for payment details and returns the processed transaction or error.
+// A payment in another currency than the account's is converted to it; the transaction carries the rate applied.
+func (g *GatewayService) ProcessPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AccountID   string       `json:"account_id"`
+		Amount      common.Cents `json:"amount"`
+		Description string       `json:"description"`
+		Currency    string       `json:"currency"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
 	}
-}
 
-// responseWriter wraps http.ResponseWriter to capture status code
-type responseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
+	grpcReq := &pbTransaction.ProcessPaymentRequest{
+		AccountId:   req.AccountID,
+		AmountCents: int64(req.Amount),
+		Description: req.Description,
+		Currency:    req.Currency,
+	}
 
-func (rw *responseWriter) WriteHeader(code int) {
-	rw.statusCode = code
-	rw.ResponseWriter.WriteHeader(code)
-}
+	resp, err := g.transactionClient.ProcessPayment(r.Context(), grpcReq)
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
 
-// NewGatewayService creates a new gateway service instance.
-// It takes gRPC client connections for account and transaction services and returns a configured GatewayService.
-func NewGatewayService(accountConn, transactionConn *grpc.ClientConn, logger *common.Logger) *GatewayService {
-	return &GatewayService{
-		accountClient:     pbAccount.NewAccountServiceClient(accountConn),
-		transactionClient: pbTransaction.NewTransactionServiceClient(transactionConn),
-		logger:            logger,
+	if resp.Error == accountRateLimited {
+		writeAccountRateLimited(w)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
 	}
-}
 
-// CreateAccountHandler handles HTTP POST requests to create new accounts.
-// It accepts JSON input, converts it to gRPC format, and returns the created account or error.
-func (g *GatewayService) CreateAccountHandler(w http.ResponseWriter, r *http.Request) {
-	g.logger.Info("Creating new account")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp.Transaction)
+}
 
+// TransferHandler handles HTTP POST requests moving money between two accounts.
+// It returns 201 Created with the transfer ID and its debit and credit transactions, 404 Not Found if either
+// account does not exist, 429 Too Many Requests if the source account exceeds its transaction rate, or
+// 400 Bad Request if the transfer is refused.
+func (g *GatewayService) TransferHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		DocumentNumber string  `json:"document_number"`
-		AccountType    string  `json:"account_type"`
-		InitialBalance float64 `json:"initial_balance"`
+		SourceAccountID      string       `json:"source_account_id"`
+		DestinationAccountID string       `json:"destination_account_id"`
+		Amount               common.Cents `json:"amount"`
+		Description          string       `json:"description"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		g.logger.Error("Failed to decode JSON request: %v", err)
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	g.logger.Debug("Account creation request: DocumentNumber=%s, AccountType=%s, InitialBalance=%f",
-		req.DocumentNumber, req.AccountType, req.InitialBalance)
+	resp, err := g.transactionClient.Transfer(r.Context(), &pbTransaction.TransferRequest{
+		SourceAccountId:      req.SourceAccountID,
+		DestinationAccountId: req.DestinationAccountID,
+		AmountCents:          int64(req.Amount),
+		Description:          req.Description,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
 
-	grpcReq := &pbAccount.CreateAccountRequest{
-		DocumentNumber: req.DocumentNumber,
-		AccountType:    req.AccountType,
-		InitialBalance: req.InitialBalance,
+	if resp.Error == accountRateLimited {
+		writeAccountRateLimited(w)
+		return
+	}
+	if resp.Error != "" {
+		statusCode := http.StatusBadRequest
+		if resp.Error == "account not found" {
+			statusCode = http.StatusNotFound
+		}
+		http.Error(w, resp.Error, statusCode)
+		return
 	}
 
-	start := time.Now()
-	resp, err := g.accountClient.CreateAccount(context.Background(), grpcReq)
-	duration := time.Since(start)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"transfer_id": resp.TransferId,
+		"debit":       resp.Debit,
+		"credit":      resp.Credit,
+	})
+}
+
+// batchJSON renders a transaction batch with all of its counts, including those that are zero.
+func batchJSON(batch *pbTransaction.TransactionBatch) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             batch.Id,
+		"source":         batch.Source,
+		"status":         batch.Status,
+		"expected_count": batch.ExpectedCount,
+		"succeeded":      batch.Succeeded,
+		"failed":         batch.Failed,
+		"created_at":     batch.CreatedAt,
+	}
+}
 
-	g.logger.LogGRPC("CreateAccount", duration, err)
+// CreateBatchHandler handles HTTP POST requests that open a transaction batch for a bulk import or settlement run.
+// The body names the source of the items and how many are expected; transactions join the batch with batch_id
+// and batch_item.
+func (g *GatewayService) CreateBatchHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Source        string `json:"source"`
+		ExpectedCount int32  `json:"expected_count"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
 
+	resp, err := g.transactionClient.CreateBatch(operatorContext(r), &pbTransaction.CreateBatchRequest{
+		Source:        req.Source,
+		ExpectedCount: req.ExpectedCount,
+	})
 	if err != nil {
-		g.logger.Error("Account service error: %v", err)
-		http.Error(w, fmt.Sprintf("Account service error: %v", err), http.StatusInternalServerError)
+		writeServiceError(w, r, "Transaction", err)
 		return
 	}
 
 	if resp.Error != "" {
-		g.logger.Error("Account creation failed: %s", resp.Error)
 		http.Error(w, resp.Error, http.StatusBadRequest)
 		return
 	}
 
-	g.logger.Info("Account created successfully: ID=%s", resp.Account.Id)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp.Account)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(batchJSON(resp.Batch))
 }
 
-// GetAccountHandler handles HTTP GET requests to retrieve account details by ID.
-// It extracts the account ID from the URL path and returns the account information or error.
-func (g *GatewayService) GetAccountHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	accountID := vars["id"]
+// GetBatchHandler handles HTTP GET requests for the progress of a transaction batch. It lists the items that
+// failed in item order, failures_limit at a time; failures_after_item continues from a previous page.
+func (g *GatewayService) GetBatchHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &pbTransaction.GetBatchRequest{Id: mux.Vars(r)["id"]}
+	if value := query.Get("failures_limit"); value != "" {
+		if l, err := strconv.Atoi(value); err == nil {
+			req.FailuresLimit = int32(l)
+		}
+	}
+	if value := query.Get("failures_after_item"); value != "" {
+		after, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "failures_after_item must be an item number", http.StatusBadRequest)
+			return
+		}
+		req.FailuresAfterItem = int32(after)
+	}
 
-	grpcReq := &pbAccount.GetAccountRequest{Id: accountID}
-	resp, err := g.accountClient.GetAccount(context.Background(), grpcReq)
+	resp, err := g.transactionClient.GetBatch(r.Context(), req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Account service error: %v", err), http.StatusInternalServerError)
+		writeServiceError(w, r, "Transaction", err)
 		return
 	}
 
-	if resp.Error != "" {
+	switch resp.Error {
+	case "":
+	case "batch not found":
 		http.Error(w, resp.Error, http.StatusNotFound)
 		return
+	default:
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	failures := resp.Failures
+	if failures == nil {
+		failures = []*pbTransaction.BatchFailure{}
+	}
+	body := batchJSON(resp.Batch)
+	body["failures"] = failures
+	if resp.NextFailuresAfterItem != 0 {
+		body["next_failures_after_item"] = resp.NextFailuresAfterItem
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp.Account)
+	json.NewEncoder(w).Encode(body)
 }
 
-// GetBalanceHandler handles HTTP GET requests to retrieve account balance by ID.
-// It extracts the account ID from the URL path and returns the current balance or error.
-func (g *GatewayService) GetBalanceHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	accountID := vars["id"]
-
-	grpcReq := &pbAccount.GetBalanceRequest{AccountId: accountID}
-	resp, err := g.accountClient.GetBalance(context.Background(), grpcReq)
+// ListOperationRulesHandler handles HTTP GET requests for the rules applied to each operation type.
+func (g *GatewayService) ListOperationRulesHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := g.transactionClient.ListOperationRules(r.Context(), &pbTransaction.ListOperationRulesRequest{})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Account service error: %v", err), http.StatusInternalServerError)
+		writeServiceError(w, r, "Transaction", err)
 		return
 	}
 
 	if resp.Error != "" {
-		http.Error(w, resp.Error, http.StatusNotFound)
+		http.Error(w, resp.Error, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]float64{"balance": resp.Balance})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rules": resp.Rules,
+	})
 }
 
-// CreateTransactionHandler handles HTTP POST requests to create new transactions.
-// It accepts JSON input, converts it to gRPC format, and returns the created transaction or error.
-func (g *GatewayService) CreateTransactionHandler(w http.ResponseWriter, r *http.Request) {
+// UpdateOperationRuleHandler handles HTTP PUT requests to replace the rule of an operation type.
+// Like UpdateTenantSettingsHandler it forwards the X-Caller-Role header; only admins may change rules.
+func (g *GatewayService) UpdateOperationRuleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
 	var req struct {
-		AccountID     string  `json:"account_id"`
-		OperationType string  `json:"operation_type"`
-		Amount        float64 `json:"amount"`
-		Description   string  `json:"description"`
+		Direction              string   `json:"direction"`
+		RequiresPositiveAmount bool     `json:"requires_positive_amount"`
+		AllowedAccountTypes    []string `json:"allowed_account_types"`
+		FeePolicy              string   `json:"fee_policy"`
 	}
-
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	grpcReq := &pbTransaction.CreateTransactionRequest{
-		AccountId:     req.AccountID,
-		OperationType: req.OperationType,
-		Amount:        req.Amount,
-		Description:   req.Description,
+	ctx := r.Context()
+	if role := r.Header.Get("X-Caller-Role"); role != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, common.CallerRoleMetadataKey, role)
 	}
 
-	resp, err := g.transactionClient.CreateTransaction(context.Background(), grpcReq)
+	resp, err := g.transactionClient.UpdateOperationRule(ctx, &pbTransaction.UpdateOperationRuleRequest{
+		OperationType: vars["operation_type"],
+		Rule: &pbTransaction.OperationRule{
+			Direction:              req.Direction,
+			RequiresPositiveAmount: req.RequiresPositiveAmount,
+			AllowedAccountTypes:    req.AllowedAccountTypes,
+			FeePolicy:              req.FeePolicy,
+		},
+	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Transaction service error: %v", err), http.StatusInternalServerError)
+		writeServiceError(w, r, "Transaction", err)
 		return
 	}
 
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
 	if resp.Error != "" {
 		http.Error(w, resp.Error, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp.Transaction)
+	json.NewEncoder(w).Encode(resp.Rule)
 }
 
-// GetTransactionHandler handles HTTP GET requests to retrieve transaction details by ID.
-// It extracts the transaction ID from the URL path and returns the transaction information or error.
-func (g *GatewayService) GetTransactionHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	transactionID := vars["id"]
+// maxChargebackFileSize caps the size of a chargeback file uploaded to ImportChargebacksHandler.
+const maxChargebackFileSize = 2 << 20
+
+// ImportChargebacksHandler handles HTTP POST requests that upload a network chargeback file.
+// The raw file is the request body and the format query parameter selects csv (the default) or fixed.
+// Only support and admin operators may import files, identified by the X-Caller-Role header.
+func (g *GatewayService) ImportChargebacksHandler(w http.ResponseWriter, r *http.Request) {
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxChargebackFileSize))
+	if err != nil {
+		http.Error(w, "File too large", http.StatusRequestEntityTooLarge)
+		return
+	}
 
-	grpcReq := &pbTransaction.GetTransactionRequest{Id: transactionID}
-	resp, err := g.transactionClient.GetTransaction(context.Background(), grpcReq)
+	resp, err := g.transactionClient.ImportChargebacks(operatorContext(r), &pbTransaction.ImportChargebacksRequest{
+		Content: content,
+		Format:  r.URL.Query().Get("format"),
+	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Transaction service error: %v", err), http.StatusInternalServerError)
+		writeServiceError(w, r, "Transaction", err)
 		return
 	}
 
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
 	if resp.Error != "" {
-		http.Error(w, resp.Error, http.StatusNotFound)
+		http.Error(w, resp.Error, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp.Transaction)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"opened":           resp.Opened,
+		"already_disputed": resp.AlreadyDisputed,
+		"unmatched":        resp.Unmatched,
+	})
 }
 
-// GetTransactionHistoryHandler handles HTTP GET requests to retrieve transaction history for an account.
-// It supports pagination with limit and offset query parameters and returns the transaction list with total count.
-func (g *GatewayService) GetTransactionHistoryHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	accountID := vars["account_id"]
-
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+// ReconcileSettlementHandler handles HTTP POST requests that upload a processor settlement file to reconcile.
+// The raw CSV file is the request body; auto_adjust=true requests balance adjustments for amount differences.
+// Support and admin operators may reconcile files, identified by the X-Caller-Role header; adjustments also
+// need the X-Operator-ID header. Files are limited like chargeback files.
+func (g *GatewayService) ReconcileSettlementHandler(w http.ResponseWriter, r *http.Request) {
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxChargebackFileSize))
+	if err != nil {
+		http.Error(w, "File too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	autoAdjust := false
+	if value := r.URL.Query().Get("auto_adjust"); value != "" {
+		if autoAdjust, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "auto_adjust must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
 
-	limit := int32(50)
-	offset := int32(0)
+	resp, err := g.transactionClient.ReconcileSettlement(operatorContext(r), &pbTransaction.ReconcileSettlementRequest{
+		Content:    content,
+		AutoAdjust: autoAdjust,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
 
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil {
-			limit = int32(l)
-		}
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
 	}
 
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil {
-			offset = int32(o)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"matched":          resp.Matched,
+		"mismatched":       resp.Mismatched,
+		"missing":          resp.Missing,
+		"extra":            resp.Extra,
+		"extra_truncated":  resp.ExtraTruncated,
+		"invalid":          resp.Invalid,
+		"adjustments":      resp.Adjustments,
+		"already_adjusted": resp.AlreadyAdjusted,
+	})
+}
+
+// ListStuckTransactionsHandler handles HTTP GET requests for transactions left PENDING, oldest first.
+// The older_than query parameter is a duration such as 30m or 2h and defaults to 15 minutes; limit caps the result.
+// Only admins may list them, identified by the X-Caller-Role header.
+func (g *GatewayService) ListStuckTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	req := &pbTransaction.ListStuckTransactionsRequest{}
+	if value := r.URL.Query().Get("older_than"); value != "" {
+		olderThan, err := time.ParseDuration(value)
+		if err != nil || olderThan < time.Second {
+			http.Error(w, "older_than must be a duration of at least 1s", http.StatusBadRequest)
+			return
 		}
+		req.OlderThanSeconds = int64(olderThan / time.Second)
 	}
-
-	grpcReq := &pbTransaction.GetTransactionHistoryRequest{
-		AccountId: accountID,
-		Limit:     limit,
-		Offset:    offset,
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if l, err := strconv.Atoi(value); err == nil {
+			req.Limit = int32(l)
+		}
 	}
 
-	resp, err := g.transactionClient.GetTransactionHistory(context.Background(), grpcReq)
+	resp, err := g.transactionClient.ListStuckTransactions(operatorContext(r), req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Transaction service error: %v", err), http.StatusInternalServerError)
+		writeServiceError(w, r, "Transaction", err)
 		return
 	}
 
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
 	if resp.Error != "" {
 		http.Error(w, resp.Error, http.StatusBadRequest)
 		return
@@ -267,43 +3562,138 @@ func (g *GatewayService) GetTransactionHistoryHandler(w http.ResponseWriter, r *
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"transactions": resp.Transactions,
-		"total":        resp.Total,
 	})
 }
 
-// ProcessPaymentHandler handles HTTP POST requests to process payment transactions.
-// It accepts JSON input for payment details and returns the processed transaction or error.
-func (g *GatewayService) ProcessPaymentHandler(w http.ResponseWriter, r *http.Request) {
+// ResolveStuckTransactionsHandler handles HTTP POST requests that complete, fail or reverse PENDING transactions
+// in bulk. The body names the transaction IDs, the action and the reason recorded in the audit trail.
+// Only admins may resolve transactions, identified by the X-Caller-Role and X-Operator-ID headers.
+// Transactions that could not be resolved are reported in their result; the request itself still succeeds.
+func (g *GatewayService) ResolveStuckTransactionsHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		AccountID   string  `json:"account_id"`
-		Amount      float64 `json:"amount"`
-		Description string  `json:"description"`
+		IDs    []string `json:"ids"`
+		Action string   `json:"action"`
+		Reason string   `json:"reason"`
 	}
-
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	grpcReq := &pbTransaction.ProcessPaymentRequest{
-		AccountId:   req.AccountID,
-		Amount:      req.Amount,
-		Description: req.Description,
+	resp, err := g.transactionClient.ResolveStuckTransactions(operatorContext(r), &pbTransaction.ResolveStuckTransactionsRequest{
+		Ids:    req.IDs,
+		Action: req.Action,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		writeServiceError(w, r, "Transaction", err)
+		return
+	}
+
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
+	if resp.Error != "" {
+		http.Error(w, resp.Error, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results": resp.Results,
+	})
+}
+
+// ListFlaggedTransactionsHandler handles HTTP GET requests for the transactions held for review by fraud scoring,
+// oldest first. The account_id query parameter restricts the queue to one account; limit caps the result.
+// Support staff and admins may list them, identified by the X-Caller-Role header.
+func (g *GatewayService) ListFlaggedTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	req := &pbTransaction.ListFlaggedTransactionsRequest{AccountId: r.URL.Query().Get("account_id")}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if l, err := strconv.Atoi(value); err == nil {
+			req.Limit = int32(l)
+		}
 	}
 
-	resp, err := g.transactionClient.ProcessPayment(context.Background(), grpcReq)
+	resp, err := g.transactionClient.ListFlaggedTransactions(operatorContext(r), req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Transaction service error: %v", err), http.StatusInternalServerError)
+		writeServiceError(w, r, "Transaction", err)
 		return
 	}
 
+	if resp.Error == "permission denied" {
+		http.Error(w, resp.Error, http.StatusForbidden)
+		return
+	}
 	if resp.Error != "" {
 		http.Error(w, resp.Error, http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp.Transaction)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"transactions": resp.Transactions,
+	})
+}
+
+// ReviewFlaggedTransactionHandler returns a handler for HTTP POST requests that approve or decline a transaction
+// held for review. An optional JSON body may carry a review note. Support staff and admins may review
+// transactions, identified by the X-Caller-Role and X-Operator-ID headers.
+func (g *GatewayService) ReviewFlaggedTransactionHandler(approve bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		var req struct {
+			Note string `json:"note"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+				http.Error(w, "Invalid JSON", http.StatusBadRequest)
+				return
+			}
+		}
+
+		var flagged *pbTransaction.FlaggedTransaction
+		var errMsg string
+		var err error
+		if approve {
+			var resp *pbTransaction.ApproveFlaggedResponse
+			resp, err = g.transactionClient.ApproveFlagged(operatorContext(r), &pbTransaction.ApproveFlaggedRequest{Id: vars["id"], Note: req.Note})
+			if err == nil {
+				flagged, errMsg = resp.Transaction, resp.Error
+			}
+		} else {
+			var resp *pbTransaction.DeclineFlaggedResponse
+			resp, err = g.transactionClient.DeclineFlagged(operatorContext(r), &pbTransaction.DeclineFlaggedRequest{Id: vars["id"], Note: req.Note})
+			if err == nil {
+				flagged, errMsg = resp.Transaction, resp.Error
+			}
+		}
+		if err != nil {
+			writeServiceError(w, r, "Transaction", err)
+			return
+		}
+
+		switch errMsg {
+		case "":
+		case "permission denied":
+			http.Error(w, errMsg, http.StatusForbidden)
+			return
+		case "transaction not found":
+			http.Error(w, errMsg, http.StatusNotFound)
+			return
+		case "transaction is not under review":
+			http.Error(w, errMsg, http.StatusConflict)
+			return
+		default:
+			http.Error(w, errMsg, http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(flagged)
+	}
 }
 
 // HealthHandler handles HTTP GET requests for health checks.
@@ -319,66 +3709,265 @@ func (g *GatewayService) HealthHandler(w http.ResponseWriter, r *http.Request) {
 // main starts the Gateway HTTP service.
 // It establishes connections to account and transaction gRPC services, sets up HTTP routes,
 // configures CORS, and starts the HTTP server on port 8080 (or PORT environment variable).
+// backendAddrs returns the addresses of the account and transaction services, from ACCOUNT_SERVICE_ADDR and
+// TRANSACTION_SERVICE_ADDR.
+func backendAddrs() (accountAddr, transactionAddr string) {
+	accountAddr = os.Getenv("ACCOUNT_SERVICE_ADDR")
+	if accountAddr == "" {
+		accountAddr = "localhost:8081"
+	}
+	transactionAddr = os.Getenv("TRANSACTION_SERVICE_ADDR")
+	if transactionAddr == "" {
+		transactionAddr = "localhost:8082"
+	}
+	return accountAddr, transactionAddr
+}
+
 func main() {
-	logLevel := common.ParseLogLevel(os.Getenv("LOG_LEVEL"))
-	logger, err := common.NewLogger("gateway", logLevel)
+	selfTest := flag.Bool("selftest", false, "check the configuration and the backend services, then exit")
+	flag.Parse()
+
+	logger, err := common.NewLoggerFromEnv("gateway")
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
 	}
 	defer logger.Close()
 
+	// An init container can run the self-test to hold the rollout until the gateway can start
+	if *selfTest {
+		code := runSelfTest(logger)
+		logger.Close()
+		os.Exit(code)
+	}
+
 	logger.Info("Starting Gateway service")
 
-	accountAddr := os.Getenv("ACCOUNT_SERVICE_ADDR")
-	if accountAddr == "" {
-		accountAddr = "localhost:8081"
+	runtimeConfig, err := common.NewRuntimeConfigManager(os.Getenv("RUNTIME_CONFIG_FILE"), logger)
+	if err != nil {
+		logger.Fatal("Failed to load runtime config: %v", err)
 	}
+	go runtimeConfig.Watch(context.Background(), common.DefaultRuntimeConfigPollInterval)
 
-	transactionAddr := os.Getenv("TRANSACTION_SERVICE_ADDR")
-	if transactionAddr == "" {
-		transactionAddr = "localhost:8082"
+	accountAddr, transactionAddr := backendAddrs()
+	logger.Info("Connecting to services: Account=%s, Transaction=%s", accountAddr, transactionAddr)
+
+	compression := common.NewCompressionConfig()
+	logger.Info("gRPC compression: Enabled=%t, Threshold=%d bytes", compression.Enabled, compression.Threshold)
+
+	// Idempotent reads of the account overview can be hedged to cut tail latency
+	hedging := common.NewHedgingConfigFromEnv(
+		pbAccount.AccountService_GetAccount_FullMethodName,
+		pbAccount.AccountService_GetBalance_FullMethodName,
+		pbAccount.AccountService_GetBalances_FullMethodName,
+	)
+	if hedging.Enabled {
+		logger.Info("gRPC hedging: Percentile=%g, Delay=%s-%s, Budget=%g%%", hedging.Percentile, hedging.MinDelay, hedging.MaxDelay, hedging.Budget)
 	}
 
-	logger.Info("Connecting to services: Account=%s, Transaction=%s", accountAddr, transactionAddr)
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(
+			common.RequestIDUnaryClientInterceptor(),
+			common.HedgingUnaryClientInterceptor(hedging),
+			common.RateLimitHeadersUnaryClientInterceptor(),
+			common.CompressionUnaryClientInterceptor(compression),
+		),
+		common.LoadBalancingDialOption(),
+	}
 
-	accountConn, err := grpc.Dial(accountAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// Calls to each backend are spread over several channels that are replaced as they age or lose their
+	// connections, so rolling restarts of the backends do not cause bursts of UNAVAILABLE errors
+	poolConfig := common.NewClientConnPoolConfigFromEnv()
+	logger.Info("gRPC channels: PerBackend=%d, MaxAge=%s, DrainGrace=%s", poolConfig.Channels, poolConfig.MaxAge, poolConfig.DrainGrace)
+
+	// Frontend teams can run the gateway without the backends: MOCK_DOWNSTREAMS replaces them with in-memory fakes
+	mocks, err := mockdownstreams.FromEnv()
 	if err != nil {
-		logger.Fatal("Failed to connect to account service: %v", err)
+		logger.Fatal("Invalid MOCK_DOWNSTREAMS: %v", err)
+	}
+	var fakes *mockdownstreams.Server
+	fakeConn := func(name string) grpc.ClientConnInterface {
+		logger.Warn("Serving %s with in-memory fakes; data is lost when the gateway stops", name)
+		if fakes == nil {
+			if fakes, err = mockdownstreams.Start(); err != nil {
+				logger.Fatal("Failed to start fake backends: %v", err)
+			}
+		}
+		return fakes.Conn()
 	}
-	defer accountConn.Close()
+	defer func() {
+		if fakes != nil {
+			fakes.Stop()
+		}
+	}()
 
-	transactionConn, err := grpc.Dial(transactionAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		logger.Fatal("Failed to connect to transaction service: %v", err)
+	// Serve only once both backends are reachable, so the first requests after a cold start do not fail
+	startup := common.NewStartupCoordinatorFromEnv(logger)
+	connect := func(name, addr string, mock bool) grpc.ClientConnInterface {
+		if mock {
+			return fakeConn(name)
+		}
+		pool, err := common.NewClientConnPool(common.ServiceTarget(addr), poolConfig, logger, dialOptions...)
+		if err != nil {
+			logger.Fatal("Failed to connect to %s: %v", name, err)
+		}
+		if err := startup.WaitForChannels(name, pool); err != nil {
+			if !mocks.Missing {
+				logger.Fatal("Failed to connect to %s: %v", name, err)
+			}
+			logger.Warn("Failed to connect to %s: %v", name, err)
+			pool.Close()
+			return fakeConn(name)
+		}
+		return pool
+	}
+	accountConn := connect("account service", accountAddr, mocks.Account)
+	if pool, ok := accountConn.(*common.ClientConnPool); ok {
+		defer pool.Close()
+	}
+	transactionConn := connect("transaction service", transactionAddr, mocks.Transaction)
+	if pool, ok := transactionConn.(*common.ClientConnPool); ok {
+		defer pool.Close()
 	}
-	defer transactionConn.Close()
 
 	logger.Info("Successfully connected to all services")
 
 	gateway := NewGatewayService(accountConn, transactionConn, logger)
 
+	// Readiness changes of the backends are logged and counted, so alerts can tell which one failed
+	readiness := common.NewReadinessMonitor("gateway", logger,
+		common.GRPCReadinessCheck("account-service", accountConn),
+		common.GRPCReadinessCheck("transaction-service", transactionConn),
+	)
+	go readiness.Run(context.Background(), common.ReadinessIntervalFromEnv())
+	if metricsPort := os.Getenv("READINESS_METRICS_PORT"); metricsPort != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", readiness)
+		go func() {
+			if err := http.ListenAndServe(":"+metricsPort, mux); err != nil {
+				logger.Error("Readiness metrics server stopped: %v", err)
+			}
+		}()
+		logger.Info("Readiness metrics listening on port %s", metricsPort)
+	}
+
 	r := mux.NewRouter()
 
-	// Add logging middleware
+	// Add request ID and logging middleware
+	r.Use(RequestIDMiddleware())
 	r.Use(LoggingMiddleware(logger))
+	r.Use(RateLimitMiddleware())
+	r.Use(TenantMiddleware())
+	r.Use(TrafficClassMiddleware())
+	r.Use(AuthorizationMiddleware(runtimeConfig, logger))
+
+	readOnly := os.Getenv("READ_ONLY_MODE") == "true"
+	retryAfter := 60 * time.Second
+	if value := os.Getenv("READ_ONLY_RETRY_AFTER"); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			retryAfter = d
+		}
+	}
+	if readOnly {
+		logger.Warn("Gateway starting in read-only mode")
+	}
+	r.Use(ReadOnlyMiddleware(runtimeConfig, readOnly, retryAfter, logger))
+	r.Use(DebugBodyLoggingMiddleware(runtimeConfig, logger))
+	r.Use(LocalizationMiddleware())
+	r.Use(ResponseMaskingMiddleware(runtimeConfig))
+	r.Use(AmountMiddleware())
+	r.Use(StaleReadMiddleware(common.NewStaleResponseCacheFromEnv(), logger))
 
 	r.HandleFunc("/health", gateway.HealthHandler).Methods("GET")
 
 	r.HandleFunc("/accounts", gateway.CreateAccountHandler).Methods("POST")
+	r.HandleFunc("/accounts", gateway.ListAccountsHandler).Methods("GET")
+	r.HandleFunc("/accounts/search", gateway.SearchAccountsHandler).Methods("GET")
+	r.HandleFunc("/accounts/by-document/{document}", gateway.GetAccountByDocumentHandler).Methods("GET")
 	r.HandleFunc("/accounts/{id}", gateway.GetAccountHandler).Methods("GET")
+	r.HandleFunc("/accounts/{id}", gateway.UpdateAccountHandler).Methods("PUT")
+	r.HandleFunc("/accounts/{id}", gateway.DeleteAccountHandler).Methods("DELETE")
 	r.HandleFunc("/accounts/{id}/balance", gateway.GetBalanceHandler).Methods("GET")
+	r.HandleFunc("/accounts/{id}/balances", gateway.GetBalancesHandler).Methods("GET")
+	r.HandleFunc("/accounts/{id}/overview", gateway.AccountOverviewHandler).Methods("GET")
+	r.HandleFunc("/accounts/{id}/payment-preview", gateway.PaymentPreviewHandler).Methods("GET")
+	r.HandleFunc("/accounts/{id}/holder", gateway.UpdateAccountHolderHandler).Methods("PUT")
+	r.HandleFunc("/accounts/{id}/onboarding", gateway.AdvanceOnboardingHandler).Methods("POST")
+	r.HandleFunc("/accounts/{id}/overdraft", gateway.SetOverdraftLimitHandler).Methods("PUT")
+	r.HandleFunc("/accounts/{id}/tags", gateway.UpdateAccountTagsHandler).Methods("PATCH")
+
+	r.HandleFunc("/tenants/{tenant_id}/settings", gateway.GetTenantSettingsHandler).Methods("GET")
+	r.HandleFunc("/tenants/{tenant_id}/settings", gateway.UpdateTenantSettingsHandler).Methods("PUT")
+	r.HandleFunc("/admin/tenants/{tenant_id}/fx-revaluations/{period}", gateway.GetFxRevaluationReportHandler).Methods("GET")
+
+	r.HandleFunc("/accounts/{id}/adjustments", gateway.RequestBalanceAdjustmentHandler).Methods("POST")
+	r.HandleFunc("/accounts/{id}/adjustments", gateway.ListBalanceAdjustmentsHandler).Methods("GET")
+	r.HandleFunc("/adjustments/{id}/approve", gateway.ReviewBalanceAdjustmentHandler(true)).Methods("POST")
+	r.HandleFunc("/adjustments/{id}/reject", gateway.ReviewBalanceAdjustmentHandler(false)).Methods("POST")
+	r.HandleFunc("/accounts/{id}/document-changes", gateway.RequestDocumentChangeHandler).Methods("POST")
+	r.HandleFunc("/accounts/{id}/document-changes", gateway.ListDocumentChangesHandler).Methods("GET")
+	r.HandleFunc("/accounts/{id}/audit", gateway.GetAccountAuditHandler).Methods("GET")
+	r.HandleFunc("/accounts/{id}/snapshots", gateway.ListAccountSnapshotsHandler).Methods("GET")
+	r.HandleFunc("/account-snapshots/{id}/restore", gateway.RestoreAccountSnapshotHandler).Methods("POST")
+	r.HandleFunc("/document-changes/{id}/verify", gateway.VerifyDocumentChangeHandler(true)).Methods("POST")
+	r.HandleFunc("/document-changes/{id}/reject", gateway.VerifyDocumentChangeHandler(false)).Methods("POST")
+	r.HandleFunc("/document-changes/{id}/apply", gateway.ApplyDocumentChangeHandler).Methods("POST")
 
 	r.HandleFunc("/transactions", gateway.CreateTransactionHandler).Methods("POST")
+	r.HandleFunc("/transactions", gateway.GetTransactionHandler).Methods("GET").Queries("external_id", "{external_id}")
+	r.HandleFunc("/transactions/simulate", gateway.SimulateTransactionHandler).Methods("POST")
 	r.HandleFunc("/transactions/{id}", gateway.GetTransactionHandler).Methods("GET")
+	r.HandleFunc("/transactions/{id}", gateway.UpdateTransactionHandler).Methods("PATCH")
+	r.HandleFunc("/transactions/{id}/timeline", gateway.GetTransactionTimelineHandler).Methods("GET")
+	r.HandleFunc("/transactions/{id}/reverse", gateway.ReverseTransactionHandler).Methods("POST")
 	r.HandleFunc("/accounts/{account_id}/transactions", gateway.GetTransactionHistoryHandler).Methods("GET")
+	r.HandleFunc("/accounts/{account_id}/transactions/aggregate", gateway.AggregateTransactionsHandler).Methods("GET")
+	r.HandleFunc("/accounts/{account_id}/transactions/export", gateway.ExportTransactionHistoryHandler).Methods("GET")
+	r.HandleFunc("/accounts/{account_id}/transactions/stream", gateway.StreamTransactionsHandler).Methods("GET")
+	r.HandleFunc("/accounts/{account_id}/budgets", gateway.GetBudgetStatusHandler).Methods("GET")
+	r.HandleFunc("/accounts/{account_id}/statements/{period}", gateway.GetStatementHandler).Methods("GET")
+	r.HandleFunc("/business-days/current", gateway.GetBusinessDayStatusHandler).Methods("GET")
+	r.HandleFunc("/business-days/{date}", gateway.GetBusinessDayStatusHandler).Methods("GET")
+	r.HandleFunc("/accounts/{account_id}/budgets/{category}", gateway.SetBudgetHandler).Methods("PUT")
+	r.HandleFunc("/accounts/{account_id}/budgets/{category}", gateway.DeleteBudgetHandler).Methods("DELETE")
+	r.HandleFunc("/accounts/{account_id}/recurring", gateway.ListRecurringRulesHandler).Methods("GET")
+	r.HandleFunc("/accounts/{account_id}/recurring", gateway.CreateRecurringRuleHandler).Methods("POST")
+	r.HandleFunc("/accounts/{account_id}/recurring/{rule_id}", gateway.CancelRecurringRuleHandler).Methods("DELETE")
+	r.HandleFunc("/accounts/{account_id}/events/deliveries", gateway.ListEventDeliveriesHandler).Methods("GET")
 	r.HandleFunc("/payments", gateway.ProcessPaymentHandler).Methods("POST")
+	r.HandleFunc("/transfers", gateway.TransferHandler).Methods("POST")
+	r.HandleFunc("/batches", gateway.CreateBatchHandler).Methods("POST")
+	r.HandleFunc("/batches/{id}", gateway.GetBatchHandler).Methods("GET")
+
+	r.HandleFunc("/operation-rules", gateway.ListOperationRulesHandler).Methods("GET")
+	r.HandleFunc("/operation-rules/{operation_type}", gateway.UpdateOperationRuleHandler).Methods("PUT")
+
+	r.HandleFunc("/chargebacks/import", gateway.ImportChargebacksHandler).Methods("POST")
+	r.HandleFunc("/reconciliations", gateway.ReconcileSettlementHandler).Methods("POST")
+
+	r.HandleFunc("/admin/access-decisions", gateway.ListAccessDecisionsHandler).Methods("GET")
+	r.HandleFunc("/admin/slo", gateway.SLOStatusHandler).Methods("GET")
+	r.HandleFunc("/admin/webhooks/{subscriber}/signing-keys", gateway.ListWebhookSigningKeysHandler).Methods("GET")
+	r.HandleFunc("/admin/webhooks/{subscriber}/signing-keys", gateway.CreateWebhookSigningKeyHandler).Methods("POST")
+	r.HandleFunc("/admin/webhooks/{subscriber}/signing-keys/{key_id}/retire", gateway.RetireWebhookSigningKeyHandler).Methods("POST")
+	r.HandleFunc("/admin/tenants/{tenant_id}/message-templates", gateway.ListMessageTemplatesHandler).Methods("GET")
+	r.HandleFunc("/admin/tenants/{tenant_id}/message-templates/{event_type}/{channel}", gateway.PutMessageTemplateHandler).Methods("PUT")
+	r.HandleFunc("/admin/tenants/{tenant_id}/message-templates/{event_type}/{channel}", gateway.DeleteMessageTemplateHandler).Methods("DELETE")
+	r.HandleFunc("/admin/tenants/{tenant_id}/message-templates/{event_type}/{channel}/preview", gateway.PreviewMessageTemplateHandler).Methods("POST")
+	r.HandleFunc("/admin/tenants/{tenant_id}/message-templates/{event_type}/{channel}/test-send", gateway.TestSendMessageTemplateHandler).Methods("POST")
+	r.HandleFunc("/admin/transactions/stuck", gateway.ListStuckTransactionsHandler).Methods("GET")
+	r.HandleFunc("/admin/transactions/stuck/resolve", gateway.ResolveStuckTransactionsHandler).Methods("POST")
+	r.HandleFunc("/admin/transactions/flagged", gateway.ListFlaggedTransactionsHandler).Methods("GET")
+	r.HandleFunc("/admin/transactions/flagged/{id}/approve", gateway.ReviewFlaggedTransactionHandler(true)).Methods("POST")
+	r.HandleFunc("/admin/transactions/flagged/{id}/decline", gateway.ReviewFlaggedTransactionHandler(false)).Methods("POST")
 
 	corsHandler := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Caller-Role, X-Request-ID, X-API-Key, X-Tenant-ID, X-Operator-ID, X-Traffic-Class, If-Match, Cache-Control")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After, ETag")
 
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pbTransaction "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// fakeTransactionClient answers CreateTransaction with a canned response, and StreamTransactions with canned
+// transactions followed by streamErr, or io.EOF if it is nil. It records the requests it got; calling any other
+// method panics.
+type fakeTransactionClient struct {
+	pbTransaction.TransactionServiceClient
+	resp           *pbTransaction.CreateTransactionResponse
+	requests       []*pbTransaction.CreateTransactionRequest
+	streamed       []*pbTransaction.Transaction
+	streamErr      error
+	streamRequests []*pbTransaction.StreamTransactionsRequest
+}
+
+func (f *fakeTransactionClient) CreateTransaction(ctx context.Context, req *pbTransaction.CreateTransactionRequest, opts ...grpc.CallOption) (*pbTransaction.CreateTransactionResponse, error) {
+	f.requests = append(f.requests, req)
+	return f.resp, nil
+}
+
+func (f *fakeTransactionClient) StreamTransactions(ctx context.Context, req *pbTransaction.StreamTransactionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pbTransaction.Transaction], error) {
+	f.streamRequests = append(f.streamRequests, req)
+	err := f.streamErr
+	if err == nil {
+		err = io.EOF
+	}
+	return &fakeTransactionStream{transactions: f.streamed, err: err}, nil
+}
+
+// fakeTransactionStream receives its transactions, then fails with err.
+type fakeTransactionStream struct {
+	grpc.ClientStream
+	transactions []*pbTransaction.Transaction
+	err          error
+}
+
+func (s *fakeTransactionStream) Recv() (*pbTransaction.Transaction, error) {
+	if len(s.transactions) == 0 {
+		return nil, s.err
+	}
+	transaction := s.transactions[0]
+	s.transactions = s.transactions[1:]
+	return transaction, nil
+}
+
+func newTestGateway(t *testing.T, transactions *fakeTransactionClient) *GatewayService {
+	logger, err := common.NewLogger("gateway-test", common.INFO)
+	require.NoError(t, err)
+	return &GatewayService{transactionClient: transactions, logger: logger}
+}
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		readOnly       bool
+		runtimeConfig  string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{name: "read", readOnly: true, method: http.MethodGet, path: "/transactions/txn-1", expectedStatus: http.StatusOK},
+		{name: "simulation", readOnly: true, method: http.MethodPost, path: "/transactions/simulate", expectedStatus: http.StatusOK},
+		{name: "message template preview", readOnly: true, method: http.MethodPost, path: "/admin/tenants/acme/message-templates/transaction.created/email/preview", expectedStatus: http.StatusOK},
+		{name: "transaction", readOnly: true, method: http.MethodPost, path: "/transactions", expectedStatus: http.StatusServiceUnavailable},
+		{name: "account deletion", readOnly: true, method: http.MethodDelete, path: "/accounts/acc-1", expectedStatus: http.StatusServiceUnavailable},
+		{name: "runtime feature flag", runtimeConfig: `{"feature_flags": {"read_only": true}}`, method: http.MethodPost, path: "/transactions", expectedStatus: http.StatusServiceUnavailable},
+		{name: "writable", method: http.MethodPost, path: "/transactions", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := ""
+			if tt.runtimeConfig != "" {
+				configPath = filepath.Join(t.TempDir(), "runtime.json")
+				require.NoError(t, os.WriteFile(configPath, []byte(tt.runtimeConfig), 0o600))
+			}
+			runtimeConfig, err := common.NewRuntimeConfigManager(configPath, nil)
+			require.NoError(t, err)
+			logger, err := common.NewLogger("gateway-test", common.INFO)
+			require.NoError(t, err)
+
+			called := false
+			handler := func(w http.ResponseWriter, r *http.Request) { called = true }
+			r := mux.NewRouter()
+			r.Use(ReadOnlyMiddleware(runtimeConfig, tt.readOnly, 30*time.Second, logger))
+			r.HandleFunc("/transactions", handler).Methods("POST")
+			r.HandleFunc("/transactions/simulate", handler).Methods("POST")
+			r.HandleFunc("/transactions/{id}", handler).Methods("GET")
+			r.HandleFunc("/accounts/{id}", handler).Methods("DELETE")
+			r.HandleFunc("/admin/tenants/{tenant_id}/message-templates/{event_type}/{channel}/preview", handler).Methods("POST")
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedStatus == http.StatusOK, called)
+			if tt.expectedStatus == http.StatusServiceUnavailable {
+				assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+				assert.Contains(t, rec.Body.String(), "read-only mode")
+			}
+		})
+	}
+}
+
+func TestReadOnlyMiddleware_Simulation(t *testing.T) {
+	transactions := &fakeTransactionClient{resp: &pbTransaction.CreateTransactionResponse{
+		Transaction:       &pbTransaction.Transaction{AccountId: "acc-1", OperationType: "CASH_PURCHASE", AmountCents: -2500},
+		BalanceAfterCents: 7500,
+		Simulated:         true,
+	}}
+	gateway := newTestGateway(t, transactions)
+	runtimeConfig, err := common.NewRuntimeConfigManager("", nil)
+	require.NoError(t, err)
+
+	r := mux.NewRouter()
+	r.Use(ReadOnlyMiddleware(runtimeConfig, true, 30*time.Second, gateway.logger))
+	r.HandleFunc("/transactions", gateway.CreateTransactionHandler).Methods("POST")
+	r.HandleFunc("/transactions/simulate", gateway.SimulateTransactionHandler).Methods("POST")
+
+	body := `{"account_id": "acc-1", "operation_type": "CASH_PURCHASE", "amount": 25.00}`
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(body)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Empty(t, transactions.requests, "rejected requests never reach the backend")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/transactions/simulate", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var simulated struct {
+		BalanceAfter common.Cents `json:"balance_after"`
+		Simulated    bool         `json:"simulated"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &simulated))
+	assert.Equal(t, common.Cents(7500), simulated.BalanceAfter)
+	assert.True(t, simulated.Simulated)
+	require.Len(t, transactions.requests, 1)
+	assert.True(t, transactions.requests[0].Simulate)
+	assert.Equal(t, int64(2500), transactions.requests[0].AmountCents)
+}
+
+func TestPaymentPreviewHandler(t *testing.T) {
+	tests := []struct {
+		name                string
+		query               string
+		resp                *pbTransaction.CreateTransactionResponse
+		expectedStatus      int
+		expectedBody        string
+		expectedAllocations string
+	}{
+		{
+			name:           "unknown account",
+			query:          "amount=200.00",
+			resp:           &pbTransaction.CreateTransactionResponse{Error: "account not found"},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "account not found",
+		},
+		{
+			name:           "invalid amount",
+			query:          "amount=lots",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid amount",
+		},
+		{
+			name:  "no debts",
+			query: "amount=200.00",
+			resp: &pbTransaction.CreateTransactionResponse{
+				Transaction:       &pbTransaction.Transaction{OperationType: "PAYMENT", AmountCents: 20000, BalanceCents: 20000},
+				BalanceAfterCents: 120000,
+				Simulated:         true,
+			},
+			expectedStatus:      http.StatusOK,
+			expectedAllocations: `[{"target": "balance", "amount": 200}]`,
+		},
+		{
+			name:  "partial discharge",
+			query: "amount=200.00",
+			resp: &pbTransaction.CreateTransactionResponse{
+				Transaction:       &pbTransaction.Transaction{OperationType: "PAYMENT", AmountCents: 20000},
+				BalanceAfterCents: 100000,
+				Simulated:         true,
+				Discharges: []*pbTransaction.Discharge{
+					{TransactionId: "purchase-1", AmountCents: 15000},
+					{TransactionId: "withdrawal-1", AmountCents: 5000, BalanceAfterCents: 2500},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedAllocations: `[
+				{"target": "transaction", "transaction_id": "purchase-1", "amount": 150, "balance_after": 0},
+				{"target": "transaction", "transaction_id": "withdrawal-1", "amount": 50, "balance_after": 25}
+			]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions := &fakeTransactionClient{resp: tt.resp}
+			gateway := newTestGateway(t, transactions)
+			r := mux.NewRouter()
+			r.HandleFunc("/accounts/{id}/payment-preview", gateway.PaymentPreviewHandler).Methods("GET")
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/accounts/acc-1/payment-preview?"+tt.query, nil))
+
+			require.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if tt.expectedStatus != http.StatusOK {
+				assert.Contains(t, rec.Body.String(), tt.expectedBody)
+				return
+			}
+
+			require.Len(t, transactions.requests, 1)
+			req := transactions.requests[0]
+			assert.Equal(t, "acc-1", req.AccountId)
+			assert.Equal(t, "PAYMENT", req.OperationType)
+			assert.Equal(t, int64(20000), req.AmountCents)
+			assert.True(t, req.Simulate, "previews never store the payment")
+
+			var preview struct {
+				AccountID     string          `json:"account_id"`
+				Amount        common.Cents    `json:"amount"`
+				BalanceBefore common.Cents    `json:"balance_before"`
+				BalanceAfter  common.Cents    `json:"balance_after"`
+				Allocations   json.RawMessage `json:"allocations"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &preview))
+			assert.Equal(t, "acc-1", preview.AccountID)
+			assert.Equal(t, common.Cents(20000), preview.Amount)
+			assert.Equal(t, common.Cents(tt.resp.BalanceAfterCents-20000), preview.BalanceBefore)
+			assert.Equal(t, common.Cents(tt.resp.BalanceAfterCents), preview.BalanceAfter)
+			assert.JSONEq(t, tt.expectedAllocations, string(preview.Allocations))
+		})
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "", expected: ""},
+		{value: "Salary", expected: "Salary"},
+		{value: "Lunch, \"team\"", expected: "Lunch, \"team\""},
+		{value: "a=b", expected: "a=b"},
+		{value: "=HYPERLINK(\"http://evil\")", expected: "'=HYPERLINK(\"http://evil\")"},
+		{value: "+1+1", expected: "'+1+1"},
+		{value: "-1+1", expected: "'-1+1"},
+		{value: "@SUM(A1)", expected: "'@SUM(A1)"},
+		{value: "\t=1+1", expected: "'\t=1+1"},
+		{value: "\r=1+1", expected: "'\r=1+1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, csvText(tt.value))
+		})
+	}
+}
+
+func TestParseExportColumns(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expected      []string
+		expectedError string
+	}{
+		{name: "default", value: "", expected: defaultExportColumns},
+		{name: "chosen", value: "id, amount ,original_transaction_id", expected: []string{"id", "amount", "original_transaction_id"}},
+		{name: "unknown", value: "id,balance", expectedError: `unknown column "balance"`},
+		{name: "duplicate", value: "id,amount,id", expectedError: `duplicate column "id"`},
+		{name: "empty", value: "id,,amount", expectedError: "empty column"},
+		{name: "trailing comma", value: "id,", expectedError: "empty column"},
+		{name: "blank", value: " ", expectedError: "empty column"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := parseExportColumns(tt.value)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, columns)
+		})
+	}
+}
+
+func TestExportTransactionHistoryHandler(t *testing.T) {
+	transactions := []*pbTransaction.Transaction{
+		{Id: "txn-1", AccountId: "acc-1", OperationType: "PAYMENT", AmountCents: 10000, Description: "Salary", Status: "COMPLETED", CreatedAt: 1700000000},
+		{Id: "txn-2", AccountId: "acc-1", OperationType: "CASH_PURCHASE", AmountCents: -1250, Description: "=1+1", Status: "COMPLETED", Tags: []string{"food", "team"}, CreatedAt: 1700003600},
+	}
+
+	tests := []struct {
+		name            string
+		query           string
+		streamed        []*pbTransaction.Transaction
+		streamErr       error
+		expectedStatus  int
+		expectedBody    string
+		expectedRequest *pbTransaction.StreamTransactionsRequest
+	}{
+		{
+			name:           "default columns",
+			streamed:       transactions,
+			expectedStatus: http.StatusOK,
+			expectedBody: "id,account_id,operation_type,amount,description,status,external_id,tags,created_at\n" +
+				"txn-1,acc-1,PAYMENT,100.00,Salary,COMPLETED,,,2023-11-14T22:13:20Z\n" +
+				"txn-2,acc-1,CASH_PURCHASE,-12.50,'=1+1,COMPLETED,,\"food,team\",2023-11-14T23:13:20Z\n",
+			expectedRequest: &pbTransaction.StreamTransactionsRequest{AccountId: "acc-1"},
+		},
+		{
+			name:            "chosen columns and filters",
+			query:           "columns=id,amount&from=1700000000&to=1700003600&status=COMPLETED&operation_type=PAYMENT",
+			streamed:        transactions[:1],
+			expectedStatus:  http.StatusOK,
+			expectedBody:    "id,amount\ntxn-1,100.00\n",
+			expectedRequest: &pbTransaction.StreamTransactionsRequest{AccountId: "acc-1", From: 1700000000, To: 1700003600, Status: "COMPLETED", OperationType: "PAYMENT"},
+		},
+		{
+			name:            "no transactions",
+			query:           "format=csv&columns=id",
+			expectedStatus:  http.StatusOK,
+			expectedBody:    "id\n",
+			expectedRequest: &pbTransaction.StreamTransactionsRequest{AccountId: "acc-1"},
+		},
+		{name: "unknown format", query: "format=xlsx", expectedStatus: http.StatusBadRequest, expectedBody: "format must be csv"},
+		{name: "unknown column", query: "columns=id,balance", expectedStatus: http.StatusBadRequest, expectedBody: `unknown column "balance"`},
+		{name: "duplicate column", query: "columns=id,id", expectedStatus: http.StatusBadRequest, expectedBody: `duplicate column "id"`},
+		{name: "empty column", query: "columns=id,", expectedStatus: http.StatusBadRequest, expectedBody: "empty column"},
+		{name: "invalid timestamp", query: "from=yesterday", expectedStatus: http.StatusBadRequest, expectedBody: "Invalid from"},
+		{
+			name:            "invalid stream request",
+			query:           "status=LOST",
+			streamErr:       status.Error(codes.InvalidArgument, "invalid status"),
+			expectedStatus:  http.StatusBadRequest,
+			expectedBody:    "invalid status",
+			expectedRequest: &pbTransaction.StreamTransactionsRequest{AccountId: "acc-1", Status: "LOST"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeTransactionClient{streamed: tt.streamed, streamErr: tt.streamErr}
+			gateway := newTestGateway(t, client)
+			r := mux.NewRouter()
+			r.HandleFunc("/accounts/{account_id}/transactions/export", gateway.ExportTransactionHistoryHandler).Methods("GET")
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/accounts/acc-1/transactions/export?"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			} else {
+				assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+				assert.Equal(t, `attachment; filename="transactions-acc-1.csv"`, rec.Header().Get("Content-Disposition"))
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+			}
+			if tt.expectedRequest == nil {
+				assert.Empty(t, client.streamRequests, "invalid parameters are refused before streaming")
+				return
+			}
+			require.Len(t, client.streamRequests, 1)
+			assert.Equal(t, tt.expectedRequest.String(), client.streamRequests[0].String())
+		})
+	}
+}
+
+func TestExportTransactionHistoryHandler_AbortsOnStreamFailure(t *testing.T) {
+	client := &fakeTransactionClient{
+		streamed:  []*pbTransaction.Transaction{{Id: "txn-1", AccountId: "acc-1", AmountCents: 10000}},
+		streamErr: status.Error(codes.Unavailable, "connection reset"),
+	}
+	gateway := newTestGateway(t, client)
+	r := mux.NewRouter()
+	r.HandleFunc("/accounts/{account_id}/transactions/export", gateway.ExportTransactionHistoryHandler).Methods("GET")
+
+	rec := httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/accounts/acc-1/transactions/export?columns=id", nil))
+	}, "a failure after the download started aborts the response")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "id\ntxn-1\n", rec.Body.String())
+}
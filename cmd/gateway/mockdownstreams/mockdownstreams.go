@@ -0,0 +1,548 @@
+// Package mockdownstreams serves in-memory fakes of the account and transaction services, which the gateway uses
+// in place of the backends named by MOCK_DOWNSTREAMS.
+package mockdownstreams
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pbAccount "github.com/YASHIRAI/pismo-task/proto/account"
+	pbTransaction "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// Downstreams tells which backends MOCK_DOWNSTREAMS replaces with in-memory fakes: "account",
+// "transaction" or both, comma separated, "all", or "missing" for the backends that are not reachable at startup.
+type Downstreams struct {
+	Account     bool
+	Transaction bool
+	Missing     bool
+}
+
+// FromEnv parses MOCK_DOWNSTREAMS. Unset, no backend is replaced.
+func FromEnv() (Downstreams, error) {
+	var mocks Downstreams
+	for _, name := range strings.Split(os.Getenv("MOCK_DOWNSTREAMS"), ",") {
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case "":
+		case "account":
+			mocks.Account = true
+		case "transaction":
+			mocks.Transaction = true
+		case "all":
+			mocks.Account, mocks.Transaction = true, true
+		case "missing":
+			mocks.Missing = true
+		default:
+			return Downstreams{}, fmt.Errorf("unknown downstream %q: want account, transaction, all or missing", name)
+		}
+	}
+	return mocks, nil
+}
+
+// Server serves the fake account and transaction services in process, with a health service reporting
+// them SERVING, so the gateway uses them like the real backends.
+type Server struct {
+	server *grpc.Server
+	conn   *grpc.ClientConn
+}
+
+// Start starts the fake services on a loopback port. They share one in-memory store, which is lost when the
+// gateway stops.
+func Start() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	store := newMockStore()
+	server := grpc.NewServer()
+	pbAccount.RegisterAccountServiceServer(server, &mockAccountService{store: store})
+	pbTransaction.RegisterTransactionServiceServer(server, &mockTransactionService{store: store})
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///"+listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(common.RequestIDUnaryClientInterceptor()),
+	)
+	if err != nil {
+		server.Stop()
+		return nil, err
+	}
+	return &Server{server: server, conn: conn}, nil
+}
+
+// Conn returns the connection to the fake services.
+func (m *Server) Conn() *grpc.ClientConn {
+	return m.conn
+}
+
+// Stop closes the connection to the fake services and stops them.
+func (m *Server) Stop() {
+	m.conn.Close()
+	m.server.Stop()
+}
+
+// mockStore holds the accounts and transactions of the fake services.
+type mockStore struct {
+	mu           sync.Mutex
+	accounts     map[string]*pbAccount.Account
+	accountIDs   []string // in creation order
+	transactions map[string]*pbTransaction.Transaction
+	history      map[string][]string // transaction IDs by account, oldest first
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		accounts:     make(map[string]*pbAccount.Account),
+		transactions: make(map[string]*pbTransaction.Transaction),
+		history:      make(map[string][]string),
+	}
+}
+
+// accountByDocument returns the account holding documentNumber, or nil. The caller holds mu.
+func (s *mockStore) accountByDocument(documentNumber string) *pbAccount.Account {
+	for _, account := range s.accounts {
+		if account.DocumentNumber == documentNumber {
+			return account
+		}
+	}
+	return nil
+}
+
+// mockAccountService fakes the account operations the gateway uses most: creating, reading, listing, updating
+// and deleting accounts and reading their balances. Other operations are unimplemented.
+type mockAccountService struct {
+	pbAccount.UnimplementedAccountServiceServer
+	store *mockStore
+}
+
+func (m *mockAccountService) CreateAccount(_ context.Context, req *pbAccount.CreateAccountRequest) (*pbAccount.CreateAccountResponse, error) {
+	if req.DocumentNumber == "" || req.AccountType == "" {
+		return &pbAccount.CreateAccountResponse{Error: "missing required fields"}, nil
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	if existing := m.store.accountByDocument(req.DocumentNumber); existing != nil {
+		return &pbAccount.CreateAccountResponse{Error: "account already exists", ExistingAccountId: existing.Id}, nil
+	}
+
+	now := common.GetCurrentTimestamp()
+	account := &pbAccount.Account{
+		Id:             uuid.New().String(),
+		DocumentNumber: req.DocumentNumber,
+		AccountType:    req.AccountType,
+		BalanceCents:   req.InitialBalanceCents,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Status:         "ACTIVE",
+		HolderName:     req.HolderName,
+		HolderEmail:    req.HolderEmail,
+		HolderPhone:    req.HolderPhone,
+		Version:        1,
+	}
+	if req.Draft {
+		account.Status = "DRAFT"
+	}
+	m.store.accounts[account.Id] = account
+	m.store.accountIDs = append(m.store.accountIDs, account.Id)
+	return &pbAccount.CreateAccountResponse{Account: cloneAccount(account)}, nil
+}
+
+func (m *mockAccountService) GetAccount(_ context.Context, req *pbAccount.GetAccountRequest) (*pbAccount.GetAccountResponse, error) {
+	if req.Id == "" {
+		return &pbAccount.GetAccountResponse{Error: "id required"}, nil
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	account, ok := m.store.accounts[req.Id]
+	if !ok {
+		return &pbAccount.GetAccountResponse{Error: "not found"}, nil
+	}
+	return &pbAccount.GetAccountResponse{Account: cloneAccount(account)}, nil
+}
+
+func (m *mockAccountService) GetAccountByDocument(_ context.Context, req *pbAccount.GetAccountByDocumentRequest) (*pbAccount.GetAccountByDocumentResponse, error) {
+	if req.DocumentNumber == "" {
+		return &pbAccount.GetAccountByDocumentResponse{Error: "document_number required"}, nil
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	account := m.store.accountByDocument(req.DocumentNumber)
+	if account == nil {
+		return &pbAccount.GetAccountByDocumentResponse{Error: "not found"}, nil
+	}
+	return &pbAccount.GetAccountByDocumentResponse{Account: cloneAccount(account)}, nil
+}
+
+func (m *mockAccountService) ListAccounts(_ context.Context, req *pbAccount.ListAccountsRequest) (*pbAccount.ListAccountsResponse, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	// Newest first, as the account service lists them
+	var matching []*pbAccount.Account
+	for i := len(m.store.accountIDs) - 1; i >= 0; i-- {
+		account := m.store.accounts[m.store.accountIDs[i]]
+		switch {
+		case req.CreatedFrom > 0 && account.CreatedAt < req.CreatedFrom,
+			req.CreatedTo > 0 && account.CreatedAt >= req.CreatedTo,
+			req.MinBalanceCents != nil && account.BalanceCents < *req.MinBalanceCents,
+			req.MaxBalanceCents != nil && account.BalanceCents > *req.MaxBalanceCents:
+			continue
+		}
+		matching = append(matching, account)
+	}
+
+	response := &pbAccount.ListAccountsResponse{Total: int32(len(matching))}
+	for _, account := range page(matching, int(req.Offset), int(req.Limit)) {
+		response.Accounts = append(response.Accounts, cloneAccount(account))
+	}
+	return response, nil
+}
+
+func (m *mockAccountService) UpdateAccount(_ context.Context, req *pbAccount.UpdateAccountRequest) (*pbAccount.UpdateAccountResponse, error) {
+	if req.Id == "" {
+		return &pbAccount.UpdateAccountResponse{Error: "id required"}, nil
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	account, ok := m.store.accounts[req.Id]
+	switch {
+	case !ok:
+		return &pbAccount.UpdateAccountResponse{Error: "not found"}, nil
+	case req.ExpectedVersion != 0 && req.ExpectedVersion != account.Version:
+		return &pbAccount.UpdateAccountResponse{Error: "version conflict"}, nil
+	case req.DocumentNumber != "" && req.DocumentNumber != account.DocumentNumber:
+		return &pbAccount.UpdateAccountResponse{Error: "document_number changes require verification"}, nil
+	}
+
+	if req.AccountType != "" {
+		account.AccountType = req.AccountType
+	}
+	if req.HolderName != "" {
+		account.HolderName = req.HolderName
+	}
+	if req.HolderEmail != "" {
+		account.HolderEmail = req.HolderEmail
+	}
+	if req.HolderPhone != "" {
+		account.HolderPhone = req.HolderPhone
+	}
+	account.Version++
+	account.UpdatedAt = common.GetCurrentTimestamp()
+	return &pbAccount.UpdateAccountResponse{Account: cloneAccount(account)}, nil
+}
+
+func (m *mockAccountService) DeleteAccount(_ context.Context, req *pbAccount.DeleteAccountRequest) (*pbAccount.DeleteAccountResponse, error) {
+	if req.Id == "" {
+		return &pbAccount.DeleteAccountResponse{Error: "id required"}, nil
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	if _, ok := m.store.accounts[req.Id]; !ok {
+		return &pbAccount.DeleteAccountResponse{Error: "account not found"}, nil
+	}
+	delete(m.store.accounts, req.Id)
+	for i, id := range m.store.accountIDs {
+		if id == req.Id {
+			m.store.accountIDs = append(m.store.accountIDs[:i], m.store.accountIDs[i+1:]...)
+			break
+		}
+	}
+	return &pbAccount.DeleteAccountResponse{Success: true}, nil
+}
+
+func (m *mockAccountService) GetBalance(_ context.Context, req *pbAccount.GetBalanceRequest) (*pbAccount.GetBalanceResponse, error) {
+	if req.AccountId == "" {
+		return &pbAccount.GetBalanceResponse{Error: "account_id required"}, nil
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	account, ok := m.store.accounts[req.AccountId]
+	if !ok {
+		return &pbAccount.GetBalanceResponse{Error: "account not found"}, nil
+	}
+	return &pbAccount.GetBalanceResponse{BalanceCents: account.BalanceCents}, nil
+}
+
+func (m *mockAccountService) GetBalances(_ context.Context, req *pbAccount.GetBalancesRequest) (*pbAccount.GetBalancesResponse, error) {
+	if req.AccountId == "" {
+		return &pbAccount.GetBalancesResponse{Error: "account_id required"}, nil
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	account, ok := m.store.accounts[req.AccountId]
+	if !ok {
+		return &pbAccount.GetBalancesResponse{Error: "account not found"}, nil
+	}
+	return &pbAccount.GetBalancesResponse{
+		AccountId: account.Id,
+		Balances: []*pbAccount.CurrencyBalance{{
+			BalanceCents:   account.BalanceCents,
+			AvailableCents: account.BalanceCents,
+		}},
+	}, nil
+}
+
+// mockTransactionService fakes creating, simulating, reading and reversing transactions, payments and
+// transaction histories, with the default operation rules. Transactions are made on the accounts of the fake
+// account service. Other operations are unimplemented.
+type mockTransactionService struct {
+	pbTransaction.UnimplementedTransactionServiceServer
+	store *mockStore
+}
+
+// mockDebits lists the operation types that debit the account; PAYMENT credits it.
+var mockDebits = map[string]bool{
+	"CASH_PURCHASE":        true,
+	"INSTALLMENT_PURCHASE": true,
+	"WITHDRAWAL":           true,
+}
+
+func (m *mockTransactionService) CreateTransaction(_ context.Context, req *pbTransaction.CreateTransactionRequest) (*pbTransaction.CreateTransactionResponse, error) {
+	transaction, balanceAfter, msg := m.create(req)
+	if msg != "" {
+		return &pbTransaction.CreateTransactionResponse{Error: msg, Simulated: req.Simulate}, nil
+	}
+	if req.Simulate {
+		return &pbTransaction.CreateTransactionResponse{Transaction: transaction, Simulated: true, BalanceAfterCents: balanceAfter}, nil
+	}
+	return &pbTransaction.CreateTransactionResponse{Transaction: transaction}, nil
+}
+
+func (m *mockTransactionService) ProcessPayment(_ context.Context, req *pbTransaction.ProcessPaymentRequest) (*pbTransaction.ProcessPaymentResponse, error) {
+	if req.Currency != "" {
+		return &pbTransaction.ProcessPaymentResponse{Error: "currency conversion is not available"}, nil
+	}
+	transaction, _, msg := m.create(&pbTransaction.CreateTransactionRequest{
+		AccountId:     req.AccountId,
+		OperationType: "PAYMENT",
+		AmountCents:   req.AmountCents,
+		Description:   req.Description,
+	})
+	return &pbTransaction.ProcessPaymentResponse{Transaction: transaction, Error: msg}, nil
+}
+
+// create applies a transaction to its account, or only checks it for simulations. It returns the transaction
+// and the account balance after it, or the error message of a declined transaction.
+func (m *mockTransactionService) create(req *pbTransaction.CreateTransactionRequest) (*pbTransaction.Transaction, int64, string) {
+	if req.AccountId == "" || req.OperationType == "" {
+		return nil, 0, "missing required fields"
+	}
+	debit := mockDebits[req.OperationType]
+	if !debit && req.OperationType != "PAYMENT" {
+		return nil, 0, "invalid operation type"
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	account, ok := m.store.accounts[req.AccountId]
+	if !ok {
+		return nil, 0, "account not found"
+	}
+	if account.Status != "ACTIVE" {
+		return nil, 0, "account not active"
+	}
+
+	amount := req.AmountCents
+	switch {
+	case !debit && amount <= 0:
+		return nil, 0, "payment amount must be positive"
+	case debit && amount > 0:
+		amount = -amount
+	}
+	if amount < 0 && account.BalanceCents+amount < -account.OverdraftLimitCents {
+		return nil, 0, "insufficient balance"
+	}
+	if req.ExternalId != "" {
+		for _, existing := range m.store.transactions {
+			if existing.ExternalId == req.ExternalId {
+				return nil, 0, "could not create transaction"
+			}
+		}
+	}
+
+	balanceAfter := account.BalanceCents + amount
+	transaction := &pbTransaction.Transaction{
+		AccountId:     req.AccountId,
+		OperationType: req.OperationType,
+		AmountCents:   amount,
+		Description:   req.Description,
+		CreatedAt:     common.GetCurrentTimestamp(),
+		Status:        "COMPLETED",
+		ExternalId:    req.ExternalId,
+		Overdrawn:     amount < 0 && balanceAfter < 0,
+	}
+	if req.Simulate {
+		return transaction, balanceAfter, ""
+	}
+
+	transaction.Id = uuid.New().String()
+	account.BalanceCents = balanceAfter
+	m.store.transactions[transaction.Id] = transaction
+	m.store.history[account.Id] = append(m.store.history[account.Id], transaction.Id)
+	return cloneTransaction(transaction), balanceAfter, ""
+}
+
+func (m *mockTransactionService) GetTransaction(_ context.Context, req *pbTransaction.GetTransactionRequest) (*pbTransaction.GetTransactionResponse, error) {
+	if req.Id == "" && req.ExternalId == "" {
+		return &pbTransaction.GetTransactionResponse{Error: "id required"}, nil
+	}
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	for _, transaction := range m.store.transactions {
+		if (req.Id != "" && transaction.Id == req.Id) || (req.Id == "" && transaction.ExternalId == req.ExternalId) {
+			return &pbTransaction.GetTransactionResponse{Transaction: cloneTransaction(transaction)}, nil
+		}
+	}
+	return &pbTransaction.GetTransactionResponse{Error: "not found"}, nil
+}
+
+// GetTransactionHistory returns the transactions of an account, newest first. Page tokens are offsets.
+func (m *mockTransactionService) GetTransactionHistory(_ context.Context, req *pbTransaction.GetTransactionHistoryRequest) (*pbTransaction.GetTransactionHistoryResponse, error) {
+	if req.AccountId == "" {
+		return &pbTransaction.GetTransactionHistoryResponse{Error: "account_id required"}, nil
+	}
+	offset := int(req.Offset)
+	if req.PageToken != "" {
+		var err error
+		if offset, err = strconv.Atoi(req.PageToken); err != nil || offset < 0 {
+			return &pbTransaction.GetTransactionHistoryResponse{Error: "invalid page token"}, nil
+		}
+	}
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = 10
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	var matching []*pbTransaction.Transaction
+	ids := m.store.history[req.AccountId]
+	for i := len(ids) - 1; i >= 0; i-- {
+		transaction := m.store.transactions[ids[i]]
+		if req.Search != "" && !strings.Contains(strings.ToLower(transaction.Description), strings.ToLower(req.Search)) {
+			continue
+		}
+		matching = append(matching, transaction)
+	}
+
+	response := &pbTransaction.GetTransactionHistoryResponse{Total: int32(len(matching)), AppliedLimit: int32(limit)}
+	for _, transaction := range page(matching, offset, limit) {
+		response.Transactions = append(response.Transactions, cloneTransaction(transaction))
+	}
+	if offset+limit < len(matching) {
+		response.NextPageToken = strconv.Itoa(offset + limit)
+	}
+	return response, nil
+}
+
+// StreamTransactions sends the transactions of an account oldest first, filtered as the real service does.
+func (m *mockTransactionService) StreamTransactions(req *pbTransaction.StreamTransactionsRequest, stream pbTransaction.TransactionService_StreamTransactionsServer) error {
+	if req.AccountId == "" {
+		return status.Error(codes.InvalidArgument, "account_id required")
+	}
+
+	m.store.mu.Lock()
+	var matching []*pbTransaction.Transaction
+	for _, id := range m.store.history[req.AccountId] {
+		transaction := m.store.transactions[id]
+		if (req.From > 0 && transaction.CreatedAt < req.From) || (req.To > 0 && transaction.CreatedAt >= req.To) ||
+			(req.Status != "" && transaction.Status != req.Status) ||
+			(req.OperationType != "" && transaction.OperationType != req.OperationType) {
+			continue
+		}
+		matching = append(matching, cloneTransaction(transaction))
+	}
+	m.store.mu.Unlock()
+
+	for _, transaction := range matching {
+		if err := stream.Send(transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockTransactionService) ReverseTransaction(_ context.Context, req *pbTransaction.ReverseTransactionRequest) (*pbTransaction.ReverseTransactionResponse, error) {
+	if req.Id == "" {
+		return &pbTransaction.ReverseTransactionResponse{Error: "id required"}, nil
+	}
+	if req.Reason == "" {
+		return &pbTransaction.ReverseTransactionResponse{Error: "reason required"}, nil
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	original, ok := m.store.transactions[req.Id]
+	switch {
+	case !ok:
+		return &pbTransaction.ReverseTransactionResponse{Error: "not found"}, nil
+	case original.Status == "REVERSED":
+		return &pbTransaction.ReverseTransactionResponse{Error: "transaction already reversed"}, nil
+	case original.OperationType == "REVERSAL":
+		return &pbTransaction.ReverseTransactionResponse{Error: "reversals cannot be reversed"}, nil
+	}
+	account, ok := m.store.accounts[original.AccountId]
+	if !ok {
+		return &pbTransaction.ReverseTransactionResponse{Error: "not found"}, nil
+	}
+	amount := -original.AmountCents
+	if amount < 0 && account.BalanceCents+amount < 0 {
+		return &pbTransaction.ReverseTransactionResponse{Error: "insufficient balance"}, nil
+	}
+
+	reversal := &pbTransaction.Transaction{
+		Id:                    uuid.New().String(),
+		AccountId:             original.AccountId,
+		OperationType:         "REVERSAL",
+		AmountCents:           amount,
+		Description:           req.Reason,
+		CreatedAt:             common.GetCurrentTimestamp(),
+		Status:                "COMPLETED",
+		OriginalTransactionId: original.Id,
+	}
+	original.Status = "REVERSED"
+	account.BalanceCents += reversal.AmountCents
+	m.store.transactions[reversal.Id] = reversal
+	m.store.history[account.Id] = append(m.store.history[account.Id], reversal.Id)
+	return &pbTransaction.ReverseTransactionResponse{Original: cloneTransaction(original), Reversal: cloneTransaction(reversal)}, nil
+}
+
+// page returns the items of a page of at most limit items starting at offset; a limit of zero or less is
+// unlimited.
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// cloneAccount copies an account of the store, so responses do not share it.
+func cloneAccount(account *pbAccount.Account) *pbAccount.Account {
+	return proto.Clone(account).(*pbAccount.Account)
+}
+
+// cloneTransaction copies a transaction of the store, so responses do not share it.
+func cloneTransaction(transaction *pbTransaction.Transaction) *pbTransaction.Transaction {
+	return proto.Clone(transaction).(*pbTransaction.Transaction)
+}
@@ -0,0 +1,131 @@
+package mockdownstreams
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pbAccount "github.com/YASHIRAI/pismo-task/proto/account"
+	pbTransaction "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expected      Downstreams
+		expectedError bool
+	}{
+		{name: "unset", value: "", expected: Downstreams{}},
+		{name: "one backend", value: "account", expected: Downstreams{Account: true}},
+		{name: "both backends", value: "account, Transaction", expected: Downstreams{Account: true, Transaction: true}},
+		{name: "all", value: "all", expected: Downstreams{Account: true, Transaction: true}},
+		{name: "missing", value: "missing", expected: Downstreams{Missing: true}},
+		{name: "unknown backend", value: "ledger", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MOCK_DOWNSTREAMS", tt.value)
+
+			mocks, err := FromEnv()
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mocks)
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	server, err := Start()
+	require.NoError(t, err)
+	defer server.Stop()
+
+	ctx := context.Background()
+	accounts := pbAccount.NewAccountServiceClient(server.Conn())
+	transactions := pbTransaction.NewTransactionServiceClient(server.Conn())
+
+	healthResp, err := healthpb.NewHealthClient(server.Conn()).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthResp.Status)
+
+	created, err := accounts.CreateAccount(ctx, &pbAccount.CreateAccountRequest{DocumentNumber: "12345678901", AccountType: "CHECKING", InitialBalanceCents: 5000})
+	require.NoError(t, err)
+	require.Empty(t, created.Error)
+	accountID := created.Account.Id
+
+	duplicate, err := accounts.CreateAccount(ctx, &pbAccount.CreateAccountRequest{DocumentNumber: "12345678901", AccountType: "SAVINGS"})
+	require.NoError(t, err)
+	assert.Equal(t, "account already exists", duplicate.Error)
+	assert.Equal(t, accountID, duplicate.ExistingAccountId)
+
+	// Debits are signed by their operation type and may not exceed the balance
+	declined, err := transactions.CreateTransaction(ctx, &pbTransaction.CreateTransactionRequest{AccountId: accountID, OperationType: "WITHDRAWAL", AmountCents: 6000})
+	require.NoError(t, err)
+	assert.Equal(t, "insufficient balance", declined.Error)
+
+	simulated, err := transactions.CreateTransaction(ctx, &pbTransaction.CreateTransactionRequest{AccountId: accountID, OperationType: "CASH_PURCHASE", AmountCents: 2000, Simulate: true})
+	require.NoError(t, err)
+	require.Empty(t, simulated.Error)
+	assert.True(t, simulated.Simulated)
+	assert.Equal(t, int64(3000), simulated.BalanceAfterCents)
+
+	purchase, err := transactions.CreateTransaction(ctx, &pbTransaction.CreateTransactionRequest{AccountId: accountID, OperationType: "CASH_PURCHASE", AmountCents: 2000, Description: "Coffee"})
+	require.NoError(t, err)
+	require.Empty(t, purchase.Error)
+	assert.Equal(t, int64(-2000), purchase.Transaction.AmountCents)
+
+	payment, err := transactions.ProcessPayment(ctx, &pbTransaction.ProcessPaymentRequest{AccountId: accountID, AmountCents: 1000})
+	require.NoError(t, err)
+	require.Empty(t, payment.Error)
+
+	balance, err := accounts.GetBalance(ctx, &pbAccount.GetBalanceRequest{AccountId: accountID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4000), balance.BalanceCents, "simulations leave the balance alone")
+
+	// Histories are newest first, and their page tokens are offsets
+	history, err := transactions.GetTransactionHistory(ctx, &pbTransaction.GetTransactionHistoryRequest{AccountId: accountID, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, history.Transactions, 1)
+	assert.Equal(t, payment.Transaction.Id, history.Transactions[0].Id)
+	assert.Equal(t, int32(2), history.Total)
+	assert.Equal(t, "1", history.NextPageToken)
+
+	searched, err := transactions.GetTransactionHistory(ctx, &pbTransaction.GetTransactionHistoryRequest{AccountId: accountID, Search: "coffee"})
+	require.NoError(t, err)
+	require.Len(t, searched.Transactions, 1)
+	assert.Equal(t, purchase.Transaction.Id, searched.Transactions[0].Id)
+
+	reversed, err := transactions.ReverseTransaction(ctx, &pbTransaction.ReverseTransactionRequest{Id: purchase.Transaction.Id, Reason: "Refund"})
+	require.NoError(t, err)
+	require.Empty(t, reversed.Error)
+	assert.Equal(t, "REVERSED", reversed.Original.Status)
+	assert.Equal(t, int64(2000), reversed.Reversal.AmountCents)
+
+	again, err := transactions.ReverseTransaction(ctx, &pbTransaction.ReverseTransactionRequest{Id: purchase.Transaction.Id, Reason: "Refund"})
+	require.NoError(t, err)
+	assert.Equal(t, "transaction already reversed", again.Error)
+
+	// Streams are oldest first and filtered like the real service's
+	stream, err := transactions.StreamTransactions(ctx, &pbTransaction.StreamTransactionsRequest{AccountId: accountID, OperationType: "REVERSAL"})
+	require.NoError(t, err)
+	streamed, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, reversed.Reversal.Id, streamed.Id)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	deleted, err := accounts.DeleteAccount(ctx, &pbAccount.DeleteAccountRequest{Id: accountID})
+	require.NoError(t, err)
+	assert.True(t, deleted.Success)
+	orphan, err := transactions.CreateTransaction(ctx, &pbTransaction.CreateTransactionRequest{AccountId: accountID, OperationType: "PAYMENT", AmountCents: 100})
+	require.NoError(t, err)
+	assert.Equal(t, "account not found", orphan.Error)
+}
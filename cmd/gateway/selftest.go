@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+)
+
+// runSelfTest checks what the gateway needs to start: its runtime config, its read-only settings and the
+// account and transaction services it forwards to, which must be reachable and serving. Returns the exit code.
+func runSelfTest(logger *common.Logger) int {
+	selfTest := common.NewSelfTest(logger)
+	selfTest.CheckRuntimeConfig(os.Getenv("RUNTIME_CONFIG_FILE"))
+	selfTest.Check("environment", func(ctx context.Context) error {
+		if value := os.Getenv("READ_ONLY_RETRY_AFTER"); value != "" {
+			if _, err := time.ParseDuration(value); err != nil {
+				return fmt.Errorf("invalid READ_ONLY_RETRY_AFTER %q", value)
+			}
+		}
+		return nil
+	})
+
+	accountAddr, transactionAddr := backendAddrs()
+	selfTest.Check("account service", func(ctx context.Context) error {
+		return checkBackend(ctx, accountAddr, logger)
+	})
+	selfTest.Check("transaction service", func(ctx context.Context) error {
+		return checkBackend(ctx, transactionAddr, logger)
+	})
+	return selfTest.ExitCode()
+}
+
+// checkBackend dials a backend the way the gateway does and waits until every channel to it is ready.
+func checkBackend(ctx context.Context, addr string, logger *common.Logger) error {
+	pool, err := common.NewClientConnPool(common.ServiceTarget(addr), common.NewClientConnPoolConfigFromEnv(), logger,
+		grpc.WithTransportCredentials(insecure.NewCredentials()), common.LoadBalancingDialOption())
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	if err := pool.WaitForReady(ctx); err != nil {
+		return fmt.Errorf("%s: %w", addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+)
+
+// schemaCheckTimeout bounds the connection and the schema query of schema-check.
+const schemaCheckTimeout = 30 * time.Second
+
+// usage lists the commands of pismoctl.
+const usage = `usage: pismoctl <command>
+
+commands:
+  schema-check   compare the live database schema with the schema the services expect`
+
+// main runs an operator command against the database, configured with the same DB_* variables as the services.
+func main() {
+	if len(os.Args) < 2 {
+		fail("%s", usage)
+	}
+
+	switch os.Args[1] {
+	case "schema-check":
+		schemaCheck()
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+	default:
+		fail("unknown command %q\n%s", os.Args[1], usage)
+	}
+}
+
+// schemaCheck prints every drift between the live schema and the expected one, and exits with status 1
+// if there are any, so it can gate a deployment.
+func schemaCheck() {
+	ctx, cancel := context.WithTimeout(context.Background(), schemaCheckTimeout)
+	defer cancel()
+
+	dbManager, err := common.NewDatabaseManagerContext(ctx)
+	if err != nil {
+		fail("failed to connect to the database: %v", err)
+	}
+	defer dbManager.Close()
+
+	drifts, err := common.CheckSchemaDrift(ctx, dbManager.GetDB())
+	if err != nil {
+		fail("%v", err)
+	}
+	if len(drifts) == 0 {
+		fmt.Println("OK: the schema matches the expected schema")
+		return
+	}
+
+	for _, drift := range drifts {
+		fmt.Println(drift)
+	}
+	dbManager.Close()
+	fail("%d schema drifts", len(drifts))
+}
+
+// fail prints an error and exits with status 1.
+func fail(format string, v ...interface{}) {
+	fmt.Fprintf(os.Stderr, "pismoctl: "+format+"\n", v...)
+	os.Exit(1)
+}
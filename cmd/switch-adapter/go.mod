@@ -0,0 +1,27 @@
+module github.com/YASHIRAI/pismo-task/cmd/switch-adapter
+
+go 1.24.0
+
+require (
+	github.com/YASHIRAI/pismo-task/internal/common v0.0.0-00010101000000-000000000000
+	github.com/YASHIRAI/pismo-task/internal/switchadapter v0.0.0-00010101000000-000000000000
+	github.com/YASHIRAI/pismo-task/proto/transaction v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.71.0
+)
+
+replace github.com/YASHIRAI/pismo-task/internal/common => ../../internal/common
+
+replace github.com/YASHIRAI/pismo-task/internal/switchadapter => ../../internal/switchadapter
+
+replace github.com/YASHIRAI/pismo-task/proto/transaction => ../../proto/transaction
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/lib/pq v1.10.9 // indirect
+	golang.org/x/net v0.37.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20250922171735-9219d122eba9 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250908214217-97024824d090 // indirect
+	google.golang.org/protobuf v1.36.9 // indirect
+)
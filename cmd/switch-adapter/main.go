@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	"github.com/YASHIRAI/pismo-task/internal/switchadapter"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// main starts the switch adapter, which answers ISO 8583-style messages from card switch simulators on
+// SWITCH_ADAPTER_ADDR (default :8583) with the transaction service at TRANSACTION_SERVICE_ADDR.
+func main() {
+	logger, err := common.NewLoggerFromEnv("switch-adapter")
+	if err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Close()
+
+	config := switchadapter.NewConfigFromEnv()
+	transactionAddr := os.Getenv("TRANSACTION_SERVICE_ADDR")
+	if transactionAddr == "" {
+		transactionAddr = "localhost:8082"
+	}
+	logger.Info("Starting switch adapter: Addr=%s, Transaction=%s, Tenant=%q, Timeout=%s",
+		config.Addr, transactionAddr, config.TenantID, config.Timeout)
+
+	transactionConn, err := common.NewClientConnPool(common.ServiceTarget(transactionAddr), common.NewClientConnPoolConfigFromEnv(), logger,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(common.RequestIDUnaryClientInterceptor()),
+		common.LoadBalancingDialOption(),
+	)
+	if err != nil {
+		logger.Fatal("Failed to connect to transaction service: %v", err)
+	}
+	defer transactionConn.Close()
+
+	startup := common.NewStartupCoordinatorFromEnv(logger)
+	if err := startup.WaitForChannels("transaction service", transactionConn); err != nil {
+		logger.Fatal("Failed to connect to transaction service: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		logger.Fatal("Failed to listen: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	adapter := switchadapter.NewAdapter(pb.NewTransactionServiceClient(transactionConn), logger, config)
+	logger.Info("Switch adapter listening on %s", listener.Addr())
+	if err := adapter.Serve(ctx, listener); err != nil {
+		logger.Fatal("Switch adapter stopped: %v", err)
+	}
+	logger.Info("Switch adapter stopped")
+}
@@ -3,13 +3,21 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/YASHIRAI/pismo-task/internal/common"
+	"github.com/YASHIRAI/pismo-task/internal/fx"
 	"github.com/YASHIRAI/pismo-task/internal/transaction"
 	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
 )
@@ -18,18 +26,35 @@ import (
 // It initializes the database connection, sets up the schema, and starts the gRPC server on port 8082.
 // The service handles transaction-related operations including creation, retrieval, and payment processing.
 func main() {
+	selfTest := flag.Bool("selftest", false, "check the configuration, the database and the pending migrations, then exit")
+	flag.Parse()
+
 	// Initialize logging
-	logLevel := common.ParseLogLevel(os.Getenv("LOG_LEVEL"))
-	logger, err := common.NewLogger("transaction-mgr", logLevel)
+	logger, err := common.NewLoggerFromEnv("transaction-mgr")
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
 	}
 	defer logger.Close()
 
+	// An init container can run the self-test to hold the rollout until the service can start
+	if *selfTest {
+		code := runSelfTest(logger)
+		logger.Close()
+		os.Exit(code)
+	}
+
 	logger.Info("Starting Transaction Manager service")
 
-	dbManager, err := common.NewDatabaseManager()
+	runtimeConfig, err := common.NewRuntimeConfigManager(os.Getenv("RUNTIME_CONFIG_FILE"), logger)
+	if err != nil {
+		logger.Fatal("Failed to load runtime config: %v", err)
+	}
+	go runtimeConfig.Watch(context.Background(), common.DefaultRuntimeConfigPollInterval)
+
+	// The database may still be starting on a cold start; wait for it rather than exit before binding the port
+	startup := common.NewStartupCoordinatorFromEnv(logger)
+	dbManager, err := startup.WaitForDatabase()
 	if err != nil {
 		logger.Fatal("Failed to initialize database: %v", err)
 	}
@@ -41,9 +66,165 @@ func main() {
 		logger.Fatal("Failed to initialize database schema: %v", err)
 	}
 
+	// InitSchema does not change existing columns, so a column of another type would go unnoticed
+	if err := dbManager.VerifySchema(context.Background(), logger, common.SchemaDriftModeFromEnv()); err != nil {
+		logger.Fatal("Database schema drift detected: %v", err)
+	}
+
 	logger.Info("Database schema initialized")
 
 	transactionService := transaction.NewService(dbManager.GetDB(), logger)
+	if err := transactionService.LoadOperationRules(context.Background()); err != nil {
+		logger.Fatal("Failed to load operation rules: %v", err)
+	}
+	rulesRefresh := transaction.DefaultOperationRulesRefreshInterval
+	if value := os.Getenv("OPERATION_RULES_REFRESH_INTERVAL"); value != "" {
+		if interval, err := time.ParseDuration(value); err == nil && interval > 0 {
+			rulesRefresh = interval
+		} else {
+			logger.Warn("Ignoring invalid OPERATION_RULES_REFRESH_INTERVAL %q", value)
+		}
+	}
+	go transactionService.WatchOperationRules(context.Background(), rulesRefresh)
+	logger.Info("Operation rules loaded, refreshing every %s", rulesRefresh)
+
+	if value := os.Getenv("EXPORT_WORKERS"); value != "" {
+		if workers, err := strconv.Atoi(value); err == nil && workers > 0 {
+			transactionService.SetExportWorkers(workers)
+		} else {
+			logger.Warn("Ignoring invalid EXPORT_WORKERS %q", value)
+		}
+	}
+
+	// Debits flagged by fraud scoring are held UNDER_REVIEW until an analyst approves or declines them
+	if risk := transaction.RiskPolicyFromEnv(); risk.Enabled() {
+		transactionService.SetRiskPolicy(risk)
+		logger.Info("Fraud scoring enabled: ReviewScore=%d, LargeAmount=%s, VelocityLimit=%d per %s",
+			risk.ReviewScore, risk.LargeAmount, risk.VelocityLimit, risk.VelocityWindow)
+	}
+
+	// Transactions created on one account beyond its sustained rate and burst credits are refused, whatever the caller
+	throttle := transaction.NewAccountThrottleFromEnv()
+	throttle.ApplyRuntimeConfig(runtimeConfig.Current())
+	runtimeConfig.OnReload(throttle.ApplyRuntimeConfig)
+	transactionService.SetAccountThrottle(throttle)
+	accountRate, accountBurst := throttle.Rates()
+	logger.Info("Account transaction throttling: Rate=%.1f per second, Burst=%.0f", accountRate, accountBurst)
+
+	// Events carry the messages rendered from the message templates of their tenant, or of the runtime config
+	transactionService.MessageTemplates().ApplyRuntimeConfig(runtimeConfig.Current())
+	runtimeConfig.OnReload(transactionService.MessageTemplates().ApplyRuntimeConfig)
+
+	// Payments in a currency other than the account's are converted at the rates of the configured provider
+	rates, err := fx.NewProviderFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid exchange rate configuration: %v", err)
+	}
+	if rates != nil {
+		transactionService.SetRateProvider(rates)
+		logger.Info("Currency conversion enabled: Provider=%s", os.Getenv("FX_RATE_PROVIDER"))
+	}
+
+	// Sandbox tenants see their held transactions settle on their own, as if cleared by the network
+	if value := os.Getenv("SANDBOX_CLEARING_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		delay := transaction.DefaultSandboxClearingDelay
+		if custom, derr := time.ParseDuration(os.Getenv("SANDBOX_CLEARING_DELAY")); derr == nil && custom >= 0 {
+			delay = custom
+		}
+		if err == nil && interval > 0 {
+			go transactionService.RunSandboxClearing(context.Background(), interval, delay)
+			logger.Info("Sandbox clearing started: Interval=%s, Delay=%s", interval, delay)
+		} else {
+			logger.Warn("Ignoring invalid SANDBOX_CLEARING_INTERVAL %q", value)
+		}
+	}
+
+	// The end-of-day close seals each UTC day once it has ended and records the balances of every account
+	if value := os.Getenv("EOD_CLOSE_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		delay := transaction.DefaultEndOfDayCloseDelay
+		if custom, derr := time.ParseDuration(os.Getenv("EOD_CLOSE_DELAY")); derr == nil && custom >= 0 {
+			delay = custom
+		}
+		if err == nil && interval > 0 {
+			go transactionService.RunEndOfDayClose(context.Background(), interval, delay)
+			logger.Info("End-of-day close started: Interval=%s, Delay=%s", interval, delay)
+		} else {
+			logger.Warn("Ignoring invalid EOD_CLOSE_INTERVAL %q", value)
+		}
+	}
+
+	// Recurring rules make their transactions when their runs are due
+	if value := os.Getenv("RECURRING_PAYMENTS_INTERVAL"); value != "" {
+		if interval, err := time.ParseDuration(value); err == nil && interval > 0 {
+			go transactionService.RunRecurringPayments(context.Background(), interval)
+			logger.Info("Recurring payments started: Interval=%s", interval)
+		} else {
+			logger.Warn("Ignoring invalid RECURRING_PAYMENTS_INTERVAL %q", value)
+		}
+	}
+
+	// Installment purchases debit their later installments when they fall due
+	if value := os.Getenv("INSTALLMENTS_INTERVAL"); value != "" {
+		if interval, err := time.ParseDuration(value); err == nil && interval > 0 {
+			go transactionService.RunInstallments(context.Background(), interval)
+			logger.Info("Installment debits started: Interval=%s", interval)
+		} else {
+			logger.Warn("Ignoring invalid INSTALLMENTS_INTERVAL %q", value)
+		}
+	}
+
+	outbox := common.NewOutboxConfigFromEnv()
+	if outbox.Enabled() {
+		transactionService.EnableEventOutbox()
+		publisher := common.NewHTTPEventPublisher(outbox.PublishURL, outbox.Timeout)
+		publisher.SignWith(transactionService.WebhookKeys())
+		relay := common.NewOutboxRelay(dbManager.GetDB(), logger, publisher, outbox.BatchSize)
+		go relay.Run(context.Background(), outbox.Interval)
+		logger.Info("Event outbox relay started: Interval=%s, BatchSize=%d", outbox.Interval, outbox.BatchSize)
+	}
+
+	// Business KPIs are served apart from the gRPC port so product dashboards can scrape them without database access
+	if metricsPort := os.Getenv("BUSINESS_METRICS_PORT"); metricsPort != "" {
+		kpis := common.NewBusinessMetrics(dbManager.GetDB(), logger)
+		transactionService.SetBusinessMetrics(kpis)
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", kpis)
+		go func() {
+			if err := http.ListenAndServe(":"+metricsPort, mux); err != nil {
+				logger.Error("Business metrics server stopped: %v", err)
+			}
+		}()
+		logger.Info("Business metrics listening on port %s", metricsPort)
+	}
+
+	// Business events are exported as OpenTelemetry metrics to the OTLP collector, tagged with the release
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT") != "" {
+		release := os.Getenv("RELEASE_VERSION")
+		meterProvider, err := transaction.NewBusinessEventsMeterProvider(context.Background(), "transaction-mgr", release)
+		if err != nil {
+			logger.Fatal("Failed to create business events exporter: %v", err)
+		}
+		defer meterProvider.Shutdown(context.Background())
+		if err := transactionService.SetMeterProvider(meterProvider); err != nil {
+			logger.Fatal("Failed to create business event instruments: %v", err)
+		}
+		logger.Info("Business events exported over OTLP: Release=%q", release)
+	}
+
+	// Money movements are traced with an event per balance mutation; failed and reversing ones are always exported
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		release := os.Getenv("RELEASE_VERSION")
+		ratio := transaction.TraceSampleRatioFromEnv()
+		tracerProvider, err := transaction.NewTracerProvider(context.Background(), "transaction-mgr", release, ratio)
+		if err != nil {
+			logger.Fatal("Failed to create money movement trace exporter: %v", err)
+		}
+		defer tracerProvider.Shutdown(context.Background())
+		transactionService.SetTracerProvider(tracerProvider)
+		logger.Info("Money movements traced over OTLP: SampleRatio=%g, Release=%q", ratio, release)
+	}
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -55,9 +236,112 @@ func main() {
 		logger.Fatal("Failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	compression := common.NewCompressionConfig()
+	logger.Info("gRPC compression: Enabled=%t, Threshold=%d bytes", compression.Enabled, compression.Threshold)
+
+	// Calls to the methods with an SLO target count against their error budget
+	sloTracker := common.NewSLOTracker()
+	sloTracker.ApplyRuntimeConfig(runtimeConfig.Current())
+	runtimeConfig.OnReload(sloTracker.ApplyRuntimeConfig)
+	transactionService.SetSLOTracker(sloTracker)
+
+	rateLimiter := common.NewRateLimiterFromEnv()
+	rateLimiter.ApplyRuntimeConfig(runtimeConfig.Current())
+	runtimeConfig.OnReload(rateLimiter.ApplyRuntimeConfig)
+	globalRate, callerRate := rateLimiter.Rates()
+	logger.Info("Rate limits: Global=%.0f QPS, PerCaller=%.0f QPS", globalRate, callerRate)
+
+	// Interactive calls are admitted ahead of bulk ones when the replica is saturated
+	scheduler := common.NewPrioritySchedulerFromEnv()
+	scheduler.ApplyRuntimeConfig(runtimeConfig.Current())
+	runtimeConfig.OnReload(scheduler.ApplyRuntimeConfig)
+	maxConcurrent, interactiveWeight := scheduler.Limits()
+	logger.Info("Priority lanes: MaxConcurrentCalls=%d, InteractiveWeight=%d", maxConcurrent, interactiveWeight)
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			common.RequestIDUnaryServerInterceptor(),
+			common.SLOUnaryServerInterceptor(sloTracker),
+			common.CancellationUnaryServerInterceptor(logger),
+			common.RateLimitUnaryServerInterceptor(rateLimiter, logger),
+			common.PriorityUnaryServerInterceptor(scheduler, logger),
+			common.CompressionUnaryServerInterceptor(compression),
+			common.AccessAuditUnaryServerInterceptor(common.NewAccessAuditor(dbManager.GetDB(), logger, "transaction-mgr")),
+			common.AuthorizationUnaryServerInterceptor(runtimeConfig),
+			common.LegacyAmountsUnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			common.RequestIDStreamServerInterceptor(),
+			common.RateLimitStreamServerInterceptor(rateLimiter, logger),
+			common.PriorityStreamServerInterceptor(scheduler, logger),
+			common.CompressionStreamServerInterceptor(compression),
+			common.AuthorizationStreamServerInterceptor(runtimeConfig),
+			common.LegacyAmountsStreamServerInterceptor(),
+		),
+	)
 	pb.RegisterTransactionServiceServer(grpcServer, transactionService)
 
+	// Standard gRPC health service used by client-side load balancers to detect unhealthy replicas
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	// Replicas that lose the database report NOT_SERVING, and every readiness change is logged and counted
+	readiness := common.NewReadinessMonitor("transaction-mgr", logger, common.DatabaseReadinessCheck(dbManager.GetDB()))
+	readiness.OnChange(func(ready bool) {
+		servingStatus := healthpb.HealthCheckResponse_NOT_SERVING
+		if ready {
+			servingStatus = healthpb.HealthCheckResponse_SERVING
+		}
+		healthServer.SetServingStatus("", servingStatus)
+	})
+	go readiness.Run(context.Background(), common.ReadinessIntervalFromEnv())
+	if metricsPort := os.Getenv("READINESS_METRICS_PORT"); metricsPort != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", readiness)
+		go func() {
+			if err := http.ListenAndServe(":"+metricsPort, mux); err != nil {
+				logger.Error("Readiness metrics server stopped: %v", err)
+			}
+		}()
+		logger.Info("Readiness metrics listening on port %s", metricsPort)
+	}
+
+	// Read-only streams for analytics workloads, on their own port and served from the read replica
+	if analyticsPort := os.Getenv("ANALYTICS_GRPC_PORT"); analyticsPort != "" {
+		replicaConfig, ok := common.ReplicaDatabaseConfigFromEnv()
+		if !ok {
+			logger.Fatal("ANALYTICS_GRPC_PORT is set but REPLICA_DB_HOST is not")
+		}
+		tokens, err := common.AnalyticsServiceTokensFromEnv()
+		if err != nil {
+			logger.Fatal("Invalid ANALYTICS_SERVICE_TOKENS: %v", err)
+		}
+		if len(tokens) == 0 {
+			logger.Fatal("ANALYTICS_GRPC_PORT is set but ANALYTICS_SERVICE_TOKENS lists no services")
+		}
+		replica, err := startup.WaitForReplica(replicaConfig)
+		if err != nil {
+			logger.Fatal("Failed to connect to read replica: %v", err)
+		}
+		defer replica.Close()
+		analyticsLis, err := net.Listen("tcp", ":"+analyticsPort)
+		if err != nil {
+			logger.Fatal("Failed to listen on analytics port: %v", err)
+		}
+		analyticsLimiter := common.NewAnalyticsRateLimiterFromEnv()
+		analyticsServer := common.NewAnalyticsServer(tokens, analyticsLimiter,
+			common.NewAccessAuditor(dbManager.GetDB(), logger, "transaction-mgr-analytics"), logger)
+		pb.RegisterTransactionAnalyticsServiceServer(analyticsServer, transaction.NewAnalyticsService(replica, logger))
+		go func() {
+			if err := analyticsServer.Serve(analyticsLis); err != nil {
+				logger.Error("Analytics gRPC server stopped: %v", err)
+			}
+		}()
+		globalRate, callerRate := analyticsLimiter.Rates()
+		logger.Info("Analytics service listening on port %s: Replica=%s, Global=%.0f QPS, PerCaller=%.0f QPS",
+			analyticsPort, replicaConfig.Host, globalRate, callerRate)
+	}
+
 	logger.Info("Transaction service listening on port %s", port)
 	if err := grpcServer.Serve(lis); err != nil {
 		logger.Fatal("Failed to serve: %v", err)
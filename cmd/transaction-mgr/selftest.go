@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+)
+
+// runSelfTest checks what the service needs to start: its runtime config, the settings it would otherwise
+// ignore with a warning when invalid, the database and the pending schema migrations. Returns the exit code.
+func runSelfTest(logger *common.Logger) int {
+	selfTest := common.NewSelfTest(logger)
+	selfTest.CheckRuntimeConfig(os.Getenv("RUNTIME_CONFIG_FILE"))
+	selfTest.Check("environment", func(ctx context.Context) error {
+		return checkEnvironment()
+	})
+	selfTest.CheckDatabase()
+	return selfTest.ExitCode()
+}
+
+// checkEnvironment reports the settings that main ignores when they are invalid.
+func checkEnvironment() error {
+	var invalid []string
+	for _, name := range []string{"OPERATION_RULES_REFRESH_INTERVAL", "SANDBOX_CLEARING_INTERVAL", "EOD_CLOSE_INTERVAL", "RECURRING_PAYMENTS_INTERVAL", "INSTALLMENTS_INTERVAL"} {
+		if value := os.Getenv(name); value != "" {
+			if interval, err := time.ParseDuration(value); err != nil || interval <= 0 {
+				invalid = append(invalid, fmt.Sprintf("%s=%q", name, value))
+			}
+		}
+	}
+	for _, name := range []string{"SANDBOX_CLEARING_DELAY", "EOD_CLOSE_DELAY"} {
+		if value := os.Getenv(name); value != "" {
+			if delay, err := time.ParseDuration(value); err != nil || delay < 0 {
+				invalid = append(invalid, fmt.Sprintf("%s=%q", name, value))
+			}
+		}
+	}
+	if value := os.Getenv("EXPORT_WORKERS"); value != "" {
+		if workers, err := strconv.Atoi(value); err != nil || workers <= 0 {
+			invalid = append(invalid, fmt.Sprintf("EXPORT_WORKERS=%q", value))
+		}
+	}
+	for _, name := range []string{"ACCOUNT_TRANSACTION_RATE", "ACCOUNT_TRANSACTION_BURST"} {
+		if value := os.Getenv(name); value != "" {
+			if limit, err := strconv.ParseFloat(value, 64); err != nil || limit < 0 {
+				invalid = append(invalid, fmt.Sprintf("%s=%q", name, value))
+			}
+		}
+	}
+	if value := os.Getenv("MAX_CONCURRENT_CALLS"); value != "" {
+		if limit, err := strconv.Atoi(value); err != nil || limit < 0 {
+			invalid = append(invalid, fmt.Sprintf("MAX_CONCURRENT_CALLS=%q", value))
+		}
+	}
+	if value := os.Getenv("INTERACTIVE_WEIGHT"); value != "" {
+		if weight, err := strconv.Atoi(value); err != nil || weight < 1 {
+			invalid = append(invalid, fmt.Sprintf("INTERACTIVE_WEIGHT=%q", value))
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("invalid settings: %s", strings.Join(invalid, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+)
+
+// ListAccessDecisions returns the authorization decisions recorded by the account and transaction services,
+// newest first, for access reviews. Decisions can be filtered by principal, method, outcome and time range.
+// Only admins may read the audit; reading it is itself recorded.
+func (s *Service) ListAccessDecisions(ctx context.Context, req *pb.ListAccessDecisionsRequest) (*pb.ListAccessDecisionsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.AdminOnly) {
+		logger.Warn("Rejected access decision listing: Role=%q", common.CallerRoleFromContext(ctx))
+		return &pb.ListAccessDecisionsResponse{Error: "permission denied"}, nil
+	}
+	if req.Decision != "" && req.Decision != common.AccessAllowed && req.Decision != common.AccessDenied {
+		return &pb.ListAccessDecisionsResponse{Error: "decision must be ALLOW or DENY"}, nil
+	}
+	if req.From < 0 || req.To < 0 || (req.To > 0 && req.To <= req.From) {
+		return &pb.ListAccessDecisionsResponse{Error: "invalid time range"}, nil
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 || limit > common.MaxAccessDecisionLimit {
+		limit = common.DefaultAccessDecisionLimit
+	}
+
+	start := time.Now()
+	decisions, err := common.QueryAccessDecisions(ctx, s.db, common.AccessDecisionFilter{
+		Principal: req.Principal,
+		Method:    req.Method,
+		Decision:  req.Decision,
+		From:      req.From,
+		To:        req.To,
+		BeforeID:  req.BeforeId,
+		Limit:     limit,
+	})
+	logger.LogDatabase("SELECT", "access_audit_log", time.Since(start), err)
+	if err != nil {
+		if common.IsCancellation(err) {
+			return &pb.ListAccessDecisionsResponse{Error: "request cancelled"}, nil
+		}
+		logger.Error("Access decision listing failed: %v", err)
+		return &pb.ListAccessDecisionsResponse{Error: "database error"}, nil
+	}
+
+	resp := &pb.ListAccessDecisionsResponse{Decisions: make([]*pb.AccessDecision, len(decisions))}
+	for i, d := range decisions {
+		resp.Decisions[i] = &pb.AccessDecision{
+			Id:         d.ID,
+			Service:    d.Service,
+			Method:     d.Method,
+			Principal:  d.Principal,
+			Role:       d.Role,
+			TenantId:   d.TenantID,
+			RequestId:  d.RequestID,
+			Decision:   d.Decision,
+			Reason:     d.Reason,
+			OccurredAt: d.OccurredAt,
+		}
+	}
+	if len(decisions) == limit {
+		resp.NextBeforeId = decisions[len(decisions)-1].ID
+	}
+	return resp, nil
+}
@@ -3,9 +3,15 @@ package account
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/YASHIRAI/pismo-task/internal/common"
+	"github.com/YASHIRAI/pismo-task/internal/validation"
 	pb "github.com/YASHIRAI/pismo-task/proto/account"
 	"github.com/google/uuid"
 )
@@ -14,116 +20,387 @@ import (
 // It handles account-related operations including creation, retrieval, updates, and balance management.
 type Service struct {
 	pb.UnimplementedAccountServiceServer
-	db     *sql.DB
-	logger *common.Logger
+	db      *sql.DB
+	logger  *common.Logger
+	tenants *common.TenantConfigStore
+	ledger  *common.LedgerBalanceReader
+	slo     *common.SLOTracker
+	// reportExcludedTags are the account tags whose accounts are left out of reports
+	reportExcludedTags []string
+	// runtimeConfig holds the latest runtime configuration, set by ApplyRuntimeConfig
+	runtimeConfig atomic.Pointer[common.RuntimeConfig]
 }
 
 // NewService creates a new instance of the Account service.
 // It takes a database connection and logger, and returns a configured Service instance.
+// Tenant settings are read and written for the environment named by APP_ENV, and balances derived from
+// the ledger are cached for LEDGER_BALANCE_CACHE_TTL. Accounts tagged with one of REPORT_EXCLUDED_TAGS are
+// left out of reports.
 func NewService(db *sql.DB, logger *common.Logger) *Service {
-	return &Service{db: db, logger: logger}
+	return &Service{
+		db:                 db,
+		logger:             logger,
+		tenants:            common.NewTenantConfigStoreFromEnv(db),
+		ledger:             common.NewLedgerBalanceReaderFromEnv(db),
+		reportExcludedTags: common.ReportExcludedTagsFromEnv(),
+	}
 }
 
 // CreateAccount creates a new account with the provided document number and account type.
 // It validates required fields and generates a unique UUID for the account.
-// Returns the created account or an error message if creation fails.
+// Returns the created account or an error message if creation fails. A document number that already has an
+// account gets "account already exists", with the existing account's ID when it belongs to the caller's tenant,
+// or "account closed" if that account is closed but not yet anonymized, which frees its document number.
+// The creation is recorded in account_audit.
 func (s *Service) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
-	s.logger.Info("Creating account: DocumentNumber=%s, AccountType=%s, InitialBalance=%f",
-		req.DocumentNumber, req.AccountType, req.InitialBalance)
+	logger := s.logger.WithContext(ctx)
+
+	logger.Info("Creating account: DocumentNumber=%s, AccountType=%s, InitialBalance=%s",
+		req.DocumentNumber, req.AccountType, common.Cents(req.InitialBalanceCents))
 
 	if req.DocumentNumber == "" || req.AccountType == "" {
-		s.logger.Error("Account creation failed: missing required fields")
+		logger.Error("Account creation failed: missing required fields")
 		return &pb.CreateAccountResponse{Error: "missing required fields"}, nil
 	}
+	if msg := validateHolder(req.HolderName, req.HolderEmail, req.HolderPhone); msg != "" {
+		logger.Warn("Account creation rejected: %s", msg)
+		return &pb.CreateAccountResponse{Error: msg}, nil
+	}
 
-	dbAccount := ConvertCreateAccountRequestToAccount(req)
-	dbAccount.ID = uuid.New().String()
+	documentNumber, msg := s.checkDocumentNumber(ctx, req.DocumentNumber)
+	if msg != "" {
+		logger.Warn("Account creation rejected: DocumentNumber=%s: %s", req.DocumentNumber, msg)
+		return &pb.CreateAccountResponse{Error: msg}, nil
+	}
+	req.DocumentNumber = documentNumber
 
-	start := time.Now()
-	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO accounts (id, document_number, account_type, balance, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`, dbAccount.ID, dbAccount.DocumentNumber, dbAccount.AccountType, dbAccount.Balance, dbAccount.CreatedAt, dbAccount.UpdatedAt)
-	duration := time.Since(start)
+	if msg := s.checkAccountQuota(ctx, req.DocumentNumber, req.AccountType); msg != "" {
+		logger.Warn("Account creation rejected: DocumentNumber=%s, AccountType=%s: %s", req.DocumentNumber, req.AccountType, msg)
+		return &pb.CreateAccountResponse{Error: msg}, nil
+	}
 
-	s.logger.LogDatabase("INSERT", "accounts", duration, err)
+	dbAccount := ConvertCreateAccountRequestToAccount(req)
+	dbAccount.ID = uuid.New().String()
 
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		start := time.Now()
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO accounts (id, document_number, account_type, balance, created_at, updated_at, status, opening_balance, tenant_id,
+			                      holder_name, holder_email, holder_phone)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $4, NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''))
+		`, dbAccount.ID, dbAccount.DocumentNumber, dbAccount.AccountType, dbAccount.Balance, dbAccount.CreatedAt, dbAccount.UpdatedAt, dbAccount.Status,
+			common.TenantIDFromContext(ctx), dbAccount.HolderName, dbAccount.HolderEmail, dbAccount.HolderPhone)
+		logger.LogDatabase("INSERT", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		return s.recordAccountChange(ctx, tx, dbAccount.ID, auditCreate, nil, dbAccount)
+	})
+
+	if common.IsUniqueViolation(err) {
+		existingID, existingStatus := s.existingAccount(ctx, dbAccount.DocumentNumber)
+		logger.Warn("Account creation rejected: DocumentNumber=%s already has account %s (%s)", dbAccount.DocumentNumber, existingID, existingStatus)
+		if existingStatus == "CLOSED" {
+			return &pb.CreateAccountResponse{Error: "account closed", ExistingAccountId: existingID}, nil
+		}
+		return &pb.CreateAccountResponse{Error: "account already exists", ExistingAccountId: existingID}, nil
+	}
 	if err != nil {
-		s.logger.Error("Account creation failed: %v", err)
+		logger.Error("Account creation failed: %v", err)
 		return &pb.CreateAccountResponse{Error: "could not create account"}, nil
 	}
 
-	s.logger.Info("Account created successfully: ID=%s", dbAccount.ID)
+	logger.Info("Account created successfully: ID=%s", dbAccount.ID)
 	pbAccount := ConvertAccountToProto(dbAccount)
 	return &pb.CreateAccountResponse{Account: pbAccount}, nil
 }
 
+// existingAccount returns the ID and status of the account holding documentNumber, if it belongs to the
+// caller's tenant. Accounts of other tenants are not disclosed, and a failed lookup only costs the caller the ID.
+func (s *Service) existingAccount(ctx context.Context, documentNumber string) (id, status string) {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, status FROM accounts
+		WHERE document_number = $1 AND tenant_id IS NOT DISTINCT FROM NULLIF($2, '')
+	`, documentNumber, common.TenantIDFromContext(ctx)).Scan(&id, &status)
+	logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logger.Error("Existing account lookup failed: %v", err)
+		}
+		return "", ""
+	}
+	return id, status
+}
+
+// CreateAccounts creates accounts from a client stream of account records.
+// Accounts whose document number already exists are counted as duplicates rather than failures,
+// so migration runs can be safely re-executed. Returns a summary once the client closes the stream.
+func (s *Service) CreateAccounts(stream pb.AccountService_CreateAccountsServer) error {
+	ctx := stream.Context()
+	logger := s.logger.WithContext(ctx)
+	summary := &pb.CreateAccountsResponse{}
+
+	for index := int32(0); ; index++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			logger.Info("Bulk account creation finished: Created=%d, Duplicates=%d, Failures=%d",
+				summary.Created, summary.Duplicates, len(summary.Failures))
+			return stream.SendAndClose(summary)
+		}
+		if err != nil {
+			logger.Error("Bulk account creation stream failed: %v", err)
+			return err
+		}
+
+		if req.DocumentNumber == "" || req.AccountType == "" {
+			summary.Failures = append(summary.Failures, &pb.CreateAccountsFailure{
+				Index:          index,
+				DocumentNumber: req.DocumentNumber,
+				Reason:         "missing required fields",
+			})
+			continue
+		}
+		if msg := validateHolder(req.HolderName, req.HolderEmail, req.HolderPhone); msg != "" {
+			summary.Failures = append(summary.Failures, &pb.CreateAccountsFailure{
+				Index:          index,
+				DocumentNumber: req.DocumentNumber,
+				Reason:         msg,
+			})
+			continue
+		}
+
+		documentNumber, msg := s.checkDocumentNumber(ctx, req.DocumentNumber)
+		if msg != "" {
+			summary.Failures = append(summary.Failures, &pb.CreateAccountsFailure{
+				Index:          index,
+				DocumentNumber: req.DocumentNumber,
+				Reason:         msg,
+			})
+			continue
+		}
+		req.DocumentNumber = documentNumber
+
+		if msg := s.checkAccountQuota(ctx, req.DocumentNumber, req.AccountType); msg != "" {
+			summary.Failures = append(summary.Failures, &pb.CreateAccountsFailure{
+				Index:          index,
+				DocumentNumber: req.DocumentNumber,
+				Reason:         msg,
+			})
+			continue
+		}
+
+		dbAccount := ConvertCreateAccountRequestToAccount(req)
+		dbAccount.ID = uuid.New().String()
+
+		var created bool
+		err = common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+			start := time.Now()
+			result, err := tx.ExecContext(ctx, `
+				INSERT INTO accounts (id, document_number, account_type, balance, created_at, updated_at, status, opening_balance, tenant_id,
+				                      holder_name, holder_email, holder_phone)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $4, NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''))
+				ON CONFLICT (document_number) DO NOTHING
+			`, dbAccount.ID, dbAccount.DocumentNumber, dbAccount.AccountType, dbAccount.Balance, dbAccount.CreatedAt, dbAccount.UpdatedAt, dbAccount.Status,
+				common.TenantIDFromContext(ctx), dbAccount.HolderName, dbAccount.HolderEmail, dbAccount.HolderPhone)
+			logger.LogDatabase("INSERT", "accounts", time.Since(start), err)
+			if err != nil {
+				return err
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil || rowsAffected == 0 {
+				return err
+			}
+			created = true
+			return s.recordAccountChange(ctx, tx, dbAccount.ID, auditCreate, nil, dbAccount)
+		})
+
+		if err != nil {
+			logger.Error("Bulk account creation failed: DocumentNumber=%s: %v", req.DocumentNumber, err)
+			summary.Failures = append(summary.Failures, &pb.CreateAccountsFailure{
+				Index:          index,
+				DocumentNumber: req.DocumentNumber,
+				Reason:         "could not create account",
+			})
+			continue
+		}
+
+		if !created {
+			summary.Duplicates++
+			continue
+		}
+		summary.Created++
+	}
+}
+
 // GetAccount retrieves an account by its ID.
 // Returns the account details or an error if the account is not found.
+// A ledger-derived balance may come from the cache if the caller set max_staleness_ms.
 func (s *Service) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
-	s.logger.Debug("Getting account: ID=%s", req.Id)
+	logger := s.logger.WithContext(ctx)
+
+	logger.Debug("Getting account: ID=%s", req.Id)
 
 	if req.Id == "" {
-		s.logger.Error("Get account failed: ID required")
+		logger.Error("Get account failed: ID required")
 		return &pb.GetAccountResponse{Error: "id required"}, nil
 	}
 
-	var dbAccount common.Account
+	dbAccount, msg := s.lookupAccount(ctx, "id", req.Id, req.MaxStalenessMs)
+	if msg != "" {
+		return &pb.GetAccountResponse{Error: msg}, nil
+	}
+
+	logger.Debug("Account retrieved successfully: ID=%s", dbAccount.ID)
+	pbAccount := ConvertAccountToProto(dbAccount)
+	return &pb.GetAccountResponse{Account: pbAccount}, nil
+}
+
+// GetAccountByDocument retrieves the account of a document number, for clients that know the customer's
+// document rather than the account ID. Document numbers are unique, so there is at most one.
+// Returns the same account as GetAccount, or "not found".
+func (s *Service) GetAccountByDocument(ctx context.Context, req *pb.GetAccountByDocumentRequest) (*pb.GetAccountByDocumentResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	documentNumber := strings.TrimSpace(req.DocumentNumber)
+	logger.Debug("Getting account by document: DocumentNumber=%s", common.MaskDocumentNumber(documentNumber))
+
+	if documentNumber == "" {
+		logger.Error("Get account by document failed: document number required")
+		return &pb.GetAccountByDocumentResponse{Error: "document_number required"}, nil
+	}
+
+	dbAccount, msg := s.lookupAccount(ctx, "document_number", documentNumber, req.MaxStalenessMs)
+	if msg != "" {
+		return &pb.GetAccountByDocumentResponse{Error: msg}, nil
+	}
+
+	logger.Debug("Account retrieved successfully: ID=%s", dbAccount.ID)
+	return &pb.GetAccountByDocumentResponse{Account: ConvertAccountToProto(dbAccount)}, nil
+}
+
+// lookupAccount reads the account whose column equals value, with its ledger balance for tenants that use one.
+// On failure it returns the error message of the response instead.
+func (s *Service) lookupAccount(ctx context.Context, column, value string, maxStalenessMs int64) (*common.Account, string) {
+	logger := s.logger.WithContext(ctx)
+
 	start := time.Now()
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, document_number, account_type, balance, created_at, updated_at
-		FROM accounts WHERE id = $1
-	`, req.Id).Scan(&dbAccount.ID, &dbAccount.DocumentNumber, &dbAccount.AccountType, &dbAccount.Balance, &dbAccount.CreatedAt, &dbAccount.UpdatedAt)
+	dbAccount, err := scanAccount(s.db.QueryRowContext(ctx, `
+		SELECT `+accountColumns+`
+		FROM accounts WHERE `+column+` = $1
+	`, value))
 	duration := time.Since(start)
 
-	s.logger.LogDatabase("SELECT", "accounts", duration, err)
+	logger.LogDatabase("SELECT", "accounts", duration, err)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			s.logger.Warn("Account not found: ID=%s", req.Id)
-			return &pb.GetAccountResponse{Error: "not found"}, nil
+			if column == "document_number" {
+				value = common.MaskDocumentNumber(value)
+			}
+			logger.Warn("Account not found: %s=%s", column, value)
+			return nil, "not found"
 		}
-		s.logger.Error("Account lookup failed: %v", err)
-		return &pb.GetAccountResponse{Error: "database error"}, nil
+		logger.Error("Account lookup failed: %v", err)
+		return nil, "database error"
 	}
 
-	s.logger.Debug("Account retrieved successfully: ID=%s", dbAccount.ID)
-	pbAccount := ConvertAccountToProto(&dbAccount)
-	return &pb.GetAccountResponse{Account: pbAccount}, nil
+	settings, msg := s.callerTenantSettings(ctx)
+	if msg != "" {
+		return nil, msg
+	}
+	if settings.UsesLedgerBalance() {
+		if dbAccount.Balance, err = s.ledgerBalance(ctx, dbAccount.ID, maxStalenessMs); err != nil {
+			logger.Error("Ledger balance lookup failed: ID=%s, Error=%v", dbAccount.ID, err)
+			return nil, "database error"
+		}
+	}
+	return dbAccount, ""
 }
 
-// UpdateAccount updates an existing account's document number and/or account type.
-// Only non-empty fields are updated, preserving existing values for empty fields.
-// Returns the updated account or an error if the update fails.
+// UpdateAccount updates an existing account's account type and holder contact details.
+// Only non-empty fields are updated, preserving existing values for empty fields. A document number, when
+// given, must match the current one: changing it requires a verified RequestDocumentChange.
+// When expected_version is set the update is only applied if the account is still at that version,
+// so clients replacing the whole account do not overwrite a concurrent change.
+// Returns the updated account or an error if the update fails. The update is recorded in account_audit.
 func (s *Service) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.UpdateAccountResponse, error) {
-	s.logger.Info("Updating account: ID=%s", req.Id)
+	logger := s.logger.WithContext(ctx)
+
+	logger.Info("Updating account: ID=%s, ExpectedVersion=%d", req.Id, req.ExpectedVersion)
 
 	if req.Id == "" {
-		s.logger.Error("Update account failed: ID required")
+		logger.Error("Update account failed: ID required")
 		return &pb.UpdateAccountResponse{Error: "id required"}, nil
 	}
+	if req.ExpectedVersion < 0 {
+		return &pb.UpdateAccountResponse{Error: "expected_version must not be negative"}, nil
+	}
+	if msg := validateHolder(req.HolderName, req.HolderEmail, req.HolderPhone); msg != "" {
+		return &pb.UpdateAccountResponse{Error: msg}, nil
+	}
 
-	start := time.Now()
-	_, err := s.db.ExecContext(ctx, `
-		UPDATE accounts
-		SET document_number = COALESCE(NULLIF($2, ''), document_number),
-		    account_type    = COALESCE(NULLIF($3, ''), account_type),
-		    updated_at      = $4
-		WHERE id = $1
-	`, req.Id, req.DocumentNumber, req.AccountType, common.GetCurrentTimestamp())
-	duration := time.Since(start)
-
-	s.logger.LogDatabase("UPDATE", "accounts", duration, err)
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		account, err := s.lockAccount(ctx, tx, req.Id)
+		if errors.Is(err, onboardingError("account not found")) {
+			return onboardingError("not found")
+		}
+		if err != nil {
+			return err
+		}
+		if req.ExpectedVersion != 0 && account.Version != req.ExpectedVersion {
+			logger.Warn("Account update rejected: ID=%s, ExpectedVersion=%d, Version=%d", req.Id, req.ExpectedVersion, account.Version)
+			return onboardingError("version conflict")
+		}
+		if req.DocumentNumber != "" && req.DocumentNumber != account.DocumentNumber {
+			logger.Warn("Account update rejected: ID=%s, document number differs", req.Id)
+			return onboardingError("document_number changes require verification")
+		}
 
-	if err != nil {
-		s.logger.Error("Account update failed: %v", err)
+		previous := *account
+		if req.AccountType != "" {
+			account.AccountType = req.AccountType
+		}
+		if req.HolderName != "" {
+			account.HolderName = req.HolderName
+		}
+		if req.HolderEmail != "" {
+			account.HolderEmail = req.HolderEmail
+		}
+		if req.HolderPhone != "" {
+			account.HolderPhone = req.HolderPhone
+		}
+		account.UpdatedAt = common.GetCurrentTimestamp()
+
+		start := time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE accounts
+			SET account_type = $2, holder_name = NULLIF($3, ''), holder_email = NULLIF($4, ''), holder_phone = NULLIF($5, ''),
+			    updated_at = $6, version = version + 1
+			WHERE id = $1
+		`, account.ID, account.AccountType, account.HolderName, account.HolderEmail, account.HolderPhone, account.UpdatedAt)
+		logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		return s.recordAccountChange(ctx, tx, account.ID, auditUpdate, &previous, account)
+	})
+	var onboardingErr onboardingError
+	switch {
+	case errors.As(err, &onboardingErr):
+		return &pb.UpdateAccountResponse{Error: onboardingErr.Error()}, nil
+	case err != nil:
+		logger.Error("Account update failed: %v", err)
 		return &pb.UpdateAccountResponse{Error: "could not update account"}, nil
 	}
 
-	s.logger.Info("Account updated successfully: ID=%s", req.Id)
+	logger.Info("Account updated successfully: ID=%s", req.Id)
 	resp, err := s.GetAccount(ctx, &pb.GetAccountRequest{Id: req.Id})
 	if err != nil {
-		s.logger.Error("Could not retrieve updated account: %v", err)
+		logger.Error("Could not retrieve updated account: %v", err)
 		return &pb.UpdateAccountResponse{Error: "could not retrieve updated account"}, nil
 	}
 
@@ -132,56 +409,399 @@ func (s *Service) UpdateAccount(ctx context.Context, req *pb.UpdateAccountReques
 
 // DeleteAccount removes an account from the database by its ID.
 // Returns success status or an error if the account is not found or deletion fails.
+// For tenants with retention settings the account is closed instead, keeping it and its transactions
+// until the retention worker purges them or anonymizes the holder data; closing an account that is
+// already closed gets "account already closed". Deletions and closures are recorded in account_audit, and a
+// snapshot of the account is taken first, whose ID is returned for RestoreAccountSnapshot.
 func (s *Service) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
 	if req.Id == "" {
 		return &pb.DeleteAccountResponse{Error: "id required"}, nil
 	}
 
-	start := time.Now()
-	result, err := s.db.ExecContext(ctx, `DELETE FROM accounts WHERE id = $1`, req.Id)
-	duration := time.Since(start)
-
-	s.logger.LogDatabase("DELETE", "accounts", duration, err)
-
-	if err != nil {
-		s.logger.Error("Account deletion failed: %v", err)
-		return &pb.DeleteAccountResponse{Error: "could not delete account"}, nil
+	settings, msg := s.callerTenantSettings(ctx)
+	if msg != "" {
+		return &pb.DeleteAccountResponse{Error: msg}, nil
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return &pb.DeleteAccountResponse{Error: "could not determine deletion result"}, nil
-	}
+	var snapshotID string
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		account, err := s.lockAccount(ctx, tx, req.Id)
+		if err != nil {
+			return err
+		}
+
+		if !settings.RetainsClosedAccounts() {
+			if snapshotID, err = s.snapshotAccount(ctx, tx, account.ID, snapshotDelete); err != nil {
+				return err
+			}
+			start := time.Now()
+			_, err = tx.ExecContext(ctx, `DELETE FROM accounts WHERE id = $1`, req.Id)
+			logger.LogDatabase("DELETE", "accounts", time.Since(start), err)
+			if err != nil {
+				return err
+			}
+			return s.recordAccountChange(ctx, tx, account.ID, auditDelete, account, nil)
+		}
 
-	if rowsAffected == 0 {
-		return &pb.DeleteAccountResponse{Error: "account not found"}, nil
+		if account.Status == "CLOSED" {
+			return onboardingError("account already closed")
+		}
+		if snapshotID, err = s.snapshotAccount(ctx, tx, account.ID, snapshotClose); err != nil {
+			return err
+		}
+		previous := *account
+		account.Status = "CLOSED"
+		account.UpdatedAt = common.GetCurrentTimestamp()
+		account.Version++
+
+		start := time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE accounts
+			SET status = 'CLOSED', closed_at = $2, updated_at = $2, version = version + 1
+			WHERE id = $1
+		`, req.Id, account.UpdatedAt)
+		logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		return s.recordAccountChange(ctx, tx, account.ID, auditDelete, &previous, account)
+	})
+	var onboardingErr onboardingError
+	switch {
+	case errors.As(err, &onboardingErr):
+		return &pb.DeleteAccountResponse{Error: onboardingErr.Error()}, nil
+	case err != nil:
+		logger.Error("Account deletion failed: %v", err)
+		return &pb.DeleteAccountResponse{Error: "could not delete account"}, nil
 	}
 
-	return &pb.DeleteAccountResponse{Success: true}, nil
+	return &pb.DeleteAccountResponse{Success: true, SnapshotId: snapshotID}, nil
 }
 
 // GetBalance retrieves the current balance of an account by its ID.
 // Returns the balance amount or an error if the account is not found.
+// A ledger-derived balance may come from the cache if the caller set max_staleness_ms.
 func (s *Service) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
 	if req.AccountId == "" {
 		return &pb.GetBalanceResponse{Error: "account_id required"}, nil
 	}
 
-	var balance float64
-	start := time.Now()
-	err := s.db.QueryRowContext(ctx, `SELECT balance FROM accounts WHERE id = $1`, req.AccountId).Scan(&balance)
-	duration := time.Since(start)
+	settings, msg := s.callerTenantSettings(ctx)
+	if msg != "" {
+		return &pb.GetBalanceResponse{Error: msg}, nil
+	}
+
+	var balance common.Cents
+	var err error
+	if settings.UsesLedgerBalance() {
+		balance, err = s.ledgerBalance(ctx, req.AccountId, req.MaxStalenessMs)
+	} else {
+		start := time.Now()
+		err = s.db.QueryRowContext(ctx, `SELECT balance FROM accounts WHERE id = $1`, req.AccountId).Scan(&balance)
+		duration := time.Since(start)
 
-	s.logger.LogDatabase("SELECT", "accounts", duration, err)
+		logger.LogDatabase("SELECT", "accounts", duration, err)
+	}
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			s.logger.Warn("Account not found for balance lookup: ID=%s", req.AccountId)
+			logger.Warn("Account not found for balance lookup: ID=%s", req.AccountId)
 			return &pb.GetBalanceResponse{Error: "account not found"}, nil
 		}
-		s.logger.Error("Balance lookup failed: %v", err)
+		logger.Error("Balance lookup failed: %v", err)
 		return &pb.GetBalanceResponse{Error: "database error"}, nil
 	}
 
-	return &pb.GetBalanceResponse{Balance: balance}, nil
+	return &pb.GetBalanceResponse{BalanceCents: int64(balance)}, nil
+}
+
+// GetBalances returns the balances of an account per currency, with the amounts held and available,
+// and the credit availability of credit accounts. Accounts hold a single balance for now, reported in
+// the currency of the caller's tenant, and read from the ledger when the tenant's balance_source is LEDGER.
+// No holds are placed yet, so the whole balance is available. As for GetBalance, max_staleness_ms lets
+// a ledger-derived balance come from the cache.
+func (s *Service) GetBalances(ctx context.Context, req *pb.GetBalancesRequest) (*pb.GetBalancesResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" {
+		return &pb.GetBalancesResponse{Error: "account_id required"}, nil
+	}
+
+	settings, msg := s.callerTenantSettings(ctx)
+	if msg != "" {
+		return &pb.GetBalancesResponse{Error: msg}, nil
+	}
+
+	var accountType string
+	var balance common.Cents
+	start := time.Now()
+	err := s.db.QueryRowContext(ctx, `SELECT account_type, balance FROM accounts WHERE id = $1`, req.AccountId).Scan(&accountType, &balance)
+	logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Warn("Account not found for balances lookup: ID=%s", req.AccountId)
+			return &pb.GetBalancesResponse{Error: "account not found"}, nil
+		}
+		logger.Error("Balances lookup failed: %v", err)
+		return &pb.GetBalancesResponse{Error: "database error"}, nil
+	}
+	if settings.UsesLedgerBalance() {
+		if balance, err = s.ledgerBalance(ctx, req.AccountId, req.MaxStalenessMs); err != nil {
+			logger.Error("Ledger balance lookup failed: ID=%s, Error=%v", req.AccountId, err)
+			return &pb.GetBalancesResponse{Error: "database error"}, nil
+		}
+	}
+
+	var held common.Cents
+	response := &pb.GetBalancesResponse{
+		AccountId: req.AccountId,
+		Balances: []*pb.CurrencyBalance{{
+			Currency:       settings.Currency,
+			BalanceCents:   int64(balance),
+			HeldCents:      int64(held),
+			AvailableCents: int64(balance - held),
+		}},
+	}
+	if accountType == "CREDIT" {
+		// Transactions may not take the balance below zero and there are no credit lines yet,
+		// so the credit available is the positive part of the available balance.
+		response.Credit = &pb.CreditAvailability{AvailableCents: int64(max(balance-held, 0))}
+	}
+	return response, nil
+}
+
+// minSearchQueryLength is the shortest partial document number accepted by SearchAccounts.
+// Shorter fragments match too many accounts and cannot use the trigram index.
+const minSearchQueryLength = 3
+
+// SearchAccounts finds accounts whose document number starts or ends with the given fragment.
+// Document numbers in the results are masked unless the caller role is allowed to see them in full.
+// Returns at most limit accounts (default 20, max 50) ordered by document number.
+func (s *Service) SearchAccounts(ctx context.Context, req *pb.SearchAccountsRequest) (*pb.SearchAccountsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	role := common.CallerRoleFromContext(ctx)
+	logger.Info("Searching accounts: Match=%s, QueryLength=%d, Role=%s", req.Match, len(req.Query), role)
+
+	query := strings.TrimSpace(req.Query)
+	if len(query) < minSearchQueryLength {
+		return &pb.SearchAccountsResponse{Error: "query must be at least 3 characters"}, nil
+	}
+
+	var pattern string
+	switch req.Match {
+	case "", "prefix":
+		pattern = common.EscapeLikePattern(query) + "%"
+	case "suffix":
+		pattern = "%" + common.EscapeLikePattern(query)
+	default:
+		return &pb.SearchAccountsResponse{Error: "match must be prefix or suffix"}, nil
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, document_number, account_type, balance, created_at, updated_at, status
+		FROM accounts
+		WHERE document_number LIKE $1
+		ORDER BY document_number
+		LIMIT $2
+	`, pattern, limit)
+	duration := time.Since(start)
+
+	logger.LogDatabase("SELECT", "accounts", duration, err)
+	if err != nil {
+		logger.Error("Account search failed: %v", err)
+		return &pb.SearchAccountsResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	showFull := common.CanViewFullDocumentNumber(role)
+	var accounts []*pb.Account
+	for rows.Next() {
+		var dbAccount common.Account
+		if err := rows.Scan(&dbAccount.ID, &dbAccount.DocumentNumber, &dbAccount.AccountType, &dbAccount.Balance, &dbAccount.CreatedAt, &dbAccount.UpdatedAt, &dbAccount.Status); err != nil {
+			logger.Error("Row scan failed: %v", err)
+			continue
+		}
+		if !showFull {
+			dbAccount.DocumentNumber = common.MaskDocumentNumber(dbAccount.DocumentNumber)
+		}
+		accounts = append(accounts, ConvertAccountToProto(&dbAccount))
+	}
+
+	return &pb.SearchAccountsResponse{Accounts: accounts}, nil
+}
+
+// ListAccounts returns a page of accounts, newest first, optionally narrowed to a creation time range,
+// a balance range and tags, e.g. to pull the VIP accounts opened this week with a balance over some amount.
+// Document numbers are masked unless the caller role is allowed to see them in full.
+// Returns at most limit accounts (default 50, max 100) and the number of accounts matching the filters.
+func (s *Service) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.CreatedFrom < 0 || req.CreatedTo < 0 {
+		return &pb.ListAccountsResponse{Error: "created_from and created_to must not be negative"}, nil
+	}
+	if req.CreatedFrom > 0 && req.CreatedTo > 0 && req.CreatedFrom >= req.CreatedTo {
+		return &pb.ListAccountsResponse{Error: "created_from must be before created_to"}, nil
+	}
+	if req.MinBalanceCents != nil && req.MaxBalanceCents != nil && *req.MinBalanceCents > *req.MaxBalanceCents {
+		return &pb.ListAccountsResponse{Error: "min_balance must not exceed max_balance"}, nil
+	}
+	tags, msg := normalizeAccountTags(req.Tags)
+	if msg != "" {
+		return &pb.ListAccountsResponse{Error: msg}, nil
+	}
+	excludeTags, msg := normalizeAccountTags(req.ExcludeTags)
+	if msg != "" {
+		return &pb.ListAccountsResponse{Error: msg}, nil
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	offset := req.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if req.CreatedFrom > 0 {
+		addCondition("created_at >= $%d", req.CreatedFrom)
+	}
+	if req.CreatedTo > 0 {
+		addCondition("created_at < $%d", req.CreatedTo)
+	}
+	if req.MinBalanceCents != nil {
+		addCondition("balance >= $%d", common.Cents(*req.MinBalanceCents))
+	}
+	if req.MaxBalanceCents != nil {
+		addCondition("balance <= $%d", common.Cents(*req.MaxBalanceCents))
+	}
+	if len(tags) > 0 {
+		addCondition("string_to_array(tags, ',') @> string_to_array($%d, ',')", strings.Join(tags, ","))
+	}
+	if len(excludeTags) > 0 {
+		addCondition("NOT (string_to_array(tags, ',') && string_to_array($%d, ','))", strings.Join(excludeTags, ","))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	role := common.CallerRoleFromContext(ctx)
+	logger.Info("Listing accounts: Filters=%d, Limit=%d, Offset=%d, Role=%s", len(conditions), limit, offset, role)
+
+	var total int32
+	start := time.Now()
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM accounts `+where, args...).Scan(&total)
+	logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+	if err != nil {
+		logger.Error("Account count failed: %v", err)
+		return &pb.ListAccountsResponse{Error: "database error"}, nil
+	}
+
+	start = time.Now()
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, document_number, account_type, balance, created_at, updated_at, status, tags
+		FROM accounts
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2), append(args, limit, offset)...)
+	logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+	if err != nil {
+		logger.Error("Account listing failed: %v", err)
+		return &pb.ListAccountsResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	showFull := common.CanViewFullDocumentNumber(role)
+	var accounts []*pb.Account
+	for rows.Next() {
+		var dbAccount common.Account
+		var accountTags string
+		if err := rows.Scan(&dbAccount.ID, &dbAccount.DocumentNumber, &dbAccount.AccountType, &dbAccount.Balance, &dbAccount.CreatedAt, &dbAccount.UpdatedAt, &dbAccount.Status, &accountTags); err != nil {
+			logger.Error("Row scan failed: %v", err)
+			continue
+		}
+		dbAccount.Tags = common.ParseAccountTags(accountTags)
+		if !showFull {
+			dbAccount.DocumentNumber = common.MaskDocumentNumber(dbAccount.DocumentNumber)
+		}
+		accounts = append(accounts, ConvertAccountToProto(&dbAccount))
+	}
+
+	return &pb.ListAccountsResponse{Accounts: accounts, Total: total}, nil
+}
+
+// GetTenantSettings returns the settings of a tenant in the service's environment.
+// Tenants without stored settings get empty settings, meaning the platform defaults apply.
+func (s *Service) GetTenantSettings(ctx context.Context, req *pb.GetTenantSettingsRequest) (*pb.GetTenantSettingsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.ValidTenantID(req.TenantId) {
+		return &pb.GetTenantSettingsResponse{Error: "invalid tenant id"}, nil
+	}
+
+	settings, err := s.tenants.Get(ctx, req.TenantId)
+	if err != nil {
+		logger.Error("Tenant settings lookup failed: TenantID=%s, Error=%v", req.TenantId, err)
+		return &pb.GetTenantSettingsResponse{Error: "database error"}, nil
+	}
+
+	return &pb.GetTenantSettingsResponse{
+		Settings:    ConvertTenantSettingsToProto(settings),
+		Environment: s.tenants.Environment(),
+	}, nil
+}
+
+// UpdateTenantSettings replaces the settings of a tenant in the service's environment.
+// Only admins may change settings. Other service instances apply the change once their cached copy expires.
+func (s *Service) UpdateTenantSettings(ctx context.Context, req *pb.UpdateTenantSettingsRequest) (*pb.UpdateTenantSettingsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.AdminOnly) {
+		logger.Warn("Rejected tenant settings update: TenantID=%s, caller is not an admin", req.TenantId)
+		return &pb.UpdateTenantSettingsResponse{Error: "permission denied"}, nil
+	}
+	if !common.ValidTenantID(req.TenantId) {
+		return &pb.UpdateTenantSettingsResponse{Error: "invalid tenant id"}, nil
+	}
+
+	settings := ConvertTenantSettingsFromProto(req.Settings)
+	if err := settings.Validate(); err != nil {
+		return &pb.UpdateTenantSettingsResponse{Error: err.Error()}, nil
+	}
+	for _, documentType := range settings.DocumentTypes {
+		if _, ok := validation.Lookup(documentType); !ok {
+			return &pb.UpdateTenantSettingsResponse{Error: "unknown document type: " + documentType}, nil
+		}
+	}
+
+	if err := s.tenants.Put(ctx, req.TenantId, settings); err != nil {
+		logger.Error("Tenant settings update failed: TenantID=%s, Error=%v", req.TenantId, err)
+		return &pb.UpdateTenantSettingsResponse{Error: "database error"}, nil
+	}
+
+	logger.Info("Tenant settings updated: TenantID=%s, Environment=%s", req.TenantId, s.tenants.Environment())
+	return &pb.UpdateTenantSettingsResponse{
+		Settings:    ConvertTenantSettingsToProto(settings),
+		Environment: s.tenants.Environment(),
+	}, nil
 }
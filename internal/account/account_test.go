@@ -3,13 +3,20 @@ package account
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/YASHIRAI/pismo-task/internal/common"
 	pb "github.com/YASHIRAI/pismo-task/proto/account"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 func TestNewService(t *testing.T) {
@@ -23,6 +30,19 @@ func TestNewService(t *testing.T) {
 	assert.Equal(t, db, service.db)
 }
 
+// expectAccountAudit expects a change to an account to be recorded in account_audit.
+func expectAccountAudit(mock sqlmock.Sqlmock, action string) {
+	mock.ExpectExec(`INSERT INTO account_audit \(id, account_id, action, actor, actor_role, changed_at, previous, updated\)`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), action, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func expectAccountSnapshot(mock sqlmock.Sqlmock, accountID, operation string) {
+	mock.ExpectExec(`INSERT INTO account_snapshots \(id, account_id, operation, taken_by, taken_by_role, taken_at, account, transactions\)`).
+		WithArgs(sqlmock.AnyArg(), accountID, operation, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), snapshotTransactionLimit).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
 func TestService_CreateAccount(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -34,30 +54,33 @@ func TestService_CreateAccount(t *testing.T) {
 		{
 			name: "successful account creation",
 			request: &pb.CreateAccountRequest{
-				DocumentNumber: "12345678901",
-				AccountType:    "CHECKING",
-				InitialBalance: 100.50,
+				DocumentNumber:      "12345678901",
+				AccountType:         "CHECKING",
+				InitialBalanceCents: 10050,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
 				mock.ExpectExec(`INSERT INTO accounts`).
-					WithArgs(sqlmock.AnyArg(), "12345678901", "CHECKING", 100.50, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WithArgs(sqlmock.AnyArg(), "12345678901", "CHECKING", "100.50", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "", "", "", "").
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectAccountAudit(mock, auditCreate)
+				mock.ExpectCommit()
 			},
 			expectedError: "",
 			expectedResult: &pb.CreateAccountResponse{
 				Account: &pb.Account{
 					DocumentNumber: "12345678901",
 					AccountType:    "CHECKING",
-					Balance:        100.50,
+					BalanceCents:   10050,
 				},
 			},
 		},
 		{
 			name: "missing document number",
 			request: &pb.CreateAccountRequest{
-				DocumentNumber: "",
-				AccountType:    "CHECKING",
-				InitialBalance: 100.50,
+				DocumentNumber:      "",
+				AccountType:         "CHECKING",
+				InitialBalanceCents: 10050,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// No database call expected
@@ -70,9 +93,9 @@ func TestService_CreateAccount(t *testing.T) {
 		{
 			name: "missing account type",
 			request: &pb.CreateAccountRequest{
-				DocumentNumber: "12345678901",
-				AccountType:    "",
-				InitialBalance: 100.50,
+				DocumentNumber:      "12345678901",
+				AccountType:         "",
+				InitialBalanceCents: 10050,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// No database call expected
@@ -85,20 +108,85 @@ func TestService_CreateAccount(t *testing.T) {
 		{
 			name: "database error",
 			request: &pb.CreateAccountRequest{
-				DocumentNumber: "12345678901",
-				AccountType:    "CHECKING",
-				InitialBalance: 100.50,
+				DocumentNumber:      "12345678901",
+				AccountType:         "CHECKING",
+				InitialBalanceCents: 10050,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
 				mock.ExpectExec(`INSERT INTO accounts`).
-					WithArgs(sqlmock.AnyArg(), "12345678901", "CHECKING", 100.50, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WithArgs(sqlmock.AnyArg(), "12345678901", "CHECKING", "100.50", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "", "", "", "").
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			expectedError: "could not create account",
 			expectedResult: &pb.CreateAccountResponse{
 				Error: "could not create account",
 			},
 		},
+		{
+			name: "duplicate document number",
+			request: &pb.CreateAccountRequest{
+				DocumentNumber: "12345678901",
+				AccountType:    "CHECKING",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO accounts`).
+					WithArgs(sqlmock.AnyArg(), "12345678901", "CHECKING", "0.00", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "", "", "", "").
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "accounts_document_number_key"})
+				mock.ExpectRollback()
+				mock.ExpectQuery(`SELECT id, status FROM accounts\s+WHERE document_number = \$1 AND tenant_id IS NOT DISTINCT FROM NULLIF\(\$2, ''\)`).
+					WithArgs("12345678901", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow("existing-account-id", "ACTIVE"))
+			},
+			expectedError: "account already exists",
+			expectedResult: &pb.CreateAccountResponse{
+				Error:             "account already exists",
+				ExistingAccountId: "existing-account-id",
+			},
+		},
+		{
+			name: "document number of a closed account",
+			request: &pb.CreateAccountRequest{
+				DocumentNumber: "12345678901",
+				AccountType:    "CHECKING",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO accounts`).
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "accounts_document_number_key"})
+				mock.ExpectRollback()
+				mock.ExpectQuery(`SELECT id, status FROM accounts`).
+					WithArgs("12345678901", "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow("closed-account-id", "CLOSED"))
+			},
+			expectedError: "account closed",
+			expectedResult: &pb.CreateAccountResponse{
+				Error:             "account closed",
+				ExistingAccountId: "closed-account-id",
+			},
+		},
+		{
+			name: "duplicate document number of another tenant",
+			request: &pb.CreateAccountRequest{
+				DocumentNumber: "12345678901",
+				AccountType:    "CHECKING",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO accounts`).
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "accounts_document_number_key"})
+				mock.ExpectRollback()
+				mock.ExpectQuery(`SELECT id, status FROM accounts`).
+					WithArgs("12345678901", "").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: "account already exists",
+			expectedResult: &pb.CreateAccountResponse{
+				Error: "account already exists",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -115,11 +203,12 @@ func TestService_CreateAccount(t *testing.T) {
 
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedError, response.Error)
+			assert.Equal(t, tt.expectedResult.ExistingAccountId, response.ExistingAccountId)
 			if tt.expectedError == "" {
 				assert.NotEmpty(t, response.Account.Id)
 				assert.Equal(t, tt.request.DocumentNumber, response.Account.DocumentNumber)
 				assert.Equal(t, tt.request.AccountType, response.Account.AccountType)
-				assert.Equal(t, tt.request.InitialBalance, response.Account.Balance)
+				assert.Equal(t, tt.request.InitialBalanceCents, response.Account.BalanceCents)
 			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -141,8 +230,8 @@ func TestService_GetAccount(t *testing.T) {
 				Id: "test-account-id",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at"}).
-					AddRow("test-account-id", "12345678901", "CHECKING", 100.50, 1234567890, 1234567890)
+				rows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "holder_name", "holder_email", "kyc_reference", "version", "overdraft_limit", "holder_phone", "tags"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 100.50, 1234567890, 1234567890, "ACTIVE", "", "", "", 1, 0.0, "", "")
 				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
 					WithArgs("test-account-id").
 					WillReturnRows(rows)
@@ -153,9 +242,11 @@ func TestService_GetAccount(t *testing.T) {
 					Id:             "test-account-id",
 					DocumentNumber: "12345678901",
 					AccountType:    "CHECKING",
-					Balance:        100.50,
+					BalanceCents:   10050,
 					CreatedAt:      1234567890,
 					UpdatedAt:      1234567890,
+					Status:         "ACTIVE",
+					Version:        1,
 				},
 			},
 		},
@@ -222,7 +313,69 @@ func TestService_GetAccount(t *testing.T) {
 				assert.Equal(t, tt.expectedResult.Account.Id, response.Account.Id)
 				assert.Equal(t, tt.expectedResult.Account.DocumentNumber, response.Account.DocumentNumber)
 				assert.Equal(t, tt.expectedResult.Account.AccountType, response.Account.AccountType)
-				assert.Equal(t, tt.expectedResult.Account.Balance, response.Account.Balance)
+				assert.Equal(t, tt.expectedResult.Account.BalanceCents, response.Account.BalanceCents)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_GetAccountByDocument(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       *pb.GetAccountByDocumentRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name:    "successful lookup",
+			request: &pb.GetAccountByDocumentRequest{DocumentNumber: " 12345678901 "},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "holder_name", "holder_email", "kyc_reference", "version", "overdraft_limit", "holder_phone", "tags"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 100.50, 1234567890, 1234567890, "ACTIVE", "", "", "", 3, 0.0, "", "")
+				mock.ExpectQuery(`FROM accounts WHERE document_number = \$1`).
+					WithArgs("12345678901").
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name:          "missing document number",
+			request:       &pb.GetAccountByDocumentRequest{DocumentNumber: "  "},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "document_number required",
+		},
+		{
+			name:    "account not found",
+			request: &pb.GetAccountByDocumentRequest{DocumentNumber: "99999999999"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM accounts WHERE document_number = \$1`).
+					WithArgs("99999999999").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.GetAccountByDocument(context.Background(), tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				assert.Equal(t, "test-account-id", response.Account.Id)
+				assert.Equal(t, "12345678901", response.Account.DocumentNumber)
+				assert.Equal(t, int64(10050), response.Account.BalanceCents)
+				assert.Equal(t, int64(3), response.Account.Version)
 			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -231,6 +384,13 @@ func TestService_GetAccount(t *testing.T) {
 }
 
 func TestService_UpdateAccount(t *testing.T) {
+	lockedAccount := func(mock sqlmock.Sqlmock, id string, version int64) {
+		mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+				AddRow(id, "98765432109", "CHECKING", 100.50, 1234567890, 1234567890, "ACTIVE", "", "", "", version, 0.0, "", ""))
+	}
+
 	tests := []struct {
 		name          string
 		request       *pb.UpdateAccountRequest
@@ -245,13 +405,17 @@ func TestService_UpdateAccount(t *testing.T) {
 				AccountType:    "SAVINGS",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				lockedAccount(mock, "test-account-id", 1)
 				mock.ExpectExec(`UPDATE accounts`).
-					WithArgs("test-account-id", "98765432109", "SAVINGS", sqlmock.AnyArg()).
+					WithArgs("test-account-id", "SAVINGS", "", "", "", sqlmock.AnyArg()).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectAccountAudit(mock, auditUpdate)
+				mock.ExpectCommit()
 
 				// Mock the GetAccount call that happens after update
-				rows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at"}).
-					AddRow("test-account-id", "98765432109", "SAVINGS", 100.50, 1234567890, 1234567890)
+				rows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "holder_name", "holder_email", "kyc_reference", "version", "overdraft_limit", "holder_phone", "tags"}).
+					AddRow("test-account-id", "98765432109", "SAVINGS", 100.50, 1234567890, 1234567890, "ACTIVE", "", "", "", 2, 0.0, "", "")
 				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
 					WithArgs("test-account-id").
 					WillReturnRows(rows)
@@ -276,12 +440,99 @@ func TestService_UpdateAccount(t *testing.T) {
 				AccountType:    "SAVINGS",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				lockedAccount(mock, "test-account-id", 1)
 				mock.ExpectExec(`UPDATE accounts`).
-					WithArgs("test-account-id", "98765432109", "SAVINGS", sqlmock.AnyArg()).
+					WithArgs("test-account-id", "SAVINGS", "", "", "", sqlmock.AnyArg()).
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			expectedError: "could not update account",
 		},
+		{
+			name: "account not found",
+			request: &pb.UpdateAccountRequest{
+				Id:          "non-existent-id",
+				AccountType: "SAVINGS",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+					WithArgs("non-existent-id").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: "not found",
+		},
+		{
+			name: "expected version matches",
+			request: &pb.UpdateAccountRequest{
+				Id:              "test-account-id",
+				DocumentNumber:  "98765432109",
+				AccountType:     "SAVINGS",
+				ExpectedVersion: 1,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				lockedAccount(mock, "test-account-id", 1)
+				mock.ExpectExec(`UPDATE accounts\s+SET account_type = \$2, .* version = version \+ 1\s+WHERE id = \$1`).
+					WithArgs("test-account-id", "SAVINGS", "", "", "", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectAccountAudit(mock, auditUpdate)
+				mock.ExpectCommit()
+				rows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "holder_name", "holder_email", "kyc_reference", "version", "overdraft_limit", "holder_phone", "tags"}).
+					AddRow("test-account-id", "98765432109", "SAVINGS", 100.50, 1234567890, 1234567890, "ACTIVE", "", "", "", 2, 0.0, "", "")
+				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+					WithArgs("test-account-id").
+					WillReturnRows(rows)
+			},
+			expectedError: "",
+		},
+		{
+			name: "expected version is stale",
+			request: &pb.UpdateAccountRequest{
+				Id:              "test-account-id",
+				DocumentNumber:  "98765432109",
+				AccountType:     "SAVINGS",
+				ExpectedVersion: 1,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				lockedAccount(mock, "test-account-id", 3)
+				mock.ExpectRollback()
+			},
+			expectedError: "version conflict",
+		},
+		{
+			name: "expected version for a missing account",
+			request: &pb.UpdateAccountRequest{
+				Id:              "non-existent-id",
+				AccountType:     "SAVINGS",
+				ExpectedVersion: 1,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+					WithArgs("non-existent-id").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: "not found",
+		},
+		{
+			name: "document number differs from the current one",
+			request: &pb.UpdateAccountRequest{
+				Id:             "test-account-id",
+				DocumentNumber: "11111111111",
+				AccountType:    "SAVINGS",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				lockedAccount(mock, "test-account-id", 3)
+				mock.ExpectRollback()
+			},
+			expectedError: "document_number changes require verification",
+		},
 	}
 
 	for _, tt := range tests {
@@ -305,6 +556,13 @@ func TestService_UpdateAccount(t *testing.T) {
 }
 
 func TestService_DeleteAccount(t *testing.T) {
+	lockedAccount := func(mock sqlmock.Sqlmock, id string) {
+		mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+				AddRow(id, "12345678901", "CHECKING", 0.0, 1234567890, 1234567890, "ACTIVE", "", "", "", 1, 0.0, "", ""))
+	}
+
 	tests := []struct {
 		name           string
 		request        *pb.DeleteAccountRequest
@@ -318,9 +576,14 @@ func TestService_DeleteAccount(t *testing.T) {
 				Id: "test-account-id",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				lockedAccount(mock, "test-account-id")
+				expectAccountSnapshot(mock, "test-account-id", snapshotDelete)
 				mock.ExpectExec(`DELETE FROM accounts WHERE id = \$1`).
 					WithArgs("test-account-id").
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectAccountAudit(mock, auditDelete)
+				mock.ExpectCommit()
 			},
 			expectedError: "",
 			expectedResult: &pb.DeleteAccountResponse{
@@ -346,9 +609,11 @@ func TestService_DeleteAccount(t *testing.T) {
 				Id: "non-existent-id",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(`DELETE FROM accounts WHERE id = \$1`).
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
 					WithArgs("non-existent-id").
-					WillReturnResult(sqlmock.NewResult(0, 0))
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
 			},
 			expectedError: "account not found",
 			expectedResult: &pb.DeleteAccountResponse{
@@ -361,9 +626,13 @@ func TestService_DeleteAccount(t *testing.T) {
 				Id: "test-account-id",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				lockedAccount(mock, "test-account-id")
+				expectAccountSnapshot(mock, "test-account-id", snapshotDelete)
 				mock.ExpectExec(`DELETE FROM accounts WHERE id = \$1`).
 					WithArgs("test-account-id").
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			expectedError: "could not delete account",
 			expectedResult: &pb.DeleteAccountResponse{
@@ -389,71 +658,323 @@ func TestService_DeleteAccount(t *testing.T) {
 			if tt.expectedResult != nil {
 				assert.Equal(t, tt.expectedResult.Success, response.Success)
 			}
+			assert.Equal(t, response.Success, response.SnapshotId != "")
 
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
 
-func TestService_GetBalance(t *testing.T) {
+func TestService_DeleteAccount_ClosesForRetainingTenant(t *testing.T) {
+	tenant := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.TenantIDMetadataKey, "issuer-a"))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	settings := sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{"retention":{"transaction_days":2555}}`))
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).WillReturnRows(settings)
+	lockedAccount := func(id, status string) {
+		mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+				AddRow(id, "12345678901", "CHECKING", 0.0, 1234567890, 1234567890, status, "", "", "", 1, 0.0, "", ""))
+	}
+	mock.ExpectBegin()
+	lockedAccount("test-account-id", "ACTIVE")
+	expectAccountSnapshot(mock, "test-account-id", snapshotClose)
+	mock.ExpectExec(`UPDATE accounts\s+SET status = 'CLOSED', closed_at = \$2, updated_at = \$2, version = version \+ 1\s+WHERE id = \$1`).
+		WithArgs("test-account-id", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAccountAudit(mock, auditDelete)
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	lockedAccount("closed-account-id", "CLOSED")
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+		WithArgs("missing-account-id").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	response, err := service.DeleteAccount(tenant, &pb.DeleteAccountRequest{Id: "test-account-id"})
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.NotEmpty(t, response.SnapshotId)
+
+	// Closing an account that is already closed is told apart from deleting a missing one
+	response, err = service.DeleteAccount(tenant, &pb.DeleteAccountRequest{Id: "closed-account-id"})
+	assert.NoError(t, err)
+	assert.Equal(t, "account already closed", response.Error)
+
+	response, err = service.DeleteAccount(tenant, &pb.DeleteAccountRequest{Id: "missing-account-id"})
+	assert.NoError(t, err)
+	assert.Equal(t, "account not found", response.Error)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_UpdateAccount_RecordsAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, common.RoleSupport, common.OperatorIDMetadataKey, "ops-alice"))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+			AddRow("test-account-id", "12345678901", "CHECKING", 100.50, 1234567890, 1234567890, "ACTIVE", "Maria Silva", "", "", 1, 0.0, "", "VIP"))
+	mock.ExpectExec(`UPDATE accounts`).
+		WithArgs("test-account-id", "CHECKING", "Maria Souza", "", "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO account_audit`).
+		WithArgs(sqlmock.AnyArg(), "test-account-id", "UPDATE", "ops-alice", "support", sqlmock.AnyArg(),
+			`{"document_number":"12345678901","account_type":"CHECKING","status":"ACTIVE","holder_name":"Maria Silva","overdraft_limit":0.00,"tags":["VIP"]}`,
+			`{"document_number":"12345678901","account_type":"CHECKING","status":"ACTIVE","holder_name":"Maria Souza","overdraft_limit":0.00,"tags":["VIP"]}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+			AddRow("test-account-id", "12345678901", "CHECKING", 100.50, 1234567890, 1234567890, "ACTIVE", "Maria Souza", "", "", 2, 0.0, "", "VIP"))
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	response, err := service.UpdateAccount(ctx, &pb.UpdateAccountRequest{Id: "test-account-id", HolderName: "Maria Souza"})
+	require.NoError(t, err)
+	assert.Empty(t, response.Error)
+	assert.Equal(t, "Maria Souza", response.Account.HolderName)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_GetAccountAudit(t *testing.T) {
+	auditColumns := []string{"id", "account_id", "action", "actor", "actor_role", "changed_at", "previous", "updated"}
+	withRole := func(role string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, role))
+	}
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	response, err := service.GetAccountAudit(context.Background(), &pb.GetAccountAuditRequest{AccountId: "test-account-id"})
+	require.NoError(t, err)
+	assert.Equal(t, "permission denied", response.Error)
+
+	response, err = service.GetAccountAudit(withRole(common.RoleSupport), &pb.GetAccountAuditRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "account_id required", response.Error)
+
+	response, err = service.GetAccountAudit(withRole(common.RoleSupport), &pb.GetAccountAuditRequest{AccountId: "test-account-id", Limit: -1})
+	require.NoError(t, err)
+	assert.Equal(t, "limit must not be negative", response.Error)
+
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(auditColumns).
+			AddRow("audit-2", "test-account-id", "DELETE", "ops-alice", "admin", 1234567990,
+				[]byte(`{"document_number":"12345678901","account_type":"CHECKING","status":"ACTIVE","overdraft_limit":50.00,"tags":["VIP"]}`), nil).
+			AddRow("audit-1", "test-account-id", "CREATE", "client-1", "", 1234567890,
+				nil, []byte(`{"document_number":"12345678901","account_type":"CHECKING","status":"ACTIVE","holder_name":"Maria Silva","overdraft_limit":0.00}`))
+	}
+	mock.ExpectQuery(`FROM account_audit\s+WHERE account_id = \$1\s+ORDER BY changed_at DESC, id\s+LIMIT \$2`).
+		WithArgs("test-account-id", int32(defaultAccountAuditLimit)).
+		WillReturnRows(rows())
+	mock.ExpectQuery(`FROM account_audit`).
+		WithArgs("test-account-id", int32(maxAccountAuditLimit)).
+		WillReturnRows(rows())
+
+	// Support operators see masked document numbers
+	response, err = service.GetAccountAudit(withRole(common.RoleSupport), &pb.GetAccountAuditRequest{AccountId: "test-account-id"})
+	require.NoError(t, err)
+	require.Empty(t, response.Error)
+	require.Len(t, response.Entries, 2)
+	deleted, created := response.Entries[0], response.Entries[1]
+	assert.Equal(t, "DELETE", deleted.Action)
+	assert.Equal(t, "ops-alice", deleted.Actor)
+	assert.Equal(t, common.MaskDocumentNumber("12345678901"), deleted.Previous.DocumentNumber)
+	assert.Equal(t, int64(5000), deleted.Previous.OverdraftLimitCents)
+	assert.Equal(t, []string{"VIP"}, deleted.Previous.Tags)
+	assert.Nil(t, deleted.Updated)
+	assert.Nil(t, created.Previous)
+	assert.Equal(t, "Maria Silva", created.Updated.HolderName)
+
+	response, err = service.GetAccountAudit(withRole(common.RoleAdmin), &pb.GetAccountAuditRequest{AccountId: "test-account-id", Limit: 10000})
+	require.NoError(t, err)
+	require.Len(t, response.Entries, 2)
+	assert.Equal(t, "12345678901", response.Entries[0].Previous.DocumentNumber)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_ListAccountSnapshots(t *testing.T) {
+	admin := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleAdmin))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	response, err := service.ListAccountSnapshots(context.Background(), &pb.ListAccountSnapshotsRequest{AccountId: "test-account-id"})
+	require.NoError(t, err)
+	assert.Equal(t, "permission denied", response.Error)
+
+	response, err = service.ListAccountSnapshots(admin, &pb.ListAccountSnapshotsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "account_id required", response.Error)
+
+	mock.ExpectQuery(`FROM account_snapshots\s+WHERE account_id = \$1\s+ORDER BY taken_at DESC, id`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "operation", "taken_by", "taken_by_role", "taken_at", "account",
+			"transaction_count", "restored_at", "restored_by"}).
+			AddRow("snapshot-1", "test-account-id", snapshotDelete, "ops-alice", "admin", 1234567990,
+				[]byte(`{"id":"test-account-id","document_number":"12345678901","account_type":"CHECKING","balance":120.50,`+
+					`"status":"ACTIVE","holder_name":null,"overdraft_limit":0.00,"tags":"VIP,PAYROLL","tenant_id":null}`),
+				3, 0, ""))
+
+	response, err = service.ListAccountSnapshots(admin, &pb.ListAccountSnapshotsRequest{AccountId: "test-account-id"})
+	require.NoError(t, err)
+	require.Empty(t, response.Error)
+	require.Len(t, response.Snapshots, 1)
+	snapshot := response.Snapshots[0]
+	assert.Equal(t, snapshotDelete, snapshot.Operation)
+	assert.Equal(t, "ops-alice", snapshot.TakenBy)
+	assert.Equal(t, int32(3), snapshot.TransactionCount)
+	assert.Equal(t, "12345678901", snapshot.Account.DocumentNumber)
+	assert.Equal(t, int64(12050), snapshot.Account.BalanceCents)
+	assert.ElementsMatch(t, []string{"VIP", "PAYROLL"}, snapshot.Account.Tags)
+	assert.Empty(t, snapshot.Account.HolderName)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_RestoreAccountSnapshot(t *testing.T) {
+	admin := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(common.CallerRoleMetadataKey, common.RoleAdmin, common.OperatorIDMetadataKey, "ops-alice"))
+	snapshotRow := func(mock sqlmock.Sqlmock, operation, status string, restoredAt interface{}) {
+		mock.ExpectQuery(`SELECT account_id, operation, account->>'status', restored_at\s+FROM account_snapshots\s+WHERE id = \$1\s+FOR UPDATE`).
+			WithArgs("snapshot-1").
+			WillReturnRows(sqlmock.NewRows([]string{"account_id", "operation", "status", "restored_at"}).
+				AddRow("test-account-id", operation, status, restoredAt))
+	}
+	lockedAccount := func(mock sqlmock.Sqlmock, status string) {
+		mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+			WithArgs("test-account-id").
+			WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+				AddRow("test-account-id", "12345678901", "CHECKING", 120.5, 1234567890, 1234567890, status, "", "", "", 3, 0.0, "", ""))
+	}
+	markedRestored := func(mock sqlmock.Sqlmock) {
+		mock.ExpectExec(`UPDATE account_snapshots SET restored_at = \$2, restored_by = \$3 WHERE id = \$1`).
+			WithArgs("snapshot-1", sqlmock.AnyArg(), "ops-alice").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
 	tests := []struct {
-		name            string
-		request         *pb.GetBalanceRequest
-		mockSetup       func(sqlmock.Sqlmock)
-		expectedError   string
-		expectedBalance float64
+		name                 string
+		ctx                  context.Context
+		mockSetup            func(sqlmock.Sqlmock)
+		expectedError        string
+		expectedStatus       string
+		expectedTransactions int32
 	}{
 		{
-			name: "successful balance retrieval",
-			request: &pb.GetBalanceRequest{
-				AccountId: "test-account-id",
-			},
+			name:          "not an admin",
+			ctx:           context.Background(),
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name: "deleted account",
+			ctx:  admin,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"balance"}).
-					AddRow(150.75)
-				mock.ExpectQuery(`SELECT balance FROM accounts WHERE id = \$1`).
-					WithArgs("test-account-id").
-					WillReturnRows(rows)
+				mock.ExpectBegin()
+				snapshotRow(mock, snapshotDelete, "ACTIVE", nil)
+				mock.ExpectExec(`INSERT INTO accounts\s+SELECT \(jsonb_populate_record\(NULL::accounts, account\)\)\.\* FROM account_snapshots WHERE id = \$1`).
+					WithArgs("snapshot-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`SELECT set_config\('pismo.allow_backdated', 'on', true\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO transactions\s+SELECT t\.\* FROM account_snapshots s, jsonb_populate_recordset\(NULL::transactions, s\.transactions\) t\s+WHERE s\.id = \$1\s+ON CONFLICT DO NOTHING`).
+					WithArgs("snapshot-1").
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				lockedAccount(mock, "ACTIVE")
+				expectAccountAudit(mock, auditCreate)
+				markedRestored(mock)
+				mock.ExpectCommit()
 			},
-			expectedError:   "",
-			expectedBalance: 150.75,
+			expectedStatus:       "ACTIVE",
+			expectedTransactions: 3,
 		},
 		{
-			name: "missing account id",
-			request: &pb.GetBalanceRequest{
-				AccountId: "",
-			},
+			name: "document number taken since",
+			ctx:  admin,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				// No database call expected
+				mock.ExpectBegin()
+				snapshotRow(mock, snapshotDelete, "ACTIVE", nil)
+				mock.ExpectExec(`INSERT INTO accounts`).
+					WithArgs("snapshot-1").
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "accounts_document_number_key"})
+				mock.ExpectRollback()
 			},
-			expectedError:   "account_id required",
-			expectedBalance: 0,
+			expectedError: "account or document number exists",
 		},
 		{
-			name: "account not found",
-			request: &pb.GetBalanceRequest{
-				AccountId: "non-existent-id",
+			name: "closed account",
+			ctx:  admin,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				snapshotRow(mock, snapshotClose, "PENDING_KYC", nil)
+				lockedAccount(mock, "CLOSED")
+				mock.ExpectExec(`UPDATE accounts\s+SET status = \$2, closed_at = NULL, updated_at = \$3, version = version \+ 1\s+WHERE id = \$1`).
+					WithArgs("test-account-id", "PENDING_KYC", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectAccountAudit(mock, auditStatusChange)
+				markedRestored(mock)
+				mock.ExpectCommit()
 			},
+			expectedStatus: "PENDING_KYC",
+		},
+		{
+			name: "closed account reopened since",
+			ctx:  admin,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT balance FROM accounts WHERE id = \$1`).
-					WithArgs("non-existent-id").
-					WillReturnError(sql.ErrNoRows)
+				mock.ExpectBegin()
+				snapshotRow(mock, snapshotClose, "ACTIVE", nil)
+				lockedAccount(mock, "ACTIVE")
+				mock.ExpectRollback()
 			},
-			expectedError:   "account not found",
-			expectedBalance: 0,
+			expectedError: "account not closed",
 		},
 		{
-			name: "database error",
-			request: &pb.GetBalanceRequest{
-				AccountId: "test-account-id",
+			name: "already restored",
+			ctx:  admin,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				snapshotRow(mock, snapshotDelete, "ACTIVE", 1234568000)
+				mock.ExpectRollback()
 			},
+			expectedError: "snapshot already restored",
+		},
+		{
+			name: "snapshot not found",
+			ctx:  admin,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT balance FROM accounts WHERE id = \$1`).
-					WithArgs("test-account-id").
-					WillReturnError(sql.ErrConnDone)
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM account_snapshots`).
+					WithArgs("snapshot-1").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
 			},
-			expectedError:   "database error",
-			expectedBalance: 0,
+			expectedError: "snapshot not found",
 		},
 	}
 
@@ -467,13 +988,2452 @@ func TestService_GetBalance(t *testing.T) {
 
 			logger, _ := common.NewLogger("test-service", common.INFO)
 			service := NewService(db, logger)
-			response, err := service.GetBalance(context.Background(), tt.request)
+			response, err := service.RestoreAccountSnapshot(tt.ctx, &pb.RestoreAccountSnapshotRequest{Id: "snapshot-1"})
 
-			assert.NoError(t, err)
+			require.NoError(t, err)
 			assert.Equal(t, tt.expectedError, response.Error)
-			assert.Equal(t, tt.expectedBalance, response.Balance)
+			if tt.expectedError == "" {
+				assert.Equal(t, "test-account-id", response.Account.Id)
+				assert.Equal(t, tt.expectedStatus, response.Account.Status)
+				assert.Equal(t, tt.expectedTransactions, response.RestoredTransactions)
+			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
+
+func TestService_GetBalanceAt(t *testing.T) {
+	now := common.GetCurrentTimestamp()
+	tenant := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.TenantIDMetadataKey, "issuer-a"))
+
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		request         *pb.GetBalanceAtRequest
+		mockSetup       func(sqlmock.Sqlmock)
+		expectedError   string
+		expectedBalance int64
+	}{
+		{
+			name:    "balance at a past time",
+			ctx:     context.Background(),
+			request: &pb.GetBalanceAtRequest{AccountId: "test-account-id", Timestamp: now - 3600},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT a.created_at, a.opening_balance .* FROM accounts a WHERE a.id = \$1`).
+					WithArgs("test-account-id", now-3600).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "balance"}).AddRow(now-86400, 42.5))
+			},
+			expectedBalance: 4250,
+		},
+		{
+			name:          "missing timestamp",
+			ctx:           context.Background(),
+			request:       &pb.GetBalanceAtRequest{AccountId: "test-account-id"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "timestamp required",
+		},
+		{
+			name:          "future timestamp",
+			ctx:           context.Background(),
+			request:       &pb.GetBalanceAtRequest{AccountId: "test-account-id", Timestamp: now + 3600},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "timestamp must not be in the future",
+		},
+		{
+			name:    "before the account was created",
+			ctx:     context.Background(),
+			request: &pb.GetBalanceAtRequest{AccountId: "test-account-id", Timestamp: now - 86400},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM accounts a WHERE a.id = \$1`).
+					WithArgs("test-account-id", now-86400).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "balance"}).AddRow(now-3600, 0.0))
+			},
+			expectedError: "account did not exist at that time",
+		},
+		{
+			name:    "account not found",
+			ctx:     context.Background(),
+			request: &pb.GetBalanceAtRequest{AccountId: "non-existent-id", Timestamp: now - 60},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM accounts a WHERE a.id = \$1`).
+					WithArgs("non-existent-id", now-60).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: "account not found",
+		},
+		{
+			name:    "before the tenant's retention period",
+			ctx:     tenant,
+			request: &pb.GetBalanceAtRequest{AccountId: "test-account-id", Timestamp: now - 31*86400},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+					WithArgs("issuer-a", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{"retention":{"transaction_days":30}}`)))
+			},
+			expectedError: "timestamp is before the transaction retention period",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.GetBalanceAt(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			assert.Equal(t, tt.expectedBalance, response.BalanceCents)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_GetBalance(t *testing.T) {
+	tests := []struct {
+		name            string
+		request         *pb.GetBalanceRequest
+		mockSetup       func(sqlmock.Sqlmock)
+		expectedError   string
+		expectedBalance int64
+	}{
+		{
+			name: "successful balance retrieval",
+			request: &pb.GetBalanceRequest{
+				AccountId: "test-account-id",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"balance"}).
+					AddRow(150.75)
+				mock.ExpectQuery(`SELECT balance FROM accounts WHERE id = \$1`).
+					WithArgs("test-account-id").
+					WillReturnRows(rows)
+			},
+			expectedError:   "",
+			expectedBalance: 15075,
+		},
+		{
+			name: "missing account id",
+			request: &pb.GetBalanceRequest{
+				AccountId: "",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				// No database call expected
+			},
+			expectedError:   "account_id required",
+			expectedBalance: 0,
+		},
+		{
+			name: "account not found",
+			request: &pb.GetBalanceRequest{
+				AccountId: "non-existent-id",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT balance FROM accounts WHERE id = \$1`).
+					WithArgs("non-existent-id").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError:   "account not found",
+			expectedBalance: 0,
+		},
+		{
+			name: "database error",
+			request: &pb.GetBalanceRequest{
+				AccountId: "test-account-id",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT balance FROM accounts WHERE id = \$1`).
+					WithArgs("test-account-id").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError:   "database error",
+			expectedBalance: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.GetBalance(context.Background(), tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			assert.Equal(t, tt.expectedBalance, response.BalanceCents)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_GetBalances(t *testing.T) {
+	tenant := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.TenantIDMetadataKey, "issuer-a"))
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		request          *pb.GetBalancesRequest
+		mockSetup        func(sqlmock.Sqlmock)
+		expectedError    string
+		expectedBalances []*pb.CurrencyBalance
+		expectedCredit   *pb.CreditAvailability
+	}{
+		{
+			name:    "checking account in the tenant currency",
+			ctx:     tenant,
+			request: &pb.GetBalancesRequest{AccountId: "test-account-id"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+					WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{"currency":"BRL"}`)))
+				mock.ExpectQuery(`SELECT account_type, balance FROM accounts WHERE id = \$1`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"account_type", "balance"}).AddRow("CHECKING", 150.75))
+			},
+			expectedBalances: []*pb.CurrencyBalance{{Currency: "BRL", BalanceCents: 15075, AvailableCents: 15075}},
+		},
+		{
+			name:    "credit account without a tenant",
+			ctx:     context.Background(),
+			request: &pb.GetBalancesRequest{AccountId: "test-account-id"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT account_type, balance FROM accounts WHERE id = \$1`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"account_type", "balance"}).AddRow("CREDIT", 80.0))
+			},
+			expectedBalances: []*pb.CurrencyBalance{{BalanceCents: 8000, AvailableCents: 8000}},
+			expectedCredit:   &pb.CreditAvailability{AvailableCents: 8000},
+		},
+		{
+			name:          "missing account id",
+			ctx:           context.Background(),
+			request:       &pb.GetBalancesRequest{},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "account_id required",
+		},
+		{
+			name:    "account not found",
+			ctx:     context.Background(),
+			request: &pb.GetBalancesRequest{AccountId: "non-existent-id"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT account_type, balance FROM accounts WHERE id = \$1`).
+					WithArgs("non-existent-id").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: "account not found",
+		},
+		{
+			name:    "tenant settings lookup fails",
+			ctx:     tenant,
+			request: &pb.GetBalancesRequest{AccountId: "test-account-id"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT settings FROM tenant_settings`).WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.GetBalances(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			assert.Equal(t, tt.expectedBalances, response.Balances)
+			assert.Equal(t, tt.expectedCredit, response.Credit)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_GetBalance_Ledger(t *testing.T) {
+	tenant := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.TenantIDMetadataKey, "issuer-a"))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	settings := sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{"balance_source":"LEDGER"}`))
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).WillReturnRows(settings)
+	mock.ExpectQuery(`SELECT a.opening_balance .* FROM accounts a WHERE a.id = \$1`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(42.5))
+	mock.ExpectQuery(`FROM accounts a WHERE a.id = \$1`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(40.0))
+	mock.ExpectQuery(`FROM accounts a WHERE a.id = \$1`).
+		WithArgs("non-existent-id").
+		WillReturnError(sql.ErrNoRows)
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	response, err := service.GetBalance(tenant, &pb.GetBalanceRequest{AccountId: "test-account-id"})
+	assert.NoError(t, err)
+	assert.Empty(t, response.Error)
+	assert.Equal(t, int64(4250), response.BalanceCents)
+
+	// Tenant settings and, for callers accepting a stale balance, the ledger balance are served from their caches
+	response, err = service.GetBalance(tenant, &pb.GetBalanceRequest{AccountId: "test-account-id", MaxStalenessMs: 5000})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4250), response.BalanceCents)
+
+	// Without a staleness hint the balance is read from the database
+	response, err = service.GetBalance(tenant, &pb.GetBalanceRequest{AccountId: "test-account-id"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4000), response.BalanceCents)
+
+	response, err = service.GetBalance(tenant, &pb.GetBalanceRequest{AccountId: "non-existent-id"})
+	assert.NoError(t, err)
+	assert.Equal(t, "account not found", response.Error)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// fakeCreateAccountsStream is an in-memory client stream used to drive CreateAccounts in tests.
+type fakeCreateAccountsStream struct {
+	grpc.ServerStream
+	requests []*pb.CreateAccountRequest
+	response *pb.CreateAccountsResponse
+}
+
+func (f *fakeCreateAccountsStream) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeCreateAccountsStream) Recv() (*pb.CreateAccountRequest, error) {
+	if len(f.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := f.requests[0]
+	f.requests = f.requests[1:]
+	return req, nil
+}
+
+func (f *fakeCreateAccountsStream) SendAndClose(resp *pb.CreateAccountsResponse) error {
+	f.response = resp
+	return nil
+}
+
+func TestService_CreateAccount_Sandbox(t *testing.T) {
+	sandbox := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.TenantIDMetadataKey, "sandbox-a"))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+		WithArgs("sandbox-a", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{"sandbox":true}`)))
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO accounts`).
+		WithArgs(sqlmock.AnyArg(), "TEST00000000001", "CHECKING", "0.00", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "sandbox-a", "", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAccountAudit(mock, auditCreate)
+	mock.ExpectCommit()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	response, err := service.CreateAccount(sandbox, &pb.CreateAccountRequest{DocumentNumber: "TEST00000000001", AccountType: "CHECKING"})
+	assert.NoError(t, err)
+	assert.Empty(t, response.Error)
+
+	// Sandbox tenants only take test document numbers, which no other caller may use
+	response, err = service.CreateAccount(sandbox, &pb.CreateAccountRequest{DocumentNumber: "12345678901", AccountType: "CHECKING"})
+	assert.NoError(t, err)
+	assert.Equal(t, "sandbox accounts require a test document number", response.Error)
+
+	response, err = service.CreateAccount(context.Background(), &pb.CreateAccountRequest{DocumentNumber: "TEST00000000001", AccountType: "CHECKING"})
+	assert.NoError(t, err)
+	assert.Equal(t, "test document numbers are only accepted in sandbox", response.Error)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_CreateAccount_DocumentValidation(t *testing.T) {
+	tenant := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.TenantIDMetadataKey, "issuer-br"))
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+		WithArgs("issuer-br", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{"document_types":["CPF","CNPJ"]}`)))
+	// Formatted documents are stored in canonical form
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO accounts`).
+		WithArgs(sqlmock.AnyArg(), "12345678909", "CHECKING", "0.00", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "issuer-br", "", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAccountAudit(mock, auditCreate)
+	mock.ExpectCommit()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	response, err := service.CreateAccount(tenant, &pb.CreateAccountRequest{DocumentNumber: "123.456.789-09", AccountType: "CHECKING"})
+	assert.NoError(t, err)
+	assert.Empty(t, response.Error)
+	assert.Equal(t, "12345678909", response.Account.DocumentNumber)
+
+	response, err = service.CreateAccount(tenant, &pb.CreateAccountRequest{DocumentNumber: "12345678901", AccountType: "CHECKING"})
+	assert.NoError(t, err)
+	assert.Equal(t, "invalid document_number: CPF 12345678901 has invalid check digits", response.Error)
+
+	response, err = service.CreateAccount(tenant, &pb.CreateAccountRequest{DocumentNumber: "1234", AccountType: "CHECKING"})
+	assert.NoError(t, err)
+	assert.Equal(t, "invalid document_number: must be a CPF or CNPJ", response.Error)
+
+	// Callers without document types accept any document number
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO accounts`).
+		WithArgs(sqlmock.AnyArg(), "12345678901", "CHECKING", "0.00", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "", "", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAccountAudit(mock, auditCreate)
+	mock.ExpectCommit()
+	response, err = service.CreateAccount(context.Background(), &pb.CreateAccountRequest{DocumentNumber: "12345678901", AccountType: "CHECKING"})
+	assert.NoError(t, err)
+	assert.Empty(t, response.Error)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_CreateAccounts(t *testing.T) {
+	tests := []struct {
+		name             string
+		requests         []*pb.CreateAccountRequest
+		mockSetup        func(sqlmock.Sqlmock)
+		expectedCreated  int32
+		expectedDups     int32
+		expectedFailures []*pb.CreateAccountsFailure
+	}{
+		{
+			name: "created, duplicate and invalid records",
+			requests: []*pb.CreateAccountRequest{
+				{DocumentNumber: "11111111111", AccountType: "CHECKING", InitialBalanceCents: 1000},
+				{DocumentNumber: "22222222222", AccountType: "SAVINGS"},
+				{DocumentNumber: "", AccountType: "CHECKING"},
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO accounts .* ON CONFLICT`).
+					WithArgs(sqlmock.AnyArg(), "11111111111", "CHECKING", "10.00", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "", "", "", "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				expectAccountAudit(mock, auditCreate)
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO accounts .* ON CONFLICT`).
+					WithArgs(sqlmock.AnyArg(), "22222222222", "SAVINGS", "0.00", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "", "", "", "").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			expectedCreated: 1,
+			expectedDups:    1,
+			expectedFailures: []*pb.CreateAccountsFailure{
+				{Index: 2, DocumentNumber: "", Reason: "missing required fields"},
+			},
+		},
+		{
+			name: "database error is reported per record",
+			requests: []*pb.CreateAccountRequest{
+				{DocumentNumber: "33333333333", AccountType: "CHECKING"},
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO accounts`).
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedFailures: []*pb.CreateAccountsFailure{
+				{Index: 0, DocumentNumber: "33333333333", Reason: "could not create account"},
+			},
+		},
+		{
+			name:      "empty stream",
+			requests:  nil,
+			mockSetup: func(mock sqlmock.Sqlmock) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			stream := &fakeCreateAccountsStream{requests: tt.requests}
+			err = service.CreateAccounts(stream)
+
+			assert.NoError(t, err)
+			require.NotNil(t, stream.response)
+			assert.Equal(t, tt.expectedCreated, stream.response.Created)
+			assert.Equal(t, tt.expectedDups, stream.response.Duplicates)
+			require.Len(t, stream.response.Failures, len(tt.expectedFailures))
+			for i, expected := range tt.expectedFailures {
+				assert.Equal(t, expected.Index, stream.response.Failures[i].Index)
+				assert.Equal(t, expected.DocumentNumber, stream.response.Failures[i].DocumentNumber)
+				assert.Equal(t, expected.Reason, stream.response.Failures[i].Reason)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_CreateAccount_Quota(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	service.ApplyRuntimeConfig(&common.RuntimeConfig{AccountQuotas: map[string]int{"CREDIT": 1}})
+
+	// Account types without a quota are not counted
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO accounts`).
+		WithArgs(sqlmock.AnyArg(), "11111111111", "CHECKING", "0.00", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "", "", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAccountAudit(mock, auditCreate)
+	mock.ExpectCommit()
+	response, err := service.CreateAccount(context.Background(), &pb.CreateAccountRequest{DocumentNumber: "11111111111", AccountType: "CHECKING"})
+	require.NoError(t, err)
+	assert.Empty(t, response.Error)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM accounts WHERE document_number = \$1 AND account_type = \$2`).
+		WithArgs("22222222222", "CREDIT").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO accounts`).
+		WithArgs(sqlmock.AnyArg(), "22222222222", "CREDIT", "0.00", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "", "", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAccountAudit(mock, auditCreate)
+	mock.ExpectCommit()
+	response, err = service.CreateAccount(context.Background(), &pb.CreateAccountRequest{DocumentNumber: "22222222222", AccountType: "CREDIT"})
+	require.NoError(t, err)
+	assert.Empty(t, response.Error)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM accounts`).
+		WithArgs("33333333333", "CREDIT").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	response, err = service.CreateAccount(context.Background(), &pb.CreateAccountRequest{DocumentNumber: "33333333333", AccountType: "CREDIT"})
+	require.NoError(t, err)
+	assert.Equal(t, "account quota exceeded", response.Error)
+	assert.Nil(t, response.Account)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM accounts`).
+		WithArgs("33333333333", "CREDIT").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	stream := &fakeCreateAccountsStream{requests: []*pb.CreateAccountRequest{{DocumentNumber: "33333333333", AccountType: "CREDIT"}}}
+	require.NoError(t, service.CreateAccounts(stream))
+	assert.Zero(t, stream.response.Created)
+	require.Len(t, stream.response.Failures, 1)
+	assert.Equal(t, "account quota exceeded", stream.response.Failures[0].Reason)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_SearchAccounts(t *testing.T) {
+	columns := []string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status"}
+
+	tests := []struct {
+		name              string
+		role              string
+		request           *pb.SearchAccountsRequest
+		mockSetup         func(sqlmock.Sqlmock)
+		expectedError     string
+		expectedDocuments []string
+	}{
+		{
+			name:    "prefix search masks document numbers for support",
+			role:    common.RoleSupport,
+			request: &pb.SearchAccountsRequest{Query: "12345"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE document_number LIKE \$1`).
+					WithArgs("12345%", 20).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("account-1", "12345678901", "CHECKING", 100.0, 1234567890, 1234567890, "ACTIVE").
+						AddRow("account-2", "12345678902", "SAVINGS", 200.0, 1234567890, 1234567890, "ACTIVE"))
+			},
+			expectedDocuments: []string{"*******8901", "*******8902"},
+		},
+		{
+			name:    "suffix search shows full document numbers for admin",
+			role:    common.RoleAdmin,
+			request: &pb.SearchAccountsRequest{Query: "8901", Match: "suffix", Limit: 5},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE document_number LIKE \$1`).
+					WithArgs("%8901", 5).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("account-1", "12345678901", "CHECKING", 100.0, 1234567890, 1234567890, "ACTIVE"))
+			},
+			expectedDocuments: []string{"12345678901"},
+		},
+		{
+			name:    "wildcards are matched literally",
+			request: &pb.SearchAccountsRequest{Query: "12%_"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE document_number LIKE \$1`).
+					WithArgs(`12\%\_%`, 20).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		{
+			name:          "query too short",
+			request:       &pb.SearchAccountsRequest{Query: "12"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "query must be at least 3 characters",
+		},
+		{
+			name:          "invalid match mode",
+			request:       &pb.SearchAccountsRequest{Query: "12345", Match: "contains"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "match must be prefix or suffix",
+		},
+		{
+			name:    "database error",
+			request: &pb.SearchAccountsRequest{Query: "12345"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE document_number LIKE \$1`).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			ctx := context.Background()
+			if tt.role != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(common.CallerRoleMetadataKey, tt.role))
+			}
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.SearchAccounts(ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+
+			var documents []string
+			for _, account := range response.Accounts {
+				documents = append(documents, account.DocumentNumber)
+			}
+			assert.Equal(t, tt.expectedDocuments, documents)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_ListAccounts(t *testing.T) {
+	columns := []string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "tags"}
+	minBalance, maxBalance := int64(1000000), int64(500000)
+
+	tests := []struct {
+		name              string
+		role              string
+		request           *pb.ListAccountsRequest
+		mockSetup         func(sqlmock.Sqlmock)
+		expectedError     string
+		expectedDocuments []string
+		expectedTotal     int32
+	}{
+		{
+			name:    "cohort by creation date and minimum balance",
+			role:    common.RoleAdmin,
+			request: &pb.ListAccountsRequest{CreatedFrom: 1700000000, CreatedTo: 1700604800, MinBalanceCents: &minBalance},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM accounts WHERE created_at >= \$1 AND created_at < \$2 AND balance >= \$3`).
+					WithArgs(int64(1700000000), int64(1700604800), "10000.00").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(`WHERE created_at >= \$1 AND created_at < \$2 AND balance >= \$3\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$4 OFFSET \$5`).
+					WithArgs(int64(1700000000), int64(1700604800), "10000.00", int32(50), int32(0)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("account-1", "12345678901", "SAVINGS", 12000.0, 1700100000, 1700100000, "ACTIVE", ""))
+			},
+			expectedDocuments: []string{"12345678901"},
+			expectedTotal:     1,
+		},
+		{
+			name:    "unfiltered listing masks document numbers",
+			request: &pb.ListAccountsRequest{Limit: 10, Offset: 20},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM accounts\s*$`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+				mock.ExpectQuery(`LIMIT \$1 OFFSET \$2`).
+					WithArgs(int32(10), int32(20)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("account-1", "12345678901", "CHECKING", 100.0, 1234567890, 1234567890, "ACTIVE", ""))
+			},
+			expectedDocuments: []string{"*******8901"},
+			expectedTotal:     21,
+		},
+		{
+			name:    "segment by tags",
+			role:    common.RoleAdmin,
+			request: &pb.ListAccountsRequest{Tags: []string{"vip", "VIP", "collections"}, ExcludeTags: []string{"test"}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				condition := `WHERE string_to_array\(tags, ','\) @> string_to_array\(\$1, ','\) AND NOT \(string_to_array\(tags, ','\) && string_to_array\(\$2, ','\)\)`
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM accounts `+condition).
+					WithArgs("COLLECTIONS,VIP", "TEST").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(condition).
+					WithArgs("COLLECTIONS,VIP", "TEST", int32(50), int32(0)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("account-1", "12345678901", "CHECKING", 100.0, 1234567890, 1234567890, "ACTIVE", "VIP,COLLECTIONS"))
+			},
+			expectedDocuments: []string{"12345678901"},
+			expectedTotal:     1,
+		},
+		{
+			name:          "invalid tag",
+			request:       &pb.ListAccountsRequest{Tags: []string{"late payer"}},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: `invalid tag: "late payer"`,
+		},
+		{
+			name:          "inverted balance range",
+			request:       &pb.ListAccountsRequest{MinBalanceCents: &minBalance, MaxBalanceCents: &maxBalance},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "min_balance must not exceed max_balance",
+		},
+		{
+			name:          "inverted creation range",
+			request:       &pb.ListAccountsRequest{CreatedFrom: 200, CreatedTo: 100},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "created_from must be before created_to",
+		},
+		{
+			name:    "database error",
+			request: &pb.ListAccountsRequest{MaxBalanceCents: &maxBalance},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM accounts WHERE balance <= \$1`).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			ctx := context.Background()
+			if tt.role != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(common.CallerRoleMetadataKey, tt.role))
+			}
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.ListAccounts(ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			assert.Equal(t, tt.expectedTotal, response.Total)
+
+			var documents []string
+			for _, account := range response.Accounts {
+				documents = append(documents, account.DocumentNumber)
+			}
+			assert.Equal(t, tt.expectedDocuments, documents)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_TenantSettings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Setenv("APP_ENV", "staging")
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	settings := &pb.TenantSettings{
+		Currency:                  "BRL",
+		AllowedOperationTypes:     []string{"CASH_PURCHASE", "PAYMENT"},
+		MaxTransactionAmountCents: 100000,
+		FeeSchedule:               map[string]*pb.FeeRule{"CASH_PURCHASE": {FixedCents: 50}},
+		Retention:                 &pb.RetentionSettings{TransactionDays: 2555},
+	}
+	admin := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleAdmin))
+
+	// Only admins may update settings
+	updated, err := service.UpdateTenantSettings(context.Background(), &pb.UpdateTenantSettingsRequest{TenantId: "issuer-a", Settings: settings})
+	require.NoError(t, err)
+	assert.Equal(t, "permission denied", updated.Error)
+
+	// Invalid settings are rejected
+	updated, err = service.UpdateTenantSettings(admin, &pb.UpdateTenantSettingsRequest{TenantId: "issuer-a", Settings: &pb.TenantSettings{Currency: "brl"}})
+	require.NoError(t, err)
+	assert.Contains(t, updated.Error, "currency")
+
+	updated, err = service.UpdateTenantSettings(admin, &pb.UpdateTenantSettingsRequest{TenantId: "issuer-a", Settings: &pb.TenantSettings{DocumentTypes: []string{"CPF", "SSN"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "unknown document type: SSN", updated.Error)
+
+	mock.ExpectExec(`INSERT INTO tenant_settings`).
+		WithArgs("issuer-a", "staging", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	updated, err = service.UpdateTenantSettings(admin, &pb.UpdateTenantSettingsRequest{TenantId: "issuer-a", Settings: settings})
+	require.NoError(t, err)
+	assert.Empty(t, updated.Error)
+	assert.Equal(t, "staging", updated.Environment)
+	assert.Equal(t, int32(2555), updated.Settings.Retention.TransactionDays)
+
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+		WithArgs("issuer-a", "staging").
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).
+			AddRow([]byte(`{"currency":"BRL","allowed_operation_types":["CASH_PURCHASE","PAYMENT"],"max_transaction_amount":1000,"fee_schedule":{"CASH_PURCHASE":{"fixed":0.5,"percent":0}},"retention":{"anonymize_closed_account_days":90}}`)))
+	got, err := service.GetTenantSettings(context.Background(), &pb.GetTenantSettingsRequest{TenantId: "issuer-a"})
+	require.NoError(t, err)
+	assert.Empty(t, got.Error)
+	assert.Equal(t, "BRL", got.Settings.Currency)
+	assert.Equal(t, []string{"CASH_PURCHASE", "PAYMENT"}, got.Settings.AllowedOperationTypes)
+	assert.Equal(t, int64(100000), got.Settings.MaxTransactionAmountCents)
+	assert.Equal(t, int64(50), got.Settings.FeeSchedule["CASH_PURCHASE"].FixedCents)
+	assert.Equal(t, int32(90), got.Settings.Retention.AnonymizeClosedAccountDays)
+
+	got, err = service.GetTenantSettings(context.Background(), &pb.GetTenantSettingsRequest{TenantId: "bad id"})
+	require.NoError(t, err)
+	assert.Equal(t, "invalid tenant id", got.Error)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// operatorContext returns a context carrying the caller role and operator identity set by the gateway.
+func operatorContext(role, operator string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, role,
+		common.OperatorIDMetadataKey, operator,
+	))
+}
+
+var adjustmentRowColumns = []string{"id", "account_id", "direction", "amount", "reason_code", "description", "status",
+	"requested_by", "requested_at", "reviewed_by", "reviewed_at", "review_note", "source", "reference"}
+
+func TestService_RequestBalanceAdjustment(t *testing.T) {
+	valid := &pb.RequestBalanceAdjustmentRequest{
+		AccountId: "test-account-id", Direction: "CREDIT", AmountCents: 2500, ReasonCode: "FEE_REVERSAL", Description: "Duplicate fee",
+	}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		request       *pb.RequestBalanceAdjustmentRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name:    "support operator requests an adjustment",
+			ctx:     operatorContext(common.RoleSupport, "ops-alice"),
+			request: valid,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM accounts WHERE id = \$1\)`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectExec(`INSERT INTO balance_adjustments`).
+					WithArgs(sqlmock.AnyArg(), "test-account-id", "CREDIT", "25.00", "FEE_REVERSAL", "Duplicate fee", "PENDING", "ops-alice", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:          "caller without an operator role",
+			ctx:           operatorContext("", "ops-alice"),
+			request:       valid,
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:          "missing operator identity",
+			ctx:           operatorContext(common.RoleAdmin, ""),
+			request:       valid,
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:          "invalid direction",
+			ctx:           operatorContext(common.RoleSupport, "ops-alice"),
+			request:       &pb.RequestBalanceAdjustmentRequest{AccountId: "test-account-id", Direction: "UP", AmountCents: 100, ReasonCode: "OTHER"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "direction must be CREDIT or DEBIT",
+		},
+		{
+			name:          "non-positive amount",
+			ctx:           operatorContext(common.RoleSupport, "ops-alice"),
+			request:       &pb.RequestBalanceAdjustmentRequest{AccountId: "test-account-id", Direction: "DEBIT", AmountCents: -500, ReasonCode: "OTHER"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "amount must be positive",
+		},
+		{
+			name:          "unknown reason code",
+			ctx:           operatorContext(common.RoleSupport, "ops-alice"),
+			request:       &pb.RequestBalanceAdjustmentRequest{AccountId: "test-account-id", Direction: "DEBIT", AmountCents: 500, ReasonCode: "BECAUSE"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "invalid reason code",
+		},
+		{
+			name:    "account not found",
+			ctx:     operatorContext(common.RoleSupport, "ops-alice"),
+			request: valid,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXISTS`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedError: "account not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.RequestBalanceAdjustment(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, response.Adjustment)
+				assert.NotEmpty(t, response.Adjustment.Id)
+				assert.Equal(t, "PENDING", response.Adjustment.Status)
+				assert.Equal(t, "ops-alice", response.Adjustment.RequestedBy)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_ReviewBalanceAdjustment(t *testing.T) {
+	pendingDebit := func() *sqlmock.Rows {
+		return sqlmock.NewRows(adjustmentRowColumns).
+			AddRow("adj-1", "test-account-id", "DEBIT", 40.0, "DUPLICATE_CORRECTION", "", "PENDING", "ops-alice", 1700000000, "", 0, "", "", "")
+	}
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		request        *pb.ReviewBalanceAdjustmentRequest
+		mockSetup      func(sqlmock.Sqlmock)
+		expectedError  string
+		expectedStatus string
+	}{
+		{
+			name:    "approval posts the adjustment",
+			ctx:     operatorContext(common.RoleAdmin, "ops-bob"),
+			request: &pb.ReviewBalanceAdjustmentRequest{Id: "adj-1", Approve: true, Note: "verified"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM balance_adjustments WHERE id = \$1 FOR UPDATE`).
+					WithArgs("adj-1").
+					WillReturnRows(pendingDebit())
+				mock.ExpectExec(`UPDATE accounts`).
+					WithArgs("-40.00", sqlmock.AnyArg(), "test-account-id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE balance_adjustments`).
+					WithArgs("APPROVED", "ops-bob", sqlmock.AnyArg(), "verified", "adj-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedStatus: "APPROVED",
+		},
+		{
+			name:    "rejection leaves the balance alone",
+			ctx:     operatorContext(common.RoleAdmin, "ops-bob"),
+			request: &pb.ReviewBalanceAdjustmentRequest{Id: "adj-1", Approve: false, Note: "no evidence"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM balance_adjustments WHERE id = \$1 FOR UPDATE`).
+					WithArgs("adj-1").
+					WillReturnRows(pendingDebit())
+				mock.ExpectExec(`UPDATE balance_adjustments`).
+					WithArgs("REJECTED", "ops-bob", sqlmock.AnyArg(), "no evidence", "adj-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedStatus: "REJECTED",
+		},
+		{
+			name:    "requester cannot approve their own adjustment",
+			ctx:     operatorContext(common.RoleAdmin, "ops-alice"),
+			request: &pb.ReviewBalanceAdjustmentRequest{Id: "adj-1", Approve: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM balance_adjustments WHERE id = \$1 FOR UPDATE`).
+					WithArgs("adj-1").
+					WillReturnRows(pendingDebit())
+				mock.ExpectRollback()
+			},
+			expectedError: "adjustments must be reviewed by a different operator",
+		},
+		{
+			name:    "debit beyond the balance is not posted",
+			ctx:     operatorContext(common.RoleAdmin, "ops-bob"),
+			request: &pb.ReviewBalanceAdjustmentRequest{Id: "adj-1", Approve: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM balance_adjustments WHERE id = \$1 FOR UPDATE`).
+					WithArgs("adj-1").
+					WillReturnRows(pendingDebit())
+				mock.ExpectExec(`UPDATE accounts`).
+					WithArgs("-40.00", sqlmock.AnyArg(), "test-account-id").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: "insufficient balance",
+		},
+		{
+			name:    "credit to an overdrawn account",
+			ctx:     operatorContext(common.RoleAdmin, "ops-bob"),
+			request: &pb.ReviewBalanceAdjustmentRequest{Id: "adj-1", Approve: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM balance_adjustments WHERE id = \$1 FOR UPDATE`).
+					WithArgs("adj-1").
+					WillReturnRows(sqlmock.NewRows(adjustmentRowColumns).
+						AddRow("adj-1", "test-account-id", "CREDIT", 20.0, "DUPLICATE_CORRECTION", "", "PENDING", "ops-alice", 1700000000, "", 0, "", "", ""))
+				mock.ExpectExec(`WHERE id = \$3 AND \(\$1 >= 0 OR balance \+ \$1 >= -overdraft_limit\)`).
+					WithArgs("20.00", sqlmock.AnyArg(), "test-account-id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE balance_adjustments`).
+					WithArgs("APPROVED", "ops-bob", sqlmock.AnyArg(), "", "adj-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedStatus: "APPROVED",
+		},
+		{
+			name:    "already reviewed",
+			ctx:     operatorContext(common.RoleAdmin, "ops-bob"),
+			request: &pb.ReviewBalanceAdjustmentRequest{Id: "adj-1", Approve: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM balance_adjustments WHERE id = \$1 FOR UPDATE`).
+					WithArgs("adj-1").
+					WillReturnRows(sqlmock.NewRows(adjustmentRowColumns).
+						AddRow("adj-1", "test-account-id", "DEBIT", 40.0, "OTHER", "", "APPROVED", "ops-alice", 1700000000, "ops-carol", 1700000100, "", "", ""))
+				mock.ExpectRollback()
+			},
+			expectedError: "adjustment already reviewed",
+		},
+		{
+			name:    "not found",
+			ctx:     operatorContext(common.RoleAdmin, "ops-bob"),
+			request: &pb.ReviewBalanceAdjustmentRequest{Id: "missing", Approve: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM balance_adjustments WHERE id = \$1 FOR UPDATE`).
+					WithArgs("missing").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: "adjustment not found",
+		},
+		{
+			name:          "support operators cannot review",
+			ctx:           operatorContext(common.RoleSupport, "ops-bob"),
+			request:       &pb.ReviewBalanceAdjustmentRequest{Id: "adj-1", Approve: true},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.ReviewBalanceAdjustment(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedStatus != "" {
+				require.NotNil(t, response.Adjustment)
+				assert.Equal(t, tt.expectedStatus, response.Adjustment.Status)
+				assert.Equal(t, "ops-alice", response.Adjustment.RequestedBy)
+				assert.Equal(t, "ops-bob", response.Adjustment.ReviewedBy)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_ListBalanceAdjustments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	mock.ExpectQuery(`FROM balance_adjustments`).
+		WithArgs("test-account-id", "APPROVED", maxListedAdjustments).
+		WillReturnRows(sqlmock.NewRows(adjustmentRowColumns).
+			AddRow("adj-2", "test-account-id", "CREDIT", 10.0, "GOODWILL_CREDIT", "Outage", "APPROVED", "ops-alice", 1700000200, "ops-bob", 1700000300, "ok", "", ""))
+
+	response, err := service.ListBalanceAdjustments(operatorContext(common.RoleSupport, "ops-alice"),
+		&pb.ListBalanceAdjustmentsRequest{AccountId: "test-account-id", Status: "APPROVED"})
+	require.NoError(t, err)
+	assert.Empty(t, response.Error)
+	require.Len(t, response.Adjustments, 1)
+	assert.Equal(t, "ops-bob", response.Adjustments[0].ReviewedBy)
+
+	response, err = service.ListBalanceAdjustments(operatorContext(common.RoleSupport, "ops-alice"),
+		&pb.ListBalanceAdjustmentsRequest{AccountId: "test-account-id", Status: "DONE"})
+	require.NoError(t, err)
+	assert.Equal(t, "invalid status", response.Error)
+
+	response, err = service.ListBalanceAdjustments(context.Background(), &pb.ListBalanceAdjustmentsRequest{AccountId: "test-account-id"})
+	require.NoError(t, err)
+	assert.Equal(t, "permission denied", response.Error)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var onboardingAccountColumns = []string{"id", "document_number", "account_type", "balance", "created_at", "updated_at",
+	"status", "holder_name", "holder_email", "kyc_reference", "version", "overdraft_limit", "holder_phone", "tags"}
+
+func TestService_CreateAccount_Draft(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO accounts`).
+		WithArgs(sqlmock.AnyArg(), "12345678901", "CHECKING", "0.00", sqlmock.AnyArg(), sqlmock.AnyArg(), "DRAFT", "", "", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAccountAudit(mock, auditCreate)
+	mock.ExpectCommit()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	response, err := service.CreateAccount(context.Background(), &pb.CreateAccountRequest{
+		DocumentNumber: "12345678901", AccountType: "CHECKING", Draft: true,
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, response.Error)
+	assert.Equal(t, "DRAFT", response.Account.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_CreateAccount_HolderDetails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO accounts`).
+		WithArgs(sqlmock.AnyArg(), "12345678901", "CHECKING", "0.00", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "",
+			"Maria Silva", "maria@example.com", "+55 11 91234-5678").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectAccountAudit(mock, auditCreate)
+	mock.ExpectCommit()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	response, err := service.CreateAccount(context.Background(), &pb.CreateAccountRequest{
+		DocumentNumber: "12345678901", AccountType: "CHECKING",
+		HolderName: "Maria Silva", HolderEmail: "maria@example.com", HolderPhone: "+55 11 91234-5678",
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, response.Error)
+	assert.Equal(t, "Maria Silva", response.Account.HolderName)
+	assert.Equal(t, "maria@example.com", response.Account.HolderEmail)
+	assert.Equal(t, "+55 11 91234-5678", response.Account.HolderPhone)
+
+	// Invalid contact details are rejected before touching the database
+	response, err = service.CreateAccount(context.Background(), &pb.CreateAccountRequest{
+		DocumentNumber: "12345678901", AccountType: "CHECKING", HolderPhone: "12-34",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "invalid holder phone", response.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateHolder(t *testing.T) {
+	assert.Empty(t, validateHolder("", "", ""))
+	assert.Empty(t, validateHolder("Maria Silva", "maria@example.com", "+55 (11) 91234-5678"))
+	assert.Empty(t, validateHolder("", "", "020 7946 0958"))
+	assert.Equal(t, "invalid holder email", validateHolder("", "maria.example.com", ""))
+	assert.Equal(t, "invalid holder phone", validateHolder("", "", "123456"))
+	assert.Equal(t, "invalid holder phone", validateHolder("", "", "1234567890123456"))
+	assert.Equal(t, "invalid holder phone", validateHolder("", "", "55+11912345678"))
+	assert.Equal(t, "invalid holder phone", validateHolder("", "", "+55 11 9123x4567"))
+	assert.Equal(t, "holder data too long", validateHolder(strings.Repeat("a", 201), "", ""))
+}
+
+func TestService_UpdateAccountHolder(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       *pb.UpdateAccountHolderRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name:    "draft account is enriched",
+			request: &pb.UpdateAccountHolderRequest{AccountId: "test-account-id", HolderName: "Maria Silva", KycReference: "kyc-123", HolderPhone: "+55 11 91234-5678"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+						AddRow("test-account-id", "12345678901", "CHECKING", 0.0, 1234567890, 1234567890, "DRAFT", "", "maria@example.com", "", 1, 0.0, "", ""))
+				mock.ExpectExec(`UPDATE accounts`).
+					WithArgs("Maria Silva", "maria@example.com", "kyc-123", sqlmock.AnyArg(), "test-account-id", "+55 11 91234-5678").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectAccountAudit(mock, auditUpdate)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "active account cannot be changed",
+			request: &pb.UpdateAccountHolderRequest{AccountId: "test-account-id", HolderName: "Maria Silva"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+						AddRow("test-account-id", "12345678901", "CHECKING", 0.0, 1234567890, 1234567890, "ACTIVE", "", "", "", 1, 0.0, "", ""))
+				mock.ExpectRollback()
+			},
+			expectedError: "holder data can only be changed while the account is a draft",
+		},
+		{
+			name:          "invalid email",
+			request:       &pb.UpdateAccountHolderRequest{AccountId: "test-account-id", HolderEmail: "not-an-email"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "invalid holder email",
+		},
+		{
+			name:          "invalid phone",
+			request:       &pb.UpdateAccountHolderRequest{AccountId: "test-account-id", HolderPhone: "call me"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "invalid holder phone",
+		},
+		{
+			name:    "account not found",
+			request: &pb.UpdateAccountHolderRequest{AccountId: "missing", HolderName: "Maria Silva"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+					WithArgs("missing").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: "account not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.UpdateAccountHolder(context.Background(), tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, response.Account)
+				assert.Equal(t, "Maria Silva", response.Account.HolderName)
+				assert.Equal(t, "maria@example.com", response.Account.HolderEmail)
+				assert.Equal(t, "kyc-123", response.Account.KycReference)
+				assert.Equal(t, "+55 11 91234-5678", response.Account.HolderPhone)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_AdvanceOnboarding(t *testing.T) {
+	support := operatorContext(common.RoleSupport, "ops-alice")
+
+	lockedAccount := func(status, holderName, kycReference string) func(sqlmock.Sqlmock) {
+		return func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+				WithArgs("test-account-id").
+				WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+					AddRow("test-account-id", "12345678901", "CHECKING", 0.0, 1234567890, 1234567890, status, holderName, "", kycReference, 1, 0.0, "", ""))
+		}
+	}
+	transitioned := func(setup func(sqlmock.Sqlmock), status string) func(sqlmock.Sqlmock) {
+		return func(mock sqlmock.Sqlmock) {
+			setup(mock)
+			mock.ExpectExec(`UPDATE accounts SET status = \$1`).
+				WithArgs(status, sqlmock.AnyArg(), "test-account-id").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectAccountAudit(mock, auditStatusChange)
+			mock.ExpectCommit()
+		}
+	}
+	rejected := func(setup func(sqlmock.Sqlmock)) func(sqlmock.Sqlmock) {
+		return func(mock sqlmock.Sqlmock) {
+			setup(mock)
+			mock.ExpectRollback()
+		}
+	}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		status        string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name:      "draft with holder data is submitted for KYC",
+			ctx:       context.Background(),
+			status:    "PENDING_KYC",
+			mockSetup: transitioned(lockedAccount("DRAFT", "Maria Silva", "kyc-123"), "PENDING_KYC"),
+		},
+		{
+			name:          "draft without holder data cannot be submitted",
+			ctx:           context.Background(),
+			status:        "PENDING_KYC",
+			mockSetup:     rejected(lockedAccount("DRAFT", "Maria Silva", "")),
+			expectedError: "holder name and kyc reference required",
+		},
+		{
+			name:      "support approves KYC",
+			ctx:       support,
+			status:    "ACTIVE",
+			mockSetup: transitioned(lockedAccount("PENDING_KYC", "Maria Silva", "kyc-123"), "ACTIVE"),
+		},
+		{
+			name:      "support rejects KYC back to draft",
+			ctx:       support,
+			status:    "DRAFT",
+			mockSetup: transitioned(lockedAccount("PENDING_KYC", "Maria Silva", "kyc-123"), "DRAFT"),
+		},
+		{
+			name:          "KYC approval requires an operator role",
+			ctx:           context.Background(),
+			status:        "ACTIVE",
+			mockSetup:     rejected(lockedAccount("PENDING_KYC", "Maria Silva", "kyc-123")),
+			expectedError: "permission denied",
+		},
+		{
+			name:          "draft cannot skip KYC",
+			ctx:           support,
+			status:        "ACTIVE",
+			mockSetup:     rejected(lockedAccount("DRAFT", "Maria Silva", "kyc-123")),
+			expectedError: "cannot move account from DRAFT to ACTIVE",
+		},
+		{
+			name:          "active accounts stay active",
+			ctx:           support,
+			status:        "DRAFT",
+			mockSetup:     rejected(lockedAccount("ACTIVE", "", "")),
+			expectedError: "cannot move account from ACTIVE to DRAFT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.AdvanceOnboarding(tt.ctx, &pb.AdvanceOnboardingRequest{AccountId: "test-account-id", Status: tt.status})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, response.Account)
+				assert.Equal(t, tt.status, response.Account.Status)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_SetOverdraftLimit(t *testing.T) {
+	admin := operatorContext(common.RoleAdmin, "ops-bob")
+
+	lockedAccount := func(accountType string, balance, overdraftLimit float64) func(sqlmock.Sqlmock) {
+		return func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+				WithArgs("test-account-id").
+				WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+					AddRow("test-account-id", "12345678901", accountType, balance, 1234567890, 1234567890, "ACTIVE", "", "", "", 1, overdraftLimit, "", ""))
+		}
+	}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		limit         int64
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name:  "admin sets the limit of a checking account",
+			ctx:   admin,
+			limit: 50000,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount("CHECKING", 100.0, 0)(mock)
+				mock.ExpectExec(`UPDATE accounts SET overdraft_limit = \$1, updated_at = \$2, version = version \+ 1 WHERE id = \$3`).
+					WithArgs("500.00", sqlmock.AnyArg(), "test-account-id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectAccountAudit(mock, auditUpdate)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:  "limit is removed while the account is in credit",
+			ctx:   admin,
+			limit: 0,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount("CHECKING", 0, 500.0)(mock)
+				mock.ExpectExec(`UPDATE accounts SET overdraft_limit`).
+					WithArgs("0.00", sqlmock.AnyArg(), "test-account-id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectAccountAudit(mock, auditUpdate)
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:  "limit cannot drop below the current overdraft",
+			ctx:   admin,
+			limit: 10000,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount("CHECKING", -150.0, 500.0)(mock)
+				mock.ExpectRollback()
+			},
+			expectedError: "account is overdrawn by more than the overdraft limit",
+		},
+		{
+			name:  "only checking accounts have an overdraft",
+			ctx:   admin,
+			limit: 10000,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount("SAVINGS", 100.0, 0)(mock)
+				mock.ExpectRollback()
+			},
+			expectedError: "overdraft is only available for checking accounts",
+		},
+		{
+			name:  "account not found",
+			ctx:   admin,
+			limit: 10000,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).WithArgs("test-account-id").WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: "account not found",
+		},
+		{
+			name:          "support may not set limits",
+			ctx:           operatorContext(common.RoleSupport, "ops-alice"),
+			limit:         10000,
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:          "negative limit",
+			ctx:           admin,
+			limit:         -1,
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "overdraft_limit must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.SetOverdraftLimit(tt.ctx, &pb.SetOverdraftLimitRequest{AccountId: "test-account-id", OverdraftLimitCents: tt.limit})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, response.Account)
+				assert.Equal(t, tt.limit, response.Account.OverdraftLimitCents)
+				assert.Equal(t, int64(2), response.Account.Version)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_UpdateAccountTags(t *testing.T) {
+	admin := operatorContext(common.RoleAdmin, "ops-bob")
+
+	lockedAccount := func(tags string) func(sqlmock.Sqlmock) {
+		return func(mock sqlmock.Sqlmock) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+				WithArgs("test-account-id").
+				WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+					AddRow("test-account-id", "12345678901", "CHECKING", 0.0, 1234567890, 1234567890, "ACTIVE", "", "", "", 1, 0.0, "", tags))
+		}
+	}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		request       *pb.UpdateAccountTagsRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+		expectedTags  []string
+	}{
+		{
+			name:    "tags are added and removed case-insensitively",
+			ctx:     admin,
+			request: &pb.UpdateAccountTagsRequest{AccountId: "test-account-id", Add: []string{"vip", "Collections"}, Remove: []string{"test"}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount("TEST,VIP")(mock)
+				mock.ExpectExec(`UPDATE accounts SET tags = \$1, updated_at = \$2, version = version \+ 1 WHERE id = \$3`).
+					WithArgs("COLLECTIONS,VIP", sqlmock.AnyArg(), "test-account-id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectAccountAudit(mock, auditUpdate)
+				mock.ExpectCommit()
+			},
+			expectedTags: []string{"COLLECTIONS", "VIP"},
+		},
+		{
+			name:          "callers other than admins are rejected",
+			ctx:           operatorContext(common.RoleSupport, "ops-alice"),
+			request:       &pb.UpdateAccountTagsRequest{AccountId: "test-account-id", Add: []string{"VIP"}},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:          "invalid tag",
+			ctx:           admin,
+			request:       &pb.UpdateAccountTagsRequest{AccountId: "test-account-id", Add: []string{"VIP,TEST"}},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: `invalid tag: "VIP,TEST"`,
+		},
+		{
+			name:          "nothing to change",
+			ctx:           admin,
+			request:       &pb.UpdateAccountTagsRequest{AccountId: "test-account-id"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "no tags to add or remove",
+		},
+		{
+			name:    "too many tags",
+			ctx:     admin,
+			request: &pb.UpdateAccountTagsRequest{AccountId: "test-account-id", Add: []string{"K"}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount("A,B,C,D,E,F,G,H,I,J")(mock)
+				mock.ExpectRollback()
+			},
+			expectedError: "at most 10 tags allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.UpdateAccountTags(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, response.Account)
+				assert.Equal(t, tt.expectedTags, response.Account.Tags)
+				assert.Equal(t, int64(2), response.Account.Version)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_ListAccessDecisions(t *testing.T) {
+	admin := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleAdmin))
+	columns := []string{"id", "service", "method", "principal", "role", "tenant_id", "request_id", "decision", "reason", "occurred_at"}
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		request        *pb.ListAccessDecisionsRequest
+		mockSetup      func(sqlmock.Sqlmock)
+		expectedError  string
+		expectedIDs    []int64
+		expectedBefore int64
+	}{
+		{
+			name:    "full page returns the cursor for the next one",
+			ctx:     admin,
+			request: &pb.ListAccessDecisionsRequest{Decision: "DENY", Limit: 2},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM access_audit_log\s+WHERE decision = \$1`).
+					WithArgs("DENY", 2).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(9, "transaction-mgr", "/transaction.TransactionService/UpdateOperationRule", "ops-2", "support", "", "req-9", "DENY", "role support not permitted", 1300).
+						AddRow(7, "account-mgr", "/account.AccountService/ReviewBalanceAdjustment", "ops-1", "admin", "", "req-7", "DENY", "operator id required", 1200))
+			},
+			expectedIDs:    []int64{9, 7},
+			expectedBefore: 7,
+		},
+		{
+			name:          "only admins may read the audit",
+			ctx:           metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleSupport)),
+			request:       &pb.ListAccessDecisionsRequest{},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:          "unknown decision",
+			ctx:           admin,
+			request:       &pb.ListAccessDecisionsRequest{Decision: "MAYBE"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "decision must be ALLOW or DENY",
+		},
+		{
+			name:          "empty time range",
+			ctx:           admin,
+			request:       &pb.ListAccessDecisionsRequest{From: 2000, To: 1000},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "invalid time range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.ListAccessDecisions(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+
+			var ids []int64
+			for _, decision := range response.Decisions {
+				ids = append(ids, decision.Id)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedBefore, response.NextBeforeId)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_GetSLOStatus(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	tracker := common.NewSLOTracker()
+	tracker.ApplyRuntimeConfig(&common.RuntimeConfig{SLOTargets: map[string]common.SLOTarget{
+		"/account.AccountService/CreateAccount": {Availability: 99.5, LatencyMs: 200},
+	}})
+	service.SetSLOTracker(tracker)
+	tracker.Record("/account.AccountService/CreateAccount", 10*time.Millisecond, false)
+	tracker.Record("/account.AccountService/CreateAccount", 10*time.Millisecond, true)
+
+	response, err := service.GetSLOStatus(context.Background(), &pb.GetSLOStatusRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "permission denied", response.Error)
+
+	support := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleSupport))
+	response, err = service.GetSLOStatus(support, &pb.GetSLOStatusRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, response.Error)
+	require.Len(t, response.Methods, 1)
+	method := response.Methods[0]
+	assert.Equal(t, "/account.AccountService/CreateAccount", method.Method)
+	assert.Equal(t, 99.5, method.AvailabilityTarget)
+	assert.Equal(t, int64(200), method.LatencyTargetMs)
+	assert.False(t, method.WithinBudget)
+	require.Len(t, method.Windows, 3)
+	assert.Equal(t, int64(300), method.Windows[0].WindowSeconds)
+	assert.Equal(t, int64(2), method.Windows[0].Total)
+	assert.Equal(t, int64(1), method.Windows[0].Bad)
+	assert.InDelta(t, 100, method.Windows[0].BurnRate, 0.001)
+}
+
+func TestFXRevaluationJob_RunOnce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	job := NewFXRevaluationJob(db, logger)
+	job.now = func() time.Time { return time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC) }
+
+	const (
+		sep01 = int64(1788220800)
+		sep05 = int64(1788566400)
+		sep15 = int64(1789430400)
+		sep30 = int64(1790726400)
+		oct01 = int64(1790812800)
+		jul10 = int64(1783641600)
+	)
+
+	mock.ExpectQuery(`SELECT tenant_id, settings FROM tenant_settings`).
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "settings"}).
+			AddRow("issuer-a", []byte(`{"currency":"EUR","reporting_currency":"USD"}`)).
+			AddRow("issuer-b", []byte(`{"currency":"BRL"}`)))
+	mock.ExpectQuery(`SELECT rate_date, rate FROM fx_rates`).
+		WithArgs("EUR", "USD", oct01).
+		WillReturnRows(sqlmock.NewRows([]string{"rate_date", "rate"}).
+			AddRow(sep05, 1.10).AddRow(sep15, 1.20).AddRow(sep30, 1.25))
+	mock.ExpectQuery(`SELECT id, COALESCE\(opening_balance, 0\), created_at FROM accounts a`).
+		WithArgs("issuer-a", oct01, sep01, "2026-09").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "opening_balance", "created_at"}).
+			AddRow("acc-1", 100.0, sep05).
+			AddRow("acc-2", 0.0, jul10))
+
+	// Never revalued: carried at the rates of the days it was opened and moved
+	mock.ExpectQuery(`SELECT period, revalued_value FROM fx_revaluations`).
+		WithArgs("acc-1", "2026-09").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`SELECT day, SUM\(amount\) FROM`).
+		WithArgs("acc-1", oct01).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "sum"}).AddRow(sep15, 50.0))
+
+	// Revalued in August: only movements since then are added at their day's rate
+	mock.ExpectQuery(`SELECT period, revalued_value FROM fx_revaluations`).
+		WithArgs("acc-2", "2026-09").
+		WillReturnRows(sqlmock.NewRows([]string{"period", "revalued_value"}).AddRow("2026-08", 240.0))
+	mock.ExpectQuery(`SELECT day, SUM\(amount\) FROM`).
+		WithArgs("acc-2", oct01).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "sum"}).AddRow(jul10, 200.0).AddRow(sep15, -20.0))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO fx_revaluations`).
+		WithArgs("issuer-a", "acc-1", "2026-09", "EUR", "USD", "150.00", 1.25, "170.00", "187.50", "17.50", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO fx_revaluations`).
+		WithArgs("issuer-a", "acc-2", "2026-09", "EUR", "USD", "180.00", 1.25, "216.00", "225.00", "9.00", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	job.RunOnce(context.Background())
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// The month is not revalued before the rate of its last day is known
+	mock.ExpectQuery(`SELECT rate_date, rate FROM fx_rates`).
+		WithArgs("EUR", "USD", oct01).
+		WillReturnRows(sqlmock.NewRows([]string{"rate_date", "rate"}).AddRow(sep15, 1.20))
+	_, err = job.Revalue(context.Background(), "issuer-a", common.TenantSettings{Currency: "EUR", ReportingCurrency: "USD"},
+		time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC))
+	assert.EqualError(t, err, "no EUR/USD rate for the last day of 2026-09")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_GetFxRevaluationReport(t *testing.T) {
+	support := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleSupport))
+	columns := []string{"account_id", "currency", "reporting_currency", "balance", "closing_rate", "carrying_value",
+		"revalued_value", "unrealized_gain", "created_at"}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		request       *pb.GetFxRevaluationReportRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+		expectedTotal int64
+		expectedCount int
+	}{
+		{
+			name:          "caller without role",
+			ctx:           context.Background(),
+			request:       &pb.GetFxRevaluationReportRequest{TenantId: "issuer-a", Period: "2026-09"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:          "invalid period",
+			ctx:           support,
+			request:       &pb.GetFxRevaluationReportRequest{TenantId: "issuer-a", Period: "2026-9"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "period must be YYYY-MM",
+		},
+		{
+			name:    "entries with total",
+			ctx:     support,
+			request: &pb.GetFxRevaluationReportRequest{TenantId: "issuer-a", Period: "2026-09"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+					WithArgs("issuer-a", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{"currency":"EUR","reporting_currency":"USD"}`)))
+				mock.ExpectQuery(`FROM fx_revaluations r\s+WHERE tenant_id = \$1 AND period = \$2\s+AND NOT EXISTS`).
+					WithArgs("issuer-a", "2026-09", "TEST").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("acc-1", "EUR", "USD", 150.0, 1.25, 170.0, 187.5, 17.5, 1790830000).
+						AddRow("acc-2", "EUR", "USD", 180.0, 1.25, 216.0, 225.0, 9.0, 1790830000).
+						AddRow("acc-3", "EUR", "USD", 10.0, 1.25, 13.1, 12.5, -0.6, 1790830000))
+			},
+			expectedTotal: 2590,
+			expectedCount: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			resp, err := service.GetFxRevaluationReport(tt.ctx, tt.request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, resp.Error)
+			if tt.expectedError == "" {
+				assert.Equal(t, "USD", resp.ReportingCurrency)
+				assert.Len(t, resp.Revaluations, tt.expectedCount)
+				assert.Equal(t, tt.expectedTotal, resp.TotalUnrealizedGainCents)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+var documentChangeRowColumns = []string{"id", "account_id", "previous_document_number", "document_number", "status", "reason",
+	"requested_by", "requested_at", "verification_reference", "verified_by", "verified_at", "verification_note", "applied_by", "applied_at"}
+
+func TestService_RequestDocumentChange(t *testing.T) {
+	valid := &pb.RequestDocumentChangeRequest{AccountId: "test-account-id", DocumentNumber: "98765432109", Reason: "Typo at onboarding"}
+	lockedAccount := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+			WithArgs("test-account-id").
+			WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+				AddRow("test-account-id", "12345678901", "CHECKING", 0.0, 1234567890, 1234567890, "ACTIVE", "", "", "", 1, 0.0, "", ""))
+	}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		request       *pb.RequestDocumentChangeRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name:    "support operator requests a change",
+			ctx:     operatorContext(common.RoleSupport, "ops-alice"),
+			request: valid,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount(mock)
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM accounts WHERE document_number = \$1 AND id <> \$2\)`).
+					WithArgs("98765432109", "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery(`FROM document_changes WHERE account_id = \$1 AND status IN`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectExec(`INSERT INTO document_changes`).
+					WithArgs(sqlmock.AnyArg(), "test-account-id", "12345678901", "98765432109", "PENDING", "Typo at onboarding", "ops-alice", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:          "caller without an operator role",
+			ctx:           operatorContext("", "ops-alice"),
+			request:       valid,
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:          "document number too long",
+			ctx:           operatorContext(common.RoleSupport, "ops-alice"),
+			request:       &pb.RequestDocumentChangeRequest{AccountId: "test-account-id", DocumentNumber: "123456789012345678901"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "document_number must be at most 20 characters",
+		},
+		{
+			name:    "number held by another account",
+			ctx:     operatorContext(common.RoleSupport, "ops-alice"),
+			request: valid,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount(mock)
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM accounts WHERE document_number`).
+					WithArgs("98765432109", "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedError: "document number already in use",
+		},
+		{
+			name:    "change already in progress",
+			ctx:     operatorContext(common.RoleSupport, "ops-alice"),
+			request: valid,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount(mock)
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM accounts WHERE document_number`).
+					WithArgs("98765432109", "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery(`FROM document_changes WHERE account_id = \$1 AND status IN`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedError: "document change already in progress",
+		},
+		{
+			name:    "account not found",
+			ctx:     operatorContext(common.RoleSupport, "ops-alice"),
+			request: valid,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+					WithArgs("test-account-id").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: "account not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.RequestDocumentChange(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, response.Change)
+				assert.Equal(t, "PENDING", response.Change.Status)
+				assert.Equal(t, "12345678901", response.Change.PreviousDocumentNumber)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_VerifyDocumentChange(t *testing.T) {
+	pending := func() *sqlmock.Rows {
+		return sqlmock.NewRows(documentChangeRowColumns).
+			AddRow("dc-1", "test-account-id", "12345678901", "98765432109", "PENDING", "", "ops-alice", 1700000000, "", "", 0, "", "", 0)
+	}
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		request        *pb.VerifyDocumentChangeRequest
+		mockSetup      func(sqlmock.Sqlmock)
+		expectedError  string
+		expectedStatus string
+	}{
+		{
+			name:    "admin verifies the change",
+			ctx:     operatorContext(common.RoleAdmin, "ops-bob"),
+			request: &pb.VerifyDocumentChangeRequest{Id: "dc-1", Approve: true, VerificationReference: "kyc-77", Note: "ID card checked"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM document_changes WHERE id = \$1 FOR UPDATE`).
+					WithArgs("dc-1").
+					WillReturnRows(pending())
+				mock.ExpectExec(`UPDATE document_changes`).
+					WithArgs("VERIFIED", "kyc-77", "ops-bob", sqlmock.AnyArg(), "ID card checked", "dc-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedStatus: "VERIFIED",
+		},
+		{
+			name:          "verification needs a reference",
+			ctx:           operatorContext(common.RoleAdmin, "ops-bob"),
+			request:       &pb.VerifyDocumentChangeRequest{Id: "dc-1", Approve: true},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "verification_reference required",
+		},
+		{
+			name:    "requester cannot verify their own change",
+			ctx:     operatorContext(common.RoleAdmin, "ops-alice"),
+			request: &pb.VerifyDocumentChangeRequest{Id: "dc-1", Approve: true, VerificationReference: "kyc-77"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM document_changes WHERE id = \$1 FOR UPDATE`).
+					WithArgs("dc-1").
+					WillReturnRows(pending())
+				mock.ExpectRollback()
+			},
+			expectedError: "document changes must be verified by a different operator",
+		},
+		{
+			name:          "support operators cannot verify",
+			ctx:           operatorContext(common.RoleSupport, "ops-bob"),
+			request:       &pb.VerifyDocumentChangeRequest{Id: "dc-1", Approve: true, VerificationReference: "kyc-77"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.VerifyDocumentChange(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedStatus != "" {
+				require.NotNil(t, response.Change)
+				assert.Equal(t, tt.expectedStatus, response.Change.Status)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_ApplyDocumentChange(t *testing.T) {
+	verified := func() *sqlmock.Rows {
+		return sqlmock.NewRows(documentChangeRowColumns).
+			AddRow("dc-1", "test-account-id", "12345678901", "98765432109", "VERIFIED", "", "ops-alice", 1700000000, "kyc-77", "ops-bob", 1700000100, "", "", 0)
+	}
+	lockedAccount := func(documentNumber string) *sqlmock.Rows {
+		return sqlmock.NewRows(onboardingAccountColumns).
+			AddRow("test-account-id", documentNumber, "CHECKING", 0.0, 1234567890, 1234567890, "ACTIVE", "", "", "", 1, 0.0, "", "")
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name: "verified change is applied",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM document_changes WHERE id = \$1 FOR UPDATE`).
+					WithArgs("dc-1").
+					WillReturnRows(verified())
+				mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+					WithArgs("test-account-id").
+					WillReturnRows(lockedAccount("12345678901"))
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM accounts WHERE document_number`).
+					WithArgs("98765432109", "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectExec(`UPDATE accounts\s+SET document_number = \$1, updated_at = \$2, version = version \+ 1`).
+					WithArgs("98765432109", sqlmock.AnyArg(), "test-account-id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectAccountAudit(mock, auditUpdate)
+				mock.ExpectExec(`UPDATE document_changes SET status = \$1, applied_by = \$2, applied_at = \$3`).
+					WithArgs("APPLIED", "ops-carol", sqlmock.AnyArg(), "dc-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "number taken by another account meanwhile",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM document_changes WHERE id = \$1 FOR UPDATE`).
+					WithArgs("dc-1").
+					WillReturnRows(verified())
+				mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+					WithArgs("test-account-id").
+					WillReturnRows(lockedAccount("12345678901"))
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM accounts WHERE document_number`).
+					WithArgs("98765432109", "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			expectedError: "document number already in use",
+		},
+		{
+			name: "account document changed since the request",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM document_changes WHERE id = \$1 FOR UPDATE`).
+					WithArgs("dc-1").
+					WillReturnRows(verified())
+				mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+					WithArgs("test-account-id").
+					WillReturnRows(lockedAccount("55555555555"))
+				mock.ExpectRollback()
+			},
+			expectedError: "document number changed since the request",
+		},
+		{
+			name: "pending change cannot be applied",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM document_changes WHERE id = \$1 FOR UPDATE`).
+					WithArgs("dc-1").
+					WillReturnRows(sqlmock.NewRows(documentChangeRowColumns).
+						AddRow("dc-1", "test-account-id", "12345678901", "98765432109", "PENDING", "", "ops-alice", 1700000000, "", "", 0, "", "", 0))
+				mock.ExpectRollback()
+			},
+			expectedError: "document change not verified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.ApplyDocumentChange(operatorContext(common.RoleSupport, "ops-carol"),
+				&pb.ApplyDocumentChangeRequest{Id: "dc-1"})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, response.Change)
+				assert.Equal(t, "APPLIED", response.Change.Status)
+				assert.Equal(t, "ops-carol", response.Change.AppliedBy)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestInternalService_AdjustBalance(t *testing.T) {
+	valid := &pb.AdjustBalanceRequest{
+		AccountId: "test-account-id", Direction: "DEBIT", AmountCents: 250, ReasonCode: "FEE_CHARGE", Reference: "fee-2026-09-test-account-id",
+	}
+	lockedAccount := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+			WithArgs("test-account-id").
+			WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+				AddRow("test-account-id", "12345678901", "CHECKING", 100.0, 1234567890, 1234567890, "ACTIVE", "", "", "", 1, 0.0, "", ""))
+	}
+
+	tests := []struct {
+		name          string
+		service       string
+		request       *pb.AdjustBalanceRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+		expectedID    string
+	}{
+		{
+			name:    "service posts a fee",
+			service: "fee-worker",
+			request: valid,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount(mock)
+				mock.ExpectQuery(`FROM balance_adjustments WHERE source = \$1 AND reference = \$2`).
+					WithArgs("fee-worker", "fee-2026-09-test-account-id").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec(`UPDATE accounts`).
+					WithArgs("-2.50", sqlmock.AnyArg(), "test-account-id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO balance_adjustments`).
+					WithArgs(sqlmock.AnyArg(), "test-account-id", "DEBIT", "2.50", "FEE_CHARGE", "", "APPROVED",
+						"service:fee-worker", sqlmock.AnyArg(), "service:fee-worker", sqlmock.AnyArg(), "fee-worker", "fee-2026-09-test-account-id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "retry returns the posted adjustment",
+			service: "fee-worker",
+			request: valid,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount(mock)
+				mock.ExpectQuery(`FROM balance_adjustments WHERE source = \$1 AND reference = \$2`).
+					WithArgs("fee-worker", "fee-2026-09-test-account-id").
+					WillReturnRows(sqlmock.NewRows(adjustmentRowColumns).
+						AddRow("adj-9", "test-account-id", "DEBIT", 2.5, "FEE_CHARGE", "", "APPROVED", "service:fee-worker", 1700000000,
+							"service:fee-worker", 1700000000, "", "fee-worker", "fee-2026-09-test-account-id"))
+				mock.ExpectCommit()
+			},
+			expectedID: "adj-9",
+		},
+		{
+			name:    "reference reused for another amount",
+			service: "fee-worker",
+			request: valid,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount(mock)
+				mock.ExpectQuery(`FROM balance_adjustments WHERE source = \$1 AND reference = \$2`).
+					WithArgs("fee-worker", "fee-2026-09-test-account-id").
+					WillReturnRows(sqlmock.NewRows(adjustmentRowColumns).
+						AddRow("adj-9", "test-account-id", "DEBIT", 5.0, "FEE_CHARGE", "", "APPROVED", "service:fee-worker", 1700000000,
+							"service:fee-worker", 1700000000, "", "fee-worker", "fee-2026-09-test-account-id"))
+				mock.ExpectRollback()
+			},
+			expectedError: "reference already used for another adjustment",
+		},
+		{
+			name:    "debit beyond the balance",
+			service: "fee-worker",
+			request: valid,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				lockedAccount(mock)
+				mock.ExpectQuery(`FROM balance_adjustments WHERE source = \$1 AND reference = \$2`).
+					WithArgs("fee-worker", "fee-2026-09-test-account-id").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec(`UPDATE accounts`).
+					WithArgs("-2.50", sqlmock.AnyArg(), "test-account-id").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: "insufficient balance",
+		},
+		{
+			name:    "credit to an overdrawn account",
+			service: "fee-worker",
+			request: &pb.AdjustBalanceRequest{
+				AccountId: "test-account-id", Direction: "CREDIT", AmountCents: 2000, ReasonCode: "FEE_REFUND", Reference: "refund-1",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id = \$1 FOR UPDATE`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows(onboardingAccountColumns).
+						AddRow("test-account-id", "12345678901", "CHECKING", -50.0, 1234567890, 1234567890, "ACTIVE", "", "", "", 1, 100.0, "", ""))
+				mock.ExpectQuery(`FROM balance_adjustments WHERE source = \$1 AND reference = \$2`).
+					WithArgs("fee-worker", "refund-1").
+					WillReturnError(sql.ErrNoRows)
+				// Credits are not held to the overdraft floor, so they can bring an overdrawn account back up
+				mock.ExpectExec(`WHERE id = \$3 AND \(\$1 >= 0 OR balance \+ \$1 >= -overdraft_limit\)`).
+					WithArgs("20.00", sqlmock.AnyArg(), "test-account-id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO balance_adjustments`).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:          "unauthenticated caller",
+			request:       valid,
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:    "operator reason code",
+			service: "fee-worker",
+			request: &pb.AdjustBalanceRequest{
+				AccountId: "test-account-id", Direction: "CREDIT", AmountCents: 100, ReasonCode: "GOODWILL_CREDIT", Reference: "r-1",
+			},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "invalid reason code",
+		},
+		{
+			name:          "missing reference",
+			service:       "fee-worker",
+			request:       &pb.AdjustBalanceRequest{AccountId: "test-account-id", Direction: "DEBIT", AmountCents: 100, ReasonCode: "FEE_CHARGE"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "missing required fields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewInternalService(NewService(db, logger))
+			ctx := context.Background()
+			if tt.service != "" {
+				ctx = internalServiceContext(t, tt.service)
+			}
+			response, err := service.AdjustBalance(ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, response.Adjustment)
+				assert.Equal(t, "APPROVED", response.Adjustment.Status)
+				assert.Equal(t, tt.service, response.Adjustment.Source)
+				if tt.expectedID != "" {
+					assert.Equal(t, tt.expectedID, response.Adjustment.Id)
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// internalServiceContext returns the context a call authenticated as service reaches the handler with.
+func internalServiceContext(t *testing.T, service string) context.Context {
+	var authenticated context.Context
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	mock.ExpectExec(`INSERT INTO access_audit_log`).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	interceptor := common.InternalAuthUnaryServerInterceptor(common.ServiceTokens{service: "0123456789abcdef"},
+		common.NewAccessAuditor(db, logger, "account-mgr-internal"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.ServiceTokenMetadataKey, "0123456789abcdef"))
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: pb.InternalAccountService_AdjustBalance_FullMethodName},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			authenticated = ctx
+			return nil, nil
+		})
+	require.NoError(t, err)
+	return authenticated
+}
+
+// fakeStreamAccountsStream is an in-memory server stream used to drive StreamAccounts in tests.
+type fakeStreamAccountsStream struct {
+	grpc.ServerStream
+	batches []*pb.StreamAccountsResponse
+}
+
+func (f *fakeStreamAccountsStream) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeStreamAccountsStream) Send(batch *pb.StreamAccountsResponse) error {
+	f.batches = append(f.batches, batch)
+	return nil
+}
+
+func TestAnalyticsService_StreamAccounts(t *testing.T) {
+	columns := []string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "version"}
+
+	t.Run("accounts are masked and streamed in batches", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		fullBatch := sqlmock.NewRows(columns)
+		for i := 0; i < analyticsBatchSize; i++ {
+			fullBatch.AddRow(fmt.Sprintf("acc-%04d", i), "12345678901", "CHECKING", 10.0, int64(100+i), int64(100+i), "ACTIVE", 1)
+		}
+		mock.ExpectQuery(`WHERE created_at < \$1 AND status = \$2 AND \(created_at, id\) > \(\$3, \$4\)\s+ORDER BY created_at, id\s+LIMIT 500`).
+			WithArgs(int64(10000), "ACTIVE", int64(-1), "").
+			WillReturnRows(fullBatch)
+		mock.ExpectQuery(`\(created_at, id\) > \(\$3, \$4\)`).
+			WithArgs(int64(10000), "ACTIVE", int64(100+analyticsBatchSize-1), fmt.Sprintf("acc-%04d", analyticsBatchSize-1)).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		logger, _ := common.NewLogger("test-service", common.INFO)
+		stream := &fakeStreamAccountsStream{}
+		err = NewAnalyticsService(db, logger).StreamAccounts(&pb.StreamAccountsRequest{CreatedTo: 10000, Status: "ACTIVE"}, stream)
+		require.NoError(t, err)
+
+		require.Len(t, stream.batches, 1)
+		assert.Empty(t, stream.batches[0].Error)
+		require.Len(t, stream.batches[0].Accounts, analyticsBatchSize)
+		first := stream.batches[0].Accounts[0]
+		assert.Equal(t, "acc-0000", first.Id)
+		assert.Equal(t, common.MaskDocumentNumber("12345678901"), first.DocumentNumber)
+		assert.Equal(t, int64(1000), first.BalanceCents)
+		assert.Empty(t, first.HolderName)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("invalid requests and replica errors are reported in the stream", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(`FROM accounts`).
+			WithArgs(int64(-1), "").
+			WillReturnError(sql.ErrConnDone)
+
+		logger, _ := common.NewLogger("test-service", common.INFO)
+		service := NewAnalyticsService(db, logger)
+		for request, expectedError := range map[*pb.StreamAccountsRequest]string{
+			{CreatedFrom: 200, CreatedTo: 100}: "created_from must be before created_to",
+			{Status: "FROZEN"}:                 "invalid status",
+			{}:                                 "database error",
+		} {
+			stream := &fakeStreamAccountsStream{}
+			require.NoError(t, service.StreamAccounts(request, stream))
+			require.Len(t, stream.batches, 1)
+			assert.Equal(t, expectedError, stream.batches[0].Error)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
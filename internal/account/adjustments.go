@@ -0,0 +1,268 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+	"github.com/google/uuid"
+)
+
+// adjustmentReasonCodes lists the reasons a manual balance adjustment may be requested for.
+var adjustmentReasonCodes = map[string]bool{
+	"CHARGEBACK_CORRECTION": true,
+	"DUPLICATE_CORRECTION":  true,
+	"FEE_REVERSAL":          true,
+	"GOODWILL_CREDIT":       true,
+	"MIGRATION_CORRECTION":  true,
+	"OTHER":                 true,
+}
+
+// adjustmentStatuses lists the states of a balance adjustment.
+var adjustmentStatuses = map[string]bool{
+	"PENDING":  true,
+	"APPROVED": true,
+	"REJECTED": true,
+}
+
+// maxListedAdjustments caps how many adjustments ListBalanceAdjustments returns.
+const maxListedAdjustments = 100
+
+const adjustmentColumns = `id, account_id, direction, amount, reason_code, COALESCE(description, ''), status,
+	requested_by, requested_at, COALESCE(reviewed_by, ''), COALESCE(reviewed_at, 0), COALESCE(review_note, ''),
+	COALESCE(source, ''), COALESCE(reference, '')`
+
+// adjustmentError is a review failure reported to the caller rather than logged as a database error.
+type adjustmentError string
+
+func (e adjustmentError) Error() string { return string(e) }
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanBalanceAdjustment reads a row selected with adjustmentColumns.
+func scanBalanceAdjustment(row rowScanner) (*pb.BalanceAdjustment, error) {
+	var adjustment pb.BalanceAdjustment
+	err := row.Scan(&adjustment.Id, &adjustment.AccountId, &adjustment.Direction, (*common.Cents)(&adjustment.AmountCents), &adjustment.ReasonCode,
+		&adjustment.Description, &adjustment.Status, &adjustment.RequestedBy, &adjustment.RequestedAt,
+		&adjustment.ReviewedBy, &adjustment.ReviewedAt, &adjustment.ReviewNote, &adjustment.Source, &adjustment.Reference)
+	if err != nil {
+		return nil, err
+	}
+	return &adjustment, nil
+}
+
+// RequestBalanceAdjustment records a manual credit or debit for an account as PENDING.
+// Support and admin operators may request adjustments; nothing is posted to the balance until
+// a different admin approves it with ReviewBalanceAdjustment.
+func (s *Service) RequestBalanceAdjustment(ctx context.Context, req *pb.RequestBalanceAdjustmentRequest) (*pb.BalanceAdjustmentResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	role := common.CallerRoleFromContext(ctx)
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.SupportOrAdminOperator) {
+		logger.Warn("Rejected balance adjustment request: AccountID=%s, Role=%q", req.AccountId, role)
+		return &pb.BalanceAdjustmentResponse{Error: "permission denied"}, nil
+	}
+
+	if req.AccountId == "" || req.ReasonCode == "" {
+		return &pb.BalanceAdjustmentResponse{Error: "missing required fields"}, nil
+	}
+	if req.Direction != "CREDIT" && req.Direction != "DEBIT" {
+		return &pb.BalanceAdjustmentResponse{Error: "direction must be CREDIT or DEBIT"}, nil
+	}
+	if req.AmountCents <= 0 {
+		return &pb.BalanceAdjustmentResponse{Error: "amount must be positive"}, nil
+	}
+	if !adjustmentReasonCodes[req.ReasonCode] {
+		return &pb.BalanceAdjustmentResponse{Error: "invalid reason code"}, nil
+	}
+
+	var exists bool
+	start := time.Now()
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM accounts WHERE id = $1)`, req.AccountId).Scan(&exists)
+	logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+	if err != nil {
+		logger.Error("Account check failed for balance adjustment: %v", err)
+		return &pb.BalanceAdjustmentResponse{Error: "database error"}, nil
+	}
+	if !exists {
+		return &pb.BalanceAdjustmentResponse{Error: "account not found"}, nil
+	}
+
+	adjustment := &pb.BalanceAdjustment{
+		Id:          uuid.New().String(),
+		AccountId:   req.AccountId,
+		Direction:   req.Direction,
+		AmountCents: req.AmountCents,
+		ReasonCode:  req.ReasonCode,
+		Description: req.Description,
+		Status:      "PENDING",
+		RequestedBy: operator,
+		RequestedAt: common.GetCurrentTimestamp(),
+	}
+
+	start = time.Now()
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO balance_adjustments (id, account_id, direction, amount, reason_code, description, status, requested_by, requested_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, adjustment.Id, adjustment.AccountId, adjustment.Direction, common.Cents(adjustment.AmountCents), adjustment.ReasonCode,
+		adjustment.Description, adjustment.Status, adjustment.RequestedBy, adjustment.RequestedAt)
+	logger.LogDatabase("INSERT", "balance_adjustments", time.Since(start), err)
+	if err != nil {
+		logger.Error("Balance adjustment request failed: %v", err)
+		return &pb.BalanceAdjustmentResponse{Error: "could not create adjustment"}, nil
+	}
+
+	logger.Info("Balance adjustment requested: ID=%s, AccountID=%s, Direction=%s, Amount=%s, Reason=%s, RequestedBy=%s",
+		adjustment.Id, adjustment.AccountId, adjustment.Direction, common.Cents(adjustment.AmountCents), adjustment.ReasonCode, operator)
+	return &pb.BalanceAdjustmentResponse{Adjustment: adjustment}, nil
+}
+
+// ReviewBalanceAdjustment approves or rejects a pending adjustment.
+// Only admins may review, and never their own requests. Approval posts the adjustment to the account
+// balance in the same database transaction that records the review, and fails if a debit would take
+// the balance below the account's overdraft limit.
+func (s *Service) ReviewBalanceAdjustment(ctx context.Context, req *pb.ReviewBalanceAdjustmentRequest) (*pb.BalanceAdjustmentResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected balance adjustment review: ID=%s, caller is not an admin", req.Id)
+		return &pb.BalanceAdjustmentResponse{Error: "permission denied"}, nil
+	}
+	if req.Id == "" {
+		return &pb.BalanceAdjustmentResponse{Error: "id required"}, nil
+	}
+
+	var adjustment *pb.BalanceAdjustment
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		start := time.Now()
+		adjustment, err = scanBalanceAdjustment(tx.QueryRowContext(ctx,
+			`SELECT `+adjustmentColumns+` FROM balance_adjustments WHERE id = $1 FOR UPDATE`, req.Id))
+		logger.LogDatabase("SELECT", "balance_adjustments", time.Since(start), err)
+		if err == sql.ErrNoRows {
+			return adjustmentError("adjustment not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		if adjustment.Status != "PENDING" {
+			return adjustmentError("adjustment already reviewed")
+		}
+		if adjustment.RequestedBy == operator {
+			return adjustmentError("adjustments must be reviewed by a different operator")
+		}
+
+		now := common.GetCurrentTimestamp()
+		adjustment.Status = "REJECTED"
+		if req.Approve {
+			adjustment.Status = "APPROVED"
+
+			delta := common.Cents(adjustment.AmountCents)
+			if adjustment.Direction == "DEBIT" {
+				delta = -delta
+			}
+			start = time.Now()
+			result, err := tx.ExecContext(ctx, `
+				UPDATE accounts
+				SET balance = balance + $1, updated_at = $2
+				WHERE id = $3 AND ($1 >= 0 OR balance + $1 >= -overdraft_limit)
+			`, delta, now, adjustment.AccountId)
+			logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+			if err != nil {
+				return err
+			}
+			if updated, err := result.RowsAffected(); err != nil {
+				return err
+			} else if updated == 0 {
+				return adjustmentError("insufficient balance")
+			}
+		}
+		adjustment.ReviewedBy = operator
+		adjustment.ReviewedAt = now
+		adjustment.ReviewNote = req.Note
+
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE balance_adjustments
+			SET status = $1, reviewed_by = $2, reviewed_at = $3, review_note = $4
+			WHERE id = $5
+		`, adjustment.Status, adjustment.ReviewedBy, adjustment.ReviewedAt, adjustment.ReviewNote, adjustment.Id)
+		logger.LogDatabase("UPDATE", "balance_adjustments", time.Since(start), err)
+		return err
+	})
+
+	var reviewErr adjustmentError
+	if errors.As(err, &reviewErr) {
+		return &pb.BalanceAdjustmentResponse{Error: reviewErr.Error()}, nil
+	}
+	if err != nil {
+		if common.IsCancellation(err) {
+			return &pb.BalanceAdjustmentResponse{Error: "request cancelled"}, nil
+		}
+		logger.Error("Balance adjustment review failed: ID=%s, Error=%v", req.Id, err)
+		return &pb.BalanceAdjustmentResponse{Error: "database error"}, nil
+	}
+
+	if adjustment.Status == "APPROVED" {
+		s.ledger.Invalidate(adjustment.AccountId)
+	}
+	logger.Info("Balance adjustment %s: ID=%s, AccountID=%s, ReviewedBy=%s",
+		adjustment.Status, adjustment.Id, adjustment.AccountId, operator)
+	return &pb.BalanceAdjustmentResponse{Adjustment: adjustment}, nil
+}
+
+// ListBalanceAdjustments returns the adjustments of an account with their approval trail, newest first.
+// Results can be filtered by status and are capped at maxListedAdjustments.
+func (s *Service) ListBalanceAdjustments(ctx context.Context, req *pb.ListBalanceAdjustmentsRequest) (*pb.ListBalanceAdjustmentsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.SupportOrAdmin) {
+		return &pb.ListBalanceAdjustmentsResponse{Error: "permission denied"}, nil
+	}
+	if req.AccountId == "" {
+		return &pb.ListBalanceAdjustmentsResponse{Error: "account_id required"}, nil
+	}
+	if req.Status != "" && !adjustmentStatuses[req.Status] {
+		return &pb.ListBalanceAdjustmentsResponse{Error: "invalid status"}, nil
+	}
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+adjustmentColumns+`
+		FROM balance_adjustments
+		WHERE account_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY requested_at DESC, id
+		LIMIT $3
+	`, req.AccountId, req.Status, maxListedAdjustments)
+	logger.LogDatabase("SELECT", "balance_adjustments", time.Since(start), err)
+	if err != nil {
+		logger.Error("Balance adjustment listing failed: %v", err)
+		return &pb.ListBalanceAdjustmentsResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	var adjustments []*pb.BalanceAdjustment
+	for rows.Next() {
+		adjustment, err := scanBalanceAdjustment(rows)
+		if err != nil {
+			logger.Error("Row scan failed: %v", err)
+			return &pb.ListBalanceAdjustmentsResponse{Error: "database error"}, nil
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Balance adjustment listing failed: %v", err)
+		return &pb.ListBalanceAdjustmentsResponse{Error: "database error"}, nil
+	}
+
+	return &pb.ListBalanceAdjustmentsResponse{Adjustments: adjustments}, nil
+}
@@ -0,0 +1,120 @@
+package account
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+)
+
+// analyticsBatchSize is the number of accounts read per query and sent per StreamAccountsResponse.
+const analyticsBatchSize = 500
+
+// analyticsAccountStatuses lists the states StreamAccounts can filter on.
+var analyticsAccountStatuses = map[string]bool{"DRAFT": true, "PENDING_KYC": true, "ACTIVE": true, "CLOSED": true}
+
+// AnalyticsService implements the AccountAnalyticsService, read-only streams of accounts for analytics
+// workloads. It reads from a read replica only, so its queries never hold primary connections.
+type AnalyticsService struct {
+	pb.UnimplementedAccountAnalyticsServiceServer
+	replica *sql.DB
+	logger  *common.Logger
+}
+
+// NewAnalyticsService creates the analytics service on top of a read replica connection.
+func NewAnalyticsService(replica *sql.DB, logger *common.Logger) *AnalyticsService {
+	return &AnalyticsService{replica: replica, logger: logger}
+}
+
+// StreamAccounts streams the accounts matching the request oldest first, in batches of analyticsBatchSize.
+// Each batch is a short query resuming after the last account sent, rather than one cursor held open for the
+// whole stream, so a slow consumer does not keep a long-running query on the replica. Document numbers are
+// masked and the holder's name, email and KYC reference are left out, as analytics has no use for them.
+func (s *AnalyticsService) StreamAccounts(req *pb.StreamAccountsRequest, stream pb.AccountAnalyticsService_StreamAccountsServer) error {
+	ctx := stream.Context()
+	logger := s.logger.WithContext(ctx)
+
+	if req.CreatedFrom < 0 || req.CreatedTo < 0 {
+		return stream.Send(&pb.StreamAccountsResponse{Error: "created_from and created_to must not be negative"})
+	}
+	if req.CreatedFrom > 0 && req.CreatedTo > 0 && req.CreatedFrom >= req.CreatedTo {
+		return stream.Send(&pb.StreamAccountsResponse{Error: "created_from must be before created_to"})
+	}
+	if req.Status != "" && !analyticsAccountStatuses[req.Status] {
+		return stream.Send(&pb.StreamAccountsResponse{Error: "invalid status"})
+	}
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if req.CreatedFrom > 0 {
+		addCondition("created_at >= $%d", req.CreatedFrom)
+	}
+	if req.CreatedTo > 0 {
+		addCondition("created_at < $%d", req.CreatedTo)
+	}
+	if req.Status != "" {
+		addCondition("status = $%d", req.Status)
+	}
+	// The position after the last account sent takes the last two arguments
+	conditions = append(conditions, fmt.Sprintf("(created_at, id) > ($%d, $%d)", len(args)+1, len(args)+2))
+	query := fmt.Sprintf(`
+		SELECT id, document_number, account_type, balance, created_at, updated_at, status, version
+		FROM accounts
+		WHERE %s
+		ORDER BY created_at, id
+		LIMIT %d
+	`, strings.Join(conditions, " AND "), analyticsBatchSize)
+
+	logger.Info("Streaming accounts: Filters=%d, Caller=%s", len(conditions)-1, common.InternalServiceFromContext(ctx))
+
+	afterCreatedAt, afterID := int64(-1), ""
+	streamed := 0
+	for {
+		start := time.Now()
+		rows, err := s.replica.QueryContext(ctx, query, append(args, afterCreatedAt, afterID)...)
+		logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+		if err != nil {
+			logger.Error("Account stream failed: Streamed=%d, Error=%v", streamed, err)
+			return stream.Send(&pb.StreamAccountsResponse{Error: "database error"})
+		}
+
+		var accounts []*pb.Account
+		for rows.Next() {
+			var account common.Account
+			if err := rows.Scan(&account.ID, &account.DocumentNumber, &account.AccountType, &account.Balance,
+				&account.CreatedAt, &account.UpdatedAt, &account.Status, &account.Version); err != nil {
+				rows.Close()
+				logger.Error("Account stream scan failed: Streamed=%d, Error=%v", streamed, err)
+				return stream.Send(&pb.StreamAccountsResponse{Error: "database error"})
+			}
+			afterCreatedAt, afterID = account.CreatedAt, account.ID
+			account.DocumentNumber = common.MaskDocumentNumber(account.DocumentNumber)
+			accounts = append(accounts, ConvertAccountToProto(&account))
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			logger.Error("Account stream failed: Streamed=%d, Error=%v", streamed, err)
+			return stream.Send(&pb.StreamAccountsResponse{Error: "database error"})
+		}
+
+		if len(accounts) > 0 {
+			if err := stream.Send(&pb.StreamAccountsResponse{Accounts: accounts}); err != nil {
+				return err
+			}
+			streamed += len(accounts)
+		}
+		if len(accounts) < analyticsBatchSize {
+			break
+		}
+	}
+
+	logger.Info("Accounts streamed: Count=%d", streamed)
+	return nil
+}
@@ -0,0 +1,187 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+	"github.com/google/uuid"
+)
+
+// Actions recorded in account_audit.
+const (
+	auditCreate       = "CREATE"
+	auditUpdate       = "UPDATE"
+	auditDelete       = "DELETE"
+	auditStatusChange = "STATUS_CHANGE"
+)
+
+// Limits on the entries GetAccountAudit returns.
+const (
+	defaultAccountAuditLimit = 100
+	maxAccountAuditLimit     = 500
+)
+
+// auditValues is the part of an account recorded in account_audit before and after a change. Balances are left
+// out: their movements are recorded by the ledger. The retention worker removes the holder data by these JSON
+// names when it anonymizes an account.
+type auditValues struct {
+	DocumentNumber string       `json:"document_number"`
+	AccountType    string       `json:"account_type"`
+	Status         string       `json:"status"`
+	HolderName     string       `json:"holder_name,omitempty"`
+	HolderEmail    string       `json:"holder_email,omitempty"`
+	HolderPhone    string       `json:"holder_phone,omitempty"`
+	KYCReference   string       `json:"kyc_reference,omitempty"`
+	OverdraftLimit common.Cents `json:"overdraft_limit"`
+	Tags           []string     `json:"tags,omitempty"`
+}
+
+// encodeAuditValues returns the recorded values of account as JSON, or an empty string for a nil account.
+func encodeAuditValues(account *common.Account) (string, error) {
+	if account == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(auditValues{
+		DocumentNumber: account.DocumentNumber,
+		AccountType:    account.AccountType,
+		Status:         account.Status,
+		HolderName:     account.HolderName,
+		HolderEmail:    account.HolderEmail,
+		HolderPhone:    account.HolderPhone,
+		KYCReference:   account.KYCReference,
+		OverdraftLimit: account.OverdraftLimit,
+		Tags:           account.Tags,
+	})
+	return string(data), err
+}
+
+// auditActor returns the principal and role of the request, as recorded in account_audit and account_snapshots.
+// Both come from caller-supplied metadata, so they are cut to the column sizes rather than fail the change.
+func auditActor(ctx context.Context) (actor, role string) {
+	actor = common.PrincipalFromContext(ctx)
+	if len(actor) > 100 {
+		actor = actor[:100]
+	}
+	role = common.CallerRoleFromContext(ctx)
+	if len(role) > 50 {
+		role = role[:50]
+	}
+	return actor, role
+}
+
+// recordAccountChange records a change to an account in account_audit within tx, made by the principal of the
+// request. previous is nil for creations and updated is nil for deletions.
+func (s *Service) recordAccountChange(ctx context.Context, tx *sql.Tx, accountID, action string, previous, updated *common.Account) error {
+	previousJSON, err := encodeAuditValues(previous)
+	if err != nil {
+		return err
+	}
+	updatedJSON, err := encodeAuditValues(updated)
+	if err != nil {
+		return err
+	}
+
+	actor, role := auditActor(ctx)
+	start := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO account_audit (id, account_id, action, actor, actor_role, changed_at, previous, updated)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::jsonb, NULLIF($8, '')::jsonb)
+	`, uuid.New().String(), accountID, action, actor, role, common.GetCurrentTimestamp(), previousJSON, updatedJSON)
+	s.logger.WithContext(ctx).LogDatabase("INSERT", "account_audit", time.Since(start), err)
+	return err
+}
+
+// GetAccountAudit returns the recorded changes to an account, newest first, for compliance review. Entries are
+// kept after the account is deleted. Document numbers are masked unless the caller may see them in full.
+func (s *Service) GetAccountAudit(ctx context.Context, req *pb.GetAccountAuditRequest) (*pb.GetAccountAuditResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.SupportOrAdmin) {
+		return &pb.GetAccountAuditResponse{Error: "permission denied"}, nil
+	}
+	if req.AccountId == "" {
+		return &pb.GetAccountAuditResponse{Error: "account_id required"}, nil
+	}
+	limit := req.Limit
+	switch {
+	case limit < 0:
+		return &pb.GetAccountAuditResponse{Error: "limit must not be negative"}, nil
+	case limit == 0:
+		limit = defaultAccountAuditLimit
+	case limit > maxAccountAuditLimit:
+		limit = maxAccountAuditLimit
+	}
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, account_id, action, actor, actor_role, changed_at, previous, updated
+		FROM account_audit
+		WHERE account_id = $1
+		ORDER BY changed_at DESC, id
+		LIMIT $2
+	`, req.AccountId, limit)
+	logger.LogDatabase("SELECT", "account_audit", time.Since(start), err)
+	if err != nil {
+		logger.Error("Account audit lookup failed: %v", err)
+		return &pb.GetAccountAuditResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	showFull := common.CanViewFullDocumentNumber(common.CallerRoleFromContext(ctx))
+	var entries []*pb.AccountAuditEntry
+	for rows.Next() {
+		var entry pb.AccountAuditEntry
+		var previous, updated []byte
+		if err := rows.Scan(&entry.Id, &entry.AccountId, &entry.Action, &entry.Actor, &entry.ActorRole, &entry.ChangedAt,
+			&previous, &updated); err != nil {
+			logger.Error("Row scan failed: %v", err)
+			return &pb.GetAccountAuditResponse{Error: "database error"}, nil
+		}
+		if entry.Previous, err = decodeAuditValues(previous, showFull); err != nil {
+			logger.Error("Invalid account audit entry %s: %v", entry.Id, err)
+			return &pb.GetAccountAuditResponse{Error: "database error"}, nil
+		}
+		if entry.Updated, err = decodeAuditValues(updated, showFull); err != nil {
+			logger.Error("Invalid account audit entry %s: %v", entry.Id, err)
+			return &pb.GetAccountAuditResponse{Error: "database error"}, nil
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Account audit lookup failed: %v", err)
+		return &pb.GetAccountAuditResponse{Error: "database error"}, nil
+	}
+
+	return &pb.GetAccountAuditResponse{Entries: entries}, nil
+}
+
+// decodeAuditValues converts values recorded in account_audit to their protobuf form, or nil if none were
+// recorded. The document number is masked unless showFull is set.
+func decodeAuditValues(data []byte, showFull bool) (*pb.AccountAuditValues, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var values auditValues
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("invalid audit values: %w", err)
+	}
+	if !showFull && values.DocumentNumber != "" {
+		values.DocumentNumber = common.MaskDocumentNumber(values.DocumentNumber)
+	}
+	return &pb.AccountAuditValues{
+		DocumentNumber:      values.DocumentNumber,
+		AccountType:         values.AccountType,
+		Status:              values.Status,
+		HolderName:          values.HolderName,
+		HolderEmail:         values.HolderEmail,
+		HolderPhone:         values.HolderPhone,
+		KycReference:        values.KYCReference,
+		OverdraftLimitCents: int64(values.OverdraftLimit),
+		Tags:                values.Tags,
+	}, nil
+}
@@ -0,0 +1,363 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+	"github.com/google/uuid"
+)
+
+// documentChangeStatuses lists the states of a document change.
+var documentChangeStatuses = map[string]bool{
+	"PENDING":  true,
+	"VERIFIED": true,
+	"REJECTED": true,
+	"APPLIED":  true,
+}
+
+// maxListedDocumentChanges caps how many changes ListDocumentChanges returns.
+const maxListedDocumentChanges = 100
+
+const documentChangeColumns = `id, account_id, previous_document_number, document_number, status, COALESCE(reason, ''),
+	requested_by, requested_at, COALESCE(verification_reference, ''), COALESCE(verified_by, ''), COALESCE(verified_at, 0),
+	COALESCE(verification_note, ''), COALESCE(applied_by, ''), COALESCE(applied_at, 0)`
+
+// documentChangeError is a document change failure reported to the caller rather than logged as a database error.
+type documentChangeError string
+
+func (e documentChangeError) Error() string { return string(e) }
+
+// scanDocumentChange reads a row selected with documentChangeColumns.
+func scanDocumentChange(row rowScanner) (*pb.DocumentChange, error) {
+	var change pb.DocumentChange
+	err := row.Scan(&change.Id, &change.AccountId, &change.PreviousDocumentNumber, &change.DocumentNumber, &change.Status,
+		&change.Reason, &change.RequestedBy, &change.RequestedAt, &change.VerificationReference, &change.VerifiedBy,
+		&change.VerifiedAt, &change.VerificationNote, &change.AppliedBy, &change.AppliedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// documentInUse reports whether an account other than accountID holds documentNumber.
+func documentInUse(ctx context.Context, tx *sql.Tx, documentNumber, accountID string) (bool, error) {
+	var inUse bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM accounts WHERE document_number = $1 AND id <> $2)`,
+		documentNumber, accountID).Scan(&inUse)
+	return inUse, err
+}
+
+// documentChangeFailure maps the error of a document change operation to the message returned to the caller.
+func documentChangeFailure(logger *common.Logger, operation string, err error) string {
+	var changeErr documentChangeError
+	switch {
+	case errors.As(err, &changeErr):
+		return changeErr.Error()
+	case common.IsCancellation(err):
+		return "request cancelled"
+	default:
+		logger.Error("%s failed: %v", operation, err)
+		return "database error"
+	}
+}
+
+// RequestDocumentChange records a change of an account's document number as PENDING.
+// Support and admin operators may request changes; the account keeps its document number until a different
+// admin verifies the change with VerifyDocumentChange and it is applied with ApplyDocumentChange.
+// An account has at most one change in progress, and a number held by another account cannot be requested.
+func (s *Service) RequestDocumentChange(ctx context.Context, req *pb.RequestDocumentChangeRequest) (*pb.DocumentChangeResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	role := common.CallerRoleFromContext(ctx)
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.SupportOrAdminOperator) {
+		logger.Warn("Rejected document change request: AccountID=%s, Role=%q", req.AccountId, role)
+		return &pb.DocumentChangeResponse{Error: "permission denied"}, nil
+	}
+
+	if req.AccountId == "" || req.DocumentNumber == "" {
+		return &pb.DocumentChangeResponse{Error: "missing required fields"}, nil
+	}
+	if len(req.DocumentNumber) > 20 {
+		return &pb.DocumentChangeResponse{Error: "document_number must be at most 20 characters"}, nil
+	}
+	documentNumber, msg := s.checkDocumentNumber(ctx, req.DocumentNumber)
+	if msg != "" {
+		return &pb.DocumentChangeResponse{Error: msg}, nil
+	}
+	req.DocumentNumber = documentNumber
+
+	var change *pb.DocumentChange
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		account, err := s.lockAccount(ctx, tx, req.AccountId)
+		if errors.Is(err, onboardingError("account not found")) {
+			return documentChangeError("account not found")
+		}
+		if err != nil {
+			return err
+		}
+		if account.DocumentNumber == req.DocumentNumber {
+			return documentChangeError("document number unchanged")
+		}
+
+		start := time.Now()
+		inUse, err := documentInUse(ctx, tx, req.DocumentNumber, req.AccountId)
+		logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return documentChangeError("document number already in use")
+		}
+
+		var open bool
+		start = time.Now()
+		err = tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM document_changes WHERE account_id = $1 AND status IN ('PENDING', 'VERIFIED'))
+		`, req.AccountId).Scan(&open)
+		logger.LogDatabase("SELECT", "document_changes", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		if open {
+			return documentChangeError("document change already in progress")
+		}
+
+		change = &pb.DocumentChange{
+			Id:                     uuid.New().String(),
+			AccountId:              req.AccountId,
+			PreviousDocumentNumber: account.DocumentNumber,
+			DocumentNumber:         req.DocumentNumber,
+			Status:                 "PENDING",
+			Reason:                 req.Reason,
+			RequestedBy:            operator,
+			RequestedAt:            common.GetCurrentTimestamp(),
+		}
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO document_changes (id, account_id, previous_document_number, document_number, status, reason, requested_by, requested_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, change.Id, change.AccountId, change.PreviousDocumentNumber, change.DocumentNumber, change.Status,
+			change.Reason, change.RequestedBy, change.RequestedAt)
+		logger.LogDatabase("INSERT", "document_changes", time.Since(start), err)
+		return err
+	})
+	if err != nil {
+		return &pb.DocumentChangeResponse{Error: documentChangeFailure(logger, "Document change request", err)}, nil
+	}
+
+	logger.Info("Document change requested: ID=%s, AccountID=%s, RequestedBy=%s", change.Id, change.AccountId, operator)
+	return &pb.DocumentChangeResponse{Change: change}, nil
+}
+
+// VerifyDocumentChange verifies or rejects a pending document change.
+// Only admins may verify, and never their own requests; verifying requires the reference of the document
+// check the admin relied on.
+func (s *Service) VerifyDocumentChange(ctx context.Context, req *pb.VerifyDocumentChangeRequest) (*pb.DocumentChangeResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected document change verification: ID=%s, caller is not an admin", req.Id)
+		return &pb.DocumentChangeResponse{Error: "permission denied"}, nil
+	}
+	if req.Id == "" {
+		return &pb.DocumentChangeResponse{Error: "id required"}, nil
+	}
+	if req.Approve && req.VerificationReference == "" {
+		return &pb.DocumentChangeResponse{Error: "verification_reference required"}, nil
+	}
+	if len(req.VerificationReference) > 100 {
+		return &pb.DocumentChangeResponse{Error: "verification_reference too long"}, nil
+	}
+
+	var change *pb.DocumentChange
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		start := time.Now()
+		change, err = scanDocumentChange(tx.QueryRowContext(ctx,
+			`SELECT `+documentChangeColumns+` FROM document_changes WHERE id = $1 FOR UPDATE`, req.Id))
+		logger.LogDatabase("SELECT", "document_changes", time.Since(start), err)
+		if err == sql.ErrNoRows {
+			return documentChangeError("document change not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		if change.Status != "PENDING" {
+			return documentChangeError("document change already verified")
+		}
+		if change.RequestedBy == operator {
+			return documentChangeError("document changes must be verified by a different operator")
+		}
+
+		change.Status = "REJECTED"
+		if req.Approve {
+			change.Status = "VERIFIED"
+		}
+		change.VerificationReference = req.VerificationReference
+		change.VerifiedBy = operator
+		change.VerifiedAt = common.GetCurrentTimestamp()
+		change.VerificationNote = req.Note
+
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE document_changes
+			SET status = $1, verification_reference = $2, verified_by = $3, verified_at = $4, verification_note = $5
+			WHERE id = $6
+		`, change.Status, change.VerificationReference, change.VerifiedBy, change.VerifiedAt, change.VerificationNote, change.Id)
+		logger.LogDatabase("UPDATE", "document_changes", time.Since(start), err)
+		return err
+	})
+	if err != nil {
+		return &pb.DocumentChangeResponse{Error: documentChangeFailure(logger, "Document change verification", err)}, nil
+	}
+
+	logger.Info("Document change %s: ID=%s, AccountID=%s, VerifiedBy=%s", change.Status, change.Id, change.AccountId, operator)
+	return &pb.DocumentChangeResponse{Change: change}, nil
+}
+
+// ApplyDocumentChange sets the document number of the account to that of a verified change.
+// The change fails if the account's document number moved on since the request, or if another account took
+// the new number meanwhile; the account update and the APPLIED record are written in one database transaction.
+func (s *Service) ApplyDocumentChange(ctx context.Context, req *pb.ApplyDocumentChangeRequest) (*pb.DocumentChangeResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.SupportOrAdminOperator) {
+		logger.Warn("Rejected document change application: ID=%s", req.Id)
+		return &pb.DocumentChangeResponse{Error: "permission denied"}, nil
+	}
+	if req.Id == "" {
+		return &pb.DocumentChangeResponse{Error: "id required"}, nil
+	}
+
+	var change *pb.DocumentChange
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		start := time.Now()
+		change, err = scanDocumentChange(tx.QueryRowContext(ctx,
+			`SELECT `+documentChangeColumns+` FROM document_changes WHERE id = $1 FOR UPDATE`, req.Id))
+		logger.LogDatabase("SELECT", "document_changes", time.Since(start), err)
+		if err == sql.ErrNoRows {
+			return documentChangeError("document change not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		switch change.Status {
+		case "VERIFIED":
+		case "APPLIED":
+			return documentChangeError("document change already applied")
+		default:
+			return documentChangeError("document change not verified")
+		}
+
+		account, err := s.lockAccount(ctx, tx, change.AccountId)
+		if errors.Is(err, onboardingError("account not found")) {
+			return documentChangeError("account not found")
+		}
+		if err != nil {
+			return err
+		}
+		if account.DocumentNumber != change.PreviousDocumentNumber {
+			return documentChangeError("document number changed since the request")
+		}
+
+		start = time.Now()
+		inUse, err := documentInUse(ctx, tx, change.DocumentNumber, change.AccountId)
+		logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return documentChangeError("document number already in use")
+		}
+
+		now := common.GetCurrentTimestamp()
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE accounts
+			SET document_number = $1, updated_at = $2, version = version + 1
+			WHERE id = $3
+		`, change.DocumentNumber, now, change.AccountId)
+		logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		previous := *account
+		account.DocumentNumber = change.DocumentNumber
+		if err := s.recordAccountChange(ctx, tx, account.ID, auditUpdate, &previous, account); err != nil {
+			return err
+		}
+
+		change.Status = "APPLIED"
+		change.AppliedBy = operator
+		change.AppliedAt = now
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE document_changes SET status = $1, applied_by = $2, applied_at = $3 WHERE id = $4
+		`, change.Status, change.AppliedBy, change.AppliedAt, change.Id)
+		logger.LogDatabase("UPDATE", "document_changes", time.Since(start), err)
+		return err
+	})
+	if err != nil {
+		return &pb.DocumentChangeResponse{Error: documentChangeFailure(logger, "Document change application", err)}, nil
+	}
+
+	logger.Info("Document change applied: ID=%s, AccountID=%s, AppliedBy=%s", change.Id, change.AccountId, operator)
+	return &pb.DocumentChangeResponse{Change: change}, nil
+}
+
+// ListDocumentChanges returns the document changes of an account with their audit trail, newest first.
+// Results can be filtered by status and are capped at maxListedDocumentChanges.
+func (s *Service) ListDocumentChanges(ctx context.Context, req *pb.ListDocumentChangesRequest) (*pb.ListDocumentChangesResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.SupportOrAdmin) {
+		return &pb.ListDocumentChangesResponse{Error: "permission denied"}, nil
+	}
+	if req.AccountId == "" {
+		return &pb.ListDocumentChangesResponse{Error: "account_id required"}, nil
+	}
+	if req.Status != "" && !documentChangeStatuses[req.Status] {
+		return &pb.ListDocumentChangesResponse{Error: "invalid status"}, nil
+	}
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+documentChangeColumns+`
+		FROM document_changes
+		WHERE account_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY requested_at DESC, id
+		LIMIT $3
+	`, req.AccountId, req.Status, maxListedDocumentChanges)
+	logger.LogDatabase("SELECT", "document_changes", time.Since(start), err)
+	if err != nil {
+		logger.Error("Document change listing failed: %v", err)
+		return &pb.ListDocumentChangesResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	var changes []*pb.DocumentChange
+	for rows.Next() {
+		change, err := scanDocumentChange(rows)
+		if err != nil {
+			logger.Error("Row scan failed: %v", err)
+			return &pb.ListDocumentChangesResponse{Error: "database error"}, nil
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Document change listing failed: %v", err)
+		return &pb.ListDocumentChangesResponse{Error: "database error"}, nil
+	}
+
+	return &pb.ListDocumentChangesResponse{Changes: changes}, nil
+}
@@ -0,0 +1,398 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+)
+
+// DefaultFXRevaluationInterval is how often the FX revaluation job looks for a month to revalue.
+const DefaultFXRevaluationInterval = time.Hour
+
+// fxPeriodLayout is the format of revaluation periods.
+const fxPeriodLayout = "2006-01"
+
+// secondsPerDay is the length of a rate day and of a rollup day.
+const secondsPerDay = 24 * 60 * 60
+
+// fxRate is the rate of one day: reporting currency per unit of account currency.
+type fxRate struct {
+	date int64
+	rate float64
+}
+
+// fxRateSeries holds the daily rates of a currency pair, oldest first.
+type fxRateSeries []fxRate
+
+// at returns the latest rate dated at or before t. Movements older than the first known rate
+// are valued at that first rate.
+func (s fxRateSeries) at(t int64) float64 {
+	i := sort.Search(len(s), func(i int) bool { return s[i].date > t })
+	if i == 0 {
+		return s[0].rate
+	}
+	return s[i-1].rate
+}
+
+// fxRevaluationEntry is the revaluation of one account for one period.
+type fxRevaluationEntry struct {
+	accountID      string
+	balance        common.Cents
+	closingRate    float64
+	carryingValue  common.Cents
+	revaluedValue  common.Cents
+	unrealizedGain common.Cents
+}
+
+// FXRevaluationJob posts month-end FX revaluation entries for tenants whose balances are held in a currency
+// other than their reporting currency (see TenantSettings.RevaluesFX). For every account it values the balance
+// at the end of the month at the closing rate and records the difference to its carrying value, i.e. the value
+// after the previous revaluation plus the movements since, each at the rate of its day, as an unrealized gain
+// or loss in fx_revaluations. Account balances are left unchanged.
+//
+// A month is revalued once the rate of its last day is in fx_rates. Accounts already revalued for the month
+// are skipped, so the job can run repeatedly and on several replicas.
+type FXRevaluationJob struct {
+	db      *sql.DB
+	logger  *common.Logger
+	tenants *common.TenantConfigStore
+	now     func() time.Time
+}
+
+// NewFXRevaluationJob creates a revaluation job for the tenants of the environment named by APP_ENV.
+func NewFXRevaluationJob(db *sql.DB, logger *common.Logger) *FXRevaluationJob {
+	return &FXRevaluationJob{
+		db:      db,
+		logger:  logger,
+		tenants: common.NewTenantConfigStoreFromEnv(db),
+		now:     time.Now,
+	}
+}
+
+// FXRevaluationIntervalFromEnv returns the FX_REVALUATION_INTERVAL duration, defaulting to DefaultFXRevaluationInterval.
+func FXRevaluationIntervalFromEnv() time.Duration {
+	interval, err := time.ParseDuration(os.Getenv("FX_REVALUATION_INTERVAL"))
+	if err != nil || interval <= 0 {
+		return DefaultFXRevaluationInterval
+	}
+	return interval
+}
+
+// Run revalues the last completed month on every interval until ctx is cancelled.
+// It is intended to be run in its own goroutine.
+func (j *FXRevaluationJob) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		j.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce revalues the last completed month for every tenant that needs it.
+// A failing tenant is logged and does not stop the others.
+func (j *FXRevaluationJob) RunOnce(ctx context.Context) {
+	tenants, err := j.tenants.List(ctx)
+	if err != nil {
+		j.logger.Error("FX revaluation skipped: %v", err)
+		return
+	}
+
+	now := j.now().UTC()
+	period := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+
+	ids := make([]string, 0, len(tenants))
+	for id := range tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, tenantID := range ids {
+		settings := tenants[tenantID]
+		if !settings.RevaluesFX() {
+			continue
+		}
+		posted, err := j.Revalue(ctx, tenantID, settings, period)
+		if err != nil {
+			j.logger.Error("FX revaluation of %s failed: TenantID=%s, Error=%v", period.Format(fxPeriodLayout), tenantID, err)
+			continue
+		}
+		if posted > 0 {
+			j.logger.Info("FX revaluation of %s posted: TenantID=%s, Accounts=%d, %s/%s",
+				period.Format(fxPeriodLayout), tenantID, posted, settings.Currency, settings.ReportingCurrency)
+		}
+	}
+}
+
+// Revalue posts the revaluation entries of the month starting at period for the tenant's accounts that
+// have none yet, and returns how many were posted. All entries of a run are written in one transaction.
+func (j *FXRevaluationJob) Revalue(ctx context.Context, tenantID string, settings common.TenantSettings, period time.Time) (int, error) {
+	logger := j.logger.WithContext(ctx)
+	start, end := period.Unix(), period.AddDate(0, 1, 0).Unix()
+	name := period.Format(fxPeriodLayout)
+
+	rates, err := j.loadRates(ctx, settings.Currency, settings.ReportingCurrency, end)
+	if err != nil {
+		return 0, err
+	}
+	if len(rates) == 0 || rates[len(rates)-1].date < end-secondsPerDay {
+		return 0, fmt.Errorf("no %s/%s rate for the last day of %s", settings.Currency, settings.ReportingCurrency, name)
+	}
+	closingRate := rates[len(rates)-1].rate
+
+	type account struct {
+		id        string
+		opening   common.Cents
+		createdAt int64
+	}
+	queryStart := time.Now()
+	rows, err := j.db.QueryContext(ctx, `
+		SELECT id, COALESCE(opening_balance, 0), created_at FROM accounts a
+		WHERE tenant_id = $1 AND created_at < $2 AND (closed_at IS NULL OR closed_at >= $3)
+			AND NOT EXISTS (SELECT 1 FROM fx_revaluations r WHERE r.account_id = a.id AND r.period = $4)
+		ORDER BY id
+	`, tenantID, end, start, name)
+	logger.LogDatabase("SELECT", "accounts", time.Since(queryStart), err)
+	if err != nil {
+		return 0, err
+	}
+	var accounts []account
+	for rows.Next() {
+		var a account
+		if err := rows.Scan(&a.id, &a.opening, &a.createdAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		accounts = append(accounts, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(accounts) == 0 {
+		return 0, nil
+	}
+
+	entries := make([]fxRevaluationEntry, 0, len(accounts))
+	for _, a := range accounts {
+		entry, err := j.revalueAccount(ctx, a.id, a.opening, a.createdAt, name, end, rates)
+		if err != nil {
+			return 0, fmt.Errorf("account %s: %w", a.id, err)
+		}
+		entry.closingRate = closingRate
+		entry.revaluedValue = entry.balance.MulRate(closingRate)
+		entry.unrealizedGain = entry.revaluedValue - entry.carryingValue
+		entries = append(entries, entry)
+	}
+
+	posted := 0
+	now := common.GetCurrentTimestamp()
+	err = common.WithTransaction(ctx, j.db, func(tx *sql.Tx) error {
+		for _, entry := range entries {
+			start := time.Now()
+			result, err := tx.ExecContext(ctx, `
+				INSERT INTO fx_revaluations (tenant_id, account_id, period, currency, reporting_currency, balance,
+					closing_rate, carrying_value, revalued_value, unrealized_gain, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+				ON CONFLICT (account_id, period) DO NOTHING
+			`, tenantID, entry.accountID, name, settings.Currency, settings.ReportingCurrency, entry.balance,
+				entry.closingRate, entry.carryingValue, entry.revaluedValue, entry.unrealizedGain, now)
+			logger.LogDatabase("INSERT", "fx_revaluations", time.Since(start), err)
+			if err != nil {
+				return err
+			}
+			if n, _ := result.RowsAffected(); n > 0 {
+				posted++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return posted, nil
+}
+
+// loadRates returns the daily rates of a currency pair dated before end, oldest first.
+func (j *FXRevaluationJob) loadRates(ctx context.Context, currency, reportingCurrency string, end int64) (fxRateSeries, error) {
+	start := time.Now()
+	rows, err := j.db.QueryContext(ctx, `
+		SELECT rate_date, rate FROM fx_rates
+		WHERE base_currency = $1 AND quote_currency = $2 AND rate_date < $3
+		ORDER BY rate_date
+	`, currency, reportingCurrency, end)
+	j.logger.WithContext(ctx).LogDatabase("SELECT", "fx_rates", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates fxRateSeries
+	for rows.Next() {
+		var rate fxRate
+		if err := rows.Scan(&rate.date, &rate.rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// revalueAccount computes the balance of an account at end and its carrying value before revaluation.
+// The carrying value starts from the account's latest revaluation; for an account never revalued,
+// from its opening balance at the rate of the day it was opened.
+func (j *FXRevaluationJob) revalueAccount(ctx context.Context, accountID string, opening common.Cents, createdAt int64, period string, end int64, rates fxRateSeries) (fxRevaluationEntry, error) {
+	logger := j.logger.WithContext(ctx)
+	entry := fxRevaluationEntry{accountID: accountID, balance: opening}
+
+	// Movements since the previous revaluation are valued at their day's rate
+	var previousPeriod string
+	var previousValue common.Cents
+	start := time.Now()
+	err := j.db.QueryRowContext(ctx, `
+		SELECT period, revalued_value FROM fx_revaluations
+		WHERE account_id = $1 AND period < $2
+		ORDER BY period DESC LIMIT 1
+	`, accountID, period).Scan(&previousPeriod, &previousValue)
+	logger.LogDatabase("SELECT", "fx_revaluations", time.Since(start), err)
+	// The carrying value sums amounts at different rates, so it is only rounded to cents once complete
+	var since int64
+	var carryingValue float64
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		carryingValue = opening.Float64() * rates.at(createdAt)
+	case err != nil:
+		return entry, err
+	default:
+		previous, err := time.Parse(fxPeriodLayout, previousPeriod)
+		if err != nil {
+			return entry, fmt.Errorf("invalid period %q: %w", previousPeriod, err)
+		}
+		carryingValue = previousValue.Float64()
+		since = previous.AddDate(0, 1, 0).Unix()
+	}
+
+	// Daily movements from the ledger: completed transactions and approved adjustments
+	start = time.Now()
+	rows, err := j.db.QueryContext(ctx, `
+		SELECT day, SUM(amount) FROM (
+			SELECT day_start AS day, total_amount AS amount FROM transaction_daily_rollups
+			WHERE account_id = $1 AND day_start < $2
+			UNION ALL
+			SELECT reviewed_at - reviewed_at % 86400, CASE WHEN direction = 'CREDIT' THEN amount ELSE -amount END
+			FROM balance_adjustments
+			WHERE account_id = $1 AND status = 'APPROVED' AND reviewed_at < $2
+		) movements
+		GROUP BY day ORDER BY day
+	`, accountID, end)
+	logger.LogDatabase("SELECT", "transaction_daily_rollups", time.Since(start), err)
+	if err != nil {
+		return entry, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day int64
+		var amount common.Cents
+		if err := rows.Scan(&day, &amount); err != nil {
+			return entry, err
+		}
+		entry.balance += amount
+		if day >= since {
+			carryingValue += amount.Float64() * rates.at(day)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return entry, err
+	}
+
+	entry.carryingValue = common.CentsFromFloat(carryingValue)
+	return entry, nil
+}
+
+// GetFxRevaluationReport returns the FX revaluation entries posted for a tenant for one month,
+// with their total unrealized gain or loss in the tenant's reporting currency. Entries of accounts tagged
+// with one of the report-excluded tags, e.g. TEST accounts, are left out. Support and admin operators may read
+// the report.
+func (s *Service) GetFxRevaluationReport(ctx context.Context, req *pb.GetFxRevaluationReportRequest) (*pb.GetFxRevaluationReportResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.SupportOrAdmin) {
+		return &pb.GetFxRevaluationReportResponse{Error: "permission denied"}, nil
+	}
+	if _, err := time.Parse(fxPeriodLayout, req.Period); err != nil {
+		return &pb.GetFxRevaluationReportResponse{Error: "period must be YYYY-MM"}, nil
+	}
+
+	settings, err := s.tenants.Get(ctx, req.TenantId)
+	if err == common.ErrInvalidTenantID {
+		return &pb.GetFxRevaluationReportResponse{Error: "invalid tenant id"}, nil
+	}
+	if err != nil {
+		logger.Error("Tenant settings lookup failed: TenantID=%s, Error=%v", req.TenantId, err)
+		return &pb.GetFxRevaluationReportResponse{Error: "database error"}, nil
+	}
+
+	resp := &pb.GetFxRevaluationReportResponse{
+		TenantId:          req.TenantId,
+		Period:            req.Period,
+		Currency:          settings.Currency,
+		ReportingCurrency: settings.ReportingCurrency,
+	}
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT account_id, currency, reporting_currency, balance, closing_rate, carrying_value, revalued_value,
+			unrealized_gain, created_at
+		FROM fx_revaluations r
+		WHERE tenant_id = $1 AND period = $2
+			AND NOT EXISTS (
+				SELECT 1 FROM accounts a
+				WHERE a.id = r.account_id AND string_to_array(a.tags, ',') && string_to_array($3, ',')
+			)
+		ORDER BY account_id
+	`, req.TenantId, req.Period, strings.Join(s.reportExcludedTags, ","))
+	logger.LogDatabase("SELECT", "fx_revaluations", time.Since(start), err)
+	if err != nil {
+		if common.IsCancellation(err) {
+			return &pb.GetFxRevaluationReportResponse{Error: "request cancelled"}, nil
+		}
+		logger.Error("FX revaluation report failed: %v", err)
+		return &pb.GetFxRevaluationReportResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	var total common.Cents
+	for rows.Next() {
+		var revaluation pb.FxRevaluation
+		// The currencies the entries were posted in win over the current settings
+		if err := rows.Scan(&revaluation.AccountId, &resp.Currency, &resp.ReportingCurrency,
+			(*common.Cents)(&revaluation.BalanceCents), &revaluation.ClosingRate, (*common.Cents)(&revaluation.CarryingValueCents),
+			(*common.Cents)(&revaluation.RevaluedValueCents), (*common.Cents)(&revaluation.UnrealizedGainCents), &revaluation.CreatedAt); err != nil {
+			logger.Error("Row scan failed: %v", err)
+			return &pb.GetFxRevaluationReportResponse{Error: "database error"}, nil
+		}
+		total += common.Cents(revaluation.UnrealizedGainCents)
+		resp.Revaluations = append(resp.Revaluations, &revaluation)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("FX revaluation report failed: %v", err)
+		return &pb.GetFxRevaluationReportResponse{Error: "database error"}, nil
+	}
+
+	resp.TotalUnrealizedGainCents = int64(total)
+	return resp, nil
+}
@@ -0,0 +1,168 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+	"github.com/google/uuid"
+)
+
+// internalAdjustmentReasonCodes lists the reasons other services may post balance adjustments for.
+var internalAdjustmentReasonCodes = map[string]bool{
+	"INTEREST_ACCRUAL": true,
+	"FEE_CHARGE":       true,
+	"FEE_REFUND":       true,
+	"SETTLEMENT":       true,
+}
+
+// maxAdjustmentReferenceLength is the size of the balance_adjustments.reference column.
+const maxAdjustmentReferenceLength = 100
+
+// InternalService implements the InternalAccountService, the account operations of other services of the platform.
+// It is served apart from the AccountService, behind common.InternalAuthUnaryServerInterceptor.
+type InternalService struct {
+	pb.UnimplementedInternalAccountServiceServer
+	accounts *Service
+}
+
+// NewInternalService creates the internal service on top of the account service.
+func NewInternalService(accounts *Service) *InternalService {
+	return &InternalService{accounts: accounts}
+}
+
+// AdjustBalance posts a credit or debit to an account on behalf of the authenticated service, without the
+// maker-checker review of operator adjustments. It is recorded as an APPROVED balance adjustment with the
+// service as requester and reviewer, its source and its reference, in the same database transaction that
+// changes the balance. A debit that would take the balance below the account's overdraft limit is refused;
+// credits are always posted, even to an overdrawn account.
+// The reference makes retries safe: a second call with the same reference returns the adjustment already
+// posted, or fails if it describes a different adjustment.
+func (s *InternalService) AdjustBalance(ctx context.Context, req *pb.AdjustBalanceRequest) (*pb.BalanceAdjustmentResponse, error) {
+	logger := s.accounts.logger.WithContext(ctx)
+
+	service := common.InternalServiceFromContext(ctx)
+	if service == "" {
+		logger.Warn("Rejected internal balance adjustment: AccountID=%s, caller is not an authenticated service", req.AccountId)
+		return &pb.BalanceAdjustmentResponse{Error: "permission denied"}, nil
+	}
+
+	if req.AccountId == "" || req.ReasonCode == "" || req.Reference == "" {
+		return &pb.BalanceAdjustmentResponse{Error: "missing required fields"}, nil
+	}
+	if req.Direction != "CREDIT" && req.Direction != "DEBIT" {
+		return &pb.BalanceAdjustmentResponse{Error: "direction must be CREDIT or DEBIT"}, nil
+	}
+	if req.AmountCents <= 0 {
+		return &pb.BalanceAdjustmentResponse{Error: "amount must be positive"}, nil
+	}
+	if !internalAdjustmentReasonCodes[req.ReasonCode] {
+		return &pb.BalanceAdjustmentResponse{Error: "invalid reason code"}, nil
+	}
+	if len(req.Reference) > maxAdjustmentReferenceLength {
+		return &pb.BalanceAdjustmentResponse{Error: "reference too long"}, nil
+	}
+
+	principal := "service:" + service
+	now := common.GetCurrentTimestamp()
+	adjustment := &pb.BalanceAdjustment{
+		Id:          uuid.New().String(),
+		AccountId:   req.AccountId,
+		Direction:   req.Direction,
+		AmountCents: req.AmountCents,
+		ReasonCode:  req.ReasonCode,
+		Description: req.Description,
+		Status:      "APPROVED",
+		RequestedBy: principal,
+		RequestedAt: now,
+		ReviewedBy:  principal,
+		ReviewedAt:  now,
+		Source:      service,
+		Reference:   req.Reference,
+	}
+
+	replayed := false
+	err := common.WithTransaction(ctx, s.accounts.db, func(tx *sql.Tx) error {
+		if _, err := s.accounts.lockAccount(ctx, tx, req.AccountId); err != nil {
+			if errors.Is(err, onboardingError("account not found")) {
+				return adjustmentError("account not found")
+			}
+			return err
+		}
+
+		// The account lock serializes retries of the same reference, so the lookup cannot miss a concurrent post
+		start := time.Now()
+		existing, err := scanBalanceAdjustment(tx.QueryRowContext(ctx,
+			`SELECT `+adjustmentColumns+` FROM balance_adjustments WHERE source = $1 AND reference = $2`, service, req.Reference))
+		logger.LogDatabase("SELECT", "balance_adjustments", time.Since(start), err)
+		switch {
+		case err == nil:
+			if existing.AccountId != req.AccountId || existing.Direction != req.Direction ||
+				existing.AmountCents != req.AmountCents || existing.ReasonCode != req.ReasonCode {
+				return adjustmentError("reference already used for another adjustment")
+			}
+			adjustment, replayed = existing, true
+			return nil
+		case err != sql.ErrNoRows:
+			return err
+		}
+
+		delta := common.Cents(adjustment.AmountCents)
+		if adjustment.Direction == "DEBIT" {
+			delta = -delta
+		}
+		start = time.Now()
+		result, err := tx.ExecContext(ctx, `
+			UPDATE accounts
+			SET balance = balance + $1, updated_at = $2
+			WHERE id = $3 AND ($1 >= 0 OR balance + $1 >= -overdraft_limit)
+		`, delta, now, adjustment.AccountId)
+		logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		if updated, err := result.RowsAffected(); err != nil {
+			return err
+		} else if updated == 0 {
+			return adjustmentError("insufficient balance")
+		}
+
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO balance_adjustments (id, account_id, direction, amount, reason_code, description, status,
+				requested_by, requested_at, reviewed_by, reviewed_at, source, reference)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		`, adjustment.Id, adjustment.AccountId, adjustment.Direction, common.Cents(adjustment.AmountCents), adjustment.ReasonCode,
+			adjustment.Description, adjustment.Status, adjustment.RequestedBy, adjustment.RequestedAt,
+			adjustment.ReviewedBy, adjustment.ReviewedAt, adjustment.Source, adjustment.Reference)
+		logger.LogDatabase("INSERT", "balance_adjustments", time.Since(start), err)
+		return err
+	})
+
+	var adjustErr adjustmentError
+	if errors.As(err, &adjustErr) {
+		logger.Warn("Internal balance adjustment refused: Service=%s, AccountID=%s, Reference=%s, Error=%s",
+			service, req.AccountId, req.Reference, adjustErr.Error())
+		return &pb.BalanceAdjustmentResponse{Error: adjustErr.Error()}, nil
+	}
+	if err != nil {
+		if common.IsCancellation(err) {
+			return &pb.BalanceAdjustmentResponse{Error: "request cancelled"}, nil
+		}
+		logger.Error("Internal balance adjustment failed: Service=%s, AccountID=%s, Reference=%s, Error=%v",
+			service, req.AccountId, req.Reference, err)
+		return &pb.BalanceAdjustmentResponse{Error: "database error"}, nil
+	}
+
+	if replayed {
+		logger.Info("Internal balance adjustment replayed: ID=%s, Service=%s, Reference=%s", adjustment.Id, service, req.Reference)
+		return &pb.BalanceAdjustmentResponse{Adjustment: adjustment}, nil
+	}
+	s.accounts.ledger.Invalidate(adjustment.AccountId)
+	logger.Info("Internal balance adjustment posted: ID=%s, Service=%s, AccountID=%s, Direction=%s, Amount=%s, Reason=%s, Reference=%s",
+		adjustment.Id, service, adjustment.AccountId, adjustment.Direction, common.Cents(adjustment.AmountCents), adjustment.ReasonCode, req.Reference)
+	return &pb.BalanceAdjustmentResponse{Adjustment: adjustment}, nil
+}
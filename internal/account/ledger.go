@@ -0,0 +1,84 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+)
+
+// callerTenantSettings returns the settings of the tenant the request is made on behalf of,
+// or zero settings without a tenant. On failure it returns the error message for the caller.
+func (s *Service) callerTenantSettings(ctx context.Context) (common.TenantSettings, string) {
+	tenantID := common.TenantIDFromContext(ctx)
+	if tenantID == "" {
+		return common.TenantSettings{}, ""
+	}
+
+	settings, err := s.tenants.Get(ctx, tenantID)
+	if err == common.ErrInvalidTenantID {
+		return common.TenantSettings{}, "invalid tenant id"
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Tenant settings lookup failed: TenantID=%s, Error=%v", tenantID, err)
+		return common.TenantSettings{}, "database error"
+	}
+	return settings, ""
+}
+
+// ledgerBalance returns the balance of an account derived from its ledger, for tenants whose
+// balance_source is LEDGER, from the cache if the caller accepts a balance up to maxStalenessMs old.
+// It returns sql.ErrNoRows if the account does not exist.
+func (s *Service) ledgerBalance(ctx context.Context, accountID string, maxStalenessMs int64) (common.Cents, error) {
+	start := time.Now()
+	balance, err := s.ledger.Balance(ctx, accountID, time.Duration(maxStalenessMs)*time.Millisecond)
+	s.logger.WithContext(ctx).LogDatabase("SELECT", "accounts", time.Since(start), err)
+	return balance, err
+}
+
+// GetBalanceAt returns the balance an account had at a past point in time, derived from its ledger whatever the
+// tenant's balance_source, for dispute investigations and backdated interest. Transactions count from their
+// creation if they completed, and adjustments from their approval. Times before the account was created, in the
+// future, or before the transaction retention period of the caller's tenant, whose purged transactions are
+// folded into the opening balance, are rejected.
+func (s *Service) GetBalanceAt(ctx context.Context, req *pb.GetBalanceAtRequest) (*pb.GetBalanceAtResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" {
+		return &pb.GetBalanceAtResponse{Error: "account_id required"}, nil
+	}
+	now := common.GetCurrentTimestamp()
+	if req.Timestamp <= 0 {
+		return &pb.GetBalanceAtResponse{Error: "timestamp required"}, nil
+	}
+	if req.Timestamp > now {
+		return &pb.GetBalanceAtResponse{Error: "timestamp must not be in the future"}, nil
+	}
+
+	settings, msg := s.callerTenantSettings(ctx)
+	if msg != "" {
+		return &pb.GetBalanceAtResponse{Error: msg}, nil
+	}
+	if settings.Retention != nil && settings.Retention.TransactionDays > 0 &&
+		req.Timestamp < now-int64(settings.Retention.TransactionDays)*86400 {
+		return &pb.GetBalanceAtResponse{Error: "timestamp is before the transaction retention period"}, nil
+	}
+
+	start := time.Now()
+	balance, err := s.ledger.BalanceAt(ctx, req.AccountId, req.Timestamp)
+	logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+	switch {
+	case err == sql.ErrNoRows:
+		return &pb.GetBalanceAtResponse{Error: "account not found"}, nil
+	case errors.Is(err, common.ErrBeforeAccountCreated):
+		return &pb.GetBalanceAtResponse{Error: err.Error()}, nil
+	case err != nil:
+		logger.Error("Balance at time lookup failed: ID=%s, Timestamp=%d, Error=%v", req.AccountId, req.Timestamp, err)
+		return &pb.GetBalanceAtResponse{Error: "database error"}, nil
+	}
+
+	return &pb.GetBalanceAtResponse{BalanceCents: int64(balance), Timestamp: req.Timestamp}, nil
+}
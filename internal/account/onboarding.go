@@ -0,0 +1,230 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+)
+
+const accountColumns = `id, document_number, account_type, balance, created_at, updated_at, status,
+	COALESCE(holder_name, ''), COALESCE(holder_email, ''), COALESCE(kyc_reference, ''), version, overdraft_limit,
+	COALESCE(holder_phone, ''), tags`
+
+// onboardingTransitions lists the states an account may move to from each onboarding state.
+// PENDING_KYC goes back to DRAFT when the KYC check fails, so the holder data can be corrected.
+var onboardingTransitions = map[string][]string{
+	"DRAFT":       {"PENDING_KYC"},
+	"PENDING_KYC": {"ACTIVE", "DRAFT"},
+}
+
+// onboardingError is an onboarding failure reported to the caller rather than logged as a database error.
+type onboardingError string
+
+func (e onboardingError) Error() string { return string(e) }
+
+// scanAccount reads a row selected with accountColumns.
+func scanAccount(row rowScanner) (*common.Account, error) {
+	var account common.Account
+	var tags string
+	err := row.Scan(&account.ID, &account.DocumentNumber, &account.AccountType, &account.Balance, &account.CreatedAt,
+		&account.UpdatedAt, &account.Status, &account.HolderName, &account.HolderEmail, &account.KYCReference, &account.Version,
+		&account.OverdraftLimit, &account.HolderPhone, &tags)
+	if err != nil {
+		return nil, err
+	}
+	account.Tags = common.ParseAccountTags(tags)
+	return &account, nil
+}
+
+// validateHolder checks the holder contact details of a request and returns the error message of the response,
+// or "" if they are valid. Empty fields are not checked.
+func validateHolder(name, email, phone string) string {
+	if len(name) > 200 || len(email) > 200 || len(phone) > 32 {
+		return "holder data too long"
+	}
+	if email != "" && !strings.Contains(email, "@") {
+		return "invalid holder email"
+	}
+	if phone != "" && !validPhone(phone) {
+		return "invalid holder phone"
+	}
+	return ""
+}
+
+// validPhone reports whether phone looks like a phone number: 7 to 15 digits, optionally starting with +,
+// and separated by spaces, dots, dashes or parentheses.
+func validPhone(phone string) bool {
+	digits := 0
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case strings.ContainsRune(" .-()", r):
+		default:
+			return false
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
+
+// canTransition reports whether an account may move from one onboarding state to another.
+func canTransition(from, to string) bool {
+	for _, next := range onboardingTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateAccountHolder sets the holder and KYC data of an account that is still a DRAFT.
+// Only non-empty fields are updated, preserving existing values for empty fields.
+func (s *Service) UpdateAccountHolder(ctx context.Context, req *pb.UpdateAccountHolderRequest) (*pb.UpdateAccountHolderResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" {
+		return &pb.UpdateAccountHolderResponse{Error: "account_id required"}, nil
+	}
+	if len(req.KycReference) > 100 {
+		return &pb.UpdateAccountHolderResponse{Error: "holder data too long"}, nil
+	}
+	if msg := validateHolder(req.HolderName, req.HolderEmail, req.HolderPhone); msg != "" {
+		return &pb.UpdateAccountHolderResponse{Error: msg}, nil
+	}
+
+	var account *common.Account
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		account, err = s.lockAccount(ctx, tx, req.AccountId)
+		if err != nil {
+			return err
+		}
+		if account.Status != "DRAFT" {
+			return onboardingError("holder data can only be changed while the account is a draft")
+		}
+
+		previous := *account
+
+		if req.HolderName != "" {
+			account.HolderName = req.HolderName
+		}
+		if req.HolderEmail != "" {
+			account.HolderEmail = req.HolderEmail
+		}
+		if req.HolderPhone != "" {
+			account.HolderPhone = req.HolderPhone
+		}
+		if req.KycReference != "" {
+			account.KYCReference = req.KycReference
+		}
+		account.UpdatedAt = common.GetCurrentTimestamp()
+		account.Version++
+
+		start := time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE accounts
+			SET holder_name = $1, holder_email = $2, kyc_reference = $3, updated_at = $4, version = version + 1,
+			    holder_phone = NULLIF($6, '')
+			WHERE id = $5
+		`, account.HolderName, account.HolderEmail, account.KYCReference, account.UpdatedAt, account.ID, account.HolderPhone)
+		logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		return s.recordAccountChange(ctx, tx, account.ID, auditUpdate, &previous, account)
+	})
+	if msg := onboardingFailure(logger, "Account holder update", err); msg != "" {
+		return &pb.UpdateAccountHolderResponse{Error: msg}, nil
+	}
+
+	logger.Info("Account holder updated: ID=%s", account.ID)
+	return &pb.UpdateAccountHolderResponse{Account: ConvertAccountToProto(account)}, nil
+}
+
+// AdvanceOnboarding moves an account to another onboarding state, following onboardingTransitions.
+// Submitting for KYC requires the holder name and KYC reference. Approving or rejecting the KYC check,
+// the transitions out of PENDING_KYC, is reserved for support and admin operators.
+func (s *Service) AdvanceOnboarding(ctx context.Context, req *pb.AdvanceOnboardingRequest) (*pb.AdvanceOnboardingResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" || req.Status == "" {
+		return &pb.AdvanceOnboardingResponse{Error: "missing required fields"}, nil
+	}
+
+	var account *common.Account
+	var from string
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		account, err = s.lockAccount(ctx, tx, req.AccountId)
+		if err != nil {
+			return err
+		}
+
+		from = account.Status
+		if !canTransition(from, req.Status) {
+			return onboardingError(fmt.Sprintf("cannot move account from %s to %s", from, req.Status))
+		}
+		if from == "PENDING_KYC" && !common.Authorize(ctx, common.SupportOrAdmin) {
+			return onboardingError("permission denied")
+		}
+		if req.Status == "PENDING_KYC" && (account.HolderName == "" || account.KYCReference == "") {
+			return onboardingError("holder name and kyc reference required")
+		}
+
+		previous := *account
+		account.Status = req.Status
+		account.UpdatedAt = common.GetCurrentTimestamp()
+		account.Version++
+
+		start := time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE accounts SET status = $1, updated_at = $2, version = version + 1 WHERE id = $3
+		`, account.Status, account.UpdatedAt, account.ID)
+		logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		return s.recordAccountChange(ctx, tx, account.ID, auditStatusChange, &previous, account)
+	})
+	if msg := onboardingFailure(logger, "Onboarding transition", err); msg != "" {
+		return &pb.AdvanceOnboardingResponse{Error: msg}, nil
+	}
+
+	logger.Info("Account onboarding advanced: ID=%s, From=%s, To=%s", account.ID, from, account.Status)
+	return &pb.AdvanceOnboardingResponse{Account: ConvertAccountToProto(account)}, nil
+}
+
+// lockAccount loads and row-locks an account within tx.
+func (s *Service) lockAccount(ctx context.Context, tx *sql.Tx, id string) (*common.Account, error) {
+	start := time.Now()
+	account, err := scanAccount(tx.QueryRowContext(ctx, `SELECT `+accountColumns+` FROM accounts WHERE id = $1 FOR UPDATE`, id))
+	s.logger.WithContext(ctx).LogDatabase("SELECT", "accounts", time.Since(start), err)
+	if err == sql.ErrNoRows {
+		return nil, onboardingError("account not found")
+	}
+	return account, err
+}
+
+// onboardingFailure maps the error of an onboarding operation to the message returned to the caller,
+// or an empty string if the operation succeeded.
+func onboardingFailure(logger *common.Logger, operation string, err error) string {
+	var onboardingErr onboardingError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &onboardingErr):
+		return onboardingErr.Error()
+	case common.IsCancellation(err):
+		return "request cancelled"
+	default:
+		logger.Error("%s failed: %v", operation, err)
+		return "database error"
+	}
+}
@@ -0,0 +1,68 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+)
+
+// SetOverdraftLimit sets how far below zero debits may take the balance of a checking account; 0 removes the
+// overdraft. The limit cannot be lowered below what the account is already overdrawn by. Only admin operators
+// may set it.
+func (s *Service) SetOverdraftLimit(ctx context.Context, req *pb.SetOverdraftLimitRequest) (*pb.SetOverdraftLimitResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected overdraft limit change: AccountID=%s, caller is not an admin", req.AccountId)
+		return &pb.SetOverdraftLimitResponse{Error: "permission denied"}, nil
+	}
+	if req.AccountId == "" {
+		return &pb.SetOverdraftLimitResponse{Error: "account_id required"}, nil
+	}
+	if req.OverdraftLimitCents < 0 {
+		return &pb.SetOverdraftLimitResponse{Error: "overdraft_limit must not be negative"}, nil
+	}
+	limit := common.Cents(req.OverdraftLimitCents)
+
+	var account *common.Account
+	var previous common.Account
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		account, err = s.lockAccount(ctx, tx, req.AccountId)
+		if err != nil {
+			return err
+		}
+
+		if account.AccountType != "CHECKING" && limit > 0 {
+			return onboardingError("overdraft is only available for checking accounts")
+		}
+		if account.Balance < -limit {
+			return onboardingError("account is overdrawn by more than the overdraft limit")
+		}
+
+		previous = *account
+		account.OverdraftLimit = limit
+		account.UpdatedAt = common.GetCurrentTimestamp()
+		account.Version++
+
+		start := time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE accounts SET overdraft_limit = $1, updated_at = $2, version = version + 1 WHERE id = $3
+		`, account.OverdraftLimit, account.UpdatedAt, account.ID)
+		logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		return s.recordAccountChange(ctx, tx, account.ID, auditUpdate, &previous, account)
+	})
+	if msg := onboardingFailure(logger, "Overdraft limit change", err); msg != "" {
+		return &pb.SetOverdraftLimitResponse{Error: msg}, nil
+	}
+
+	logger.Info("Overdraft limit changed: AccountID=%s, From=%s, To=%s, ChangedBy=%s", account.ID, previous.OverdraftLimit, limit, operator)
+	return &pb.SetOverdraftLimitResponse{Account: ConvertAccountToProto(account)}, nil
+}
@@ -9,12 +9,20 @@ import (
 // This function maps all fields from the common.Account to the corresponding protobuf fields.
 func ConvertAccountToProto(dbAccount *common.Account) *pbAccount.Account {
 	return &pbAccount.Account{
-		Id:             dbAccount.ID,
-		DocumentNumber: dbAccount.DocumentNumber,
-		AccountType:    dbAccount.AccountType,
-		Balance:        dbAccount.Balance,
-		CreatedAt:      dbAccount.CreatedAt,
-		UpdatedAt:      dbAccount.UpdatedAt,
+		Id:                  dbAccount.ID,
+		DocumentNumber:      dbAccount.DocumentNumber,
+		AccountType:         dbAccount.AccountType,
+		BalanceCents:        int64(dbAccount.Balance),
+		CreatedAt:           dbAccount.CreatedAt,
+		UpdatedAt:           dbAccount.UpdatedAt,
+		Status:              dbAccount.Status,
+		HolderName:          dbAccount.HolderName,
+		HolderEmail:         dbAccount.HolderEmail,
+		HolderPhone:         dbAccount.HolderPhone,
+		KycReference:        dbAccount.KYCReference,
+		Version:             dbAccount.Version,
+		OverdraftLimitCents: int64(dbAccount.OverdraftLimit),
+		Tags:                dbAccount.Tags,
 	}
 }
 
@@ -25,21 +33,90 @@ func ConvertAccountFromProto(pbAccount *pbAccount.Account) *common.Account {
 		ID:             pbAccount.Id,
 		DocumentNumber: pbAccount.DocumentNumber,
 		AccountType:    pbAccount.AccountType,
-		Balance:        pbAccount.Balance,
+		Balance:        common.Cents(pbAccount.BalanceCents),
 		CreatedAt:      pbAccount.CreatedAt,
 		UpdatedAt:      pbAccount.UpdatedAt,
+		Status:         pbAccount.Status,
+		HolderName:     pbAccount.HolderName,
+		HolderEmail:    pbAccount.HolderEmail,
+		HolderPhone:    pbAccount.HolderPhone,
+		KYCReference:   pbAccount.KycReference,
+		OverdraftLimit: common.Cents(pbAccount.OverdraftLimitCents),
+		Tags:           pbAccount.Tags,
 	}
 }
 
 // ConvertCreateAccountRequestToAccount converts a CreateAccountRequest to a database Account struct.
-// It sets the current timestamp for both created_at and updated_at fields.
+// It sets the current timestamp for both created_at and updated_at fields, and starts the account
+// in DRAFT state when the request asks for it, ACTIVE otherwise.
 func ConvertCreateAccountRequestToAccount(req *pbAccount.CreateAccountRequest) *common.Account {
 	now := common.GetCurrentTimestamp()
+	status := "ACTIVE"
+	if req.Draft {
+		status = "DRAFT"
+	}
 	return &common.Account{
 		DocumentNumber: req.DocumentNumber,
 		AccountType:    req.AccountType,
-		Balance:        req.InitialBalance,
+		Balance:        common.Cents(req.InitialBalanceCents),
 		CreatedAt:      now,
 		UpdatedAt:      now,
+		Status:         status,
+		HolderName:     req.HolderName,
+		HolderEmail:    req.HolderEmail,
+		HolderPhone:    req.HolderPhone,
+	}
+}
+
+// ConvertTenantSettingsToProto converts tenant settings to a protobuf TenantSettings message.
+func ConvertTenantSettingsToProto(settings common.TenantSettings) *pbAccount.TenantSettings {
+	pbSettings := &pbAccount.TenantSettings{
+		Currency:                  settings.Currency,
+		AllowedOperationTypes:     settings.AllowedOperationTypes,
+		MaxTransactionAmountCents: int64(settings.MaxTransactionAmount),
+		BalanceSource:             settings.BalanceSource,
+		ReportingCurrency:         settings.ReportingCurrency,
+		Sandbox:                   settings.Sandbox,
+		DocumentTypes:             settings.DocumentTypes,
+	}
+	if settings.Retention != nil {
+		pbSettings.Retention = &pbAccount.RetentionSettings{
+			TransactionDays:            int32(settings.Retention.TransactionDays),
+			AnonymizeClosedAccountDays: int32(settings.Retention.AnonymizeClosedAccountDays),
+		}
+	}
+	if len(settings.FeeSchedule) > 0 {
+		pbSettings.FeeSchedule = make(map[string]*pbAccount.FeeRule, len(settings.FeeSchedule))
+		for operationType, rule := range settings.FeeSchedule {
+			pbSettings.FeeSchedule[operationType] = &pbAccount.FeeRule{FixedCents: int64(rule.Fixed), Percent: rule.Percent}
+		}
+	}
+	return pbSettings
+}
+
+// ConvertTenantSettingsFromProto converts a protobuf TenantSettings message to tenant settings.
+// A nil message yields empty settings.
+func ConvertTenantSettingsFromProto(pbSettings *pbAccount.TenantSettings) common.TenantSettings {
+	settings := common.TenantSettings{
+		Currency:              pbSettings.GetCurrency(),
+		AllowedOperationTypes: pbSettings.GetAllowedOperationTypes(),
+		MaxTransactionAmount:  common.Cents(pbSettings.GetMaxTransactionAmountCents()),
+		BalanceSource:         pbSettings.GetBalanceSource(),
+		ReportingCurrency:     pbSettings.GetReportingCurrency(),
+		Sandbox:               pbSettings.GetSandbox(),
+		DocumentTypes:         pbSettings.GetDocumentTypes(),
+	}
+	if retention := pbSettings.GetRetention(); retention != nil {
+		settings.Retention = &common.RetentionSettings{
+			TransactionDays:            int(retention.GetTransactionDays()),
+			AnonymizeClosedAccountDays: int(retention.GetAnonymizeClosedAccountDays()),
+		}
+	}
+	if len(pbSettings.GetFeeSchedule()) > 0 {
+		settings.FeeSchedule = make(map[string]common.FeeRule, len(pbSettings.GetFeeSchedule()))
+		for operationType, rule := range pbSettings.GetFeeSchedule() {
+			settings.FeeSchedule[operationType] = common.FeeRule{Fixed: common.Cents(rule.GetFixedCents()), Percent: rule.GetPercent()}
+		}
 	}
+	return settings
 }
@@ -0,0 +1,49 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+)
+
+// ApplyRuntimeConfig makes the service use the given runtime configuration, e.g. for account quotas.
+// It is safe to call while requests are being served.
+func (s *Service) ApplyRuntimeConfig(config *common.RuntimeConfig) {
+	s.runtimeConfig.Store(config)
+}
+
+// checkAccountQuota returns an error message if the document number already holds as many accounts
+// of accountType as the runtime config allows, or an empty string if another one may be opened.
+// Account types without a configured quota are unlimited.
+func (s *Service) checkAccountQuota(ctx context.Context, documentNumber, accountType string) string {
+	config := s.runtimeConfig.Load()
+	if config == nil {
+		return ""
+	}
+	quota, ok := config.AccountQuota(accountType)
+	if !ok {
+		return ""
+	}
+
+	logger := s.logger.WithContext(ctx)
+
+	var count int
+	start := time.Now()
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM accounts WHERE document_number = $1 AND account_type = $2
+	`, documentNumber, accountType).Scan(&count)
+	logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+	if err != nil {
+		if common.IsCancellation(err) {
+			return "request cancelled"
+		}
+		logger.Error("Account quota check failed: %v", err)
+		return "database error"
+	}
+
+	if count >= quota {
+		return "account quota exceeded"
+	}
+	return ""
+}
@@ -0,0 +1,32 @@
+package account
+
+import (
+	"context"
+
+	"github.com/YASHIRAI/pismo-task/internal/validation"
+)
+
+// checkDocumentNumber returns documentNumber in canonical form and an error message if the caller's tenant does
+// not accept it for an account holder, or an empty message if it does. Sandbox tenants accept only test document
+// numbers, and other callers never accept them. Tenants with document types accept only documents valid for one
+// of them, e.g. a CPF with matching check digits, stored without formatting.
+func (s *Service) checkDocumentNumber(ctx context.Context, documentNumber string) (string, string) {
+	settings, msg := s.callerTenantSettings(ctx)
+	if msg != "" {
+		return "", msg
+	}
+	if !settings.AcceptsDocumentNumber(documentNumber) {
+		if settings.Sandbox {
+			return "", "sandbox accounts require a test document number"
+		}
+		return "", "test document numbers are only accepted in sandbox"
+	}
+	if len(settings.DocumentTypes) == 0 || settings.Sandbox {
+		return documentNumber, ""
+	}
+	normalized, fieldErr := validation.ValidateDocument("document_number", documentNumber, settings.DocumentTypes)
+	if fieldErr != nil {
+		return "", fieldErr.Error()
+	}
+	return normalized, ""
+}
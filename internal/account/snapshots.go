@@ -0,0 +1,277 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+	"github.com/google/uuid"
+)
+
+// Operations recorded in account_snapshots.
+const (
+	snapshotDelete = "DELETE"
+	snapshotClose  = "CLOSE"
+)
+
+// snapshotTransactionLimit is how many of the most recent transactions of an account a snapshot copies.
+const snapshotTransactionLimit = 500
+
+// snapshottedAccount is the part of an account row copied to account_snapshots that ListAccountSnapshots returns.
+// The JSON names are the column names.
+type snapshottedAccount struct {
+	ID             string       `json:"id"`
+	DocumentNumber string       `json:"document_number"`
+	AccountType    string       `json:"account_type"`
+	Balance        common.Cents `json:"balance"`
+	CreatedAt      int64        `json:"created_at"`
+	UpdatedAt      int64        `json:"updated_at"`
+	Status         string       `json:"status"`
+	HolderName     string       `json:"holder_name"`
+	HolderEmail    string       `json:"holder_email"`
+	HolderPhone    string       `json:"holder_phone"`
+	KYCReference   string       `json:"kyc_reference"`
+	Version        int64        `json:"version"`
+	OverdraftLimit common.Cents `json:"overdraft_limit"`
+	Tags           string       `json:"tags"`
+}
+
+// snapshotAccount copies an account and its most recent transactions to account_snapshots within tx, before
+// operation deletes or closes it, and returns the ID of the snapshot. Whole rows are copied, so a restore puts
+// back every column, including those added after the snapshot was taken.
+func (s *Service) snapshotAccount(ctx context.Context, tx *sql.Tx, accountID, operation string) (string, error) {
+	actor, role := auditActor(ctx)
+	id := uuid.New().String()
+
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO account_snapshots (id, account_id, operation, taken_by, taken_by_role, taken_at, account, transactions)
+		SELECT $1, a.id, $3, $4, $5, $6, to_jsonb(a), COALESCE((
+			SELECT jsonb_agg(to_jsonb(t) ORDER BY t.created_at DESC, t.id)
+			FROM (SELECT * FROM transactions WHERE account_id = a.id ORDER BY created_at DESC, id LIMIT $7) t
+		), '[]'::jsonb)
+		FROM accounts a
+		WHERE a.id = $2
+	`, id, accountID, operation, actor, role, common.GetCurrentTimestamp(), snapshotTransactionLimit)
+	s.logger.WithContext(ctx).LogDatabase("INSERT", "account_snapshots", time.Since(start), err)
+	return id, err
+}
+
+// ListAccountSnapshots returns the snapshots taken of an account before it was deleted or closed, newest first.
+// Only admins may list them. Document numbers are masked unless the caller may see them in full.
+func (s *Service) ListAccountSnapshots(ctx context.Context, req *pb.ListAccountSnapshotsRequest) (*pb.ListAccountSnapshotsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.AdminOnly) {
+		return &pb.ListAccountSnapshotsResponse{Error: "permission denied"}, nil
+	}
+	if req.AccountId == "" {
+		return &pb.ListAccountSnapshotsResponse{Error: "account_id required"}, nil
+	}
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, account_id, operation, taken_by, taken_by_role, taken_at, account, jsonb_array_length(transactions),
+			COALESCE(restored_at, 0), COALESCE(restored_by, '')
+		FROM account_snapshots
+		WHERE account_id = $1
+		ORDER BY taken_at DESC, id
+	`, req.AccountId)
+	logger.LogDatabase("SELECT", "account_snapshots", time.Since(start), err)
+	if err != nil {
+		logger.Error("Account snapshot lookup failed: %v", err)
+		return &pb.ListAccountSnapshotsResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	showFull := common.CanViewFullDocumentNumber(common.CallerRoleFromContext(ctx))
+	var snapshots []*pb.AccountSnapshot
+	for rows.Next() {
+		var snapshot pb.AccountSnapshot
+		var account []byte
+		if err := rows.Scan(&snapshot.Id, &snapshot.AccountId, &snapshot.Operation, &snapshot.TakenBy, &snapshot.TakenByRole,
+			&snapshot.TakenAt, &account, &snapshot.TransactionCount, &snapshot.RestoredAt, &snapshot.RestoredBy); err != nil {
+			logger.Error("Row scan failed: %v", err)
+			return &pb.ListAccountSnapshotsResponse{Error: "database error"}, nil
+		}
+		if snapshot.Account, err = decodeSnapshottedAccount(account, showFull); err != nil {
+			logger.Error("Invalid account snapshot %s: %v", snapshot.Id, err)
+			return &pb.ListAccountSnapshotsResponse{Error: "database error"}, nil
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Account snapshot lookup failed: %v", err)
+		return &pb.ListAccountSnapshotsResponse{Error: "database error"}, nil
+	}
+
+	return &pb.ListAccountSnapshotsResponse{Snapshots: snapshots}, nil
+}
+
+// decodeSnapshottedAccount converts an account row copied to account_snapshots to its protobuf form. The
+// document number is masked unless showFull is set.
+func decodeSnapshottedAccount(data []byte, showFull bool) (*pb.Account, error) {
+	var account snapshottedAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("invalid snapshotted account: %w", err)
+	}
+	if !showFull {
+		account.DocumentNumber = common.MaskDocumentNumber(account.DocumentNumber)
+	}
+	return ConvertAccountToProto(&common.Account{
+		ID:             account.ID,
+		DocumentNumber: account.DocumentNumber,
+		AccountType:    account.AccountType,
+		Balance:        account.Balance,
+		CreatedAt:      account.CreatedAt,
+		UpdatedAt:      account.UpdatedAt,
+		Status:         account.Status,
+		HolderName:     account.HolderName,
+		HolderEmail:    account.HolderEmail,
+		HolderPhone:    account.HolderPhone,
+		KYCReference:   account.KYCReference,
+		Version:        account.Version,
+		OverdraftLimit: account.OverdraftLimit,
+		Tags:           common.ParseAccountTags(account.Tags),
+	}), nil
+}
+
+// RestoreAccountSnapshot undoes the operation a snapshot was taken before. A deleted account is inserted again
+// as it was, with the transactions copied in the snapshot; those whose ID or external ID has been taken since are
+// skipped. A closed account is reopened in the state it had. The restore is recorded in the audit log of the
+// account, and a snapshot can only be restored once. Only admins may restore snapshots.
+func (s *Service) RestoreAccountSnapshot(ctx context.Context, req *pb.RestoreAccountSnapshotRequest) (*pb.RestoreAccountSnapshotResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.AdminOnly) {
+		logger.Warn("Rejected account snapshot restore: Role=%q", common.CallerRoleFromContext(ctx))
+		return &pb.RestoreAccountSnapshotResponse{Error: "permission denied"}, nil
+	}
+	if req.Id == "" {
+		return &pb.RestoreAccountSnapshotResponse{Error: "id required"}, nil
+	}
+
+	resp := &pb.RestoreAccountSnapshotResponse{}
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		var accountID, operation, status string
+		var restoredAt sql.NullInt64
+		start := time.Now()
+		err := tx.QueryRowContext(ctx, `
+			SELECT account_id, operation, account->>'status', restored_at
+			FROM account_snapshots
+			WHERE id = $1
+			FOR UPDATE
+		`, req.Id).Scan(&accountID, &operation, &status, &restoredAt)
+		logger.LogDatabase("SELECT", "account_snapshots", time.Since(start), err)
+		switch {
+		case err == sql.ErrNoRows:
+			return onboardingError("snapshot not found")
+		case err != nil:
+			return err
+		case restoredAt.Valid:
+			return onboardingError("snapshot already restored")
+		}
+
+		var account *common.Account
+		switch operation {
+		case snapshotDelete:
+			if account, resp.RestoredTransactions, err = s.restoreDeletedAccount(ctx, tx, req.Id, accountID); err != nil {
+				return err
+			}
+			if err := s.recordAccountChange(ctx, tx, accountID, auditCreate, nil, account); err != nil {
+				return err
+			}
+		case snapshotClose:
+			previous, err := s.lockAccount(ctx, tx, accountID)
+			if err != nil {
+				return err
+			}
+			if previous.Status != "CLOSED" {
+				return onboardingError("account not closed")
+			}
+			restored := *previous
+			restored.Status = status
+			restored.UpdatedAt = common.GetCurrentTimestamp()
+			restored.Version++
+
+			start = time.Now()
+			_, err = tx.ExecContext(ctx, `
+				UPDATE accounts
+				SET status = $2, closed_at = NULL, updated_at = $3, version = version + 1
+				WHERE id = $1
+			`, accountID, status, restored.UpdatedAt)
+			logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+			if err != nil {
+				return err
+			}
+			if err := s.recordAccountChange(ctx, tx, accountID, auditStatusChange, previous, &restored); err != nil {
+				return err
+			}
+			account = &restored
+		default:
+			return fmt.Errorf("unknown snapshot operation %q", operation)
+		}
+
+		actor, _ := auditActor(ctx)
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE account_snapshots SET restored_at = $2, restored_by = $3 WHERE id = $1
+		`, req.Id, common.GetCurrentTimestamp(), actor)
+		logger.LogDatabase("UPDATE", "account_snapshots", time.Since(start), err)
+		resp.Account = ConvertAccountToProto(account)
+		return err
+	})
+	if msg := onboardingFailure(logger, "Account snapshot restore", err); msg != "" {
+		return &pb.RestoreAccountSnapshotResponse{Error: msg}, nil
+	}
+
+	logger.Info("Account restored from snapshot: ID=%s, Snapshot=%s, Transactions=%d", resp.Account.Id, req.Id, resp.RestoredTransactions)
+	return resp, nil
+}
+
+// restoreDeletedAccount inserts the account and transactions copied in a snapshot again within tx, and returns
+// the account and the number of transactions inserted.
+func (s *Service) restoreDeletedAccount(ctx context.Context, tx *sql.Tx, snapshotID, accountID string) (*common.Account, int32, error) {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO accounts
+		SELECT (jsonb_populate_record(NULL::accounts, account)).* FROM account_snapshots WHERE id = $1
+	`, snapshotID)
+	logger.LogDatabase("INSERT", "accounts", time.Since(start), err)
+	if common.IsUniqueViolation(err) {
+		// Either the account was never deleted, or its document number belongs to another account now
+		return nil, 0, onboardingError("account or document number exists")
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The transactions are put back on the days they were recorded, including days closed since
+	if err := common.AllowBackdatedInserts(ctx, tx); err != nil {
+		return nil, 0, err
+	}
+	start = time.Now()
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO transactions
+		SELECT t.* FROM account_snapshots s, jsonb_populate_recordset(NULL::transactions, s.transactions) t
+		WHERE s.id = $1
+		ON CONFLICT DO NOTHING
+	`, snapshotID)
+	logger.LogDatabase("INSERT", "transactions", time.Since(start), err)
+	if err != nil {
+		return nil, 0, err
+	}
+	restored, err := result.RowsAffected()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	account, err := s.lockAccount(ctx, tx, accountID)
+	return account, int32(restored), err
+}
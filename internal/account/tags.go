@@ -0,0 +1,103 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/account"
+)
+
+// normalizeAccountTags returns tags normalized, deduplicated and sorted, or the error message of the
+// response if one of them is not a valid tag.
+func normalizeAccountTags(tags []string) ([]string, string) {
+	seen := make(map[string]bool, len(tags))
+	var normalized []string
+	for _, tag := range tags {
+		normalizedTag, ok := common.NormalizeAccountTag(tag)
+		if !ok {
+			return nil, fmt.Sprintf("invalid tag: %q", tag)
+		}
+		if !seen[normalizedTag] {
+			seen[normalizedTag] = true
+			normalized = append(normalized, normalizedTag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, ""
+}
+
+// UpdateAccountTags adds and removes tags of an account, e.g. VIP, TEST or COLLECTIONS. Tags are case-insensitive
+// and stored in upper case; adding a tag the account has, or removing one it lacks, is not an error. An account
+// has at most common.MaxAccountTags tags. Only admin operators may change tags.
+func (s *Service) UpdateAccountTags(ctx context.Context, req *pb.UpdateAccountTagsRequest) (*pb.UpdateAccountTagsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected account tags change: AccountID=%s, caller is not an admin", req.AccountId)
+		return &pb.UpdateAccountTagsResponse{Error: "permission denied"}, nil
+	}
+	if req.AccountId == "" {
+		return &pb.UpdateAccountTagsResponse{Error: "account_id required"}, nil
+	}
+	add, msg := normalizeAccountTags(req.Add)
+	if msg != "" {
+		return &pb.UpdateAccountTagsResponse{Error: msg}, nil
+	}
+	remove, msg := normalizeAccountTags(req.Remove)
+	if msg != "" {
+		return &pb.UpdateAccountTagsResponse{Error: msg}, nil
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return &pb.UpdateAccountTagsResponse{Error: "no tags to add or remove"}, nil
+	}
+
+	var account *common.Account
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		account, err = s.lockAccount(ctx, tx, req.AccountId)
+		if err != nil {
+			return err
+		}
+
+		removed := make(map[string]bool, len(remove))
+		for _, tag := range remove {
+			removed[tag] = true
+		}
+		tags, _ := normalizeAccountTags(append(account.Tags, add...))
+		kept := tags[:0]
+		for _, tag := range tags {
+			if !removed[tag] {
+				kept = append(kept, tag)
+			}
+		}
+		if len(kept) > common.MaxAccountTags {
+			return onboardingError(fmt.Sprintf("at most %d tags allowed", common.MaxAccountTags))
+		}
+		previous := *account
+		account.Tags = kept
+		account.UpdatedAt = common.GetCurrentTimestamp()
+		account.Version++
+
+		start := time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE accounts SET tags = $1, updated_at = $2, version = version + 1 WHERE id = $3
+		`, strings.Join(account.Tags, ","), account.UpdatedAt, account.ID)
+		logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		return s.recordAccountChange(ctx, tx, account.ID, auditUpdate, &previous, account)
+	})
+	if msg := onboardingFailure(logger, "Account tags change", err); msg != "" {
+		return &pb.UpdateAccountTagsResponse{Error: msg}, nil
+	}
+
+	logger.Info("Account tags changed: AccountID=%s, Added=%v, Removed=%v, ChangedBy=%s", account.ID, add, remove, operator)
+	return &pb.UpdateAccountTagsResponse{Account: ConvertAccountToProto(account)}, nil
+}
@@ -0,0 +1,272 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Outcomes of an access decision.
+const (
+	AccessAllowed = "ALLOW"
+	AccessDenied  = "DENY"
+)
+
+// accessAuditTimeout bounds the write of one access decision, which outlives a cancelled call.
+const accessAuditTimeout = 2 * time.Second
+
+// Limits on access decision queries.
+const (
+	DefaultAccessDecisionLimit = 100
+	MaxAccessDecisionLimit     = 1000
+)
+
+// AccessPolicy describes which callers may perform a protected operation: callers with one of Roles,
+// identified by an operator ID when RequireOperator is set.
+type AccessPolicy struct {
+	Roles           []string `json:"roles"`
+	RequireOperator bool     `json:"require_operator"`
+}
+
+// Access policies of the protected operations.
+var (
+	AdminOnly              = AccessPolicy{Roles: []string{RoleAdmin}}
+	AdminOperator          = AccessPolicy{Roles: []string{RoleAdmin}, RequireOperator: true}
+	SupportOrAdmin         = AccessPolicy{Roles: []string{RoleSupport, RoleAdmin}}
+	SupportOrAdminOperator = AccessPolicy{Roles: []string{RoleSupport, RoleAdmin}, RequireOperator: true}
+)
+
+// Check returns whether the caller with role and operator passes the policy, and the reason recorded for the decision.
+func (p AccessPolicy) Check(role, operator string) (bool, string) {
+	permitted := false
+	for _, allowed := range p.Roles {
+		if role == allowed {
+			permitted = true
+			break
+		}
+	}
+	switch {
+	case role == "":
+		return false, "no caller role"
+	case !permitted:
+		return false, fmt.Sprintf("role %s not permitted", role)
+	case p.RequireOperator && operator == "":
+		return false, "operator id required"
+	default:
+		return true, fmt.Sprintf("role %s permitted", role)
+	}
+}
+
+// AccessDecision is an authorization decision made for a call, as recorded in access_audit_log.
+type AccessDecision struct {
+	ID         int64
+	Service    string
+	Method     string
+	Principal  string
+	Role       string
+	TenantID   string
+	RequestID  string
+	Decision   string
+	Reason     string
+	OccurredAt int64
+}
+
+// AccessAuditor records the access decisions of a service in the access_audit_log table.
+// Authentication happens at the proxy in front of the gateway, so the decisions are the services' checks
+// of the asserted caller role and operator identity against the policy of each protected operation.
+type AccessAuditor struct {
+	db      *sql.DB
+	logger  *Logger
+	service string
+}
+
+// NewAccessAuditor creates an auditor recording decisions made by the named service.
+func NewAccessAuditor(db *sql.DB, logger *Logger, service string) *AccessAuditor {
+	return &AccessAuditor{db: db, logger: logger, service: service}
+}
+
+// Record stores a decision. A failed write is logged and does not affect the call the decision was made for.
+func (a *AccessAuditor) Record(ctx context.Context, decision AccessDecision) {
+	decision.Service = a.service
+	if decision.OccurredAt == 0 {
+		decision.OccurredAt = GetCurrentTimestamp()
+	}
+
+	// Role, principal and reason come from caller-supplied metadata; cut them to the column sizes
+	// rather than lose the record
+	decision.Principal = truncateAuditField(decision.Principal, 100)
+	decision.Role = truncateAuditField(decision.Role, 50)
+	decision.TenantID = truncateAuditField(decision.TenantID, 64)
+	decision.RequestID = truncateAuditField(decision.RequestID, 64)
+	decision.Reason = truncateAuditField(decision.Reason, 200)
+
+	writeCtx, cancel := context.WithTimeout(context.Background(), accessAuditTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err := a.db.ExecContext(writeCtx, `
+		INSERT INTO access_audit_log (service, method, principal, role, tenant_id, request_id, decision, reason, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, decision.Service, decision.Method, decision.Principal, decision.Role, decision.TenantID, decision.RequestID,
+		decision.Decision, decision.Reason, decision.OccurredAt)
+	logger := a.logger.WithContext(ctx)
+	logger.LogDatabase("INSERT", "access_audit_log", time.Since(start), err)
+	if err != nil {
+		logger.Error("Access decision not recorded: Method=%s, Principal=%s, Decision=%s, Error=%v",
+			decision.Method, decision.Principal, decision.Decision, err)
+	}
+}
+
+// truncateAuditField cuts value to at most max bytes.
+func truncateAuditField(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
+
+type accessAuditKey struct{}
+
+// accessAuditScope is what the interceptor attaches to a call for Authorize: the auditor and the method called.
+type accessAuditScope struct {
+	auditor *AccessAuditor
+	method  string
+}
+
+// AccessAuditUnaryServerInterceptor returns a server interceptor that makes the auditor available to Authorize,
+// so every access decision made while handling a call is recorded with the called method.
+func AccessAuditUnaryServerInterceptor(auditor *AccessAuditor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = context.WithValue(ctx, accessAuditKey{}, accessAuditScope{auditor: auditor, method: info.FullMethod})
+		return handler(ctx, req)
+	}
+}
+
+// Authorize reports whether the caller may perform an operation protected by policy, judged by the caller role
+// and operator ID in the incoming metadata. The decision is recorded when the call went through
+// AccessAuditUnaryServerInterceptor, with PrincipalFromContext as its principal.
+// Calls that passed the policy configured for their method by AuthorizationUnaryServerInterceptor are allowed
+// without checking policy again.
+func Authorize(ctx context.Context, policy AccessPolicy) bool {
+	if policyAuthorized(ctx) {
+		return true
+	}
+	role := CallerRoleFromContext(ctx)
+	operator := OperatorIDFromContext(ctx)
+	allowed, reason := policy.Check(role, operator)
+
+	if scope, ok := ctx.Value(accessAuditKey{}).(accessAuditScope); ok {
+		decision := AccessDecision{
+			Method:    scope.method,
+			Principal: PrincipalFromContext(ctx),
+			Role:      role,
+			TenantID:  TenantIDFromContext(ctx),
+			RequestID: RequestIDFromContext(ctx),
+			Decision:  AccessDenied,
+			Reason:    reason,
+		}
+		if allowed {
+			decision.Decision = AccessAllowed
+		}
+		scope.auditor.Record(ctx, decision)
+	}
+	return allowed
+}
+
+// PrincipalFromContext returns who a call is made by, as recorded in audit trails: the operator ID from the incoming
+// metadata, or else the caller ID forwarded by the gateway. Returns an empty string if neither was sent.
+func PrincipalFromContext(ctx context.Context) string {
+	if operator := OperatorIDFromContext(ctx); operator != "" {
+		return operator
+	}
+	return callerIDFromIncoming(ctx)
+}
+
+// callerIDFromIncoming returns the x-caller-id metadata of a call, or an empty string if none was sent.
+func callerIDFromIncoming(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(CallerIDMetadataKey); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// AccessDecisionFilter selects recorded decisions. Empty fields match everything; From and To are Unix seconds,
+// From inclusive and To exclusive. BeforeID resumes a listing after the last decision of the previous page.
+type AccessDecisionFilter struct {
+	Principal string
+	Method    string
+	Decision  string
+	From      int64
+	To        int64
+	BeforeID  int64
+	Limit     int
+}
+
+// QueryAccessDecisions returns the recorded decisions matching filter, newest first.
+func QueryAccessDecisions(ctx context.Context, db *sql.DB, filter AccessDecisionFilter) ([]AccessDecision, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Principal != "" {
+		add("principal = $%d", filter.Principal)
+	}
+	if filter.Method != "" {
+		add("method = $%d", filter.Method)
+	}
+	if filter.Decision != "" {
+		add("decision = $%d", filter.Decision)
+	}
+	if filter.From > 0 {
+		add("occurred_at >= $%d", filter.From)
+	}
+	if filter.To > 0 {
+		add("occurred_at < $%d", filter.To)
+	}
+	if filter.BeforeID > 0 {
+		add("id < $%d", filter.BeforeID)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > MaxAccessDecisionLimit {
+		limit = DefaultAccessDecisionLimit
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT id, service, method, principal, role, tenant_id, request_id, decision, reason, occurred_at
+		FROM access_audit_log`
+	if len(conditions) > 0 {
+		query += `
+		WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(`
+		ORDER BY id DESC
+		LIMIT $%d`, len(args))
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decisions []AccessDecision
+	for rows.Next() {
+		var d AccessDecision
+		if err := rows.Scan(&d.ID, &d.Service, &d.Method, &d.Principal, &d.Role, &d.TenantID, &d.RequestID,
+			&d.Decision, &d.Reason, &d.OccurredAt); err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions, rows.Err()
+}
@@ -0,0 +1,120 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name     string
+		pairs    []string
+		policy   AccessPolicy
+		expected bool
+	}{
+		{"admin passes admin only", []string{CallerRoleMetadataKey, RoleAdmin}, AdminOnly, true},
+		{"support fails admin only", []string{CallerRoleMetadataKey, RoleSupport}, AdminOnly, false},
+		{"support passes support or admin", []string{CallerRoleMetadataKey, "Support"}, SupportOrAdmin, true},
+		{"missing role", nil, SupportOrAdmin, false},
+		{"operator required", []string{CallerRoleMetadataKey, RoleAdmin}, AdminOperator, false},
+		{"operator present", []string{CallerRoleMetadataKey, RoleAdmin, OperatorIDMetadataKey, "ops-1"}, SupportOrAdminOperator, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(tt.pairs...))
+			assert.Equal(t, tt.expected, Authorize(ctx, tt.policy))
+		})
+	}
+}
+
+func TestAccessPolicy_Reasons(t *testing.T) {
+	_, reason := AdminOnly.Check("", "")
+	assert.Equal(t, "no caller role", reason)
+	_, reason = AdminOnly.Check(RoleSupport, "agent-7")
+	assert.Equal(t, "role support not permitted", reason)
+	_, reason = AdminOperator.Check(RoleAdmin, "")
+	assert.Equal(t, "operator id required", reason)
+	_, reason = AdminOperator.Check(RoleAdmin, "ops-1")
+	assert.Equal(t, "role admin permitted", reason)
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	assert.Equal(t, "", PrincipalFromContext(context.Background()))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(CallerIDMetadataKey, "client-1"))
+	assert.Equal(t, "client-1", PrincipalFromContext(ctx))
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(CallerIDMetadataKey, "client-1", OperatorIDMetadataKey, "ops-1"))
+	assert.Equal(t, "ops-1", PrincipalFromContext(ctx))
+}
+
+func TestAccessAuditUnaryServerInterceptor_RecordsDecisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := NewLogger("test-service", INFO)
+	interceptor := AccessAuditUnaryServerInterceptor(NewAccessAuditor(db, logger, "transaction-mgr"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/transaction.TransactionService/UpdateOperationRule"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return Authorize(ctx, AdminOnly), nil
+	}
+
+	mock.ExpectExec(`INSERT INTO access_audit_log`).
+		WithArgs("transaction-mgr", info.FullMethod, "api-key-hash", RoleSupport, "", "req-1", AccessDenied, "role support not permitted", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	ctx := metadata.NewIncomingContext(WithRequestID(context.Background(), "req-1"), metadata.Pairs(
+		CallerRoleMetadataKey, RoleSupport, CallerIDMetadataKey, "api-key-hash"))
+	allowed, err := interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, false, allowed)
+
+	// The operator is the principal when one is asserted; a failed write does not change the decision
+	mock.ExpectExec(`INSERT INTO access_audit_log`).
+		WithArgs("transaction-mgr", info.FullMethod, "ops-1", RoleAdmin, "", "req-2", AccessAllowed, "role admin permitted", sqlmock.AnyArg()).
+		WillReturnError(sql.ErrConnDone)
+	ctx = metadata.NewIncomingContext(WithRequestID(context.Background(), "req-2"), metadata.Pairs(
+		CallerRoleMetadataKey, RoleAdmin, OperatorIDMetadataKey, "ops-1", CallerIDMetadataKey, "api-key-hash"))
+	allowed, err = interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, true, allowed)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQueryAccessDecisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"id", "service", "method", "principal", "role", "tenant_id", "request_id", "decision", "reason", "occurred_at"}
+	mock.ExpectQuery(`FROM access_audit_log\s+WHERE principal = \$1 AND decision = \$2 AND occurred_at >= \$3 AND id < \$4\s+ORDER BY id DESC\s+LIMIT \$5`).
+		WithArgs("ops-1", AccessDenied, int64(1000), int64(50), 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(42, "account-mgr", "/account.AccountService/ReviewBalanceAdjustment", "ops-1", "support", "", "req-1", AccessDenied, "role support not permitted", 1200))
+
+	decisions, err := QueryAccessDecisions(context.Background(), db, AccessDecisionFilter{
+		Principal: "ops-1", Decision: AccessDenied, From: 1000, BeforeID: 50, Limit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, int64(42), decisions[0].ID)
+	assert.Equal(t, "role support not permitted", decisions[0].Reason)
+
+	mock.ExpectQuery(`FROM access_audit_log\s+ORDER BY id DESC\s+LIMIT \$1`).
+		WithArgs(DefaultAccessDecisionLimit).
+		WillReturnRows(sqlmock.NewRows(columns))
+	decisions, err = QueryAccessDecisions(context.Background(), db, AccessDecisionFilter{Limit: MaxAccessDecisionLimit + 1})
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
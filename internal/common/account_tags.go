@@ -0,0 +1,53 @@
+package common
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Limits on account tags. Tags are stored comma-separated, so they are restricted to characters that need
+// no escaping.
+const (
+	MaxAccountTags      = 10
+	MaxAccountTagLength = 32
+)
+
+// DefaultReportExcludedTags lists the account tags whose accounts are left out of reports by default.
+const DefaultReportExcludedTags = "TEST"
+
+var accountTagPattern = regexp.MustCompile(`^[A-Z0-9_-]+$`)
+
+// NormalizeAccountTag returns tag in upper case without surrounding spaces, and whether it is a valid tag.
+func NormalizeAccountTag(tag string) (string, bool) {
+	tag = strings.ToUpper(strings.TrimSpace(tag))
+	return tag, len(tag) <= MaxAccountTagLength && accountTagPattern.MatchString(tag)
+}
+
+// ParseAccountTags splits the comma-separated tags column of an account, sorted.
+func ParseAccountTags(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+	parsed := strings.Split(tags, ",")
+	sort.Strings(parsed)
+	return parsed
+}
+
+// ReportExcludedTagsFromEnv returns the account tags whose accounts are left out of reports, from the
+// comma-separated REPORT_EXCLUDED_TAGS environment variable, defaulting to DefaultReportExcludedTags.
+// Set it to an empty value to report all accounts; invalid tags are ignored.
+func ReportExcludedTagsFromEnv() []string {
+	value, ok := os.LookupEnv("REPORT_EXCLUDED_TAGS")
+	if !ok {
+		value = DefaultReportExcludedTags
+	}
+	var excluded []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag, ok := NormalizeAccountTag(tag); ok {
+			excluded = append(excluded, tag)
+		}
+	}
+	return excluded
+}
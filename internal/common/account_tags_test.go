@@ -0,0 +1,33 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeAccountTag(t *testing.T) {
+	tag, ok := NormalizeAccountTag(" vip ")
+	assert.True(t, ok)
+	assert.Equal(t, "VIP", tag)
+
+	for _, tag := range []string{"", "a,b", "late payer", "ÜBER", "X12345678901234567890123456789012"} {
+		_, ok := NormalizeAccountTag(tag)
+		assert.False(t, ok, tag)
+	}
+}
+
+func TestParseAccountTags(t *testing.T) {
+	assert.Nil(t, ParseAccountTags(""))
+	assert.Equal(t, []string{"COLLECTIONS", "VIP"}, ParseAccountTags("VIP,COLLECTIONS"))
+}
+
+func TestReportExcludedTagsFromEnv(t *testing.T) {
+	assert.Equal(t, []string{"TEST"}, ReportExcludedTagsFromEnv())
+
+	t.Setenv("REPORT_EXCLUDED_TAGS", "test, sandbox,not valid")
+	assert.Equal(t, []string{"TEST", "SANDBOX"}, ReportExcludedTagsFromEnv())
+
+	t.Setenv("REPORT_EXCLUDED_TAGS", "")
+	assert.Empty(t, ReportExcludedTagsFromEnv())
+}
@@ -0,0 +1,94 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// The analytics services serve read-only, streaming-only reads of accounts and transactions for reporting and
+// data pipelines. They run on their own port against a read replica, with their own connection pool and rate
+// limits, so a heavy analytics query never competes with the transactional path for primary connections.
+
+// ReplicaDatabaseConfigFromEnv returns the connection settings of the read replica from REPLICA_DB_HOST and
+// REPLICA_DB_PORT. The credentials, database name and SSL mode default to those of the primary and can be
+// overridden with REPLICA_DB_USER, REPLICA_DB_PASSWORD, REPLICA_DB_NAME and REPLICA_DB_SSLMODE.
+// ok is false when REPLICA_DB_HOST is not set.
+func ReplicaDatabaseConfigFromEnv() (config DatabaseConfig, ok bool) {
+	config = DatabaseConfig{
+		Host:     getEnv("REPLICA_DB_HOST", ""),
+		Port:     getEnv("REPLICA_DB_PORT", getEnv("DB_PORT", "5432")),
+		User:     getEnv("REPLICA_DB_USER", getEnv("DB_USER", "pismo")),
+		Password: getEnv("REPLICA_DB_PASSWORD", getEnv("DB_PASSWORD", "pismo123")),
+		DBName:   getEnv("REPLICA_DB_NAME", getEnv("DB_NAME", "pismo")),
+		SSLMode:  getEnv("REPLICA_DB_SSLMODE", getEnv("DB_SSLMODE", "disable")),
+	}
+	return config, config.Host != ""
+}
+
+// replicaDSN returns the connection string of a replica. Sessions default to read-only transactions, so a
+// replica address that points at the primary by mistake still cannot be written through.
+func replicaDSN(config DatabaseConfig) string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s&default_transaction_read_only=on",
+		config.User, config.Password, config.Host, config.Port, config.DBName, config.SSLMode)
+}
+
+// OpenReplicaDatabase connects to the read replica with a pool of its own, smaller than the primary's since
+// analytics streams are few and long. The initial ping is bounded by ctx and the pool is closed if the
+// replica cannot be reached.
+func OpenReplicaDatabase(ctx context.Context, config DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", replicaDSN(config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replica database: %w", err)
+	}
+
+	db.SetMaxOpenConns(10)
+	db.SetMaxIdleConns(2)
+	db.SetConnMaxLifetime(5 * time.Minute)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping replica database: %w", err)
+	}
+	return db, nil
+}
+
+// NewAnalyticsRateLimiterFromEnv creates the rate limiter of the analytics port from the
+// ANALYTICS_RATE_LIMIT_GLOBAL_QPS and ANALYTICS_RATE_LIMIT_CALLER_QPS environment variables. The limits are
+// relaxed compared to the public port, defaulting to 5000 and 1000 streams per second respectively, since
+// the replica absorbs the load; a rate of zero disables that limit.
+func NewAnalyticsRateLimiterFromEnv() *RateLimiter {
+	globalRate, err := strconv.ParseFloat(getEnv("ANALYTICS_RATE_LIMIT_GLOBAL_QPS", "5000"), 64)
+	if err != nil || globalRate < 0 {
+		globalRate = 5000
+	}
+	callerRate, err := strconv.ParseFloat(getEnv("ANALYTICS_RATE_LIMIT_CALLER_QPS", "1000"), 64)
+	if err != nil || callerRate < 0 {
+		callerRate = 1000
+	}
+	return NewRateLimiter(globalRate, callerRate)
+}
+
+// AnalyticsServiceTokensFromEnv reads ANALYTICS_SERVICE_TOKENS, the name=token pairs of the consumers allowed
+// on the analytics port. They are kept apart from INTERNAL_SERVICE_TOKENS so a reporting job's token cannot
+// move money.
+func AnalyticsServiceTokensFromEnv() (ServiceTokens, error) {
+	return ParseServiceTokens(getEnv("ANALYTICS_SERVICE_TOKENS", ""))
+}
+
+// NewAnalyticsServer creates the gRPC server of the analytics port. Only streaming calls are intercepted, as
+// the analytics services have no unary methods: each stream is rate limited and must carry a service token
+// when it is opened, and every stream opened is recorded in the access audit.
+func NewAnalyticsServer(tokens ServiceTokens, limiter *RateLimiter, auditor *AccessAuditor, logger *Logger) *grpc.Server {
+	return grpc.NewServer(
+		grpc.ChainStreamInterceptor(
+			RequestIDStreamServerInterceptor(),
+			RateLimitStreamServerInterceptor(limiter, logger),
+			InternalAuthStreamServerInterceptor(tokens, auditor),
+		),
+	)
+}
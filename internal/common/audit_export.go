@@ -0,0 +1,361 @@
+package common
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditExportFormat identifies the layout of the packages written by AuditExporter.
+const AuditExportFormat = "pismo-audit-export/1"
+
+// Files of an audit package besides its data files.
+const (
+	AuditManifestFile  = "manifest.json"
+	AuditSignatureFile = "manifest.sig"
+	AuditChecksumsFile = "SHA256SUMS"
+)
+
+// AuditFile describes a data file of an audit package.
+type AuditFile struct {
+	Name   string `json:"name"`
+	Rows   int64  `json:"rows"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// AuditManifest lists the data files of an audit package with their checksums. The manifest is signed,
+// so the checksums prove that no file was changed, added or removed after the export.
+// From and To bound the exported records as a half-open range [From, To) in RFC 3339 UTC.
+type AuditManifest struct {
+	Format      string      `json:"format"`
+	GeneratedAt string      `json:"generated_at"`
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	PublicKey   string      `json:"public_key"`
+	Files       []AuditFile `json:"files"`
+}
+
+// auditDataset is a data file of an audit package and the query selecting its rows. The query takes the
+// bounds of the range as unix timestamps in $1 and $2; the columns listed in timestamps hold unix timestamps
+// and are written in RFC 3339 UTC.
+type auditDataset struct {
+	name       string
+	table      string
+	header     []string
+	timestamps []int
+	query      string
+}
+
+// auditDatasets are the data files of an audit package, in manifest order.
+var auditDatasets = []auditDataset{
+	{
+		name:       "transactions.csv",
+		table:      "transactions",
+		header:     []string{"created_at", "id", "account_id", "tenant_id", "operation_type", "amount", "status", "external_id", "description"},
+		timestamps: []int{0},
+		query: `
+			SELECT t.created_at, t.id, t.account_id, COALESCE(a.tenant_id, ''), t.operation_type, t.amount, t.status,
+				COALESCE(t.external_id, ''), COALESCE(t.description, '')
+			FROM transactions t JOIN accounts a ON a.id = t.account_id
+			WHERE t.created_at >= $1 AND t.created_at < $2
+			ORDER BY t.created_at, t.id`,
+	},
+	{
+		// Lifecycle changes of accounts, their balance adjustments and document changes, and the manual changes made to their transactions
+		name:       "account_changes.csv",
+		table:      "accounts",
+		header:     []string{"occurred_at", "account_id", "change", "reference_id", "actor", "detail"},
+		timestamps: []int{0},
+		query: `
+			SELECT created_at, id, 'ACCOUNT_OPENED', id, '', account_type FROM accounts
+			WHERE created_at >= $1 AND created_at < $2
+			UNION ALL
+			SELECT closed_at, id, 'ACCOUNT_CLOSED', id, '', '' FROM accounts
+			WHERE closed_at >= $1 AND closed_at < $2
+			UNION ALL
+			SELECT anonymized_at, id, 'ACCOUNT_ANONYMIZED', id, '', '' FROM accounts
+			WHERE anonymized_at >= $1 AND anonymized_at < $2
+			UNION ALL
+			SELECT requested_at, account_id, 'ADJUSTMENT_REQUESTED', id, requested_by, direction || ' ' || amount || ' ' || reason_code || COALESCE(' ' || reference, '')
+			FROM balance_adjustments WHERE requested_at >= $1 AND requested_at < $2
+			UNION ALL
+			SELECT reviewed_at, account_id, 'ADJUSTMENT_' || status, id, COALESCE(reviewed_by, ''), COALESCE(review_note, '')
+			FROM balance_adjustments WHERE reviewed_at >= $1 AND reviewed_at < $2
+			UNION ALL
+			SELECT requested_at, account_id, 'DOCUMENT_CHANGE_REQUESTED', id, requested_by, previous_document_number || ' -> ' || document_number
+			FROM document_changes WHERE requested_at >= $1 AND requested_at < $2
+			UNION ALL
+			SELECT verified_at, account_id, 'DOCUMENT_CHANGE_' || CASE WHEN status = 'REJECTED' THEN 'REJECTED' ELSE 'VERIFIED' END, id,
+				COALESCE(verified_by, ''), TRIM(COALESCE(verification_reference, '') || ' ' || COALESCE(verification_note, ''))
+			FROM document_changes WHERE verified_at >= $1 AND verified_at < $2
+			UNION ALL
+			SELECT applied_at, account_id, 'DOCUMENT_CHANGE_APPLIED', id, COALESCE(applied_by, ''), document_number
+			FROM document_changes WHERE applied_at >= $1 AND applied_at < $2
+			UNION ALL
+			SELECT e.edited_at, t.account_id, 'TRANSACTION_EDITED', e.transaction_id, e.edited_by, e.updated::TEXT
+			FROM transaction_edits e JOIN transactions t ON t.id = e.transaction_id
+			WHERE e.edited_at >= $1 AND e.edited_at < $2
+			UNION ALL
+			SELECT r.resolved_at, t.account_id, 'TRANSACTION_' || r.action, r.transaction_id, r.resolved_by, r.reason
+			FROM transaction_resolutions r JOIN transactions t ON t.id = r.transaction_id
+			WHERE r.resolved_at >= $1 AND r.resolved_at < $2
+			UNION ALL
+			SELECT v.reviewed_at, t.account_id, 'REVIEW_' || v.decision, v.transaction_id, COALESCE(v.reviewed_by, ''), v.note
+			FROM transaction_reviews v JOIN transactions t ON t.id = v.transaction_id
+			WHERE v.reviewed_at >= $1 AND v.reviewed_at < $2
+			ORDER BY 1, 2, 4`,
+	},
+	{
+		name:       "access_log.csv",
+		table:      "access_audit_log",
+		header:     []string{"occurred_at", "service", "method", "principal", "role", "tenant_id", "request_id", "decision", "reason"},
+		timestamps: []int{0},
+		query: `
+			SELECT occurred_at, service, method, principal, role, tenant_id, request_id, decision, reason
+			FROM access_audit_log
+			WHERE occurred_at >= $1 AND occurred_at < $2
+			ORDER BY id`,
+	},
+}
+
+// AuditSigningKeyFromEnv reads the key audit packages are signed with from AUDIT_EXPORT_SIGNING_KEY,
+// a base64-encoded 32-byte Ed25519 seed. Packages are never written unsigned, so the key is required.
+func AuditSigningKeyFromEnv() (ed25519.PrivateKey, error) {
+	value := getEnv("AUDIT_EXPORT_SIGNING_KEY", "")
+	if value == "" {
+		return nil, errors.New("AUDIT_EXPORT_SIGNING_KEY is not set")
+	}
+	seed, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("AUDIT_EXPORT_SIGNING_KEY must be a base64-encoded %d-byte Ed25519 seed", ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// AuditExporter writes audit packages for regulators: the transactions, account changes and access decisions
+// of a time range as CSV files, with a signed manifest of their checksums.
+type AuditExporter struct {
+	db     *sql.DB
+	logger *Logger
+	key    ed25519.PrivateKey
+	now    func() time.Time
+}
+
+// NewAuditExporter creates an exporter reading from db and signing packages with key.
+func NewAuditExporter(db *sql.DB, logger *Logger, key ed25519.PrivateKey) *AuditExporter {
+	return &AuditExporter{db: db, logger: logger, key: key, now: time.Now}
+}
+
+// Export writes the package of the records in [from, to) to dir, which is created if needed and must be empty.
+// Alongside the data files it writes the manifest, its signature and a SHA256SUMS file that can be checked
+// with sha256sum -c. Returns the manifest.
+func (e *AuditExporter) Export(ctx context.Context, dir string, from, to time.Time) (*AuditManifest, error) {
+	if !to.After(from) {
+		return nil, errors.New("end of the range must be after its start")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil {
+		return nil, fmt.Errorf("failed to read output directory: %w", err)
+	} else if len(entries) > 0 {
+		return nil, fmt.Errorf("output directory %s is not empty", dir)
+	}
+
+	manifest := &AuditManifest{
+		Format:      AuditExportFormat,
+		GeneratedAt: e.now().UTC().Format(time.RFC3339),
+		From:        from.UTC().Format(time.RFC3339),
+		To:          to.UTC().Format(time.RFC3339),
+		PublicKey:   base64.StdEncoding.EncodeToString(e.key.Public().(ed25519.PublicKey)),
+	}
+
+	// All files are read from one snapshot, so records changed during the export are consistent across them
+	tx, err := e.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, dataset := range auditDatasets {
+		file, err := e.writeDataset(ctx, tx, dir, dataset, from.Unix(), to.Unix())
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", dataset.name, err)
+		}
+		manifest.Files = append(manifest.Files, file)
+		e.logger.Info("Exported %s: Rows=%d, Bytes=%d", file.Name, file.Rows, file.Bytes)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	data = append(data, '\n')
+	if err := os.WriteFile(filepath.Join(dir, AuditManifestFile), data, 0o640); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(e.key, data)) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, AuditSignatureFile), []byte(signature), 0o640); err != nil {
+		return nil, fmt.Errorf("failed to write manifest signature: %w", err)
+	}
+
+	var sums strings.Builder
+	for _, file := range manifest.Files {
+		fmt.Fprintf(&sums, "%s  %s\n", file.SHA256, file.Name)
+	}
+	manifestSum := sha256.Sum256(data)
+	fmt.Fprintf(&sums, "%s  %s\n", hex.EncodeToString(manifestSum[:]), AuditManifestFile)
+	if err := os.WriteFile(filepath.Join(dir, AuditChecksumsFile), []byte(sums.String()), 0o640); err != nil {
+		return nil, fmt.Errorf("failed to write checksums: %w", err)
+	}
+	return manifest, nil
+}
+
+// writeDataset streams the rows of dataset in [from, to) read within tx to a CSV file in dir, hashing it as it is written.
+func (e *AuditExporter) writeDataset(ctx context.Context, tx *sql.Tx, dir string, dataset auditDataset, from, to int64) (AuditFile, error) {
+	file := AuditFile{Name: dataset.name}
+
+	out, err := os.OpenFile(filepath.Join(dir, dataset.name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return file, err
+	}
+	defer out.Close()
+
+	digest := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(out, digest)}
+	w := csv.NewWriter(counter)
+	if err := w.Write(dataset.header); err != nil {
+		return file, err
+	}
+
+	start := time.Now()
+	rows, err := tx.QueryContext(ctx, dataset.query, from, to)
+	e.logger.WithContext(ctx).LogDatabase("SELECT", dataset.table, time.Since(start), err)
+	if err != nil {
+		return file, err
+	}
+	defer rows.Close()
+
+	values := make([]sql.NullString, len(dataset.header))
+	dest := make([]interface{}, len(values))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(values))
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return file, err
+		}
+		for i, value := range values {
+			record[i] = value.String
+		}
+		for _, i := range dataset.timestamps {
+			if seconds, err := strconv.ParseInt(record[i], 10, 64); err == nil {
+				record[i] = time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return file, err
+		}
+		file.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return file, err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return file, err
+	}
+	if err := out.Close(); err != nil {
+		return file, err
+	}
+	file.Bytes = counter.n
+	file.SHA256 = hex.EncodeToString(digest.Sum(nil))
+	return file, nil
+}
+
+// VerifyAuditPackage checks the audit package in dir: that its manifest was signed with the private key of
+// publicKey and that every data file still has the size and checksum recorded in the manifest.
+// Returns the manifest, or an error describing the first failed check.
+func VerifyAuditPackage(dir string, publicKey ed25519.PublicKey) (*AuditManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, AuditManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	encoded, err := os.ReadFile(filepath.Join(dir, AuditSignatureFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest signature: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return nil, errors.New("manifest signature is not valid base64")
+	}
+	if !ed25519.Verify(publicKey, data, signature) {
+		return nil, errors.New("manifest signature does not match the public key")
+	}
+
+	var manifest AuditManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Format != AuditExportFormat {
+		return nil, fmt.Errorf("unsupported package format %q", manifest.Format)
+	}
+
+	for _, file := range manifest.Files {
+		if file.Name != filepath.Base(file.Name) {
+			return nil, fmt.Errorf("invalid file name %q in manifest", file.Name)
+		}
+		size, sum, err := hashFile(filepath.Join(dir, file.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		if size != file.Bytes || sum != file.SHA256 {
+			return nil, fmt.Errorf("%s does not match its checksum in the manifest", file.Name)
+		}
+	}
+	return &manifest, nil
+}
+
+// hashFile returns the size and hex-encoded SHA-256 digest of a file.
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	digest := sha256.New()
+	size, err := io.Copy(digest, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
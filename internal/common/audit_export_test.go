@@ -0,0 +1,216 @@
+package common
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAuditKey returns a deterministic signing key for audit export tests.
+func testAuditKey() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed([]byte(strings.Repeat("k", ed25519.SeedSize)))
+}
+
+// expectAuditExport sets up the queries of an export of [from, to) returning one row per file.
+func expectAuditExport(mock sqlmock.Sqlmock, from, to int64) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM transactions t JOIN accounts a ON a.id = t.account_id\s+WHERE t.created_at >= \$1 AND t.created_at < \$2`).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "id", "account_id", "tenant_id", "operation_type", "amount", "status", "external_id", "description"}).
+			AddRow(1759363200, "tx-1", "acc-1", "issuer-a", "CASH_PURCHASE", "-12.50", "COMPLETED", "", "Coffee, large"))
+	mock.ExpectQuery(`'ACCOUNT_OPENED'.*'ADJUSTMENT_REQUESTED'.*'TRANSACTION_EDITED'.*'REVIEW_'`).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"occurred_at", "account_id", "change", "reference_id", "actor", "detail"}).
+			AddRow(1759366800, "acc-1", "ADJUSTMENT_REQUESTED", "adj-1", "ops-1", "CREDIT 10.00 GOODWILL"))
+	mock.ExpectQuery(`FROM access_audit_log\s+WHERE occurred_at >= \$1 AND occurred_at < \$2\s+ORDER BY id`).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"occurred_at", "service", "method", "principal", "role", "tenant_id", "request_id", "decision", "reason"}).
+			AddRow(1759370400, "account-mgr", "/account.AccountService/ReviewBalanceAdjustment", "ops-2", "admin", "", "req-1", "ALLOW", "role admin permitted"))
+	mock.ExpectRollback()
+}
+
+func TestAuditExporter_Export(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(expected, actual string) error {
+		return sqlmock.QueryMatcherRegexp.Match(expected, strings.Join(strings.Fields(actual), " "))
+	})))
+	require.NoError(t, err)
+	defer db.Close()
+
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	expectAuditExport(mock, from.Unix(), to.Unix())
+
+	logger, _ := NewLogger("test-service", INFO)
+	key := testAuditKey()
+	exporter := NewAuditExporter(db, logger, key)
+	exporter.now = func() time.Time { return time.Date(2025, 11, 2, 8, 0, 0, 0, time.UTC) }
+
+	dir := filepath.Join(t.TempDir(), "package")
+	manifest, err := exporter.Export(context.Background(), dir, from, to)
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, AuditExportFormat, manifest.Format)
+	assert.Equal(t, "2025-10-01T00:00:00Z", manifest.From)
+	assert.Equal(t, "2025-11-01T00:00:00Z", manifest.To)
+	assert.Equal(t, "2025-11-02T08:00:00Z", manifest.GeneratedAt)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)), manifest.PublicKey)
+	require.Len(t, manifest.Files, 3)
+
+	transactions, err := os.ReadFile(filepath.Join(dir, "transactions.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "created_at,id,account_id,tenant_id,operation_type,amount,status,external_id,description\n"+
+		"2025-10-02T00:00:00Z,tx-1,acc-1,issuer-a,CASH_PURCHASE,-12.50,COMPLETED,,\"Coffee, large\"\n", string(transactions))
+
+	sum := sha256.Sum256(transactions)
+	assert.Equal(t, AuditFile{Name: "transactions.csv", Rows: 1, Bytes: int64(len(transactions)), SHA256: hex.EncodeToString(sum[:])}, manifest.Files[0])
+	assert.Equal(t, "account_changes.csv", manifest.Files[1].Name)
+	assert.Equal(t, "access_log.csv", manifest.Files[2].Name)
+
+	checksums, err := os.ReadFile(filepath.Join(dir, AuditChecksumsFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(checksums), hex.EncodeToString(sum[:])+"  transactions.csv\n")
+	assert.Contains(t, string(checksums), "  "+AuditManifestFile+"\n")
+
+	verified, err := VerifyAuditPackage(dir, key.Public().(ed25519.PublicKey))
+	require.NoError(t, err)
+	assert.Equal(t, manifest, verified)
+}
+
+func TestAuditExporter_Export_RequiresEmptyDirectory(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "previous.csv"), []byte("x"), 0o600))
+
+	logger, _ := NewLogger("test-service", INFO)
+	exporter := NewAuditExporter(db, logger, testAuditKey())
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err = exporter.Export(context.Background(), dir, from, from.AddDate(0, 1, 0))
+	assert.ErrorContains(t, err, "is not empty")
+
+	_, err = exporter.Export(context.Background(), filepath.Join(dir, "new"), from, from)
+	assert.ErrorContains(t, err, "end of the range must be after its start")
+}
+
+func TestAuditExporter_Export_QueryFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM transactions`).WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	logger, _ := NewLogger("test-service", INFO)
+	exporter := NewAuditExporter(db, logger, testAuditKey())
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err = exporter.Export(context.Background(), t.TempDir(), from, from.AddDate(0, 1, 0))
+	assert.ErrorContains(t, err, "failed to export transactions.csv")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyAuditPackage(t *testing.T) {
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	key := testAuditKey()
+
+	// export writes a fresh package and returns its directory
+	export := func(t *testing.T) string {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(expected, actual string) error {
+			return sqlmock.QueryMatcherRegexp.Match(expected, strings.Join(strings.Fields(actual), " "))
+		})))
+		require.NoError(t, err)
+		defer db.Close()
+		expectAuditExport(mock, from.Unix(), to.Unix())
+
+		logger, _ := NewLogger("test-service", INFO)
+		dir := t.TempDir()
+		_, err = NewAuditExporter(db, logger, key).Export(context.Background(), dir, from, to)
+		require.NoError(t, err)
+		return dir
+	}
+
+	tests := []struct {
+		name          string
+		tamper        func(t *testing.T, dir string)
+		publicKey     ed25519.PublicKey
+		expectedError string
+	}{
+		{
+			name:          "edited data file",
+			tamper:        func(t *testing.T, dir string) { appendToFile(t, filepath.Join(dir, "access_log.csv"), "extra,row\n") },
+			expectedError: "access_log.csv does not match its checksum in the manifest",
+		},
+		{
+			name:          "removed data file",
+			tamper:        func(t *testing.T, dir string) { require.NoError(t, os.Remove(filepath.Join(dir, "transactions.csv"))) },
+			expectedError: "failed to read transactions.csv",
+		},
+		{
+			name:          "edited manifest",
+			tamper:        func(t *testing.T, dir string) { appendToFile(t, filepath.Join(dir, AuditManifestFile), " ") },
+			expectedError: "manifest signature does not match the public key",
+		},
+		{
+			name:          "signed with another key",
+			publicKey:     ed25519.NewKeyFromSeed([]byte(strings.Repeat("x", ed25519.SeedSize))).Public().(ed25519.PublicKey),
+			expectedError: "manifest signature does not match the public key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := export(t)
+			if tt.tamper != nil {
+				tt.tamper(t, dir)
+			}
+			publicKey := tt.publicKey
+			if publicKey == nil {
+				publicKey = key.Public().(ed25519.PublicKey)
+			}
+
+			_, err := VerifyAuditPackage(dir, publicKey)
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestAuditSigningKeyFromEnv(t *testing.T) {
+	t.Setenv("AUDIT_EXPORT_SIGNING_KEY", "")
+	_, err := AuditSigningKeyFromEnv()
+	assert.ErrorContains(t, err, "is not set")
+
+	t.Setenv("AUDIT_EXPORT_SIGNING_KEY", base64.StdEncoding.EncodeToString([]byte("short")))
+	_, err = AuditSigningKeyFromEnv()
+	assert.ErrorContains(t, err, "32-byte Ed25519 seed")
+
+	t.Setenv("AUDIT_EXPORT_SIGNING_KEY", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", ed25519.SeedSize))))
+	key, err := AuditSigningKeyFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, testAuditKey(), key)
+}
+
+func appendToFile(t *testing.T, path, data string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+}
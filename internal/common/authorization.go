@@ -0,0 +1,102 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizationPolicies is the authorization section of the runtime config: the access policies of gateway
+// routes and gRPC methods, so who may call what can be changed without a code change or a restart.
+// Routes are keyed by HTTP method and route template, e.g. "POST /transactions/{id}/reverse"; methods by full
+// gRPC method name, e.g. "/transaction.TransactionService/ReverseTransaction". A configured method policy
+// replaces the policy the service checks in code for that method.
+type AuthorizationPolicies struct {
+	Routes  map[string]AccessPolicy `json:"routes"`
+	Methods map[string]AccessPolicy `json:"methods"`
+}
+
+// Validate reports the first malformed entry. Every policy must name at least one role.
+func (a AuthorizationPolicies) Validate() error {
+	for route, policy := range a.Routes {
+		method, path, ok := strings.Cut(route, " ")
+		if !ok || method == "" || method != strings.ToUpper(method) || !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("invalid route %q: expected an HTTP method and a path, e.g. \"GET /accounts/{id}\"", route)
+		}
+		if len(policy.Roles) == 0 {
+			return fmt.Errorf("policy of route %s has no roles", route)
+		}
+	}
+	for method, policy := range a.Methods {
+		if !strings.HasPrefix(method, "/") || strings.Count(method, "/") != 2 {
+			return fmt.Errorf("invalid method %q: expected a full gRPC method name, e.g. \"/account.AccountService/GetAccount\"", method)
+		}
+		if len(policy.Roles) == 0 {
+			return fmt.Errorf("policy of method %s has no roles", method)
+		}
+	}
+	return nil
+}
+
+// RoutePolicy returns the policy configured for an HTTP method and route template.
+func (a AuthorizationPolicies) RoutePolicy(method, template string) (AccessPolicy, bool) {
+	policy, ok := a.Routes[method+" "+template]
+	return policy, ok
+}
+
+// MethodPolicy returns the policy configured for a full gRPC method name.
+func (a AuthorizationPolicies) MethodPolicy(method string) (AccessPolicy, bool) {
+	policy, ok := a.Methods[method]
+	return policy, ok
+}
+
+type policyAuthorizedKey struct{}
+
+// policyAuthorized reports whether the call already passed the policy configured for its method.
+func policyAuthorized(ctx context.Context) bool {
+	authorized, _ := ctx.Value(policyAuthorizedKey{}).(bool)
+	return authorized
+}
+
+// AuthorizationUnaryServerInterceptor returns a server interceptor enforcing the method policies of the
+// runtime config. Calls to a method with a configured policy are checked before the handler runs and rejected
+// with codes.PermissionDenied; calls that pass are not checked again by Authorize, so the configured policy
+// replaces the one in code. It must run after AccessAuditUnaryServerInterceptor for its decisions to be audited.
+func AuthorizationUnaryServerInterceptor(runtimeConfig *RuntimeConfigManager) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorizeMethod(ctx, info.FullMethod, runtimeConfig)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthorizationStreamServerInterceptor returns the streaming counterpart of AuthorizationUnaryServerInterceptor.
+// The policy is checked once when the stream is opened.
+func AuthorizationStreamServerInterceptor(runtimeConfig *RuntimeConfigManager) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorizeMethod(ss.Context(), info.FullMethod, runtimeConfig)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorizeMethod checks a call to method against its configured policy, if any. It returns a context marking
+// the call as authorized, or a codes.PermissionDenied error.
+func authorizeMethod(ctx context.Context, method string, runtimeConfig *RuntimeConfigManager) (context.Context, error) {
+	policy, ok := runtimeConfig.Current().Authorization.MethodPolicy(method)
+	if !ok {
+		return ctx, nil
+	}
+	if !Authorize(ctx, policy) {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return context.WithValue(ctx, policyAuthorizedKey{}, true), nil
+}
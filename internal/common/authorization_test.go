@@ -0,0 +1,119 @@
+package common
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorizationPolicies_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies AuthorizationPolicies
+		wantErr  bool
+	}{
+		{"empty", AuthorizationPolicies{}, false},
+		{"valid", AuthorizationPolicies{
+			Routes:  map[string]AccessPolicy{"POST /transactions/{id}/reverse": AdminOperator},
+			Methods: map[string]AccessPolicy{"/transaction.TransactionService/ReverseTransaction": AdminOperator},
+		}, false},
+		{"route without method", AuthorizationPolicies{Routes: map[string]AccessPolicy{"/accounts": AdminOnly}}, true},
+		{"lowercase route method", AuthorizationPolicies{Routes: map[string]AccessPolicy{"get /accounts": AdminOnly}}, true},
+		{"route without roles", AuthorizationPolicies{Routes: map[string]AccessPolicy{"GET /accounts": {}}}, true},
+		{"short method name", AuthorizationPolicies{Methods: map[string]AccessPolicy{"GetAccount": AdminOnly}}, true},
+		{"method without roles", AuthorizationPolicies{Methods: map[string]AccessPolicy{"/account.AccountService/GetAccount": {}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policies.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRuntimeConfigManager_RejectsInvalidAuthorization(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	writeRuntimeConfig(t, path, `{"authorization": {"routes": {"GET /accounts": {"roles": ["admin"]}}}}`)
+
+	manager, err := NewRuntimeConfigManager(path, nil)
+	require.NoError(t, err)
+	policy, ok := manager.Current().Authorization.RoutePolicy("GET", "/accounts")
+	require.True(t, ok)
+	assert.Equal(t, []string{RoleAdmin}, policy.Roles)
+
+	writeRuntimeConfig(t, path, `{"authorization": {"routes": {"GET /accounts": {"roles": []}}}}`)
+	assert.Error(t, manager.Reload())
+	_, ok = manager.Current().Authorization.RoutePolicy("GET", "/accounts")
+	assert.True(t, ok)
+}
+
+func TestAuthorizationUnaryServerInterceptor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	writeRuntimeConfig(t, path, `{"authorization": {"methods": {
+		"/account.AccountService/ListAccessDecisions": {"roles": ["support", "admin"]}
+	}}}`)
+	manager, err := NewRuntimeConfigManager(path, nil)
+	require.NoError(t, err)
+
+	interceptor := AuthorizationUnaryServerInterceptor(manager)
+	// The handler checks the policy the service has in code
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return Authorize(ctx, AdminOnly), nil
+	}
+	call := func(method, role string) (interface{}, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(CallerRoleMetadataKey, role))
+		return interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	}
+
+	// The configured policy replaces the one in code
+	allowed, err := call("/account.AccountService/ListAccessDecisions", RoleSupport)
+	require.NoError(t, err)
+	assert.Equal(t, true, allowed)
+
+	_, err = call("/account.AccountService/ListAccessDecisions", "viewer")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Methods without a configured policy are left to the service
+	allowed, err = call("/account.AccountService/UpdateTenantSettings", RoleSupport)
+	require.NoError(t, err)
+	assert.Equal(t, false, allowed)
+}
+
+func TestAuthorizationStreamServerInterceptor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	writeRuntimeConfig(t, path, `{"authorization": {"methods": {
+		"/transaction.TransactionService/ExportTransactionHistory": {"roles": ["admin"], "require_operator": true}
+	}}}`)
+	manager, err := NewRuntimeConfigManager(path, nil)
+	require.NoError(t, err)
+
+	interceptor := AuthorizationStreamServerInterceptor(manager)
+	info := &grpc.StreamServerInfo{FullMethod: "/transaction.TransactionService/ExportTransactionHistory"}
+	handled := false
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		handled = true
+		return nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(CallerRoleMetadataKey, RoleAdmin))
+	err = interceptor(nil, &contextServerStream{ctx: ctx}, info, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.False(t, handled)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		CallerRoleMetadataKey, RoleAdmin, OperatorIDMetadataKey, "ops-1"))
+	require.NoError(t, interceptor(nil, &contextServerStream{ctx: ctx}, info, handler))
+	assert.True(t, handled)
+}
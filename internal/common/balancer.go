@@ -0,0 +1,31 @@
+package common
+
+import (
+	"strings"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/health"
+)
+
+// LoadBalancingServiceConfig is the default gRPC service config for connections to internal services.
+// It spreads calls across all resolved replicas with round_robin and enables client-side health checking,
+// so replicas reporting NOT_SERVING through grpc.health.v1 are taken out of rotation.
+const LoadBalancingServiceConfig = `{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"healthCheckConfig": {"serviceName": ""}
+}`
+
+// ServiceTarget converts a service address into a gRPC dial target using the DNS resolver.
+// Addresses that already specify a resolver scheme (e.g. "dns:///" or "passthrough:///") are returned unchanged.
+func ServiceTarget(addr string) string {
+	if strings.Contains(addr, ":///") {
+		return addr
+	}
+	return "dns:///" + addr
+}
+
+// LoadBalancingDialOption returns the dial option applying LoadBalancingServiceConfig.
+// The DNS resolver re-resolves on connection failures, so newly scaled replicas are picked up automatically.
+func LoadBalancingDialOption() grpc.DialOption {
+	return grpc.WithDefaultServiceConfig(LoadBalancingServiceConfig)
+}
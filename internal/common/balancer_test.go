@@ -0,0 +1,59 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		addr     string
+		expected string
+	}{
+		{
+			name:     "host and port",
+			addr:     "account-mgr:8081",
+			expected: "dns:///account-mgr:8081",
+		},
+		{
+			name:     "localhost",
+			addr:     "localhost:8082",
+			expected: "dns:///localhost:8082",
+		},
+		{
+			name:     "explicit dns scheme",
+			addr:     "dns:///transaction-mgr:8082",
+			expected: "dns:///transaction-mgr:8082",
+		},
+		{
+			name:     "explicit passthrough scheme",
+			addr:     "passthrough:///10.0.0.1:8081",
+			expected: "passthrough:///10.0.0.1:8081",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ServiceTarget(tt.addr))
+		})
+	}
+}
+
+func TestLoadBalancingServiceConfig(t *testing.T) {
+	var config struct {
+		LoadBalancingConfig []map[string]interface{} `json:"loadBalancingConfig"`
+		HealthCheckConfig   struct {
+			ServiceName string `json:"serviceName"`
+		} `json:"healthCheckConfig"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(LoadBalancingServiceConfig), &config))
+	require.Len(t, config.LoadBalancingConfig, 1)
+	assert.Contains(t, config.LoadBalancingConfig[0], "round_robin")
+	assert.Equal(t, "", config.HealthCheckConfig.ServiceName)
+	assert.NotNil(t, LoadBalancingDialOption())
+}
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// The business_days table records the UTC days sealed by the end-of-day close, with their totals, and
+// end_of_day_balances the balance of each account at the end of a sealed day. A trigger refuses transactions
+// dated before the end of the last sealed day, so the books of a closed day cannot change through a late or
+// backdated insert; corrections are booked as balance adjustments, dated when approved. Flows that knowingly
+// book into a sealed day, such as restoring a deleted account, opt out with AllowBackdatedInserts. End-of-day
+// balances have no foreign key to accounts, so they outlive the accounts they were recorded for.
+const (
+	createBusinessDaysTableSQL = `
+		CREATE TABLE IF NOT EXISTS business_days (
+			day_start BIGINT PRIMARY KEY,
+			closed_at BIGINT NOT NULL,
+			closed_by VARCHAR(100) NOT NULL,
+			account_count BIGINT NOT NULL,
+			transaction_count BIGINT NOT NULL,
+			net_amount DECIMAL(18,2) NOT NULL
+		)`
+
+	createEndOfDayBalancesTableSQL = `
+		CREATE TABLE IF NOT EXISTS end_of_day_balances (
+			account_id VARCHAR(36) NOT NULL,
+			day_start BIGINT NOT NULL,
+			balance DECIMAL(18,2) NOT NULL,
+			transaction_count BIGINT NOT NULL,
+			net_amount DECIMAL(18,2) NOT NULL,
+			PRIMARY KEY (account_id, day_start)
+		)`
+
+	createSealFunctionSQL = `
+		CREATE OR REPLACE FUNCTION seal_business_days() RETURNS TRIGGER AS $$
+		DECLARE
+			sealed_until BIGINT;
+		BEGIN
+			IF current_setting('pismo.allow_backdated', true) = 'on' THEN
+				RETURN NEW;
+			END IF;
+			SELECT MAX(day_start) + 86400 INTO sealed_until FROM business_days;
+			IF NEW.created_at < sealed_until THEN
+				RAISE EXCEPTION 'business day closed'
+					USING ERRCODE = 'PB001', DETAIL = format('created_at %s is before the end of the last closed day, %s', NEW.created_at, sealed_until);
+			END IF;
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql`
+
+	createSealTriggerSQL = `
+		CREATE TRIGGER transactions_seal
+		BEFORE INSERT ON transactions
+		FOR EACH ROW EXECUTE FUNCTION seal_business_days()`
+)
+
+// businessDayClosed is the SQLSTATE the seal trigger refuses a transaction dated in a closed business day with.
+const businessDayClosed = "PB001"
+
+// initBusinessDays creates the business day tables and the trigger sealing closed days.
+func (dm *DatabaseManager) initBusinessDays() error {
+	if _, err := dm.db.Exec(createBusinessDaysTableSQL); err != nil {
+		return fmt.Errorf("failed to create business_days table: %w", err)
+	}
+	if _, err := dm.db.Exec(createEndOfDayBalancesTableSQL); err != nil {
+		return fmt.Errorf("failed to create end_of_day_balances table: %w", err)
+	}
+
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin business day initialization: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(createSealFunctionSQL); err != nil {
+		return fmt.Errorf("failed to create seal function: %w", err)
+	}
+	var installed bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'transactions_seal')").Scan(&installed); err != nil {
+		return fmt.Errorf("failed to check seal trigger: %w", err)
+	}
+	if !installed {
+		if _, err := tx.Exec(createSealTriggerSQL); err != nil {
+			return fmt.Errorf("failed to create seal trigger: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit business day initialization: %w", err)
+	}
+	return nil
+}
+
+// IsBusinessDayClosed reports whether err is the database refusing a transaction dated in a closed business day.
+func IsBusinessDayClosed(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == businessDayClosed
+}
+
+// AllowBackdatedInserts lets tx insert transactions dated in closed business days, for flows that restore
+// transactions recorded before a day was closed. It lasts until tx ends.
+func AllowBackdatedInserts(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, "SELECT set_config('pismo.allow_backdated', 'on', true)")
+	return err
+}
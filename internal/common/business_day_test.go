@@ -0,0 +1,72 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseManager_initBusinessDays(t *testing.T) {
+	tests := []struct {
+		name          string
+		installed     bool
+		expectTrigger bool
+	}{
+		{name: "first run installs the trigger", installed: false, expectTrigger: true},
+		{name: "later runs only refresh the function", installed: true, expectTrigger: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectExec(`CREATE TABLE IF NOT EXISTS business_days`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`CREATE TABLE IF NOT EXISTS end_of_day_balances`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectBegin()
+			mock.ExpectExec(`CREATE OR REPLACE FUNCTION seal_business_days`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM pg_trigger`).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.installed))
+			if tt.expectTrigger {
+				mock.ExpectExec(`CREATE TRIGGER transactions_seal`).WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+			mock.ExpectCommit()
+
+			dm := &DatabaseManager{db: db}
+			require.NoError(t, dm.initBusinessDays())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestIsBusinessDayClosed(t *testing.T) {
+	sealed := &pq.Error{Code: businessDayClosed, Message: "business day closed"}
+
+	assert.True(t, IsBusinessDayClosed(sealed))
+	assert.True(t, IsBusinessDayClosed(fmt.Errorf("transaction insert failed: %w", sealed)))
+	assert.False(t, IsBusinessDayClosed(&pq.Error{Code: uniqueViolation}))
+	assert.False(t, IsBusinessDayClosed(sql.ErrConnDone))
+}
+
+func TestAllowBackdatedInserts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('pismo.allow_backdated', 'on', true\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	require.NoError(t, AllowBackdatedInserts(context.Background(), tx))
+	require.NoError(t, tx.Rollback())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
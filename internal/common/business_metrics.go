@@ -0,0 +1,216 @@
+package common
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenMetricsContentType is the content type of the OpenMetrics text exposition format.
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// businessMetricsQueryTimeout bounds the database queries of one scrape.
+const businessMetricsQueryTimeout = 5 * time.Second
+
+// Outcomes of a transaction authorization.
+const (
+	AuthorizationApproved = "approved"
+	AuthorizationDeclined = "declined"
+)
+
+// authorizationKey identifies one authorization counter.
+type authorizationKey struct {
+	operationType string
+	outcome       string
+	reason        string
+}
+
+// BusinessMetrics exposes business KPIs in the OpenMetrics text format, for product dashboards that should not
+// need database access: the balance under management and the transactions of the last minute, read from the
+// database on each scrape, and counters of the transaction authorizations made by this instance.
+type BusinessMetrics struct {
+	db     *sql.DB
+	logger *Logger
+	now    func() time.Time
+	// excludedTags are the account tags whose accounts are left out of the balance under management
+	excludedTags []string
+
+	mu             sync.Mutex
+	authorizations map[authorizationKey]uint64
+}
+
+// NewBusinessMetrics creates an exporter reading the database-derived KPIs from db.
+// Accounts tagged with one of REPORT_EXCLUDED_TAGS are left out of the balance under management.
+func NewBusinessMetrics(db *sql.DB, logger *Logger) *BusinessMetrics {
+	return &BusinessMetrics{
+		db:             db,
+		logger:         logger,
+		now:            time.Now,
+		excludedTags:   ReportExcludedTagsFromEnv(),
+		authorizations: make(map[authorizationKey]uint64),
+	}
+}
+
+// RecordAuthorization counts a transaction request of the given operation type as approved, when declineReason
+// is empty, or as declined for declineReason. It does nothing on a nil exporter.
+func (m *BusinessMetrics) RecordAuthorization(operationType, declineReason string) {
+	if m == nil {
+		return
+	}
+	key := authorizationKey{operationType: operationType, outcome: AuthorizationApproved}
+	if declineReason != "" {
+		key.outcome = AuthorizationDeclined
+		key.reason = declineReason
+	}
+
+	m.mu.Lock()
+	m.authorizations[key]++
+	m.mu.Unlock()
+}
+
+// ServeHTTP writes the KPIs. A failed database query fails the scrape, so dashboards show a gap
+// rather than partial values.
+func (m *BusinessMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), businessMetricsQueryTimeout)
+	defer cancel()
+
+	var body strings.Builder
+	if err := m.WriteOpenMetrics(ctx, &body); err != nil {
+		m.logger.WithContext(ctx).Error("Business metrics scrape failed: %v", err)
+		http.Error(w, "metrics unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", OpenMetricsContentType)
+	io.WriteString(w, body.String())
+}
+
+// WriteOpenMetrics writes the KPIs to out in the OpenMetrics text format.
+func (m *BusinessMetrics) WriteOpenMetrics(ctx context.Context, out io.Writer) error {
+	balances, err := m.balanceUnderManagement(ctx)
+	if err != nil {
+		return fmt.Errorf("balance under management: %w", err)
+	}
+	perMinute, err := m.transactionsLastMinute(ctx)
+	if err != nil {
+		return fmt.Errorf("transactions per minute: %w", err)
+	}
+
+	w := bufio.NewWriter(out)
+	fmt.Fprintln(w, "# TYPE pismo_balance_under_management gauge")
+	fmt.Fprintln(w, "# HELP pismo_balance_under_management Sum of the balances of open accounts, by account type.")
+	for _, accountType := range sortedKeys(balances) {
+		fmt.Fprintf(w, "pismo_balance_under_management{account_type=%s} %s\n",
+			quoteLabel(accountType), strconv.FormatFloat(balances[accountType], 'f', -1, 64))
+	}
+
+	fmt.Fprintln(w, "# TYPE pismo_transactions_per_minute gauge")
+	fmt.Fprintln(w, "# HELP pismo_transactions_per_minute Completed transactions created in the last minute, by operation type.")
+	for _, operationType := range sortedKeys(perMinute) {
+		fmt.Fprintf(w, "pismo_transactions_per_minute{operation_type=%s} %d\n", quoteLabel(operationType), perMinute[operationType])
+	}
+
+	m.mu.Lock()
+	keys := make([]authorizationKey, 0, len(m.authorizations))
+	for key := range m.authorizations {
+		keys = append(keys, key)
+	}
+	counts := make(map[authorizationKey]uint64, len(keys))
+	for _, key := range keys {
+		counts[key] = m.authorizations[key]
+	}
+	m.mu.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operationType != keys[j].operationType {
+			return keys[i].operationType < keys[j].operationType
+		}
+		if keys[i].outcome != keys[j].outcome {
+			return keys[i].outcome < keys[j].outcome
+		}
+		return keys[i].reason < keys[j].reason
+	})
+
+	fmt.Fprintln(w, "# TYPE pismo_transaction_authorizations counter")
+	fmt.Fprintln(w, "# HELP pismo_transaction_authorizations Transaction requests handled by this instance, by operation type and outcome.")
+	for _, key := range keys {
+		fmt.Fprintf(w, "pismo_transaction_authorizations_total{operation_type=%s,outcome=%s,reason=%s} %d\n",
+			quoteLabel(key.operationType), quoteLabel(key.outcome), quoteLabel(key.reason), counts[key])
+	}
+	fmt.Fprintln(w, "# EOF")
+	return w.Flush()
+}
+
+// balanceUnderManagement returns the sum of the balances of accounts that are not closed, by account type.
+func (m *BusinessMetrics) balanceUnderManagement(ctx context.Context) (map[string]float64, error) {
+	start := time.Now()
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT account_type, COALESCE(SUM(balance), 0) FROM accounts
+		WHERE status <> 'CLOSED' AND NOT (string_to_array(tags, ',') && string_to_array($1, ','))
+		GROUP BY account_type
+	`, strings.Join(m.excludedTags, ","))
+	m.logger.WithContext(ctx).LogDatabase("SELECT", "accounts", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[string]float64)
+	for rows.Next() {
+		var accountType string
+		var balance float64
+		if err := rows.Scan(&accountType, &balance); err != nil {
+			return nil, err
+		}
+		balances[accountType] = balance
+	}
+	return balances, rows.Err()
+}
+
+// transactionsLastMinute counts the completed transactions created in the last minute, by operation type.
+func (m *BusinessMetrics) transactionsLastMinute(ctx context.Context) (map[string]int64, error) {
+	start := time.Now()
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT operation_type, COUNT(*) FROM transactions
+		WHERE created_at >= $1 AND status = 'COMPLETED'
+		GROUP BY operation_type
+	`, m.now().Add(-time.Minute).Unix())
+	m.logger.WithContext(ctx).LogDatabase("SELECT", "transactions", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var operationType string
+		var count int64
+		if err := rows.Scan(&operationType, &count); err != nil {
+			return nil, err
+		}
+		counts[operationType] = count
+	}
+	return counts, rows.Err()
+}
+
+// quoteLabel renders a label value as a quoted OpenMetrics string.
+func quoteLabel(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// sortedKeys returns the keys of a map in ascending order.
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,87 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusinessMetrics_WriteOpenMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := NewLogger("test-service", INFO)
+	metrics := NewBusinessMetrics(db, logger)
+	metrics.now = func() time.Time { return time.Unix(1000, 0) }
+
+	metrics.RecordAuthorization("PAYMENT", "")
+	metrics.RecordAuthorization("PAYMENT", "")
+	metrics.RecordAuthorization("WITHDRAWAL", "insufficient balance")
+	var nilMetrics *BusinessMetrics
+	nilMetrics.RecordAuthorization("PAYMENT", "")
+
+	mock.ExpectQuery(`SELECT account_type, COALESCE\(SUM\(balance\), 0\) FROM accounts\s+WHERE status <> 'CLOSED' AND NOT \(string_to_array\(tags, ','\) && string_to_array\(\$1, ','\)\)`).
+		WithArgs("TEST").
+		WillReturnRows(sqlmock.NewRows([]string{"account_type", "sum"}).
+			AddRow("SAVINGS", 1500.25).
+			AddRow("CHECKING", 300.0))
+	mock.ExpectQuery(`SELECT operation_type, COUNT\(\*\) FROM transactions`).
+		WithArgs(int64(940)).
+		WillReturnRows(sqlmock.NewRows([]string{"operation_type", "count"}).AddRow("PAYMENT", 2))
+
+	var out strings.Builder
+	require.NoError(t, metrics.WriteOpenMetrics(context.Background(), &out))
+	assert.Equal(t, `# TYPE pismo_balance_under_management gauge
+# HELP pismo_balance_under_management Sum of the balances of open accounts, by account type.
+pismo_balance_under_management{account_type="CHECKING"} 300
+pismo_balance_under_management{account_type="SAVINGS"} 1500.25
+# TYPE pismo_transactions_per_minute gauge
+# HELP pismo_transactions_per_minute Completed transactions created in the last minute, by operation type.
+pismo_transactions_per_minute{operation_type="PAYMENT"} 2
+# TYPE pismo_transaction_authorizations counter
+# HELP pismo_transaction_authorizations Transaction requests handled by this instance, by operation type and outcome.
+pismo_transaction_authorizations_total{operation_type="PAYMENT",outcome="approved",reason=""} 2
+pismo_transaction_authorizations_total{operation_type="WITHDRAWAL",outcome="declined",reason="insufficient balance"} 1
+# EOF
+`, out.String())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBusinessMetrics_ServeHTTP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := NewLogger("test-service", INFO)
+	metrics := NewBusinessMetrics(db, logger)
+
+	mock.ExpectQuery(`FROM accounts`).WillReturnRows(sqlmock.NewRows([]string{"account_type", "sum"}))
+	mock.ExpectQuery(`FROM transactions`).WillReturnRows(sqlmock.NewRows([]string{"operation_type", "count"}))
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, OpenMetricsContentType, rec.Header().Get("Content-Type"))
+	assert.True(t, strings.HasSuffix(rec.Body.String(), "# EOF\n"))
+
+	// A failed query fails the whole scrape
+	mock.ExpectQuery(`FROM accounts`).WillReturnError(sql.ErrConnDone)
+	rec = httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuoteLabel(t *testing.T) {
+	assert.Equal(t, `"a\"b\\c\nd"`, quoteLabel("a\"b\\c\nd"))
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// CallerRoleMetadataKey is the gRPC metadata key carrying the role of the end user a call is made for.
+const CallerRoleMetadataKey = "x-caller-role"
+
+// Caller roles recognised by the services. Unknown or missing roles get the most restricted view.
+const (
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+// OperatorIDMetadataKey is the gRPC metadata key carrying the identity of the back-office operator a call
+// is made for, as asserted by the authenticating proxy in front of the gateway.
+const OperatorIDMetadataKey = "x-operator-id"
+
+// visibleDocumentDigits is how many trailing characters of a document number stay visible when masked.
+const visibleDocumentDigits = 4
+
+// CallerRoleFromContext returns the caller role from incoming gRPC metadata, or an empty string if none was sent.
+func CallerRoleFromContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(CallerRoleMetadataKey); len(values) > 0 {
+			return strings.ToLower(strings.TrimSpace(values[0]))
+		}
+	}
+	return ""
+}
+
+// OperatorIDFromContext returns the operator identity from incoming gRPC metadata, or an empty string if none was sent.
+func OperatorIDFromContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(OperatorIDMetadataKey); len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+	}
+	return ""
+}
+
+// CanViewFullDocumentNumber reports whether the role may see unmasked document numbers.
+func CanViewFullDocumentNumber(role string) bool {
+	return role == RoleAdmin
+}
+
+// MaskDocumentNumber replaces all but the last few characters of a document number with '*'.
+func MaskDocumentNumber(documentNumber string) string {
+	if len(documentNumber) <= visibleDocumentDigits {
+		return strings.Repeat("*", len(documentNumber))
+	}
+	hidden := len(documentNumber) - visibleDocumentDigits
+	return strings.Repeat("*", hidden) + documentNumber[hidden:]
+}
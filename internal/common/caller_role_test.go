@@ -0,0 +1,47 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestCallerRoleFromContext(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(CallerRoleMetadataKey, " Admin "))
+	assert.Equal(t, RoleAdmin, CallerRoleFromContext(ctx))
+
+	assert.Equal(t, "", CallerRoleFromContext(context.Background()))
+}
+
+func TestOperatorIDFromContext(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(OperatorIDMetadataKey, " ops-alice "))
+	assert.Equal(t, "ops-alice", OperatorIDFromContext(ctx))
+
+	assert.Equal(t, "", OperatorIDFromContext(context.Background()))
+}
+
+func TestCanViewFullDocumentNumber(t *testing.T) {
+	assert.True(t, CanViewFullDocumentNumber(RoleAdmin))
+	assert.False(t, CanViewFullDocumentNumber(RoleSupport))
+	assert.False(t, CanViewFullDocumentNumber(""))
+}
+
+func TestMaskDocumentNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "12345678901", expected: "*******8901"},
+		{input: "12345", expected: "*2345"},
+		{input: "1234", expected: "****"},
+		{input: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MaskDocumentNumber(tt.input))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StatusClientClosedRequest is the non-standard HTTP status (popularised by nginx) recorded for
+// requests whose client went away before a response was sent.
+const StatusClientClosedRequest = 499
+
+// IsCancellation reports whether err is the result of the caller cancelling the request,
+// either locally or as reported by a downstream gRPC call.
+func IsCancellation(err error) bool {
+	return errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled
+}
+
+// WithTransaction runs fn in a database transaction bound to ctx and commits it if fn succeeds.
+// If fn fails, or ctx is cancelled before the commit (for example because the client disconnected),
+// the transaction is rolled back so no partial changes are left behind.
+func WithTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CancellationUnaryServerInterceptor logs calls whose client cancelled them, with status 499,
+// so abandoned requests can be told apart from failures in the logs.
+func CancellationUnaryServerInterceptor(logger *Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		if errors.Is(ctx.Err(), context.Canceled) {
+			logger.WithContext(ctx).Warn("gRPC %s cancelled by client after %v - Status: %d", info.FullMethod, time.Since(start), StatusClientClosedRequest)
+		}
+		return resp, err
+	}
+}
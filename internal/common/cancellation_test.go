@@ -0,0 +1,103 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsCancellation(t *testing.T) {
+	assert.True(t, IsCancellation(context.Canceled))
+	assert.True(t, IsCancellation(status.Error(codes.Canceled, "context canceled")))
+	assert.False(t, IsCancellation(status.Error(codes.Unavailable, "connection refused")))
+	assert.False(t, IsCancellation(nil))
+}
+
+func TestWithTransaction(t *testing.T) {
+	t.Run("commits on success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE accounts`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err = WithTransaction(context.Background(), db, func(tx *sql.Tx) error {
+			_, err := tx.Exec("UPDATE accounts SET balance = 0")
+			return err
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back when fn fails", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		failure := errors.New("insert failed")
+		err = WithTransaction(context.Background(), db, func(tx *sql.Tx) error { return failure })
+		assert.ErrorIs(t, err, failure)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back when the client cancels mid-way", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE accounts`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		err = WithTransaction(ctx, db, func(tx *sql.Tx) error {
+			if _, err := tx.Exec("UPDATE accounts SET balance = 0"); err != nil {
+				return err
+			}
+			cancel()
+			return nil
+		})
+		assert.True(t, IsCancellation(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCancellationUnaryServerInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	interceptor := CancellationUnaryServerInterceptor(newBufferLogger(&buf))
+	info := &grpc.UnaryServerInfo{FullMethod: "/transaction.TransactionService/CreateTransaction"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	_, err := interceptor(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "CreateTransaction cancelled by client")
+	assert.Contains(t, buf.String(), "Status: 499")
+}
+
+func TestLogger_LogRequest_ClientClosedRequest(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newBufferLogger(&buf)
+
+	logger.LogRequest("POST", "/transactions", "127.0.0.1", StatusClientClosedRequest, 0)
+	assert.Contains(t, buf.String(), "Status: 499 (client closed request)")
+}
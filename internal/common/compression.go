@@ -0,0 +1,86 @@
+package common
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultCompressionThreshold is the minimum message size in bytes that gets gzip-compressed.
+// Smaller messages are sent uncompressed since the CPU cost outweighs the bandwidth saved.
+const DefaultCompressionThreshold = 1024
+
+// CompressionConfig holds configuration for gRPC message compression.
+// Messages at or above Threshold bytes are compressed with gzip when Enabled is true.
+type CompressionConfig struct {
+	Enabled   bool
+	Threshold int
+}
+
+// NewCompressionConfig creates a compression configuration from environment variables.
+// GRPC_COMPRESSION set to "none" disables compression; GRPC_COMPRESSION_THRESHOLD sets the size threshold in bytes.
+func NewCompressionConfig() CompressionConfig {
+	threshold, err := strconv.Atoi(getEnv("GRPC_COMPRESSION_THRESHOLD", strconv.Itoa(DefaultCompressionThreshold)))
+	if err != nil || threshold < 0 {
+		threshold = DefaultCompressionThreshold
+	}
+
+	return CompressionConfig{
+		Enabled:   getEnv("GRPC_COMPRESSION", gzip.Name) != "none",
+		Threshold: threshold,
+	}
+}
+
+// shouldCompress reports whether a message is large enough to be worth compressing.
+func (c CompressionConfig) shouldCompress(msg interface{}) bool {
+	if !c.Enabled {
+		return false
+	}
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return false
+	}
+	return proto.Size(m) >= c.Threshold
+}
+
+// CompressionUnaryServerInterceptor returns a server interceptor that gzip-compresses large responses.
+// Compression is only applied when the client advertises gzip support, which grpc-go clients do once
+// the gzip codec is registered.
+func CompressionUnaryServerInterceptor(config CompressionConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil && config.shouldCompress(resp) {
+			// Best effort: the response is still sent uncompressed if the peer does not accept gzip.
+			_ = grpc.SetSendCompressor(ctx, gzip.Name)
+		}
+		return resp, err
+	}
+}
+
+// CompressionStreamServerInterceptor returns the streaming counterpart of CompressionUnaryServerInterceptor.
+// A stream's compressor is chosen before its first message, when the size of what it will send is not known,
+// so every stream is gzip-compressed while compression is enabled: streams carry bulk traffic, whose many
+// messages add up even when each is small.
+func CompressionStreamServerInterceptor(config CompressionConfig) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if config.Enabled {
+			// Best effort: the stream is still sent uncompressed if the peer does not accept gzip.
+			_ = grpc.SetSendCompressor(ss.Context(), gzip.Name)
+		}
+		return handler(srv, ss)
+	}
+}
+
+// CompressionUnaryClientInterceptor returns a client interceptor that gzip-compresses large requests.
+// Responses are decompressed transparently by grpc-go regardless of their size.
+func CompressionUnaryClientInterceptor(config CompressionConfig) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if config.shouldCompress(req) {
+			opts = append(opts, grpc.UseCompressor(gzip.Name))
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,190 @@
+package common
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestNewCompressionConfig_DefaultValues(t *testing.T) {
+	config := NewCompressionConfig()
+
+	assert.True(t, config.Enabled)
+	assert.Equal(t, DefaultCompressionThreshold, config.Threshold)
+}
+
+func TestNewCompressionConfig_EnvironmentVariables(t *testing.T) {
+	os.Setenv("GRPC_COMPRESSION", "none")
+	os.Setenv("GRPC_COMPRESSION_THRESHOLD", "2048")
+
+	defer func() {
+		os.Unsetenv("GRPC_COMPRESSION")
+		os.Unsetenv("GRPC_COMPRESSION_THRESHOLD")
+	}()
+
+	config := NewCompressionConfig()
+
+	assert.False(t, config.Enabled)
+	assert.Equal(t, 2048, config.Threshold)
+}
+
+func TestNewCompressionConfig_InvalidThreshold(t *testing.T) {
+	os.Setenv("GRPC_COMPRESSION_THRESHOLD", "not-a-number")
+	defer os.Unsetenv("GRPC_COMPRESSION_THRESHOLD")
+
+	config := NewCompressionConfig()
+
+	assert.Equal(t, DefaultCompressionThreshold, config.Threshold)
+}
+
+func TestCompressionUnaryClientInterceptor(t *testing.T) {
+	config := CompressionConfig{Enabled: true, Threshold: 100}
+
+	tests := []struct {
+		name             string
+		config           CompressionConfig
+		request          interface{}
+		expectCompressor bool
+	}{
+		{
+			name:             "small request is not compressed",
+			config:           config,
+			request:          wrapperspb.String("small"),
+			expectCompressor: false,
+		},
+		{
+			name:             "large request is compressed",
+			config:           config,
+			request:          wrapperspb.String(strings.Repeat("x", 200)),
+			expectCompressor: true,
+		},
+		{
+			name:             "compression disabled",
+			config:           CompressionConfig{Enabled: false, Threshold: 100},
+			request:          wrapperspb.String(strings.Repeat("x", 200)),
+			expectCompressor: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := CompressionUnaryClientInterceptor(tt.config)
+
+			var received []grpc.CallOption
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				received = opts
+				return nil
+			}
+
+			err := interceptor(context.Background(), "/test.Service/Method", tt.request, nil, nil, invoker)
+			assert.NoError(t, err)
+
+			hasCompressor := false
+			for _, opt := range received {
+				if _, ok := opt.(grpc.CompressorCallOption); ok {
+					hasCompressor = true
+				}
+			}
+			assert.Equal(t, tt.expectCompressor, hasCompressor)
+		})
+	}
+}
+
+func TestCompressionUnaryServerInterceptor(t *testing.T) {
+	interceptor := CompressionUnaryServerInterceptor(CompressionConfig{Enabled: true, Threshold: 10})
+	expected := wrapperspb.String(strings.Repeat("x", 100))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return expected, nil
+	}
+
+	resp, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, resp)
+}
+
+// payloadRecorder records the messages a client receives.
+type payloadRecorder struct {
+	mu       sync.Mutex
+	payloads []*stats.InPayload
+}
+
+func (r *payloadRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *payloadRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *payloadRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func (r *payloadRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if payload, ok := s.(*stats.InPayload); ok {
+		r.mu.Lock()
+		r.payloads = append(r.payloads, payload)
+		r.mu.Unlock()
+	}
+}
+
+func TestCompressionStreamServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         CompressionConfig
+		wantCompressed bool
+	}{
+		{
+			name:           "streams are compressed whatever the size of their messages",
+			config:         CompressionConfig{Enabled: true, Threshold: 1024},
+			wantCompressed: true,
+		},
+		{
+			name:           "compression disabled",
+			config:         CompressionConfig{Enabled: false, Threshold: 1024},
+			wantCompressed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			server := grpc.NewServer(grpc.StreamInterceptor(CompressionStreamServerInterceptor(tt.config)))
+			healthpb.RegisterHealthServer(server, health.NewServer())
+			go server.Serve(lis)
+			defer server.Stop()
+
+			recorder := &payloadRecorder{}
+			conn, err := grpc.NewClient("passthrough:///"+lis.Addr().String(),
+				grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithStatsHandler(recorder))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			stream, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{})
+			require.NoError(t, err)
+			_, err = stream.Recv()
+			require.NoError(t, err)
+
+			recorder.mu.Lock()
+			defer recorder.mu.Unlock()
+			require.Len(t, recorder.payloads, 1)
+			payload := recorder.payloads[0]
+			assert.Equal(t, tt.wantCompressed, payload.CompressedLength != payload.Length)
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// Defaults for client connection pools.
+const (
+	DefaultChannelsPerBackend        = 2
+	DefaultClientMaxConnectionAge    = 30 * time.Minute
+	DefaultClientConnectionDrainTime = 30 * time.Second
+	connPoolCheckInterval            = time.Second
+	connPoolReplaceTimeout           = 10 * time.Second
+	connPoolDrainPollInterval        = 50 * time.Millisecond
+)
+
+// ClientConnPoolConfig configures the channels a ClientConnPool keeps to its backend.
+// A zero MaxAge disables age-based replacement.
+type ClientConnPoolConfig struct {
+	Channels   int
+	MaxAge     time.Duration
+	DrainGrace time.Duration
+}
+
+// NewClientConnPoolConfigFromEnv reads GRPC_CHANNELS_PER_BACKEND, GRPC_CLIENT_MAX_CONNECTION_AGE and
+// GRPC_CLIENT_DRAIN_GRACE. Invalid values fall back to the defaults.
+func NewClientConnPoolConfigFromEnv() ClientConnPoolConfig {
+	config := ClientConnPoolConfig{
+		Channels:   DefaultChannelsPerBackend,
+		MaxAge:     DefaultClientMaxConnectionAge,
+		DrainGrace: DefaultClientConnectionDrainTime,
+	}
+	if channels, err := strconv.Atoi(getEnv("GRPC_CHANNELS_PER_BACKEND", "")); err == nil && channels > 0 {
+		config.Channels = channels
+	}
+	if age, err := time.ParseDuration(getEnv("GRPC_CLIENT_MAX_CONNECTION_AGE", "")); err == nil && age >= 0 {
+		config.MaxAge = age
+	}
+	if grace, err := time.ParseDuration(getEnv("GRPC_CLIENT_DRAIN_GRACE", "")); err == nil && grace >= 0 {
+		config.DrainGrace = grace
+	}
+	return config
+}
+
+// pooledConn is one channel of a pool, with the number of calls still using it.
+type pooledConn struct {
+	conn      *grpc.ClientConn
+	expiresAt time.Time
+	inflight  atomic.Int64
+}
+
+// ClientConnPool spreads the calls to one backend over several gRPC channels, so a rolling restart
+// of the backend does not fail every call at once. It implements grpc.ClientConnInterface and can be
+// passed to generated client constructors.
+//
+// Each channel is replaced by a freshly dialed one, which resolves the backend address again:
+//   - when it reaches its max age, jittered so channels are not replaced together, once the new channel is READY;
+//   - when it loses its connections after having been READY, e.g. because the backend sent GOAWAY on shutdown.
+//
+// Replaced channels stop receiving calls and are closed once their in-flight calls complete,
+// or after the drain grace period.
+type ClientConnPool struct {
+	target  string
+	options []grpc.DialOption
+	config  ClientConnPoolConfig
+	logger  *Logger
+
+	mu       sync.RWMutex
+	channels []*pooledConn
+	closed   bool
+	next     atomic.Uint64
+	stop     chan struct{}
+}
+
+// NewClientConnPool dials config.Channels channels to target and starts replacing them as they age or
+// lose their connections. Dialing does not wait for the backend; use WaitForReady for that.
+func NewClientConnPool(target string, config ClientConnPoolConfig, logger *Logger, options ...grpc.DialOption) (*ClientConnPool, error) {
+	if config.Channels <= 0 {
+		config.Channels = 1
+	}
+	p := &ClientConnPool{
+		target:  target,
+		options: options,
+		config:  config,
+		logger:  logger,
+		stop:    make(chan struct{}),
+	}
+	for i := 0; i < config.Channels; i++ {
+		pc, err := p.dial()
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.channels = append(p.channels, pc)
+		go p.watch(pc)
+	}
+	if config.MaxAge > 0 {
+		go p.expireChannels()
+	}
+	return p, nil
+}
+
+// Invoke performs a unary RPC on the next channel of the pool.
+func (p *ClientConnPool) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	pc := p.pick()
+	pc.inflight.Add(1)
+	defer pc.inflight.Add(-1)
+	return pc.conn.Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream opens a stream on the next channel of the pool. The stream counts as in flight
+// until it returns an error or io.EOF from RecvMsg.
+func (p *ClientConnPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	pc := p.pick()
+	pc.inflight.Add(1)
+	stream, err := pc.conn.NewStream(ctx, desc, method, opts...)
+	if err != nil {
+		pc.inflight.Add(-1)
+		return nil, err
+	}
+	return &pooledStream{ClientStream: stream, pc: pc}, nil
+}
+
+// WaitForReady blocks until every channel of the pool is READY.
+func (p *ClientConnPool) WaitForReady(ctx context.Context) error {
+	p.mu.RLock()
+	channels := append([]*pooledConn(nil), p.channels...)
+	p.mu.RUnlock()
+	for _, pc := range channels {
+		if err := WaitForReady(ctx, pc.conn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes every channel, cancelling calls still in flight.
+func (p *ClientConnPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	channels := p.channels
+	p.mu.Unlock()
+
+	close(p.stop)
+	for _, pc := range channels {
+		pc.conn.Close()
+	}
+	return nil
+}
+
+// pick returns the channels of the pool in turn.
+func (p *ClientConnPool) pick() *pooledConn {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.channels[p.next.Add(1)%uint64(len(p.channels))]
+}
+
+// dial creates a channel to the backend and starts connecting it.
+func (p *ClientConnPool) dial() (*pooledConn, error) {
+	conn, err := grpc.Dial(p.target, p.options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", p.target, err)
+	}
+	conn.Connect()
+
+	pc := &pooledConn{conn: conn}
+	if p.config.MaxAge > 0 {
+		// Up to 10% jitter so channels dialed together are not replaced together
+		jitter := time.Duration(rand.Int63n(int64(p.config.MaxAge)/10 + 1))
+		pc.expiresAt = time.Now().Add(p.config.MaxAge - jitter)
+	}
+	return pc, nil
+}
+
+// watch replaces pc if it loses its connections after having been READY. A channel that never became
+// READY is left to the gRPC reconnect backoff, so an unavailable backend does not cause redial churn.
+func (p *ClientConnPool) watch(pc *pooledConn) {
+	state := pc.conn.GetState()
+	for {
+		if !pc.conn.WaitForStateChange(context.Background(), state) {
+			return
+		}
+		previous := state
+		state = pc.conn.GetState()
+		if state == connectivity.Shutdown {
+			return
+		}
+		if previous == connectivity.Ready && state != connectivity.Ready {
+			p.replace(pc, "connection lost ("+state.String()+")", false)
+			return
+		}
+	}
+}
+
+// expireChannels replaces channels that reached their max age until the pool is closed.
+func (p *ClientConnPool) expireChannels() {
+	ticker := time.NewTicker(connPoolCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			p.mu.RLock()
+			var expired []*pooledConn
+			for _, pc := range p.channels {
+				if now.After(pc.expiresAt) {
+					expired = append(expired, pc)
+				}
+			}
+			p.mu.RUnlock()
+			for _, pc := range expired {
+				p.replace(pc, "max age reached", true)
+			}
+		}
+	}
+}
+
+// replace swaps old for a new channel and drains old. With waitReady, the swap only happens once the
+// new channel is READY; otherwise old is kept and replaced on a later attempt.
+func (p *ClientConnPool) replace(old *pooledConn, reason string, waitReady bool) {
+	pc, err := p.dial()
+	if err != nil {
+		p.logger.Error("Failed to replace channel to %s (%s): %v", p.target, reason, err)
+		return
+	}
+	if waitReady {
+		ctx, cancel := context.WithTimeout(context.Background(), connPoolReplaceTimeout)
+		err := WaitForReady(ctx, pc.conn)
+		cancel()
+		if err != nil {
+			p.logger.Warn("Keeping channel to %s (%s): replacement not ready: %v", p.target, reason, err)
+			pc.conn.Close()
+			return
+		}
+	}
+
+	p.mu.Lock()
+	index := -1
+	for i, current := range p.channels {
+		if current == old {
+			index = i
+		}
+	}
+	if p.closed || index < 0 {
+		p.mu.Unlock()
+		pc.conn.Close()
+		return
+	}
+	p.channels[index] = pc
+	p.mu.Unlock()
+
+	p.logger.Info("Replaced channel to %s: %s", p.target, reason)
+	go p.watch(pc)
+	go p.drain(old)
+}
+
+// drain closes pc once its in-flight calls complete, or after the drain grace period.
+func (p *ClientConnPool) drain(pc *pooledConn) {
+	deadline := time.Now().Add(p.config.DrainGrace)
+	for pc.inflight.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(connPoolDrainPollInterval)
+	}
+	if n := pc.inflight.Load(); n > 0 {
+		p.logger.Warn("Closing channel to %s with %d calls still in flight", p.target, n)
+	}
+	pc.conn.Close()
+}
+
+// pooledStream releases its channel when the stream ends.
+type pooledStream struct {
+	grpc.ClientStream
+	pc   *pooledConn
+	once sync.Once
+}
+
+// RecvMsg receives a message, releasing the channel once the stream has ended.
+func (s *pooledStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.once.Do(func() { s.pc.inflight.Add(-1) })
+	}
+	return err
+}
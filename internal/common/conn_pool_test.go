@@ -0,0 +1,105 @@
+package common
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// startHealthServer serves the gRPC health service on a local port.
+func startHealthServer(t *testing.T) (*grpc.Server, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	return server, lis.Addr().String()
+}
+
+func newTestConnPool(t *testing.T, addr string, config ClientConnPoolConfig) *ClientConnPool {
+	logger, _ := NewLogger("test-service", INFO)
+	pool, err := NewClientConnPool("passthrough:///"+addr, config, logger, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { pool.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, pool.WaitForReady(ctx))
+	return pool
+}
+
+func TestClientConnPool_SpreadsCalls(t *testing.T) {
+	server, addr := startHealthServer(t)
+	defer server.Stop()
+
+	pool := newTestConnPool(t, addr, ClientConnPoolConfig{Channels: 3})
+	picked := make(map[*pooledConn]bool)
+	for i := 0; i < 3; i++ {
+		picked[pool.pick()] = true
+	}
+	assert.Len(t, picked, 3)
+
+	resp, err := healthpb.NewHealthClient(pool).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	for pc := range picked {
+		assert.Zero(t, pc.inflight.Load())
+	}
+}
+
+func TestClientConnPool_ReplaceDrainsOldChannel(t *testing.T) {
+	server, addr := startHealthServer(t)
+	defer server.Stop()
+
+	pool := newTestConnPool(t, addr, ClientConnPoolConfig{Channels: 1, DrainGrace: time.Second})
+	old := pool.channels[0]
+	old.inflight.Add(1)
+
+	pool.replace(old, "max age reached", true)
+	require.NotSame(t, old, pool.pick())
+
+	// The old channel stays open while a call is in flight
+	time.Sleep(3 * connPoolDrainPollInterval)
+	assert.NotEqual(t, connectivity.Shutdown, old.conn.GetState())
+	old.inflight.Add(-1)
+	assert.Eventually(t, func() bool { return old.conn.GetState() == connectivity.Shutdown }, time.Second, 10*time.Millisecond)
+
+	resp, err := healthpb.NewHealthClient(pool).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+func TestClientConnPool_ReplacesChannelOnGoAway(t *testing.T) {
+	server, addr := startHealthServer(t)
+
+	pool := newTestConnPool(t, addr, ClientConnPoolConfig{Channels: 1})
+	old := pool.pick()
+
+	// A graceful stop sends GOAWAY, as a backend does during a rolling restart
+	server.GracefulStop()
+	assert.Eventually(t, func() bool { return pool.pick() != old }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNewClientConnPoolConfigFromEnv(t *testing.T) {
+	config := NewClientConnPoolConfigFromEnv()
+	assert.Equal(t, DefaultChannelsPerBackend, config.Channels)
+	assert.Equal(t, DefaultClientMaxConnectionAge, config.MaxAge)
+	assert.Equal(t, DefaultClientConnectionDrainTime, config.DrainGrace)
+
+	t.Setenv("GRPC_CHANNELS_PER_BACKEND", "4")
+	t.Setenv("GRPC_CLIENT_MAX_CONNECTION_AGE", "0")
+	t.Setenv("GRPC_CLIENT_DRAIN_GRACE", "invalid")
+	config = NewClientConnPoolConfigFromEnv()
+	assert.Equal(t, 4, config.Channels)
+	assert.Zero(t, config.MaxAge)
+	assert.Equal(t, DefaultClientConnectionDrainTime, config.DrainGrace)
+}
@@ -1,13 +1,15 @@
 package common
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // DatabaseConfig holds configuration parameters for database connection.
@@ -32,6 +34,12 @@ type DatabaseManager struct {
 // It reads configuration from environment variables and establishes a connection to PostgreSQL.
 // Returns the manager instance or an error if connection fails.
 func NewDatabaseManager() (*DatabaseManager, error) {
+	return NewDatabaseManagerContext(context.Background())
+}
+
+// NewDatabaseManagerContext is NewDatabaseManager with the initial ping bounded by ctx.
+// The connection pool is closed if the database cannot be reached.
+func NewDatabaseManagerContext(ctx context.Context) (*DatabaseManager, error) {
 	config := DatabaseConfig{
 		Host:     getEnv("DB_HOST", "localhost"),
 		Port:     getEnv("DB_PORT", "5432"),
@@ -53,7 +61,8 @@ func NewDatabaseManager() (*DatabaseManager, error) {
 	db.SetMaxIdleConns(5)
 	db.SetConnMaxLifetime(5 * time.Minute)
 
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
@@ -85,7 +94,9 @@ func (dm *DatabaseManager) Health() error {
 }
 
 // InitSchema initializes the database schema by creating tables and indexes.
-// It creates the accounts and transactions tables with appropriate constraints and indexes.
+// It creates the accounts, transactions, disputes, tenant_settings, balance_adjustments, document_changes, account_audit, account_snapshots, operation_type_rules and account_budgets tables with
+// appropriate constraints and indexes, seeds the default operation type rules, and creates the rollup tables used for reporting.
+// New tables and columns must also be added to expectedSchema, which VerifySchema checks the live schema against.
 // Returns an error if schema initialization fails.
 func (dm *DatabaseManager) InitSchema() error {
 	_, err := dm.db.Exec(`
@@ -93,24 +104,74 @@ func (dm *DatabaseManager) InitSchema() error {
 			id VARCHAR(36) PRIMARY KEY,
 			document_number VARCHAR(20) NOT NULL UNIQUE,
 			account_type VARCHAR(20) NOT NULL CHECK (account_type IN ('CHECKING', 'SAVINGS', 'CREDIT')),
-			balance DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (balance >= 0),
+			balance DECIMAL(15,2) NOT NULL DEFAULT 0,
 			created_at BIGINT NOT NULL,
-			updated_at BIGINT NOT NULL
+			updated_at BIGINT NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('DRAFT', 'PENDING_KYC', 'ACTIVE', 'CLOSED')),
+			holder_name VARCHAR(200),
+			holder_email VARCHAR(200),
+			holder_phone VARCHAR(32),
+			tags VARCHAR(500) NOT NULL DEFAULT '',
+			kyc_reference VARCHAR(100),
+			opening_balance DECIMAL(15,2),
+			version BIGINT NOT NULL DEFAULT 1,
+			tenant_id VARCHAR(64),
+			closed_at BIGINT,
+			anonymized_at BIGINT,
+			overdraft_limit DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0),
+			CONSTRAINT accounts_balance_check CHECK (balance >= -overdraft_limit)
 		)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to create accounts table: %w", err)
 	}
 
+	// Onboarding columns for accounts tables created before they existed; existing accounts stay ACTIVE
+	accountColumns := []string{
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('DRAFT', 'PENDING_KYC', 'ACTIVE'))",
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS holder_name VARCHAR(200)",
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS holder_email VARCHAR(200)",
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS kyc_reference VARCHAR(100)",
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS opening_balance DECIMAL(15,2)",
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1",
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64)",
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS closed_at BIGINT",
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS anonymized_at BIGINT",
+		// Accounts of tenants with retention settings are closed instead of deleted
+		"ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_status_check",
+		"ALTER TABLE accounts ADD CONSTRAINT accounts_status_check CHECK (status IN ('DRAFT', 'PENDING_KYC', 'ACTIVE', 'CLOSED'))",
+		// Checking accounts may be overdrawn up to their overdraft limit
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS overdraft_limit DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0)",
+		"ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_balance_check",
+		"ALTER TABLE accounts ADD CONSTRAINT accounts_balance_check CHECK (balance >= -overdraft_limit)",
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS holder_phone VARCHAR(32)",
+		"ALTER TABLE accounts ADD COLUMN IF NOT EXISTS tags VARCHAR(500) NOT NULL DEFAULT ''",
+	}
+	for _, columnSQL := range accountColumns {
+		if _, err := dm.db.Exec(columnSQL); err != nil {
+			return fmt.Errorf("failed to migrate accounts table: %w", err)
+		}
+	}
+
 	_, err = dm.db.Exec(`
 		CREATE TABLE IF NOT EXISTS transactions (
 			id VARCHAR(36) PRIMARY KEY,
 			account_id VARCHAR(36) NOT NULL,
-			operation_type VARCHAR(50) NOT NULL CHECK (operation_type IN ('CASH_PURCHASE', 'INSTALLMENT_PURCHASE', 'WITHDRAWAL', 'PAYMENT')),
+			operation_type VARCHAR(50) NOT NULL CHECK (operation_type IN ('CASH_PURCHASE', 'INSTALLMENT_PURCHASE', 'WITHDRAWAL', 'PAYMENT', 'TRANSFER_OUT', 'TRANSFER_IN', 'REVERSAL')),
 			amount DECIMAL(15,2) NOT NULL,
 			description TEXT,
 			created_at BIGINT NOT NULL,
-			status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'COMPLETED', 'FAILED', 'CANCELLED')),
+			status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'UNDER_REVIEW', 'COMPLETED', 'DECLINED', 'FAILED', 'CANCELLED', 'REVERSED')),
+			external_id VARCHAR(64),
+			tags VARCHAR(500) NOT NULL DEFAULT '',
+			metadata JSONB NOT NULL DEFAULT '{}',
+			transfer_id VARCHAR(36),
+			original_transaction_id VARCHAR(36),
+			balance DECIMAL(15,2) NOT NULL DEFAULT 0,
+			overdrawn BOOLEAN NOT NULL DEFAULT FALSE,
+			original_currency VARCHAR(3),
+			original_amount DECIMAL(15,2),
+			fx_rate DECIMAL(20,10),
 			FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
 		)
 	`)
@@ -118,15 +179,602 @@ func (dm *DatabaseManager) InitSchema() error {
 		return fmt.Errorf("failed to create transactions table: %w", err)
 	}
 
+	// Columns for transactions tables created before they existed
+	transactionColumns := []string{
+		"ALTER TABLE transactions ADD COLUMN IF NOT EXISTS external_id VARCHAR(64)",
+		"ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tags VARCHAR(500) NOT NULL DEFAULT ''",
+		"ALTER TABLE transactions ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}'",
+		// Allow the statuses of transactions held for review by fraud scoring
+		"ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check",
+		"ALTER TABLE transactions ADD CONSTRAINT transactions_status_check CHECK (status IN ('PENDING', 'UNDER_REVIEW', 'COMPLETED', 'DECLINED', 'FAILED', 'CANCELLED', 'REVERSED'))",
+		// Transfers between accounts record a TRANSFER_OUT and a TRANSFER_IN transaction sharing a transfer_id
+		"ALTER TABLE transactions ADD COLUMN IF NOT EXISTS transfer_id VARCHAR(36)",
+		"ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_operation_type_check",
+		"ALTER TABLE transactions ADD CONSTRAINT transactions_operation_type_check CHECK (operation_type IN ('CASH_PURCHASE', 'INSTALLMENT_PURCHASE', 'WITHDRAWAL', 'PAYMENT', 'TRANSFER_OUT', 'TRANSFER_IN', 'REVERSAL'))",
+		// A reversal records a REVERSAL transaction pointing at the transaction it compensates
+		"ALTER TABLE transactions ADD COLUMN IF NOT EXISTS original_transaction_id VARCHAR(36)",
+		// Part of a purchase or withdrawal not yet discharged by payments, or of a payment left as credit.
+		// Transactions recorded before it existed start with nothing outstanding.
+		"ALTER TABLE transactions ADD COLUMN IF NOT EXISTS balance DECIMAL(15,2) NOT NULL DEFAULT 0",
+		// Debits that took the account balance below zero, into its overdraft
+		"ALTER TABLE transactions ADD COLUMN IF NOT EXISTS overdrawn BOOLEAN NOT NULL DEFAULT FALSE",
+		// Payments made in a currency other than the account's keep the amount paid and the rate applied
+		"ALTER TABLE transactions ADD COLUMN IF NOT EXISTS original_currency VARCHAR(3)",
+		"ALTER TABLE transactions ADD COLUMN IF NOT EXISTS original_amount DECIMAL(15,2)",
+		"ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fx_rate DECIMAL(20,10)",
+	}
+	for _, columnSQL := range transactionColumns {
+		if _, err := dm.db.Exec(columnSQL); err != nil {
+			return fmt.Errorf("failed to migrate transactions table: %w", err)
+		}
+	}
+
+	// Audit trail of edits to transaction descriptions, tags and metadata, with the values before and after
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_edits (
+			id VARCHAR(36) PRIMARY KEY,
+			transaction_id VARCHAR(36) NOT NULL,
+			edited_by VARCHAR(100) NOT NULL,
+			edited_at BIGINT NOT NULL,
+			previous JSONB NOT NULL,
+			updated JSONB NOT NULL,
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_edits table: %w", err)
+	}
+
+	// Audit trail of stuck PENDING transactions completed, failed or reversed by an operator
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_resolutions (
+			id VARCHAR(36) PRIMARY KEY,
+			transaction_id VARCHAR(36) NOT NULL,
+			action VARCHAR(10) NOT NULL CHECK (action IN ('COMPLETE', 'FAIL', 'REVERSE')),
+			previous_status VARCHAR(20) NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			reason TEXT NOT NULL,
+			resolved_by VARCHAR(100) NOT NULL,
+			resolved_at BIGINT NOT NULL,
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_resolutions table: %w", err)
+	}
+
+	// Risk scores of transactions held for review by fraud scoring, with the analyst's decision once made
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_reviews (
+			transaction_id VARCHAR(36) PRIMARY KEY,
+			risk_score INTEGER NOT NULL,
+			risk_factors VARCHAR(200) NOT NULL,
+			flagged_at BIGINT NOT NULL,
+			decision VARCHAR(10) CHECK (decision IN ('APPROVE', 'DECLINE')),
+			note TEXT NOT NULL DEFAULT '',
+			reviewed_by VARCHAR(100),
+			reviewed_at BIGINT,
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_reviews table: %w", err)
+	}
+
+	// Disputes opened against transactions, e.g. from imported network chargeback files; at most one per transaction
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS disputes (
+			id VARCHAR(36) PRIMARY KEY,
+			transaction_id VARCHAR(36) NOT NULL UNIQUE,
+			account_id VARCHAR(36) NOT NULL,
+			amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+			reason_code VARCHAR(20) NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'WON', 'LOST')),
+			source VARCHAR(20) NOT NULL,
+			opened_at BIGINT NOT NULL,
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+			FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create disputes table: %w", err)
+	}
+
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS tenant_settings (
+			tenant_id VARCHAR(64) NOT NULL,
+			environment VARCHAR(32) NOT NULL,
+			settings JSONB NOT NULL DEFAULT '{}',
+			updated_at BIGINT NOT NULL,
+			PRIMARY KEY (tenant_id, environment)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant_settings table: %w", err)
+	}
+
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS balance_adjustments (
+			id VARCHAR(36) PRIMARY KEY,
+			account_id VARCHAR(36) NOT NULL,
+			direction VARCHAR(10) NOT NULL CHECK (direction IN ('CREDIT', 'DEBIT')),
+			amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+			reason_code VARCHAR(50) NOT NULL,
+			description TEXT,
+			status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED')),
+			requested_by VARCHAR(100) NOT NULL,
+			requested_at BIGINT NOT NULL,
+			reviewed_by VARCHAR(100),
+			reviewed_at BIGINT,
+			review_note TEXT,
+			FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create balance_adjustments table: %w", err)
+	}
+
+	// Adjustments posted by internal services record the calling service and its reference
+	adjustmentColumns := []string{
+		"ALTER TABLE balance_adjustments ADD COLUMN IF NOT EXISTS source VARCHAR(100)",
+		"ALTER TABLE balance_adjustments ADD COLUMN IF NOT EXISTS reference VARCHAR(100)",
+	}
+	for _, columnSQL := range adjustmentColumns {
+		if _, err := dm.db.Exec(columnSQL); err != nil {
+			return fmt.Errorf("failed to migrate balance_adjustments table: %w", err)
+		}
+	}
+
+	// Document number changes; each row records the request, its verification by a second operator and when it was applied
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS document_changes (
+			id VARCHAR(36) PRIMARY KEY,
+			account_id VARCHAR(36) NOT NULL,
+			previous_document_number VARCHAR(20) NOT NULL,
+			document_number VARCHAR(20) NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'VERIFIED', 'REJECTED', 'APPLIED')),
+			reason TEXT,
+			requested_by VARCHAR(100) NOT NULL,
+			requested_at BIGINT NOT NULL,
+			verification_reference VARCHAR(100),
+			verified_by VARCHAR(100),
+			verified_at BIGINT,
+			verification_note TEXT,
+			applied_by VARCHAR(100),
+			applied_at BIGINT,
+			FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create document_changes table: %w", err)
+	}
+
+	// Audit trail of changes to account attributes, with the values before and after. Rows are kept when the
+	// account is deleted, so there is no foreign key to accounts.
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS account_audit (
+			id VARCHAR(36) PRIMARY KEY,
+			account_id VARCHAR(36) NOT NULL,
+			action VARCHAR(20) NOT NULL CHECK (action IN ('CREATE', 'UPDATE', 'DELETE', 'STATUS_CHANGE')),
+			actor VARCHAR(100) NOT NULL,
+			actor_role VARCHAR(50) NOT NULL,
+			changed_at BIGINT NOT NULL,
+			previous JSONB,
+			updated JSONB
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create account_audit table: %w", err)
+	}
+
+	// Copies of accounts and their most recent transactions taken before they are deleted or closed, so an
+	// operator can undo the operation. Like account_audit, rows outlive the account.
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS account_snapshots (
+			id VARCHAR(36) PRIMARY KEY,
+			account_id VARCHAR(36) NOT NULL,
+			operation VARCHAR(20) NOT NULL CHECK (operation IN ('DELETE', 'CLOSE')),
+			taken_by VARCHAR(100) NOT NULL,
+			taken_by_role VARCHAR(50) NOT NULL,
+			taken_at BIGINT NOT NULL,
+			account JSONB NOT NULL,
+			transactions JSONB NOT NULL,
+			restored_at BIGINT,
+			restored_by VARCHAR(100)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create account_snapshots table: %w", err)
+	}
+
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS operation_type_rules (
+			operation_type VARCHAR(50) PRIMARY KEY CHECK (operation_type IN ('CASH_PURCHASE', 'INSTALLMENT_PURCHASE', 'WITHDRAWAL', 'PAYMENT')),
+			direction VARCHAR(10) NOT NULL CHECK (direction IN ('CREDIT', 'DEBIT')),
+			requires_positive_amount BOOLEAN NOT NULL DEFAULT FALSE,
+			allowed_account_types VARCHAR(100) NOT NULL DEFAULT '',
+			fee_policy VARCHAR(20) NOT NULL DEFAULT 'NONE' CHECK (fee_policy IN ('NONE', 'TENANT_SCHEDULE')),
+			updated_at BIGINT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create operation_type_rules table: %w", err)
+	}
+
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS event_outbox (
+			id BIGSERIAL PRIMARY KEY,
+			event_id VARCHAR(36) NOT NULL UNIQUE,
+			event_type VARCHAR(64) NOT NULL,
+			partition_key VARCHAR(64) NOT NULL,
+			envelope BYTEA NOT NULL,
+			created_at BIGINT NOT NULL,
+			published_at BIGINT,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create event_outbox table: %w", err)
+	}
+
+	// Secrets outbox deliveries are signed with; every active key of a subscriber signs each delivery
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS webhook_signing_keys (
+			subscriber VARCHAR(64) NOT NULL,
+			key_id VARCHAR(20) NOT NULL,
+			secret VARCHAR(100) NOT NULL,
+			status VARCHAR(10) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'RETIRED')),
+			created_by VARCHAR(100) NOT NULL,
+			created_at BIGINT NOT NULL,
+			retired_by VARCHAR(100),
+			retired_at BIGINT,
+			PRIMARY KEY (subscriber, key_id)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook_signing_keys table: %w", err)
+	}
+
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS retention_reports (
+			id BIGSERIAL PRIMARY KEY,
+			tenant_id VARCHAR(64) NOT NULL,
+			policy VARCHAR(50) NOT NULL,
+			dry_run BOOLEAN NOT NULL,
+			affected BIGINT NOT NULL,
+			cutoff BIGINT NOT NULL,
+			run_at BIGINT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create retention_reports table: %w", err)
+	}
+
+	// Authorization decisions of both services, for access reviews
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS access_audit_log (
+			id BIGSERIAL PRIMARY KEY,
+			service VARCHAR(50) NOT NULL,
+			method VARCHAR(200) NOT NULL,
+			principal VARCHAR(100) NOT NULL,
+			role VARCHAR(50) NOT NULL,
+			tenant_id VARCHAR(64) NOT NULL,
+			request_id VARCHAR(64) NOT NULL,
+			decision VARCHAR(5) NOT NULL CHECK (decision IN ('ALLOW', 'DENY')),
+			reason VARCHAR(200) NOT NULL,
+			occurred_at BIGINT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create access_audit_log table: %w", err)
+	}
+
+	// Daily FX rates (quote_currency per unit of base_currency) and the month-end revaluation entries posted from them
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS fx_rates (
+			base_currency VARCHAR(3) NOT NULL,
+			quote_currency VARCHAR(3) NOT NULL,
+			rate_date BIGINT NOT NULL,
+			rate DECIMAL(20,10) NOT NULL CHECK (rate > 0),
+			PRIMARY KEY (base_currency, quote_currency, rate_date)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create fx_rates table: %w", err)
+	}
+
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS fx_revaluations (
+			id BIGSERIAL PRIMARY KEY,
+			tenant_id VARCHAR(64) NOT NULL,
+			account_id VARCHAR(36) NOT NULL,
+			period VARCHAR(7) NOT NULL,
+			currency VARCHAR(3) NOT NULL,
+			reporting_currency VARCHAR(3) NOT NULL,
+			balance DECIMAL(15,2) NOT NULL,
+			closing_rate DECIMAL(20,10) NOT NULL,
+			carrying_value DECIMAL(18,2) NOT NULL,
+			revalued_value DECIMAL(18,2) NOT NULL,
+			unrealized_gain DECIMAL(18,2) NOT NULL,
+			created_at BIGINT NOT NULL,
+			UNIQUE (account_id, period),
+			FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create fx_revaluations table: %w", err)
+	}
+
+	// Monthly spending budgets per transaction category; thresholds are comma-separated percentages of the limit
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS account_budgets (
+			account_id VARCHAR(36) NOT NULL,
+			category VARCHAR(32) NOT NULL,
+			monthly_limit DECIMAL(15,2) NOT NULL CHECK (monthly_limit > 0),
+			thresholds VARCHAR(100) NOT NULL,
+			created_at BIGINT NOT NULL,
+			updated_at BIGINT NOT NULL,
+			PRIMARY KEY (account_id, category),
+			FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create account_budgets table: %w", err)
+	}
+
+	// Batches group the transactions of a bulk import or settlement run. Accepted items are linked to their
+	// transaction; the latest failed attempt of each item is kept until the item is accepted
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_batches (
+			id VARCHAR(36) PRIMARY KEY,
+			source VARCHAR(100) NOT NULL,
+			expected_count INTEGER NOT NULL CHECK (expected_count > 0),
+			created_by VARCHAR(100),
+			created_at BIGINT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_batches table: %w", err)
+	}
+
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_batch_items (
+			batch_id VARCHAR(36) NOT NULL,
+			item INTEGER NOT NULL CHECK (item > 0),
+			transaction_id VARCHAR(36) NOT NULL UNIQUE,
+			PRIMARY KEY (batch_id, item),
+			FOREIGN KEY (batch_id) REFERENCES transaction_batches(id) ON DELETE CASCADE,
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_batch_items table: %w", err)
+	}
+
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_batch_failures (
+			batch_id VARCHAR(36) NOT NULL,
+			item INTEGER NOT NULL CHECK (item > 0),
+			account_id VARCHAR(36),
+			external_id VARCHAR(64),
+			error VARCHAR(200) NOT NULL,
+			failed_at BIGINT NOT NULL,
+			PRIMARY KEY (batch_id, item),
+			FOREIGN KEY (batch_id) REFERENCES transaction_batches(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_batch_failures table: %w", err)
+	}
+
+	// Monthly statements, generated once per account and calendar month; totals is an array of the count and
+	// total amount of each operation type
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS statements (
+			id VARCHAR(36) PRIMARY KEY,
+			account_id VARCHAR(36) NOT NULL,
+			period VARCHAR(7) NOT NULL,
+			period_start BIGINT NOT NULL,
+			period_end BIGINT NOT NULL,
+			opening_balance DECIMAL(18,2) NOT NULL,
+			closing_balance DECIMAL(18,2) NOT NULL,
+			adjustments DECIMAL(18,2) NOT NULL,
+			totals JSONB NOT NULL,
+			transaction_count BIGINT NOT NULL,
+			generated_at BIGINT NOT NULL,
+			UNIQUE (account_id, period),
+			FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create statements table: %w", err)
+	}
+
+	// Recurring rules make a transaction on an account on a schedule; next_run_at is the time of the next run
+	// while the rule is ACTIVE. Each run that made a transaction is linked to it, so a run is made at most once
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS recurring_rules (
+			id VARCHAR(36) PRIMARY KEY,
+			account_id VARCHAR(36) NOT NULL,
+			tenant_id VARCHAR(64),
+			operation_type VARCHAR(50) NOT NULL,
+			amount DECIMAL(15,2) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('DAILY', 'WEEKLY', 'MONTHLY')),
+			start_at BIGINT NOT NULL,
+			end_at BIGINT,
+			next_run_at BIGINT NOT NULL,
+			status VARCHAR(10) NOT NULL CHECK (status IN ('ACTIVE', 'CANCELLED', 'COMPLETED')),
+			run_count INTEGER NOT NULL DEFAULT 0,
+			skipped_count INTEGER NOT NULL DEFAULT 0,
+			last_run_at BIGINT,
+			last_transaction_id VARCHAR(36),
+			last_error VARCHAR(200),
+			created_by VARCHAR(100),
+			created_at BIGINT NOT NULL,
+			cancelled_at BIGINT,
+			FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create recurring_rules table: %w", err)
+	}
+
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS recurring_rule_runs (
+			rule_id VARCHAR(36) NOT NULL,
+			run INTEGER NOT NULL CHECK (run > 0),
+			scheduled_at BIGINT NOT NULL,
+			transaction_id VARCHAR(36) NOT NULL UNIQUE,
+			PRIMARY KEY (rule_id, run),
+			FOREIGN KEY (rule_id) REFERENCES recurring_rules(id) ON DELETE CASCADE,
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create recurring_rule_runs table: %w", err)
+	}
+
+	// The installments of an installment purchase after the first, which the purchase debits; each is debited by
+	// a transaction of its own when due. Declined installments are OVERDUE and retried from next_attempt_at
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS installments (
+			purchase_id VARCHAR(36) NOT NULL,
+			number INTEGER NOT NULL CHECK (number > 1),
+			amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+			due_at BIGINT NOT NULL,
+			status VARCHAR(10) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'IN_REVIEW', 'PAID', 'OVERDUE', 'CANCELLED')),
+			next_attempt_at BIGINT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error VARCHAR(200),
+			transaction_id VARCHAR(36) UNIQUE,
+			paid_at BIGINT,
+			tenant_id VARCHAR(64),
+			PRIMARY KEY (purchase_id, number),
+			FOREIGN KEY (purchase_id) REFERENCES transactions(id) ON DELETE CASCADE,
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create installments table: %w", err)
+	}
+
+	installmentColumns := []string{
+		// Installments are debited on behalf of the tenant the purchase was made for
+		"ALTER TABLE installments ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64)",
+		// Installments whose debit is held for review are IN_REVIEW until it is approved or declined
+		"ALTER TABLE installments DROP CONSTRAINT IF EXISTS installments_status_check",
+		"ALTER TABLE installments ADD CONSTRAINT installments_status_check CHECK (status IN ('PENDING', 'IN_REVIEW', 'PAID', 'OVERDUE', 'CANCELLED'))",
+	}
+	for _, columnSQL := range installmentColumns {
+		if _, err := dm.db.Exec(columnSQL); err != nil {
+			return fmt.Errorf("failed to migrate installments table: %w", err)
+		}
+	}
+
+	// Per-tenant overrides of the platform message templates of the runtime config
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS message_templates (
+			tenant_id VARCHAR(64) NOT NULL,
+			event_type VARCHAR(64) NOT NULL,
+			channel VARCHAR(10) NOT NULL CHECK (channel IN ('EMAIL', 'WEBHOOK')),
+			subject VARCHAR(200) NOT NULL DEFAULT '',
+			body TEXT NOT NULL,
+			updated_by VARCHAR(100) NOT NULL,
+			updated_at BIGINT NOT NULL,
+			PRIMARY KEY (tenant_id, event_type, channel)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create message_templates table: %w", err)
+	}
+
+	// What each payment discharged from each purchase or withdrawal, so reversing the payment can reopen them
+	_, err = dm.db.Exec(`
+		CREATE TABLE IF NOT EXISTS payment_discharges (
+			payment_id VARCHAR(36) NOT NULL,
+			debt_id VARCHAR(36) NOT NULL,
+			amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+			PRIMARY KEY (payment_id, debt_id),
+			FOREIGN KEY (payment_id) REFERENCES transactions(id) ON DELETE CASCADE,
+			FOREIGN KEY (debt_id) REFERENCES transactions(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create payment_discharges table: %w", err)
+	}
+
+	// Default rules: payments credit the account and must be positive, every other operation type debits it.
+	// Existing rows are left alone so rules edited by an admin survive restarts.
+	_, err = dm.db.Exec(`
+		INSERT INTO operation_type_rules (operation_type, direction, requires_positive_amount, updated_at)
+		VALUES
+			('CASH_PURCHASE', 'DEBIT', FALSE, $1),
+			('INSTALLMENT_PURCHASE', 'DEBIT', FALSE, $1),
+			('WITHDRAWAL', 'DEBIT', FALSE, $1),
+			('PAYMENT', 'CREDIT', TRUE, $1)
+		ON CONFLICT (operation_type) DO NOTHING
+	`, GetCurrentTimestamp())
+	if err != nil {
+		return fmt.Errorf("failed to seed operation_type_rules table: %w", err)
+	}
+
+	// Accounts created before opening_balance existed get the opening balance that reconciles their
+	// balance column with their ledger, so ledger-derived balances match the column from the start
+	_, err = dm.db.Exec(`
+		UPDATE accounts a
+		SET opening_balance = a.balance
+			- COALESCE((SELECT SUM(t.amount) FROM transactions t WHERE t.account_id = a.id AND t.status IN ('COMPLETED', 'REVERSED')), 0)
+			- COALESCE((SELECT SUM(CASE WHEN b.direction = 'CREDIT' THEN b.amount ELSE -b.amount END)
+				FROM balance_adjustments b WHERE b.account_id = a.id AND b.status = 'APPROVED'), 0)
+		WHERE a.opening_balance IS NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to backfill account opening balances: %w", err)
+	}
+
 	indexes := []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
 		"CREATE INDEX IF NOT EXISTS idx_accounts_document_number ON accounts(document_number)",
+		"CREATE INDEX IF NOT EXISTS idx_accounts_document_number_trgm ON accounts USING GIN (document_number gin_trgm_ops)",
 		"CREATE INDEX IF NOT EXISTS idx_accounts_account_type ON accounts(account_type)",
 		"CREATE INDEX IF NOT EXISTS idx_accounts_created_at ON accounts(created_at)",
+		"CREATE INDEX IF NOT EXISTS idx_accounts_balance ON accounts(balance)",
+		"CREATE INDEX IF NOT EXISTS idx_accounts_onboarding ON accounts(status) WHERE status <> 'ACTIVE'",
 		"CREATE INDEX IF NOT EXISTS idx_transactions_account_id ON transactions(account_id)",
 		"CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions(created_at DESC)",
 		"CREATE INDEX IF NOT EXISTS idx_transactions_account_created ON transactions(account_id, created_at DESC)",
 		"CREATE INDEX IF NOT EXISTS idx_transactions_operation_type ON transactions(operation_type)",
 		"CREATE INDEX IF NOT EXISTS idx_transactions_status ON transactions(status)",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_external_id ON transactions(external_id) WHERE external_id IS NOT NULL",
+		"CREATE INDEX IF NOT EXISTS idx_transactions_description_trgm ON transactions USING GIN (description gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_transactions_transfer_id ON transactions(transfer_id) WHERE transfer_id IS NOT NULL",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_original_transaction_id ON transactions(original_transaction_id) WHERE original_transaction_id IS NOT NULL",
+		"CREATE INDEX IF NOT EXISTS idx_transactions_outstanding ON transactions(account_id, created_at) WHERE balance < 0",
+		"CREATE INDEX IF NOT EXISTS idx_disputes_account ON disputes(account_id, opened_at DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_transaction_edits_transaction ON transaction_edits(transaction_id, edited_at)",
+		"CREATE INDEX IF NOT EXISTS idx_transaction_resolutions_transaction ON transaction_resolutions(transaction_id, resolved_at)",
+		"CREATE INDEX IF NOT EXISTS idx_transactions_pending ON transactions(created_at) WHERE status = 'PENDING'",
+		"CREATE INDEX IF NOT EXISTS idx_transaction_reviews_open ON transaction_reviews(flagged_at) WHERE decision IS NULL",
+		"CREATE INDEX IF NOT EXISTS idx_balance_adjustments_account ON balance_adjustments(account_id, requested_at DESC)",
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_balance_adjustments_reference ON balance_adjustments(source, reference) WHERE reference IS NOT NULL",
+		"CREATE INDEX IF NOT EXISTS idx_document_changes_account ON document_changes(account_id, requested_at DESC)",
+		// At most one change per account may be in progress
+		"CREATE UNIQUE INDEX IF NOT EXISTS idx_document_changes_open ON document_changes(account_id) WHERE status IN ('PENDING', 'VERIFIED')",
+		"CREATE INDEX IF NOT EXISTS idx_account_audit_account ON account_audit(account_id, changed_at DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_account_snapshots_account ON account_snapshots(account_id, taken_at DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_event_outbox_unpublished ON event_outbox(id) WHERE published_at IS NULL",
+		"CREATE INDEX IF NOT EXISTS idx_event_outbox_partition ON event_outbox(partition_key, created_at DESC, id DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_accounts_tenant ON accounts(tenant_id) WHERE tenant_id IS NOT NULL",
+		"CREATE INDEX IF NOT EXISTS idx_retention_reports_tenant ON retention_reports(tenant_id, run_at DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_access_audit_log_principal ON access_audit_log(principal, id DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_access_audit_log_occurred_at ON access_audit_log(occurred_at)",
+		"CREATE INDEX IF NOT EXISTS idx_fx_revaluations_tenant_period ON fx_revaluations(tenant_id, period)",
+		"CREATE INDEX IF NOT EXISTS idx_transactions_category ON transactions(account_id, (metadata->>'category'), created_at) WHERE metadata ? 'category'",
+		"CREATE INDEX IF NOT EXISTS idx_recurring_rules_account ON recurring_rules(account_id, created_at DESC)",
+		"CREATE INDEX IF NOT EXISTS idx_recurring_rules_due ON recurring_rules(next_run_at) WHERE status = 'ACTIVE'",
+		"CREATE INDEX IF NOT EXISTS idx_installments_due ON installments(next_attempt_at) WHERE status IN ('PENDING', 'OVERDUE')",
 	}
 
 	for _, indexSQL := range indexes {
@@ -135,7 +783,25 @@ func (dm *DatabaseManager) InitSchema() error {
 		}
 	}
 
-	return nil
+	if err := dm.initRollups(); err != nil {
+		return err
+	}
+	if err := dm.initMonthlyCounts(); err != nil {
+		return err
+	}
+	if err := dm.initBusinessDays(); err != nil {
+		return err
+	}
+	return dm.migrateTenantSettingsAmounts()
+}
+
+// uniqueViolation is the SQLSTATE PostgreSQL reports for a row violating a unique constraint.
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err is PostgreSQL rejecting a row that violates a unique constraint.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
 }
 
 // getEnv retrieves an environment variable value or returns a default value.
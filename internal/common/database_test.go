@@ -1,9 +1,12 @@
 package common
 
 import (
+	"database/sql"
+	"fmt"
 	"os"
 	"testing"
 
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -301,3 +304,12 @@ func TestDatabaseConfig_PortValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestIsUniqueViolation(t *testing.T) {
+	duplicate := &pq.Error{Code: "23505", Constraint: "accounts_document_number_key"}
+	assert.True(t, IsUniqueViolation(duplicate))
+	assert.True(t, IsUniqueViolation(fmt.Errorf("insert failed: %w", duplicate)))
+	assert.False(t, IsUniqueViolation(&pq.Error{Code: "23503"}))
+	assert.False(t, IsUniqueViolation(sql.ErrConnDone))
+	assert.False(t, IsUniqueViolation(nil))
+}
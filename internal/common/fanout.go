@@ -0,0 +1,105 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// FailurePolicy decides how a WorkerGroup reacts to a failing task.
+type FailurePolicy int
+
+const (
+	// FailFast cancels the remaining tasks on the first error, which Wait returns.
+	FailFast FailurePolicy = iota
+	// CollectErrors lets every task run; Wait returns the errors of all failed tasks joined together,
+	// so callers can use the results of the tasks that succeeded.
+	CollectErrors
+)
+
+// WorkerGroup runs tasks concurrently, at most limit at a time, under a shared context.
+// It replaces ad-hoc goroutines wherever independent work is fanned out.
+type WorkerGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	policy FailurePolicy
+	slots  chan struct{}
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewWorkerGroup creates a group running at most limit tasks at a time; a limit below one means one.
+// The returned context is passed to the tasks and is cancelled when ctx is, when a task fails under
+// FailFast, or when Wait returns.
+func NewWorkerGroup(ctx context.Context, limit int, policy FailurePolicy) (*WorkerGroup, context.Context) {
+	if limit < 1 {
+		limit = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &WorkerGroup{
+		ctx:    ctx,
+		cancel: cancel,
+		policy: policy,
+		slots:  make(chan struct{}, limit),
+	}, ctx
+}
+
+// Go runs task in a new goroutine once a slot is free, blocking until then.
+// It returns false without running task if the group's context is done first.
+func (g *WorkerGroup) Go(task func(ctx context.Context) error) bool {
+	select {
+	case g.slots <- struct{}{}:
+	case <-g.ctx.Done():
+		return false
+	}
+	if g.ctx.Err() != nil {
+		<-g.slots
+		return false
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			<-g.slots
+			g.wg.Done()
+		}()
+		if err := task(g.ctx); err != nil {
+			g.fail(err)
+		}
+	}()
+	return true
+}
+
+// Wait waits for the started tasks to finish and returns their error, according to the failure policy.
+// If the parent context was cancelled before every task could start, its error is included.
+func (g *WorkerGroup) Wait() error {
+	g.wg.Wait()
+	ctxErr := g.ctx.Err()
+	g.cancel()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.policy == FailFast {
+		if len(g.errs) > 0 {
+			return g.errs[0]
+		}
+		return ctxErr
+	}
+	if len(g.errs) == 0 {
+		return ctxErr
+	}
+	return errors.Join(g.errs...)
+}
+
+// fail records the error of a task, cancelling the others under FailFast.
+func (g *WorkerGroup) fail(err error) {
+	g.mu.Lock()
+	g.errs = append(g.errs, err)
+	g.mu.Unlock()
+
+	if g.policy == FailFast {
+		g.cancel()
+	}
+}
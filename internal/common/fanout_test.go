@@ -0,0 +1,98 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerGroup_BoundsConcurrency(t *testing.T) {
+	group, _ := NewWorkerGroup(context.Background(), 3, FailFast)
+
+	var running, peak, done int32
+	for i := 0; i < 20; i++ {
+		require.True(t, group.Go(func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&done, 1)
+			return nil
+		}))
+	}
+
+	assert.NoError(t, group.Wait())
+	assert.Equal(t, int32(20), done)
+	assert.LessOrEqual(t, peak, int32(3))
+}
+
+func TestWorkerGroup_FailFastCancelsRemainingTasks(t *testing.T) {
+	group, ctx := NewWorkerGroup(context.Background(), 2, FailFast)
+	failure := errors.New("shard failed")
+
+	started := make(chan struct{})
+	group.Go(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	group.Go(func(ctx context.Context) error { return failure })
+
+	// Once the context is cancelled no further task is started
+	<-ctx.Done()
+	assert.False(t, group.Go(func(ctx context.Context) error {
+		t.Error("task started after failure")
+		return nil
+	}))
+
+	assert.Equal(t, failure, group.Wait())
+}
+
+func TestWorkerGroup_CollectErrorsRunsEveryTask(t *testing.T) {
+	group, _ := NewWorkerGroup(context.Background(), 2, CollectErrors)
+	first := errors.New("balances unavailable")
+	second := errors.New("history unavailable")
+
+	var succeeded int32
+	group.Go(func(ctx context.Context) error { return first })
+	group.Go(func(ctx context.Context) error { return second })
+	for i := 0; i < 3; i++ {
+		group.Go(func(ctx context.Context) error {
+			if ctx.Err() == nil {
+				atomic.AddInt32(&succeeded, 1)
+			}
+			return nil
+		})
+	}
+
+	err := group.Wait()
+	assert.ErrorIs(t, err, first)
+	assert.ErrorIs(t, err, second)
+	assert.Equal(t, int32(3), succeeded)
+}
+
+func TestWorkerGroup_ParentCancellation(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	group, ctx := NewWorkerGroup(parent, 1, CollectErrors)
+
+	group.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	cancel()
+	<-ctx.Done()
+
+	assert.False(t, group.Go(func(ctx context.Context) error { return nil }))
+	assert.ErrorIs(t, group.Wait(), context.Canceled)
+}
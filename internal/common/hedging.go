@@ -0,0 +1,229 @@
+package common
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Defaults for hedged requests.
+const (
+	DefaultHedgingPercentile = 95
+	DefaultHedgingMinDelay   = 10 * time.Millisecond
+	DefaultHedgingMaxDelay   = 500 * time.Millisecond
+	DefaultHedgingBudget     = 10
+	// hedgingWindowSize is how many recent latencies of a method the hedge delay is computed from,
+	// and hedgingMinSamples how many are needed before the percentile is trusted over MaxDelay.
+	hedgingWindowSize = 200
+	hedgingMinSamples = 20
+	// hedgingMaxTokens caps the hedges saved up while calls are fast, so a slow spell after a quiet one
+	// cannot hedge every call.
+	hedgingMaxTokens = 10
+)
+
+// HedgingConfig configures hedged requests. When Enabled, a call to one of Methods that has not completed
+// within the Percentile-th percentile of the method's recent latencies, clamped to [MinDelay, MaxDelay], is
+// sent a second time and the first successful response is used. Budget is the percentage of calls that may
+// be hedged, which bounds the extra load on the backend when it slows down as a whole.
+//
+// Only idempotent reads may be hedged, since both attempts can reach the backend.
+type HedgingConfig struct {
+	Enabled    bool
+	Percentile float64
+	MinDelay   time.Duration
+	MaxDelay   time.Duration
+	Budget     float64
+	Methods    []string
+}
+
+// NewHedgingConfigFromEnv reads GRPC_HEDGING, GRPC_HEDGING_PERCENTILE, GRPC_HEDGING_MIN_DELAY,
+// GRPC_HEDGING_MAX_DELAY and GRPC_HEDGING_BUDGET for the given methods. Hedging is disabled unless
+// GRPC_HEDGING is "true". Invalid values fall back to the defaults.
+func NewHedgingConfigFromEnv(methods ...string) HedgingConfig {
+	config := HedgingConfig{
+		Enabled:    getEnv("GRPC_HEDGING", "") == "true",
+		Percentile: DefaultHedgingPercentile,
+		MinDelay:   DefaultHedgingMinDelay,
+		MaxDelay:   DefaultHedgingMaxDelay,
+		Budget:     DefaultHedgingBudget,
+		Methods:    methods,
+	}
+	if percentile, err := strconv.ParseFloat(getEnv("GRPC_HEDGING_PERCENTILE", ""), 64); err == nil && percentile > 0 && percentile <= 100 {
+		config.Percentile = percentile
+	}
+	if delay, err := time.ParseDuration(getEnv("GRPC_HEDGING_MIN_DELAY", "")); err == nil && delay >= 0 {
+		config.MinDelay = delay
+	}
+	if delay, err := time.ParseDuration(getEnv("GRPC_HEDGING_MAX_DELAY", "")); err == nil && delay > 0 {
+		config.MaxDelay = delay
+	}
+	if config.MaxDelay < config.MinDelay {
+		config.MaxDelay = config.MinDelay
+	}
+	if budget, err := strconv.ParseFloat(getEnv("GRPC_HEDGING_BUDGET", ""), 64); err == nil && budget >= 0 && budget <= 100 {
+		config.Budget = budget
+	}
+	return config
+}
+
+// latencyWindow holds the most recent latencies of one method.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// observe records a latency, replacing the oldest one once the window is full.
+func (w *latencyWindow) observe(latency time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.samples) < hedgingWindowSize {
+		w.samples = append(w.samples, latency)
+		return
+	}
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % hedgingWindowSize
+}
+
+// percentile returns the p-th percentile of the recorded latencies, or false while there are too few of them.
+func (w *latencyWindow) percentile(p float64) (time.Duration, bool) {
+	w.mu.Lock()
+	sorted := append([]time.Duration(nil), w.samples...)
+	w.mu.Unlock()
+
+	if len(sorted) < hedgingMinSamples {
+		return 0, false
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(p/100*float64(len(sorted))+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index], true
+}
+
+// hedger tracks the latencies of the hedged methods and the hedges the budget allows.
+type hedger struct {
+	config  HedgingConfig
+	windows map[string]*latencyWindow
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+func newHedger(config HedgingConfig) *hedger {
+	windows := make(map[string]*latencyWindow, len(config.Methods))
+	for _, method := range config.Methods {
+		windows[method] = &latencyWindow{}
+	}
+	return &hedger{config: config, windows: windows}
+}
+
+// delay returns how long a call waits for its first attempt before hedging.
+func (h *hedger) delay(window *latencyWindow) time.Duration {
+	delay, ok := window.percentile(h.config.Percentile)
+	if !ok || delay > h.config.MaxDelay {
+		return h.config.MaxDelay
+	}
+	if delay < h.config.MinDelay {
+		return h.config.MinDelay
+	}
+	return delay
+}
+
+// deposit credits the budget for one call.
+func (h *hedger) deposit() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tokens += h.config.Budget / 100
+	if h.tokens > hedgingMaxTokens {
+		h.tokens = hedgingMaxTokens
+	}
+}
+
+// withdraw reports whether the budget allows a hedge, and charges it if so.
+func (h *hedger) withdraw() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+// attemptResult is the outcome of one attempt of a hedged call.
+type attemptResult struct {
+	reply   proto.Message
+	err     error
+	latency time.Duration
+}
+
+// invoke runs a call with a hedge. Each attempt decodes into its own message, so the loser can still be
+// writing after the call returns; the winner is copied into reply.
+func (h *hedger) invoke(ctx context.Context, window *latencyWindow, method string, req interface{}, reply proto.Message, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	h.deposit()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan attemptResult, 2)
+	attempt := func() {
+		start := time.Now()
+		attemptReply := reply.ProtoReflect().New().Interface()
+		err := invoker(ctx, method, req, attemptReply, cc, opts...)
+		results <- attemptResult{reply: attemptReply, err: err, latency: time.Since(start)}
+	}
+
+	go attempt()
+	pending := 1
+	timer := time.NewTimer(h.delay(window))
+	defer timer.Stop()
+	hedge := timer.C
+
+	for {
+		select {
+		case <-hedge:
+			hedge = nil
+			if h.withdraw() {
+				go attempt()
+				pending++
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				window.observe(result.latency)
+				proto.Reset(reply)
+				proto.Merge(reply, result.reply)
+				return nil
+			}
+			// Hedging is not a retry: a call whose only attempt fails before the hedge delay fails
+			if pending == 0 {
+				return result.err
+			}
+		}
+	}
+}
+
+// HedgingUnaryClientInterceptor returns a client interceptor that hedges the calls to the configured
+// methods, cutting the tail latency caused by a slow backend instance or connection.
+// It should run after the request ID interceptor, so both attempts carry the same request ID.
+func HedgingUnaryClientInterceptor(config HedgingConfig) grpc.UnaryClientInterceptor {
+	h := newHedger(config)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		window, ok := h.windows[method]
+		message, isProto := reply.(proto.Message)
+		if !config.Enabled || !ok || !isProto {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return h.invoke(ctx, window, method, req, message, cc, invoker, opts...)
+	}
+}
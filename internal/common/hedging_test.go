@@ -0,0 +1,148 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const hedgedMethod = "/test.Service/Get"
+
+func TestNewHedgingConfigFromEnv(t *testing.T) {
+	config := NewHedgingConfigFromEnv(hedgedMethod)
+	assert.False(t, config.Enabled)
+	assert.Equal(t, float64(DefaultHedgingPercentile), config.Percentile)
+	assert.Equal(t, DefaultHedgingMaxDelay, config.MaxDelay)
+	assert.Equal(t, []string{hedgedMethod}, config.Methods)
+
+	os.Setenv("GRPC_HEDGING", "true")
+	os.Setenv("GRPC_HEDGING_PERCENTILE", "99")
+	os.Setenv("GRPC_HEDGING_MIN_DELAY", "50ms")
+	os.Setenv("GRPC_HEDGING_MAX_DELAY", "20ms")
+	os.Setenv("GRPC_HEDGING_BUDGET", "250")
+	defer func() {
+		for _, name := range []string{"GRPC_HEDGING", "GRPC_HEDGING_PERCENTILE", "GRPC_HEDGING_MIN_DELAY", "GRPC_HEDGING_MAX_DELAY", "GRPC_HEDGING_BUDGET"} {
+			os.Unsetenv(name)
+		}
+	}()
+
+	config = NewHedgingConfigFromEnv()
+	assert.True(t, config.Enabled)
+	assert.Equal(t, float64(99), config.Percentile)
+	assert.Equal(t, 50*time.Millisecond, config.MinDelay)
+	assert.Equal(t, 50*time.Millisecond, config.MaxDelay)
+	assert.Equal(t, float64(DefaultHedgingBudget), config.Budget)
+}
+
+func TestLatencyWindow_Percentile(t *testing.T) {
+	window := &latencyWindow{}
+	for i := 1; i < hedgingMinSamples; i++ {
+		window.observe(time.Duration(i) * time.Millisecond)
+	}
+	_, ok := window.percentile(95)
+	assert.False(t, ok)
+
+	for i := hedgingMinSamples; i <= hedgingWindowSize+100; i++ {
+		window.observe(time.Duration(i) * time.Millisecond)
+	}
+	// Only the latest hedgingWindowSize latencies, 101ms to 300ms, are kept
+	p95, ok := window.percentile(95)
+	require.True(t, ok)
+	assert.Equal(t, 290*time.Millisecond, p95)
+	p0, _ := window.percentile(0)
+	assert.Equal(t, 101*time.Millisecond, p0)
+}
+
+// slowFirstInvoker answers every attempt but the first immediately with its attempt number; the first
+// attempt takes firstDelay, or fails with firstErr.
+func slowFirstInvoker(attempts *int32, firstDelay time.Duration, firstErr error) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		attempt := atomic.AddInt32(attempts, 1)
+		if attempt == 1 {
+			if firstErr != nil {
+				return firstErr
+			}
+			select {
+			case <-time.After(firstDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		reply.(*wrapperspb.StringValue).Value = map[int32]string{1: "first", 2: "hedge"}[attempt]
+		return nil
+	}
+}
+
+func TestHedgingUnaryClientInterceptor(t *testing.T) {
+	config := HedgingConfig{Enabled: true, Percentile: 95, MinDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond, Budget: 100, Methods: []string{hedgedMethod}}
+	failure := errors.New("not found")
+
+	tests := []struct {
+		name             string
+		config           HedgingConfig
+		method           string
+		firstDelay       time.Duration
+		firstErr         error
+		expectedReply    string
+		expectedErr      error
+		expectedAttempts int32
+	}{
+		{name: "fast call is not hedged", config: config, method: hedgedMethod, expectedReply: "first", expectedAttempts: 1},
+		{name: "slow call is hedged", config: config, method: hedgedMethod, firstDelay: time.Second, expectedReply: "hedge", expectedAttempts: 2},
+		{name: "failed call is not hedged", config: config, method: hedgedMethod, firstErr: failure, expectedErr: failure, expectedAttempts: 1},
+		{name: "other methods are not hedged", config: config, method: "/test.Service/Update", firstDelay: 50 * time.Millisecond, expectedReply: "first", expectedAttempts: 1},
+		{
+			name:   "exhausted budget",
+			config: HedgingConfig{Enabled: true, Percentile: 95, MaxDelay: 10 * time.Millisecond, Budget: 0, Methods: []string{hedgedMethod}},
+			method: hedgedMethod, firstDelay: 50 * time.Millisecond, expectedReply: "first", expectedAttempts: 1,
+		},
+		{
+			name:   "disabled",
+			config: HedgingConfig{MaxDelay: 10 * time.Millisecond, Budget: 100, Methods: []string{hedgedMethod}},
+			method: hedgedMethod, firstDelay: 50 * time.Millisecond, expectedReply: "first", expectedAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			reply := &wrapperspb.StringValue{}
+			interceptor := HedgingUnaryClientInterceptor(tt.config)
+
+			err := interceptor(context.Background(), tt.method, wrapperspb.String("req"), reply, nil, slowFirstInvoker(&attempts, tt.firstDelay, tt.firstErr))
+
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedReply, reply.Value)
+			assert.Equal(t, tt.expectedAttempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestHedgingUnaryClientInterceptor_WaitsForHedgeWhenFirstAttemptFails(t *testing.T) {
+	config := HedgingConfig{Enabled: true, Percentile: 95, MaxDelay: 10 * time.Millisecond, Budget: 100, Methods: []string{hedgedMethod}}
+	var attempts int32
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			time.Sleep(20 * time.Millisecond)
+			return errors.New("unavailable")
+		}
+		time.Sleep(30 * time.Millisecond)
+		reply.(*wrapperspb.StringValue).Value = "hedge"
+		return nil
+	}
+
+	reply := &wrapperspb.StringValue{}
+	err := HedgingUnaryClientInterceptor(config)(context.Background(), hedgedMethod, wrapperspb.String("req"), reply, nil, invoker)
+
+	require.NoError(t, err)
+	assert.Equal(t, "hedge", reply.Value)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
@@ -0,0 +1,141 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// The transaction_monthly_counts table holds the number of transactions of each account per UTC month,
+// whatever their status. A trigger keeps it in step with every insert and delete on transactions, so history
+// pagination and exports of accounts with a deep history can count transactions and find the month holding
+// a given position without scanning them. Transactions never change account or creation time, so updates
+// leave it untouched.
+const (
+	createMonthlyCountsTableSQL = `
+		CREATE TABLE IF NOT EXISTS transaction_monthly_counts (
+			account_id VARCHAR(36) NOT NULL,
+			month_start BIGINT NOT NULL,
+			txn_count BIGINT NOT NULL DEFAULT 0,
+			PRIMARY KEY (account_id, month_start),
+			FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+		)`
+
+	createMonthlyCountsFunctionSQL = `
+		CREATE OR REPLACE FUNCTION count_transaction_month() RETURNS TRIGGER AS $$
+		BEGIN
+			IF TG_OP = 'DELETE' THEN
+				UPDATE transaction_monthly_counts
+				SET txn_count = txn_count - 1
+				WHERE account_id = OLD.account_id
+					AND month_start = EXTRACT(EPOCH FROM date_trunc('month', to_timestamp(OLD.created_at) AT TIME ZONE 'UTC'))::BIGINT;
+			ELSE
+				INSERT INTO transaction_monthly_counts (account_id, month_start, txn_count)
+				VALUES (NEW.account_id, EXTRACT(EPOCH FROM date_trunc('month', to_timestamp(NEW.created_at) AT TIME ZONE 'UTC'))::BIGINT, 1)
+				ON CONFLICT (account_id, month_start) DO UPDATE
+				SET txn_count = transaction_monthly_counts.txn_count + 1;
+			END IF;
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql`
+
+	createMonthlyCountsTriggerSQL = `
+		CREATE TRIGGER transactions_monthly_count
+		AFTER INSERT OR DELETE ON transactions
+		FOR EACH ROW EXECUTE FUNCTION count_transaction_month()`
+
+	backfillMonthlyCountsSQL = `
+		INSERT INTO transaction_monthly_counts (account_id, month_start, txn_count)
+		SELECT account_id, EXTRACT(EPOCH FROM date_trunc('month', to_timestamp(created_at) AT TIME ZONE 'UTC'))::BIGINT, COUNT(*)
+		FROM transactions
+		GROUP BY 1, 2`
+)
+
+// initMonthlyCounts creates the monthly count table and the trigger that maintains it. Like initRollups, the
+// first installation backfills the table in the same database transaction, with writes to transactions blocked.
+func (dm *DatabaseManager) initMonthlyCounts() error {
+	if _, err := dm.db.Exec(createMonthlyCountsTableSQL); err != nil {
+		return fmt.Errorf("failed to create monthly count table: %w", err)
+	}
+
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin monthly count initialization: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("LOCK TABLE transactions IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("failed to lock transactions table: %w", err)
+	}
+	if _, err := tx.Exec(createMonthlyCountsFunctionSQL); err != nil {
+		return fmt.Errorf("failed to create monthly count function: %w", err)
+	}
+
+	var installed bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'transactions_monthly_count')").Scan(&installed); err != nil {
+		return fmt.Errorf("failed to check monthly count trigger: %w", err)
+	}
+	if !installed {
+		if _, err := tx.Exec(createMonthlyCountsTriggerSQL); err != nil {
+			return fmt.Errorf("failed to create monthly count trigger: %w", err)
+		}
+		if _, err := tx.Exec(backfillMonthlyCountsSQL); err != nil {
+			return fmt.Errorf("failed to backfill monthly counts: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit monthly count initialization: %w", err)
+	}
+	return nil
+}
+
+// MonthCount is the number of transactions of an account created in one UTC month.
+type MonthCount struct {
+	// Start is the first second of the month, in Unix seconds
+	Start int64
+	Count int64
+}
+
+// End returns the first second of the following month, in Unix seconds.
+func (m MonthCount) End() int64 {
+	return time.Unix(m.Start, 0).UTC().AddDate(0, 1, 0).Unix()
+}
+
+// LoadMonthCounts returns the months in which an account has transactions, oldest first.
+func LoadMonthCounts(ctx context.Context, db *sql.DB, accountID string) ([]MonthCount, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT month_start, txn_count FROM transaction_monthly_counts
+		WHERE account_id = $1 AND txn_count > 0
+		ORDER BY month_start
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var months []MonthCount
+	for rows.Next() {
+		var month MonthCount
+		if err := rows.Scan(&month.Start, &month.Count); err != nil {
+			return nil, err
+		}
+		months = append(months, month)
+	}
+	return months, rows.Err()
+}
+
+// LocateHistoryOffset finds the position offset in a history ordered newest first, given the account's month
+// counts oldest first. It returns the end of the month holding that position and the offset within the
+// transactions created before that end, so a page can be read from there instead of skipping offset rows.
+// ok is false when offset is past the end of the history.
+func LocateHistoryOffset(months []MonthCount, offset int64) (before int64, remaining int64, ok bool) {
+	for i := len(months) - 1; i >= 0; i-- {
+		if offset < months[i].Count {
+			return months[i].End(), offset, true
+		}
+		offset -= months[i].Count
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseManager_initMonthlyCounts(t *testing.T) {
+	tests := []struct {
+		name           string
+		installed      bool
+		expectBackfill bool
+	}{
+		{name: "first run installs the trigger and backfills", installed: false, expectBackfill: true},
+		{name: "later runs only refresh the function", installed: true, expectBackfill: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectExec(`CREATE TABLE IF NOT EXISTS transaction_monthly_counts`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectBegin()
+			mock.ExpectExec(`LOCK TABLE transactions`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`CREATE OR REPLACE FUNCTION count_transaction_month`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM pg_trigger WHERE tgname = 'transactions_monthly_count'\)`).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.installed))
+			if tt.expectBackfill {
+				mock.ExpectExec(`CREATE TRIGGER transactions_monthly_count`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO transaction_monthly_counts`).WillReturnResult(sqlmock.NewResult(0, 3))
+			}
+			mock.ExpectCommit()
+
+			dm := &DatabaseManager{db: db}
+			require.NoError(t, dm.initMonthlyCounts())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDatabaseManager_initMonthlyCounts_RollsBackOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS transaction_monthly_counts`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`LOCK TABLE transactions`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE OR REPLACE FUNCTION count_transaction_month`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM pg_trigger`).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(`CREATE TRIGGER transactions_monthly_count`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO transaction_monthly_counts`).WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	dm := &DatabaseManager{db: db}
+	err = dm.initMonthlyCounts()
+	assert.ErrorContains(t, err, "failed to backfill monthly counts")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMonthCount_End(t *testing.T) {
+	// January 2024 has 31 days and February 2024, a leap year, 29
+	assert.Equal(t, int64(1706745600), MonthCount{Start: 1704067200}.End())
+	assert.Equal(t, int64(1709251200), MonthCount{Start: 1706745600}.End())
+	// December rolls over into the next year
+	assert.Equal(t, int64(1735689600), MonthCount{Start: 1733011200}.End())
+}
+
+func TestLocateHistoryOffset(t *testing.T) {
+	months := []MonthCount{{Start: 1704067200, Count: 3}, {Start: 1706745600, Count: 2}}
+
+	tests := []struct {
+		name              string
+		offset            int64
+		expectedBefore    int64
+		expectedRemaining int64
+		expectedOK        bool
+	}{
+		{name: "newest month", offset: 1, expectedBefore: 1709251200, expectedRemaining: 1, expectedOK: true},
+		{name: "first position of an older month", offset: 2, expectedBefore: 1706745600, expectedRemaining: 0, expectedOK: true},
+		{name: "last position", offset: 4, expectedBefore: 1706745600, expectedRemaining: 2, expectedOK: true},
+		{name: "past the end", offset: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, remaining, ok := LocateHistoryOffset(months, tt.offset)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedBefore, before)
+			assert.Equal(t, tt.expectedRemaining, remaining)
+		})
+	}
+}
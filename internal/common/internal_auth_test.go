@@ -0,0 +1,126 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseServiceTokens(t *testing.T) {
+	tokens, err := ParseServiceTokens(" interest-worker=0123456789abcdef, fee-worker=fedcba9876543210 ,")
+	require.NoError(t, err)
+	assert.Equal(t, ServiceTokens{"interest-worker": "0123456789abcdef", "fee-worker": "fedcba9876543210"}, tokens)
+
+	tokens, err = ParseServiceTokens("")
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	for value, expected := range map[string]string{
+		"interest-worker":                       `invalid service token entry "interest-worker": expected name=token`,
+		"fee-worker=short":                      "token of service fee-worker must be at least 16 characters",
+		"a=0123456789abcdef,a=fedcba9876543210": "duplicate service a",
+		"a=0123456789abcdef,b=0123456789abcdef": "token of service b is shared with another service",
+		"=0123456789abcdef":                     `invalid service token entry "=0123456789abcdef": expected name=token`,
+	} {
+		_, err := ParseServiceTokens(value)
+		assert.EqualError(t, err, expected, value)
+	}
+}
+
+func TestInternalAuthUnaryServerInterceptor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := NewLogger("test-service", INFO)
+	tokens := ServiceTokens{"interest-worker": "0123456789abcdef"}
+	interceptor := InternalAuthUnaryServerInterceptor(tokens, NewAccessAuditor(db, logger, "account-mgr-internal"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/account.InternalAccountService/AdjustBalance"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return InternalServiceFromContext(ctx), nil
+	}
+	incoming := func(requestID string, pairs ...string) context.Context {
+		return metadata.NewIncomingContext(WithRequestID(context.Background(), requestID), metadata.Pairs(pairs...))
+	}
+
+	// Every call is recorded, and the handler only runs for a known token
+	mock.ExpectExec(`INSERT INTO access_audit_log`).
+		WithArgs("account-mgr-internal", info.FullMethod, "interest-worker", RoleService, "", "req-1", AccessAllowed, "service token valid", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	service, err := interceptor(incoming("req-1", ServiceTokenMetadataKey, "0123456789abcdef"), nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "interest-worker", service)
+
+	mock.ExpectExec(`INSERT INTO access_audit_log`).
+		WithArgs("account-mgr-internal", info.FullMethod, "", RoleService, "", "req-2", AccessDenied, "unknown service token", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	_, err = interceptor(incoming("req-2", ServiceTokenMetadataKey, "0123456789abcdeX"), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Operator metadata does not grant access to internal endpoints
+	mock.ExpectExec(`INSERT INTO access_audit_log`).
+		WithArgs("account-mgr-internal", info.FullMethod, "", RoleService, "", "req-3", AccessDenied, "no service token", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	_, err = interceptor(incoming("req-3", CallerRoleMetadataKey, RoleAdmin, OperatorIDMetadataKey, "ops-1"), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// contextStream is a server stream that only carries a context.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestInternalAuthStreamServerInterceptor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := NewLogger("test-service", INFO)
+	tokens := ServiceTokens{"reporting": "0123456789abcdef"}
+	interceptor := InternalAuthStreamServerInterceptor(tokens, NewAccessAuditor(db, logger, "transaction-mgr-analytics"))
+	info := &grpc.StreamServerInfo{FullMethod: "/transaction.TransactionAnalyticsService/StreamTransactions", IsServerStream: true}
+	var service string
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		service = InternalServiceFromContext(ss.Context())
+		return nil
+	}
+	incoming := func(requestID string, pairs ...string) grpc.ServerStream {
+		return &contextStream{ctx: metadata.NewIncomingContext(WithRequestID(context.Background(), requestID), metadata.Pairs(pairs...))}
+	}
+
+	mock.ExpectExec(`INSERT INTO access_audit_log`).
+		WithArgs("transaction-mgr-analytics", info.FullMethod, "reporting", RoleService, "", "req-1", AccessAllowed, "service token valid", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	require.NoError(t, interceptor(nil, incoming("req-1", ServiceTokenMetadataKey, "0123456789abcdef"), info, handler))
+	assert.Equal(t, "reporting", service)
+
+	service = ""
+	mock.ExpectExec(`INSERT INTO access_audit_log`).
+		WithArgs("transaction-mgr-analytics", info.FullMethod, "", RoleService, "", "req-2", AccessDenied, "no service token", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	err = interceptor(nil, incoming("req-2"), info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Empty(t, service)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithServiceToken(t *testing.T) {
+	md, ok := metadata.FromOutgoingContext(WithServiceToken(context.Background(), "0123456789abcdef"))
+	require.True(t, ok)
+	assert.Equal(t, []string{"0123456789abcdef"}, md.Get(ServiceTokenMetadataKey))
+}
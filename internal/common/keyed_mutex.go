@@ -0,0 +1,70 @@
+package common
+
+import (
+	"context"
+	"sync"
+)
+
+// keyedLock is a single lock slot shared by all waiters on the same key.
+type keyedLock struct {
+	ch      chan struct{}
+	waiters int
+}
+
+// KeyedMutex serializes work per key while letting different keys proceed in parallel.
+// Waiters are admitted in arrival order per key. Entries are reference-counted and removed
+// once no goroutine holds or waits for the key, so memory does not grow with the key space.
+type KeyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// NewKeyedMutex creates a new keyed mutex.
+func NewKeyedMutex() *KeyedMutex {
+	return &KeyedMutex{locks: make(map[string]*keyedLock)}
+}
+
+// Lock acquires the lock for key, blocking until it is available or ctx is done.
+// It returns a function that releases the lock, or the context error if ctx ends first.
+func (km *KeyedMutex) Lock(ctx context.Context, key string) (func(), error) {
+	km.mu.Lock()
+	lock, ok := km.locks[key]
+	if !ok {
+		lock = &keyedLock{ch: make(chan struct{}, 1)}
+		km.locks[key] = lock
+	}
+	lock.waiters++
+	km.mu.Unlock()
+
+	select {
+	case lock.ch <- struct{}{}:
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				<-lock.ch
+				km.release(key, lock)
+			})
+		}, nil
+	case <-ctx.Done():
+		km.release(key, lock)
+		return nil, ctx.Err()
+	}
+}
+
+// release drops a waiter reference and deletes the entry when it is no longer used.
+func (km *KeyedMutex) release(key string, lock *keyedLock) {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	lock.waiters--
+	if lock.waiters == 0 {
+		delete(km.locks, key)
+	}
+}
+
+// Len returns the number of keys currently held or waited on.
+func (km *KeyedMutex) Len() int {
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	return len(km.locks)
+}
@@ -0,0 +1,83 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyedMutex_SerializesSameKey(t *testing.T) {
+	km := NewKeyedMutex()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		active  int
+		maxSeen int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := km.Lock(context.Background(), "account-1")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer unlock()
+
+			mu.Lock()
+			active++
+			if active > maxSeen {
+				maxSeen = active
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, maxSeen)
+	assert.Equal(t, 0, km.Len())
+}
+
+func TestKeyedMutex_IndependentKeys(t *testing.T) {
+	km := NewKeyedMutex()
+
+	unlockA, err := km.Lock(context.Background(), "account-1")
+	require.NoError(t, err)
+	defer unlockA()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	unlockB, err := km.Lock(ctx, "account-2")
+	require.NoError(t, err)
+	unlockB()
+
+	assert.Equal(t, 1, km.Len())
+}
+
+func TestKeyedMutex_ContextCancelled(t *testing.T) {
+	km := NewKeyedMutex()
+
+	unlock, err := km.Lock(context.Background(), "account-1")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = km.Lock(ctx, "account-1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Releasing twice is harmless and the entry is cleaned up
+	unlock()
+	unlock()
+	assert.Equal(t, 0, km.Len())
+}
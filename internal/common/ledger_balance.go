@@ -0,0 +1,150 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultLedgerBalanceCacheTTL is how long a balance derived from the ledger is reused.
+const DefaultLedgerBalanceCacheTTL = 5 * time.Second
+
+// maxLedgerBalanceEntries bounds the number of cached balances.
+const maxLedgerBalanceEntries = 100000
+
+// ledgerBalanceSQL derives the balance of an account from its ledger: the opening balance, the running totals
+// of completed transactions kept in transaction_daily_rollups, and approved balance adjustments.
+// Rollups are maintained by a trigger in the same database transaction as every transaction write,
+// so the result never depends on the mutable balance column.
+const ledgerBalanceSQL = `
+	SELECT a.opening_balance
+		+ COALESCE((SELECT SUM(r.total_amount) FROM transaction_daily_rollups r WHERE r.account_id = a.id), 0)
+		+ COALESCE((SELECT SUM(CASE WHEN b.direction = 'CREDIT' THEN b.amount ELSE -b.amount END)
+			FROM balance_adjustments b WHERE b.account_id = a.id AND b.status = 'APPROVED'), 0)
+	FROM accounts a WHERE a.id = $1`
+
+// ledgerBalanceAtSQL derives the balance of an account at the end of second $2 from its ledger: the opening
+// balance, the rollups of the days before that second's day, the completed transactions of that day up to the
+// second, and the adjustments approved by then. It also returns when the account was created.
+const ledgerBalanceAtSQL = `
+	SELECT a.created_at, a.opening_balance
+		+ COALESCE((SELECT SUM(r.total_amount) FROM transaction_daily_rollups r
+			WHERE r.account_id = a.id AND r.day_start < $2 - $2 % 86400), 0)
+		+ COALESCE((SELECT SUM(t.amount) FROM transactions t
+			WHERE t.account_id = a.id AND t.status IN ('COMPLETED', 'REVERSED')
+				AND t.created_at >= $2 - $2 % 86400 AND t.created_at <= $2), 0)
+		+ COALESCE((SELECT SUM(CASE WHEN b.direction = 'CREDIT' THEN b.amount ELSE -b.amount END)
+			FROM balance_adjustments b
+			WHERE b.account_id = a.id AND b.status = 'APPROVED' AND COALESCE(b.reviewed_at, b.requested_at) <= $2), 0)
+	FROM accounts a WHERE a.id = $1`
+
+// ErrBeforeAccountCreated is returned by BalanceAt for a time before the account was created.
+var ErrBeforeAccountCreated = errors.New("account did not exist at that time")
+
+// LedgerBalanceReader reads account balances derived from the ledger, caching them for a TTL.
+// A cached balance is only served to callers that accept a stale balance, and only while it is younger
+// than they allow, so writes made through another service instance are visible to the others after at most
+// that long; call Invalidate after local writes. It is safe for concurrent use.
+type LedgerBalanceReader struct {
+	db  *sql.DB
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]ledgerBalanceEntry
+}
+
+type ledgerBalanceEntry struct {
+	balance Cents
+	fetched time.Time
+	expires time.Time
+}
+
+// NewLedgerBalanceReader creates a reader whose balances are cached for ttl. A TTL of zero disables caching.
+func NewLedgerBalanceReader(db *sql.DB, ttl time.Duration) *LedgerBalanceReader {
+	return &LedgerBalanceReader{
+		db:      db,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]ledgerBalanceEntry),
+	}
+}
+
+// NewLedgerBalanceReaderFromEnv creates a reader with the cache TTL from the LEDGER_BALANCE_CACHE_TTL
+// environment variable, defaulting to DefaultLedgerBalanceCacheTTL. Set it to 0 to disable caching.
+func NewLedgerBalanceReaderFromEnv(db *sql.DB) *LedgerBalanceReader {
+	ttl, err := time.ParseDuration(getEnv("LEDGER_BALANCE_CACHE_TTL", DefaultLedgerBalanceCacheTTL.String()))
+	if err != nil || ttl < 0 {
+		ttl = DefaultLedgerBalanceCacheTTL
+	}
+	return NewLedgerBalanceReader(db, ttl)
+}
+
+// Balance returns the ledger balance of an account. A cached balance is returned if it is at most
+// maxStaleness old; with a maxStaleness of zero the balance is always read from the database.
+// It returns sql.ErrNoRows if the account does not exist.
+func (r *LedgerBalanceReader) Balance(ctx context.Context, accountID string, maxStaleness time.Duration) (Cents, error) {
+	now := r.now()
+	if maxStaleness > 0 {
+		r.mu.Lock()
+		entry, ok := r.entries[accountID]
+		r.mu.Unlock()
+		if ok && now.Before(entry.expires) && now.Sub(entry.fetched) <= maxStaleness {
+			return entry.balance, nil
+		}
+	}
+
+	var balance Cents
+	if err := r.db.QueryRowContext(ctx, ledgerBalanceSQL, accountID).Scan(&balance); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to derive ledger balance: %w", err)
+	}
+
+	if r.ttl > 0 {
+		r.mu.Lock()
+		if len(r.entries) >= maxLedgerBalanceEntries {
+			for id, cached := range r.entries {
+				if !now.Before(cached.expires) {
+					delete(r.entries, id)
+				}
+			}
+		}
+		if len(r.entries) < maxLedgerBalanceEntries {
+			r.entries[accountID] = ledgerBalanceEntry{balance: balance, fetched: now, expires: now.Add(r.ttl)}
+		}
+		r.mu.Unlock()
+	}
+	return balance, nil
+}
+
+// Invalidate drops the cached balance of an account, e.g. after a write that changed it.
+func (r *LedgerBalanceReader) Invalidate(accountID string) {
+	r.mu.Lock()
+	delete(r.entries, accountID)
+	r.mu.Unlock()
+}
+
+// BalanceAt returns the ledger balance of an account at the end of the second at, a Unix timestamp, bypassing
+// the cache. Transactions count from the second they were created if they completed, whenever that was, and
+// adjustments from the second they were approved. Amounts the retention worker folded into the opening balance
+// count from the start, so balances before a tenant's retention period are not reliable.
+// It returns sql.ErrNoRows if the account does not exist and ErrBeforeAccountCreated if it did not exist yet.
+func (r *LedgerBalanceReader) BalanceAt(ctx context.Context, accountID string, at int64) (Cents, error) {
+	var createdAt int64
+	var balance Cents
+	if err := r.db.QueryRowContext(ctx, ledgerBalanceAtSQL, accountID, at).Scan(&createdAt, &balance); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, err
+		}
+		return 0, fmt.Errorf("failed to derive ledger balance: %w", err)
+	}
+	if at < createdAt {
+		return 0, ErrBeforeAccountCreated
+	}
+	return balance, nil
+}
@@ -0,0 +1,117 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerBalanceReader_Balance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Unix(1700000000, 0)
+	reader := NewLedgerBalanceReader(db, 5*time.Second)
+	reader.now = func() time.Time { return now }
+
+	mock.ExpectQuery(`SELECT a.opening_balance .* FROM accounts a WHERE a.id = \$1`).
+		WithArgs("account-1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(120.5))
+
+	balance, err := reader.Balance(context.Background(), "account-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, Cents(12050), balance)
+
+	// Served from the cache until the TTL elapses to callers that accept a stale balance
+	now = now.Add(2 * time.Second)
+	balance, err = reader.Balance(context.Background(), "account-1", 5*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, Cents(12050), balance)
+
+	// Callers that accept less staleness than the cached balance's age, or none, read the database
+	mock.ExpectQuery(`FROM accounts a WHERE a.id = \$1`).
+		WithArgs("account-1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(110.0))
+	balance, err = reader.Balance(context.Background(), "account-1", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, Cents(11000), balance)
+
+	mock.ExpectQuery(`FROM accounts a WHERE a.id = \$1`).
+		WithArgs("account-1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(105.0))
+	balance, err = reader.Balance(context.Background(), "account-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, Cents(10500), balance)
+
+	now = now.Add(5 * time.Second)
+	mock.ExpectQuery(`FROM accounts a WHERE a.id = \$1`).
+		WithArgs("account-1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100.0))
+	balance, err = reader.Balance(context.Background(), "account-1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, Cents(10000), balance)
+
+	// Invalidate forces the next read to hit the database
+	reader.Invalidate("account-1")
+	mock.ExpectQuery(`FROM accounts a WHERE a.id = \$1`).
+		WithArgs("account-1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(90.0))
+	balance, err = reader.Balance(context.Background(), "account-1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, Cents(9000), balance)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLedgerBalanceReader_MissingAccount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	reader := NewLedgerBalanceReader(db, 0)
+	mock.ExpectQuery(`FROM accounts a WHERE a.id = \$1`).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+
+	_, err = reader.Balance(context.Background(), "missing", 0)
+	assert.Equal(t, sql.ErrNoRows, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLedgerBalanceReader_BalanceAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	reader := NewLedgerBalanceReader(db, 5*time.Second)
+	mock.ExpectQuery(`SELECT a.created_at, a.opening_balance .* r.day_start < \$2 - \$2 % 86400.* t.created_at <= \$2.* FROM accounts a WHERE a.id = \$1`).
+		WithArgs("account-1", int64(1700000000)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "balance"}).AddRow(1690000000, 80.25))
+	balance, err := reader.BalanceAt(context.Background(), "account-1", 1700000000)
+	require.NoError(t, err)
+	assert.Equal(t, Cents(8025), balance)
+
+	// Past balances are never cached
+	mock.ExpectQuery(`FROM accounts a WHERE a.id = \$1`).
+		WithArgs("account-1", int64(1600000000)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "balance"}).AddRow(1690000000, 0.0))
+	_, err = reader.BalanceAt(context.Background(), "account-1", 1600000000)
+	assert.ErrorIs(t, err, ErrBeforeAccountCreated)
+
+	mock.ExpectQuery(`FROM accounts a WHERE a.id = \$1`).WithArgs("missing", int64(1700000000)).WillReturnError(sql.ErrNoRows)
+	_, err = reader.BalanceAt(context.Background(), "missing", 1700000000)
+	assert.Equal(t, sql.ErrNoRows, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewLedgerBalanceReaderFromEnv(t *testing.T) {
+	t.Setenv("LEDGER_BALANCE_CACHE_TTL", "1s")
+	assert.Equal(t, time.Second, NewLedgerBalanceReaderFromEnv(nil).ttl)
+
+	t.Setenv("LEDGER_BALANCE_CACHE_TTL", "bogus")
+	assert.Equal(t, DefaultLedgerBalanceCacheTTL, NewLedgerBalanceReaderFromEnv(nil).ttl)
+}
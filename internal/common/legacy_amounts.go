@@ -0,0 +1,132 @@
+package common
+
+import (
+	"context"
+	"math"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// LegacyAmountsUnaryServerInterceptor returns a server interceptor that keeps clients built against the
+// double amount fields working. Amounts are exchanged as int64 fields ending in _cents; the double fields
+// they replaced are kept under their old names and numbers, deprecated. Requests that set only the double
+// field have it rounded into the cents field, and responses carry both.
+func LegacyAmountsUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if m, ok := req.(proto.Message); ok {
+			upgradeLegacyAmounts(m.ProtoReflect())
+		}
+		resp, err := handler(ctx, req)
+		if m, ok := resp.(proto.Message); ok && err == nil {
+			fillLegacyAmounts(m.ProtoReflect())
+		}
+		return resp, err
+	}
+}
+
+// LegacyAmountsStreamServerInterceptor returns the streaming counterpart of LegacyAmountsUnaryServerInterceptor.
+func LegacyAmountsStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &legacyAmountsServerStream{ServerStream: ss})
+	}
+}
+
+// legacyAmountsServerStream upgrades the messages a stream receives and fills the ones it sends.
+type legacyAmountsServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *legacyAmountsServerStream) RecvMsg(msg interface{}) error {
+	if err := s.ServerStream.RecvMsg(msg); err != nil {
+		return err
+	}
+	if m, ok := msg.(proto.Message); ok {
+		upgradeLegacyAmounts(m.ProtoReflect())
+	}
+	return nil
+}
+
+func (s *legacyAmountsServerStream) SendMsg(msg interface{}) error {
+	if m, ok := msg.(proto.Message); ok {
+		fillLegacyAmounts(m.ProtoReflect())
+	}
+	return s.ServerStream.SendMsg(msg)
+}
+
+// upgradeLegacyAmounts sets each unset cents field from its deprecated double field, at any depth.
+func upgradeLegacyAmounts(m protoreflect.Message) {
+	for _, pair := range legacyAmountFields(m.Descriptor()) {
+		if !m.Has(pair.cents) && m.Has(pair.legacy) {
+			m.Set(pair.cents, protoreflect.ValueOfInt64(int64(math.Round(m.Get(pair.legacy).Float()*100))))
+		}
+	}
+	rangeNestedMessages(m, upgradeLegacyAmounts)
+}
+
+// fillLegacyAmounts sets each deprecated double field from its cents field, at any depth.
+func fillLegacyAmounts(m protoreflect.Message) {
+	for _, pair := range legacyAmountFields(m.Descriptor()) {
+		if m.Has(pair.cents) {
+			m.Set(pair.legacy, protoreflect.ValueOfFloat64(float64(m.Get(pair.cents).Int())/100))
+		} else {
+			m.Clear(pair.legacy)
+		}
+	}
+	rangeNestedMessages(m, fillLegacyAmounts)
+}
+
+// legacyAmountField pairs a cents field with the deprecated double field it replaced.
+type legacyAmountField struct {
+	cents  protoreflect.FieldDescriptor
+	legacy protoreflect.FieldDescriptor
+}
+
+// legacyAmountFields returns the message's deprecated double fields that have a <name>_cents counterpart.
+func legacyAmountFields(md protoreflect.MessageDescriptor) []legacyAmountField {
+	var pairs []legacyAmountField
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		legacy := fields.Get(i)
+		if legacy.Kind() != protoreflect.DoubleKind || legacy.IsList() {
+			continue
+		}
+		if options, ok := legacy.Options().(*descriptorpb.FieldOptions); !ok || !options.GetDeprecated() {
+			continue
+		}
+		cents := fields.ByName(legacy.Name() + "_cents")
+		if cents == nil || cents.Kind() != protoreflect.Int64Kind || cents.IsList() {
+			continue
+		}
+		pairs = append(pairs, legacyAmountField{cents: cents, legacy: legacy})
+	}
+	return pairs
+}
+
+// rangeNestedMessages calls fn on every message held by the populated fields of m.
+func rangeNestedMessages(m protoreflect.Message, fn func(protoreflect.Message)) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil {
+			return true
+		}
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				fn(list.Get(i).Message())
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+					fn(value.Message())
+					return true
+				})
+			}
+		default:
+			fn(v.Message())
+		}
+		return true
+	})
+}
@@ -0,0 +1,98 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// legacyPaymentDescriptor describes a message shaped like the services' amounts: a cents field, the deprecated
+// double it replaced, an undeprecated double and a repeated nested message.
+func legacyPaymentDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	field := func(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type, deprecated bool) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     kind.Enum(),
+			JsonName: proto.String(name),
+			Options:  &descriptorpb.FieldOptions{Deprecated: proto.Bool(deprecated)},
+		}
+	}
+	items := field("items", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, false)
+	items.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	items.TypeName = proto.String(".legacy.Payment")
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("legacy.proto"),
+		Package: proto.String("legacy"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Payment"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("amount", 2, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, true),
+				field("amount_cents", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64, false),
+				field("rate", 4, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, false),
+				items,
+			},
+		}},
+	}, nil)
+	require.NoError(t, err)
+	return file.Messages().Get(0)
+}
+
+func TestLegacyAmountsUnaryServerInterceptor(t *testing.T) {
+	md := legacyPaymentDescriptor(t)
+	fields := md.Fields()
+	amount, amountCents, rate, items := fields.ByName("amount"), fields.ByName("amount_cents"), fields.ByName("rate"), fields.ByName("items")
+
+	tests := []struct {
+		name          string
+		amount        float64
+		amountCents   int64
+		expectedCents int64
+	}{
+		{name: "legacy client", amount: 10.29, expectedCents: 1029},
+		{name: "migrated client", amountCents: 500, expectedCents: 500},
+		{name: "both set", amount: 1, amountCents: 500, expectedCents: 500},
+		{name: "neither set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := dynamicpb.NewMessage(md)
+			req.Set(amount, protoreflect.ValueOfFloat64(tt.amount))
+			req.Set(amountCents, protoreflect.ValueOfInt64(tt.amountCents))
+			req.Set(rate, protoreflect.ValueOfFloat64(0.5))
+
+			var received int64
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				received = req.(*dynamicpb.Message).Get(amountCents).Int()
+				resp := dynamicpb.NewMessage(md)
+				resp.Set(amountCents, protoreflect.ValueOfInt64(received))
+				item := dynamicpb.NewMessage(md)
+				item.Set(amountCents, protoreflect.ValueOfInt64(-250))
+				resp.Mutable(items).List().Append(protoreflect.ValueOfMessage(item))
+				return resp, nil
+			}
+
+			interceptor := LegacyAmountsUnaryServerInterceptor()
+			resp, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{}, handler)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCents, received)
+			assert.Equal(t, 0.5, req.Get(rate).Float(), "fields without a cents counterpart are left alone")
+
+			payment := resp.(*dynamicpb.Message)
+			assert.Equal(t, float64(tt.expectedCents)/100, payment.Get(amount).Float())
+			assert.Equal(t, -2.5, payment.Get(items).List().Get(0).Message().Get(amount).Float())
+		})
+	}
+}
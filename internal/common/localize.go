@@ -0,0 +1,156 @@
+package common
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLocale is used when a localized response is requested without a supported locale.
+const DefaultLocale = "en-US"
+
+// numberSeparators are the thousands and decimal separators of a locale.
+type numberSeparators struct {
+	group   string
+	decimal string
+}
+
+// localeSeparators lists the supported locales, by language with regional exceptions.
+var localeSeparators = map[string]numberSeparators{
+	"en":    {",", "."},
+	"ja":    {",", "."},
+	"zh":    {",", "."},
+	"pt":    {".", ","},
+	"es":    {".", ","},
+	"es-mx": {",", "."},
+	"de":    {".", ","},
+	"de-ch": {"’", "."},
+	"it":    {".", ","},
+	"nl":    {".", ","},
+	"fr":    {"\u202f", ","},
+}
+
+// ResolveLocale returns the first supported locale of a locale tag or Accept-Language header value,
+// such as "pt-BR" or "fr-CH, fr;q=0.9, en;q=0.8", and whether one was found. Regions without their
+// own conventions use those of their language.
+func ResolveLocale(value string) (string, bool) {
+	for _, part := range strings.Split(value, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		tag = strings.ReplaceAll(tag, "_", "-")
+		if tag == "" {
+			continue
+		}
+		lower := strings.ToLower(tag)
+		if _, ok := localeSeparators[lower]; ok {
+			return tag, true
+		}
+		if _, ok := localeSeparators[strings.SplitN(lower, "-", 2)[0]]; ok {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// FormatAmount formats an amount with two decimals and the separators of locale, prefixed by the
+// currency code when one is given, e.g. "BRL -1.234,56" for pt-BR.
+func FormatAmount(amount float64, locale, currency string) string {
+	separators := separatorsFor(locale)
+
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', 2, 64)
+	integer, fraction := digits[:len(digits)-3], digits[len(digits)-2:]
+
+	var b strings.Builder
+	if currency != "" {
+		b.WriteString(currency + " ")
+	}
+	if amount < 0 && digits != "0.00" {
+		b.WriteString("-")
+	}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(separators.group)
+		}
+		b.WriteRune(digit)
+	}
+	b.WriteString(separators.decimal)
+	b.WriteString(fraction)
+	return b.String()
+}
+
+// separatorsFor returns the separators of a resolved locale, or of DefaultLocale.
+func separatorsFor(locale string) numberSeparators {
+	lower := strings.ToLower(locale)
+	if separators, ok := localeSeparators[lower]; ok {
+		return separators
+	}
+	if separators, ok := localeSeparators[strings.SplitN(lower, "-", 2)[0]]; ok {
+		return separators
+	}
+	return localeSeparators["en"]
+}
+
+// LocalizeResponseBody adds formatting metadata to a JSON response body for clients that do not
+// implement money and date formatting themselves. Next to every numeric money field (amount, balance
+// and fields ending in _amount or _balance, or starting with balance_) a formatted_<field> string is
+// added, using the currency field of the same object if there is one; next to every unix timestamp
+// field ending in _at, a <field>_iso string in RFC 3339 UTC. The original fields are kept.
+// Bodies that are not valid JSON are returned unchanged.
+func LocalizeResponseBody(body []byte, locale string) []byte {
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return body
+	}
+
+	localized, err := json.Marshal(localizeFields(payload, locale))
+	if err != nil {
+		return body
+	}
+	// Keep the trailing newline written by json.Encoder
+	if len(body) > 0 && body[len(body)-1] == '\n' {
+		localized = append(localized, '\n')
+	}
+	return localized
+}
+
+// localizeFields adds the formatted fields to a decoded JSON value in place and returns it.
+func localizeFields(value interface{}, locale string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		currency, _ := v["currency"].(string)
+		added := make(map[string]interface{})
+		for key, field := range v {
+			number, ok := field.(float64)
+			if !ok {
+				v[key] = localizeFields(field, locale)
+				continue
+			}
+			switch {
+			case isMoneyField(key):
+				added["formatted_"+key] = FormatAmount(number, locale, currency)
+			case strings.HasSuffix(key, "_at") && number > 0:
+				added[key+"_iso"] = time.Unix(int64(number), 0).UTC().Format(time.RFC3339)
+			}
+		}
+		for key, field := range added {
+			if _, exists := v[key]; !exists {
+				v[key] = field
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = localizeFields(item, locale)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// isMoneyField reports whether a JSON field name holds an amount of money.
+func isMoneyField(key string) bool {
+	return key == "amount" || key == "balance" ||
+		strings.HasSuffix(key, "_amount") || strings.HasSuffix(key, "_balance") || strings.HasPrefix(key, "balance_")
+}
@@ -0,0 +1,80 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveLocale(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		ok       bool
+	}{
+		{"pt-BR", "pt-BR", true},
+		{"pt_BR", "pt-BR", true},
+		{"xx-YY, fr-CH;q=0.9, en;q=0.8", "fr-CH", true},
+		{"es-MX", "es-MX", true},
+		{"xx", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			locale, ok := ResolveLocale(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, locale)
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		locale   string
+		currency string
+		expected string
+	}{
+		{1234567.891, "en-US", "", "1,234,567.89"},
+		{-1234.5, "pt-BR", "BRL", "BRL -1.234,50"},
+		{999.999, "de-DE", "", "1.000,00"},
+		{12345.6, "fr-FR", "EUR", "EUR 12\u202f345,60"},
+		{12345.6, "es-MX", "", "12,345.60"},
+		{12345.6, "es-ES", "", "12.345,60"},
+		{-0.001, "en-US", "", "0.00"},
+		{100, "unknown", "", "100.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatAmount(tt.amount, tt.locale, tt.currency))
+		})
+	}
+}
+
+func TestLocalizeResponseBody(t *testing.T) {
+	body := []byte(`{"id":"acc-1","balance":1500.5,"created_at":1700000000,"status":"ACTIVE",` +
+		`"balances":[{"currency":"BRL","balance":-20}],"transactions":[{"amount":10,"balance_after":1510.5,"reversed_at":0}],` +
+		`"document_number":"[REDACTED]"}` + "\n")
+
+	localized := LocalizeResponseBody(body, "pt-BR")
+	require.True(t, len(localized) > 0 && localized[len(localized)-1] == '\n')
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(localized, &payload))
+	assert.Equal(t, 1500.5, payload["balance"])
+	assert.Equal(t, "1.500,50", payload["formatted_balance"])
+	assert.Equal(t, float64(1700000000), payload["created_at"])
+	assert.Equal(t, "2023-11-14T22:13:20Z", payload["created_at_iso"])
+	assert.Equal(t, "BRL -20,00", payload["balances"].([]interface{})[0].(map[string]interface{})["formatted_balance"])
+
+	transaction := payload["transactions"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "10,00", transaction["formatted_amount"])
+	assert.Equal(t, "1.510,50", transaction["formatted_balance_after"])
+	assert.NotContains(t, transaction, "reversed_at_iso")
+
+	assert.Equal(t, []byte("not json"), LocalizeResponseBody([]byte("not json"), "en-US"))
+}
@@ -0,0 +1,336 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LogEntry is a log line as handed to the sinks of a Logger.
+type LogEntry struct {
+	Time    time.Time
+	Service string
+	Level   LogLevel
+	// Location is the file and line of the code that logged, e.g. account/account.go:120
+	Location  string
+	RequestID string
+	Message   string
+}
+
+// Text returns the entry as a line of text, as written to the console and the log file, without a newline.
+func (e LogEntry) Text() string {
+	requestID := ""
+	if e.RequestID != "" {
+		requestID = "request_id=" + e.RequestID + " "
+	}
+	return fmt.Sprintf("%s [%s][%s] %s %s%s", e.Time.Format("2006-01-02 15:04:05"), e.Service, e.Level,
+		e.Location, requestID, e.Message)
+}
+
+// LogSink receives the entries of a Logger. Write is called with the logger's lock held, so entries arrive one
+// at a time and in order; sinks shipping entries over the network should buffer them rather than block.
+type LogSink interface {
+	Write(entry LogEntry) error
+	Close() error
+}
+
+// writerSink writes entries as lines of text, closing closer, if any, on Close.
+type writerSink struct {
+	writer io.Writer
+	closer io.Closer
+}
+
+// NewWriterSink creates a sink writing entries as lines of text to w, like the console and the log file.
+// Closing the sink leaves w open.
+func NewWriterSink(w io.Writer) LogSink {
+	return &writerSink{writer: w}
+}
+
+func (s *writerSink) Write(entry LogEntry) error {
+	_, err := io.WriteString(s.writer, entry.Text()+"\n")
+	return err
+}
+
+func (s *writerSink) Close() error {
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
+
+// syslogFacility is the facility of the messages sent by SyslogSink, local0.
+const syslogFacility = 16
+
+// syslogSeverities maps log levels to syslog severities.
+var syslogSeverities = map[LogLevel]int{DEBUG: 7, INFO: 6, WARN: 4, ERROR: 3, FATAL: 2}
+
+// SyslogSink sends entries to a syslog server as RFC 5424 messages, with the request ID and location as
+// structured data. Over TCP messages are framed by octet counting (RFC 6587) and the connection is
+// re-established once when a write fails.
+type SyslogSink struct {
+	network  string
+	address  string
+	hostname string
+	conn     net.Conn
+}
+
+// NewSyslogSink connects to the syslog server at address over network, "udp" or "tcp".
+func NewSyslogSink(network, address string) (*SyslogSink, error) {
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported syslog network %q: expected udp or tcp", network)
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	sink := &SyslogSink{network: network, address: address, hostname: hostname}
+	if err := sink.dial(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (s *SyslogSink) dial() error {
+	conn, err := net.DialTimeout(s.network, s.address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog at %s: %w", s.address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// Write sends one entry.
+func (s *SyslogSink) Write(entry LogEntry) error {
+	message := s.format(entry)
+	if s.network == "tcp" {
+		message = strconv.Itoa(len(message)) + " " + message
+	}
+	_, err := io.WriteString(s.conn, message)
+	if err != nil && s.network == "tcp" {
+		s.conn.Close()
+		if err = s.dial(); err == nil {
+			_, err = io.WriteString(s.conn, message)
+		}
+	}
+	return err
+}
+
+// format renders an entry as an RFC 5424 message.
+func (s *SyslogSink) format(entry LogEntry) string {
+	severity, ok := syslogSeverities[entry.Level]
+	if !ok {
+		severity = 6
+	}
+	data := fmt.Sprintf(`[log@32473 location="%s"`, syslogParam(entry.Location))
+	if entry.RequestID != "" {
+		data += fmt.Sprintf(` request_id="%s"`, syslogParam(entry.RequestID))
+	}
+	data += "]"
+	return fmt.Sprintf("<%d>1 %s %s %s %d - %s %s", syslogFacility*8+severity,
+		entry.Time.UTC().Format(time.RFC3339Nano), s.hostname, entry.Service, os.Getpid(), data, entry.Message)
+}
+
+// syslogParam escapes a structured data parameter value.
+func syslogParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+// Close closes the connection.
+func (s *SyslogSink) Close() error {
+	return s.conn.Close()
+}
+
+// OTLP exporter limits: entries are sent in batches of up to otlpBatchSize, at least every otlpFlushInterval.
+// At most otlpQueueSize entries wait to be sent; further entries are dropped rather than slow the service down.
+const (
+	otlpBatchSize     = 512
+	otlpFlushInterval = 2 * time.Second
+	otlpQueueSize     = 4096
+)
+
+// otlpSeverities maps log levels to OTLP severity numbers.
+var otlpSeverities = map[LogLevel]int{DEBUG: 5, INFO: 9, WARN: 13, ERROR: 17, FATAL: 21}
+
+// OTLPLogSink exports entries to an OpenTelemetry collector with OTLP over HTTP, JSON encoded. Entries are queued
+// and sent in batches from a goroutine of its own, so logging never waits for the collector. Batches the
+// collector does not accept are dropped and counted, as are entries arriving while the queue is full.
+type OTLPLogSink struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+	entries  chan LogEntry
+	done     chan struct{}
+	close    sync.Once
+	dropped  atomic.Int64
+	failing  bool
+}
+
+// NewOTLPLogSink creates an exporter posting to endpoint, e.g. http://otel-collector:4318/v1/logs, with headers
+// added to every request, e.g. for authentication.
+func NewOTLPLogSink(endpoint string, headers map[string]string) *OTLPLogSink {
+	s := &OTLPLogSink{
+		endpoint: endpoint,
+		headers:  headers,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		entries:  make(chan LogEntry, otlpQueueSize),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write queues an entry, or drops it if the queue is full.
+func (s *OTLPLogSink) Write(entry LogEntry) error {
+	select {
+	case s.entries <- entry:
+		return nil
+	default:
+		s.dropped.Add(1)
+		return fmt.Errorf("export queue full")
+	}
+}
+
+// Dropped returns how many entries were not exported, because the queue was full or the collector failed.
+func (s *OTLPLogSink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close sends the queued entries and stops the exporter.
+func (s *OTLPLogSink) Close() error {
+	s.close.Do(func() { close(s.entries) })
+	<-s.done
+	return nil
+}
+
+// run batches queued entries until the sink is closed.
+func (s *OTLPLogSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]LogEntry, 0, otlpBatchSize)
+	for {
+		select {
+		case entry, ok := <-s.entries:
+			if !ok {
+				s.export(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) < otlpBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		s.export(batch)
+		batch = batch[:0]
+	}
+}
+
+// export posts a batch to the collector. Failures are reported on stderr when the collector starts failing,
+// since the logger cannot report its own export.
+func (s *OTLPLogSink) export(batch []LogEntry) {
+	if len(batch) == 0 {
+		return
+	}
+	err := s.post(batch)
+	if err != nil {
+		s.dropped.Add(int64(len(batch)))
+		if !s.failing {
+			fmt.Fprintf(os.Stderr, "log export to %s failing: %v\n", s.endpoint, err)
+		}
+	}
+	s.failing = err != nil
+}
+
+func (s *OTLPLogSink) post(batch []LogEntry) error {
+	body, err := json.Marshal(otlpLogsRequest(batch))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded %s", resp.Status)
+	}
+	return nil
+}
+
+// otlpValue is an OTLP AnyValue holding a string.
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// otlpAttribute is an OTLP KeyValue.
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+// otlpLogRecord is an OTLP LogRecord.
+type otlpLogRecord struct {
+	TimeUnixNano   string          `json:"timeUnixNano"`
+	SeverityNumber int             `json:"severityNumber"`
+	SeverityText   string          `json:"severityText"`
+	Body           otlpValue       `json:"body"`
+	Attributes     []otlpAttribute `json:"attributes"`
+}
+
+// otlpLogsRequest builds the ExportLogsServiceRequest of a batch, with one resource per service.
+func otlpLogsRequest(batch []LogEntry) map[string]interface{} {
+	var services []string
+	records := make(map[string][]otlpLogRecord)
+	for _, entry := range batch {
+		if _, ok := records[entry.Service]; !ok {
+			services = append(services, entry.Service)
+		}
+		attributes := []otlpAttribute{{Key: "code.location", Value: otlpValue{entry.Location}}}
+		if entry.RequestID != "" {
+			attributes = append(attributes, otlpAttribute{Key: "request_id", Value: otlpValue{entry.RequestID}})
+		}
+		records[entry.Service] = append(records[entry.Service], otlpLogRecord{
+			TimeUnixNano:   strconv.FormatInt(entry.Time.UnixNano(), 10),
+			SeverityNumber: otlpSeverities[entry.Level],
+			SeverityText:   entry.Level.String(),
+			Body:           otlpValue{entry.Message},
+			Attributes:     attributes,
+		})
+	}
+
+	resourceLogs := make([]map[string]interface{}, 0, len(services))
+	for _, service := range services {
+		resourceLogs = append(resourceLogs, map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []otlpAttribute{{Key: "service.name", Value: otlpValue{service}}},
+			},
+			"scopeLogs": []map[string]interface{}{{
+				"scope":      map[string]string{"name": "github.com/YASHIRAI/pismo-task/internal/common"},
+				"logRecords": records[service],
+			}},
+		})
+	}
+	return map[string]interface{}{"resourceLogs": resourceLogs}
+}
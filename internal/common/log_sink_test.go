@@ -0,0 +1,184 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogEntry_Text(t *testing.T) {
+	entry := LogEntry{
+		Time:      time.Date(2025, 9, 23, 15, 30, 45, 0, time.Local),
+		Service:   "gateway",
+		Level:     INFO,
+		Location:  "gateway/main.go:332",
+		RequestID: "req-1",
+		Message:   "Starting Gateway service",
+	}
+	assert.Equal(t, "2025-09-23 15:30:45 [gateway][INFO] gateway/main.go:332 request_id=req-1 Starting Gateway service", entry.Text())
+
+	entry.RequestID = ""
+	assert.Equal(t, "2025-09-23 15:30:45 [gateway][INFO] gateway/main.go:332 Starting Gateway service", entry.Text())
+}
+
+// failingSink fails every write until it is told to recover.
+type failingSink struct {
+	fail    bool
+	entries []LogEntry
+}
+
+func (s *failingSink) Write(entry LogEntry) error {
+	if s.fail {
+		return errors.New("unavailable")
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *failingSink) Close() error { return nil }
+
+func TestLogger_AddSink(t *testing.T) {
+	var console bytes.Buffer
+	logger := &Logger{core: &logCore{serviceName: "sink-test"}}
+	logger.AddSink(ConsoleDestination, NewWriterSink(&console), INFO)
+	sink := &failingSink{fail: true}
+	logger.AddSink(OTLPDestination, sink, WARN)
+
+	// A failing sink does not keep the other destinations from logging
+	logger.Warn("Ledger balance lookup failed")
+	assert.Contains(t, console.String(), "Ledger balance lookup failed")
+	assert.Empty(t, sink.entries)
+
+	sink.fail = false
+	logger.WithContext(WithRequestID(context.Background(), "req-7")).Error("Account lookup failed")
+	logger.Info("Below the sink's level")
+	require.Len(t, sink.entries, 1)
+	assert.Equal(t, ERROR, sink.entries[0].Level)
+	assert.Equal(t, "req-7", sink.entries[0].RequestID)
+	assert.Equal(t, "sink-test", sink.entries[0].Service)
+	assert.True(t, strings.HasPrefix(sink.entries[0].Location, "common/log_sink_test.go:"), sink.entries[0].Location)
+}
+
+func TestSyslogSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		length, _ := reader.ReadString(' ')
+		size, _ := strconv.Atoi(strings.TrimSpace(length))
+		message := make([]byte, size)
+		io.ReadFull(reader, message)
+		received <- string(message)
+	}()
+
+	sink, err := NewSyslogSink("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer sink.Close()
+
+	require.NoError(t, sink.Write(LogEntry{
+		Time:      time.Date(2025, 9, 23, 15, 30, 45, 0, time.UTC),
+		Service:   "account-mgr",
+		Level:     ERROR,
+		Location:  "account/account.go:120",
+		RequestID: `req"1]`,
+		Message:   "Account lookup failed",
+	}))
+
+	select {
+	case message := <-received:
+		assert.True(t, strings.HasPrefix(message, "<131>1 2025-09-23T15:30:45Z "), message)
+		assert.Contains(t, message, ` account-mgr `)
+		assert.Contains(t, message, `[log@32473 location="account/account.go:120" request_id="req\"1\]"] Account lookup failed`)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no syslog message received")
+	}
+
+	_, err = NewSyslogSink("unix", "/dev/log")
+	assert.Error(t, err)
+}
+
+func TestOTLPLogSink(t *testing.T) {
+	var mu sync.Mutex
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		requests = append(requests, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sink := NewOTLPLogSink(server.URL+"/v1/logs", map[string]string{"Authorization": "Bearer secret"})
+	now := time.Now()
+	require.NoError(t, sink.Write(LogEntry{Time: now, Service: "transaction-mgr", Level: WARN, Location: "transaction/risk.go:40", RequestID: "req-1", Message: "Velocity limit reached"}))
+	require.NoError(t, sink.Write(LogEntry{Time: now, Service: "transaction-mgr", Level: INFO, Location: "transaction/transaction.go:90", Message: "Transaction created"}))
+	require.NoError(t, sink.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, requests, 1)
+	resourceLogs := requests[0]["resourceLogs"].([]interface{})
+	require.Len(t, resourceLogs, 1)
+	resource := resourceLogs[0].(map[string]interface{})
+	serviceName := resource["resource"].(map[string]interface{})["attributes"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "service.name", serviceName["key"])
+	assert.Equal(t, "transaction-mgr", serviceName["value"].(map[string]interface{})["stringValue"])
+
+	records := resource["scopeLogs"].([]interface{})[0].(map[string]interface{})["logRecords"].([]interface{})
+	require.Len(t, records, 2)
+	first := records[0].(map[string]interface{})
+	assert.Equal(t, float64(13), first["severityNumber"])
+	assert.Equal(t, "WARN", first["severityText"])
+	assert.Equal(t, "Velocity limit reached", first["body"].(map[string]interface{})["stringValue"])
+	assert.Len(t, first["attributes"], 2)
+	assert.Len(t, records[1].(map[string]interface{})["attributes"], 1)
+	assert.Zero(t, sink.Dropped())
+}
+
+func TestOTLPLogSink_CountsRejectedBatches(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink := NewOTLPLogSink(server.URL, nil)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, sink.Write(LogEntry{Time: time.Now(), Service: "gateway", Level: INFO, Message: "HTTP GET /health"}))
+	}
+	require.NoError(t, sink.Close())
+	assert.Equal(t, int64(3), sink.Dropped())
+}
+
+func TestParseLogHeaders(t *testing.T) {
+	headers, err := parseLogHeaders("Authorization=Bearer abc==, X-Scope = ops ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer abc==", "X-Scope": "ops"}, headers)
+
+	_, err = parseLogHeaders("missing-value")
+	assert.Error(t, err)
+}
@@ -1,11 +1,15 @@
 package common
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,19 +24,71 @@ const (
 	FATAL
 )
 
+// String returns the name of the level as accepted by ParseLogLevel.
+func (level LogLevel) String() string {
+	switch level {
+	case DEBUG:
+		return "DEBUG"
+	case INFO:
+		return "INFO"
+	case WARN:
+		return "WARN"
+	case ERROR:
+		return "ERROR"
+	case FATAL:
+		return "FATAL"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(level))
+	}
+}
+
+// LogDestination identifies one of the outputs of a Logger.
+type LogDestination string
+
+// Log destinations. Each has a level of its own, so e.g. the file can keep DEBUG lines the console leaves out.
+const (
+	ConsoleDestination LogDestination = "console"
+	FileDestination    LogDestination = "file"
+	SyslogDestination  LogDestination = "syslog"
+	OTLPDestination    LogDestination = "otlp"
+)
+
+// logOutput is a destination of a logCore, the sink it writes to and the lowest level it writes.
+type logOutput struct {
+	destination LogDestination
+	sink        LogSink
+	level       LogLevel
+	failing     bool
+}
+
+// logCore is the state shared by a Logger and the loggers derived from it with WithContext: the outputs,
+// their levels, and the lock serializing writes so lines from concurrent goroutines are never interleaved.
+type logCore struct {
+	serviceName string
+	mu          sync.Mutex
+	outputs     []*logOutput
+}
+
 // Logger represents a structured logger
 type Logger struct {
-	debugLogger *log.Logger
-	infoLogger  *log.Logger
-	warnLogger  *log.Logger
-	errorLogger *log.Logger
-	fatalLogger *log.Logger
-	level       LogLevel
-	logFile     *os.File
+	core      *logCore
+	requestID string
 }
 
-// NewLogger creates a new logger instance
+// NewLogger creates a new logger writing to stdout and to a timestamped file in the logs directory,
+// both at logLevel.
 func NewLogger(serviceName string, logLevel LogLevel) (*Logger, error) {
+	return newLogger(serviceName, logLevel, true)
+}
+
+// newLogger creates a logger writing to stdout and, with withFile, to a timestamped file in the logs directory.
+func newLogger(serviceName string, logLevel LogLevel, withFile bool) (*Logger, error) {
+	logger := &Logger{core: &logCore{serviceName: serviceName}}
+	logger.AddSink(ConsoleDestination, NewWriterSink(os.Stdout), logLevel)
+	if !withFile {
+		return logger, nil
+	}
+
 	// Create logs directory if it doesn't exist
 	logDir := "logs"
 	if err := os.MkdirAll(logDir, 0755); err != nil {
@@ -48,77 +104,228 @@ func NewLogger(serviceName string, logLevel LogLevel) (*Logger, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open log file: %w", err)
 	}
+	logger.AddSink(FileDestination, &writerSink{writer: logFile, closer: logFile}, logLevel)
 
-	// Create multi-writer to write to both file and stdout
-	multiWriter := io.MultiWriter(os.Stdout, logFile)
+	return logger, nil
+}
 
-	// Create loggers with different prefixes
-	debugLogger := log.New(multiWriter, fmt.Sprintf("[%s][DEBUG] ", serviceName), log.LstdFlags|log.Lshortfile)
-	infoLogger := log.New(multiWriter, fmt.Sprintf("[%s][INFO] ", serviceName), log.LstdFlags|log.Lshortfile)
-	warnLogger := log.New(multiWriter, fmt.Sprintf("[%s][WARN] ", serviceName), log.LstdFlags|log.Lshortfile)
-	errorLogger := log.New(multiWriter, fmt.Sprintf("[%s][ERROR] ", serviceName), log.LstdFlags|log.Lshortfile)
-	fatalLogger := log.New(multiWriter, fmt.Sprintf("[%s][FATAL] ", serviceName), log.LstdFlags|log.Lshortfile)
+// NewLoggerFromEnv creates a logger with the level of LOG_LEVEL, INFO by default, writing to stdout and, unless
+// LOG_FILE_ENABLED is false, to a file. LOG_SYSLOG_ADDRESS (e.g. udp://logs.internal:514) adds a syslog sink and
+// LOG_OTLP_ENDPOINT (e.g. http://otel-collector:4318/v1/logs) an OTLP/HTTP exporter, with the headers of
+// LOG_OTLP_HEADERS as comma-separated name=value pairs. LOG_<DESTINATION>_LEVEL, e.g. LOG_FILE_LEVEL,
+// overrides the level of one destination.
+func NewLoggerFromEnv(serviceName string) (*Logger, error) {
+	level := ParseLogLevel(os.Getenv("LOG_LEVEL"))
+	logger, err := newLogger(serviceName, level, getEnv("LOG_FILE_ENABLED", "true") != "false")
+	if err != nil {
+		return nil, err
+	}
 
-	return &Logger{
-		debugLogger: debugLogger,
-		infoLogger:  infoLogger,
-		warnLogger:  warnLogger,
-		errorLogger: errorLogger,
-		fatalLogger: fatalLogger,
-		level:       logLevel,
-		logFile:     logFile,
-	}, nil
+	if address := os.Getenv("LOG_SYSLOG_ADDRESS"); address != "" {
+		network, host, ok := strings.Cut(address, "://")
+		if !ok {
+			network, host = "udp", address
+		}
+		sink, err := NewSyslogSink(network, host)
+		if err != nil {
+			logger.Close()
+			return nil, err
+		}
+		logger.AddSink(SyslogDestination, sink, level)
+	}
+	if endpoint := os.Getenv("LOG_OTLP_ENDPOINT"); endpoint != "" {
+		headers, err := parseLogHeaders(os.Getenv("LOG_OTLP_HEADERS"))
+		if err != nil {
+			logger.Close()
+			return nil, err
+		}
+		logger.AddSink(OTLPDestination, NewOTLPLogSink(endpoint, headers), level)
+	}
+
+	for _, destination := range []LogDestination{ConsoleDestination, FileDestination, SyslogDestination, OTLPDestination} {
+		if value := os.Getenv("LOG_" + strings.ToUpper(string(destination)) + "_LEVEL"); value != "" {
+			logger.SetDestinationLevel(destination, ParseLogLevel(value))
+		}
+	}
+	return logger, nil
+}
+
+// parseLogHeaders parses comma-separated name=value pairs, e.g. "authorization=Bearer ..., x-tenant=ops".
+func parseLogHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, header, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid log header %q: expected name=value", pair)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(header)
+	}
+	return headers, nil
+}
+
+// AddSink adds an output to the logger, and to every logger derived from it, writing entries of level and above
+// to sink. The sink is closed by Close. It is safe to call while other goroutines are logging.
+func (l *Logger) AddSink(destination LogDestination, sink LogSink, level LogLevel) {
+	output := &logOutput{destination: destination, sink: sink, level: level}
+
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	l.core.outputs = append(l.core.outputs, output)
+}
+
+// log builds an entry and hands it to every output whose level it reaches. A sink that starts failing is
+// reported once on stderr, as it cannot be reported through the logger itself, and again once it recovers.
+func (l *Logger) log(level LogLevel, format string, v ...interface{}) {
+	if l.Level() > level {
+		return
+	}
+
+	entry := LogEntry{
+		Time:      time.Now(),
+		Service:   l.core.serviceName,
+		Level:     level,
+		Location:  callerLocation(),
+		RequestID: l.requestID,
+		Message:   fmt.Sprintf(format, v...),
+	}
+
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	for _, output := range l.core.outputs {
+		if output.level > level {
+			continue
+		}
+		err := output.sink.Write(entry)
+		if err != nil && !output.failing {
+			fmt.Fprintf(os.Stderr, "log sink %s failing: %v\n", output.destination, err)
+		} else if err == nil && output.failing {
+			fmt.Fprintf(os.Stderr, "log sink %s recovered\n", output.destination)
+		}
+		output.failing = err != nil
+	}
+}
+
+// callerLocation returns the file and line of the code that logged, skipping the frames of this file
+// so lines logged through helpers like LogDatabase point at their caller.
+func callerLocation() string {
+	pcs := make([]uintptr, 8)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasSuffix(frame.File, "/common/logger.go") {
+			return filepath.Base(filepath.Dir(frame.File)) + "/" + filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return "???"
+		}
+	}
 }
 
 // Debug logs a debug message
 func (l *Logger) Debug(format string, v ...interface{}) {
-	if l.level <= DEBUG {
-		l.debugLogger.Printf(format, v...)
-	}
+	l.log(DEBUG, format, v...)
 }
 
 // Info logs an info message
 func (l *Logger) Info(format string, v ...interface{}) {
-	if l.level <= INFO {
-		l.infoLogger.Printf(format, v...)
-	}
+	l.log(INFO, format, v...)
 }
 
 // Warn logs a warning message
 func (l *Logger) Warn(format string, v ...interface{}) {
-	if l.level <= WARN {
-		l.warnLogger.Printf(format, v...)
-	}
+	l.log(WARN, format, v...)
 }
 
 // Error logs an error message
 func (l *Logger) Error(format string, v ...interface{}) {
-	if l.level <= ERROR {
-		l.errorLogger.Printf(format, v...)
-	}
+	l.log(ERROR, format, v...)
 }
 
-// Fatal logs a fatal message and exits
+// Fatal logs a fatal message and exits. The sinks are closed first, so buffered entries are shipped.
 func (l *Logger) Fatal(format string, v ...interface{}) {
-	l.fatalLogger.Printf(format, v...)
+	l.log(FATAL, format, v...)
+	l.Close()
 	os.Exit(1)
 }
 
-// Close closes the log file
+// WithContext returns a logger that tags every line with the request ID carried by ctx.
+// The returned logger shares its outputs and levels with l; if ctx has no request ID, l itself is returned.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" {
+		return l
+	}
+	return &Logger{core: l.core, requestID: requestID}
+}
+
+// Close closes every sink: the log file, and network sinks after shipping what they have buffered.
 func (l *Logger) Close() error {
-	if l.logFile != nil {
-		return l.logFile.Close()
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	var errs []error
+	for _, output := range l.core.outputs {
+		if err := output.sink.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing log sink %s: %w", output.destination, err))
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
-// SetLevel sets the logging level
+// SetLevel sets the logging level of every destination.
+// It is safe to call while other goroutines are logging, e.g. on a runtime config reload.
 func (l *Logger) SetLevel(level LogLevel) {
-	l.level = level
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	for _, output := range l.core.outputs {
+		output.level = level
+	}
+}
+
+// SetDestinationLevel sets the logging level of one destination, leaving the others as they are.
+// Unknown destinations are ignored.
+func (l *Logger) SetDestinationLevel(destination LogDestination, level LogLevel) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	for _, output := range l.core.outputs {
+		if output.destination == destination {
+			output.level = level
+		}
+	}
+}
+
+// DestinationLevel returns the logging level of a destination, or FATAL for unknown destinations.
+func (l *Logger) DestinationLevel(destination LogDestination) LogLevel {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	for _, output := range l.core.outputs {
+		if output.destination == destination {
+			return output.level
+		}
+	}
+	return FATAL
+}
+
+// Level returns the most verbose level of any destination, below which nothing is logged at all.
+func (l *Logger) Level() LogLevel {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	level := FATAL
+	for _, output := range l.core.outputs {
+		level = min(level, output.level)
+	}
+	return level
 }
 
 // LogRequest logs HTTP request details
 func (l *Logger) LogRequest(method, path, clientIP string, statusCode int, duration time.Duration) {
+	if statusCode == StatusClientClosedRequest {
+		l.Warn("HTTP %s %s from %s - Status: %d (client closed request) - Duration: %v", method, path, clientIP, statusCode, duration)
+		return
+	}
 	l.Info("HTTP %s %s from %s - Status: %d - Duration: %v", method, path, clientIP, statusCode, duration)
 }
 
@@ -1,10 +1,15 @@
 package common
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -153,3 +158,72 @@ func TestConcurrentLogging(t *testing.T) {
 		<-done
 	}
 }
+
+func TestLogger_DestinationLevels(t *testing.T) {
+	var console, file bytes.Buffer
+	logger := &Logger{core: &logCore{serviceName: "levels-test"}}
+	logger.AddSink(ConsoleDestination, NewWriterSink(&console), INFO)
+	logger.AddSink(FileDestination, NewWriterSink(&file), DEBUG)
+
+	assert.Equal(t, DEBUG, logger.Level())
+	assert.Equal(t, INFO, logger.DestinationLevel(ConsoleDestination))
+	assert.Equal(t, DEBUG, logger.DestinationLevel(FileDestination))
+
+	logger.Debug("Cache miss: Key=%s", "account-1")
+	logger.Info("Account created: ID=%s", "account-1")
+
+	assert.NotContains(t, console.String(), "Cache miss")
+	assert.Contains(t, console.String(), "[levels-test][INFO] common/logger_test.go:")
+	assert.Contains(t, file.String(), "[levels-test][DEBUG] common/logger_test.go:")
+	assert.Contains(t, file.String(), "Account created: ID=account-1")
+
+	// Helpers report the line that called them
+	logger.LogDatabase("SELECT", "accounts", 0, nil)
+	assert.NotContains(t, file.String(), "logger.go:")
+
+	// SetLevel applies to every destination
+	logger.SetLevel(ERROR)
+	console.Reset()
+	file.Reset()
+	logger.Warn("suppressed")
+	assert.Empty(t, console.String())
+	assert.Empty(t, file.String())
+}
+
+func TestNewLoggerFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "WARN")
+	t.Setenv("LOG_FILE_LEVEL", "DEBUG")
+
+	logger, err := NewLoggerFromEnv("env-test")
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	assert.Equal(t, WARN, logger.DestinationLevel(ConsoleDestination))
+	assert.Equal(t, DEBUG, logger.DestinationLevel(FileDestination))
+}
+
+func TestConcurrentLogging_LinesNotInterleaved(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &Logger{core: &logCore{serviceName: "concurrent-test"}}
+	logger.AddSink(ConsoleDestination, NewWriterSink(&buf), INFO)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				logger.Info("Concurrent log message %d-%d", id, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 500)
+	for _, line := range lines {
+		assert.Contains(t, line, "[concurrent-test][INFO]")
+	}
+}
@@ -0,0 +1,424 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+// Channels messages are rendered for. An EMAIL template renders a subject and a body; a WEBHOOK template renders
+// the JSON payload posted to a subscriber.
+const (
+	MessageChannelEmail   = "EMAIL"
+	MessageChannelWebhook = "WEBHOOK"
+)
+
+// Sources of the message template in effect for a tenant.
+const (
+	MessageTemplateSourceTenant   = "TENANT"
+	MessageTemplateSourcePlatform = "PLATFORM"
+)
+
+// DefaultMessageTemplatesTTL is how long the message templates of a tenant are cached before they are reloaded.
+const DefaultMessageTemplatesTTL = time.Minute
+
+// Limits on message templates and on the messages rendered from them; maxMessageSubjectLength matches
+// message_templates.subject.
+const (
+	maxMessageSubjectLength  = 200
+	maxMessageTemplateLength = 10000
+	maxRenderedMessageLength = 64 * 1024
+)
+
+// ErrMessageTemplateNotFound is returned when deleting a tenant template that does not exist.
+var ErrMessageTemplateNotFound = errors.New("message template not found")
+
+// messageChannels lists the channels templates can be written for.
+var messageChannels = map[string]bool{
+	MessageChannelEmail:   true,
+	MessageChannelWebhook: true,
+}
+
+// messageTemplateFuncs are the functions available to message templates besides the text/template builtins.
+var messageTemplateFuncs = template.FuncMap{
+	// json encodes a value as JSON, e.g. to quote a string in a webhook payload
+	"json": func(value interface{}) (string, error) {
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// date formats Unix seconds in UTC with a Go time layout, e.g. {{date "2006-01-02" .created_at}}
+	"date": func(layout string, unix int64) string {
+		return time.Unix(unix, 0).UTC().Format(layout)
+	},
+	// amount formats a decimal amount with two decimal places
+	"amount": func(value float64) string {
+		return fmt.Sprintf("%.2f", value)
+	},
+}
+
+// MessageTemplateConfig is a platform message template in the runtime configuration.
+type MessageTemplateConfig struct {
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body"`
+}
+
+// ValidateMessageTemplates checks the platform message templates of a runtime configuration, keyed by event type
+// and channel.
+func ValidateMessageTemplates(templates map[string]map[string]MessageTemplateConfig) error {
+	for eventType, channels := range templates {
+		for channel, config := range channels {
+			t := MessageTemplate{EventType: eventType, Channel: channel, Subject: config.Subject, Body: config.Body}
+			if err := t.Parse(); err != nil {
+				return fmt.Errorf("message template %s %s: %w", eventType, channel, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MessageTemplate is a Go text/template of the messages rendered for the events of one type on one channel, for
+// the whole platform when TenantID is empty, or overriding it for a tenant. Templates read the variables of the
+// event with missingkey=error, so a misspelled variable fails rendering instead of rendering "<no value>".
+type MessageTemplate struct {
+	TenantID  string
+	EventType string
+	Channel   string
+	Subject   string
+	Body      string
+	UpdatedBy string
+	UpdatedAt int64
+
+	subject *template.Template
+	body    *template.Template
+}
+
+// Source returns whether the template is a tenant's or the platform's.
+func (t *MessageTemplate) Source() string {
+	if t.TenantID != "" {
+		return MessageTemplateSourceTenant
+	}
+	return MessageTemplateSourcePlatform
+}
+
+// Parse checks that the template is well formed and compiles it for Render.
+func (t *MessageTemplate) Parse() error {
+	if !messageChannels[t.Channel] {
+		return fmt.Errorf("channel must be EMAIL or WEBHOOK")
+	}
+	if t.Body == "" {
+		return fmt.Errorf("body required")
+	}
+	if len(t.Body) > maxMessageTemplateLength {
+		return fmt.Errorf("body longer than %d characters", maxMessageTemplateLength)
+	}
+	if len(t.Subject) > maxMessageSubjectLength {
+		return fmt.Errorf("subject longer than %d characters", maxMessageSubjectLength)
+	}
+	switch {
+	case t.Channel == MessageChannelEmail && t.Subject == "":
+		return fmt.Errorf("subject required for EMAIL")
+	case t.Channel == MessageChannelWebhook && t.Subject != "":
+		return fmt.Errorf("subject only allowed for EMAIL")
+	}
+
+	body, err := parseMessageTemplate("body", t.Body)
+	if err != nil {
+		return err
+	}
+	var subject *template.Template
+	if t.Subject != "" {
+		if subject, err = parseMessageTemplate("subject", t.Subject); err != nil {
+			return err
+		}
+	}
+	t.subject, t.body = subject, body
+	return nil
+}
+
+// parseMessageTemplate compiles one part of a message template.
+func parseMessageTemplate(name, text string) (*template.Template, error) {
+	parsed, err := template.New(name).Funcs(messageTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", name, strings.TrimPrefix(err.Error(), "template: "))
+	}
+	return parsed, nil
+}
+
+// Render renders a message from the template with the variables of an event, parsing the template first if
+// needed. EMAIL subjects must render to a single line and WEBHOOK bodies to valid JSON.
+func (t *MessageTemplate) Render(vars map[string]interface{}) (subject, body string, err error) {
+	if t.body == nil {
+		if err := t.Parse(); err != nil {
+			return "", "", err
+		}
+	}
+	if t.subject != nil {
+		if subject, err = executeMessageTemplate(t.subject, vars); err != nil {
+			return "", "", err
+		}
+		subject = strings.TrimSpace(subject)
+		if strings.ContainsAny(subject, "\r\n") {
+			return "", "", fmt.Errorf("subject must be a single line")
+		}
+	}
+	if body, err = executeMessageTemplate(t.body, vars); err != nil {
+		return "", "", err
+	}
+	if t.Channel == MessageChannelWebhook && !json.Valid([]byte(body)) {
+		return "", "", fmt.Errorf("webhook body is not valid JSON")
+	}
+	return subject, body, nil
+}
+
+// executeMessageTemplate executes one part of a template, failing once its output exceeds maxRenderedMessageLength.
+func executeMessageTemplate(parsed *template.Template, vars map[string]interface{}) (string, error) {
+	var out limitedBuffer
+	if err := parsed.Execute(&out, vars); err != nil {
+		return "", fmt.Errorf("rendering %s failed: %s", parsed.Name(), strings.TrimPrefix(err.Error(), "template: "))
+	}
+	return out.String(), nil
+}
+
+// errMessageTooLong is returned by limitedBuffer once the rendered message exceeds maxRenderedMessageLength.
+var errMessageTooLong = fmt.Errorf("message longer than %d bytes", maxRenderedMessageLength)
+
+// limitedBuffer collects a rendered message, refusing writes past maxRenderedMessageLength.
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > maxRenderedMessageLength {
+		return 0, errMessageTooLong
+	}
+	return b.Buffer.Write(p)
+}
+
+// messageTemplateKey identifies the template of one event type on one channel.
+type messageTemplateKey struct {
+	eventType string
+	channel   string
+}
+
+// messageTemplatesEntry is a cached copy of one tenant's templates.
+type messageTemplatesEntry struct {
+	templates map[messageTemplateKey]*MessageTemplate
+	expires   time.Time
+}
+
+// MessageTemplateStore holds the message templates: the platform's, read from the runtime configuration, and the
+// overrides of each tenant in the message_templates table, cached for a TTL so events do not query the database
+// every time. Changes invalidate the local cache; other service instances pick them up once their cached copy
+// expires.
+type MessageTemplateStore struct {
+	db       *sql.DB
+	ttl      time.Duration
+	now      func() time.Time
+	platform atomic.Pointer[map[messageTemplateKey]*MessageTemplate]
+
+	mu      sync.Mutex
+	entries map[string]messageTemplatesEntry
+}
+
+// NewMessageTemplateStore creates a store whose tenant templates are cached for ttl. A TTL of zero disables caching.
+func NewMessageTemplateStore(db *sql.DB, ttl time.Duration) *MessageTemplateStore {
+	s := &MessageTemplateStore{
+		db:      db,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]messageTemplatesEntry),
+	}
+	s.platform.Store(&map[messageTemplateKey]*MessageTemplate{})
+	return s
+}
+
+// NewMessageTemplateStoreFromEnv creates a store caching tenant templates for MESSAGE_TEMPLATES_TTL, defaulting to
+// DefaultMessageTemplatesTTL.
+func NewMessageTemplateStoreFromEnv(db *sql.DB) *MessageTemplateStore {
+	ttl, err := time.ParseDuration(getEnv("MESSAGE_TEMPLATES_TTL", DefaultMessageTemplatesTTL.String()))
+	if err != nil || ttl < 0 {
+		ttl = DefaultMessageTemplatesTTL
+	}
+	return NewMessageTemplateStore(db, ttl)
+}
+
+// ApplyRuntimeConfig replaces the platform templates with the message_templates of the runtime configuration,
+// which Reload has validated.
+func (s *MessageTemplateStore) ApplyRuntimeConfig(config *RuntimeConfig) {
+	platform := make(map[messageTemplateKey]*MessageTemplate)
+	for eventType, channels := range config.MessageTemplates {
+		for channel, templateConfig := range channels {
+			t := &MessageTemplate{EventType: eventType, Channel: channel, Subject: templateConfig.Subject, Body: templateConfig.Body}
+			if t.Parse() == nil {
+				platform[messageTemplateKey{eventType, channel}] = t
+			}
+		}
+	}
+	s.platform.Store(&platform)
+}
+
+// Resolve returns the template in effect for a tenant, the tenant's own or else the platform's, or nil if neither
+// has one. An empty tenant ID resolves the platform template.
+func (s *MessageTemplateStore) Resolve(ctx context.Context, tenantID, eventType, channel string) (*MessageTemplate, error) {
+	key := messageTemplateKey{eventType, channel}
+	if tenantID != "" {
+		tenantTemplates, err := s.tenantTemplates(ctx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := tenantTemplates[key]; ok {
+			return t, nil
+		}
+	}
+	return (*s.platform.Load())[key], nil
+}
+
+// ForEvent returns the templates in effect for a tenant for the events of one type, EMAIL first.
+func (s *MessageTemplateStore) ForEvent(ctx context.Context, tenantID, eventType string) ([]*MessageTemplate, error) {
+	var templates []*MessageTemplate
+	for _, channel := range []string{MessageChannelEmail, MessageChannelWebhook} {
+		t, err := s.Resolve(ctx, tenantID, eventType, channel)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			templates = append(templates, t)
+		}
+	}
+	return templates, nil
+}
+
+// List returns the templates in effect for a tenant, ordered by event type and channel, bypassing the cache.
+func (s *MessageTemplateStore) List(ctx context.Context, tenantID string) ([]*MessageTemplate, error) {
+	if !ValidTenantID(tenantID) {
+		return nil, ErrInvalidTenantID
+	}
+	tenantTemplates, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := make(map[messageTemplateKey]*MessageTemplate, len(tenantTemplates))
+	for key, t := range *s.platform.Load() {
+		effective[key] = t
+	}
+	for key, t := range tenantTemplates {
+		effective[key] = t
+	}
+	templates := make([]*MessageTemplate, 0, len(effective))
+	for _, t := range effective {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].EventType != templates[j].EventType {
+			return templates[i].EventType < templates[j].EventType
+		}
+		return templates[i].Channel < templates[j].Channel
+	})
+	return templates, nil
+}
+
+// Put validates and stores a tenant's template, replacing any it had for the event type and channel.
+func (s *MessageTemplateStore) Put(ctx context.Context, t *MessageTemplate) error {
+	if !ValidTenantID(t.TenantID) {
+		return ErrInvalidTenantID
+	}
+	if err := t.Parse(); err != nil {
+		return err
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO message_templates (tenant_id, event_type, channel, subject, body, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id, event_type, channel) DO UPDATE
+		SET subject = EXCLUDED.subject, body = EXCLUDED.body, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+	`, t.TenantID, t.EventType, t.Channel, t.Subject, t.Body, t.UpdatedBy, t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store message template: %w", err)
+	}
+	s.invalidate(t.TenantID)
+	return nil
+}
+
+// Delete removes a tenant's template, so the platform's applies again.
+func (s *MessageTemplateStore) Delete(ctx context.Context, tenantID, eventType, channel string) error {
+	if !ValidTenantID(tenantID) {
+		return ErrInvalidTenantID
+	}
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM message_templates WHERE tenant_id = $1 AND event_type = $2 AND channel = $3
+	`, tenantID, eventType, channel)
+	if err != nil {
+		return fmt.Errorf("failed to delete message template: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return ErrMessageTemplateNotFound
+	}
+	s.invalidate(tenantID)
+	return nil
+}
+
+// tenantTemplates returns the templates of a tenant from the cache, loading them when missing or expired.
+func (s *MessageTemplateStore) tenantTemplates(ctx context.Context, tenantID string) (map[messageTemplateKey]*MessageTemplate, error) {
+	now := s.now()
+	s.mu.Lock()
+	entry, ok := s.entries[tenantID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.templates, nil
+	}
+
+	templates, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if s.ttl > 0 {
+		s.mu.Lock()
+		s.entries[tenantID] = messageTemplatesEntry{templates: templates, expires: now.Add(s.ttl)}
+		s.mu.Unlock()
+	}
+	return templates, nil
+}
+
+// load reads the templates of a tenant. Stored templates that no longer parse are left out, so the platform's
+// apply in their place.
+func (s *MessageTemplateStore) load(ctx context.Context, tenantID string) (map[messageTemplateKey]*MessageTemplate, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT event_type, channel, subject, body, updated_by, updated_at
+		FROM message_templates WHERE tenant_id = $1
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load message templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make(map[messageTemplateKey]*MessageTemplate)
+	for rows.Next() {
+		t := &MessageTemplate{TenantID: tenantID}
+		if err := rows.Scan(&t.EventType, &t.Channel, &t.Subject, &t.Body, &t.UpdatedBy, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if t.Parse() == nil {
+			templates[messageTemplateKey{t.EventType, t.Channel}] = t
+		}
+	}
+	return templates, rows.Err()
+}
+
+// invalidate drops the cached templates of a tenant.
+func (s *MessageTemplateStore) invalidate(tenantID string) {
+	s.mu.Lock()
+	delete(s.entries, tenantID)
+	s.mu.Unlock()
+}
@@ -0,0 +1,161 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var messageTemplateColumns = []string{"event_type", "channel", "subject", "body", "updated_by", "updated_at"}
+
+func TestMessageTemplate_Render(t *testing.T) {
+	vars := map[string]interface{}{
+		"transaction_id": "tx-1",
+		"amount":         -42.5,
+		"status":         "COMPLETED",
+		"description":    "Coffee\nShop",
+		"created_at":     int64(1700000000),
+	}
+
+	tests := []struct {
+		name            string
+		template        MessageTemplate
+		expectedSubject string
+		expectedBody    string
+		expectedError   string
+	}{
+		{
+			name: "email",
+			template: MessageTemplate{Channel: MessageChannelEmail, Subject: "Purchase of {{amount .amount}}",
+				Body: `{{lower .status}} on {{date "2006-01-02" .created_at}}`},
+			expectedSubject: "Purchase of -42.50",
+			expectedBody:    "completed on 2023-11-14",
+		},
+		{
+			name:         "webhook",
+			template:     MessageTemplate{Channel: MessageChannelWebhook, Body: `{"id": {{json .transaction_id}}, "amount": {{.amount}}}`},
+			expectedBody: `{"id": "tx-1", "amount": -42.5}`,
+		},
+		{
+			name:          "missing variable",
+			template:      MessageTemplate{Channel: MessageChannelEmail, Subject: "Hi", Body: "{{.merchant}}"},
+			expectedError: `rendering body failed: body:1:2: executing "body" at <.merchant>: map has no entry for key "merchant"`,
+		},
+		{
+			name:          "subject spanning lines",
+			template:      MessageTemplate{Channel: MessageChannelEmail, Subject: "{{.description}}", Body: "Hi"},
+			expectedError: "subject must be a single line",
+		},
+		{
+			name:          "webhook body not JSON",
+			template:      MessageTemplate{Channel: MessageChannelWebhook, Body: `{"id": {{.transaction_id}}}`},
+			expectedError: "webhook body is not valid JSON",
+		},
+		{
+			name:          "email without subject",
+			template:      MessageTemplate{Channel: MessageChannelEmail, Body: "Hi"},
+			expectedError: "subject required for EMAIL",
+		},
+		{
+			name:          "webhook with subject",
+			template:      MessageTemplate{Channel: MessageChannelWebhook, Subject: "Hi", Body: "{}"},
+			expectedError: "subject only allowed for EMAIL",
+		},
+		{
+			name:          "unknown channel",
+			template:      MessageTemplate{Channel: "SMS", Body: "Hi"},
+			expectedError: "channel must be EMAIL or WEBHOOK",
+		},
+		{
+			name:          "malformed",
+			template:      MessageTemplate{Channel: MessageChannelWebhook, Body: "{{.amount"},
+			expectedError: "invalid body: body:1: unclosed action",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, body, err := tt.template.Render(vars)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSubject, subject)
+			assert.Equal(t, tt.expectedBody, body)
+		})
+	}
+}
+
+func TestMessageTemplateStore_Resolve(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := NewMessageTemplateStore(db, time.Minute)
+	store.ApplyRuntimeConfig(&RuntimeConfig{MessageTemplates: map[string]map[string]MessageTemplateConfig{
+		"transaction.created": {
+			MessageChannelEmail:   {Subject: "Platform", Body: "Platform"},
+			MessageChannelWebhook: {Body: "{}"},
+		},
+	}})
+
+	// A stored template that no longer parses is skipped, so the platform's applies in its place
+	mock.ExpectQuery(`FROM message_templates WHERE tenant_id = \$1`).
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows(messageTemplateColumns).
+			AddRow("transaction.created", "EMAIL", "Acme", "Acme", "admin-1", 1000).
+			AddRow("transaction.created", "WEBHOOK", "", "{{", "admin-1", 1000))
+
+	for i := 0; i < 2; i++ {
+		templates, err := store.ForEvent(context.Background(), "acme", "transaction.created")
+		require.NoError(t, err)
+		require.Len(t, templates, 2)
+		assert.Equal(t, MessageTemplateSourceTenant, templates[0].Source())
+		assert.Equal(t, "Acme", templates[0].Body)
+		assert.Equal(t, MessageTemplateSourcePlatform, templates[1].Source())
+	}
+
+	none, err := store.Resolve(context.Background(), "acme", "transaction.reversed", MessageChannelEmail)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageTemplateStore_PutAndDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := NewMessageTemplateStore(db, time.Minute)
+	err = store.Put(context.Background(), &MessageTemplate{TenantID: "acme", EventType: "transaction.created",
+		Channel: MessageChannelWebhook, Subject: "Hi", Body: "{}"})
+	assert.EqualError(t, err, "subject only allowed for EMAIL")
+	assert.ErrorIs(t, store.Put(context.Background(), &MessageTemplate{TenantID: "ACME!", Channel: MessageChannelWebhook, Body: "{}"}),
+		ErrInvalidTenantID)
+
+	mock.ExpectExec(`INSERT INTO message_templates`).
+		WithArgs("acme", "transaction.created", "WEBHOOK", "", "{}", "admin-1", int64(1000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.Put(context.Background(), &MessageTemplate{TenantID: "acme", EventType: "transaction.created",
+		Channel: MessageChannelWebhook, Body: "{}", UpdatedBy: "admin-1", UpdatedAt: 1000}))
+
+	mock.ExpectExec(`DELETE FROM message_templates`).
+		WithArgs("acme", "transaction.created", "EMAIL").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, store.Delete(context.Background(), "acme", "transaction.created", MessageChannelEmail), ErrMessageTemplateNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateMessageTemplates(t *testing.T) {
+	assert.NoError(t, ValidateMessageTemplates(map[string]map[string]MessageTemplateConfig{
+		"transaction.created": {MessageChannelEmail: {Subject: "Hi", Body: "{{.amount}}"}},
+	}))
+	assert.EqualError(t, ValidateMessageTemplates(map[string]map[string]MessageTemplateConfig{
+		"transaction.created": {MessageChannelEmail: {Body: "Hi"}},
+	}), "message template transaction.created EMAIL: subject required for EMAIL")
+}
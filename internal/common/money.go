@@ -0,0 +1,219 @@
+package common
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Cents is an amount of money in minor units, hundredths of the currency unit, the precision of the
+// DECIMAL(15,2) columns amounts are stored in. Amounts are added and compared as integers, so balances and
+// totals no longer drift by fractions of a cent the way float64 sums do. In the database and in JSON an
+// amount is a decimal such as 12.50.
+type Cents int64
+
+// ParseCents parses a decimal amount such as "12.5", "-0.01" or "1e3". Amounts with more than two decimals
+// are rejected rather than rounded, so no money is silently lost at the API boundary.
+func ParseCents(s string) (Cents, error) {
+	return parseCents(s, false)
+}
+
+// CentsFromFloat converts an amount held as a float64, such as one read from configuration or an event
+// written before amounts were kept in cents, rounding half away from zero. The float is taken at its
+// shortest decimal representation, so 10.005 becomes 1001 cents even though it is stored just below.
+func CentsFromFloat(amount float64) Cents {
+	cents, _ := parseCents(strconv.FormatFloat(amount, 'g', -1, 64), true)
+	return cents
+}
+
+// Float64 returns the amount in currency units, for reporting and for published events that predate
+// amounts in cents. The result's shortest decimal representation is the exact amount.
+func (c Cents) Float64() float64 {
+	return float64(c) / 100
+}
+
+// String returns the amount as a decimal with two decimals, e.g. "-12.50".
+func (c Cents) String() string {
+	sign := ""
+	units := int64(c)
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units/100, units%100)
+}
+
+// Abs returns the amount without its sign.
+func (c Cents) Abs() Cents {
+	if c < 0 {
+		return -c
+	}
+	return c
+}
+
+// MulRate returns the amount multiplied by rate, e.g. a percentage fee or an exchange rate,
+// rounded half away from zero to whole cents.
+func (c Cents) MulRate(rate float64) Cents {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'g', -1, 64))
+	if !ok {
+		return 0
+	}
+	cents, _ := roundRat(r.Mul(r, new(big.Rat).SetInt64(int64(c))))
+	return cents
+}
+
+// Scan reads a DECIMAL column, which the driver returns as text, without going through float64.
+// Values with more than two decimals, such as averages, are rounded half away from zero.
+func (c *Cents) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return c.scanText(string(v))
+	case string:
+		return c.scanText(v)
+	case float64:
+		*c = CentsFromFloat(v)
+		return nil
+	case int64:
+		*c = Cents(v * 100)
+		return nil
+	case nil:
+		return errors.New("cannot scan NULL into Cents")
+	default:
+		return fmt.Errorf("cannot scan %T into Cents", src)
+	}
+}
+
+func (c *Cents) scanText(text string) error {
+	cents, err := parseCents(text, true)
+	if err != nil {
+		return err
+	}
+	*c = cents
+	return nil
+}
+
+// Value writes the amount as decimal text, e.g. "-12.50", which NUMERIC and DECIMAL columns take exactly.
+func (c Cents) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimals.
+func (c Cents) MarshalJSON() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number, or a string holding one, with at most two decimals.
+// null leaves the amount unchanged.
+func (c *Cents) UnmarshalJSON(data []byte) error {
+	text := string(bytes.Trim(data, `"`))
+	if text == "null" {
+		return nil
+	}
+	cents, err := ParseCents(text)
+	if err != nil {
+		return err
+	}
+	*c = cents
+	return nil
+}
+
+// DecimalizeResponseBody renders the amounts of a JSON response body as API clients receive them: every
+// integer field named <field>_cents, as the services exchange amounts, is replaced by <field> holding the
+// amount as a decimal number, so {"balance_cents":1050} becomes {"balance":10.50}. Other fields are kept,
+// and bodies without such fields or that are not valid JSON are returned unchanged.
+func DecimalizeResponseBody(body []byte) []byte {
+	if !bytes.Contains(body, []byte(`_cents"`)) {
+		return body
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return body
+	}
+
+	decimalized, err := json.Marshal(decimalizeFields(payload))
+	if err != nil {
+		return body
+	}
+	// Keep the trailing newline written by json.Encoder
+	if len(body) > 0 && body[len(body)-1] == '\n' {
+		decimalized = append(decimalized, '\n')
+	}
+	return decimalized
+}
+
+// decimalizeFields replaces the cents fields of a decoded JSON value in place and returns it.
+func decimalizeFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		amounts := make(map[string]Cents)
+		for key, field := range v {
+			name, isCents := strings.CutSuffix(key, "_cents")
+			if number, ok := field.(json.Number); ok && isCents && name != "" {
+				if cents, err := number.Int64(); err == nil {
+					delete(v, key)
+					amounts[name] = Cents(cents)
+					continue
+				}
+			}
+			v[key] = decimalizeFields(field)
+		}
+		for name, amount := range amounts {
+			v[name] = amount
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = decimalizeFields(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// parseCents parses a decimal amount exactly, rounding or rejecting extra decimals.
+func parseCents(s string, round bool) (Cents, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.Contains(s, "/") {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	r.Mul(r, big.NewRat(100, 1))
+	if !r.IsInt() && !round {
+		return 0, fmt.Errorf("amount %q has more than two decimals", s)
+	}
+	cents, ok := roundRat(r)
+	if !ok {
+		return 0, fmt.Errorf("amount %q out of range", s)
+	}
+	return cents, nil
+}
+
+// roundRat rounds r half away from zero and reports whether the result fits in Cents.
+func roundRat(r *big.Rat) (Cents, bool) {
+	num, den := r.Num(), r.Denom()
+	if !r.IsInt() {
+		// (2*num ± den) / (2*den), truncated towards zero
+		twice := new(big.Int).Mul(num, big.NewInt(2))
+		if num.Sign() < 0 {
+			twice.Sub(twice, den)
+		} else {
+			twice.Add(twice, den)
+		}
+		num = twice.Quo(twice, new(big.Int).Mul(den, big.NewInt(2)))
+	}
+	if !num.IsInt64() {
+		return 0, false
+	}
+	return Cents(num.Int64()), true
+}
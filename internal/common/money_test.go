@@ -0,0 +1,138 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCents(t *testing.T) {
+	tests := []struct {
+		value         string
+		expected      Cents
+		expectedError string
+	}{
+		{value: "12.5", expected: 1250},
+		{value: "-0.01", expected: -1},
+		{value: " 100 ", expected: 10000},
+		{value: "1e3", expected: 100000},
+		{value: "0.1", expected: 10},
+		{value: "10.005", expectedError: `amount "10.005" has more than two decimals`},
+		{value: "1/3", expectedError: `invalid amount "1/3"`},
+		{value: "abc", expectedError: `invalid amount "abc"`},
+		{value: "", expectedError: `invalid amount ""`},
+		{value: "1e30", expectedError: `amount "1e30" out of range`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cents, err := ParseCents(tt.value)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cents)
+		})
+	}
+}
+
+func TestCentsFromFloat(t *testing.T) {
+	// 0.1 + 0.2 is 0.30000000000000004 as a float64; in cents the sum is exact
+	assert.Equal(t, Cents(30), CentsFromFloat(0.1+0.2))
+	assert.Equal(t, Cents(1001), CentsFromFloat(10.005))
+	assert.Equal(t, Cents(-1001), CentsFromFloat(-10.005))
+	assert.Equal(t, Cents(10050), CentsFromFloat(100.50))
+	assert.Equal(t, 100.5, Cents(10050).Float64())
+}
+
+func TestCents_StringAndMulRate(t *testing.T) {
+	assert.Equal(t, "-12.50", Cents(-1250).String())
+	assert.Equal(t, "0.05", Cents(5).String())
+	assert.Equal(t, "-0.05", Cents(-5).String())
+	assert.Equal(t, Cents(5), Cents(-5).Abs())
+
+	assert.Equal(t, Cents(6), Cents(1250).MulRate(0.005))
+	assert.Equal(t, Cents(-6), Cents(-1250).MulRate(0.005))
+	assert.Equal(t, Cents(20075), Cents(10000).MulRate(2.0075))
+}
+
+func TestCents_Scan(t *testing.T) {
+	tests := []struct {
+		name          string
+		src           interface{}
+		expected      Cents
+		expectedError string
+	}{
+		{name: "decimal text", src: []byte("-1234.56"), expected: -123456},
+		{name: "average", src: "10.125", expected: 1013},
+		{name: "float", src: 100.5, expected: 10050},
+		{name: "integer", src: int64(7), expected: 700},
+		{name: "null", src: nil, expectedError: "cannot scan NULL into Cents"},
+		{name: "other", src: true, expectedError: "cannot scan bool into Cents"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cents Cents
+			err := cents.Scan(tt.src)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cents)
+		})
+	}
+}
+
+func TestCents_JSON(t *testing.T) {
+	var req struct {
+		Amount  Cents `json:"amount"`
+		Limit   Cents `json:"limit"`
+		Missing Cents `json:"missing"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":12.5,"limit":"100.25","missing":null}`), &req))
+	assert.Equal(t, Cents(1250), req.Amount)
+	assert.Equal(t, Cents(10025), req.Limit)
+	assert.Equal(t, Cents(0), req.Missing)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":12.505}`), &req))
+
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":12.50,"limit":100.25,"missing":0.00}`, string(data))
+}
+
+func TestCents_Value(t *testing.T) {
+	for _, cents := range []Cents{0, 5, -1250, 123456789} {
+		value, err := cents.Value()
+		require.NoError(t, err)
+
+		var scanned Cents
+		require.NoError(t, scanned.Scan(value))
+		assert.Equal(t, cents, scanned)
+	}
+
+	value, err := Cents(-5).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "-0.05", value)
+}
+
+func TestDecimalizeResponseBody(t *testing.T) {
+	body := []byte(`{"id":"acc-1","balance_cents":150050,"created_at":1700000000,` +
+		`"balances":[{"currency":"BRL","balance_cents":-2000,"held_cents":5}],"percent_used":12.5,"note_cents":"text"}` + "\n")
+
+	decimalized := DecimalizeResponseBody(body)
+	assert.Equal(t, byte('\n'), decimalized[len(decimalized)-1])
+	assert.Contains(t, string(decimalized), `"balance":1500.50`)
+	assert.Contains(t, string(decimalized), `"held":0.05`)
+	assert.JSONEq(t, `{"id":"acc-1","balance":1500.50,"created_at":1700000000,`+
+		`"balances":[{"currency":"BRL","balance":-20.00,"held":0.05}],"percent_used":12.5,"note_cents":"text"}`, string(decimalized))
+
+	unchanged := []byte(`{"balance":10.5}`)
+	assert.Equal(t, unchanged, DecimalizeResponseBody(unchanged))
+	assert.Equal(t, []byte(`not json "x_cents"`), DecimalizeResponseBody([]byte(`not json "x_cents"`)))
+}
@@ -0,0 +1,104 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultNegativeCacheTTL is how long a "not found" lookup result is remembered.
+const DefaultNegativeCacheTTL = 30 * time.Second
+
+// maxNegativeCacheEntries bounds the number of remembered misses so a flood of random keys cannot exhaust memory.
+const maxNegativeCacheEntries = 100000
+
+// NegativeCache remembers keys that were recently looked up and not found, so repeated
+// lookups of the same missing key can be answered without hitting the database.
+// A TTL of zero disables the cache.
+type NegativeCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]time.Time
+	now     func() time.Time
+}
+
+// NewNegativeCache creates a negative cache whose entries expire after ttl.
+func NewNegativeCache(ttl time.Duration) *NegativeCache {
+	return &NegativeCache{
+		ttl:     ttl,
+		entries: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// NewNegativeCacheFromEnv creates a negative cache with the TTL from the NEGATIVE_CACHE_TTL
+// environment variable, defaulting to DefaultNegativeCacheTTL. Set it to 0 to disable caching.
+func NewNegativeCacheFromEnv() *NegativeCache {
+	ttl, err := time.ParseDuration(getEnv("NEGATIVE_CACHE_TTL", DefaultNegativeCacheTTL.String()))
+	if err != nil || ttl < 0 {
+		ttl = DefaultNegativeCacheTTL
+	}
+	return NewNegativeCache(ttl)
+}
+
+// Add records key as missing until the TTL elapses.
+// When the cache is full and no entries have expired, the key is not recorded.
+func (c *NegativeCache) Add(key string) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries) >= maxNegativeCacheEntries {
+		c.evictExpired(now)
+		if len(c.entries) >= maxNegativeCacheEntries {
+			return
+		}
+	}
+	c.entries[key] = now.Add(c.ttl)
+}
+
+// Contains reports whether key was recorded as missing and has not yet expired.
+func (c *NegativeCache) Contains(key string) bool {
+	if c.ttl <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiry, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	if !c.now().Before(expiry) {
+		delete(c.entries, key)
+		return false
+	}
+	return true
+}
+
+// Remove forgets key, e.g. once it is known to exist.
+func (c *NegativeCache) Remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Len returns the number of entries currently held, including expired ones not yet evicted.
+func (c *NegativeCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// evictExpired drops all entries whose TTL has elapsed.
+func (c *NegativeCache) evictExpired(now time.Time) {
+	for key, expiry := range c.entries {
+		if !now.Before(expiry) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestNegativeCache(ttl time.Duration) (*NegativeCache, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	cache := NewNegativeCache(ttl)
+	cache.now = clock.Now
+	return cache, clock
+}
+
+func TestNegativeCache_Expiry(t *testing.T) {
+	cache, clock := newTestNegativeCache(time.Minute)
+
+	assert.False(t, cache.Contains("missing-account"))
+
+	cache.Add("missing-account")
+	assert.True(t, cache.Contains("missing-account"))
+	assert.False(t, cache.Contains("other-account"))
+
+	clock.now = clock.now.Add(59 * time.Second)
+	assert.True(t, cache.Contains("missing-account"))
+
+	clock.now = clock.now.Add(time.Second)
+	assert.False(t, cache.Contains("missing-account"))
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestNegativeCache_Remove(t *testing.T) {
+	cache, _ := newTestNegativeCache(time.Minute)
+
+	cache.Add("missing-account")
+	cache.Remove("missing-account")
+	assert.False(t, cache.Contains("missing-account"))
+}
+
+func TestNegativeCache_Disabled(t *testing.T) {
+	cache, _ := newTestNegativeCache(0)
+
+	cache.Add("missing-account")
+	assert.False(t, cache.Contains("missing-account"))
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestNegativeCache_Bounded(t *testing.T) {
+	cache, clock := newTestNegativeCache(time.Minute)
+	for i := 0; i < maxNegativeCacheEntries; i++ {
+		cache.entries[strconv.Itoa(i)] = clock.now.Add(time.Minute)
+	}
+
+	cache.Add("overflow")
+	assert.False(t, cache.Contains("overflow"))
+
+	// Once entries expire there is room again
+	clock.now = clock.now.Add(2 * time.Minute)
+	cache.Add("overflow")
+	assert.True(t, cache.Contains("overflow"))
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestNewNegativeCacheFromEnv(t *testing.T) {
+	t.Setenv("NEGATIVE_CACHE_TTL", "5s")
+	assert.Equal(t, 5*time.Second, NewNegativeCacheFromEnv().ttl)
+
+	t.Setenv("NEGATIVE_CACHE_TTL", "bogus")
+	assert.Equal(t, DefaultNegativeCacheTTL, NewNegativeCacheFromEnv().ttl)
+}
@@ -1,30 +1,56 @@
 package common
 
 import (
+	"strings"
 	"time"
 )
 
 // Account represents a bank account in the database.
-// It contains all account-related information including balance and metadata.
+// It contains all account-related information including balance, onboarding state and metadata.
 type Account struct {
-	ID             string  `db:"id"`
-	DocumentNumber string  `db:"document_number"`
-	AccountType    string  `db:"account_type"`
-	Balance        float64 `db:"balance"`
-	CreatedAt      int64   `db:"created_at"`
-	UpdatedAt      int64   `db:"updated_at"`
+	ID             string `db:"id"`
+	DocumentNumber string `db:"document_number"`
+	AccountType    string `db:"account_type"`
+	Balance        Cents  `db:"balance"`
+	CreatedAt      int64  `db:"created_at"`
+	UpdatedAt      int64  `db:"updated_at"`
+	Status         string `db:"status"`
+	HolderName     string `db:"holder_name"`
+	HolderEmail    string `db:"holder_email"`
+	HolderPhone    string `db:"holder_phone"`
+	KYCReference   string `db:"kyc_reference"`
+	Version        int64  `db:"version"`
+	// OverdraftLimit is how far below zero debits may take the balance of a checking account
+	OverdraftLimit Cents `db:"overdraft_limit"`
+	// Tags segment accounts, e.g. VIP, TEST or COLLECTIONS; stored comma-separated
+	Tags []string `db:"tags"`
 }
 
 // Transaction represents a financial transaction in the database.
 // It contains transaction details including operation type, amount, and status.
 type Transaction struct {
-	ID            string  `db:"id"`
-	AccountID     string  `db:"account_id"`
-	OperationType string  `db:"operation_type"`
-	Amount        float64 `db:"amount"`
-	Description   string  `db:"description"`
-	CreatedAt     int64   `db:"created_at"`
-	Status        string  `db:"status"`
+	ID                    string            `db:"id"`
+	AccountID             string            `db:"account_id"`
+	OperationType         string            `db:"operation_type"`
+	Amount                Cents             `db:"amount"`
+	Description           string            `db:"description"`
+	CreatedAt             int64             `db:"created_at"`
+	Status                string            `db:"status"`
+	ExternalID            string            `db:"external_id"`
+	Tags                  []string          `db:"tags"`
+	Metadata              map[string]string `db:"metadata"`
+	TransferID            string            `db:"transfer_id"`
+	OriginalTransactionID string            `db:"original_transaction_id"`
+	// Balance is the part of a purchase or withdrawal not yet discharged by payments, or the part of a
+	// payment left over after discharging them
+	Balance Cents `db:"balance"`
+	// Overdrawn is set on debits that took the account balance below zero, into its overdraft
+	Overdrawn bool `db:"overdrawn"`
+	// OriginalCurrency, OriginalAmount and FXRate are set on payments made in a currency other than the
+	// account's: the currency and amount paid, and the rate they were converted at
+	OriginalCurrency string  `db:"original_currency"`
+	OriginalAmount   Cents   `db:"original_amount"`
+	FXRate           float64 `db:"fx_rate"`
 }
 
 // ToUnixTimestamp converts a time.Time to Unix timestamp (seconds since epoch).
@@ -44,3 +70,8 @@ func FromUnixTimestamp(ts int64) time.Time {
 func GetCurrentTimestamp() int64 {
 	return time.Now().Unix()
 }
+
+// EscapeLikePattern escapes LIKE wildcards so user input is matched literally.
+func EscapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -83,7 +83,7 @@ func TestAccount_Struct(t *testing.T) {
 		ID:             "test-id",
 		DocumentNumber: "12345678901",
 		AccountType:    "CHECKING",
-		Balance:        100.50,
+		Balance:        10050,
 		CreatedAt:      1234567890,
 		UpdatedAt:      1234567891,
 	}
@@ -91,7 +91,7 @@ func TestAccount_Struct(t *testing.T) {
 	assert.Equal(t, "test-id", account.ID)
 	assert.Equal(t, "12345678901", account.DocumentNumber)
 	assert.Equal(t, "CHECKING", account.AccountType)
-	assert.Equal(t, 100.50, account.Balance)
+	assert.Equal(t, Cents(10050), account.Balance)
 	assert.Equal(t, int64(1234567890), account.CreatedAt)
 	assert.Equal(t, int64(1234567891), account.UpdatedAt)
 }
@@ -101,7 +101,7 @@ func TestTransaction_Struct(t *testing.T) {
 		ID:            "tx-123",
 		AccountID:     "acc-456",
 		OperationType: "PAYMENT",
-		Amount:        50.25,
+		Amount:        5025,
 		Description:   "Test transaction",
 		CreatedAt:     1234567890,
 		Status:        "COMPLETED",
@@ -110,7 +110,7 @@ func TestTransaction_Struct(t *testing.T) {
 	assert.Equal(t, "tx-123", transaction.ID)
 	assert.Equal(t, "acc-456", transaction.AccountID)
 	assert.Equal(t, "PAYMENT", transaction.OperationType)
-	assert.Equal(t, 50.25, transaction.Amount)
+	assert.Equal(t, Cents(5025), transaction.Amount)
 	assert.Equal(t, "Test transaction", transaction.Description)
 	assert.Equal(t, int64(1234567890), transaction.CreatedAt)
 	assert.Equal(t, "COMPLETED", transaction.Status)
@@ -137,7 +137,7 @@ func TestAccount_Validation(t *testing.T) {
 				ID:             "valid-id",
 				DocumentNumber: "12345678901",
 				AccountType:    "CHECKING",
-				Balance:        100.0,
+				Balance:        10000,
 				CreatedAt:      1234567890,
 				UpdatedAt:      1234567890,
 			},
@@ -149,7 +149,7 @@ func TestAccount_Validation(t *testing.T) {
 				ID:             "",
 				DocumentNumber: "12345678901",
 				AccountType:    "CHECKING",
-				Balance:        100.0,
+				Balance:        10000,
 				CreatedAt:      1234567890,
 				UpdatedAt:      1234567890,
 			},
@@ -161,7 +161,7 @@ func TestAccount_Validation(t *testing.T) {
 				ID:             "valid-id",
 				DocumentNumber: "12345678901",
 				AccountType:    "CHECKING",
-				Balance:        -50.0,
+				Balance:        -5000,
 				CreatedAt:      1234567890,
 				UpdatedAt:      1234567890,
 			},
@@ -189,7 +189,7 @@ func TestTransaction_Validation(t *testing.T) {
 				ID:            "tx-123",
 				AccountID:     "acc-456",
 				OperationType: "PAYMENT",
-				Amount:        50.0,
+				Amount:        5000,
 				Description:   "Test",
 				CreatedAt:     1234567890,
 				Status:        "COMPLETED",
@@ -202,7 +202,7 @@ func TestTransaction_Validation(t *testing.T) {
 				ID:            "",
 				AccountID:     "acc-456",
 				OperationType: "PAYMENT",
-				Amount:        50.0,
+				Amount:        5000,
 				Description:   "Test",
 				CreatedAt:     1234567890,
 				Status:        "COMPLETED",
@@ -215,7 +215,7 @@ func TestTransaction_Validation(t *testing.T) {
 				ID:            "tx-123",
 				AccountID:     "",
 				OperationType: "PAYMENT",
-				Amount:        50.0,
+				Amount:        5000,
 				Description:   "Test",
 				CreatedAt:     1234567890,
 				Status:        "COMPLETED",
@@ -228,7 +228,7 @@ func TestTransaction_Validation(t *testing.T) {
 				ID:            "tx-123",
 				AccountID:     "acc-456",
 				OperationType: "PAYMENT",
-				Amount:        0.0,
+				Amount:        0,
 				Description:   "Test",
 				CreatedAt:     1234567890,
 				Status:        "COMPLETED",
@@ -245,3 +245,8 @@ func TestTransaction_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestEscapeLikePattern(t *testing.T) {
+	assert.Equal(t, "UBER", EscapeLikePattern("UBER"))
+	assert.Equal(t, `50\% off\_now\\`, EscapeLikePattern(`50% off_now\`))
+}
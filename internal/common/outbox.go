@@ -0,0 +1,353 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults for the outbox relay.
+const (
+	DefaultOutboxInterval  = time.Second
+	DefaultOutboxBatchSize = 100
+	DefaultOutboxTimeout   = 10 * time.Second
+)
+
+// OutboxSubscriber names the broker in delivery reports. The relay delivers every event to the
+// broker alone, which fans it out to its own consumers.
+const OutboxSubscriber = "broker"
+
+// Delivery statuses of an outbox event.
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryFailing   = "FAILING"
+	DeliveryDelivered = "DELIVERED"
+)
+
+// OutboxEvent is an event written to the event_outbox table, to be published by the relay.
+// Envelope holds the serialized event envelope; EventID is the deduplication key of the broker.
+type OutboxEvent struct {
+	ID           int64
+	EventID      string
+	EventType    string
+	PartitionKey string
+	Envelope     []byte
+	CreatedAt    int64
+}
+
+// EventPublisher delivers events to the broker. Publish may be called more than once for the same
+// event, e.g. when the relay stops after publishing but before marking it published, so the broker
+// must drop events whose EventID it has already accepted.
+type EventPublisher interface {
+	Publish(ctx context.Context, event OutboxEvent) error
+}
+
+// EnqueueEvent writes an event to the outbox within tx, so it is published if and only if the change
+// it describes is committed.
+func EnqueueEvent(ctx context.Context, tx *sql.Tx, event OutboxEvent) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO event_outbox (event_id, event_type, partition_key, envelope, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, event.EventID, event.EventType, event.PartitionKey, event.Envelope, event.CreatedAt)
+	return err
+}
+
+// HTTPEventPublisher publishes events by POSTing their envelope to a broker endpoint, with the event ID
+// in the Idempotency-Key header so the broker can drop redelivered events.
+type HTTPEventPublisher struct {
+	url    string
+	client *http.Client
+	keys   *WebhookKeyStore
+	now    func() time.Time
+}
+
+// NewHTTPEventPublisher creates a publisher posting to url.
+func NewHTTPEventPublisher(url string, timeout time.Duration) *HTTPEventPublisher {
+	return &HTTPEventPublisher{url: url, client: &http.Client{Timeout: timeout}, now: time.Now}
+}
+
+// SignWith makes the publisher sign every delivery with the active signing keys of the broker in keys, in the
+// WebhookSignatureHeader. Each attempt is signed anew, so a retried delivery carries a fresh timestamp and the
+// keys active at the time. Deliveries stay unsigned while the broker has no active key.
+func (p *HTTPEventPublisher) SignWith(keys *WebhookKeyStore) {
+	p.keys = keys
+}
+
+// Publish posts one event. Any response other than 2xx is an error.
+func (p *HTTPEventPublisher) Publish(ctx context.Context, event OutboxEvent) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(event.Envelope))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Idempotency-Key", event.EventID)
+	req.Header.Set("X-Event-Type", event.EventType)
+	req.Header.Set("X-Partition-Key", event.PartitionKey)
+	if p.keys != nil {
+		keys, err := p.keys.ActiveKeys(ctx, OutboxSubscriber)
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			req.Header.Set(WebhookSignatureHeader, SignWebhook(keys, p.now().Unix(), event.Envelope))
+		}
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("broker returned %s", resp.Status)
+	}
+	return nil
+}
+
+// OutboxConfig configures the outbox relay. The relay is disabled when PublishURL is empty.
+type OutboxConfig struct {
+	PublishURL string
+	Interval   time.Duration
+	BatchSize  int
+	Timeout    time.Duration
+}
+
+// NewOutboxConfigFromEnv reads OUTBOX_PUBLISH_URL, OUTBOX_INTERVAL, OUTBOX_BATCH_SIZE and OUTBOX_PUBLISH_TIMEOUT.
+// Invalid values fall back to the defaults.
+func NewOutboxConfigFromEnv() OutboxConfig {
+	config := OutboxConfig{
+		PublishURL: getEnv("OUTBOX_PUBLISH_URL", ""),
+		Interval:   DefaultOutboxInterval,
+		BatchSize:  DefaultOutboxBatchSize,
+		Timeout:    DefaultOutboxTimeout,
+	}
+	if interval, err := time.ParseDuration(getEnv("OUTBOX_INTERVAL", "")); err == nil && interval > 0 {
+		config.Interval = interval
+	}
+	if size, err := strconv.Atoi(getEnv("OUTBOX_BATCH_SIZE", "")); err == nil && size > 0 {
+		config.BatchSize = size
+	}
+	if timeout, err := time.ParseDuration(getEnv("OUTBOX_PUBLISH_TIMEOUT", "")); err == nil && timeout > 0 {
+		config.Timeout = timeout
+	}
+	return config
+}
+
+// Enabled reports whether events should be written to the outbox and relayed.
+func (c OutboxConfig) Enabled() bool {
+	return c.PublishURL != ""
+}
+
+// OutboxStats reports the progress of the relay. Lag is the age of the oldest unpublished event
+// as of the last run, and Pending the number of unpublished events.
+type OutboxStats struct {
+	Published       int64
+	Failed          int64
+	Pending         int64
+	Lag             time.Duration
+	LastRun         time.Time
+	LastPublishedAt time.Time
+	LastError       string
+}
+
+// OutboxRelay publishes the events of the event_outbox table in order.
+//
+// Each run claims a batch of unpublished events with FOR UPDATE SKIP LOCKED, so several replicas can run
+// the relay without publishing the same event concurrently, publishes them one by one, marks the published
+// ones and commits. An event is only marked once the broker has accepted it; if the relay stops before the
+// commit, the events are published again and the broker drops them by their event ID. Publishing stops at
+// the first failure, so an event is never published ahead of an earlier one of the same partition key.
+type OutboxRelay struct {
+	db        *sql.DB
+	logger    *Logger
+	publisher EventPublisher
+	batchSize int
+	now       func() time.Time
+
+	mu    sync.Mutex
+	stats OutboxStats
+}
+
+// NewOutboxRelay creates a relay publishing batches of up to batchSize events.
+func NewOutboxRelay(db *sql.DB, logger *Logger, publisher EventPublisher, batchSize int) *OutboxRelay {
+	if batchSize <= 0 {
+		batchSize = DefaultOutboxBatchSize
+	}
+	return &OutboxRelay{
+		db:        db,
+		logger:    logger,
+		publisher: publisher,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+// Run relays events on every interval until ctx is cancelled. A full batch is followed immediately
+// by the next one, so a backlog is drained without waiting for the interval.
+// It is intended to be run in its own goroutine.
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := r.RelayOnce(ctx)
+		if err != nil {
+			r.logger.Error("Outbox relay failed: %v", err)
+		}
+		if err == nil && published == r.batchSize {
+			continue
+		}
+		if err := r.updateLag(ctx); err != nil {
+			r.logger.Error("Outbox lag lookup failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce claims and publishes one batch of events, returning how many were published.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	var published int
+	var publishErr error
+	err := WithTransaction(ctx, r.db, func(tx *sql.Tx) error {
+		events, err := r.claim(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if publishErr = r.publisher.Publish(ctx, event); publishErr != nil {
+				r.logger.Warn("Outbox publish failed: EventID=%s, EventType=%s, Error=%v", event.EventID, event.EventType, publishErr)
+				return r.recordFailure(ctx, tx, event, publishErr)
+			}
+			if err := r.markPublished(ctx, tx, event); err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	if err != nil {
+		published = 0
+	}
+	r.record(published, publishErr, err)
+	if err == nil && publishErr != nil {
+		err = fmt.Errorf("publish failed: %w", publishErr)
+	}
+	return published, err
+}
+
+// Stats returns a snapshot of the relay statistics.
+func (r *OutboxRelay) Stats() OutboxStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// claim locks the oldest unpublished events that no other relay has claimed.
+func (r *OutboxRelay) claim(ctx context.Context, tx *sql.Tx) ([]OutboxEvent, error) {
+	start := time.Now()
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, event_id, event_type, partition_key, envelope, created_at
+		FROM event_outbox
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, r.batchSize)
+	r.logger.LogDatabase("SELECT", "event_outbox", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []OutboxEvent
+	for rows.Next() {
+		var event OutboxEvent
+		if err := rows.Scan(&event.ID, &event.EventID, &event.EventType, &event.PartitionKey, &event.Envelope, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// markPublished records that the broker accepted an event.
+func (r *OutboxRelay) markPublished(ctx context.Context, tx *sql.Tx, event OutboxEvent) error {
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, `
+		UPDATE event_outbox SET published_at = $1, attempts = attempts + 1, last_error = NULL WHERE id = $2
+	`, r.now().Unix(), event.ID)
+	r.logger.LogDatabase("UPDATE", "event_outbox", time.Since(start), err)
+	return err
+}
+
+// recordFailure records a failed publish attempt of an event, which is retried on the next run.
+func (r *OutboxRelay) recordFailure(ctx context.Context, tx *sql.Tx, event OutboxEvent, publishErr error) error {
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, `
+		UPDATE event_outbox SET attempts = attempts + 1, last_error = $1 WHERE id = $2
+	`, publishErr.Error(), event.ID)
+	r.logger.LogDatabase("UPDATE", "event_outbox", time.Since(start), err)
+	return err
+}
+
+// updateLag refreshes the pending count and the age of the oldest unpublished event.
+func (r *OutboxRelay) updateLag(ctx context.Context) error {
+	var pending int64
+	var oldest sql.NullInt64
+	start := time.Now()
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), MIN(created_at) FROM event_outbox WHERE published_at IS NULL
+	`).Scan(&pending, &oldest)
+	r.logger.LogDatabase("SELECT", "event_outbox", time.Since(start), err)
+	if err != nil {
+		return err
+	}
+
+	var lag time.Duration
+	if oldest.Valid {
+		lag = r.now().Sub(time.Unix(oldest.Int64, 0))
+		if lag < 0 {
+			lag = 0
+		}
+	}
+
+	r.mu.Lock()
+	r.stats.Pending = pending
+	r.stats.Lag = lag
+	r.mu.Unlock()
+
+	if pending > 0 {
+		r.logger.Info("Outbox lag: Pending=%d, OldestAge=%s", pending, lag)
+	}
+	return nil
+}
+
+// record updates the statistics after a run.
+func (r *OutboxRelay) record(published int, publishErr, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats.LastRun = r.now()
+	r.stats.Published += int64(published)
+	if published > 0 {
+		r.stats.LastPublishedAt = r.stats.LastRun
+	}
+	r.stats.LastError = ""
+	switch {
+	case err != nil:
+		r.stats.LastError = err.Error()
+	case publishErr != nil:
+		r.stats.Failed++
+		r.stats.LastError = publishErr.Error()
+	}
+}
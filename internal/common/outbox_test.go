@@ -0,0 +1,179 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePublisher records published events and fails those listed in failures.
+type fakePublisher struct {
+	published []string
+	failures  map[string]error
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, event OutboxEvent) error {
+	if err := p.failures[event.EventID]; err != nil {
+		return err
+	}
+	p.published = append(p.published, event.EventID)
+	return nil
+}
+
+func newTestOutboxRelay(t *testing.T, publisher EventPublisher) (*OutboxRelay, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	logger, err := NewLogger("test-outbox", INFO)
+	require.NoError(t, err)
+	t.Cleanup(func() { logger.Close() })
+
+	relay := NewOutboxRelay(db, logger, publisher, 10)
+	relay.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return relay, mock
+}
+
+func outboxRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "event_id", "event_type", "partition_key", "envelope", "created_at"}).
+		AddRow(1, "event-1", "transaction.created", "acc-1", []byte("e1"), 1699999990).
+		AddRow(2, "event-2", "transaction.created", "acc-1", []byte("e2"), 1699999995).
+		AddRow(3, "event-3", "transaction.created", "acc-2", []byte("e3"), 1699999998)
+}
+
+func TestOutboxRelay_PublishesThenMarks(t *testing.T) {
+	publisher := &fakePublisher{}
+	relay, mock := newTestOutboxRelay(t, publisher)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, event_id, event_type, partition_key, envelope, created_at\s+FROM event_outbox\s+WHERE published_at IS NULL\s+ORDER BY id\s+LIMIT \$1\s+FOR UPDATE SKIP LOCKED`).
+		WithArgs(10).
+		WillReturnRows(outboxRows())
+	for id := 1; id <= 3; id++ {
+		mock.ExpectExec(`UPDATE event_outbox SET published_at = \$1, attempts = attempts \+ 1, last_error = NULL WHERE id = \$2`).
+			WithArgs(int64(1700000000), int64(id)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, published)
+	assert.Equal(t, []string{"event-1", "event-2", "event-3"}, publisher.published)
+
+	stats := relay.Stats()
+	assert.Equal(t, int64(3), stats.Published)
+	assert.Equal(t, time.Unix(1700000000, 0), stats.LastPublishedAt)
+	assert.Empty(t, stats.LastError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRelay_StopsAtFirstFailure(t *testing.T) {
+	publisher := &fakePublisher{failures: map[string]error{"event-2": errors.New("broker unavailable")}}
+	relay, mock := newTestOutboxRelay(t, publisher)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM event_outbox`).WillReturnRows(outboxRows())
+	mock.ExpectExec(`UPDATE event_outbox SET published_at`).
+		WithArgs(int64(1700000000), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE event_outbox SET attempts = attempts \+ 1, last_error = \$1 WHERE id = \$2`).
+		WithArgs("broker unavailable", int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	published, err := relay.RelayOnce(context.Background())
+	assert.EqualError(t, err, "publish failed: broker unavailable")
+	assert.Equal(t, 1, published)
+	// event-3 is left for the next run so it is not published ahead of event-2
+	assert.Equal(t, []string{"event-1"}, publisher.published)
+
+	stats := relay.Stats()
+	assert.Equal(t, int64(1), stats.Published)
+	assert.Equal(t, int64(1), stats.Failed)
+	assert.Equal(t, "broker unavailable", stats.LastError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRelay_CommitFailureCountsNothing(t *testing.T) {
+	relay, mock := newTestOutboxRelay(t, &fakePublisher{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM event_outbox`).WillReturnRows(outboxRows())
+	for id := 1; id <= 3; id++ {
+		mock.ExpectExec(`UPDATE event_outbox SET published_at`).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit().WillReturnError(errors.New("connection reset"))
+
+	// The events stay unpublished in the table and are published again, for the broker to deduplicate
+	published, err := relay.RelayOnce(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 0, published)
+	assert.Equal(t, int64(0), relay.Stats().Published)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutboxRelay_UpdateLag(t *testing.T) {
+	relay, mock := newTestOutboxRelay(t, &fakePublisher{})
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\), MIN\(created_at\) FROM event_outbox WHERE published_at IS NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(7, 1699999940))
+	require.NoError(t, relay.updateLag(context.Background()))
+	assert.Equal(t, int64(7), relay.Stats().Pending)
+	assert.Equal(t, time.Minute, relay.Stats().Lag)
+
+	mock.ExpectQuery(`FROM event_outbox WHERE published_at IS NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(0, nil))
+	require.NoError(t, relay.updateLag(context.Background()))
+	assert.Equal(t, int64(0), relay.Stats().Pending)
+	assert.Equal(t, time.Duration(0), relay.Stats().Lag)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHTTPEventPublisher(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	publisher := NewHTTPEventPublisher(server.URL, time.Second)
+	event := OutboxEvent{EventID: "event-1", EventType: "transaction.created", PartitionKey: "acc-1", Envelope: []byte("envelope")}
+
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "event-1", received.Header.Get("Idempotency-Key"))
+	assert.Equal(t, "transaction.created", received.Header.Get("X-Event-Type"))
+	assert.Equal(t, "acc-1", received.Header.Get("X-Partition-Key"))
+	assert.Equal(t, []byte("envelope"), body)
+
+	status = http.StatusServiceUnavailable
+	assert.EqualError(t, publisher.Publish(context.Background(), event), "broker returned 503 Service Unavailable")
+}
+
+func TestNewOutboxConfigFromEnv(t *testing.T) {
+	config := NewOutboxConfigFromEnv()
+	assert.False(t, config.Enabled())
+	assert.Equal(t, DefaultOutboxInterval, config.Interval)
+
+	t.Setenv("OUTBOX_PUBLISH_URL", "http://broker/events")
+	t.Setenv("OUTBOX_INTERVAL", "5s")
+	t.Setenv("OUTBOX_BATCH_SIZE", "invalid")
+	config = NewOutboxConfigFromEnv()
+	assert.True(t, config.Enabled())
+	assert.Equal(t, 5*time.Second, config.Interval)
+	assert.Equal(t, DefaultOutboxBatchSize, config.BatchSize)
+	assert.Equal(t, DefaultOutboxTimeout, config.Timeout)
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageTokenSigner_RoundTrip(t *testing.T) {
+	signer := NewPageTokenSigner([]byte("test-secret"))
+	filter := PageFilterHash("account-1")
+	cursor := PageCursor{CreatedAt: 1700000000, ID: "tx-42"}
+
+	token := signer.Encode(cursor, filter)
+	assert.NotContains(t, token, "tx-42")
+
+	decoded, err := signer.Decode(token, filter)
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+}
+
+func TestPageTokenSigner_Rejects(t *testing.T) {
+	signer := NewPageTokenSigner([]byte("test-secret"))
+	filter := PageFilterHash("account-1")
+	token := signer.Encode(PageCursor{CreatedAt: 1700000000, ID: "tx-42"}, filter)
+	payload, signature, _ := strings.Cut(token, ".")
+
+	// Re-encode a forged cursor under the original signature
+	forged := NewPageTokenSigner([]byte("other-secret")).Encode(PageCursor{CreatedAt: 1, ID: "tx-1"}, filter)
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name     string
+		token    string
+		filter   string
+		expected error
+	}{
+		{name: "malformed", token: "not-a-token", filter: filter, expected: ErrInvalidPageToken},
+		{name: "bad signature encoding", token: payload + ".!!!", filter: filter, expected: ErrInvalidPageToken},
+		{name: "tampered payload", token: forgedPayload + "." + signature, filter: filter, expected: ErrInvalidPageToken},
+		{name: "signed with another key", token: forged, filter: filter, expected: ErrInvalidPageToken},
+		{name: "different filters", token: token, filter: PageFilterHash("account-2"), expected: ErrPageTokenFilterMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := signer.Decode(tt.token, tt.filter)
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestPageFilterHash(t *testing.T) {
+	assert.Equal(t, PageFilterHash("a", "b"), PageFilterHash("a", "b"))
+	assert.NotEqual(t, PageFilterHash("ab", ""), PageFilterHash("a", "b"))
+}
+
+func TestNewPageTokenSignerFromEnv(t *testing.T) {
+	t.Setenv("PAGE_TOKEN_SECRET", "shared-secret")
+	filter := PageFilterHash("account-1")
+	token := NewPageTokenSignerFromEnv().Encode(PageCursor{CreatedAt: 1, ID: "tx-1"}, filter)
+
+	// Replicas sharing the secret accept each other's tokens
+	_, err := NewPageTokenSignerFromEnv().Decode(token, filter)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,261 @@
+package common
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TrafficClassMetadataKey is the gRPC metadata key callers use to tag a call as interactive or batch traffic.
+const TrafficClassMetadataKey = "x-traffic-class"
+
+// Traffic classes of a call. Interactive calls serve a customer waiting on the answer; batch calls are bulk
+// work such as imports and exports.
+const (
+	TrafficInteractive = "interactive"
+	TrafficBatch       = "batch"
+)
+
+// Defaults of the priority scheduler: the calls served at once, and how many interactive calls are admitted
+// for every batch call while both are waiting.
+const (
+	DefaultMaxConcurrentCalls = 64
+	DefaultInteractiveWeight  = 4
+)
+
+// Keys of the runtime configuration's rate_limits section for the priority scheduler.
+const (
+	RateLimitMaxConcurrentKey     = "max_concurrent_calls"
+	RateLimitInteractiveWeightKey = "interactive_weight"
+)
+
+// batchMethods are the bulk methods, which are always batch traffic whatever their caller claims.
+var batchMethods = map[string]bool{
+	"/transaction.TransactionService/IngestTransactions":          true,
+	"/transaction.TransactionService/ExportTransactionHistory":    true,
+	"/transaction.TransactionService/StreamTransactions":          true,
+	"/transaction.TransactionService/ImportChargebacks":           true,
+	"/transaction.TransactionService/ReconcileSettlement":         true,
+	"/transaction.TransactionService/CreateBatch":                 true,
+	"/transaction.TransactionAnalyticsService/StreamTransactions": true,
+	"/account.AccountService/CreateAccounts":                      true,
+	"/account.AccountAnalyticsService/StreamAccounts":             true,
+}
+
+// ValidTrafficClass reports whether class is a traffic class callers may tag calls with.
+func ValidTrafficClass(class string) bool {
+	return class == TrafficInteractive || class == TrafficBatch
+}
+
+// TrafficClass returns the traffic class of a call to method: batch for the bulk methods and the calls tagged
+// batch in the x-traffic-class metadata, interactive otherwise.
+func TrafficClass(ctx context.Context, method string) string {
+	if batchMethods[method] {
+		return TrafficBatch
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(TrafficClassMetadataKey); len(values) > 0 && values[0] == TrafficBatch {
+			return TrafficBatch
+		}
+	}
+	return TrafficInteractive
+}
+
+// PriorityScheduler bounds the calls a replica serves at once and, when calls have to wait for a slot, admits
+// interactive ones ahead of batch ones, so bulk imports and exports cannot starve customer-facing calls such as
+// CreateTransaction and GetBalance. While both kinds are waiting, weight interactive calls are admitted for
+// every batch call, so batch traffic still progresses under sustained load. Batch calls never hold more than
+// one slot in weight+1, as a stream can hold its slot for minutes. A limit of zero disables the scheduling.
+// Limits are per replica. It is safe for concurrent use.
+type PriorityScheduler struct {
+	mu          sync.Mutex
+	limit       int
+	weight      int
+	active      int
+	activeBatch int
+	interactive []chan struct{}
+	batch       []chan struct{}
+	streak      int
+}
+
+// NewPriorityScheduler creates a scheduler serving limit calls at once with the given interactive weight;
+// a weight below one is raised to one.
+func NewPriorityScheduler(limit, weight int) *PriorityScheduler {
+	s := &PriorityScheduler{}
+	s.SetLimits(limit, weight)
+	return s
+}
+
+// NewPrioritySchedulerFromEnv creates a scheduler from MAX_CONCURRENT_CALLS and INTERACTIVE_WEIGHT,
+// defaulting to DefaultMaxConcurrentCalls and DefaultInteractiveWeight.
+func NewPrioritySchedulerFromEnv() *PriorityScheduler {
+	limit, err := strconv.Atoi(getEnv("MAX_CONCURRENT_CALLS", strconv.Itoa(DefaultMaxConcurrentCalls)))
+	if err != nil || limit < 0 {
+		limit = DefaultMaxConcurrentCalls
+	}
+	weight, err := strconv.Atoi(getEnv("INTERACTIVE_WEIGHT", strconv.Itoa(DefaultInteractiveWeight)))
+	if err != nil || weight < 1 {
+		weight = DefaultInteractiveWeight
+	}
+	return NewPriorityScheduler(limit, weight)
+}
+
+// SetLimits replaces the limit and weight, e.g. after a runtime config reload. Calls in flight keep their
+// slots; waiting calls are admitted if the limit was raised.
+func (s *PriorityScheduler) SetLimits(limit, weight int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = limit
+	s.weight = max(1, weight)
+	s.admit()
+}
+
+// Limits returns the current limit and weight.
+func (s *PriorityScheduler) Limits() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limit, s.weight
+}
+
+// ApplyRuntimeConfig updates the limits from the max_concurrent_calls and interactive_weight entries of the
+// runtime configuration's rate_limits, keeping current values for unset keys.
+func (s *PriorityScheduler) ApplyRuntimeConfig(config *RuntimeConfig) {
+	limit, weight := s.Limits()
+	s.SetLimits(
+		int(config.RateLimit(RateLimitMaxConcurrentKey, float64(limit))),
+		int(config.RateLimit(RateLimitInteractiveWeightKey, float64(weight))),
+	)
+}
+
+// Acquire waits for a slot for a call of the given class, and returns the function releasing it. It fails
+// with ctx's error if ctx is done first.
+func (s *PriorityScheduler) Acquire(ctx context.Context, class string) (func(), error) {
+	s.mu.Lock()
+	ready := make(chan struct{})
+	if class == TrafficBatch {
+		s.batch = append(s.batch, ready)
+	} else {
+		s.interactive = append(s.interactive, ready)
+	}
+	s.admit()
+	s.mu.Unlock()
+
+	release := func() { s.release(class) }
+	select {
+	case <-ready:
+		return release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.dequeue(class, ready) {
+			return nil, ctx.Err()
+		}
+		// Admitted while giving up: hand the slot on
+		s.releaseLocked(class)
+		return nil, ctx.Err()
+	}
+}
+
+// release frees the slot of a call of the given class.
+func (s *PriorityScheduler) release(class string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releaseLocked(class)
+}
+
+// releaseLocked is release with s.mu held.
+func (s *PriorityScheduler) releaseLocked(class string) {
+	s.active--
+	if class == TrafficBatch {
+		s.activeBatch--
+	}
+	s.admit()
+}
+
+// dequeue removes a waiting call, and reports whether it was still waiting.
+func (s *PriorityScheduler) dequeue(class string, ready chan struct{}) bool {
+	queue := &s.interactive
+	if class == TrafficBatch {
+		queue = &s.batch
+	}
+	for i, waiting := range *queue {
+		if waiting == ready {
+			*queue = append((*queue)[:i], (*queue)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// admit gives free slots to waiting calls, in their order within each class, weighting interactive calls
+// over batch ones.
+func (s *PriorityScheduler) admit() {
+	for len(s.interactive) > 0 || len(s.batch) > 0 {
+		if s.limit > 0 && s.active >= s.limit {
+			return
+		}
+		batchAllowed := len(s.batch) > 0 && (s.limit <= 0 || s.activeBatch < s.batchLimit())
+		switch {
+		case len(s.interactive) > 0 && (!batchAllowed || s.streak < s.weight):
+			close(s.interactive[0])
+			s.interactive = s.interactive[1:]
+			s.active++
+			if batchAllowed {
+				s.streak++
+			}
+		case batchAllowed:
+			close(s.batch[0])
+			s.batch = s.batch[1:]
+			s.active++
+			s.activeBatch++
+			s.streak = 0
+		default:
+			return
+		}
+	}
+}
+
+// batchLimit returns the slots batch calls may hold: one in weight+1, at least one.
+func (s *PriorityScheduler) batchLimit() int {
+	return max(1, s.limit/(s.weight+1))
+}
+
+// PriorityUnaryServerInterceptor returns a server interceptor running each call once the scheduler admits it.
+// Calls whose deadline passes while waiting fail with DEADLINE_EXCEEDED. Health checks are not scheduled.
+func PriorityUnaryServerInterceptor(scheduler *PriorityScheduler, logger *Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if rateLimitExempt(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		class := TrafficClass(ctx, info.FullMethod)
+		release, err := scheduler.Acquire(ctx, class)
+		if err != nil {
+			logger.WithContext(ctx).Warn("Call gave up waiting for a slot: Method=%s, Class=%s", info.FullMethod, class)
+			return nil, status.FromContextError(err).Err()
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+// PriorityStreamServerInterceptor returns a stream interceptor holding a slot of the scheduler for the whole
+// stream.
+func PriorityStreamServerInterceptor(scheduler *PriorityScheduler, logger *Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if rateLimitExempt(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		class := TrafficClass(ss.Context(), info.FullMethod)
+		release, err := scheduler.Acquire(ss.Context(), class)
+		if err != nil {
+			logger.WithContext(ss.Context()).Warn("Stream gave up waiting for a slot: Method=%s, Class=%s", info.FullMethod, class)
+			return status.FromContextError(err).Err()
+		}
+		defer release()
+		return handler(srv, ss)
+	}
+}
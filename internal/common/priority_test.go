@@ -0,0 +1,161 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// acquireAsync starts acquiring a slot of class and returns a channel receiving the release function once
+// the slot is granted.
+func acquireAsync(t *testing.T, s *PriorityScheduler, class string) chan func() {
+	t.Helper()
+	granted := make(chan func(), 1)
+	go func() {
+		release, err := s.Acquire(context.Background(), class)
+		if err == nil {
+			granted <- release
+		}
+	}()
+	return granted
+}
+
+// waitQueued waits until the scheduler has the given number of waiting calls.
+func waitQueued(t *testing.T, s *PriorityScheduler, interactive, batch int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.interactive) == interactive && len(s.batch) == batch
+	}, time.Second, time.Millisecond)
+}
+
+func TestTrafficClass(t *testing.T) {
+	batchCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(TrafficClassMetadataKey, TrafficBatch))
+	interactiveCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(TrafficClassMetadataKey, TrafficInteractive))
+
+	assert.Equal(t, TrafficInteractive, TrafficClass(context.Background(), "/account.AccountService/GetBalance"))
+	assert.Equal(t, TrafficBatch, TrafficClass(batchCtx, "/transaction.TransactionService/CreateTransaction"))
+	assert.Equal(t, TrafficBatch, TrafficClass(context.Background(), "/transaction.TransactionService/IngestTransactions"))
+
+	// Bulk methods cannot jump the queue by claiming to be interactive
+	assert.Equal(t, TrafficBatch, TrafficClass(interactiveCtx, "/transaction.TransactionService/StreamTransactions"))
+}
+
+func TestPriorityScheduler_InteractiveFirst(t *testing.T) {
+	s := NewPriorityScheduler(1, 2)
+
+	release, err := s.Acquire(context.Background(), TrafficInteractive)
+	require.NoError(t, err)
+
+	batch := acquireAsync(t, s, TrafficBatch)
+	waitQueued(t, s, 0, 1)
+	first := acquireAsync(t, s, TrafficInteractive)
+	waitQueued(t, s, 1, 1)
+	second := acquireAsync(t, s, TrafficInteractive)
+	waitQueued(t, s, 2, 1)
+	third := acquireAsync(t, s, TrafficInteractive)
+	waitQueued(t, s, 3, 1)
+
+	// Two interactive calls are admitted ahead of the batch call that was waiting before them, then the batch call
+	release()
+	(<-first)()
+	(<-second)()
+	(<-batch)()
+	(<-third)()
+}
+
+func TestPriorityScheduler_BatchShare(t *testing.T) {
+	s := NewPriorityScheduler(4, 3)
+
+	// Batch calls hold at most one slot in four, leaving the others to interactive calls
+	release, err := s.Acquire(context.Background(), TrafficBatch)
+	require.NoError(t, err)
+	batch := acquireAsync(t, s, TrafficBatch)
+	waitQueued(t, s, 0, 1)
+
+	_, err = s.Acquire(context.Background(), TrafficInteractive)
+	require.NoError(t, err)
+	select {
+	case <-batch:
+		t.Fatal("batch call admitted over its share")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	release()
+	(<-batch)()
+}
+
+func TestPriorityScheduler_Cancelled(t *testing.T) {
+	s := NewPriorityScheduler(1, 1)
+	release, err := s.Acquire(context.Background(), TrafficInteractive)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = s.Acquire(ctx, TrafficBatch)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	waitQueued(t, s, 0, 0)
+
+	// The slot is handed on once released
+	release()
+	release, err = s.Acquire(context.Background(), TrafficBatch)
+	require.NoError(t, err)
+	release()
+}
+
+func TestPriorityScheduler_Disabled(t *testing.T) {
+	s := NewPriorityScheduler(0, 1)
+	for i := 0; i < 10; i++ {
+		_, err := s.Acquire(context.Background(), TrafficBatch)
+		require.NoError(t, err)
+	}
+}
+
+func TestPriorityScheduler_ApplyRuntimeConfig(t *testing.T) {
+	s := NewPriorityScheduler(64, 4)
+
+	s.ApplyRuntimeConfig(&RuntimeConfig{RateLimits: map[string]float64{RateLimitInteractiveWeightKey: 9}})
+
+	limit, weight := s.Limits()
+	assert.Equal(t, 64, limit)
+	assert.Equal(t, 9, weight)
+}
+
+func TestPriorityUnaryServerInterceptor(t *testing.T) {
+	logger, err := NewLogger("test-priority", INFO)
+	require.NoError(t, err)
+	defer logger.Close()
+
+	s := NewPriorityScheduler(1, 1)
+	interceptor := PriorityUnaryServerInterceptor(s, logger)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/account.AccountService/GetBalance"}
+
+	resp, err := interceptor(context.Background(), nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	release, err := s.Acquire(context.Background(), TrafficBatch)
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = interceptor(ctx, nil, info, handler)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// Health checks are never queued
+	healthInfo := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+	_, err = interceptor(context.Background(), nil, healthInfo, handler)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,241 @@
+package common
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Rate limit keys used in the runtime configuration's rate_limits section.
+const (
+	RateLimitGlobalKey    = "global"
+	RateLimitPerCallerKey = "per_caller"
+)
+
+// CallerIDMetadataKey is the gRPC metadata key internal callers use to identify themselves for rate limiting.
+const CallerIDMetadataKey = "x-caller-id"
+
+// maxTrackedCallers bounds the number of per-caller buckets kept in memory before idle ones are evicted.
+const maxTrackedCallers = 10000
+
+// tokenBucket is a token bucket refilled continuously at the configured rate.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// info reports the bucket's state after the last call to allow.
+func (b *tokenBucket) info(rate, burst float64) *RateLimitInfo {
+	return &RateLimitInfo{
+		Limit:     int(burst),
+		Remaining: int(math.Floor(b.tokens)),
+		Reset:     int(math.Ceil((burst - b.tokens) / rate)),
+	}
+}
+
+// allow refills the bucket for the time elapsed since the last call and takes a token if one is available.
+func (b *tokenBucket) allow(now time.Time, rate, burst float64) bool {
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// RateLimiter enforces a global and a per-caller request rate using token buckets.
+// Rates are expressed in requests per second; a rate of zero disables that limit.
+type RateLimiter struct {
+	mu         sync.Mutex
+	globalRate float64
+	callerRate float64
+	global     *tokenBucket
+	callers    map[string]*tokenBucket
+	now        func() time.Time
+}
+
+// NewRateLimiter creates a rate limiter with the given global and per-caller rates.
+func NewRateLimiter(globalRate, callerRate float64) *RateLimiter {
+	rl := &RateLimiter{
+		callers: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+	rl.SetRates(globalRate, callerRate)
+	return rl
+}
+
+// NewRateLimiterFromEnv creates a rate limiter from the RATE_LIMIT_GLOBAL_QPS and RATE_LIMIT_CALLER_QPS
+// environment variables, defaulting to 1000 and 200 requests per second respectively.
+func NewRateLimiterFromEnv() *RateLimiter {
+	globalRate, err := strconv.ParseFloat(getEnv("RATE_LIMIT_GLOBAL_QPS", "1000"), 64)
+	if err != nil || globalRate < 0 {
+		globalRate = 1000
+	}
+	callerRate, err := strconv.ParseFloat(getEnv("RATE_LIMIT_CALLER_QPS", "200"), 64)
+	if err != nil || callerRate < 0 {
+		callerRate = 200
+	}
+	return NewRateLimiter(globalRate, callerRate)
+}
+
+// SetRates replaces the global and per-caller rates, e.g. after a runtime config reload.
+// Existing buckets keep their tokens so in-flight bursts are not reset.
+func (rl *RateLimiter) SetRates(globalRate, callerRate float64) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.globalRate = globalRate
+	rl.callerRate = callerRate
+	if rl.global == nil {
+		rl.global = &tokenBucket{tokens: burstFor(globalRate), last: rl.now()}
+	}
+}
+
+// Rates returns the current global and per-caller rates.
+func (rl *RateLimiter) Rates() (float64, float64) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.globalRate, rl.callerRate
+}
+
+// ApplyRuntimeConfig updates the rates from the runtime configuration, keeping current values for unset keys.
+func (rl *RateLimiter) ApplyRuntimeConfig(config *RuntimeConfig) {
+	globalRate, callerRate := rl.Rates()
+	rl.SetRates(
+		config.RateLimit(RateLimitGlobalKey, globalRate),
+		config.RateLimit(RateLimitPerCallerKey, callerRate),
+	)
+}
+
+// Allow reports whether a request from the given caller may proceed, consuming a token from
+// both the caller's bucket and the global bucket when it does.
+func (rl *RateLimiter) Allow(caller string) bool {
+	allowed, _ := rl.Take(caller)
+	return allowed
+}
+
+// Take is like Allow but also reports the state of the bucket that applies to the caller:
+// the bucket that rejected the request, otherwise the caller's bucket, otherwise the global one.
+// The returned info is nil when both limits are disabled.
+func (rl *RateLimiter) Take(caller string) (bool, *RateLimitInfo) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	var info *RateLimitInfo
+
+	if rl.callerRate > 0 {
+		bucket, ok := rl.callers[caller]
+		if !ok {
+			if len(rl.callers) >= maxTrackedCallers {
+				rl.evictIdle(now)
+			}
+			bucket = &tokenBucket{tokens: burstFor(rl.callerRate), last: now}
+			rl.callers[caller] = bucket
+		}
+		allowed := bucket.allow(now, rl.callerRate, burstFor(rl.callerRate))
+		info = bucket.info(rl.callerRate, burstFor(rl.callerRate))
+		if !allowed {
+			return false, info
+		}
+	}
+
+	if rl.globalRate > 0 {
+		allowed := rl.global.allow(now, rl.globalRate, burstFor(rl.globalRate))
+		if !allowed {
+			return false, rl.global.info(rl.globalRate, burstFor(rl.globalRate))
+		}
+		if info == nil {
+			info = rl.global.info(rl.globalRate, burstFor(rl.globalRate))
+		}
+	}
+
+	return true, info
+}
+
+// evictIdle drops per-caller buckets that have been idle long enough to be full again.
+func (rl *RateLimiter) evictIdle(now time.Time) {
+	for caller, bucket := range rl.callers {
+		if now.Sub(bucket.last) > time.Minute {
+			delete(rl.callers, caller)
+		}
+	}
+}
+
+// burstFor returns the bucket capacity for a rate: one second's worth of requests, at least one.
+func burstFor(rate float64) float64 {
+	return math.Max(1, rate)
+}
+
+// CallerFromContext identifies the caller of a gRPC request.
+// It prefers the x-caller-id metadata and falls back to the peer's host address.
+func CallerFromContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(CallerIDMetadataKey); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr := p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			return host
+		}
+		return addr
+	}
+	return "unknown"
+}
+
+// rateLimitExempt reports whether a method bypasses rate limiting.
+// Health checks are exempt so load balancers never mark a busy replica as unhealthy.
+func rateLimitExempt(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.") || strings.HasPrefix(method, "/health.")
+}
+
+// RateLimitUnaryServerInterceptor returns a server interceptor rejecting calls over the limit with RESOURCE_EXHAUSTED.
+// The caller's bucket state is sent in the x-ratelimit-* response header metadata on every limited call.
+func RateLimitUnaryServerInterceptor(limiter *RateLimiter, logger *Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !rateLimitExempt(info.FullMethod) {
+			caller := CallerFromContext(ctx)
+			allowed, limitInfo := limiter.Take(caller)
+			if limitInfo != nil {
+				// Best effort: there is no transport stream when the interceptor is invoked directly
+				_ = grpc.SetHeader(ctx, limitInfo.Metadata())
+			}
+			if !allowed {
+				logger.WithContext(ctx).Warn("Rate limit exceeded: Method=%s, Caller=%s", info.FullMethod, caller)
+				return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded")
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamServerInterceptor returns a stream interceptor that applies the limit when a stream is opened.
+func RateLimitStreamServerInterceptor(limiter *RateLimiter, logger *Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !rateLimitExempt(info.FullMethod) {
+			caller := CallerFromContext(ss.Context())
+			allowed, limitInfo := limiter.Take(caller)
+			if limitInfo != nil {
+				_ = ss.SetHeader(limitInfo.Metadata())
+			}
+			if !allowed {
+				logger.WithContext(ss.Context()).Warn("Rate limit exceeded: Method=%s, Caller=%s", info.FullMethod, caller)
+				return status.Errorf(codes.ResourceExhausted, "rate limit exceeded")
+			}
+		}
+		return handler(srv, ss)
+	}
+}
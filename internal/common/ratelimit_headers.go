@@ -0,0 +1,123 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// HTTP headers describing the caller's rate limit. The same names, lower-cased, are used as gRPC metadata keys.
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// RateLimitInfo describes the state of a rate limit bucket: its capacity, the requests still allowed
+// right now, and the number of seconds until it is completely refilled.
+type RateLimitInfo struct {
+	Limit     int
+	Remaining int
+	Reset     int
+}
+
+// Metadata encodes the info as gRPC metadata.
+func (info RateLimitInfo) Metadata() metadata.MD {
+	return metadata.Pairs(
+		"x-ratelimit-limit", strconv.Itoa(info.Limit),
+		"x-ratelimit-remaining", strconv.Itoa(info.Remaining),
+		"x-ratelimit-reset", strconv.Itoa(info.Reset),
+	)
+}
+
+// SetHTTPHeaders writes the info as X-RateLimit-* response headers.
+func (info RateLimitInfo) SetHTTPHeaders(h http.Header) {
+	h.Set(RateLimitLimitHeader, strconv.Itoa(info.Limit))
+	h.Set(RateLimitRemainingHeader, strconv.Itoa(info.Remaining))
+	h.Set(RateLimitResetHeader, strconv.Itoa(info.Reset))
+}
+
+// RateLimitInfoFromMetadata decodes rate limit info sent by a service, reporting false if it is absent or malformed.
+func RateLimitInfoFromMetadata(md metadata.MD) (RateLimitInfo, bool) {
+	var info RateLimitInfo
+	for key, dest := range map[string]*int{
+		"x-ratelimit-limit":     &info.Limit,
+		"x-ratelimit-remaining": &info.Remaining,
+		"x-ratelimit-reset":     &info.Reset,
+	} {
+		values := md.Get(key)
+		if len(values) == 0 {
+			return RateLimitInfo{}, false
+		}
+		value, err := strconv.Atoi(values[0])
+		if err != nil {
+			return RateLimitInfo{}, false
+		}
+		*dest = value
+	}
+	return info, true
+}
+
+// RateLimitTracker collects the rate limit info returned by the backend calls made for one request.
+// When several calls are made it keeps the most restrictive, so clients back off on the tightest limit.
+type RateLimitTracker struct {
+	mu   sync.Mutex
+	info *RateLimitInfo
+}
+
+type rateLimitTrackerContextKey struct{}
+
+// WithRateLimitTracker returns a copy of ctx carrying a new tracker, and the tracker itself.
+func WithRateLimitTracker(ctx context.Context) (context.Context, *RateLimitTracker) {
+	tracker := &RateLimitTracker{}
+	return context.WithValue(ctx, rateLimitTrackerContextKey{}, tracker), tracker
+}
+
+// RateLimitTrackerFromContext returns the tracker carried by ctx, or nil if there is none.
+func RateLimitTrackerFromContext(ctx context.Context) *RateLimitTracker {
+	tracker, _ := ctx.Value(rateLimitTrackerContextKey{}).(*RateLimitTracker)
+	return tracker
+}
+
+// Record stores info if it is more restrictive than what was recorded so far.
+func (t *RateLimitTracker) Record(info RateLimitInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.info == nil || info.Remaining < t.info.Remaining {
+		t.info = &info
+	}
+}
+
+// Info returns the recorded info, reporting false if no backend call returned any.
+func (t *RateLimitTracker) Info() (RateLimitInfo, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.info == nil {
+		return RateLimitInfo{}, false
+	}
+	return *t.info, true
+}
+
+// RateLimitHeadersUnaryClientInterceptor returns a client interceptor that records the rate limit info
+// returned by each call into the tracker carried by the call's context, if any.
+func RateLimitHeadersUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		tracker := RateLimitTrackerFromContext(ctx)
+		if tracker == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		var header, trailer metadata.MD
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&header), grpc.Trailer(&trailer))...)
+		if info, ok := RateLimitInfoFromMetadata(metadata.Join(header, trailer)); ok {
+			tracker.Record(info)
+		}
+		return err
+	}
+}
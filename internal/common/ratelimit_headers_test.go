@@ -0,0 +1,82 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRateLimitInfo_MetadataRoundTrip(t *testing.T) {
+	info := RateLimitInfo{Limit: 200, Remaining: 17, Reset: 1}
+
+	decoded, ok := RateLimitInfoFromMetadata(info.Metadata())
+	require.True(t, ok)
+	assert.Equal(t, info, decoded)
+
+	_, ok = RateLimitInfoFromMetadata(metadata.Pairs("x-ratelimit-limit", "200"))
+	assert.False(t, ok)
+	_, ok = RateLimitInfoFromMetadata(metadata.Pairs("x-ratelimit-limit", "x", "x-ratelimit-remaining", "1", "x-ratelimit-reset", "1"))
+	assert.False(t, ok)
+
+	h := http.Header{}
+	info.SetHTTPHeaders(h)
+	assert.Equal(t, "200", h.Get(RateLimitLimitHeader))
+	assert.Equal(t, "17", h.Get(RateLimitRemainingHeader))
+	assert.Equal(t, "1", h.Get(RateLimitResetHeader))
+}
+
+func TestRateLimiter_Take(t *testing.T) {
+	rl, _ := newTestRateLimiter(100, 2)
+
+	allowed, info := rl.Take("caller-a")
+	assert.True(t, allowed)
+	assert.Equal(t, &RateLimitInfo{Limit: 2, Remaining: 1, Reset: 1}, info)
+
+	rl.Take("caller-a")
+	allowed, info = rl.Take("caller-a")
+	assert.False(t, allowed)
+	assert.Equal(t, 0, info.Remaining)
+
+	// Without limits there is nothing to report
+	rl, _ = newTestRateLimiter(0, 0)
+	allowed, info = rl.Take("caller-a")
+	assert.True(t, allowed)
+	assert.Nil(t, info)
+}
+
+func TestRateLimitHeadersUnaryClientInterceptor(t *testing.T) {
+	interceptor := RateLimitHeadersUnaryClientInterceptor()
+	invoker := func(remaining int) grpc.UnaryInvoker {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			for _, opt := range opts {
+				if header, ok := opt.(grpc.HeaderCallOption); ok {
+					*header.HeaderAddr = RateLimitInfo{Limit: 10, Remaining: remaining, Reset: 1}.Metadata()
+				}
+			}
+			return nil
+		}
+	}
+
+	ctx, tracker := WithRateLimitTracker(context.Background())
+	assert.Same(t, tracker, RateLimitTrackerFromContext(ctx))
+	assert.Nil(t, RateLimitTrackerFromContext(context.Background()))
+	_, ok := tracker.Info()
+	assert.False(t, ok)
+
+	require.NoError(t, interceptor(ctx, "/account.AccountService/GetAccount", nil, nil, nil, invoker(7)))
+	require.NoError(t, interceptor(ctx, "/transaction.TransactionService/GetTransaction", nil, nil, nil, invoker(3)))
+	require.NoError(t, interceptor(ctx, "/account.AccountService/GetBalance", nil, nil, nil, invoker(5)))
+
+	// The most restrictive limit wins
+	info, ok := tracker.Info()
+	require.True(t, ok)
+	assert.Equal(t, 3, info.Remaining)
+
+	// Calls without a tracker pass straight through
+	assert.NoError(t, interceptor(context.Background(), "/account.AccountService/GetAccount", nil, nil, nil, invoker(1)))
+}
@@ -0,0 +1,114 @@
+package common
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// fakeClock is a manually advanced clock for deterministic rate limiter tests.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestRateLimiter(globalRate, callerRate float64) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	rl := &RateLimiter{callers: make(map[string]*tokenBucket), now: clock.Now}
+	rl.SetRates(globalRate, callerRate)
+	return rl, clock
+}
+
+func TestRateLimiter_PerCallerLimit(t *testing.T) {
+	rl, clock := newTestRateLimiter(0, 2)
+
+	assert.True(t, rl.Allow("caller-a"))
+	assert.True(t, rl.Allow("caller-a"))
+	assert.False(t, rl.Allow("caller-a"))
+
+	// Other callers have their own bucket
+	assert.True(t, rl.Allow("caller-b"))
+
+	// Tokens are refilled over time
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	assert.True(t, rl.Allow("caller-a"))
+	assert.False(t, rl.Allow("caller-a"))
+}
+
+func TestRateLimiter_GlobalLimit(t *testing.T) {
+	rl, clock := newTestRateLimiter(3, 0)
+
+	assert.True(t, rl.Allow("caller-a"))
+	assert.True(t, rl.Allow("caller-b"))
+	assert.True(t, rl.Allow("caller-c"))
+	assert.False(t, rl.Allow("caller-d"))
+
+	clock.now = clock.now.Add(time.Second)
+	assert.True(t, rl.Allow("caller-d"))
+}
+
+func TestRateLimiter_Disabled(t *testing.T) {
+	rl, _ := newTestRateLimiter(0, 0)
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, rl.Allow("caller-a"))
+	}
+}
+
+func TestRateLimiter_ApplyRuntimeConfig(t *testing.T) {
+	rl, _ := newTestRateLimiter(100, 10)
+
+	rl.ApplyRuntimeConfig(&RuntimeConfig{RateLimits: map[string]float64{RateLimitPerCallerKey: 5}})
+
+	globalRate, callerRate := rl.Rates()
+	assert.Equal(t, 100.0, globalRate)
+	assert.Equal(t, 5.0, callerRate)
+}
+
+func TestCallerFromContext(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(CallerIDMetadataKey, "gateway"))
+	assert.Equal(t, "gateway", CallerFromContext(ctx))
+
+	ctx = peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234}})
+	assert.Equal(t, "10.0.0.7", CallerFromContext(ctx))
+
+	assert.Equal(t, "unknown", CallerFromContext(context.Background()))
+}
+
+func TestRateLimitUnaryServerInterceptor(t *testing.T) {
+	logger, err := NewLogger("test-ratelimit", INFO)
+	require.NoError(t, err)
+	defer logger.Close()
+
+	rl, _ := newTestRateLimiter(0, 1)
+	interceptor := RateLimitUnaryServerInterceptor(rl, logger)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(CallerIDMetadataKey, "caller-a"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/account.AccountService/GetAccount"}
+
+	resp, err := interceptor(ctx, nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(ctx, nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Health checks are never limited
+	healthInfo := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+	_, err = interceptor(ctx, nil, healthInfo, handler)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,325 @@
+package common
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults for the readiness monitor.
+const (
+	DefaultReadinessInterval = 5 * time.Second
+	readinessCheckTimeout    = 3 * time.Second
+)
+
+// Kinds of readiness dependencies, so alerts can tell a database outage from a failing backend service.
+const (
+	DependencyDatabase = "database"
+	DependencyGRPC     = "grpc"
+)
+
+// Readiness states, as used in transition logs and metrics.
+const (
+	StateReady    = "ready"
+	StateNotReady = "not_ready"
+)
+
+// ReadinessCheck checks one dependency of a service. Kind is DependencyDatabase or DependencyGRPC.
+type ReadinessCheck struct {
+	Name  string
+	Kind  string
+	Check func(ctx context.Context) error
+}
+
+// DatabaseReadinessCheck checks that the database answers a ping.
+func DatabaseReadinessCheck(db *sql.DB) ReadinessCheck {
+	return ReadinessCheck{Name: "database", Kind: DependencyDatabase, Check: db.PingContext}
+}
+
+// GRPCReadinessCheck checks that the backend behind conn reports SERVING on the standard health service.
+func GRPCReadinessCheck(name string, conn grpc.ClientConnInterface) ReadinessCheck {
+	client := healthpb.NewHealthClient(conn)
+	return ReadinessCheck{Name: name, Kind: DependencyGRPC, Check: func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return status.Errorf(codes.Unavailable, "health status %s", resp.Status)
+		}
+		return nil
+	}}
+}
+
+// ReadinessReason classifies a failed check into a reason of low cardinality for metric labels:
+// "timeout", the snake-case gRPC status code, e.g. "unavailable", or "error".
+func ReadinessReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		if s.Code() == codes.DeadlineExceeded {
+			return "timeout"
+		}
+		return toSnakeCase(s.Code().String())
+	}
+	return "error"
+}
+
+// toSnakeCase converts a CamelCase name such as "FailedPrecondition" to "failed_precondition".
+func toSnakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// dependencyState is the last known state of one dependency.
+type dependencyState struct {
+	check ReadinessCheck
+	ready bool
+	// reason and message describe the last failure while not ready
+	reason  string
+	message string
+	since   time.Time
+}
+
+// transitionKey identifies one transition counter.
+type transitionKey struct {
+	dependency string
+	kind       string
+	to         string
+	reason     string
+}
+
+// ReadinessMonitor checks the dependencies of a service periodically and records every change of their
+// readiness: it logs the transition with its reason, counts it in OpenMetrics counters by dependency, kind and
+// reason, and notifies the OnChange listeners when the service as a whole becomes ready or not ready.
+// A service is ready while all its dependencies are. Dependencies start out ready, since services only start
+// once their dependencies are up.
+type ReadinessMonitor struct {
+	service string
+	logger  *Logger
+	now     func() time.Time
+
+	mu          sync.Mutex
+	states      []*dependencyState
+	transitions map[transitionKey]uint64
+	listeners   []func(ready bool)
+}
+
+// NewReadinessMonitor creates a monitor of the given dependencies of service.
+func NewReadinessMonitor(service string, logger *Logger, checks ...ReadinessCheck) *ReadinessMonitor {
+	m := &ReadinessMonitor{
+		service:     service,
+		logger:      logger,
+		now:         time.Now,
+		transitions: make(map[transitionKey]uint64),
+	}
+	for _, check := range checks {
+		m.states = append(m.states, &dependencyState{check: check, ready: true, since: m.now()})
+	}
+	return m
+}
+
+// ReadinessIntervalFromEnv reads READINESS_CHECK_INTERVAL. Invalid values fall back to the default.
+func ReadinessIntervalFromEnv() time.Duration {
+	if interval, err := time.ParseDuration(getEnv("READINESS_CHECK_INTERVAL", "")); err == nil && interval > 0 {
+		return interval
+	}
+	return DefaultReadinessInterval
+}
+
+// OnChange registers fn to be called with the new readiness of the service whenever it changes.
+func (m *ReadinessMonitor) OnChange(fn func(ready bool)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// Ready reports whether all dependencies passed their last check.
+func (m *ReadinessMonitor) Ready() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.readyLocked()
+}
+
+func (m *ReadinessMonitor) readyLocked() bool {
+	for _, state := range m.states {
+		if !state.ready {
+			return false
+		}
+	}
+	return true
+}
+
+// Run checks the dependencies on every interval until ctx is cancelled.
+// It is intended to be run in its own goroutine.
+func (m *ReadinessMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.CheckOnce(ctx)
+		}
+	}
+}
+
+// CheckOnce checks every dependency, each with its own timeout, and records the transitions.
+func (m *ReadinessMonitor) CheckOnce(ctx context.Context) {
+	results := make([]error, len(m.states))
+	var wg sync.WaitGroup
+	for i, state := range m.states {
+		wg.Add(1)
+		go func(i int, check ReadinessCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+			defer cancel()
+			results[i] = check.Check(checkCtx)
+		}(i, state.check)
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	wasReady := m.readyLocked()
+	for i, state := range m.states {
+		m.record(state, results[i])
+	}
+	ready := m.readyLocked()
+	listeners := append([]func(bool){}, m.listeners...)
+	m.mu.Unlock()
+
+	if ready == wasReady {
+		return
+	}
+	if ready {
+		m.logger.Info("Readiness changed: Service=%s, State=%s", m.service, StateReady)
+	} else {
+		m.logger.Error("Readiness changed: Service=%s, State=%s", m.service, StateNotReady)
+	}
+	for _, fn := range listeners {
+		fn(ready)
+	}
+}
+
+// record applies the result of a check to the state of its dependency. Called with mu held.
+func (m *ReadinessMonitor) record(state *dependencyState, err error) {
+	ready := err == nil
+	if ready == state.ready {
+		if !ready {
+			state.reason, state.message = ReadinessReason(err), err.Error()
+		}
+		return
+	}
+
+	state.ready = ready
+	state.since = m.now()
+	key := transitionKey{dependency: state.check.Name, kind: state.check.Kind, to: StateReady}
+	if ready {
+		state.reason, state.message = "", ""
+		m.logger.Info("Dependency readiness changed: Service=%s, Dependency=%s, Kind=%s, State=%s",
+			m.service, state.check.Name, state.check.Kind, StateReady)
+	} else {
+		state.reason, state.message = ReadinessReason(err), err.Error()
+		key.to, key.reason = StateNotReady, state.reason
+		m.logger.Error("Dependency readiness changed: Service=%s, Dependency=%s, Kind=%s, State=%s, Reason=%s, Error=%s",
+			m.service, state.check.Name, state.check.Kind, StateNotReady, state.reason, state.message)
+	}
+	m.transitions[key]++
+}
+
+// ServeHTTP serves the readiness metrics in the OpenMetrics text format.
+func (m *ReadinessMonitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", OpenMetricsContentType)
+	if err := m.WriteOpenMetrics(w); err != nil {
+		m.logger.Error("Readiness metrics write failed: %v", err)
+	}
+}
+
+// WriteOpenMetrics writes the readiness of the service and its dependencies, the time of their last transition
+// and the transition counters to out in the OpenMetrics text format.
+func (m *ReadinessMonitor) WriteOpenMetrics(out io.Writer) error {
+	m.mu.Lock()
+	ready := m.readyLocked()
+	states := make([]dependencyState, len(m.states))
+	for i, state := range m.states {
+		states[i] = *state
+	}
+	keys := make([]transitionKey, 0, len(m.transitions))
+	counts := make(map[transitionKey]uint64, len(m.transitions))
+	for key, count := range m.transitions {
+		keys = append(keys, key)
+		counts[key] = count
+	}
+	m.mu.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].dependency != keys[j].dependency {
+			return keys[i].dependency < keys[j].dependency
+		}
+		if keys[i].to != keys[j].to {
+			return keys[i].to < keys[j].to
+		}
+		return keys[i].reason < keys[j].reason
+	})
+
+	service := quoteLabel(m.service)
+	w := bufio.NewWriter(out)
+	fmt.Fprintln(w, "# TYPE pismo_ready gauge")
+	fmt.Fprintln(w, "# HELP pismo_ready Whether all dependencies of the service passed their last check.")
+	fmt.Fprintf(w, "pismo_ready{service=%s} %d\n", service, boolGauge(ready))
+
+	fmt.Fprintln(w, "# TYPE pismo_dependency_ready gauge")
+	fmt.Fprintln(w, "# HELP pismo_dependency_ready Whether a dependency passed its last check; reason is set while it is not ready.")
+	for _, state := range states {
+		fmt.Fprintf(w, "pismo_dependency_ready{service=%s,dependency=%s,kind=%s,reason=%s} %d\n",
+			service, quoteLabel(state.check.Name), quoteLabel(state.check.Kind), quoteLabel(state.reason), boolGauge(state.ready))
+	}
+
+	fmt.Fprintln(w, "# TYPE pismo_dependency_last_transition_timestamp_seconds gauge")
+	fmt.Fprintln(w, "# HELP pismo_dependency_last_transition_timestamp_seconds When a dependency last changed readiness, or the service started.")
+	for _, state := range states {
+		fmt.Fprintf(w, "pismo_dependency_last_transition_timestamp_seconds{service=%s,dependency=%s,kind=%s} %d\n",
+			service, quoteLabel(state.check.Name), quoteLabel(state.check.Kind), state.since.Unix())
+	}
+
+	fmt.Fprintln(w, "# TYPE pismo_readiness_transitions counter")
+	fmt.Fprintln(w, "# HELP pismo_readiness_transitions Readiness changes of the dependencies, by the state entered and the reason.")
+	for _, key := range keys {
+		fmt.Fprintf(w, "pismo_readiness_transitions_total{service=%s,dependency=%s,kind=%s,to=%s,reason=%s} %d\n",
+			service, quoteLabel(key.dependency), quoteLabel(key.kind), quoteLabel(key.to), quoteLabel(key.reason), counts[key])
+	}
+	fmt.Fprintln(w, "# EOF")
+	return w.Flush()
+}
+
+// boolGauge renders a boolean as a gauge value.
+func boolGauge(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReadinessReason(t *testing.T) {
+	assert.Equal(t, "timeout", ReadinessReason(context.DeadlineExceeded))
+	assert.Equal(t, "timeout", ReadinessReason(fmt.Errorf("ping: %w", context.DeadlineExceeded)))
+	assert.Equal(t, "timeout", ReadinessReason(status.Error(codes.DeadlineExceeded, "slow")))
+	assert.Equal(t, "unavailable", ReadinessReason(status.Error(codes.Unavailable, "connection refused")))
+	assert.Equal(t, "failed_precondition", ReadinessReason(status.Error(codes.FailedPrecondition, "draining")))
+	assert.Equal(t, "error", ReadinessReason(errors.New("connection refused")))
+}
+
+func TestReadinessMonitor_Transitions(t *testing.T) {
+	logger, err := NewLogger("test-readiness", INFO)
+	require.NoError(t, err)
+
+	var databaseErr, backendErr error
+	monitor := NewReadinessMonitor("test-service", logger,
+		ReadinessCheck{Name: "database", Kind: DependencyDatabase, Check: func(ctx context.Context) error { return databaseErr }},
+		ReadinessCheck{Name: "backend", Kind: DependencyGRPC, Check: func(ctx context.Context) error { return backendErr }},
+	)
+	now := time.Unix(1700000000, 0)
+	monitor.now = func() time.Time { return now }
+
+	var changes []bool
+	monitor.OnChange(func(ready bool) { changes = append(changes, ready) })
+
+	// Ready at start; a passing check changes nothing
+	monitor.CheckOnce(context.Background())
+	assert.True(t, monitor.Ready())
+	assert.Empty(t, changes)
+
+	// The database goes down, then the backend too, then both recover
+	databaseErr = context.DeadlineExceeded
+	monitor.CheckOnce(context.Background())
+	backendErr = status.Error(codes.Unavailable, "connection refused")
+	monitor.CheckOnce(context.Background())
+	assert.False(t, monitor.Ready())
+
+	var metrics bytes.Buffer
+	require.NoError(t, monitor.WriteOpenMetrics(&metrics))
+	assert.Contains(t, metrics.String(), `pismo_ready{service="test-service"} 0`)
+	assert.Contains(t, metrics.String(), `pismo_dependency_ready{service="test-service",dependency="database",kind="database",reason="timeout"} 0`)
+	assert.Contains(t, metrics.String(), `pismo_dependency_ready{service="test-service",dependency="backend",kind="grpc",reason="unavailable"} 0`)
+
+	now = now.Add(time.Minute)
+	databaseErr, backendErr = nil, nil
+	monitor.CheckOnce(context.Background())
+	assert.True(t, monitor.Ready())
+	assert.Equal(t, []bool{false, true}, changes)
+
+	metrics.Reset()
+	require.NoError(t, monitor.WriteOpenMetrics(&metrics))
+	lines := strings.Split(strings.TrimSpace(metrics.String()), "\n")
+	assert.Equal(t, []string{
+		`# TYPE pismo_ready gauge`,
+		`# HELP pismo_ready Whether all dependencies of the service passed their last check.`,
+		`pismo_ready{service="test-service"} 1`,
+		`# TYPE pismo_dependency_ready gauge`,
+		`# HELP pismo_dependency_ready Whether a dependency passed its last check; reason is set while it is not ready.`,
+		`pismo_dependency_ready{service="test-service",dependency="database",kind="database",reason=""} 1`,
+		`pismo_dependency_ready{service="test-service",dependency="backend",kind="grpc",reason=""} 1`,
+		`# TYPE pismo_dependency_last_transition_timestamp_seconds gauge`,
+		`# HELP pismo_dependency_last_transition_timestamp_seconds When a dependency last changed readiness, or the service started.`,
+		`pismo_dependency_last_transition_timestamp_seconds{service="test-service",dependency="database",kind="database"} 1700000060`,
+		`pismo_dependency_last_transition_timestamp_seconds{service="test-service",dependency="backend",kind="grpc"} 1700000060`,
+		`# TYPE pismo_readiness_transitions counter`,
+		`# HELP pismo_readiness_transitions Readiness changes of the dependencies, by the state entered and the reason.`,
+		`pismo_readiness_transitions_total{service="test-service",dependency="backend",kind="grpc",to="not_ready",reason="unavailable"} 1`,
+		`pismo_readiness_transitions_total{service="test-service",dependency="backend",kind="grpc",to="ready",reason=""} 1`,
+		`pismo_readiness_transitions_total{service="test-service",dependency="database",kind="database",to="not_ready",reason="timeout"} 1`,
+		`pismo_readiness_transitions_total{service="test-service",dependency="database",kind="database",to="ready",reason=""} 1`,
+		`# EOF`,
+	}, lines)
+}
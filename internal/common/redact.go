@@ -0,0 +1,68 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RedactedValue replaces the value of sensitive fields in logged payloads.
+const RedactedValue = "[REDACTED]"
+
+// sensitiveFields lists JSON field names whose values must never be logged.
+var sensitiveFields = map[string]bool{
+	"document_number": true,
+	"password":        true,
+	"secret":          true,
+	"token":           true,
+	"page_token":      true,
+	"next_page_token": true,
+	"api_key":         true,
+	"authorization":   true,
+}
+
+// SanitizeBody renders a request or response body for logging.
+// JSON bodies have sensitive fields redacted at any depth; other bodies are summarised by size only.
+// The result is truncated to maxBytes.
+func SanitizeBody(body []byte, maxBytes int) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Sprintf("[non-JSON body, %d bytes]", len(body))
+	}
+
+	sanitized, err := json.Marshal(redact(payload))
+	if err != nil {
+		return fmt.Sprintf("[unprintable body, %d bytes]", len(body))
+	}
+
+	if maxBytes > 0 && len(sanitized) > maxBytes {
+		return fmt.Sprintf("%s...[truncated, %d bytes]", sanitized[:maxBytes], len(sanitized))
+	}
+	return string(sanitized)
+}
+
+// redact returns a copy of a decoded JSON value with sensitive fields replaced.
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = RedactedValue
+			} else {
+				v[key] = redact(field)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redact(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
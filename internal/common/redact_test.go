@@ -0,0 +1,50 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		maxBytes int
+		expected string
+	}{
+		{
+			name:     "redacts top-level fields",
+			body:     `{"document_number":"12345678901","account_type":"CHECKING"}`,
+			expected: `{"account_type":"CHECKING","document_number":"[REDACTED]"}`,
+		},
+		{
+			name:     "redacts nested fields case-insensitively",
+			body:     `{"accounts":[{"id":"a1","Document_Number":"123"}],"next_page_token":"abc"}`,
+			expected: `{"accounts":[{"Document_Number":"[REDACTED]","id":"a1"}],"next_page_token":"[REDACTED]"}`,
+		},
+		{
+			name:     "non-JSON body",
+			body:     "account service error",
+			expected: "[non-JSON body, 21 bytes]",
+		},
+		{
+			name:     "empty body",
+			body:     "",
+			expected: "",
+		},
+		{
+			name:     "truncates to the size cap",
+			body:     `{"description":"` + strings.Repeat("x", 100) + `"}`,
+			maxBytes: 20,
+			expected: `{"description":"xxxx...[truncated, 118 bytes]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SanitizeBody([]byte(tt.body), tt.maxBytes))
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader is the HTTP header carrying the request ID between clients and the gateway.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMetadataKey is the gRPC metadata key carrying the request ID between services.
+const RequestIDMetadataKey = "x-request-id"
+
+// maxRequestIDLength bounds request IDs accepted from callers so they cannot bloat log lines.
+const maxRequestIDLength = 64
+
+type requestIDContextKey struct{}
+
+// NewRequestID mints a new request ID.
+func NewRequestID() string {
+	return uuid.New().String()
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or an empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	if requestID, ok := ctx.Value(requestIDContextKey{}).(string); ok {
+		return requestID
+	}
+	return ""
+}
+
+// ValidRequestID reports whether a caller-supplied request ID is safe to propagate and log.
+// Only short IDs made of letters, digits, '-', '_' and '.' are accepted.
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range requestID {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDFromIncoming returns the request ID from incoming gRPC metadata, minting one if it is missing or invalid.
+func requestIDFromIncoming(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 && ValidRequestID(values[0]) {
+			return values[0]
+		}
+	}
+	return NewRequestID()
+}
+
+// RequestIDUnaryClientInterceptor returns a client interceptor that forwards the context's request ID as metadata.
+func RequestIDUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, requestID)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// RequestIDUnaryServerInterceptor returns a server interceptor that places the caller's request ID in the
+// handler context, so the context-aware logger can include it in every line logged for the call.
+func RequestIDUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(WithRequestID(ctx, requestIDFromIncoming(ctx)), req)
+	}
+}
+
+// RequestIDStreamServerInterceptor returns the streaming counterpart of RequestIDUnaryServerInterceptor.
+func RequestIDStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := WithRequestID(ss.Context(), requestIDFromIncoming(ss.Context()))
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// contextServerStream overrides the stream context, e.g. with one carrying the request ID or the authenticated service.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
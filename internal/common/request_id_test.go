@@ -0,0 +1,85 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// newBufferLogger creates a logger writing every level to buf.
+func newBufferLogger(buf *bytes.Buffer) *Logger {
+	logger := &Logger{core: &logCore{serviceName: "test-service"}}
+	logger.AddSink(ConsoleDestination, NewWriterSink(buf), DEBUG)
+	return logger
+}
+
+func TestValidRequestID(t *testing.T) {
+	assert.True(t, ValidRequestID(NewRequestID()))
+	assert.True(t, ValidRequestID("req_123.abc"))
+	assert.False(t, ValidRequestID(""))
+	assert.False(t, ValidRequestID("has space"))
+	assert.False(t, ValidRequestID("percent%d"))
+	assert.False(t, ValidRequestID(strings.Repeat("a", maxRequestIDLength+1)))
+}
+
+func TestLogger_WithContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newBufferLogger(&buf)
+
+	// Without a request ID the logger is returned unchanged
+	assert.Same(t, logger, logger.WithContext(context.Background()))
+
+	ctxLogger := logger.WithContext(WithRequestID(context.Background(), "req-1"))
+	ctxLogger.Info("Getting account: ID=%s", "account-1")
+	ctxLogger.LogDatabase("SELECT", "accounts", 0, nil)
+	ctxLogger.LogGRPC("GetAccount", 0, nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	for _, line := range lines {
+		assert.Contains(t, line, " request_id=req-1 ")
+	}
+
+	// Derived loggers share the level of their parent
+	logger.SetLevel(ERROR)
+	buf.Reset()
+	ctxLogger.Info("suppressed")
+	assert.Empty(t, buf.String())
+}
+
+func TestRequestIDInterceptors(t *testing.T) {
+	// The client interceptor forwards the context's request ID as metadata
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	ctx := WithRequestID(context.Background(), "req-1")
+	require.NoError(t, RequestIDUnaryClientInterceptor()(ctx, "/account.AccountService/GetAccount", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"req-1"}, outgoing.Get(RequestIDMetadataKey))
+
+	// The server interceptor places it in the handler context
+	var received string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		received = RequestIDFromContext(ctx)
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/account.AccountService/GetAccount"}
+	incoming := metadata.NewIncomingContext(context.Background(), outgoing)
+	_, err := RequestIDUnaryServerInterceptor()(incoming, nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", received)
+
+	// Calls without a usable request ID get a fresh one
+	bad := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDMetadataKey, "not valid"))
+	_, err = RequestIDUnaryServerInterceptor()(bad, nil, info, handler)
+	require.NoError(t, err)
+	assert.True(t, ValidRequestID(received))
+	assert.NotEqual(t, "not valid", received)
+}
@@ -0,0 +1,83 @@
+package common
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// DefaultResponseMaskRole is the response_masking entry applied to callers whose role has no entry of its own,
+// including callers without a role.
+const DefaultResponseMaskRole = "default"
+
+// ResponseMaskRules lists the response fields hidden from callers with a given role, by JSON field name at any depth.
+// Masked string fields keep their last few characters, like masked document numbers; other masked values are
+// replaced with RedactedValue. Omitted fields are removed from the response.
+type ResponseMaskRules struct {
+	Mask []string `json:"mask"`
+	Omit []string `json:"omit"`
+}
+
+// Empty reports whether the rules leave responses unchanged.
+func (r ResponseMaskRules) Empty() bool {
+	return len(r.Mask) == 0 && len(r.Omit) == 0
+}
+
+// MaskResponseBody applies the rules to a JSON response body and returns the rewritten body.
+// Bodies that are not valid JSON are returned unchanged.
+func MaskResponseBody(body []byte, rules ResponseMaskRules) []byte {
+	if rules.Empty() {
+		return body
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return body
+	}
+
+	actions := make(map[string]string, len(rules.Mask)+len(rules.Omit))
+	for _, field := range rules.Mask {
+		actions[strings.ToLower(field)] = "mask"
+	}
+	for _, field := range rules.Omit {
+		actions[strings.ToLower(field)] = "omit"
+	}
+
+	masked, err := json.Marshal(maskFields(payload, actions))
+	if err != nil {
+		return body
+	}
+	// Keep the trailing newline written by json.Encoder
+	if len(body) > 0 && body[len(body)-1] == '\n' {
+		masked = append(masked, '\n')
+	}
+	return masked
+}
+
+// maskFields applies the per-field actions to a decoded JSON value in place and returns it.
+func maskFields(value interface{}, actions map[string]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			switch actions[strings.ToLower(key)] {
+			case "omit":
+				delete(v, key)
+			case "mask":
+				if s, ok := field.(string); ok {
+					v[key] = MaskDocumentNumber(s)
+				} else if field != nil {
+					v[key] = RedactedValue
+				}
+			default:
+				v[key] = maskFields(field, actions)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskFields(item, actions)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,54 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskResponseBody(t *testing.T) {
+	rules := ResponseMaskRules{Mask: []string{"document_number", "holder_email"}, Omit: []string{"balance"}}
+
+	tests := []struct {
+		name     string
+		body     string
+		rules    ResponseMaskRules
+		expected string
+	}{
+		{
+			name:     "masks and omits fields at any depth",
+			body:     `{"accounts":[{"id":"acc-1","document_number":"12345678900","balance":150.5,"holder_email":null}],"total":1}` + "\n",
+			rules:    rules,
+			expected: `{"accounts":[{"document_number":"*******8900","holder_email":null,"id":"acc-1"}],"total":1}` + "\n",
+		},
+		{
+			name:     "non-string values are redacted",
+			body:     `{"account":{"document_number":12345678900}}`,
+			rules:    rules,
+			expected: `{"account":{"document_number":"[REDACTED]"}}`,
+		},
+		{
+			name:     "field names are matched case-insensitively",
+			body:     `{"Balance":10}`,
+			rules:    rules,
+			expected: `{}`,
+		},
+		{
+			name:     "non-JSON bodies are unchanged",
+			body:     "account not found\n",
+			rules:    rules,
+			expected: "account not found\n",
+		},
+		{
+			name:     "empty rules leave the body as it is",
+			body:     `{"balance":10, "document_number":"12345678900"}`,
+			expected: `{"balance":10, "document_number":"12345678900"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(MaskResponseBody([]byte(tt.body), tt.rules)))
+		})
+	}
+}
@@ -0,0 +1,410 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Defaults for the retention worker.
+const (
+	DefaultRetentionInterval  = time.Hour
+	DefaultRetentionBatchSize = 1000
+)
+
+// RetentionPolicy describes a class of rows that can be permanently removed once they are older
+// than Retention. TimestampColumn must hold Unix seconds, as all timestamps in the schema do.
+// Condition is an optional extra SQL predicate, e.g. "deleted_at IS NOT NULL".
+type RetentionPolicy struct {
+	Name            string
+	Table           string
+	TimestampColumn string
+	Condition       string
+	Retention       time.Duration
+}
+
+// RetentionStats records the outcome of the most recent runs of a policy.
+type RetentionStats struct {
+	Purged    int64
+	Eligible  int64
+	LastRun   time.Time
+	LastError string
+}
+
+// RetentionWorker periodically purges rows matched by its policies and, once SetTenantSettings is called,
+// applies the retention settings of every tenant.
+// In dry-run mode it only counts eligible rows, so retention periods can be validated safely.
+type RetentionWorker struct {
+	db        *sql.DB
+	logger    *Logger
+	policies  []RetentionPolicy
+	tenants   *TenantConfigStore
+	dryRun    bool
+	batchSize int
+	now       func() time.Time
+
+	mu    sync.Mutex
+	stats map[string]RetentionStats
+}
+
+// NewRetentionWorker creates a retention worker for the given policies.
+func NewRetentionWorker(db *sql.DB, logger *Logger, policies []RetentionPolicy, dryRun bool) *RetentionWorker {
+	return &RetentionWorker{
+		db:        db,
+		logger:    logger,
+		policies:  policies,
+		dryRun:    dryRun,
+		batchSize: DefaultRetentionBatchSize,
+		now:       time.Now,
+		stats:     make(map[string]RetentionStats),
+	}
+}
+
+// SetTenantSettings makes every run also apply the retention settings of the tenants in store's environment.
+func (w *RetentionWorker) SetTenantSettings(store *TenantConfigStore) {
+	w.tenants = store
+}
+
+// RetentionDryRunFromEnv reports whether RETENTION_DRY_RUN is set to "true".
+func RetentionDryRunFromEnv() bool {
+	dryRun, _ := strconv.ParseBool(getEnv("RETENTION_DRY_RUN", "false"))
+	return dryRun
+}
+
+// RetentionIntervalFromEnv returns the RETENTION_INTERVAL duration, defaulting to DefaultRetentionInterval.
+func RetentionIntervalFromEnv() time.Duration {
+	interval, err := time.ParseDuration(getEnv("RETENTION_INTERVAL", DefaultRetentionInterval.String()))
+	if err != nil || interval <= 0 {
+		return DefaultRetentionInterval
+	}
+	return interval
+}
+
+// Run purges on every interval until ctx is cancelled.
+// It is intended to be run in its own goroutine.
+func (w *RetentionWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce applies every policy once. A failing policy is logged and does not stop the others.
+func (w *RetentionWorker) RunOnce(ctx context.Context) {
+	for _, policy := range w.policies {
+		var count int64
+		var err error
+		if w.dryRun {
+			count, err = w.countEligible(ctx, policy)
+		} else {
+			count, err = w.purge(ctx, policy)
+		}
+		w.record(policy.Name, count, err)
+
+		if err != nil {
+			w.logger.Error("Retention policy %s failed: %v", policy.Name, err)
+			continue
+		}
+		if w.dryRun {
+			w.logger.Info("Retention policy %s (dry run): %d rows eligible for purge", policy.Name, count)
+		} else if count > 0 {
+			w.logger.Info("Retention policy %s: purged %d rows", policy.Name, count)
+		}
+	}
+
+	if w.tenants != nil {
+		w.applyTenantRetention(ctx)
+	}
+}
+
+// Stats returns a snapshot of the per-policy statistics.
+func (w *RetentionWorker) Stats() map[string]RetentionStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := make(map[string]RetentionStats, len(w.stats))
+	for name, s := range w.stats {
+		stats[name] = s
+	}
+	return stats
+}
+
+// cutoff returns the Unix timestamp before which rows of the policy are eligible for purge.
+func (w *RetentionWorker) cutoff(policy RetentionPolicy) int64 {
+	return w.now().Add(-policy.Retention).Unix()
+}
+
+// where builds the predicate selecting the rows eligible for purge.
+func (policy RetentionPolicy) where() string {
+	clause := fmt.Sprintf("%s < $1", policy.TimestampColumn)
+	if policy.Condition != "" {
+		clause += " AND (" + policy.Condition + ")"
+	}
+	return clause
+}
+
+// countEligible counts the rows a purge would remove.
+func (w *RetentionWorker) countEligible(ctx context.Context, policy RetentionPolicy) (int64, error) {
+	var count int64
+	start := time.Now()
+	err := w.db.QueryRowContext(ctx, fmt.Sprintf(
+		"SELECT COUNT(*) FROM %s WHERE %s", policy.Table, policy.where(),
+	), w.cutoff(policy)).Scan(&count)
+	w.logger.LogDatabase("SELECT", policy.Table, time.Since(start), err)
+	return count, err
+}
+
+// purge deletes eligible rows in batches so a large backlog never holds long locks.
+func (w *RetentionWorker) purge(ctx context.Context, policy RetentionPolicy) (int64, error) {
+	cutoff := w.cutoff(policy)
+	query := fmt.Sprintf(
+		"DELETE FROM %s WHERE ctid IN (SELECT ctid FROM %s WHERE %s LIMIT %d)",
+		policy.Table, policy.Table, policy.where(), w.batchSize,
+	)
+
+	var total int64
+	for {
+		start := time.Now()
+		result, err := w.db.ExecContext(ctx, query, cutoff)
+		w.logger.LogDatabase("DELETE", policy.Table, time.Since(start), err)
+		if err != nil {
+			return total, err
+		}
+
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if deleted < int64(w.batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// record updates the statistics for a policy run.
+func (w *RetentionWorker) record(name string, count int64, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	s := w.stats[name]
+	s.LastRun = w.now()
+	s.LastError = ""
+	if err != nil {
+		s.LastError = err.Error()
+	}
+	if w.dryRun {
+		s.Eligible = count
+	} else {
+		s.Purged += count
+	}
+	w.stats[name] = s
+}
+
+// Names of the per-tenant retention rules, used in statistics and reports.
+const (
+	tenantTransactionRetention = "transactions"
+	tenantAccountAnonymization = "closed_account_holder_data"
+)
+
+// tenantRetentionRule is one retention rule of a tenant. Rows older than the cutoff are counted by countSQL,
+// which takes the tenant ID and the cutoff, and processed in batches by apply, which returns the rows processed.
+type tenantRetentionRule struct {
+	name     string
+	table    string
+	days     int
+	countSQL string
+	apply    func(ctx context.Context, tenantID string, cutoff int64) (int64, error)
+}
+
+// applyTenantRetention applies the retention settings of every tenant that has some.
+// A failing rule is logged and does not stop the others.
+func (w *RetentionWorker) applyTenantRetention(ctx context.Context) {
+	tenants, err := w.tenants.List(ctx)
+	if err != nil {
+		w.logger.Error("Tenant retention skipped: %v", err)
+		return
+	}
+
+	ids := make([]string, 0, len(tenants))
+	for id := range tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, tenantID := range ids {
+		retention := tenants[tenantID].Retention
+		if retention == nil {
+			continue
+		}
+		for _, rule := range w.tenantRetentionRules(retention) {
+			if rule.days <= 0 {
+				continue
+			}
+			w.applyTenantRule(ctx, tenantID, rule)
+		}
+	}
+}
+
+// tenantRetentionRules returns the retention rules of a tenant's settings.
+func (w *RetentionWorker) tenantRetentionRules(retention *RetentionSettings) []tenantRetentionRule {
+	return []tenantRetentionRule{
+		{
+			name:  tenantTransactionRetention,
+			table: "transactions",
+			days:  retention.TransactionDays,
+			countSQL: `
+				SELECT COUNT(*) FROM transactions t JOIN accounts a ON a.id = t.account_id
+				WHERE a.tenant_id = $1 AND t.created_at < $2`,
+			apply: w.purgeTenantTransactions,
+		},
+		{
+			name:  tenantAccountAnonymization,
+			table: "accounts",
+			days:  retention.AnonymizeClosedAccountDays,
+			countSQL: `
+				SELECT COUNT(*) FROM accounts
+				WHERE tenant_id = $1 AND status = 'CLOSED' AND closed_at < $2 AND anonymized_at IS NULL`,
+			apply: w.anonymizeClosedAccounts,
+		},
+	}
+}
+
+// applyTenantRule applies or, in dry-run mode, counts one retention rule of a tenant and reports the outcome.
+func (w *RetentionWorker) applyTenantRule(ctx context.Context, tenantID string, rule tenantRetentionRule) {
+	name := fmt.Sprintf("tenant/%s/%s", tenantID, rule.name)
+	cutoff := w.now().AddDate(0, 0, -rule.days).Unix()
+
+	var count int64
+	var err error
+	if w.dryRun {
+		start := time.Now()
+		err = w.db.QueryRowContext(ctx, rule.countSQL, tenantID, cutoff).Scan(&count)
+		w.logger.LogDatabase("SELECT", rule.table, time.Since(start), err)
+	} else {
+		count, err = rule.apply(ctx, tenantID, cutoff)
+	}
+	w.record(name, count, err)
+
+	if err != nil {
+		w.logger.Error("Retention policy %s failed: %v", name, err)
+		return
+	}
+	if count == 0 {
+		return
+	}
+	if w.dryRun {
+		w.logger.Info("Retention policy %s (dry run): %d rows eligible", name, count)
+	} else {
+		w.logger.Info("Retention policy %s: %d rows removed", name, count)
+	}
+	if err := w.report(ctx, tenantID, rule.name, count, cutoff); err != nil {
+		w.logger.Error("Retention report for %s failed: %v", name, err)
+	}
+}
+
+// purgeTenantTransactions deletes a tenant's transactions created before cutoff, in batches. The amounts
+// of purged completed transactions are added to their account's opening balance in the same statement,
+// so balances derived from the ledger do not change.
+func (w *RetentionWorker) purgeTenantTransactions(ctx context.Context, tenantID string, cutoff int64) (int64, error) {
+	var total int64
+	for {
+		var purged int64
+		start := time.Now()
+		err := w.db.QueryRowContext(ctx, `
+			WITH purged AS (
+				DELETE FROM transactions WHERE ctid IN (
+					SELECT t.ctid FROM transactions t JOIN accounts a ON a.id = t.account_id
+					WHERE a.tenant_id = $1 AND t.created_at < $2
+					LIMIT $3
+				)
+				RETURNING account_id, amount, status
+			), folded AS (
+				UPDATE accounts a SET opening_balance = COALESCE(a.opening_balance, 0) + p.total
+				FROM (SELECT account_id, SUM(amount) AS total FROM purged WHERE status IN ('COMPLETED', 'REVERSED') GROUP BY account_id) p
+				WHERE a.id = p.account_id
+				RETURNING a.id
+			)
+			SELECT COUNT(*) FROM purged
+		`, tenantID, cutoff, w.batchSize).Scan(&purged)
+		w.logger.LogDatabase("DELETE", "transactions", time.Since(start), err)
+		if err != nil {
+			return total, err
+		}
+		total += purged
+		if purged < int64(w.batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// anonymizeClosedAccounts replaces the holder data of a tenant's accounts closed before cutoff, in batches.
+// The document number is replaced by a value derived from the account ID, as it must stay unique. The holder
+// data recorded in the account_audit entries of the accounts is removed, and their account_snapshots deleted,
+// in the same statement.
+func (w *RetentionWorker) anonymizeClosedAccounts(ctx context.Context, tenantID string, cutoff int64) (int64, error) {
+	var total int64
+	for {
+		var anonymized int64
+		start := time.Now()
+		err := w.db.QueryRowContext(ctx, `
+			WITH anonymized AS (
+				UPDATE accounts
+				SET document_number = 'ANON' || substr(md5(id), 1, 16), holder_name = NULL, holder_email = NULL,
+					holder_phone = NULL, kyc_reference = NULL, anonymized_at = $4, updated_at = $4, version = version + 1
+				WHERE ctid IN (
+					SELECT ctid FROM accounts
+					WHERE tenant_id = $1 AND status = 'CLOSED' AND closed_at < $2 AND anonymized_at IS NULL
+					LIMIT $3
+				)
+				RETURNING id
+			), scrubbed AS (
+				UPDATE account_audit
+				SET previous = previous - $5::text[], updated = updated - $5::text[]
+				WHERE account_id IN (SELECT id FROM anonymized)
+				RETURNING id
+			), unsnapshotted AS (
+				DELETE FROM account_snapshots
+				WHERE account_id IN (SELECT id FROM anonymized)
+				RETURNING id
+			)
+			SELECT COUNT(*) FROM anonymized
+		`, tenantID, cutoff, w.batchSize, w.now().Unix(), pq.Array(anonymizedAuditFields)).Scan(&anonymized)
+		w.logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+		if err != nil {
+			return total, err
+		}
+
+		total += anonymized
+		if anonymized < int64(w.batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// anonymizedAuditFields are the account_audit values removed when an account is anonymized.
+var anonymizedAuditFields = []string{"document_number", "holder_name", "holder_email", "holder_phone", "kyc_reference"}
+
+// report records what a tenant retention rule removed, or would remove in dry-run mode, in retention_reports.
+func (w *RetentionWorker) report(ctx context.Context, tenantID, policy string, count, cutoff int64) error {
+	start := time.Now()
+	_, err := w.db.ExecContext(ctx, `
+		INSERT INTO retention_reports (tenant_id, policy, dry_run, affected, cutoff, run_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, tenantID, policy, w.dryRun, count, cutoff, w.now().Unix())
+	w.logger.LogDatabase("INSERT", "retention_reports", time.Since(start), err)
+	return err
+}
@@ -0,0 +1,150 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRetentionWorker(t *testing.T, policies []RetentionPolicy, dryRun bool) (*RetentionWorker, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	logger, err := NewLogger("test-retention", INFO)
+	require.NoError(t, err)
+	t.Cleanup(func() { logger.Close() })
+
+	worker := NewRetentionWorker(db, logger, policies, dryRun)
+	worker.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return worker, mock
+}
+
+var testRetentionPolicy = RetentionPolicy{
+	Name:            "deleted_accounts",
+	Table:           "accounts",
+	TimestampColumn: "deleted_at",
+	Condition:       "deleted_at IS NOT NULL",
+	Retention:       24 * time.Hour,
+}
+
+func TestRetentionWorker_PurgesInBatches(t *testing.T) {
+	worker, mock := newTestRetentionWorker(t, []RetentionPolicy{testRetentionPolicy}, false)
+	worker.batchSize = 2
+
+	cutoff := int64(1700000000 - 24*60*60)
+	mock.ExpectExec(`DELETE FROM accounts WHERE ctid IN \(SELECT ctid FROM accounts WHERE deleted_at < \$1 AND \(deleted_at IS NOT NULL\) LIMIT 2\)`).
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM accounts`).
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	worker.RunOnce(context.Background())
+
+	stats := worker.Stats()["deleted_accounts"]
+	assert.Equal(t, int64(3), stats.Purged)
+	assert.Empty(t, stats.LastError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetentionWorker_DryRunOnlyCounts(t *testing.T) {
+	worker, mock := newTestRetentionWorker(t, []RetentionPolicy{testRetentionPolicy}, true)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM accounts WHERE deleted_at < \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+
+	worker.RunOnce(context.Background())
+
+	stats := worker.Stats()["deleted_accounts"]
+	assert.Equal(t, int64(42), stats.Eligible)
+	assert.Equal(t, int64(0), stats.Purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetentionWorker_FailureDoesNotStopOtherPolicies(t *testing.T) {
+	other := RetentionPolicy{Name: "old_rows", Table: "old_rows", TimestampColumn: "created_at", Retention: time.Hour}
+	worker, mock := newTestRetentionWorker(t, []RetentionPolicy{testRetentionPolicy, other}, false)
+
+	mock.ExpectExec(`DELETE FROM accounts`).WillReturnError(sql.ErrConnDone)
+	mock.ExpectExec(`DELETE FROM old_rows WHERE ctid IN \(SELECT ctid FROM old_rows WHERE created_at < \$1 LIMIT`).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+
+	worker.RunOnce(context.Background())
+
+	stats := worker.Stats()
+	assert.Equal(t, sql.ErrConnDone.Error(), stats["deleted_accounts"].LastError)
+	assert.Equal(t, int64(5), stats["old_rows"].Purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetentionConfigFromEnv(t *testing.T) {
+	t.Setenv("RETENTION_DRY_RUN", "true")
+	t.Setenv("RETENTION_INTERVAL", "15m")
+	assert.True(t, RetentionDryRunFromEnv())
+	assert.Equal(t, 15*time.Minute, RetentionIntervalFromEnv())
+
+	t.Setenv("RETENTION_INTERVAL", "bogus")
+	assert.Equal(t, DefaultRetentionInterval, RetentionIntervalFromEnv())
+}
+
+func TestRetentionWorker_TenantRetention(t *testing.T) {
+	worker, mock := newTestRetentionWorker(t, nil, false)
+	worker.SetTenantSettings(NewTenantConfigStore(worker.db, "production", time.Minute))
+	worker.batchSize = 2
+
+	mock.ExpectQuery(`SELECT tenant_id, settings FROM tenant_settings WHERE environment = \$1`).
+		WithArgs("production").
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "settings"}).
+			AddRow("issuer-a", []byte(`{"retention":{"transaction_days":30,"anonymize_closed_account_days":10}}`)).
+			AddRow("issuer-b", []byte(`{"currency":"BRL"}`)))
+
+	transactionCutoff := time.Unix(1700000000, 0).AddDate(0, 0, -30).Unix()
+	mock.ExpectQuery(`WITH purged AS \(\s+DELETE FROM transactions .* UPDATE accounts a SET opening_balance = COALESCE\(a.opening_balance, 0\) \+ p.total .* SELECT COUNT\(\*\) FROM purged`).
+		WithArgs("issuer-a", transactionCutoff, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`WITH purged AS`).
+		WithArgs("issuer-a", transactionCutoff, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(`INSERT INTO retention_reports \(tenant_id, policy, dry_run, affected, cutoff, run_at\)`).
+		WithArgs("issuer-a", "transactions", false, int64(3), transactionCutoff, int64(1700000000)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	closedCutoff := time.Unix(1700000000, 0).AddDate(0, 0, -10).Unix()
+	mock.ExpectQuery(`WITH anonymized AS \(\s+UPDATE accounts\s+SET document_number = 'ANON' .* WHERE tenant_id = \$1 AND status = 'CLOSED' AND closed_at < \$2 AND anonymized_at IS NULL .* UPDATE account_audit\s+SET previous = previous - \$5::text\[\].* DELETE FROM account_snapshots`).
+		WithArgs("issuer-a", closedCutoff, 2, int64(1700000000), pq.Array(anonymizedAuditFields)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	worker.RunOnce(context.Background())
+
+	stats := worker.Stats()
+	assert.Equal(t, int64(3), stats["tenant/issuer-a/transactions"].Purged)
+	assert.Equal(t, int64(0), stats["tenant/issuer-a/closed_account_holder_data"].Purged)
+	assert.NotContains(t, stats, "tenant/issuer-b/transactions")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetentionWorker_TenantRetentionDryRun(t *testing.T) {
+	worker, mock := newTestRetentionWorker(t, nil, true)
+	worker.SetTenantSettings(NewTenantConfigStore(worker.db, "production", time.Minute))
+
+	mock.ExpectQuery(`SELECT tenant_id, settings FROM tenant_settings`).
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "settings"}).
+			AddRow("issuer-a", []byte(`{"retention":{"anonymize_closed_account_days":10}}`)))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM accounts\s+WHERE tenant_id = \$1 AND status = 'CLOSED'`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	mock.ExpectExec(`INSERT INTO retention_reports`).
+		WithArgs("issuer-a", "closed_account_holder_data", true, int64(4), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	worker.RunOnce(context.Background())
+
+	assert.Equal(t, int64(4), worker.Stats()["tenant/issuer-a/closed_account_holder_data"].Eligible)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
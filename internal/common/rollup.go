@@ -0,0 +1,100 @@
+package common
+
+import "fmt"
+
+// The transaction_daily_rollups table holds the count and total amount of each account's completed
+// transactions per UTC day and operation type. A trigger keeps it in step with every insert, update
+// and delete on transactions, so reporting queries read a few rows per day instead of scanning the
+// raw table. Reversed transactions stay counted, offset by the REVERSAL transaction compensating them.
+const (
+	createRollupTableSQL = `
+		CREATE TABLE IF NOT EXISTS transaction_daily_rollups (
+			account_id VARCHAR(36) NOT NULL,
+			day_start BIGINT NOT NULL,
+			operation_type VARCHAR(50) NOT NULL,
+			txn_count BIGINT NOT NULL DEFAULT 0,
+			total_amount DECIMAL(18,2) NOT NULL DEFAULT 0,
+			PRIMARY KEY (account_id, day_start, operation_type),
+			FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+		)`
+
+	// The end-of-day close reads the rollups of one day over all accounts
+	createRollupDayIndexSQL = `
+		CREATE INDEX IF NOT EXISTS idx_transaction_daily_rollups_day ON transaction_daily_rollups(day_start)`
+
+	createRollupFunctionSQL = `
+		CREATE OR REPLACE FUNCTION rollup_transaction() RETURNS TRIGGER AS $$
+		BEGIN
+			IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.status IN ('COMPLETED', 'REVERSED') THEN
+				UPDATE transaction_daily_rollups
+				SET txn_count = txn_count - 1, total_amount = total_amount - OLD.amount
+				WHERE account_id = OLD.account_id
+					AND day_start = OLD.created_at - OLD.created_at % 86400
+					AND operation_type = OLD.operation_type;
+			END IF;
+			IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.status IN ('COMPLETED', 'REVERSED') THEN
+				INSERT INTO transaction_daily_rollups (account_id, day_start, operation_type, txn_count, total_amount)
+				VALUES (NEW.account_id, NEW.created_at - NEW.created_at % 86400, NEW.operation_type, 1, NEW.amount)
+				ON CONFLICT (account_id, day_start, operation_type) DO UPDATE
+				SET txn_count = transaction_daily_rollups.txn_count + 1,
+					total_amount = transaction_daily_rollups.total_amount + EXCLUDED.total_amount;
+			END IF;
+			RETURN NULL;
+		END;
+		$$ LANGUAGE plpgsql`
+
+	createRollupTriggerSQL = `
+		CREATE TRIGGER transactions_rollup
+		AFTER INSERT OR UPDATE OR DELETE ON transactions
+		FOR EACH ROW EXECUTE FUNCTION rollup_transaction()`
+
+	backfillRollupsSQL = `
+		INSERT INTO transaction_daily_rollups (account_id, day_start, operation_type, txn_count, total_amount)
+		SELECT account_id, created_at - created_at % 86400, operation_type, COUNT(*), SUM(amount)
+		FROM transactions
+		WHERE status IN ('COMPLETED', 'REVERSED')
+		GROUP BY account_id, created_at - created_at % 86400, operation_type`
+)
+
+// initRollups creates the reporting rollup table and the trigger that maintains it.
+// The first time the trigger is installed, existing transactions are backfilled in the same
+// database transaction, with writes to transactions blocked so none are missed or counted twice.
+func (dm *DatabaseManager) initRollups() error {
+	if _, err := dm.db.Exec(createRollupTableSQL); err != nil {
+		return fmt.Errorf("failed to create rollup table: %w", err)
+	}
+	if _, err := dm.db.Exec(createRollupDayIndexSQL); err != nil {
+		return fmt.Errorf("failed to create rollup day index: %w", err)
+	}
+
+	tx, err := dm.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin rollup initialization: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("LOCK TABLE transactions IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("failed to lock transactions table: %w", err)
+	}
+	if _, err := tx.Exec(createRollupFunctionSQL); err != nil {
+		return fmt.Errorf("failed to create rollup function: %w", err)
+	}
+
+	var installed bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'transactions_rollup')").Scan(&installed); err != nil {
+		return fmt.Errorf("failed to check rollup trigger: %w", err)
+	}
+	if !installed {
+		if _, err := tx.Exec(createRollupTriggerSQL); err != nil {
+			return fmt.Errorf("failed to create rollup trigger: %w", err)
+		}
+		if _, err := tx.Exec(backfillRollupsSQL); err != nil {
+			return fmt.Errorf("failed to backfill rollups: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rollup initialization: %w", err)
+	}
+	return nil
+}
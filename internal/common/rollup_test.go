@@ -0,0 +1,68 @@
+package common
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseManager_initRollups(t *testing.T) {
+	tests := []struct {
+		name           string
+		installed      bool
+		expectBackfill bool
+	}{
+		{name: "first run installs the trigger and backfills", installed: false, expectBackfill: true},
+		{name: "later runs only refresh the function", installed: true, expectBackfill: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectExec(`CREATE TABLE IF NOT EXISTS transaction_daily_rollups`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`CREATE INDEX IF NOT EXISTS idx_transaction_daily_rollups_day`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectBegin()
+			mock.ExpectExec(`LOCK TABLE transactions`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`CREATE OR REPLACE FUNCTION rollup_transaction`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM pg_trigger`).
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.installed))
+			if tt.expectBackfill {
+				mock.ExpectExec(`CREATE TRIGGER transactions_rollup`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO transaction_daily_rollups`).WillReturnResult(sqlmock.NewResult(0, 12))
+			}
+			mock.ExpectCommit()
+
+			dm := &DatabaseManager{db: db}
+			require.NoError(t, dm.initRollups())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDatabaseManager_initRollups_RollsBackOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS transaction_daily_rollups`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE INDEX IF NOT EXISTS idx_transaction_daily_rollups_day`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`LOCK TABLE transactions`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE OR REPLACE FUNCTION rollup_transaction`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM pg_trigger`).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(`CREATE TRIGGER transactions_rollup`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO transaction_daily_rollups`).WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	dm := &DatabaseManager{db: db}
+	err = dm.initRollups()
+	assert.ErrorContains(t, err, "failed to backfill rollups")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
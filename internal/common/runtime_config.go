@@ -0,0 +1,261 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultRuntimeConfigPollInterval is how often the runtime config file is checked for changes.
+const DefaultRuntimeConfigPollInterval = 10 * time.Second
+
+// RuntimeConfig holds settings that can be changed while a service is running.
+// It is loaded from a JSON file and replaced atomically on reload, so readers always see a consistent snapshot.
+type RuntimeConfig struct {
+	LogLevel string `json:"log_level"`
+	// LogLevels overrides LogLevel per destination, e.g. {"file": "DEBUG"}
+	LogLevels          map[LogDestination]string `json:"log_levels"`
+	RateLimits         map[string]float64        `json:"rate_limits"`
+	FeatureFlags       map[string]bool           `json:"feature_flags"`
+	VelocityThresholds map[string]float64        `json:"velocity_thresholds"`
+	AccountQuotas      map[string]int            `json:"account_quotas"`
+	DebugLogging       DebugLoggingConfig        `json:"debug_logging"`
+	// ResponseMasking maps caller roles, or DefaultResponseMaskRole, to the response fields hidden from them
+	ResponseMasking map[string]ResponseMaskRules `json:"response_masking"`
+	Authorization   AuthorizationPolicies        `json:"authorization"`
+	// SLOTargets maps full gRPC method names to their objectives, tracked by SLOTracker
+	SLOTargets map[string]SLOTarget `json:"slo_targets"`
+	// MessageTemplates maps event types, then channels, to the platform templates of the messages rendered for
+	// events, which tenants can override
+	MessageTemplates map[string]map[string]MessageTemplateConfig `json:"message_templates"`
+}
+
+// DefaultDebugLogMaxBodyBytes caps logged bodies when debug_logging.max_body_bytes is not set.
+const DefaultDebugLogMaxBodyBytes = 2048
+
+// DebugLoggingConfig selects the traffic whose sanitized request and response bodies are logged.
+// Logging is targeted at specific accounts or API keys so it can be left on in production while troubleshooting.
+type DebugLoggingConfig struct {
+	AccountIDs   []string `json:"account_ids"`
+	APIKeys      []string `json:"api_keys"`
+	MaxBodyBytes int      `json:"max_body_bytes"`
+}
+
+// Enabled reports whether any debug logging target is configured.
+func (c DebugLoggingConfig) Enabled() bool {
+	return len(c.AccountIDs) > 0 || len(c.APIKeys) > 0
+}
+
+// Matches reports whether traffic for the account or API key should be logged.
+// Empty values never match.
+func (c DebugLoggingConfig) Matches(accountID, apiKey string) bool {
+	for _, id := range c.AccountIDs {
+		if accountID != "" && id == accountID {
+			return true
+		}
+	}
+	for _, key := range c.APIKeys {
+		if apiKey != "" && key == apiKey {
+			return true
+		}
+	}
+	return false
+}
+
+// BodyLimit returns the maximum number of body bytes to log.
+func (c DebugLoggingConfig) BodyLimit() int {
+	if c.MaxBodyBytes > 0 {
+		return c.MaxBodyBytes
+	}
+	return DefaultDebugLogMaxBodyBytes
+}
+
+// FeatureEnabled reports whether the named feature flag is switched on.
+// Unknown flags are treated as disabled.
+func (c *RuntimeConfig) FeatureEnabled(name string) bool {
+	return c.FeatureFlags[name]
+}
+
+// RateLimit returns the configured rate limit for the given key, or defaultValue when not set.
+func (c *RuntimeConfig) RateLimit(key string, defaultValue float64) float64 {
+	if value, ok := c.RateLimits[key]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+// VelocityThreshold returns the configured velocity threshold for the given key, or defaultValue when not set.
+func (c *RuntimeConfig) VelocityThreshold(key string, defaultValue float64) float64 {
+	if value, ok := c.VelocityThresholds[key]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+// AccountQuota returns how many accounts of the given type a single document number may open.
+// ok is false when no quota is configured for the account type, which leaves it unlimited.
+func (c *RuntimeConfig) AccountQuota(accountType string) (quota int, ok bool) {
+	quota, ok = c.AccountQuotas[accountType]
+	return quota, ok
+}
+
+// ResponseMaskRulesFor returns the response masking rules for callers with role: the role's own entry,
+// or else the DefaultResponseMaskRole entry. Without either, responses are not masked.
+func (c *RuntimeConfig) ResponseMaskRulesFor(role string) ResponseMaskRules {
+	if rules, ok := c.ResponseMasking[role]; ok && role != "" {
+		return rules
+	}
+	return c.ResponseMasking[DefaultResponseMaskRole]
+}
+
+// RuntimeConfigManager loads the runtime configuration and reloads it on SIGHUP or when the file changes.
+// Components read the latest snapshot through Current or subscribe to changes with OnReload.
+type RuntimeConfigManager struct {
+	path        string
+	logger      *Logger
+	current     atomic.Pointer[RuntimeConfig]
+	mu          sync.Mutex
+	modTime     time.Time
+	subscribers []func(*RuntimeConfig)
+}
+
+// NewRuntimeConfigManager creates a runtime config manager for the JSON file at path.
+// An empty path yields an empty configuration that never changes.
+// Returns an error if the file cannot be read or parsed.
+func NewRuntimeConfigManager(path string, logger *Logger) (*RuntimeConfigManager, error) {
+	m := &RuntimeConfigManager{path: path, logger: logger}
+	m.current.Store(&RuntimeConfig{})
+
+	if path == "" {
+		return m, nil
+	}
+
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Current returns the most recently loaded configuration snapshot.
+func (m *RuntimeConfigManager) Current() *RuntimeConfig {
+	return m.current.Load()
+}
+
+// OnReload registers a callback invoked with the new configuration after every successful reload.
+func (m *RuntimeConfigManager) OnReload(fn func(*RuntimeConfig)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, fn)
+}
+
+// Reload reads the configuration file and atomically replaces the current configuration.
+// An invalid file leaves the previous configuration in place so a bad edit cannot take a service down.
+func (m *RuntimeConfigManager) Reload() error {
+	if m.path == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	info, err := os.Stat(m.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat runtime config: %w", err)
+	}
+	m.modTime = info.ModTime()
+
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		return fmt.Errorf("failed to read runtime config: %w", err)
+	}
+
+	var config RuntimeConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to parse runtime config: %w", err)
+	}
+	if err := config.Authorization.Validate(); err != nil {
+		return fmt.Errorf("invalid runtime config: %w", err)
+	}
+	if err := ValidateSLOTargets(config.SLOTargets); err != nil {
+		return fmt.Errorf("invalid runtime config: %w", err)
+	}
+	if err := ValidateMessageTemplates(config.MessageTemplates); err != nil {
+		return fmt.Errorf("invalid runtime config: %w", err)
+	}
+
+	m.current.Store(&config)
+
+	if config.LogLevel != "" && m.logger != nil {
+		m.logger.SetLevel(ParseLogLevel(config.LogLevel))
+	}
+	for destination, level := range config.LogLevels {
+		if m.logger != nil {
+			m.logger.SetDestinationLevel(destination, ParseLogLevel(level))
+		}
+	}
+
+	for _, fn := range m.subscribers {
+		fn(&config)
+	}
+
+	return nil
+}
+
+// Watch reloads the configuration on SIGHUP and whenever the file's modification time changes.
+// It blocks until ctx is cancelled and is intended to be run in its own goroutine.
+func (m *RuntimeConfigManager) Watch(ctx context.Context, interval time.Duration) {
+	if m.path == "" {
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			m.reloadAndLog("SIGHUP")
+		case <-ticker.C:
+			if m.changed() {
+				m.reloadAndLog("file change")
+			}
+		}
+	}
+}
+
+// changed reports whether the configuration file was modified since it was last loaded.
+func (m *RuntimeConfigManager) changed() bool {
+	info, err := os.Stat(m.path)
+	if err != nil {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !info.ModTime().Equal(m.modTime)
+}
+
+// reloadAndLog reloads the configuration and logs the outcome.
+func (m *RuntimeConfigManager) reloadAndLog(trigger string) {
+	if err := m.Reload(); err != nil {
+		if m.logger != nil {
+			m.logger.Error("Runtime config reload (%s) failed, keeping previous config: %v", trigger, err)
+		}
+		return
+	}
+	if m.logger != nil {
+		m.logger.Info("Runtime config reloaded (%s) from %s", trigger, m.path)
+	}
+}
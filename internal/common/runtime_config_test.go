@@ -0,0 +1,122 @@
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRuntimeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestNewRuntimeConfigManager_EmptyPath(t *testing.T) {
+	manager, err := NewRuntimeConfigManager("", nil)
+	require.NoError(t, err)
+
+	config := manager.Current()
+	require.NotNil(t, config)
+	assert.False(t, config.FeatureEnabled("anything"))
+	assert.Equal(t, 5.0, config.RateLimit("global", 5.0))
+	assert.NoError(t, manager.Reload())
+}
+
+func TestNewRuntimeConfigManager_MissingFile(t *testing.T) {
+	_, err := NewRuntimeConfigManager(filepath.Join(t.TempDir(), "missing.json"), nil)
+	assert.Error(t, err)
+}
+
+func TestRuntimeConfigManager_LoadAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	writeRuntimeConfig(t, path, `{
+		"log_level": "ERROR",
+		"rate_limits": {"global": 100},
+		"feature_flags": {"read_only": false},
+		"velocity_thresholds": {"daily_amount": 5000},
+		"account_quotas": {"CREDIT": 1}
+	}`)
+
+	logger, err := NewLogger("test-runtime-config", INFO)
+	require.NoError(t, err)
+	defer logger.Close()
+
+	manager, err := NewRuntimeConfigManager(path, logger)
+	require.NoError(t, err)
+
+	config := manager.Current()
+	assert.Equal(t, ERROR, logger.Level())
+	assert.Equal(t, 100.0, config.RateLimit("global", 1))
+	assert.Equal(t, 1.0, config.RateLimit("per_caller", 1))
+	assert.False(t, config.FeatureEnabled("read_only"))
+	assert.Equal(t, 5000.0, config.VelocityThreshold("daily_amount", 0))
+	quota, ok := config.AccountQuota("CREDIT")
+	assert.True(t, ok)
+	assert.Equal(t, 1, quota)
+	_, ok = config.AccountQuota("CHECKING")
+	assert.False(t, ok)
+
+	var reloaded *RuntimeConfig
+	manager.OnReload(func(c *RuntimeConfig) { reloaded = c })
+
+	writeRuntimeConfig(t, path, `{"log_level": "DEBUG", "log_levels": {"console": "WARN"}, "feature_flags": {"read_only": true}}`)
+	require.NoError(t, manager.Reload())
+
+	assert.Equal(t, DEBUG, logger.Level())
+	assert.Equal(t, DEBUG, logger.DestinationLevel(FileDestination))
+	assert.Equal(t, WARN, logger.DestinationLevel(ConsoleDestination))
+	assert.True(t, manager.Current().FeatureEnabled("read_only"))
+	assert.Equal(t, manager.Current(), reloaded)
+}
+
+func TestRuntimeConfigManager_InvalidReloadKeepsPrevious(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	writeRuntimeConfig(t, path, `{"feature_flags": {"read_only": true}}`)
+
+	manager, err := NewRuntimeConfigManager(path, nil)
+	require.NoError(t, err)
+
+	writeRuntimeConfig(t, path, `{not json`)
+	assert.Error(t, manager.Reload())
+	assert.True(t, manager.Current().FeatureEnabled("read_only"))
+}
+
+func TestDebugLoggingConfig(t *testing.T) {
+	var config RuntimeConfig
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"debug_logging": {"account_ids": ["account-1"], "api_keys": ["key-1"], "max_body_bytes": 512}
+	}`), &config))
+
+	debug := config.DebugLogging
+	assert.True(t, debug.Enabled())
+	assert.True(t, debug.Matches("account-1", ""))
+	assert.True(t, debug.Matches("", "key-1"))
+	assert.False(t, debug.Matches("account-2", "key-2"))
+	assert.False(t, debug.Matches("", ""))
+	assert.Equal(t, 512, debug.BodyLimit())
+
+	assert.False(t, DebugLoggingConfig{}.Enabled())
+	assert.Equal(t, DefaultDebugLogMaxBodyBytes, DebugLoggingConfig{}.BodyLimit())
+}
+
+func TestRuntimeConfig_ResponseMaskRulesFor(t *testing.T) {
+	var config RuntimeConfig
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"response_masking": {
+			"support": {"mask": ["document_number"], "omit": ["balance"]},
+			"admin": {},
+			"default": {"mask": ["document_number"]}
+		}
+	}`), &config))
+
+	assert.Equal(t, []string{"balance"}, config.ResponseMaskRulesFor("support").Omit)
+	assert.True(t, config.ResponseMaskRulesFor("admin").Empty())
+	assert.Equal(t, []string{"document_number"}, config.ResponseMaskRulesFor("").Mask)
+	assert.Equal(t, []string{"document_number"}, config.ResponseMaskRulesFor("auditor").Mask)
+
+	assert.True(t, (&RuntimeConfig{}).ResponseMaskRulesFor("support").Empty())
+}
@@ -0,0 +1,515 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schema drift modes: what a service does on startup when the live schema differs from the expected one.
+const (
+	SchemaDriftFail = "fail"
+	SchemaDriftWarn = "warn"
+	SchemaDriftOff  = "off"
+)
+
+// expectedColumn is a column of the expected schema with its type as reported by describeColumnType.
+type expectedColumn struct {
+	name     string
+	dataType string
+}
+
+// expectedTable is a table of the expected schema.
+type expectedTable struct {
+	name    string
+	columns []expectedColumn
+}
+
+// expectedSchema is the schema InitSchema creates. InitSchema only adds missing tables and columns, so a
+// column created with another type, e.g. by hand or by an old version, stays as it is; CheckSchemaDrift
+// compares the live schema against this list to find such columns. Keep it in step with InitSchema.
+var expectedSchema = []expectedTable{
+	{"accounts", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"document_number", "varchar(20)"},
+		{"account_type", "varchar(20)"},
+		{"balance", "numeric(15,2)"},
+		{"created_at", "bigint"},
+		{"updated_at", "bigint"},
+		{"status", "varchar(20)"},
+		{"holder_name", "varchar(200)"},
+		{"holder_email", "varchar(200)"},
+		{"holder_phone", "varchar(32)"},
+		{"tags", "varchar(500)"},
+		{"kyc_reference", "varchar(100)"},
+		{"opening_balance", "numeric(15,2)"},
+		{"version", "bigint"},
+		{"tenant_id", "varchar(64)"},
+		{"closed_at", "bigint"},
+		{"anonymized_at", "bigint"},
+		{"overdraft_limit", "numeric(15,2)"},
+	}},
+	{"transactions", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"account_id", "varchar(36)"},
+		{"operation_type", "varchar(50)"},
+		{"amount", "numeric(15,2)"},
+		{"description", "text"},
+		{"created_at", "bigint"},
+		{"status", "varchar(20)"},
+		{"external_id", "varchar(64)"},
+		{"tags", "varchar(500)"},
+		{"metadata", "jsonb"},
+		{"transfer_id", "varchar(36)"},
+		{"original_transaction_id", "varchar(36)"},
+		{"balance", "numeric(15,2)"},
+		{"overdrawn", "boolean"},
+		{"original_currency", "varchar(3)"},
+		{"original_amount", "numeric(15,2)"},
+		{"fx_rate", "numeric(20,10)"},
+	}},
+	{"transaction_edits", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"transaction_id", "varchar(36)"},
+		{"edited_by", "varchar(100)"},
+		{"edited_at", "bigint"},
+		{"previous", "jsonb"},
+		{"updated", "jsonb"},
+	}},
+	{"transaction_resolutions", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"transaction_id", "varchar(36)"},
+		{"action", "varchar(10)"},
+		{"previous_status", "varchar(20)"},
+		{"status", "varchar(20)"},
+		{"reason", "text"},
+		{"resolved_by", "varchar(100)"},
+		{"resolved_at", "bigint"},
+	}},
+	{"transaction_reviews", []expectedColumn{
+		{"transaction_id", "varchar(36)"},
+		{"risk_score", "integer"},
+		{"risk_factors", "varchar(200)"},
+		{"flagged_at", "bigint"},
+		{"decision", "varchar(10)"},
+		{"note", "text"},
+		{"reviewed_by", "varchar(100)"},
+		{"reviewed_at", "bigint"},
+	}},
+	{"disputes", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"transaction_id", "varchar(36)"},
+		{"account_id", "varchar(36)"},
+		{"amount", "numeric(15,2)"},
+		{"reason_code", "varchar(20)"},
+		{"status", "varchar(20)"},
+		{"source", "varchar(20)"},
+		{"opened_at", "bigint"},
+	}},
+	{"tenant_settings", []expectedColumn{
+		{"tenant_id", "varchar(64)"},
+		{"environment", "varchar(32)"},
+		{"settings", "jsonb"},
+		{"updated_at", "bigint"},
+	}},
+	{"balance_adjustments", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"account_id", "varchar(36)"},
+		{"direction", "varchar(10)"},
+		{"amount", "numeric(15,2)"},
+		{"reason_code", "varchar(50)"},
+		{"description", "text"},
+		{"status", "varchar(20)"},
+		{"requested_by", "varchar(100)"},
+		{"requested_at", "bigint"},
+		{"reviewed_by", "varchar(100)"},
+		{"reviewed_at", "bigint"},
+		{"review_note", "text"},
+		{"source", "varchar(100)"},
+		{"reference", "varchar(100)"},
+	}},
+	{"document_changes", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"account_id", "varchar(36)"},
+		{"previous_document_number", "varchar(20)"},
+		{"document_number", "varchar(20)"},
+		{"status", "varchar(20)"},
+		{"reason", "text"},
+		{"requested_by", "varchar(100)"},
+		{"requested_at", "bigint"},
+		{"verification_reference", "varchar(100)"},
+		{"verified_by", "varchar(100)"},
+		{"verified_at", "bigint"},
+		{"verification_note", "text"},
+		{"applied_by", "varchar(100)"},
+		{"applied_at", "bigint"},
+	}},
+	{"account_audit", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"account_id", "varchar(36)"},
+		{"action", "varchar(20)"},
+		{"actor", "varchar(100)"},
+		{"actor_role", "varchar(50)"},
+		{"changed_at", "bigint"},
+		{"previous", "jsonb"},
+		{"updated", "jsonb"},
+	}},
+	{"account_snapshots", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"account_id", "varchar(36)"},
+		{"operation", "varchar(20)"},
+		{"taken_by", "varchar(100)"},
+		{"taken_by_role", "varchar(50)"},
+		{"taken_at", "bigint"},
+		{"account", "jsonb"},
+		{"transactions", "jsonb"},
+		{"restored_at", "bigint"},
+		{"restored_by", "varchar(100)"},
+	}},
+	{"operation_type_rules", []expectedColumn{
+		{"operation_type", "varchar(50)"},
+		{"direction", "varchar(10)"},
+		{"requires_positive_amount", "boolean"},
+		{"allowed_account_types", "varchar(100)"},
+		{"fee_policy", "varchar(20)"},
+		{"updated_at", "bigint"},
+	}},
+	{"event_outbox", []expectedColumn{
+		{"id", "bigint"},
+		{"event_id", "varchar(36)"},
+		{"event_type", "varchar(64)"},
+		{"partition_key", "varchar(64)"},
+		{"envelope", "bytea"},
+		{"created_at", "bigint"},
+		{"published_at", "bigint"},
+		{"attempts", "integer"},
+		{"last_error", "text"},
+	}},
+	{"webhook_signing_keys", []expectedColumn{
+		{"subscriber", "varchar(64)"},
+		{"key_id", "varchar(20)"},
+		{"secret", "varchar(100)"},
+		{"status", "varchar(10)"},
+		{"created_by", "varchar(100)"},
+		{"created_at", "bigint"},
+		{"retired_by", "varchar(100)"},
+		{"retired_at", "bigint"},
+	}},
+	{"retention_reports", []expectedColumn{
+		{"id", "bigint"},
+		{"tenant_id", "varchar(64)"},
+		{"policy", "varchar(50)"},
+		{"dry_run", "boolean"},
+		{"affected", "bigint"},
+		{"cutoff", "bigint"},
+		{"run_at", "bigint"},
+	}},
+	{"access_audit_log", []expectedColumn{
+		{"id", "bigint"},
+		{"service", "varchar(50)"},
+		{"method", "varchar(200)"},
+		{"principal", "varchar(100)"},
+		{"role", "varchar(50)"},
+		{"tenant_id", "varchar(64)"},
+		{"request_id", "varchar(64)"},
+		{"decision", "varchar(5)"},
+		{"reason", "varchar(200)"},
+		{"occurred_at", "bigint"},
+	}},
+	{"fx_rates", []expectedColumn{
+		{"base_currency", "varchar(3)"},
+		{"quote_currency", "varchar(3)"},
+		{"rate_date", "bigint"},
+		{"rate", "numeric(20,10)"},
+	}},
+	{"fx_revaluations", []expectedColumn{
+		{"id", "bigint"},
+		{"tenant_id", "varchar(64)"},
+		{"account_id", "varchar(36)"},
+		{"period", "varchar(7)"},
+		{"currency", "varchar(3)"},
+		{"reporting_currency", "varchar(3)"},
+		{"balance", "numeric(15,2)"},
+		{"closing_rate", "numeric(20,10)"},
+		{"carrying_value", "numeric(18,2)"},
+		{"revalued_value", "numeric(18,2)"},
+		{"unrealized_gain", "numeric(18,2)"},
+		{"created_at", "bigint"},
+	}},
+	{"account_budgets", []expectedColumn{
+		{"account_id", "varchar(36)"},
+		{"category", "varchar(32)"},
+		{"monthly_limit", "numeric(15,2)"},
+		{"thresholds", "varchar(100)"},
+		{"created_at", "bigint"},
+		{"updated_at", "bigint"},
+	}},
+	{"transaction_batches", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"source", "varchar(100)"},
+		{"expected_count", "integer"},
+		{"created_by", "varchar(100)"},
+		{"created_at", "bigint"},
+	}},
+	{"transaction_batch_items", []expectedColumn{
+		{"batch_id", "varchar(36)"},
+		{"item", "integer"},
+		{"transaction_id", "varchar(36)"},
+	}},
+	{"transaction_batch_failures", []expectedColumn{
+		{"batch_id", "varchar(36)"},
+		{"item", "integer"},
+		{"account_id", "varchar(36)"},
+		{"external_id", "varchar(64)"},
+		{"error", "varchar(200)"},
+		{"failed_at", "bigint"},
+	}},
+	{"statements", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"account_id", "varchar(36)"},
+		{"period", "varchar(7)"},
+		{"period_start", "bigint"},
+		{"period_end", "bigint"},
+		{"opening_balance", "numeric(18,2)"},
+		{"closing_balance", "numeric(18,2)"},
+		{"adjustments", "numeric(18,2)"},
+		{"totals", "jsonb"},
+		{"transaction_count", "bigint"},
+		{"generated_at", "bigint"},
+	}},
+	{"recurring_rules", []expectedColumn{
+		{"id", "varchar(36)"},
+		{"account_id", "varchar(36)"},
+		{"tenant_id", "varchar(64)"},
+		{"operation_type", "varchar(50)"},
+		{"amount", "numeric(15,2)"},
+		{"description", "text"},
+		{"frequency", "varchar(10)"},
+		{"start_at", "bigint"},
+		{"end_at", "bigint"},
+		{"next_run_at", "bigint"},
+		{"status", "varchar(10)"},
+		{"run_count", "integer"},
+		{"skipped_count", "integer"},
+		{"last_run_at", "bigint"},
+		{"last_transaction_id", "varchar(36)"},
+		{"last_error", "varchar(200)"},
+		{"created_by", "varchar(100)"},
+		{"created_at", "bigint"},
+		{"cancelled_at", "bigint"},
+	}},
+	{"recurring_rule_runs", []expectedColumn{
+		{"rule_id", "varchar(36)"},
+		{"run", "integer"},
+		{"scheduled_at", "bigint"},
+		{"transaction_id", "varchar(36)"},
+	}},
+	{"installments", []expectedColumn{
+		{"purchase_id", "varchar(36)"},
+		{"number", "integer"},
+		{"amount", "numeric(15,2)"},
+		{"due_at", "bigint"},
+		{"status", "varchar(10)"},
+		{"next_attempt_at", "bigint"},
+		{"attempts", "integer"},
+		{"last_error", "varchar(200)"},
+		{"transaction_id", "varchar(36)"},
+		{"paid_at", "bigint"},
+		{"tenant_id", "varchar(64)"},
+	}},
+	{"message_templates", []expectedColumn{
+		{"tenant_id", "varchar(64)"},
+		{"event_type", "varchar(64)"},
+		{"channel", "varchar(10)"},
+		{"subject", "varchar(200)"},
+		{"body", "text"},
+		{"updated_by", "varchar(100)"},
+		{"updated_at", "bigint"},
+	}},
+	{"payment_discharges", []expectedColumn{
+		{"payment_id", "varchar(36)"},
+		{"debt_id", "varchar(36)"},
+		{"amount", "numeric(15,2)"},
+	}},
+	{"transaction_daily_rollups", []expectedColumn{
+		{"account_id", "varchar(36)"},
+		{"day_start", "bigint"},
+		{"operation_type", "varchar(50)"},
+		{"txn_count", "bigint"},
+		{"total_amount", "numeric(18,2)"},
+	}},
+	{"transaction_monthly_counts", []expectedColumn{
+		{"account_id", "varchar(36)"},
+		{"month_start", "bigint"},
+		{"txn_count", "bigint"},
+	}},
+	{"business_days", []expectedColumn{
+		{"day_start", "bigint"},
+		{"closed_at", "bigint"},
+		{"closed_by", "varchar(100)"},
+		{"account_count", "bigint"},
+		{"transaction_count", "bigint"},
+		{"net_amount", "numeric(18,2)"},
+	}},
+	{"end_of_day_balances", []expectedColumn{
+		{"account_id", "varchar(36)"},
+		{"day_start", "bigint"},
+		{"balance", "numeric(18,2)"},
+		{"transaction_count", "bigint"},
+		{"net_amount", "numeric(18,2)"},
+	}},
+}
+
+// SchemaDrift is a difference between the live schema and the expected one. Actual is empty when
+// the table or column is missing.
+type SchemaDrift struct {
+	Table    string
+	Column   string
+	Expected string
+	Actual   string
+}
+
+// String describes the drift, e.g. "transactions.created_at: expected bigint, found timestamp without time zone".
+func (d SchemaDrift) String() string {
+	switch {
+	case d.Column == "":
+		return fmt.Sprintf("%s: table missing", d.Table)
+	case d.Actual == "":
+		return fmt.Sprintf("%s.%s: column missing, expected %s", d.Table, d.Column, d.Expected)
+	default:
+		return fmt.Sprintf("%s.%s: expected %s, found %s", d.Table, d.Column, d.Expected, d.Actual)
+	}
+}
+
+// describeColumnType formats a column type from information_schema.columns the way expectedSchema lists it.
+func describeColumnType(dataType string, maxLength, precision, scale sql.NullInt64) string {
+	switch {
+	case dataType == "character varying" && maxLength.Valid:
+		return fmt.Sprintf("varchar(%d)", maxLength.Int64)
+	case dataType == "numeric" && precision.Valid && scale.Valid:
+		return fmt.Sprintf("numeric(%d,%d)", precision.Int64, scale.Int64)
+	default:
+		return dataType
+	}
+}
+
+// CheckSchemaDrift compares the tables and columns of the current schema against the schema InitSchema
+// creates and returns the missing tables, missing columns and columns of another type, in table order.
+// Columns that are not expected are ignored.
+func CheckSchemaDrift(ctx context.Context, db *sql.DB) ([]SchemaDrift, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT table_name, column_name, data_type, character_maximum_length, numeric_precision, numeric_scale
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the schema: %w", err)
+	}
+	defer rows.Close()
+
+	live := make(map[string]map[string]string)
+	for rows.Next() {
+		var table, column, dataType string
+		var maxLength, precision, scale sql.NullInt64
+		if err := rows.Scan(&table, &column, &dataType, &maxLength, &precision, &scale); err != nil {
+			return nil, fmt.Errorf("failed to read the schema: %w", err)
+		}
+		if live[table] == nil {
+			live[table] = make(map[string]string)
+		}
+		live[table][column] = describeColumnType(dataType, maxLength, precision, scale)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the schema: %w", err)
+	}
+
+	var drifts []SchemaDrift
+	for _, table := range expectedSchema {
+		columns, ok := live[table.name]
+		if !ok {
+			drifts = append(drifts, SchemaDrift{Table: table.name})
+			continue
+		}
+		for _, column := range table.columns {
+			if actual := columns[column.name]; actual != column.dataType {
+				drifts = append(drifts, SchemaDrift{Table: table.name, Column: column.name, Expected: column.dataType, Actual: actual})
+			}
+		}
+	}
+	return drifts, nil
+}
+
+// SchemaDriftModeFromEnv reads SCHEMA_DRIFT_MODE: fail (default), warn or off. Invalid values fall back to fail.
+func SchemaDriftModeFromEnv() string {
+	switch mode := getEnv("SCHEMA_DRIFT_MODE", SchemaDriftFail); mode {
+	case SchemaDriftWarn, SchemaDriftOff:
+		return mode
+	default:
+		return SchemaDriftFail
+	}
+}
+
+// VerifySchema checks the live schema for drift after InitSchema. Every drift is logged as an error; in fail
+// mode VerifySchema then returns an error, so the service refuses to serve against a schema its queries may
+// misread, e.g. a created_at column holding timestamps instead of Unix seconds.
+func (dm *DatabaseManager) VerifySchema(ctx context.Context, logger *Logger, mode string) error {
+	if mode == SchemaDriftOff {
+		return nil
+	}
+
+	start := time.Now()
+	drifts, err := CheckSchemaDrift(ctx, dm.db)
+	logger.LogDatabase("SELECT", "information_schema.columns", time.Since(start), err)
+	if err != nil {
+		return err
+	}
+	if len(drifts) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, len(drifts))
+	for i, drift := range drifts {
+		descriptions[i] = drift.String()
+		logger.Error("Schema drift: %s", descriptions[i])
+	}
+	if mode == SchemaDriftWarn {
+		logger.Warn("Serving despite %d schema drifts (SCHEMA_DRIFT_MODE=warn)", len(drifts))
+		return nil
+	}
+	return fmt.Errorf("%d schema drifts: %s", len(drifts), strings.Join(descriptions, "; "))
+}
+
+// PlanMigrations is a dry run of InitSchema: it returns the tables and columns InitSchema would add to the live
+// schema, without changing it. Columns of another type, which InitSchema would leave as they are, are then
+// handled as by VerifySchema in the given mode.
+func (dm *DatabaseManager) PlanMigrations(ctx context.Context, logger *Logger, mode string) ([]SchemaDrift, error) {
+	start := time.Now()
+	drifts, err := CheckSchemaDrift(ctx, dm.db)
+	logger.LogDatabase("SELECT", "information_schema.columns", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []SchemaDrift
+	var conflicts []string
+	for _, drift := range drifts {
+		if drift.Actual == "" {
+			pending = append(pending, drift)
+		} else {
+			conflicts = append(conflicts, drift.String())
+		}
+	}
+	if len(conflicts) == 0 || mode == SchemaDriftOff {
+		return pending, nil
+	}
+	for _, conflict := range conflicts {
+		logger.Error("Schema drift: %s", conflict)
+	}
+	if mode == SchemaDriftWarn {
+		logger.Warn("Ignoring %d schema drifts (SCHEMA_DRIFT_MODE=warn)", len(conflicts))
+		return pending, nil
+	}
+	return pending, fmt.Errorf("%d schema drifts: %s", len(conflicts), strings.Join(conflicts, "; "))
+}
@@ -0,0 +1,173 @@
+package common
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var schemaColumns = []string{"table_name", "column_name", "data_type", "character_maximum_length", "numeric_precision", "numeric_scale"}
+
+// liveSchemaRows returns the information_schema rows of the expected schema, with the types of the given
+// columns replaced and the tables and columns mapped to "" left out.
+func liveSchemaRows(overrides map[string]string) *sqlmock.Rows {
+	rows := sqlmock.NewRows(schemaColumns)
+	for _, table := range expectedSchema {
+		if override, ok := overrides[table.name]; ok && override == "" {
+			continue
+		}
+		for _, column := range table.columns {
+			dataType := column.dataType
+			if override, ok := overrides[table.name+"."+column.name]; ok {
+				if override == "" {
+					continue
+				}
+				dataType = override
+			}
+
+			var maxLength, precision, scale interface{}
+			switch {
+			case strings.HasPrefix(dataType, "varchar("):
+				maxLength, _ = strconv.Atoi(strings.Trim(dataType[len("varchar"):], "()"))
+				dataType = "character varying"
+			case strings.HasPrefix(dataType, "numeric("):
+				parts := strings.Split(strings.Trim(dataType[len("numeric"):], "()"), ",")
+				precision, _ = strconv.Atoi(parts[0])
+				scale, _ = strconv.Atoi(parts[1])
+				dataType = "numeric"
+			}
+			rows.AddRow(table.name, column.name, dataType, maxLength, precision, scale)
+		}
+	}
+	rows.AddRow("accounts", "legacy_flag", "boolean", nil, nil, nil)
+	return rows
+}
+
+func TestCheckSchemaDrift(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		expected  []string
+	}{
+		{
+			name: "matching schema",
+		},
+		{
+			name: "drifted schema",
+			overrides: map[string]string{
+				"transactions.created_at": "timestamp without time zone",
+				"accounts.holder_name":    "varchar(100)",
+				"accounts.anonymized_at":  "",
+				"account_budgets":         "",
+			},
+			expected: []string{
+				"accounts.holder_name: expected varchar(200), found varchar(100)",
+				"accounts.anonymized_at: column missing, expected bigint",
+				"transactions.created_at: expected bigint, found timestamp without time zone",
+				"account_budgets: table missing",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.columns")).WillReturnRows(liveSchemaRows(tt.overrides))
+
+			drifts, err := CheckSchemaDrift(context.Background(), db)
+			require.NoError(t, err)
+
+			var descriptions []string
+			for _, drift := range drifts {
+				descriptions = append(descriptions, drift.String())
+			}
+			assert.Equal(t, tt.expected, descriptions)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDatabaseManager_VerifySchema(t *testing.T) {
+	logger, err := NewLogger("test-schema", INFO)
+	require.NoError(t, err)
+	drifted := map[string]string{"event_outbox.created_at": "integer"}
+
+	tests := []struct {
+		name          string
+		mode          string
+		expectQuery   bool
+		expectedError string
+	}{
+		{name: "fail", mode: SchemaDriftFail, expectQuery: true, expectedError: "1 schema drifts: event_outbox.created_at: expected bigint, found integer"},
+		{name: "warn", mode: SchemaDriftWarn, expectQuery: true},
+		{name: "off", mode: SchemaDriftOff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			if tt.expectQuery {
+				mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.columns")).WillReturnRows(liveSchemaRows(drifted))
+			}
+
+			err = (&DatabaseManager{db: db}).VerifySchema(context.Background(), logger, tt.mode)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedError)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDatabaseManager_PlanMigrations(t *testing.T) {
+	logger, err := NewLogger("test-schema", INFO)
+	require.NoError(t, err)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Missing tables and columns are what InitSchema would add; a column of another type is drift
+	mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.columns")).WillReturnRows(liveSchemaRows(map[string]string{
+		"accounts.anonymized_at":  "",
+		"account_budgets":         "",
+		"event_outbox.created_at": "integer",
+	}))
+	pending, err := (&DatabaseManager{db: db}).PlanMigrations(context.Background(), logger, SchemaDriftFail)
+	assert.EqualError(t, err, "1 schema drifts: event_outbox.created_at: expected bigint, found integer")
+
+	var descriptions []string
+	for _, drift := range pending {
+		descriptions = append(descriptions, drift.String())
+	}
+	assert.Equal(t, []string{"accounts.anonymized_at: column missing, expected bigint", "account_budgets: table missing"}, descriptions)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.columns")).WillReturnRows(liveSchemaRows(map[string]string{
+		"event_outbox.created_at": "integer",
+	}))
+	pending, err = (&DatabaseManager{db: db}).PlanMigrations(context.Background(), logger, SchemaDriftWarn)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSchemaDriftModeFromEnv(t *testing.T) {
+	for value, expected := range map[string]string{"": SchemaDriftFail, "warn": SchemaDriftWarn, "off": SchemaDriftOff, "loud": SchemaDriftFail} {
+		t.Setenv("SCHEMA_DRIFT_MODE", value)
+		assert.Equal(t, expected, SchemaDriftModeFromEnv(), value)
+	}
+}
@@ -0,0 +1,90 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultSelfTestTimeout bounds each check of a self-test.
+const DefaultSelfTestTimeout = 10 * time.Second
+
+// SelfTest runs the checks of a service started with --selftest: its configuration, its dependencies and its
+// pending migrations, without serving. Every check runs even after one fails, so a single run reports every
+// problem; the service then exits non-zero if any failed, which makes the self-test usable as an init container.
+type SelfTest struct {
+	Timeout time.Duration
+	logger  *Logger
+	failed  []string
+}
+
+// NewSelfTest creates a self-test logging the outcome of each check to logger.
+func NewSelfTest(logger *Logger) *SelfTest {
+	return &SelfTest{Timeout: DefaultSelfTestTimeout, logger: logger}
+}
+
+// Check runs check with a deadline of Timeout and records whether it passed.
+func (t *SelfTest) Check(name string, check func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := check(ctx); err != nil {
+		t.failed = append(t.failed, name)
+		t.logger.Error("Self-test %s: FAIL after %s: %v", name, time.Since(start).Round(time.Millisecond), err)
+		return
+	}
+	t.logger.Info("Self-test %s: ok (%s)", name, time.Since(start).Round(time.Millisecond))
+}
+
+// Err returns an error naming the failed checks, or nil if all passed.
+func (t *SelfTest) Err() error {
+	if len(t.failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d self-test checks failed: %s", len(t.failed), strings.Join(t.failed, ", "))
+}
+
+// ExitCode logs the result of the self-test and returns the exit code of the service: 0 if every check passed,
+// 1 otherwise.
+func (t *SelfTest) ExitCode() int {
+	if err := t.Err(); err != nil {
+		t.logger.Error("Self-test failed: %v", err)
+		return 1
+	}
+	t.logger.Info("Self-test passed")
+	return 0
+}
+
+// CheckDatabase connects to the database configured in the environment, once, and checks the migrations
+// InitSchema would run against it in dry-run. Pending migrations are logged; columns of another type fail
+// the check unless SCHEMA_DRIFT_MODE allows them. The connection is closed afterwards.
+func (t *SelfTest) CheckDatabase() {
+	var dbManager *DatabaseManager
+	t.Check("database", func(ctx context.Context) error {
+		var err error
+		dbManager, err = NewDatabaseManagerContext(ctx)
+		return err
+	})
+	if dbManager == nil {
+		return
+	}
+	defer dbManager.Close()
+
+	t.Check("migrations", func(ctx context.Context) error {
+		pending, err := dbManager.PlanMigrations(ctx, t.logger, SchemaDriftModeFromEnv())
+		for _, drift := range pending {
+			t.logger.Info("Migration pending: %s", drift)
+		}
+		return err
+	})
+}
+
+// CheckRuntimeConfig loads the runtime config file at path, if any, and validates it as Reload does.
+func (t *SelfTest) CheckRuntimeConfig(path string) {
+	t.Check("runtime config", func(ctx context.Context) error {
+		_, err := NewRuntimeConfigManager(path, t.logger)
+		return err
+	})
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTest(t *testing.T) {
+	var output bytes.Buffer
+	logger := &Logger{core: &logCore{serviceName: "selftest"}}
+	logger.AddSink(ConsoleDestination, NewWriterSink(&output), INFO)
+
+	selfTest := NewSelfTest(logger)
+	selfTest.Timeout = 50 * time.Millisecond
+	selfTest.Check("config", func(ctx context.Context) error { return nil })
+	assert.NoError(t, selfTest.Err())
+
+	// Every check runs, even after one failed, and checks are bounded by the timeout
+	selfTest.Check("database", func(ctx context.Context) error { return errors.New("connection refused") })
+	selfTest.Check("backend", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.EqualError(t, selfTest.Err(), "2 self-test checks failed: database, backend")
+	assert.Equal(t, 1, selfTest.ExitCode())
+
+	assert.Contains(t, output.String(), "Self-test config: ok")
+	assert.Contains(t, output.String(), "Self-test database: FAIL")
+	assert.Contains(t, output.String(), "connection refused")
+	assert.Contains(t, output.String(), "context deadline exceeded")
+}
+
+func TestSelfTest_CheckRuntimeConfig(t *testing.T) {
+	logger, err := NewLogger("test-selftest", INFO)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "runtime.json")
+
+	writeRuntimeConfig(t, path, `{"log_level": "DEBUG"}`)
+	selfTest := NewSelfTest(logger)
+	selfTest.CheckRuntimeConfig(path)
+	assert.NoError(t, selfTest.Err())
+
+	writeRuntimeConfig(t, path, `{"authorization": {"routes": {"/accounts": {"roles": ["admin"]}}}}`)
+	selfTest.CheckRuntimeConfig(path)
+	assert.Error(t, selfTest.Err())
+}
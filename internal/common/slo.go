@@ -0,0 +1,198 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SLOTarget is the objective of one gRPC method: Availability percent of its calls must succeed within
+// LatencyMs, e.g. 99.9 and 300. A call that fails or takes longer is bad and spends error budget.
+type SLOTarget struct {
+	Availability float64 `json:"availability"`
+	LatencyMs    int64   `json:"latency_ms"`
+}
+
+// ValidateSLOTargets reports the first malformed target, keyed by full gRPC method name.
+func ValidateSLOTargets(targets map[string]SLOTarget) error {
+	for method, target := range targets {
+		if !strings.HasPrefix(method, "/") || strings.Count(method, "/") != 2 {
+			return fmt.Errorf("invalid SLO method %q: expected a full gRPC method name, e.g. \"/transaction.TransactionService/CreateTransaction\"", method)
+		}
+		if target.Availability <= 0 || target.Availability >= 100 {
+			return fmt.Errorf("availability of %s must be between 0 and 100 percent, exclusive", method)
+		}
+		if target.LatencyMs <= 0 {
+			return fmt.Errorf("latency_ms of %s must be positive", method)
+		}
+	}
+	return nil
+}
+
+// SLOWindows are the windows burn rates are reported over. The short one shows a fast burn as it happens; the
+// long ones whether it lasted.
+var SLOWindows = []time.Duration{5 * time.Minute, time.Hour, 6 * time.Hour}
+
+// sloBucketCount is the number of one-minute buckets kept per method, enough for the longest window.
+const sloBucketCount = 360
+
+// sloBucket counts the calls of one minute.
+type sloBucket struct {
+	minute int64
+	total  int64
+	bad    int64
+}
+
+// SLOWindowStatus is the performance of a method over one window. BurnRate is how fast the window spends the
+// error budget: 1 spends exactly the budget the target allows, 10 spends it ten times as fast.
+type SLOWindowStatus struct {
+	Window         time.Duration
+	Total          int64
+	Bad            int64
+	SuccessPercent float64
+	BurnRate       float64
+}
+
+// SLOMethodStatus is the status of a method with a target. It is within budget while its burn rate over the
+// longest window is at most 1.
+type SLOMethodStatus struct {
+	Method       string
+	Target       SLOTarget
+	Windows      []SLOWindowStatus
+	WithinBudget bool
+}
+
+// SLOTracker records the outcome and latency of the calls to the methods with an SLO target, in one-minute
+// buckets, and reports their burn rates. Targets come from the slo_targets section of the runtime config;
+// calls to other methods are not recorded. It is safe for concurrent use.
+type SLOTracker struct {
+	mu      sync.Mutex
+	targets map[string]SLOTarget
+	buckets map[string]*[sloBucketCount]sloBucket
+	now     func() time.Time
+}
+
+// NewSLOTracker creates a tracker without targets.
+func NewSLOTracker() *SLOTracker {
+	return &SLOTracker{
+		targets: make(map[string]SLOTarget),
+		buckets: make(map[string]*[sloBucketCount]sloBucket),
+		now:     time.Now,
+	}
+}
+
+// ApplyRuntimeConfig replaces the targets. Methods that keep a target keep their recorded calls.
+func (t *SLOTracker) ApplyRuntimeConfig(config *RuntimeConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.targets = make(map[string]SLOTarget, len(config.SLOTargets))
+	for method, target := range config.SLOTargets {
+		t.targets[method] = target
+	}
+	for method := range t.buckets {
+		if _, ok := t.targets[method]; !ok {
+			delete(t.buckets, method)
+		}
+	}
+}
+
+// Record counts a call to method that took duration; it is bad if it failed or was slower than the target.
+func (t *SLOTracker) Record(method string, duration time.Duration, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	target, ok := t.targets[method]
+	if !ok {
+		return
+	}
+	buckets := t.buckets[method]
+	if buckets == nil {
+		buckets = new([sloBucketCount]sloBucket)
+		t.buckets[method] = buckets
+	}
+
+	minute := t.now().Unix() / 60
+	bucket := &buckets[minute%sloBucketCount]
+	if bucket.minute != minute {
+		*bucket = sloBucket{minute: minute}
+	}
+	bucket.total++
+	if failed || duration > time.Duration(target.LatencyMs)*time.Millisecond {
+		bucket.bad++
+	}
+}
+
+// Status returns the status of every method with a target, sorted by method. Windows include the current minute.
+func (t *SLOTracker) Status() []SLOMethodStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	minute := t.now().Unix() / 60
+	statuses := make([]SLOMethodStatus, 0, len(t.targets))
+	for method, target := range t.targets {
+		methodStatus := SLOMethodStatus{Method: method, Target: target, WithinBudget: true}
+		budget := 1 - target.Availability/100
+		for _, window := range SLOWindows {
+			windowStatus := SLOWindowStatus{Window: window, SuccessPercent: 100}
+			if buckets := t.buckets[method]; buckets != nil {
+				for _, bucket := range buckets {
+					if bucket.total > 0 && minute-bucket.minute < int64(window/time.Minute) {
+						windowStatus.Total += bucket.total
+						windowStatus.Bad += bucket.bad
+					}
+				}
+			}
+			if windowStatus.Total > 0 {
+				badRatio := float64(windowStatus.Bad) / float64(windowStatus.Total)
+				windowStatus.SuccessPercent = 100 * (1 - badRatio)
+				windowStatus.BurnRate = badRatio / budget
+			}
+			methodStatus.Windows = append(methodStatus.Windows, windowStatus)
+		}
+		methodStatus.WithinBudget = methodStatus.Windows[len(methodStatus.Windows)-1].BurnRate <= 1
+		statuses = append(statuses, methodStatus)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Method < statuses[j].Method })
+	return statuses
+}
+
+// sloServerErrorCodes are the status codes of calls that failed on the server's side. Other codes, e.g.
+// InvalidArgument or PermissionDenied, are the caller's doing and do not spend error budget.
+var sloServerErrorCodes = map[codes.Code]bool{
+	codes.Unknown:          true,
+	codes.Internal:         true,
+	codes.Unavailable:      true,
+	codes.DataLoss:         true,
+	codes.DeadlineExceeded: true,
+}
+
+// sloCallFailed reports whether a call failed on the server's side: with a server error status, or with a
+// response whose error field reports a server error, such as "database error" or "could not create account".
+func sloCallFailed(resp interface{}, err error) bool {
+	if err != nil {
+		return sloServerErrorCodes[status.Code(err)]
+	}
+	withError, ok := resp.(interface{ GetError() string })
+	if !ok {
+		return false
+	}
+	message := withError.GetError()
+	return message == "database error" || strings.HasPrefix(message, "could not ")
+}
+
+// SLOUnaryServerInterceptor returns a server interceptor recording every unary call in tracker. It should run
+// early in the chain, so the latency covers the other interceptors too. Streams are not tracked.
+func SLOUnaryServerInterceptor(tracker *SLOTracker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		tracker.Record(info.FullMethod, time.Since(start), sloCallFailed(resp, err))
+		return resp, err
+	}
+}
@@ -0,0 +1,115 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const createTransactionMethod = "/transaction.TransactionService/CreateTransaction"
+
+func TestValidateSLOTargets(t *testing.T) {
+	assert.NoError(t, ValidateSLOTargets(map[string]SLOTarget{createTransactionMethod: {Availability: 99.9, LatencyMs: 300}}))
+	assert.Error(t, ValidateSLOTargets(map[string]SLOTarget{"CreateTransaction": {Availability: 99.9, LatencyMs: 300}}))
+	assert.Error(t, ValidateSLOTargets(map[string]SLOTarget{createTransactionMethod: {Availability: 100, LatencyMs: 300}}))
+	assert.Error(t, ValidateSLOTargets(map[string]SLOTarget{createTransactionMethod: {Availability: 99.9}}))
+
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	writeRuntimeConfig(t, path, `{"slo_targets": {"/transaction.TransactionService/CreateTransaction": {"availability": 99.9, "latency_ms": 0}}}`)
+	_, err := NewRuntimeConfigManager(path, nil)
+	assert.Error(t, err)
+}
+
+func TestSLOTracker(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tracker := NewSLOTracker()
+	tracker.now = func() time.Time { return now }
+	tracker.ApplyRuntimeConfig(&RuntimeConfig{SLOTargets: map[string]SLOTarget{
+		createTransactionMethod: {Availability: 99, LatencyMs: 300},
+	}})
+
+	// Two hours ago: 100 calls, 4 bad; only the 6 hour window sees them
+	now = now.Add(-2 * time.Hour)
+	for i := 0; i < 100; i++ {
+		tracker.Record(createTransactionMethod, 10*time.Millisecond, i < 4)
+	}
+	// Now: 10 calls, one slow and one failed
+	now = now.Add(2 * time.Hour)
+	for i := 0; i < 8; i++ {
+		tracker.Record(createTransactionMethod, 100*time.Millisecond, false)
+	}
+	tracker.Record(createTransactionMethod, 400*time.Millisecond, false)
+	tracker.Record(createTransactionMethod, time.Millisecond, true)
+	// Methods without a target are not recorded
+	tracker.Record("/transaction.TransactionService/GetTransaction", time.Second, true)
+
+	statuses := tracker.Status()
+	require.Len(t, statuses, 1)
+	slo := statuses[0]
+	assert.Equal(t, createTransactionMethod, slo.Method)
+	require.Len(t, slo.Windows, 3)
+
+	assert.Equal(t, 5*time.Minute, slo.Windows[0].Window)
+	assert.Equal(t, int64(10), slo.Windows[0].Total)
+	assert.Equal(t, int64(2), slo.Windows[0].Bad)
+	assert.InDelta(t, 80, slo.Windows[0].SuccessPercent, 0.001)
+	assert.InDelta(t, 20, slo.Windows[0].BurnRate, 0.001)
+	assert.Equal(t, int64(10), slo.Windows[1].Total)
+
+	assert.Equal(t, int64(110), slo.Windows[2].Total)
+	assert.Equal(t, int64(6), slo.Windows[2].Bad)
+	assert.InDelta(t, 6.0/110/0.01, slo.Windows[2].BurnRate, 0.001)
+	assert.False(t, slo.WithinBudget)
+
+	// Calls age out of every window
+	now = now.Add(7 * time.Hour)
+	slo = tracker.Status()[0]
+	assert.Zero(t, slo.Windows[2].Total)
+	assert.Equal(t, float64(100), slo.Windows[2].SuccessPercent)
+	assert.True(t, slo.WithinBudget)
+
+	// Dropping a target drops its method
+	tracker.ApplyRuntimeConfig(&RuntimeConfig{})
+	assert.Empty(t, tracker.Status())
+}
+
+// errorResponse is a response reporting its error in a field, like the services' responses.
+type errorResponse struct{ err string }
+
+func (r errorResponse) GetError() string { return r.err }
+
+func TestSLOUnaryServerInterceptor(t *testing.T) {
+	tracker := NewSLOTracker()
+	tracker.ApplyRuntimeConfig(&RuntimeConfig{SLOTargets: map[string]SLOTarget{
+		createTransactionMethod: {Availability: 99.9, LatencyMs: 1000},
+	}})
+	interceptor := SLOUnaryServerInterceptor(tracker)
+	info := &grpc.UnaryServerInfo{FullMethod: createTransactionMethod}
+	call := func(resp interface{}, err error) {
+		interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return resp, err
+		})
+	}
+
+	call(errorResponse{}, nil)
+	// Errors of the caller's doing do not count
+	call(errorResponse{err: "insufficient balance"}, nil)
+	call(nil, status.Error(codes.InvalidArgument, "invalid amount"))
+	// Server errors do, whether reported in the response or as a status
+	call(errorResponse{err: "database error"}, nil)
+	call(errorResponse{err: "could not create transaction"}, nil)
+	call(nil, status.Error(codes.Unavailable, "unavailable"))
+	call(nil, errors.New("boom"))
+
+	window := tracker.Status()[0].Windows[0]
+	assert.Equal(t, int64(7), window.Total)
+	assert.Equal(t, int64(4), window.Bad)
+}
@@ -0,0 +1,132 @@
+package common
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// DefaultStaleResponseTTL is how long a successful read response is kept to be served while its backend is unavailable.
+const DefaultStaleResponseTTL = 10 * time.Minute
+
+// Bounds of the stale response cache, so large or numerous responses cannot exhaust the gateway's memory.
+const (
+	maxStaleResponseEntries = 10000
+	maxStaleResponseBytes   = 256 * 1024
+)
+
+// StaleResponseCache keeps the latest successful response of each read, so the gateway can still answer it,
+// marked as stale, while the backend service that produces it is unavailable. A TTL of zero disables the cache.
+// It is safe for concurrent use.
+type StaleResponseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]staleResponse
+	now     func() time.Time
+}
+
+type staleResponse struct {
+	body     []byte
+	storedAt time.Time
+}
+
+// NewStaleResponseCache creates a stale response cache whose entries expire after ttl.
+func NewStaleResponseCache(ttl time.Duration) *StaleResponseCache {
+	return &StaleResponseCache{
+		ttl:     ttl,
+		entries: make(map[string]staleResponse),
+		now:     time.Now,
+	}
+}
+
+// NewStaleResponseCacheFromEnv creates a stale response cache with the TTL from the STALE_RESPONSE_TTL
+// environment variable, defaulting to DefaultStaleResponseTTL. Set it to 0 to disable the cache.
+func NewStaleResponseCacheFromEnv() *StaleResponseCache {
+	ttl, err := time.ParseDuration(getEnv("STALE_RESPONSE_TTL", DefaultStaleResponseTTL.String()))
+	if err != nil || ttl < 0 {
+		ttl = DefaultStaleResponseTTL
+	}
+	return NewStaleResponseCache(ttl)
+}
+
+// Store records body as the latest response for key. Bodies larger than maxStaleResponseBytes are not kept,
+// and neither are new keys when the cache is full and no entries have expired.
+func (c *StaleResponseCache) Store(key string, body []byte) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(body) > maxStaleResponseBytes {
+		delete(c.entries, key)
+		return
+	}
+	now := c.now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxStaleResponseEntries {
+		c.evictExpired(now)
+		if len(c.entries) >= maxStaleResponseEntries {
+			return
+		}
+	}
+	c.entries[key] = staleResponse{body: append([]byte(nil), body...), storedAt: now}
+}
+
+// Load returns the latest response stored for key and when it was stored, if it has not expired.
+func (c *StaleResponseCache) Load(key string) ([]byte, time.Time, bool) {
+	if c.ttl <= 0 {
+		return nil, time.Time{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	if !c.now().Before(entry.storedAt.Add(c.ttl)) {
+		delete(c.entries, key)
+		return nil, time.Time{}, false
+	}
+	return entry.body, entry.storedAt, true
+}
+
+// Len returns the number of entries currently held, including expired ones not yet evicted.
+func (c *StaleResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// evictExpired drops all entries whose TTL has elapsed.
+func (c *StaleResponseCache) evictExpired(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.storedAt.Add(c.ttl)) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// MarkStaleResponseBody adds "stale": true and "stale_as_of", the Unix time the response was produced,
+// to a JSON object response. Other bodies are returned unchanged.
+func MarkStaleResponseBody(body []byte, storedAt time.Time) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return body
+	}
+	fields["stale"] = json.RawMessage("true")
+	asOf, _ := json.Marshal(storedAt.Unix())
+	fields["stale_as_of"] = asOf
+
+	marked, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	// Keep the trailing newline written by json.Encoder
+	if len(body) > 0 && body[len(body)-1] == '\n' {
+		marked = append(marked, '\n')
+	}
+	return marked
+}
@@ -0,0 +1,90 @@
+package common
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStaleResponseCache(ttl time.Duration) (*StaleResponseCache, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	cache := NewStaleResponseCache(ttl)
+	cache.now = clock.Now
+	return cache, clock
+}
+
+func TestStaleResponseCache_Expiry(t *testing.T) {
+	cache, clock := newTestStaleResponseCache(time.Minute)
+
+	_, _, ok := cache.Load("/accounts/acc-1")
+	assert.False(t, ok)
+
+	body := []byte(`{"balance_cents":1000}`)
+	cache.Store("/accounts/acc-1", body)
+	// The cache keeps its own copy of the body
+	body[2] = 'X'
+
+	clock.now = clock.now.Add(59 * time.Second)
+	stored, storedAt, ok := cache.Load("/accounts/acc-1")
+	require.True(t, ok)
+	assert.Equal(t, `{"balance_cents":1000}`, string(stored))
+	assert.Equal(t, time.Unix(1700000000, 0), storedAt)
+
+	clock.now = clock.now.Add(time.Second)
+	_, _, ok = cache.Load("/accounts/acc-1")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestStaleResponseCache_Disabled(t *testing.T) {
+	cache, _ := newTestStaleResponseCache(0)
+
+	cache.Store("/accounts/acc-1", []byte(`{}`))
+	_, _, ok := cache.Load("/accounts/acc-1")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestStaleResponseCache_Bounded(t *testing.T) {
+	cache, clock := newTestStaleResponseCache(time.Minute)
+
+	// A response too large to keep also drops the previous one, which is now outdated
+	cache.Store("/accounts/acc-1/transactions", []byte(`{}`))
+	cache.Store("/accounts/acc-1/transactions", bytes.Repeat([]byte("x"), maxStaleResponseBytes+1))
+	_, _, ok := cache.Load("/accounts/acc-1/transactions")
+	assert.False(t, ok)
+
+	for i := 0; i < maxStaleResponseEntries; i++ {
+		cache.entries[strconv.Itoa(i)] = staleResponse{body: []byte(`{}`), storedAt: clock.now}
+	}
+	cache.Store("overflow", []byte(`{}`))
+	_, _, ok = cache.Load("overflow")
+	assert.False(t, ok)
+
+	// Existing keys are still refreshed
+	cache.Store("0", []byte(`{"refreshed":true}`))
+	stored, _, ok := cache.Load("0")
+	require.True(t, ok)
+	assert.Equal(t, `{"refreshed":true}`, string(stored))
+
+	// Once entries expire there is room again
+	clock.now = clock.now.Add(time.Minute)
+	cache.Store("overflow", []byte(`{}`))
+	_, _, ok = cache.Load("overflow")
+	assert.True(t, ok)
+}
+
+func TestMarkStaleResponseBody(t *testing.T) {
+	storedAt := time.Unix(1700000000, 0)
+
+	marked := MarkStaleResponseBody([]byte(`{"id":"acc-1","balance_cents":1000}`+"\n"), storedAt)
+	assert.JSONEq(t, `{"id":"acc-1","balance_cents":1000,"stale":true,"stale_as_of":1700000000}`, string(marked))
+	assert.Equal(t, byte('\n'), marked[len(marked)-1])
+
+	assert.Equal(t, `[1,2]`, string(MarkStaleResponseBody([]byte(`[1,2]`), storedAt)))
+	assert.Equal(t, `not json`, string(MarkStaleResponseBody([]byte(`not json`), storedAt)))
+}
@@ -0,0 +1,125 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// Defaults for the startup coordinator.
+const (
+	DefaultStartupTimeout       = 2 * time.Minute
+	DefaultStartupRetryInterval = time.Second
+	maxStartupRetryInterval     = 15 * time.Second
+	startupAttemptTimeout       = 5 * time.Second
+)
+
+// StartupCoordinator waits for the dependencies of a service before it binds its port, so a service
+// started before its database or backends are up keeps retrying instead of exiting and crash-looping.
+// Checks are retried with exponential backoff until they pass or Timeout elapses.
+type StartupCoordinator struct {
+	Timeout       time.Duration
+	RetryInterval time.Duration
+	logger        *Logger
+}
+
+// NewStartupCoordinatorFromEnv reads STARTUP_TIMEOUT and STARTUP_RETRY_INTERVAL.
+// Invalid values fall back to the defaults.
+func NewStartupCoordinatorFromEnv(logger *Logger) *StartupCoordinator {
+	coordinator := &StartupCoordinator{
+		Timeout:       DefaultStartupTimeout,
+		RetryInterval: DefaultStartupRetryInterval,
+		logger:        logger,
+	}
+	if timeout, err := time.ParseDuration(getEnv("STARTUP_TIMEOUT", "")); err == nil && timeout > 0 {
+		coordinator.Timeout = timeout
+	}
+	if interval, err := time.ParseDuration(getEnv("STARTUP_RETRY_INTERVAL", "")); err == nil && interval > 0 {
+		coordinator.RetryInterval = interval
+	}
+	return coordinator
+}
+
+// Wait runs check until it succeeds, logging every failed attempt. Each attempt gets its own deadline
+// of a few seconds so a hanging dependency is retried too. Returns the last error once Timeout elapses.
+func (c *StartupCoordinator) Wait(name string, check func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	backoff := c.RetryInterval
+	c.logger.Info("Waiting for %s (timeout %s)", name, c.Timeout)
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancelAttempt := context.WithTimeout(ctx, startupAttemptTimeout)
+		err := check(attemptCtx)
+		cancelAttempt()
+		if err == nil {
+			c.logger.Info("Dependency %s ready after %s (%d attempts)", name, time.Since(start).Round(time.Millisecond), attempt)
+			return nil
+		}
+
+		remaining := c.Timeout - time.Since(start)
+		if remaining <= 0 {
+			return fmt.Errorf("%s not ready after %s: %w", name, c.Timeout, err)
+		}
+		c.logger.Warn("Dependency %s not ready (attempt %d): %v; retrying in %s, giving up in %s",
+			name, attempt, err, backoff, remaining.Round(time.Second))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not ready after %s: %w", name, c.Timeout, err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxStartupRetryInterval {
+			backoff = maxStartupRetryInterval
+		}
+	}
+}
+
+// WaitForDatabase connects to the database configured in the environment, retrying until it accepts connections.
+func (c *StartupCoordinator) WaitForDatabase() (*DatabaseManager, error) {
+	var dbManager *DatabaseManager
+	err := c.Wait("database", func(ctx context.Context) error {
+		var err error
+		dbManager, err = NewDatabaseManagerContext(ctx)
+		return err
+	})
+	return dbManager, err
+}
+
+// WaitForReplica connects to the read replica described by config, retrying until it accepts connections.
+func (c *StartupCoordinator) WaitForReplica(config DatabaseConfig) (*sql.DB, error) {
+	var replica *sql.DB
+	err := c.Wait("read replica", func(ctx context.Context) error {
+		var err error
+		replica, err = OpenReplicaDatabase(ctx, config)
+		return err
+	})
+	return replica, err
+}
+
+// WaitForChannels waits until every channel of pool is READY, i.e. the backend behind it is reachable and serving.
+// name identifies the backend in the logs.
+func (c *StartupCoordinator) WaitForChannels(name string, pool *ClientConnPool) error {
+	return c.Wait(name, pool.WaitForReady)
+}
+
+// WaitForReady blocks until conn is READY, starting to connect if it is idle.
+// Returns an error naming the last state if ctx is done first.
+func WaitForReady(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("channel %s", state)
+		}
+	}
+}
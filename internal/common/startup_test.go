@@ -0,0 +1,76 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestStartupCoordinator_Wait(t *testing.T) {
+	logger, _ := NewLogger("test-service", INFO)
+	coordinator := &StartupCoordinator{Timeout: time.Second, RetryInterval: time.Millisecond, logger: logger}
+
+	attempts := 0
+	err := coordinator.Wait("database", func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	coordinator.Timeout = 20 * time.Millisecond
+	err = coordinator.Wait("database", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	assert.EqualError(t, err, "database not ready after 20ms: connection refused")
+}
+
+func TestNewStartupCoordinatorFromEnv(t *testing.T) {
+	logger, _ := NewLogger("test-service", INFO)
+	coordinator := NewStartupCoordinatorFromEnv(logger)
+	assert.Equal(t, DefaultStartupTimeout, coordinator.Timeout)
+	assert.Equal(t, DefaultStartupRetryInterval, coordinator.RetryInterval)
+
+	t.Setenv("STARTUP_TIMEOUT", "30s")
+	t.Setenv("STARTUP_RETRY_INTERVAL", "invalid")
+	coordinator = NewStartupCoordinatorFromEnv(logger)
+	assert.Equal(t, 30*time.Second, coordinator.Timeout)
+	assert.Equal(t, DefaultStartupRetryInterval, coordinator.RetryInterval)
+}
+
+func TestWaitForReady(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	lis.Close()
+
+	conn, err := grpc.Dial("passthrough:///"+addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Nothing listens yet
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Error(t, WaitForReady(ctx, conn))
+
+	lis, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn.ResetConnectBackoff()
+	assert.NoError(t, WaitForReady(ctx, conn))
+}
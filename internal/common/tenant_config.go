@@ -0,0 +1,407 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// TenantIDMetadataKey is the gRPC metadata key carrying the issuer a request is made on behalf of.
+// The gateway fills it from the X-Tenant-ID header.
+const TenantIDMetadataKey = "x-tenant-id"
+
+// DefaultTenantConfigTTL is how long tenant settings are cached before they are reloaded.
+const DefaultTenantConfigTTL = time.Minute
+
+// knownOperationTypes lists the operation types tenant settings may refer to.
+var knownOperationTypes = map[string]bool{
+	"CASH_PURCHASE":        true,
+	"INSTALLMENT_PURCHASE": true,
+	"WITHDRAWAL":           true,
+	"PAYMENT":              true,
+}
+
+var (
+	tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// ErrInvalidTenantID is returned for tenant IDs that are empty, too long or contain unsupported characters.
+var ErrInvalidTenantID = errors.New("invalid tenant id")
+
+// FeeRule describes the fee charged for one operation type: a fixed amount plus a percentage of the amount.
+type FeeRule struct {
+	Fixed   Cents   `json:"fixed"`
+	Percent float64 `json:"percent"`
+}
+
+// TenantSettings holds the configuration of one tenant (card issuer) in one environment.
+// Zero values mean "no tenant-specific setting": every operation type is allowed and amounts are not capped.
+// Sandbox tenants are for integrators testing end-to-end flows: their accounts use test document numbers and
+// their held transactions are cleared automatically after a short delay by the transaction service.
+// DocumentTypes lists the document types, e.g. CPF and CNPJ, whose format and check digits the document numbers
+// of the tenant's account holders are validated against; empty accepts any document number.
+type TenantSettings struct {
+	Currency              string             `json:"currency,omitempty"`
+	AllowedOperationTypes []string           `json:"allowed_operation_types,omitempty"`
+	MaxTransactionAmount  Cents              `json:"max_transaction_amount,omitempty"`
+	FeeSchedule           map[string]FeeRule `json:"fee_schedule,omitempty"`
+	BalanceSource         string             `json:"balance_source,omitempty"`
+	Retention             *RetentionSettings `json:"retention,omitempty"`
+	ReportingCurrency     string             `json:"reporting_currency,omitempty"`
+	Sandbox               bool               `json:"sandbox,omitempty"`
+	DocumentTypes         []string           `json:"document_types,omitempty"`
+}
+
+// TestDocumentPrefix starts the document numbers of test account holders. Sandbox tenants accept only test
+// document numbers and other tenants never accept them, so test data and real data are never mixed.
+const TestDocumentPrefix = "TEST"
+
+// IsTestDocumentNumber reports whether documentNumber is a test document number.
+func IsTestDocumentNumber(documentNumber string) bool {
+	return strings.HasPrefix(documentNumber, TestDocumentPrefix)
+}
+
+// AcceptsDocumentNumber reports whether the tenant accepts documentNumber for an account holder:
+// sandbox tenants accept test document numbers only, other tenants anything but test document numbers.
+func (s TenantSettings) AcceptsDocumentNumber(documentNumber string) bool {
+	return s.Sandbox == IsTestDocumentNumber(documentNumber)
+}
+
+// RetentionSettings are the data retention periods of a tenant, in days. Zero keeps the data indefinitely.
+// TransactionDays is how long transactions are kept before they are purged; AnonymizeClosedAccountDays
+// is how long after an account is closed its holder data is anonymized. They are enforced by RetentionWorker.
+type RetentionSettings struct {
+	TransactionDays            int `json:"transaction_days,omitempty"`
+	AnonymizeClosedAccountDays int `json:"anonymize_closed_account_days,omitempty"`
+}
+
+// RetainsClosedAccounts reports whether the tenant has retention settings, in which case its deleted
+// accounts are closed and kept, with their transactions, until the retention worker removes their data.
+func (s TenantSettings) RetainsClosedAccounts() bool {
+	return s.Retention != nil && (s.Retention.TransactionDays > 0 || s.Retention.AnonymizeClosedAccountDays > 0)
+}
+
+// Balance sources a tenant can read account balances from. The balance column is maintained on every write;
+// the ledger balance is derived from the account's opening balance and its completed transactions and
+// approved adjustments, see LedgerBalanceReader.
+const (
+	BalanceSourceColumn = "COLUMN"
+	BalanceSourceLedger = "LEDGER"
+)
+
+// RevaluesFX reports whether the tenant's balances are held in a currency other than the one it reports in,
+// so they are revalued at every month end.
+func (s TenantSettings) RevaluesFX() bool {
+	return s.ReportingCurrency != "" && s.ReportingCurrency != s.Currency
+}
+
+// UsesLedgerBalance reports whether the tenant reads account balances from the ledger instead of the balance column.
+func (s TenantSettings) UsesLedgerBalance() bool {
+	return s.BalanceSource == BalanceSourceLedger
+}
+
+// AllowsOperationType reports whether the tenant accepts transactions of the given operation type.
+func (s TenantSettings) AllowsOperationType(operationType string) bool {
+	if len(s.AllowedOperationTypes) == 0 {
+		return true
+	}
+	for _, allowed := range s.AllowedOperationTypes {
+		if allowed == operationType {
+			return true
+		}
+	}
+	return false
+}
+
+// ExceedsMaxAmount reports whether amount is above the tenant's per-transaction cap.
+func (s TenantSettings) ExceedsMaxAmount(amount Cents) bool {
+	return s.MaxTransactionAmount > 0 && amount.Abs() > s.MaxTransactionAmount
+}
+
+// Validate checks that the settings are well formed.
+func (s TenantSettings) Validate() error {
+	if s.Currency != "" && !currencyPattern.MatchString(s.Currency) {
+		return fmt.Errorf("currency must be a three-letter ISO 4217 code")
+	}
+	if s.ReportingCurrency != "" && !currencyPattern.MatchString(s.ReportingCurrency) {
+		return fmt.Errorf("reporting_currency must be a three-letter ISO 4217 code")
+	}
+	if s.ReportingCurrency != "" && s.Currency == "" {
+		return fmt.Errorf("reporting_currency requires currency")
+	}
+	for _, operationType := range s.AllowedOperationTypes {
+		if !knownOperationTypes[operationType] {
+			return fmt.Errorf("unknown operation type: %s", operationType)
+		}
+	}
+	if s.BalanceSource != "" && s.BalanceSource != BalanceSourceColumn && s.BalanceSource != BalanceSourceLedger {
+		return fmt.Errorf("balance_source must be COLUMN or LEDGER")
+	}
+	if s.MaxTransactionAmount < 0 {
+		return fmt.Errorf("max_transaction_amount must not be negative")
+	}
+	if s.Retention != nil && (s.Retention.TransactionDays < 0 || s.Retention.AnonymizeClosedAccountDays < 0) {
+		return fmt.Errorf("retention periods must not be negative")
+	}
+	for _, documentType := range s.DocumentTypes {
+		if documentType == "" {
+			return fmt.Errorf("document_types must not contain empty types")
+		}
+	}
+	for operationType, rule := range s.FeeSchedule {
+		if !knownOperationTypes[operationType] {
+			return fmt.Errorf("unknown operation type in fee schedule: %s", operationType)
+		}
+		if rule.Fixed < 0 || rule.Percent < 0 || rule.Percent > 100 {
+			return fmt.Errorf("invalid fee rule for %s", operationType)
+		}
+	}
+	return nil
+}
+
+// ValidTenantID reports whether id is safe to use as a tenant ID.
+func ValidTenantID(id string) bool {
+	return tenantIDPattern.MatchString(id)
+}
+
+// TenantIDFromContext returns the tenant ID carried in the incoming gRPC metadata,
+// or an empty string when the request is not made on behalf of a tenant.
+func TenantIDFromContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(TenantIDMetadataKey); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// tenantConfigEntry is a cached copy of one tenant's settings.
+type tenantConfigEntry struct {
+	settings TenantSettings
+	expires  time.Time
+}
+
+// TenantConfigStore reads and writes tenant settings in the tenant_settings table for one environment,
+// caching reads for a TTL so hot paths do not query the database on every request.
+// Writes invalidate the local cache; other service instances pick them up once their cached copy expires.
+type TenantConfigStore struct {
+	db          *sql.DB
+	environment string
+	ttl         time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]tenantConfigEntry
+}
+
+// NewTenantConfigStore creates a store for the given environment whose reads are cached for ttl.
+// A TTL of zero disables caching.
+func NewTenantConfigStore(db *sql.DB, environment string, ttl time.Duration) *TenantConfigStore {
+	return &TenantConfigStore{
+		db:          db,
+		environment: environment,
+		ttl:         ttl,
+		now:         time.Now,
+		entries:     make(map[string]tenantConfigEntry),
+	}
+}
+
+// NewTenantConfigStoreFromEnv creates a store for the environment named by APP_ENV (default "development"),
+// caching settings for TENANT_CONFIG_TTL, defaulting to DefaultTenantConfigTTL.
+func NewTenantConfigStoreFromEnv(db *sql.DB) *TenantConfigStore {
+	ttl, err := time.ParseDuration(getEnv("TENANT_CONFIG_TTL", DefaultTenantConfigTTL.String()))
+	if err != nil || ttl < 0 {
+		ttl = DefaultTenantConfigTTL
+	}
+	return NewTenantConfigStore(db, getEnv("APP_ENV", "development"), ttl)
+}
+
+// Environment returns the environment the store reads and writes.
+func (s *TenantConfigStore) Environment() string {
+	return s.environment
+}
+
+// Get returns the settings of a tenant. Tenants without stored settings get zero settings.
+func (s *TenantConfigStore) Get(ctx context.Context, tenantID string) (TenantSettings, error) {
+	if !ValidTenantID(tenantID) {
+		return TenantSettings{}, ErrInvalidTenantID
+	}
+
+	now := s.now()
+	s.mu.Lock()
+	entry, ok := s.entries[tenantID]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.settings, nil
+	}
+
+	var raw []byte
+	err := s.db.QueryRowContext(ctx, `
+		SELECT settings FROM tenant_settings WHERE tenant_id = $1 AND environment = $2
+	`, tenantID, s.environment).Scan(&raw)
+
+	var settings TenantSettings
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return TenantSettings{}, fmt.Errorf("failed to load tenant settings: %w", err)
+	default:
+		if err := json.Unmarshal(raw, &settings); err != nil {
+			return TenantSettings{}, fmt.Errorf("failed to decode tenant settings: %w", err)
+		}
+	}
+
+	if s.ttl > 0 {
+		s.mu.Lock()
+		s.entries[tenantID] = tenantConfigEntry{settings: settings, expires: now.Add(s.ttl)}
+		s.mu.Unlock()
+	}
+	return settings, nil
+}
+
+// List returns the stored settings of every tenant in the environment, bypassing the cache.
+func (s *TenantConfigStore) List(ctx context.Context) (map[string]TenantSettings, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT tenant_id, settings FROM tenant_settings WHERE environment = $1 ORDER BY tenant_id
+	`, s.environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant settings: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := make(map[string]TenantSettings)
+	for rows.Next() {
+		var tenantID string
+		var raw []byte
+		if err := rows.Scan(&tenantID, &raw); err != nil {
+			return nil, err
+		}
+		var settings TenantSettings
+		if err := json.Unmarshal(raw, &settings); err != nil {
+			return nil, fmt.Errorf("failed to decode settings of tenant %s: %w", tenantID, err)
+		}
+		tenants[tenantID] = settings
+	}
+	return tenants, rows.Err()
+}
+
+// Put validates and stores the settings of a tenant, replacing any previous settings.
+func (s *TenantConfigStore) Put(ctx context.Context, tenantID string, settings TenantSettings) error {
+	if !ValidTenantID(tenantID) {
+		return ErrInvalidTenantID
+	}
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode tenant settings: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO tenant_settings (tenant_id, environment, settings, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, environment) DO UPDATE
+		SET settings = EXCLUDED.settings, updated_at = EXCLUDED.updated_at
+	`, tenantID, s.environment, raw, GetCurrentTimestamp())
+	if err != nil {
+		return fmt.Errorf("failed to store tenant settings: %w", err)
+	}
+
+	s.mu.Lock()
+	delete(s.entries, tenantID)
+	s.mu.Unlock()
+	return nil
+}
+
+// migrateTenantSettingsAmounts rounds the amounts of stored tenant settings to whole cents. Settings stored while
+// amounts were float64 may hold fractions of a cent, which Cents rejects, so they are rewritten once with their
+// amounts rounded half away from zero; settings that already decode are left untouched.
+func (dm *DatabaseManager) migrateTenantSettingsAmounts() error {
+	rows, err := dm.db.Query(`SELECT tenant_id, environment, settings FROM tenant_settings`)
+	if err != nil {
+		return fmt.Errorf("failed to read tenant settings: %w", err)
+	}
+	type storedSettings struct {
+		tenantID, environment string
+		raw                   []byte
+	}
+	var stale []storedSettings
+	for rows.Next() {
+		var stored storedSettings
+		if err := rows.Scan(&stored.tenantID, &stored.environment, &stored.raw); err != nil {
+			rows.Close()
+			return err
+		}
+		var settings TenantSettings
+		if json.Unmarshal(stored.raw, &settings) != nil {
+			stale = append(stale, stored)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, stored := range stale {
+		rounded, err := roundSettingsAmounts(stored.raw)
+		if err != nil {
+			return fmt.Errorf("failed to migrate settings of tenant %s: %w", stored.tenantID, err)
+		}
+		if _, err := dm.db.Exec(`
+			UPDATE tenant_settings SET settings = $1 WHERE tenant_id = $2 AND environment = $3
+		`, rounded, stored.tenantID, stored.environment); err != nil {
+			return fmt.Errorf("failed to migrate settings of tenant %s: %w", stored.tenantID, err)
+		}
+	}
+	return nil
+}
+
+// roundSettingsAmounts rounds the amounts of encoded tenant settings to whole cents, keeping the other fields.
+func roundSettingsAmounts(raw []byte) ([]byte, error) {
+	round := func(value json.RawMessage) (json.RawMessage, error) {
+		var amount float64
+		if err := json.Unmarshal(value, &amount); err != nil {
+			return nil, err
+		}
+		return json.Marshal(CentsFromFloat(amount))
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	var err error
+	if amount, ok := fields["max_transaction_amount"]; ok {
+		if fields["max_transaction_amount"], err = round(amount); err != nil {
+			return nil, err
+		}
+	}
+	if encoded, ok := fields["fee_schedule"]; ok {
+		var schedule map[string]map[string]json.RawMessage
+		if err := json.Unmarshal(encoded, &schedule); err != nil {
+			return nil, err
+		}
+		for _, rule := range schedule {
+			if fixed, ok := rule["fixed"]; ok {
+				if rule["fixed"], err = round(fixed); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if fields["fee_schedule"], err = json.Marshal(schedule); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(fields)
+}
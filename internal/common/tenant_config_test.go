@@ -0,0 +1,194 @@
+package common
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestTenantSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    TenantSettings
+		expectedErr string
+	}{
+		{name: "empty settings", settings: TenantSettings{}},
+		{
+			name: "full settings",
+			settings: TenantSettings{
+				Currency:              "BRL",
+				AllowedOperationTypes: []string{"CASH_PURCHASE", "PAYMENT"},
+				MaxTransactionAmount:  500000,
+				FeeSchedule:           map[string]FeeRule{"WITHDRAWAL": {Fixed: 250, Percent: 1}},
+				BalanceSource:         BalanceSourceLedger,
+				Retention:             &RetentionSettings{TransactionDays: 2555, AnonymizeClosedAccountDays: 90},
+				ReportingCurrency:     "USD",
+			},
+		},
+		{name: "bad currency", settings: TenantSettings{Currency: "real"}, expectedErr: "currency"},
+		{name: "unknown operation type", settings: TenantSettings{AllowedOperationTypes: []string{"REFUND"}}, expectedErr: "unknown operation type: REFUND"},
+		{name: "negative max amount", settings: TenantSettings{MaxTransactionAmount: -1}, expectedErr: "max_transaction_amount"},
+		{name: "bad fee percent", settings: TenantSettings{FeeSchedule: map[string]FeeRule{"PAYMENT": {Percent: 150}}}, expectedErr: "invalid fee rule for PAYMENT"},
+		{name: "unknown balance source", settings: TenantSettings{BalanceSource: "CACHE"}, expectedErr: "balance_source must be COLUMN or LEDGER"},
+		{name: "bad reporting currency", settings: TenantSettings{Currency: "BRL", ReportingCurrency: "usd"}, expectedErr: "reporting_currency must be"},
+		{name: "reporting currency without currency", settings: TenantSettings{ReportingCurrency: "USD"}, expectedErr: "reporting_currency requires currency"},
+		{name: "negative retention", settings: TenantSettings{Retention: &RetentionSettings{TransactionDays: -1}}, expectedErr: "retention periods must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.settings.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestTenantSettings_Policy(t *testing.T) {
+	var open TenantSettings
+	assert.True(t, open.AllowsOperationType("WITHDRAWAL"))
+	assert.False(t, open.ExceedsMaxAmount(1e11))
+	assert.False(t, open.UsesLedgerBalance())
+	assert.True(t, TenantSettings{BalanceSource: BalanceSourceLedger}.UsesLedgerBalance())
+	assert.False(t, open.RetainsClosedAccounts())
+	assert.False(t, TenantSettings{Retention: &RetentionSettings{}}.RetainsClosedAccounts())
+	assert.True(t, TenantSettings{Retention: &RetentionSettings{AnonymizeClosedAccountDays: 30}}.RetainsClosedAccounts())
+
+	restricted := TenantSettings{AllowedOperationTypes: []string{"PAYMENT"}, MaxTransactionAmount: 10000}
+	assert.True(t, restricted.AllowsOperationType("PAYMENT"))
+	assert.False(t, restricted.AllowsOperationType("WITHDRAWAL"))
+	assert.False(t, restricted.ExceedsMaxAmount(10000))
+	assert.True(t, restricted.ExceedsMaxAmount(-10001))
+
+	sandbox := TenantSettings{Sandbox: true}
+	assert.True(t, sandbox.AcceptsDocumentNumber("TEST00000000001"))
+	assert.False(t, sandbox.AcceptsDocumentNumber("12345678901"))
+	assert.True(t, open.AcceptsDocumentNumber("12345678901"))
+	assert.False(t, open.AcceptsDocumentNumber("TEST00000000001"))
+}
+
+func TestTenantIDFromContext(t *testing.T) {
+	assert.Empty(t, TenantIDFromContext(context.Background()))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(TenantIDMetadataKey, "issuer-a"))
+	assert.Equal(t, "issuer-a", TenantIDFromContext(ctx))
+
+	assert.True(t, ValidTenantID("issuer_a-1"))
+	assert.False(t, ValidTenantID(""))
+	assert.False(t, ValidTenantID("issuer a"))
+}
+
+func TestTenantConfigStore_GetCachesUntilTTL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := NewTenantConfigStore(db, "production", time.Minute)
+	now := time.Unix(1700000000, 0)
+	store.now = func() time.Time { return now }
+
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings WHERE tenant_id = \$1 AND environment = \$2`).
+		WithArgs("issuer-a", "production").
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{"currency":"BRL","max_transaction_amount":500}`)))
+
+	settings, err := store.Get(context.Background(), "issuer-a")
+	require.NoError(t, err)
+	assert.Equal(t, TenantSettings{Currency: "BRL", MaxTransactionAmount: 50000}, settings)
+
+	// Served from cache
+	settings, err = store.Get(context.Background(), "issuer-a")
+	require.NoError(t, err)
+	assert.Equal(t, "BRL", settings.Currency)
+
+	// Reloaded once the TTL has elapsed; tenants without settings get zero settings
+	now = now.Add(time.Minute)
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+		WithArgs("issuer-a", "production").
+		WillReturnError(sql.ErrNoRows)
+	settings, err = store.Get(context.Background(), "issuer-a")
+	require.NoError(t, err)
+	assert.Equal(t, TenantSettings{}, settings)
+
+	_, err = store.Get(context.Background(), "not a tenant")
+	assert.ErrorIs(t, err, ErrInvalidTenantID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTenantConfigStore_PutInvalidatesCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := NewTenantConfigStore(db, "staging", time.Minute)
+
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+		WithArgs("issuer-a", "staging").
+		WillReturnError(sql.ErrNoRows)
+	_, err = store.Get(context.Background(), "issuer-a")
+	require.NoError(t, err)
+
+	mock.ExpectExec(`INSERT INTO tenant_settings`).
+		WithArgs("issuer-a", "staging", []byte(`{"currency":"USD"}`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.Put(context.Background(), "issuer-a", TenantSettings{Currency: "USD"}))
+
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+		WithArgs("issuer-a", "staging").
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{"currency":"USD"}`)))
+	settings, err := store.Get(context.Background(), "issuer-a")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", settings.Currency)
+
+	// Invalid settings are rejected before touching the database
+	assert.Error(t, store.Put(context.Background(), "issuer-a", TenantSettings{Currency: "usd"}))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTenantConfigStore_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := NewTenantConfigStore(db, "staging", time.Minute)
+	mock.ExpectQuery(`SELECT tenant_id, settings FROM tenant_settings WHERE environment = \$1`).
+		WithArgs("staging").
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "settings"}).
+			AddRow("issuer-a", []byte(`{"retention":{"transaction_days":30}}`)).
+			AddRow("issuer-b", []byte(`{"currency":"USD"}`)))
+
+	tenants, err := store.List(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, tenants, 2)
+	assert.Equal(t, 30, tenants["issuer-a"].Retention.TransactionDays)
+	assert.Equal(t, "USD", tenants["issuer-b"].Currency)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDatabaseManager_migrateTenantSettingsAmounts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT tenant_id, environment, settings FROM tenant_settings`).
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "environment", "settings"}).
+			AddRow("issuer-a", "production", []byte(`{"currency":"USD","max_transaction_amount":500.5}`)).
+			AddRow("issuer-b", "production", []byte(`{"max_transaction_amount":1000.005,"fee_schedule":{"WITHDRAWAL":{"fixed":0.125,"percent":1.5}}}`)))
+	mock.ExpectExec(`UPDATE tenant_settings SET settings = \$1 WHERE tenant_id = \$2 AND environment = \$3`).
+		WithArgs([]byte(`{"fee_schedule":{"WITHDRAWAL":{"fixed":0.13,"percent":1.5}},"max_transaction_amount":1000.01}`), "issuer-b", "production").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	dm := &DatabaseManager{db: db}
+	require.NoError(t, dm.migrateTenantSettingsAmounts())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
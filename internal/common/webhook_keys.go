@@ -0,0 +1,338 @@
+package common
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebhookSignatureHeader carries the signatures of a delivery, e.g. "t=1700000000,k_3f2a9c1e0b7d4e55=5d41...".
+// The timestamp is the time of the delivery attempt and each active signing key of the subscriber contributes
+// one key_id=signature pair, the hex HMAC-SHA256 of "<timestamp>.<body>" under the key's secret.
+const WebhookSignatureHeader = "X-Webhook-Signature"
+
+// DefaultWebhookKeysTTL is how long the active signing keys of a subscriber are cached before they are reloaded.
+const DefaultWebhookKeysTTL = time.Minute
+
+// maxActiveWebhookKeys bounds the keys a subscriber can have active at once: the current key, its successor
+// during a rotation and one spare.
+const maxActiveWebhookKeys = 3
+
+// Statuses of a webhook signing key.
+const (
+	WebhookKeyActive  = "ACTIVE"
+	WebhookKeyRetired = "RETIRED"
+)
+
+// Errors returned by WebhookKeyStore for requests that cannot be applied.
+var (
+	ErrUnknownWebhookSubscriber = errors.New("unknown subscriber")
+	ErrWebhookKeyNotFound       = errors.New("signing key not found")
+	ErrWebhookKeyRetired        = errors.New("signing key already retired")
+	ErrTooManyWebhookKeys       = errors.New("too many active signing keys")
+	ErrLastWebhookKey           = errors.New("cannot retire the last active signing key")
+)
+
+// webhookSubscribers lists the subscribers events are delivered to, the only ones signing keys can be created for.
+var webhookSubscribers = map[string]bool{
+	OutboxSubscriber: true,
+}
+
+// WebhookSigningKey is a secret deliveries to a subscriber are signed with. The secret is only returned when the
+// key is created.
+type WebhookSigningKey struct {
+	Subscriber string
+	KeyID      string
+	Secret     string
+	Status     string
+	CreatedBy  string
+	CreatedAt  int64
+	RetiredBy  string
+	RetiredAt  int64
+}
+
+// SignWebhook returns the WebhookSignatureHeader value of body delivered at timestamp, signed with every key.
+func SignWebhook(keys []WebhookSigningKey, timestamp int64, body []byte) string {
+	parts := make([]string, 0, len(keys)+1)
+	parts = append(parts, "t="+strconv.FormatInt(timestamp, 10))
+	for _, key := range keys {
+		parts = append(parts, key.KeyID+"="+webhookSignature(key.Secret, timestamp, body))
+	}
+	return strings.Join(parts, ",")
+}
+
+// VerifyWebhookSignature checks that header holds a valid signature of body under the key keyID with secret,
+// made at most tolerance before now. It is what subscribers do on receipt, and serves them as a reference.
+func VerifyWebhookSignature(header, keyID, secret string, body []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp int64
+	var signature string
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "t":
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return errors.New("invalid signature timestamp")
+			}
+			timestamp = parsed
+		case keyID:
+			signature = value
+		}
+	}
+	if timestamp == 0 {
+		return errors.New("missing signature timestamp")
+	}
+	if signature == "" {
+		return fmt.Errorf("no signature with key %s", keyID)
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return errors.New("signature timestamp outside the tolerance")
+	}
+	if !hmac.Equal([]byte(signature), []byte(webhookSignature(secret, timestamp, body))) {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
+
+// webhookSignature computes the hex HMAC-SHA256 of "<timestamp>.<body>".
+func webhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookKeyStore manages the signing keys of the webhook_signing_keys table, caching the active keys of each
+// subscriber for a TTL so deliveries do not query the database every time.
+// Changes invalidate the local cache; other service instances pick them up once their cached copy expires,
+// which is why a key should only be retired once its successor has been active for longer than the TTL.
+type WebhookKeyStore struct {
+	db  *sql.DB
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]webhookKeysEntry
+}
+
+type webhookKeysEntry struct {
+	keys    []WebhookSigningKey
+	expires time.Time
+}
+
+// NewWebhookKeyStore creates a store whose active keys are cached for ttl. A TTL of zero disables caching.
+func NewWebhookKeyStore(db *sql.DB, ttl time.Duration) *WebhookKeyStore {
+	return &WebhookKeyStore{
+		db:      db,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]webhookKeysEntry),
+	}
+}
+
+// NewWebhookKeyStoreFromEnv creates a store caching active keys for WEBHOOK_KEYS_TTL, defaulting to DefaultWebhookKeysTTL.
+func NewWebhookKeyStoreFromEnv(db *sql.DB) *WebhookKeyStore {
+	ttl, err := time.ParseDuration(getEnv("WEBHOOK_KEYS_TTL", DefaultWebhookKeysTTL.String()))
+	if err != nil || ttl < 0 {
+		ttl = DefaultWebhookKeysTTL
+	}
+	return NewWebhookKeyStore(db, ttl)
+}
+
+// ActiveKeys returns the active signing keys of a subscriber, oldest first, with their secrets.
+func (s *WebhookKeyStore) ActiveKeys(ctx context.Context, subscriber string) ([]WebhookSigningKey, error) {
+	now := s.now()
+	s.mu.Lock()
+	entry, ok := s.entries[subscriber]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.keys, nil
+	}
+
+	keys, err := queryWebhookKeys(ctx, s.db, `
+		SELECT subscriber, key_id, secret, status, created_by, created_at, COALESCE(retired_by, ''), COALESCE(retired_at, 0)
+		FROM webhook_signing_keys WHERE subscriber = $1 AND status = 'ACTIVE' ORDER BY created_at, key_id
+	`, subscriber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook signing keys: %w", err)
+	}
+
+	if s.ttl > 0 {
+		s.mu.Lock()
+		s.entries[subscriber] = webhookKeysEntry{keys: keys, expires: now.Add(s.ttl)}
+		s.mu.Unlock()
+	}
+	return keys, nil
+}
+
+// List returns all keys of a subscriber, newest first, without their secrets.
+func (s *WebhookKeyStore) List(ctx context.Context, subscriber string) ([]WebhookSigningKey, error) {
+	if !webhookSubscribers[subscriber] {
+		return nil, ErrUnknownWebhookSubscriber
+	}
+	keys, err := queryWebhookKeys(ctx, s.db, `
+		SELECT subscriber, key_id, '', status, created_by, created_at, COALESCE(retired_by, ''), COALESCE(retired_at, 0)
+		FROM webhook_signing_keys WHERE subscriber = $1 ORDER BY created_at DESC, key_id
+	`, subscriber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook signing keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Create generates a new active signing key for a subscriber. Deliveries are signed with it alongside the keys
+// already active, so subscribers can switch to it before the old key is retired.
+func (s *WebhookKeyStore) Create(ctx context.Context, subscriber, operator string) (*WebhookSigningKey, error) {
+	if !webhookSubscribers[subscriber] {
+		return nil, ErrUnknownWebhookSubscriber
+	}
+	keyID, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	key := &WebhookSigningKey{
+		Subscriber: subscriber,
+		KeyID:      "k_" + keyID,
+		Secret:     "whsec_" + secret,
+		Status:     WebhookKeyActive,
+		CreatedBy:  operator,
+		CreatedAt:  GetCurrentTimestamp(),
+	}
+
+	err = WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		active, err := lockActiveWebhookKeys(ctx, tx, subscriber)
+		if err != nil {
+			return err
+		}
+		if active >= maxActiveWebhookKeys {
+			return ErrTooManyWebhookKeys
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO webhook_signing_keys (subscriber, key_id, secret, status, created_by, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, key.Subscriber, key.KeyID, key.Secret, key.Status, key.CreatedBy, key.CreatedAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(subscriber)
+	return key, nil
+}
+
+// Retire stops deliveries to a subscriber from being signed with a key. The last active key of a subscriber
+// cannot be retired, so deliveries never go out unsigned once signing was set up.
+func (s *WebhookKeyStore) Retire(ctx context.Context, subscriber, keyID, operator string) (*WebhookSigningKey, error) {
+	if !webhookSubscribers[subscriber] {
+		return nil, ErrUnknownWebhookSubscriber
+	}
+
+	var key WebhookSigningKey
+	err := WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		active, err := lockActiveWebhookKeys(ctx, tx, subscriber)
+		if err != nil {
+			return err
+		}
+		keys, err := queryWebhookKeys(ctx, tx, `
+			SELECT subscriber, key_id, '', status, created_by, created_at, COALESCE(retired_by, ''), COALESCE(retired_at, 0)
+			FROM webhook_signing_keys WHERE subscriber = $1 AND key_id = $2
+		`, subscriber, keyID)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return ErrWebhookKeyNotFound
+		}
+		key = keys[0]
+		if key.Status != WebhookKeyActive {
+			return ErrWebhookKeyRetired
+		}
+		if active <= 1 {
+			return ErrLastWebhookKey
+		}
+
+		key.Status, key.RetiredBy, key.RetiredAt = WebhookKeyRetired, operator, GetCurrentTimestamp()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE webhook_signing_keys SET status = $1, retired_by = $2, retired_at = $3
+			WHERE subscriber = $4 AND key_id = $5
+		`, key.Status, key.RetiredBy, key.RetiredAt, subscriber, keyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(subscriber)
+	return &key, nil
+}
+
+// lockActiveWebhookKeys locks the active keys of a subscriber within tx and returns how many there are,
+// so concurrent creations and retirements cannot together exceed the limit or retire every key.
+func lockActiveWebhookKeys(ctx context.Context, tx *sql.Tx, subscriber string) (int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT key_id FROM webhook_signing_keys WHERE subscriber = $1 AND status = 'ACTIVE' FOR UPDATE
+	`, subscriber)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	active := 0
+	for rows.Next() {
+		active++
+	}
+	return active, rows.Err()
+}
+
+// webhookKeyQuerier is implemented by *sql.DB and *sql.Tx.
+type webhookKeyQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryWebhookKeys reads the keys selected by a query returning the columns of WebhookSigningKey, in order.
+func queryWebhookKeys(ctx context.Context, q webhookKeyQuerier, query string, args ...interface{}) ([]WebhookSigningKey, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []WebhookSigningKey
+	for rows.Next() {
+		var key WebhookSigningKey
+		if err := rows.Scan(&key.Subscriber, &key.KeyID, &key.Secret, &key.Status, &key.CreatedBy, &key.CreatedAt,
+			&key.RetiredBy, &key.RetiredAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// invalidate drops the cached active keys of a subscriber.
+func (s *WebhookKeyStore) invalidate(subscriber string) {
+	s.mu.Lock()
+	delete(s.entries, subscriber)
+	s.mu.Unlock()
+}
+
+// randomHex returns n random bytes, hex encoded.
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
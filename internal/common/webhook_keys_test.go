@@ -0,0 +1,195 @@
+package common
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var webhookKeyColumns = []string{"subscriber", "key_id", "secret", "status", "created_by", "created_at", "retired_by", "retired_at"}
+
+func TestSignWebhook_VerifiesWithEveryActiveKey(t *testing.T) {
+	keys := []WebhookSigningKey{
+		{KeyID: "k_old", Secret: "whsec_old"},
+		{KeyID: "k_new", Secret: "whsec_new"},
+	}
+	body := []byte("envelope")
+	now := time.Unix(1700000000, 0)
+	header := SignWebhook(keys, now.Unix(), body)
+
+	// A subscriber verifying with either key accepts the delivery, so it can switch keys at any time
+	assert.NoError(t, VerifyWebhookSignature(header, "k_old", "whsec_old", body, 5*time.Minute, now))
+	assert.NoError(t, VerifyWebhookSignature(header, "k_new", "whsec_new", body, 5*time.Minute, now.Add(time.Minute)))
+
+	assert.EqualError(t, VerifyWebhookSignature(header, "k_new", "whsec_old", body, 5*time.Minute, now), "signature does not match")
+	assert.EqualError(t, VerifyWebhookSignature(header, "k_new", "whsec_new", []byte("tampered"), 5*time.Minute, now), "signature does not match")
+	assert.EqualError(t, VerifyWebhookSignature(header, "k_gone", "whsec_gone", body, 5*time.Minute, now), "no signature with key k_gone")
+	assert.EqualError(t, VerifyWebhookSignature(header, "k_new", "whsec_new", body, 5*time.Minute, now.Add(time.Hour)),
+		"signature timestamp outside the tolerance")
+}
+
+func TestWebhookKeyStore_ActiveKeysCached(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`FROM webhook_signing_keys WHERE subscriber = \$1 AND status = 'ACTIVE'`).
+		WithArgs("broker").
+		WillReturnRows(sqlmock.NewRows(webhookKeyColumns).AddRow("broker", "k_1", "whsec_1", "ACTIVE", "admin-1", 1000, "", 0))
+
+	store := NewWebhookKeyStore(db, time.Minute)
+	for i := 0; i < 2; i++ {
+		keys, err := store.ActiveKeys(context.Background(), "broker")
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, "whsec_1", keys[0].Secret)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookKeyStore_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		subscriber    string
+		active        int
+		expectedError error
+	}{
+		{name: "first key", subscriber: "broker"},
+		{name: "rotation", subscriber: "broker", active: 1},
+		{name: "limit reached", subscriber: "broker", active: maxActiveWebhookKeys, expectedError: ErrTooManyWebhookKeys},
+		{name: "unknown subscriber", subscriber: "acme", expectedError: ErrUnknownWebhookSubscriber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			if tt.subscriber == "broker" {
+				active := sqlmock.NewRows([]string{"key_id"})
+				for i := 0; i < tt.active; i++ {
+					active.AddRow("k_existing")
+				}
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT key_id FROM webhook_signing_keys WHERE subscriber = \$1 AND status = 'ACTIVE' FOR UPDATE`).
+					WithArgs("broker").
+					WillReturnRows(active)
+				if tt.expectedError == nil {
+					mock.ExpectExec(`INSERT INTO webhook_signing_keys`).
+						WithArgs("broker", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "admin-1", sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				} else {
+					mock.ExpectRollback()
+				}
+			}
+
+			store := NewWebhookKeyStore(db, time.Minute)
+			key, err := store.Create(context.Background(), tt.subscriber, "admin-1")
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Regexp(t, `^k_[0-9a-f]{16}$`, key.KeyID)
+				assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, key.Secret)
+				assert.Equal(t, WebhookKeyActive, key.Status)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWebhookKeyStore_Retire(t *testing.T) {
+	tests := []struct {
+		name          string
+		active        int
+		status        string
+		expectedError error
+	}{
+		{name: "old key after rotation", active: 2, status: "ACTIVE"},
+		{name: "last active key", active: 1, status: "ACTIVE", expectedError: ErrLastWebhookKey},
+		{name: "already retired", active: 1, status: "RETIRED", expectedError: ErrWebhookKeyRetired},
+		{name: "unknown key", active: 1, expectedError: ErrWebhookKeyNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			active := sqlmock.NewRows([]string{"key_id"})
+			for i := 0; i < tt.active; i++ {
+				active.AddRow("k_existing")
+			}
+			key := sqlmock.NewRows(webhookKeyColumns)
+			if tt.status != "" {
+				key.AddRow("broker", "k_old", "", tt.status, "admin-1", 1000, "", 0)
+			}
+			mock.ExpectBegin()
+			mock.ExpectQuery(`FOR UPDATE`).WithArgs("broker").WillReturnRows(active)
+			mock.ExpectQuery(`FROM webhook_signing_keys WHERE subscriber = \$1 AND key_id = \$2`).
+				WithArgs("broker", "k_old").
+				WillReturnRows(key)
+			if tt.expectedError == nil {
+				mock.ExpectExec(`UPDATE webhook_signing_keys SET status = \$1, retired_by = \$2, retired_at = \$3`).
+					WithArgs("RETIRED", "admin-2", sqlmock.AnyArg(), "broker", "k_old").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			store := NewWebhookKeyStore(db, time.Minute)
+			retired, err := store.Retire(context.Background(), "broker", "k_old", "admin-2")
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, WebhookKeyRetired, retired.Status)
+				assert.Equal(t, "admin-2", retired.RetiredBy)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestHTTPEventPublisher_SignsWithActiveKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`FROM webhook_signing_keys WHERE subscriber = \$1 AND status = 'ACTIVE'`).
+		WithArgs("broker").
+		WillReturnRows(sqlmock.NewRows(webhookKeyColumns).
+			AddRow("broker", "k_old", "whsec_old", "ACTIVE", "admin-1", 1000, "", 0).
+			AddRow("broker", "k_new", "whsec_new", "ACTIVE", "admin-1", 2000, "", 0))
+
+	now := time.Unix(1700000000, 0)
+	var header string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(WebhookSignatureHeader)
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	publisher := NewHTTPEventPublisher(server.URL, time.Second)
+	publisher.now = func() time.Time { return now }
+	publisher.SignWith(NewWebhookKeyStore(db, time.Minute))
+	require.NoError(t, publisher.Publish(context.Background(), OutboxEvent{EventID: "event-1", Envelope: []byte("payload")}))
+
+	assert.Equal(t, []byte("payload"), body)
+	assert.NoError(t, VerifyWebhookSignature(header, "k_old", "whsec_old", body, time.Minute, now))
+	assert.NoError(t, VerifyWebhookSignature(header, "k_new", "whsec_new", body, time.Minute, now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,243 @@
+// Package fx provides the exchange rates amounts in one currency are converted to another at, e.g. payments made
+// in a currency other than the one of the account they are made to.
+// Rates come from a RateProvider: a static table, a table configured with FX_RATES, or an external rates API.
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidCurrency is returned for currencies that are not three-letter ISO 4217 codes.
+	ErrInvalidCurrency = errors.New("invalid currency")
+	// ErrRateUnavailable is wrapped by providers that have no rate for a currency pair.
+	ErrRateUnavailable = errors.New("exchange rate not available")
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidCurrency reports whether currency is a three-letter ISO 4217 code, e.g. "USD".
+func ValidCurrency(currency string) bool {
+	return currencyPattern.MatchString(currency)
+}
+
+// RateProvider returns exchange rates.
+type RateProvider interface {
+	// Rate returns the value of one unit of from in units of to. Converting a currency to itself has a rate
+	// of 1. Errors for pairs the provider has no rate for wrap ErrRateUnavailable.
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// StaticProvider serves rates from a fixed table. A pair is also served inverted, so a table with a EUR/USD
+// rate converts USD to EUR as well.
+type StaticProvider struct {
+	rates map[string]float64
+}
+
+// NewStaticProvider creates a provider serving rates, keyed by pair as "FROM/TO", e.g. {"EUR/USD": 1.08}.
+func NewStaticProvider(rates map[string]float64) *StaticProvider {
+	p := &StaticProvider{rates: make(map[string]float64, len(rates))}
+	for pair, rate := range rates {
+		p.rates[pair] = rate
+	}
+	return p
+}
+
+// Rate returns the rate of the pair, or the inverse of the rate of the inverted pair.
+func (p *StaticProvider) Rate(_ context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	if rate, ok := p.rates[from+"/"+to]; ok {
+		return rate, nil
+	}
+	if rate, ok := p.rates[to+"/"+from]; ok {
+		return 1 / rate, nil
+	}
+	return 0, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, from, to)
+}
+
+// ParseRates parses a comma-separated list of FROM/TO=rate entries, e.g. "EUR/USD=1.08,GBP/USD=1.27".
+// Rates must be positive.
+func ParseRates(spec string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pair, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate %q: want FROM/TO=rate", entry)
+		}
+		from, to, ok := strings.Cut(strings.TrimSpace(pair), "/")
+		if !ok || !ValidCurrency(from) || !ValidCurrency(to) {
+			return nil, fmt.Errorf("invalid rate %q: %w", entry, ErrInvalidCurrency)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || !validRate(rate) {
+			return nil, fmt.Errorf("invalid rate %q: rate must be a positive number", entry)
+		}
+		rates[from+"/"+to] = rate
+	}
+	return rates, nil
+}
+
+func validRate(rate float64) bool {
+	return rate > 0 && !math.IsInf(rate, 0) && !math.IsNaN(rate)
+}
+
+// HTTPProvider fetches rates from an external rates API, caching each pair for a while so conversions do not
+// wait on the API every time. The API is called as GET <url>?from=EUR&to=USD and answers {"rate": 1.08};
+// 404 Not Found means it has no rate for the pair.
+type HTTPProvider struct {
+	url    string
+	client *http.Client
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedRate
+}
+
+type cachedRate struct {
+	rate      float64
+	fetchedAt time.Time
+}
+
+// NewHTTPProvider creates a provider fetching rates from the API at url. Each call waits at most timeout, and
+// rates are cached for ttl; 0 disables caching.
+func NewHTTPProvider(url string, timeout, ttl time.Duration) *HTTPProvider {
+	return &HTTPProvider{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		ttl:    ttl,
+		now:    time.Now,
+		cache:  make(map[string]cachedRate),
+	}
+}
+
+// Rate returns the cached rate of the pair if it is recent enough, or fetches it from the API.
+func (p *HTTPProvider) Rate(ctx context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	pair := from + "/" + to
+
+	p.mu.Lock()
+	cached, ok := p.cache[pair]
+	p.mu.Unlock()
+	if ok && p.now().Sub(cached.fetchedAt) < p.ttl {
+		return cached.rate, nil
+	}
+
+	rate, err := p.fetch(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+	if p.ttl > 0 {
+		p.mu.Lock()
+		p.cache[pair] = cachedRate{rate: rate, fetchedAt: p.now()}
+		p.mu.Unlock()
+	}
+	return rate, nil
+}
+
+func (p *HTTPProvider) fetch(ctx context.Context, from, to string) (float64, error) {
+	query := url.Values{"from": {from}, "to": {to}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"?"+query.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, from, to)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("rates API returned %s", resp.Status)
+	}
+
+	var body struct {
+		Rate float64 `json:"rate"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("invalid rates API response: %w", err)
+	}
+	if !validRate(body.Rate) {
+		return 0, fmt.Errorf("invalid rates API response: rate %v", body.Rate)
+	}
+	return body.Rate, nil
+}
+
+// Providers FX_RATE_PROVIDER selects.
+const (
+	ProviderEnv = "env"
+	ProviderAPI = "api"
+)
+
+// Defaults of the API provider.
+const (
+	DefaultAPITimeout = 2 * time.Second
+	DefaultAPIRateTTL = time.Minute
+)
+
+// NewProviderFromEnv returns the provider FX_RATE_PROVIDER selects: "env" serves the rates of FX_RATES, as parsed
+// by ParseRates, and "api" fetches them from the API at FX_RATES_URL, waiting at most FX_RATES_TIMEOUT (default
+// 2s) and caching them for FX_RATES_TTL (default 1m). Returns nil when FX_RATE_PROVIDER is unset, so no
+// conversions are made.
+func NewProviderFromEnv() (RateProvider, error) {
+	switch kind := os.Getenv("FX_RATE_PROVIDER"); kind {
+	case "":
+		return nil, nil
+	case ProviderEnv:
+		rates, err := ParseRates(os.Getenv("FX_RATES"))
+		if err != nil {
+			return nil, fmt.Errorf("FX_RATES: %w", err)
+		}
+		return NewStaticProvider(rates), nil
+	case ProviderAPI:
+		url := os.Getenv("FX_RATES_URL")
+		if url == "" {
+			return nil, errors.New("FX_RATES_URL required by the api rate provider")
+		}
+		timeout, err := durationFromEnv("FX_RATES_TIMEOUT", DefaultAPITimeout)
+		if err != nil {
+			return nil, err
+		}
+		ttl, err := durationFromEnv("FX_RATES_TTL", DefaultAPIRateTTL)
+		if err != nil {
+			return nil, err
+		}
+		return NewHTTPProvider(url, timeout, ttl), nil
+	default:
+		return nil, fmt.Errorf("unknown FX_RATE_PROVIDER %q", kind)
+	}
+}
+
+func durationFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q", key, value)
+	}
+	return d, nil
+}
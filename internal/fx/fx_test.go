@@ -0,0 +1,125 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider_Rate(t *testing.T) {
+	provider := NewStaticProvider(map[string]float64{"EUR/USD": 1.25})
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     float64
+		wantErr  bool
+	}{
+		{"direct pair", "EUR", "USD", 1.25, false},
+		{"inverted pair", "USD", "EUR", 0.8, false},
+		{"same currency", "BRL", "BRL", 1, false},
+		{"unknown pair", "GBP", "USD", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := provider.Rate(context.Background(), tt.from, tt.to)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrRateUnavailable))
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-12)
+		})
+	}
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates("EUR/USD=1.08, GBP/USD = 1.27,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"EUR/USD": 1.08, "GBP/USD": 1.27}, rates)
+
+	rates, err = ParseRates("")
+	require.NoError(t, err)
+	assert.Empty(t, rates)
+
+	for _, spec := range []string{"EUR/USD", "EURUSD=1.08", "eur/usd=1.08", "EUR/USD=0", "EUR/USD=-1", "EUR/USD=abc"} {
+		_, err := ParseRates(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestHTTPProvider_Rate(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("from") != "EUR" || r.URL.Query().Get("to") != "USD" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"rate": 1.08}`))
+	}))
+	defer server.Close()
+
+	now := time.Unix(1790000000, 0)
+	provider := NewHTTPProvider(server.URL, time.Second, time.Minute)
+	provider.now = func() time.Time { return now }
+
+	rate, err := provider.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, 1.08, rate)
+
+	// Served from the cache until it expires
+	_, err = provider.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	now = now.Add(time.Minute)
+	_, err = provider.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	_, err = provider.Rate(context.Background(), "GBP", "USD")
+	assert.True(t, errors.Is(err, ErrRateUnavailable))
+
+	rate, err = provider.Rate(context.Background(), "USD", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, rate)
+	assert.Equal(t, 3, calls)
+}
+
+func TestNewProviderFromEnv(t *testing.T) {
+	t.Setenv("FX_RATE_PROVIDER", "")
+	provider, err := NewProviderFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, provider)
+
+	t.Setenv("FX_RATE_PROVIDER", ProviderEnv)
+	t.Setenv("FX_RATES", "EUR/BRL=6.2")
+	provider, err = NewProviderFromEnv()
+	require.NoError(t, err)
+	rate, err := provider.Rate(context.Background(), "EUR", "BRL")
+	require.NoError(t, err)
+	assert.Equal(t, 6.2, rate)
+
+	t.Setenv("FX_RATES", "EUR/BRL")
+	_, err = NewProviderFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("FX_RATE_PROVIDER", ProviderAPI)
+	_, err = NewProviderFromEnv()
+	assert.Error(t, err, "FX_RATES_URL is required")
+	t.Setenv("FX_RATES_URL", "http://rates.internal/v1/rate")
+	provider, err = NewProviderFromEnv()
+	require.NoError(t, err)
+	assert.IsType(t, &HTTPProvider{}, provider)
+
+	t.Setenv("FX_RATE_PROVIDER", "ecb")
+	_, err = NewProviderFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,425 @@
+package switchadapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"google.golang.org/grpc/metadata"
+)
+
+// Response codes set in FieldResponseCode.
+const (
+	ResponseApproved           = "00"
+	ResponseHeldForReview      = "01" // refer to issuer: the debit is held for fraud review
+	ResponseInvalidTransaction = "12"
+	ResponseInvalidAmount      = "13"
+	ResponseAccountNotFound    = "14"
+	ResponseOriginalNotFound   = "25"
+	ResponseFormatError        = "30"
+	ResponseInsufficientFunds  = "51"
+	ResponseNotPermitted       = "57"
+	ResponseExceedsAmountLimit = "61"
+	ResponseRestrictedAccount  = "62"
+	ResponseSystemError        = "96"
+)
+
+// CallerID identifies the adapter to the transaction service, which rate limits and audits calls by caller.
+const CallerID = "switch-adapter"
+
+// DefaultAddr is the TCP address the adapter listens on unless SWITCH_ADAPTER_ADDR is set.
+const DefaultAddr = ":8583"
+
+// DefaultTimeout bounds the transaction service calls made for one message unless SWITCH_ADAPTER_TIMEOUT is set.
+const DefaultTimeout = 5 * time.Second
+
+// externalIDPrefix starts the external IDs of the transactions the adapter creates, see externalID.
+const externalIDPrefix = "iso8583"
+
+// maxTerminalIDLength bounds FieldTerminalID, so external IDs fit the transactions.external_id column.
+const maxTerminalIDLength = 16
+
+// operationTypes maps the transaction type, the first two digits of the processing code, to operation types.
+var operationTypes = map[string]string{
+	"00": "CASH_PURCHASE",
+	"01": "WITHDRAWAL",
+}
+
+// declineResponses maps the errors of CreateTransaction to response codes. Other errors are system errors.
+var declineResponses = map[string]string{
+	"insufficient balance":                        ResponseInsufficientFunds,
+	"amount exceeds tenant limit":                 ResponseExceedsAmountLimit,
+	"account not found":                           ResponseAccountNotFound,
+	"account not active":                          ResponseRestrictedAccount,
+	"operation type not allowed for account type": ResponseNotPermitted,
+	"operation type not allowed for tenant":       ResponseNotPermitted,
+	"invalid operation type":                      ResponseInvalidTransaction,
+}
+
+// Config configures the adapter.
+type Config struct {
+	// Addr is the TCP address to listen on.
+	Addr string
+	// TenantID, if set, is the tenant every transaction is made for.
+	TenantID string
+	// Timeout bounds the transaction service calls made for one message.
+	Timeout time.Duration
+}
+
+// NewConfigFromEnv returns the adapter configuration from SWITCH_ADAPTER_ADDR, SWITCH_ADAPTER_TENANT_ID and
+// SWITCH_ADAPTER_TIMEOUT.
+func NewConfigFromEnv() Config {
+	config := Config{
+		Addr:     os.Getenv("SWITCH_ADAPTER_ADDR"),
+		TenantID: os.Getenv("SWITCH_ADAPTER_TENANT_ID"),
+		Timeout:  DefaultTimeout,
+	}
+	if config.Addr == "" {
+		config.Addr = DefaultAddr
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("SWITCH_ADAPTER_TIMEOUT")); err == nil && timeout > 0 {
+		config.Timeout = timeout
+	}
+	return config
+}
+
+// Adapter answers switch messages with the transaction service:
+//   - 0100 authorization requests are checked with a simulated transaction, which holds no funds;
+//   - 0200 financial requests and 0220 advices, the capture of an earlier authorization, post a transaction;
+//   - 0400 reversal requests and 0420 reversal advices reverse the transaction posted for the original message;
+//   - 0800 network management requests are echoed.
+//
+// A transaction is identified by the terminal, local date and STAN of its message, which are kept as its
+// external ID, so retransmitted financial messages return the transaction already posted instead of posting
+// it again, and reversals find the transaction they reverse.
+type Adapter struct {
+	transactions pb.TransactionServiceClient
+	logger       *common.Logger
+	config       Config
+	now          func() time.Time
+}
+
+// NewAdapter creates an adapter making its calls with transactions.
+func NewAdapter(transactions pb.TransactionServiceClient, logger *common.Logger, config Config) *Adapter {
+	return &Adapter{transactions: transactions, logger: logger, config: config, now: time.Now}
+}
+
+// Serve accepts connections on listener and answers the messages framed on them, see ReadFrame, until ctx is
+// done. Messages on one connection are answered in order. It returns the error that stopped the listener, or
+// nil once ctx is done and every connection is closed.
+func (a *Adapter) Serve(ctx context.Context, listener net.Listener) error {
+	stop := context.AfterFunc(ctx, func() { listener.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// serveConn answers the messages of conn until it is closed or ctx is done.
+func (a *Adapter) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	remote := conn.RemoteAddr().String()
+	a.logger.Info("Switch connected: Remote=%s", remote)
+	for {
+		data, err := ReadFrame(conn)
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				a.logger.Warn("Switch connection failed: Remote=%s, Error=%v", remote, err)
+			}
+			a.logger.Info("Switch disconnected: Remote=%s", remote)
+			return
+		}
+		req, err := ParseMessage(data)
+		if err != nil {
+			a.logger.Warn("Discarding malformed message: Remote=%s, Error=%v", remote, err)
+			continue
+		}
+		resp := a.Handle(ctx, req)
+		if resp == nil {
+			continue
+		}
+		if err := WriteFrame(conn, resp.Encode()); err != nil {
+			a.logger.Warn("Switch response failed: Remote=%s, Error=%v", remote, err)
+			return
+		}
+	}
+}
+
+// Handle returns the response to req, or nil if req is itself a response and is not answered.
+func (a *Adapter) Handle(ctx context.Context, req *Message) *Message {
+	if (req.MTI[2]-'0')%2 == 1 {
+		a.logger.Warn("Ignoring unsolicited response: MTI=%s, STAN=%s", req.MTI, req.Field(FieldSTAN))
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(a.outgoingContext(ctx), a.config.Timeout)
+	defer cancel()
+
+	resp := NewMessage(ResponseMTI(req.MTI))
+	for n, value := range req.Fields {
+		resp.Set(n, value)
+	}
+	switch req.MTI {
+	case MTIAuthorizationRequest:
+		a.authorize(ctx, req, resp)
+	case MTIFinancialRequest, MTIFinancialAdvice:
+		a.post(ctx, req, resp)
+	case MTIReversalRequest, MTIReversalAdvice:
+		a.reverse(ctx, req, resp)
+	case MTINetworkRequest:
+		resp.Set(FieldResponseCode, ResponseApproved)
+	default:
+		resp.Set(FieldResponseCode, ResponseInvalidTransaction)
+	}
+
+	a.logger.Info("Switch message answered: MTI=%s, STAN=%s, Terminal=%s, ResponseCode=%s",
+		req.MTI, req.Field(FieldSTAN), req.Field(FieldTerminalID), resp.Field(FieldResponseCode))
+	return resp
+}
+
+// outgoingContext returns ctx with the metadata the adapter's calls carry: its caller ID, the configured tenant
+// and a request ID. Reversals are made by support staff, which the adapter acts as with itself as operator.
+func (a *Adapter) outgoingContext(ctx context.Context) context.Context {
+	pairs := []string{
+		common.CallerIDMetadataKey, CallerID,
+		common.CallerRoleMetadataKey, common.RoleSupport,
+		common.OperatorIDMetadataKey, CallerID,
+	}
+	if a.config.TenantID != "" {
+		pairs = append(pairs, common.TenantIDMetadataKey, a.config.TenantID)
+	}
+	return metadata.AppendToOutgoingContext(common.WithRequestID(ctx, common.NewRequestID()), pairs...)
+}
+
+// authorize checks the transaction of req without posting it.
+func (a *Adapter) authorize(ctx context.Context, req, resp *Message) {
+	createReq, code := a.transactionRequest(req)
+	if code != "" {
+		resp.Set(FieldResponseCode, code)
+		return
+	}
+	createReq.Simulate = true
+	result, err := a.transactions.CreateTransaction(ctx, createReq)
+	if err != nil {
+		a.logger.WithContext(ctx).Error("Authorization failed: STAN=%s, Error=%v", req.Field(FieldSTAN), err)
+		resp.Set(FieldResponseCode, ResponseSystemError)
+		return
+	}
+	if result.Error != "" {
+		resp.Set(FieldResponseCode, declineResponse(result.Error))
+		return
+	}
+	approve(resp, createReq.ExternalId)
+}
+
+// post posts the transaction of req, unless a retransmission of req already did.
+func (a *Adapter) post(ctx context.Context, req, resp *Message) {
+	logger := a.logger.WithContext(ctx)
+
+	createReq, code := a.transactionRequest(req)
+	if code != "" {
+		resp.Set(FieldResponseCode, code)
+		return
+	}
+
+	existing, err := a.transactions.GetTransaction(ctx, &pb.GetTransactionRequest{ExternalId: createReq.ExternalId})
+	if err != nil {
+		logger.Error("Transaction lookup failed: ExternalID=%s, Error=%v", createReq.ExternalId, err)
+		resp.Set(FieldResponseCode, ResponseSystemError)
+		return
+	}
+	if existing.Transaction != nil {
+		logger.Info("Retransmitted message: ExternalID=%s, TransactionID=%s", createReq.ExternalId, existing.Transaction.Id)
+		setTransactionResponse(resp, existing.Transaction)
+		return
+	}
+	if existing.Error != "not found" {
+		logger.Error("Transaction lookup failed: ExternalID=%s, Error=%s", createReq.ExternalId, existing.Error)
+		resp.Set(FieldResponseCode, ResponseSystemError)
+		return
+	}
+
+	result, err := a.transactions.CreateTransaction(ctx, createReq)
+	if err != nil {
+		logger.Error("Transaction posting failed: ExternalID=%s, Error=%v", createReq.ExternalId, err)
+		resp.Set(FieldResponseCode, ResponseSystemError)
+		return
+	}
+	if result.Error != "" {
+		resp.Set(FieldResponseCode, declineResponse(result.Error))
+		return
+	}
+	setTransactionResponse(resp, result.Transaction)
+}
+
+// reverse reverses the transaction posted for the original of req, which carries the same account, terminal,
+// local date and STAN. A transaction already reversed is reported approved, so reversals can be retransmitted.
+func (a *Adapter) reverse(ctx context.Context, req, resp *Message) {
+	logger := a.logger.WithContext(ctx)
+
+	externalID, code := a.externalID(req)
+	if code != "" {
+		resp.Set(FieldResponseCode, code)
+		return
+	}
+	original, err := a.transactions.GetTransaction(ctx, &pb.GetTransactionRequest{ExternalId: externalID})
+	if err != nil {
+		logger.Error("Transaction lookup failed: ExternalID=%s, Error=%v", externalID, err)
+		resp.Set(FieldResponseCode, ResponseSystemError)
+		return
+	}
+	switch {
+	case original.Error == "not found" || (original.Transaction != nil && original.Transaction.AccountId != req.Field(FieldAccount)):
+		resp.Set(FieldResponseCode, ResponseOriginalNotFound)
+		return
+	case original.Error != "":
+		logger.Error("Transaction lookup failed: ExternalID=%s, Error=%s", externalID, original.Error)
+		resp.Set(FieldResponseCode, ResponseSystemError)
+		return
+	case original.Transaction.Status == "REVERSED":
+		resp.Set(FieldResponseCode, ResponseApproved)
+		return
+	}
+
+	result, err := a.transactions.ReverseTransaction(ctx, &pb.ReverseTransactionRequest{
+		Id:     original.Transaction.Id,
+		Reason: "Switch reversal " + req.Field(FieldSTAN) + " from terminal " + req.Field(FieldTerminalID),
+	})
+	if err != nil {
+		logger.Error("Reversal failed: TransactionID=%s, Error=%v", original.Transaction.Id, err)
+		resp.Set(FieldResponseCode, ResponseSystemError)
+		return
+	}
+	switch result.Error {
+	case "", "transaction already reversed":
+		resp.Set(FieldResponseCode, ResponseApproved)
+	case "not found":
+		resp.Set(FieldResponseCode, ResponseOriginalNotFound)
+	case "insufficient balance":
+		resp.Set(FieldResponseCode, ResponseInsufficientFunds)
+	case "only completed transactions can be reversed":
+		resp.Set(FieldResponseCode, ResponseInvalidTransaction)
+	default:
+		logger.Error("Reversal failed: TransactionID=%s, Error=%s", original.Transaction.Id, result.Error)
+		resp.Set(FieldResponseCode, ResponseSystemError)
+	}
+}
+
+// transactionRequest returns the transaction of an authorization or financial message, or the response code
+// of a message that cannot be mapped to one.
+func (a *Adapter) transactionRequest(req *Message) (*pb.CreateTransactionRequest, string) {
+	externalID, code := a.externalID(req)
+	if code != "" {
+		return nil, code
+	}
+
+	processingCode := req.Field(FieldProcessingCode)
+	if len(processingCode) != 6 || !isDigits(processingCode) {
+		return nil, ResponseFormatError
+	}
+	operationType, ok := operationTypes[processingCode[:2]]
+	if !ok {
+		return nil, ResponseInvalidTransaction
+	}
+
+	amount := req.Field(FieldAmount)
+	if len(amount) > 12 || !isDigits(amount) {
+		return nil, ResponseFormatError
+	}
+	amountCents, _ := strconv.ParseInt(amount, 10, 64)
+	if amountCents == 0 {
+		return nil, ResponseInvalidAmount
+	}
+
+	description := "Card " + strings.ToLower(strings.ReplaceAll(operationType, "_", " "))
+	if mcc := req.Field(FieldMCC); mcc != "" {
+		if len(mcc) != 4 || !isDigits(mcc) {
+			return nil, ResponseFormatError
+		}
+		description += ", MCC " + mcc
+	}
+
+	return &pb.CreateTransactionRequest{
+		AccountId:     req.Field(FieldAccount),
+		OperationType: operationType,
+		AmountCents:   amountCents,
+		Description:   description,
+		ExternalId:    externalID,
+	}, ""
+}
+
+// externalID returns the external ID of the transaction of req, "iso8583:<terminal>:<MMDD>:<STAN>", or the
+// response code of a message without the fields identifying it. Messages without a local date are dated today.
+func (a *Adapter) externalID(req *Message) (string, string) {
+	account, stan, terminal := req.Field(FieldAccount), req.Field(FieldSTAN), req.Field(FieldTerminalID)
+	if account == "" || terminal == "" || len(terminal) > maxTerminalIDLength || strings.Contains(terminal, ":") {
+		return "", ResponseFormatError
+	}
+	if len(stan) != 6 || !isDigits(stan) {
+		return "", ResponseFormatError
+	}
+	date := req.Field(FieldLocalDate)
+	if date == "" {
+		date = a.now().Format("0102")
+	}
+	if len(date) != 4 || !isDigits(date) {
+		return "", ResponseFormatError
+	}
+	return externalIDPrefix + ":" + terminal + ":" + date + ":" + stan, ""
+}
+
+// setTransactionResponse sets the response code of a posted transaction in resp.
+func setTransactionResponse(resp *Message, transaction *pb.Transaction) {
+	switch transaction.Status {
+	case "COMPLETED":
+		approve(resp, transaction.Id)
+	case "UNDER_REVIEW":
+		resp.Set(FieldResponseCode, ResponseHeldForReview)
+	default:
+		resp.Set(FieldResponseCode, ResponseInvalidTransaction)
+	}
+}
+
+// approve marks resp approved, with an approval code derived from seed so retransmissions get the same code.
+func approve(resp *Message, seed string) {
+	sum := sha256.Sum256([]byte(seed))
+	resp.Set(FieldApprovalCode, strings.ToUpper(hex.EncodeToString(sum[:3])))
+	resp.Set(FieldResponseCode, ResponseApproved)
+}
+
+// declineResponse returns the response code of a CreateTransaction error.
+func declineResponse(reason string) string {
+	if code, ok := declineResponses[reason]; ok {
+		return code
+	}
+	if strings.HasSuffix(reason, " amount must be positive") {
+		return ResponseInvalidAmount
+	}
+	return ResponseSystemError
+}
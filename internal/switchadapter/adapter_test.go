@@ -0,0 +1,204 @@
+package switchadapter
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeTransactions is a transaction service client keeping transactions by external ID.
+type fakeTransactions struct {
+	pb.TransactionServiceClient
+	byExternalID map[string]*pb.Transaction
+	decline      string
+	created      []*pb.CreateTransactionRequest
+	reversed     []*pb.ReverseTransactionRequest
+	md           metadata.MD
+}
+
+func newFakeTransactions() *fakeTransactions {
+	return &fakeTransactions{byExternalID: make(map[string]*pb.Transaction)}
+}
+
+func (f *fakeTransactions) CreateTransaction(ctx context.Context, req *pb.CreateTransactionRequest, _ ...grpc.CallOption) (*pb.CreateTransactionResponse, error) {
+	f.md, _ = metadata.FromOutgoingContext(ctx)
+	f.created = append(f.created, req)
+	if f.decline != "" {
+		return &pb.CreateTransactionResponse{Error: f.decline, Simulated: req.Simulate}, nil
+	}
+	transaction := &pb.Transaction{AccountId: req.AccountId, OperationType: req.OperationType, AmountCents: -req.AmountCents,
+		Status: "COMPLETED", ExternalId: req.ExternalId}
+	if req.Simulate {
+		return &pb.CreateTransactionResponse{Transaction: transaction, Simulated: true}, nil
+	}
+	transaction.Id = "tx-" + req.ExternalId
+	f.byExternalID[req.ExternalId] = transaction
+	return &pb.CreateTransactionResponse{Transaction: transaction}, nil
+}
+
+func (f *fakeTransactions) GetTransaction(_ context.Context, req *pb.GetTransactionRequest, _ ...grpc.CallOption) (*pb.GetTransactionResponse, error) {
+	if transaction, ok := f.byExternalID[req.ExternalId]; ok {
+		return &pb.GetTransactionResponse{Transaction: transaction}, nil
+	}
+	return &pb.GetTransactionResponse{Error: "not found"}, nil
+}
+
+func (f *fakeTransactions) ReverseTransaction(ctx context.Context, req *pb.ReverseTransactionRequest, _ ...grpc.CallOption) (*pb.ReverseTransactionResponse, error) {
+	f.md, _ = metadata.FromOutgoingContext(ctx)
+	f.reversed = append(f.reversed, req)
+	for _, transaction := range f.byExternalID {
+		if transaction.Id == req.Id {
+			transaction.Status = "REVERSED"
+			return &pb.ReverseTransactionResponse{}, nil
+		}
+	}
+	return &pb.ReverseTransactionResponse{Error: "not found"}, nil
+}
+
+func newTestAdapter(t *testing.T, transactions *fakeTransactions) *Adapter {
+	logger, err := common.NewLogger("test-service", common.INFO)
+	require.NoError(t, err)
+	adapter := NewAdapter(transactions, logger, Config{TenantID: "tenant-1", Timeout: time.Second})
+	adapter.now = func() time.Time { return time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC) }
+	return adapter
+}
+
+func purchase(mti, stan string) *Message {
+	msg := NewMessage(mti)
+	msg.Set(FieldAccount, "acc-1")
+	msg.Set(FieldProcessingCode, "000000")
+	msg.Set(FieldAmount, "000000001250")
+	msg.Set(FieldSTAN, stan)
+	msg.Set(FieldMCC, "5411")
+	msg.Set(FieldTerminalID, "TERM0001")
+	return msg
+}
+
+func TestAdapter_Authorize(t *testing.T) {
+	transactions := newFakeTransactions()
+	adapter := newTestAdapter(t, transactions)
+
+	resp := adapter.Handle(context.Background(), purchase(MTIAuthorizationRequest, "000001"))
+	assert.Equal(t, "0110", resp.MTI)
+	assert.Equal(t, ResponseApproved, resp.Field(FieldResponseCode))
+	assert.Len(t, resp.Field(FieldApprovalCode), 6)
+	assert.Equal(t, "000001", resp.Field(FieldSTAN))
+
+	require.Len(t, transactions.created, 1)
+	created := transactions.created[0]
+	assert.True(t, created.Simulate)
+	assert.Equal(t, "CASH_PURCHASE", created.OperationType)
+	assert.Equal(t, int64(1250), created.AmountCents)
+	assert.Equal(t, "Card cash purchase, MCC 5411", created.Description)
+	assert.Equal(t, "iso8583:TERM0001:0309:000001", created.ExternalId)
+	assert.Equal(t, []string{CallerID}, transactions.md.Get(common.CallerIDMetadataKey))
+	assert.Equal(t, []string{"tenant-1"}, transactions.md.Get(common.TenantIDMetadataKey))
+	assert.Empty(t, transactions.byExternalID)
+
+	transactions.decline = "insufficient balance"
+	resp = adapter.Handle(context.Background(), purchase(MTIAuthorizationRequest, "000002"))
+	assert.Equal(t, ResponseInsufficientFunds, resp.Field(FieldResponseCode))
+	assert.Equal(t, "", resp.Field(FieldApprovalCode))
+}
+
+func TestAdapter_PostIsIdempotent(t *testing.T) {
+	transactions := newFakeTransactions()
+	adapter := newTestAdapter(t, transactions)
+
+	first := adapter.Handle(context.Background(), purchase(MTIFinancialRequest, "000003"))
+	assert.Equal(t, "0210", first.MTI)
+	assert.Equal(t, ResponseApproved, first.Field(FieldResponseCode))
+
+	// A retransmitted advice for the same message returns the transaction already posted
+	retransmitted := adapter.Handle(context.Background(), purchase(MTIFinancialAdvice, "000003"))
+	assert.Equal(t, "0230", retransmitted.MTI)
+	assert.Equal(t, ResponseApproved, retransmitted.Field(FieldResponseCode))
+	assert.Equal(t, first.Field(FieldApprovalCode), retransmitted.Field(FieldApprovalCode))
+	assert.Len(t, transactions.created, 1)
+}
+
+func TestAdapter_Reverse(t *testing.T) {
+	transactions := newFakeTransactions()
+	adapter := newTestAdapter(t, transactions)
+
+	resp := adapter.Handle(context.Background(), purchase(MTIReversalRequest, "000004"))
+	assert.Equal(t, "0410", resp.MTI)
+	assert.Equal(t, ResponseOriginalNotFound, resp.Field(FieldResponseCode))
+
+	adapter.Handle(context.Background(), purchase(MTIFinancialRequest, "000004"))
+	resp = adapter.Handle(context.Background(), purchase(MTIReversalRequest, "000004"))
+	assert.Equal(t, ResponseApproved, resp.Field(FieldResponseCode))
+	require.Len(t, transactions.reversed, 1)
+	assert.Equal(t, "tx-iso8583:TERM0001:0309:000004", transactions.reversed[0].Id)
+	assert.Equal(t, []string{common.RoleSupport}, transactions.md.Get(common.CallerRoleMetadataKey))
+	assert.Equal(t, []string{CallerID}, transactions.md.Get(common.OperatorIDMetadataKey))
+
+	// Retransmitted reversals are approved without reversing again
+	resp = adapter.Handle(context.Background(), purchase(MTIReversalAdvice, "000004"))
+	assert.Equal(t, "0430", resp.MTI)
+	assert.Equal(t, ResponseApproved, resp.Field(FieldResponseCode))
+	assert.Len(t, transactions.reversed, 1)
+}
+
+func TestAdapter_RejectsInvalidMessages(t *testing.T) {
+	transactions := newFakeTransactions()
+	adapter := newTestAdapter(t, transactions)
+
+	tests := []struct {
+		name     string
+		field    int
+		value    string
+		expected string
+	}{
+		{"missing account", FieldAccount, "", ResponseFormatError},
+		{"short stan", FieldSTAN, "123", ResponseFormatError},
+		{"unknown transaction type", FieldProcessingCode, "200000", ResponseInvalidTransaction},
+		{"zero amount", FieldAmount, "000000000000", ResponseInvalidAmount},
+		{"invalid amount", FieldAmount, "12.50", ResponseFormatError},
+		{"invalid mcc", FieldMCC, "54", ResponseFormatError},
+		{"invalid local date", FieldLocalDate, "March", ResponseFormatError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := purchase(MTIFinancialRequest, "000005")
+			msg.Set(tt.field, tt.value)
+			assert.Equal(t, tt.expected, adapter.Handle(context.Background(), msg).Field(FieldResponseCode))
+		})
+	}
+	assert.Empty(t, transactions.created)
+
+	assert.Equal(t, ResponseInvalidTransaction, adapter.Handle(context.Background(), NewMessage("0300")).Field(FieldResponseCode))
+	assert.Nil(t, adapter.Handle(context.Background(), NewMessage("0210")))
+}
+
+func TestAdapter_Serve(t *testing.T) {
+	adapter := newTestAdapter(t, newFakeTransactions())
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- adapter.Serve(ctx, listener) }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Malformed messages are discarded without closing the connection
+	require.NoError(t, WriteFrame(conn, []byte("hello")))
+	require.NoError(t, WriteFrame(conn, []byte("0800|11=000006|70=301")))
+	data, err := ReadFrame(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "0810|11=000006|39=00|70=301", string(data))
+
+	cancel()
+	assert.NoError(t, <-served)
+}
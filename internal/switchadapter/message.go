@@ -0,0 +1,158 @@
+// Package switchadapter bridges card switch simulators to the transaction service. It speaks a simplified,
+// ISO 8583-like message format and maps authorization, financial, advice and reversal messages onto
+// transaction service calls.
+package switchadapter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Message type indicators the adapter handles. Each request is answered with the indicator of its response,
+// see ResponseMTI.
+const (
+	MTIAuthorizationRequest = "0100"
+	MTIFinancialRequest     = "0200"
+	MTIFinancialAdvice      = "0220"
+	MTIReversalRequest      = "0400"
+	MTIReversalAdvice       = "0420"
+	MTINetworkRequest       = "0800"
+)
+
+// Data elements of the messages, numbered as in ISO 8583.
+const (
+	FieldAccount        = 2  // account ID, in place of the primary account number
+	FieldProcessingCode = 3  // the first two digits select the transaction type, see operationTypes
+	FieldAmount         = 4  // amount in minor units, up to 12 digits
+	FieldSTAN           = 11 // systems trace audit number
+	FieldLocalDate      = 13 // local transaction date, MMDD
+	FieldMCC            = 18 // merchant category code
+	FieldApprovalCode   = 38 // set on approved responses
+	FieldResponseCode   = 39 // set on every response
+	FieldTerminalID     = 41 // card acceptor terminal
+)
+
+// maxField is the highest data element number a message may carry.
+const maxField = 128
+
+// MaxFrameSize is the largest message a frame can carry: frames start with its length as two bytes, big endian.
+const MaxFrameSize = 1<<16 - 1
+
+// ErrFrameTooLarge is returned by WriteFrame for messages longer than MaxFrameSize.
+var ErrFrameTooLarge = errors.New("message exceeds maximum frame size")
+
+// Message is a request or response: its message type indicator and data elements by number. On the wire it
+// is the indicator followed by the elements in ascending order, separated by '|', e.g.
+// "0200|2=acc-1|3=000000|4=000000001250|11=000123".
+type Message struct {
+	MTI    string
+	Fields map[int]string
+}
+
+// NewMessage returns an empty message of type mti.
+func NewMessage(mti string) *Message {
+	return &Message{MTI: mti, Fields: make(map[int]string)}
+}
+
+// Field returns the value of data element n, or an empty string if the message does not carry it.
+func (m *Message) Field(n int) string {
+	return m.Fields[n]
+}
+
+// Set sets data element n to value.
+func (m *Message) Set(n int, value string) {
+	m.Fields[n] = value
+}
+
+// ParseMessage decodes a message from its wire form.
+func ParseMessage(data []byte) (*Message, error) {
+	parts := strings.Split(string(data), "|")
+	if !isDigits(parts[0]) || len(parts[0]) != 4 {
+		return nil, fmt.Errorf("invalid message type indicator: %q", parts[0])
+	}
+
+	msg := NewMessage(parts[0])
+	for _, part := range parts[1:] {
+		number, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid data element: %q", part)
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 2 || n > maxField {
+			return nil, fmt.Errorf("invalid data element number: %q", number)
+		}
+		if _, ok := msg.Fields[n]; ok {
+			return nil, fmt.Errorf("duplicate data element %d", n)
+		}
+		msg.Fields[n] = value
+	}
+	return msg, nil
+}
+
+// Encode returns the wire form of the message. Values must not contain '|'.
+func (m *Message) Encode() []byte {
+	numbers := make([]int, 0, len(m.Fields))
+	for n := range m.Fields {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	var b strings.Builder
+	b.WriteString(m.MTI)
+	for _, n := range numbers {
+		fmt.Fprintf(&b, "|%d=%s", n, m.Fields[n])
+	}
+	return []byte(b.String())
+}
+
+// ResponseMTI returns the message type indicator of the response to a request of type mti: its function
+// digit is incremented, so 0200 is answered with 0210.
+func ResponseMTI(mti string) string {
+	return mti[:2] + string(mti[2]+1) + mti[3:]
+}
+
+// ReadFrame reads one length-prefixed message from r.
+func ReadFrame(r io.Reader) ([]byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	data := make([]byte, binary.BigEndian.Uint16(header[:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// WriteFrame writes data to w as one length-prefixed message.
+func WriteFrame(w io.Writer, data []byte) error {
+	if len(data) > MaxFrameSize {
+		return ErrFrameTooLarge
+	}
+	frame := make([]byte, 2+len(data))
+	binary.BigEndian.PutUint16(frame, uint16(len(data)))
+	copy(frame[2:], data)
+	_, err := w.Write(frame)
+	return err
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package switchadapter
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessage(t *testing.T) {
+	msg, err := ParseMessage([]byte("0200|11=000123|2=acc-1|4=000000001250"))
+	require.NoError(t, err)
+	assert.Equal(t, MTIFinancialRequest, msg.MTI)
+	assert.Equal(t, "acc-1", msg.Field(FieldAccount))
+	assert.Equal(t, "000000001250", msg.Field(FieldAmount))
+	assert.Equal(t, "", msg.Field(FieldMCC))
+
+	// Elements are encoded in ascending order
+	assert.Equal(t, "0200|2=acc-1|4=000000001250|11=000123", string(msg.Encode()))
+
+	for _, data := range []string{"", "02x0", "020", "0200|2", "0200|1=x", "0200|129=x", "0200|2=a|2=b"} {
+		_, err := ParseMessage([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestResponseMTI(t *testing.T) {
+	assert.Equal(t, "0110", ResponseMTI(MTIAuthorizationRequest))
+	assert.Equal(t, "0230", ResponseMTI(MTIFinancialAdvice))
+	assert.Equal(t, "0430", ResponseMTI(MTIReversalAdvice))
+	assert.Equal(t, "0810", ResponseMTI(MTINetworkRequest))
+}
+
+func TestFrames(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteFrame(&buf, []byte("0800|11=000001")))
+	require.NoError(t, WriteFrame(&buf, []byte("0800|11=000002")))
+	assert.Equal(t, []byte{0, 14}, buf.Bytes()[:2])
+
+	data, err := ReadFrame(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "0800|11=000001", string(data))
+	data, err = ReadFrame(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "0800|11=000002", string(data))
+	_, err = ReadFrame(&buf)
+	assert.Equal(t, io.EOF, err)
+
+	_, err = ReadFrame(bytes.NewReader([]byte{0, 10, '0'}))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	assert.Equal(t, ErrFrameTooLarge, WriteFrame(&buf, []byte(strings.Repeat("x", MaxFrameSize+1))))
+}
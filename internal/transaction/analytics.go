@@ -0,0 +1,162 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// analyticsBatchSize is the number of transactions read per query and sent per StreamTransactionsResponse.
+const analyticsBatchSize = 1000
+
+// analyticsTransactionStatuses lists the states StreamTransactions can filter on.
+var analyticsTransactionStatuses = map[string]bool{
+	"PENDING":      true,
+	"UNDER_REVIEW": true,
+	"COMPLETED":    true,
+	"DECLINED":     true,
+	"FAILED":       true,
+	"CANCELLED":    true,
+	"REVERSED":     true,
+}
+
+// AnalyticsService implements the TransactionAnalyticsService, read-only streams of transactions for
+// analytics workloads. It reads from a read replica only, so its queries never hold primary connections.
+type AnalyticsService struct {
+	pb.UnimplementedTransactionAnalyticsServiceServer
+	replica *sql.DB
+	logger  *common.Logger
+}
+
+// NewAnalyticsService creates the analytics service on top of a read replica connection.
+func NewAnalyticsService(replica *sql.DB, logger *common.Logger) *AnalyticsService {
+	return &AnalyticsService{replica: replica, logger: logger}
+}
+
+// StreamTransactions streams the transactions matching the request oldest first, in batches of
+// analyticsBatchSize. Like ExportTransactionHistory it never holds one query open for the whole stream: each
+// batch is a short query resuming after the last transaction sent, so a slow consumer does not keep a
+// long-running query on the replica.
+func (s *AnalyticsService) StreamTransactions(req *pb.StreamTransactionsRequest, stream pb.TransactionAnalyticsService_StreamTransactionsServer) error {
+	ctx := stream.Context()
+	logger := s.logger.WithContext(ctx)
+
+	if msg := validateStreamTransactionsRequest(req); msg != "" {
+		return stream.Send(&pb.StreamTransactionsResponse{Error: msg})
+	}
+
+	reader := newTransactionBatchReader(s.replica, req, analyticsBatchSize)
+	logger.Info("Streaming transactions: Filters=%d, Caller=%s", reader.filters, common.InternalServiceFromContext(ctx))
+
+	streamed := 0
+	for {
+		transactions, err := reader.next(ctx, logger)
+		if err != nil {
+			logger.Error("Transaction stream failed: Streamed=%d, Error=%v", streamed, err)
+			return stream.Send(&pb.StreamTransactionsResponse{Error: "database error"})
+		}
+
+		if len(transactions) > 0 {
+			if err := stream.Send(&pb.StreamTransactionsResponse{Transactions: transactions}); err != nil {
+				return err
+			}
+			streamed += len(transactions)
+		}
+		if len(transactions) < analyticsBatchSize {
+			break
+		}
+	}
+
+	logger.Info("Transactions streamed: Count=%d", streamed)
+	return nil
+}
+
+// validateStreamTransactionsRequest returns the error of an invalid StreamTransactionsRequest, or "".
+func validateStreamTransactionsRequest(req *pb.StreamTransactionsRequest) string {
+	if req.From < 0 || req.To < 0 {
+		return "from and to must not be negative"
+	}
+	if req.From > 0 && req.To > 0 && req.From >= req.To {
+		return "from must be before to"
+	}
+	if req.Status != "" && !analyticsTransactionStatuses[req.Status] {
+		return "invalid status"
+	}
+	return ""
+}
+
+// transactionBatchReader reads the transactions matching a StreamTransactionsRequest oldest first, a batch per
+// query, each query resuming after the last transaction read.
+type transactionBatchReader struct {
+	db    *sql.DB
+	query string
+	args  []interface{}
+	// filters is the number of conditions of the request
+	filters        int
+	afterCreatedAt int64
+	afterID        string
+}
+
+// newTransactionBatchReader creates a reader of the transactions matching req, batchSize at a time.
+func newTransactionBatchReader(db *sql.DB, req *pb.StreamTransactionsRequest, batchSize int) *transactionBatchReader {
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if req.From > 0 {
+		addCondition("created_at >= $%d", req.From)
+	}
+	if req.To > 0 {
+		addCondition("created_at < $%d", req.To)
+	}
+	if req.AccountId != "" {
+		addCondition("account_id = $%d", req.AccountId)
+	}
+	if req.Status != "" {
+		addCondition("status = $%d", req.Status)
+	}
+	if req.OperationType != "" {
+		addCondition("operation_type = $%d", req.OperationType)
+	}
+	filters := len(conditions)
+	// The position after the last transaction read takes the last two arguments
+	conditions = append(conditions, fmt.Sprintf("(created_at, id) > ($%d, $%d)", len(args)+1, len(args)+2))
+	query := fmt.Sprintf(`
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE %s
+		ORDER BY created_at, id
+		LIMIT %d
+	`, strings.Join(conditions, " AND "), batchSize)
+
+	return &transactionBatchReader{db: db, query: query, args: args, filters: filters, afterCreatedAt: -1}
+}
+
+// next returns the next batch of transactions; a batch smaller than the batch size is the last one.
+func (r *transactionBatchReader) next(ctx context.Context, logger *common.Logger) ([]*pb.Transaction, error) {
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, r.query, append(r.args, r.afterCreatedAt, r.afterID)...)
+	logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []*pb.Transaction
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		r.afterCreatedAt, r.afterID = transaction.CreatedAt, transaction.ID
+		transactions = append(transactions, ConvertTransactionToProto(transaction))
+	}
+	return transactions, rows.Err()
+}
@@ -0,0 +1,476 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"github.com/google/uuid"
+)
+
+// maxIngestBatchSize caps how many streamed transactions are applied in a single database round trip.
+const maxIngestBatchSize = 100
+
+// batchResult is the outcome of a single request within a batch.
+type batchResult struct {
+	transaction *common.Transaction
+	err         string
+}
+
+// validateCreateTransactionRequest checks the fields that can be validated without touching the database
+// and returns the rule of the request's operation type.
+// Returns an error message, or an empty string if the request is valid.
+func (s *Service) validateCreateTransactionRequest(req *pb.CreateTransactionRequest) (OperationRule, string) {
+	if req.AccountId == "" || req.OperationType == "" {
+		return OperationRule{}, "missing required fields"
+	}
+	if len(req.ExternalId) > maxExternalIDLength {
+		return OperationRule{}, "external_id too long"
+	}
+	if req.Category != "" && !validCategory(req.Category) {
+		return OperationRule{}, "invalid category"
+	}
+	if msg := validateBatchItem(req); msg != "" {
+		return OperationRule{}, msg
+	}
+	if msg := validateInstallmentCount(req); msg != "" {
+		return OperationRule{}, msg
+	}
+	rule, ok := s.rules.get(req.OperationType)
+	if !ok {
+		return OperationRule{}, "invalid operation type"
+	}
+	return rule, rule.CheckAmount(common.Cents(req.AmountCents))
+}
+
+// createTransactionBatch applies a batch of transaction requests in one database transaction.
+// Accounts are locked and loaded with a single query, requests are applied to the in-memory balances
+// in order, and the result is written back with one grouped balance update and one multi-row insert.
+// Completed payments then discharge the outstanding purchases and withdrawals of their accounts.
+// Requests that name a transaction batch item are linked to it; an item is applied at most once. Requests whose
+// external_id another transaction, or an earlier request of the batch, already has fail on their own.
+// Requests on an account out of throttling credits fail on their own, as does CreateTransaction.
+// Returns one result per request, in the same order as the requests.
+func (s *Service) createTransactionBatch(ctx context.Context, reqs []*pb.CreateTransactionRequest) (results []batchResult) {
+	ctx, span := s.startMoneyMovement(ctx, "transaction.batch", batchSizeAttribute.Int(len(reqs)))
+	defer func() { endMoneyMovement(span, batchFailureSummary(results)) }()
+	logger := s.logger.WithContext(ctx)
+
+	results = make([]batchResult, len(reqs))
+	rules := make([]OperationRule, len(reqs))
+
+	accountSet := make(map[string]bool)
+	for i, req := range reqs {
+		rule, msg := s.validateCreateTransactionRequest(req)
+		if msg != "" {
+			results[i].err = msg
+			continue
+		}
+		rules[i] = rule
+		if req.Simulate {
+			results[i].err = "simulate is not supported for ingested transactions"
+			continue
+		}
+		if req.InstallmentCount > 1 {
+			results[i].err = "installment_count is not supported for ingested transactions"
+			continue
+		}
+		if msg := s.checkTenantPolicy(ctx, req); msg != "" {
+			results[i].err = msg
+			continue
+		}
+		if s.missingAccounts.Contains(req.AccountId) {
+			results[i].err = "account not found"
+			continue
+		}
+		// Each request spends a credit of its account, so a batch cannot get past the per-account throttling
+		if s.throttle != nil && !s.throttle.Allow(req.AccountId) {
+			results[i].err = errAccountRateLimited
+			continue
+		}
+		accountSet[req.AccountId] = true
+	}
+	if len(accountSet) == 0 {
+		return results
+	}
+
+	// Lock accounts in a stable order so concurrent batches cannot deadlock each other
+	accountIDs := make([]string, 0, len(accountSet))
+	for id := range accountSet {
+		accountIDs = append(accountIDs, id)
+	}
+	sort.Strings(accountIDs)
+
+	for _, id := range accountIDs {
+		unlock, err := s.accountLocks.Lock(ctx, id)
+		if err != nil {
+			logger.Error("Transaction batch aborted while waiting for account lock: ID=%s, Error=%v", id, err)
+			return failPending(results, "request cancelled")
+		}
+		defer unlock()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("Transaction batch failed to begin: %v", err)
+		return failPending(results, "database error")
+	}
+	defer tx.Rollback()
+
+	accounts, err := s.lockAccounts(ctx, tx, accountIDs)
+	if err != nil {
+		logger.Error("Account check failed for transaction batch: %v", err)
+		return failPending(results, "database error")
+	}
+	for _, id := range accountIDs {
+		if _, ok := accounts[id]; !ok {
+			s.missingAccounts.Add(id)
+		}
+	}
+
+	batchItems, err := s.lookupBatchItems(ctx, tx, pendingRequests(reqs, results))
+	if err != nil {
+		logger.Error("Batch item check failed for transaction batch: %v", err)
+		return failPending(results, "database error")
+	}
+
+	usedExternalIDs, err := s.lookupUsedExternalIDs(ctx, tx, pendingRequests(reqs, results))
+	if err != nil {
+		logger.Error("External ID check failed for transaction batch: %v", err)
+		return failPending(results, "database error")
+	}
+
+	var recentDebits map[string]int
+	if s.risk.Enabled() {
+		recentDebits, err = s.countRecentDebits(ctx, tx, accountIDs)
+		if err != nil {
+			logger.Error("Risk scoring failed for transaction batch: %v", err)
+			return failPending(results, "database error")
+		}
+	}
+
+	deltas := make(map[string]common.Cents)
+	var accepted, completed []*common.Transaction
+	var reviews []*transactionReview
+	var items []batchItem
+	for i, req := range reqs {
+		if results[i].err != "" {
+			continue
+		}
+		if msg := batchItems.check(req); msg != "" {
+			results[i].err = msg
+			continue
+		}
+		if req.ExternalId != "" && usedExternalIDs[req.ExternalId] {
+			results[i].err = errExternalIDAlreadyUsed
+			continue
+		}
+
+		account, ok := accounts[req.AccountId]
+		if !ok {
+			results[i].err = "account not found"
+			continue
+		}
+		if account.Status != "ACTIVE" {
+			results[i].err = "account not active"
+			continue
+		}
+		if !rules[i].AllowsAccountType(account.AccountType) {
+			results[i].err = "operation type not allowed for account type"
+			continue
+		}
+
+		amount := rules[i].SignedAmount(common.Cents(req.AmountCents))
+		if exceedsOverdraft(account.Balance, amount, account.OverdraftLimit) {
+			results[i].err = "insufficient balance"
+			continue
+		}
+
+		dbTransaction := ConvertCreateTransactionRequestToTransaction(req)
+		dbTransaction.ID = uuid.New().String()
+		dbTransaction.Amount = amount
+		dbTransaction.Status = "COMPLETED"
+		dbTransaction.Balance = initialBalance(dbTransaction)
+		results[i].transaction = dbTransaction
+		accepted = append(accepted, dbTransaction)
+		if req.ExternalId != "" {
+			// A later request with the same external_id in this batch is a duplicate
+			usedExternalIDs[req.ExternalId] = true
+		}
+		if req.BatchId != "" {
+			// A later request for the same item in this batch is a duplicate
+			key := batchItemKey{req.BatchId, req.BatchItem}
+			batchItems.applied[key] = true
+			items = append(items, batchItem{key, dbTransaction.ID})
+		}
+
+		// Debits flagged by fraud scoring are recorded UNDER_REVIEW without changing the balance
+		if s.risk.Enabled() && amount < 0 {
+			review := s.screenTransaction(dbTransaction, account.Balance, recentDebits[req.AccountId])
+			recentDebits[req.AccountId]++
+			if review != nil {
+				reviews = append(reviews, review)
+				continue
+			}
+		}
+		dbTransaction.Overdrawn = amount < 0 && account.Balance+amount < 0
+		completed = append(completed, dbTransaction)
+		// A batch that is rolled back later ends its span with an error status, so these events are kept too
+		recordBalanceMutation(span, req.AccountId, dbTransaction.ID, req.OperationType, amount, account.Balance)
+		account.Balance += amount
+		accounts[req.AccountId] = account
+		deltas[req.AccountId] += amount
+	}
+	if len(accepted) == 0 {
+		return results
+	}
+
+	if len(deltas) > 0 {
+		if err := s.applyBalanceDeltas(ctx, tx, accountIDs, deltas); err != nil {
+			logger.Error("Balance update failed for transaction batch: %v", err)
+			return failAccepted(results, "could not process transaction")
+		}
+	}
+
+	if err := s.insertTransactions(ctx, tx, accepted); err != nil {
+		logger.Error("Transaction insert failed for batch: %v", err)
+		if common.IsBusinessDayClosed(err) {
+			return failAccepted(results, errBusinessDayClosed)
+		}
+		return failAccepted(results, "could not create transaction")
+	}
+
+	if err := s.insertBatchItems(ctx, tx, items); err != nil {
+		logger.Error("Batch item insert failed for transaction batch: %v", err)
+		return failAccepted(results, "could not create transaction")
+	}
+
+	if err := s.dischargeBatchPayments(ctx, tx, completed); err != nil {
+		logger.Error("Payment discharge failed for transaction batch: %v", err)
+		return failAccepted(results, "could not process payment")
+	}
+
+	if err := s.insertTransactionReviews(ctx, tx, reviews); err != nil {
+		logger.Error("Review insert failed for transaction batch: %v", err)
+		return failAccepted(results, "could not create transaction")
+	}
+
+	// Held transactions are published once approved
+	if err := s.enqueueTransactionsCreated(ctx, tx, completed...); err != nil {
+		logger.Error("Event enqueue failed for transaction batch: %v", err)
+		return failAccepted(results, "could not create transaction")
+	}
+	if err := s.enqueueBudgetThresholdsCrossed(ctx, tx, completed...); err != nil {
+		logger.Error("Budget event enqueue failed for transaction batch: %v", err)
+		return failAccepted(results, "could not create transaction")
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("Transaction batch commit failed: %v", err)
+		return failAccepted(results, "could not create transaction")
+	}
+
+	logger.Info("Applied transaction batch: Requests=%d, Created=%d, HeldForReview=%d, Accounts=%d", len(reqs), len(accepted), len(reviews), len(deltas))
+	return results
+}
+
+// lockAccounts loads and row-locks the balances, overdraft limits, types and onboarding states of the given accounts.
+// Accounts that do not exist are absent from the returned map.
+func (s *Service) lockAccounts(ctx context.Context, tx *sql.Tx, accountIDs []string) (map[string]common.Account, error) {
+	logger := s.logger.WithContext(ctx)
+
+	args := make([]interface{}, len(accountIDs))
+	for i, id := range accountIDs {
+		args[i] = id
+	}
+
+	start := time.Now()
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, account_type, balance, status, overdraft_limit FROM accounts WHERE id IN (%s) ORDER BY id FOR UPDATE
+	`, placeholders(1, len(accountIDs))), args...)
+	duration := time.Since(start)
+
+	logger.LogDatabase("SELECT", "accounts", duration, err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := make(map[string]common.Account, len(accountIDs))
+	for rows.Next() {
+		var account common.Account
+		if err := rows.Scan(&account.ID, &account.AccountType, &account.Balance, &account.Status, &account.OverdraftLimit); err != nil {
+			return nil, err
+		}
+		accounts[account.ID] = account
+	}
+	return accounts, rows.Err()
+}
+
+// lookupUsedExternalIDs returns which of the external_ids of reqs other transactions already have, within tx.
+func (s *Service) lookupUsedExternalIDs(ctx context.Context, tx *sql.Tx, reqs []*pb.CreateTransactionRequest) (map[string]bool, error) {
+	logger := s.logger.WithContext(ctx)
+
+	used := make(map[string]bool)
+	seen := make(map[string]bool)
+	var args []interface{}
+	for _, req := range reqs {
+		if req.ExternalId != "" && !seen[req.ExternalId] {
+			seen[req.ExternalId] = true
+			args = append(args, req.ExternalId)
+		}
+	}
+	if len(args) == 0 {
+		return used, nil
+	}
+
+	start := time.Now()
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		SELECT external_id FROM transactions WHERE external_id IN (%s)
+	`, placeholders(1, len(args))), args...)
+	logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var externalID string
+		if err := rows.Scan(&externalID); err != nil {
+			return nil, err
+		}
+		used[externalID] = true
+	}
+	return used, rows.Err()
+}
+
+// applyBalanceDeltas adds the accumulated per-account deltas to the balances in a single statement.
+func (s *Service) applyBalanceDeltas(ctx context.Context, tx *sql.Tx, accountIDs []string, deltas map[string]common.Cents) error {
+	logger := s.logger.WithContext(ctx)
+
+	args := []interface{}{common.GetCurrentTimestamp()}
+	values := make([]string, 0, len(deltas))
+	for _, id := range accountIDs {
+		delta, ok := deltas[id]
+		if !ok {
+			continue
+		}
+		values = append(values, fmt.Sprintf("($%d::VARCHAR, $%d::DECIMAL)", len(args)+1, len(args)+2))
+		args = append(args, id, delta)
+	}
+
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE accounts AS a
+		SET balance = a.balance + v.delta, updated_at = $1
+		FROM (VALUES %s) AS v(id, delta)
+		WHERE a.id = v.id
+	`, strings.Join(values, ", ")), args...)
+	duration := time.Since(start)
+
+	logger.LogDatabase("UPDATE", "accounts", duration, err)
+	return err
+}
+
+// insertTransactions writes the transactions with a single multi-row insert.
+func (s *Service) insertTransactions(ctx context.Context, tx *sql.Tx, transactions []*common.Transaction) error {
+	logger := s.logger.WithContext(ctx)
+
+	const columns = 11
+	args := make([]interface{}, 0, len(transactions)*columns)
+	values := make([]string, 0, len(transactions))
+	for _, t := range transactions {
+		metadata, err := encodeMetadata(t.Metadata)
+		if err != nil {
+			return err
+		}
+		first := len(args) + 1
+		values = append(values, fmt.Sprintf("(%s, NULLIF($%d, ''), $%d, $%d, $%d)", placeholders(first, 7), first+7, first+8, first+9, first+10))
+		args = append(args, t.ID, t.AccountID, t.OperationType, t.Amount, t.Description, t.CreatedAt, t.Status, t.ExternalID, metadata, t.Balance, t.Overdrawn)
+	}
+
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO transactions (id, account_id, operation_type, amount, description, created_at, status, external_id, metadata, balance, overdrawn)
+		VALUES %s
+	`, strings.Join(values, ", ")), args...)
+	duration := time.Since(start)
+
+	logger.LogDatabase("INSERT", "transactions", duration, err)
+	return err
+}
+
+// dischargeBatchPayments applies the completed payments of a batch to the outstanding purchases and withdrawals
+// of their accounts, in order, once the batch is inserted, and records the discharges and the remainder of each
+// payment. The debits of the same batch are discharged as well, whatever their position in the batch.
+func (s *Service) dischargeBatchPayments(ctx context.Context, tx *sql.Tx, completed []*common.Transaction) error {
+	for _, t := range completed {
+		if t.OperationType != "PAYMENT" {
+			continue
+		}
+		if err := s.dischargePayment(ctx, tx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placeholders returns n comma-separated positional parameters starting at $first.
+func placeholders(first, n int) string {
+	params := make([]string, n)
+	for i := range params {
+		params[i] = fmt.Sprintf("$%d", first+i)
+	}
+	return strings.Join(params, ", ")
+}
+
+// pendingRequests returns the requests that have not already failed.
+func pendingRequests(reqs []*pb.CreateTransactionRequest, results []batchResult) []*pb.CreateTransactionRequest {
+	pending := make([]*pb.CreateTransactionRequest, 0, len(reqs))
+	for i, req := range reqs {
+		if results[i].err == "" {
+			pending = append(pending, req)
+		}
+	}
+	return pending
+}
+
+// batchFailureSummary returns how many requests of a batch failed, or "" if none did.
+func batchFailureSummary(results []batchResult) string {
+	failed := 0
+	for _, result := range results {
+		if result.err != "" {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d of %d transactions failed", failed, len(results))
+}
+
+// failPending marks every request that has not already failed validation with the given error.
+func failPending(results []batchResult, msg string) []batchResult {
+	for i := range results {
+		if results[i].err == "" {
+			results[i].err = msg
+		}
+	}
+	return results
+}
+
+// failAccepted marks every request that was applied in the rolled back transaction with the given error.
+func failAccepted(results []batchResult, msg string) []batchResult {
+	for i := range results {
+		if results[i].transaction != nil {
+			results[i].transaction = nil
+			results[i].err = msg
+		}
+	}
+	return results
+}
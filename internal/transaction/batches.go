@@ -0,0 +1,344 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"github.com/google/uuid"
+)
+
+// Limits on transaction batches and their failure listing; the lengths match the table columns.
+const (
+	maxBatchSourceLength      = 100
+	maxAccountIDLength        = 36
+	defaultBatchFailuresLimit = 100
+	maxBatchFailuresLimit     = 1000
+)
+
+// errBatchItemAlreadyApplied is the error of a request for a batch item that already has a transaction.
+const errBatchItemAlreadyApplied = "batch item already processed"
+
+// Statuses of a transaction batch, derived from its items.
+const (
+	batchOpen                = "OPEN"
+	batchCompleted           = "COMPLETED"
+	batchCompletedWithErrors = "COMPLETED_WITH_ERRORS"
+)
+
+// batchItemKey identifies an item of a transaction batch.
+type batchItemKey struct {
+	batchID string
+	item    int32
+}
+
+// batchItem links an applied transaction to its batch item.
+type batchItem struct {
+	batchItemKey
+	transactionID string
+}
+
+// batchFailure is the failed attempt of a transaction batch item.
+type batchFailure struct {
+	batchItemKey
+	accountID  string
+	externalID string
+	err        string
+}
+
+// batchItemState is what lookupBatchItems found about the batches and items of a set of requests.
+type batchItemState struct {
+	expectedCounts map[string]int32
+	applied        map[batchItemKey]bool
+}
+
+// check returns the error of a request that names a batch that does not exist, an item outside the batch or an
+// item that was already applied, or an empty string if the item may be applied.
+func (s batchItemState) check(req *pb.CreateTransactionRequest) string {
+	if req.BatchId == "" {
+		return ""
+	}
+	expected, ok := s.expectedCounts[req.BatchId]
+	if !ok {
+		return "batch not found"
+	}
+	if req.BatchItem > expected {
+		return "batch_item exceeds the batch's expected_count"
+	}
+	if s.applied[batchItemKey{req.BatchId, req.BatchItem}] {
+		return errBatchItemAlreadyApplied
+	}
+	return ""
+}
+
+// validateBatchItem checks that a request names both a batch and its position in it, or neither.
+// Returns an error message, or an empty string if the fields are valid.
+func validateBatchItem(req *pb.CreateTransactionRequest) string {
+	if req.BatchId == "" {
+		if req.BatchItem != 0 {
+			return "batch_id required"
+		}
+		return ""
+	}
+	if req.BatchItem <= 0 {
+		return "batch_item must be positive"
+	}
+	return ""
+}
+
+// batchStatus derives the status of a batch from its item counts. A batch is OPEN until every expected item was
+// either applied or failed; an item that failed and was then retried successfully counts as applied.
+func batchStatus(expected, succeeded, failed int32) string {
+	switch {
+	case succeeded+failed < expected:
+		return batchOpen
+	case failed == 0:
+		return batchCompleted
+	default:
+		return batchCompletedWithErrors
+	}
+}
+
+// CreateBatch opens a batch that groups the transactions of a bulk import or settlement run. Transactions join it
+// by naming the batch and their 1-based item number in it; the batch is complete once expected_count items were
+// processed.
+func (s *Service) CreateBatch(ctx context.Context, req *pb.CreateBatchRequest) (*pb.CreateBatchResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.Source == "" {
+		return &pb.CreateBatchResponse{Error: "source required"}, nil
+	}
+	if len(req.Source) > maxBatchSourceLength {
+		return &pb.CreateBatchResponse{Error: "source too long"}, nil
+	}
+	if req.ExpectedCount <= 0 {
+		return &pb.CreateBatchResponse{Error: "expected_count must be positive"}, nil
+	}
+
+	batch := &pb.TransactionBatch{
+		Id:            uuid.New().String(),
+		Source:        req.Source,
+		ExpectedCount: req.ExpectedCount,
+		Status:        batchOpen,
+		CreatedAt:     common.GetCurrentTimestamp(),
+	}
+	operator := common.OperatorIDFromContext(ctx)
+
+	start := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO transaction_batches (id, source, expected_count, created_by, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+	`, batch.Id, batch.Source, batch.ExpectedCount, operator, batch.CreatedAt)
+	logger.LogDatabase("INSERT", "transaction_batches", time.Since(start), err)
+	if err != nil {
+		logger.Error("Batch creation failed: %v", err)
+		return &pb.CreateBatchResponse{Error: "database error"}, nil
+	}
+
+	logger.Info("Transaction batch created: ID=%s, Source=%s, ExpectedCount=%d, CreatedBy=%s", batch.Id, batch.Source, batch.ExpectedCount, operator)
+	return &pb.CreateBatchResponse{Batch: batch}, nil
+}
+
+// GetBatch returns the progress of a batch and a page of the items that failed, in item order. Only the latest
+// attempt of an item counts, so a failed item that was retried successfully is no longer listed.
+func (s *Service) GetBatch(ctx context.Context, req *pb.GetBatchRequest) (*pb.GetBatchResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.Id == "" {
+		return &pb.GetBatchResponse{Error: "id required"}, nil
+	}
+	limit := req.FailuresLimit
+	if limit <= 0 || limit > maxBatchFailuresLimit {
+		limit = defaultBatchFailuresLimit
+	}
+
+	batch := &pb.TransactionBatch{}
+	start := time.Now()
+	err := s.db.QueryRowContext(ctx, `
+		SELECT b.id, b.source, b.expected_count, b.created_at,
+			(SELECT COUNT(*) FROM transaction_batch_items i WHERE i.batch_id = b.id),
+			(SELECT COUNT(*) FROM transaction_batch_failures f WHERE f.batch_id = b.id
+				AND NOT EXISTS (SELECT 1 FROM transaction_batch_items i WHERE i.batch_id = f.batch_id AND i.item = f.item))
+		FROM transaction_batches b
+		WHERE b.id = $1
+	`, req.Id).Scan(&batch.Id, &batch.Source, &batch.ExpectedCount, &batch.CreatedAt, &batch.Succeeded, &batch.Failed)
+	logger.LogDatabase("SELECT", "transaction_batches", time.Since(start), err)
+	if err == sql.ErrNoRows {
+		return &pb.GetBatchResponse{Error: "batch not found"}, nil
+	}
+	if err != nil {
+		logger.Error("Batch lookup failed: ID=%s, Error=%v", req.Id, err)
+		return &pb.GetBatchResponse{Error: "database error"}, nil
+	}
+	batch.Status = batchStatus(batch.ExpectedCount, batch.Succeeded, batch.Failed)
+
+	start = time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT f.item, COALESCE(f.account_id, ''), COALESCE(f.external_id, ''), f.error, f.failed_at
+		FROM transaction_batch_failures f
+		WHERE f.batch_id = $1 AND f.item > $2
+			AND NOT EXISTS (SELECT 1 FROM transaction_batch_items i WHERE i.batch_id = f.batch_id AND i.item = f.item)
+		ORDER BY f.item
+		LIMIT $3
+	`, req.Id, req.FailuresAfterItem, limit+1)
+	logger.LogDatabase("SELECT", "transaction_batch_failures", time.Since(start), err)
+	if err != nil {
+		logger.Error("Batch failures query failed: ID=%s, Error=%v", req.Id, err)
+		return &pb.GetBatchResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	var failures []*pb.BatchFailure
+	for rows.Next() {
+		failure := &pb.BatchFailure{}
+		if err := rows.Scan(&failure.Item, &failure.AccountId, &failure.ExternalId, &failure.Error, &failure.FailedAt); err != nil {
+			logger.Error("Row scan failed: %v", err)
+			return &pb.GetBatchResponse{Error: "database error"}, nil
+		}
+		failures = append(failures, failure)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Batch failures query failed: ID=%s, Error=%v", req.Id, err)
+		return &pb.GetBatchResponse{Error: "database error"}, nil
+	}
+
+	resp := &pb.GetBatchResponse{Batch: batch, Failures: failures}
+	if len(failures) > int(limit) {
+		resp.Failures = failures[:limit]
+		resp.NextFailuresAfterItem = resp.Failures[limit-1].Item
+	}
+	return resp, nil
+}
+
+// lookupBatchItems loads the expected counts of the batches named by reqs and which of their items were already
+// applied, within tx.
+func (s *Service) lookupBatchItems(ctx context.Context, tx *sql.Tx, reqs []*pb.CreateTransactionRequest) (batchItemState, error) {
+	logger := s.logger.WithContext(ctx)
+
+	state := batchItemState{expectedCounts: make(map[string]int32), applied: make(map[batchItemKey]bool)}
+	var args []interface{}
+	var values []string
+	for _, req := range reqs {
+		if req.BatchId == "" {
+			continue
+		}
+		values = append(values, fmt.Sprintf("($%d::VARCHAR, $%d::INTEGER)", len(args)+1, len(args)+2))
+		args = append(args, req.BatchId, req.BatchItem)
+	}
+	if len(values) == 0 {
+		return state, nil
+	}
+
+	start := time.Now()
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		SELECT v.batch_id, v.item, b.expected_count, i.item IS NOT NULL
+		FROM (VALUES %s) AS v(batch_id, item)
+		JOIN transaction_batches b ON b.id = v.batch_id
+		LEFT JOIN transaction_batch_items i ON i.batch_id = v.batch_id AND i.item = v.item
+	`, strings.Join(values, ", ")), args...)
+	logger.LogDatabase("SELECT", "transaction_batches", time.Since(start), err)
+	if err != nil {
+		return state, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key batchItemKey
+		var expected int32
+		var applied bool
+		if err := rows.Scan(&key.batchID, &key.item, &expected, &applied); err != nil {
+			return state, err
+		}
+		state.expectedCounts[key.batchID] = expected
+		if applied {
+			state.applied[key] = true
+		}
+	}
+	return state, rows.Err()
+}
+
+// insertBatchItems links applied transactions to their batch items with a single multi-row insert.
+func (s *Service) insertBatchItems(ctx context.Context, tx *sql.Tx, items []batchItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	logger := s.logger.WithContext(ctx)
+
+	args := make([]interface{}, 0, len(items)*3)
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		values = append(values, fmt.Sprintf("(%s)", placeholders(len(args)+1, 3)))
+		args = append(args, item.batchID, item.item, item.transactionID)
+	}
+
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO transaction_batch_items (batch_id, item, transaction_id) VALUES %s
+	`, strings.Join(values, ", ")), args...)
+	logger.LogDatabase("INSERT", "transaction_batch_items", time.Since(start), err)
+	return err
+}
+
+// batchFailureOf returns the failure to record for a batch item that could not be applied. Items that were
+// already applied are not recorded, so a duplicate submission cannot mark an applied item as failed.
+// Identifiers too long for their column are left out; they failed validation anyway.
+func batchFailureOf(req *pb.CreateTransactionRequest, msg string) (batchFailure, bool) {
+	if msg == "" || msg == errBatchItemAlreadyApplied || req.BatchId == "" || req.BatchItem <= 0 {
+		return batchFailure{}, false
+	}
+	failure := batchFailure{batchItemKey: batchItemKey{req.BatchId, req.BatchItem}, err: msg}
+	if len(req.AccountId) <= maxAccountIDLength {
+		failure.accountID = req.AccountId
+	}
+	if len(req.ExternalId) <= maxExternalIDLength {
+		failure.externalID = req.ExternalId
+	}
+	return failure, true
+}
+
+// recordBatchFailures keeps the latest failed attempt of each batch item. It is written outside the transaction
+// of the attempt, which was rolled back. Failures of batches that do not exist and of items beyond the batch's
+// expected count are dropped. Recording is best effort: a failure is logged, not returned.
+func (s *Service) recordBatchFailures(ctx context.Context, failures []batchFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	logger := s.logger.WithContext(ctx)
+
+	// An item may fail more than once in an ingest batch; only its last attempt is kept
+	latest := make(map[batchItemKey]int, len(failures))
+	for i, failure := range failures {
+		latest[failure.batchItemKey] = i
+	}
+
+	failedAt := common.GetCurrentTimestamp()
+	args := []interface{}{failedAt}
+	values := make([]string, 0, len(latest))
+	for i, failure := range failures {
+		if latest[failure.batchItemKey] != i {
+			continue
+		}
+		first := len(args) + 1
+		values = append(values, fmt.Sprintf("($%d::VARCHAR, $%d::INTEGER, $%d::VARCHAR, $%d::VARCHAR, $%d::VARCHAR)",
+			first, first+1, first+2, first+3, first+4))
+		args = append(args, failure.batchID, failure.item, failure.accountID, failure.externalID, failure.err)
+	}
+
+	start := time.Now()
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO transaction_batch_failures (batch_id, item, account_id, external_id, error, failed_at)
+		SELECT v.batch_id, v.item, NULLIF(v.account_id, ''), NULLIF(v.external_id, ''), v.error, $1
+		FROM (VALUES %s) AS v(batch_id, item, account_id, external_id, error)
+		JOIN transaction_batches b ON b.id = v.batch_id AND v.item <= b.expected_count
+		ON CONFLICT (batch_id, item) DO UPDATE
+		SET account_id = EXCLUDED.account_id, external_id = EXCLUDED.external_id, error = EXCLUDED.error, failed_at = EXCLUDED.failed_at
+	`, strings.Join(values, ", ")), args...)
+	logger.LogDatabase("INSERT", "transaction_batch_failures", time.Since(start), err)
+	if err != nil {
+		logger.Error("Batch failure recording failed: Failures=%d, Error=%v", len(values), err)
+	}
+}
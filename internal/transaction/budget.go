@@ -0,0 +1,387 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	"github.com/YASHIRAI/pismo-task/proto/events"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// categoryMetadataKey is the metadata entry holding a transaction's spending category.
+const categoryMetadataKey = "category"
+
+// budgetMonthLayout is the format of budget months.
+const budgetMonthLayout = "2006-01"
+
+// defaultBudgetThresholds are the percentages of the limit that publish events when a budget sets none.
+var defaultBudgetThresholds = []int32{80, 100}
+
+// Limits on budget thresholds.
+const (
+	maxBudgetThresholds = 10
+	maxBudgetThreshold  = 1000
+)
+
+// validCategory reports whether category can name a spending category. Categories follow the rules of tags.
+func validCategory(category string) bool {
+	return len(category) <= maxTagLength && tagPattern.MatchString(category)
+}
+
+// transactionCategory returns the spending category of a transaction, or an empty string if it has none.
+func transactionCategory(t *common.Transaction) string {
+	return t.Metadata[categoryMetadataKey]
+}
+
+// budgetMonth returns the bounds, as Unix seconds, and the name of the UTC month containing ts.
+func budgetMonth(ts int64) (start, end int64, name string) {
+	t := time.Unix(ts, 0).UTC()
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return month.Unix(), month.AddDate(0, 1, 0).Unix(), month.Format(budgetMonthLayout)
+}
+
+// normalizeBudgetThresholds validates the thresholds of a budget and returns them sorted without duplicates,
+// or the defaults if none are given. Returns an error message for the caller if they are invalid.
+func normalizeBudgetThresholds(thresholds []int32) ([]int32, string) {
+	if len(thresholds) == 0 {
+		return defaultBudgetThresholds, ""
+	}
+	if len(thresholds) > maxBudgetThresholds {
+		return nil, fmt.Sprintf("at most %d thresholds allowed", maxBudgetThresholds)
+	}
+	seen := make(map[int32]bool, len(thresholds))
+	normalized := make([]int32, 0, len(thresholds))
+	for _, threshold := range thresholds {
+		if threshold < 1 || threshold > maxBudgetThreshold {
+			return nil, fmt.Sprintf("thresholds must be between 1 and %d", maxBudgetThreshold)
+		}
+		if !seen[threshold] {
+			seen[threshold] = true
+			normalized = append(normalized, threshold)
+		}
+	}
+	sort.Slice(normalized, func(i, j int) bool { return normalized[i] < normalized[j] })
+	return normalized, ""
+}
+
+// formatBudgetThresholds returns thresholds as stored in account_budgets.thresholds.
+func formatBudgetThresholds(thresholds []int32) string {
+	values := make([]string, len(thresholds))
+	for i, threshold := range thresholds {
+		values[i] = strconv.Itoa(int(threshold))
+	}
+	return strings.Join(values, ",")
+}
+
+// parseBudgetThresholds decodes account_budgets.thresholds.
+func parseBudgetThresholds(value string) ([]int32, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.Split(value, ",")
+	thresholds := make([]int32, len(parts))
+	for i, part := range parts {
+		threshold, err := strconv.ParseInt(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid budget threshold %q: %w", part, err)
+		}
+		thresholds[i] = int32(threshold)
+	}
+	return thresholds, nil
+}
+
+// thresholdAmount is the spending at which threshold percent of limit is reached, rounded up to whole cents
+// so that spending reaches it exactly when it reaches the unrounded amount.
+func thresholdAmount(limit common.Cents, threshold int32) common.Cents {
+	return common.Cents((int64(limit)*int64(threshold) + 99) / 100)
+}
+
+// crossedThresholds returns the thresholds reached by spent, ascending.
+func crossedThresholds(limit, spent common.Cents, thresholds []int32) []int32 {
+	var crossed []int32
+	for _, threshold := range thresholds {
+		if spent >= thresholdAmount(limit, threshold) {
+			crossed = append(crossed, threshold)
+		}
+	}
+	return crossed
+}
+
+// SetBudget creates or replaces the monthly spending budget of one category of an account.
+// Budgets are soft: spending beyond the limit is never declined, but crossing one of the budget's thresholds
+// publishes a budget.threshold_crossed event.
+func (s *Service) SetBudget(ctx context.Context, req *pb.SetBudgetRequest) (*pb.SetBudgetResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" || req.Category == "" {
+		return &pb.SetBudgetResponse{Error: "account_id and category required"}, nil
+	}
+	if !validCategory(req.Category) {
+		return &pb.SetBudgetResponse{Error: "invalid category"}, nil
+	}
+	if req.MonthlyLimitCents <= 0 {
+		return &pb.SetBudgetResponse{Error: "monthly_limit must be positive"}, nil
+	}
+	thresholds, msg := normalizeBudgetThresholds(req.Thresholds)
+	if msg != "" {
+		return &pb.SetBudgetResponse{Error: msg}, nil
+	}
+
+	var exists int
+	start := time.Now()
+	err := s.db.QueryRowContext(ctx, `SELECT 1 FROM accounts WHERE id = $1`, req.AccountId).Scan(&exists)
+	logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+	if err == sql.ErrNoRows {
+		return &pb.SetBudgetResponse{Error: "account not found"}, nil
+	}
+	if err != nil {
+		logger.Error("Account lookup failed for budget: AccountID=%s, Error=%v", req.AccountId, err)
+		return &pb.SetBudgetResponse{Error: "database error"}, nil
+	}
+
+	budget := &pb.Budget{
+		AccountId:         req.AccountId,
+		Category:          req.Category,
+		MonthlyLimitCents: req.MonthlyLimitCents,
+		Thresholds:        thresholds,
+		UpdatedAt:         common.GetCurrentTimestamp(),
+	}
+	start = time.Now()
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO account_budgets (account_id, category, monthly_limit, thresholds, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (account_id, category) DO UPDATE
+		SET monthly_limit = EXCLUDED.monthly_limit, thresholds = EXCLUDED.thresholds, updated_at = EXCLUDED.updated_at
+		RETURNING created_at
+	`, budget.AccountId, budget.Category, common.Cents(budget.MonthlyLimitCents), formatBudgetThresholds(thresholds), budget.UpdatedAt).Scan(&budget.CreatedAt)
+	logger.LogDatabase("INSERT", "account_budgets", time.Since(start), err)
+	if err != nil {
+		logger.Error("Budget update failed: AccountID=%s, Category=%s, Error=%v", req.AccountId, req.Category, err)
+		return &pb.SetBudgetResponse{Error: "database error"}, nil
+	}
+
+	logger.Info("Budget set: AccountID=%s, Category=%s, MonthlyLimit=%s, Thresholds=%v",
+		budget.AccountId, budget.Category, common.Cents(budget.MonthlyLimitCents), budget.Thresholds)
+	return &pb.SetBudgetResponse{Budget: budget}, nil
+}
+
+// DeleteBudget removes the budget of one category of an account.
+func (s *Service) DeleteBudget(ctx context.Context, req *pb.DeleteBudgetRequest) (*pb.DeleteBudgetResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" || req.Category == "" {
+		return &pb.DeleteBudgetResponse{Error: "account_id and category required"}, nil
+	}
+
+	start := time.Now()
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM account_budgets WHERE account_id = $1 AND category = $2
+	`, req.AccountId, req.Category)
+	logger.LogDatabase("DELETE", "account_budgets", time.Since(start), err)
+	if err != nil {
+		logger.Error("Budget deletion failed: AccountID=%s, Category=%s, Error=%v", req.AccountId, req.Category, err)
+		return &pb.DeleteBudgetResponse{Error: "database error"}, nil
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return &pb.DeleteBudgetResponse{Error: "budget not found"}, nil
+	}
+
+	logger.Info("Budget deleted: AccountID=%s, Category=%s", req.AccountId, req.Category)
+	return &pb.DeleteBudgetResponse{}, nil
+}
+
+// GetBudgetStatus returns the spending against each budget of an account within one UTC month, by default the
+// current one. Spending is the total of the month's completed debits carrying the budget's category.
+func (s *Service) GetBudgetStatus(ctx context.Context, req *pb.GetBudgetStatusRequest) (*pb.GetBudgetStatusResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" {
+		return &pb.GetBudgetStatusResponse{Error: "account_id required"}, nil
+	}
+	at := time.Now().Unix()
+	if req.Month != "" {
+		month, err := time.Parse(budgetMonthLayout, req.Month)
+		if err != nil {
+			return &pb.GetBudgetStatusResponse{Error: "month must be YYYY-MM"}, nil
+		}
+		at = month.Unix()
+	}
+	from, to, name := budgetMonth(at)
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT b.category, b.monthly_limit, b.thresholds, b.created_at, b.updated_at, COALESCE(SUM(-t.amount), 0)
+		FROM account_budgets b
+		LEFT JOIN transactions t ON t.account_id = b.account_id AND t.metadata->>'category' = b.category
+			AND t.status = 'COMPLETED' AND t.amount < 0 AND t.created_at >= $2 AND t.created_at < $3
+		WHERE b.account_id = $1
+		GROUP BY b.category, b.monthly_limit, b.thresholds, b.created_at, b.updated_at
+		ORDER BY b.category
+	`, req.AccountId, from, to)
+	logger.LogDatabase("SELECT", "account_budgets", time.Since(start), err)
+	if err != nil {
+		logger.Error("Budget status query failed: AccountID=%s, Error=%v", req.AccountId, err)
+		return &pb.GetBudgetStatusResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	var statuses []*pb.BudgetStatus
+	for rows.Next() {
+		budget := &pb.Budget{AccountId: req.AccountId}
+		var thresholds string
+		var limit, spent common.Cents
+		if err := rows.Scan(&budget.Category, &limit, &thresholds, &budget.CreatedAt, &budget.UpdatedAt, &spent); err != nil {
+			logger.Error("Row scan failed: %v", err)
+			return &pb.GetBudgetStatusResponse{Error: "database error"}, nil
+		}
+		if budget.Thresholds, err = parseBudgetThresholds(thresholds); err != nil {
+			logger.Error("Budget status query failed: AccountID=%s, Error=%v", req.AccountId, err)
+			return &pb.GetBudgetStatusResponse{Error: "database error"}, nil
+		}
+		budget.MonthlyLimitCents = int64(limit)
+		statuses = append(statuses, &pb.BudgetStatus{
+			Budget:            budget,
+			SpentCents:        int64(spent),
+			RemainingCents:    int64(limit - spent),
+			PercentUsed:       float64(spent) / float64(limit) * 100,
+			CrossedThresholds: crossedThresholds(limit, spent, budget.Thresholds),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Budget status query failed: AccountID=%s, Error=%v", req.AccountId, err)
+		return &pb.GetBudgetStatusResponse{Error: "database error"}, nil
+	}
+
+	return &pb.GetBudgetStatusResponse{Month: name, Budgets: statuses}, nil
+}
+
+// budgetSpending is the spending that transactions written together add to one budget within one month.
+type budgetSpending struct {
+	accountID  string
+	category   string
+	monthStart int64
+	amount     common.Cents
+	// Last transaction of the group, which is credited with the thresholds the group crosses
+	last *common.Transaction
+}
+
+// enqueueBudgetThresholdsCrossed writes a budget.threshold_crossed event within tx for every budget threshold
+// crossed by the given transactions, if the outbox is enabled. It must run after the transactions are written
+// as COMPLETED in tx, so the month's spending includes them; the spending before them is that total minus theirs.
+func (s *Service) enqueueBudgetThresholdsCrossed(ctx context.Context, tx *sql.Tx, transactions ...*common.Transaction) error {
+	if !s.eventOutbox {
+		return nil
+	}
+
+	var groups []*budgetSpending
+	index := make(map[string]*budgetSpending)
+	for _, t := range transactions {
+		category := transactionCategory(t)
+		if category == "" || t.Status != "COMPLETED" || t.Amount >= 0 {
+			continue
+		}
+		monthStart, _, _ := budgetMonth(t.CreatedAt)
+		key := fmt.Sprintf("%s/%s/%d", t.AccountID, category, monthStart)
+		group, ok := index[key]
+		if !ok {
+			group = &budgetSpending{accountID: t.AccountID, category: category, monthStart: monthStart}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		group.amount += -t.Amount
+		group.last = t
+	}
+
+	for _, group := range groups {
+		if err := s.enqueueBudgetGroupCrossings(ctx, tx, group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueueBudgetGroupCrossings writes the events of the thresholds one group of spending crosses, if the account
+// has a budget for its category.
+func (s *Service) enqueueBudgetGroupCrossings(ctx context.Context, tx *sql.Tx, group *budgetSpending) error {
+	logger := s.logger.WithContext(ctx)
+	from, to, month := budgetMonth(group.monthStart)
+
+	var limit, spent common.Cents
+	var thresholdList string
+	start := time.Now()
+	err := tx.QueryRowContext(ctx, `
+		SELECT b.monthly_limit, b.thresholds, COALESCE(SUM(-t.amount), 0)
+		FROM account_budgets b
+		LEFT JOIN transactions t ON t.account_id = b.account_id AND t.metadata->>'category' = b.category
+			AND t.status = 'COMPLETED' AND t.amount < 0 AND t.created_at >= $3 AND t.created_at < $4
+		WHERE b.account_id = $1 AND b.category = $2
+		GROUP BY b.monthly_limit, b.thresholds
+	`, group.accountID, group.category, from, to).Scan(&limit, &thresholdList, &spent)
+	logger.LogDatabase("SELECT", "account_budgets", time.Since(start), err)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	thresholds, err := parseBudgetThresholds(thresholdList)
+	if err != nil {
+		return err
+	}
+
+	before := spent - group.amount
+	tenantID := common.TenantIDFromContext(ctx)
+	for _, threshold := range thresholds {
+		reached := thresholdAmount(limit, threshold)
+		if before >= reached || spent < reached {
+			continue
+		}
+
+		eventID := uuid.New().String()
+		crossedAt := group.last.CreatedAt
+		payload := &events.BudgetThresholdCrossedV1{
+			AccountId:     group.accountID,
+			Category:      group.category,
+			Month:         month,
+			Threshold:     threshold,
+			MonthlyLimit:  limit.Float64(),
+			Spent:         spent.Float64(),
+			TransactionId: group.last.ID,
+			CrossedAt:     crossedAt,
+		}
+		envelope, err := events.NewEnvelope(eventID, events.BudgetThresholdCrossed, crossedAt, tenantID, group.accountID, payload)
+		if err != nil {
+			return err
+		}
+		s.renderEventMessages(ctx, envelope, payload)
+		data, err := proto.Marshal(envelope)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event envelope: %w", err)
+		}
+
+		start := time.Now()
+		err = common.EnqueueEvent(ctx, tx, common.OutboxEvent{
+			EventID:      eventID,
+			EventType:    events.BudgetThresholdCrossed,
+			PartitionKey: group.accountID,
+			Envelope:     data,
+			CreatedAt:    crossedAt,
+		})
+		logger.LogDatabase("INSERT", "event_outbox", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		logger.Info("Budget threshold crossed: AccountID=%s, Category=%s, Month=%s, Threshold=%d%%, Spent=%s, MonthlyLimit=%s",
+			group.accountID, group.category, month, threshold, spent, limit)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// DefaultEndOfDayCloseDelay is how long after a UTC day ends the end-of-day close seals it, unless
+// EOD_CLOSE_DELAY says otherwise, so transactions being written as the day ends are recorded first.
+const DefaultEndOfDayCloseDelay = 5 * time.Minute
+
+// businessDayLayout is the format of business days.
+const businessDayLayout = "2006-01-02"
+
+// endOfDayCloseOperator is who the end-of-day close records as having closed a day.
+const endOfDayCloseOperator = "eod-close"
+
+// Statuses of a business day.
+const (
+	businessDayOpen          = "OPEN"
+	businessDayAwaitingClose = "AWAITING_CLOSE"
+	businessDayClosed        = "CLOSED"
+)
+
+// errBusinessDayClosed is the error of a transaction dated in a business day that has been closed.
+const errBusinessDayClosed = "business day closed"
+
+// CloseBusinessDays closes, oldest first, the UTC days that ended by until and are not closed yet, and returns
+// the number closed. The first close only closes the last of them; earlier days are sealed without totals.
+func (s *Service) CloseBusinessDays(ctx context.Context, until time.Time) (int, error) {
+	logger := s.logger.WithContext(ctx)
+
+	var last sql.NullInt64
+	start := time.Now()
+	err := s.db.QueryRowContext(ctx, `SELECT MAX(day_start) FROM business_days`).Scan(&last)
+	logger.LogDatabase("SELECT", "business_days", time.Since(start), err)
+	if err != nil {
+		return 0, err
+	}
+
+	end := until.Unix() - until.Unix()%secondsPerDay
+	day := end - secondsPerDay
+	if last.Valid {
+		day = last.Int64 + secondsPerDay
+	}
+	closed := 0
+	for ; day < end; day += secondsPerDay {
+		ok, err := s.closeBusinessDay(ctx, day)
+		if err != nil {
+			return closed, err
+		}
+		if ok {
+			closed++
+		}
+	}
+	return closed, nil
+}
+
+// closeBusinessDay seals the UTC day starting at dayStart: it records the balance of every account that existed
+// by its end, with the count and net amount of the day's completed transactions, and their totals over all
+// accounts. Each balance is the one recorded for the previous day plus the day's transactions and approved
+// adjustments; accounts without one, at the first close or created during the day, are derived from the ledger
+// like GetBalanceAt. Writes to transactions only wait for the final step, which books the transactions committed
+// while the balances were recorded and seals the day. It reports false if the day, or a later one, was closed
+// already, e.g. by another replica.
+func (s *Service) closeBusinessDay(ctx context.Context, dayStart int64) (bool, error) {
+	logger := s.logger.WithContext(ctx)
+	dayEnd := dayStart + secondsPerDay
+
+	var accounts, transactions int64
+	closed := false
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		// Closes by other replicas wait for this one; the seal trigger only reads business_days, so writes go on
+		if _, err := tx.ExecContext(ctx, "LOCK TABLE business_days IN EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+
+		var exists bool
+		start := time.Now()
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM business_days WHERE day_start >= $1)`, dayStart).Scan(&exists)
+		logger.LogDatabase("SELECT", "business_days", time.Since(start), err)
+		if err != nil || exists {
+			return err
+		}
+
+		start = time.Now()
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO end_of_day_balances (account_id, day_start, balance, transaction_count, net_amount)
+			SELECT a.id, $1,
+				CASE WHEN p.account_id IS NOT NULL THEN p.balance + COALESCE(d.total_amount, 0) + COALESCE(b.amount, 0)
+				ELSE COALESCE(a.opening_balance, 0)
+					+ COALESCE((SELECT SUM(r.total_amount) FROM transaction_daily_rollups r
+						WHERE r.account_id = a.id AND r.day_start < $2), 0)
+					+ COALESCE((SELECT SUM(CASE WHEN h.direction = 'CREDIT' THEN h.amount ELSE -h.amount END)
+						FROM balance_adjustments h
+						WHERE h.account_id = a.id AND h.status = 'APPROVED' AND COALESCE(h.reviewed_at, h.requested_at) < $2), 0)
+				END,
+				COALESCE(d.txn_count, 0),
+				COALESCE(d.total_amount, 0)
+			FROM accounts a
+			LEFT JOIN end_of_day_balances p ON p.account_id = a.id AND p.day_start = $3
+			LEFT JOIN (
+				SELECT account_id, SUM(txn_count) AS txn_count, SUM(total_amount) AS total_amount
+				FROM transaction_daily_rollups
+				WHERE day_start = $1
+				GROUP BY account_id
+			) d ON d.account_id = a.id
+			LEFT JOIN (
+				SELECT account_id, SUM(CASE WHEN direction = 'CREDIT' THEN amount ELSE -amount END) AS amount
+				FROM balance_adjustments
+				WHERE status = 'APPROVED' AND COALESCE(reviewed_at, requested_at) >= $1 AND COALESCE(reviewed_at, requested_at) < $2
+				GROUP BY account_id
+			) b ON b.account_id = a.id
+			WHERE a.created_at < $2
+		`, dayStart, dayEnd, dayStart-secondsPerDay)
+		logger.LogDatabase("INSERT", "end_of_day_balances", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		if accounts, err = result.RowsAffected(); err != nil {
+			return err
+		}
+
+		// Transactions being written are committed first, and later ones dated in the day are refused by the seal
+		// trigger once this commits, so the day's rollups are final
+		if _, err := tx.ExecContext(ctx, "LOCK TABLE transactions IN SHARE MODE"); err != nil {
+			return err
+		}
+
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE end_of_day_balances e
+			SET balance = e.balance + d.total_amount - e.net_amount, transaction_count = d.txn_count, net_amount = d.total_amount
+			FROM (
+				SELECT account_id, SUM(txn_count) AS txn_count, SUM(total_amount) AS total_amount
+				FROM transaction_daily_rollups
+				WHERE day_start = $1
+				GROUP BY account_id
+			) d
+			WHERE e.account_id = d.account_id AND e.day_start = $1
+				AND (e.transaction_count <> d.txn_count OR e.net_amount <> d.total_amount)
+		`, dayStart)
+		logger.LogDatabase("UPDATE", "end_of_day_balances", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+
+		start = time.Now()
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO business_days (day_start, closed_at, closed_by, account_count, transaction_count, net_amount)
+			SELECT $1, $2, $3, $4, COALESCE(SUM(r.txn_count), 0), COALESCE(SUM(r.total_amount), 0)
+			FROM transaction_daily_rollups r
+			JOIN end_of_day_balances e ON e.account_id = r.account_id AND e.day_start = r.day_start
+			WHERE r.day_start = $1
+			RETURNING transaction_count
+		`, dayStart, common.GetCurrentTimestamp(), endOfDayCloseOperator, accounts).Scan(&transactions)
+		logger.LogDatabase("INSERT", "business_days", time.Since(start), err)
+		closed = err == nil
+		return err
+	})
+	if err != nil {
+		logger.Error("Business day close failed: Day=%s, Error=%v", time.Unix(dayStart, 0).UTC().Format(businessDayLayout), err)
+		return false, err
+	}
+	if closed {
+		logger.Info("Business day closed: Day=%s, Accounts=%d, Transactions=%d",
+			time.Unix(dayStart, 0).UTC().Format(businessDayLayout), accounts, transactions)
+	}
+	return closed, nil
+}
+
+// RunEndOfDayClose closes the business days that ended at least delay ago, checking every interval, until
+// ctx is done.
+func (s *Service) RunEndOfDayClose(ctx context.Context, interval, delay time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.CloseBusinessDays(ctx, time.Now().Add(-delay)); err != nil {
+				s.logger.Error("End-of-day close failed: %v", err)
+			}
+		}
+	}
+}
+
+// GetBusinessDayStatus returns whether a UTC business day is open, awaiting its close or closed, with the totals
+// recorded by its close and, for an account, the balance recorded for it.
+func (s *Service) GetBusinessDayStatus(ctx context.Context, req *pb.GetBusinessDayStatusRequest) (*pb.BusinessDayStatusResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	now := time.Now().UTC()
+	date := req.Date
+	if date == "" {
+		date = now.Format(businessDayLayout)
+	}
+	parsed, err := time.Parse(businessDayLayout, date)
+	if err != nil {
+		return &pb.BusinessDayStatusResponse{Error: "date must be YYYY-MM-DD"}, nil
+	}
+	if parsed.After(now) {
+		return &pb.BusinessDayStatusResponse{Error: "date is in the future"}, nil
+	}
+	day := &pb.BusinessDay{
+		Date:     date,
+		DayStart: parsed.Unix(),
+		DayEnd:   parsed.Unix() + secondsPerDay,
+		Status:   businessDayOpen,
+	}
+	if day.DayEnd <= now.Unix() {
+		day.Status = businessDayAwaitingClose
+	}
+	resp := &pb.BusinessDayStatusResponse{Day: day}
+
+	var last sql.NullInt64
+	var netAmount common.Cents
+	start := time.Now()
+	err = s.db.QueryRowContext(ctx, `
+		SELECT m.last, COALESCE(b.closed_at, 0), COALESCE(b.closed_by, ''), COALESCE(b.account_count, 0),
+			COALESCE(b.transaction_count, 0), COALESCE(b.net_amount, 0)
+		FROM (SELECT MAX(day_start) AS last FROM business_days) m
+		LEFT JOIN business_days b ON b.day_start = $1
+	`, day.DayStart).Scan(&last, &day.ClosedAt, &day.ClosedBy, &day.AccountCount, &day.TransactionCount, &netAmount)
+	logger.LogDatabase("SELECT", "business_days", time.Since(start), err)
+	if err != nil {
+		logger.Error("Business day lookup failed: Date=%s, Error=%v", date, err)
+		return &pb.BusinessDayStatusResponse{Error: "database error"}, nil
+	}
+	day.NetAmountCents = int64(netAmount)
+	if !last.Valid || last.Int64 < day.DayStart {
+		return resp, nil
+	}
+	// Days before the first close are sealed with it, without totals
+	day.Status = businessDayClosed
+	resp.LastClosedDate = time.Unix(last.Int64, 0).UTC().Format(businessDayLayout)
+
+	if req.AccountId == "" {
+		return resp, nil
+	}
+	var balance common.Cents
+	balanceResp := &pb.EndOfDayBalance{AccountId: req.AccountId, Date: date}
+	start = time.Now()
+	err = s.db.QueryRowContext(ctx, `
+		SELECT balance, transaction_count, net_amount FROM end_of_day_balances WHERE account_id = $1 AND day_start = $2
+	`, req.AccountId, day.DayStart).Scan(&balance, &balanceResp.TransactionCount, &netAmount)
+	logger.LogDatabase("SELECT", "end_of_day_balances", time.Since(start), err)
+	switch {
+	case err == sql.ErrNoRows:
+		// The account did not exist by the end of the day, or the day was sealed without totals
+		return resp, nil
+	case err != nil:
+		logger.Error("End-of-day balance lookup failed: AccountID=%s, Date=%s, Error=%v", req.AccountId, date, err)
+		return &pb.BusinessDayStatusResponse{Error: "database error"}, nil
+	}
+	balanceResp.BalanceCents = int64(balance)
+	balanceResp.NetAmountCents = int64(netAmount)
+	resp.Balance = balanceResp
+	return resp, nil
+}
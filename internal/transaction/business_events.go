@@ -0,0 +1,125 @@
+package transaction
+
+import (
+	"context"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// businessEventsMeter is the instrumentation scope of the business event instruments.
+const businessEventsMeter = "github.com/YASHIRAI/pismo-task/internal/transaction"
+
+// Business event instruments the service records, exported under the names given by BusinessEventViews.
+const (
+	insufficientBalanceInstrument = "transaction.declines.insufficient_balance"
+	limitExceededInstrument       = "transaction.declines.limit_exceeded"
+	reversalsInstrument           = "transaction.reversals"
+)
+
+// Attributes of the business events. The tenant is recorded but left out by BusinessEventViews, so the number of
+// exported series does not grow with the number of tenants.
+const (
+	operationTypeAttribute = attribute.Key("operation_type")
+	tenantIDAttribute      = attribute.Key("tenant_id")
+)
+
+// limitExceededReasons are the decline reasons counted as limit_exceeded_rejections.
+var limitExceededReasons = map[string]bool{
+	"amount exceeds tenant limit": true,
+}
+
+// BusinessEventViews returns the OpenTelemetry views exporting the business events of the service as the metrics
+// product dashboards read: insufficient_balance_rejections, limit_exceeded_rejections and reversals, each by
+// operation type. Combined with the service.version of the resource, they show how decline reasons move with
+// releases.
+func BusinessEventViews() []sdkmetric.View {
+	view := func(instrument, name, description string) sdkmetric.View {
+		return sdkmetric.NewView(
+			sdkmetric.Instrument{Name: instrument, Scope: instrumentation.Scope{Name: businessEventsMeter}},
+			sdkmetric.Stream{
+				Name:            name,
+				Description:     description,
+				Unit:            "{transaction}",
+				AttributeFilter: attribute.NewAllowKeysFilter(operationTypeAttribute),
+			},
+		)
+	}
+	return []sdkmetric.View{
+		view(insufficientBalanceInstrument, "insufficient_balance_rejections",
+			"Transaction requests declined because the account balance, including its overdraft, was insufficient."),
+		view(limitExceededInstrument, "limit_exceeded_rejections",
+			"Transaction requests declined because the amount exceeded the tenant's limit."),
+		view(reversalsInstrument, "reversals", "Transactions reversed, by the operation type of the reversed transaction."),
+	}
+}
+
+// NewBusinessEventsMeterProvider creates a meter provider exporting the business events of the service through
+// BusinessEventViews with the OTLP/HTTP exporter, which is configured by the standard OTEL_EXPORTER_OTLP_*
+// environment variables and exports every OTEL_METRIC_EXPORT_INTERVAL (default 1m). release is reported as the
+// service.version of the resource.
+func NewBusinessEventsMeterProvider(ctx context.Context, serviceName, release string) (*sdkmetric.MeterProvider, error) {
+	exporter, err := otlpmetrichttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName), semconv.ServiceVersion(release))),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+		sdkmetric.WithView(BusinessEventViews()...),
+	), nil
+}
+
+// businessEvents counts the business events of the service.
+type businessEvents struct {
+	insufficientBalance metric.Int64Counter
+	limitExceeded       metric.Int64Counter
+	reversals           metric.Int64Counter
+}
+
+// SetMeterProvider makes the service record its business events with a meter of provider.
+func (s *Service) SetMeterProvider(provider metric.MeterProvider) error {
+	meter := provider.Meter(businessEventsMeter)
+	var events businessEvents
+	var err error
+	if events.insufficientBalance, err = meter.Int64Counter(insufficientBalanceInstrument); err != nil {
+		return err
+	}
+	if events.limitExceeded, err = meter.Int64Counter(limitExceededInstrument); err != nil {
+		return err
+	}
+	if events.reversals, err = meter.Int64Counter(reversalsInstrument); err != nil {
+		return err
+	}
+	s.events = &events
+	return nil
+}
+
+// recordDecline counts a declined transaction request if its reason is a business event. It does nothing on nil
+// events.
+func (e *businessEvents) recordDecline(ctx context.Context, operationType, reason string) {
+	if e == nil {
+		return
+	}
+	attributes := metric.WithAttributes(operationTypeAttribute.String(operationType), tenantIDAttribute.String(common.TenantIDFromContext(ctx)))
+	switch {
+	case reason == "insufficient balance":
+		e.insufficientBalance.Add(ctx, 1, attributes)
+	case limitExceededReasons[reason]:
+		e.limitExceeded.Add(ctx, 1, attributes)
+	}
+}
+
+// recordReversal counts a reversal of a transaction of the given operation type. It does nothing on nil events.
+func (e *businessEvents) recordReversal(ctx context.Context, operationType string) {
+	if e == nil {
+		return
+	}
+	e.reversals.Add(ctx, 1, metric.WithAttributes(operationTypeAttribute.String(operationType), tenantIDAttribute.String(common.TenantIDFromContext(ctx))))
+}
@@ -0,0 +1,340 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"github.com/google/uuid"
+)
+
+// maxChargebackLines caps the number of chargeback records accepted in one imported file.
+const maxChargebackLines = 10000
+
+// chargebackLookupBatchSize caps how many external IDs are matched against transactions per query.
+const chargebackLookupBatchSize = 500
+
+// chargebackLookupWorkers is how many lookup batches of a chargeback file are queried concurrently.
+const chargebackLookupWorkers = 4
+
+// maxReasonCodeLength is the size of the disputes.reason_code column.
+const maxReasonCodeLength = 20
+
+// Fixed-width chargeback record layout. Each record is one line starting with the record type;
+// header (HD) and trailer (TR) records are skipped. Amounts are in minor units, zero padded.
+//
+//	cols  1-2   record type, CB
+//	cols  3-34  external ID, space padded
+//	cols 35-46  amount in minor units
+//	cols 47-50  network reason code, space padded
+//	cols 51-58  chargeback date, YYYYMMDD (optional)
+const (
+	fixedRecordType      = "CB"
+	fixedExternalIDEnd   = 34
+	fixedAmountEnd       = 46
+	fixedReasonCodeEnd   = 50
+	fixedDateEnd         = 58
+	fixedMinRecordLength = fixedReasonCodeEnd
+)
+
+// chargebackLine is a chargeback record parsed from an imported file.
+type chargebackLine struct {
+	line       int32
+	externalID string
+	amount     common.Cents
+	reasonCode string
+}
+
+// chargebackTarget is a transaction a chargeback was matched to.
+type chargebackTarget struct {
+	id        string
+	accountID string
+	amount    common.Cents
+}
+
+// parseChargebackFile parses a chargeback file in the given format, csv (the default) or fixed.
+// Records that cannot be parsed are returned as unmatched lines; an error is returned only when
+// the file as a whole cannot be read.
+func parseChargebackFile(content []byte, format string) ([]chargebackLine, []*pb.UnmatchedChargeback, error) {
+	switch format {
+	case "", "csv":
+		return parseChargebackCSV(content)
+	case "fixed":
+		return parseChargebackFixed(content)
+	default:
+		return nil, nil, fmt.Errorf("format must be csv or fixed")
+	}
+}
+
+// parseChargebackCSV parses a CSV file with a header row naming at least the external_id, amount
+// and reason_code columns. Amounts are in major units, e.g. 12.50. Other columns are ignored.
+func parseChargebackCSV(content []byte) ([]chargebackLine, []*pb.UnmatchedChargeback, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"external_id", "amount", "reason_code"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("missing column: %s", required)
+		}
+	}
+
+	var lines []chargebackLine
+	var unmatched []*pb.UnmatchedChargeback
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			unmatched = append(unmatched, &pb.UnmatchedChargeback{Line: int32(line), Reason: "invalid line"})
+			continue
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		parsed, reason := newChargebackLine(int32(line), field("external_id"), field("reason_code"))
+		if reason == "" {
+			parsed.amount, err = common.ParseCents(field("amount"))
+			if err != nil || parsed.amount <= 0 {
+				reason = "invalid amount"
+			}
+		}
+		if reason != "" {
+			unmatched = append(unmatched, &pb.UnmatchedChargeback{Line: int32(line), ExternalId: parsed.externalID, Reason: reason})
+			continue
+		}
+		lines = append(lines, parsed)
+	}
+	return lines, unmatched, nil
+}
+
+// parseChargebackFixed parses a fixed-width file using the record layout described above.
+func parseChargebackFixed(content []byte) ([]chargebackLine, []*pb.UnmatchedChargeback, error) {
+	var lines []chargebackLine
+	var unmatched []*pb.UnmatchedChargeback
+	for i, raw := range strings.Split(string(content), "\n") {
+		lineNumber := int32(i + 1)
+		record := strings.TrimRight(raw, "\r")
+		if strings.TrimSpace(record) == "" || strings.HasPrefix(record, "HD") || strings.HasPrefix(record, "TR") {
+			continue
+		}
+		if !strings.HasPrefix(record, fixedRecordType) || len(record) < fixedMinRecordLength {
+			unmatched = append(unmatched, &pb.UnmatchedChargeback{Line: lineNumber, Reason: "invalid line"})
+			continue
+		}
+
+		parsed, reason := newChargebackLine(lineNumber,
+			strings.TrimSpace(record[len(fixedRecordType):fixedExternalIDEnd]),
+			strings.TrimSpace(record[fixedAmountEnd:fixedReasonCodeEnd]))
+		if reason == "" {
+			minorUnits, err := strconv.ParseInt(record[fixedExternalIDEnd:fixedAmountEnd], 10, 64)
+			if err != nil || minorUnits <= 0 {
+				reason = "invalid amount"
+			}
+			parsed.amount = common.Cents(minorUnits)
+		}
+		if reason == "" && len(record) >= fixedDateEnd {
+			if date := strings.TrimSpace(record[fixedReasonCodeEnd:fixedDateEnd]); date != "" {
+				if _, err := time.Parse("20060102", date); err != nil {
+					reason = "invalid date"
+				}
+			}
+		}
+		if reason != "" {
+			unmatched = append(unmatched, &pb.UnmatchedChargeback{Line: lineNumber, ExternalId: parsed.externalID, Reason: reason})
+			continue
+		}
+		lines = append(lines, parsed)
+	}
+	return lines, unmatched, nil
+}
+
+// newChargebackLine validates the fields shared by every file format.
+// Returns the line and an empty reason, or the reason the line is rejected.
+func newChargebackLine(line int32, externalID, reasonCode string) (chargebackLine, string) {
+	parsed := chargebackLine{line: line, externalID: externalID, reasonCode: reasonCode}
+	switch {
+	case externalID == "" || len(externalID) > maxExternalIDLength:
+		return parsed, "invalid external_id"
+	case reasonCode == "" || len(reasonCode) > maxReasonCodeLength:
+		return parsed, "invalid reason code"
+	}
+	return parsed, ""
+}
+
+// ImportChargebacks imports a network chargeback file. Each record is matched to a transaction by its
+// external_id and opens a dispute for the charged-back amount; a transaction has at most one dispute,
+// so re-importing a file is safe. Records that cannot be parsed or matched are reported as unmatched.
+// Only support and admin operators may import files. Disputes are opened in a single database
+// transaction, so a failed import leaves nothing behind.
+func (s *Service) ImportChargebacks(ctx context.Context, req *pb.ImportChargebacksRequest) (*pb.ImportChargebacksResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.SupportOrAdmin) {
+		logger.Warn("Rejected chargeback import: Role=%q", common.CallerRoleFromContext(ctx))
+		return &pb.ImportChargebacksResponse{Error: "permission denied"}, nil
+	}
+	if len(req.Content) == 0 {
+		return &pb.ImportChargebacksResponse{Error: "empty file"}, nil
+	}
+
+	lines, unmatched, err := parseChargebackFile(req.Content, req.Format)
+	if err != nil {
+		return &pb.ImportChargebacksResponse{Error: err.Error()}, nil
+	}
+	if len(lines)+len(unmatched) > maxChargebackLines {
+		return &pb.ImportChargebacksResponse{Error: fmt.Sprintf("file has more than %d records", maxChargebackLines)}, nil
+	}
+
+	targets, err := s.findChargebackTargets(ctx, lines)
+	if err != nil {
+		if common.IsCancellation(err) {
+			return &pb.ImportChargebacksResponse{Error: "request cancelled"}, nil
+		}
+		logger.Error("Chargeback matching failed: %v", err)
+		return &pb.ImportChargebacksResponse{Error: "database error"}, nil
+	}
+
+	type match struct {
+		line   chargebackLine
+		target chargebackTarget
+	}
+	var matches []match
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		target, ok := targets[line.externalID]
+		switch {
+		case seen[line.externalID]:
+			unmatched = append(unmatched, &pb.UnmatchedChargeback{Line: line.line, ExternalId: line.externalID, Reason: "duplicate external_id in file"})
+		case !ok:
+			unmatched = append(unmatched, &pb.UnmatchedChargeback{Line: line.line, ExternalId: line.externalID, Reason: "transaction not found"})
+		case line.amount > target.amount.Abs():
+			unmatched = append(unmatched, &pb.UnmatchedChargeback{Line: line.line, ExternalId: line.externalID, Reason: "amount exceeds transaction amount"})
+		default:
+			matches = append(matches, match{line: line, target: target})
+		}
+		seen[line.externalID] = true
+	}
+
+	response := &pb.ImportChargebacksResponse{Unmatched: unmatched}
+	err = common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		response.Opened = nil
+		response.AlreadyDisputed = 0
+		for _, m := range matches {
+			dispute := &pb.Dispute{
+				Id:            uuid.New().String(),
+				TransactionId: m.target.id,
+				AccountId:     m.target.accountID,
+				AmountCents:   int64(m.line.amount),
+				ReasonCode:    m.line.reasonCode,
+				Status:        "OPEN",
+				OpenedAt:      common.GetCurrentTimestamp(),
+			}
+
+			start := time.Now()
+			result, err := tx.ExecContext(ctx, `
+				INSERT INTO disputes (id, transaction_id, account_id, amount, reason_code, status, source, opened_at)
+				VALUES ($1, $2, $3, $4, $5, $6, 'NETWORK_FILE', $7)
+				ON CONFLICT (transaction_id) DO NOTHING
+			`, dispute.Id, dispute.TransactionId, dispute.AccountId, m.line.amount, dispute.ReasonCode, dispute.Status, dispute.OpenedAt)
+			logger.LogDatabase("INSERT", "disputes", time.Since(start), err)
+			if err != nil {
+				return err
+			}
+			if inserted, err := result.RowsAffected(); err != nil {
+				return err
+			} else if inserted == 0 {
+				response.AlreadyDisputed++
+				continue
+			}
+			response.Opened = append(response.Opened, dispute)
+		}
+		return nil
+	})
+	if err != nil {
+		if common.IsCancellation(err) {
+			return &pb.ImportChargebacksResponse{Error: "request cancelled"}, nil
+		}
+		logger.Error("Chargeback import failed: %v", err)
+		return &pb.ImportChargebacksResponse{Error: "could not open disputes"}, nil
+	}
+
+	logger.Info("Chargeback file imported: Format=%s, Opened=%d, AlreadyDisputed=%d, Unmatched=%d",
+		req.Format, len(response.Opened), response.AlreadyDisputed, len(response.Unmatched))
+	return response, nil
+}
+
+// findChargebackTargets loads the transactions referenced by the chargeback lines, keyed by external_id.
+func (s *Service) findChargebackTargets(ctx context.Context, lines []chargebackLine) (map[string]chargebackTarget, error) {
+	logger := s.logger.WithContext(ctx)
+
+	ids := make([]interface{}, 0, len(lines))
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		if !seen[line.externalID] {
+			seen[line.externalID] = true
+			ids = append(ids, line.externalID)
+		}
+	}
+
+	// Lookup batches are independent, so a few are queried at a time; the first failure cancels the rest
+	targets := make(map[string]chargebackTarget, len(ids))
+	var mu sync.Mutex
+	group, ctx := common.NewWorkerGroup(ctx, chargebackLookupWorkers, common.FailFast)
+	for start := 0; start < len(ids); start += chargebackLookupBatchSize {
+		batch := ids[start:min(start+chargebackLookupBatchSize, len(ids))]
+		ok := group.Go(func(ctx context.Context) error {
+			queryStart := time.Now()
+			rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+				SELECT external_id, id, account_id, amount FROM transactions WHERE external_id IN (%s)
+			`, placeholders(1, len(batch))), batch...)
+			logger.LogDatabase("SELECT", "transactions", time.Since(queryStart), err)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				var externalID string
+				var target chargebackTarget
+				if err := rows.Scan(&externalID, &target.id, &target.accountID, &target.amount); err != nil {
+					return err
+				}
+				mu.Lock()
+				targets[externalID] = target
+				mu.Unlock()
+			}
+			return rows.Err()
+		})
+		if !ok {
+			break
+		}
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
@@ -0,0 +1,67 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	"github.com/YASHIRAI/pismo-task/internal/fx"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// paymentConversion is how a payment made in a currency other than the account's was converted to it.
+type paymentConversion struct {
+	currency string
+	amount   common.Cents
+	rate     float64
+}
+
+// SetRateProvider makes the service accept payments in currencies other than the account's, converted at the
+// rates of provider. Without one, only payments in the account's currency are accepted.
+func (s *Service) SetRateProvider(provider fx.RateProvider) {
+	s.rates = provider
+}
+
+// convertPayment looks up the rate a payment made in a currency other than the account's is converted at.
+// Accounts hold the currency of the tenant the request is made for. Returns nil if the payment needs no
+// conversion, or the error message of the response.
+func (s *Service) convertPayment(ctx context.Context, req *pb.ProcessPaymentRequest) (*paymentConversion, string) {
+	if req.Currency == "" {
+		return nil, ""
+	}
+	if !fx.ValidCurrency(req.Currency) {
+		return nil, "invalid currency"
+	}
+
+	var accountCurrency string
+	if tenantID := common.TenantIDFromContext(ctx); tenantID != "" {
+		settings, err := s.tenants.Get(ctx, tenantID)
+		if err == common.ErrInvalidTenantID {
+			return nil, "invalid tenant id"
+		}
+		if err != nil {
+			s.logger.WithContext(ctx).Error("Tenant settings lookup failed: TenantID=%s, Error=%v", tenantID, err)
+			return nil, "database error"
+		}
+		accountCurrency = settings.Currency
+	}
+	if req.Currency == accountCurrency {
+		return nil, ""
+	}
+	if accountCurrency == "" {
+		return nil, "account currency not configured"
+	}
+	if s.rates == nil {
+		return nil, "currency conversion not available"
+	}
+
+	rate, err := s.rates.Rate(ctx, req.Currency, accountCurrency)
+	if errors.Is(err, fx.ErrRateUnavailable) {
+		return nil, "exchange rate not available"
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).Error("Exchange rate lookup failed: From=%s, To=%s, Error=%v", req.Currency, accountCurrency, err)
+		return nil, "exchange rate lookup failed"
+	}
+	return &paymentConversion{currency: req.Currency, amount: common.Cents(req.AmountCents), rate: rate}, ""
+}
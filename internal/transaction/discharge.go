@@ -0,0 +1,189 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// dischargedOperationTypes lists the debits payments discharge. Transfers are settled as they happen.
+var dischargedOperationTypes = map[string]bool{
+	"CASH_PURCHASE":        true,
+	"INSTALLMENT_PURCHASE": true,
+	"WITHDRAWAL":           true,
+}
+
+// outstandingDebtsSQL selects the completed purchases and withdrawals of an account with part of their amount
+// still to be discharged, oldest first. Held or pending debits have not been applied to the balance yet.
+const outstandingDebtsSQL = `
+	SELECT id, balance FROM transactions
+	WHERE account_id = $1 AND balance < 0 AND status = 'COMPLETED'
+		AND operation_type IN ('CASH_PURCHASE', 'INSTALLMENT_PURCHASE', 'WITHDRAWAL')
+	ORDER BY created_at, id`
+
+// debtQuerier is implemented by *sql.DB and *sql.Tx.
+type debtQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// outstandingDebt is a completed purchase or withdrawal with part of its amount still to be discharged.
+type outstandingDebt struct {
+	id      string
+	balance common.Cents
+}
+
+// initialBalance returns the balance a transaction is recorded with: purchases and withdrawals start fully
+// outstanding, payments with their whole amount before any discharge, and other transactions with nothing.
+func initialBalance(t *common.Transaction) common.Cents {
+	if dischargedOperationTypes[t.OperationType] || t.OperationType == "PAYMENT" {
+		return t.Amount
+	}
+	return 0
+}
+
+// loadOutstandingDebts returns the outstanding debts of an account a payment of amount reaches, oldest first.
+// The rest are not read. Pass FOR UPDATE as suffix to lock the transactions the payment will discharge.
+func (s *Service) loadOutstandingDebts(ctx context.Context, q debtQuerier, accountID string, amount common.Cents, suffix string) ([]outstandingDebt, error) {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, outstandingDebtsSQL+suffix, accountID)
+	logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+	if err != nil {
+		return nil, fmt.Errorf("outstanding transactions lookup failed: %w", err)
+	}
+	defer rows.Close()
+
+	var debts []outstandingDebt
+	covered := common.Cents(0)
+	for covered < amount && rows.Next() {
+		var debt outstandingDebt
+		if err := rows.Scan(&debt.id, &debt.balance); err != nil {
+			return nil, err
+		}
+		debts = append(debts, debt)
+		covered -= debt.balance
+	}
+	return debts, rows.Err()
+}
+
+// planDischarges applies a payment of amount to debts in order and returns the part discharged from each,
+// and the part of the payment left over.
+func planDischarges(debts []outstandingDebt, amount common.Cents) ([]*pb.Discharge, common.Cents) {
+	var discharges []*pb.Discharge
+	remaining := amount
+	for _, debt := range debts {
+		if remaining <= 0 {
+			break
+		}
+		discharged := min(remaining, -debt.balance)
+		discharges = append(discharges, &pb.Discharge{
+			TransactionId:     debt.id,
+			AmountCents:       int64(discharged),
+			BalanceAfterCents: int64(debt.balance + discharged),
+		})
+		remaining -= discharged
+	}
+	return discharges, remaining
+}
+
+// dischargeDebts applies a payment of amount to the account's outstanding purchases and withdrawals, oldest
+// first, within tx, and returns what it discharged and the part of the payment left over. The discharged
+// transactions are locked so two payments on different instances cannot discharge the same balance twice.
+// The account balance still moves by the whole payment: the debits were taken from it when they completed,
+// so the discharged part and the remainder together are what the payment gives back.
+func (s *Service) dischargeDebts(ctx context.Context, tx *sql.Tx, accountID string, amount common.Cents) ([]*pb.Discharge, common.Cents, error) {
+	logger := s.logger.WithContext(ctx)
+
+	debts, err := s.loadOutstandingDebts(ctx, tx, accountID, amount, " FOR UPDATE")
+	if err != nil {
+		return nil, 0, err
+	}
+	discharges, remainder := planDischarges(debts, amount)
+	for _, discharge := range discharges {
+		start := time.Now()
+		_, err := tx.ExecContext(ctx, `UPDATE transactions SET balance = balance + $1 WHERE id = $2`,
+			common.Cents(discharge.AmountCents), discharge.TransactionId)
+		logger.LogDatabase("UPDATE", "transactions", time.Since(start), err)
+		if err != nil {
+			return nil, 0, fmt.Errorf("discharge of transaction %s failed: %w", discharge.TransactionId, err)
+		}
+		logger.Debug("Transaction discharged: ID=%s, Discharged=%s, Balance=%s",
+			discharge.TransactionId, common.Cents(discharge.AmountCents), common.Cents(discharge.BalanceAfterCents))
+	}
+	return discharges, remainder, nil
+}
+
+// recordDischarges records what a payment discharged from each debt, within the transaction writing the payment
+// after it is inserted, so reversing the payment can reopen them.
+func (s *Service) recordDischarges(ctx context.Context, tx *sql.Tx, paymentID string, discharges []*pb.Discharge) error {
+	if len(discharges) == 0 {
+		return nil
+	}
+	logger := s.logger.WithContext(ctx)
+
+	args := make([]interface{}, 0, len(discharges)*3)
+	values := make([]string, 0, len(discharges))
+	for _, discharge := range discharges {
+		values = append(values, fmt.Sprintf("(%s)", placeholders(len(args)+1, 3)))
+		args = append(args, paymentID, discharge.TransactionId, common.Cents(discharge.AmountCents))
+	}
+
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO payment_discharges (payment_id, debt_id, amount) VALUES %s
+	`, strings.Join(values, ", ")), args...)
+	logger.LogDatabase("INSERT", "payment_discharges", time.Since(start), err)
+	return err
+}
+
+// dischargePayment applies a payment that completed after it was written to the outstanding debts of its
+// account, within tx, records the discharges and updates the part of the payment left over.
+func (s *Service) dischargePayment(ctx context.Context, tx *sql.Tx, payment *common.Transaction) error {
+	logger := s.logger.WithContext(ctx)
+
+	discharges, remainder, err := s.dischargeDebts(ctx, tx, payment.AccountID, payment.Amount)
+	if err != nil {
+		return err
+	}
+	if err := s.recordDischarges(ctx, tx, payment.ID, discharges); err != nil {
+		return err
+	}
+	if remainder == payment.Balance {
+		return nil
+	}
+	start := time.Now()
+	_, err = tx.ExecContext(ctx, `UPDATE transactions SET balance = $1 WHERE id = $2`, remainder, payment.ID)
+	logger.LogDatabase("UPDATE", "transactions", time.Since(start), err)
+	if err != nil {
+		return err
+	}
+	payment.Balance = remainder
+	return nil
+}
+
+// reopenDischargedDebts gives the debts a payment discharged back what it discharged from them, within the
+// transaction reversing the payment, so they are outstanding again.
+func (s *Service) reopenDischargedDebts(ctx context.Context, tx *sql.Tx, paymentID string) error {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE transactions t SET balance = t.balance - d.amount
+		FROM payment_discharges d
+		WHERE d.payment_id = $1 AND t.id = d.debt_id
+	`, paymentID)
+	logger.LogDatabase("UPDATE", "transactions", time.Since(start), err)
+	if err != nil {
+		return err
+	}
+	if reopened, err := result.RowsAffected(); err == nil && reopened > 0 {
+		logger.Info("Discharged transactions reopened: PaymentID=%s, Count=%d", paymentID, reopened)
+	}
+	return nil
+}
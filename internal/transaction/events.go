@@ -0,0 +1,219 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	"github.com/YASHIRAI/pismo-task/proto/events"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// EnableEventOutbox makes the service write a transaction.created event to the outbox for every
+// transaction it creates, in the same database transaction, for the outbox relay to publish. Events carry the
+// messages rendered from the message templates of their tenant.
+func (s *Service) EnableEventOutbox() {
+	s.eventOutbox = true
+}
+
+// enqueueTransactionsCreated writes a transaction.created event for each transaction within tx,
+// if the outbox is enabled. Events are keyed by account, so those of an account are published in order.
+func (s *Service) enqueueTransactionsCreated(ctx context.Context, tx *sql.Tx, transactions ...*common.Transaction) error {
+	if !s.eventOutbox {
+		return nil
+	}
+	logger := s.logger.WithContext(ctx)
+	tenantID := common.TenantIDFromContext(ctx)
+
+	for _, t := range transactions {
+		eventID := uuid.New().String()
+		payload := &events.TransactionCreatedV1{
+			TransactionId: t.ID,
+			AccountId:     t.AccountID,
+			OperationType: t.OperationType,
+			Amount:        t.Amount.Float64(),
+			Description:   t.Description,
+			Status:        t.Status,
+			ExternalId:    t.ExternalID,
+			CreatedAt:     t.CreatedAt,
+			TransferId:    t.TransferID,
+		}
+		envelope, err := events.NewEnvelope(eventID, events.TransactionCreated, t.CreatedAt, tenantID, t.AccountID, payload)
+		if err != nil {
+			return err
+		}
+		s.renderEventMessages(ctx, envelope, payload)
+		data, err := proto.Marshal(envelope)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event envelope: %w", err)
+		}
+
+		start := time.Now()
+		err = common.EnqueueEvent(ctx, tx, common.OutboxEvent{
+			EventID:      eventID,
+			EventType:    events.TransactionCreated,
+			PartitionKey: t.AccountID,
+			Envelope:     data,
+			CreatedAt:    t.CreatedAt,
+		})
+		logger.LogDatabase("INSERT", "event_outbox", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueueTransactionReversed writes a transaction.reversed event for original, reversed by reversal, within tx,
+// if the outbox is enabled. It is keyed by account like transaction.created.
+func (s *Service) enqueueTransactionReversed(ctx context.Context, tx *sql.Tx, original, reversal *common.Transaction) error {
+	if !s.eventOutbox {
+		return nil
+	}
+
+	eventID := uuid.New().String()
+	payload := &events.TransactionReversedV1{
+		TransactionId:         original.ID,
+		AccountId:             original.AccountID,
+		Amount:                reversal.Amount.Float64(),
+		Reason:                reversal.Description,
+		ReversedAt:            reversal.CreatedAt,
+		ReversalTransactionId: reversal.ID,
+	}
+	envelope, err := events.NewEnvelope(eventID, events.TransactionReversed, reversal.CreatedAt, common.TenantIDFromContext(ctx), original.AccountID, payload)
+	if err != nil {
+		return err
+	}
+	s.renderEventMessages(ctx, envelope, payload)
+	data, err := proto.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event envelope: %w", err)
+	}
+
+	start := time.Now()
+	err = common.EnqueueEvent(ctx, tx, common.OutboxEvent{
+		EventID:      eventID,
+		EventType:    events.TransactionReversed,
+		PartitionKey: original.AccountID,
+		Envelope:     data,
+		CreatedAt:    reversal.CreatedAt,
+	})
+	s.logger.WithContext(ctx).LogDatabase("INSERT", "event_outbox", time.Since(start), err)
+	return err
+}
+
+// deliveryStatusFilters maps the delivery statuses to their conditions on the event_outbox table.
+var deliveryStatusFilters = map[string]string{
+	common.DeliveryPending:   "published_at IS NULL AND attempts = 0",
+	common.DeliveryFailing:   "published_at IS NULL AND attempts > 0",
+	common.DeliveryDelivered: "published_at IS NOT NULL",
+}
+
+// ListEventDeliveries lists the events written to the outbox for an account, newest first, with their
+// delivery status, so integrators can check what was generated for the account and whether it reached them.
+// Events of an account are those keyed by it. Pages are addressed by next_page_token.
+func (s *Service) ListEventDeliveries(ctx context.Context, req *pb.ListEventDeliveriesRequest) (*pb.ListEventDeliveriesResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" {
+		return &pb.ListEventDeliveriesResponse{Error: "account_id required"}, nil
+	}
+
+	conditions := []string{"partition_key = $1"}
+	args := []interface{}{req.AccountId}
+	if req.EventType != "" {
+		args = append(args, req.EventType)
+		conditions = append(conditions, fmt.Sprintf("event_type = $%d", len(args)))
+	}
+	if req.Status != "" {
+		condition, ok := deliveryStatusFilters[req.Status]
+		if !ok {
+			return &pb.ListEventDeliveriesResponse{Error: "status must be one of PENDING, FAILING, DELIVERED"}, nil
+		}
+		conditions = append(conditions, condition)
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	filterHash := common.PageFilterHash(req.AccountId, req.EventType, req.Status)
+	if req.PageToken != "" {
+		cursor, err := s.pageTokens.Decode(req.PageToken, filterHash)
+		if err != nil {
+			logger.Warn("Rejected page token for event deliveries: AccountID=%s, Error=%v", req.AccountId, err)
+			return &pb.ListEventDeliveriesResponse{Error: "invalid page token"}, nil
+		}
+		id, err := strconv.ParseInt(cursor.ID, 10, 64)
+		if err != nil {
+			return &pb.ListEventDeliveriesResponse{Error: "invalid page token"}, nil
+		}
+		args = append(args, cursor.CreatedAt, id)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	args = append(args, limit)
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT id, event_id, event_type, created_at, published_at, attempts, last_error
+		FROM event_outbox
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), len(args)), args...)
+	logger.LogDatabase("SELECT", "event_outbox", time.Since(start), err)
+	if err != nil {
+		logger.Error("Event deliveries query failed: %v", err)
+		return &pb.ListEventDeliveriesResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	var accountEvents []*pb.AccountEvent
+	var lastID int64
+	for rows.Next() {
+		var event pb.AccountEvent
+		var publishedAt sql.NullInt64
+		var lastError sql.NullString
+		var attempts int32
+		if err := rows.Scan(&lastID, &event.EventId, &event.EventType, &event.CreatedAt, &publishedAt, &attempts, &lastError); err != nil {
+			logger.Error("Row scan failed: %v", err)
+			return &pb.ListEventDeliveriesResponse{Error: "database error"}, nil
+		}
+
+		delivery := &pb.EventDelivery{
+			Subscriber: common.OutboxSubscriber,
+			Status:     common.DeliveryPending,
+			Attempts:   attempts,
+			LastError:  lastError.String,
+		}
+		switch {
+		case publishedAt.Valid:
+			delivery.Status = common.DeliveryDelivered
+			delivery.DeliveredAt = publishedAt.Int64
+		case attempts > 0:
+			delivery.Status = common.DeliveryFailing
+		}
+		event.Deliveries = []*pb.EventDelivery{delivery}
+		accountEvents = append(accountEvents, &event)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Event deliveries query failed: %v", err)
+		return &pb.ListEventDeliveriesResponse{Error: "database error"}, nil
+	}
+
+	var nextPageToken string
+	if len(accountEvents) == int(limit) {
+		last := accountEvents[len(accountEvents)-1]
+		nextPageToken = s.pageTokens.Encode(common.PageCursor{CreatedAt: last.CreatedAt, ID: strconv.FormatInt(lastID, 10)}, filterHash)
+	}
+
+	return &pb.ListEventDeliveriesResponse{Events: accountEvents, NextPageToken: nextPageToken}, nil
+}
@@ -0,0 +1,316 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultExportWorkers is how many shards of a history export are queried concurrently.
+const DefaultExportWorkers = 4
+
+// exportShardsPerWorker splits an export into more shards than workers, so a busy period of the
+// account's history does not leave the other workers idle.
+const exportShardsPerWorker = 4
+
+// minExportShardSeconds is the smallest time window covered by one export shard.
+const minExportShardSeconds = 60 * 60
+
+// maxExportChunkSize caps the data sent in one ExportTransactionHistoryChunk, well below the gRPC message limit.
+const maxExportChunkSize = 1 << 20
+
+// streamTransactionsBatchSize is the number of transactions StreamTransactions reads per query.
+const streamTransactionsBatchSize = 500
+
+// exportCSVHeader is the first line of a CSV export.
+var exportCSVHeader = []string{"id", "account_id", "operation_type", "amount", "description", "status", "external_id", "tags", "created_at"}
+
+// exportShard is a time window of an export, from inclusive and to exclusive, in Unix seconds.
+type exportShard struct {
+	from int64
+	to   int64
+}
+
+// exportShardResult is the CSV rendering of one shard.
+type exportShardResult struct {
+	data []byte
+	err  error
+}
+
+// SetExportWorkers sets how many shards of a history export are queried concurrently.
+// Values below one are ignored.
+func (s *Service) SetExportWorkers(workers int) {
+	if workers > 0 {
+		s.exportWorkers = workers
+	}
+}
+
+// ExportTransactionHistory streams the transaction history of an account as a CSV file, oldest first.
+// The time range is split into shards of about the same number of transactions, using the account's monthly
+// counts, that are queried concurrently by a bounded number of workers and streamed in order as they complete,
+// so at most one shard per worker is held in memory. Months without transactions are not queried at all.
+// Request errors are reported in the first chunk; a failure part way through is reported in the last one.
+// Returning early cancels the context of the workers, which releases any still waiting to be streamed.
+func (s *Service) ExportTransactionHistory(req *pb.ExportTransactionHistoryRequest, stream pb.TransactionService_ExportTransactionHistoryServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" {
+		return stream.Send(&pb.ExportTransactionHistoryChunk{Error: "account_id required"})
+	}
+	if req.Format != "" && req.Format != "csv" {
+		return stream.Send(&pb.ExportTransactionHistoryChunk{Error: "format must be csv"})
+	}
+
+	to := req.To
+	if to <= 0 {
+		to = time.Now().Unix() + 1
+	}
+	if req.From > to {
+		return stream.Send(&pb.ExportTransactionHistoryChunk{Error: "from must be before to"})
+	}
+
+	start := time.Now()
+	months, err := common.LoadMonthCounts(ctx, s.db, req.AccountId)
+	logger.LogDatabase("SELECT", "transaction_monthly_counts", time.Since(start), err)
+	if err != nil {
+		logger.Error("Export range lookup failed: AccountID=%s, Error=%v", req.AccountId, err)
+		return stream.Send(&pb.ExportTransactionHistoryChunk{Error: "database error"})
+	}
+	from := req.From
+	if from <= 0 {
+		from = to
+		if len(months) > 0 && months[0].Start < to {
+			from = months[0].Start
+		}
+	}
+
+	header, err := renderExportCSV(func(w *csv.Writer) error { return w.Write(exportCSVHeader) })
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&pb.ExportTransactionHistoryChunk{Data: header}); err != nil {
+		return err
+	}
+
+	workers := s.exportWorkers
+	shards := splitExportMonths(months, from, to, workers*exportShardsPerWorker)
+	logger.Info("Exporting transaction history: AccountID=%s, From=%d, To=%d, Shards=%d, Workers=%d",
+		req.AccountId, from, to, len(shards), workers)
+
+	// A shard's worker is held until the shard has been streamed, which bounds both the concurrent
+	// queries and the shards buffered ahead of the one being streamed.
+	group, ctx := common.NewWorkerGroup(ctx, workers, common.FailFast)
+	results := make([]chan exportShardResult, len(shards))
+	streamed := make([]chan struct{}, len(shards))
+	for i := range results {
+		results[i] = make(chan exportShardResult, 1)
+		streamed[i] = make(chan struct{})
+	}
+	go func() {
+		for i, shard := range shards {
+			ok := group.Go(func(ctx context.Context) error {
+				data, err := s.exportShardCSV(ctx, req.AccountId, shard)
+				results[i] <- exportShardResult{data: data, err: err}
+				select {
+				case <-streamed[i]:
+				case <-ctx.Done():
+				}
+				return nil
+			})
+			if !ok {
+				return
+			}
+		}
+	}()
+
+	for i := range shards {
+		result := <-results[i]
+		if result.err != nil {
+			logger.Error("Export shard failed: AccountID=%s, From=%d, To=%d, Error=%v",
+				req.AccountId, shards[i].from, shards[i].to, result.err)
+			return stream.Send(&pb.ExportTransactionHistoryChunk{Error: "database error"})
+		}
+		for data := result.data; len(data) > 0; {
+			n := min(len(data), maxExportChunkSize)
+			if err := stream.Send(&pb.ExportTransactionHistoryChunk{Data: data[:n]}); err != nil {
+				return err
+			}
+			data = data[n:]
+		}
+		close(streamed[i])
+	}
+	return group.Wait()
+}
+
+// StreamTransactions streams the transactions of an account oldest first, one message per transaction, optionally
+// limited to a time range, status and operation type. As the messages are transactions, errors are reported as the
+// status of the stream: INVALID_ARGUMENT for request errors, INTERNAL for a database failure part way through.
+// Transactions are read in batches of streamTransactionsBatchSize, each a short query resuming after the last
+// transaction sent, so a slow consumer does not keep a long-running query open.
+func (s *Service) StreamTransactions(req *pb.StreamTransactionsRequest, stream pb.TransactionService_StreamTransactionsServer) error {
+	ctx := stream.Context()
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" {
+		return status.Error(codes.InvalidArgument, "account_id required")
+	}
+	if msg := validateStreamTransactionsRequest(req); msg != "" {
+		return status.Error(codes.InvalidArgument, msg)
+	}
+
+	reader := newTransactionBatchReader(s.db, req, streamTransactionsBatchSize)
+	logger.Info("Streaming account transactions: AccountID=%s, Filters=%d", req.AccountId, reader.filters)
+
+	streamed := 0
+	for {
+		transactions, err := reader.next(ctx, logger)
+		if common.IsCancellation(err) {
+			logger.Warn("Account transaction stream cancelled: AccountID=%s, Streamed=%d", req.AccountId, streamed)
+			return status.Error(codes.Canceled, "request cancelled")
+		}
+		if err != nil {
+			logger.Error("Account transaction stream failed: AccountID=%s, Streamed=%d, Error=%v", req.AccountId, streamed, err)
+			return status.Error(codes.Internal, "database error")
+		}
+
+		for _, transaction := range transactions {
+			if err := stream.Send(transaction); err != nil {
+				return err
+			}
+		}
+		streamed += len(transactions)
+		if len(transactions) < streamTransactionsBatchSize {
+			break
+		}
+	}
+
+	logger.Info("Account transactions streamed: AccountID=%s, Count=%d", req.AccountId, streamed)
+	return nil
+}
+
+// exportShardCSV renders the transactions of an account created within a shard as CSV rows.
+func (s *Service) exportShardCSV(ctx context.Context, accountID string, shard exportShard) ([]byte, error) {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE account_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at, id
+	`, accountID, shard.from, shard.to)
+	logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return renderExportCSV(func(w *csv.Writer) error {
+		for rows.Next() {
+			transaction, err := scanTransaction(rows)
+			if err != nil {
+				return err
+			}
+			err = w.Write([]string{
+				transaction.ID,
+				transaction.AccountID,
+				transaction.OperationType,
+				transaction.Amount.String(),
+				transaction.Description,
+				transaction.Status,
+				transaction.ExternalID,
+				strings.Join(transaction.Tags, ","),
+				time.Unix(transaction.CreatedAt, 0).UTC().Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// renderExportCSV returns the CSV written by write.
+func renderExportCSV(write func(w *csv.Writer) error) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := write(w); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// splitExportMonths splits the months of [from, to) that hold transactions into about count shards of a similar
+// number of transactions. Consecutive quiet months share a shard and busy ones are split by time with
+// splitExportRange; a month only partly within the range is weighed by its full count.
+func splitExportMonths(months []common.MonthCount, from, to int64, count int) []exportShard {
+	var total int64
+	for _, month := range months {
+		if month.Start < to && month.End() > from {
+			total += month.Count
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+	target := (total + int64(count) - 1) / int64(count)
+
+	var shards []exportShard
+	var current *exportShard
+	var size int64
+	flush := func() {
+		if current != nil {
+			shards = append(shards, *current)
+			current = nil
+		}
+	}
+	for _, month := range months {
+		start, end := max(month.Start, from), min(month.End(), to)
+		if start >= end {
+			continue
+		}
+		if month.Count > target {
+			flush()
+			shards = append(shards, splitExportRange(start, end, int((month.Count+target-1)/target))...)
+			continue
+		}
+		if current != nil && size+month.Count > target {
+			flush()
+		}
+		if current == nil {
+			current = &exportShard{from: start}
+			size = 0
+		}
+		current.to = end
+		size += month.Count
+	}
+	flush()
+	return shards
+}
+
+// splitExportRange splits [from, to) into at most count consecutive shards of equal length,
+// none shorter than minExportShardSeconds except the last.
+func splitExportRange(from, to int64, count int) []exportShard {
+	if from >= to {
+		return nil
+	}
+	size := (to - from + int64(count) - 1) / int64(count)
+	if size < minExportShardSeconds {
+		size = minExportShardSeconds
+	}
+	var shards []exportShard
+	for start := from; start < to; start += size {
+		shards = append(shards, exportShard{from: start, to: min(start+size, to)})
+	}
+	return shards
+}
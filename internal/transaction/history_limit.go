@@ -0,0 +1,179 @@
+package transaction
+
+import (
+	"math"
+	"math/bits"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults of the per-page budgets of transaction history; a page of an account whose transactions are unusually
+// large or slow to read is made smaller so it stays within them.
+const (
+	DefaultHistoryPageMaxBytes   = 256 * 1024
+	DefaultHistoryPageMaxLatency = 250 * time.Millisecond
+)
+
+// Page sizes of transaction history.
+const (
+	defaultHistoryPageLimit = 50
+	maxHistoryPageLimit     = 100
+	minHistoryPageLimit     = 10
+)
+
+// Tuning of the adaptive history page limit.
+const (
+	// historyLimitMinSamples is how many pages of an account are observed before its page size is adapted
+	historyLimitMinSamples = 3
+	// historyLimitQuantile is the quantile of the per-transaction size and latency the budgets are divided by
+	historyLimitQuantile = 0.9
+	// historyHistogramDecayAt halves the counts of a histogram once it holds this many samples, so an account's
+	// recent pages outweigh old ones
+	historyHistogramDecayAt = 64
+	// maxHistoryLimitAccounts bounds the number of accounts tracked
+	maxHistoryLimitAccounts = 10000
+	// historyLimitIdleTTL is how long an account that is not read is remembered
+	historyLimitIdleTTL = time.Hour
+)
+
+// log2Histogram counts samples in power-of-two buckets: bucket i holds values below 2^(i+1).
+type log2Histogram struct {
+	counts [40]uint32
+	total  uint32
+}
+
+func (h *log2Histogram) add(value uint64) {
+	bucket := 0
+	if value > 0 {
+		bucket = min(bits.Len64(value)-1, len(h.counts)-1)
+	}
+	h.counts[bucket]++
+	h.total++
+	if h.total >= historyHistogramDecayAt {
+		h.total = 0
+		for i := range h.counts {
+			h.counts[i] /= 2
+			h.total += h.counts[i]
+		}
+	}
+}
+
+// quantile returns the upper bound of the bucket holding the q quantile, so estimates err on the large side.
+func (h *log2Histogram) quantile(q float64) uint64 {
+	target := uint32(math.Ceil(q * float64(h.total)))
+	var cumulative uint32
+	for i, count := range h.counts {
+		cumulative += count
+		if cumulative >= target && cumulative > 0 {
+			return 1 << (i + 1)
+		}
+	}
+	return 1 << len(h.counts)
+}
+
+// accountHistoryStats holds the distributions of the per-transaction size and read latency of an account's history pages.
+type accountHistoryStats struct {
+	bytesPerTransaction  log2Histogram
+	microsPerTransaction log2Histogram
+	pages                int
+	lastSeen             time.Time
+}
+
+// historyPageLimiter adapts the page size of transaction history per account. It observes the size and latency of
+// every page read and, once it has seen enough pages of an account, caps the account's page size so a page stays
+// within maxBytes and maxLatency at the 90th percentile of the per-transaction size and latency. Accounts with
+// ordinary transactions keep the requested page size; pathological ones get smaller pages, never fewer than
+// minHistoryPageLimit transactions. It is safe for concurrent use.
+type historyPageLimiter struct {
+	maxBytes   int64
+	maxLatency time.Duration
+	now        func() time.Time
+
+	mu       sync.Mutex
+	accounts map[string]*accountHistoryStats
+}
+
+// newHistoryPageLimiter creates a limiter with the given per-page budgets; a zero budget is not enforced.
+func newHistoryPageLimiter(maxBytes int64, maxLatency time.Duration) *historyPageLimiter {
+	return &historyPageLimiter{
+		maxBytes:   maxBytes,
+		maxLatency: maxLatency,
+		now:        time.Now,
+		accounts:   make(map[string]*accountHistoryStats),
+	}
+}
+
+// newHistoryPageLimiterFromEnv creates a limiter with the budgets from the HISTORY_PAGE_MAX_BYTES and
+// HISTORY_PAGE_MAX_LATENCY environment variables, defaulting to DefaultHistoryPageMaxBytes and
+// DefaultHistoryPageMaxLatency. Set both to 0 to always use the requested page size.
+func newHistoryPageLimiterFromEnv() *historyPageLimiter {
+	maxBytes := int64(DefaultHistoryPageMaxBytes)
+	if value, err := strconv.ParseInt(os.Getenv("HISTORY_PAGE_MAX_BYTES"), 10, 64); err == nil && value >= 0 {
+		maxBytes = value
+	}
+	maxLatency := DefaultHistoryPageMaxLatency
+	if value, err := time.ParseDuration(os.Getenv("HISTORY_PAGE_MAX_LATENCY")); err == nil && value >= 0 {
+		maxLatency = value
+	}
+	return newHistoryPageLimiter(maxBytes, maxLatency)
+}
+
+// Limit returns the page size to use for a history page of accountID of at most requested transactions.
+func (l *historyPageLimiter) Limit(accountID string, requested int32) int32 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats, ok := l.accounts[accountID]
+	if !ok || stats.pages < historyLimitMinSamples {
+		return requested
+	}
+
+	allowed := int64(maxHistoryPageLimit)
+	if l.maxBytes > 0 {
+		allowed = min(allowed, l.maxBytes/int64(stats.bytesPerTransaction.quantile(historyLimitQuantile)))
+	}
+	if l.maxLatency > 0 {
+		allowed = min(allowed, l.maxLatency.Microseconds()/int64(stats.microsPerTransaction.quantile(historyLimitQuantile)))
+	}
+	allowed = max(allowed, minHistoryPageLimit)
+	return min(requested, int32(allowed))
+}
+
+// Observe records a history page of accountID that returned transactions transactions, size bytes in total,
+// and took latency to read. Empty pages tell nothing about the account's transactions and are ignored.
+func (l *historyPageLimiter) Observe(accountID string, transactions, size int, latency time.Duration) {
+	if transactions == 0 || (l.maxBytes <= 0 && l.maxLatency <= 0) {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	stats, ok := l.accounts[accountID]
+	if !ok {
+		if len(l.accounts) >= maxHistoryLimitAccounts {
+			l.evictIdle(now)
+			if len(l.accounts) >= maxHistoryLimitAccounts {
+				return
+			}
+		}
+		stats = &accountHistoryStats{}
+		l.accounts[accountID] = stats
+	}
+	stats.bytesPerTransaction.add(uint64(size / transactions))
+	stats.microsPerTransaction.add(uint64(latency.Microseconds() / int64(transactions)))
+	stats.pages++
+	stats.lastSeen = now
+}
+
+// evictIdle forgets the accounts that have not been read for historyLimitIdleTTL.
+func (l *historyPageLimiter) evictIdle(now time.Time) {
+	for accountID, stats := range l.accounts {
+		if now.Sub(stats.lastSeen) >= historyLimitIdleTTL {
+			delete(l.accounts, accountID)
+		}
+	}
+}
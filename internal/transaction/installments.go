@@ -0,0 +1,452 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// installmentOperationType is the operation type of purchases that may be paid in installments, and of the
+// debits of their later installments.
+const installmentOperationType = "INSTALLMENT_PURCHASE"
+
+// Statuses of installments.
+const (
+	installmentPending   = "PENDING"
+	installmentInReview  = "IN_REVIEW"
+	installmentPaid      = "PAID"
+	installmentOverdue   = "OVERDUE"
+	installmentCancelled = "CANCELLED"
+)
+
+// Limits on installments; maxInstallmentErrorLength matches installments.last_error.
+const (
+	maxInstallmentCount       = 48
+	maxInstallmentsPerPass    = 100
+	maxInstallmentErrorLength = 200
+)
+
+// installmentRetryDelay is how long after being declined an overdue installment is attempted again.
+const installmentRetryDelay = secondsPerDay
+
+// dueInstallment is an installment due to be debited, as read by DebitDueInstallments.
+type dueInstallment struct {
+	purchaseID     string
+	number         int32
+	count          int32
+	amount         common.Cents
+	nextAttemptAt  int64
+	accountID      string
+	description    string
+	purchaseStatus string
+	tenantID       string
+}
+
+// validateInstallmentCount checks the installment count of a request, which only installment purchases may set
+// and which must leave every installment at least a cent. Returns an error message, or an empty string if the
+// count is valid.
+func validateInstallmentCount(req *pb.CreateTransactionRequest) string {
+	if req.InstallmentCount == 0 {
+		return ""
+	}
+	if req.OperationType != installmentOperationType {
+		return "installment_count only allowed for INSTALLMENT_PURCHASE"
+	}
+	if req.InstallmentCount < 1 || req.InstallmentCount > maxInstallmentCount {
+		return fmt.Sprintf("installment_count must be between 1 and %d", maxInstallmentCount)
+	}
+	if req.AmountCents < int64(req.InstallmentCount) {
+		return "amount too small for installment_count"
+	}
+	return ""
+}
+
+// installmentAmounts splits total into count installments of whole cents; the first carries the remainder.
+func installmentAmounts(total common.Cents, count int32) []common.Cents {
+	if count <= 1 {
+		return []common.Cents{total}
+	}
+	amounts := make([]common.Cents, count)
+	for i := range amounts {
+		amounts[i] = total / common.Cents(count)
+	}
+	amounts[0] += total % common.Cents(count)
+	return amounts
+}
+
+// firstInstallmentAmount is the amount a request debits now: the whole amount, or its first installment.
+func firstInstallmentAmount(req *pb.CreateTransactionRequest) common.Cents {
+	return installmentAmounts(common.Cents(req.AmountCents), req.InstallmentCount)[0]
+}
+
+// firstInstallmentStatus derives the status of the first installment of a purchase, which the purchase debits,
+// from the purchase's status.
+func firstInstallmentStatus(purchaseStatus string) string {
+	switch purchaseStatus {
+	case "COMPLETED":
+		return installmentPaid
+	case "UNDER_REVIEW":
+		return installmentInReview
+	case "PENDING":
+		return installmentPending
+	}
+	return installmentCancelled
+}
+
+// firstInstallment returns the first installment of a purchase, which is the purchase's own debit.
+func firstInstallment(purchaseID string, amount common.Cents, purchaseStatus string, createdAt int64) *pb.Installment {
+	installment := &pb.Installment{
+		Number:      1,
+		AmountCents: int64(amount),
+		DueAt:       createdAt,
+		Status:      firstInstallmentStatus(purchaseStatus),
+	}
+	if installment.Status == installmentPaid || installment.Status == installmentInReview {
+		installment.TransactionId = purchaseID
+	}
+	if installment.Status == installmentPaid {
+		installment.PaidAt = createdAt
+	}
+	return installment
+}
+
+// newInstallmentPlan returns the plan of a purchase just created with the installment amounts given: installments
+// are due monthly from the purchase, on the same day of the month, or the last day of shorter months.
+func newInstallmentPlan(purchase *common.Transaction, amounts []common.Cents) *pb.InstallmentPlan {
+	plan := &pb.InstallmentPlan{
+		PurchaseId:       purchase.ID,
+		InstallmentCount: int32(len(amounts)),
+		Installments:     []*pb.Installment{firstInstallment(purchase.ID, amounts[0], purchase.Status, purchase.CreatedAt)},
+	}
+	for i := 1; i < len(amounts); i++ {
+		plan.Installments = append(plan.Installments, &pb.Installment{
+			Number:      int32(i + 1),
+			AmountCents: int64(amounts[i]),
+			DueAt:       recurringRunAt(purchase.CreatedAt, recurringMonthly, int32(i)),
+			Status:      installmentPending,
+		})
+	}
+	summarizeInstallmentPlan(plan)
+	return plan
+}
+
+// summarizeInstallmentPlan sets the totals of a plan from its installments.
+func summarizeInstallmentPlan(plan *pb.InstallmentPlan) {
+	plan.TotalAmountCents, plan.PaidCount, plan.RemainingCents = 0, 0, 0
+	for _, installment := range plan.Installments {
+		plan.TotalAmountCents += installment.AmountCents
+		switch installment.Status {
+		case installmentPaid:
+			plan.PaidCount++
+		case installmentPending, installmentInReview, installmentOverdue:
+			plan.RemainingCents += installment.AmountCents
+		}
+	}
+}
+
+// insertInstallments records the installments of a purchase after the first, due monthly from the purchase,
+// within the transaction writing it. Each keeps the purchase's tenant, on whose behalf it is debited.
+func (s *Service) insertInstallments(ctx context.Context, tx *sql.Tx, purchase *common.Transaction, amounts []common.Cents) error {
+	if len(amounts) <= 1 {
+		return nil
+	}
+	logger := s.logger.WithContext(ctx)
+
+	var tenantID interface{}
+	if tenant := common.TenantIDFromContext(ctx); tenant != "" {
+		tenantID = tenant
+	}
+	args := make([]interface{}, 0, (len(amounts)-1)*6)
+	values := make([]string, 0, len(amounts)-1)
+	for i := 1; i < len(amounts); i++ {
+		dueAt := recurringRunAt(purchase.CreatedAt, recurringMonthly, int32(i))
+		values = append(values, fmt.Sprintf("(%s)", placeholders(len(args)+1, 6)))
+		args = append(args, purchase.ID, i+1, amounts[i], dueAt, dueAt, tenantID)
+	}
+
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO installments (purchase_id, number, amount, due_at, next_attempt_at, tenant_id) VALUES %s
+	`, strings.Join(values, ", ")), args...)
+	logger.LogDatabase("INSERT", "installments", time.Since(start), err)
+	return err
+}
+
+// getInstallmentPlan returns the plan of the installment purchase a transaction is, or debits an installment of,
+// or nil if it is neither.
+func (s *Service) getInstallmentPlan(ctx context.Context, transactionID string) (*pb.InstallmentPlan, error) {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.id, p.amount, p.status, p.created_at, i.number, i.amount, i.due_at, i.status,
+			COALESCE(i.transaction_id, ''), COALESCE(i.paid_at, 0), i.attempts, COALESCE(i.last_error, '')
+		FROM installments i
+		JOIN transactions p ON p.id = i.purchase_id
+		WHERE i.purchase_id = COALESCE((SELECT purchase_id FROM installments WHERE transaction_id = $1), $1)
+		ORDER BY i.number
+	`, transactionID)
+	logger.LogDatabase("SELECT", "installments", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plan *pb.InstallmentPlan
+	for rows.Next() {
+		var purchaseAmount, amount common.Cents
+		var purchaseID, purchaseStatus string
+		var purchaseCreatedAt int64
+		installment := &pb.Installment{}
+		if err := rows.Scan(&purchaseID, &purchaseAmount, &purchaseStatus, &purchaseCreatedAt, &installment.Number, &amount,
+			&installment.DueAt, &installment.Status, &installment.TransactionId, &installment.PaidAt, &installment.Attempts,
+			&installment.LastError); err != nil {
+			return nil, err
+		}
+		installment.AmountCents = int64(amount)
+		if plan == nil {
+			first := firstInstallment(purchaseID, -purchaseAmount, purchaseStatus, purchaseCreatedAt)
+			plan = &pb.InstallmentPlan{PurchaseId: purchaseID, Installments: []*pb.Installment{first}}
+		}
+		plan.Installments = append(plan.Installments, installment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if plan != nil {
+		plan.InstallmentCount = int32(len(plan.Installments))
+		summarizeInstallmentPlan(plan)
+	}
+	return plan, nil
+}
+
+// DebitDueInstallments debits the installments due by now, oldest first, and returns the number debited. Each is
+// debited by an INSTALLMENT_PURCHASE transaction of its own, through the same checks as CreateTransaction. An
+// installment declined for a lasting reason, e.g. insufficient balance, becomes OVERDUE and is attempted again a
+// day later; one that failed for a passing reason, such as a database error, is retried at the next call.
+// Installments wait while their purchase is held for review, and are cancelled once it is declined or reversed.
+// Installments of purchases held for review are left out of the query, so they cannot fill every pass.
+func (s *Service) DebitDueInstallments(ctx context.Context, now int64) (int, error) {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT i.purchase_id, i.number, (SELECT COUNT(*) + 1 FROM installments c WHERE c.purchase_id = i.purchase_id),
+			i.amount, i.next_attempt_at, p.account_id, COALESCE(p.description, ''), p.status, COALESCE(i.tenant_id, '')
+		FROM installments i
+		JOIN transactions p ON p.id = i.purchase_id
+		WHERE i.status IN ($1, $2) AND i.next_attempt_at <= $3 AND p.status NOT IN ('PENDING', 'UNDER_REVIEW')
+		ORDER BY i.next_attempt_at
+		LIMIT $4
+	`, installmentPending, installmentOverdue, now, maxInstallmentsPerPass)
+	logger.LogDatabase("SELECT", "installments", time.Since(start), err)
+	if err != nil {
+		return 0, err
+	}
+	var due []dueInstallment
+	for rows.Next() {
+		var installment dueInstallment
+		if err := rows.Scan(&installment.purchaseID, &installment.number, &installment.count, &installment.amount,
+			&installment.nextAttemptAt, &installment.accountID, &installment.description, &installment.purchaseStatus,
+			&installment.tenantID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, installment)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	debited := 0
+	for _, installment := range due {
+		if firstInstallmentStatus(installment.purchaseStatus) == installmentCancelled {
+			if err := s.cancelInstallments(ctx, s.db, installment.purchaseID); err != nil {
+				return debited, err
+			}
+			continue
+		}
+		ok, err := s.debitInstallment(ctx, &installment)
+		if err != nil {
+			return debited, err
+		}
+		if ok {
+			debited++
+		}
+	}
+	return debited, nil
+}
+
+// debitInstallment makes the debit of a due installment, on behalf of the purchase's tenant, and reports whether
+// it was made.
+func (s *Service) debitInstallment(ctx context.Context, installment *dueInstallment) (bool, error) {
+	logger := s.logger.WithContext(ctx)
+
+	description := fmt.Sprintf("Installment %d/%d", installment.number, installment.count)
+	if installment.description != "" {
+		description += ": " + installment.description
+	}
+	if len(description) > maxDescriptionLength {
+		description = description[:maxDescriptionLength]
+	}
+	debitCtx := ctx
+	if installment.tenantID != "" {
+		debitCtx = metadata.NewIncomingContext(ctx, metadata.Pairs(common.TenantIDMetadataKey, installment.tenantID))
+	}
+	resp, err := s.createTransaction(debitCtx, &pb.CreateTransactionRequest{
+		AccountId:     installment.accountID,
+		OperationType: installmentOperationType,
+		AmountCents:   int64(installment.amount),
+		Description:   description,
+	}, nil, installment)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case resp.Error == "":
+		logger.Info("Installment debited: PurchaseID=%s, Number=%d, TransactionID=%s", installment.purchaseID, installment.number, resp.Transaction.Id)
+		return true, nil
+	case resp.Error == errRunNotDue.Error():
+		return false, nil
+	case retryableRunErrors[resp.Error]:
+		logger.Warn("Installment debit failed, will retry: PurchaseID=%s, Number=%d, Error=%s", installment.purchaseID, installment.number, resp.Error)
+		return false, nil
+	}
+	return false, s.markInstallmentOverdue(ctx, installment, resp.Error)
+}
+
+// record marks the installment paid by the transaction debiting it or, if the debit was held for review, in review
+// until the debit is approved or declined (see settleInstallmentReview). It returns errRunNotDue if the installment
+// was paid or cancelled since it was found due.
+func (installment *dueInstallment) record(ctx context.Context, tx *sql.Tx, logger *common.Logger, transactionID string, held bool) error {
+	status := installmentPaid
+	var paidAt interface{} = common.GetCurrentTimestamp()
+	if held {
+		status, paidAt = installmentInReview, nil
+	}
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE installments SET status = $3, transaction_id = $4, paid_at = $5, last_error = NULL
+		WHERE purchase_id = $1 AND number = $2 AND status IN ($6, $7) AND next_attempt_at = $8
+	`, installment.purchaseID, installment.number, status, transactionID, paidAt,
+		installmentPending, installmentOverdue, installment.nextAttemptAt)
+	logger.LogDatabase("UPDATE", "installments", time.Since(start), err)
+	if err != nil {
+		return err
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return errRunNotDue
+	}
+	return nil
+}
+
+// markInstallmentOverdue records a declined attempt to debit an installment, which is attempted again after
+// installmentRetryDelay.
+func (s *Service) markInstallmentOverdue(ctx context.Context, installment *dueInstallment, failure string) error {
+	logger := s.logger.WithContext(ctx)
+
+	if len(failure) > maxInstallmentErrorLength {
+		failure = failure[:maxInstallmentErrorLength]
+	}
+	now := common.GetCurrentTimestamp()
+	start := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE installments SET status = $3, attempts = attempts + 1, last_error = $4, next_attempt_at = $5
+		WHERE purchase_id = $1 AND number = $2 AND status IN ($6, $3) AND next_attempt_at = $7
+	`, installment.purchaseID, installment.number, installmentOverdue, failure, now+installmentRetryDelay,
+		installmentPending, installment.nextAttemptAt)
+	logger.LogDatabase("UPDATE", "installments", time.Since(start), err)
+	if err != nil {
+		return err
+	}
+	logger.Warn("Installment overdue: PurchaseID=%s, Number=%d, Error=%s", installment.purchaseID, installment.number, failure)
+	return nil
+}
+
+// settleInstallmentReview applies the review of a held installment debit, within the transaction applying it: an
+// approved debit pays its installment, while a declined one leaves the installment OVERDUE, to be attempted again
+// after installmentRetryDelay like other declined debits.
+func (s *Service) settleInstallmentReview(ctx context.Context, tx *sql.Tx, transactionID string, approved bool) error {
+	logger := s.logger.WithContext(ctx)
+
+	now := common.GetCurrentTimestamp()
+	start := time.Now()
+	var err error
+	if approved {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE installments SET status = $2, paid_at = $3 WHERE transaction_id = $1 AND status = $4
+		`, transactionID, installmentPaid, now, installmentInReview)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE installments
+			SET status = $2, transaction_id = NULL, attempts = attempts + 1, last_error = $3, next_attempt_at = $4
+			WHERE transaction_id = $1 AND status = $5
+		`, transactionID, installmentOverdue, errDeclinedInReview, now+installmentRetryDelay, installmentInReview)
+	}
+	logger.LogDatabase("UPDATE", "installments", time.Since(start), err)
+	return err
+}
+
+// installmentExecer is implemented by *sql.DB and *sql.Tx.
+type installmentExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// cancelInstallments cancels the unpaid installments of a purchase that was declined or reversed.
+func (s *Service) cancelInstallments(ctx context.Context, db installmentExecer, purchaseID string) error {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	result, err := db.ExecContext(ctx, `
+		UPDATE installments SET status = $2 WHERE purchase_id = $1 AND status IN ($3, $4)
+	`, purchaseID, installmentCancelled, installmentPending, installmentOverdue)
+	logger.LogDatabase("UPDATE", "installments", time.Since(start), err)
+	if err != nil {
+		return err
+	}
+	if cancelled, err := result.RowsAffected(); err == nil && cancelled > 0 {
+		logger.Info("Installments cancelled: PurchaseID=%s, Count=%d", purchaseID, cancelled)
+	}
+	return nil
+}
+
+// countPaidInstallments returns the number of installments of a purchase paid by a debit that was not reversed,
+// or whose debit is held for review, within the transaction reversing the purchase.
+func (s *Service) countPaidInstallments(ctx context.Context, tx *sql.Tx, purchaseID string) (int, error) {
+	logger := s.logger.WithContext(ctx)
+
+	var paid int
+	start := time.Now()
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM installments i
+		JOIN transactions d ON d.id = i.transaction_id
+		WHERE i.purchase_id = $1 AND i.status IN ($2, $3) AND d.status IN ('COMPLETED', 'UNDER_REVIEW')
+	`, purchaseID, installmentPaid, installmentInReview).Scan(&paid)
+	logger.LogDatabase("SELECT", "installments", time.Since(start), err)
+	return paid, err
+}
+
+// RunInstallments debits the installments that are due every interval until ctx is done.
+func (s *Service) RunInstallments(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DebitDueInstallments(ctx, common.GetCurrentTimestamp()); err != nil {
+				s.logger.Error("Installment debits failed: %v", err)
+			}
+		}
+	}
+}
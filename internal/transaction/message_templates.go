@@ -0,0 +1,434 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	"github.com/YASHIRAI/pismo-task/proto/events"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// messageTemplateSamples holds a sample payload of each event type the service renders messages for; templates
+// are checked against it before they are stored, and previews render it when not given a payload.
+var messageTemplateSamples = map[string]proto.Message{
+	events.TransactionCreated: &events.TransactionCreatedV1{
+		TransactionId: "7f8d2c4e-5b1a-4c3d-9e8f-0a1b2c3d4e5f",
+		AccountId:     "1c2d3e4f-5a6b-4c7d-8e9f-0a1b2c3d4e5f",
+		OperationType: "CASH_PURCHASE",
+		Amount:        -42.5,
+		Description:   "Coffee shop",
+		Status:        "COMPLETED",
+		ExternalId:    "NET-000123",
+		CreatedAt:     1767225600,
+	},
+	events.TransactionReversed: &events.TransactionReversedV1{
+		TransactionId:         "7f8d2c4e-5b1a-4c3d-9e8f-0a1b2c3d4e5f",
+		AccountId:             "1c2d3e4f-5a6b-4c7d-8e9f-0a1b2c3d4e5f",
+		Amount:                42.5,
+		Reason:                "Merchant refund",
+		ReversedAt:            1767312000,
+		ReversalTransactionId: "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d",
+	},
+	events.BudgetThresholdCrossed: &events.BudgetThresholdCrossedV1{
+		AccountId:     "1c2d3e4f-5a6b-4c7d-8e9f-0a1b2c3d4e5f",
+		Category:      "groceries",
+		Month:         "2026-01",
+		Threshold:     80,
+		MonthlyLimit:  500,
+		Spent:         412.3,
+		TransactionId: "7f8d2c4e-5b1a-4c3d-9e8f-0a1b2c3d4e5f",
+		CrossedAt:     1767225600,
+	},
+}
+
+// messageTemplateErrors are the template store errors reported to the caller as is.
+var messageTemplateErrors = []error{
+	common.ErrInvalidTenantID,
+	common.ErrMessageTemplateNotFound,
+}
+
+// errNoMessageTemplate is the error of a preview or test send of a template that is not set.
+const errNoMessageTemplate = "no message template for event type and channel"
+
+// MessageTemplates returns the store of the message templates, for the runtime config to update the platform's.
+func (s *Service) MessageTemplates() *common.MessageTemplateStore {
+	return s.messageTemplates
+}
+
+// eventVariables returns the variables messages are rendered with for an event: the fields of its payload, by
+// their names in the event schema, and the event_id, event_type, tenant_id and occurred_at of its envelope.
+func eventVariables(envelope *events.EventEnvelope, payload proto.Message) map[string]interface{} {
+	vars := messageVariables(payload.ProtoReflect())
+	vars["event_id"] = envelope.EventId
+	vars["event_type"] = envelope.EventType
+	vars["tenant_id"] = envelope.TenantId
+	vars["occurred_at"] = envelope.OccurredAt
+	return vars
+}
+
+// messageVariables converts the fields of a message, set or not, to template variables. Integers become int64
+// and enums their names, so templates can pass them to the date function or compare them to strings.
+func messageVariables(message protoreflect.Message) map[string]interface{} {
+	vars := make(map[string]interface{})
+	fields := message.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		value := message.Get(field)
+		if field.IsList() {
+			list := value.List()
+			items := make([]interface{}, list.Len())
+			for j := range items {
+				items[j] = messageVariable(field, list.Get(j))
+			}
+			vars[string(field.Name())] = items
+			continue
+		}
+		vars[string(field.Name())] = messageVariable(field, value)
+	}
+	return vars
+}
+
+// messageVariable converts one value of a field to a template variable.
+func messageVariable(field protoreflect.FieldDescriptor, value protoreflect.Value) interface{} {
+	switch field.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return value.Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64(value.Uint())
+	case protoreflect.EnumKind:
+		if enum := field.Enum().Values().ByNumber(value.Enum()); enum != nil {
+			return string(enum.Name())
+		}
+		return int64(value.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageVariables(value.Message())
+	}
+	return value.Interface()
+}
+
+// renderEventMessages renders the messages of an event from the templates of its tenant into its envelope. A
+// template that fails to render for the event is left out, and so are all of them if they cannot be loaded;
+// neither fails the change the event describes.
+func (s *Service) renderEventMessages(ctx context.Context, envelope *events.EventEnvelope, payload proto.Message) {
+	logger := s.logger.WithContext(ctx)
+
+	templates, err := s.messageTemplates.ForEvent(ctx, envelope.TenantId, envelope.EventType)
+	if err != nil {
+		logger.Error("Message templates lookup failed, event published without messages: EventID=%s, TenantID=%s, Error=%v",
+			envelope.EventId, envelope.TenantId, err)
+		return
+	}
+	if len(templates) == 0 {
+		return
+	}
+	vars := eventVariables(envelope, payload)
+	for _, t := range templates {
+		subject, body, err := t.Render(vars)
+		if err != nil {
+			logger.Warn("Message template failed to render: EventID=%s, EventType=%s, Channel=%s, Source=%s, Error=%v",
+				envelope.EventId, envelope.EventType, t.Channel, t.Source(), err)
+			continue
+		}
+		envelope.Messages = append(envelope.Messages, &events.Message{Channel: t.Channel, Subject: subject, Body: body})
+	}
+}
+
+// ListMessageTemplates lists the message templates in effect for a tenant: its own, else the platform's.
+// Only admins may list templates.
+func (s *Service) ListMessageTemplates(ctx context.Context, req *pb.ListMessageTemplatesRequest) (*pb.ListMessageTemplatesResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected message template listing: TenantID=%s, caller is not an admin operator", req.TenantId)
+		return &pb.ListMessageTemplatesResponse{Error: "permission denied"}, nil
+	}
+
+	start := time.Now()
+	templates, err := s.messageTemplates.List(ctx, req.TenantId)
+	logger.LogDatabase("SELECT", "message_templates", time.Since(start), err)
+	if err != nil {
+		return &pb.ListMessageTemplatesResponse{Error: s.messageTemplateError(ctx, "listing", req.TenantId, err)}, nil
+	}
+
+	pbTemplates := make([]*pb.MessageTemplate, len(templates))
+	for i, t := range templates {
+		pbTemplates[i] = ConvertMessageTemplateToProto(t)
+	}
+	return &pb.ListMessageTemplatesResponse{Templates: pbTemplates}, nil
+}
+
+// PutMessageTemplate sets a tenant's template of the messages of an event type on a channel. The template must
+// render for a sample event of the type, so a misspelled variable is refused rather than leaving messages out.
+// Only admin operators may set templates.
+func (s *Service) PutMessageTemplate(ctx context.Context, req *pb.PutMessageTemplateRequest) (*pb.MessageTemplateResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected message template change: TenantID=%s, EventType=%s, Channel=%s, Operator=%q",
+			req.TenantId, req.EventType, req.Channel, operator)
+		return &pb.MessageTemplateResponse{Error: "permission denied"}, nil
+	}
+	if message := validateMessageTemplateTarget(req.TenantId, req.EventType, req.Channel); message != "" {
+		return &pb.MessageTemplateResponse{Error: message}, nil
+	}
+
+	t := &common.MessageTemplate{
+		TenantID:  req.TenantId,
+		EventType: req.EventType,
+		Channel:   req.Channel,
+		Subject:   req.Subject,
+		Body:      req.Body,
+		UpdatedBy: operator,
+		UpdatedAt: common.GetCurrentTimestamp(),
+	}
+	if _, err := renderSampleMessage(t, req.EventType, ""); err != nil {
+		return &pb.MessageTemplateResponse{Error: err.Error()}, nil
+	}
+
+	start := time.Now()
+	err := s.messageTemplates.Put(ctx, t)
+	logger.LogDatabase("UPSERT", "message_templates", time.Since(start), err)
+	if err != nil {
+		return &pb.MessageTemplateResponse{Error: s.messageTemplateError(ctx, "change", req.TenantId, err)}, nil
+	}
+
+	logger.Info("Message template set: TenantID=%s, EventType=%s, Channel=%s, Operator=%s", req.TenantId, req.EventType, req.Channel, operator)
+	return &pb.MessageTemplateResponse{Template: ConvertMessageTemplateToProto(t)}, nil
+}
+
+// DeleteMessageTemplate removes a tenant's template and returns the platform's, which applies again, if any.
+// Only admin operators may remove templates.
+func (s *Service) DeleteMessageTemplate(ctx context.Context, req *pb.DeleteMessageTemplateRequest) (*pb.MessageTemplateResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected message template removal: TenantID=%s, EventType=%s, Channel=%s, Operator=%q",
+			req.TenantId, req.EventType, req.Channel, operator)
+		return &pb.MessageTemplateResponse{Error: "permission denied"}, nil
+	}
+	if message := validateMessageTemplateTarget(req.TenantId, req.EventType, req.Channel); message != "" {
+		return &pb.MessageTemplateResponse{Error: message}, nil
+	}
+
+	start := time.Now()
+	err := s.messageTemplates.Delete(ctx, req.TenantId, req.EventType, req.Channel)
+	logger.LogDatabase("DELETE", "message_templates", time.Since(start), err)
+	if err != nil {
+		return &pb.MessageTemplateResponse{Error: s.messageTemplateError(ctx, "removal", req.TenantId, err)}, nil
+	}
+	logger.Info("Message template removed: TenantID=%s, EventType=%s, Channel=%s, Operator=%s", req.TenantId, req.EventType, req.Channel, operator)
+
+	platform, err := s.messageTemplates.Resolve(ctx, "", req.EventType, req.Channel)
+	if err != nil || platform == nil {
+		return &pb.MessageTemplateResponse{}, nil
+	}
+	return &pb.MessageTemplateResponse{Template: ConvertMessageTemplateToProto(platform)}, nil
+}
+
+// PreviewMessageTemplate renders a template for a tenant without storing or sending anything: the draft in the
+// request if it has a body, else the template in effect, with the payload given or a sample event.
+// Only admins may preview templates.
+func (s *Service) PreviewMessageTemplate(ctx context.Context, req *pb.PreviewMessageTemplateRequest) (*pb.PreviewMessageTemplateResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected message template preview: TenantID=%s, caller is not an admin operator", req.TenantId)
+		return &pb.PreviewMessageTemplateResponse{Error: "permission denied"}, nil
+	}
+	if message := validateMessageTemplateTarget(req.TenantId, req.EventType, req.Channel); message != "" {
+		return &pb.PreviewMessageTemplateResponse{Error: message}, nil
+	}
+
+	t, message := s.messageTemplateToRender(ctx, req.TenantId, req.EventType, req.Channel, req.Subject, req.Body)
+	if message != "" {
+		return &pb.PreviewMessageTemplateResponse{Error: message}, nil
+	}
+	rendered, err := renderSampleMessage(t, req.EventType, req.PayloadJson)
+	if err != nil {
+		return &pb.PreviewMessageTemplateResponse{Error: err.Error()}, nil
+	}
+	variables, err := json.Marshal(rendered.vars)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PreviewMessageTemplateResponse{Message: rendered.message, VariablesJson: string(variables)}, nil
+}
+
+// TestSendMessageTemplate renders a template like PreviewMessageTemplate and publishes a message.test_send event
+// through the outbox, for notification consumers to deliver the message to the recipient given. It requires the
+// outbox. Only admin operators may send test messages.
+func (s *Service) TestSendMessageTemplate(ctx context.Context, req *pb.TestSendMessageTemplateRequest) (*pb.TestSendMessageTemplateResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected message template test send: TenantID=%s, EventType=%s, Channel=%s, Operator=%q",
+			req.TenantId, req.EventType, req.Channel, operator)
+		return &pb.TestSendMessageTemplateResponse{Error: "permission denied"}, nil
+	}
+	if message := validateMessageTemplateTarget(req.TenantId, req.EventType, req.Channel); message != "" {
+		return &pb.TestSendMessageTemplateResponse{Error: message}, nil
+	}
+	if message := validateTestRecipient(req.Channel, req.Recipient); message != "" {
+		return &pb.TestSendMessageTemplateResponse{Error: message}, nil
+	}
+	if !s.eventOutbox {
+		return &pb.TestSendMessageTemplateResponse{Error: "event outbox disabled"}, nil
+	}
+
+	t, message := s.messageTemplateToRender(ctx, req.TenantId, req.EventType, req.Channel, req.Subject, req.Body)
+	if message != "" {
+		return &pb.TestSendMessageTemplateResponse{Error: message}, nil
+	}
+	rendered, err := renderSampleMessage(t, req.EventType, req.PayloadJson)
+	if err != nil {
+		return &pb.TestSendMessageTemplateResponse{Error: err.Error()}, nil
+	}
+
+	eventID := uuid.New().String()
+	now := common.GetCurrentTimestamp()
+	envelope, err := events.NewEnvelope(eventID, events.MessageTestSend, now, req.TenantId, req.TenantId, &events.MessageTestSendV1{
+		TemplateEventType: req.EventType,
+		Channel:           req.Channel,
+		Recipient:         req.Recipient,
+		Subject:           rendered.message.Subject,
+		Body:              rendered.message.Body,
+		RequestedBy:       operator,
+		RequestedAt:       now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event envelope: %w", err)
+	}
+
+	start := time.Now()
+	err = common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		return common.EnqueueEvent(ctx, tx, common.OutboxEvent{
+			EventID:      eventID,
+			EventType:    events.MessageTestSend,
+			PartitionKey: req.TenantId,
+			Envelope:     data,
+			CreatedAt:    now,
+		})
+	})
+	logger.LogDatabase("INSERT", "event_outbox", time.Since(start), err)
+	if err != nil {
+		logger.Error("Message template test send failed: TenantID=%s, EventType=%s, Channel=%s, Error=%v", req.TenantId, req.EventType, req.Channel, err)
+		return &pb.TestSendMessageTemplateResponse{Error: "database error"}, nil
+	}
+
+	logger.Info("Message template test send queued: TenantID=%s, EventType=%s, Channel=%s, EventID=%s, Operator=%s",
+		req.TenantId, req.EventType, req.Channel, eventID, operator)
+	return &pb.TestSendMessageTemplateResponse{Message: rendered.message, EventId: eventID}, nil
+}
+
+// validateMessageTemplateTarget checks the tenant, event type and channel a template request is for. Returns an
+// error message, or an empty string if they are valid.
+func validateMessageTemplateTarget(tenantID, eventType, channel string) string {
+	if !common.ValidTenantID(tenantID) {
+		return common.ErrInvalidTenantID.Error()
+	}
+	if _, ok := messageTemplateSamples[eventType]; !ok {
+		return "unknown event type"
+	}
+	if channel != common.MessageChannelEmail && channel != common.MessageChannelWebhook {
+		return "channel must be EMAIL or WEBHOOK"
+	}
+	return ""
+}
+
+// validateTestRecipient checks the recipient of a test send: an email address for EMAIL, an https URL for
+// WEBHOOK. Returns an error message, or an empty string if it is valid.
+func validateTestRecipient(channel, recipient string) string {
+	if recipient == "" {
+		return "recipient required"
+	}
+	if channel == common.MessageChannelEmail {
+		if address, err := mail.ParseAddress(recipient); err != nil || address.Address != recipient {
+			return "recipient must be an email address"
+		}
+		return ""
+	}
+	if parsed, err := url.Parse(recipient); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return "recipient must be an https URL"
+	}
+	return ""
+}
+
+// messageTemplateToRender returns the draft given if it has a body, else the template in effect for the tenant.
+// Returns an error message if there is neither.
+func (s *Service) messageTemplateToRender(ctx context.Context, tenantID, eventType, channel, subject, body string) (*common.MessageTemplate, string) {
+	if body != "" {
+		return &common.MessageTemplate{TenantID: tenantID, EventType: eventType, Channel: channel, Subject: subject, Body: body}, ""
+	}
+	t, err := s.messageTemplates.Resolve(ctx, tenantID, eventType, channel)
+	if err != nil {
+		return nil, s.messageTemplateError(ctx, "lookup", tenantID, err)
+	}
+	if t == nil {
+		return nil, errNoMessageTemplate
+	}
+	return t, ""
+}
+
+// sampleMessage is a message rendered for a sample event, with the variables it was rendered with.
+type sampleMessage struct {
+	message *pb.RenderedMessage
+	vars    map[string]interface{}
+}
+
+// renderSampleMessage renders a template for an event of its type: the payload given as JSON, with the field
+// names of the event schema, or the sample of the event type.
+func renderSampleMessage(t *common.MessageTemplate, eventType, payloadJSON string) (*sampleMessage, error) {
+	payload := messageTemplateSamples[eventType]
+	if payloadJSON != "" {
+		schema, _ := events.Latest(eventType)
+		payload = schema.Message.New().Interface()
+		if err := protojson.Unmarshal([]byte(payloadJSON), payload); err != nil {
+			return nil, errors.New("invalid payload_json")
+		}
+	}
+	envelope, err := events.NewEnvelope(uuid.New().String(), eventType, common.GetCurrentTimestamp(), t.TenantID, "", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := eventVariables(envelope, payload)
+	subject, body, err := t.Render(vars)
+	if err != nil {
+		return nil, err
+	}
+	return &sampleMessage{message: &pb.RenderedMessage{Channel: t.Channel, Subject: subject, Body: body}, vars: vars}, nil
+}
+
+// messageTemplateError returns the message reported for a failed template operation, logging unexpected failures.
+func (s *Service) messageTemplateError(ctx context.Context, operation, tenantID string, err error) string {
+	for _, known := range messageTemplateErrors {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	if common.IsCancellation(err) {
+		return "request cancelled"
+	}
+	s.logger.WithContext(ctx).Error("Message template %s failed: TenantID=%s, Error=%v", operation, tenantID, err)
+	return "database error"
+}
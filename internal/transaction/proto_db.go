@@ -9,13 +9,23 @@ import (
 // This function maps all fields from the common.Transaction to the corresponding protobuf fields.
 func ConvertTransactionToProto(dbTransaction *common.Transaction) *pbTransaction.Transaction {
 	return &pbTransaction.Transaction{
-		Id:            dbTransaction.ID,
-		AccountId:     dbTransaction.AccountID,
-		OperationType: dbTransaction.OperationType,
-		Amount:        dbTransaction.Amount,
-		Description:   dbTransaction.Description,
-		CreatedAt:     dbTransaction.CreatedAt,
-		Status:        dbTransaction.Status,
+		Id:                    dbTransaction.ID,
+		AccountId:             dbTransaction.AccountID,
+		OperationType:         dbTransaction.OperationType,
+		AmountCents:           int64(dbTransaction.Amount),
+		Description:           dbTransaction.Description,
+		CreatedAt:             dbTransaction.CreatedAt,
+		Status:                dbTransaction.Status,
+		ExternalId:            dbTransaction.ExternalID,
+		Tags:                  dbTransaction.Tags,
+		Metadata:              dbTransaction.Metadata,
+		TransferId:            dbTransaction.TransferID,
+		OriginalTransactionId: dbTransaction.OriginalTransactionID,
+		BalanceCents:          int64(dbTransaction.Balance),
+		Overdrawn:             dbTransaction.Overdrawn,
+		OriginalCurrency:      dbTransaction.OriginalCurrency,
+		OriginalAmountCents:   int64(dbTransaction.OriginalAmount),
+		FxRate:                dbTransaction.FXRate,
 	}
 }
 
@@ -23,28 +33,44 @@ func ConvertTransactionToProto(dbTransaction *common.Transaction) *pbTransaction
 // This function maps all fields from the protobuf Transaction to the corresponding common.Transaction fields.
 func ConvertTransactionFromProto(pbTransaction *pbTransaction.Transaction) *common.Transaction {
 	return &common.Transaction{
-		ID:            pbTransaction.Id,
-		AccountID:     pbTransaction.AccountId,
-		OperationType: pbTransaction.OperationType,
-		Amount:        pbTransaction.Amount,
-		Description:   pbTransaction.Description,
-		CreatedAt:     pbTransaction.CreatedAt,
-		Status:        pbTransaction.Status,
+		ID:                    pbTransaction.Id,
+		AccountID:             pbTransaction.AccountId,
+		OperationType:         pbTransaction.OperationType,
+		Amount:                common.Cents(pbTransaction.AmountCents),
+		Description:           pbTransaction.Description,
+		CreatedAt:             pbTransaction.CreatedAt,
+		Status:                pbTransaction.Status,
+		ExternalID:            pbTransaction.ExternalId,
+		Tags:                  pbTransaction.Tags,
+		Metadata:              pbTransaction.Metadata,
+		TransferID:            pbTransaction.TransferId,
+		OriginalTransactionID: pbTransaction.OriginalTransactionId,
+		Balance:               common.Cents(pbTransaction.BalanceCents),
+		Overdrawn:             pbTransaction.Overdrawn,
+		OriginalCurrency:      pbTransaction.OriginalCurrency,
+		OriginalAmount:        common.Cents(pbTransaction.OriginalAmountCents),
+		FXRate:                pbTransaction.FxRate,
 	}
 }
 
 // ConvertCreateTransactionRequestToTransaction converts a CreateTransactionRequest to a database Transaction struct.
 // It sets the current timestamp for the created_at field and initializes status as PENDING.
+// The category, if any, becomes the transaction's category metadata entry.
 func ConvertCreateTransactionRequestToTransaction(req *pbTransaction.CreateTransactionRequest) *common.Transaction {
 	now := common.GetCurrentTimestamp()
-	return &common.Transaction{
+	transaction := &common.Transaction{
 		AccountID:     req.AccountId,
 		OperationType: req.OperationType,
-		Amount:        req.Amount,
+		Amount:        common.Cents(req.AmountCents),
 		Description:   req.Description,
 		CreatedAt:     now,
 		Status:        "PENDING",
+		ExternalID:    req.ExternalId,
+	}
+	if req.Category != "" {
+		transaction.Metadata = map[string]string{categoryMetadataKey: req.Category}
 	}
+	return transaction
 }
 
 // ConvertProcessPaymentRequestToTransaction converts a ProcessPaymentRequest to a database Transaction struct.
@@ -54,9 +80,61 @@ func ConvertProcessPaymentRequestToTransaction(req *pbTransaction.ProcessPayment
 	return &common.Transaction{
 		AccountID:     req.AccountId,
 		OperationType: "PAYMENT",
-		Amount:        req.Amount,
+		Amount:        common.Cents(req.AmountCents),
 		Description:   req.Description,
 		CreatedAt:     now,
 		Status:        "PENDING",
 	}
 }
+
+// ConvertOperationRuleToProto converts an OperationRule to a protobuf OperationRule message.
+func ConvertOperationRuleToProto(rule OperationRule) *pbTransaction.OperationRule {
+	return &pbTransaction.OperationRule{
+		OperationType:          rule.OperationType,
+		Direction:              rule.Direction,
+		RequiresPositiveAmount: rule.RequiresPositiveAmount,
+		AllowedAccountTypes:    rule.AllowedAccountTypes,
+		FeePolicy:              rule.FeePolicy,
+		UpdatedAt:              rule.UpdatedAt,
+	}
+}
+
+// ConvertOperationRuleFromProto converts a protobuf OperationRule message to an OperationRule.
+func ConvertOperationRuleFromProto(pbRule *pbTransaction.OperationRule) OperationRule {
+	return OperationRule{
+		OperationType:          pbRule.OperationType,
+		Direction:              pbRule.Direction,
+		RequiresPositiveAmount: pbRule.RequiresPositiveAmount,
+		AllowedAccountTypes:    pbRule.AllowedAccountTypes,
+		FeePolicy:              pbRule.FeePolicy,
+		UpdatedAt:              pbRule.UpdatedAt,
+	}
+}
+
+// ConvertWebhookSigningKeyToProto converts a WebhookSigningKey to a protobuf WebhookSigningKey message.
+func ConvertWebhookSigningKeyToProto(key *common.WebhookSigningKey) *pbTransaction.WebhookSigningKey {
+	return &pbTransaction.WebhookSigningKey{
+		Subscriber: key.Subscriber,
+		KeyId:      key.KeyID,
+		Secret:     key.Secret,
+		Status:     key.Status,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		RetiredBy:  key.RetiredBy,
+		RetiredAt:  key.RetiredAt,
+	}
+}
+
+// ConvertMessageTemplateToProto converts a message template to its protobuf representation.
+func ConvertMessageTemplateToProto(t *common.MessageTemplate) *pbTransaction.MessageTemplate {
+	return &pbTransaction.MessageTemplate{
+		TenantId:  t.TenantID,
+		EventType: t.EventType,
+		Channel:   t.Channel,
+		Subject:   t.Subject,
+		Body:      t.Body,
+		Source:    t.Source(),
+		UpdatedBy: t.UpdatedBy,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
@@ -0,0 +1,387 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"github.com/google/uuid"
+)
+
+// Limits on a settlement file: the number of records, the number of days its dates may span, and the number
+// of extra transactions reported for it.
+const (
+	maxSettlementLines    = 10000
+	maxSettlementDays     = 31
+	maxSettlementExtras   = 1000
+	settlementDateLayout  = "2006-01-02"
+	settlementLookupBatch = 500
+)
+
+// Balance adjustments requested by a reconciliation are recorded with this source and reason code, and the
+// ID of the transaction as reference, so each transaction gets at most one.
+const (
+	settlementAdjustmentSource = "reconciliation"
+	settlementReasonCode       = "SETTLEMENT"
+)
+
+// settlementLine is a record parsed from a settlement file.
+type settlementLine struct {
+	line       int32
+	externalID string
+	amount     common.Cents
+	date       time.Time
+}
+
+// proto returns the line as reported in a reconciliation.
+func (l settlementLine) proto() *pb.ReconciliationLine {
+	return &pb.ReconciliationLine{
+		Line:        l.line,
+		ExternalId:  l.externalID,
+		AmountCents: int64(l.amount),
+		Date:        l.date.Format(settlementDateLayout),
+	}
+}
+
+// settlementDifference is a settled amount that differs from the amount of its transaction.
+type settlementDifference struct {
+	line        settlementLine
+	transaction *common.Transaction
+}
+
+// parseSettlementFile parses a CSV settlement file with a header row naming at least the external_id, amount
+// and date columns. Amounts are positive and in major units, e.g. 12.50; dates are YYYY-MM-DD in UTC. Other
+// columns are ignored. Records that cannot be read are returned as invalid lines; an error is returned only
+// when the file as a whole cannot be read.
+func parseSettlementFile(content []byte) ([]settlementLine, []*pb.ReconciliationLine, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"external_id", "amount", "date"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("missing column: %s", required)
+		}
+	}
+
+	var lines []settlementLine
+	var invalid []*pb.ReconciliationLine
+	seen := make(map[string]bool)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			invalid = append(invalid, &pb.ReconciliationLine{Line: int32(line), Reason: "invalid line"})
+			continue
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		parsed := settlementLine{line: int32(line), externalID: field("external_id")}
+		var reason string
+		switch {
+		case parsed.externalID == "" || len(parsed.externalID) > maxExternalIDLength:
+			reason = "invalid external_id"
+		case seen[parsed.externalID]:
+			reason = "duplicate external_id in file"
+		}
+		if reason == "" {
+			parsed.amount, err = common.ParseCents(field("amount"))
+			if err != nil || parsed.amount <= 0 {
+				reason = "invalid amount"
+			}
+		}
+		if reason == "" {
+			if parsed.date, err = time.Parse(settlementDateLayout, field("date")); err != nil {
+				reason = "invalid date"
+			}
+		}
+		if reason != "" {
+			invalid = append(invalid, &pb.ReconciliationLine{Line: int32(line), ExternalId: parsed.externalID, Reason: reason})
+			continue
+		}
+		seen[parsed.externalID] = true
+		lines = append(lines, parsed)
+	}
+	return lines, invalid, nil
+}
+
+// settlementAdjustment returns the balance adjustment that accounts for a transaction settling for a different
+// amount than it was recorded with.
+func settlementAdjustment(transaction *common.Transaction, settled common.Cents) (string, common.Cents) {
+	difference := settled - transaction.Amount.Abs()
+	// A debit settled for more takes more from the balance; a credit settled for more adds more to it
+	if (transaction.Amount < 0) == (difference > 0) {
+		return "DEBIT", difference.Abs()
+	}
+	return "CREDIT", difference.Abs()
+}
+
+// ReconcileSettlement matches a processor settlement file against transactions by external_id, amount and date.
+// Lines whose transaction agrees on all three are matched; lines whose transaction differs in amount or date, or
+// is not COMPLETED, are mismatched; lines without a transaction are missing. Completed transactions with an
+// external_id created on the days the file covers but absent from it are reported as extra.
+// Only support and admin operators may reconcile files. With auto_adjust, an operator ID is also required and a
+// PENDING SETTLEMENT balance adjustment is requested for every amount difference, for an admin to approve through
+// the balance adjustment review. A transaction gets at most one, so reconciling a file again is safe.
+func (s *Service) ReconcileSettlement(ctx context.Context, req *pb.ReconcileSettlementRequest) (*pb.ReconcileSettlementResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	policy := common.SupportOrAdmin
+	if req.AutoAdjust {
+		policy = common.SupportOrAdminOperator
+	}
+	if !common.Authorize(ctx, policy) {
+		logger.Warn("Rejected settlement reconciliation: Role=%q, Operator=%q", common.CallerRoleFromContext(ctx), operator)
+		return &pb.ReconcileSettlementResponse{Error: "permission denied"}, nil
+	}
+	if len(req.Content) == 0 {
+		return &pb.ReconcileSettlementResponse{Error: "empty file"}, nil
+	}
+
+	lines, invalid, err := parseSettlementFile(req.Content)
+	if err != nil {
+		return &pb.ReconcileSettlementResponse{Error: err.Error()}, nil
+	}
+	if len(lines)+len(invalid) > maxSettlementLines {
+		return &pb.ReconcileSettlementResponse{Error: fmt.Sprintf("file has more than %d records", maxSettlementLines)}, nil
+	}
+	response := &pb.ReconcileSettlementResponse{Invalid: invalid}
+	if len(lines) == 0 {
+		return response, nil
+	}
+
+	first, last := lines[0].date, lines[0].date
+	for _, line := range lines {
+		if line.date.Before(first) {
+			first = line.date
+		}
+		if line.date.After(last) {
+			last = line.date
+		}
+	}
+	if last.Sub(first) >= maxSettlementDays*24*time.Hour {
+		return &pb.ReconcileSettlementResponse{Error: fmt.Sprintf("file spans more than %d days", maxSettlementDays)}, nil
+	}
+
+	transactions, err := s.findSettledTransactions(ctx, lines)
+	if err != nil {
+		if common.IsCancellation(err) {
+			return &pb.ReconcileSettlementResponse{Error: "request cancelled"}, nil
+		}
+		logger.Error("Settlement matching failed: %v", err)
+		return &pb.ReconcileSettlementResponse{Error: "database error"}, nil
+	}
+
+	var differences []settlementDifference
+	for _, line := range lines {
+		result := line.proto()
+		transaction, ok := transactions[line.externalID]
+		if !ok {
+			response.Missing = append(response.Missing, result)
+			continue
+		}
+		result.TransactionId, result.AccountId = transaction.ID, transaction.AccountID
+		switch {
+		case transaction.Status != "COMPLETED":
+			result.Reason = "transaction not completed"
+		case line.amount != transaction.Amount.Abs():
+			result.Reason = "amount differs"
+			differences = append(differences, settlementDifference{line: line, transaction: transaction})
+		case time.Unix(transaction.CreatedAt, 0).UTC().Format(settlementDateLayout) != result.Date:
+			result.Reason = "date differs"
+		}
+		if result.Reason == "" {
+			response.Matched = append(response.Matched, result)
+		} else {
+			response.Mismatched = append(response.Mismatched, result)
+		}
+	}
+
+	response.Extra, response.ExtraTruncated, err = s.findUnsettledTransactions(ctx, lines, first.Unix(), last.AddDate(0, 0, 1).Unix())
+	if err != nil {
+		if common.IsCancellation(err) {
+			return &pb.ReconcileSettlementResponse{Error: "request cancelled"}, nil
+		}
+		logger.Error("Settlement extra lookup failed: %v", err)
+		return &pb.ReconcileSettlementResponse{Error: "database error"}, nil
+	}
+
+	if req.AutoAdjust && len(differences) > 0 {
+		err = common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+			response.Adjustments = nil
+			response.AlreadyAdjusted = 0
+			for _, difference := range differences {
+				adjustment, err := s.requestSettlementAdjustment(ctx, tx, difference, operator)
+				if err != nil {
+					return err
+				}
+				if adjustment == nil {
+					response.AlreadyAdjusted++
+					continue
+				}
+				response.Adjustments = append(response.Adjustments, adjustment)
+			}
+			return nil
+		})
+		if err != nil {
+			if common.IsCancellation(err) {
+				return &pb.ReconcileSettlementResponse{Error: "request cancelled"}, nil
+			}
+			logger.Error("Settlement adjustments failed: %v", err)
+			return &pb.ReconcileSettlementResponse{Error: "could not request adjustments"}, nil
+		}
+	}
+
+	logger.Info("Settlement file reconciled: Matched=%d, Mismatched=%d, Missing=%d, Extra=%d, Invalid=%d, Adjustments=%d, Operator=%s",
+		len(response.Matched), len(response.Mismatched), len(response.Missing), len(response.Extra), len(response.Invalid),
+		len(response.Adjustments), operator)
+	return response, nil
+}
+
+// requestSettlementAdjustment records a PENDING balance adjustment for an amount difference within tx.
+// It returns nil if the transaction already has one.
+func (s *Service) requestSettlementAdjustment(ctx context.Context, tx *sql.Tx, difference settlementDifference, operator string) (*pb.ReconciliationAdjustment, error) {
+	logger := s.logger.WithContext(ctx)
+
+	transaction := difference.transaction
+	direction, amount := settlementAdjustment(transaction, difference.line.amount)
+	adjustment := &pb.ReconciliationAdjustment{
+		Id:            uuid.New().String(),
+		AccountId:     transaction.AccountID,
+		TransactionId: transaction.ID,
+		Direction:     direction,
+		AmountCents:   int64(amount),
+	}
+	description := fmt.Sprintf("Settlement of %s for %s, recorded as %s",
+		difference.line.externalID, difference.line.amount, transaction.Amount.Abs())
+
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO balance_adjustments (id, account_id, direction, amount, reason_code, description, status, requested_by, requested_at, source, reference)
+		VALUES ($1, $2, $3, $4, $5, $6, 'PENDING', $7, $8, $9, $10)
+		ON CONFLICT (source, reference) WHERE reference IS NOT NULL DO NOTHING
+	`, adjustment.Id, adjustment.AccountId, direction, amount, settlementReasonCode, description, operator,
+		common.GetCurrentTimestamp(), settlementAdjustmentSource, transaction.ID)
+	logger.LogDatabase("INSERT", "balance_adjustments", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	if inserted, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if inserted == 0 {
+		return nil, nil
+	}
+	return adjustment, nil
+}
+
+// findSettledTransactions loads the transactions referenced by the settlement lines, keyed by external_id.
+func (s *Service) findSettledTransactions(ctx context.Context, lines []settlementLine) (map[string]*common.Transaction, error) {
+	logger := s.logger.WithContext(ctx)
+
+	ids := make([]interface{}, len(lines))
+	for i, line := range lines {
+		ids[i] = line.externalID
+	}
+
+	// Like chargeback lookups, a few batches are queried at a time and the first failure cancels the rest
+	transactions := make(map[string]*common.Transaction, len(ids))
+	var mu sync.Mutex
+	group, ctx := common.NewWorkerGroup(ctx, chargebackLookupWorkers, common.FailFast)
+	for start := 0; start < len(ids); start += settlementLookupBatch {
+		batch := ids[start:min(start+settlementLookupBatch, len(ids))]
+		ok := group.Go(func(ctx context.Context) error {
+			queryStart := time.Now()
+			rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+				SELECT `+transactionColumns+` FROM transactions WHERE external_id IN (%s)
+			`, placeholders(1, len(batch))), batch...)
+			logger.LogDatabase("SELECT", "transactions", time.Since(queryStart), err)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				transaction, err := scanTransaction(rows)
+				if err != nil {
+					return err
+				}
+				mu.Lock()
+				transactions[transaction.ExternalID] = transaction
+				mu.Unlock()
+			}
+			return rows.Err()
+		})
+		if !ok {
+			break
+		}
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// findUnsettledTransactions returns the completed transactions with an external_id created between from
+// (inclusive) and to (exclusive) that are not in the settlement lines, oldest first, and whether there were
+// more than maxSettlementExtras of them.
+func (s *Service) findUnsettledTransactions(ctx context.Context, lines []settlementLine, from, to int64) ([]*pb.Transaction, bool, error) {
+	logger := s.logger.WithContext(ctx)
+
+	settled := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		settled[line.externalID] = true
+	}
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+transactionColumns+` FROM transactions
+		WHERE external_id IS NOT NULL AND status = 'COMPLETED' AND created_at >= $1 AND created_at < $2
+		ORDER BY created_at, id
+	`, from, to)
+	logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var extra []*pb.Transaction
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		if settled[transaction.ExternalID] {
+			continue
+		}
+		if len(extra) == maxSettlementExtras {
+			return extra, true, nil
+		}
+		extra = append(extra, ConvertTransactionToProto(transaction))
+	}
+	return extra, false, rows.Err()
+}
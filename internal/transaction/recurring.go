@@ -0,0 +1,453 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// Frequencies of recurring rules.
+const (
+	recurringDaily   = "DAILY"
+	recurringWeekly  = "WEEKLY"
+	recurringMonthly = "MONTHLY"
+)
+
+// Statuses of recurring rules.
+const (
+	recurringActive    = "ACTIVE"
+	recurringCancelled = "CANCELLED"
+	recurringCompleted = "COMPLETED"
+)
+
+// Limits on recurring rules; maxRecurringErrorLength matches recurring_rules.last_error.
+const (
+	maxActiveRecurringRules = 50
+	maxRecurringRunsPerPass = 100
+	maxRecurringErrorLength = 200
+)
+
+// retryableRunErrors are the errors of scheduled runs that are retried at the next check instead of being
+// skipped, because they say nothing about whether the transaction should be made.
+var retryableRunErrors = map[string]bool{
+	"database error":                true,
+	"request cancelled":             true,
+	"could not create transaction":  true,
+	"could not process payment":     true,
+	"could not process transaction": true,
+	errAccountRateLimited:           true,
+	errBusinessDayClosed:            true,
+}
+
+// recurringRuleColumns selects the columns read by scanRecurringRule.
+const recurringRuleColumns = `id, account_id, operation_type, amount, description, frequency, start_at, COALESCE(end_at, 0),
+	next_run_at, status, run_count, skipped_count, COALESCE(last_run_at, 0), COALESCE(last_transaction_id, ''),
+	COALESCE(last_error, ''), COALESCE(created_by, ''), created_at, COALESCE(cancelled_at, 0)`
+
+// recurringRun is one scheduled run of a recurring rule.
+type recurringRun struct {
+	ruleID      string
+	run         int32
+	scheduledAt int64
+	// Time of the rule's next run, and whether this run is its last one
+	nextRunAt int64
+	last      bool
+}
+
+// dueRecurringRule is what MakeRecurringTransactions reads about a rule that has a run due.
+type dueRecurringRule struct {
+	id            string
+	accountID     string
+	tenantID      string
+	operationType string
+	amount        common.Cents
+	description   string
+	frequency     string
+	startAt       int64
+	endAt         int64
+	nextRunAt     int64
+	runs          int32
+}
+
+// validRecurringFrequency reports whether frequency is a frequency of recurring rules.
+func validRecurringFrequency(frequency string) bool {
+	return frequency == recurringDaily || frequency == recurringWeekly || frequency == recurringMonthly
+}
+
+// recurringRunAt returns the time of the zero-based nth run of a rule starting at start. Monthly runs keep the
+// day of the month of start, falling back to the last day of shorter months, so they do not drift.
+func recurringRunAt(start int64, frequency string, n int32) int64 {
+	switch frequency {
+	case recurringDaily:
+		return start + int64(n)*secondsPerDay
+	case recurringWeekly:
+		return start + int64(n)*7*secondsPerDay
+	}
+	t := time.Unix(start, 0).UTC()
+	month := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	lastDay := month.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return month.AddDate(0, 0, day-1).Unix()
+}
+
+// scanRecurringRule reads a row selected with recurringRuleColumns.
+func scanRecurringRule(row rowScanner) (*pb.RecurringRule, error) {
+	rule := &pb.RecurringRule{}
+	var amount common.Cents
+	err := row.Scan(&rule.Id, &rule.AccountId, &rule.OperationType, &amount, &rule.Description, &rule.Frequency,
+		&rule.StartAt, &rule.EndAt, &rule.NextRunAt, &rule.Status, &rule.RunCount, &rule.SkippedCount, &rule.LastRunAt,
+		&rule.LastTransactionId, &rule.LastError, &rule.CreatedBy, &rule.CreatedAt, &rule.CancelledAt)
+	if err != nil {
+		return nil, err
+	}
+	rule.AmountCents = int64(amount)
+	if rule.Status != recurringActive {
+		rule.NextRunAt = 0
+	}
+	return rule, nil
+}
+
+// CreateRecurringRule creates a rule that makes a transaction on an account on a schedule, from start_at until
+// end_at if set. The checks of the account, the operation type and the tenant's policy are made now, to refuse a
+// rule whose runs could never succeed; every run is still checked like any other transaction.
+func (s *Service) CreateRecurringRule(ctx context.Context, req *pb.CreateRecurringRuleRequest) (*pb.RecurringRuleResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" || req.OperationType == "" || req.Frequency == "" {
+		return &pb.RecurringRuleResponse{Error: "account_id, operation_type and frequency required"}, nil
+	}
+	if !validRecurringFrequency(req.Frequency) {
+		return &pb.RecurringRuleResponse{Error: "frequency must be DAILY, WEEKLY or MONTHLY"}, nil
+	}
+	if req.AmountCents <= 0 {
+		return &pb.RecurringRuleResponse{Error: "amount must be positive"}, nil
+	}
+	if len(req.Description) > maxDescriptionLength {
+		return &pb.RecurringRuleResponse{Error: "description too long"}, nil
+	}
+	now := common.GetCurrentTimestamp()
+	startAt := req.StartAt
+	if startAt == 0 {
+		startAt = now
+	}
+	if startAt < now {
+		return &pb.RecurringRuleResponse{Error: "start_at is in the past"}, nil
+	}
+	if req.EndAt != 0 && req.EndAt < startAt {
+		return &pb.RecurringRuleResponse{Error: "end_at is before start_at"}, nil
+	}
+	if _, ok := s.rules.get(req.OperationType); !ok {
+		return &pb.RecurringRuleResponse{Error: "invalid operation type"}, nil
+	}
+	if msg := s.checkTenantPolicy(ctx, &pb.CreateTransactionRequest{OperationType: req.OperationType, AmountCents: req.AmountCents}); msg != "" {
+		return &pb.RecurringRuleResponse{Error: msg}, nil
+	}
+
+	var status string
+	start := time.Now()
+	err := s.db.QueryRowContext(ctx, `SELECT status FROM accounts WHERE id = $1`, req.AccountId).Scan(&status)
+	logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+	if err == sql.ErrNoRows {
+		return &pb.RecurringRuleResponse{Error: "account not found"}, nil
+	}
+	if err != nil {
+		logger.Error("Account lookup failed for recurring rule: AccountID=%s, Error=%v", req.AccountId, err)
+		return &pb.RecurringRuleResponse{Error: "database error"}, nil
+	}
+	if status != "ACTIVE" {
+		return &pb.RecurringRuleResponse{Error: "account not active"}, nil
+	}
+
+	rule := &pb.RecurringRule{
+		Id:            uuid.New().String(),
+		AccountId:     req.AccountId,
+		OperationType: req.OperationType,
+		AmountCents:   req.AmountCents,
+		Description:   req.Description,
+		Frequency:     req.Frequency,
+		StartAt:       startAt,
+		EndAt:         req.EndAt,
+		NextRunAt:     startAt,
+		Status:        recurringActive,
+		CreatedBy:     common.OperatorIDFromContext(ctx),
+		CreatedAt:     now,
+	}
+	start = time.Now()
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO recurring_rules (id, account_id, tenant_id, operation_type, amount, description, frequency, start_at,
+			end_at, next_run_at, status, created_by, created_at)
+		SELECT $1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, NULLIF($9::bigint, 0), $8, $10, NULLIF($11, ''), $12
+		WHERE (SELECT COUNT(*) FROM recurring_rules WHERE account_id = $2 AND status = $10) < $13
+	`, rule.Id, rule.AccountId, common.TenantIDFromContext(ctx), rule.OperationType, common.Cents(rule.AmountCents),
+		rule.Description, rule.Frequency, rule.StartAt, rule.EndAt, rule.Status, rule.CreatedBy, rule.CreatedAt, maxActiveRecurringRules)
+	logger.LogDatabase("INSERT", "recurring_rules", time.Since(start), err)
+	if err != nil {
+		logger.Error("Recurring rule creation failed: AccountID=%s, Error=%v", req.AccountId, err)
+		return &pb.RecurringRuleResponse{Error: "database error"}, nil
+	}
+	if created, err := result.RowsAffected(); err == nil && created == 0 {
+		return &pb.RecurringRuleResponse{Error: fmt.Sprintf("at most %d active recurring rules per account", maxActiveRecurringRules)}, nil
+	}
+
+	logger.Info("Recurring rule created: ID=%s, AccountID=%s, OperationType=%s, Amount=%s, Frequency=%s, StartAt=%d",
+		rule.Id, rule.AccountId, rule.OperationType, common.Cents(rule.AmountCents), rule.Frequency, rule.StartAt)
+	return &pb.RecurringRuleResponse{Rule: rule}, nil
+}
+
+// ListRecurringRules returns the recurring rules of an account, newest first, optionally only those with a status.
+func (s *Service) ListRecurringRules(ctx context.Context, req *pb.ListRecurringRulesRequest) (*pb.ListRecurringRulesResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" {
+		return &pb.ListRecurringRulesResponse{Error: "account_id required"}, nil
+	}
+	if req.Status != "" && req.Status != recurringActive && req.Status != recurringCancelled && req.Status != recurringCompleted {
+		return &pb.ListRecurringRulesResponse{Error: "status must be ACTIVE, CANCELLED or COMPLETED"}, nil
+	}
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+recurringRuleColumns+`
+		FROM recurring_rules
+		WHERE account_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, id
+	`, req.AccountId, req.Status)
+	logger.LogDatabase("SELECT", "recurring_rules", time.Since(start), err)
+	if err != nil {
+		logger.Error("Recurring rule listing failed: AccountID=%s, Error=%v", req.AccountId, err)
+		return &pb.ListRecurringRulesResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	resp := &pb.ListRecurringRulesResponse{}
+	for rows.Next() {
+		rule, err := scanRecurringRule(rows)
+		if err != nil {
+			logger.Error("Recurring rule scan failed: %v", err)
+			return &pb.ListRecurringRulesResponse{Error: "database error"}, nil
+		}
+		resp.Rules = append(resp.Rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Recurring rule listing failed: AccountID=%s, Error=%v", req.AccountId, err)
+		return &pb.ListRecurringRulesResponse{Error: "database error"}, nil
+	}
+	return resp, nil
+}
+
+// CancelRecurringRule stops an active recurring rule from making further transactions. A run being made as the
+// rule is cancelled is rolled back.
+func (s *Service) CancelRecurringRule(ctx context.Context, req *pb.CancelRecurringRuleRequest) (*pb.RecurringRuleResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" || req.Id == "" {
+		return &pb.RecurringRuleResponse{Error: "account_id and id required"}, nil
+	}
+
+	start := time.Now()
+	rule, err := scanRecurringRule(s.db.QueryRowContext(ctx, `
+		UPDATE recurring_rules SET status = $3, cancelled_at = $4
+		WHERE id = $1 AND account_id = $2 AND status = $5
+		RETURNING `+recurringRuleColumns,
+		req.Id, req.AccountId, recurringCancelled, common.GetCurrentTimestamp(), recurringActive))
+	logger.LogDatabase("UPDATE", "recurring_rules", time.Since(start), err)
+	if err == sql.ErrNoRows {
+		var exists bool
+		start = time.Now()
+		err = s.db.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM recurring_rules WHERE id = $1 AND account_id = $2)
+		`, req.Id, req.AccountId).Scan(&exists)
+		logger.LogDatabase("SELECT", "recurring_rules", time.Since(start), err)
+		if err == nil && !exists {
+			return &pb.RecurringRuleResponse{Error: "recurring rule not found"}, nil
+		}
+		if err == nil {
+			return &pb.RecurringRuleResponse{Error: "recurring rule not active"}, nil
+		}
+	}
+	if err != nil {
+		logger.Error("Recurring rule cancellation failed: ID=%s, Error=%v", req.Id, err)
+		return &pb.RecurringRuleResponse{Error: "database error"}, nil
+	}
+
+	logger.Info("Recurring rule cancelled: ID=%s, AccountID=%s, Runs=%d", rule.Id, rule.AccountId, rule.RunCount)
+	return &pb.RecurringRuleResponse{Rule: rule}, nil
+}
+
+// MakeRecurringTransactions makes the runs of the active recurring rules that are due by now, oldest first, and
+// returns the number of transactions made. A rule makes one run per call, so runs missed while the worker was
+// stopped are caught up one per check. Runs declined for a lasting reason are skipped; runs that failed for a
+// passing one, such as a database error, are retried at the next call.
+func (s *Service) MakeRecurringTransactions(ctx context.Context, now int64) (int, error) {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, account_id, COALESCE(tenant_id, ''), operation_type, amount, description, frequency, start_at,
+			COALESCE(end_at, 0), next_run_at, run_count + skipped_count
+		FROM recurring_rules
+		WHERE status = $1 AND next_run_at <= $2
+		ORDER BY next_run_at
+		LIMIT $3
+	`, recurringActive, now, maxRecurringRunsPerPass)
+	logger.LogDatabase("SELECT", "recurring_rules", time.Since(start), err)
+	if err != nil {
+		return 0, err
+	}
+	var due []dueRecurringRule
+	for rows.Next() {
+		var rule dueRecurringRule
+		if err := rows.Scan(&rule.id, &rule.accountID, &rule.tenantID, &rule.operationType, &rule.amount, &rule.description,
+			&rule.frequency, &rule.startAt, &rule.endAt, &rule.nextRunAt, &rule.runs); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, rule)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	made := 0
+	for _, rule := range due {
+		ok, err := s.makeRecurringRun(ctx, rule)
+		if err != nil {
+			return made, err
+		}
+		if ok {
+			made++
+		}
+	}
+	return made, nil
+}
+
+// makeRecurringRun makes the due run of a rule through the same path as CreateTransaction, on behalf of the tenant
+// the rule was created for, and reports whether it made a transaction.
+func (s *Service) makeRecurringRun(ctx context.Context, rule dueRecurringRule) (bool, error) {
+	logger := s.logger.WithContext(ctx)
+
+	run := &recurringRun{
+		ruleID:      rule.id,
+		run:         rule.runs + 1,
+		scheduledAt: rule.nextRunAt,
+		nextRunAt:   recurringRunAt(rule.startAt, rule.frequency, rule.runs+1),
+	}
+	run.last = rule.endAt != 0 && run.nextRunAt > rule.endAt
+
+	runCtx := ctx
+	if rule.tenantID != "" {
+		runCtx = metadata.NewIncomingContext(ctx, metadata.Pairs(common.TenantIDMetadataKey, rule.tenantID))
+	}
+	resp, err := s.createTransaction(runCtx, &pb.CreateTransactionRequest{
+		AccountId:     rule.accountID,
+		OperationType: rule.operationType,
+		AmountCents:   int64(rule.amount),
+		Description:   rule.description,
+	}, nil, run)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case resp.Error == "":
+		logger.Info("Recurring run made: RuleID=%s, Run=%d, TransactionID=%s", rule.id, run.run, resp.Transaction.Id)
+		return true, nil
+	case resp.Error == errRunNotDue.Error():
+		return false, nil
+	case retryableRunErrors[resp.Error]:
+		logger.Warn("Recurring run failed, will retry: RuleID=%s, Run=%d, Error=%s", rule.id, run.run, resp.Error)
+		return false, nil
+	}
+	return false, s.skipRecurringRun(ctx, run, resp.Error)
+}
+
+// record links the transaction made by the run to its rule and moves the rule to its next run. A run held for
+// review counts as made until it is declined (see settleRecurringRunReview), so the rule moves on either way. It
+// returns errRunNotDue if the rule was cancelled, or the run made by another replica, since it was found due.
+func (run *recurringRun) record(ctx context.Context, tx *sql.Tx, logger *common.Logger, transactionID string, held bool) error {
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE recurring_rules
+		SET next_run_at = $3, status = CASE WHEN $4 THEN $5 ELSE status END, run_count = run_count + 1,
+			last_run_at = $6, last_transaction_id = $7, last_error = NULL
+		WHERE id = $1 AND next_run_at = $2 AND status = $8
+	`, run.ruleID, run.scheduledAt, run.nextRunAt, run.last, recurringCompleted, common.GetCurrentTimestamp(), transactionID, recurringActive)
+	logger.LogDatabase("UPDATE", "recurring_rules", time.Since(start), err)
+	if err != nil {
+		return err
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return errRunNotDue
+	}
+
+	start = time.Now()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO recurring_rule_runs (rule_id, run, scheduled_at, transaction_id) VALUES ($1, $2, $3, $4)
+	`, run.ruleID, run.run, run.scheduledAt, transactionID)
+	logger.LogDatabase("INSERT", "recurring_rule_runs", time.Since(start), err)
+	return err
+}
+
+// settleRecurringRunReview counts the run that made a transaction declined in review as skipped, within the
+// transaction declining it. Transactions that are not recurring runs are left alone.
+func (s *Service) settleRecurringRunReview(ctx context.Context, tx *sql.Tx, transactionID string) error {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, `
+		UPDATE recurring_rules
+		SET run_count = run_count - 1, skipped_count = skipped_count + 1, last_error = $2
+		WHERE id = (SELECT rule_id FROM recurring_rule_runs WHERE transaction_id = $1)
+	`, transactionID, errDeclinedInReview)
+	logger.LogDatabase("UPDATE", "recurring_rules", time.Since(start), err)
+	return err
+}
+
+// skipRecurringRun moves a rule past a run that was declined, keeping the error.
+func (s *Service) skipRecurringRun(ctx context.Context, run *recurringRun, failure string) error {
+	logger := s.logger.WithContext(ctx)
+
+	if len(failure) > maxRecurringErrorLength {
+		failure = failure[:maxRecurringErrorLength]
+	}
+	start := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE recurring_rules
+		SET next_run_at = $3, status = CASE WHEN $4 THEN $5 ELSE status END, skipped_count = skipped_count + 1,
+			last_run_at = $6, last_error = $7
+		WHERE id = $1 AND next_run_at = $2 AND status = $8
+	`, run.ruleID, run.scheduledAt, run.nextRunAt, run.last, recurringCompleted, common.GetCurrentTimestamp(), failure, recurringActive)
+	logger.LogDatabase("UPDATE", "recurring_rules", time.Since(start), err)
+	if err != nil {
+		return err
+	}
+	logger.Warn("Recurring run skipped: RuleID=%s, Run=%d, Error=%s", run.ruleID, run.run, failure)
+	return nil
+}
+
+// RunRecurringPayments makes the due runs of recurring rules every interval until ctx is done.
+func (s *Service) RunRecurringPayments(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.MakeRecurringTransactions(ctx, common.GetCurrentTimestamp()); err != nil {
+				s.logger.Error("Recurring payments failed: %v", err)
+			}
+		}
+	}
+}
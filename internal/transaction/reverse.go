@@ -0,0 +1,198 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"github.com/google/uuid"
+)
+
+// reversalOperationType is the operation type of the compensating transaction recorded by a reversal.
+// It has no operation rule, so it cannot be created through CreateTransaction.
+const reversalOperationType = "REVERSAL"
+
+// reversalError is a reversal refused for a reason reported to the caller.
+type reversalError string
+
+func (e reversalError) Error() string { return string(e) }
+
+// ReverseTransaction reverses a COMPLETED transaction: it records a COMPLETED REVERSAL transaction of the
+// opposite amount that links to the original through original_transaction_id, gives the amount back to the
+// balance and marks the original REVERSED, all in one database transaction. A transaction can only be reversed
+// once; reversals and transfer legs cannot be reversed. Reversing a credit is refused when the balance no
+// longer covers it. Reversing an installment purchase cancels its unpaid installments, and is refused while the
+// debit of a later installment stands or is held for review. Reversing a payment reopens the purchases and
+// withdrawals it discharged. The reason becomes the description of the reversal.
+// Only support staff and admins identified by an operator ID may reverse transactions.
+func (s *Service) ReverseTransaction(ctx context.Context, req *pb.ReverseTransactionRequest) (resp *pb.ReverseTransactionResponse, err error) {
+	// Reversals are always traced, for forensic analysis
+	ctx, span := s.startMoneyMovement(ctx, "transaction.reverse", transactionIDAttribute.String(req.Id), forensicAttribute.Bool(true))
+	defer func() { endMoneyMovement(span, resp.GetError()) }()
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.SupportOrAdminOperator) {
+		logger.Warn("Rejected transaction reversal: ID=%s, Role=%q, Operator=%q", req.Id, common.CallerRoleFromContext(ctx), operator)
+		return &pb.ReverseTransactionResponse{Error: "permission denied"}, nil
+	}
+	if req.Id == "" {
+		return &pb.ReverseTransactionResponse{Error: "id required"}, nil
+	}
+	if req.Reason == "" {
+		return &pb.ReverseTransactionResponse{Error: "reason required"}, nil
+	}
+	if len(req.Reason) > maxDescriptionLength {
+		return &pb.ReverseTransactionResponse{Error: "reason too long"}, nil
+	}
+
+	// The balance update is ordered with other operations on the account, so the account is found first
+	var accountID string
+	start := time.Now()
+	err = s.db.QueryRowContext(ctx, `SELECT account_id FROM transactions WHERE id = $1`, req.Id).Scan(&accountID)
+	logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+	if err == sql.ErrNoRows {
+		return &pb.ReverseTransactionResponse{Error: "not found"}, nil
+	}
+	if err != nil {
+		if common.IsCancellation(err) {
+			return &pb.ReverseTransactionResponse{Error: "request cancelled"}, nil
+		}
+		logger.Error("Transaction lookup for reversal failed: ID=%s, Error=%v", req.Id, err)
+		return &pb.ReverseTransactionResponse{Error: "database error"}, nil
+	}
+
+	unlock, err := s.accountLocks.Lock(ctx, accountID)
+	if err != nil {
+		logger.Error("Reversal aborted while waiting for account lock: ID=%s, Error=%v", accountID, err)
+		return &pb.ReverseTransactionResponse{Error: "request cancelled"}, nil
+	}
+	defer unlock()
+
+	var original, reversal *common.Transaction
+	err = common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		// The row lock makes a concurrent reversal of the same transaction wait and then find it REVERSED
+		start := time.Now()
+		original, err = scanTransaction(tx.QueryRowContext(ctx,
+			`SELECT `+transactionColumns+` FROM transactions WHERE id = $1 FOR UPDATE`, req.Id))
+		logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+		if err == sql.ErrNoRows {
+			return reversalError("not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case original.Status == "REVERSED":
+			return reversalError("transaction already reversed")
+		case original.Status != "COMPLETED":
+			return reversalError("only completed transactions can be reversed")
+		case original.OperationType == reversalOperationType:
+			return reversalError("reversals cannot be reversed")
+		case original.TransferID != "":
+			return reversalError("transfers cannot be reversed")
+		}
+
+		// An installment purchase takes its unpaid installments with it; the debits of those already paid are
+		// reversed on their own first, and those held for review reviewed
+		if original.OperationType == installmentOperationType {
+			if err := s.cancelInstallments(ctx, tx, original.ID); err != nil {
+				return err
+			}
+			paid, err := s.countPaidInstallments(ctx, tx, original.ID)
+			if err != nil {
+				return err
+			}
+			if paid > 0 {
+				return reversalError("paid installments must be reversed first")
+			}
+		}
+
+		now := common.GetCurrentTimestamp()
+		reversal = &common.Transaction{
+			ID:                    uuid.New().String(),
+			AccountID:             original.AccountID,
+			OperationType:         reversalOperationType,
+			Amount:                -original.Amount,
+			Description:           req.Reason,
+			CreatedAt:             now,
+			Status:                "COMPLETED",
+			OriginalTransactionID: original.ID,
+		}
+
+		var balance common.Cents
+		start = time.Now()
+		err = tx.QueryRowContext(ctx, `
+			UPDATE accounts
+			SET balance = balance + $1, updated_at = $2
+			WHERE id = $3 AND ($1 >= 0 OR balance + $1 >= -overdraft_limit)
+			RETURNING balance
+		`, reversal.Amount, now, reversal.AccountID).Scan(&balance)
+		logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+		if err == sql.ErrNoRows {
+			return reversalError("insufficient balance")
+		}
+		if err != nil {
+			return err
+		}
+		recordBalanceMutation(span, reversal.AccountID, reversal.ID, reversal.OperationType, reversal.Amount, balance-reversal.Amount)
+		reversal.Overdrawn = reversal.Amount < 0 && balance < 0
+
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `UPDATE transactions SET status = 'REVERSED' WHERE id = $1`, original.ID)
+		logger.LogDatabase("UPDATE", "transactions", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		original.Status = "REVERSED"
+
+		// The debts a payment discharged are outstanding again once it is reversed
+		if original.OperationType == "PAYMENT" {
+			if err := s.reopenDischargedDebts(ctx, tx, original.ID); err != nil {
+				return err
+			}
+		}
+
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO transactions (id, account_id, operation_type, amount, description, created_at, status, original_transaction_id, overdrawn)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, reversal.ID, reversal.AccountID, reversal.OperationType, reversal.Amount, reversal.Description,
+			reversal.CreatedAt, reversal.Status, reversal.OriginalTransactionID, reversal.Overdrawn)
+		logger.LogDatabase("INSERT", "transactions", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+
+		if err := s.enqueueTransactionsCreated(ctx, tx, reversal); err != nil {
+			return err
+		}
+		return s.enqueueTransactionReversed(ctx, tx, original, reversal)
+	})
+
+	var reverseErr reversalError
+	switch {
+	case err == nil:
+	case errors.As(err, &reverseErr):
+		logger.Warn("Transaction reversal refused: ID=%s, Operator=%s, Error=%s", req.Id, operator, reverseErr.Error())
+		return &pb.ReverseTransactionResponse{Error: reverseErr.Error()}, nil
+	case common.IsCancellation(err):
+		logger.Warn("Transaction reversal cancelled by client, changes rolled back: ID=%s", req.Id)
+		return &pb.ReverseTransactionResponse{Error: "request cancelled"}, nil
+	default:
+		logger.Error("Transaction reversal failed: ID=%s, Error=%v", req.Id, err)
+		return &pb.ReverseTransactionResponse{Error: "could not reverse transaction"}, nil
+	}
+
+	logger.Info("Transaction reversed: ID=%s, ReversalID=%s, AccountID=%s, Amount=%s, Operator=%s",
+		original.ID, reversal.ID, reversal.AccountID, reversal.Amount, operator)
+	s.events.recordReversal(ctx, original.OperationType)
+	return &pb.ReverseTransactionResponse{
+		Original: ConvertTransactionToProto(original),
+		Reversal: ConvertTransactionToProto(reversal),
+	}, nil
+}
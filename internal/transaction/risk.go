@@ -0,0 +1,462 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// Defaults of the risk policy.
+const (
+	DefaultRiskReviewScore    = 60
+	DefaultRiskVelocityWindow = 10 * time.Minute
+)
+
+// Risk factors a debit can be flagged for.
+const (
+	riskLargeAmount   = "LARGE_AMOUNT"
+	riskHighVelocity  = "HIGH_VELOCITY"
+	riskDrainsBalance = "DRAINS_BALANCE"
+)
+
+// riskFactorScores is what each factor adds to the risk score of a debit. With the default review score
+// a large amount is held on its own, a burst of debits only when it also drains the balance.
+var riskFactorScores = map[string]int{
+	riskLargeAmount:   60,
+	riskHighVelocity:  50,
+	riskDrainsBalance: 30,
+}
+
+// riskDrainRatio is the share of the balance a debit must take to drain it.
+const riskDrainRatio = 0.9
+
+// Limits on flagged transaction listing and review requests.
+const (
+	defaultFlaggedTransactionsLimit = 100
+	maxFlaggedTransactionsLimit     = 500
+	maxReviewNoteLength             = 500
+)
+
+// reviewStatuses maps each review decision to the status it gives an UNDER_REVIEW transaction.
+var reviewStatuses = map[string]string{
+	"APPROVE": "COMPLETED",
+	"DECLINE": "DECLINED",
+}
+
+// errDeclinedInReview is the last error of the installment or recurring rule whose run was declined in review.
+const errDeclinedInReview = "declined in review"
+
+// RiskPolicy decides which debits fraud scoring holds for manual review. Each factor a debit shows adds to
+// its risk score: an amount of at least LargeAmount, more than VelocityLimit debits on the account within
+// VelocityWindow, or taking most of the balance. Debits scoring at least ReviewScore are held.
+// A zero LargeAmount or VelocityLimit disables that factor; with both disabled no debit is held.
+type RiskPolicy struct {
+	ReviewScore    int
+	LargeAmount    common.Cents
+	VelocityLimit  int
+	VelocityWindow time.Duration
+}
+
+// RiskPolicyFromEnv reads RISK_REVIEW_SCORE, RISK_LARGE_AMOUNT, RISK_VELOCITY_LIMIT and RISK_VELOCITY_WINDOW.
+// Invalid values fall back to the defaults, which disable fraud scoring.
+func RiskPolicyFromEnv() RiskPolicy {
+	policy := RiskPolicy{
+		ReviewScore:    DefaultRiskReviewScore,
+		VelocityWindow: DefaultRiskVelocityWindow,
+	}
+	if score, err := strconv.Atoi(os.Getenv("RISK_REVIEW_SCORE")); err == nil && score > 0 {
+		policy.ReviewScore = score
+	}
+	if amount, err := common.ParseCents(os.Getenv("RISK_LARGE_AMOUNT")); err == nil && amount > 0 {
+		policy.LargeAmount = amount
+	}
+	if limit, err := strconv.Atoi(os.Getenv("RISK_VELOCITY_LIMIT")); err == nil && limit > 0 {
+		policy.VelocityLimit = limit
+	}
+	if window, err := time.ParseDuration(os.Getenv("RISK_VELOCITY_WINDOW")); err == nil && window > 0 {
+		policy.VelocityWindow = window
+	}
+	return policy
+}
+
+// Enabled reports whether the policy can hold debits.
+func (p RiskPolicy) Enabled() bool {
+	return p.LargeAmount > 0 || p.VelocityLimit > 0
+}
+
+// assess scores a balance change of amount on an account with the given balance, which had recentDebits
+// debits within the velocity window. Returns the score and the factors that contributed to it.
+func (p RiskPolicy) assess(amount, balance common.Cents, recentDebits int) (int, []string) {
+	if !p.Enabled() || amount >= 0 {
+		return 0, nil
+	}
+	debit := -amount
+
+	var factors []string
+	if p.LargeAmount > 0 && debit >= p.LargeAmount {
+		factors = append(factors, riskLargeAmount)
+	}
+	if p.VelocityLimit > 0 && recentDebits+1 > p.VelocityLimit {
+		factors = append(factors, riskHighVelocity)
+	}
+	if balance > 0 && debit >= balance.MulRate(riskDrainRatio) {
+		factors = append(factors, riskDrainsBalance)
+	}
+
+	score := 0
+	for _, factor := range factors {
+		score += riskFactorScores[factor]
+	}
+	return score, factors
+}
+
+// transactionReview is the risk assessment of a transaction held for review.
+type transactionReview struct {
+	transactionID string
+	score         int
+	factors       []string
+	flaggedAt     int64
+}
+
+// SetRiskPolicy makes the service hold the debits policy flags for manual review instead of applying them.
+func (s *Service) SetRiskPolicy(policy RiskPolicy) {
+	s.risk = policy
+}
+
+// screenTransaction scores a new transaction applied to an account with the given balance. If it reaches the
+// review score, its status becomes UNDER_REVIEW and the review to record is returned; otherwise nil.
+func (s *Service) screenTransaction(transaction *common.Transaction, balance common.Cents, recentDebits int) *transactionReview {
+	score, factors := s.risk.assess(transaction.Amount, balance, recentDebits)
+	if len(factors) == 0 || score < s.risk.ReviewScore {
+		return nil
+	}
+	transaction.Status = "UNDER_REVIEW"
+	return &transactionReview{
+		transactionID: transaction.ID,
+		score:         score,
+		factors:       factors,
+		flaggedAt:     transaction.CreatedAt,
+	}
+}
+
+// countRecentDebits returns the number of debits of each account created within the velocity window, whatever
+// their status. It does not query the database when the velocity factor is disabled.
+func (s *Service) countRecentDebits(ctx context.Context, tx *sql.Tx, accountIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(accountIDs))
+	if s.risk.VelocityLimit <= 0 || len(accountIDs) == 0 {
+		return counts, nil
+	}
+
+	args := []interface{}{common.GetCurrentTimestamp() - int64(s.risk.VelocityWindow/time.Second)}
+	for _, id := range accountIDs {
+		args = append(args, id)
+	}
+
+	start := time.Now()
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		SELECT account_id, COUNT(*) FROM transactions
+		WHERE created_at >= $1 AND amount < 0 AND account_id IN (%s)
+		GROUP BY account_id
+	`, placeholders(2, len(accountIDs))), args...)
+	s.logger.WithContext(ctx).LogDatabase("SELECT", "transactions", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var accountID string
+		var count int
+		if err := rows.Scan(&accountID, &count); err != nil {
+			return nil, err
+		}
+		counts[accountID] = count
+	}
+	return counts, rows.Err()
+}
+
+// insertTransactionReviews records the reviews of held transactions with a single multi-row insert.
+func (s *Service) insertTransactionReviews(ctx context.Context, tx *sql.Tx, reviews []*transactionReview) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	const columns = 4
+	args := make([]interface{}, 0, len(reviews)*columns)
+	values := make([]string, 0, len(reviews))
+	for _, review := range reviews {
+		values = append(values, "("+placeholders(len(args)+1, columns)+")")
+		args = append(args, review.transactionID, review.score, strings.Join(review.factors, ","), review.flaggedAt)
+	}
+
+	start := time.Now()
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO transaction_reviews (transaction_id, risk_score, risk_factors, flagged_at) VALUES %s
+	`, strings.Join(values, ", ")), args...)
+	s.logger.WithContext(ctx).LogDatabase("INSERT", "transaction_reviews", time.Since(start), err)
+	return err
+}
+
+// flaggedColumns selects the columns read by scanFlaggedTransaction, from transactions joined with transaction_reviews.
+const flaggedColumns = transactionColumns + `, risk_score, risk_factors, flagged_at,
+	COALESCE(decision, ''), note, COALESCE(reviewed_by, ''), COALESCE(reviewed_at, 0)`
+
+// scanFlaggedTransaction reads a row selected with flaggedColumns.
+func scanFlaggedTransaction(row rowScanner) (*pb.FlaggedTransaction, error) {
+	var scanned scannedTransaction
+	var factors string
+	flagged := &pb.FlaggedTransaction{}
+	dest := append(scanned.dest(), &flagged.RiskScore, &factors, &flagged.FlaggedAt,
+		&flagged.Decision, &flagged.Note, &flagged.ReviewedBy, &flagged.ReviewedAt)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	transaction, err := scanned.decode()
+	if err != nil {
+		return nil, err
+	}
+	flagged.Transaction = ConvertTransactionToProto(transaction)
+	if factors != "" {
+		flagged.RiskFactors = strings.Split(factors, ",")
+	}
+	return flagged, nil
+}
+
+// ListFlaggedTransactions returns the transactions held for review by fraud scoring that have not been
+// reviewed yet, oldest first, optionally only those of one account. Support staff and admins may list them.
+func (s *Service) ListFlaggedTransactions(ctx context.Context, req *pb.ListFlaggedTransactionsRequest) (*pb.ListFlaggedTransactionsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.SupportOrAdmin) {
+		logger.Warn("Rejected flagged transaction listing: Role=%q", common.CallerRoleFromContext(ctx))
+		return &pb.ListFlaggedTransactionsResponse{Error: "permission denied"}, nil
+	}
+	limit := req.Limit
+	if limit <= 0 || limit > maxFlaggedTransactionsLimit {
+		limit = defaultFlaggedTransactionsLimit
+	}
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+flaggedColumns+`
+		FROM transactions JOIN transaction_reviews ON transaction_id = id
+		WHERE decision IS NULL AND ($1 = '' OR account_id = $1)
+		ORDER BY flagged_at, id
+		LIMIT $2
+	`, req.AccountId, limit)
+	logger.LogDatabase("SELECT", "transaction_reviews", time.Since(start), err)
+	if err != nil {
+		logger.Error("Flagged transaction lookup failed: %v", err)
+		return &pb.ListFlaggedTransactionsResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	var transactions []*pb.FlaggedTransaction
+	for rows.Next() {
+		flagged, err := scanFlaggedTransaction(rows)
+		if err != nil {
+			logger.Error("Flagged transaction scan failed: %v", err)
+			return &pb.ListFlaggedTransactionsResponse{Error: "database error"}, nil
+		}
+		transactions = append(transactions, flagged)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Flagged transaction lookup failed: %v", err)
+		return &pb.ListFlaggedTransactionsResponse{Error: "database error"}, nil
+	}
+
+	return &pb.ListFlaggedTransactionsResponse{Transactions: transactions}, nil
+}
+
+// ApproveFlagged releases a transaction held for review: its amount is applied to the account balance,
+// subject to the usual account and balance checks, and it becomes COMPLETED. Support staff and admins
+// identified by an operator ID may review transactions; the decision is recorded with the operator and note.
+func (s *Service) ApproveFlagged(ctx context.Context, req *pb.ApproveFlaggedRequest) (*pb.ApproveFlaggedResponse, error) {
+	flagged, msg := s.reviewFlagged(ctx, req.Id, "APPROVE", req.Note)
+	return &pb.ApproveFlaggedResponse{Transaction: flagged, Error: msg}, nil
+}
+
+// DeclineFlagged declines a transaction held for review: it becomes DECLINED and the balance is left unchanged.
+// Support staff and admins identified by an operator ID may review transactions; the decision is recorded
+// with the operator and note.
+func (s *Service) DeclineFlagged(ctx context.Context, req *pb.DeclineFlaggedRequest) (*pb.DeclineFlaggedResponse, error) {
+	flagged, msg := s.reviewFlagged(ctx, req.Id, "DECLINE", req.Note)
+	return &pb.DeclineFlaggedResponse{Transaction: flagged, Error: msg}, nil
+}
+
+// reviewFlagged applies a review decision to a transaction held for review.
+// Returns the reviewed transaction, or an error message.
+func (s *Service) reviewFlagged(ctx context.Context, id, decision, note string) (*pb.FlaggedTransaction, string) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.SupportOrAdminOperator) {
+		logger.Warn("Rejected flagged transaction review: ID=%s, Decision=%s, Role=%q, Operator=%q",
+			id, decision, common.CallerRoleFromContext(ctx), operator)
+		return nil, "permission denied"
+	}
+	if id == "" {
+		return nil, "missing transaction id"
+	}
+	if len(note) > maxReviewNoteLength {
+		return nil, "note too long"
+	}
+
+	flagged, err := s.applyReview(ctx, id, decision, note, operator)
+	var reviewErr resolutionError
+	switch {
+	case err == nil:
+	case errors.As(err, &reviewErr):
+		return nil, reviewErr.Error()
+	case common.IsCancellation(err):
+		return nil, "request cancelled"
+	default:
+		logger.Error("Flagged transaction review failed: ID=%s, Decision=%s, Error=%v", id, decision, err)
+		return nil, "database error"
+	}
+
+	logger.Info("Flagged transaction reviewed: ID=%s, Decision=%s, Status=%s, Operator=%s",
+		id, decision, flagged.Transaction.Status, operator)
+	return flagged, ""
+}
+
+// applyReview gives a transaction under review the status of decision and records the decision, within one
+// database transaction. Approvals hold the account lock, so the balance check and update are ordered with
+// other operations on the account, and write the transaction.created event of the now completed transaction
+// along with the events of any budget thresholds it crosses.
+func (s *Service) applyReview(ctx context.Context, id, decision, note, operator string) (flagged *pb.FlaggedTransaction, err error) {
+	ctx, span := s.startMoneyMovement(ctx, "transaction.review", transactionIDAttribute.String(id), reviewDecisionAttribute.String(decision))
+	defer func() {
+		failure := ""
+		if err != nil {
+			failure = err.Error()
+		}
+		endMoneyMovement(span, failure)
+	}()
+	logger := s.logger.WithContext(ctx)
+
+	if decision == "APPROVE" {
+		var accountID string
+		start := time.Now()
+		err := s.db.QueryRowContext(ctx, `SELECT account_id FROM transactions WHERE id = $1`, id).Scan(&accountID)
+		logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+		if err == sql.ErrNoRows {
+			return nil, resolutionError("transaction not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		unlock, err := s.accountLocks.Lock(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	err = common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		start := time.Now()
+		var err error
+		flagged, err = scanFlaggedTransaction(tx.QueryRowContext(ctx, `
+			SELECT `+flaggedColumns+`
+			FROM transactions JOIN transaction_reviews ON transaction_id = id
+			WHERE id = $1
+			FOR UPDATE
+		`, id))
+		logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+		if err == sql.ErrNoRows {
+			return resolutionError("transaction not found")
+		}
+		if err != nil {
+			return err
+		}
+		if flagged.Transaction.Status != "UNDER_REVIEW" || flagged.Decision != "" {
+			return resolutionError("transaction is not under review")
+		}
+
+		reviewedAt := common.GetCurrentTimestamp()
+		transaction := flagged.Transaction
+		if decision == "APPROVE" {
+			var balance, overdraftLimit common.Cents
+			var status string
+			amount := common.Cents(transaction.AmountCents)
+			start = time.Now()
+			err := tx.QueryRowContext(ctx, `SELECT balance, status, overdraft_limit FROM accounts WHERE id = $1 FOR UPDATE`,
+				transaction.AccountId).Scan(&balance, &status, &overdraftLimit)
+			logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+			if err != nil {
+				return err
+			}
+			if status != "ACTIVE" {
+				return resolutionError("account not active")
+			}
+			if exceedsOverdraft(balance, amount, overdraftLimit) {
+				return resolutionError("insufficient balance")
+			}
+			transaction.Overdrawn = amount < 0 && balance+amount < 0
+
+			start = time.Now()
+			_, err = tx.ExecContext(ctx, `
+				UPDATE accounts SET balance = balance + $1, updated_at = $2 WHERE id = $3
+			`, amount, reviewedAt, transaction.AccountId)
+			logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+			if err != nil {
+				return err
+			}
+			recordBalanceMutation(span, transaction.AccountId, transaction.Id, transaction.OperationType, amount, balance)
+		}
+
+		transaction.Status = reviewStatuses[decision]
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `UPDATE transactions SET status = $1, overdrawn = $2 WHERE id = $3`, transaction.Status, transaction.Overdrawn, id)
+		logger.LogDatabase("UPDATE", "transactions", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+
+		flagged.Decision, flagged.Note, flagged.ReviewedBy, flagged.ReviewedAt = decision, note, operator, reviewedAt
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE transaction_reviews SET decision = $1, note = $2, reviewed_by = $3, reviewed_at = $4
+			WHERE transaction_id = $5
+		`, decision, note, operator, reviewedAt, id)
+		logger.LogDatabase("UPDATE", "transaction_reviews", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+
+		// Installment debits and recurring runs held for review are settled with their transaction
+		if transaction.OperationType == installmentOperationType {
+			if err := s.settleInstallmentReview(ctx, tx, id, decision == "APPROVE"); err != nil {
+				return err
+			}
+		}
+		if decision != "APPROVE" {
+			if err := s.settleRecurringRunReview(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+
+		if decision == "APPROVE" {
+			approved := ConvertTransactionFromProto(transaction)
+			if err := s.enqueueTransactionsCreated(ctx, tx, approved); err != nil {
+				return err
+			}
+			return s.enqueueBudgetThresholdsCrossed(ctx, tx, approved)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flagged, nil
+}
@@ -0,0 +1,255 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// DefaultOperationRulesRefreshInterval is how often WatchOperationRules reloads the rules from the database,
+// so changes made through another service instance are picked up.
+const DefaultOperationRulesRefreshInterval = time.Minute
+
+// ruleAccountTypes lists the account types an operation rule may be restricted to.
+var ruleAccountTypes = map[string]bool{
+	"CHECKING": true,
+	"SAVINGS":  true,
+	"CREDIT":   true,
+}
+
+// ruleFeePolicies lists the supported fee policies. TENANT_SCHEDULE marks operation types that are subject
+// to the tenant's fee schedule; fees are not charged yet.
+var ruleFeePolicies = map[string]bool{
+	"NONE":            true,
+	"TENANT_SCHEDULE": true,
+}
+
+// OperationRule describes how transactions of one operation type are validated and applied to the balance.
+type OperationRule struct {
+	OperationType          string
+	Direction              string
+	RequiresPositiveAmount bool
+	AllowedAccountTypes    []string
+	FeePolicy              string
+	UpdatedAt              int64
+}
+
+// SignedAmount returns the balance change a transaction of amount applies: credits add the amount as given,
+// debits subtract it.
+func (r OperationRule) SignedAmount(amount common.Cents) common.Cents {
+	if r.Direction == "DEBIT" && amount >= 0 {
+		return -amount
+	}
+	return amount
+}
+
+// AllowsAccountType reports whether the operation may be applied to an account of the given type.
+func (r OperationRule) AllowsAccountType(accountType string) bool {
+	if len(r.AllowedAccountTypes) == 0 {
+		return true
+	}
+	for _, allowed := range r.AllowedAccountTypes {
+		if allowed == accountType {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAmount returns an error message if amount is not acceptable for the operation type,
+// or an empty string if it is.
+func (r OperationRule) CheckAmount(amount common.Cents) string {
+	if r.RequiresPositiveAmount && amount <= 0 {
+		return strings.ToLower(strings.ReplaceAll(r.OperationType, "_", " ")) + " amount must be positive"
+	}
+	return ""
+}
+
+// Validate checks that the rule is well formed.
+func (r OperationRule) Validate() error {
+	if r.Direction != "CREDIT" && r.Direction != "DEBIT" {
+		return fmt.Errorf("direction must be CREDIT or DEBIT")
+	}
+	// Payments discharge purchases and withdrawals by operation type, which only holds while payments credit
+	// the account and the others debit it
+	if builtin, ok := defaultOperationRules()[r.OperationType]; ok && r.Direction != builtin.Direction {
+		return fmt.Errorf("direction of %s cannot be changed from %s", r.OperationType, builtin.Direction)
+	}
+	for _, accountType := range r.AllowedAccountTypes {
+		if !ruleAccountTypes[accountType] {
+			return fmt.Errorf("unknown account type: %s", accountType)
+		}
+	}
+	if !ruleFeePolicies[r.FeePolicy] {
+		return fmt.Errorf("fee_policy must be NONE or TENANT_SCHEDULE")
+	}
+	return nil
+}
+
+// defaultOperationRules returns the rules seeded by InitSchema: payments credit the account and must be positive,
+// every other operation type debits it. They apply until the rules are loaded from the database.
+func defaultOperationRules() map[string]OperationRule {
+	rules := make(map[string]OperationRule)
+	for _, operationType := range []string{"CASH_PURCHASE", "INSTALLMENT_PURCHASE", "WITHDRAWAL"} {
+		rules[operationType] = OperationRule{OperationType: operationType, Direction: "DEBIT", FeePolicy: "NONE"}
+	}
+	rules["PAYMENT"] = OperationRule{OperationType: "PAYMENT", Direction: "CREDIT", RequiresPositiveAmount: true, FeePolicy: "NONE"}
+	return rules
+}
+
+// operationRuleSet holds the rules in effect, keyed by operation type. It is safe for concurrent use.
+type operationRuleSet struct {
+	mu    sync.RWMutex
+	rules map[string]OperationRule
+}
+
+func newOperationRuleSet(rules map[string]OperationRule) *operationRuleSet {
+	return &operationRuleSet{rules: rules}
+}
+
+// get returns the rule of an operation type; ok is false for unknown operation types.
+func (rs *operationRuleSet) get(operationType string) (rule OperationRule, ok bool) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	rule, ok = rs.rules[operationType]
+	return rule, ok
+}
+
+// list returns all rules ordered by operation type.
+func (rs *operationRuleSet) list() []OperationRule {
+	rs.mu.RLock()
+	rules := make([]OperationRule, 0, len(rs.rules))
+	for _, rule := range rs.rules {
+		rules = append(rules, rule)
+	}
+	rs.mu.RUnlock()
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i].OperationType < rules[j].OperationType })
+	return rules
+}
+
+func (rs *operationRuleSet) put(rule OperationRule) {
+	rs.mu.Lock()
+	rs.rules[rule.OperationType] = rule
+	rs.mu.Unlock()
+}
+
+func (rs *operationRuleSet) replace(rules map[string]OperationRule) {
+	rs.mu.Lock()
+	rs.rules = rules
+	rs.mu.Unlock()
+}
+
+// LoadOperationRules replaces the rules in effect with the contents of the operation_type_rules table.
+// Operation types without a row are rejected afterwards.
+func (s *Service) LoadOperationRules(ctx context.Context) error {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT operation_type, direction, requires_positive_amount, allowed_account_types, fee_policy, updated_at
+		FROM operation_type_rules
+	`)
+	logger.LogDatabase("SELECT", "operation_type_rules", time.Since(start), err)
+	if err != nil {
+		return fmt.Errorf("failed to load operation rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make(map[string]OperationRule)
+	for rows.Next() {
+		var rule OperationRule
+		var accountTypes string
+		if err := rows.Scan(&rule.OperationType, &rule.Direction, &rule.RequiresPositiveAmount, &accountTypes, &rule.FeePolicy, &rule.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to load operation rules: %w", err)
+		}
+		if accountTypes != "" {
+			rule.AllowedAccountTypes = strings.Split(accountTypes, ",")
+		}
+		rules[rule.OperationType] = rule
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load operation rules: %w", err)
+	}
+
+	s.rules.replace(rules)
+	return nil
+}
+
+// WatchOperationRules reloads the operation rules every interval until ctx is cancelled.
+// A failed reload is logged and the previously loaded rules stay in effect.
+func (s *Service) WatchOperationRules(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.LoadOperationRules(ctx); err != nil {
+				s.logger.Error("Operation rules reload failed, keeping previous rules: %v", err)
+			}
+		}
+	}
+}
+
+// ListOperationRules returns the rules in effect for every operation type.
+func (s *Service) ListOperationRules(ctx context.Context, req *pb.ListOperationRulesRequest) (*pb.ListOperationRulesResponse, error) {
+	rules := s.rules.list()
+	pbRules := make([]*pb.OperationRule, len(rules))
+	for i, rule := range rules {
+		pbRules[i] = ConvertOperationRuleToProto(rule)
+	}
+	return &pb.ListOperationRulesResponse{Rules: pbRules}, nil
+}
+
+// UpdateOperationRule replaces the rule of an existing operation type. Only admins may change rules.
+// The change applies immediately on this instance and on others at their next reload.
+func (s *Service) UpdateOperationRule(ctx context.Context, req *pb.UpdateOperationRuleRequest) (*pb.UpdateOperationRuleResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.AdminOnly) {
+		logger.Warn("Rejected operation rule update: OperationType=%s, caller is not an admin", req.OperationType)
+		return &pb.UpdateOperationRuleResponse{Error: "permission denied"}, nil
+	}
+	if _, ok := s.rules.get(req.OperationType); !ok {
+		return &pb.UpdateOperationRuleResponse{Error: "invalid operation type"}, nil
+	}
+	if req.Rule == nil {
+		return &pb.UpdateOperationRuleResponse{Error: "rule required"}, nil
+	}
+
+	rule := ConvertOperationRuleFromProto(req.Rule)
+	rule.OperationType = req.OperationType
+	if rule.FeePolicy == "" {
+		rule.FeePolicy = "NONE"
+	}
+	if err := rule.Validate(); err != nil {
+		return &pb.UpdateOperationRuleResponse{Error: err.Error()}, nil
+	}
+	rule.UpdatedAt = common.GetCurrentTimestamp()
+
+	start := time.Now()
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE operation_type_rules
+		SET direction = $1, requires_positive_amount = $2, allowed_account_types = $3, fee_policy = $4, updated_at = $5
+		WHERE operation_type = $6
+	`, rule.Direction, rule.RequiresPositiveAmount, strings.Join(rule.AllowedAccountTypes, ","), rule.FeePolicy, rule.UpdatedAt, rule.OperationType)
+	logger.LogDatabase("UPDATE", "operation_type_rules", time.Since(start), err)
+	if err != nil {
+		logger.Error("Operation rule update failed: OperationType=%s, Error=%v", rule.OperationType, err)
+		return &pb.UpdateOperationRuleResponse{Error: "database error"}, nil
+	}
+
+	s.rules.put(rule)
+	logger.Info("Operation rule updated: OperationType=%s, Direction=%s, RequiresPositiveAmount=%t, AllowedAccountTypes=%v, FeePolicy=%s",
+		rule.OperationType, rule.Direction, rule.RequiresPositiveAmount, rule.AllowedAccountTypes, rule.FeePolicy)
+	return &pb.UpdateOperationRuleResponse{Rule: ConvertOperationRuleToProto(rule)}, nil
+}
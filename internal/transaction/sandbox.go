@@ -0,0 +1,144 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+)
+
+// DefaultSandboxClearingDelay is how long a transaction of a sandbox tenant stays PENDING or UNDER_REVIEW
+// before simulated clearing settles it, unless SANDBOX_CLEARING_DELAY says otherwise.
+const DefaultSandboxClearingDelay = 10 * time.Second
+
+// Simulated clearing settles at most this many transactions per tenant and run, and records its decisions
+// as made by sandboxClearingOperator.
+const (
+	maxSandboxClearingBatch = 100
+	sandboxClearingOperator = "sandbox-clearing"
+	sandboxClearingNote     = "simulated clearing"
+)
+
+// ClearSandboxTransactions simulates clearing for sandbox tenants, so integrators see their transactions
+// settle without an operator: transactions held UNDER_REVIEW for at least delay are approved and PENDING ones
+// completed, through the same paths as operator reviews and resolutions. Those the balance or account no longer
+// allows are declined or failed instead. It returns the number of transactions settled.
+func (s *Service) ClearSandboxTransactions(ctx context.Context, delay time.Duration) (int, error) {
+	tenants, err := s.tenants.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	cleared := 0
+	for tenantID, settings := range tenants {
+		if !settings.Sandbox {
+			continue
+		}
+		// Events written while clearing carry the tenant like those of the tenant's own requests
+		tenantCtx := metadata.NewIncomingContext(ctx, metadata.Pairs(common.TenantIDMetadataKey, tenantID))
+		count, err := s.clearSandboxTenant(tenantCtx, tenantID, common.GetCurrentTimestamp()-int64(delay/time.Second))
+		cleared += count
+		if err != nil {
+			return cleared, err
+		}
+	}
+	return cleared, nil
+}
+
+// clearSandboxTenant settles the transactions of one sandbox tenant created at or before cutoff.
+func (s *Service) clearSandboxTenant(ctx context.Context, tenantID string, cutoff int64) (int, error) {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.id, t.status
+		FROM transactions t JOIN accounts a ON a.id = t.account_id
+		WHERE a.tenant_id = $1 AND t.status IN ('PENDING', 'UNDER_REVIEW') AND t.created_at <= $2
+		ORDER BY t.created_at, t.id
+		LIMIT $3
+	`, tenantID, cutoff, maxSandboxClearingBatch)
+	logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+	if err != nil {
+		return 0, err
+	}
+	type heldTransaction struct{ id, status string }
+	var held []heldTransaction
+	for rows.Next() {
+		var t heldTransaction
+		if err := rows.Scan(&t.id, &t.status); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		held = append(held, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	cleared := 0
+	for _, t := range held {
+		status, err := s.clearSandboxTransaction(ctx, t.id, t.status)
+		var refused resolutionError
+		switch {
+		case err == nil:
+			cleared++
+			logger.Info("Sandbox transaction cleared: ID=%s, TenantID=%s, Status=%s", t.id, tenantID, status)
+		case errors.As(err, &refused):
+			// Reviewed or resolved by an operator in the meantime
+			logger.Debug("Sandbox transaction left unchanged: ID=%s, Error=%s", t.id, refused.Error())
+		default:
+			return cleared, err
+		}
+	}
+	return cleared, nil
+}
+
+// clearSandboxTransaction settles one held transaction and returns the status it was given.
+func (s *Service) clearSandboxTransaction(ctx context.Context, id, status string) (string, error) {
+	if status == "UNDER_REVIEW" {
+		flagged, err := s.applyReview(ctx, id, "APPROVE", sandboxClearingNote, sandboxClearingOperator)
+		if isClearingRefusal(err) {
+			flagged, err = s.applyReview(ctx, id, "DECLINE", sandboxClearingNote+": "+err.Error(), sandboxClearingOperator)
+		}
+		if err != nil {
+			return "", err
+		}
+		return flagged.Transaction.Status, nil
+	}
+
+	resolution, err := s.resolveStuckTransaction(ctx, id, "COMPLETE", sandboxClearingNote, sandboxClearingOperator)
+	if isClearingRefusal(err) {
+		resolution, err = s.resolveStuckTransaction(ctx, id, "FAIL", sandboxClearingNote+": "+err.Error(), sandboxClearingOperator)
+	}
+	if err != nil {
+		return "", err
+	}
+	return resolution.Status, nil
+}
+
+// isClearingRefusal reports whether err is a refusal to settle a transaction that clearing would decline,
+// as opposed to one that no longer needs clearing.
+func isClearingRefusal(err error) bool {
+	return errors.Is(err, resolutionError("insufficient balance")) || errors.Is(err, resolutionError("account not active"))
+}
+
+// RunSandboxClearing clears the held transactions of sandbox tenants every interval until ctx is cancelled.
+func (s *Service) RunSandboxClearing(ctx context.Context, interval, delay time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ClearSandboxTransactions(ctx, delay); err != nil {
+				s.logger.Error("Sandbox clearing failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// simulateTransaction runs the checks CreateTransaction would apply to a request against the current
+// account balance and returns the transaction it would create, without writing anything. A payment lists the
+// outstanding purchases and withdrawals it would discharge.
+// The account is not locked, so a concurrent transaction can still change the real outcome.
+func (s *Service) simulateTransaction(ctx context.Context, req *pb.CreateTransactionRequest, rule OperationRule) *pb.CreateTransactionResponse {
+	logger := s.logger.WithContext(ctx)
+
+	if msg := rule.CheckAmount(common.Cents(req.AmountCents)); msg != "" {
+		return &pb.CreateTransactionResponse{Error: msg, Simulated: true}
+	}
+
+	var balance, overdraftLimit common.Cents
+	var accountType, status string
+	start := time.Now()
+	err := s.db.QueryRowContext(ctx, `
+		SELECT balance, account_type, status, overdraft_limit FROM accounts WHERE id = $1
+	`, req.AccountId).Scan(&balance, &accountType, &status, &overdraftLimit)
+	duration := time.Since(start)
+
+	logger.LogDatabase("SELECT", "accounts", duration, err)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Error("Account not found for simulated transaction: ID=%s", req.AccountId)
+			s.missingAccounts.Add(req.AccountId)
+			return &pb.CreateTransactionResponse{Error: "account not found", Simulated: true}
+		}
+		logger.Error("Account check failed: %v", err)
+		return &pb.CreateTransactionResponse{Error: "database error", Simulated: true}
+	}
+
+	if status != "ACTIVE" {
+		return &pb.CreateTransactionResponse{Error: "account not active", Simulated: true}
+	}
+	if !rule.AllowsAccountType(accountType) {
+		return &pb.CreateTransactionResponse{Error: "operation type not allowed for account type", Simulated: true}
+	}
+
+	amount := rule.SignedAmount(firstInstallmentAmount(req))
+	if exceedsOverdraft(balance, amount, overdraftLimit) {
+		return &pb.CreateTransactionResponse{Error: "insufficient balance", Simulated: true}
+	}
+
+	dbTransaction := ConvertCreateTransactionRequestToTransaction(req)
+	dbTransaction.Amount = amount
+	dbTransaction.Status = "COMPLETED"
+	dbTransaction.Overdrawn = amount < 0 && balance+amount < 0
+	dbTransaction.Balance = initialBalance(dbTransaction)
+
+	var discharges []*pb.Discharge
+	if req.OperationType == "PAYMENT" {
+		debts, err := s.loadOutstandingDebts(ctx, s.db, req.AccountId, amount, "")
+		if err != nil {
+			logger.Error("Simulated payment discharge failed: %v", err)
+			return &pb.CreateTransactionResponse{Error: "database error", Simulated: true}
+		}
+		discharges, dbTransaction.Balance = planDischarges(debts, amount)
+	}
+
+	pbTransaction := ConvertTransactionToProto(dbTransaction)
+	if req.InstallmentCount > 1 {
+		pbTransaction.InstallmentPlan = newInstallmentPlan(dbTransaction, installmentAmounts(common.Cents(req.AmountCents), req.InstallmentCount))
+	}
+	return &pb.CreateTransactionResponse{
+		Transaction:       pbTransaction,
+		Simulated:         true,
+		BalanceAfterCents: int64(balance + amount),
+		Discharges:        discharges,
+	}
+}
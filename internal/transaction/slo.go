@@ -0,0 +1,46 @@
+package transaction
+
+import (
+	"context"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// SetSLOTracker makes GetSLOStatus report the calls recorded by tracker.
+func (s *Service) SetSLOTracker(tracker *common.SLOTracker) {
+	s.slo = tracker
+}
+
+// GetSLOStatus returns the burn rates of the methods with an SLO target in the runtime config.
+// Support and admin callers may read it.
+func (s *Service) GetSLOStatus(ctx context.Context, req *pb.GetSLOStatusRequest) (*pb.GetSLOStatusResponse, error) {
+	if !common.Authorize(ctx, common.SupportOrAdmin) {
+		s.logger.WithContext(ctx).Warn("Rejected SLO status request: Role=%q", common.CallerRoleFromContext(ctx))
+		return &pb.GetSLOStatusResponse{Error: "permission denied"}, nil
+	}
+	if s.slo == nil {
+		return &pb.GetSLOStatusResponse{}, nil
+	}
+
+	var methods []*pb.MethodSLOStatus
+	for _, status := range s.slo.Status() {
+		method := &pb.MethodSLOStatus{
+			Method:             status.Method,
+			AvailabilityTarget: status.Target.Availability,
+			LatencyTargetMs:    status.Target.LatencyMs,
+			WithinBudget:       status.WithinBudget,
+		}
+		for _, window := range status.Windows {
+			method.Windows = append(method.Windows, &pb.SLOWindowStatus{
+				WindowSeconds:  int64(window.Window.Seconds()),
+				Total:          window.Total,
+				Bad:            window.Bad,
+				SuccessPercent: window.SuccessPercent,
+				BurnRate:       window.BurnRate,
+			})
+		}
+		methods = append(methods, method)
+	}
+	return &pb.GetSLOStatusResponse{Methods: methods}, nil
+}
@@ -0,0 +1,197 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// statementIDNamespace is the UUID namespace statement IDs are derived in.
+var statementIDNamespace = uuid.MustParse("5c1f7d9e-2b4a-4e0f-9a63-8d2e71b0c4f5")
+
+// statementColumns are the columns of statements read by scanStatement.
+const statementColumns = `id, account_id, period, period_start, period_end, opening_balance, closing_balance, adjustments,
+	totals, transaction_count, generated_at`
+
+// statementTotal is an element of statements.totals.
+type statementTotal struct {
+	OperationType string       `json:"operation_type"`
+	Count         int64        `json:"count"`
+	Total         common.Cents `json:"total"`
+}
+
+// statementID returns the ID of the statement of an account for a period. IDs are derived rather than random,
+// so a statement keeps its ID if it is generated again, e.g. after the account was restored.
+func statementID(accountID, period string) string {
+	return uuid.NewSHA1(statementIDNamespace, []byte(accountID+"/"+period)).String()
+}
+
+// scanStatement reads a row of statementColumns.
+func scanStatement(row interface{ Scan(...any) error }) (*pb.Statement, error) {
+	var statement pb.Statement
+	var opening, closing, adjustments common.Cents
+	var totals []byte
+	err := row.Scan(&statement.Id, &statement.AccountId, &statement.Period, &statement.PeriodStart, &statement.PeriodEnd,
+		&opening, &closing, &adjustments, &totals, &statement.TransactionCount, &statement.GeneratedAt)
+	if err != nil {
+		return nil, err
+	}
+	statement.OpeningBalanceCents = int64(opening)
+	statement.ClosingBalanceCents = int64(closing)
+	statement.AdjustmentsCents = int64(adjustments)
+
+	var decoded []statementTotal
+	if err := json.Unmarshal(totals, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid statement totals: %w", err)
+	}
+	for _, total := range decoded {
+		statement.Totals = append(statement.Totals, &pb.StatementTotal{
+			OperationType: total.OperationType,
+			Count:         total.Count,
+			TotalCents:    int64(total.Total),
+		})
+	}
+	return &statement, nil
+}
+
+// GetStatement returns a statement generated by GenerateStatement, by ID or by account and period.
+func (s *Service) GetStatement(ctx context.Context, req *pb.GetStatementRequest) (*pb.StatementResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	id := req.Id
+	if id == "" {
+		if req.AccountId == "" || req.Period == "" {
+			return &pb.StatementResponse{Error: "id or account_id and period required"}, nil
+		}
+		id = statementID(req.AccountId, req.Period)
+	}
+
+	start := time.Now()
+	statement, err := scanStatement(s.db.QueryRowContext(ctx, `SELECT `+statementColumns+` FROM statements WHERE id = $1`, id))
+	logger.LogDatabase("SELECT", "statements", time.Since(start), err)
+	if err == sql.ErrNoRows {
+		return &pb.StatementResponse{Error: "statement not found"}, nil
+	}
+	if err != nil {
+		logger.Error("Statement lookup failed: ID=%s, Error=%v", id, err)
+		return &pb.StatementResponse{Error: "database error"}, nil
+	}
+	return &pb.StatementResponse{Statement: statement}, nil
+}
+
+// GenerateStatement generates the statement of an account for a calendar month that has ended: its opening and
+// closing balance, derived from the ledger like GetBalanceAt, and the count and total of its completed
+// transactions per operation type, read from the daily rollups. A statement is stored when first generated and
+// later calls return it unchanged, so it keeps matching what was issued even if the ledger of the month changes
+// afterwards, e.g. a pending transaction completing or the retention worker purging the month's transactions.
+func (s *Service) GenerateStatement(ctx context.Context, req *pb.GenerateStatementRequest) (*pb.StatementResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" {
+		return &pb.StatementResponse{Error: "account_id required"}, nil
+	}
+	month, err := time.Parse(budgetMonthLayout, req.Period)
+	if err != nil {
+		return &pb.StatementResponse{Error: "period must be YYYY-MM"}, nil
+	}
+	from, to, period := budgetMonth(month.Unix())
+	if to > time.Now().Unix() {
+		return &pb.StatementResponse{Error: "period has not ended"}, nil
+	}
+
+	if existing, err := s.GetStatement(ctx, &pb.GetStatementRequest{AccountId: req.AccountId, Period: period}); err != nil || existing.Error != "statement not found" {
+		return existing, err
+	}
+
+	// Months start at midnight UTC, so whole days of rollups cover them exactly
+	statement := &pb.Statement{
+		Id:          statementID(req.AccountId, period),
+		AccountId:   req.AccountId,
+		Period:      period,
+		PeriodStart: from,
+		PeriodEnd:   to,
+		GeneratedAt: common.GetCurrentTimestamp(),
+	}
+	var createdAt int64
+	var opening, adjustments common.Cents
+	var totals []byte
+	start := time.Now()
+	err = s.db.QueryRowContext(ctx, `
+		SELECT a.created_at,
+			a.opening_balance
+				+ COALESCE((SELECT SUM(r.total_amount) FROM transaction_daily_rollups r
+					WHERE r.account_id = a.id AND r.day_start < $2), 0)
+				+ COALESCE((SELECT SUM(CASE WHEN b.direction = 'CREDIT' THEN b.amount ELSE -b.amount END)
+					FROM balance_adjustments b
+					WHERE b.account_id = a.id AND b.status = 'APPROVED' AND COALESCE(b.reviewed_at, b.requested_at) < $2), 0),
+			COALESCE((SELECT SUM(CASE WHEN b.direction = 'CREDIT' THEN b.amount ELSE -b.amount END)
+				FROM balance_adjustments b
+				WHERE b.account_id = a.id AND b.status = 'APPROVED'
+					AND COALESCE(b.reviewed_at, b.requested_at) >= $2 AND COALESCE(b.reviewed_at, b.requested_at) < $3), 0),
+			COALESCE((SELECT jsonb_agg(jsonb_build_object('operation_type', t.operation_type, 'count', t.count, 'total', t.total)
+					ORDER BY t.operation_type)
+				FROM (SELECT r.operation_type, SUM(r.txn_count) AS count, SUM(r.total_amount) AS total
+					FROM transaction_daily_rollups r
+					WHERE r.account_id = a.id AND r.day_start >= $2 AND r.day_start < $3
+					GROUP BY r.operation_type) t), '[]'::jsonb)
+		FROM accounts a
+		WHERE a.id = $1
+	`, req.AccountId, from, to).Scan(&createdAt, &opening, &adjustments, &totals)
+	logger.LogDatabase("SELECT", "transaction_daily_rollups", time.Since(start), err)
+	if err == sql.ErrNoRows {
+		return &pb.StatementResponse{Error: "account not found"}, nil
+	}
+	if err != nil {
+		logger.Error("Statement query failed: AccountID=%s, Period=%s, Error=%v", req.AccountId, period, err)
+		return &pb.StatementResponse{Error: "database error"}, nil
+	}
+	if createdAt >= to {
+		return &pb.StatementResponse{Error: "account did not exist in that period"}, nil
+	}
+
+	var decoded []statementTotal
+	if err := json.Unmarshal(totals, &decoded); err != nil {
+		logger.Error("Statement query failed: AccountID=%s, Period=%s, Error=%v", req.AccountId, period, err)
+		return &pb.StatementResponse{Error: "database error"}, nil
+	}
+	closing := opening + adjustments
+	for _, total := range decoded {
+		closing += total.Total
+		statement.TransactionCount += total.Count
+		statement.Totals = append(statement.Totals, &pb.StatementTotal{
+			OperationType: total.OperationType,
+			Count:         total.Count,
+			TotalCents:    int64(total.Total),
+		})
+	}
+	statement.OpeningBalanceCents = int64(opening)
+	statement.ClosingBalanceCents = int64(closing)
+	statement.AdjustmentsCents = int64(adjustments)
+
+	start = time.Now()
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO statements (`+statementColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (account_id, period) DO NOTHING
+	`, statement.Id, statement.AccountId, statement.Period, statement.PeriodStart, statement.PeriodEnd,
+		opening, closing, adjustments, totals, statement.TransactionCount, statement.GeneratedAt)
+	logger.LogDatabase("INSERT", "statements", time.Since(start), err)
+	if err != nil {
+		logger.Error("Statement insert failed: AccountID=%s, Period=%s, Error=%v", req.AccountId, period, err)
+		return &pb.StatementResponse{Error: "database error"}, nil
+	}
+	if inserted, err := result.RowsAffected(); err == nil && inserted == 0 {
+		// Generated concurrently by another call; return the stored one
+		return s.GetStatement(ctx, &pb.GetStatementRequest{Id: statement.Id})
+	}
+
+	logger.Info("Statement generated: AccountID=%s, Period=%s, Transactions=%d", req.AccountId, period, statement.TransactionCount)
+	return &pb.StatementResponse{Statement: statement}, nil
+}
@@ -0,0 +1,268 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"github.com/google/uuid"
+)
+
+// DefaultStuckTransactionAge is how long a transaction must have been PENDING to be listed as stuck
+// when the request does not specify a threshold.
+const DefaultStuckTransactionAge = 15 * time.Minute
+
+// Limits on stuck transaction listing and resolution requests.
+const (
+	defaultStuckTransactionsLimit = 100
+	maxStuckTransactionsLimit     = 500
+	maxStuckResolutionBatch       = 100
+	maxResolutionReasonLength     = 500
+)
+
+// stuckResolutionStatuses maps each resolution action to the status it gives a PENDING transaction.
+// A PENDING transaction has not been applied to the balance, so only COMPLETE changes the balance;
+// REVERSE cancels a transaction withdrawn by the client or network, FAIL one that failed in processing.
+var stuckResolutionStatuses = map[string]string{
+	"COMPLETE": "COMPLETED",
+	"FAIL":     "FAILED",
+	"REVERSE":  "CANCELLED",
+}
+
+// resolutionError is a resolution rejected for a reason reported to the caller.
+type resolutionError string
+
+func (e resolutionError) Error() string { return string(e) }
+
+// ListStuckTransactions returns the transactions that have been PENDING for at least older_than_seconds,
+// or DefaultStuckTransactionAge, oldest first. Only admins may list them.
+func (s *Service) ListStuckTransactions(ctx context.Context, req *pb.ListStuckTransactionsRequest) (*pb.ListStuckTransactionsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.AdminOnly) {
+		logger.Warn("Rejected stuck transaction listing: Role=%q", common.CallerRoleFromContext(ctx))
+		return &pb.ListStuckTransactionsResponse{Error: "permission denied"}, nil
+	}
+	if req.OlderThanSeconds < 0 {
+		return &pb.ListStuckTransactionsResponse{Error: "older_than_seconds must not be negative"}, nil
+	}
+
+	age := int64(DefaultStuckTransactionAge / time.Second)
+	if req.OlderThanSeconds > 0 {
+		age = req.OlderThanSeconds
+	}
+	limit := req.Limit
+	if limit <= 0 || limit > maxStuckTransactionsLimit {
+		limit = defaultStuckTransactionsLimit
+	}
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE status = 'PENDING' AND created_at <= $1
+		ORDER BY created_at, id
+		LIMIT $2
+	`, common.GetCurrentTimestamp()-age, limit)
+	logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+	if err != nil {
+		logger.Error("Stuck transaction lookup failed: %v", err)
+		return &pb.ListStuckTransactionsResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	var transactions []*pb.Transaction
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			logger.Error("Stuck transaction scan failed: %v", err)
+			return &pb.ListStuckTransactionsResponse{Error: "database error"}, nil
+		}
+		transactions = append(transactions, ConvertTransactionToProto(transaction))
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Stuck transaction lookup failed: %v", err)
+		return &pb.ListStuckTransactionsResponse{Error: "database error"}, nil
+	}
+
+	return &pb.ListStuckTransactionsResponse{Transactions: transactions}, nil
+}
+
+// ResolveStuckTransactions completes, fails or reverses PENDING transactions in bulk, so operators do not
+// have to fix them with manual SQL. Completing a transaction applies its amount to the account balance,
+// subject to the usual balance check. Each transaction is resolved in its own database transaction and
+// recorded in transaction_resolutions with the operator and reason; transactions that are no longer PENDING
+// are left unchanged and reported in their result. Only admins identified by an operator ID may resolve them.
+func (s *Service) ResolveStuckTransactions(ctx context.Context, req *pb.ResolveStuckTransactionsRequest) (*pb.ResolveStuckTransactionsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	role := common.CallerRoleFromContext(ctx)
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected stuck transaction resolution: Role=%q, Operator=%q", role, operator)
+		return &pb.ResolveStuckTransactionsResponse{Error: "permission denied"}, nil
+	}
+	if _, ok := stuckResolutionStatuses[req.Action]; !ok {
+		return &pb.ResolveStuckTransactionsResponse{Error: "action must be COMPLETE, FAIL or REVERSE"}, nil
+	}
+	if req.Reason == "" {
+		return &pb.ResolveStuckTransactionsResponse{Error: "reason required"}, nil
+	}
+	if len(req.Reason) > maxResolutionReasonLength {
+		return &pb.ResolveStuckTransactionsResponse{Error: "reason too long"}, nil
+	}
+	if len(req.Ids) == 0 {
+		return &pb.ResolveStuckTransactionsResponse{Error: "ids required"}, nil
+	}
+	if len(req.Ids) > maxStuckResolutionBatch {
+		return &pb.ResolveStuckTransactionsResponse{Error: fmt.Sprintf("at most %d transactions per request", maxStuckResolutionBatch)}, nil
+	}
+
+	results := make([]*pb.ResolveStuckTransactionResult, len(req.Ids))
+	seen := make(map[string]bool, len(req.Ids))
+	resolved := 0
+	for i, id := range req.Ids {
+		result := &pb.ResolveStuckTransactionResult{TransactionId: id}
+		results[i] = result
+		if seen[id] {
+			result.Error = "duplicate id"
+			continue
+		}
+		seen[id] = true
+
+		resolution, err := s.resolveStuckTransaction(ctx, id, req.Action, req.Reason, operator)
+		var resolveErr resolutionError
+		switch {
+		case err == nil:
+			result.Resolution = resolution
+			resolved++
+		case errors.As(err, &resolveErr):
+			result.Error = resolveErr.Error()
+		case common.IsCancellation(err):
+			result.Error = "request cancelled"
+		default:
+			logger.Error("Stuck transaction resolution failed: ID=%s, Error=%v", id, err)
+			result.Error = "database error"
+		}
+	}
+
+	logger.Info("Stuck transactions resolved: Action=%s, Operator=%s, Resolved=%d, Unchanged=%d",
+		req.Action, operator, resolved, len(req.Ids)-resolved)
+	return &pb.ResolveStuckTransactionsResponse{Results: results}, nil
+}
+
+// resolveStuckTransaction gives one PENDING transaction the status of action and records the resolution.
+// Completions hold the account lock, so the balance check and update are ordered with other operations
+// on the account.
+func (s *Service) resolveStuckTransaction(ctx context.Context, id, action, reason, operator string) (resolution *pb.TransactionResolution, err error) {
+	ctx, span := s.startMoneyMovement(ctx, "transaction.resolve", transactionIDAttribute.String(id), resolutionActionAttribute.String(action))
+	defer func() {
+		failure := ""
+		if err != nil {
+			failure = err.Error()
+		}
+		endMoneyMovement(span, failure)
+	}()
+	logger := s.logger.WithContext(ctx)
+
+	if action == "COMPLETE" {
+		var accountID string
+		start := time.Now()
+		err := s.db.QueryRowContext(ctx, `SELECT account_id FROM transactions WHERE id = $1`, id).Scan(&accountID)
+		logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+		if err == sql.ErrNoRows {
+			return nil, resolutionError("not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		unlock, err := s.accountLocks.Lock(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	resolution = &pb.TransactionResolution{
+		Id:            uuid.New().String(),
+		TransactionId: id,
+		Action:        action,
+		Status:        stuckResolutionStatuses[action],
+		Reason:        reason,
+		ResolvedBy:    operator,
+		ResolvedAt:    common.GetCurrentTimestamp(),
+	}
+	err = common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		var accountID, operationType string
+		var amount, outstanding common.Cents
+		start := time.Now()
+		err := tx.QueryRowContext(ctx, `
+			SELECT account_id, operation_type, amount, status, balance FROM transactions WHERE id = $1 FOR UPDATE
+		`, id).Scan(&accountID, &operationType, &amount, &resolution.PreviousStatus, &outstanding)
+		logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+		if err == sql.ErrNoRows {
+			return resolutionError("not found")
+		}
+		if err != nil {
+			return err
+		}
+		if resolution.PreviousStatus != "PENDING" {
+			return resolutionError("transaction is not pending")
+		}
+
+		var overdrawn bool
+		if action == "COMPLETE" {
+			var balance common.Cents
+			start = time.Now()
+			err := tx.QueryRowContext(ctx, `
+				UPDATE accounts
+				SET balance = balance + $1, updated_at = $2
+				WHERE id = $3 AND ($1 >= 0 OR balance + $1 >= -overdraft_limit)
+				RETURNING balance
+			`, amount, resolution.ResolvedAt, accountID).Scan(&balance)
+			logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+			if err == sql.ErrNoRows {
+				return resolutionError("insufficient balance")
+			}
+			if err != nil {
+				return err
+			}
+			recordBalanceMutation(span, accountID, id, operationType, amount, balance-amount)
+			overdrawn = amount < 0 && balance < 0
+
+			// A payment discharges the account's outstanding debts once it completes, like any other payment
+			if operationType == "PAYMENT" {
+				payment := &common.Transaction{ID: id, AccountID: accountID, OperationType: operationType, Amount: amount, Balance: outstanding}
+				if err := s.dischargePayment(ctx, tx, payment); err != nil {
+					return err
+				}
+			}
+		}
+
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `UPDATE transactions SET status = $1, overdrawn = $2 WHERE id = $3`, resolution.Status, overdrawn, id)
+		logger.LogDatabase("UPDATE", "transactions", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO transaction_resolutions (id, transaction_id, action, previous_status, status, reason, resolved_by, resolved_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, resolution.Id, id, action, resolution.PreviousStatus, resolution.Status, reason, operator, resolution.ResolvedAt)
+		logger.LogDatabase("INSERT", "transaction_resolutions", time.Since(start), err)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Stuck transaction resolved: ID=%s, Action=%s, Status=%s, Operator=%s", id, action, resolution.Status, operator)
+	return resolution, nil
+}
@@ -0,0 +1,142 @@
+package transaction
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+)
+
+// Defaults of the per-account throttling of transaction creation: a sustained rate in transactions per second,
+// and the burst credits an account accumulates while below it.
+const (
+	DefaultAccountTransactionRate  = 10
+	DefaultAccountTransactionBurst = 100
+)
+
+// Keys of the runtime configuration's rate_limits section for the per-account throttling.
+const (
+	rateLimitPerAccountKey      = "per_account"
+	rateLimitPerAccountBurstKey = "per_account_burst"
+)
+
+// errAccountRateLimited is the error of a transaction refused by the per-account throttling.
+const errAccountRateLimited = "account rate limit exceeded"
+
+// maxThrottledAccounts bounds the number of accounts tracked before those whose credits are full again are evicted.
+const maxThrottledAccounts = 100000
+
+// accountCredits are the burst credits of one account, refilled continuously at the sustained rate.
+type accountCredits struct {
+	credits float64
+	last    time.Time
+}
+
+// AccountThrottle limits how fast transactions are created on each account, so a client stuck in a loop cannot
+// flood one account's ledger, and the database with it. It is independent of the rate limits on callers at the
+// edge: an account creating transactions through many callers is throttled all the same. Each account earns
+// credits at the sustained rate up to the burst, and every transaction spends one, so an account that has been
+// quiet can briefly go faster than the sustained rate. A rate of zero disables the throttling. Limits are
+// per replica. It is safe for concurrent use.
+type AccountThrottle struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    float64
+	accounts map[string]*accountCredits
+	now      func() time.Time
+}
+
+// SetAccountThrottle throttles the transactions created on each account with t: through CreateTransaction,
+// ProcessPayment and streamed ingestion, and transfers on their source account. Simulations are not throttled.
+func (s *Service) SetAccountThrottle(t *AccountThrottle) {
+	s.throttle = t
+}
+
+// NewAccountThrottle creates a throttle with the given sustained rate and burst; a burst below one is raised to one.
+func NewAccountThrottle(rate, burst float64) *AccountThrottle {
+	t := &AccountThrottle{
+		accounts: make(map[string]*accountCredits),
+		now:      time.Now,
+	}
+	t.SetRates(rate, burst)
+	return t
+}
+
+// NewAccountThrottleFromEnv creates a throttle from ACCOUNT_TRANSACTION_RATE and ACCOUNT_TRANSACTION_BURST,
+// defaulting to DefaultAccountTransactionRate and DefaultAccountTransactionBurst.
+func NewAccountThrottleFromEnv() *AccountThrottle {
+	rate, err := strconv.ParseFloat(os.Getenv("ACCOUNT_TRANSACTION_RATE"), 64)
+	if err != nil || rate < 0 {
+		rate = DefaultAccountTransactionRate
+	}
+	burst, err := strconv.ParseFloat(os.Getenv("ACCOUNT_TRANSACTION_BURST"), 64)
+	if err != nil || burst < 0 {
+		burst = DefaultAccountTransactionBurst
+	}
+	return NewAccountThrottle(rate, burst)
+}
+
+// SetRates replaces the sustained rate and burst, e.g. after a runtime config reload. Accounts keep their
+// credits, capped at the new burst.
+func (t *AccountThrottle) SetRates(rate, burst float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rate = rate
+	t.burst = math.Max(1, burst)
+}
+
+// Rates returns the current sustained rate and burst.
+func (t *AccountThrottle) Rates() (float64, float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rate, t.burst
+}
+
+// ApplyRuntimeConfig updates the rates from the per_account and per_account_burst entries of the runtime
+// configuration's rate_limits, keeping current values for unset keys.
+func (t *AccountThrottle) ApplyRuntimeConfig(config *common.RuntimeConfig) {
+	rate, burst := t.Rates()
+	t.SetRates(
+		config.RateLimit(rateLimitPerAccountKey, rate),
+		config.RateLimit(rateLimitPerAccountBurstKey, burst),
+	)
+}
+
+// Allow reports whether a transaction may be created on the account, spending one of its credits when it may.
+func (t *AccountThrottle) Allow(accountID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.rate <= 0 {
+		return true
+	}
+	now := t.now()
+	account, ok := t.accounts[accountID]
+	if !ok {
+		if len(t.accounts) >= maxThrottledAccounts {
+			t.evictFull(now)
+		}
+		account = &accountCredits{credits: t.burst, last: now}
+		t.accounts[accountID] = account
+	}
+	account.credits = math.Min(t.burst, account.credits+now.Sub(account.last).Seconds()*t.rate)
+	account.last = now
+	if account.credits < 1 {
+		return false
+	}
+	account.credits--
+	return true
+}
+
+// evictFull drops the accounts idle long enough for their credits to be full again, which a new entry restores.
+func (t *AccountThrottle) evictFull(now time.Time) {
+	refill := time.Duration(t.burst / t.rate * float64(time.Second))
+	for id, account := range t.accounts {
+		if now.Sub(account.last) >= refill {
+			delete(t.accounts, id)
+		}
+	}
+}
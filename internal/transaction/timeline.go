@@ -0,0 +1,130 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// Types of the events of a transaction timeline.
+const (
+	timelineCreated       = "CREATED"
+	timelineEdited        = "EDITED"
+	timelineDisputeOpened = "DISPUTE_OPENED"
+)
+
+// GetTransactionTimeline returns a transaction with the records linked to it, i.e. its edits and dispute,
+// as events in chronological order for support investigations. Events at the same time keep the order
+// in which the records were made, with the creation of the transaction always first.
+// Only support and admin operators may read timelines, as edits identify the operators who made them.
+func (s *Service) GetTransactionTimeline(ctx context.Context, req *pb.GetTransactionTimelineRequest) (*pb.GetTransactionTimelineResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.SupportOrAdmin) {
+		logger.Warn("Rejected transaction timeline: ID=%s, Role=%q", req.Id, common.CallerRoleFromContext(ctx))
+		return &pb.GetTransactionTimelineResponse{Error: "permission denied"}, nil
+	}
+	if req.Id == "" {
+		return &pb.GetTransactionTimelineResponse{Error: "id required"}, nil
+	}
+
+	start := time.Now()
+	dbTransaction, err := scanTransaction(s.db.QueryRowContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions WHERE id = $1
+	`, req.Id))
+	logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+	if err == sql.ErrNoRows {
+		return &pb.GetTransactionTimelineResponse{Error: "not found"}, nil
+	}
+	if err != nil {
+		logger.Error("Transaction lookup failed: %v", err)
+		return &pb.GetTransactionTimelineResponse{Error: "database error"}, nil
+	}
+
+	events := []*pb.TimelineEvent{{Type: timelineCreated, OccurredAt: dbTransaction.CreatedAt}}
+
+	edits, err := s.transactionEdits(ctx, req.Id)
+	if err != nil {
+		logger.Error("Transaction edits lookup failed: ID=%s, Error=%v", req.Id, err)
+		return &pb.GetTransactionTimelineResponse{Error: "database error"}, nil
+	}
+	for _, edit := range edits {
+		events = append(events, &pb.TimelineEvent{Type: timelineEdited, OccurredAt: edit.EditedAt, Edit: edit})
+	}
+
+	var dispute pb.Dispute
+	start = time.Now()
+	err = s.db.QueryRowContext(ctx, `
+		SELECT id, transaction_id, account_id, amount, reason_code, status, opened_at
+		FROM disputes WHERE transaction_id = $1
+	`, req.Id).Scan(&dispute.Id, &dispute.TransactionId, &dispute.AccountId, (*common.Cents)(&dispute.AmountCents),
+		&dispute.ReasonCode, &dispute.Status, &dispute.OpenedAt)
+	logger.LogDatabase("SELECT", "disputes", time.Since(start), err)
+	switch {
+	case err == nil:
+		events = append(events, &pb.TimelineEvent{Type: timelineDisputeOpened, OccurredAt: dispute.OpenedAt, Dispute: &dispute})
+	case err != sql.ErrNoRows:
+		logger.Error("Dispute lookup failed: ID=%s, Error=%v", req.Id, err)
+		return &pb.GetTransactionTimelineResponse{Error: "database error"}, nil
+	}
+
+	linked := events[1:]
+	sort.SliceStable(linked, func(i, j int) bool {
+		return linked[i].OccurredAt < linked[j].OccurredAt
+	})
+
+	return &pb.GetTransactionTimelineResponse{
+		Transaction: ConvertTransactionToProto(dbTransaction),
+		Events:      events,
+	}, nil
+}
+
+// transactionEdits returns the recorded edits of a transaction, oldest first.
+func (s *Service) transactionEdits(ctx context.Context, transactionID string) ([]*pb.TransactionEdit, error) {
+	logger := s.logger.WithContext(ctx)
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, edited_by, edited_at, previous, updated
+		FROM transaction_edits WHERE transaction_id = $1
+		ORDER BY edited_at, id
+	`, transactionID)
+	logger.LogDatabase("SELECT", "transaction_edits", time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edits []*pb.TransactionEdit
+	for rows.Next() {
+		var edit pb.TransactionEdit
+		var previous, updated []byte
+		if err := rows.Scan(&edit.Id, &edit.EditedBy, &edit.EditedAt, &previous, &updated); err != nil {
+			return nil, err
+		}
+		if edit.Previous, err = decodeEditValues(previous); err != nil {
+			return nil, fmt.Errorf("invalid edit %s: %w", edit.Id, err)
+		}
+		if edit.Updated, err = decodeEditValues(updated); err != nil {
+			return nil, fmt.Errorf("invalid edit %s: %w", edit.Id, err)
+		}
+		edits = append(edits, &edit)
+	}
+	return edits, rows.Err()
+}
+
+// decodeEditValues converts editable fields recorded in transaction_edits to their protobuf form.
+func decodeEditValues(data []byte) (*pb.TransactionEditValues, error) {
+	var values editableFields
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return &pb.TransactionEditValues{Description: values.Description, Tags: values.Tags, Metadata: values.Metadata}, nil
+}
@@ -0,0 +1,192 @@
+package transaction
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation scope of the money movement spans.
+const tracerName = "github.com/YASHIRAI/pismo-task/internal/transaction"
+
+// DefaultTraceSampleRatio is the share of money movements traced unless OTEL_TRACES_SAMPLER_ARG sets another.
+const DefaultTraceSampleRatio = 0.1
+
+// balanceMutationEvent is the span event recorded for each change to an account balance.
+const balanceMutationEvent = "balance.mutation"
+
+// Attributes of the money movement spans and their balance mutation events.
+const (
+	accountIDAttribute     = attribute.Key("account.id")
+	transactionIDAttribute = attribute.Key("transaction.id")
+	requestIDAttribute     = attribute.Key("request.id")
+	amountAttribute        = attribute.Key("amount_cents")
+	balanceBeforeAttribute = attribute.Key("balance.before_cents")
+	balanceAfterAttribute  = attribute.Key("balance.after_cents")
+	// resolutionActionAttribute is the action of an operator resolving a stuck transaction
+	resolutionActionAttribute = attribute.Key("resolution.action")
+	// reviewDecisionAttribute is the decision of an operator reviewing a flagged transaction
+	reviewDecisionAttribute = attribute.Key("review.decision")
+	// batchSizeAttribute is the number of requests of a transaction batch
+	batchSizeAttribute = attribute.Key("batch.size")
+	// forensicAttribute marks the spans exported whatever the sampling decision, see ForensicSpanProcessor
+	forensicAttribute = attribute.Key("money_movement.forensic")
+)
+
+// TraceSampleRatioFromEnv returns the share of money movements to trace, from OTEL_TRACES_SAMPLER_ARG
+// (default DefaultTraceSampleRatio). Failed and reversing money movements are traced regardless.
+func TraceSampleRatioFromEnv() float64 {
+	if ratio, err := strconv.ParseFloat(os.Getenv("OTEL_TRACES_SAMPLER_ARG"), 64); err == nil && ratio >= 0 && ratio <= 1 {
+		return ratio
+	}
+	return DefaultTraceSampleRatio
+}
+
+// NewTracerProvider creates a tracer provider exporting the money movement spans of the service with the
+// OTLP/HTTP exporter, which is configured by the standard OTEL_EXPORTER_OTLP_* environment variables. ratio of
+// the traces is sampled, see MoneyMovementSampler; the failed and reversing money movements among the others
+// are exported too, see ForensicSpanProcessor. release is reported as the service.version of the resource.
+func NewTracerProvider(ctx context.Context, serviceName, release string, ratio float64) (*sdktrace.TracerProvider, error) {
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName), semconv.ServiceVersion(release))),
+		sdktrace.WithSampler(MoneyMovementSampler(ratio)),
+		sdktrace.WithSpanProcessor(NewForensicSpanProcessor(sdktrace.NewBatchSpanProcessor(exporter))),
+	), nil
+}
+
+// MoneyMovementSampler returns a sampler that samples ratio of the root spans, and follows the decision of the
+// parent for the others. Spans it does not sample are still recorded, so that ForensicSpanProcessor can export
+// them once they turn out to be failed or reversing money movements, which cannot be known when they start.
+func MoneyMovementSampler(ratio float64) sdktrace.Sampler {
+	return recordingSampler{sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))}
+}
+
+// recordingSampler records the spans its base sampler drops.
+type recordingSampler struct {
+	base sdktrace.Sampler
+}
+
+func (r recordingSampler) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := r.base.ShouldSample(params)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+func (r recordingSampler) Description() string {
+	return "MoneyMovementSampler{" + r.base.Description() + "}"
+}
+
+// ForensicSpanProcessor passes sampled spans on to its next processor, and of the spans that were recorded but
+// not sampled, those with an error status or marked forensic, i.e. failed and reversing money movements, as if
+// they had been sampled. Only the span itself is exported: its unsampled parent and children are not.
+type ForensicSpanProcessor struct {
+	next sdktrace.SpanProcessor
+}
+
+// NewForensicSpanProcessor creates a processor passing the spans to export on to next.
+func NewForensicSpanProcessor(next sdktrace.SpanProcessor) *ForensicSpanProcessor {
+	return &ForensicSpanProcessor{next: next}
+}
+
+func (p *ForensicSpanProcessor) OnStart(ctx context.Context, span sdktrace.ReadWriteSpan) {
+	p.next.OnStart(ctx, span)
+}
+
+func (p *ForensicSpanProcessor) OnEnd(span sdktrace.ReadOnlySpan) {
+	if !span.SpanContext().IsSampled() {
+		if !isForensic(span) {
+			return
+		}
+		span = sampledSpan{span}
+	}
+	p.next.OnEnd(span)
+}
+
+func (p *ForensicSpanProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *ForensicSpanProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// isForensic reports whether span is exported whatever the sampling decision.
+func isForensic(span sdktrace.ReadOnlySpan) bool {
+	if span.Status().Code == codes.Error {
+		return true
+	}
+	for _, attr := range span.Attributes() {
+		if attr.Key == forensicAttribute && attr.Value.AsBool() {
+			return true
+		}
+	}
+	return false
+}
+
+// sampledSpan is a span promoted to sampled by ForensicSpanProcessor.
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
+
+// SetTracerProvider makes the service trace its money movements with a tracer of provider: each operation that
+// changes balances gets a span with a balance mutation event per change, carrying the account, operation type,
+// amount and the balance before and after it.
+func (s *Service) SetTracerProvider(provider trace.TracerProvider) {
+	s.tracer = provider.Tracer(tracerName)
+}
+
+// startMoneyMovement starts the span of a money movement, a child of the span of ctx if any. It returns a
+// non-recording span when tracing is not enabled.
+func (s *Service) startMoneyMovement(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if s.tracer == nil {
+		return ctx, noop.Span{}
+	}
+	if requestID := common.RequestIDFromContext(ctx); requestID != "" {
+		attributes = append(attributes, requestIDAttribute.String(requestID))
+	}
+	return s.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(attributes...))
+}
+
+// recordBalanceMutation adds the event of a change of amount to the balance of an account to span.
+func recordBalanceMutation(span trace.Span, accountID, transactionID, operationType string, amount, before common.Cents) {
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent(balanceMutationEvent, trace.WithAttributes(
+		accountIDAttribute.String(accountID),
+		transactionIDAttribute.String(transactionID),
+		operationTypeAttribute.String(operationType),
+		amountAttribute.Int64(int64(amount)),
+		balanceBeforeAttribute.Int64(int64(before)),
+		balanceAfterAttribute.Int64(int64(before+amount)),
+	))
+}
+
+// endMoneyMovement ends span, with an error status if the money movement failed with failure.
+func endMoneyMovement(span trace.Span, failure string) {
+	if failure != "" {
+		span.SetStatus(codes.Error, failure)
+	}
+	span.End()
+}
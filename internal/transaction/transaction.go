@@ -3,243 +3,830 @@ package transaction
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/YASHIRAI/pismo-task/internal/common"
+	"github.com/YASHIRAI/pismo-task/internal/fx"
 	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/proto"
 )
 
 // Service implements the TransactionService gRPC server.
 // It handles all transaction-related operations including creation, retrieval, and payment processing.
 type Service struct {
 	pb.UnimplementedTransactionServiceServer
-	db     *sql.DB
-	logger *common.Logger
+	db               *sql.DB
+	logger           *common.Logger
+	accountLocks     *common.KeyedMutex
+	missingAccounts  *common.NegativeCache
+	pageTokens       *common.PageTokenSigner
+	tenants          *common.TenantConfigStore
+	webhookKeys      *common.WebhookKeyStore
+	messageTemplates *common.MessageTemplateStore
+	rules            *operationRuleSet
+	exportWorkers    int
+	eventOutbox      bool
+	kpis             *common.BusinessMetrics
+	events           *businessEvents
+	risk             RiskPolicy
+	throttle         *AccountThrottle
+	slo              *common.SLOTracker
+	historyLimits    *historyPageLimiter
+	rates            fx.RateProvider
+	tracer           trace.Tracer
 }
 
+// aggregationWindows maps each supported AggregateTransactions bucket size to the
+// time range aggregated when the request does not specify one.
+var aggregationWindows = map[string]time.Duration{
+	"day":   30 * 24 * time.Hour,
+	"week":  12 * 7 * 24 * time.Hour,
+	"month": 365 * 24 * time.Hour,
+}
+
+// maxAggregationRange bounds the time range of a single AggregateTransactions request.
+const maxAggregationRange = 2 * 366 * 24 * time.Hour
+
+// secondsPerDay is the granularity of the transaction_daily_rollups table.
+const secondsPerDay = 24 * 60 * 60
+
+// errExternalIDAlreadyUsed is the error of a request whose external_id another transaction already has.
+const errExternalIDAlreadyUsed = "external_id already used"
+
+// maxExternalIDLength is the size of the transactions.external_id column.
+const maxExternalIDLength = 64
+
+// Bounds of the description search of GetTransactionHistory; shorter terms cannot use the trigram index.
+const (
+	minSearchLength = 3
+	maxSearchLength = 100
+)
+
 // NewService creates a new instance of the Transaction service.
 // It takes a database connection and logger, and returns a configured Service instance.
+// Missing account lookups are cached for NEGATIVE_CACHE_TTL to shield the database from invalid IDs,
+// tenant settings for TENANT_CONFIG_TTL and webhook signing keys for WEBHOOK_KEYS_TTL.
+// History pages are kept within HISTORY_PAGE_MAX_BYTES and HISTORY_PAGE_MAX_LATENCY.
+// The default operation type rules apply until LoadOperationRules is called.
 func NewService(db *sql.DB, logger *common.Logger) *Service {
-	return &Service{db: db, logger: logger}
+	return &Service{
+		db:               db,
+		logger:           logger,
+		accountLocks:     common.NewKeyedMutex(),
+		missingAccounts:  common.NewNegativeCacheFromEnv(),
+		pageTokens:       common.NewPageTokenSignerFromEnv(),
+		tenants:          common.NewTenantConfigStoreFromEnv(db),
+		webhookKeys:      common.NewWebhookKeyStoreFromEnv(db),
+		messageTemplates: common.NewMessageTemplateStoreFromEnv(db),
+		rules:            newOperationRuleSet(defaultOperationRules()),
+		exportWorkers:    DefaultExportWorkers,
+		historyLimits:    newHistoryPageLimiterFromEnv(),
+	}
 }
 
 // CreateTransaction creates a new transaction and processes it based on the operation type.
-// It validates the operation type, checks account existence, and updates account balance.
-// For PAYMENT operations, it adds to the balance; for other operations, it debits the balance.
+// It validates the request against the operation type's rule and the tenant's settings, checks account existence,
+// and updates account balance. The rule decides whether the operation credits or debits the balance,
+// whether the amount must be positive and which account types it may be applied to.
+// Only ACTIVE accounts, i.e. ones that completed onboarding, can transact.
+// Operations on the same account are serialized so the balance check and update are applied in order.
+// When simulate is set the same checks run but nothing is written, and the resulting balance is returned.
+// A category is kept as the transaction's category metadata entry; completed debits count against the account's
+// budget for it.
+// A completed payment discharges the outstanding balance of the account's purchases and withdrawals, oldest first;
+// its own balance is the remainder.
+// A transaction that names a batch item is linked to it; a failed item is recorded on the batch until it is applied.
 // Returns the created transaction or an error if processing fails.
 func (s *Service) CreateTransaction(ctx context.Context, req *pb.CreateTransactionRequest) (*pb.CreateTransactionResponse, error) {
-	s.logger.Info("Creating transaction: AccountID=%s, OperationType=%s, Amount=%f",
-		req.AccountId, req.OperationType, req.Amount)
+	return s.createTransaction(ctx, req, nil, nil)
+}
+
+// scheduledRun is a transaction made on schedule by a worker, such as the run of a recurring rule or a due
+// installment, recorded within the database transaction writing it.
+type scheduledRun interface {
+	// record links the transaction made by the run to what scheduled it; held is set if the transaction was held
+	// for review. It returns errRunNotDue if the run was cancelled, or made elsewhere, since it was found due.
+	record(ctx context.Context, tx *sql.Tx, logger *common.Logger, transactionID string, held bool) error
+}
+
+// errRunNotDue is the error of a scheduled run that is no longer due.
+var errRunNotDue = errors.New("run no longer due")
+
+// createTransaction creates a transaction as CreateTransaction does. A payment converted from another currency
+// keeps the amount and currency paid and the rate applied in conversion. A transaction made by a scheduled run is
+// linked to it, and is not written if the run is no longer due.
+func (s *Service) createTransaction(ctx context.Context, req *pb.CreateTransactionRequest, conversion *paymentConversion, run scheduledRun) (resp *pb.CreateTransactionResponse, err error) {
+	// Simulations move no money, so they get no span
+	var span trace.Span = noop.Span{}
+	if !req.Simulate {
+		ctx, span = s.startMoneyMovement(ctx, "transaction.create", accountIDAttribute.String(req.AccountId),
+			operationTypeAttribute.String(req.OperationType), amountAttribute.Int64(req.AmountCents))
+	}
+	logger := s.logger.WithContext(ctx)
+	if !req.Simulate {
+		defer func() {
+			endMoneyMovement(span, resp.GetError())
+			s.recordAuthorization(ctx, req.OperationType, resp.Error)
+			if failure, ok := batchFailureOf(req, resp.Error); ok {
+				s.recordBatchFailures(ctx, []batchFailure{failure})
+			}
+		}()
+	}
+
+	logger.Info("Creating transaction: AccountID=%s, OperationType=%s, Amount=%s",
+		req.AccountId, req.OperationType, common.Cents(req.AmountCents))
 
 	if req.AccountId == "" || req.OperationType == "" {
-		s.logger.Error("Transaction creation failed: missing required fields")
-		return &pb.CreateTransactionResponse{Error: "missing required fields"}, nil
+		logger.Error("Transaction creation failed: missing required fields")
+		return &pb.CreateTransactionResponse{Error: "missing required fields", Simulated: req.Simulate}, nil
+	}
+	if len(req.ExternalId) > maxExternalIDLength {
+		return &pb.CreateTransactionResponse{Error: "external_id too long", Simulated: req.Simulate}, nil
+	}
+	if req.Category != "" && !validCategory(req.Category) {
+		return &pb.CreateTransactionResponse{Error: "invalid category", Simulated: req.Simulate}, nil
+	}
+	if msg := validateBatchItem(req); msg != "" {
+		return &pb.CreateTransactionResponse{Error: msg, Simulated: req.Simulate}, nil
+	}
+	if msg := validateInstallmentCount(req); msg != "" {
+		return &pb.CreateTransactionResponse{Error: msg, Simulated: req.Simulate}, nil
+	}
+
+	rule, ok := s.rules.get(req.OperationType)
+	if !ok {
+		logger.Error("Transaction creation failed: invalid operation type: %s", req.OperationType)
+		return &pb.CreateTransactionResponse{Error: "invalid operation type", Simulated: req.Simulate}, nil
+	}
+
+	if msg := s.checkTenantPolicy(ctx, req); msg != "" {
+		logger.Error("Transaction creation failed: %s: TenantID=%s", msg, common.TenantIDFromContext(ctx))
+		return &pb.CreateTransactionResponse{Error: msg, Simulated: req.Simulate}, nil
 	}
 
-	validOperations := map[string]bool{
-		"CASH_PURCHASE":        true,
-		"INSTALLMENT_PURCHASE": true,
-		"WITHDRAWAL":           true,
-		"PAYMENT":              true,
+	if s.missingAccounts.Contains(req.AccountId) {
+		logger.Error("Account not found for transaction (cached): ID=%s", req.AccountId)
+		return &pb.CreateTransactionResponse{Error: "account not found", Simulated: req.Simulate}, nil
 	}
-	if !validOperations[req.OperationType] {
-		s.logger.Error("Transaction creation failed: invalid operation type: %s", req.OperationType)
-		return &pb.CreateTransactionResponse{Error: "invalid operation type"}, nil
+
+	if req.Simulate {
+		return s.simulateTransaction(ctx, req, rule), nil
+	}
+
+	if s.throttle != nil && !s.throttle.Allow(req.AccountId) {
+		logger.Warn("Transaction creation throttled: AccountID=%s", req.AccountId)
+		return &pb.CreateTransactionResponse{Error: errAccountRateLimited}, nil
 	}
 
+	unlock, err := s.accountLocks.Lock(ctx, req.AccountId)
+	if err != nil {
+		logger.Error("Transaction creation aborted while waiting for account lock: ID=%s, Error=%v", req.AccountId, err)
+		return &pb.CreateTransactionResponse{Error: "request cancelled"}, nil
+	}
+	defer unlock()
+
 	var account common.Account
 	start := time.Now()
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, document_number, account_type, balance, created_at, updated_at
+	err = s.db.QueryRowContext(ctx, `
+		SELECT id, document_number, account_type, balance, created_at, updated_at, status, overdraft_limit
 		FROM accounts WHERE id = $1
-	`, req.AccountId).Scan(&account.ID, &account.DocumentNumber, &account.AccountType, &account.Balance, &account.CreatedAt, &account.UpdatedAt)
+	`, req.AccountId).Scan(&account.ID, &account.DocumentNumber, &account.AccountType, &account.Balance, &account.CreatedAt, &account.UpdatedAt, &account.Status, &account.OverdraftLimit)
 	duration := time.Since(start)
 
-	s.logger.LogDatabase("SELECT", "accounts", duration, err)
+	logger.LogDatabase("SELECT", "accounts", duration, err)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			s.logger.Error("Account not found for transaction: ID=%s", req.AccountId)
+			logger.Error("Account not found for transaction: ID=%s", req.AccountId)
+			s.missingAccounts.Add(req.AccountId)
 			return &pb.CreateTransactionResponse{Error: "account not found"}, nil
 		}
-		s.logger.Error("Account check failed: %v", err)
+		logger.Error("Account check failed: %v", err)
 		return &pb.CreateTransactionResponse{Error: "database error"}, nil
 	}
 
+	if account.Status != "ACTIVE" {
+		return &pb.CreateTransactionResponse{Error: "account not active"}, nil
+	}
+	if msg := rule.CheckAmount(common.Cents(req.AmountCents)); msg != "" {
+		return &pb.CreateTransactionResponse{Error: msg}, nil
+	}
+	if !rule.AllowsAccountType(account.AccountType) {
+		return &pb.CreateTransactionResponse{Error: "operation type not allowed for account type"}, nil
+	}
+
+	// An installment purchase debits its first installment now, and the others when due
+	installments := installmentAmounts(common.Cents(req.AmountCents), req.InstallmentCount)
+	amount := rule.SignedAmount(installments[0])
+	if exceedsOverdraft(account.Balance, amount, account.OverdraftLimit) {
+		return &pb.CreateTransactionResponse{Error: "insufficient balance"}, nil
+	}
+
 	dbTransaction := ConvertCreateTransactionRequestToTransaction(req)
 	dbTransaction.ID = uuid.New().String()
-	status := "PENDING"
+	dbTransaction.Amount = amount
+	dbTransaction.Status = "COMPLETED"
+	if conversion != nil {
+		dbTransaction.OriginalCurrency = conversion.currency
+		dbTransaction.OriginalAmount = conversion.amount
+		dbTransaction.FXRate = conversion.rate
+	}
 
-	if req.OperationType == "PAYMENT" {
-		if req.Amount <= 0 {
-			return &pb.CreateTransactionResponse{Error: "payment amount must be positive"}, nil
+	// The balance update and the transaction record are written together, so a client that
+	// disconnects half-way cannot leave a balance change without its transaction.
+	// Debits flagged by fraud scoring are recorded UNDER_REVIEW without changing the balance.
+	// The account lock only serializes requests within this instance, so the update re-checks the balance
+	// in the database and a debit that another instance made insufficient rolls back instead of exceeding the
+	// overdraft limit. It returns the new balance, which tells whether the debit went into the overdraft.
+	failure := "could not create transaction"
+	var discharges []*pb.Discharge
+	err = common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		if req.BatchId != "" {
+			batchItems, err := s.lookupBatchItems(ctx, tx, []*pb.CreateTransactionRequest{req})
+			if err != nil {
+				return fmt.Errorf("batch item check failed: %w", err)
+			}
+			if msg := batchItems.check(req); msg != "" {
+				failure = msg
+				return errors.New(msg)
+			}
 		}
 
-		start = time.Now()
-		_, err = s.db.ExecContext(ctx, `
-			UPDATE accounts 
-			SET balance = balance + $1, updated_at = $2 
-			WHERE id = $3
-		`, req.Amount, common.GetCurrentTimestamp(), req.AccountId)
-		duration = time.Since(start)
+		var review *transactionReview
+		if s.risk.Enabled() && amount < 0 {
+			recent, err := s.countRecentDebits(ctx, tx, []string{req.AccountId})
+			if err != nil {
+				return fmt.Errorf("risk scoring failed: %w", err)
+			}
+			review = s.screenTransaction(dbTransaction, account.Balance, recent[req.AccountId])
+		}
+
+		if review == nil {
+			var balance common.Cents
+			start := time.Now()
+			err := tx.QueryRowContext(ctx, `
+				UPDATE accounts 
+				SET balance = balance + $1, updated_at = $2 
+				WHERE id = $3 AND ($1 >= 0 OR balance + $1 >= -overdraft_limit)
+				RETURNING balance
+			`, amount, common.GetCurrentTimestamp(), req.AccountId).Scan(&balance)
+			logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+			if err == sql.ErrNoRows {
+				failure = "insufficient balance"
+				return errors.New("balance changed concurrently")
+			}
+			if err != nil {
+				if req.OperationType == "PAYMENT" {
+					failure = "could not process payment"
+				} else {
+					failure = "could not process transaction"
+				}
+				return fmt.Errorf("balance update failed: %w", err)
+			}
+			recordBalanceMutation(span, req.AccountId, dbTransaction.ID, req.OperationType, amount, balance-amount)
+			dbTransaction.Overdrawn = amount < 0 && balance < 0
+		}
+
+		// A payment first discharges the account's outstanding purchases and withdrawals, oldest first
+		dbTransaction.Balance = initialBalance(dbTransaction)
+		discharges = nil
+		if review == nil && req.OperationType == "PAYMENT" {
+			discharges, dbTransaction.Balance, err = s.dischargeDebts(ctx, tx, req.AccountId, amount)
+			if err != nil {
+				failure = "could not process payment"
+				return err
+			}
+		}
 
-		s.logger.LogDatabase("UPDATE", "accounts", duration, err)
+		metadata, err := encodeMetadata(dbTransaction.Metadata)
 		if err != nil {
-			s.logger.Error("Balance update failed for payment: %v", err)
-			return &pb.CreateTransactionResponse{Error: "could not process payment"}, nil
+			return err
 		}
-		status = "COMPLETED"
-	} else {
-		amount := req.Amount
-		if amount >= 0 {
-			amount = -amount
+		start := time.Now()
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO transactions (id, account_id, operation_type, amount, description, created_at, status, external_id, metadata, balance, overdrawn,
+				original_currency, original_amount, fx_rate)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, NULLIF($12, ''), NULLIF($13::numeric, 0), NULLIF($14::numeric, 0))
+		`, dbTransaction.ID, dbTransaction.AccountID, dbTransaction.OperationType, dbTransaction.Amount, dbTransaction.Description, dbTransaction.CreatedAt, dbTransaction.Status, dbTransaction.ExternalID, metadata, dbTransaction.Balance, dbTransaction.Overdrawn,
+			dbTransaction.OriginalCurrency, dbTransaction.OriginalAmount, dbTransaction.FXRate)
+		logger.LogDatabase("INSERT", "transactions", time.Since(start), err)
+		if common.IsUniqueViolation(err) {
+			// Only external_id is unique besides the generated ID
+			failure = errExternalIDAlreadyUsed
+		}
+		if common.IsBusinessDayClosed(err) {
+			// Created as its day ended, and written after the end-of-day close sealed it
+			failure = errBusinessDayClosed
+		}
+		if err != nil {
+			return fmt.Errorf("transaction insert failed: %w", err)
+		}
+		if err := s.recordDischarges(ctx, tx, dbTransaction.ID, discharges); err != nil {
+			failure = "could not process payment"
+			return fmt.Errorf("discharges insert failed: %w", err)
+		}
+		if req.BatchId != "" {
+			err := s.insertBatchItems(ctx, tx, []batchItem{{batchItemKey{req.BatchId, req.BatchItem}, dbTransaction.ID}})
+			if common.IsUniqueViolation(err) {
+				failure = errBatchItemAlreadyApplied
+			}
+			if err != nil {
+				return fmt.Errorf("batch item insert failed: %w", err)
+			}
+		}
+		if err := s.insertInstallments(ctx, tx, dbTransaction, installments); err != nil {
+			return fmt.Errorf("installments insert failed: %w", err)
+		}
+		if run != nil {
+			err := run.record(ctx, tx, logger, dbTransaction.ID, review != nil)
+			if errors.Is(err, errRunNotDue) {
+				failure = err.Error()
+			}
+			if err != nil {
+				return fmt.Errorf("scheduled run record failed: %w", err)
+			}
 		}
 
-		if account.Balance+amount < 0 {
-			return &pb.CreateTransactionResponse{Error: "insufficient balance"}, nil
+		// Held transactions are published once approved
+		if review != nil {
+			if err := s.insertTransactionReviews(ctx, tx, []*transactionReview{review}); err != nil {
+				return fmt.Errorf("review insert failed: %w", err)
+			}
+			logger.Warn("Transaction held for review: ID=%s, AccountID=%s, RiskScore=%d, RiskFactors=%v",
+				dbTransaction.ID, req.AccountId, review.score, review.factors)
+			return nil
+		}
+		if err := s.enqueueTransactionsCreated(ctx, tx, dbTransaction); err != nil {
+			return fmt.Errorf("event enqueue failed: %w", err)
+		}
+		if err := s.enqueueBudgetThresholdsCrossed(ctx, tx, dbTransaction); err != nil {
+			return fmt.Errorf("budget event enqueue failed: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		if common.IsCancellation(err) {
+			logger.Warn("Transaction creation cancelled by client, changes rolled back: AccountID=%s", req.AccountId)
+			return &pb.CreateTransactionResponse{Error: "request cancelled"}, nil
+		}
+		logger.Error("Transaction creation failed: %v", err)
+		return &pb.CreateTransactionResponse{Error: failure}, nil
+	}
 
-		start = time.Now()
-		_, err = s.db.ExecContext(ctx, `
-			UPDATE accounts 
-			SET balance = balance + $1, updated_at = $2 
-			WHERE id = $3
-		`, amount, common.GetCurrentTimestamp(), req.AccountId)
-		duration = time.Since(start)
+	pbTransaction := ConvertTransactionToProto(dbTransaction)
+	if len(installments) > 1 {
+		pbTransaction.InstallmentPlan = newInstallmentPlan(dbTransaction, installments)
+	}
+	return &pb.CreateTransactionResponse{Transaction: pbTransaction, Discharges: discharges}, nil
+}
 
-		s.logger.LogDatabase("UPDATE", "accounts", duration, err)
-		if err != nil {
-			s.logger.Error("Balance update failed for transaction: %v", err)
-			return &pb.CreateTransactionResponse{Error: "could not process transaction"}, nil
-		}
-		status = "COMPLETED"
-		dbTransaction.Amount = amount
+// SetBusinessMetrics makes the service count the transactions it approves and declines in kpis.
+func (s *Service) SetBusinessMetrics(kpis *common.BusinessMetrics) {
+	s.kpis = kpis
+}
+
+// recordAuthorization counts the outcome of a transaction request in the business metrics and business events,
+// if enabled. Unknown operation types are counted together so callers cannot grow the label set.
+func (s *Service) recordAuthorization(ctx context.Context, operationType, failure string) {
+	if s.kpis == nil && s.events == nil {
+		return
+	}
+	if _, ok := s.rules.get(operationType); !ok {
+		operationType = "UNKNOWN"
 	}
+	s.kpis.RecordAuthorization(operationType, failure)
+	s.events.recordDecline(ctx, operationType, failure)
+}
 
-	dbTransaction.Status = status
-	start = time.Now()
-	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO transactions (id, account_id, operation_type, amount, description, created_at, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`, dbTransaction.ID, dbTransaction.AccountID, dbTransaction.OperationType, dbTransaction.Amount, dbTransaction.Description, dbTransaction.CreatedAt, dbTransaction.Status)
-	duration = time.Since(start)
+// checkTenantPolicy applies the settings of the tenant a request is made for, if any:
+// the tenant's allowed operation types and per-transaction amount cap.
+// Returns an error message, or an empty string if the request is allowed.
+func (s *Service) checkTenantPolicy(ctx context.Context, req *pb.CreateTransactionRequest) string {
+	tenantID := common.TenantIDFromContext(ctx)
+	if tenantID == "" {
+		return ""
+	}
 
-	s.logger.LogDatabase("INSERT", "transactions", duration, err)
+	settings, err := s.tenants.Get(ctx, tenantID)
+	if err == common.ErrInvalidTenantID {
+		return "invalid tenant id"
+	}
 	if err != nil {
-		s.logger.Error("Transaction insert failed: %v", err)
-		return &pb.CreateTransactionResponse{Error: "could not create transaction"}, nil
+		s.logger.WithContext(ctx).Error("Tenant settings lookup failed: TenantID=%s, Error=%v", tenantID, err)
+		return "database error"
 	}
 
-	pbTransaction := ConvertTransactionToProto(dbTransaction)
-	return &pb.CreateTransactionResponse{Transaction: pbTransaction}, nil
+	if !settings.AllowsOperationType(req.OperationType) {
+		return "operation type not allowed for tenant"
+	}
+	if settings.ExceedsMaxAmount(common.Cents(req.AmountCents)) {
+		return "amount exceeds tenant limit"
+	}
+	return ""
 }
 
-// GetTransaction retrieves a transaction by its ID.
-// Returns the transaction details or an error if the transaction is not found.
+// exceedsOverdraft reports whether amount is a debit that would take balance below the account's overdraft limit.
+// Accounts without an overdraft have a limit of 0, so their debits must not take the balance below zero.
+func exceedsOverdraft(balance, amount, overdraftLimit common.Cents) bool {
+	return amount < 0 && balance+amount < -overdraftLimit
+}
+
+// IngestTransactions processes a bidirectional stream of transactions.
+// Each request is acknowledged with a result carrying its zero-based position in the stream,
+// so callers can correlate acks without per-call overhead. Requests that are already queued
+// on the stream are applied together as a batch to cut database round trips; a caller that
+// waits for each ack before sending the next request simply gets batches of one.
+func (s *Service) IngestTransactions(stream pb.TransactionService_IngestTransactionsServer) error {
+	ctx := stream.Context()
+	logger := s.logger.WithContext(ctx)
+
+	type received struct {
+		req *pb.CreateTransactionRequest
+		err error
+	}
+	incoming := make(chan received, maxIngestBatchSize)
+	go func() {
+		defer close(incoming)
+		for {
+			req, err := stream.Recv()
+			select {
+			case incoming <- received{req: req, err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var index int32
+	for {
+		next, ok := <-incoming
+		if !ok {
+			return ctx.Err()
+		}
+
+		var batch []*pb.CreateTransactionRequest
+		var streamErr error
+	collect:
+		for {
+			if next.err != nil {
+				streamErr = next.err
+				break
+			}
+			batch = append(batch, next.req)
+			if len(batch) == maxIngestBatchSize {
+				break
+			}
+			select {
+			case next, ok = <-incoming:
+				if !ok {
+					break collect
+				}
+			default:
+				break collect
+			}
+		}
+
+		if len(batch) > 0 {
+			results := s.createTransactionBatch(ctx, batch)
+			var failures []batchFailure
+			for i, result := range results {
+				if failure, ok := batchFailureOf(batch[i], result.err); ok {
+					failures = append(failures, failure)
+				}
+			}
+			s.recordBatchFailures(ctx, failures)
+
+			for i, result := range results {
+				s.recordAuthorization(ctx, batch[i].OperationType, result.err)
+				ack := &pb.IngestTransactionResult{Index: index, Error: result.err}
+				if result.transaction != nil {
+					ack.Transaction = ConvertTransactionToProto(result.transaction)
+				}
+				if err := stream.Send(ack); err != nil {
+					logger.Error("Failed to acknowledge ingested transaction %d: %v", index, err)
+					return err
+				}
+				index++
+			}
+		}
+
+		if streamErr == io.EOF {
+			logger.Info("Transaction ingest stream closed after %d transactions", index)
+			return nil
+		}
+		if streamErr != nil {
+			logger.Error("Transaction ingest stream failed: %v", streamErr)
+			return streamErr
+		}
+	}
+}
+
+// GetTransaction retrieves a transaction by its ID or external ID, with its remaining balance: what is left to
+// discharge of a purchase or withdrawal, or of a payment after discharging them. Transactions that are not
+// completed have none. Returns the transaction details or an error if the transaction is not found.
 func (s *Service) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (*pb.GetTransactionResponse, error) {
-	if req.Id == "" {
+	logger := s.logger.WithContext(ctx)
+
+	column, key := "id", req.Id
+	switch {
+	case req.Id != "" && req.ExternalId != "":
+		return &pb.GetTransactionResponse{Error: "only one of id and external_id allowed"}, nil
+	case req.ExternalId != "":
+		column, key = "external_id", req.ExternalId
+	case req.Id == "":
 		return &pb.GetTransactionResponse{Error: "id required"}, nil
 	}
 
-	var dbTransaction common.Transaction
+	var scanned scannedTransaction
 	start := time.Now()
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, account_id, operation_type, amount, description, created_at, status
-		FROM transactions WHERE id = $1
-	`, req.Id).Scan(&dbTransaction.ID, &dbTransaction.AccountID, &dbTransaction.OperationType, &dbTransaction.Amount, &dbTransaction.Description, &dbTransaction.CreatedAt, &dbTransaction.Status)
+		SELECT `+transactionColumns+`, CASE WHEN status = 'COMPLETED' THEN balance ELSE 0 END, overdrawn,
+			COALESCE(original_currency, ''), COALESCE(original_amount, 0), COALESCE(fx_rate, 0)
+		FROM transactions WHERE `+column+` = $1
+	`, key).Scan(append(scanned.dest(), &scanned.transaction.Balance, &scanned.transaction.Overdrawn,
+		&scanned.transaction.OriginalCurrency, &scanned.transaction.OriginalAmount, &scanned.transaction.FXRate)...)
 	duration := time.Since(start)
 
-	s.logger.LogDatabase("SELECT", "transactions", duration, err)
+	logger.LogDatabase("SELECT", "transactions", duration, err)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			s.logger.Warn("Transaction not found: ID=%s", req.Id)
+			logger.Warn("Transaction not found: %s=%s", column, key)
 			return &pb.GetTransactionResponse{Error: "not found"}, nil
 		}
-		s.logger.Error("Transaction lookup failed: %v", err)
+		logger.Error("Transaction lookup failed: %v", err)
+		return &pb.GetTransactionResponse{Error: "database error"}, nil
+	}
+	dbTransaction, err := scanned.decode()
+	if err != nil {
+		logger.Error("Transaction lookup failed: %v", err)
 		return &pb.GetTransactionResponse{Error: "database error"}, nil
 	}
 
-	pbTransaction := ConvertTransactionToProto(&dbTransaction)
+	pbTransaction := ConvertTransactionToProto(dbTransaction)
+	if dbTransaction.OperationType == installmentOperationType {
+		pbTransaction.InstallmentPlan, err = s.getInstallmentPlan(ctx, dbTransaction.ID)
+		if err != nil {
+			logger.Error("Installment plan lookup failed: TransactionID=%s, Error=%v", dbTransaction.ID, err)
+			return &pb.GetTransactionResponse{Error: "database error"}, nil
+		}
+	}
 	return &pb.GetTransactionResponse{Transaction: pbTransaction}, nil
 }
 
 // GetTransactionHistory retrieves paginated transaction history for an account.
-// It supports limit and offset parameters for pagination and returns the total count.
-// Transactions are ordered by creation time in descending order.
+// Pages are addressed by the opaque next_page_token returned with each page, which resumes
+// after the last transaction seen; the legacy offset is only used when no token is given.
+// Transactions are ordered by creation time in descending order and the total count is returned.
+// An optional search narrows the history to transactions whose description contains it, ignoring case;
+// the trigram index on description keeps this fast for accounts with a long history.
+// Unfiltered histories are counted, and offsets located, from the per-month transaction counts.
+// Accounts whose pages are unusually large or slow to read get smaller pages than requested, see
+// historyPageLimiter; the page size used is returned as applied_limit.
 func (s *Service) GetTransactionHistory(ctx context.Context, req *pb.GetTransactionHistoryRequest) (*pb.GetTransactionHistoryResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
 	if req.AccountId == "" {
 		return &pb.GetTransactionHistoryResponse{Error: "account_id required"}, nil
 	}
 
 	limit := req.Limit
-	if limit <= 0 || limit > 100 {
-		limit = 50
+	if limit <= 0 || limit > maxHistoryPageLimit {
+		limit = defaultHistoryPageLimit
+	}
+	if applied := s.historyLimits.Limit(req.AccountId, limit); applied < limit {
+		logger.Info("Transaction history page size reduced: AccountID=%s, Requested=%d, Applied=%d", req.AccountId, limit, applied)
+		limit = applied
 	}
 	offset := req.Offset
 	if offset < 0 {
 		offset = 0
 	}
 
+	where := "account_id = $1"
+	args := []interface{}{req.AccountId}
+	filters := []string{req.AccountId}
+	if search := strings.TrimSpace(req.Search); search != "" {
+		if len(search) < minSearchLength || len(search) > maxSearchLength {
+			return &pb.GetTransactionHistoryResponse{Error: "search must be between 3 and 100 characters"}, nil
+		}
+		args = append(args, "%"+common.EscapeLikePattern(search)+"%")
+		where += fmt.Sprintf(" AND description ILIKE $%d", len(args))
+		filters = append(filters, search)
+	}
+
+	filterHash := common.PageFilterHash(filters...)
+	var cursor *common.PageCursor
+	if req.PageToken != "" {
+		decoded, err := s.pageTokens.Decode(req.PageToken, filterHash)
+		if err != nil {
+			logger.Warn("Rejected page token for transaction history: AccountID=%s, Error=%v", req.AccountId, err)
+			return &pb.GetTransactionHistoryResponse{Error: "invalid page token"}, nil
+		}
+		cursor = &decoded
+	}
+
+	// Without a search the monthly counts give the total, and a legacy offset is turned into a range that starts
+	// in the month holding it, so deep pages skip whole months instead of scanning every transaction before them
 	var total int32
 	start := time.Now()
-	err := s.db.QueryRowContext(ctx, `
-		SELECT COUNT(*) FROM transactions WHERE account_id = $1
-	`, req.AccountId).Scan(&total)
-	duration := time.Since(start)
-
-	s.logger.LogDatabase("SELECT", "transactions", duration, err)
+	var err error
+	if len(filters) == 1 {
+		var months []common.MonthCount
+		months, err = common.LoadMonthCounts(ctx, s.db, req.AccountId)
+		logger.LogDatabase("SELECT", "transaction_monthly_counts", time.Since(start), err)
+		if err == nil {
+			for _, month := range months {
+				total += int32(month.Count)
+			}
+			if cursor == nil && offset > 0 {
+				before, remaining, ok := common.LocateHistoryOffset(months, int64(offset))
+				if !ok {
+					return &pb.GetTransactionHistoryResponse{Total: total, AppliedLimit: limit}, nil
+				}
+				args = append(args, before)
+				where += fmt.Sprintf(" AND created_at < $%d", len(args))
+				offset = int32(remaining)
+			}
+		}
+	} else {
+		err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM transactions WHERE `+where, args...).Scan(&total)
+		logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+	}
 	if err != nil {
-		s.logger.Error("Count query failed: %v", err)
+		logger.Error("Count query failed: %v", err)
 		return &pb.GetTransactionHistoryResponse{Error: "database error"}, nil
 	}
 
-	start = time.Now()
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, account_id, operation_type, amount, description, created_at, status
-		FROM transactions 
-		WHERE account_id = $1 
-		ORDER BY created_at DESC 
-		LIMIT $2 OFFSET $3
-	`, req.AccountId, limit, offset)
-	duration = time.Since(start)
-
-	s.logger.LogDatabase("SELECT", "transactions", duration, err)
+	var rows *sql.Rows
+	pageStart := time.Now()
+	start = pageStart
+	if cursor != nil {
+		rows, err = s.db.QueryContext(ctx, fmt.Sprintf(`
+			SELECT `+transactionColumns+`
+			FROM transactions 
+			WHERE %s AND (created_at, id) < ($%d, $%d)
+			ORDER BY created_at DESC, id DESC 
+			LIMIT $%d
+		`, where, len(args)+1, len(args)+2, len(args)+3), append(args, cursor.CreatedAt, cursor.ID, limit)...)
+	} else {
+		rows, err = s.db.QueryContext(ctx, fmt.Sprintf(`
+			SELECT `+transactionColumns+`
+			FROM transactions 
+			WHERE %s 
+			ORDER BY created_at DESC, id DESC 
+			LIMIT $%d OFFSET $%d
+		`, where, len(args)+1, len(args)+2), append(args, limit, offset)...)
+	}
+	duration := time.Since(start)
+
+	logger.LogDatabase("SELECT", "transactions", duration, err)
 	if err != nil {
-		s.logger.Error("Transactions query failed: %v", err)
+		logger.Error("Transactions query failed: %v", err)
 		return &pb.GetTransactionHistoryResponse{Error: "database error"}, nil
 	}
 	defer rows.Close()
 
 	var transactions []*pb.Transaction
+	var size int
 	for rows.Next() {
-		var dbTransaction common.Transaction
-		if err := rows.Scan(&dbTransaction.ID, &dbTransaction.AccountID, &dbTransaction.OperationType, &dbTransaction.Amount, &dbTransaction.Description, &dbTransaction.CreatedAt, &dbTransaction.Status); err != nil {
-			s.logger.Error("Row scan failed: %v", err)
+		dbTransaction, err := scanTransaction(rows)
+		if err != nil {
+			logger.Error("Row scan failed: %v", err)
 			continue
 		}
-		transactions = append(transactions, ConvertTransactionToProto(&dbTransaction))
+		transaction := ConvertTransactionToProto(dbTransaction)
+		size += proto.Size(transaction)
+		transactions = append(transactions, transaction)
+	}
+	s.historyLimits.Observe(req.AccountId, len(transactions), size, time.Since(pageStart))
+
+	var nextPageToken string
+	if len(transactions) == int(limit) {
+		last := transactions[len(transactions)-1]
+		nextPageToken = s.pageTokens.Encode(common.PageCursor{CreatedAt: last.CreatedAt, ID: last.Id}, filterHash)
 	}
 
 	return &pb.GetTransactionHistoryResponse{
-		Transactions: transactions,
-		Total:        total,
+		Transactions:  transactions,
+		Total:         total,
+		NextPageToken: nextPageToken,
+		AppliedLimit:  limit,
 	}, nil
 }
 
+// AggregateTransactions summarises an account's completed transactions for spend charts.
+// Transactions in the requested range are grouped into day, week or month buckets (in UTC) and by
+// operation type, and the count and total amount of each group are returned.
+// It reads the daily rollups rather than the raw transactions, so the range is widened to whole UTC days.
+func (s *Service) AggregateTransactions(ctx context.Context, req *pb.AggregateTransactionsRequest) (*pb.AggregateTransactionsResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if req.AccountId == "" {
+		return &pb.AggregateTransactionsResponse{Error: "account_id required"}, nil
+	}
+
+	groupBy := req.GroupBy
+	if groupBy == "" {
+		groupBy = "day"
+	}
+	window, ok := aggregationWindows[groupBy]
+	if !ok {
+		return &pb.AggregateTransactionsResponse{Error: "group_by must be one of day, week, month"}, nil
+	}
+
+	to := req.To
+	if to <= 0 {
+		to = time.Now().Unix()
+	}
+	from := req.From
+	if from <= 0 {
+		from = to - int64(window/time.Second)
+	}
+	if from >= to {
+		return &pb.AggregateTransactionsResponse{Error: "from must be before to"}, nil
+	}
+	if to-from > int64(maxAggregationRange/time.Second) {
+		return &pb.AggregateTransactionsResponse{Error: "time range too large"}, nil
+	}
+
+	from -= from % secondsPerDay
+	if to%secondsPerDay != 0 {
+		to += secondsPerDay - to%secondsPerDay
+	}
+
+	logger.Info("Aggregating transactions: AccountID=%s, GroupBy=%s, From=%d, To=%d", req.AccountId, groupBy, from, to)
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT EXTRACT(EPOCH FROM date_trunc($2, to_timestamp(day_start) AT TIME ZONE 'UTC'))::BIGINT AS bucket_start,
+			operation_type, SUM(txn_count), SUM(total_amount)
+		FROM transaction_daily_rollups
+		WHERE account_id = $1 AND day_start >= $3 AND day_start < $4
+		GROUP BY bucket_start, operation_type
+		HAVING SUM(txn_count) > 0
+		ORDER BY bucket_start, operation_type
+	`, req.AccountId, groupBy, from, to)
+	duration := time.Since(start)
+
+	logger.LogDatabase("SELECT", "transaction_daily_rollups", duration, err)
+	if err != nil {
+		logger.Error("Aggregation query failed: %v", err)
+		return &pb.AggregateTransactionsResponse{Error: "database error"}, nil
+	}
+	defer rows.Close()
+
+	var buckets []*pb.TransactionBucket
+	for rows.Next() {
+		var bucket pb.TransactionBucket
+		if err := rows.Scan(&bucket.BucketStart, &bucket.OperationType, &bucket.Count, (*common.Cents)(&bucket.TotalAmountCents)); err != nil {
+			logger.Error("Row scan failed: %v", err)
+			return &pb.AggregateTransactionsResponse{Error: "database error"}, nil
+		}
+		buckets = append(buckets, &bucket)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Aggregation query failed: %v", err)
+		return &pb.AggregateTransactionsResponse{Error: "database error"}, nil
+	}
+
+	return &pb.AggregateTransactionsResponse{Buckets: buckets}, nil
+}
+
 // ProcessPayment processes a payment transaction by creating a PAYMENT operation.
 // This is a convenience method that delegates to CreateTransaction with PAYMENT operation type.
+// A payment in a currency other than the account's is converted to it at the rate of the service's rate
+// provider, rounded to whole cents; the transaction keeps the amount and currency paid and the rate applied.
 // Returns the processed transaction or an error if processing fails.
 func (s *Service) ProcessPayment(ctx context.Context, req *pb.ProcessPaymentRequest) (*pb.ProcessPaymentResponse, error) {
+	conversion, msg := s.convertPayment(ctx, req)
+	if msg != "" {
+		s.logger.WithContext(ctx).Error("Payment failed: %s: AccountID=%s, Currency=%s", msg, req.AccountId, req.Currency)
+		return &pb.ProcessPaymentResponse{Error: msg}, nil
+	}
+
 	createReq := &pb.CreateTransactionRequest{
 		AccountId:     req.AccountId,
 		OperationType: "PAYMENT",
-		Amount:        req.Amount,
+		AmountCents:   req.AmountCents,
 		Description:   req.Description,
 	}
+	if conversion != nil {
+		createReq.AmountCents = int64(conversion.amount.MulRate(conversion.rate))
+	}
 
-	resp, err := s.CreateTransaction(ctx, createReq)
+	resp, err := s.createTransaction(ctx, createReq, conversion, nil)
 	if err != nil {
 		return &pb.ProcessPaymentResponse{Error: err.Error()}, nil
 	}
@@ -3,13 +3,33 @@ package transaction
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/YASHIRAI/pismo-task/internal/common"
+	"github.com/YASHIRAI/pismo-task/internal/fx"
+	"github.com/YASHIRAI/pismo-task/proto/events"
 	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestNewService(t *testing.T) {
@@ -36,70 +56,105 @@ func TestService_CreateTransaction(t *testing.T) {
 			request: &pb.CreateTransactionRequest{
 				AccountId:     "test-account-id",
 				OperationType: "PAYMENT",
-				Amount:        100.50,
+				AmountCents:   10050,
 				Description:   "Test payment",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Mock account lookup
-				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at"}).
-					AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890)
+				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0)
 				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
 					WithArgs("test-account-id").
 					WillReturnRows(accountRows)
 
-				// Mock balance update
-				mock.ExpectExec(`UPDATE accounts`).
-					WithArgs(100.50, sqlmock.AnyArg(), "test-account-id").
-					WillReturnResult(sqlmock.NewResult(1, 1))
+				// Balance update and insert run in one database transaction
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE accounts`).
+					WithArgs("100.50", sqlmock.AnyArg(), "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100.0))
+
+				// No outstanding purchases to discharge
+				mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
 
 				// Mock transaction insert
 				mock.ExpectExec(`INSERT INTO transactions`).
-					WithArgs(sqlmock.AnyArg(), "test-account-id", "PAYMENT", 100.50, "Test payment", sqlmock.AnyArg(), "COMPLETED").
+					WithArgs(sqlmock.AnyArg(), "test-account-id", "PAYMENT", "100.50", "Test payment", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "100.50", false, "", "0.00", 0.0).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 			expectedError: "",
 			expectedResult: &pb.CreateTransactionResponse{
 				Transaction: &pb.Transaction{
 					AccountId:     "test-account-id",
 					OperationType: "PAYMENT",
-					Amount:        100.50,
+					AmountCents:   10050,
 					Description:   "Test payment",
 					Status:        "COMPLETED",
 				},
 			},
 		},
+		{
+			name: "external id already used",
+			request: &pb.CreateTransactionRequest{
+				AccountId:     "test-account-id",
+				OperationType: "CASH_PURCHASE",
+				AmountCents:   5000,
+				Description:   "Test purchase",
+				ExternalId:    "NET-000123",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0)
+				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+					WithArgs("test-account-id").
+					WillReturnRows(accountRows)
+
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE accounts`).
+					WithArgs("-50.00", sqlmock.AnyArg(), "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(150.0))
+				mock.ExpectExec(`INSERT INTO transactions`).
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "idx_transactions_external_id"})
+				mock.ExpectRollback()
+			},
+			expectedError: "external_id already used",
+		},
 		{
 			name: "successful cash purchase transaction",
 			request: &pb.CreateTransactionRequest{
 				AccountId:     "test-account-id",
 				OperationType: "CASH_PURCHASE",
-				Amount:        50.00,
+				AmountCents:   5000,
 				Description:   "Test purchase",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Mock account lookup
-				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at"}).
-					AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890)
+				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0)
 				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
 					WithArgs("test-account-id").
 					WillReturnRows(accountRows)
 
-				// Mock balance update (negative amount)
-				mock.ExpectExec(`UPDATE accounts`).
-					WithArgs(-50.00, sqlmock.AnyArg(), "test-account-id").
-					WillReturnResult(sqlmock.NewResult(1, 1))
+				// Balance update (negative amount) and insert run in one database transaction
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE accounts`).
+					WithArgs("-50.00", sqlmock.AnyArg(), "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100.0))
 
 				// Mock transaction insert
 				mock.ExpectExec(`INSERT INTO transactions`).
-					WithArgs(sqlmock.AnyArg(), "test-account-id", "CASH_PURCHASE", -50.00, "Test purchase", sqlmock.AnyArg(), "COMPLETED").
+					WithArgs(sqlmock.AnyArg(), "test-account-id", "CASH_PURCHASE", "-50.00", "Test purchase", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "-50.00", false, "", "0.00", 0.0).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 			expectedError: "",
 			expectedResult: &pb.CreateTransactionResponse{
 				Transaction: &pb.Transaction{
 					AccountId:     "test-account-id",
 					OperationType: "CASH_PURCHASE",
-					Amount:        -50.00,
+					AmountCents:   -5000,
 					Description:   "Test purchase",
 					Status:        "COMPLETED",
 				},
@@ -110,7 +165,7 @@ func TestService_CreateTransaction(t *testing.T) {
 			request: &pb.CreateTransactionRequest{
 				AccountId:     "",
 				OperationType: "PAYMENT",
-				Amount:        100.50,
+				AmountCents:   10050,
 				Description:   "Test payment",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
@@ -126,7 +181,7 @@ func TestService_CreateTransaction(t *testing.T) {
 			request: &pb.CreateTransactionRequest{
 				AccountId:     "test-account-id",
 				OperationType: "INVALID_OPERATION",
-				Amount:        100.50,
+				AmountCents:   10050,
 				Description:   "Test payment",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
@@ -142,7 +197,7 @@ func TestService_CreateTransaction(t *testing.T) {
 			request: &pb.CreateTransactionRequest{
 				AccountId:     "non-existent-id",
 				OperationType: "PAYMENT",
-				Amount:        100.50,
+				AmountCents:   10050,
 				Description:   "Test payment",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
@@ -160,13 +215,69 @@ func TestService_CreateTransaction(t *testing.T) {
 			request: &pb.CreateTransactionRequest{
 				AccountId:     "test-account-id",
 				OperationType: "CASH_PURCHASE",
-				Amount:        500.00,
+				AmountCents:   50000,
 				Description:   "Large purchase",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Mock account lookup with low balance
-				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at"}).
-					AddRow("test-account-id", "12345678901", "CHECKING", 100.00, 1234567890, 1234567890)
+				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 100.00, 1234567890, 1234567890, "ACTIVE", 0.0)
+				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+					WithArgs("test-account-id").
+					WillReturnRows(accountRows)
+			},
+			expectedError: "insufficient balance",
+			expectedResult: &pb.CreateTransactionResponse{
+				Error: "insufficient balance",
+			},
+		},
+		{
+			name: "debit within the overdraft limit",
+			request: &pb.CreateTransactionRequest{
+				AccountId:     "test-account-id",
+				OperationType: "CASH_PURCHASE",
+				AmountCents:   15000,
+				Description:   "Overdrawn purchase",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 100.00, 1234567890, 1234567890, "ACTIVE", 50.00)
+				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+					WithArgs("test-account-id").
+					WillReturnRows(accountRows)
+
+				// The database re-checks the limit and returns the new balance
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE accounts\s+SET balance = balance \+ \$1, updated_at = \$2\s+WHERE id = \$3 AND \(\$1 >= 0 OR balance \+ \$1 >= -overdraft_limit\)\s+RETURNING balance`).
+					WithArgs("-150.00", sqlmock.AnyArg(), "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(-50.00))
+				mock.ExpectExec(`INSERT INTO transactions`).
+					WithArgs(sqlmock.AnyArg(), "test-account-id", "CASH_PURCHASE", "-150.00", "Overdrawn purchase", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "-150.00", true, "", "0.00", 0.0).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			expectedResult: &pb.CreateTransactionResponse{
+				Transaction: &pb.Transaction{
+					AccountId:     "test-account-id",
+					OperationType: "CASH_PURCHASE",
+					AmountCents:   -15000,
+					Description:   "Overdrawn purchase",
+					Status:        "COMPLETED",
+					Overdrawn:     true,
+				},
+			},
+		},
+		{
+			name: "debit beyond the overdraft limit",
+			request: &pb.CreateTransactionRequest{
+				AccountId:     "test-account-id",
+				OperationType: "CASH_PURCHASE",
+				AmountCents:   15001,
+				Description:   "Large purchase",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 100.00, 1234567890, 1234567890, "ACTIVE", 50.00)
 				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
 					WithArgs("test-account-id").
 					WillReturnRows(accountRows)
@@ -181,13 +292,13 @@ func TestService_CreateTransaction(t *testing.T) {
 			request: &pb.CreateTransactionRequest{
 				AccountId:     "test-account-id",
 				OperationType: "PAYMENT",
-				Amount:        -100.50,
+				AmountCents:   -10050,
 				Description:   "Invalid payment",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Mock account lookup
-				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at"}).
-					AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890)
+				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0)
 				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
 					WithArgs("test-account-id").
 					WillReturnRows(accountRows)
@@ -202,7 +313,7 @@ func TestService_CreateTransaction(t *testing.T) {
 			request: &pb.CreateTransactionRequest{
 				AccountId:     "test-account-id",
 				OperationType: "PAYMENT",
-				Amount:        100.50,
+				AmountCents:   10050,
 				Description:   "Test payment",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
@@ -236,6 +347,7 @@ func TestService_CreateTransaction(t *testing.T) {
 				assert.Equal(t, tt.request.AccountId, response.Transaction.AccountId)
 				assert.Equal(t, tt.request.OperationType, response.Transaction.OperationType)
 				assert.Equal(t, tt.request.Description, response.Transaction.Description)
+				assert.Equal(t, tt.expectedResult.Transaction.Overdrawn, response.Transaction.Overdrawn)
 			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -257,8 +369,8 @@ func TestService_GetTransaction(t *testing.T) {
 				Id: "test-transaction-id",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status"}).
-					AddRow("test-transaction-id", "test-account-id", "PAYMENT", 100.50, "Test payment", 1234567890, "COMPLETED")
+				rows := sqlmock.NewRows([]string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id", "balance", "overdrawn", "original_currency", "original_amount", "fx_rate"}).
+					AddRow("test-transaction-id", "test-account-id", "PAYMENT", 100.50, "Test payment", 1234567890, "COMPLETED", "", "", []byte("{}"), "", "", 20.25, false, "", "0.00", 0.0)
 				mock.ExpectQuery(`SELECT id, account_id, operation_type, amount, description, created_at, status`).
 					WithArgs("test-transaction-id").
 					WillReturnRows(rows)
@@ -269,10 +381,11 @@ func TestService_GetTransaction(t *testing.T) {
 					Id:            "test-transaction-id",
 					AccountId:     "test-account-id",
 					OperationType: "PAYMENT",
-					Amount:        100.50,
+					AmountCents:   10050,
 					Description:   "Test payment",
 					CreatedAt:     1234567890,
 					Status:        "COMPLETED",
+					BalanceCents:  2025,
 				},
 			},
 		},
@@ -289,6 +402,35 @@ func TestService_GetTransaction(t *testing.T) {
 				Error: "id required",
 			},
 		},
+		{
+			name: "lookup by external id",
+			request: &pb.GetTransactionRequest{
+				ExternalId: "NET-000123",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM transactions WHERE external_id = \$1`).
+					WithArgs("NET-000123").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: "not found",
+			expectedResult: &pb.GetTransactionResponse{
+				Error: "not found",
+			},
+		},
+		{
+			name: "both id and external id",
+			request: &pb.GetTransactionRequest{
+				Id:         "test-transaction-id",
+				ExternalId: "NET-000123",
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				// No database call expected
+			},
+			expectedError: "only one of id and external_id allowed",
+			expectedResult: &pb.GetTransactionResponse{
+				Error: "only one of id and external_id allowed",
+			},
+		},
 		{
 			name: "transaction not found",
 			request: &pb.GetTransactionRequest{
@@ -339,9 +481,10 @@ func TestService_GetTransaction(t *testing.T) {
 				assert.Equal(t, tt.expectedResult.Transaction.Id, response.Transaction.Id)
 				assert.Equal(t, tt.expectedResult.Transaction.AccountId, response.Transaction.AccountId)
 				assert.Equal(t, tt.expectedResult.Transaction.OperationType, response.Transaction.OperationType)
-				assert.Equal(t, tt.expectedResult.Transaction.Amount, response.Transaction.Amount)
+				assert.Equal(t, tt.expectedResult.Transaction.AmountCents, response.Transaction.AmountCents)
 				assert.Equal(t, tt.expectedResult.Transaction.Description, response.Transaction.Description)
 				assert.Equal(t, tt.expectedResult.Transaction.Status, response.Transaction.Status)
+				assert.Equal(t, tt.expectedResult.Transaction.BalanceCents, response.Transaction.BalanceCents)
 			}
 
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -349,6 +492,8 @@ func TestService_GetTransaction(t *testing.T) {
 	}
 }
 
+var monthCountColumns = []string{"month_start", "txn_count"}
+
 func TestService_GetTransactionHistory(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -366,16 +511,16 @@ func TestService_GetTransactionHistory(t *testing.T) {
 				Offset:    0,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				// Mock count query
-				countRows := sqlmock.NewRows([]string{"count"}).AddRow(2)
-				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM transactions WHERE account_id = \$1`).
+				// Mock monthly count query
+				countRows := sqlmock.NewRows(monthCountColumns).AddRow(1230768000, 2)
+				mock.ExpectQuery(`FROM transaction_monthly_counts`).
 					WithArgs("test-account-id").
 					WillReturnRows(countRows)
 
 				// Mock transactions query
-				rows := sqlmock.NewRows([]string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status"}).
-					AddRow("tx1", "test-account-id", "PAYMENT", 100.50, "Payment 1", 1234567890, "COMPLETED").
-					AddRow("tx2", "test-account-id", "CASH_PURCHASE", -50.00, "Purchase 1", 1234567891, "COMPLETED")
+				rows := sqlmock.NewRows([]string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}).
+					AddRow("tx1", "test-account-id", "PAYMENT", 100.50, "Payment 1", 1234567890, "COMPLETED", "", "", []byte("{}"), "", "").
+					AddRow("tx2", "test-account-id", "CASH_PURCHASE", -50.00, "Purchase 1", 1234567891, "COMPLETED", "", "", []byte("{}"), "", "")
 				mock.ExpectQuery(`SELECT id, account_id, operation_type, amount, description, created_at, status`).
 					WithArgs("test-account-id", 10, 0).
 					WillReturnRows(rows)
@@ -406,14 +551,14 @@ func TestService_GetTransactionHistory(t *testing.T) {
 				Offset:    -1, // Should default to 0
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				// Mock count query
-				countRows := sqlmock.NewRows([]string{"count"}).AddRow(0)
-				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM transactions WHERE account_id = \$1`).
+				// Mock monthly count query: no transactions yet
+				countRows := sqlmock.NewRows(monthCountColumns)
+				mock.ExpectQuery(`FROM transaction_monthly_counts`).
 					WithArgs("test-account-id").
 					WillReturnRows(countRows)
 
 				// Mock transactions query with default values
-				rows := sqlmock.NewRows([]string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status"})
+				rows := sqlmock.NewRows([]string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"})
 				mock.ExpectQuery(`SELECT id, account_id, operation_type, amount, description, created_at, status`).
 					WithArgs("test-account-id", 50, 0).
 					WillReturnRows(rows)
@@ -430,14 +575,14 @@ func TestService_GetTransactionHistory(t *testing.T) {
 				Offset:    0,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				// Mock count query
-				countRows := sqlmock.NewRows([]string{"count"}).AddRow(0)
-				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM transactions WHERE account_id = \$1`).
+				// Mock monthly count query: no transactions yet
+				countRows := sqlmock.NewRows(monthCountColumns)
+				mock.ExpectQuery(`FROM transaction_monthly_counts`).
 					WithArgs("test-account-id").
 					WillReturnRows(countRows)
 
 				// Mock transactions query with default limit (50, not 100)
-				rows := sqlmock.NewRows([]string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status"})
+				rows := sqlmock.NewRows([]string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"})
 				mock.ExpectQuery(`SELECT id, account_id, operation_type, amount, description, created_at, status`).
 					WithArgs("test-account-id", 50, 0).
 					WillReturnRows(rows)
@@ -454,7 +599,7 @@ func TestService_GetTransactionHistory(t *testing.T) {
 				Offset:    0,
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM transactions WHERE account_id = \$1`).
+				mock.ExpectQuery(`FROM transaction_monthly_counts`).
 					WithArgs("test-account-id").
 					WillReturnError(sql.ErrConnDone)
 			},
@@ -498,33 +643,40 @@ func TestService_ProcessPayment(t *testing.T) {
 			name: "successful payment processing",
 			request: &pb.ProcessPaymentRequest{
 				AccountId:   "test-account-id",
-				Amount:      100.50,
+				AmountCents: 10050,
 				Description: "Test payment",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Mock account lookup
-				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at"}).
-					AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890)
+				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0)
 				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
 					WithArgs("test-account-id").
 					WillReturnRows(accountRows)
 
-				// Mock balance update
-				mock.ExpectExec(`UPDATE accounts`).
-					WithArgs(100.50, sqlmock.AnyArg(), "test-account-id").
-					WillReturnResult(sqlmock.NewResult(1, 1))
+				// Balance update and insert run in one database transaction
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE accounts`).
+					WithArgs("100.50", sqlmock.AnyArg(), "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100.0))
+
+				// No outstanding purchases to discharge
+				mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
 
 				// Mock transaction insert
 				mock.ExpectExec(`INSERT INTO transactions`).
-					WithArgs(sqlmock.AnyArg(), "test-account-id", "PAYMENT", 100.50, "Test payment", sqlmock.AnyArg(), "COMPLETED").
+					WithArgs(sqlmock.AnyArg(), "test-account-id", "PAYMENT", "100.50", "Test payment", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "100.50", false, "", "0.00", 0.0).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 			expectedError: "",
 			expectedResult: &pb.ProcessPaymentResponse{
 				Transaction: &pb.Transaction{
 					AccountId:     "test-account-id",
 					OperationType: "PAYMENT",
-					Amount:        100.50,
+					AmountCents:   10050,
 					Description:   "Test payment",
 					Status:        "COMPLETED",
 				},
@@ -534,26 +686,33 @@ func TestService_ProcessPayment(t *testing.T) {
 			name: "error in create transaction",
 			request: &pb.ProcessPaymentRequest{
 				AccountId:   "test-account-id",
-				Amount:      100.50,
+				AmountCents: 10050,
 				Description: "Test payment",
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				// Mock account lookup
-				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at"}).
-					AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890)
+				accountRows := sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0)
 				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
 					WithArgs("test-account-id").
 					WillReturnRows(accountRows)
 
-				// Mock balance update
-				mock.ExpectExec(`UPDATE accounts`).
-					WithArgs(100.50, sqlmock.AnyArg(), "test-account-id").
-					WillReturnResult(sqlmock.NewResult(1, 1))
+				// Balance update and insert run in one database transaction
+				mock.ExpectBegin()
+				mock.ExpectQuery(`UPDATE accounts`).
+					WithArgs("100.50", sqlmock.AnyArg(), "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100.0))
+
+				// No outstanding purchases to discharge
+				mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
 
 				// Mock transaction insert error
 				mock.ExpectExec(`INSERT INTO transactions`).
-					WithArgs(sqlmock.AnyArg(), "test-account-id", "PAYMENT", 100.50, "Test payment", sqlmock.AnyArg(), "COMPLETED").
+					WithArgs(sqlmock.AnyArg(), "test-account-id", "PAYMENT", "100.50", "Test payment", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "100.50", false, "", "0.00", 0.0).
 					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
 			},
 			expectedError: "could not create transaction",
 			expectedResult: &pb.ProcessPaymentResponse{
@@ -580,7 +739,7 @@ func TestService_ProcessPayment(t *testing.T) {
 				assert.NotEmpty(t, response.Transaction.Id)
 				assert.Equal(t, tt.request.AccountId, response.Transaction.AccountId)
 				assert.Equal(t, "PAYMENT", response.Transaction.OperationType)
-				assert.Equal(t, tt.request.Amount, response.Transaction.Amount)
+				assert.Equal(t, tt.request.AmountCents, response.Transaction.AmountCents)
 				assert.Equal(t, tt.request.Description, response.Transaction.Description)
 			}
 
@@ -588,3 +747,4882 @@ func TestService_ProcessPayment(t *testing.T) {
 		})
 	}
 }
+
+func TestService_ProcessPayment_CurrencyConversion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Setenv("APP_ENV", "production")
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.TenantIDMetadataKey, "issuer-a"))
+
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+		WithArgs("issuer-a", "production").
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{"currency":"BRL"}`)))
+
+	// Without a rate provider only payments in the account's currency are accepted
+	response, err := service.ProcessPayment(ctx, &pb.ProcessPaymentRequest{AccountId: "test-account-id", AmountCents: 1000, Currency: "EUR"})
+	require.NoError(t, err)
+	assert.Equal(t, "currency conversion not available", response.Error)
+
+	service.SetRateProvider(fx.NewStaticProvider(map[string]float64{"EUR/BRL": 6.2}))
+	for currency, expected := range map[string]string{"eur": "invalid currency", "GBP": "exchange rate not available"} {
+		response, err := service.ProcessPayment(ctx, &pb.ProcessPaymentRequest{AccountId: "test-account-id", AmountCents: 1000, Currency: currency})
+		require.NoError(t, err)
+		assert.Equal(t, expected, response.Error, currency)
+	}
+
+	// 10.00 EUR is paid as 62.00 BRL, keeping the amount paid and the rate
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("test-account-id", "12345678901", "CHECKING", 0.0, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE accounts`).
+		WithArgs("62.00", sqlmock.AnyArg(), "test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(62.0))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(sqlmock.AnyArg(), "test-account-id", "PAYMENT", "62.00", "", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "62.00", false, "EUR", "10.00", 6.2).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	response, err = service.ProcessPayment(ctx, &pb.ProcessPaymentRequest{AccountId: "test-account-id", AmountCents: 1000, Currency: "EUR"})
+	require.NoError(t, err)
+	require.Empty(t, response.Error)
+	assert.Equal(t, int64(6200), response.Transaction.AmountCents)
+	assert.Equal(t, "EUR", response.Transaction.OriginalCurrency)
+	assert.Equal(t, int64(1000), response.Transaction.OriginalAmountCents)
+	assert.Equal(t, 6.2, response.Transaction.FxRate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// fakeIngestTransactionsStream is an in-memory bidirectional stream used to drive IngestTransactions in tests.
+type fakeIngestTransactionsStream struct {
+	grpc.ServerStream
+	requests []*pb.CreateTransactionRequest
+	results  []*pb.IngestTransactionResult
+}
+
+func (f *fakeIngestTransactionsStream) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeIngestTransactionsStream) Recv() (*pb.CreateTransactionRequest, error) {
+	if len(f.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := f.requests[0]
+	f.requests = f.requests[1:]
+	return req, nil
+}
+
+func (f *fakeIngestTransactionsStream) Send(result *pb.IngestTransactionResult) error {
+	f.results = append(f.results, result)
+	return nil
+}
+
+func TestService_IngestTransactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, account_type, balance, status, overdraft_limit FROM accounts WHERE id IN`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_type", "balance", "status", "overdraft_limit"}).AddRow("test-account-id", "CHECKING", 200.00, "ACTIVE", 0.0))
+	mock.ExpectExec(`UPDATE accounts AS a`).
+		WithArgs(sqlmock.AnyArg(), "test-account-id", "-50.00").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(sqlmock.AnyArg(), "test-account-id", "CASH_PURCHASE", "-50.00", "Coffee", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "-50.00", false).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	stream := &fakeIngestTransactionsStream{
+		requests: []*pb.CreateTransactionRequest{
+			{AccountId: "test-account-id", OperationType: "CASH_PURCHASE", AmountCents: 5000, Description: "Coffee"},
+			{AccountId: "test-account-id", OperationType: "INVALID_OPERATION", AmountCents: 1000},
+		},
+	}
+
+	err = service.IngestTransactions(stream)
+	assert.NoError(t, err)
+	require.Len(t, stream.results, 2)
+
+	assert.Equal(t, int32(0), stream.results[0].Index)
+	assert.Empty(t, stream.results[0].Error)
+	require.NotNil(t, stream.results[0].Transaction)
+	assert.Equal(t, "COMPLETED", stream.results[0].Transaction.Status)
+
+	assert.Equal(t, int32(1), stream.results[1].Index)
+	assert.Equal(t, "invalid operation type", stream.results[1].Error)
+	assert.Nil(t, stream.results[1].Transaction)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_createTransactionBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, account_type, balance, status, overdraft_limit FROM accounts WHERE id IN`).
+		WithArgs("account-a", "account-b", "missing-account").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_type", "balance", "status", "overdraft_limit"}).
+			AddRow("account-a", "CHECKING", 100.00, "ACTIVE", 0.0).
+			AddRow("account-b", "CHECKING", 20.00, "ACTIVE", 0.0))
+	// Balance changes are grouped into one delta per account
+	mock.ExpectExec(`UPDATE accounts AS a`).
+		WithArgs(sqlmock.AnyArg(), "account-a", "-30.00", "account-b", "50.00").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(
+			sqlmock.AnyArg(), "account-a", "CASH_PURCHASE", "-60.00", "", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "-60.00", false,
+			sqlmock.AnyArg(), "account-b", "PAYMENT", "50.00", "", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "50.00", false,
+			sqlmock.AnyArg(), "account-a", "PAYMENT", "30.00", "", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "30.00", false,
+		).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	// Payments then discharge outstanding purchases, including those of the batch
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+		WithArgs("account-b").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+		WithArgs("account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow("purchase-a", -60.0))
+	mock.ExpectExec(`UPDATE transactions SET balance = balance \+ \$1 WHERE id = \$2`).
+		WithArgs("30.00", "purchase-a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO payment_discharges \(payment_id, debt_id, amount\) VALUES \(\$1, \$2, \$3\)`).
+		WithArgs(sqlmock.AnyArg(), "purchase-a", "30.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE transactions SET balance = \$1 WHERE id = \$2`).
+		WithArgs("0.00", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	results := service.createTransactionBatch(context.Background(), []*pb.CreateTransactionRequest{
+		{AccountId: "account-a", OperationType: "CASH_PURCHASE", AmountCents: 6000},
+		{AccountId: "account-a", OperationType: "WITHDRAWAL", AmountCents: 5000},
+		{AccountId: "account-b", OperationType: "PAYMENT", AmountCents: 5000},
+		{AccountId: "missing-account", OperationType: "PAYMENT", AmountCents: 1000},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 3000},
+		{AccountId: "account-b", OperationType: "PAYMENT", AmountCents: -500},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 500, Simulate: true},
+	})
+	require.Len(t, results, 7)
+
+	assert.Empty(t, results[0].err)
+	require.NotNil(t, results[0].transaction)
+	assert.Equal(t, common.Cents(-6000), results[0].transaction.Amount)
+	assert.Equal(t, "insufficient balance", results[1].err)
+	assert.Empty(t, results[2].err)
+	assert.Equal(t, "account not found", results[3].err)
+	assert.Empty(t, results[4].err)
+	assert.Equal(t, "payment amount must be positive", results[5].err)
+	assert.Equal(t, "simulate is not supported for ingested transactions", results[6].err)
+	assert.Equal(t, common.Cents(5000), results[2].transaction.Balance)
+	assert.Equal(t, common.Cents(0), results[4].transaction.Balance)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_CreateTransaction_DischargesPurchases(t *testing.T) {
+	tests := []struct {
+		name              string
+		amountCents       int64
+		outstanding       [][]interface{}
+		discharges        [][]interface{}
+		expectedRemainder common.Cents
+	}{
+		{
+			name:              "oldest purchases are discharged first",
+			amountCents:       6000,
+			outstanding:       [][]interface{}{{"purchase-1", -50.0}, {"purchase-2", -23.5}, {"purchase-3", -18.7}},
+			discharges:        [][]interface{}{{"50.00", "purchase-1"}, {"10.00", "purchase-2"}},
+			expectedRemainder: 0,
+		},
+		{
+			name:              "remainder is kept as the payment's balance",
+			amountCents:       10000,
+			outstanding:       [][]interface{}{{"purchase-1", -50.0}, {"withdrawal-1", -23.5}},
+			discharges:        [][]interface{}{{"50.00", "purchase-1"}, {"23.50", "withdrawal-1"}},
+			expectedRemainder: 2650,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+				WithArgs("test-account-id").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+					AddRow("test-account-id", "12345678901", "CHECKING", 10.0, 1234567890, 1234567890, "ACTIVE", 0.0))
+			mock.ExpectBegin()
+			mock.ExpectQuery(`UPDATE accounts`).
+				WithArgs(common.Cents(tt.amountCents), sqlmock.AnyArg(), "test-account-id").
+				WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100.0))
+			rows := sqlmock.NewRows([]string{"id", "balance"})
+			for _, row := range tt.outstanding {
+				rows.AddRow(row[0], row[1])
+			}
+			mock.ExpectQuery(`SELECT id, balance FROM transactions\s+WHERE account_id = \$1 AND balance < 0 AND status = 'COMPLETED'`).
+				WithArgs("test-account-id").
+				WillReturnRows(rows)
+			for _, discharge := range tt.discharges {
+				mock.ExpectExec(`UPDATE transactions SET balance = balance \+ \$1 WHERE id = \$2`).
+					WithArgs(discharge[0], discharge[1]).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectExec(`INSERT INTO transactions`).
+				WithArgs(sqlmock.AnyArg(), "test-account-id", "PAYMENT", common.Cents(tt.amountCents), "", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), tt.expectedRemainder, false, "", "0.00", 0.0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			// Each discharge is recorded once the payment is written, for its reversal
+			var recorded []driver.Value
+			for _, discharge := range tt.discharges {
+				recorded = append(recorded, sqlmock.AnyArg(), discharge[1], discharge[0])
+			}
+			mock.ExpectExec(`INSERT INTO payment_discharges \(payment_id, debt_id, amount\) VALUES \(\$1, \$2, \$3\), \(\$4, \$5, \$6\)`).
+				WithArgs(recorded...).
+				WillReturnResult(sqlmock.NewResult(0, int64(len(tt.discharges))))
+			mock.ExpectCommit()
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{
+				AccountId: "test-account-id", OperationType: "PAYMENT", AmountCents: tt.amountCents,
+			})
+
+			require.NoError(t, err)
+			require.Empty(t, response.Error)
+			assert.Equal(t, tt.expectedRemainder, common.Cents(response.Transaction.BalanceCents))
+			assert.Len(t, response.Discharges, len(tt.discharges))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_createTransactionBatch_RollsBackOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, account_type, balance, status, overdraft_limit FROM accounts WHERE id IN`).
+		WithArgs("account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_type", "balance", "status", "overdraft_limit"}).AddRow("account-a", "CHECKING", 100.00, "ACTIVE", 0.0))
+	mock.ExpectExec(`UPDATE accounts AS a`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	results := service.createTransactionBatch(context.Background(), []*pb.CreateTransactionRequest{
+		{AccountId: "account-a", OperationType: "CASH_PURCHASE", AmountCents: 1000},
+		{AccountId: "account-a", OperationType: "INVALID_OPERATION", AmountCents: 1000},
+	})
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "could not create transaction", results[0].err)
+	assert.Nil(t, results[0].transaction)
+	assert.Equal(t, "invalid operation type", results[1].err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_CreateTransaction_WaitsForAccountLock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	// Hold the account lock as if another operation were in flight
+	unlock, err := service.accountLocks.Lock(context.Background(), "test-account-id")
+	require.NoError(t, err)
+	defer unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resp, err := service.CreateTransaction(ctx, &pb.CreateTransactionRequest{
+		AccountId:     "test-account-id",
+		OperationType: "CASH_PURCHASE",
+		AmountCents:   5000,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "request cancelled", resp.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_CreateTransaction_RollsBackOnCancellation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	// The client disconnects once the balance has been updated
+	mock.ExpectQuery(`UPDATE accounts`).
+		WithArgs("-50.00", sqlmock.AnyArg(), "test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100.0))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WillReturnError(context.Canceled)
+	mock.ExpectRollback()
+
+	resp, err := service.CreateTransaction(ctx, &pb.CreateTransactionRequest{
+		AccountId:     "test-account-id",
+		OperationType: "CASH_PURCHASE",
+		AmountCents:   5000,
+	})
+	cancel()
+	assert.NoError(t, err)
+	assert.Equal(t, "request cancelled", resp.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// envelopeArg matches a serialized event envelope and keeps it for inspection.
+type envelopeArg struct {
+	envelope *events.EventEnvelope
+}
+
+func (a *envelopeArg) Match(v driver.Value) bool {
+	data, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	a.envelope = &events.EventEnvelope{}
+	return proto.Unmarshal(data, a.envelope) == nil
+}
+
+func TestService_CreateTransaction_WritesOutboxEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	service.EnableEventOutbox()
+
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE accounts`).
+		WithArgs("-50.00", sqlmock.AnyArg(), "test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100.0))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	envelope := &envelopeArg{}
+	mock.ExpectExec(`INSERT INTO event_outbox \(event_id, event_type, partition_key, envelope, created_at\)`).
+		WithArgs(sqlmock.AnyArg(), events.TransactionCreated, "test-account-id", envelope, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	resp, err := service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{
+		AccountId:     "test-account-id",
+		OperationType: "CASH_PURCHASE",
+		AmountCents:   5000,
+		Description:   "Coffee",
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	require.NotNil(t, envelope.envelope)
+	assert.Equal(t, "test-account-id", envelope.envelope.PartitionKey)
+	payload, err := events.DecodePayload(envelope.envelope)
+	require.NoError(t, err)
+	created := payload.(*events.TransactionCreatedV1)
+	assert.Equal(t, resp.Transaction.Id, created.TransactionId)
+	assert.Equal(t, -50.0, created.Amount)
+	assert.Equal(t, "Coffee", created.Description)
+}
+
+func TestService_CreateTransaction_OutboxFailureRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	service.EnableEventOutbox()
+
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE accounts`).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100.0))
+	mock.ExpectExec(`INSERT INTO transactions`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO event_outbox`).WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	resp, err := service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{
+		AccountId:     "test-account-id",
+		OperationType: "CASH_PURCHASE",
+		AmountCents:   5000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "could not create transaction", resp.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_CreateTransaction_RechecksBalanceOnUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	// The balance read before the update still covers the debit, but another instance spent it meanwhile
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("test-account-id", "12345678901", "CHECKING", 60.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE accounts\s+SET balance = balance \+ \$1, updated_at = \$2\s+WHERE id = \$3 AND \(\$1 >= 0 OR balance \+ \$1 >= -overdraft_limit\)\s+RETURNING balance`).
+		WithArgs("-50.00", sqlmock.AnyArg(), "test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}))
+	mock.ExpectRollback()
+
+	resp, err := service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{
+		AccountId:     "test-account-id",
+		OperationType: "CASH_PURCHASE",
+		AmountCents:   5000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "insufficient balance", resp.Error)
+	assert.Nil(t, resp.Transaction)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_CreateTransaction_CachesMissingAccounts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Only the first lookup reaches the database
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("missing-account").
+		WillReturnError(sql.ErrNoRows)
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	req := &pb.CreateTransactionRequest{AccountId: "missing-account", OperationType: "CASH_PURCHASE", AmountCents: 1000}
+
+	for i := 0; i < 3; i++ {
+		resp, err := service.CreateTransaction(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, "account not found", resp.Error)
+	}
+
+	results := service.createTransactionBatch(context.Background(), []*pb.CreateTransactionRequest{req})
+	require.Len(t, results, 1)
+	assert.Equal(t, "account not found", results[0].err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_GetTransactionHistory_PageTokens(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+
+	// First page: a full page yields a token for the next one
+	mock.ExpectQuery(`FROM transaction_monthly_counts`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows(monthCountColumns).AddRow(1230768000, 3))
+	mock.ExpectQuery(`SELECT id, account_id, operation_type, amount, description, created_at, status`).
+		WithArgs("test-account-id", 2, 0).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("tx3", "test-account-id", "PAYMENT", 10.0, "", 1234567893, "COMPLETED", "", "", []byte("{}"), "", "").
+			AddRow("tx2", "test-account-id", "PAYMENT", 10.0, "", 1234567892, "COMPLETED", "", "", []byte("{}"), "", ""))
+
+	first, err := service.GetTransactionHistory(context.Background(), &pb.GetTransactionHistoryRequest{AccountId: "test-account-id", Limit: 2})
+	require.NoError(t, err)
+	require.Empty(t, first.Error)
+	require.NotEmpty(t, first.NextPageToken)
+
+	// Second page resumes after the last transaction using the keyset position
+	mock.ExpectQuery(`FROM transaction_monthly_counts`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows(monthCountColumns).AddRow(1230768000, 3))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND \(created_at, id\) < \(\$2, \$3\)`).
+		WithArgs("test-account-id", 1234567892, "tx2", 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("tx1", "test-account-id", "PAYMENT", 10.0, "", 1234567891, "COMPLETED", "", "", []byte("{}"), "", ""))
+
+	second, err := service.GetTransactionHistory(context.Background(), &pb.GetTransactionHistoryRequest{
+		AccountId: "test-account-id",
+		Limit:     2,
+		PageToken: first.NextPageToken,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, second.Error)
+	assert.Len(t, second.Transactions, 1)
+	assert.Empty(t, second.NextPageToken)
+
+	// Tampered tokens and tokens issued for another account are rejected before querying
+	for _, req := range []*pb.GetTransactionHistoryRequest{
+		{AccountId: "test-account-id", PageToken: first.NextPageToken + "x"},
+		{AccountId: "other-account-id", PageToken: first.NextPageToken},
+	} {
+		resp, err := service.GetTransactionHistory(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, "invalid page token", resp.Error)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHistoryPageLimiter(t *testing.T) {
+	limiter := newHistoryPageLimiter(64*1024, 100*time.Millisecond)
+
+	// Too few pages observed: the requested size is used
+	limiter.Observe("acc-large", 50, 50*4096, 10*time.Millisecond)
+	assert.Equal(t, int32(50), limiter.Limit("acc-large", 50))
+	limiter.Observe("acc-large", 50, 50*4096, 10*time.Millisecond)
+	limiter.Observe("acc-large", 50, 50*4096, 10*time.Millisecond)
+
+	// 4KiB transactions round up to 8KiB: 64KiB/8KiB = 8, raised to the minimum page size
+	assert.Equal(t, int32(minHistoryPageLimit), limiter.Limit("acc-large", 50))
+
+	// Slow pages are clamped by latency: 2ms per transaction rounds up to 2048us, 100ms/2048us = 48
+	for i := 0; i < historyLimitMinSamples; i++ {
+		limiter.Observe("acc-slow", 20, 20*100, 40*time.Millisecond)
+	}
+	assert.Equal(t, int32(48), limiter.Limit("acc-slow", 50))
+	assert.Equal(t, int32(20), limiter.Limit("acc-slow", 20))
+
+	// Ordinary accounts keep the requested size, and empty pages are ignored
+	for i := 0; i < historyLimitMinSamples; i++ {
+		limiter.Observe("acc-small", 50, 50*200, 5*time.Millisecond)
+		limiter.Observe("acc-empty", 0, 0, time.Second)
+	}
+	assert.Equal(t, int32(maxHistoryPageLimit), limiter.Limit("acc-small", maxHistoryPageLimit))
+	assert.Equal(t, int32(50), limiter.Limit("acc-empty", 50))
+
+	// With no budgets nothing is tracked
+	disabled := newHistoryPageLimiter(0, 0)
+	for i := 0; i < historyLimitMinSamples; i++ {
+		disabled.Observe("acc-large", 50, 50*4096, time.Second)
+	}
+	assert.Equal(t, int32(50), disabled.Limit("acc-large", 50))
+}
+
+func TestHistoryPageLimiter_EvictsIdleAccounts(t *testing.T) {
+	clock := time.Unix(1700000000, 0)
+	limiter := newHistoryPageLimiter(DefaultHistoryPageMaxBytes, DefaultHistoryPageMaxLatency)
+	limiter.now = func() time.Time { return clock }
+
+	for i := 0; i < maxHistoryLimitAccounts; i++ {
+		limiter.Observe(strconv.Itoa(i), 1, 100, time.Millisecond)
+	}
+	limiter.Observe("overflow", 1, 100, time.Millisecond)
+	assert.NotContains(t, limiter.accounts, "overflow")
+
+	clock = clock.Add(historyLimitIdleTTL)
+	limiter.Observe("overflow", 1, 100, time.Millisecond)
+	assert.Contains(t, limiter.accounts, "overflow")
+	assert.Len(t, limiter.accounts, 1)
+}
+
+func TestService_GetTransactionHistory_AppliedLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	service.historyLimits = newHistoryPageLimiter(64*1024, 0)
+	for i := 0; i < historyLimitMinSamples; i++ {
+		service.historyLimits.Observe("test-account-id", 50, 50*4096, 10*time.Millisecond)
+	}
+
+	mock.ExpectQuery(`FROM transaction_monthly_counts`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows(monthCountColumns).AddRow(1230768000, 30))
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+	mock.ExpectQuery(`SELECT id, account_id, operation_type, amount, description, created_at, status`).
+		WithArgs("test-account-id", minHistoryPageLimit, 0).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("tx1", "test-account-id", "PAYMENT", 10.0, "", 1234567891, "COMPLETED", "", "", []byte("{}"), "", ""))
+
+	resp, err := service.GetTransactionHistory(context.Background(), &pb.GetTransactionHistoryRequest{AccountId: "test-account-id", Limit: 50})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Error)
+	assert.Equal(t, int32(minHistoryPageLimit), resp.AppliedLimit)
+	assert.Equal(t, int32(30), resp.Total)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_GetTransactionHistory_MonthOffsets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+	// Three transactions in January 2009 and two in February
+	months := func() *sqlmock.Rows {
+		return sqlmock.NewRows(monthCountColumns).AddRow(1230768000, 3).AddRow(1233446400, 2)
+	}
+
+	// Offset 3 skips February and one January transaction: the page is read from before February
+	mock.ExpectQuery(`FROM transaction_monthly_counts`).WithArgs("test-account-id").WillReturnRows(months())
+	mock.ExpectQuery(`WHERE account_id = \$1 AND created_at < \$2\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$3 OFFSET \$4`).
+		WithArgs("test-account-id", 1233446400, 2, 1).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("tx2", "test-account-id", "PAYMENT", 10.0, "", 1231000000, "COMPLETED", "", "", []byte("{}"), "", "").
+			AddRow("tx1", "test-account-id", "PAYMENT", 10.0, "", 1230800000, "COMPLETED", "", "", []byte("{}"), "", ""))
+
+	page, err := service.GetTransactionHistory(context.Background(), &pb.GetTransactionHistoryRequest{AccountId: "test-account-id", Limit: 2, Offset: 3})
+	require.NoError(t, err)
+	assert.Empty(t, page.Error)
+	assert.Equal(t, int32(5), page.Total)
+	assert.Len(t, page.Transactions, 2)
+
+	// An offset past the end of the history returns an empty page without querying transactions
+	mock.ExpectQuery(`FROM transaction_monthly_counts`).WithArgs("test-account-id").WillReturnRows(months())
+
+	page, err = service.GetTransactionHistory(context.Background(), &pb.GetTransactionHistoryRequest{AccountId: "test-account-id", Limit: 2, Offset: 5})
+	require.NoError(t, err)
+	assert.Empty(t, page.Error)
+	assert.Equal(t, int32(5), page.Total)
+	assert.Empty(t, page.Transactions)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_GetTransactionHistory_Search(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM transactions WHERE account_id = \$1 AND description ILIKE \$2`).
+		WithArgs("test-account-id", "%uber\\_eats%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND description ILIKE \$2\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$3 OFFSET \$4`).
+		WithArgs("test-account-id", "%uber\\_eats%", 1, 0).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("tx2", "test-account-id", "CASH_PURCHASE", -12.5, "UBER_EATS *ORDER", 1234567892, "COMPLETED", "", "", []byte("{}"), "", ""))
+
+	first, err := service.GetTransactionHistory(context.Background(), &pb.GetTransactionHistoryRequest{
+		AccountId: "test-account-id", Limit: 1, Search: " uber_eats ",
+	})
+	require.NoError(t, err)
+	require.Empty(t, first.Error)
+	assert.Equal(t, int32(2), first.Total)
+	require.NotEmpty(t, first.NextPageToken)
+
+	// The next page keeps the search, and its token is bound to it
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM transactions WHERE account_id = \$1 AND description ILIKE \$2`).
+		WithArgs("test-account-id", "%uber\\_eats%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND description ILIKE \$2 AND \(created_at, id\) < \(\$3, \$4\)`).
+		WithArgs("test-account-id", "%uber\\_eats%", 1234567892, "tx2", 1).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	second, err := service.GetTransactionHistory(context.Background(), &pb.GetTransactionHistoryRequest{
+		AccountId: "test-account-id", Limit: 1, Search: "uber_eats", PageToken: first.NextPageToken,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, second.Error)
+
+	for _, req := range []*pb.GetTransactionHistoryRequest{
+		{AccountId: "test-account-id", PageToken: first.NextPageToken},
+		{AccountId: "test-account-id", Search: "ub"},
+	} {
+		resp, err := service.GetTransactionHistory(context.Background(), req)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, resp.Error)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_AggregateTransactions(t *testing.T) {
+	columns := []string{"bucket_start", "operation_type", "count", "sum"}
+
+	tests := []struct {
+		name            string
+		request         *pb.AggregateTransactionsRequest
+		mockSetup       func(sqlmock.Sqlmock)
+		expectedError   string
+		expectedBuckets []*pb.TransactionBucket
+	}{
+		{
+			name: "groups rollups by month and operation type",
+			request: &pb.AggregateTransactionsRequest{
+				AccountId: "test-account-id",
+				GroupBy:   "month",
+				From:      1700000000,
+				To:        1710000000,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1698796800, "CASH_PURCHASE", 3, 150.25).
+					AddRow(1698796800, "PAYMENT", 1, 500.00).
+					AddRow(1701388800, "CASH_PURCHASE", 2, 40.00)
+				mock.ExpectQuery(`SELECT EXTRACT\(EPOCH FROM date_trunc\(\$2`).
+					WithArgs("test-account-id", "month", int64(1699920000), int64(1710028800)).
+					WillReturnRows(rows)
+			},
+			expectedBuckets: []*pb.TransactionBucket{
+				{BucketStart: 1698796800, OperationType: "CASH_PURCHASE", Count: 3, TotalAmountCents: 15025},
+				{BucketStart: 1698796800, OperationType: "PAYMENT", Count: 1, TotalAmountCents: 50000},
+				{BucketStart: 1701388800, OperationType: "CASH_PURCHASE", Count: 2, TotalAmountCents: 4000},
+			},
+		},
+		{
+			name: "defaults to daily buckets over the default window, widened to whole days",
+			request: &pb.AggregateTransactionsRequest{
+				AccountId: "test-account-id",
+				To:        1710000000,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXTRACT\(EPOCH FROM date_trunc\(\$2`).
+					WithArgs("test-account-id", "day", int64(1707350400), int64(1710028800)).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		{
+			name:          "missing account id",
+			request:       &pb.AggregateTransactionsRequest{GroupBy: "day"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "account_id required",
+		},
+		{
+			name:          "unsupported group_by",
+			request:       &pb.AggregateTransactionsRequest{AccountId: "test-account-id", GroupBy: "hour"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "group_by must be one of day, week, month",
+		},
+		{
+			name:          "inverted range",
+			request:       &pb.AggregateTransactionsRequest{AccountId: "test-account-id", From: 200, To: 100},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "from must be before to",
+		},
+		{
+			name:          "range too large",
+			request:       &pb.AggregateTransactionsRequest{AccountId: "test-account-id", GroupBy: "month", From: 1, To: 1710000000},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "time range too large",
+		},
+		{
+			name:    "database error",
+			request: &pb.AggregateTransactionsRequest{AccountId: "test-account-id", GroupBy: "week", From: 1700000000, To: 1710000000},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT EXTRACT\(EPOCH FROM date_trunc\(\$2`).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.AggregateTransactions(context.Background(), tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			assert.Equal(t, len(tt.expectedBuckets), len(response.Buckets))
+			for i, expected := range tt.expectedBuckets {
+				assert.Equal(t, expected.BucketStart, response.Buckets[i].BucketStart)
+				assert.Equal(t, expected.OperationType, response.Buckets[i].OperationType)
+				assert.Equal(t, expected.Count, response.Buckets[i].Count)
+				assert.Equal(t, expected.TotalAmountCents, response.Buckets[i].TotalAmountCents)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_CreateTransaction_Simulate(t *testing.T) {
+	tests := []struct {
+		name                 string
+		request              *pb.CreateTransactionRequest
+		mockSetup            func(sqlmock.Sqlmock)
+		expectedError        string
+		expectedAmount       int64
+		expectedBalanceAfter int64
+		expectedDischarges   []*pb.Discharge
+	}{
+		{
+			name:    "debit within balance",
+			request: &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "CASH_PURCHASE", AmountCents: 4000, Simulate: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT balance, account_type, status, overdraft_limit FROM accounts WHERE id = \$1`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "account_type", "status", "overdraft_limit"}).AddRow(100.0, "CHECKING", "ACTIVE", 0.0))
+			},
+			expectedAmount:       -4000,
+			expectedBalanceAfter: 6000,
+		},
+		{
+			name:    "payment",
+			request: &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "PAYMENT", AmountCents: 2500, Simulate: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT balance, account_type, status, overdraft_limit FROM accounts WHERE id = \$1`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "account_type", "status", "overdraft_limit"}).AddRow(100.0, "CHECKING", "ACTIVE", 0.0))
+				// Outstanding purchases are read, not locked
+				mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0 AND status = 'COMPLETED'[^$]+ORDER BY created_at, id$`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow("purchase-1", -10.0).AddRow("purchase-2", -30.0))
+			},
+			expectedAmount:       2500,
+			expectedBalanceAfter: 12500,
+			expectedDischarges: []*pb.Discharge{
+				{TransactionId: "purchase-1", AmountCents: 1000},
+				{TransactionId: "purchase-2", AmountCents: 1500, BalanceAfterCents: -1500},
+			},
+		},
+		{
+			name:    "insufficient balance",
+			request: &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "WITHDRAWAL", AmountCents: 15000, Simulate: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT balance, account_type, status, overdraft_limit FROM accounts WHERE id = \$1`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "account_type", "status", "overdraft_limit"}).AddRow(100.0, "CHECKING", "ACTIVE", 0.0))
+			},
+			expectedError: "insufficient balance",
+		},
+		{
+			name:    "account still onboarding",
+			request: &pb.CreateTransactionRequest{AccountId: "draft-account", OperationType: "PAYMENT", AmountCents: 1000, Simulate: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT balance, account_type, status, overdraft_limit FROM accounts WHERE id = \$1`).
+					WithArgs("draft-account").
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "account_type", "status", "overdraft_limit"}).AddRow(0.0, "CHECKING", "PENDING_KYC", 0.0))
+			},
+			expectedError: "account not active",
+		},
+		{
+			name:    "account not found",
+			request: &pb.CreateTransactionRequest{AccountId: "missing-account", OperationType: "PAYMENT", AmountCents: 1000, Simulate: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT balance, account_type, status, overdraft_limit FROM accounts WHERE id = \$1`).
+					WithArgs("missing-account").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: "account not found",
+		},
+		{
+			name:          "non-positive payment",
+			request:       &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "PAYMENT", AmountCents: 0, Simulate: true},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "payment amount must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Simulations never write, so any UPDATE or INSERT fails the expectations
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.CreateTransaction(context.Background(), tt.request)
+
+			assert.NoError(t, err)
+			assert.True(t, response.Simulated)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, response.Transaction)
+				assert.Empty(t, response.Transaction.Id)
+				assert.Equal(t, tt.expectedAmount, response.Transaction.AmountCents)
+				assert.Equal(t, tt.expectedBalanceAfter, response.BalanceAfterCents)
+				assert.Equal(t, tt.expectedDischarges, response.Discharges)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_CreateTransaction_TenantPolicy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Setenv("APP_ENV", "production")
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.TenantIDMetadataKey, "issuer-a"))
+
+	// Settings are loaded once and cached across requests
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+		WithArgs("issuer-a", "production").
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).
+			AddRow([]byte(`{"allowed_operation_types":["CASH_PURCHASE","PAYMENT"],"max_transaction_amount":500}`)))
+
+	response, err := service.CreateTransaction(ctx, &pb.CreateTransactionRequest{
+		AccountId: "test-account-id", OperationType: "WITHDRAWAL", AmountCents: 1000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "operation type not allowed for tenant", response.Error)
+
+	response, err = service.CreateTransaction(ctx, &pb.CreateTransactionRequest{
+		AccountId: "test-account-id", OperationType: "CASH_PURCHASE", AmountCents: 50001,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "amount exceeds tenant limit", response.Error)
+
+	// Allowed requests proceed as usual
+	mock.ExpectQuery(`SELECT balance, account_type, status, overdraft_limit FROM accounts WHERE id = \$1`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "account_type", "status", "overdraft_limit"}).AddRow(1000.0, "CHECKING", "ACTIVE", 0.0))
+	response, err = service.CreateTransaction(ctx, &pb.CreateTransactionRequest{
+		AccountId: "test-account-id", OperationType: "CASH_PURCHASE", AmountCents: 50000, Simulate: true,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, response.Error)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var operationRuleColumns = []string{"operation_type", "direction", "requires_positive_amount", "allowed_account_types", "fee_policy", "updated_at"}
+
+func TestService_LoadOperationRules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	// Withdrawals are restricted to checking accounts and purchases are no longer offered
+	mock.ExpectQuery(`FROM operation_type_rules`).
+		WillReturnRows(sqlmock.NewRows(operationRuleColumns).
+			AddRow("PAYMENT", "CREDIT", true, "", "NONE", 1700000000).
+			AddRow("WITHDRAWAL", "DEBIT", false, "CHECKING", "TENANT_SCHEDULE", 1700000000))
+	require.NoError(t, service.LoadOperationRules(context.Background()))
+
+	response, err := service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{
+		AccountId: "test-account-id", OperationType: "CASH_PURCHASE", AmountCents: 1000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "invalid operation type", response.Error)
+
+	mock.ExpectQuery(`SELECT balance, account_type, status, overdraft_limit FROM accounts WHERE id = \$1`).
+		WithArgs("savings-account").
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "account_type", "status", "overdraft_limit"}).AddRow(100.0, "SAVINGS", "ACTIVE", 0.0))
+	response, err = service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{
+		AccountId: "savings-account", OperationType: "WITHDRAWAL", AmountCents: 1000, Simulate: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "operation type not allowed for account type", response.Error)
+
+	rules, err := service.ListOperationRules(context.Background(), &pb.ListOperationRulesRequest{})
+	require.NoError(t, err)
+	require.Len(t, rules.Rules, 2)
+	assert.Equal(t, "PAYMENT", rules.Rules[0].OperationType)
+	assert.Equal(t, []string{"CHECKING"}, rules.Rules[1].AllowedAccountTypes)
+	assert.Equal(t, "TENANT_SCHEDULE", rules.Rules[1].FeePolicy)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_UpdateOperationRule(t *testing.T) {
+	admin := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleAdmin))
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		request       *pb.UpdateOperationRuleRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name: "admin updates a rule",
+			ctx:  admin,
+			request: &pb.UpdateOperationRuleRequest{
+				OperationType: "INSTALLMENT_PURCHASE",
+				Rule:          &pb.OperationRule{Direction: "DEBIT", RequiresPositiveAmount: true, AllowedAccountTypes: []string{"CREDIT"}},
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`UPDATE operation_type_rules`).
+					WithArgs("DEBIT", true, "CREDIT", "NONE", sqlmock.AnyArg(), "INSTALLMENT_PURCHASE").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "caller is not an admin",
+			ctx:  context.Background(),
+			request: &pb.UpdateOperationRuleRequest{
+				OperationType: "PAYMENT",
+				Rule:          &pb.OperationRule{Direction: "DEBIT"},
+			},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name: "unknown operation type",
+			ctx:  admin,
+			request: &pb.UpdateOperationRuleRequest{
+				OperationType: "REFUND",
+				Rule:          &pb.OperationRule{Direction: "CREDIT"},
+			},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "invalid operation type",
+		},
+		{
+			name: "invalid direction",
+			ctx:  admin,
+			request: &pb.UpdateOperationRuleRequest{
+				OperationType: "PAYMENT",
+				Rule:          &pb.OperationRule{Direction: "SIDEWAYS"},
+			},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "direction must be CREDIT or DEBIT",
+		},
+		{
+			name: "payments cannot become debits",
+			ctx:  admin,
+			request: &pb.UpdateOperationRuleRequest{
+				OperationType: "PAYMENT",
+				Rule:          &pb.OperationRule{Direction: "DEBIT", RequiresPositiveAmount: true},
+			},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "direction of PAYMENT cannot be changed from CREDIT",
+		},
+		{
+			name: "purchases cannot become credits",
+			ctx:  admin,
+			request: &pb.UpdateOperationRuleRequest{
+				OperationType: "CASH_PURCHASE",
+				Rule:          &pb.OperationRule{Direction: "CREDIT"},
+			},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "direction of CASH_PURCHASE cannot be changed from DEBIT",
+		},
+		{
+			name: "unknown account type",
+			ctx:  admin,
+			request: &pb.UpdateOperationRuleRequest{
+				OperationType: "WITHDRAWAL",
+				Rule:          &pb.OperationRule{Direction: "DEBIT", AllowedAccountTypes: []string{"BROKERAGE"}},
+			},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "unknown account type: BROKERAGE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.UpdateOperationRule(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, response.Rule)
+				assert.Equal(t, tt.request.OperationType, response.Rule.OperationType)
+				assert.NotZero(t, response.Rule.UpdatedAt)
+
+				// The new rule applies to subsequent transactions without a reload
+				created, err := service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{
+					AccountId: "test-account-id", OperationType: tt.request.OperationType, AmountCents: -500, Simulate: true,
+				})
+				require.NoError(t, err)
+				assert.Equal(t, "installment purchase amount must be positive", created.Error)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_ImportChargebacks(t *testing.T) {
+	support := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleSupport))
+	fixedRecord := func(externalID, minorUnits, reasonCode, date string) string {
+		return fmt.Sprintf("CB%-32s%012s%-4s%s", externalID, minorUnits, reasonCode, date)
+	}
+
+	tests := []struct {
+		name              string
+		ctx               context.Context
+		request           *pb.ImportChargebacksRequest
+		mockSetup         func(sqlmock.Sqlmock)
+		expectedError     string
+		expectedOpened    []string
+		expectedDisputed  int32
+		expectedUnmatched []*pb.UnmatchedChargeback
+	}{
+		{
+			name: "csv file opens disputes and reports unmatched lines",
+			ctx:  support,
+			request: &pb.ImportChargebacksRequest{Content: []byte(
+				"external_id,amount,reason_code,network_date\n" +
+					"NET-1,50.00,4837,2026-10-01\n" +
+					"NET-2,10.00,10.4,2026-10-01\n" +
+					"NET-3,abc,4837,2026-10-01\n" +
+					"NET-4,20.00,13.1,2026-10-01\n" +
+					"NET-5,500.00,4853,2026-10-01\n" +
+					"NET-1,50.00,4837,2026-10-01\n")},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT external_id, id, account_id, amount FROM transactions WHERE external_id IN \(\$1, \$2, \$3, \$4\)`).
+					WithArgs("NET-1", "NET-2", "NET-4", "NET-5").
+					WillReturnRows(sqlmock.NewRows([]string{"external_id", "id", "account_id", "amount"}).
+						AddRow("NET-1", "txn-1", "acc-1", -50.0).
+						AddRow("NET-2", "txn-2", "acc-1", -30.0).
+						AddRow("NET-5", "txn-5", "acc-2", -100.0))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO disputes`).
+					WithArgs(sqlmock.AnyArg(), "txn-1", "acc-1", "50.00", "4837", "OPEN", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO disputes`).
+					WithArgs(sqlmock.AnyArg(), "txn-2", "acc-1", "10.00", "10.4", "OPEN", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			expectedOpened:   []string{"txn-1"},
+			expectedDisputed: 1,
+			expectedUnmatched: []*pb.UnmatchedChargeback{
+				{Line: 4, ExternalId: "NET-3", Reason: "invalid amount"},
+				{Line: 5, ExternalId: "NET-4", Reason: "transaction not found"},
+				{Line: 6, ExternalId: "NET-5", Reason: "amount exceeds transaction amount"},
+				{Line: 7, ExternalId: "NET-1", Reason: "duplicate external_id in file"},
+			},
+		},
+		{
+			name: "fixed-width file skips header and trailer records",
+			ctx:  support,
+			request: &pb.ImportChargebacksRequest{Format: "fixed", Content: []byte(
+				"HD20261001VISA\n" +
+					fixedRecord("NET-1", "2500", "4837", "20261001") + "\r\n" +
+					fixedRecord("NET-2", "1000", "4837", "20261399") + "\n" +
+					"XX garbage\n" +
+					"TR000002\n")},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT external_id, id, account_id, amount FROM transactions`).
+					WithArgs("NET-1").
+					WillReturnRows(sqlmock.NewRows([]string{"external_id", "id", "account_id", "amount"}).
+						AddRow("NET-1", "txn-1", "acc-1", -50.0))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO disputes`).
+					WithArgs(sqlmock.AnyArg(), "txn-1", "acc-1", "25.00", "4837", "OPEN", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedOpened: []string{"txn-1"},
+			expectedUnmatched: []*pb.UnmatchedChargeback{
+				{Line: 3, ExternalId: "NET-2", Reason: "invalid date"},
+				{Line: 4, Reason: "invalid line"},
+			},
+		},
+		{
+			name:          "caller is not support or admin",
+			ctx:           context.Background(),
+			request:       &pb.ImportChargebacksRequest{Content: []byte("external_id,amount,reason_code\n")},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:          "csv header is missing a column",
+			ctx:           support,
+			request:       &pb.ImportChargebacksRequest{Content: []byte("external_id,reason_code\nNET-1,4837\n")},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "missing column: amount",
+		},
+		{
+			name:          "unknown format",
+			ctx:           support,
+			request:       &pb.ImportChargebacksRequest{Format: "xml", Content: []byte("<chargebacks/>")},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "format must be csv or fixed",
+		},
+		{
+			name: "failed insert rolls back the import",
+			ctx:  support,
+			request: &pb.ImportChargebacksRequest{Content: []byte(
+				"external_id,amount,reason_code\nNET-1,50.00,4837\n")},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT external_id, id, account_id, amount FROM transactions`).
+					WillReturnRows(sqlmock.NewRows([]string{"external_id", "id", "account_id", "amount"}).
+						AddRow("NET-1", "txn-1", "acc-1", -50.0))
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO disputes`).WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			expectedError: "could not open disputes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.ImportChargebacks(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				var opened []string
+				for _, dispute := range response.Opened {
+					opened = append(opened, dispute.TransactionId)
+				}
+				assert.Equal(t, tt.expectedOpened, opened)
+				assert.Equal(t, tt.expectedDisputed, response.AlreadyDisputed)
+				assert.Equal(t, tt.expectedUnmatched, response.Unmatched)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_ReconcileSettlement(t *testing.T) {
+	support := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleSupport))
+	operator := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, common.RoleAdmin, common.OperatorIDMetadataKey, "ops-1"))
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+	october1 := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC).Unix()
+	file := []byte("external_id,amount,date,merchant\n" +
+		"NET-1,50.00,2026-10-01,Cafe\n" +
+		"NET-2,30.00,2026-10-01,Cafe\n" +
+		"NET-3,10.00,2026-10-02,Cafe\n" +
+		"NET-4,20.00,2026-10-02,Cafe\n" +
+		"NET-5,abc,2026-10-02,Cafe\n" +
+		"NET-6,15.00,2026-10-02,Cafe\n" +
+		"NET-1,50.00,2026-10-01,Cafe\n")
+	expectMatching := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery(`SELECT id, account_id, operation_type, amount, description, created_at, status, .* FROM transactions WHERE external_id IN \(\$1, \$2, \$3, \$4, \$5\)`).
+			WithArgs("NET-1", "NET-2", "NET-3", "NET-4", "NET-6").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("txn-1", "acc-1", "CASH_PURCHASE", -50.0, "", october1+3600, "COMPLETED", "NET-1", "", []byte("{}"), "", "").
+				AddRow("txn-2", "acc-1", "CASH_PURCHASE", -25.0, "", october1+7200, "COMPLETED", "NET-2", "", []byte("{}"), "", "").
+				AddRow("txn-3", "acc-2", "PAYMENT", 10.0, "", october1+7200, "COMPLETED", "NET-3", "", []byte("{}"), "", "").
+				AddRow("txn-6", "acc-2", "WITHDRAWAL", -15.0, "", october1+90000, "PENDING", "NET-6", "", []byte("{}"), "", ""))
+		mock.ExpectQuery(`FROM transactions\s+WHERE external_id IS NOT NULL AND status = 'COMPLETED' AND created_at >= \$1 AND created_at < \$2`).
+			WithArgs(october1, october1+2*86400).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("txn-1", "acc-1", "CASH_PURCHASE", -50.0, "", october1+3600, "COMPLETED", "NET-1", "", []byte("{}"), "", "").
+				AddRow("txn-7", "acc-2", "CASH_PURCHASE", -8.0, "", october1+100000, "COMPLETED", "NET-7", "", []byte("{}"), "", ""))
+	}
+
+	tests := []struct {
+		name                string
+		ctx                 context.Context
+		request             *pb.ReconcileSettlementRequest
+		mockSetup           func(sqlmock.Sqlmock)
+		expectedError       string
+		expectedAdjustments []*pb.ReconciliationAdjustment
+		expectedAdjusted    int32
+	}{
+		{
+			name:      "report only",
+			ctx:       support,
+			request:   &pb.ReconcileSettlementRequest{Content: file},
+			mockSetup: expectMatching,
+		},
+		{
+			name:    "amount differences request adjustments",
+			ctx:     operator,
+			request: &pb.ReconcileSettlementRequest{Content: file, AutoAdjust: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectMatching(mock)
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO balance_adjustments .* ON CONFLICT \(source, reference\) WHERE reference IS NOT NULL DO NOTHING`).
+					WithArgs(sqlmock.AnyArg(), "acc-1", "DEBIT", "5.00", "SETTLEMENT", "Settlement of NET-2 for 30.00, recorded as 25.00",
+						"ops-1", sqlmock.AnyArg(), "reconciliation", "txn-2").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedAdjustments: []*pb.ReconciliationAdjustment{
+				{AccountId: "acc-1", TransactionId: "txn-2", Direction: "DEBIT", AmountCents: 500},
+			},
+		},
+		{
+			name:    "reconciling again finds the adjustment requested",
+			ctx:     operator,
+			request: &pb.ReconcileSettlementRequest{Content: file, AutoAdjust: true},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectMatching(mock)
+				mock.ExpectBegin()
+				mock.ExpectExec(`INSERT INTO balance_adjustments`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			expectedAdjusted: 1,
+		},
+		{
+			name:          "adjustments need an operator",
+			ctx:           support,
+			request:       &pb.ReconcileSettlementRequest{Content: file, AutoAdjust: true},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:          "header is missing a column",
+			ctx:           support,
+			request:       &pb.ReconcileSettlementRequest{Content: []byte("external_id,amount\nNET-1,50.00\n")},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "missing column: date",
+		},
+		{
+			name:          "file spans too many days",
+			ctx:           support,
+			request:       &pb.ReconcileSettlementRequest{Content: []byte("external_id,amount,date\nNET-1,50.00,2026-08-31\nNET-2,5.00,2026-10-01\n")},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "file spans more than 31 days",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.ReconcileSettlement(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				assert.Equal(t, []*pb.ReconciliationLine{
+					{Line: 2, ExternalId: "NET-1", AmountCents: 5000, Date: "2026-10-01", TransactionId: "txn-1", AccountId: "acc-1"},
+				}, response.Matched)
+				assert.Equal(t, []*pb.ReconciliationLine{
+					{Line: 3, ExternalId: "NET-2", AmountCents: 3000, Date: "2026-10-01", TransactionId: "txn-2", AccountId: "acc-1", Reason: "amount differs"},
+					{Line: 4, ExternalId: "NET-3", AmountCents: 1000, Date: "2026-10-02", TransactionId: "txn-3", AccountId: "acc-2", Reason: "date differs"},
+					{Line: 7, ExternalId: "NET-6", AmountCents: 1500, Date: "2026-10-02", TransactionId: "txn-6", AccountId: "acc-2", Reason: "transaction not completed"},
+				}, response.Mismatched)
+				assert.Equal(t, []*pb.ReconciliationLine{
+					{Line: 5, ExternalId: "NET-4", AmountCents: 2000, Date: "2026-10-02"},
+				}, response.Missing)
+				assert.Equal(t, []*pb.ReconciliationLine{
+					{Line: 6, ExternalId: "NET-5", Reason: "invalid amount"},
+					{Line: 8, ExternalId: "NET-1", Reason: "duplicate external_id in file"},
+				}, response.Invalid)
+				require.Len(t, response.Extra, 1)
+				assert.Equal(t, "txn-7", response.Extra[0].Id)
+				assert.False(t, response.ExtraTruncated)
+
+				for _, adjustment := range response.Adjustments {
+					assert.NotEmpty(t, adjustment.Id)
+					adjustment.Id = ""
+				}
+				assert.Equal(t, tt.expectedAdjustments, response.Adjustments)
+				assert.Equal(t, tt.expectedAdjusted, response.AlreadyAdjusted)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSettlementAdjustment(t *testing.T) {
+	tests := []struct {
+		recorded          common.Cents
+		settled           common.Cents
+		expectedDirection string
+		expectedAmount    common.Cents
+	}{
+		{recorded: -2500, settled: 3000, expectedDirection: "DEBIT", expectedAmount: 500},
+		{recorded: -2500, settled: 2000, expectedDirection: "CREDIT", expectedAmount: 500},
+		{recorded: 2500, settled: 3000, expectedDirection: "CREDIT", expectedAmount: 500},
+		{recorded: 2500, settled: 2499, expectedDirection: "DEBIT", expectedAmount: 1},
+	}
+
+	for _, tt := range tests {
+		direction, amount := settlementAdjustment(&common.Transaction{Amount: tt.recorded}, tt.settled)
+		assert.Equal(t, tt.expectedDirection, direction)
+		assert.Equal(t, tt.expectedAmount, amount)
+	}
+}
+
+func TestService_UpdateTransaction(t *testing.T) {
+	support := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, common.RoleSupport, common.OperatorIDMetadataKey, "agent-7"))
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		request          *pb.UpdateTransactionRequest
+		mockSetup        func(sqlmock.Sqlmock)
+		expectedError    string
+		expectedResponse *pb.Transaction
+	}{
+		{
+			name:    "fixes the description and keeps tags and metadata",
+			ctx:     support,
+			request: &pb.UpdateTransactionRequest{Id: "tx1", Description: "Coffee shop"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM transactions WHERE id = \$1 FOR UPDATE`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("tx1", "acc-1", "CASH_PURCHASE", -4.5, "Cofee shp", 1234567890, "COMPLETED", "", "food", []byte(`{"store":"12"}`), "", ""))
+				mock.ExpectExec(`UPDATE transactions SET description = \$1, tags = \$2, metadata = \$3 WHERE id = \$4`).
+					WithArgs("Coffee shop", "food", []byte(`{"store":"12"}`), "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO transaction_edits`).
+					WithArgs(sqlmock.AnyArg(), "tx1", "agent-7", sqlmock.AnyArg(),
+						[]byte(`{"description":"Cofee shp","tags":["food"],"metadata":{"store":"12"}}`),
+						[]byte(`{"description":"Coffee shop","tags":["food"],"metadata":{"store":"12"}}`)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: &pb.Transaction{
+				Id: "tx1", AccountId: "acc-1", OperationType: "CASH_PURCHASE", AmountCents: -450, Description: "Coffee shop",
+				CreatedAt: 1234567890, Status: "COMPLETED", Tags: []string{"food"}, Metadata: map[string]string{"store": "12"},
+			},
+		},
+		{
+			name:    "update mask clears tags and metadata",
+			ctx:     support,
+			request: &pb.UpdateTransactionRequest{Id: "tx1", UpdateMask: []string{"tags", "metadata"}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM transactions WHERE id = \$1 FOR UPDATE`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("tx1", "acc-1", "PAYMENT", 10.0, "Salary", 1234567890, "COMPLETED", "", "payroll,q3", []byte(`{"batch":"7"}`), "", ""))
+				mock.ExpectExec(`UPDATE transactions`).
+					WithArgs("Salary", "", []byte("{}"), "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO transaction_edits`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedResponse: &pb.Transaction{
+				Id: "tx1", AccountId: "acc-1", OperationType: "PAYMENT", AmountCents: 1000, Description: "Salary",
+				CreatedAt: 1234567890, Status: "COMPLETED",
+			},
+		},
+		{
+			name:          "amount cannot be edited",
+			ctx:           support,
+			request:       &pb.UpdateTransactionRequest{Id: "tx1", UpdateMask: []string{"amount"}},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "field cannot be updated: amount",
+		},
+		{
+			name:          "nothing to update",
+			ctx:           support,
+			request:       &pb.UpdateTransactionRequest{Id: "tx1"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "nothing to update",
+		},
+		{
+			name:          "tags must not contain commas",
+			ctx:           support,
+			request:       &pb.UpdateTransactionRequest{Id: "tx1", Tags: []string{"a,b"}},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: `invalid tag: "a,b"`,
+		},
+		{
+			name:          "caller without operator id",
+			ctx:           metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleAdmin)),
+			request:       &pb.UpdateTransactionRequest{Id: "tx1", Description: "Coffee"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:    "transaction not found",
+			ctx:     support,
+			request: &pb.UpdateTransactionRequest{Id: "missing", Description: "Coffee"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT .* FROM transactions WHERE id = \$1 FOR UPDATE`).
+					WithArgs("missing").
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.UpdateTransaction(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			assert.Equal(t, tt.expectedResponse, response.Transaction)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// fakeExportStream is an in-memory server stream used to drive ExportTransactionHistory in tests.
+type fakeExportStream struct {
+	grpc.ServerStream
+	chunks []*pb.ExportTransactionHistoryChunk
+}
+
+func (f *fakeExportStream) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeExportStream) Send(chunk *pb.ExportTransactionHistoryChunk) error {
+	f.chunks = append(f.chunks, chunk)
+	return nil
+}
+
+func (f *fakeExportStream) file() (string, string) {
+	var data, errMsg string
+	for _, chunk := range f.chunks {
+		data += string(chunk.Data)
+		errMsg = chunk.Error
+	}
+	return data, errMsg
+}
+
+func TestService_ExportTransactionHistory(t *testing.T) {
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+	header := "id,account_id,operation_type,amount,description,status,external_id,tags,created_at\n"
+
+	tests := []struct {
+		name          string
+		request       *pb.ExportTransactionHistoryRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedFile  string
+		expectedError string
+	}{
+		{
+			name:    "shards are streamed in order",
+			request: &pb.ExportTransactionHistoryRequest{AccountId: "test-account-id", To: 10800},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM transaction_monthly_counts`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows(monthCountColumns).AddRow(int64(0), int64(3)))
+				mock.ExpectQuery(`WHERE account_id = \$1 AND created_at >= \$2 AND created_at < \$3`).
+					WithArgs("test-account-id", int64(0), int64(3600)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("txn-1", "test-account-id", "PAYMENT", 100.0, "Salary", int64(60), "COMPLETED", "", "", []byte(`{}`), "", "").
+						AddRow("txn-2", "test-account-id", "CASH_PURCHASE", -12.5, "Lunch, with tip", int64(120), "COMPLETED", "", "food,team", []byte(`{}`), "", ""))
+				mock.ExpectQuery(`WHERE account_id = \$1 AND created_at >= \$2 AND created_at < \$3`).
+					WithArgs("test-account-id", int64(3600), int64(7200)).
+					WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectQuery(`WHERE account_id = \$1 AND created_at >= \$2 AND created_at < \$3`).
+					WithArgs("test-account-id", int64(7200), int64(10800)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("txn-3", "test-account-id", "WITHDRAWAL", -20.0, nil, int64(7300), "COMPLETED", "atm-1", "", []byte(`{}`), "", ""))
+			},
+			expectedFile: header +
+				"txn-1,test-account-id,PAYMENT,100.00,Salary,COMPLETED,,,1970-01-01T00:01:00Z\n" +
+				"txn-2,test-account-id,CASH_PURCHASE,-12.50,\"Lunch, with tip\",COMPLETED,,\"food,team\",1970-01-01T00:02:00Z\n" +
+				"txn-3,test-account-id,WITHDRAWAL,-20.00,,COMPLETED,atm-1,,1970-01-01T02:01:40Z\n",
+		},
+		{
+			name:          "missing account id",
+			request:       &pb.ExportTransactionHistoryRequest{},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "account_id required",
+		},
+		{
+			name:          "unsupported format",
+			request:       &pb.ExportTransactionHistoryRequest{AccountId: "test-account-id", Format: "parquet"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "format must be csv",
+		},
+		{
+			name:          "from after to",
+			request:       &pb.ExportTransactionHistoryRequest{AccountId: "test-account-id", From: 200, To: 100},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "from must be before to",
+		},
+		{
+			name:    "failed shard ends the export",
+			request: &pb.ExportTransactionHistoryRequest{AccountId: "test-account-id", From: 100, To: 200},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM transaction_monthly_counts`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows(monthCountColumns).AddRow(int64(0), int64(5)))
+				mock.ExpectQuery(`WHERE account_id = \$1 AND created_at >= \$2 AND created_at < \$3`).
+					WithArgs("test-account-id", int64(100), int64(200)).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedFile:  header,
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			mock.MatchExpectationsInOrder(false)
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			service.SetExportWorkers(2)
+			stream := &fakeExportStream{}
+
+			err = service.ExportTransactionHistory(tt.request, stream)
+			assert.NoError(t, err)
+
+			file, errMsg := stream.file()
+			assert.Equal(t, tt.expectedFile, file)
+			assert.Equal(t, tt.expectedError, errMsg)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSplitExportRange(t *testing.T) {
+	assert.Nil(t, splitExportRange(100, 100, 8))
+	assert.Equal(t, []exportShard{{from: 0, to: 3600}, {from: 3600, to: 5000}}, splitExportRange(0, 5000, 8))
+	assert.Equal(t, []exportShard{{from: 0, to: 5000}, {from: 5000, to: 10000}}, splitExportRange(0, 10000, 2))
+}
+
+func TestSplitExportMonths(t *testing.T) {
+	jan, feb, mar, apr, may, jun := int64(1230768000), int64(1233446400), int64(1235865600), int64(1238544000), int64(1241136000), int64(1243814400)
+	months := []common.MonthCount{{Start: jan, Count: 1}, {Start: feb, Count: 1}, {Start: mar, Count: 2}, {Start: may, Count: 4}}
+
+	assert.Nil(t, splitExportMonths(nil, jan, jun, 4))
+	assert.Nil(t, splitExportMonths(months, jun, jun+3600, 4))
+
+	// Quiet months share a shard, the busy May is split and the empty April is not queried
+	assert.Equal(t, []exportShard{
+		{from: jan, to: mar},
+		{from: mar, to: apr},
+		{from: may, to: may + (jun-may)/2},
+		{from: may + (jun-may)/2, to: jun},
+	}, splitExportMonths(months, jan, jun, 4))
+
+	// Shards are clamped to the requested range
+	assert.Equal(t, []exportShard{{from: feb + 10, to: feb + 20}}, splitExportMonths(months, feb+10, feb+20, 4))
+}
+
+func TestService_GetTransactionTimeline(t *testing.T) {
+	support := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleSupport))
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+	editColumns := []string{"id", "edited_by", "edited_at", "previous", "updated"}
+	disputeColumns := []string{"id", "transaction_id", "account_id", "amount", "reason_code", "status", "opened_at"}
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		request        *pb.GetTransactionTimelineRequest
+		mockSetup      func(sqlmock.Sqlmock)
+		expectedError  string
+		expectedEvents []string
+	}{
+		{
+			name:    "edits and dispute in chronological order",
+			ctx:     support,
+			request: &pb.GetTransactionTimelineRequest{Id: "tx1"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM transactions WHERE id = \$1`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("tx1", "acc-1", "CASH_PURCHASE", -40.0, "Cofee", 1000, "COMPLETED", "", "", []byte(`{}`), "", ""))
+				mock.ExpectQuery(`FROM transaction_edits WHERE transaction_id = \$1`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows(editColumns).
+						AddRow("edit-1", "agent-7", 1200, []byte(`{"description":"Cofee","tags":null,"metadata":null}`), []byte(`{"description":"Coffee","tags":null,"metadata":null}`)).
+						AddRow("edit-2", "agent-8", 3000, []byte(`{"description":"Coffee","tags":null,"metadata":null}`), []byte(`{"description":"Coffee","tags":["food"],"metadata":null}`)))
+				mock.ExpectQuery(`FROM disputes WHERE transaction_id = \$1`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows(disputeColumns).
+						AddRow("dispute-1", "tx1", "acc-1", 40.0, "4837", "OPEN", 2000))
+			},
+			expectedEvents: []string{"CREATED@1000", "EDITED@1200", "DISPUTE_OPENED@2000", "EDITED@3000"},
+		},
+		{
+			name:    "transaction without linked records",
+			ctx:     support,
+			request: &pb.GetTransactionTimelineRequest{Id: "tx1"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM transactions WHERE id = \$1`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("tx1", "acc-1", "PAYMENT", 100.0, "", 1000, "COMPLETED", "", "", []byte(`{}`), "", ""))
+				mock.ExpectQuery(`FROM transaction_edits`).WillReturnRows(sqlmock.NewRows(editColumns))
+				mock.ExpectQuery(`FROM disputes`).WillReturnError(sql.ErrNoRows)
+			},
+			expectedEvents: []string{"CREATED@1000"},
+		},
+		{
+			name:          "customers cannot read timelines",
+			ctx:           context.Background(),
+			request:       &pb.GetTransactionTimelineRequest{Id: "tx1"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:    "transaction not found",
+			ctx:     support,
+			request: &pb.GetTransactionTimelineRequest{Id: "missing"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM transactions WHERE id = \$1`).
+					WithArgs("missing").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: "not found",
+		},
+		{
+			name:    "dispute lookup fails",
+			ctx:     support,
+			request: &pb.GetTransactionTimelineRequest{Id: "tx1"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT .* FROM transactions WHERE id = \$1`).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("tx1", "acc-1", "PAYMENT", 100.0, "", 1000, "COMPLETED", "", "", []byte(`{}`), "", ""))
+				mock.ExpectQuery(`FROM transaction_edits`).WillReturnRows(sqlmock.NewRows(editColumns))
+				mock.ExpectQuery(`FROM disputes`).WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.GetTransactionTimeline(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+
+			var events []string
+			for _, event := range response.Events {
+				events = append(events, fmt.Sprintf("%s@%d", event.Type, event.OccurredAt))
+			}
+			assert.Equal(t, tt.expectedEvents, events)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_ListStuckTransactions(t *testing.T) {
+	admin := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleAdmin))
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		request       *pb.ListStuckTransactionsRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+		expectedIDs   []string
+	}{
+		{
+			name:    "lists pending transactions older than the default threshold",
+			ctx:     admin,
+			request: &pb.ListStuckTransactionsRequest{},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM transactions\s+WHERE status = 'PENDING' AND created_at <= \$1\s+ORDER BY created_at, id\s+LIMIT \$2`).
+					WithArgs(sqlmock.AnyArg(), int32(100)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("tx1", "acc-1", "CASH_PURCHASE", -20.0, "", 1000, "PENDING", "", "", []byte(`{}`), "", "").
+						AddRow("tx2", "acc-2", "PAYMENT", 50.0, "", 1100, "PENDING", "", "", []byte(`{}`), "", ""))
+			},
+			expectedIDs: []string{"tx1", "tx2"},
+		},
+		{
+			name:          "only admins may list stuck transactions",
+			ctx:           metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleSupport)),
+			request:       &pb.ListStuckTransactionsRequest{},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:          "negative threshold",
+			ctx:           admin,
+			request:       &pb.ListStuckTransactionsRequest{OlderThanSeconds: -1},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "older_than_seconds must not be negative",
+		},
+		{
+			name:    "database error",
+			ctx:     admin,
+			request: &pb.ListStuckTransactionsRequest{OlderThanSeconds: 60, Limit: 10},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM transactions`).WithArgs(sqlmock.AnyArg(), int32(10)).WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.ListStuckTransactions(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+
+			var ids []string
+			for _, transaction := range response.Transactions {
+				ids = append(ids, transaction.Id)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_ResolveStuckTransactions(t *testing.T) {
+	admin := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, common.RoleAdmin, common.OperatorIDMetadataKey, "ops-1"))
+	pendingColumns := []string{"account_id", "operation_type", "amount", "status", "balance"}
+
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		request         *pb.ResolveStuckTransactionsRequest
+		mockSetup       func(sqlmock.Sqlmock)
+		expectedError   string
+		expectedResults []string
+	}{
+		{
+			name:    "completes pending transactions and applies them to the balance",
+			ctx:     admin,
+			request: &pb.ResolveStuckTransactionsRequest{Ids: []string{"tx1", "tx2"}, Action: "COMPLETE", Reason: "acquirer confirmed settlement"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT account_id FROM transactions WHERE id = \$1`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("acc-1"))
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT account_id, operation_type, amount, status, balance FROM transactions WHERE id = \$1 FOR UPDATE`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows(pendingColumns).AddRow("acc-1", "CASH_PURCHASE", -20.0, "PENDING", 0.0))
+				mock.ExpectQuery(`UPDATE accounts\s+SET balance = balance \+ \$1, updated_at = \$2\s+WHERE id = \$3 AND \(\$1 >= 0 OR balance \+ \$1 >= -overdraft_limit\)\s+RETURNING balance`).
+					WithArgs("-20.00", sqlmock.AnyArg(), "acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(80.0))
+				mock.ExpectExec(`UPDATE transactions SET status = \$1, overdrawn = \$2 WHERE id = \$3`).
+					WithArgs("COMPLETED", false, "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO transaction_resolutions`).
+					WithArgs(sqlmock.AnyArg(), "tx1", "COMPLETE", "PENDING", "COMPLETED", "acquirer confirmed settlement", "ops-1", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				mock.ExpectQuery(`SELECT account_id FROM transactions WHERE id = \$1`).
+					WithArgs("tx2").
+					WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("acc-2"))
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).
+					WithArgs("tx2").
+					WillReturnRows(sqlmock.NewRows(pendingColumns).AddRow("acc-2", "CASH_PURCHASE", -500.0, "PENDING", 0.0))
+				mock.ExpectQuery(`UPDATE accounts`).
+					WithArgs("-500.00", sqlmock.AnyArg(), "acc-2").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}))
+				mock.ExpectRollback()
+			},
+			expectedResults: []string{"tx1:COMPLETED", "tx2:insufficient balance"},
+		},
+		{
+			name:    "completed payment discharges outstanding purchases",
+			ctx:     admin,
+			request: &pb.ResolveStuckTransactionsRequest{Ids: []string{"payment-1"}, Action: "COMPLETE", Reason: "acquirer confirmed settlement"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT account_id FROM transactions WHERE id = \$1`).
+					WithArgs("payment-1").
+					WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("acc-1"))
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).
+					WithArgs("payment-1").
+					WillReturnRows(sqlmock.NewRows(pendingColumns).AddRow("acc-1", "PAYMENT", 50.0, "PENDING", 50.0))
+				mock.ExpectQuery(`UPDATE accounts`).
+					WithArgs("50.00", sqlmock.AnyArg(), "acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(150.0))
+				mock.ExpectQuery(`SELECT id, balance FROM transactions\s+WHERE account_id = \$1 AND balance < 0 AND status = 'COMPLETED'.* FOR UPDATE`).
+					WithArgs("acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow("purchase-1", -30.0))
+				mock.ExpectExec(`UPDATE transactions SET balance = balance \+ \$1 WHERE id = \$2`).
+					WithArgs("30.00", "purchase-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO payment_discharges \(payment_id, debt_id, amount\) VALUES \(\$1, \$2, \$3\)`).
+					WithArgs("payment-1", "purchase-1", "30.00").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transactions SET balance = \$1 WHERE id = \$2`).
+					WithArgs("20.00", "payment-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transactions SET status = \$1, overdrawn = \$2 WHERE id = \$3`).
+					WithArgs("COMPLETED", false, "payment-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO transaction_resolutions`).
+					WithArgs(sqlmock.AnyArg(), "payment-1", "COMPLETE", "PENDING", "COMPLETED", "acquirer confirmed settlement", "ops-1", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedResults: []string{"payment-1:COMPLETED"},
+		},
+		{
+			name:    "reversal cancels without touching the balance",
+			ctx:     admin,
+			request: &pb.ResolveStuckTransactionsRequest{Ids: []string{"tx1", "tx3", "tx1"}, Action: "REVERSE", Reason: "withdrawn by network"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows(pendingColumns).AddRow("acc-1", "CASH_PURCHASE", -20.0, "PENDING", 0.0))
+				mock.ExpectExec(`UPDATE transactions SET status = \$1, overdrawn = \$2 WHERE id = \$3`).
+					WithArgs("CANCELLED", false, "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO transaction_resolutions`).
+					WithArgs(sqlmock.AnyArg(), "tx1", "REVERSE", "PENDING", "CANCELLED", "withdrawn by network", "ops-1", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).
+					WithArgs("tx3").
+					WillReturnRows(sqlmock.NewRows(pendingColumns).AddRow("acc-1", "CASH_PURCHASE", 10.0, "COMPLETED", 0.0))
+				mock.ExpectRollback()
+			},
+			expectedResults: []string{"tx1:CANCELLED", "tx3:transaction is not pending", "tx1:duplicate id"},
+		},
+		{
+			name:    "missing transaction",
+			ctx:     admin,
+			request: &pb.ResolveStuckTransactionsRequest{Ids: []string{"missing"}, Action: "FAIL", Reason: "timed out"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedResults: []string{"missing:not found"},
+		},
+		{
+			name:          "admin without operator id",
+			ctx:           metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleAdmin)),
+			request:       &pb.ResolveStuckTransactionsRequest{Ids: []string{"tx1"}, Action: "FAIL", Reason: "timed out"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:          "unknown action",
+			ctx:           admin,
+			request:       &pb.ResolveStuckTransactionsRequest{Ids: []string{"tx1"}, Action: "RETRY", Reason: "timed out"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "action must be COMPLETE, FAIL or REVERSE",
+		},
+		{
+			name:          "reason is required for the audit trail",
+			ctx:           admin,
+			request:       &pb.ResolveStuckTransactionsRequest{Ids: []string{"tx1"}, Action: "FAIL"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "reason required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.ResolveStuckTransactions(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+
+			var results []string
+			for _, result := range response.Results {
+				if result.Resolution != nil {
+					assert.Equal(t, "ops-1", result.Resolution.ResolvedBy)
+					results = append(results, result.TransactionId+":"+result.Resolution.Status)
+				} else {
+					results = append(results, result.TransactionId+":"+result.Error)
+				}
+			}
+			assert.Equal(t, tt.expectedResults, results)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_CreateTransaction_RecordsAuthorizations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	kpis := common.NewBusinessMetrics(db, logger)
+	service.SetBusinessMetrics(kpis)
+
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("test-account-id", "12345678901", "CHECKING", 20.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+
+	resp, err := service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "WITHDRAWAL", AmountCents: 5000})
+	require.NoError(t, err)
+	assert.Equal(t, "insufficient balance", resp.Error)
+
+	resp, err = service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "BOGUS", AmountCents: 5000})
+	require.NoError(t, err)
+	assert.Equal(t, "invalid operation type", resp.Error)
+
+	// Simulations are not counted
+	_, err = service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{OperationType: "PAYMENT", Simulate: true})
+	require.NoError(t, err)
+
+	mock.ExpectQuery(`FROM accounts`).WillReturnRows(sqlmock.NewRows([]string{"account_type", "sum"}))
+	mock.ExpectQuery(`FROM transactions`).WillReturnRows(sqlmock.NewRows([]string{"operation_type", "count"}))
+	var out strings.Builder
+	require.NoError(t, kpis.WriteOpenMetrics(context.Background(), &out))
+	assert.Contains(t, out.String(), `pismo_transaction_authorizations_total{operation_type="UNKNOWN",outcome="declined",reason="invalid operation type"} 1`)
+	assert.Contains(t, out.String(), `pismo_transaction_authorizations_total{operation_type="WITHDRAWAL",outcome="declined",reason="insufficient balance"} 1`)
+	assert.NotContains(t, out.String(), `operation_type="PAYMENT"`)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_RecordsBusinessEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Setenv("APP_ENV", "production")
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	reader := sdkmetric.NewManualReader()
+	require.NoError(t, service.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithView(BusinessEventViews()...))))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.TenantIDMetadataKey, "issuer-a", common.CallerRoleMetadataKey, common.RoleSupport, common.OperatorIDMetadataKey, "agent-7"))
+
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+		WithArgs("issuer-a", "production").
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{"max_transaction_amount":500}`)))
+	resp, err := service.CreateTransaction(ctx, &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "CASH_PURCHASE", AmountCents: 50001})
+	require.NoError(t, err)
+	assert.Equal(t, "amount exceeds tenant limit", resp.Error)
+
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("test-account-id", "12345678901", "CHECKING", 20.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	resp, err = service.CreateTransaction(ctx, &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "WITHDRAWAL", AmountCents: 5000})
+	require.NoError(t, err)
+	assert.Equal(t, "insufficient balance", resp.Error)
+
+	// Other declines are not business events
+	resp, err = service.CreateTransaction(ctx, &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "BOGUS", AmountCents: 5000})
+	require.NoError(t, err)
+	assert.Equal(t, "invalid operation type", resp.Error)
+
+	mock.ExpectQuery(`SELECT account_id FROM transactions WHERE id = \$1`).
+		WithArgs("tx1").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("test-account-id"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM transactions WHERE id = \$1 FOR UPDATE`).
+		WithArgs("tx1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}).
+			AddRow("tx1", "test-account-id", "CASH_PURCHASE", -40.0, "Coffee", 1000, "COMPLETED", "", "", []byte(`{}`), "", ""))
+	mock.ExpectQuery(`UPDATE accounts`).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(140.0))
+	mock.ExpectExec(`UPDATE transactions SET status = 'REVERSED'`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO transactions`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	reversed, err := service.ReverseTransaction(ctx, &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Merchant refund"})
+	require.NoError(t, err)
+	require.Empty(t, reversed.Error)
+
+	var collected metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &collected))
+	require.Len(t, collected.ScopeMetrics, 1)
+	counts := make(map[string]int64)
+	for _, m := range collected.ScopeMetrics[0].Metrics {
+		for _, point := range m.Data.(metricdata.Sum[int64]).DataPoints {
+			// The tenant is left out of the exported series
+			assert.Equal(t, 1, point.Attributes.Len())
+			operationType, _ := point.Attributes.Value(operationTypeAttribute)
+			counts[m.Name+"/"+operationType.AsString()] += point.Value
+		}
+	}
+	assert.Equal(t, map[string]int64{
+		"limit_exceeded_rejections/CASH_PURCHASE":    1,
+		"insufficient_balance_rejections/WITHDRAWAL": 1,
+		"reversals/CASH_PURCHASE":                    1,
+	}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_TracesMoneyMovement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	recorder := tracetest.NewSpanRecorder()
+	service.SetTracerProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(MoneyMovementSampler(0)),
+		sdktrace.WithSpanProcessor(NewForensicSpanProcessor(recorder)),
+	))
+	accountRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "balance", "status", "overdraft_limit"}).AddRow("acc-a", 5.0, "ACTIVE", 0.0).AddRow("acc-b", 100.0, "ACTIVE", 0.0)
+	}
+	transfer := func(amountCents int64) *pb.TransferResponse {
+		resp, err := service.Transfer(context.Background(), &pb.TransferRequest{SourceAccountId: "acc-b", DestinationAccountId: "acc-a", AmountCents: amountCents})
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Successful money movements are left to the sample ratio, which is zero here
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM accounts WHERE id IN`).WillReturnRows(accountRows())
+	mock.ExpectExec(`UPDATE accounts SET balance = balance \+ \$1`).WithArgs("-30.00", sqlmock.AnyArg(), "acc-b").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO transactions`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE accounts SET balance = balance \+ \$1`).WithArgs("30.00", sqlmock.AnyArg(), "acc-a").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO transactions`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.Empty(t, transfer(3000).Error)
+	assert.Empty(t, recorder.Ended())
+
+	// Failed ones are exported regardless
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM accounts WHERE id IN`).WillReturnRows(accountRows())
+	mock.ExpectRollback()
+	assert.Equal(t, "insufficient balance", transfer(13000).Error)
+	require.Len(t, recorder.Ended(), 1)
+	failed := recorder.Ended()[0]
+	assert.Equal(t, "transaction.transfer", failed.Name())
+	assert.True(t, failed.SpanContext().IsSampled())
+	assert.Equal(t, otelcodes.Error, failed.Status().Code)
+	assert.Equal(t, "insufficient balance", failed.Status().Description)
+
+	// And so are reversals, with their balance mutation
+	support := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, common.RoleSupport, common.OperatorIDMetadataKey, "agent-7"))
+	mock.ExpectQuery(`SELECT account_id FROM transactions WHERE id = \$1`).
+		WithArgs("tx1").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("acc-a"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM transactions WHERE id = \$1 FOR UPDATE`).
+		WithArgs("tx1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}).
+			AddRow("tx1", "acc-a", "CASH_PURCHASE", -40.0, "Coffee", 1000, "COMPLETED", "", "", []byte(`{}`), "", ""))
+	mock.ExpectQuery(`UPDATE accounts`).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(140.0))
+	mock.ExpectExec(`UPDATE transactions SET status = 'REVERSED'`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO transactions`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	reversed, err := service.ReverseTransaction(support, &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Merchant refund"})
+	require.NoError(t, err)
+	assert.Empty(t, reversed.Error)
+	require.Len(t, recorder.Ended(), 2)
+	reversal := recorder.Ended()[1]
+	assert.Equal(t, "transaction.reverse", reversal.Name())
+	assert.True(t, reversal.SpanContext().IsSampled())
+	assert.Equal(t, otelcodes.Unset, reversal.Status().Code)
+	require.Len(t, reversal.Events(), 1)
+	mutation := reversal.Events()[0]
+	assert.Equal(t, balanceMutationEvent, mutation.Name)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		accountIDAttribute.String("acc-a"),
+		transactionIDAttribute.String(reversed.Reversal.Id),
+		operationTypeAttribute.String("REVERSAL"),
+		amountAttribute.Int64(4000),
+		balanceBeforeAttribute.Int64(10000),
+		balanceAfterAttribute.Int64(14000),
+	}, mutation.Attributes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRiskPolicy_assess(t *testing.T) {
+	policy := RiskPolicy{ReviewScore: DefaultRiskReviewScore, LargeAmount: 100000, VelocityLimit: 3, VelocityWindow: DefaultRiskVelocityWindow}
+
+	tests := []struct {
+		name            string
+		policy          RiskPolicy
+		amount          common.Cents
+		balance         common.Cents
+		recentDebits    int
+		expectedScore   int
+		expectedFactors []string
+	}{
+		{name: "ordinary debit", policy: policy, amount: -5000, balance: 50000, recentDebits: 1},
+		{name: "large amount", policy: policy, amount: -100000, balance: 500000, expectedScore: 60, expectedFactors: []string{riskLargeAmount}},
+		{name: "burst of debits", policy: policy, amount: -1000, balance: 50000, recentDebits: 3, expectedScore: 50, expectedFactors: []string{riskHighVelocity}},
+		{name: "burst draining the balance", policy: policy, amount: -46000, balance: 50000, recentDebits: 3, expectedScore: 80,
+			expectedFactors: []string{riskHighVelocity, riskDrainsBalance}},
+		{name: "credits are not scored", policy: policy, amount: 500000, balance: 0, recentDebits: 10},
+		{name: "disabled policy", policy: RiskPolicy{ReviewScore: DefaultRiskReviewScore}, amount: -500000, balance: 500000, recentDebits: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, factors := tt.policy.assess(tt.amount, tt.balance, tt.recentDebits)
+			assert.Equal(t, tt.expectedScore, score)
+			assert.Equal(t, tt.expectedFactors, factors)
+		})
+	}
+}
+
+func TestRiskPolicyFromEnv(t *testing.T) {
+	t.Setenv("RISK_LARGE_AMOUNT", "2500")
+	t.Setenv("RISK_VELOCITY_LIMIT", "invalid")
+	t.Setenv("RISK_VELOCITY_WINDOW", "5m")
+
+	policy := RiskPolicyFromEnv()
+	assert.True(t, policy.Enabled())
+	assert.Equal(t, RiskPolicy{ReviewScore: DefaultRiskReviewScore, LargeAmount: 250000, VelocityWindow: 5 * time.Minute}, policy)
+}
+
+func TestService_CreateTransaction_HoldsFlaggedDebits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	service.SetRiskPolicy(RiskPolicy{ReviewScore: DefaultRiskReviewScore, LargeAmount: 100000, VelocityLimit: 5, VelocityWindow: time.Minute})
+	accountColumns := []string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}
+
+	// A large debit is recorded for review and leaves the balance unchanged
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("acc-1").
+		WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("acc-1", "12345678901", "CHECKING", 5000.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT account_id, COUNT\(\*\) FROM transactions\s+WHERE created_at >= \$1 AND amount < 0 AND account_id IN \(\$2\)`).
+		WithArgs(sqlmock.AnyArg(), "acc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "count"}).AddRow("acc-1", 1))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(sqlmock.AnyArg(), "acc-1", "WITHDRAWAL", "-1500.00", "", sqlmock.AnyArg(), "UNDER_REVIEW", "", []byte("{}"), "-1500.00", false, "", "0.00", 0.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO transaction_reviews \(transaction_id, risk_score, risk_factors, flagged_at\) VALUES \(\$1, \$2, \$3, \$4\)`).
+		WithArgs(sqlmock.AnyArg(), 60, "LARGE_AMOUNT", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err := service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{AccountId: "acc-1", OperationType: "WITHDRAWAL", AmountCents: 150000})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Error)
+	assert.Equal(t, "UNDER_REVIEW", resp.Transaction.Status)
+
+	// An ordinary debit is applied as usual
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("acc-1").
+		WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("acc-1", "12345678901", "CHECKING", 5000.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT account_id, COUNT\(\*\) FROM transactions`).
+		WithArgs(sqlmock.AnyArg(), "acc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "count"}).AddRow("acc-1", 2))
+	mock.ExpectQuery(`UPDATE accounts`).
+		WithArgs("-20.00", sqlmock.AnyArg(), "acc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100.0))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(sqlmock.AnyArg(), "acc-1", "WITHDRAWAL", "-20.00", "", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "-20.00", false, "", "0.00", 0.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err = service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{AccountId: "acc-1", OperationType: "WITHDRAWAL", AmountCents: 2000})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Error)
+	assert.Equal(t, "COMPLETED", resp.Transaction.Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_createTransactionBatch_HoldsFlaggedDebits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, account_type, balance, status, overdraft_limit FROM accounts WHERE id IN`).
+		WithArgs("account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_type", "balance", "status", "overdraft_limit"}).AddRow("account-a", "CHECKING", 100.00, "ACTIVE", 0.0))
+	mock.ExpectQuery(`SELECT account_id, COUNT\(\*\) FROM transactions`).
+		WithArgs(sqlmock.AnyArg(), "account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "count"}).AddRow("account-a", 1))
+	// The second debit exceeds the velocity limit and drains the balance, so only the first is applied
+	mock.ExpectExec(`UPDATE accounts AS a`).
+		WithArgs(sqlmock.AnyArg(), "account-a", "-10.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(
+			sqlmock.AnyArg(), "account-a", "CASH_PURCHASE", "-10.00", "", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "-10.00", false,
+			sqlmock.AnyArg(), "account-a", "WITHDRAWAL", "-85.00", "", sqlmock.AnyArg(), "UNDER_REVIEW", "", []byte("{}"), "-85.00", false,
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO transaction_reviews`).
+		WithArgs(sqlmock.AnyArg(), 80, "HIGH_VELOCITY,DRAINS_BALANCE", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	service.SetRiskPolicy(RiskPolicy{ReviewScore: DefaultRiskReviewScore, VelocityLimit: 2, VelocityWindow: time.Minute})
+
+	results := service.createTransactionBatch(context.Background(), []*pb.CreateTransactionRequest{
+		{AccountId: "account-a", OperationType: "CASH_PURCHASE", AmountCents: 1000},
+		{AccountId: "account-a", OperationType: "WITHDRAWAL", AmountCents: 8500},
+	})
+	require.Len(t, results, 2)
+	assert.Equal(t, "COMPLETED", results[0].transaction.Status)
+	assert.Equal(t, "UNDER_REVIEW", results[1].transaction.Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+var flaggedTransactionColumns = []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id",
+	"risk_score", "risk_factors", "flagged_at", "decision", "note", "reviewed_by", "reviewed_at"}
+
+func TestService_ListFlaggedTransactions(t *testing.T) {
+	support := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleSupport))
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		request       *pb.ListFlaggedTransactionsRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+		expectedIDs   []string
+	}{
+		{
+			name:    "lists the review queue oldest first",
+			ctx:     support,
+			request: &pb.ListFlaggedTransactionsRequest{},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM transactions JOIN transaction_reviews ON transaction_id = id\s+WHERE decision IS NULL AND \(\$1 = '' OR account_id = \$1\)\s+ORDER BY flagged_at, id\s+LIMIT \$2`).
+					WithArgs("", int32(100)).
+					WillReturnRows(sqlmock.NewRows(flaggedTransactionColumns).
+						AddRow("tx1", "acc-1", "WITHDRAWAL", -1500.0, "", 1000, "UNDER_REVIEW", "", "", []byte(`{}`), "", "", 60, "LARGE_AMOUNT", 1000, "", "", "", 0).
+						AddRow("tx2", "acc-2", "CASH_PURCHASE", -90.0, "", 1100, "UNDER_REVIEW", "", "", []byte(`{}`), "", "", 80, "HIGH_VELOCITY,DRAINS_BALANCE", 1100, "", "", "", 0))
+			},
+			expectedIDs: []string{"tx1", "tx2"},
+		},
+		{
+			name:    "filters by account",
+			ctx:     support,
+			request: &pb.ListFlaggedTransactionsRequest{AccountId: "acc-2", Limit: 10},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM transactions JOIN transaction_reviews`).
+					WithArgs("acc-2", int32(10)).
+					WillReturnRows(sqlmock.NewRows(flaggedTransactionColumns).
+						AddRow("tx2", "acc-2", "CASH_PURCHASE", -90.0, "", 1100, "UNDER_REVIEW", "", "", []byte(`{}`), "", "", 80, "HIGH_VELOCITY,DRAINS_BALANCE", 1100, "", "", "", 0))
+			},
+			expectedIDs: []string{"tx2"},
+		},
+		{
+			name:          "customers may not list the queue",
+			ctx:           context.Background(),
+			request:       &pb.ListFlaggedTransactionsRequest{},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+		{
+			name:    "database error",
+			ctx:     support,
+			request: &pb.ListFlaggedTransactionsRequest{},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM transactions JOIN transaction_reviews`).WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.ListFlaggedTransactions(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+
+			var ids []string
+			for _, flagged := range response.Transactions {
+				ids = append(ids, flagged.Transaction.Id)
+				assert.NotEmpty(t, flagged.RiskFactors)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_ReviewFlagged(t *testing.T) {
+	analyst := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, common.RoleSupport, common.OperatorIDMetadataKey, "analyst-1"))
+	underReview := func() *sqlmock.Rows {
+		return sqlmock.NewRows(flaggedTransactionColumns).
+			AddRow("tx1", "acc-1", "WITHDRAWAL", -1500.0, "", 1000, "UNDER_REVIEW", "", "", []byte(`{}`), "", "", 60, "LARGE_AMOUNT", 1000, "", "", "", 0)
+	}
+	heldInstallmentDebit := func() *sqlmock.Rows {
+		return sqlmock.NewRows(flaggedTransactionColumns).
+			AddRow("tx1", "acc-1", "INSTALLMENT_PURCHASE", -1500.0, "Installment 2/3: Laptop", 1000, "UNDER_REVIEW", "", "", []byte(`{}`), "", "", 60, "LARGE_AMOUNT", 1000, "", "", "", 0)
+	}
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		approve        bool
+		note           string
+		mockSetup      func(sqlmock.Sqlmock)
+		expectedError  string
+		expectedStatus string
+	}{
+		{
+			name:    "approval applies the transaction to the balance",
+			ctx:     analyst,
+			approve: true,
+			note:    "customer confirmed by phone",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT account_id FROM transactions WHERE id = \$1`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("acc-1"))
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM transactions JOIN transaction_reviews ON transaction_id = id\s+WHERE id = \$1\s+FOR UPDATE`).
+					WithArgs("tx1").
+					WillReturnRows(underReview())
+				mock.ExpectQuery(`SELECT balance, status, overdraft_limit FROM accounts WHERE id = \$1 FOR UPDATE`).
+					WithArgs("acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "status", "overdraft_limit"}).AddRow(5000.0, "ACTIVE", 0.0))
+				mock.ExpectExec(`UPDATE accounts SET balance = balance \+ \$1, updated_at = \$2 WHERE id = \$3`).
+					WithArgs("-1500.00", sqlmock.AnyArg(), "acc-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transactions SET status = \$1, overdrawn = \$2 WHERE id = \$3`).
+					WithArgs("COMPLETED", false, "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transaction_reviews SET decision = \$1, note = \$2, reviewed_by = \$3, reviewed_at = \$4`).
+					WithArgs("APPROVE", "customer confirmed by phone", "analyst-1", sqlmock.AnyArg(), "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedStatus: "COMPLETED",
+		},
+		{
+			name:    "approval is subject to the balance check",
+			ctx:     analyst,
+			approve: true,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT account_id FROM transactions WHERE id = \$1`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("acc-1"))
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).WithArgs("tx1").WillReturnRows(underReview())
+				mock.ExpectQuery(`SELECT balance, status, overdraft_limit FROM accounts`).
+					WithArgs("acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "status", "overdraft_limit"}).AddRow(1000.0, "ACTIVE", 400.0))
+				mock.ExpectRollback()
+			},
+			expectedError: "insufficient balance",
+		},
+		{
+			name:    "approval may draw on the overdraft",
+			ctx:     analyst,
+			approve: true,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT account_id FROM transactions WHERE id = \$1`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("acc-1"))
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).WithArgs("tx1").WillReturnRows(underReview())
+				mock.ExpectQuery(`SELECT balance, status, overdraft_limit FROM accounts`).
+					WithArgs("acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "status", "overdraft_limit"}).AddRow(1000.0, "ACTIVE", 500.0))
+				mock.ExpectExec(`UPDATE accounts SET balance = balance \+ \$1`).
+					WithArgs("-1500.00", sqlmock.AnyArg(), "acc-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transactions SET status = \$1, overdrawn = \$2 WHERE id = \$3`).
+					WithArgs("COMPLETED", true, "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transaction_reviews`).
+					WithArgs("APPROVE", "", "analyst-1", sqlmock.AnyArg(), "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedStatus: "COMPLETED",
+		},
+		{
+			name: "decline leaves the balance unchanged",
+			ctx:  analyst,
+			note: "card reported stolen",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).WithArgs("tx1").WillReturnRows(underReview())
+				mock.ExpectExec(`UPDATE transactions SET status = \$1, overdrawn = \$2 WHERE id = \$3`).
+					WithArgs("DECLINED", false, "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transaction_reviews`).
+					WithArgs("DECLINE", "card reported stolen", "analyst-1", sqlmock.AnyArg(), "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				// A declined recurring run counts as skipped; this transaction was not one
+				mock.ExpectExec(`UPDATE recurring_rules\s+SET run_count = run_count - 1, skipped_count = skipped_count \+ 1, last_error = \$2\s+WHERE id = \(SELECT rule_id FROM recurring_rule_runs WHERE transaction_id = \$1\)`).
+					WithArgs("tx1", "declined in review").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			expectedStatus: "DECLINED",
+		},
+		{
+			name:    "approval of a held installment debit pays the installment",
+			ctx:     analyst,
+			approve: true,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT account_id FROM transactions WHERE id = \$1`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("acc-1"))
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).WithArgs("tx1").WillReturnRows(heldInstallmentDebit())
+				mock.ExpectQuery(`SELECT balance, status, overdraft_limit FROM accounts`).
+					WithArgs("acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "status", "overdraft_limit"}).AddRow(5000.0, "ACTIVE", 0.0))
+				mock.ExpectExec(`UPDATE accounts SET balance = balance \+ \$1`).
+					WithArgs("-1500.00", sqlmock.AnyArg(), "acc-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transactions SET status = \$1, overdrawn = \$2 WHERE id = \$3`).
+					WithArgs("COMPLETED", false, "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transaction_reviews`).
+					WithArgs("APPROVE", "", "analyst-1", sqlmock.AnyArg(), "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE installments SET status = \$2, paid_at = \$3 WHERE transaction_id = \$1 AND status = \$4`).
+					WithArgs("tx1", "PAID", sqlmock.AnyArg(), "IN_REVIEW").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedStatus: "COMPLETED",
+		},
+		{
+			name: "decline of a held installment debit leaves the installment overdue",
+			ctx:  analyst,
+			note: "card reported stolen",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).WithArgs("tx1").WillReturnRows(heldInstallmentDebit())
+				mock.ExpectExec(`UPDATE transactions SET status = \$1, overdrawn = \$2 WHERE id = \$3`).
+					WithArgs("DECLINED", false, "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transaction_reviews`).
+					WithArgs("DECLINE", "card reported stolen", "analyst-1", sqlmock.AnyArg(), "tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				// The installment is unpaid again, and attempted again like other declined debits
+				mock.ExpectExec(`UPDATE installments\s+SET status = \$2, transaction_id = NULL, attempts = attempts \+ 1, last_error = \$3, next_attempt_at = \$4\s+WHERE transaction_id = \$1 AND status = \$5`).
+					WithArgs("tx1", "OVERDUE", "declined in review", sqlmock.AnyArg(), "IN_REVIEW").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE recurring_rules`).
+					WithArgs("tx1", "declined in review").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			expectedStatus: "DECLINED",
+		},
+		{
+			name: "already reviewed",
+			ctx:  analyst,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).
+					WithArgs("tx1").
+					WillReturnRows(sqlmock.NewRows(flaggedTransactionColumns).
+						AddRow("tx1", "acc-1", "WITHDRAWAL", -1500.0, "", 1000, "COMPLETED", "", "", []byte(`{}`), "", "", 60, "LARGE_AMOUNT", 1000, "APPROVE", "", "analyst-2", 1200))
+				mock.ExpectRollback()
+			},
+			expectedError: "transaction is not under review",
+		},
+		{
+			name: "transaction not found",
+			ctx:  analyst,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FOR UPDATE`).WithArgs("tx1").WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			expectedError: "transaction not found",
+		},
+		{
+			name:          "analyst without operator id",
+			ctx:           metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CallerRoleMetadataKey, common.RoleSupport)),
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+
+			var flagged *pb.FlaggedTransaction
+			var errMsg string
+			if tt.approve {
+				response, err := service.ApproveFlagged(tt.ctx, &pb.ApproveFlaggedRequest{Id: "tx1", Note: tt.note})
+				require.NoError(t, err)
+				flagged, errMsg = response.Transaction, response.Error
+			} else {
+				response, err := service.DeclineFlagged(tt.ctx, &pb.DeclineFlaggedRequest{Id: "tx1", Note: tt.note})
+				require.NoError(t, err)
+				flagged, errMsg = response.Transaction, response.Error
+			}
+
+			assert.Equal(t, tt.expectedError, errMsg)
+			if tt.expectedStatus != "" {
+				require.NotNil(t, flagged)
+				assert.Equal(t, tt.expectedStatus, flagged.Transaction.Status)
+				assert.Equal(t, "analyst-1", flagged.ReviewedBy)
+				assert.Equal(t, tt.note, flagged.Note)
+			} else {
+				assert.Nil(t, flagged)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_SetBudget(t *testing.T) {
+	tests := []struct {
+		name               string
+		request            *pb.SetBudgetRequest
+		mockSetup          func(sqlmock.Sqlmock)
+		expectedError      string
+		expectedThresholds []int32
+	}{
+		{
+			name:    "default thresholds",
+			request: &pb.SetBudgetRequest{AccountId: "acc-1", Category: "groceries", MonthlyLimitCents: 40000},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT 1 FROM accounts`).WithArgs("acc-1").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(1))
+				mock.ExpectQuery(`INSERT INTO account_budgets`).
+					WithArgs("acc-1", "groceries", "400.00", "80,100", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(1000))
+			},
+			expectedThresholds: []int32{80, 100},
+		},
+		{
+			name:    "thresholds sorted without duplicates",
+			request: &pb.SetBudgetRequest{AccountId: "acc-1", Category: "travel", MonthlyLimitCents: 100000, Thresholds: []int32{120, 50, 50}},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT 1 FROM accounts`).WithArgs("acc-1").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(1))
+				mock.ExpectQuery(`INSERT INTO account_budgets`).
+					WithArgs("acc-1", "travel", "1000.00", "50,120", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(1000))
+			},
+			expectedThresholds: []int32{50, 120},
+		},
+		{
+			name:    "unknown account",
+			request: &pb.SetBudgetRequest{AccountId: "missing", Category: "groceries", MonthlyLimitCents: 40000},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT 1 FROM accounts`).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: "account not found",
+		},
+		{
+			name:          "invalid category",
+			request:       &pb.SetBudgetRequest{AccountId: "acc-1", Category: "eating out", MonthlyLimitCents: 40000},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "invalid category",
+		},
+		{
+			name:          "non-positive limit",
+			request:       &pb.SetBudgetRequest{AccountId: "acc-1", Category: "groceries"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "monthly_limit must be positive",
+		},
+		{
+			name:          "threshold out of range",
+			request:       &pb.SetBudgetRequest{AccountId: "acc-1", Category: "groceries", MonthlyLimitCents: 40000, Thresholds: []int32{0}},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "thresholds must be between 1 and 1000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+
+			resp, err := service.SetBudget(context.Background(), tt.request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, resp.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, resp.Budget)
+				assert.Equal(t, tt.expectedThresholds, resp.Budget.Thresholds)
+				assert.Equal(t, int64(1000), resp.Budget.CreatedAt)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_GetBudgetStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// March 2024 in UTC
+	mock.ExpectQuery(`FROM account_budgets b`).
+		WithArgs("acc-1", int64(1709251200), int64(1711929600)).
+		WillReturnRows(sqlmock.NewRows([]string{"category", "monthly_limit", "thresholds", "created_at", "updated_at", "spent"}).
+			AddRow("groceries", 400.0, "80,100", 1000, 1000, 340.0).
+			AddRow("travel", 200.0, "50", 1000, 1000, 0.0))
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	resp, err := service.GetBudgetStatus(context.Background(), &pb.GetBudgetStatusRequest{AccountId: "acc-1", Month: "2024-03"})
+	require.NoError(t, err)
+	require.Empty(t, resp.Error)
+	assert.Equal(t, "2024-03", resp.Month)
+	require.Len(t, resp.Budgets, 2)
+
+	groceries := resp.Budgets[0]
+	assert.Equal(t, "groceries", groceries.Budget.Category)
+	assert.Equal(t, int64(34000), groceries.SpentCents)
+	assert.Equal(t, int64(6000), groceries.RemainingCents)
+	assert.Equal(t, 85.0, groceries.PercentUsed)
+	assert.Equal(t, []int32{80}, groceries.CrossedThresholds)
+	assert.Empty(t, resp.Budgets[1].CrossedThresholds)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	resp, err = service.GetBudgetStatus(context.Background(), &pb.GetBudgetStatusRequest{AccountId: "acc-1", Month: "03/2024"})
+	require.NoError(t, err)
+	assert.Equal(t, "month must be YYYY-MM", resp.Error)
+}
+
+func TestService_CreateTransaction_PublishesBudgetThresholdCrossings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	service.EnableEventOutbox()
+
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("test-account-id", "12345678901", "CHECKING", 500.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE accounts`).
+		WithArgs("-100.00", sqlmock.AnyArg(), "test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(100.0))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(sqlmock.AnyArg(), "test-account-id", "CASH_PURCHASE", "-100.00", "", sqlmock.AnyArg(), "COMPLETED", "", []byte(`{"category":"groceries"}`), "-100.00", false, "", "0.00", 0.0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO event_outbox`).
+		WithArgs(sqlmock.AnyArg(), events.TransactionCreated, "test-account-id", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// Spending goes from 300 to 400 of a 400 limit, crossing 80% and 100% but not 50%
+	mock.ExpectQuery(`FROM account_budgets b`).
+		WithArgs("test-account-id", "groceries", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"monthly_limit", "thresholds", "spent"}).AddRow(400.0, "50,80,100", 400.0))
+	crossed := []*envelopeArg{{}, {}}
+	for _, envelope := range crossed {
+		mock.ExpectExec(`INSERT INTO event_outbox`).
+			WithArgs(sqlmock.AnyArg(), events.BudgetThresholdCrossed, "test-account-id", envelope, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	mock.ExpectCommit()
+
+	resp, err := service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{
+		AccountId:     "test-account-id",
+		OperationType: "CASH_PURCHASE",
+		AmountCents:   10000,
+		Category:      "groceries",
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Error)
+	assert.Equal(t, map[string]string{"category": "groceries"}, resp.Transaction.Metadata)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	for i, threshold := range []int32{80, 100} {
+		payload, err := events.DecodePayload(crossed[i].envelope)
+		require.NoError(t, err)
+		event := payload.(*events.BudgetThresholdCrossedV1)
+		assert.Equal(t, threshold, event.Threshold)
+		assert.Equal(t, "groceries", event.Category)
+		assert.Equal(t, 400.0, event.Spent)
+		assert.Equal(t, resp.Transaction.Id, event.TransactionId)
+	}
+}
+
+func TestService_CreateTransaction_RejectsInvalidCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	resp, err := service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{
+		AccountId:     "test-account-id",
+		OperationType: "CASH_PURCHASE",
+		AmountCents:   1000,
+		Category:      "food,drinks",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "invalid category", resp.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_ListEventDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	columns := []string{"id", "event_id", "event_type", "created_at", "published_at", "attempts", "last_error"}
+
+	mock.ExpectQuery(`FROM event_outbox\s+WHERE partition_key = \$1 AND published_at IS NULL AND attempts > 0\s+ORDER BY created_at DESC, id DESC\s+LIMIT \$2`).
+		WithArgs("test-account-id", 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(int64(7), "event-7", "transaction.created", int64(1700000300), nil, 3, "broker returned 503 Service Unavailable").
+			AddRow(int64(5), "event-5", "budget.threshold_crossed", int64(1700000200), nil, 1, "timeout"))
+
+	first, err := service.ListEventDeliveries(context.Background(), &pb.ListEventDeliveriesRequest{
+		AccountId: "test-account-id",
+		Status:    common.DeliveryFailing,
+		Limit:     2,
+	})
+	require.NoError(t, err)
+	require.Empty(t, first.Error)
+	require.Len(t, first.Events, 2)
+	assert.Equal(t, "event-7", first.Events[0].EventId)
+	require.Len(t, first.Events[0].Deliveries, 1)
+	assert.Equal(t, &pb.EventDelivery{
+		Subscriber: common.OutboxSubscriber,
+		Status:     common.DeliveryFailing,
+		Attempts:   3,
+		LastError:  "broker returned 503 Service Unavailable",
+	}, first.Events[0].Deliveries[0])
+	require.NotEmpty(t, first.NextPageToken)
+
+	// The next page resumes after the last event and keeps the filters
+	mock.ExpectQuery(`WHERE partition_key = \$1 AND published_at IS NULL AND attempts > 0 AND \(created_at, id\) < \(\$2, \$3\)`).
+		WithArgs("test-account-id", int64(1700000200), int64(5), 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(int64(2), "event-2", "transaction.created", int64(1700000100), int64(1700000160), 2, nil))
+
+	second, err := service.ListEventDeliveries(context.Background(), &pb.ListEventDeliveriesRequest{
+		AccountId: "test-account-id",
+		Status:    common.DeliveryFailing,
+		Limit:     2,
+		PageToken: first.NextPageToken,
+	})
+	require.NoError(t, err)
+	require.Empty(t, second.Error)
+	require.Len(t, second.Events, 1)
+	assert.Equal(t, common.DeliveryDelivered, second.Events[0].Deliveries[0].Status)
+	assert.Equal(t, int64(1700000160), second.Events[0].Deliveries[0].DeliveredAt)
+	assert.Empty(t, second.NextPageToken)
+
+	// Tokens issued for other filters and unknown statuses are rejected before querying
+	for req, expected := range map[*pb.ListEventDeliveriesRequest]string{
+		{AccountId: "test-account-id", PageToken: first.NextPageToken}: "invalid page token",
+		{AccountId: "test-account-id", Status: "LOST"}:                 "status must be one of PENDING, FAILING, DELIVERED",
+		{}: "account_id required",
+	} {
+		resp, err := service.ListEventDeliveries(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, resp.Error)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_Transfer(t *testing.T) {
+	accountRows := func(rows ...[]driver.Value) *sqlmock.Rows {
+		result := sqlmock.NewRows([]string{"id", "balance", "status", "overdraft_limit"})
+		for _, row := range rows {
+			result.AddRow(row...)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name          string
+		request       *pb.TransferRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name:    "moves the amount between the accounts",
+			request: &pb.TransferRequest{SourceAccountId: "acc-b", DestinationAccountId: "acc-a", AmountCents: 3000, Description: "Rent share"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT id, balance, status, overdraft_limit FROM accounts WHERE id IN \(\$1, \$2\) ORDER BY id FOR UPDATE`).
+					WithArgs("acc-a", "acc-b").
+					WillReturnRows(accountRows([]driver.Value{"acc-a", 5.0, "ACTIVE", 0.0}, []driver.Value{"acc-b", 100.0, "ACTIVE", 0.0}))
+				mock.ExpectExec(`UPDATE accounts SET balance = balance \+ \$1`).
+					WithArgs("-30.00", sqlmock.AnyArg(), "acc-b").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO transactions`).
+					WithArgs(sqlmock.AnyArg(), "acc-b", "TRANSFER_OUT", "-30.00", "Rent share", sqlmock.AnyArg(), "COMPLETED", sqlmock.AnyArg(), false).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE accounts SET balance = balance \+ \$1`).
+					WithArgs("30.00", sqlmock.AnyArg(), "acc-a").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO transactions`).
+					WithArgs(sqlmock.AnyArg(), "acc-a", "TRANSFER_IN", "30.00", "Rent share", sqlmock.AnyArg(), "COMPLETED", sqlmock.AnyArg(), false).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "source balance too low",
+			request: &pb.TransferRequest{SourceAccountId: "acc-b", DestinationAccountId: "acc-a", AmountCents: 13000},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id IN`).
+					WithArgs("acc-a", "acc-b").
+					WillReturnRows(accountRows([]driver.Value{"acc-a", 5.0, "ACTIVE", 0.0}, []driver.Value{"acc-b", 100.0, "ACTIVE", 0.0}))
+				mock.ExpectRollback()
+			},
+			expectedError: "insufficient balance",
+		},
+		{
+			name:    "source goes into its overdraft",
+			request: &pb.TransferRequest{SourceAccountId: "acc-b", DestinationAccountId: "acc-a", AmountCents: 13000},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id IN`).
+					WithArgs("acc-a", "acc-b").
+					WillReturnRows(accountRows([]driver.Value{"acc-a", 5.0, "ACTIVE", 0.0}, []driver.Value{"acc-b", 100.0, "ACTIVE", 50.0}))
+				mock.ExpectExec(`UPDATE accounts SET balance = balance \+ \$1`).
+					WithArgs("-130.00", sqlmock.AnyArg(), "acc-b").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO transactions`).
+					WithArgs(sqlmock.AnyArg(), "acc-b", "TRANSFER_OUT", "-130.00", "", sqlmock.AnyArg(), "COMPLETED", sqlmock.AnyArg(), true).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE accounts SET balance = balance \+ \$1`).
+					WithArgs("130.00", sqlmock.AnyArg(), "acc-a").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO transactions`).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "destination not onboarded",
+			request: &pb.TransferRequest{SourceAccountId: "acc-b", DestinationAccountId: "acc-a", AmountCents: 1000},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id IN`).
+					WithArgs("acc-a", "acc-b").
+					WillReturnRows(accountRows([]driver.Value{"acc-a", 0.0, "PENDING_KYC", 0.0}, []driver.Value{"acc-b", 100.0, "ACTIVE", 0.0}))
+				mock.ExpectRollback()
+			},
+			expectedError: "account not active",
+		},
+		{
+			name:    "unknown destination",
+			request: &pb.TransferRequest{SourceAccountId: "acc-b", DestinationAccountId: "acc-z", AmountCents: 1000},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM accounts WHERE id IN`).
+					WithArgs("acc-b", "acc-z").
+					WillReturnRows(accountRows([]driver.Value{"acc-b", 100.0, "ACTIVE", 0.0}))
+				mock.ExpectRollback()
+			},
+			expectedError: "account not found",
+		},
+		{
+			name:          "same account",
+			request:       &pb.TransferRequest{SourceAccountId: "acc-a", DestinationAccountId: "acc-a", AmountCents: 1000},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "source and destination accounts must differ",
+		},
+		{
+			name:          "negative amount",
+			request:       &pb.TransferRequest{SourceAccountId: "acc-b", DestinationAccountId: "acc-a", AmountCents: -1000},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "transfer amount must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.Transfer(context.Background(), tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				require.NotEmpty(t, response.TransferId)
+				assert.Equal(t, -tt.request.AmountCents, response.Debit.AmountCents)
+				assert.Equal(t, tt.request.AmountCents, response.Credit.AmountCents)
+				assert.Equal(t, response.TransferId, response.Debit.TransferId)
+				assert.Equal(t, response.TransferId, response.Credit.TransferId)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_WebhookSigningKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	admin := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, common.RoleAdmin, common.OperatorIDMetadataKey, "admin-1"))
+	support := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, common.RoleSupport, common.OperatorIDMetadataKey, "agent-7"))
+
+	created, err := service.CreateWebhookSigningKey(support, &pb.CreateWebhookSigningKeyRequest{Subscriber: "broker"})
+	require.NoError(t, err)
+	assert.Equal(t, "permission denied", created.Error)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT key_id FROM webhook_signing_keys`).
+		WithArgs("broker").
+		WillReturnRows(sqlmock.NewRows([]string{"key_id"}).AddRow("k_old"))
+	mock.ExpectExec(`INSERT INTO webhook_signing_keys`).
+		WithArgs("broker", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE", "admin-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	created, err = service.CreateWebhookSigningKey(admin, &pb.CreateWebhookSigningKeyRequest{Subscriber: "broker"})
+	require.NoError(t, err)
+	assert.Empty(t, created.Error)
+	assert.NotEmpty(t, created.Key.Secret)
+	assert.Equal(t, "admin-1", created.Key.CreatedBy)
+
+	// The only active key cannot be retired, or deliveries would go out unsigned
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT key_id FROM webhook_signing_keys`).
+		WithArgs("broker").
+		WillReturnRows(sqlmock.NewRows([]string{"key_id"}).AddRow("k_old"))
+	mock.ExpectQuery(`FROM webhook_signing_keys WHERE subscriber = \$1 AND key_id = \$2`).
+		WithArgs("broker", "k_old").
+		WillReturnRows(sqlmock.NewRows([]string{"subscriber", "key_id", "secret", "status", "created_by", "created_at", "retired_by", "retired_at"}).
+			AddRow("broker", "k_old", "", "ACTIVE", "admin-1", 1000, "", 0))
+	mock.ExpectRollback()
+
+	retired, err := service.RetireWebhookSigningKey(admin, &pb.RetireWebhookSigningKeyRequest{Subscriber: "broker", KeyId: "k_old"})
+	require.NoError(t, err)
+	assert.Equal(t, "cannot retire the last active signing key", retired.Error)
+
+	listed, err := service.ListWebhookSigningKeys(admin, &pb.ListWebhookSigningKeysRequest{Subscriber: "acme"})
+	require.NoError(t, err)
+	assert.Equal(t, "unknown subscriber", listed.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_ReverseTransaction(t *testing.T) {
+	support := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, common.RoleSupport, common.OperatorIDMetadataKey, "agent-7"))
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+	originalRow := func(operationType string, amount float64, status, transferID string) *sqlmock.Rows {
+		return sqlmock.NewRows(columns).AddRow("tx1", "acc-1", operationType, amount, "Coffee", 1000, status, "", "", []byte(`{}`), transferID, "")
+	}
+	expectOriginal := func(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+		mock.ExpectQuery(`SELECT account_id FROM transactions WHERE id = \$1`).
+			WithArgs("tx1").
+			WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("acc-1"))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`FROM transactions WHERE id = \$1 FOR UPDATE`).WithArgs("tx1").WillReturnRows(rows)
+	}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		request       *pb.ReverseTransactionRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name:    "gives a purchase back",
+			ctx:     support,
+			request: &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Merchant refund"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectOriginal(mock, originalRow("CASH_PURCHASE", -40.0, "COMPLETED", ""))
+				mock.ExpectQuery(`UPDATE accounts SET balance = balance \+ \$1, updated_at = \$2 WHERE id = \$3 AND \(\$1 >= 0 OR balance \+ \$1 >= -overdraft_limit\) RETURNING balance`).
+					WithArgs("40.00", sqlmock.AnyArg(), "acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(60.0))
+				mock.ExpectExec(`UPDATE transactions SET status = 'REVERSED' WHERE id = \$1`).
+					WithArgs("tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO transactions`).
+					WithArgs(sqlmock.AnyArg(), "acc-1", "REVERSAL", "40.00", "Merchant refund", sqlmock.AnyArg(), "COMPLETED", "tx1", false).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "already reversed",
+			ctx:     support,
+			request: &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Merchant refund"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectOriginal(mock, originalRow("CASH_PURCHASE", -40.0, "REVERSED", ""))
+				mock.ExpectRollback()
+			},
+			expectedError: "transaction already reversed",
+		},
+		{
+			name:    "pending transaction",
+			ctx:     support,
+			request: &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Merchant refund"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectOriginal(mock, originalRow("CASH_PURCHASE", -40.0, "PENDING", ""))
+				mock.ExpectRollback()
+			},
+			expectedError: "only completed transactions can be reversed",
+		},
+		{
+			name:    "transfer leg",
+			ctx:     support,
+			request: &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Sent by mistake"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectOriginal(mock, originalRow("TRANSFER_OUT", -40.0, "COMPLETED", "transfer-1"))
+				mock.ExpectRollback()
+			},
+			expectedError: "transfers cannot be reversed",
+		},
+		{
+			name:    "installment purchase cancels its unpaid installments",
+			ctx:     support,
+			request: &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Merchant refund"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectOriginal(mock, originalRow("INSTALLMENT_PURCHASE", -33.34, "COMPLETED", ""))
+				mock.ExpectExec(`UPDATE installments SET status = \$2 WHERE purchase_id = \$1 AND status IN \(\$3, \$4\)`).
+					WithArgs("tx1", "CANCELLED", "PENDING", "OVERDUE").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM installments i JOIN transactions d ON d.id = i.transaction_id`).
+					WithArgs("tx1", "PAID", "IN_REVIEW").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(`UPDATE accounts`).
+					WithArgs("33.34", sqlmock.AnyArg(), "acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(200.0))
+				mock.ExpectExec(`UPDATE transactions SET status = 'REVERSED' WHERE id = \$1`).
+					WithArgs("tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO transactions`).
+					WithArgs(sqlmock.AnyArg(), "acc-1", "REVERSAL", "33.34", "Merchant refund", sqlmock.AnyArg(), "COMPLETED", "tx1", false).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "installment purchase with a later installment paid",
+			ctx:     support,
+			request: &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Merchant refund"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				// The cancellation is rolled back with the refused reversal
+				expectOriginal(mock, originalRow("INSTALLMENT_PURCHASE", -33.34, "COMPLETED", ""))
+				mock.ExpectExec(`UPDATE installments SET status = \$2`).
+					WithArgs("tx1", "CANCELLED", "PENDING", "OVERDUE").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM installments`).
+					WithArgs("tx1", "PAID", "IN_REVIEW").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			expectedError: "paid installments must be reversed first",
+		},
+		{
+			name:    "payment reopens the purchases it discharged",
+			ctx:     support,
+			request: &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Payment bounced"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectOriginal(mock, originalRow("PAYMENT", 100.0, "COMPLETED", ""))
+				mock.ExpectQuery(`UPDATE accounts`).
+					WithArgs("-100.00", sqlmock.AnyArg(), "acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(50.0))
+				mock.ExpectExec(`UPDATE transactions SET status = 'REVERSED' WHERE id = \$1`).
+					WithArgs("tx1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`UPDATE transactions t SET balance = t.balance - d.amount\s+FROM payment_discharges d\s+WHERE d.payment_id = \$1 AND t.id = d.debt_id`).
+					WithArgs("tx1").
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`INSERT INTO transactions`).
+					WithArgs(sqlmock.AnyArg(), "acc-1", "REVERSAL", "-100.00", "Payment bounced", sqlmock.AnyArg(), "COMPLETED", "tx1", false).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "credit already spent",
+			ctx:     support,
+			request: &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Payment bounced"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				expectOriginal(mock, originalRow("PAYMENT", 100.0, "COMPLETED", ""))
+				mock.ExpectQuery(`UPDATE accounts`).
+					WithArgs("-100.00", sqlmock.AnyArg(), "acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}))
+				mock.ExpectRollback()
+			},
+			expectedError: "insufficient balance",
+		},
+		{
+			name:    "unknown transaction",
+			ctx:     support,
+			request: &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Merchant refund"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT account_id FROM transactions`).WithArgs("tx1").WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: "not found",
+		},
+		{
+			name:          "reason required",
+			ctx:           support,
+			request:       &pb.ReverseTransactionRequest{Id: "tx1"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "reason required",
+		},
+		{
+			name:          "customer caller",
+			ctx:           context.Background(),
+			request:       &pb.ReverseTransactionRequest{Id: "tx1", Reason: "Merchant refund"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			response, err := service.ReverseTransaction(tt.ctx, tt.request)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedError, response.Error)
+			if tt.expectedError == "" {
+				assert.Equal(t, "REVERSED", response.Original.Status)
+				assert.Equal(t, "REVERSAL", response.Reversal.OperationType)
+				assert.Equal(t, -response.Original.AmountCents, response.Reversal.AmountCents)
+				assert.Equal(t, "tx1", response.Reversal.OriginalTransactionId)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_ClearSandboxTransactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT tenant_id, settings FROM tenant_settings WHERE environment = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "settings"}).
+			AddRow("issuer-a", []byte(`{"currency":"BRL"}`)).
+			AddRow("sandbox-a", []byte(`{"sandbox":true}`)))
+	mock.ExpectQuery(`FROM transactions t JOIN accounts a ON a.id = t.account_id\s+WHERE a.tenant_id = \$1 AND t.status IN \('PENDING', 'UNDER_REVIEW'\)`).
+		WithArgs("sandbox-a", sqlmock.AnyArg(), maxSandboxClearingBatch).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow("tx1", "PENDING").AddRow("tx2", "PENDING"))
+
+	// tx1 completes; tx2 is a debit the balance no longer covers, so it fails
+	for _, tx := range []struct {
+		id       string
+		amount   float64
+		affected int64
+		status   string
+	}{{"tx1", 50.0, 1, "COMPLETED"}, {"tx2", -500.0, 0, "FAILED"}} {
+		mock.ExpectQuery(`SELECT account_id FROM transactions WHERE id = \$1`).
+			WithArgs(tx.id).
+			WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("acc-1"))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT account_id, operation_type, amount, status, balance FROM transactions WHERE id = \$1 FOR UPDATE`).
+			WithArgs(tx.id).
+			WillReturnRows(sqlmock.NewRows([]string{"account_id", "operation_type", "amount", "status", "balance"}).AddRow("acc-1", "CASH_PURCHASE", tx.amount, "PENDING", 0.0))
+		balance := sqlmock.NewRows([]string{"balance"})
+		if tx.affected == 1 {
+			balance.AddRow(150.0)
+		}
+		mock.ExpectQuery(`UPDATE accounts`).
+			WithArgs(common.CentsFromFloat(tx.amount), sqlmock.AnyArg(), "acc-1").
+			WillReturnRows(balance)
+		if tx.affected == 0 {
+			mock.ExpectRollback()
+			mock.ExpectBegin()
+			mock.ExpectQuery(`FOR UPDATE`).
+				WithArgs(tx.id).
+				WillReturnRows(sqlmock.NewRows([]string{"account_id", "operation_type", "amount", "status", "balance"}).AddRow("acc-1", "CASH_PURCHASE", tx.amount, "PENDING", 0.0))
+		}
+		mock.ExpectExec(`UPDATE transactions SET status = \$1, overdrawn = \$2 WHERE id = \$3`).
+			WithArgs(tx.status, sqlmock.AnyArg(), tx.id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO transaction_resolutions`).
+			WithArgs(sqlmock.AnyArg(), tx.id, sqlmock.AnyArg(), "PENDING", tx.status, sqlmock.AnyArg(), "sandbox-clearing", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	cleared, err := service.ClearSandboxTransactions(context.Background(), DefaultSandboxClearingDelay)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, cleared)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// fakeAnalyticsStream is an in-memory server stream used to drive StreamTransactions in tests.
+type fakeAnalyticsStream struct {
+	grpc.ServerStream
+	batches []*pb.StreamTransactionsResponse
+}
+
+func (f *fakeAnalyticsStream) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeAnalyticsStream) Send(batch *pb.StreamTransactionsResponse) error {
+	f.batches = append(f.batches, batch)
+	return nil
+}
+
+func TestAnalyticsService_StreamTransactions(t *testing.T) {
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+	fullBatch := sqlmock.NewRows(columns)
+	for i := 0; i < analyticsBatchSize; i++ {
+		fullBatch.AddRow(fmt.Sprintf("txn-%04d", i), "acc-1", "PAYMENT", 10.0, "", int64(100+i), "COMPLETED", "", "", []byte(`{}`), "", "")
+	}
+
+	tests := []struct {
+		name          string
+		request       *pb.StreamTransactionsRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedSizes []int
+		expectedError string
+	}{
+		{
+			name:    "batches resume after the last transaction sent",
+			request: &pb.StreamTransactionsRequest{From: 100, Status: "COMPLETED"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE created_at >= \$1 AND status = \$2 AND \(created_at, id\) > \(\$3, \$4\)\s+ORDER BY created_at, id\s+LIMIT 1000`).
+					WithArgs(int64(100), "COMPLETED", int64(-1), "").
+					WillReturnRows(fullBatch)
+				mock.ExpectQuery(`\(created_at, id\) > \(\$3, \$4\)`).
+					WithArgs(int64(100), "COMPLETED", int64(100+analyticsBatchSize-1), fmt.Sprintf("txn-%04d", analyticsBatchSize-1)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("txn-last", "acc-1", "WITHDRAWAL", -5.0, "ATM", int64(5000), "COMPLETED", "", "", []byte(`{}`), "", ""))
+			},
+			expectedSizes: []int{analyticsBatchSize, 1},
+		},
+		{
+			name:    "account filter with no transactions sends nothing",
+			request: &pb.StreamTransactionsRequest{AccountId: "acc-2", OperationType: "PAYMENT"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE account_id = \$1 AND operation_type = \$2 AND \(created_at, id\) > \(\$3, \$4\)`).
+					WithArgs("acc-2", "PAYMENT", int64(-1), "").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		{
+			name:          "from after to",
+			request:       &pb.StreamTransactionsRequest{From: 200, To: 100},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedSizes: []int{0},
+			expectedError: "from must be before to",
+		},
+		{
+			name:          "invalid status",
+			request:       &pb.StreamTransactionsRequest{Status: "SETTLED"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedSizes: []int{0},
+			expectedError: "invalid status",
+		},
+		{
+			name:    "replica error ends the stream",
+			request: &pb.StreamTransactionsRequest{},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM transactions`).
+					WithArgs(int64(-1), "").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedSizes: []int{0},
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewAnalyticsService(db, logger)
+			stream := &fakeAnalyticsStream{}
+
+			err = service.StreamTransactions(tt.request, stream)
+			assert.NoError(t, err)
+
+			var sizes []int
+			var errMsg string
+			for _, batch := range stream.batches {
+				sizes = append(sizes, len(batch.Transactions))
+				errMsg = batch.Error
+			}
+			assert.Equal(t, tt.expectedSizes, sizes)
+			assert.Equal(t, tt.expectedError, errMsg)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// fakeTransactionStream is an in-memory server stream used to drive Service.StreamTransactions in tests.
+type fakeTransactionStream struct {
+	grpc.ServerStream
+	transactions []*pb.Transaction
+}
+
+func (f *fakeTransactionStream) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeTransactionStream) Send(transaction *pb.Transaction) error {
+	f.transactions = append(f.transactions, transaction)
+	return nil
+}
+
+func TestService_StreamTransactions(t *testing.T) {
+	columns := []string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id"}
+	fullBatch := sqlmock.NewRows(columns)
+	for i := 0; i < streamTransactionsBatchSize; i++ {
+		fullBatch.AddRow(fmt.Sprintf("txn-%04d", i), "acc-1", "PAYMENT", 10.0, "", int64(100+i), "COMPLETED", "", "", []byte(`{}`), "", "")
+	}
+
+	tests := []struct {
+		name          string
+		request       *pb.StreamTransactionsRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedCount int
+		expectedCode  codes.Code
+		expectedError string
+	}{
+		{
+			name:    "one message per transaction, resuming after the last one sent",
+			request: &pb.StreamTransactionsRequest{AccountId: "acc-1", To: 9000},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`WHERE created_at < \$1 AND account_id = \$2 AND \(created_at, id\) > \(\$3, \$4\)\s+ORDER BY created_at, id\s+LIMIT 500`).
+					WithArgs(int64(9000), "acc-1", int64(-1), "").
+					WillReturnRows(fullBatch)
+				mock.ExpectQuery(`\(created_at, id\) > \(\$3, \$4\)`).
+					WithArgs(int64(9000), "acc-1", int64(100+streamTransactionsBatchSize-1), fmt.Sprintf("txn-%04d", streamTransactionsBatchSize-1)).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow("txn-last", "acc-1", "WITHDRAWAL", -5.0, "ATM", int64(5000), "COMPLETED", "", "", []byte(`{}`), "", ""))
+			},
+			expectedCount: streamTransactionsBatchSize + 1,
+		},
+		{
+			name:          "missing account id",
+			request:       &pb.StreamTransactionsRequest{Status: "COMPLETED"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedCode:  codes.InvalidArgument,
+			expectedError: "account_id required",
+		},
+		{
+			name:          "invalid status",
+			request:       &pb.StreamTransactionsRequest{AccountId: "acc-1", Status: "SETTLED"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedCode:  codes.InvalidArgument,
+			expectedError: "invalid status",
+		},
+		{
+			name:    "database error ends the stream",
+			request: &pb.StreamTransactionsRequest{AccountId: "acc-1"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM transactions`).
+					WithArgs("acc-1", int64(-1), "").
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedCode:  codes.Internal,
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			stream := &fakeTransactionStream{}
+
+			err = service.StreamTransactions(tt.request, stream)
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Equal(t, tt.expectedError, status.Convert(err).Message())
+			assert.Len(t, stream.transactions, tt.expectedCount)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_CreateBatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *pb.CreateBatchRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name: "created",
+			req:  &pb.CreateBatchRequest{Source: "settlement-2024-03-01.csv", ExpectedCount: 250},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO transaction_batches`).
+					WithArgs(sqlmock.AnyArg(), "settlement-2024-03-01.csv", int32(250), "op-7", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:          "missing source",
+			req:           &pb.CreateBatchRequest{ExpectedCount: 250},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "source required",
+		},
+		{
+			name:          "source too long",
+			req:           &pb.CreateBatchRequest{Source: strings.Repeat("x", 101), ExpectedCount: 250},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "source too long",
+		},
+		{
+			name:          "no items",
+			req:           &pb.CreateBatchRequest{Source: "import"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "expected_count must be positive",
+		},
+		{
+			name: "database error",
+			req:  &pb.CreateBatchRequest{Source: "import", ExpectedCount: 1},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO transaction_batches`).WillReturnError(sql.ErrConnDone)
+			},
+			expectedError: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.OperatorIDMetadataKey, "op-7"))
+			resp, err := service.CreateBatch(ctx, tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, resp.Error)
+			if tt.expectedError == "" {
+				require.NotNil(t, resp.Batch)
+				assert.NotEmpty(t, resp.Batch.Id)
+				assert.Equal(t, "OPEN", resp.Batch.Status)
+				assert.Equal(t, int32(250), resp.Batch.ExpectedCount)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_GetBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	mock.ExpectQuery(`FROM transaction_batches b`).
+		WithArgs("batch-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "source", "expected_count", "created_at", "succeeded", "failed"}).
+			AddRow("batch-1", "import.csv", 10, 1000, 7, 3))
+	// One more failure than the page size tells there is a next page
+	mock.ExpectQuery(`FROM transaction_batch_failures f`).
+		WithArgs("batch-1", int32(0), int32(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"item", "account_id", "external_id", "error", "failed_at"}).
+			AddRow(2, "acc-1", "ext-2", "insufficient balance", 1001).
+			AddRow(5, "", "", "missing required fields", 1002).
+			AddRow(9, "acc-3", "", "account not active", 1003))
+
+	resp, err := service.GetBatch(context.Background(), &pb.GetBatchRequest{Id: "batch-1", FailuresLimit: 2})
+	require.NoError(t, err)
+	require.Empty(t, resp.Error)
+	assert.Equal(t, "COMPLETED_WITH_ERRORS", resp.Batch.Status)
+	assert.Equal(t, int32(7), resp.Batch.Succeeded)
+	assert.Equal(t, int32(3), resp.Batch.Failed)
+	require.Len(t, resp.Failures, 2)
+	assert.Equal(t, int32(2), resp.Failures[0].Item)
+	assert.Equal(t, "insufficient balance", resp.Failures[0].Error)
+	assert.Equal(t, "ext-2", resp.Failures[0].ExternalId)
+	assert.Equal(t, int32(5), resp.NextFailuresAfterItem)
+
+	mock.ExpectQuery(`FROM transaction_batches b`).
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+	resp, err = service.GetBatch(context.Background(), &pb.GetBatchRequest{Id: "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, "batch not found", resp.Error)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBatchStatus(t *testing.T) {
+	assert.Equal(t, "OPEN", batchStatus(10, 6, 2))
+	assert.Equal(t, "COMPLETED", batchStatus(10, 10, 0))
+	assert.Equal(t, "COMPLETED_WITH_ERRORS", batchStatus(10, 9, 1))
+}
+
+func TestService_CreateTransaction_BatchItems(t *testing.T) {
+	batchRows := func(applied bool) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"batch_id", "item", "expected_count", "applied"}).AddRow("batch-1", 4, 10, applied)
+	}
+
+	tests := []struct {
+		name          string
+		req           *pb.CreateTransactionRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name: "item applied",
+			req:  &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "PAYMENT", AmountCents: 5000, BatchId: "batch-1", BatchItem: 4},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM \(VALUES \(\$1::VARCHAR, \$2::INTEGER\)\) AS v\(batch_id, item\)`).
+					WithArgs("batch-1", int32(4)).
+					WillReturnRows(batchRows(false))
+				mock.ExpectQuery(`UPDATE accounts`).
+					WithArgs("50.00", sqlmock.AnyArg(), "test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(250.0))
+				mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+				mock.ExpectExec(`INSERT INTO transactions`).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO transaction_batch_items \(batch_id, item, transaction_id\)`).
+					WithArgs("batch-1", int32(4), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "item already applied",
+			req:  &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "PAYMENT", AmountCents: 5000, BatchId: "batch-1", BatchItem: 4},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM \(VALUES`).
+					WithArgs("batch-1", int32(4)).
+					WillReturnRows(batchRows(true))
+				mock.ExpectRollback()
+			},
+			expectedError: "batch item already processed",
+		},
+		{
+			name: "batch not found",
+			req:  &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "PAYMENT", AmountCents: 5000, BatchId: "missing", BatchItem: 4},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`FROM \(VALUES`).
+					WithArgs("missing", int32(4)).
+					WillReturnRows(sqlmock.NewRows([]string{"batch_id", "item", "expected_count", "applied"}))
+				mock.ExpectRollback()
+				mock.ExpectExec(`INSERT INTO transaction_batch_failures`).
+					WithArgs(sqlmock.AnyArg(), "missing", int32(4), "test-account-id", "", "batch not found").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: "batch not found",
+		},
+		{
+			name: "failed item is recorded",
+			req:  &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "WITHDRAWAL", AmountCents: 50000, ExternalId: "ext-4", BatchId: "batch-1", BatchItem: 4},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`ON CONFLICT \(batch_id, item\) DO UPDATE`).
+					WithArgs(sqlmock.AnyArg(), "batch-1", int32(4), "test-account-id", "ext-4", "insufficient balance").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedError: "insufficient balance",
+		},
+		{
+			name:          "item without batch",
+			req:           &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "PAYMENT", AmountCents: 5000, BatchItem: 4},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "batch_id required",
+		},
+		{
+			name:          "batch without item",
+			req:           &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "PAYMENT", AmountCents: 5000, BatchId: "batch-1"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "batch_item must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			if tt.expectedError == "" || !strings.HasPrefix(tt.expectedError, "batch_") {
+				mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+					WithArgs("test-account-id").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+						AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+			}
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+
+			resp, err := service.CreateTransaction(context.Background(), tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, resp.Error)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_createTransactionBatch_BatchItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, account_type, balance, status, overdraft_limit FROM accounts WHERE id IN`).
+		WithArgs("account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_type", "balance", "status", "overdraft_limit"}).
+			AddRow("account-a", "CHECKING", 100.00, "ACTIVE", 0.0))
+	mock.ExpectQuery(`FROM \(VALUES`).
+		WithArgs("batch-1", int32(1), "batch-1", int32(2), "batch-1", int32(2), "batch-1", int32(3), "batch-1", int32(11)).
+		WillReturnRows(sqlmock.NewRows([]string{"batch_id", "item", "expected_count", "applied"}).
+			AddRow("batch-1", 1, 10, false).
+			AddRow("batch-1", 2, 10, false).
+			AddRow("batch-1", 2, 10, false).
+			AddRow("batch-1", 3, 10, true).
+			AddRow("batch-1", 11, 10, false))
+	mock.ExpectExec(`UPDATE accounts AS a`).
+		WithArgs(sqlmock.AnyArg(), "account-a", "30.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`INSERT INTO transaction_batch_items`).
+		WithArgs("batch-1", int32(1), sqlmock.AnyArg(), "batch-1", int32(2), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+		WithArgs("account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+		WithArgs("account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+		WithArgs("account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+	mock.ExpectCommit()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	reqs := []*pb.CreateTransactionRequest{
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 1000, BatchId: "batch-1", BatchItem: 1},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 1000, BatchId: "batch-1", BatchItem: 2},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 1000, BatchId: "batch-1", BatchItem: 2},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 1000, BatchId: "batch-1", BatchItem: 3},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 1000, BatchId: "batch-1", BatchItem: 11},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 1000},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: -1000, BatchId: "batch-1", BatchItem: 5},
+	}
+	results := service.createTransactionBatch(context.Background(), reqs)
+	require.Len(t, results, 7)
+
+	assert.Empty(t, results[0].err)
+	assert.Empty(t, results[1].err)
+	assert.Equal(t, "batch item already processed", results[2].err)
+	assert.Equal(t, "batch item already processed", results[3].err)
+	assert.Equal(t, "batch_item exceeds the batch's expected_count", results[4].err)
+	assert.Empty(t, results[5].err)
+	assert.Equal(t, "payment amount must be positive", results[6].err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Only failures of items that were not applied are recorded
+	var failures []batchFailure
+	for i, result := range results {
+		if failure, ok := batchFailureOf(reqs[i], result.err); ok {
+			failures = append(failures, failure)
+		}
+	}
+	require.Len(t, failures, 2)
+	assert.Equal(t, int32(11), failures[0].item)
+	assert.Equal(t, int32(5), failures[1].item)
+}
+
+func TestService_createTransactionBatch_ExternalIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, account_type, balance, status, overdraft_limit FROM accounts WHERE id IN`).
+		WithArgs("account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_type", "balance", "status", "overdraft_limit"}).
+			AddRow("account-a", "CHECKING", 100.00, "ACTIVE", 0.0))
+	mock.ExpectQuery(`SELECT external_id FROM transactions WHERE external_id IN \(\$1, \$2\)`).
+		WithArgs("ext-1", "ext-2").
+		WillReturnRows(sqlmock.NewRows([]string{"external_id"}).AddRow("ext-1"))
+	// Only the colliding requests fail; the rest of the batch is applied
+	mock.ExpectExec(`UPDATE accounts AS a`).
+		WithArgs(sqlmock.AnyArg(), "account-a", "30.00").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(
+			sqlmock.AnyArg(), "account-a", "PAYMENT", "20.00", "", sqlmock.AnyArg(), "COMPLETED", "ext-2", []byte("{}"), "20.00", false,
+			sqlmock.AnyArg(), "account-a", "PAYMENT", "10.00", "", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "10.00", false,
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+		WithArgs("account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+		WithArgs("account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+	mock.ExpectCommit()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+
+	results := service.createTransactionBatch(context.Background(), []*pb.CreateTransactionRequest{
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 1000, ExternalId: "ext-1"},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 2000, ExternalId: "ext-2"},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 3000, ExternalId: "ext-2"},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 1000},
+	})
+	require.Len(t, results, 4)
+
+	assert.Equal(t, errExternalIDAlreadyUsed, results[0].err)
+	assert.Empty(t, results[1].err)
+	assert.Equal(t, errExternalIDAlreadyUsed, results[2].err)
+	assert.Empty(t, results[3].err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_GenerateStatement(t *testing.T) {
+	statementRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "account_id", "period", "period_start", "period_end", "opening_balance",
+			"closing_balance", "adjustments", "totals", "transaction_count", "generated_at"})
+	}
+	id := statementID("acc-1", "2024-03")
+
+	tests := []struct {
+		name          string
+		period        string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError string
+		check         func(t *testing.T, statement *pb.Statement)
+	}{
+		{
+			name:   "generated",
+			period: "2024-03",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM statements WHERE id = \$1`).
+					WithArgs(id).
+					WillReturnError(sql.ErrNoRows)
+				// March 2024 in UTC
+				mock.ExpectQuery(`FROM accounts a\s+WHERE a.id = \$1`).
+					WithArgs("acc-1", int64(1709251200), int64(1711929600)).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "opening", "adjustments", "totals"}).
+						AddRow(1700000000, 250.0, 10.0, []byte(`[{"operation_type": "CASH_PURCHASE", "count": 3, "total": -120.50},
+							{"operation_type": "PAYMENT", "count": 1, "total": 500.00}]`)))
+				mock.ExpectExec(`INSERT INTO statements`).
+					WithArgs(id, "acc-1", "2024-03", int64(1709251200), int64(1711929600), "250.00", "639.50", "10.00",
+						sqlmock.AnyArg(), int64(4), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			check: func(t *testing.T, statement *pb.Statement) {
+				assert.Equal(t, id, statement.Id)
+				assert.Equal(t, int64(25000), statement.OpeningBalanceCents)
+				assert.Equal(t, int64(63950), statement.ClosingBalanceCents)
+				assert.Equal(t, int64(1000), statement.AdjustmentsCents)
+				assert.Equal(t, int64(4), statement.TransactionCount)
+				require.Len(t, statement.Totals, 2)
+				assert.Equal(t, &pb.StatementTotal{OperationType: "CASH_PURCHASE", Count: 3, TotalCents: -12050}, statement.Totals[0])
+			},
+		},
+		{
+			name:   "generated before",
+			period: "2024-03",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM statements WHERE id = \$1`).
+					WithArgs(id).
+					WillReturnRows(statementRows().AddRow(id, "acc-1", "2024-03", 1709251200, 1711929600, 250.0, 639.5, 10.0,
+						[]byte(`[{"operation_type": "PAYMENT", "count": 1, "total": 379.50}]`), 1, 1712000000))
+			},
+			check: func(t *testing.T, statement *pb.Statement) {
+				assert.Equal(t, int64(1712000000), statement.GeneratedAt)
+				assert.Equal(t, []*pb.StatementTotal{{OperationType: "PAYMENT", Count: 1, TotalCents: 37950}}, statement.Totals)
+			},
+		},
+		{
+			name:   "account opened after the period",
+			period: "2024-03",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM statements`).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`FROM accounts a`).
+					WillReturnRows(sqlmock.NewRows([]string{"created_at", "opening", "adjustments", "totals"}).
+						AddRow(1711929600, 0.0, 0.0, []byte(`[]`)))
+			},
+			expectedError: "account did not exist in that period",
+		},
+		{
+			name:   "account not found",
+			period: "2024-03",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM statements`).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`FROM accounts a`).WillReturnError(sql.ErrNoRows)
+			},
+			expectedError: "account not found",
+		},
+		{
+			name:          "current month",
+			period:        time.Now().UTC().Format("2006-01"),
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "period has not ended",
+		},
+		{
+			name:          "invalid period",
+			period:        "03/2024",
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "period must be YYYY-MM",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			resp, err := service.GenerateStatement(context.Background(), &pb.GenerateStatementRequest{AccountId: "acc-1", Period: tt.period})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, resp.Error)
+			if tt.check != nil {
+				tt.check(t, resp.Statement)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAccountThrottle(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	throttle := NewAccountThrottle(2, 3)
+	throttle.now = func() time.Time { return now }
+
+	// The burst credits are spent first, then the sustained rate applies
+	for i := 0; i < 3; i++ {
+		assert.True(t, throttle.Allow("acc-1"), "transaction %d", i)
+	}
+	assert.False(t, throttle.Allow("acc-1"))
+	assert.True(t, throttle.Allow("acc-2"), "accounts are throttled separately")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, throttle.Allow("acc-1"))
+	assert.False(t, throttle.Allow("acc-1"))
+
+	// Credits accumulate while the account is quiet, up to the burst
+	now = now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		assert.True(t, throttle.Allow("acc-1"), "transaction %d", i)
+	}
+	assert.False(t, throttle.Allow("acc-1"))
+
+	throttle.ApplyRuntimeConfig(&common.RuntimeConfig{RateLimits: map[string]float64{"per_account": 0}})
+	assert.True(t, throttle.Allow("acc-1"), "a zero rate disables the throttling")
+	rate, burst := throttle.Rates()
+	assert.Equal(t, 0.0, rate)
+	assert.Equal(t, 3.0, burst)
+}
+
+func TestService_CreateTransaction_ThrottlesAccounts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	service.SetAccountThrottle(NewAccountThrottle(1, 1))
+
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("test-account-id").
+		WillReturnError(sql.ErrConnDone)
+
+	req := &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "PAYMENT", AmountCents: 1000}
+	resp, err := service.CreateTransaction(context.Background(), req)
+	require.NoError(t, err)
+	assert.NotEqual(t, errAccountRateLimited, resp.Error)
+
+	// Simulations spend no credits
+	simulated := &pb.CreateTransactionRequest{AccountId: "test-account-id", OperationType: "PAYMENT", AmountCents: 1000, Simulate: true}
+	mock.ExpectQuery(`FROM accounts`).WillReturnError(sql.ErrConnDone)
+	resp, err = service.CreateTransaction(context.Background(), simulated)
+	require.NoError(t, err)
+	assert.NotEqual(t, errAccountRateLimited, resp.Error)
+
+	// Refused before the database is queried
+	resp, err = service.CreateTransaction(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, errAccountRateLimited, resp.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_createTransactionBatch_ThrottlesAccounts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	service.SetAccountThrottle(NewAccountThrottle(1, 1))
+
+	// Only the request over account-a's credits fails; the rest of the batch is applied
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, account_type, balance, status, overdraft_limit FROM accounts WHERE id IN`).
+		WithArgs("account-a", "account-b").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_type", "balance", "status", "overdraft_limit"}).
+			AddRow("account-a", "CHECKING", 100.00, "ACTIVE", 0.0).
+			AddRow("account-b", "CHECKING", 20.00, "ACTIVE", 0.0))
+	mock.ExpectExec(`UPDATE accounts AS a`).
+		WithArgs(sqlmock.AnyArg(), "account-a", "10.00", "account-b", "5.00").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+		WithArgs("account-a").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+		WithArgs("account-b").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+	mock.ExpectCommit()
+
+	results := service.createTransactionBatch(context.Background(), []*pb.CreateTransactionRequest{
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 1000},
+		{AccountId: "account-a", OperationType: "PAYMENT", AmountCents: 2000},
+		{AccountId: "account-b", OperationType: "PAYMENT", AmountCents: 500},
+	})
+	require.Len(t, results, 3)
+
+	assert.Empty(t, results[0].err)
+	assert.Equal(t, errAccountRateLimited, results[1].err)
+	assert.Empty(t, results[2].err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_Transfer_ThrottlesSourceAccount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	service.SetAccountThrottle(NewAccountThrottle(1, 1))
+
+	mock.ExpectBegin().WillReturnError(sql.ErrConnDone)
+	resp, err := service.Transfer(context.Background(), &pb.TransferRequest{SourceAccountId: "acc-b", DestinationAccountId: "acc-a", AmountCents: 3000})
+	require.NoError(t, err)
+	assert.NotEqual(t, errAccountRateLimited, resp.Error)
+
+	// Refused before the database is queried
+	resp, err = service.Transfer(context.Background(), &pb.TransferRequest{SourceAccountId: "acc-b", DestinationAccountId: "acc-c", AmountCents: 3000})
+	require.NoError(t, err)
+	assert.Equal(t, errAccountRateLimited, resp.Error)
+
+	// Credits are spent by the source account only
+	mock.ExpectBegin().WillReturnError(sql.ErrConnDone)
+	resp, err = service.Transfer(context.Background(), &pb.TransferRequest{SourceAccountId: "acc-a", DestinationAccountId: "acc-b", AmountCents: 3000})
+	require.NoError(t, err)
+	assert.NotEqual(t, errAccountRateLimited, resp.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_CloseBusinessDays(t *testing.T) {
+	// 2024-03-03 00:10 UTC: March 1 and 2 have ended
+	until := time.Date(2024, 3, 3, 0, 10, 0, 0, time.UTC)
+	march1, march2 := int64(1709251200), int64(1709337600)
+
+	expectClose := func(mock sqlmock.Sqlmock, day int64, closedAlready bool) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`LOCK TABLE business_days IN EXCLUSIVE MODE`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM business_days WHERE day_start >= \$1\)`).
+			WithArgs(day).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(closedAlready))
+		if !closedAlready {
+			// Balances carry on from the previous day's, without holding up writes to transactions
+			mock.ExpectExec(`INSERT INTO end_of_day_balances .* LEFT JOIN end_of_day_balances p ON p.account_id = a.id AND p.day_start = \$3`).
+				WithArgs(day, day+86400, day-86400).
+				WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectExec(`LOCK TABLE transactions IN SHARE MODE`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`UPDATE end_of_day_balances e`).
+				WithArgs(day).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`INSERT INTO business_days`).
+				WithArgs(day, sqlmock.AnyArg(), "eod-close", int64(2)).
+				WillReturnRows(sqlmock.NewRows([]string{"transaction_count"}).AddRow(7))
+		}
+		mock.ExpectCommit()
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedCount int
+	}{
+		{
+			name: "first close closes the last day only",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT MAX\(day_start\) FROM business_days`).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+				expectClose(mock, march2, false)
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "catches up on the days not closed",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT MAX\(day_start\) FROM business_days`).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(march1 - 86400))
+				expectClose(mock, march1, false)
+				expectClose(mock, march2, true)
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "nothing to close",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT MAX\(day_start\) FROM business_days`).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(march2))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			closed, err := service.CloseBusinessDays(context.Background(), until)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCount, closed)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_GetBusinessDayStatus(t *testing.T) {
+	march1 := int64(1709251200)
+	dayRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"last", "closed_at", "closed_by", "account_count", "transaction_count", "net_amount"})
+	}
+
+	tests := []struct {
+		name          string
+		req           *pb.GetBusinessDayStatusRequest
+		mockSetup     func(mock sqlmock.Sqlmock)
+		expectedError string
+		check         func(t *testing.T, resp *pb.BusinessDayStatusResponse)
+	}{
+		{
+			name: "closed, with the balance of an account",
+			req:  &pb.GetBusinessDayStatusRequest{Date: "2024-03-01", AccountId: "acc-1"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM \(SELECT MAX\(day_start\) AS last FROM business_days\) m`).
+					WithArgs(march1).
+					WillReturnRows(dayRows().AddRow(march1+86400, march1+86700, "eod-close", 12, 40, -310.25))
+				mock.ExpectQuery(`FROM end_of_day_balances WHERE account_id = \$1 AND day_start = \$2`).
+					WithArgs("acc-1", march1).
+					WillReturnRows(sqlmock.NewRows([]string{"balance", "transaction_count", "net_amount"}).AddRow(99.5, 3, -20.0))
+			},
+			check: func(t *testing.T, resp *pb.BusinessDayStatusResponse) {
+				assert.Equal(t, "CLOSED", resp.Day.Status)
+				assert.Equal(t, int64(12), resp.Day.AccountCount)
+				assert.Equal(t, int64(-31025), resp.Day.NetAmountCents)
+				assert.Equal(t, "2024-03-02", resp.LastClosedDate)
+				assert.Equal(t, &pb.EndOfDayBalance{AccountId: "acc-1", Date: "2024-03-01", BalanceCents: 9950,
+					TransactionCount: 3, NetAmountCents: -2000}, resp.Balance)
+			},
+		},
+		{
+			name: "ended but not closed",
+			req:  &pb.GetBusinessDayStatusRequest{Date: "2024-03-01", AccountId: "acc-1"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM \(SELECT MAX\(day_start\) AS last FROM business_days\) m`).
+					WillReturnRows(dayRows().AddRow(march1-86400, 0, "", 0, 0, 0.0))
+			},
+			check: func(t *testing.T, resp *pb.BusinessDayStatusResponse) {
+				assert.Equal(t, "AWAITING_CLOSE", resp.Day.Status)
+				assert.Equal(t, int64(march1+86400), resp.Day.DayEnd)
+				assert.Equal(t, "", resp.LastClosedDate)
+				assert.Nil(t, resp.Balance)
+			},
+		},
+		{
+			name: "current day",
+			req:  &pb.GetBusinessDayStatusRequest{},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`FROM \(SELECT MAX\(day_start\) AS last FROM business_days\) m`).
+					WillReturnRows(dayRows().AddRow(nil, 0, "", 0, 0, 0.0))
+			},
+			check: func(t *testing.T, resp *pb.BusinessDayStatusResponse) {
+				assert.Equal(t, "OPEN", resp.Day.Status)
+				assert.Equal(t, time.Now().UTC().Format("2006-01-02"), resp.Day.Date)
+			},
+		},
+		{
+			name:          "future day",
+			req:           &pb.GetBusinessDayStatusRequest{Date: time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "date is in the future",
+		},
+		{
+			name:          "invalid date",
+			req:           &pb.GetBusinessDayStatusRequest{Date: "01/03/2024"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "date must be YYYY-MM-DD",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+			resp, err := service.GetBusinessDayStatus(context.Background(), tt.req)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, resp.Error)
+			if tt.check != nil {
+				tt.check(t, resp)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRecurringRunAt(t *testing.T) {
+	start := time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC).Unix()
+
+	tests := []struct {
+		frequency string
+		n         int32
+		expected  time.Time
+	}{
+		{recurringDaily, 0, time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC)},
+		{recurringDaily, 3, time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)},
+		{recurringWeekly, 2, time.Date(2026, 2, 14, 9, 0, 0, 0, time.UTC)},
+		// Monthly runs keep the day of start, or the last day of shorter months
+		{recurringMonthly, 1, time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC)},
+		{recurringMonthly, 2, time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC)},
+		{recurringMonthly, 3, time.Date(2026, 4, 30, 9, 0, 0, 0, time.UTC)},
+		{recurringMonthly, 12, time.Date(2027, 1, 31, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected.Unix(), recurringRunAt(start, tt.frequency, tt.n), "%s run %d", tt.frequency, tt.n)
+	}
+}
+
+func TestService_CreateRecurringRule(t *testing.T) {
+	tests := []struct {
+		name          string
+		req           *pb.CreateRecurringRuleRequest
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name: "rule created",
+			req:  &pb.CreateRecurringRuleRequest{AccountId: "acc-1", OperationType: "PAYMENT", AmountCents: 5000, Frequency: "MONTHLY", Description: "rent"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT status FROM accounts WHERE id = \$1`).
+					WithArgs("acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("ACTIVE"))
+				mock.ExpectExec(`INSERT INTO recurring_rules`).
+					WithArgs(sqlmock.AnyArg(), "acc-1", "", "PAYMENT", "50.00", "rent", "MONTHLY", sqlmock.AnyArg(), int64(0), "ACTIVE", "", sqlmock.AnyArg(), maxActiveRecurringRules).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "too many active rules",
+			req:  &pb.CreateRecurringRuleRequest{AccountId: "acc-1", OperationType: "PAYMENT", AmountCents: 5000, Frequency: "WEEKLY"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT status FROM accounts`).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("ACTIVE"))
+				mock.ExpectExec(`INSERT INTO recurring_rules`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedError: "at most 50 active recurring rules per account",
+		},
+		{
+			name: "account not active",
+			req:  &pb.CreateRecurringRuleRequest{AccountId: "acc-1", OperationType: "PAYMENT", AmountCents: 5000, Frequency: "DAILY"},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT status FROM accounts`).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("BLOCKED"))
+			},
+			expectedError: "account not active",
+		},
+		{
+			name:          "invalid frequency",
+			req:           &pb.CreateRecurringRuleRequest{AccountId: "acc-1", OperationType: "PAYMENT", AmountCents: 5000, Frequency: "YEARLY"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "frequency must be DAILY, WEEKLY or MONTHLY",
+		},
+		{
+			name:          "start in the past",
+			req:           &pb.CreateRecurringRuleRequest{AccountId: "acc-1", OperationType: "PAYMENT", AmountCents: 5000, Frequency: "DAILY", StartAt: 1},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "start_at is in the past",
+		},
+		{
+			name:          "invalid operation type",
+			req:           &pb.CreateRecurringRuleRequest{AccountId: "acc-1", OperationType: "GIFT", AmountCents: 5000, Frequency: "DAILY"},
+			mockSetup:     func(mock sqlmock.Sqlmock) {},
+			expectedError: "invalid operation type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+
+			resp, err := service.CreateRecurringRule(context.Background(), tt.req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, resp.Error)
+			if tt.expectedError == "" {
+				assert.Equal(t, "ACTIVE", resp.Rule.Status)
+				assert.Equal(t, resp.Rule.StartAt, resp.Rule.NextRunAt)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_CancelRecurringRule(t *testing.T) {
+	ruleColumns := []string{"id", "account_id", "operation_type", "amount", "description", "frequency", "start_at", "end_at",
+		"next_run_at", "status", "run_count", "skipped_count", "last_run_at", "last_transaction_id", "last_error",
+		"created_by", "created_at", "cancelled_at"}
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedError string
+	}{
+		{
+			name: "rule cancelled",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE recurring_rules SET status = \$3, cancelled_at = \$4`).
+					WithArgs("rule-1", "acc-1", "CANCELLED", sqlmock.AnyArg(), "ACTIVE").
+					WillReturnRows(sqlmock.NewRows(ruleColumns).AddRow("rule-1", "acc-1", "PAYMENT", 50.0, "rent", "MONTHLY",
+						1767225600, 0, 1772323200, "CANCELLED", 2, 0, 1769904000, "tx-2", "", "ops-1", 1767225000, 1770000000))
+			},
+		},
+		{
+			name: "rule not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE recurring_rules`).WillReturnRows(sqlmock.NewRows(ruleColumns))
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM recurring_rules WHERE id = \$1 AND account_id = \$2\)`).
+					WithArgs("rule-1", "acc-1").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedError: "recurring rule not found",
+		},
+		{
+			name: "rule already cancelled",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`UPDATE recurring_rules`).WillReturnRows(sqlmock.NewRows(ruleColumns))
+				mock.ExpectQuery(`SELECT EXISTS`).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			expectedError: "recurring rule not active",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+			tt.mockSetup(mock)
+
+			logger, _ := common.NewLogger("test-service", common.INFO)
+			service := NewService(db, logger)
+
+			resp, err := service.CancelRecurringRule(context.Background(), &pb.CancelRecurringRuleRequest{AccountId: "acc-1", Id: "rule-1"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, resp.Error)
+			if tt.expectedError == "" {
+				assert.Equal(t, int64(5000), resp.Rule.AmountCents)
+				assert.Zero(t, resp.Rule.NextRunAt)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestService_MakeRecurringTransactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	start := time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC).Unix()
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC).Unix()
+	february := time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC).Unix()
+	march := time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC).Unix()
+
+	mock.ExpectQuery(`FROM recurring_rules\s+WHERE status = \$1 AND next_run_at <= \$2`).
+		WithArgs("ACTIVE", now, maxRecurringRunsPerPass).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "tenant_id", "operation_type", "amount", "description",
+			"frequency", "start_at", "end_at", "next_run_at", "runs"}).
+			AddRow("rule-1", "acc-1", "", "PAYMENT", 50.0, "rent", "MONTHLY", start, 0, february, 1).
+			AddRow("rule-2", "acc-2", "", "PAYMENT", 20.0, "gym", "MONTHLY", start, march-1, february, 1))
+
+	// rule-1 makes its second run and moves on to March
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("acc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("acc-1", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE accounts`).
+		WithArgs("50.00", sqlmock.AnyArg(), "acc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(250.0))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).
+		WithArgs("acc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE recurring_rules\s+SET next_run_at = \$3, .* run_count = run_count \+ 1`).
+		WithArgs("rule-1", february, march, false, "COMPLETED", sqlmock.AnyArg(), sqlmock.AnyArg(), "ACTIVE").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO recurring_rule_runs \(rule_id, run, scheduled_at, transaction_id\)`).
+		WithArgs("rule-1", int32(2), february, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// rule-2's account is blocked, so its run is skipped; its next run would be after end_at, so it completes
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("acc-2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("acc-2", "12345678902", "CHECKING", 200.00, 1234567890, 1234567890, "BLOCKED", 0.0))
+	mock.ExpectExec(`UPDATE recurring_rules\s+SET next_run_at = \$3, .* skipped_count = skipped_count \+ 1`).
+		WithArgs("rule-2", february, march, true, "COMPLETED", sqlmock.AnyArg(), "account not active", "ACTIVE").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	made, err := service.MakeRecurringTransactions(context.Background(), now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, made)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_MakeRecurringTransactions_RunNoLongerDue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`FROM recurring_rules`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "tenant_id", "operation_type", "amount", "description",
+			"frequency", "start_at", "end_at", "next_run_at", "runs"}).
+			AddRow("rule-1", "acc-1", "", "PAYMENT", 50.0, "rent", "DAILY", 1767225600, 0, 1767225600, 0))
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("acc-1", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE accounts`).WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(250.0))
+	mock.ExpectQuery(`WHERE account_id = \$1 AND balance < 0`).WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}))
+	mock.ExpectExec(`INSERT INTO transactions`).WillReturnResult(sqlmock.NewResult(1, 1))
+	// Cancelled, or made by another replica, since it was found due: the transaction is rolled back
+	mock.ExpectExec(`UPDATE recurring_rules`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	made, err := service.MakeRecurringTransactions(context.Background(), 1767225600)
+
+	require.NoError(t, err)
+	assert.Zero(t, made)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInstallmentAmounts(t *testing.T) {
+	assert.Equal(t, []common.Cents{10000}, installmentAmounts(10000, 0))
+	assert.Equal(t, []common.Cents{10000}, installmentAmounts(10000, 1))
+	assert.Equal(t, []common.Cents{3334, 3333, 3333}, installmentAmounts(10000, 3))
+	assert.Equal(t, []common.Cents{1, 1}, installmentAmounts(2, 2))
+}
+
+func TestValidateInstallmentCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *pb.CreateTransactionRequest
+		want    string
+	}{
+		{name: "unset", request: &pb.CreateTransactionRequest{OperationType: "CASH_PURCHASE", AmountCents: 100}, want: ""},
+		{name: "valid", request: &pb.CreateTransactionRequest{OperationType: "INSTALLMENT_PURCHASE", AmountCents: 100, InstallmentCount: 12}, want: ""},
+		{name: "other operation type", request: &pb.CreateTransactionRequest{OperationType: "CASH_PURCHASE", AmountCents: 100, InstallmentCount: 2},
+			want: "installment_count only allowed for INSTALLMENT_PURCHASE"},
+		{name: "negative", request: &pb.CreateTransactionRequest{OperationType: "INSTALLMENT_PURCHASE", AmountCents: 100, InstallmentCount: -1},
+			want: "installment_count must be between 1 and 48"},
+		{name: "too many", request: &pb.CreateTransactionRequest{OperationType: "INSTALLMENT_PURCHASE", AmountCents: 10000, InstallmentCount: 49},
+			want: "installment_count must be between 1 and 48"},
+		{name: "less than a cent each", request: &pb.CreateTransactionRequest{OperationType: "INSTALLMENT_PURCHASE", AmountCents: 2, InstallmentCount: 3},
+			want: "amount too small for installment_count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, validateInstallmentCount(tt.request))
+		})
+	}
+}
+
+func TestService_CreateTransaction_Installments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("test-account-id", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	// Only the first installment is debited now
+	mock.ExpectQuery(`UPDATE accounts`).
+		WithArgs("-33.34", sqlmock.AnyArg(), "test-account-id").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(166.66))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(sqlmock.AnyArg(), "test-account-id", "INSTALLMENT_PURCHASE", "-33.34", "Laptop", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "-33.34", false, "", "0.00", 0.0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO installments \(purchase_id, number, amount, due_at, next_attempt_at, tenant_id\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\), \(\$7, \$8, \$9, \$10, \$11, \$12\)`).
+		WithArgs(sqlmock.AnyArg(), 2, "33.33", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg(), 3, "33.33", sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	resp, err := service.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{
+		AccountId:        "test-account-id",
+		OperationType:    "INSTALLMENT_PURCHASE",
+		AmountCents:      10000,
+		Description:      "Laptop",
+		InstallmentCount: 3,
+	})
+
+	require.NoError(t, err)
+	require.Empty(t, resp.Error)
+	assert.Equal(t, int64(-3334), resp.Transaction.AmountCents)
+	plan := resp.Transaction.InstallmentPlan
+	require.NotNil(t, plan)
+	assert.Equal(t, resp.Transaction.Id, plan.PurchaseId)
+	assert.Equal(t, int32(3), plan.InstallmentCount)
+	assert.Equal(t, int64(10000), plan.TotalAmountCents)
+	assert.Equal(t, int32(1), plan.PaidCount)
+	assert.Equal(t, int64(6666), plan.RemainingCents)
+	require.Len(t, plan.Installments, 3)
+	assert.Equal(t, "PAID", plan.Installments[0].Status)
+	assert.Equal(t, resp.Transaction.Id, plan.Installments[0].TransactionId)
+	assert.Equal(t, "PENDING", plan.Installments[2].Status)
+	assert.Equal(t, recurringRunAt(resp.Transaction.CreatedAt, recurringMonthly, 2), plan.Installments[2].DueAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_GetTransaction_InstallmentPlan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT id, account_id, operation_type, amount, description, created_at, status`).
+		WithArgs("installment-2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "operation_type", "amount", "description", "created_at", "status", "external_id", "tags", "metadata", "transfer_id", "original_transaction_id", "balance", "overdrawn", "original_currency", "original_amount", "fx_rate"}).
+			AddRow("installment-2", "test-account-id", "INSTALLMENT_PURCHASE", -33.33, "Installment 2/3: Laptop", 1769904000, "COMPLETED", "", "", []byte("{}"), "", "", 100.0, false, "", "0.00", 0.0))
+	// The debit of the second installment shows the plan of its purchase
+	mock.ExpectQuery(`FROM installments i\s+JOIN transactions p ON p.id = i.purchase_id`).
+		WithArgs("installment-2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "amount", "status", "created_at", "number", "amount", "due_at", "status",
+			"transaction_id", "paid_at", "attempts", "last_error"}).
+			AddRow("purchase-1", -33.34, "COMPLETED", 1767225600, 2, 33.33, 1769904000, "PAID", "installment-2", 1769904000, 0, "").
+			AddRow("purchase-1", -33.34, "COMPLETED", 1767225600, 3, 33.33, 1772323200, "OVERDUE", "", 0, 1, "insufficient balance"))
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	resp, err := service.GetTransaction(context.Background(), &pb.GetTransactionRequest{Id: "installment-2"})
+
+	require.NoError(t, err)
+	require.Empty(t, resp.Error)
+	plan := resp.Transaction.InstallmentPlan
+	require.NotNil(t, plan)
+	assert.Equal(t, "purchase-1", plan.PurchaseId)
+	assert.Equal(t, int32(3), plan.InstallmentCount)
+	assert.Equal(t, int64(10000), plan.TotalAmountCents)
+	assert.Equal(t, int32(2), plan.PaidCount)
+	assert.Equal(t, int64(3333), plan.RemainingCents)
+	require.Len(t, plan.Installments, 3)
+	assert.Equal(t, &pb.Installment{Number: 1, AmountCents: 3334, DueAt: 1767225600, Status: "PAID", TransactionId: "purchase-1", PaidAt: 1767225600},
+		plan.Installments[0])
+	assert.Equal(t, "OVERDUE", plan.Installments[2].Status)
+	assert.Equal(t, int32(1), plan.Installments[2].Attempts)
+	assert.Equal(t, "insufficient balance", plan.Installments[2].LastError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_DebitDueInstallments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	t.Setenv("APP_ENV", "production")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC).Unix()
+	february := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC).Unix()
+
+	// Installments of purchases held for review are not read, so they cannot crowd out those that can be debited
+	mock.ExpectQuery(`FROM installments i\s+JOIN transactions p ON p.id = i.purchase_id\s+WHERE i.status IN \(\$1, \$2\) AND i.next_attempt_at <= \$3 AND p.status NOT IN \('PENDING', 'UNDER_REVIEW'\)`).
+		WithArgs("PENDING", "OVERDUE", now, maxInstallmentsPerPass).
+		WillReturnRows(sqlmock.NewRows([]string{"purchase_id", "number", "count", "amount", "next_attempt_at", "account_id", "description", "status", "tenant_id"}).
+			AddRow("purchase-1", 2, 3, 33.33, february, "acc-1", "Laptop", "COMPLETED", "issuer-a").
+			AddRow("purchase-2", 2, 2, 500.0, february, "acc-2", "", "COMPLETED", "").
+			AddRow("purchase-3", 2, 2, 10.0, february, "acc-3", "", "REVERSED", ""))
+
+	// purchase-1's second installment is debited on behalf of its tenant and marked paid in the same transaction
+	mock.ExpectQuery(`SELECT settings FROM tenant_settings`).
+		WithArgs("issuer-a", "production").
+		WillReturnRows(sqlmock.NewRows([]string{"settings"}).AddRow([]byte(`{}`)))
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("acc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("acc-1", "12345678901", "CHECKING", 200.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE accounts`).
+		WithArgs("-33.33", sqlmock.AnyArg(), "acc-1").
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(166.67))
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(sqlmock.AnyArg(), "acc-1", "INSTALLMENT_PURCHASE", "-33.33", "Installment 2/3: Laptop", sqlmock.AnyArg(), "COMPLETED", "", []byte("{}"), "-33.33", false, "", "0.00", 0.0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE installments SET status = \$3, transaction_id = \$4`).
+		WithArgs("purchase-1", int32(2), "PAID", sqlmock.AnyArg(), sqlmock.AnyArg(), "PENDING", "OVERDUE", february).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// purchase-2's account cannot cover its installment, so it becomes overdue
+	mock.ExpectQuery(`SELECT id, document_number, account_type, balance, created_at, updated_at`).
+		WithArgs("acc-2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "document_number", "account_type", "balance", "created_at", "updated_at", "status", "overdraft_limit"}).
+			AddRow("acc-2", "12345678902", "CHECKING", 20.00, 1234567890, 1234567890, "ACTIVE", 0.0))
+	mock.ExpectExec(`UPDATE installments SET status = \$3, attempts = attempts \+ 1`).
+		WithArgs("purchase-2", int32(2), "OVERDUE", "insufficient balance", sqlmock.AnyArg(), "PENDING", february).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// purchase-3 was reversed, so its unpaid installments are cancelled
+	mock.ExpectExec(`UPDATE installments SET status = \$2 WHERE purchase_id = \$1`).
+		WithArgs("purchase-3", "CANCELLED", "PENDING", "OVERDUE").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	debited, err := service.DebitDueInstallments(context.Background(), now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, debited)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestService_MessageTemplates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	logger, _ := common.NewLogger("test-service", common.INFO)
+	service := NewService(db, logger)
+	admin := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, common.RoleAdmin, common.OperatorIDMetadataKey, "admin-1"))
+	support := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		common.CallerRoleMetadataKey, common.RoleSupport, common.OperatorIDMetadataKey, "agent-7"))
+
+	put, err := service.PutMessageTemplate(support, &pb.PutMessageTemplateRequest{
+		TenantId: "acme", EventType: "transaction.created", Channel: "WEBHOOK", Body: "{}"})
+	require.NoError(t, err)
+	assert.Equal(t, "permission denied", put.Error)
+
+	put, err = service.PutMessageTemplate(admin, &pb.PutMessageTemplateRequest{
+		TenantId: "acme", EventType: "account.created", Channel: "WEBHOOK", Body: "{}"})
+	require.NoError(t, err)
+	assert.Equal(t, "unknown event type", put.Error)
+
+	// A misspelled variable is refused when the template is set rather than when events are published
+	put, err = service.PutMessageTemplate(admin, &pb.PutMessageTemplateRequest{
+		TenantId: "acme", EventType: "transaction.created", Channel: "EMAIL", Subject: "Hi", Body: "{{.ammount}}"})
+	require.NoError(t, err)
+	assert.Contains(t, put.Error, `map has no entry for key "ammount"`)
+
+	mock.ExpectExec(`INSERT INTO message_templates`).
+		WithArgs("acme", "transaction.created", "EMAIL", "Purchase of {{amount .amount}}", "{{.description}}", "admin-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	put, err = service.PutMessageTemplate(admin, &pb.PutMessageTemplateRequest{
+		TenantId: "acme", EventType: "transaction.created", Channel: "EMAIL", Subject: "Purchase of {{amount .amount}}", Body: "{{.description}}"})
+	require.NoError(t, err)
+	assert.Empty(t, put.Error)
+	assert.Equal(t, "TENANT", put.Template.Source)
+	assert.Equal(t, "admin-1", put.Template.UpdatedBy)
+
+	preview, err := service.PreviewMessageTemplate(admin, &pb.PreviewMessageTemplateRequest{
+		TenantId: "acme", EventType: "transaction.created", Channel: "WEBHOOK",
+		Body:        `{"id": {{json .transaction_id}}, "amount": {{.amount}}, "tenant": {{json .tenant_id}}}`,
+		PayloadJson: `{"transaction_id": "tx-1", "amount": -10}`,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, preview.Error)
+	assert.Equal(t, `{"id": "tx-1", "amount": -10, "tenant": "acme"}`, preview.Message.Body)
+	assert.Contains(t, preview.VariablesJson, `"event_type":"transaction.created"`)
+
+	preview, err = service.PreviewMessageTemplate(admin, &pb.PreviewMessageTemplateRequest{
+		TenantId: "acme", EventType: "transaction.created", Channel: "WEBHOOK", Body: "{}", PayloadJson: "not json"})
+	require.NoError(t, err)
+	assert.Equal(t, "invalid payload_json", preview.Error)
+
+	sent, err := service.TestSendMessageTemplate(admin, &pb.TestSendMessageTemplateRequest{
+		TenantId: "acme", EventType: "transaction.created", Channel: "WEBHOOK", Body: "{}", Recipient: "http://hooks.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "recipient must be an https URL", sent.Error)
+
+	sent, err = service.TestSendMessageTemplate(admin, &pb.TestSendMessageTemplateRequest{
+		TenantId: "acme", EventType: "transaction.created", Channel: "WEBHOOK", Body: "{}", Recipient: "https://hooks.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "event outbox disabled", sent.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,185 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"github.com/google/uuid"
+)
+
+// Operation types of the two transactions recorded for a transfer. They have no operation rule, so they
+// cannot be created through CreateTransaction.
+const (
+	transferOutOperationType = "TRANSFER_OUT"
+	transferInOperationType  = "TRANSFER_IN"
+)
+
+// transferError is a transfer failure reported to the caller as is.
+type transferError string
+
+func (e transferError) Error() string { return string(e) }
+
+// Transfer moves amount from the source account to the destination account. The source is debited with a
+// TRANSFER_OUT transaction and the destination credited with a TRANSFER_IN transaction; both share a transfer_id
+// and are written in one database transaction with the two balance updates, so either both happen or neither does.
+// Both accounts must be ACTIVE and the source balance must cover the amount.
+// Transfers are not subject to operation rules, tenant policies or fraud scoring, but spend a credit of the
+// source account's throttling.
+func (s *Service) Transfer(ctx context.Context, req *pb.TransferRequest) (resp *pb.TransferResponse, err error) {
+	ctx, span := s.startMoneyMovement(ctx, "transaction.transfer", amountAttribute.Int64(req.AmountCents))
+	defer func() { endMoneyMovement(span, resp.GetError()) }()
+	logger := s.logger.WithContext(ctx)
+	amount := common.Cents(req.AmountCents)
+
+	logger.Info("Creating transfer: Source=%s, Destination=%s, Amount=%s", req.SourceAccountId, req.DestinationAccountId, amount)
+
+	if req.SourceAccountId == "" || req.DestinationAccountId == "" {
+		return &pb.TransferResponse{Error: "missing required fields"}, nil
+	}
+	if req.SourceAccountId == req.DestinationAccountId {
+		return &pb.TransferResponse{Error: "source and destination accounts must differ"}, nil
+	}
+	if amount <= 0 {
+		return &pb.TransferResponse{Error: "transfer amount must be positive"}, nil
+	}
+	if len(req.Description) > maxDescriptionLength {
+		return &pb.TransferResponse{Error: "description too long"}, nil
+	}
+	if s.missingAccounts.Contains(req.SourceAccountId) || s.missingAccounts.Contains(req.DestinationAccountId) {
+		return &pb.TransferResponse{Error: "account not found"}, nil
+	}
+	if s.throttle != nil && !s.throttle.Allow(req.SourceAccountId) {
+		logger.Warn("Transfer throttled: AccountID=%s", req.SourceAccountId)
+		return &pb.TransferResponse{Error: errAccountRateLimited}, nil
+	}
+
+	// Accounts are always locked in ID order, so opposite transfers between the same accounts cannot deadlock
+	accountIDs := []string{req.SourceAccountId, req.DestinationAccountId}
+	sort.Strings(accountIDs)
+	for _, accountID := range accountIDs {
+		unlock, err := s.accountLocks.Lock(ctx, accountID)
+		if err != nil {
+			logger.Error("Transfer aborted while waiting for account lock: ID=%s, Error=%v", accountID, err)
+			return &pb.TransferResponse{Error: "request cancelled"}, nil
+		}
+		defer unlock()
+	}
+
+	transferID := uuid.New().String()
+	now := common.GetCurrentTimestamp()
+	debit := &common.Transaction{
+		ID:            uuid.New().String(),
+		AccountID:     req.SourceAccountId,
+		OperationType: transferOutOperationType,
+		Amount:        -amount,
+		Description:   req.Description,
+		CreatedAt:     now,
+		Status:        "COMPLETED",
+		TransferID:    transferID,
+	}
+	credit := &common.Transaction{
+		ID:            uuid.New().String(),
+		AccountID:     req.DestinationAccountId,
+		OperationType: transferInOperationType,
+		Amount:        amount,
+		Description:   req.Description,
+		CreatedAt:     now,
+		Status:        "COMPLETED",
+		TransferID:    transferID,
+	}
+
+	err = common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		// The row locks make the balance check hold against other instances until the transfer commits
+		start := time.Now()
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id, balance, status, overdraft_limit FROM accounts WHERE id IN ($1, $2) ORDER BY id FOR UPDATE
+		`, accountIDs[0], accountIDs[1])
+		logger.LogDatabase("SELECT", "accounts", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+		balances := make(map[string]common.Cents, 2)
+		statuses := make(map[string]string, 2)
+		overdraftLimits := make(map[string]common.Cents, 2)
+		for rows.Next() {
+			var id, status string
+			var balance, overdraftLimit common.Cents
+			if err := rows.Scan(&id, &balance, &status, &overdraftLimit); err != nil {
+				rows.Close()
+				return err
+			}
+			balances[id], statuses[id], overdraftLimits[id] = balance, status, overdraftLimit
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, accountID := range accountIDs {
+			status, ok := statuses[accountID]
+			if !ok {
+				s.missingAccounts.Add(accountID)
+				return transferError("account not found")
+			}
+			if status != "ACTIVE" {
+				return transferError("account not active")
+			}
+		}
+		// The source may go into its overdraft, like any other debit
+		if balances[req.SourceAccountId]-amount < -overdraftLimits[req.SourceAccountId] {
+			return transferError("insufficient balance")
+		}
+		debit.Overdrawn = balances[req.SourceAccountId]-amount < 0
+
+		for _, t := range []*common.Transaction{debit, credit} {
+			start = time.Now()
+			_, err := tx.ExecContext(ctx, `
+				UPDATE accounts SET balance = balance + $1, updated_at = $2 WHERE id = $3
+			`, t.Amount, now, t.AccountID)
+			logger.LogDatabase("UPDATE", "accounts", time.Since(start), err)
+			if err != nil {
+				return err
+			}
+			recordBalanceMutation(span, t.AccountID, t.ID, t.OperationType, t.Amount, balances[t.AccountID])
+
+			start = time.Now()
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO transactions (id, account_id, operation_type, amount, description, created_at, status, transfer_id, overdrawn)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			`, t.ID, t.AccountID, t.OperationType, t.Amount, t.Description, t.CreatedAt, t.Status, t.TransferID, t.Overdrawn)
+			logger.LogDatabase("INSERT", "transactions", time.Since(start), err)
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.enqueueTransactionsCreated(ctx, tx, debit, credit)
+	})
+
+	var transferErr transferError
+	switch {
+	case err == nil:
+	case errors.As(err, &transferErr):
+		logger.Warn("Transfer refused: Source=%s, Destination=%s, Error=%s", req.SourceAccountId, req.DestinationAccountId, transferErr.Error())
+		return &pb.TransferResponse{Error: transferErr.Error()}, nil
+	case common.IsCancellation(err):
+		logger.Warn("Transfer cancelled by client, changes rolled back: Source=%s", req.SourceAccountId)
+		return &pb.TransferResponse{Error: "request cancelled"}, nil
+	default:
+		logger.Error("Transfer failed: Source=%s, Destination=%s, Error=%v", req.SourceAccountId, req.DestinationAccountId, err)
+		return &pb.TransferResponse{Error: "could not complete transfer"}, nil
+	}
+
+	logger.Info("Transfer completed: TransferID=%s, Source=%s, Destination=%s, Amount=%s",
+		transferID, req.SourceAccountId, req.DestinationAccountId, amount)
+	return &pb.TransferResponse{
+		TransferId: transferID,
+		Debit:      ConvertTransactionToProto(debit),
+		Credit:     ConvertTransactionToProto(credit),
+	}, nil
+}
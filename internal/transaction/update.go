@@ -0,0 +1,257 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"github.com/google/uuid"
+)
+
+// transactionColumns selects the columns read by scanTransaction.
+const transactionColumns = `id, account_id, operation_type, amount, description, created_at, status,
+	COALESCE(external_id, ''), tags, metadata, COALESCE(transfer_id, ''), COALESCE(original_transaction_id, '')`
+
+// Limits on the editable fields of a transaction. Tags are stored comma-separated, so they are restricted
+// to a character set without commas.
+const (
+	maxDescriptionLength   = 500
+	maxTransactionTags     = 10
+	maxTagLength           = 32
+	maxMetadataEntries     = 20
+	maxMetadataKeyLength   = 40
+	maxMetadataValueLength = 200
+)
+
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+
+// editableTransactionFields lists the fields UpdateTransaction may change.
+var editableTransactionFields = map[string]bool{
+	"description": true,
+	"tags":        true,
+	"metadata":    true,
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTransaction reads a row selected with transactionColumns.
+func scanTransaction(row rowScanner) (*common.Transaction, error) {
+	var scanned scannedTransaction
+	if err := row.Scan(scanned.dest()...); err != nil {
+		return nil, err
+	}
+	return scanned.decode()
+}
+
+// scannedTransaction holds the columns selected with transactionColumns until they are decoded.
+type scannedTransaction struct {
+	transaction common.Transaction
+	description sql.NullString
+	tags        string
+	metadata    []byte
+}
+
+// dest returns the scan destinations of transactionColumns, in order.
+func (t *scannedTransaction) dest() []interface{} {
+	return []interface{}{&t.transaction.ID, &t.transaction.AccountID, &t.transaction.OperationType, &t.transaction.Amount,
+		&t.description, &t.transaction.CreatedAt, &t.transaction.Status, &t.transaction.ExternalID, &t.tags, &t.metadata,
+		&t.transaction.TransferID, &t.transaction.OriginalTransactionID}
+}
+
+// decode returns the scanned transaction with its description, tags and metadata decoded.
+func (t *scannedTransaction) decode() (*common.Transaction, error) {
+	transaction := t.transaction
+	transaction.Description = t.description.String
+	if t.tags != "" {
+		transaction.Tags = strings.Split(t.tags, ",")
+	}
+	if len(t.metadata) > 0 {
+		var values map[string]string
+		if err := json.Unmarshal(t.metadata, &values); err != nil {
+			return nil, fmt.Errorf("invalid metadata of transaction %s: %w", transaction.ID, err)
+		}
+		if len(values) > 0 {
+			transaction.Metadata = values
+		}
+	}
+	return &transaction, nil
+}
+
+// encodeMetadata returns metadata as stored in transactions.metadata.
+func encodeMetadata(metadata map[string]string) ([]byte, error) {
+	if len(metadata) == 0 {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(metadata)
+}
+
+// transactionEditError is an edit rejected for a reason reported to the caller.
+type transactionEditError string
+
+func (e transactionEditError) Error() string { return string(e) }
+
+// editableFields is the part of a transaction that can be changed after creation, as recorded in transaction_edits.
+type editableFields struct {
+	Description string            `json:"description"`
+	Tags        []string          `json:"tags"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
+// validateTransactionEdit checks the requested values and returns the fields to replace,
+// or an error message for the caller.
+func validateTransactionEdit(req *pb.UpdateTransactionRequest) (map[string]bool, string) {
+	fields := make(map[string]bool)
+	if len(req.UpdateMask) > 0 {
+		for _, field := range req.UpdateMask {
+			if !editableTransactionFields[field] {
+				return nil, fmt.Sprintf("field cannot be updated: %s", field)
+			}
+			fields[field] = true
+		}
+	} else {
+		if req.Description != "" {
+			fields["description"] = true
+		}
+		if len(req.Tags) > 0 {
+			fields["tags"] = true
+		}
+		if len(req.Metadata) > 0 {
+			fields["metadata"] = true
+		}
+		if len(fields) == 0 {
+			return nil, "nothing to update"
+		}
+	}
+
+	if len(req.Description) > maxDescriptionLength {
+		return nil, "description too long"
+	}
+	if len(req.Tags) > maxTransactionTags {
+		return nil, fmt.Sprintf("at most %d tags allowed", maxTransactionTags)
+	}
+	for _, tag := range req.Tags {
+		if len(tag) > maxTagLength || !tagPattern.MatchString(tag) {
+			return nil, fmt.Sprintf("invalid tag: %q", tag)
+		}
+	}
+	if len(req.Metadata) > maxMetadataEntries {
+		return nil, fmt.Sprintf("at most %d metadata entries allowed", maxMetadataEntries)
+	}
+	for key, value := range req.Metadata {
+		if key == "" || len(key) > maxMetadataKeyLength || len(value) > maxMetadataValueLength {
+			return nil, fmt.Sprintf("invalid metadata entry: %q", key)
+		}
+	}
+	return fields, ""
+}
+
+// UpdateTransaction edits the description, tags and metadata of a transaction, so miskeyed descriptions
+// can be fixed without a reversal. Amounts, operation types and every other field are never changed.
+// Only support and admin operators identified by an operator ID may edit transactions; each edit is
+// recorded in transaction_edits with the values before and after.
+func (s *Service) UpdateTransaction(ctx context.Context, req *pb.UpdateTransactionRequest) (*pb.UpdateTransactionResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	role := common.CallerRoleFromContext(ctx)
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.SupportOrAdminOperator) {
+		logger.Warn("Rejected transaction update: ID=%s, Role=%q, Operator=%q", req.Id, role, operator)
+		return &pb.UpdateTransactionResponse{Error: "permission denied"}, nil
+	}
+	if req.Id == "" {
+		return &pb.UpdateTransactionResponse{Error: "id required"}, nil
+	}
+	fields, msg := validateTransactionEdit(req)
+	if msg != "" {
+		return &pb.UpdateTransactionResponse{Error: msg}, nil
+	}
+
+	var transaction *common.Transaction
+	err := common.WithTransaction(ctx, s.db, func(tx *sql.Tx) error {
+		start := time.Now()
+		var err error
+		transaction, err = scanTransaction(tx.QueryRowContext(ctx, `
+			SELECT `+transactionColumns+` FROM transactions WHERE id = $1 FOR UPDATE
+		`, req.Id))
+		logger.LogDatabase("SELECT", "transactions", time.Since(start), err)
+		if err == sql.ErrNoRows {
+			return transactionEditError("not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		previous := editableFields{Description: transaction.Description, Tags: transaction.Tags, Metadata: transaction.Metadata}
+		if fields["description"] {
+			transaction.Description = req.Description
+		}
+		if fields["tags"] {
+			transaction.Tags = req.Tags
+		}
+		if fields["metadata"] {
+			transaction.Metadata = req.Metadata
+		}
+		updated := editableFields{Description: transaction.Description, Tags: transaction.Tags, Metadata: transaction.Metadata}
+
+		previousJSON, err := json.Marshal(previous)
+		if err != nil {
+			return err
+		}
+		updatedJSON, err := json.Marshal(updated)
+		if err != nil {
+			return err
+		}
+		metadataJSON, err := encodeMetadata(transaction.Metadata)
+		if err != nil {
+			return err
+		}
+
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE transactions SET description = $1, tags = $2, metadata = $3 WHERE id = $4
+		`, transaction.Description, strings.Join(transaction.Tags, ","), metadataJSON, transaction.ID)
+		logger.LogDatabase("UPDATE", "transactions", time.Since(start), err)
+		if err != nil {
+			return err
+		}
+
+		start = time.Now()
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO transaction_edits (id, transaction_id, edited_by, edited_at, previous, updated)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, uuid.New().String(), transaction.ID, operator, common.GetCurrentTimestamp(), previousJSON, updatedJSON)
+		logger.LogDatabase("INSERT", "transaction_edits", time.Since(start), err)
+		return err
+	})
+	if err != nil {
+		var editErr transactionEditError
+		switch {
+		case errors.As(err, &editErr):
+			return &pb.UpdateTransactionResponse{Error: editErr.Error()}, nil
+		case common.IsCancellation(err):
+			return &pb.UpdateTransactionResponse{Error: "request cancelled"}, nil
+		default:
+			logger.Error("Transaction update failed: ID=%s, Error=%v", req.Id, err)
+			return &pb.UpdateTransactionResponse{Error: "database error"}, nil
+		}
+	}
+
+	changed := make([]string, 0, len(fields))
+	for field := range fields {
+		changed = append(changed, field)
+	}
+	sort.Strings(changed)
+	logger.Info("Transaction updated: ID=%s, Operator=%s, Fields=%s", transaction.ID, operator, strings.Join(changed, ","))
+	return &pb.UpdateTransactionResponse{Transaction: ConvertTransactionToProto(transaction)}, nil
+}
@@ -0,0 +1,104 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pb "github.com/YASHIRAI/pismo-task/proto/transaction"
+)
+
+// webhookKeyErrors are the signing key store errors reported to the caller as is.
+var webhookKeyErrors = []error{
+	common.ErrUnknownWebhookSubscriber,
+	common.ErrWebhookKeyNotFound,
+	common.ErrWebhookKeyRetired,
+	common.ErrTooManyWebhookKeys,
+	common.ErrLastWebhookKey,
+}
+
+// WebhookKeys returns the store of the keys event deliveries are signed with, for the outbox publisher to sign with.
+func (s *Service) WebhookKeys() *common.WebhookKeyStore {
+	return s.webhookKeys
+}
+
+// ListWebhookSigningKeys lists the signing keys of a subscriber, active and retired, without their secrets.
+// Only admins may list keys.
+func (s *Service) ListWebhookSigningKeys(ctx context.Context, req *pb.ListWebhookSigningKeysRequest) (*pb.ListWebhookSigningKeysResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected webhook signing key listing: Subscriber=%s, caller is not an admin operator", req.Subscriber)
+		return &pb.ListWebhookSigningKeysResponse{Error: "permission denied"}, nil
+	}
+
+	keys, err := s.webhookKeys.List(ctx, req.Subscriber)
+	if err != nil {
+		return &pb.ListWebhookSigningKeysResponse{Error: s.webhookKeyError(ctx, "listing", req.Subscriber, err)}, nil
+	}
+
+	pbKeys := make([]*pb.WebhookSigningKey, len(keys))
+	for i := range keys {
+		pbKeys[i] = ConvertWebhookSigningKeyToProto(&keys[i])
+	}
+	return &pb.ListWebhookSigningKeysResponse{Keys: pbKeys}, nil
+}
+
+// CreateWebhookSigningKey starts a key rotation: it adds an active signing key for a subscriber and returns it with
+// its secret, which is not shown again. Deliveries are signed with it alongside the keys already active, so the
+// subscriber can switch over without rejecting deliveries before the old key is retired.
+// Only admin operators may create keys.
+func (s *Service) CreateWebhookSigningKey(ctx context.Context, req *pb.CreateWebhookSigningKeyRequest) (*pb.WebhookSigningKeyResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected webhook signing key creation: Subscriber=%s, Operator=%q", req.Subscriber, operator)
+		return &pb.WebhookSigningKeyResponse{Error: "permission denied"}, nil
+	}
+
+	key, err := s.webhookKeys.Create(ctx, req.Subscriber, operator)
+	if err != nil {
+		return &pb.WebhookSigningKeyResponse{Error: s.webhookKeyError(ctx, "creation", req.Subscriber, err)}, nil
+	}
+
+	logger.Info("Webhook signing key created: Subscriber=%s, KeyID=%s, Operator=%s", key.Subscriber, key.KeyID, operator)
+	return &pb.WebhookSigningKeyResponse{Key: ConvertWebhookSigningKeyToProto(key)}, nil
+}
+
+// RetireWebhookSigningKey ends a key rotation: deliveries to the subscriber are no longer signed with the key.
+// Other service instances stop using it once their cached keys expire. Only admin operators may retire keys.
+func (s *Service) RetireWebhookSigningKey(ctx context.Context, req *pb.RetireWebhookSigningKeyRequest) (*pb.WebhookSigningKeyResponse, error) {
+	logger := s.logger.WithContext(ctx)
+
+	operator := common.OperatorIDFromContext(ctx)
+	if !common.Authorize(ctx, common.AdminOperator) {
+		logger.Warn("Rejected webhook signing key retirement: Subscriber=%s, KeyID=%s, Operator=%q", req.Subscriber, req.KeyId, operator)
+		return &pb.WebhookSigningKeyResponse{Error: "permission denied"}, nil
+	}
+	if req.KeyId == "" {
+		return &pb.WebhookSigningKeyResponse{Error: "key_id required"}, nil
+	}
+
+	key, err := s.webhookKeys.Retire(ctx, req.Subscriber, req.KeyId, operator)
+	if err != nil {
+		return &pb.WebhookSigningKeyResponse{Error: s.webhookKeyError(ctx, "retirement", req.Subscriber, err)}, nil
+	}
+
+	logger.Info("Webhook signing key retired: Subscriber=%s, KeyID=%s, Operator=%s", key.Subscriber, key.KeyID, operator)
+	return &pb.WebhookSigningKeyResponse{Key: ConvertWebhookSigningKeyToProto(key)}, nil
+}
+
+// webhookKeyError returns the message reported for a failed signing key operation, logging unexpected failures.
+func (s *Service) webhookKeyError(ctx context.Context, operation, subscriber string, err error) string {
+	for _, known := range webhookKeyErrors {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	if common.IsCancellation(err) {
+		return "request cancelled"
+	}
+	s.logger.WithContext(ctx).Error("Webhook signing key %s failed: Subscriber=%s, Error=%v", operation, subscriber, err)
+	return "database error"
+}
@@ -0,0 +1,86 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	cpfPattern  = regexp.MustCompile(`^(\d{11}|\d{3}\.\d{3}\.\d{3}-\d{2})$`)
+	cnpjPattern = regexp.MustCompile(`^([0-9A-Z]{12}\d{2}|[0-9A-Z]{2}\.[0-9A-Z]{3}\.[0-9A-Z]{3}/[0-9A-Z]{4}-\d{2})$`)
+)
+
+// CPF validates the Cadastro de Pessoas Físicas of individuals: 11 digits, the last two check digits, either
+// plain or formatted as 123.456.789-09. Its canonical form is the 11 digits.
+type CPF struct{}
+
+// Type returns "CPF".
+func (CPF) Type() string { return "CPF" }
+
+// Normalize checks the format and check digits of a CPF.
+func (CPF) Normalize(documentNumber string) (string, error) {
+	if !cpfPattern.MatchString(documentNumber) {
+		return "", fmt.Errorf("CPF must have 11 digits, optionally formatted as 000.000.000-00: %w", ErrMalformed)
+	}
+	digits := stripPunctuation(documentNumber)
+	if allSame(digits) {
+		return "", fmt.Errorf("CPF %s is not a valid CPF", digits)
+	}
+	if !checkDigitsMatch(digits, []int{10, 9, 8, 7, 6, 5, 4, 3, 2}, []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2}) {
+		return "", fmt.Errorf("CPF %s has invalid check digits", digits)
+	}
+	return digits, nil
+}
+
+// CNPJ validates the Cadastro Nacional da Pessoa Jurídica of companies: 14 characters, the last two check digits,
+// either plain or formatted as 12.345.678/0001-95. The first 12 characters may be letters, as in the
+// alphanumeric CNPJ issued from July 2026. Its canonical form is the 14 characters, upper case.
+type CNPJ struct{}
+
+// Type returns "CNPJ".
+func (CNPJ) Type() string { return "CNPJ" }
+
+// Normalize checks the format and check digits of a CNPJ.
+func (CNPJ) Normalize(documentNumber string) (string, error) {
+	documentNumber = strings.ToUpper(documentNumber)
+	if !cnpjPattern.MatchString(documentNumber) {
+		return "", fmt.Errorf("CNPJ must have 14 characters, optionally formatted as 00.000.000/0000-00: %w", ErrMalformed)
+	}
+	characters := stripPunctuation(documentNumber)
+	if allSame(characters) {
+		return "", fmt.Errorf("CNPJ %s is not a valid CNPJ", characters)
+	}
+	if !checkDigitsMatch(characters, []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}, []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) {
+		return "", fmt.Errorf("CNPJ %s has invalid check digits", characters)
+	}
+	return characters, nil
+}
+
+// stripPunctuation removes the formatting characters of a document number.
+func stripPunctuation(documentNumber string) string {
+	return strings.NewReplacer(".", "", "-", "", "/", "").Replace(documentNumber)
+}
+
+// allSame reports whether all characters are the same. Such numbers pass the check digit test but are not issued.
+func allSame(s string) bool {
+	return strings.Count(s, s[:1]) == len(s)
+}
+
+// checkDigitsMatch verifies the two trailing mod 11 check digits of s, computed with the given weights. Letters
+// are valued by their ASCII code minus 48, as specified for the alphanumeric CNPJ.
+func checkDigitsMatch(s string, firstWeights, secondWeights []int) bool {
+	return checkDigit(s, firstWeights) == int(s[len(firstWeights)]-'0') &&
+		checkDigit(s, secondWeights) == int(s[len(secondWeights)]-'0')
+}
+
+func checkDigit(s string, weights []int) int {
+	sum := 0
+	for i, weight := range weights {
+		sum += int(s[i]-'0') * weight
+	}
+	if remainder := sum % 11; remainder >= 2 {
+		return 11 - remainder
+	}
+	return 0
+}
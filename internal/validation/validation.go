@@ -0,0 +1,102 @@
+// Package validation validates the identity documents of account holders.
+// Each document type is checked by a DocumentValidator; the Brazilian CPF and CNPJ are registered by default
+// and other types can be added with Register.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrMalformed is wrapped by validators for documents that do not have the shape of their type at all, e.g. the
+// wrong number of digits, as opposed to documents of the right shape whose check digits do not match.
+var ErrMalformed = errors.New("malformed document")
+
+// FieldError describes why the value of a request field was rejected.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+// DocumentValidator validates the document numbers of one document type.
+type DocumentValidator interface {
+	// Type returns the name of the document type, e.g. "CPF".
+	Type() string
+	// Normalize returns the document number in canonical form, or an error describing why it is invalid.
+	// Errors for documents that do not have the shape of the type wrap ErrMalformed.
+	Normalize(documentNumber string) (string, error)
+}
+
+var (
+	mu         sync.RWMutex
+	validators = make(map[string]DocumentValidator)
+)
+
+func init() {
+	Register(CPF{})
+	Register(CNPJ{})
+}
+
+// Register adds a validator, replacing any validator registered for the same type.
+func Register(validator DocumentValidator) {
+	mu.Lock()
+	defer mu.Unlock()
+	validators[strings.ToUpper(validator.Type())] = validator
+}
+
+// Lookup returns the validator of a document type.
+func Lookup(documentType string) (DocumentValidator, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	validator, ok := validators[strings.ToUpper(documentType)]
+	return validator, ok
+}
+
+// Types returns the registered document types, sorted.
+func Types() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	types := make([]string, 0, len(validators))
+	for documentType := range validators {
+		types = append(types, documentType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// ValidateDocument checks documentNumber against the accepted document types, in order, and returns its
+// canonical form for the first type it is valid for. Otherwise the FieldError, on field, explains why: the
+// error of a type the document has the shape of, or else which types are accepted. Unknown types are skipped.
+func ValidateDocument(field, documentNumber string, types []string) (string, *FieldError) {
+	var names []string
+	var shapeErr error
+	for _, documentType := range types {
+		validator, ok := Lookup(documentType)
+		if !ok {
+			continue
+		}
+		normalized, err := validator.Normalize(documentNumber)
+		if err == nil {
+			return normalized, nil
+		}
+		if shapeErr == nil && !errors.Is(err, ErrMalformed) {
+			shapeErr = err
+		}
+		names = append(names, validator.Type())
+	}
+	switch {
+	case shapeErr != nil:
+		return "", &FieldError{Field: field, Message: shapeErr.Error()}
+	case len(names) == 0:
+		return "", &FieldError{Field: field, Message: "no supported document type"}
+	default:
+		return "", &FieldError{Field: field, Message: "must be a " + strings.Join(names, " or ")}
+	}
+}
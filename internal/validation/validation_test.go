@@ -0,0 +1,116 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPF_Normalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		document  string
+		want      string
+		malformed bool
+		wantErr   bool
+	}{
+		{"plain", "12345678909", "12345678909", false, false},
+		{"formatted", "123.456.789-09", "12345678909", false, false},
+		{"zero check digit", "52998224725", "52998224725", false, false},
+		{"wrong check digit", "12345678901", "", false, true},
+		{"same digits", "11111111111", "", false, true},
+		{"too short", "1234567890", "", true, true},
+		{"partly formatted", "123456789-09", "", true, true},
+		{"letters", "1234567890A", "", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CPF{}.Normalize(tt.document)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.malformed, errors.Is(err, ErrMalformed))
+		})
+	}
+}
+
+func TestCNPJ_Normalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		document  string
+		want      string
+		malformed bool
+		wantErr   bool
+	}{
+		{"plain", "11222333000181", "11222333000181", false, false},
+		{"formatted", "11.222.333/0001-81", "11222333000181", false, false},
+		{"alphanumeric", "12.ABC.345/01DE-35", "12ABC34501DE35", false, false},
+		{"lower case alphanumeric", "12abc34501de35", "12ABC34501DE35", false, false},
+		{"wrong check digit", "11222333000182", "", false, true},
+		{"same digits", "00000000000000", "", false, true},
+		{"letter check digit", "12ABC34501DE3A", "", true, true},
+		{"cpf", "12345678909", "", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CNPJ{}.Normalize(tt.document)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.malformed, errors.Is(err, ErrMalformed))
+		})
+	}
+}
+
+// passportValidator accepts passport numbers of two letters and seven digits.
+type passportValidator struct{}
+
+func (passportValidator) Type() string { return "PASSPORT" }
+
+func (passportValidator) Normalize(documentNumber string) (string, error) {
+	if len(documentNumber) != 9 {
+		return "", ErrMalformed
+	}
+	return documentNumber, nil
+}
+
+func TestValidateDocument(t *testing.T) {
+	brazilian := []string{"CPF", "CNPJ"}
+
+	normalized, fieldErr := ValidateDocument("document_number", "123.456.789-09", brazilian)
+	require.Nil(t, fieldErr)
+	assert.Equal(t, "12345678909", normalized)
+
+	normalized, fieldErr = ValidateDocument("document_number", "11.222.333/0001-81", brazilian)
+	require.Nil(t, fieldErr)
+	assert.Equal(t, "11222333000181", normalized)
+
+	// A document of the shape of one type reports why it is not valid for that type
+	_, fieldErr = ValidateDocument("document_number", "11222333000182", brazilian)
+	require.NotNil(t, fieldErr)
+	assert.Equal(t, "invalid document_number: CNPJ 11222333000182 has invalid check digits", fieldErr.Error())
+
+	_, fieldErr = ValidateDocument("document_number", "ABC", brazilian)
+	require.NotNil(t, fieldErr)
+	assert.Equal(t, "invalid document_number: must be a CPF or CNPJ", fieldErr.Error())
+
+	_, fieldErr = ValidateDocument("document_number", "12345678909", []string{"unknown"})
+	require.NotNil(t, fieldErr)
+	assert.Equal(t, "no supported document type", fieldErr.Message)
+
+	Register(passportValidator{})
+	assert.Equal(t, []string{"CNPJ", "CPF", "PASSPORT"}, Types())
+	normalized, fieldErr = ValidateDocument("document_number", "AB1234567", []string{"cpf", "passport"})
+	require.Nil(t, fieldErr)
+	assert.Equal(t, "AB1234567", normalized)
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CreateAccount opens an account. It is not retried after failures that may have left it open; a retry by the
+// caller is refused with a conflict naming the existing account, see IsConflict.
+func (c *Client) CreateAccount(ctx context.Context, req CreateAccountRequest) (*Account, error) {
+	var account Account
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/accounts", body: req}, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetAccount returns the account with the given ID.
+func (c *Client) GetAccount(ctx context.Context, id string) (*Account, error) {
+	var account Account
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/accounts/" + url.PathEscape(id), idempotent: true}, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetBalance returns the balance of the account with the given ID.
+func (c *Client) GetBalance(ctx context.Context, accountID string) (Cents, error) {
+	var balance struct {
+		Balance Cents `json:"balance"`
+	}
+	path := "/accounts/" + url.PathEscape(accountID) + "/balance"
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, idempotent: true}, &balance); err != nil {
+		return 0, err
+	}
+	return balance.Balance, nil
+}
+
+// Accounts iterates over the accounts matching opts, fetching them a page at a time. Iteration stops at the
+// first error, which is yielded with a nil account.
+func (c *Client) Accounts(ctx context.Context, opts ListAccountsOptions) iter.Seq2[*Account, error] {
+	return func(yield func(*Account, error) bool) {
+		query := url.Values{}
+		if opts.PageSize > 0 {
+			query.Set("limit", strconv.Itoa(opts.PageSize))
+		}
+		if len(opts.Tags) > 0 {
+			query.Set("tags", strings.Join(opts.Tags, ","))
+		}
+		if len(opts.ExcludeTags) > 0 {
+			query.Set("exclude_tags", strings.Join(opts.ExcludeTags, ","))
+		}
+
+		for offset := 0; ; {
+			query.Set("offset", strconv.Itoa(offset))
+			var page struct {
+				Accounts []*Account `json:"accounts"`
+				Total    int        `json:"total"`
+			}
+			if err := c.do(ctx, request{method: http.MethodGet, path: "/accounts", query: query, idempotent: true}, &page); err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, account := range page.Accounts {
+				if !yield(account, nil) {
+					return
+				}
+			}
+			offset += len(page.Accounts)
+			if len(page.Accounts) == 0 || offset >= page.Total {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Package client is the Go client of the Pismo services, for internal teams calling them from other services
+// and jobs. Client wraps the REST API of the gateway and GRPCClient the gRPC services, both with typed methods,
+// retries of the calls that are safe to repeat, idempotency keys for transactions, and iterators over paginated
+// and streamed results.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	"github.com/google/uuid"
+)
+
+// DefaultMaxRetries is how many times a failed call is retried unless the Config sets another count.
+const DefaultMaxRetries = 3
+
+// DefaultRetryBackoff is the wait before the first retry unless the Config sets another; it doubles on each retry.
+const DefaultRetryBackoff = 200 * time.Millisecond
+
+// maxRetryWait caps the wait before a retry, including one asked for with Retry-After.
+const maxRetryWait = 30 * time.Second
+
+// idempotencyKeyPrefix starts the idempotency keys generated by the client, so they are told apart from the
+// references of card networks in external_id.
+const idempotencyKeyPrefix = "sdk-"
+
+// Config configures a Client or GRPCClient.
+type Config struct {
+	// BaseURL is the address of the gateway, e.g. http://localhost:8083; only used by Client
+	BaseURL string
+	// HTTPClient sends the requests of Client; http.DefaultClient if nil
+	HTTPClient *http.Client
+	// CallerRole, OperatorID and TenantID are sent with every call, as the X-Caller-Role, X-Operator-ID and
+	// X-Tenant-ID headers or the matching gRPC metadata; empty ones are left out
+	CallerRole string
+	OperatorID string
+	TenantID   string
+	// TrafficClass is sent with every call, as the X-Traffic-Class header or the matching gRPC metadata, unless
+	// empty; set it to "batch" for bulk jobs so they leave priority to interactive traffic
+	TrafficClass string
+	// APIKey is sent in the X-API-Key header, which the gateway rate limits by; only used by Client
+	APIKey string
+	// MaxRetries is how many times a call is retried; DefaultMaxRetries if zero, none if negative
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubling on each retry; DefaultRetryBackoff if zero
+	RetryBackoff time.Duration
+}
+
+// retries returns how many times a call is retried.
+func (c Config) retries() int {
+	switch {
+	case c.MaxRetries < 0:
+		return 0
+	case c.MaxRetries == 0:
+		return DefaultMaxRetries
+	}
+	return c.MaxRetries
+}
+
+// backoff returns the wait before retry attempt, counted from 1.
+func (c Config) backoff(attempt int) time.Duration {
+	wait := c.RetryBackoff
+	if wait <= 0 {
+		wait = DefaultRetryBackoff
+	}
+	return min(wait<<(attempt-1), maxRetryWait)
+}
+
+// Error is a call the services refused or could not complete.
+type Error struct {
+	// StatusCode is the HTTP status of the response of the gateway; 0 for errors of the gRPC services
+	StatusCode int
+	// Message is the error reported by the service, e.g. insufficient balance
+	Message string
+	// RetryAfter is how long the gateway asked to wait before retrying, if it did
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsNotFound reports whether err is the gateway answering that what was asked for does not exist.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsConflict reports whether err is the gateway refusing a request that conflicts with the current state,
+// e.g. an external_id already used.
+func IsConflict(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+// retryable reports whether a call that failed with statusCode may be sent again. Rate limited calls and
+// unreachable backends were not processed, while a gateway timeout or failure may leave a call applied, so
+// those are only retried for idempotent calls.
+func retryable(statusCode int, idempotent bool) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout, http.StatusInternalServerError:
+		return idempotent
+	}
+	return false
+}
+
+// Client calls the REST API of the gateway. It is safe for concurrent use.
+type Client struct {
+	config  Config
+	baseURL string
+	http    *http.Client
+	// sleep waits between retries; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// New creates a client of the gateway at config.BaseURL.
+func New(config Config) *Client {
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		config:  config,
+		baseURL: strings.TrimSuffix(config.BaseURL, "/"),
+		http:    httpClient,
+		sleep:   sleepContext,
+	}
+}
+
+// NewIdempotencyKey returns a new key for CreateTransactionRequest.IdempotencyKey.
+func NewIdempotencyKey() string {
+	return idempotencyKeyPrefix + uuid.New().String()
+}
+
+// request is a call to the gateway.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	// idempotent calls can be retried after failures that may have left them applied
+	idempotent bool
+	// retried is called before each retry, if set
+	retried func()
+}
+
+// do sends req, retrying failures that allow it, and decodes the JSON response into out unless it is nil.
+// Every attempt carries the same request ID, so the logs of the services show the retries of a call together.
+func (c *Client) do(ctx context.Context, req request, out interface{}) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response of %s %s: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// send sends req, retrying failures that allow it, and returns the successful response, whose body the caller
+// closes.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, err
+		}
+	}
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+	requestID := common.NewRequestID()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err := c.sleep(ctx, c.config.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			if req.retried != nil {
+				req.retried()
+			}
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+		httpReq.Header.Set(common.RequestIDHeader, requestID)
+		for header, value := range map[string]string{
+			"X-Caller-Role":   c.config.CallerRole,
+			"X-Operator-ID":   c.config.OperatorID,
+			"X-Tenant-ID":     c.config.TenantID,
+			"X-Traffic-Class": c.config.TrafficClass,
+			"X-API-Key":       c.config.APIKey,
+		} {
+			if value != "" {
+				httpReq.Header.Set(header, value)
+			}
+		}
+
+		resp, err := c.http.Do(httpReq)
+		if err != nil {
+			// The request may have reached the gateway before the connection failed
+			if ctx.Err() != nil || !req.idempotent || attempt >= c.config.retries() {
+				return nil, err
+			}
+			continue
+		}
+		if resp.StatusCode < http.StatusBadRequest {
+			return resp, nil
+		}
+
+		apiErr := readError(resp)
+		if !retryable(resp.StatusCode, req.idempotent) || attempt >= c.config.retries() {
+			return nil, apiErr
+		}
+		if apiErr.RetryAfter > 0 {
+			if err := c.sleep(ctx, min(apiErr.RetryAfter, maxRetryWait)); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// readError reads the error of a failed response and closes its body. The gateway answers with the error as
+// plain text, or as a JSON object with an error field for rate limited calls and unreachable backends.
+func readError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") && json.Unmarshal(data, &payload) == nil && payload.Error != "" {
+		apiErr.Message = payload.Error
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
+
+// sleepContext waits for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
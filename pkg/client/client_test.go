@@ -0,0 +1,331 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedRequest is a request received by a test gateway.
+type recordedRequest struct {
+	method    string
+	path      string
+	query     string
+	requestID string
+	role      string
+	body      map[string]interface{}
+}
+
+// newTestClient starts a gateway answering with handle and returns a client of it, without waits between
+// retries, along with the requests received.
+func newTestClient(t *testing.T, handle func(w http.ResponseWriter, r *http.Request, attempt int)) (*Client, func() []recordedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded := recordedRequest{
+			method:    r.Method,
+			path:      r.URL.Path,
+			query:     r.URL.RawQuery,
+			requestID: r.Header.Get("X-Request-ID"),
+			role:      r.Header.Get("X-Caller-Role"),
+		}
+		json.NewDecoder(r.Body).Decode(&recorded.body)
+		mu.Lock()
+		requests = append(requests, recorded)
+		attempt := len(requests)
+		mu.Unlock()
+		handle(w, r, attempt)
+	}))
+	t.Cleanup(server.Close)
+
+	client := New(Config{BaseURL: server.URL + "/", CallerRole: "support"})
+	client.sleep = func(ctx context.Context, d time.Duration) error { return ctx.Err() }
+	return client, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedRequest(nil), requests...)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintln(w, body)
+}
+
+func TestClient_CreateTransaction(t *testing.T) {
+	tests := []struct {
+		name          string
+		handle        func(w http.ResponseWriter, r *http.Request, attempt int)
+		expectAmount  Cents
+		expectErr     string
+		expectPaths   []string
+		expectSameKey bool
+	}{
+		{
+			name: "created",
+			handle: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				writeJSON(w, http.StatusOK, `{"id":"tx-1","account_id":"acc-1","amount":-12.5,"external_id":"key-1"}`)
+			},
+			expectAmount: -1250,
+			expectPaths:  []string{"/transactions"},
+		},
+		{
+			name: "retried after the gateway timed out",
+			handle: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				if attempt == 1 {
+					http.Error(w, "Transaction service error: deadline exceeded", http.StatusInternalServerError)
+					return
+				}
+				writeJSON(w, http.StatusOK, `{"id":"tx-1","amount":-12.5}`)
+			},
+			expectAmount:  -1250,
+			expectPaths:   []string{"/transactions", "/transactions"},
+			expectSameKey: true,
+		},
+		{
+			name: "retry finds the transaction created by the first attempt",
+			handle: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				switch attempt {
+				case 1:
+					http.Error(w, "upstream timeout", http.StatusGatewayTimeout)
+				case 2:
+					http.Error(w, "external_id already used", http.StatusConflict)
+				default:
+					writeJSON(w, http.StatusOK, `{"id":"tx-1","amount":-12.5}`)
+				}
+			},
+			expectAmount:  -1250,
+			expectPaths:   []string{"/transactions", "/transactions", "/transactions"},
+			expectSameKey: true,
+		},
+		{
+			name: "key used before the call",
+			handle: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				http.Error(w, "external_id already used", http.StatusConflict)
+			},
+			expectErr:   "409 Conflict: external_id already used",
+			expectPaths: []string{"/transactions"},
+		},
+		{
+			name: "refused",
+			handle: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				http.Error(w, "insufficient balance", http.StatusBadRequest)
+			},
+			expectErr:   "400 Bad Request: insufficient balance",
+			expectPaths: []string{"/transactions"},
+		},
+		{
+			name: "gives up after the retries",
+			handle: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				w.Header().Set("Retry-After", "5")
+				writeJSON(w, http.StatusServiceUnavailable, `{"error":"Transaction service unavailable","code":"SERVICE_UNAVAILABLE"}`)
+			},
+			expectErr:     "503 Service Unavailable: Transaction service unavailable",
+			expectPaths:   []string{"/transactions", "/transactions", "/transactions", "/transactions"},
+			expectSameKey: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := newTestClient(t, tt.handle)
+
+			transaction, err := client.CreateTransaction(context.Background(), CreateTransactionRequest{
+				AccountID:     "acc-1",
+				OperationType: "PURCHASE",
+				Amount:        -1250,
+			})
+
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+				assert.Nil(t, transaction)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "tx-1", transaction.ID)
+				assert.Equal(t, tt.expectAmount, transaction.Amount)
+			}
+
+			received := requests()
+			var paths []string
+			for _, req := range received {
+				paths = append(paths, req.path)
+				if req.method == http.MethodPost {
+					assert.Equal(t, received[0].requestID, req.requestID)
+				}
+				assert.Equal(t, "support", req.role)
+			}
+			assert.Equal(t, tt.expectPaths, paths)
+
+			key, _ := received[0].body["external_id"].(string)
+			assert.Regexp(t, "^sdk-", key)
+			assert.Equal(t, -12.5, received[0].body["amount"])
+			if tt.expectSameKey {
+				assert.Equal(t, key, received[1].body["external_id"])
+			}
+			if len(received) == 3 && received[2].method == http.MethodGet {
+				assert.Equal(t, "external_id="+key, received[2].query)
+			}
+		})
+	}
+}
+
+func TestClient_ProcessPayment_NotRetriedAfterAmbiguousFailure(t *testing.T) {
+	client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	})
+
+	_, err := client.ProcessPayment(context.Background(), PaymentRequest{AccountID: "acc-1", Amount: 1000})
+
+	assert.EqualError(t, err, "502 Bad Gateway: bad gateway")
+	assert.Len(t, requests(), 1)
+}
+
+func TestClient_GetAccount(t *testing.T) {
+	client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		if attempt == 1 {
+			w.Header().Set("Retry-After", "1")
+			writeJSON(w, http.StatusTooManyRequests, `{"error":"rate limit exceeded","code":"RATE_LIMITED"}`)
+			return
+		}
+		if r.URL.Path != "/accounts/acc-1" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, `{"id":"acc-1","document_number":"12345678900","balance":100.05,"tags":["VIP"]}`)
+	})
+
+	account, err := client.GetAccount(context.Background(), "acc-1")
+	require.NoError(t, err)
+	assert.Equal(t, Cents(10005), account.Balance)
+	assert.Equal(t, []string{"VIP"}, account.Tags)
+	assert.Len(t, requests(), 2)
+
+	_, err = client.GetAccount(context.Background(), "missing")
+	assert.True(t, IsNotFound(err))
+}
+
+func TestClient_Accounts(t *testing.T) {
+	client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		var accounts []string
+		for i := offset; i < min(offset+2, 5); i++ {
+			accounts = append(accounts, fmt.Sprintf(`{"id":"acc-%d"}`, i))
+		}
+		writeJSON(w, http.StatusOK, fmt.Sprintf(`{"accounts":[%s],"total":5}`, strings.Join(accounts, ",")))
+	})
+
+	var ids []string
+	for account, err := range client.Accounts(context.Background(), ListAccountsOptions{PageSize: 2, Tags: []string{"VIP", "PAYROLL"}}) {
+		require.NoError(t, err)
+		ids = append(ids, account.ID)
+	}
+
+	assert.Equal(t, []string{"acc-0", "acc-1", "acc-2", "acc-3", "acc-4"}, ids)
+	received := requests()
+	require.Len(t, received, 3)
+	assert.Equal(t, "limit=2&offset=4&tags=VIP%2CPAYROLL", received[2].query)
+}
+
+func TestClient_Transactions(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"transactions":[{"id":"tx-3"},{"id":"tx-2"}],"total":3,"next_page_token":"p2"}`,
+		"p2": `{"transactions":[{"id":"tx-1"}],"total":3,"next_page_token":""}`,
+	}
+	client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request, attempt int) {
+		writeJSON(w, http.StatusOK, pages[r.URL.Query().Get("page_token")])
+	})
+
+	var ids []string
+	for transaction, err := range client.Transactions(context.Background(), "acc-1", TransactionsOptions{PageSize: 2}) {
+		require.NoError(t, err)
+		ids = append(ids, transaction.ID)
+	}
+
+	assert.Equal(t, []string{"tx-3", "tx-2", "tx-1"}, ids)
+	received := requests()
+	require.Len(t, received, 2)
+	assert.Equal(t, "/accounts/acc-1/transactions", received[1].path)
+	assert.Equal(t, "limit=2&page_token=p2", received[1].query)
+
+	// Stopping early fetches no further pages
+	for range client.Transactions(context.Background(), "acc-1", TransactionsOptions{}) {
+		break
+	}
+	assert.Len(t, requests(), 3)
+}
+
+func TestClient_StreamTransactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		handle    func(w http.ResponseWriter, r *http.Request, attempt int)
+		expectIDs []string
+		expectErr string
+	}{
+		{
+			name: "complete stream",
+			handle: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				fmt.Fprintln(w, `{"id":"tx-1","amount":10}`)
+				fmt.Fprintln(w, `{"id":"tx-2","amount":-2.5}`)
+			},
+			expectIDs: []string{"tx-1", "tx-2"},
+		},
+		{
+			name: "invalid request",
+			handle: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				http.Error(w, "invalid status", http.StatusBadRequest)
+			},
+			expectErr: "400 Bad Request: invalid status",
+		},
+		{
+			name: "truncated stream",
+			handle: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				fmt.Fprintln(w, `{"id":"tx-1","amount":10}`)
+				fmt.Fprint(w, `{"id":"tx-2",`)
+			},
+			expectIDs: []string{"tx-1"},
+			expectErr: "decoding streamed transaction",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := newTestClient(t, tt.handle)
+			from := time.Unix(1700000000, 0)
+
+			var ids []string
+			var streamErr error
+			for transaction, err := range client.StreamTransactions(context.Background(), "acc-1", StreamOptions{From: from, Status: "COMPLETED"}) {
+				if err != nil {
+					streamErr = err
+					continue
+				}
+				ids = append(ids, transaction.ID)
+			}
+
+			assert.Equal(t, tt.expectIDs, ids)
+			if tt.expectErr != "" {
+				assert.ErrorContains(t, streamErr, tt.expectErr)
+			} else {
+				assert.NoError(t, streamErr)
+			}
+			received := requests()
+			require.Len(t, received, 1)
+			assert.Equal(t, "/accounts/acc-1/transactions/stream", received[0].path)
+			assert.Equal(t, "from=1700000000&status=COMPLETED", received[0].query)
+		})
+	}
+}
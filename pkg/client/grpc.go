@@ -0,0 +1,276 @@
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pbAccount "github.com/YASHIRAI/pismo-task/proto/account"
+	pbTransaction "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCClient calls the account and transaction services directly over gRPC, for services running next to
+// them. It returns the messages of the services, and their errors as *Error with a zero StatusCode. It is safe
+// for concurrent use.
+type GRPCClient struct {
+	config      Config
+	account     pbAccount.AccountServiceClient
+	transaction pbTransaction.TransactionServiceClient
+	// sleep waits between retries; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewGRPCClient creates a client of the services behind accountConn and transactionConn.
+func NewGRPCClient(accountConn, transactionConn grpc.ClientConnInterface, config Config) *GRPCClient {
+	return &GRPCClient{
+		config:      config,
+		account:     pbAccount.NewAccountServiceClient(accountConn),
+		transaction: pbTransaction.NewTransactionServiceClient(transactionConn),
+		sleep:       sleepContext,
+	}
+}
+
+// retryableCode reports whether a call that failed with code may be sent again. Rate limited calls were
+// refused before being processed, while an unavailable service may have processed the call before the
+// connection broke, so those are only retried for idempotent calls.
+func retryableCode(code codes.Code, idempotent bool) bool {
+	switch code {
+	case codes.ResourceExhausted:
+		return true
+	case codes.Unavailable:
+		return idempotent
+	}
+	return false
+}
+
+// outgoingContext adds the caller identity of the config and a request ID to ctx.
+func (c *GRPCClient) outgoingContext(ctx context.Context) context.Context {
+	pairs := []string{common.RequestIDMetadataKey, common.NewRequestID()}
+	for key, value := range map[string]string{
+		common.CallerRoleMetadataKey:   c.config.CallerRole,
+		common.OperatorIDMetadataKey:   c.config.OperatorID,
+		common.TenantIDMetadataKey:     c.config.TenantID,
+		common.TrafficClassMetadataKey: c.config.TrafficClass,
+	} {
+		if value != "" {
+			pairs = append(pairs, key, value)
+		}
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// invoke runs call, retrying the failures that allow it, and returns the error reported in the response, if
+// any, as *Error. Every attempt carries the same request ID.
+func invoke[T interface{ GetError() string }](ctx context.Context, c *GRPCClient, idempotent bool, call func(ctx context.Context) (T, error)) (T, error) {
+	ctx = c.outgoingContext(ctx)
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err := c.sleep(ctx, c.config.backoff(attempt)); err != nil {
+				var zero T
+				return zero, err
+			}
+		}
+		resp, err := call(ctx)
+		if err != nil {
+			if ctx.Err() == nil && retryableCode(status.Code(err), idempotent) && attempt < c.config.retries() {
+				continue
+			}
+			return resp, err
+		}
+		if resp.GetError() != "" {
+			return resp, &Error{Message: resp.GetError()}
+		}
+		return resp, nil
+	}
+}
+
+// CreateAccount opens an account.
+func (c *GRPCClient) CreateAccount(ctx context.Context, req *pbAccount.CreateAccountRequest) (*pbAccount.Account, error) {
+	resp, err := invoke(ctx, c, false, func(ctx context.Context) (*pbAccount.CreateAccountResponse, error) {
+		return c.account.CreateAccount(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Account, nil
+}
+
+// GetAccount returns the account with the given ID.
+func (c *GRPCClient) GetAccount(ctx context.Context, id string) (*pbAccount.Account, error) {
+	resp, err := invoke(ctx, c, true, func(ctx context.Context) (*pbAccount.GetAccountResponse, error) {
+		return c.account.GetAccount(ctx, &pbAccount.GetAccountRequest{Id: id})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Account, nil
+}
+
+// GetBalance returns the balance of the account with the given ID.
+func (c *GRPCClient) GetBalance(ctx context.Context, accountID string) (Cents, error) {
+	resp, err := invoke(ctx, c, true, func(ctx context.Context) (*pbAccount.GetBalanceResponse, error) {
+		return c.account.GetBalance(ctx, &pbAccount.GetBalanceRequest{AccountId: accountID})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return Cents(resp.BalanceCents), nil
+}
+
+// CreateTransaction creates a transaction, with the same idempotency as Client.CreateTransaction: the external
+// ID of req is the idempotency key, generated when empty, and a retry refused because it is already used returns
+// the transaction created by the earlier attempt.
+func (c *GRPCClient) CreateTransaction(ctx context.Context, req *pbTransaction.CreateTransactionRequest) (*pbTransaction.Transaction, error) {
+	if req.ExternalId == "" && !req.Simulate {
+		req.ExternalId = NewIdempotencyKey()
+	}
+
+	attempts := 0
+	resp, err := invoke(ctx, c, true, func(ctx context.Context) (*pbTransaction.CreateTransactionResponse, error) {
+		attempts++
+		return c.transaction.CreateTransaction(ctx, req)
+	})
+	if attempts > 1 && resp.GetError() == errExternalIDAlreadyUsed {
+		return c.GetTransactionByExternalID(ctx, req.ExternalId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Transaction, nil
+}
+
+// GetTransaction returns the transaction with the given ID.
+func (c *GRPCClient) GetTransaction(ctx context.Context, id string) (*pbTransaction.Transaction, error) {
+	return c.getTransaction(ctx, &pbTransaction.GetTransactionRequest{Id: id})
+}
+
+// GetTransactionByExternalID returns the transaction with the given external ID.
+func (c *GRPCClient) GetTransactionByExternalID(ctx context.Context, externalID string) (*pbTransaction.Transaction, error) {
+	return c.getTransaction(ctx, &pbTransaction.GetTransactionRequest{ExternalId: externalID})
+}
+
+func (c *GRPCClient) getTransaction(ctx context.Context, req *pbTransaction.GetTransactionRequest) (*pbTransaction.Transaction, error) {
+	resp, err := invoke(ctx, c, true, func(ctx context.Context) (*pbTransaction.GetTransactionResponse, error) {
+		return c.transaction.GetTransaction(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Transaction, nil
+}
+
+// ProcessPayment processes a payment to an account; only failures that left it unprocessed are retried.
+func (c *GRPCClient) ProcessPayment(ctx context.Context, req *pbTransaction.ProcessPaymentRequest) (*pbTransaction.Transaction, error) {
+	resp, err := invoke(ctx, c, false, func(ctx context.Context) (*pbTransaction.ProcessPaymentResponse, error) {
+		return c.transaction.ProcessPayment(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Transaction, nil
+}
+
+// Transfer moves an amount between two accounts; only failures that left it undone are retried.
+func (c *GRPCClient) Transfer(ctx context.Context, req *pbTransaction.TransferRequest) (*pbTransaction.TransferResponse, error) {
+	return invoke(ctx, c, false, func(ctx context.Context) (*pbTransaction.TransferResponse, error) {
+		return c.transaction.Transfer(ctx, req)
+	})
+}
+
+// ReverseTransaction reverses a completed transaction; a retry after the reversal went through fails with
+// "transaction already reversed".
+func (c *GRPCClient) ReverseTransaction(ctx context.Context, id, reason string) (*pbTransaction.ReverseTransactionResponse, error) {
+	return invoke(ctx, c, true, func(ctx context.Context) (*pbTransaction.ReverseTransactionResponse, error) {
+		return c.transaction.ReverseTransaction(ctx, &pbTransaction.ReverseTransactionRequest{Id: id, Reason: reason})
+	})
+}
+
+// Transactions iterates over the transactions of an account, newest first, fetching them a page at a time.
+// Iteration stops at the first error, which is yielded with a nil transaction.
+func (c *GRPCClient) Transactions(ctx context.Context, accountID string, opts TransactionsOptions) iter.Seq2[*pbTransaction.Transaction, error] {
+	return func(yield func(*pbTransaction.Transaction, error) bool) {
+		req := &pbTransaction.GetTransactionHistoryRequest{
+			AccountId: accountID,
+			Limit:     int32(opts.PageSize),
+			Search:    opts.Search,
+		}
+		for {
+			resp, err := invoke(ctx, c, true, func(ctx context.Context) (*pbTransaction.GetTransactionHistoryResponse, error) {
+				return c.transaction.GetTransactionHistory(ctx, req)
+			})
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, transaction := range resp.Transactions {
+				if !yield(transaction, nil) {
+					return
+				}
+			}
+			if resp.NextPageToken == "" {
+				return
+			}
+			req.PageToken = resp.NextPageToken
+		}
+	}
+}
+
+// StreamTransactions iterates over all transactions of an account matching opts, oldest first, as the service
+// streams them. Only opening the stream is retried; an error part way through ends the iteration.
+func (c *GRPCClient) StreamTransactions(ctx context.Context, accountID string, opts StreamOptions) iter.Seq2[*pbTransaction.Transaction, error] {
+	return func(yield func(*pbTransaction.Transaction, error) bool) {
+		req := &pbTransaction.StreamTransactionsRequest{
+			AccountId:     accountID,
+			Status:        opts.Status,
+			OperationType: opts.OperationType,
+		}
+		if !opts.From.IsZero() {
+			req.From = opts.From.Unix()
+		}
+		if !opts.To.IsZero() {
+			req.To = opts.To.Unix()
+		}
+
+		ctx, cancel := context.WithCancel(c.outgoingContext(ctx))
+		defer cancel()
+		var stream grpc.ServerStreamingClient[pbTransaction.Transaction]
+		var transaction *pbTransaction.Transaction
+		for attempt := 0; ; attempt++ {
+			if attempt > 0 {
+				if err := c.sleep(ctx, c.config.backoff(attempt)); err != nil {
+					yield(nil, err)
+					return
+				}
+			}
+			var err error
+			if stream, err = c.transaction.StreamTransactions(ctx, req); err == nil {
+				// Errors opening the stream arrive with the first message
+				transaction, err = stream.Recv()
+			}
+			if err == nil || err == io.EOF {
+				break
+			}
+			if ctx.Err() != nil || !retryableCode(status.Code(err), true) || attempt >= c.config.retries() {
+				yield(nil, err)
+				return
+			}
+		}
+
+		for transaction != nil {
+			if !yield(transaction, nil) {
+				return
+			}
+			var err error
+			if transaction, err = stream.Recv(); err != nil && err != io.EOF {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+	pbTransaction "github.com/YASHIRAI/pismo-task/proto/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// createResult is the scripted result of one CreateTransaction call.
+type createResult func(req *pbTransaction.CreateTransactionRequest) (*pbTransaction.CreateTransactionResponse, error)
+
+// fakeTransactionClient answers the calls of a GRPCClient from scripted results.
+type fakeTransactionClient struct {
+	pbTransaction.TransactionServiceClient
+	createResults []createResult
+	creates       []*pbTransaction.CreateTransactionRequest
+	requestIDs    []string
+	gets          []*pbTransaction.GetTransactionRequest
+	history       map[string]*pbTransaction.GetTransactionHistoryResponse
+	streamErrs    []error
+	streamed      []*pbTransaction.Transaction
+}
+
+func (f *fakeTransactionClient) CreateTransaction(ctx context.Context, req *pbTransaction.CreateTransactionRequest, opts ...grpc.CallOption) (*pbTransaction.CreateTransactionResponse, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	f.requestIDs = append(f.requestIDs, md.Get(common.RequestIDMetadataKey)...)
+	f.creates = append(f.creates, req)
+	result := f.createResults[len(f.creates)-1]
+	return result(req)
+}
+
+func (f *fakeTransactionClient) GetTransaction(ctx context.Context, req *pbTransaction.GetTransactionRequest, opts ...grpc.CallOption) (*pbTransaction.GetTransactionResponse, error) {
+	f.gets = append(f.gets, req)
+	return &pbTransaction.GetTransactionResponse{Transaction: &pbTransaction.Transaction{Id: "tx-1", ExternalId: req.ExternalId}}, nil
+}
+
+func (f *fakeTransactionClient) GetTransactionHistory(ctx context.Context, req *pbTransaction.GetTransactionHistoryRequest, opts ...grpc.CallOption) (*pbTransaction.GetTransactionHistoryResponse, error) {
+	return f.history[req.PageToken], nil
+}
+
+func (f *fakeTransactionClient) StreamTransactions(ctx context.Context, req *pbTransaction.StreamTransactionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pbTransaction.Transaction], error) {
+	var err error
+	if len(f.streamErrs) > 0 {
+		err, f.streamErrs = f.streamErrs[0], f.streamErrs[1:]
+	}
+	return &fakeTransactionStream{transactions: f.streamed, err: err}, nil
+}
+
+// fakeTransactionStream returns transactions, then err or io.EOF.
+type fakeTransactionStream struct {
+	grpc.ClientStream
+	transactions []*pbTransaction.Transaction
+	err          error
+}
+
+func (s *fakeTransactionStream) Recv() (*pbTransaction.Transaction, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(s.transactions) == 0 {
+		return nil, io.EOF
+	}
+	transaction := s.transactions[0]
+	s.transactions = s.transactions[1:]
+	return transaction, nil
+}
+
+func newTestGRPCClient(transactions *fakeTransactionClient) *GRPCClient {
+	client := NewGRPCClient(nil, nil, Config{MaxRetries: 2})
+	client.transaction = transactions
+	client.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return client
+}
+
+func TestGRPCClient_CreateTransaction(t *testing.T) {
+	var created createResult = func(req *pbTransaction.CreateTransactionRequest) (*pbTransaction.CreateTransactionResponse, error) {
+		return &pbTransaction.CreateTransactionResponse{Transaction: &pbTransaction.Transaction{Id: "tx-1", ExternalId: req.ExternalId}}, nil
+	}
+	var unavailable createResult = func(req *pbTransaction.CreateTransactionRequest) (*pbTransaction.CreateTransactionResponse, error) {
+		return nil, status.Error(codes.Unavailable, "connection reset")
+	}
+	var alreadyUsed createResult = func(req *pbTransaction.CreateTransactionRequest) (*pbTransaction.CreateTransactionResponse, error) {
+		return &pbTransaction.CreateTransactionResponse{Error: "external_id already used"}, nil
+	}
+
+	tests := []struct {
+		name          string
+		results       []createResult
+		expectErr     string
+		expectCode    codes.Code
+		expectCreates int
+		expectLookup  bool
+	}{
+		{name: "created", results: []createResult{created}, expectCreates: 1},
+		{name: "retried while unavailable", results: []createResult{unavailable, created}, expectCreates: 2},
+		{name: "retry finds the transaction", results: []createResult{unavailable, alreadyUsed}, expectCreates: 2, expectLookup: true},
+		{name: "key used before the call", results: []createResult{alreadyUsed}, expectErr: "external_id already used", expectCreates: 1},
+		{name: "gives up after the retries", results: []createResult{unavailable, unavailable, unavailable}, expectCode: codes.Unavailable, expectCreates: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions := &fakeTransactionClient{createResults: tt.results}
+			client := newTestGRPCClient(transactions)
+
+			transaction, err := client.CreateTransaction(context.Background(), &pbTransaction.CreateTransactionRequest{
+				AccountId:     "acc-1",
+				OperationType: "PURCHASE",
+				AmountCents:   -1250,
+			})
+
+			switch {
+			case tt.expectErr != "":
+				assert.EqualError(t, err, tt.expectErr)
+			case tt.expectCode != codes.OK:
+				assert.Equal(t, tt.expectCode, status.Code(err))
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, "tx-1", transaction.Id)
+			}
+
+			require.Len(t, transactions.creates, tt.expectCreates)
+			key := transactions.creates[0].ExternalId
+			assert.Regexp(t, "^sdk-", key)
+			for i := range transactions.creates {
+				assert.Equal(t, key, transactions.creates[i].ExternalId)
+				assert.Equal(t, transactions.requestIDs[0], transactions.requestIDs[i])
+			}
+			if tt.expectLookup {
+				require.Len(t, transactions.gets, 1)
+				assert.Equal(t, key, transactions.gets[0].ExternalId)
+			} else {
+				assert.Empty(t, transactions.gets)
+			}
+		})
+	}
+}
+
+func TestGRPCClient_Transactions(t *testing.T) {
+	client := newTestGRPCClient(&fakeTransactionClient{history: map[string]*pbTransaction.GetTransactionHistoryResponse{
+		"":   {Transactions: []*pbTransaction.Transaction{{Id: "tx-3"}, {Id: "tx-2"}}, NextPageToken: "p2"},
+		"p2": {Transactions: []*pbTransaction.Transaction{{Id: "tx-1"}}},
+	}})
+
+	var ids []string
+	for transaction, err := range client.Transactions(context.Background(), "acc-1", TransactionsOptions{PageSize: 2}) {
+		require.NoError(t, err)
+		ids = append(ids, transaction.Id)
+	}
+
+	assert.Equal(t, []string{"tx-3", "tx-2", "tx-1"}, ids)
+}
+
+func TestGRPCClient_StreamTransactions(t *testing.T) {
+	transactions := &fakeTransactionClient{
+		streamErrs: []error{status.Error(codes.ResourceExhausted, "rate limit exceeded")},
+		streamed:   []*pbTransaction.Transaction{{Id: "tx-1"}, {Id: "tx-2"}},
+	}
+	client := newTestGRPCClient(transactions)
+
+	var ids []string
+	for transaction, err := range client.StreamTransactions(context.Background(), "acc-1", StreamOptions{}) {
+		require.NoError(t, err)
+		ids = append(ids, transaction.Id)
+	}
+	assert.Equal(t, []string{"tx-1", "tx-2"}, ids)
+
+	transactions.streamErrs = []error{status.Error(codes.InvalidArgument, "invalid status")}
+	var streamErr error
+	for _, err := range client.StreamTransactions(context.Background(), "acc-1", StreamOptions{Status: "BOGUS"}) {
+		streamErr = err
+	}
+	assert.Equal(t, codes.InvalidArgument, status.Code(streamErr))
+}
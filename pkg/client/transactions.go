@@ -0,0 +1,181 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// errExternalIDAlreadyUsed is the conflict the transaction service answers a repeated idempotency key with.
+const errExternalIDAlreadyUsed = "external_id already used"
+
+// maxStreamLine bounds a line of a transaction stream.
+const maxStreamLine = 1 << 20
+
+// CreateTransaction creates a transaction. Failures that may have left it created are retried with the same
+// idempotency key, so the transaction is created at most once: a retry refused because the key is already used
+// returns the transaction created by the earlier attempt. A key used by another transaction before the call is
+// still reported as a conflict, see IsConflict.
+func (c *Client) CreateTransaction(ctx context.Context, req CreateTransactionRequest) (*Transaction, error) {
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = NewIdempotencyKey()
+	}
+
+	var transaction Transaction
+	retried := false
+	call := request{
+		method:     http.MethodPost,
+		path:       "/transactions",
+		body:       req,
+		idempotent: true,
+		retried:    func() { retried = true },
+	}
+	err := c.do(ctx, call, &transaction)
+	if apiErr, ok := err.(*Error); ok && retried && apiErr.StatusCode == http.StatusConflict && apiErr.Message == errExternalIDAlreadyUsed {
+		return c.GetTransactionByExternalID(ctx, req.IdempotencyKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// GetTransaction returns the transaction with the given ID.
+func (c *Client) GetTransaction(ctx context.Context, id string) (*Transaction, error) {
+	var transaction Transaction
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/transactions/" + url.PathEscape(id), idempotent: true}, &transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// GetTransactionByExternalID returns the transaction with the given external_id, e.g. the idempotency key it
+// was created with.
+func (c *Client) GetTransactionByExternalID(ctx context.Context, externalID string) (*Transaction, error) {
+	var transaction Transaction
+	query := url.Values{"external_id": {externalID}}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/transactions", query: query, idempotent: true}, &transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// ProcessPayment processes a payment to an account. Payments carry no idempotency key, so only failures that
+// left the payment unprocessed are retried.
+func (c *Client) ProcessPayment(ctx context.Context, req PaymentRequest) (*Transaction, error) {
+	var transaction Transaction
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/payments", body: req}, &transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// Transfer moves an amount between two accounts. Only failures that left the transfer undone are retried.
+func (c *Client) Transfer(ctx context.Context, req TransferRequest) (*Transfer, error) {
+	var transfer Transfer
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/transfers", body: req}, &transfer); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// ReverseTransaction reverses a completed transaction. A transaction is reversed at most once, so failures are
+// retried; a retry after the reversal went through is refused with a conflict, see IsConflict.
+func (c *Client) ReverseTransaction(ctx context.Context, id, reason string) (*Reversal, error) {
+	var reversal Reversal
+	call := request{
+		method:     http.MethodPost,
+		path:       "/transactions/" + url.PathEscape(id) + "/reverse",
+		body:       map[string]string{"reason": reason},
+		idempotent: true,
+	}
+	if err := c.do(ctx, call, &reversal); err != nil {
+		return nil, err
+	}
+	return &reversal, nil
+}
+
+// Transactions iterates over the transactions of an account, newest first, fetching them a page at a time.
+// Iteration stops at the first error, which is yielded with a nil transaction.
+func (c *Client) Transactions(ctx context.Context, accountID string, opts TransactionsOptions) iter.Seq2[*Transaction, error] {
+	return func(yield func(*Transaction, error) bool) {
+		query := url.Values{}
+		if opts.PageSize > 0 {
+			query.Set("limit", strconv.Itoa(opts.PageSize))
+		}
+		if opts.Search != "" {
+			query.Set("search", opts.Search)
+		}
+		path := "/accounts/" + url.PathEscape(accountID) + "/transactions"
+
+		for {
+			var page struct {
+				Transactions  []*Transaction `json:"transactions"`
+				NextPageToken string         `json:"next_page_token"`
+			}
+			if err := c.do(ctx, request{method: http.MethodGet, path: path, query: query, idempotent: true}, &page); err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, transaction := range page.Transactions {
+				if !yield(transaction, nil) {
+					return
+				}
+			}
+			if page.NextPageToken == "" {
+				return
+			}
+			query.Set("page_token", page.NextPageToken)
+		}
+	}
+}
+
+// StreamTransactions iterates over all transactions of an account matching opts, oldest first, as the gateway
+// streams them. Only the start of the stream is retried: an error part way through, including the gateway
+// aborting the download, ends the iteration, and the transactions yielded so far are then incomplete.
+func (c *Client) StreamTransactions(ctx context.Context, accountID string, opts StreamOptions) iter.Seq2[*Transaction, error] {
+	return func(yield func(*Transaction, error) bool) {
+		query := url.Values{}
+		if !opts.From.IsZero() {
+			query.Set("from", strconv.FormatInt(opts.From.Unix(), 10))
+		}
+		if !opts.To.IsZero() {
+			query.Set("to", strconv.FormatInt(opts.To.Unix(), 10))
+		}
+		if opts.Status != "" {
+			query.Set("status", opts.Status)
+		}
+		if opts.OperationType != "" {
+			query.Set("operation_type", opts.OperationType)
+		}
+		path := "/accounts/" + url.PathEscape(accountID) + "/transactions/stream"
+
+		resp, err := c.send(ctx, request{method: http.MethodGet, path: path, query: query, idempotent: true})
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64<<10), maxStreamLine)
+		for scanner.Scan() {
+			var transaction Transaction
+			if err := json.Unmarshal(scanner.Bytes(), &transaction); err != nil {
+				yield(nil, fmt.Errorf("decoding streamed transaction: %w", err))
+				return
+			}
+			if !yield(&transaction, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("reading transaction stream: %w", err))
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"time"
+
+	"github.com/YASHIRAI/pismo-task/internal/common"
+)
+
+// Cents is an amount of money in hundredths of the currency unit. In JSON it is a decimal such as 12.50, as the
+// gateway exchanges amounts.
+type Cents = common.Cents
+
+// ParseCents parses a decimal amount such as "12.5"; amounts with more than two decimals are rejected.
+func ParseCents(s string) (Cents, error) {
+	return common.ParseCents(s)
+}
+
+// Account is an account as returned by the gateway.
+type Account struct {
+	ID             string `json:"id"`
+	DocumentNumber string `json:"document_number"`
+	AccountType    string `json:"account_type"`
+	Balance        Cents  `json:"balance"`
+	// CreatedAt and UpdatedAt are Unix seconds
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+	// Status is the onboarding state: DRAFT, PENDING_KYC or ACTIVE
+	Status         string   `json:"status"`
+	HolderName     string   `json:"holder_name"`
+	HolderEmail    string   `json:"holder_email"`
+	HolderPhone    string   `json:"holder_phone"`
+	KYCReference   string   `json:"kyc_reference"`
+	Version        int64    `json:"version"`
+	OverdraftLimit Cents    `json:"overdraft_limit"`
+	Tags           []string `json:"tags"`
+}
+
+// Transaction is a transaction as returned by the gateway.
+type Transaction struct {
+	ID            string `json:"id"`
+	AccountID     string `json:"account_id"`
+	OperationType string `json:"operation_type"`
+	Amount        Cents  `json:"amount"`
+	Description   string `json:"description"`
+	// CreatedAt is Unix seconds
+	CreatedAt  int64             `json:"created_at"`
+	Status     string            `json:"status"`
+	ExternalID string            `json:"external_id"`
+	Tags       []string          `json:"tags"`
+	Metadata   map[string]string `json:"metadata"`
+	// TransferID is set on both transactions of a transfer
+	TransferID string `json:"transfer_id"`
+	// OriginalTransactionID is set on a REVERSAL to the transaction it reverses
+	OriginalTransactionID string `json:"original_transaction_id"`
+	// Balance is what remains of a completed transaction after payments; only returned for single transactions
+	Balance   Cents `json:"balance"`
+	Overdrawn bool  `json:"overdrawn"`
+	// InstallmentPlan is set on single installment purchases paid in installments, and on the debits of their
+	// later installments
+	InstallmentPlan *InstallmentPlan `json:"installment_plan,omitempty"`
+}
+
+// InstallmentPlan is the installments of an installment purchase; the first is the purchase's own debit.
+type InstallmentPlan struct {
+	PurchaseID       string        `json:"purchase_id"`
+	InstallmentCount int32         `json:"installment_count"`
+	TotalAmount      Cents         `json:"total_amount"`
+	PaidCount        int32         `json:"paid_count"`
+	Remaining        Cents         `json:"remaining"`
+	Installments     []Installment `json:"installments"`
+}
+
+// Installment is one installment of an installment purchase.
+type Installment struct {
+	Number int32 `json:"number"`
+	Amount Cents `json:"amount"`
+	// DueAt and PaidAt are Unix seconds
+	DueAt int64 `json:"due_at"`
+	// Status is PENDING, PAID, OVERDUE or CANCELLED
+	Status        string `json:"status"`
+	TransactionID string `json:"transaction_id,omitempty"`
+	PaidAt        int64  `json:"paid_at,omitempty"`
+	Attempts      int32  `json:"attempts,omitempty"`
+	LastError     string `json:"last_error,omitempty"`
+}
+
+// CreateAccountRequest is an account to open.
+type CreateAccountRequest struct {
+	DocumentNumber string `json:"document_number"`
+	// AccountType is CHECKING, SAVINGS or CREDIT
+	AccountType    string `json:"account_type"`
+	InitialBalance Cents  `json:"initial_balance,omitempty"`
+	// Draft opens the account in DRAFT state, to be onboarded before it transacts
+	Draft       bool   `json:"draft,omitempty"`
+	HolderName  string `json:"holder_name,omitempty"`
+	HolderEmail string `json:"holder_email,omitempty"`
+	HolderPhone string `json:"holder_phone,omitempty"`
+}
+
+// CreateTransactionRequest is a transaction to create.
+type CreateTransactionRequest struct {
+	AccountID     string `json:"account_id"`
+	OperationType string `json:"operation_type"`
+	Amount        Cents  `json:"amount"`
+	Description   string `json:"description,omitempty"`
+	// Category is the spending category counted against the budgets of the account
+	Category string `json:"category,omitempty"`
+	// IdempotencyKey identifies the transaction across retries and is stored as its external_id, at most 64
+	// characters. A new key is generated when empty; set one to make retries of the whole call, e.g. by a job
+	// that restarts, idempotent as well.
+	IdempotencyKey string `json:"external_id,omitempty"`
+	// InstallmentCount splits an INSTALLMENT_PURCHASE into monthly installments; only the first is debited now
+	InstallmentCount int32 `json:"installment_count,omitempty"`
+}
+
+// PaymentRequest is a payment to process.
+type PaymentRequest struct {
+	AccountID   string `json:"account_id"`
+	Amount      Cents  `json:"amount"`
+	Description string `json:"description,omitempty"`
+	// Currency is the ISO 4217 currency of Amount; empty means the currency of the account
+	Currency string `json:"currency,omitempty"`
+}
+
+// TransferRequest moves an amount between two accounts.
+type TransferRequest struct {
+	SourceAccountID      string `json:"source_account_id"`
+	DestinationAccountID string `json:"destination_account_id"`
+	Amount               Cents  `json:"amount"`
+	Description          string `json:"description,omitempty"`
+}
+
+// Transfer is a completed transfer.
+type Transfer struct {
+	TransferID string `json:"transfer_id"`
+	// Debit is the TRANSFER_OUT transaction of the source account
+	Debit *Transaction `json:"debit"`
+	// Credit is the TRANSFER_IN transaction of the destination account
+	Credit *Transaction `json:"credit"`
+}
+
+// Reversal is a reversed transaction.
+type Reversal struct {
+	// Original is the reversed transaction, now REVERSED
+	Original *Transaction `json:"original"`
+	// Reversal is the REVERSAL transaction compensating it
+	Reversal *Transaction `json:"reversal"`
+}
+
+// ListAccountsOptions filters the accounts listed by Accounts.
+type ListAccountsOptions struct {
+	// PageSize is the number of accounts fetched per request; the default of the service if zero
+	PageSize int
+	// Tags and ExcludeTags keep the accounts having all of Tags and none of ExcludeTags
+	Tags        []string
+	ExcludeTags []string
+}
+
+// TransactionsOptions filters the transactions listed by Transactions.
+type TransactionsOptions struct {
+	// PageSize is the number of transactions fetched per request; the default of the service if zero
+	PageSize int
+	// Search keeps the transactions whose description contains it, ignoring case; at least 3 characters
+	Search string
+}
+
+// StreamOptions filters the transactions of StreamTransactions.
+type StreamOptions struct {
+	// From and To bound the creation time, From inclusive and To exclusive; zero leaves that end open
+	From time.Time
+	To   time.Time
+	// Status and OperationType keep the transactions having them, when set
+	Status        string
+	OperationType string
+}
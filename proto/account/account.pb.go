@@ -28,11 +28,28 @@ type Account struct {
 	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	DocumentNumber string                 `protobuf:"bytes,2,opt,name=document_number,json=documentNumber,proto3" json:"document_number,omitempty"`
 	AccountType    string                 `protobuf:"bytes,3,opt,name=account_type,json=accountType,proto3" json:"account_type,omitempty"`
-	Balance        float64                `protobuf:"fixed64,4,opt,name=balance,proto3" json:"balance,omitempty"`
-	CreatedAt      int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt      int64                  `protobuf:"varint,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	BalanceCents   int64                  `protobuf:"varint,15,opt,name=balance_cents,json=balanceCents,proto3" json:"balance_cents,omitempty"`
+	// Deprecated: use balance_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Balance   float64 `protobuf:"fixed64,4,opt,name=balance,proto3" json:"balance,omitempty"`
+	CreatedAt int64   `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt int64   `protobuf:"varint,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Onboarding state: DRAFT, PENDING_KYC or ACTIVE; only ACTIVE accounts can transact
+	Status      string `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	HolderName  string `protobuf:"bytes,8,opt,name=holder_name,json=holderName,proto3" json:"holder_name,omitempty"`
+	HolderEmail string `protobuf:"bytes,9,opt,name=holder_email,json=holderEmail,proto3" json:"holder_email,omitempty"`
+	// Identifier of the KYC check at the verification provider
+	KycReference string `protobuf:"bytes,10,opt,name=kyc_reference,json=kycReference,proto3" json:"kyc_reference,omitempty"`
+	// Incremented whenever the account's attributes change; balance movements leave it unchanged
+	Version int64 `protobuf:"varint,11,opt,name=version,proto3" json:"version,omitempty"`
+	// How far below zero debits may take the balance; only checking accounts have one
+	OverdraftLimitCents int64  `protobuf:"varint,12,opt,name=overdraft_limit_cents,json=overdraftLimitCents,proto3" json:"overdraft_limit_cents,omitempty"`
+	HolderPhone         string `protobuf:"bytes,13,opt,name=holder_phone,json=holderPhone,proto3" json:"holder_phone,omitempty"`
+	// Segmentation tags, upper case and sorted
+	Tags          []string `protobuf:"bytes,14,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Account) Reset() {
@@ -86,6 +103,14 @@ func (x *Account) GetAccountType() string {
 	return ""
 }
 
+func (x *Account) GetBalanceCents() int64 {
+	if x != nil {
+		return x.BalanceCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
 func (x *Account) GetBalance() float64 {
 	if x != nil {
 		return x.Balance
@@ -107,14 +132,80 @@ func (x *Account) GetUpdatedAt() int64 {
 	return 0
 }
 
+func (x *Account) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Account) GetHolderName() string {
+	if x != nil {
+		return x.HolderName
+	}
+	return ""
+}
+
+func (x *Account) GetHolderEmail() string {
+	if x != nil {
+		return x.HolderEmail
+	}
+	return ""
+}
+
+func (x *Account) GetKycReference() string {
+	if x != nil {
+		return x.KycReference
+	}
+	return ""
+}
+
+func (x *Account) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Account) GetOverdraftLimitCents() int64 {
+	if x != nil {
+		return x.OverdraftLimitCents
+	}
+	return 0
+}
+
+func (x *Account) GetHolderPhone() string {
+	if x != nil {
+		return x.HolderPhone
+	}
+	return ""
+}
+
+func (x *Account) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
 // Request/Response messages
 type CreateAccountRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	DocumentNumber string                 `protobuf:"bytes,1,opt,name=document_number,json=documentNumber,proto3" json:"document_number,omitempty"`
-	AccountType    string                 `protobuf:"bytes,2,opt,name=account_type,json=accountType,proto3" json:"account_type,omitempty"`
-	InitialBalance float64                `protobuf:"fixed64,3,opt,name=initial_balance,json=initialBalance,proto3" json:"initial_balance,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	DocumentNumber      string                 `protobuf:"bytes,1,opt,name=document_number,json=documentNumber,proto3" json:"document_number,omitempty"`
+	AccountType         string                 `protobuf:"bytes,2,opt,name=account_type,json=accountType,proto3" json:"account_type,omitempty"`
+	InitialBalanceCents int64                  `protobuf:"varint,8,opt,name=initial_balance_cents,json=initialBalanceCents,proto3" json:"initial_balance_cents,omitempty"`
+	// Deprecated: use initial_balance_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	InitialBalance float64 `protobuf:"fixed64,3,opt,name=initial_balance,json=initialBalance,proto3" json:"initial_balance,omitempty"`
+	// Create the account in DRAFT state so it can be onboarded before it transacts
+	Draft bool `protobuf:"varint,4,opt,name=draft,proto3" json:"draft,omitempty"`
+	// Optional holder contact details, for statements and notifications
+	HolderName    string `protobuf:"bytes,5,opt,name=holder_name,json=holderName,proto3" json:"holder_name,omitempty"`
+	HolderEmail   string `protobuf:"bytes,6,opt,name=holder_email,json=holderEmail,proto3" json:"holder_email,omitempty"`
+	HolderPhone   string `protobuf:"bytes,7,opt,name=holder_phone,json=holderPhone,proto3" json:"holder_phone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateAccountRequest) Reset() {
@@ -161,6 +252,14 @@ func (x *CreateAccountRequest) GetAccountType() string {
 	return ""
 }
 
+func (x *CreateAccountRequest) GetInitialBalanceCents() int64 {
+	if x != nil {
+		return x.InitialBalanceCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
 func (x *CreateAccountRequest) GetInitialBalance() float64 {
 	if x != nil {
 		return x.InitialBalance
@@ -168,12 +267,43 @@ func (x *CreateAccountRequest) GetInitialBalance() float64 {
 	return 0
 }
 
+func (x *CreateAccountRequest) GetDraft() bool {
+	if x != nil {
+		return x.Draft
+	}
+	return false
+}
+
+func (x *CreateAccountRequest) GetHolderName() string {
+	if x != nil {
+		return x.HolderName
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetHolderEmail() string {
+	if x != nil {
+		return x.HolderEmail
+	}
+	return ""
+}
+
+func (x *CreateAccountRequest) GetHolderPhone() string {
+	if x != nil {
+		return x.HolderPhone
+	}
+	return ""
+}
+
 type CreateAccountResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Account       *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Account *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Error   string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Set with the "account already exists" and "account closed" errors: the account holding the document number,
+	// if it is the caller's tenant's
+	ExistingAccountId string `protobuf:"bytes,3,opt,name=existing_account_id,json=existingAccountId,proto3" json:"existing_account_id,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *CreateAccountResponse) Reset() {
@@ -220,11 +350,20 @@ func (x *CreateAccountResponse) GetError() string {
 	return ""
 }
 
+func (x *CreateAccountResponse) GetExistingAccountId() string {
+	if x != nil {
+		return x.ExistingAccountId
+	}
+	return ""
+}
+
 type GetAccountRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Maximum age in milliseconds of a cached result the caller accepts; 0 forces a read from the primary database
+	MaxStalenessMs int64 `protobuf:"varint,2,opt,name=max_staleness_ms,json=maxStalenessMs,proto3" json:"max_staleness_ms,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *GetAccountRequest) Reset() {
@@ -264,6 +403,13 @@ func (x *GetAccountRequest) GetId() string {
 	return ""
 }
 
+func (x *GetAccountRequest) GetMaxStalenessMs() int64 {
+	if x != nil {
+		return x.MaxStalenessMs
+	}
+	return 0
+}
+
 type GetAccountResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Account       *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
@@ -316,18 +462,130 @@ func (x *GetAccountResponse) GetError() string {
 	return ""
 }
 
-type UpdateAccountRequest struct {
+type GetAccountByDocumentRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
-	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	DocumentNumber string                 `protobuf:"bytes,2,opt,name=document_number,json=documentNumber,proto3" json:"document_number,omitempty"`
-	AccountType    string                 `protobuf:"bytes,3,opt,name=account_type,json=accountType,proto3" json:"account_type,omitempty"`
+	DocumentNumber string                 `protobuf:"bytes,1,opt,name=document_number,json=documentNumber,proto3" json:"document_number,omitempty"`
+	// Maximum age in milliseconds of a cached result the caller accepts; 0 forces a read from the primary database
+	MaxStalenessMs int64 `protobuf:"varint,2,opt,name=max_staleness_ms,json=maxStalenessMs,proto3" json:"max_staleness_ms,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
+func (x *GetAccountByDocumentRequest) Reset() {
+	*x = GetAccountByDocumentRequest{}
+	mi := &file_account_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountByDocumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountByDocumentRequest) ProtoMessage() {}
+
+func (x *GetAccountByDocumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountByDocumentRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountByDocumentRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetAccountByDocumentRequest) GetDocumentNumber() string {
+	if x != nil {
+		return x.DocumentNumber
+	}
+	return ""
+}
+
+func (x *GetAccountByDocumentRequest) GetMaxStalenessMs() int64 {
+	if x != nil {
+		return x.MaxStalenessMs
+	}
+	return 0
+}
+
+type GetAccountByDocumentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Account       *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountByDocumentResponse) Reset() {
+	*x = GetAccountByDocumentResponse{}
+	mi := &file_account_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountByDocumentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountByDocumentResponse) ProtoMessage() {}
+
+func (x *GetAccountByDocumentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountByDocumentResponse.ProtoReflect.Descriptor instead.
+func (*GetAccountByDocumentResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetAccountByDocumentResponse) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+func (x *GetAccountByDocumentResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UpdateAccountRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Must match the current document number; changes go through RequestDocumentChange
+	DocumentNumber string `protobuf:"bytes,2,opt,name=document_number,json=documentNumber,proto3" json:"document_number,omitempty"`
+	AccountType    string `protobuf:"bytes,3,opt,name=account_type,json=accountType,proto3" json:"account_type,omitempty"`
+	// When set, the update is only applied if the account is still at this version
+	ExpectedVersion int64 `protobuf:"varint,4,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	// Holder contact details; empty fields keep their current value
+	HolderName    string `protobuf:"bytes,5,opt,name=holder_name,json=holderName,proto3" json:"holder_name,omitempty"`
+	HolderEmail   string `protobuf:"bytes,6,opt,name=holder_email,json=holderEmail,proto3" json:"holder_email,omitempty"`
+	HolderPhone   string `protobuf:"bytes,7,opt,name=holder_phone,json=holderPhone,proto3" json:"holder_phone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
 func (x *UpdateAccountRequest) Reset() {
 	*x = UpdateAccountRequest{}
-	mi := &file_account_proto_msgTypes[5]
+	mi := &file_account_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -339,7 +597,7 @@ func (x *UpdateAccountRequest) String() string {
 func (*UpdateAccountRequest) ProtoMessage() {}
 
 func (x *UpdateAccountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_account_proto_msgTypes[5]
+	mi := &file_account_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -352,7 +610,7 @@ func (x *UpdateAccountRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateAccountRequest.ProtoReflect.Descriptor instead.
 func (*UpdateAccountRequest) Descriptor() ([]byte, []int) {
-	return file_account_proto_rawDescGZIP(), []int{5}
+	return file_account_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *UpdateAccountRequest) GetId() string {
@@ -376,6 +634,34 @@ func (x *UpdateAccountRequest) GetAccountType() string {
 	return ""
 }
 
+func (x *UpdateAccountRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *UpdateAccountRequest) GetHolderName() string {
+	if x != nil {
+		return x.HolderName
+	}
+	return ""
+}
+
+func (x *UpdateAccountRequest) GetHolderEmail() string {
+	if x != nil {
+		return x.HolderEmail
+	}
+	return ""
+}
+
+func (x *UpdateAccountRequest) GetHolderPhone() string {
+	if x != nil {
+		return x.HolderPhone
+	}
+	return ""
+}
+
 type UpdateAccountResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Account       *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
@@ -386,7 +672,7 @@ type UpdateAccountResponse struct {
 
 func (x *UpdateAccountResponse) Reset() {
 	*x = UpdateAccountResponse{}
-	mi := &file_account_proto_msgTypes[6]
+	mi := &file_account_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -398,7 +684,7 @@ func (x *UpdateAccountResponse) String() string {
 func (*UpdateAccountResponse) ProtoMessage() {}
 
 func (x *UpdateAccountResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_account_proto_msgTypes[6]
+	mi := &file_account_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -411,7 +697,7 @@ func (x *UpdateAccountResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateAccountResponse.ProtoReflect.Descriptor instead.
 func (*UpdateAccountResponse) Descriptor() ([]byte, []int) {
-	return file_account_proto_rawDescGZIP(), []int{6}
+	return file_account_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *UpdateAccountResponse) GetAccount() *Account {
@@ -437,7 +723,7 @@ type DeleteAccountRequest struct {
 
 func (x *DeleteAccountRequest) Reset() {
 	*x = DeleteAccountRequest{}
-	mi := &file_account_proto_msgTypes[7]
+	mi := &file_account_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -449,7 +735,7 @@ func (x *DeleteAccountRequest) String() string {
 func (*DeleteAccountRequest) ProtoMessage() {}
 
 func (x *DeleteAccountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_account_proto_msgTypes[7]
+	mi := &file_account_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -462,7 +748,7 @@ func (x *DeleteAccountRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteAccountRequest.ProtoReflect.Descriptor instead.
 func (*DeleteAccountRequest) Descriptor() ([]byte, []int) {
-	return file_account_proto_rawDescGZIP(), []int{7}
+	return file_account_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *DeleteAccountRequest) GetId() string {
@@ -473,16 +759,18 @@ func (x *DeleteAccountRequest) GetId() string {
 }
 
 type DeleteAccountResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Snapshot of the account taken before it was deleted or closed, for RestoreAccountSnapshot
+	SnapshotId    string `protobuf:"bytes,3,opt,name=snapshot_id,json=snapshotId,proto3" json:"snapshot_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DeleteAccountResponse) Reset() {
 	*x = DeleteAccountResponse{}
-	mi := &file_account_proto_msgTypes[8]
+	mi := &file_account_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -494,7 +782,7 @@ func (x *DeleteAccountResponse) String() string {
 func (*DeleteAccountResponse) ProtoMessage() {}
 
 func (x *DeleteAccountResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_account_proto_msgTypes[8]
+	mi := &file_account_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -507,7 +795,7 @@ func (x *DeleteAccountResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteAccountResponse.ProtoReflect.Descriptor instead.
 func (*DeleteAccountResponse) Descriptor() ([]byte, []int) {
-	return file_account_proto_rawDescGZIP(), []int{8}
+	return file_account_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *DeleteAccountResponse) GetSuccess() bool {
@@ -524,16 +812,25 @@ func (x *DeleteAccountResponse) GetError() string {
 	return ""
 }
 
+func (x *DeleteAccountResponse) GetSnapshotId() string {
+	if x != nil {
+		return x.SnapshotId
+	}
+	return ""
+}
+
 type GetBalanceRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Maximum age in milliseconds of a cached result the caller accepts; 0 forces a read from the primary database
+	MaxStalenessMs int64 `protobuf:"varint,2,opt,name=max_staleness_ms,json=maxStalenessMs,proto3" json:"max_staleness_ms,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *GetBalanceRequest) Reset() {
 	*x = GetBalanceRequest{}
-	mi := &file_account_proto_msgTypes[9]
+	mi := &file_account_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -545,7 +842,7 @@ func (x *GetBalanceRequest) String() string {
 func (*GetBalanceRequest) ProtoMessage() {}
 
 func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_account_proto_msgTypes[9]
+	mi := &file_account_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -558,7 +855,7 @@ func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
 func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
-	return file_account_proto_rawDescGZIP(), []int{9}
+	return file_account_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *GetBalanceRequest) GetAccountId() string {
@@ -568,29 +865,4075 @@ func (x *GetBalanceRequest) GetAccountId() string {
 	return ""
 }
 
+func (x *GetBalanceRequest) GetMaxStalenessMs() int64 {
+	if x != nil {
+		return x.MaxStalenessMs
+	}
+	return 0
+}
+
 type GetBalanceResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Balance       float64                `protobuf:"fixed64,1,opt,name=balance,proto3" json:"balance,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	BalanceCents int64                  `protobuf:"varint,3,opt,name=balance_cents,json=balanceCents,proto3" json:"balance_cents,omitempty"`
+	// Deprecated: use balance_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Balance       float64 `protobuf:"fixed64,1,opt,name=balance,proto3" json:"balance,omitempty"`
+	Error         string  `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceResponse) Reset() {
+	*x = GetBalanceResponse{}
+	mi := &file_account_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceResponse) ProtoMessage() {}
+
+func (x *GetBalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceResponse.ProtoReflect.Descriptor instead.
+func (*GetBalanceResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetBalanceResponse) GetBalanceCents() int64 {
+	if x != nil {
+		return x.BalanceCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *GetBalanceResponse) GetBalance() float64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *GetBalanceResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetBalanceAtRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Point in time as Unix seconds; the balance includes everything up to the end of that second
+	Timestamp     int64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceAtRequest) Reset() {
+	*x = GetBalanceAtRequest{}
+	mi := &file_account_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceAtRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceAtRequest) ProtoMessage() {}
+
+func (x *GetBalanceAtRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceAtRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceAtRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetBalanceAtRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetBalanceAtRequest) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type GetBalanceAtResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BalanceCents  int64                  `protobuf:"varint,1,opt,name=balance_cents,json=balanceCents,proto3" json:"balance_cents,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceAtResponse) Reset() {
+	*x = GetBalanceAtResponse{}
+	mi := &file_account_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceAtResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceAtResponse) ProtoMessage() {}
+
+func (x *GetBalanceAtResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceAtResponse.ProtoReflect.Descriptor instead.
+func (*GetBalanceAtResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetBalanceAtResponse) GetBalanceCents() int64 {
+	if x != nil {
+		return x.BalanceCents
+	}
+	return 0
+}
+
+func (x *GetBalanceAtResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *GetBalanceAtResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetBalancesRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Maximum age in milliseconds of a cached result the caller accepts; 0 forces a read from the primary database
+	MaxStalenessMs int64 `protobuf:"varint,2,opt,name=max_staleness_ms,json=maxStalenessMs,proto3" json:"max_staleness_ms,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetBalancesRequest) Reset() {
+	*x = GetBalancesRequest{}
+	mi := &file_account_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalancesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalancesRequest) ProtoMessage() {}
+
+func (x *GetBalancesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalancesRequest.ProtoReflect.Descriptor instead.
+func (*GetBalancesRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetBalancesRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetBalancesRequest) GetMaxStalenessMs() int64 {
+	if x != nil {
+		return x.MaxStalenessMs
+	}
+	return 0
+}
+
+// Balance of an account in one currency
+type CurrencyBalance struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ISO 4217 currency code; empty when the account's tenant has no currency configured
+	Currency     string `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	BalanceCents int64  `protobuf:"varint,5,opt,name=balance_cents,json=balanceCents,proto3" json:"balance_cents,omitempty"`
+	// Deprecated: use balance_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Balance float64 `protobuf:"fixed64,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	// Amount reserved by holds and not yet spendable
+	HeldCents int64 `protobuf:"varint,6,opt,name=held_cents,json=heldCents,proto3" json:"held_cents,omitempty"`
+	// Deprecated: use held_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Held float64 `protobuf:"fixed64,3,opt,name=held,proto3" json:"held,omitempty"`
+	// balance minus held
+	AvailableCents int64 `protobuf:"varint,7,opt,name=available_cents,json=availableCents,proto3" json:"available_cents,omitempty"`
+	// Deprecated: use available_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Available     float64 `protobuf:"fixed64,4,opt,name=available,proto3" json:"available,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CurrencyBalance) Reset() {
+	*x = CurrencyBalance{}
+	mi := &file_account_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CurrencyBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CurrencyBalance) ProtoMessage() {}
+
+func (x *CurrencyBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CurrencyBalance.ProtoReflect.Descriptor instead.
+func (*CurrencyBalance) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CurrencyBalance) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *CurrencyBalance) GetBalanceCents() int64 {
+	if x != nil {
+		return x.BalanceCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *CurrencyBalance) GetBalance() float64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *CurrencyBalance) GetHeldCents() int64 {
+	if x != nil {
+		return x.HeldCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *CurrencyBalance) GetHeld() float64 {
+	if x != nil {
+		return x.Held
+	}
+	return 0
+}
+
+func (x *CurrencyBalance) GetAvailableCents() int64 {
+	if x != nil {
+		return x.AvailableCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *CurrencyBalance) GetAvailable() float64 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+// Spending power of a credit account
+type CreditAvailability struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Credit line of the account; 0 when it has none
+	LimitCents int64 `protobuf:"varint,3,opt,name=limit_cents,json=limitCents,proto3" json:"limit_cents,omitempty"`
+	// Deprecated: use limit_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Limit          float64 `protobuf:"fixed64,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	AvailableCents int64   `protobuf:"varint,4,opt,name=available_cents,json=availableCents,proto3" json:"available_cents,omitempty"`
+	// Deprecated: use available_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Available     float64 `protobuf:"fixed64,2,opt,name=available,proto3" json:"available,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreditAvailability) Reset() {
+	*x = CreditAvailability{}
+	mi := &file_account_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreditAvailability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreditAvailability) ProtoMessage() {}
+
+func (x *CreditAvailability) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreditAvailability.ProtoReflect.Descriptor instead.
+func (*CreditAvailability) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CreditAvailability) GetLimitCents() int64 {
+	if x != nil {
+		return x.LimitCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *CreditAvailability) GetLimit() float64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *CreditAvailability) GetAvailableCents() int64 {
+	if x != nil {
+		return x.AvailableCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *CreditAvailability) GetAvailable() float64 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+type GetBalancesResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Balances  []*CurrencyBalance     `protobuf:"bytes,2,rep,name=balances,proto3" json:"balances,omitempty"`
+	// Set for CREDIT accounts only
+	Credit        *CreditAvailability `protobuf:"bytes,3,opt,name=credit,proto3" json:"credit,omitempty"`
+	Error         string              `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalancesResponse) Reset() {
+	*x = GetBalancesResponse{}
+	mi := &file_account_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalancesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalancesResponse) ProtoMessage() {}
+
+func (x *GetBalancesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalancesResponse.ProtoReflect.Descriptor instead.
+func (*GetBalancesResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetBalancesResponse) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetBalancesResponse) GetBalances() []*CurrencyBalance {
+	if x != nil {
+		return x.Balances
+	}
+	return nil
+}
+
+func (x *GetBalancesResponse) GetCredit() *CreditAvailability {
+	if x != nil {
+		return x.Credit
+	}
+	return nil
+}
+
+func (x *GetBalancesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListAccountsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Limit  int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Creation time range as Unix seconds, from inclusive and to exclusive; zero leaves that end open
+	CreatedFrom int64 `protobuf:"varint,3,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`
+	CreatedTo   int64 `protobuf:"varint,4,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`
+	// Balance range, both ends inclusive; unset leaves that end open
+	MinBalanceCents *int64 `protobuf:"varint,9,opt,name=min_balance_cents,json=minBalanceCents,proto3,oneof" json:"min_balance_cents,omitempty"`
+	// Deprecated: use min_balance_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	MinBalance      *float64 `protobuf:"fixed64,5,opt,name=min_balance,json=minBalance,proto3,oneof" json:"min_balance,omitempty"`
+	MaxBalanceCents *int64   `protobuf:"varint,10,opt,name=max_balance_cents,json=maxBalanceCents,proto3,oneof" json:"max_balance_cents,omitempty"`
+	// Deprecated: use max_balance_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	MaxBalance *float64 `protobuf:"fixed64,6,opt,name=max_balance,json=maxBalance,proto3,oneof" json:"max_balance,omitempty"`
+	// Only accounts having all of tags and none of exclude_tags
+	Tags          []string `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	ExcludeTags   []string `protobuf:"bytes,8,rep,name=exclude_tags,json=excludeTags,proto3" json:"exclude_tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccountsRequest) Reset() {
+	*x = ListAccountsRequest{}
+	mi := &file_account_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccountsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccountsRequest) ProtoMessage() {}
+
+func (x *ListAccountsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccountsRequest.ProtoReflect.Descriptor instead.
+func (*ListAccountsRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListAccountsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListAccountsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListAccountsRequest) GetCreatedFrom() int64 {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return 0
+}
+
+func (x *ListAccountsRequest) GetCreatedTo() int64 {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return 0
+}
+
+func (x *ListAccountsRequest) GetMinBalanceCents() int64 {
+	if x != nil && x.MinBalanceCents != nil {
+		return *x.MinBalanceCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *ListAccountsRequest) GetMinBalance() float64 {
+	if x != nil && x.MinBalance != nil {
+		return *x.MinBalance
+	}
+	return 0
+}
+
+func (x *ListAccountsRequest) GetMaxBalanceCents() int64 {
+	if x != nil && x.MaxBalanceCents != nil {
+		return *x.MaxBalanceCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *ListAccountsRequest) GetMaxBalance() float64 {
+	if x != nil && x.MaxBalance != nil {
+		return *x.MaxBalance
+	}
+	return 0
+}
+
+func (x *ListAccountsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListAccountsRequest) GetExcludeTags() []string {
+	if x != nil {
+		return x.ExcludeTags
+	}
+	return nil
+}
+
+type ListAccountsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accounts      []*Account             `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccountsResponse) Reset() {
+	*x = ListAccountsResponse{}
+	mi := &file_account_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccountsResponse) ProtoMessage() {}
+
+func (x *ListAccountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccountsResponse.ProtoReflect.Descriptor instead.
+func (*ListAccountsResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListAccountsResponse) GetAccounts() []*Account {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (x *ListAccountsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListAccountsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CreateAccountsFailure struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Index          int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	DocumentNumber string                 `protobuf:"bytes,2,opt,name=document_number,json=documentNumber,proto3" json:"document_number,omitempty"`
+	Reason         string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateAccountsFailure) Reset() {
+	*x = CreateAccountsFailure{}
+	mi := &file_account_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountsFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountsFailure) ProtoMessage() {}
+
+func (x *CreateAccountsFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountsFailure.ProtoReflect.Descriptor instead.
+func (*CreateAccountsFailure) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CreateAccountsFailure) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *CreateAccountsFailure) GetDocumentNumber() string {
+	if x != nil {
+		return x.DocumentNumber
+	}
+	return ""
+}
+
+func (x *CreateAccountsFailure) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CreateAccountsResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Created       int32                    `protobuf:"varint,1,opt,name=created,proto3" json:"created,omitempty"`
+	Duplicates    int32                    `protobuf:"varint,2,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	Failures      []*CreateAccountsFailure `protobuf:"bytes,3,rep,name=failures,proto3" json:"failures,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAccountsResponse) Reset() {
+	*x = CreateAccountsResponse{}
+	mi := &file_account_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountsResponse) ProtoMessage() {}
+
+func (x *CreateAccountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountsResponse.ProtoReflect.Descriptor instead.
+func (*CreateAccountsResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CreateAccountsResponse) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *CreateAccountsResponse) GetDuplicates() int32 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+func (x *CreateAccountsResponse) GetFailures() []*CreateAccountsFailure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+type SearchAccountsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Partial document number, at least 3 characters
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// "prefix" (default) or "suffix"
+	Match         string `protobuf:"bytes,2,opt,name=match,proto3" json:"match,omitempty"`
+	Limit         int32  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchAccountsRequest) Reset() {
+	*x = SearchAccountsRequest{}
+	mi := &file_account_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchAccountsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchAccountsRequest) ProtoMessage() {}
+
+func (x *SearchAccountsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchAccountsRequest.ProtoReflect.Descriptor instead.
+func (*SearchAccountsRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SearchAccountsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchAccountsRequest) GetMatch() string {
+	if x != nil {
+		return x.Match
+	}
+	return ""
+}
+
+func (x *SearchAccountsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchAccountsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Document numbers are masked unless the caller role allows full visibility
+	Accounts      []*Account `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	Error         string     `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchAccountsResponse) Reset() {
+	*x = SearchAccountsResponse{}
+	mi := &file_account_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchAccountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchAccountsResponse) ProtoMessage() {}
+
+func (x *SearchAccountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchAccountsResponse.ProtoReflect.Descriptor instead.
+func (*SearchAccountsResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SearchAccountsResponse) GetAccounts() []*Account {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (x *SearchAccountsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type FeeRule struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	FixedCents int64                  `protobuf:"varint,3,opt,name=fixed_cents,json=fixedCents,proto3" json:"fixed_cents,omitempty"`
+	// Deprecated: use fixed_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Fixed         float64 `protobuf:"fixed64,1,opt,name=fixed,proto3" json:"fixed,omitempty"`
+	Percent       float64 `protobuf:"fixed64,2,opt,name=percent,proto3" json:"percent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FeeRule) Reset() {
+	*x = FeeRule{}
+	mi := &file_account_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FeeRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeeRule) ProtoMessage() {}
+
+func (x *FeeRule) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeeRule.ProtoReflect.Descriptor instead.
+func (*FeeRule) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *FeeRule) GetFixedCents() int64 {
+	if x != nil {
+		return x.FixedCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *FeeRule) GetFixed() float64 {
+	if x != nil {
+		return x.Fixed
+	}
+	return 0
+}
+
+func (x *FeeRule) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+// Settings of one tenant; unset fields fall back to the platform defaults
+type TenantSettings struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ISO 4217 currency code
+	Currency string `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	// Operation types the tenant accepts; empty allows all
+	AllowedOperationTypes []string `protobuf:"bytes,2,rep,name=allowed_operation_types,json=allowedOperationTypes,proto3" json:"allowed_operation_types,omitempty"`
+	// Largest amount of a single transaction; 0 means no limit
+	MaxTransactionAmountCents int64 `protobuf:"varint,10,opt,name=max_transaction_amount_cents,json=maxTransactionAmountCents,proto3" json:"max_transaction_amount_cents,omitempty"`
+	// Deprecated: use max_transaction_amount_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	MaxTransactionAmount float64 `protobuf:"fixed64,3,opt,name=max_transaction_amount,json=maxTransactionAmount,proto3" json:"max_transaction_amount,omitempty"`
+	// Fee rule per operation type
+	FeeSchedule map[string]*FeeRule `protobuf:"bytes,4,rep,name=fee_schedule,json=feeSchedule,proto3" json:"fee_schedule,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Where account balances are read from: COLUMN (default) or LEDGER
+	BalanceSource string `protobuf:"bytes,5,opt,name=balance_source,json=balanceSource,proto3" json:"balance_source,omitempty"`
+	// Data retention periods; unset keeps data indefinitely
+	Retention *RetentionSettings `protobuf:"bytes,6,opt,name=retention,proto3" json:"retention,omitempty"`
+	// ISO 4217 currency the tenant reports in; balances in another currency are revalued at every month end
+	ReportingCurrency string `protobuf:"bytes,7,opt,name=reporting_currency,json=reportingCurrency,proto3" json:"reporting_currency,omitempty"`
+	// Sandbox tenants take test document numbers only and have their held transactions cleared automatically
+	Sandbox bool `protobuf:"varint,8,opt,name=sandbox,proto3" json:"sandbox,omitempty"`
+	// Document types, e.g. CPF and CNPJ, whose format and check digits account holder documents must match;
+	// empty accepts any document number
+	DocumentTypes []string `protobuf:"bytes,9,rep,name=document_types,json=documentTypes,proto3" json:"document_types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TenantSettings) Reset() {
+	*x = TenantSettings{}
+	mi := &file_account_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TenantSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TenantSettings) ProtoMessage() {}
+
+func (x *TenantSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TenantSettings.ProtoReflect.Descriptor instead.
+func (*TenantSettings) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *TenantSettings) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *TenantSettings) GetAllowedOperationTypes() []string {
+	if x != nil {
+		return x.AllowedOperationTypes
+	}
+	return nil
+}
+
+func (x *TenantSettings) GetMaxTransactionAmountCents() int64 {
+	if x != nil {
+		return x.MaxTransactionAmountCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *TenantSettings) GetMaxTransactionAmount() float64 {
+	if x != nil {
+		return x.MaxTransactionAmount
+	}
+	return 0
+}
+
+func (x *TenantSettings) GetFeeSchedule() map[string]*FeeRule {
+	if x != nil {
+		return x.FeeSchedule
+	}
+	return nil
+}
+
+func (x *TenantSettings) GetBalanceSource() string {
+	if x != nil {
+		return x.BalanceSource
+	}
+	return ""
+}
+
+func (x *TenantSettings) GetRetention() *RetentionSettings {
+	if x != nil {
+		return x.Retention
+	}
+	return nil
+}
+
+func (x *TenantSettings) GetReportingCurrency() string {
+	if x != nil {
+		return x.ReportingCurrency
+	}
+	return ""
+}
+
+func (x *TenantSettings) GetSandbox() bool {
+	if x != nil {
+		return x.Sandbox
+	}
+	return false
+}
+
+func (x *TenantSettings) GetDocumentTypes() []string {
+	if x != nil {
+		return x.DocumentTypes
+	}
+	return nil
+}
+
+// Data retention periods of a tenant in days; 0 keeps the data indefinitely
+type RetentionSettings struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// How long transactions are kept before they are purged
+	TransactionDays int32 `protobuf:"varint,1,opt,name=transaction_days,json=transactionDays,proto3" json:"transaction_days,omitempty"`
+	// How long after an account is closed its holder data is anonymized
+	AnonymizeClosedAccountDays int32 `protobuf:"varint,2,opt,name=anonymize_closed_account_days,json=anonymizeClosedAccountDays,proto3" json:"anonymize_closed_account_days,omitempty"`
+	unknownFields              protoimpl.UnknownFields
+	sizeCache                  protoimpl.SizeCache
+}
+
+func (x *RetentionSettings) Reset() {
+	*x = RetentionSettings{}
+	mi := &file_account_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetentionSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetentionSettings) ProtoMessage() {}
+
+func (x *RetentionSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetentionSettings.ProtoReflect.Descriptor instead.
+func (*RetentionSettings) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RetentionSettings) GetTransactionDays() int32 {
+	if x != nil {
+		return x.TransactionDays
+	}
+	return 0
+}
+
+func (x *RetentionSettings) GetAnonymizeClosedAccountDays() int32 {
+	if x != nil {
+		return x.AnonymizeClosedAccountDays
+	}
+	return 0
+}
+
+type GetTenantSettingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTenantSettingsRequest) Reset() {
+	*x = GetTenantSettingsRequest{}
+	mi := &file_account_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTenantSettingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTenantSettingsRequest) ProtoMessage() {}
+
+func (x *GetTenantSettingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTenantSettingsRequest.ProtoReflect.Descriptor instead.
+func (*GetTenantSettingsRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetTenantSettingsRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+type GetTenantSettingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Settings      *TenantSettings        `protobuf:"bytes,1,opt,name=settings,proto3" json:"settings,omitempty"`
+	Environment   string                 `protobuf:"bytes,2,opt,name=environment,proto3" json:"environment,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTenantSettingsResponse) Reset() {
+	*x = GetTenantSettingsResponse{}
+	mi := &file_account_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTenantSettingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTenantSettingsResponse) ProtoMessage() {}
+
+func (x *GetTenantSettingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTenantSettingsResponse.ProtoReflect.Descriptor instead.
+func (*GetTenantSettingsResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetTenantSettingsResponse) GetSettings() *TenantSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *GetTenantSettingsResponse) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+func (x *GetTenantSettingsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UpdateTenantSettingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Settings      *TenantSettings        `protobuf:"bytes,2,opt,name=settings,proto3" json:"settings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTenantSettingsRequest) Reset() {
+	*x = UpdateTenantSettingsRequest{}
+	mi := &file_account_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTenantSettingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTenantSettingsRequest) ProtoMessage() {}
+
+func (x *UpdateTenantSettingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTenantSettingsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTenantSettingsRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *UpdateTenantSettingsRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *UpdateTenantSettingsRequest) GetSettings() *TenantSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+type UpdateTenantSettingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Settings      *TenantSettings        `protobuf:"bytes,1,opt,name=settings,proto3" json:"settings,omitempty"`
+	Environment   string                 `protobuf:"bytes,2,opt,name=environment,proto3" json:"environment,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTenantSettingsResponse) Reset() {
+	*x = UpdateTenantSettingsResponse{}
+	mi := &file_account_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTenantSettingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTenantSettingsResponse) ProtoMessage() {}
+
+func (x *UpdateTenantSettingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTenantSettingsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateTenantSettingsResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UpdateTenantSettingsResponse) GetSettings() *TenantSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *UpdateTenantSettingsResponse) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+func (x *UpdateTenantSettingsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// A manual balance adjustment and its approval trail
+type BalanceAdjustment struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AccountId string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// CREDIT or DEBIT
+	Direction   string `protobuf:"bytes,3,opt,name=direction,proto3" json:"direction,omitempty"`
+	AmountCents int64  `protobuf:"varint,15,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// Deprecated: use amount_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Amount      float64 `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	ReasonCode  string  `protobuf:"bytes,5,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	Description string  `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	// PENDING, APPROVED or REJECTED
+	Status      string `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	RequestedBy string `protobuf:"bytes,8,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	RequestedAt int64  `protobuf:"varint,9,opt,name=requested_at,json=requestedAt,proto3" json:"requested_at,omitempty"`
+	ReviewedBy  string `protobuf:"bytes,10,opt,name=reviewed_by,json=reviewedBy,proto3" json:"reviewed_by,omitempty"`
+	ReviewedAt  int64  `protobuf:"varint,11,opt,name=reviewed_at,json=reviewedAt,proto3" json:"reviewed_at,omitempty"`
+	ReviewNote  string `protobuf:"bytes,12,opt,name=review_note,json=reviewNote,proto3" json:"review_note,omitempty"`
+	// Set on adjustments posted through InternalAccountService: the calling service and its reference
+	Source        string `protobuf:"bytes,13,opt,name=source,proto3" json:"source,omitempty"`
+	Reference     string `protobuf:"bytes,14,opt,name=reference,proto3" json:"reference,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BalanceAdjustment) Reset() {
+	*x = BalanceAdjustment{}
+	mi := &file_account_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceAdjustment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceAdjustment) ProtoMessage() {}
+
+func (x *BalanceAdjustment) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceAdjustment.ProtoReflect.Descriptor instead.
+func (*BalanceAdjustment) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *BalanceAdjustment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BalanceAdjustment) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *BalanceAdjustment) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *BalanceAdjustment) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *BalanceAdjustment) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *BalanceAdjustment) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *BalanceAdjustment) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *BalanceAdjustment) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *BalanceAdjustment) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+func (x *BalanceAdjustment) GetRequestedAt() int64 {
+	if x != nil {
+		return x.RequestedAt
+	}
+	return 0
+}
+
+func (x *BalanceAdjustment) GetReviewedBy() string {
+	if x != nil {
+		return x.ReviewedBy
+	}
+	return ""
+}
+
+func (x *BalanceAdjustment) GetReviewedAt() int64 {
+	if x != nil {
+		return x.ReviewedAt
+	}
+	return 0
+}
+
+func (x *BalanceAdjustment) GetReviewNote() string {
+	if x != nil {
+		return x.ReviewNote
+	}
+	return ""
+}
+
+func (x *BalanceAdjustment) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *BalanceAdjustment) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+type RequestBalanceAdjustmentRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Direction string                 `protobuf:"bytes,2,opt,name=direction,proto3" json:"direction,omitempty"`
+	// Always positive; direction decides the sign
+	AmountCents int64 `protobuf:"varint,6,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// Deprecated: use amount_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Amount        float64 `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	ReasonCode    string  `protobuf:"bytes,4,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	Description   string  `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestBalanceAdjustmentRequest) Reset() {
+	*x = RequestBalanceAdjustmentRequest{}
+	mi := &file_account_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestBalanceAdjustmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestBalanceAdjustmentRequest) ProtoMessage() {}
+
+func (x *RequestBalanceAdjustmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestBalanceAdjustmentRequest.ProtoReflect.Descriptor instead.
+func (*RequestBalanceAdjustmentRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *RequestBalanceAdjustmentRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *RequestBalanceAdjustmentRequest) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *RequestBalanceAdjustmentRequest) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *RequestBalanceAdjustmentRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RequestBalanceAdjustmentRequest) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *RequestBalanceAdjustmentRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type AdjustBalanceRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Direction string                 `protobuf:"bytes,2,opt,name=direction,proto3" json:"direction,omitempty"`
+	// Always positive; direction decides the sign
+	AmountCents int64 `protobuf:"varint,7,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// Deprecated: use amount_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Amount float64 `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	// INTEREST_ACCRUAL, FEE_CHARGE, FEE_REFUND or SETTLEMENT
+	ReasonCode string `protobuf:"bytes,4,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	// Unique per calling service, e.g. the accrual period and account; a retry with the same reference
+	// returns the adjustment already posted
+	Reference     string `protobuf:"bytes,5,opt,name=reference,proto3" json:"reference,omitempty"`
+	Description   string `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustBalanceRequest) Reset() {
+	*x = AdjustBalanceRequest{}
+	mi := &file_account_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustBalanceRequest) ProtoMessage() {}
+
+func (x *AdjustBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustBalanceRequest.ProtoReflect.Descriptor instead.
+func (*AdjustBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *AdjustBalanceRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *AdjustBalanceRequest) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *AdjustBalanceRequest) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *AdjustBalanceRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *AdjustBalanceRequest) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *AdjustBalanceRequest) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *AdjustBalanceRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type ReviewBalanceAdjustmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Approve       bool                   `protobuf:"varint,2,opt,name=approve,proto3" json:"approve,omitempty"`
+	Note          string                 `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewBalanceAdjustmentRequest) Reset() {
+	*x = ReviewBalanceAdjustmentRequest{}
+	mi := &file_account_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewBalanceAdjustmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewBalanceAdjustmentRequest) ProtoMessage() {}
+
+func (x *ReviewBalanceAdjustmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewBalanceAdjustmentRequest.ProtoReflect.Descriptor instead.
+func (*ReviewBalanceAdjustmentRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ReviewBalanceAdjustmentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReviewBalanceAdjustmentRequest) GetApprove() bool {
+	if x != nil {
+		return x.Approve
+	}
+	return false
+}
+
+func (x *ReviewBalanceAdjustmentRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type BalanceAdjustmentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Adjustment    *BalanceAdjustment     `protobuf:"bytes,1,opt,name=adjustment,proto3" json:"adjustment,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BalanceAdjustmentResponse) Reset() {
+	*x = BalanceAdjustmentResponse{}
+	mi := &file_account_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceAdjustmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceAdjustmentResponse) ProtoMessage() {}
+
+func (x *BalanceAdjustmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceAdjustmentResponse.ProtoReflect.Descriptor instead.
+func (*BalanceAdjustmentResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *BalanceAdjustmentResponse) GetAdjustment() *BalanceAdjustment {
+	if x != nil {
+		return x.Adjustment
+	}
+	return nil
+}
+
+func (x *BalanceAdjustmentResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListBalanceAdjustmentsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Optional status filter
+	Status        string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBalanceAdjustmentsRequest) Reset() {
+	*x = ListBalanceAdjustmentsRequest{}
+	mi := &file_account_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBalanceAdjustmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBalanceAdjustmentsRequest) ProtoMessage() {}
+
+func (x *ListBalanceAdjustmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBalanceAdjustmentsRequest.ProtoReflect.Descriptor instead.
+func (*ListBalanceAdjustmentsRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ListBalanceAdjustmentsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ListBalanceAdjustmentsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListBalanceAdjustmentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Adjustments   []*BalanceAdjustment   `protobuf:"bytes,1,rep,name=adjustments,proto3" json:"adjustments,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBalanceAdjustmentsResponse) Reset() {
+	*x = ListBalanceAdjustmentsResponse{}
+	mi := &file_account_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBalanceAdjustmentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBalanceAdjustmentsResponse) ProtoMessage() {}
+
+func (x *ListBalanceAdjustmentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBalanceAdjustmentsResponse.ProtoReflect.Descriptor instead.
+func (*ListBalanceAdjustmentsResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ListBalanceAdjustmentsResponse) GetAdjustments() []*BalanceAdjustment {
+	if x != nil {
+		return x.Adjustments
+	}
+	return nil
+}
+
+func (x *ListBalanceAdjustmentsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UpdateAccountHolderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	HolderName    string                 `protobuf:"bytes,2,opt,name=holder_name,json=holderName,proto3" json:"holder_name,omitempty"`
+	HolderEmail   string                 `protobuf:"bytes,3,opt,name=holder_email,json=holderEmail,proto3" json:"holder_email,omitempty"`
+	KycReference  string                 `protobuf:"bytes,4,opt,name=kyc_reference,json=kycReference,proto3" json:"kyc_reference,omitempty"`
+	HolderPhone   string                 `protobuf:"bytes,5,opt,name=holder_phone,json=holderPhone,proto3" json:"holder_phone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAccountHolderRequest) Reset() {
+	*x = UpdateAccountHolderRequest{}
+	mi := &file_account_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAccountHolderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAccountHolderRequest) ProtoMessage() {}
+
+func (x *UpdateAccountHolderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAccountHolderRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAccountHolderRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *UpdateAccountHolderRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *UpdateAccountHolderRequest) GetHolderName() string {
+	if x != nil {
+		return x.HolderName
+	}
+	return ""
+}
+
+func (x *UpdateAccountHolderRequest) GetHolderEmail() string {
+	if x != nil {
+		return x.HolderEmail
+	}
+	return ""
+}
+
+func (x *UpdateAccountHolderRequest) GetKycReference() string {
+	if x != nil {
+		return x.KycReference
+	}
+	return ""
+}
+
+func (x *UpdateAccountHolderRequest) GetHolderPhone() string {
+	if x != nil {
+		return x.HolderPhone
+	}
+	return ""
+}
+
+type UpdateAccountHolderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Account       *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAccountHolderResponse) Reset() {
+	*x = UpdateAccountHolderResponse{}
+	mi := &file_account_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAccountHolderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAccountHolderResponse) ProtoMessage() {}
+
+func (x *UpdateAccountHolderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAccountHolderResponse.ProtoReflect.Descriptor instead.
+func (*UpdateAccountHolderResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *UpdateAccountHolderResponse) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+func (x *UpdateAccountHolderResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SetOverdraftLimitRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// 0 removes the overdraft
+	OverdraftLimitCents int64 `protobuf:"varint,2,opt,name=overdraft_limit_cents,json=overdraftLimitCents,proto3" json:"overdraft_limit_cents,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SetOverdraftLimitRequest) Reset() {
+	*x = SetOverdraftLimitRequest{}
+	mi := &file_account_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetOverdraftLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOverdraftLimitRequest) ProtoMessage() {}
+
+func (x *SetOverdraftLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOverdraftLimitRequest.ProtoReflect.Descriptor instead.
+func (*SetOverdraftLimitRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *SetOverdraftLimitRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *SetOverdraftLimitRequest) GetOverdraftLimitCents() int64 {
+	if x != nil {
+		return x.OverdraftLimitCents
+	}
+	return 0
+}
+
+type SetOverdraftLimitResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Account       *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetOverdraftLimitResponse) Reset() {
+	*x = SetOverdraftLimitResponse{}
+	mi := &file_account_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetOverdraftLimitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOverdraftLimitResponse) ProtoMessage() {}
+
+func (x *SetOverdraftLimitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOverdraftLimitResponse.ProtoReflect.Descriptor instead.
+func (*SetOverdraftLimitResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *SetOverdraftLimitResponse) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+func (x *SetOverdraftLimitResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UpdateAccountTagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Add           []string               `protobuf:"bytes,2,rep,name=add,proto3" json:"add,omitempty"`
+	Remove        []string               `protobuf:"bytes,3,rep,name=remove,proto3" json:"remove,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAccountTagsRequest) Reset() {
+	*x = UpdateAccountTagsRequest{}
+	mi := &file_account_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAccountTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAccountTagsRequest) ProtoMessage() {}
+
+func (x *UpdateAccountTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAccountTagsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateAccountTagsRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *UpdateAccountTagsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *UpdateAccountTagsRequest) GetAdd() []string {
+	if x != nil {
+		return x.Add
+	}
+	return nil
+}
+
+func (x *UpdateAccountTagsRequest) GetRemove() []string {
+	if x != nil {
+		return x.Remove
+	}
+	return nil
+}
+
+type UpdateAccountTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Account       *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateAccountTagsResponse) Reset() {
+	*x = UpdateAccountTagsResponse{}
+	mi := &file_account_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateAccountTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateAccountTagsResponse) ProtoMessage() {}
+
+func (x *UpdateAccountTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateAccountTagsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateAccountTagsResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *UpdateAccountTagsResponse) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+func (x *UpdateAccountTagsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type AdvanceOnboardingRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// PENDING_KYC, ACTIVE or DRAFT
+	Status        string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdvanceOnboardingRequest) Reset() {
+	*x = AdvanceOnboardingRequest{}
+	mi := &file_account_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdvanceOnboardingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdvanceOnboardingRequest) ProtoMessage() {}
+
+func (x *AdvanceOnboardingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdvanceOnboardingRequest.ProtoReflect.Descriptor instead.
+func (*AdvanceOnboardingRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *AdvanceOnboardingRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *AdvanceOnboardingRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type AdvanceOnboardingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Account       *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdvanceOnboardingResponse) Reset() {
+	*x = AdvanceOnboardingResponse{}
+	mi := &file_account_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdvanceOnboardingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdvanceOnboardingResponse) ProtoMessage() {}
+
+func (x *AdvanceOnboardingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdvanceOnboardingResponse.ProtoReflect.Descriptor instead.
+func (*AdvanceOnboardingResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *AdvanceOnboardingResponse) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+func (x *AdvanceOnboardingResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type AccessDecision struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Service that made the decision, e.g. account-mgr
+	Service string `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	// Full gRPC method called
+	Method string `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	// Operator ID, or the caller ID forwarded by the gateway
+	Principal string `protobuf:"bytes,4,opt,name=principal,proto3" json:"principal,omitempty"`
+	Role      string `protobuf:"bytes,5,opt,name=role,proto3" json:"role,omitempty"`
+	TenantId  string `protobuf:"bytes,6,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	RequestId string `protobuf:"bytes,7,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// ALLOW or DENY
+	Decision      string `protobuf:"bytes,8,opt,name=decision,proto3" json:"decision,omitempty"`
+	Reason        string `protobuf:"bytes,9,opt,name=reason,proto3" json:"reason,omitempty"`
+	OccurredAt    int64  `protobuf:"varint,10,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccessDecision) Reset() {
+	*x = AccessDecision{}
+	mi := &file_account_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccessDecision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccessDecision) ProtoMessage() {}
+
+func (x *AccessDecision) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccessDecision.ProtoReflect.Descriptor instead.
+func (*AccessDecision) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *AccessDecision) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AccessDecision) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *AccessDecision) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *AccessDecision) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *AccessDecision) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *AccessDecision) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *AccessDecision) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *AccessDecision) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+func (x *AccessDecision) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AccessDecision) GetOccurredAt() int64 {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return 0
+}
+
+type ListAccessDecisionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional filters; from and to are Unix seconds, from inclusive and to exclusive
+	Principal string `protobuf:"bytes,1,opt,name=principal,proto3" json:"principal,omitempty"`
+	Method    string `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Decision  string `protobuf:"bytes,3,opt,name=decision,proto3" json:"decision,omitempty"`
+	From      int64  `protobuf:"varint,4,opt,name=from,proto3" json:"from,omitempty"`
+	To        int64  `protobuf:"varint,5,opt,name=to,proto3" json:"to,omitempty"`
+	Limit     int32  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Continue a listing from the next_before_id of the previous page
+	BeforeId      int64 `protobuf:"varint,7,opt,name=before_id,json=beforeId,proto3" json:"before_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccessDecisionsRequest) Reset() {
+	*x = ListAccessDecisionsRequest{}
+	mi := &file_account_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccessDecisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccessDecisionsRequest) ProtoMessage() {}
+
+func (x *ListAccessDecisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccessDecisionsRequest.ProtoReflect.Descriptor instead.
+func (*ListAccessDecisionsRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ListAccessDecisionsRequest) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *ListAccessDecisionsRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *ListAccessDecisionsRequest) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+func (x *ListAccessDecisionsRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *ListAccessDecisionsRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *ListAccessDecisionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListAccessDecisionsRequest) GetBeforeId() int64 {
+	if x != nil {
+		return x.BeforeId
+	}
+	return 0
+}
+
+type ListAccessDecisionsResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Decisions []*AccessDecision      `protobuf:"bytes,1,rep,name=decisions,proto3" json:"decisions,omitempty"`
+	// Set when the page was full; pass as before_id for the next page
+	NextBeforeId  int64  `protobuf:"varint,2,opt,name=next_before_id,json=nextBeforeId,proto3" json:"next_before_id,omitempty"`
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccessDecisionsResponse) Reset() {
+	*x = ListAccessDecisionsResponse{}
+	mi := &file_account_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccessDecisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccessDecisionsResponse) ProtoMessage() {}
+
+func (x *ListAccessDecisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccessDecisionsResponse.ProtoReflect.Descriptor instead.
+func (*ListAccessDecisionsResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ListAccessDecisionsResponse) GetDecisions() []*AccessDecision {
+	if x != nil {
+		return x.Decisions
+	}
+	return nil
+}
+
+func (x *ListAccessDecisionsResponse) GetNextBeforeId() int64 {
+	if x != nil {
+		return x.NextBeforeId
+	}
+	return 0
+}
+
+func (x *ListAccessDecisionsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Unrealized FX gain or loss of one account for one month, in the tenant's reporting currency
+type FxRevaluation struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Account balance at the end of the period, in currency
+	BalanceCents int64 `protobuf:"varint,8,opt,name=balance_cents,json=balanceCents,proto3" json:"balance_cents,omitempty"`
+	// Deprecated: use balance_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	Balance float64 `protobuf:"fixed64,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	// Reporting currency per unit of currency on the last day of the period
+	ClosingRate float64 `protobuf:"fixed64,3,opt,name=closing_rate,json=closingRate,proto3" json:"closing_rate,omitempty"`
+	// Value of the balance before revaluation: the previous revaluation plus movements at their day's rate
+	CarryingValueCents int64 `protobuf:"varint,9,opt,name=carrying_value_cents,json=carryingValueCents,proto3" json:"carrying_value_cents,omitempty"`
+	// Deprecated: use carrying_value_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	CarryingValue float64 `protobuf:"fixed64,4,opt,name=carrying_value,json=carryingValue,proto3" json:"carrying_value,omitempty"`
+	// Balance at the closing rate
+	RevaluedValueCents int64 `protobuf:"varint,10,opt,name=revalued_value_cents,json=revaluedValueCents,proto3" json:"revalued_value_cents,omitempty"`
+	// Deprecated: use revalued_value_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	RevaluedValue float64 `protobuf:"fixed64,5,opt,name=revalued_value,json=revaluedValue,proto3" json:"revalued_value,omitempty"`
+	// revalued_value - carrying_value; negative for a loss
+	UnrealizedGainCents int64 `protobuf:"varint,11,opt,name=unrealized_gain_cents,json=unrealizedGainCents,proto3" json:"unrealized_gain_cents,omitempty"`
+	// Deprecated: use unrealized_gain_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	UnrealizedGain float64 `protobuf:"fixed64,6,opt,name=unrealized_gain,json=unrealizedGain,proto3" json:"unrealized_gain,omitempty"`
+	CreatedAt      int64   `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FxRevaluation) Reset() {
+	*x = FxRevaluation{}
+	mi := &file_account_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FxRevaluation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FxRevaluation) ProtoMessage() {}
+
+func (x *FxRevaluation) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FxRevaluation.ProtoReflect.Descriptor instead.
+func (*FxRevaluation) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *FxRevaluation) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *FxRevaluation) GetBalanceCents() int64 {
+	if x != nil {
+		return x.BalanceCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *FxRevaluation) GetBalance() float64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *FxRevaluation) GetClosingRate() float64 {
+	if x != nil {
+		return x.ClosingRate
+	}
+	return 0
+}
+
+func (x *FxRevaluation) GetCarryingValueCents() int64 {
+	if x != nil {
+		return x.CarryingValueCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *FxRevaluation) GetCarryingValue() float64 {
+	if x != nil {
+		return x.CarryingValue
+	}
+	return 0
+}
+
+func (x *FxRevaluation) GetRevaluedValueCents() int64 {
+	if x != nil {
+		return x.RevaluedValueCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *FxRevaluation) GetRevaluedValue() float64 {
+	if x != nil {
+		return x.RevaluedValue
+	}
+	return 0
+}
+
+func (x *FxRevaluation) GetUnrealizedGainCents() int64 {
+	if x != nil {
+		return x.UnrealizedGainCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *FxRevaluation) GetUnrealizedGain() float64 {
+	if x != nil {
+		return x.UnrealizedGain
+	}
+	return 0
+}
+
+func (x *FxRevaluation) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type GetFxRevaluationReportRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	TenantId string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	// Month as YYYY-MM
+	Period        string `protobuf:"bytes,2,opt,name=period,proto3" json:"period,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFxRevaluationReportRequest) Reset() {
+	*x = GetFxRevaluationReportRequest{}
+	mi := &file_account_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFxRevaluationReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFxRevaluationReportRequest) ProtoMessage() {}
+
+func (x *GetFxRevaluationReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFxRevaluationReportRequest.ProtoReflect.Descriptor instead.
+func (*GetFxRevaluationReportRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *GetFxRevaluationReportRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *GetFxRevaluationReportRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+type GetFxRevaluationReportResponse struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	TenantId                 string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Period                   string                 `protobuf:"bytes,2,opt,name=period,proto3" json:"period,omitempty"`
+	Currency                 string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	ReportingCurrency        string                 `protobuf:"bytes,4,opt,name=reporting_currency,json=reportingCurrency,proto3" json:"reporting_currency,omitempty"`
+	Revaluations             []*FxRevaluation       `protobuf:"bytes,5,rep,name=revaluations,proto3" json:"revaluations,omitempty"`
+	TotalUnrealizedGainCents int64                  `protobuf:"varint,8,opt,name=total_unrealized_gain_cents,json=totalUnrealizedGainCents,proto3" json:"total_unrealized_gain_cents,omitempty"`
+	// Deprecated: use total_unrealized_gain_cents
+	//
+	// Deprecated: Marked as deprecated in account.proto.
+	TotalUnrealizedGain float64 `protobuf:"fixed64,6,opt,name=total_unrealized_gain,json=totalUnrealizedGain,proto3" json:"total_unrealized_gain,omitempty"`
+	Error               string  `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GetFxRevaluationReportResponse) Reset() {
+	*x = GetFxRevaluationReportResponse{}
+	mi := &file_account_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFxRevaluationReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFxRevaluationReportResponse) ProtoMessage() {}
+
+func (x *GetFxRevaluationReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFxRevaluationReportResponse.ProtoReflect.Descriptor instead.
+func (*GetFxRevaluationReportResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *GetFxRevaluationReportResponse) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *GetFxRevaluationReportResponse) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *GetFxRevaluationReportResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *GetFxRevaluationReportResponse) GetReportingCurrency() string {
+	if x != nil {
+		return x.ReportingCurrency
+	}
+	return ""
+}
+
+func (x *GetFxRevaluationReportResponse) GetRevaluations() []*FxRevaluation {
+	if x != nil {
+		return x.Revaluations
+	}
+	return nil
+}
+
+func (x *GetFxRevaluationReportResponse) GetTotalUnrealizedGainCents() int64 {
+	if x != nil {
+		return x.TotalUnrealizedGainCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in account.proto.
+func (x *GetFxRevaluationReportResponse) GetTotalUnrealizedGain() float64 {
+	if x != nil {
+		return x.TotalUnrealizedGain
+	}
+	return 0
+}
+
+func (x *GetFxRevaluationReportResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// A change of an account's document number and its audit trail
+type DocumentChange struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Id                     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AccountId              string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	PreviousDocumentNumber string                 `protobuf:"bytes,3,opt,name=previous_document_number,json=previousDocumentNumber,proto3" json:"previous_document_number,omitempty"`
+	DocumentNumber         string                 `protobuf:"bytes,4,opt,name=document_number,json=documentNumber,proto3" json:"document_number,omitempty"`
+	// PENDING, VERIFIED, REJECTED or APPLIED
+	Status      string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Reason      string `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	RequestedBy string `protobuf:"bytes,7,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	RequestedAt int64  `protobuf:"varint,8,opt,name=requested_at,json=requestedAt,proto3" json:"requested_at,omitempty"`
+	// Reference of the document check the verifier relied on, e.g. a KYC case
+	VerificationReference string `protobuf:"bytes,9,opt,name=verification_reference,json=verificationReference,proto3" json:"verification_reference,omitempty"`
+	VerifiedBy            string `protobuf:"bytes,10,opt,name=verified_by,json=verifiedBy,proto3" json:"verified_by,omitempty"`
+	VerifiedAt            int64  `protobuf:"varint,11,opt,name=verified_at,json=verifiedAt,proto3" json:"verified_at,omitempty"`
+	VerificationNote      string `protobuf:"bytes,12,opt,name=verification_note,json=verificationNote,proto3" json:"verification_note,omitempty"`
+	AppliedBy             string `protobuf:"bytes,13,opt,name=applied_by,json=appliedBy,proto3" json:"applied_by,omitempty"`
+	AppliedAt             int64  `protobuf:"varint,14,opt,name=applied_at,json=appliedAt,proto3" json:"applied_at,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *DocumentChange) Reset() {
+	*x = DocumentChange{}
+	mi := &file_account_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocumentChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocumentChange) ProtoMessage() {}
+
+func (x *DocumentChange) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocumentChange.ProtoReflect.Descriptor instead.
+func (*DocumentChange) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *DocumentChange) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetPreviousDocumentNumber() string {
+	if x != nil {
+		return x.PreviousDocumentNumber
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetDocumentNumber() string {
+	if x != nil {
+		return x.DocumentNumber
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetRequestedAt() int64 {
+	if x != nil {
+		return x.RequestedAt
+	}
+	return 0
+}
+
+func (x *DocumentChange) GetVerificationReference() string {
+	if x != nil {
+		return x.VerificationReference
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetVerifiedBy() string {
+	if x != nil {
+		return x.VerifiedBy
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetVerifiedAt() int64 {
+	if x != nil {
+		return x.VerifiedAt
+	}
+	return 0
+}
+
+func (x *DocumentChange) GetVerificationNote() string {
+	if x != nil {
+		return x.VerificationNote
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetAppliedBy() string {
+	if x != nil {
+		return x.AppliedBy
+	}
+	return ""
+}
+
+func (x *DocumentChange) GetAppliedAt() int64 {
+	if x != nil {
+		return x.AppliedAt
+	}
+	return 0
+}
+
+type RequestDocumentChangeRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AccountId      string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	DocumentNumber string                 `protobuf:"bytes,2,opt,name=document_number,json=documentNumber,proto3" json:"document_number,omitempty"`
+	Reason         string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RequestDocumentChangeRequest) Reset() {
+	*x = RequestDocumentChangeRequest{}
+	mi := &file_account_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestDocumentChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestDocumentChangeRequest) ProtoMessage() {}
+
+func (x *RequestDocumentChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestDocumentChangeRequest.ProtoReflect.Descriptor instead.
+func (*RequestDocumentChangeRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *RequestDocumentChangeRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *RequestDocumentChangeRequest) GetDocumentNumber() string {
+	if x != nil {
+		return x.DocumentNumber
+	}
+	return ""
+}
+
+func (x *RequestDocumentChangeRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type VerifyDocumentChangeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Verifies the change when true, rejects it otherwise
+	Approve bool `protobuf:"varint,2,opt,name=approve,proto3" json:"approve,omitempty"`
+	// Required to verify
+	VerificationReference string `protobuf:"bytes,3,opt,name=verification_reference,json=verificationReference,proto3" json:"verification_reference,omitempty"`
+	Note                  string `protobuf:"bytes,4,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *VerifyDocumentChangeRequest) Reset() {
+	*x = VerifyDocumentChangeRequest{}
+	mi := &file_account_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyDocumentChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyDocumentChangeRequest) ProtoMessage() {}
+
+func (x *VerifyDocumentChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyDocumentChangeRequest.ProtoReflect.Descriptor instead.
+func (*VerifyDocumentChangeRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *VerifyDocumentChangeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *VerifyDocumentChangeRequest) GetApprove() bool {
+	if x != nil {
+		return x.Approve
+	}
+	return false
+}
+
+func (x *VerifyDocumentChangeRequest) GetVerificationReference() string {
+	if x != nil {
+		return x.VerificationReference
+	}
+	return ""
+}
+
+func (x *VerifyDocumentChangeRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type ApplyDocumentChangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyDocumentChangeRequest) Reset() {
+	*x = ApplyDocumentChangeRequest{}
+	mi := &file_account_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyDocumentChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyDocumentChangeRequest) ProtoMessage() {}
+
+func (x *ApplyDocumentChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyDocumentChangeRequest.ProtoReflect.Descriptor instead.
+func (*ApplyDocumentChangeRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *ApplyDocumentChangeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DocumentChangeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Change        *DocumentChange        `protobuf:"bytes,1,opt,name=change,proto3" json:"change,omitempty"`
 	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetBalanceResponse) Reset() {
-	*x = GetBalanceResponse{}
-	mi := &file_account_proto_msgTypes[10]
+func (x *DocumentChangeResponse) Reset() {
+	*x = DocumentChangeResponse{}
+	mi := &file_account_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocumentChangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocumentChangeResponse) ProtoMessage() {}
+
+func (x *DocumentChangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocumentChangeResponse.ProtoReflect.Descriptor instead.
+func (*DocumentChangeResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *DocumentChangeResponse) GetChange() *DocumentChange {
+	if x != nil {
+		return x.Change
+	}
+	return nil
+}
+
+func (x *DocumentChangeResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListDocumentChangesRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Optional status filter
+	Status        string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDocumentChangesRequest) Reset() {
+	*x = ListDocumentChangesRequest{}
+	mi := &file_account_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDocumentChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDocumentChangesRequest) ProtoMessage() {}
+
+func (x *ListDocumentChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDocumentChangesRequest.ProtoReflect.Descriptor instead.
+func (*ListDocumentChangesRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *ListDocumentChangesRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ListDocumentChangesRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListDocumentChangesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first
+	Changes       []*DocumentChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	Error         string            `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDocumentChangesResponse) Reset() {
+	*x = ListDocumentChangesResponse{}
+	mi := &file_account_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDocumentChangesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDocumentChangesResponse) ProtoMessage() {}
+
+func (x *ListDocumentChangesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDocumentChangesResponse.ProtoReflect.Descriptor instead.
+func (*ListDocumentChangesResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *ListDocumentChangesResponse) GetChanges() []*DocumentChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *ListDocumentChangesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Audited attributes of an account before or after a change
+type AccountAuditValues struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	DocumentNumber      string                 `protobuf:"bytes,1,opt,name=document_number,json=documentNumber,proto3" json:"document_number,omitempty"`
+	AccountType         string                 `protobuf:"bytes,2,opt,name=account_type,json=accountType,proto3" json:"account_type,omitempty"`
+	Status              string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	HolderName          string                 `protobuf:"bytes,4,opt,name=holder_name,json=holderName,proto3" json:"holder_name,omitempty"`
+	HolderEmail         string                 `protobuf:"bytes,5,opt,name=holder_email,json=holderEmail,proto3" json:"holder_email,omitempty"`
+	HolderPhone         string                 `protobuf:"bytes,6,opt,name=holder_phone,json=holderPhone,proto3" json:"holder_phone,omitempty"`
+	KycReference        string                 `protobuf:"bytes,7,opt,name=kyc_reference,json=kycReference,proto3" json:"kyc_reference,omitempty"`
+	OverdraftLimitCents int64                  `protobuf:"varint,8,opt,name=overdraft_limit_cents,json=overdraftLimitCents,proto3" json:"overdraft_limit_cents,omitempty"`
+	Tags                []string               `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *AccountAuditValues) Reset() {
+	*x = AccountAuditValues{}
+	mi := &file_account_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountAuditValues) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountAuditValues) ProtoMessage() {}
+
+func (x *AccountAuditValues) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountAuditValues.ProtoReflect.Descriptor instead.
+func (*AccountAuditValues) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *AccountAuditValues) GetDocumentNumber() string {
+	if x != nil {
+		return x.DocumentNumber
+	}
+	return ""
+}
+
+func (x *AccountAuditValues) GetAccountType() string {
+	if x != nil {
+		return x.AccountType
+	}
+	return ""
+}
+
+func (x *AccountAuditValues) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AccountAuditValues) GetHolderName() string {
+	if x != nil {
+		return x.HolderName
+	}
+	return ""
+}
+
+func (x *AccountAuditValues) GetHolderEmail() string {
+	if x != nil {
+		return x.HolderEmail
+	}
+	return ""
+}
+
+func (x *AccountAuditValues) GetHolderPhone() string {
+	if x != nil {
+		return x.HolderPhone
+	}
+	return ""
+}
+
+func (x *AccountAuditValues) GetKycReference() string {
+	if x != nil {
+		return x.KycReference
+	}
+	return ""
+}
+
+func (x *AccountAuditValues) GetOverdraftLimitCents() int64 {
+	if x != nil {
+		return x.OverdraftLimitCents
+	}
+	return 0
+}
+
+func (x *AccountAuditValues) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// A change to an account recorded in its audit log
+type AccountAuditEntry struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AccountId string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// CREATE, UPDATE, DELETE or STATUS_CHANGE
+	Action string `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	// Operator ID, or else the caller ID, of the request that made the change
+	Actor     string `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	ActorRole string `protobuf:"bytes,5,opt,name=actor_role,json=actorRole,proto3" json:"actor_role,omitempty"`
+	ChangedAt int64  `protobuf:"varint,6,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
+	// Unset for CREATE
+	Previous *AccountAuditValues `protobuf:"bytes,7,opt,name=previous,proto3" json:"previous,omitempty"`
+	// Unset when the account was deleted
+	Updated       *AccountAuditValues `protobuf:"bytes,8,opt,name=updated,proto3" json:"updated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountAuditEntry) Reset() {
+	*x = AccountAuditEntry{}
+	mi := &file_account_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountAuditEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountAuditEntry) ProtoMessage() {}
+
+func (x *AccountAuditEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountAuditEntry.ProtoReflect.Descriptor instead.
+func (*AccountAuditEntry) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *AccountAuditEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AccountAuditEntry) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *AccountAuditEntry) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AccountAuditEntry) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AccountAuditEntry) GetActorRole() string {
+	if x != nil {
+		return x.ActorRole
+	}
+	return ""
+}
+
+func (x *AccountAuditEntry) GetChangedAt() int64 {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return 0
+}
+
+func (x *AccountAuditEntry) GetPrevious() *AccountAuditValues {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *AccountAuditEntry) GetUpdated() *AccountAuditValues {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+type GetAccountAuditRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Maximum number of entries; defaults to 100, at most 500
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountAuditRequest) Reset() {
+	*x = GetAccountAuditRequest{}
+	mi := &file_account_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountAuditRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountAuditRequest) ProtoMessage() {}
+
+func (x *GetAccountAuditRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountAuditRequest.ProtoReflect.Descriptor instead.
+func (*GetAccountAuditRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *GetAccountAuditRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetAccountAuditRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetAccountAuditResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first
+	Entries       []*AccountAuditEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	Error         string               `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountAuditResponse) Reset() {
+	*x = GetAccountAuditResponse{}
+	mi := &file_account_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountAuditResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountAuditResponse) ProtoMessage() {}
+
+func (x *GetAccountAuditResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountAuditResponse.ProtoReflect.Descriptor instead.
+func (*GetAccountAuditResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *GetAccountAuditResponse) GetEntries() []*AccountAuditEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *GetAccountAuditResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// A copy of an account and its most recent transactions, taken before a destructive operation
+type AccountSnapshot struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AccountId string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Operation the snapshot was taken before: DELETE or CLOSE
+	Operation string `protobuf:"bytes,3,opt,name=operation,proto3" json:"operation,omitempty"`
+	// Operator ID, or else the caller ID, of the request that took it
+	TakenBy     string `protobuf:"bytes,4,opt,name=taken_by,json=takenBy,proto3" json:"taken_by,omitempty"`
+	TakenByRole string `protobuf:"bytes,5,opt,name=taken_by_role,json=takenByRole,proto3" json:"taken_by_role,omitempty"`
+	TakenAt     int64  `protobuf:"varint,6,opt,name=taken_at,json=takenAt,proto3" json:"taken_at,omitempty"`
+	// The account as it was
+	Account *Account `protobuf:"bytes,7,opt,name=account,proto3" json:"account,omitempty"`
+	// Number of transactions copied
+	TransactionCount int32 `protobuf:"varint,8,opt,name=transaction_count,json=transactionCount,proto3" json:"transaction_count,omitempty"`
+	// Set once the snapshot has been restored
+	RestoredAt    int64  `protobuf:"varint,9,opt,name=restored_at,json=restoredAt,proto3" json:"restored_at,omitempty"`
+	RestoredBy    string `protobuf:"bytes,10,opt,name=restored_by,json=restoredBy,proto3" json:"restored_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountSnapshot) Reset() {
+	*x = AccountSnapshot{}
+	mi := &file_account_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountSnapshot) ProtoMessage() {}
+
+func (x *AccountSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountSnapshot.ProtoReflect.Descriptor instead.
+func (*AccountSnapshot) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *AccountSnapshot) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AccountSnapshot) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *AccountSnapshot) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *AccountSnapshot) GetTakenBy() string {
+	if x != nil {
+		return x.TakenBy
+	}
+	return ""
+}
+
+func (x *AccountSnapshot) GetTakenByRole() string {
+	if x != nil {
+		return x.TakenByRole
+	}
+	return ""
+}
+
+func (x *AccountSnapshot) GetTakenAt() int64 {
+	if x != nil {
+		return x.TakenAt
+	}
+	return 0
+}
+
+func (x *AccountSnapshot) GetAccount() *Account {
+	if x != nil {
+		return x.Account
+	}
+	return nil
+}
+
+func (x *AccountSnapshot) GetTransactionCount() int32 {
+	if x != nil {
+		return x.TransactionCount
+	}
+	return 0
+}
+
+func (x *AccountSnapshot) GetRestoredAt() int64 {
+	if x != nil {
+		return x.RestoredAt
+	}
+	return 0
+}
+
+func (x *AccountSnapshot) GetRestoredBy() string {
+	if x != nil {
+		return x.RestoredBy
+	}
+	return ""
+}
+
+type ListAccountSnapshotsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccountSnapshotsRequest) Reset() {
+	*x = ListAccountSnapshotsRequest{}
+	mi := &file_account_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccountSnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccountSnapshotsRequest) ProtoMessage() {}
+
+func (x *ListAccountSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccountSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*ListAccountSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *ListAccountSnapshotsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+type ListAccountSnapshotsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first
+	Snapshots     []*AccountSnapshot `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	Error         string             `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccountSnapshotsResponse) Reset() {
+	*x = ListAccountSnapshotsResponse{}
+	mi := &file_account_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccountSnapshotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccountSnapshotsResponse) ProtoMessage() {}
+
+func (x *ListAccountSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccountSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*ListAccountSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *ListAccountSnapshotsResponse) GetSnapshots() []*AccountSnapshot {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+func (x *ListAccountSnapshotsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RestoreAccountSnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreAccountSnapshotRequest) Reset() {
+	*x = RestoreAccountSnapshotRequest{}
+	mi := &file_account_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreAccountSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreAccountSnapshotRequest) ProtoMessage() {}
+
+func (x *RestoreAccountSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreAccountSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*RestoreAccountSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *RestoreAccountSnapshotRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RestoreAccountSnapshotResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Account *Account               `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	// Transactions put back; zero when restoring a closed account, whose transactions were kept
+	RestoredTransactions int32  `protobuf:"varint,2,opt,name=restored_transactions,json=restoredTransactions,proto3" json:"restored_transactions,omitempty"`
+	Error                string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *RestoreAccountSnapshotResponse) Reset() {
+	*x = RestoreAccountSnapshotResponse{}
+	mi := &file_account_proto_msgTypes[68]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetBalanceResponse) String() string {
+func (x *RestoreAccountSnapshotResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetBalanceResponse) ProtoMessage() {}
+func (*RestoreAccountSnapshotResponse) ProtoMessage() {}
 
-func (x *GetBalanceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_account_proto_msgTypes[10]
+func (x *RestoreAccountSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[68]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -601,48 +4944,58 @@ func (x *GetBalanceResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetBalanceResponse.ProtoReflect.Descriptor instead.
-func (*GetBalanceResponse) Descriptor() ([]byte, []int) {
-	return file_account_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use RestoreAccountSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*RestoreAccountSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{68}
 }
 
-func (x *GetBalanceResponse) GetBalance() float64 {
+func (x *RestoreAccountSnapshotResponse) GetAccount() *Account {
 	if x != nil {
-		return x.Balance
+		return x.Account
+	}
+	return nil
+}
+
+func (x *RestoreAccountSnapshotResponse) GetRestoredTransactions() int32 {
+	if x != nil {
+		return x.RestoredTransactions
 	}
 	return 0
 }
 
-func (x *GetBalanceResponse) GetError() string {
+func (x *RestoreAccountSnapshotResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-type ListAccountsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+type StreamAccountsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Creation time range as Unix seconds, from inclusive and to exclusive; defaults to all accounts
+	CreatedFrom int64 `protobuf:"varint,1,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`
+	CreatedTo   int64 `protobuf:"varint,2,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`
+	// Only accounts in this state: DRAFT, PENDING_KYC, ACTIVE or CLOSED
+	Status        string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListAccountsRequest) Reset() {
-	*x = ListAccountsRequest{}
-	mi := &file_account_proto_msgTypes[11]
+func (x *StreamAccountsRequest) Reset() {
+	*x = StreamAccountsRequest{}
+	mi := &file_account_proto_msgTypes[69]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListAccountsRequest) String() string {
+func (x *StreamAccountsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListAccountsRequest) ProtoMessage() {}
+func (*StreamAccountsRequest) ProtoMessage() {}
 
-func (x *ListAccountsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_account_proto_msgTypes[11]
+func (x *StreamAccountsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[69]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -653,49 +5006,57 @@ func (x *ListAccountsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListAccountsRequest.ProtoReflect.Descriptor instead.
-func (*ListAccountsRequest) Descriptor() ([]byte, []int) {
-	return file_account_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use StreamAccountsRequest.ProtoReflect.Descriptor instead.
+func (*StreamAccountsRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{69}
 }
 
-func (x *ListAccountsRequest) GetLimit() int32 {
+func (x *StreamAccountsRequest) GetCreatedFrom() int64 {
 	if x != nil {
-		return x.Limit
+		return x.CreatedFrom
 	}
 	return 0
 }
 
-func (x *ListAccountsRequest) GetOffset() int32 {
+func (x *StreamAccountsRequest) GetCreatedTo() int64 {
 	if x != nil {
-		return x.Offset
+		return x.CreatedTo
 	}
 	return 0
 }
 
-type ListAccountsResponse struct {
+func (x *StreamAccountsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// A batch of streamed accounts. Request errors are reported in the first message and a failure part way
+// through in the last one; the accounts received so far are then incomplete.
+type StreamAccountsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Accounts      []*Account             `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
-	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
-	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListAccountsResponse) Reset() {
-	*x = ListAccountsResponse{}
-	mi := &file_account_proto_msgTypes[12]
+func (x *StreamAccountsResponse) Reset() {
+	*x = StreamAccountsResponse{}
+	mi := &file_account_proto_msgTypes[70]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListAccountsResponse) String() string {
+func (x *StreamAccountsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListAccountsResponse) ProtoMessage() {}
+func (*StreamAccountsResponse) ProtoMessage() {}
 
-func (x *ListAccountsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_account_proto_msgTypes[12]
+func (x *StreamAccountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[70]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -706,26 +5067,265 @@ func (x *ListAccountsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListAccountsResponse.ProtoReflect.Descriptor instead.
-func (*ListAccountsResponse) Descriptor() ([]byte, []int) {
-	return file_account_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use StreamAccountsResponse.ProtoReflect.Descriptor instead.
+func (*StreamAccountsResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{70}
 }
 
-func (x *ListAccountsResponse) GetAccounts() []*Account {
+func (x *StreamAccountsResponse) GetAccounts() []*Account {
 	if x != nil {
 		return x.Accounts
 	}
 	return nil
 }
 
-func (x *ListAccountsResponse) GetTotal() int32 {
+func (x *StreamAccountsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetSLOStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSLOStatusRequest) Reset() {
+	*x = GetSLOStatusRequest{}
+	mi := &file_account_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSLOStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSLOStatusRequest) ProtoMessage() {}
+
+func (x *GetSLOStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSLOStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetSLOStatusRequest) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{71}
+}
+
+// Performance of a method over one window
+type SLOWindowStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WindowSeconds int64                  `protobuf:"varint,1,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// Calls that failed on the server's side or were slower than the latency target
+	Bad            int64   `protobuf:"varint,3,opt,name=bad,proto3" json:"bad,omitempty"`
+	SuccessPercent float64 `protobuf:"fixed64,4,opt,name=success_percent,json=successPercent,proto3" json:"success_percent,omitempty"`
+	// How fast the window spends the error budget; 1 spends exactly what the target allows
+	BurnRate      float64 `protobuf:"fixed64,5,opt,name=burn_rate,json=burnRate,proto3" json:"burn_rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SLOWindowStatus) Reset() {
+	*x = SLOWindowStatus{}
+	mi := &file_account_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SLOWindowStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SLOWindowStatus) ProtoMessage() {}
+
+func (x *SLOWindowStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SLOWindowStatus.ProtoReflect.Descriptor instead.
+func (*SLOWindowStatus) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *SLOWindowStatus) GetWindowSeconds() int64 {
+	if x != nil {
+		return x.WindowSeconds
+	}
+	return 0
+}
+
+func (x *SLOWindowStatus) GetTotal() int64 {
 	if x != nil {
 		return x.Total
 	}
 	return 0
 }
 
-func (x *ListAccountsResponse) GetError() string {
+func (x *SLOWindowStatus) GetBad() int64 {
+	if x != nil {
+		return x.Bad
+	}
+	return 0
+}
+
+func (x *SLOWindowStatus) GetSuccessPercent() float64 {
+	if x != nil {
+		return x.SuccessPercent
+	}
+	return 0
+}
+
+func (x *SLOWindowStatus) GetBurnRate() float64 {
+	if x != nil {
+		return x.BurnRate
+	}
+	return 0
+}
+
+// SLO status of a method with a configured target
+type MethodSLOStatus struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Method             string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	AvailabilityTarget float64                `protobuf:"fixed64,2,opt,name=availability_target,json=availabilityTarget,proto3" json:"availability_target,omitempty"`
+	LatencyTargetMs    int64                  `protobuf:"varint,3,opt,name=latency_target_ms,json=latencyTargetMs,proto3" json:"latency_target_ms,omitempty"`
+	// 5 minute, 1 hour and 6 hour windows
+	Windows []*SLOWindowStatus `protobuf:"bytes,4,rep,name=windows,proto3" json:"windows,omitempty"`
+	// Whether the burn rate over the longest window is at most 1
+	WithinBudget  bool `protobuf:"varint,5,opt,name=within_budget,json=withinBudget,proto3" json:"within_budget,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MethodSLOStatus) Reset() {
+	*x = MethodSLOStatus{}
+	mi := &file_account_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MethodSLOStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MethodSLOStatus) ProtoMessage() {}
+
+func (x *MethodSLOStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MethodSLOStatus.ProtoReflect.Descriptor instead.
+func (*MethodSLOStatus) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *MethodSLOStatus) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *MethodSLOStatus) GetAvailabilityTarget() float64 {
+	if x != nil {
+		return x.AvailabilityTarget
+	}
+	return 0
+}
+
+func (x *MethodSLOStatus) GetLatencyTargetMs() int64 {
+	if x != nil {
+		return x.LatencyTargetMs
+	}
+	return 0
+}
+
+func (x *MethodSLOStatus) GetWindows() []*SLOWindowStatus {
+	if x != nil {
+		return x.Windows
+	}
+	return nil
+}
+
+func (x *MethodSLOStatus) GetWithinBudget() bool {
+	if x != nil {
+		return x.WithinBudget
+	}
+	return false
+}
+
+type GetSLOStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Methods       []*MethodSLOStatus     `protobuf:"bytes,1,rep,name=methods,proto3" json:"methods,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSLOStatusResponse) Reset() {
+	*x = GetSLOStatusResponse{}
+	mi := &file_account_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSLOStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSLOStatusResponse) ProtoMessage() {}
+
+func (x *GetSLOStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_account_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSLOStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetSLOStatusResponse) Descriptor() ([]byte, []int) {
+	return file_account_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *GetSLOStatusResponse) GetMethods() []*MethodSLOStatus {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+func (x *GetSLOStatusResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
@@ -736,62 +5336,493 @@ var File_account_proto protoreflect.FileDescriptor
 
 const file_account_proto_rawDesc = "" +
 	"\n" +
-	"\raccount.proto\x12\aaccount\x1a\x1cgoogle/api/annotations.proto\"\xbd\x01\n" +
+	"\raccount.proto\x12\aaccount\x1a\x1cgoogle/api/annotations.proto\"\xec\x03\n" +
 	"\aAccount\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
 	"\x0fdocument_number\x18\x02 \x01(\tR\x0edocumentNumber\x12!\n" +
-	"\faccount_type\x18\x03 \x01(\tR\vaccountType\x12\x18\n" +
-	"\abalance\x18\x04 \x01(\x01R\abalance\x12\x1d\n" +
+	"\faccount_type\x18\x03 \x01(\tR\vaccountType\x12#\n" +
+	"\rbalance_cents\x18\x0f \x01(\x03R\fbalanceCents\x12\x1c\n" +
+	"\abalance\x18\x04 \x01(\x01B\x02\x18\x01R\abalance\x12\x1d\n" +
 	"\n" +
 	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12\x1d\n" +
 	"\n" +
-	"updated_at\x18\x06 \x01(\x03R\tupdatedAt\"\x8b\x01\n" +
+	"updated_at\x18\x06 \x01(\x03R\tupdatedAt\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\x1f\n" +
+	"\vholder_name\x18\b \x01(\tR\n" +
+	"holderName\x12!\n" +
+	"\fholder_email\x18\t \x01(\tR\vholderEmail\x12#\n" +
+	"\rkyc_reference\x18\n" +
+	" \x01(\tR\fkycReference\x12\x18\n" +
+	"\aversion\x18\v \x01(\x03R\aversion\x122\n" +
+	"\x15overdraft_limit_cents\x18\f \x01(\x03R\x13overdraftLimitCents\x12!\n" +
+	"\fholder_phone\x18\r \x01(\tR\vholderPhone\x12\x12\n" +
+	"\x04tags\x18\x0e \x03(\tR\x04tags\"\xc0\x02\n" +
 	"\x14CreateAccountRequest\x12'\n" +
 	"\x0fdocument_number\x18\x01 \x01(\tR\x0edocumentNumber\x12!\n" +
-	"\faccount_type\x18\x02 \x01(\tR\vaccountType\x12'\n" +
-	"\x0finitial_balance\x18\x03 \x01(\x01R\x0einitialBalance\"Y\n" +
+	"\faccount_type\x18\x02 \x01(\tR\vaccountType\x122\n" +
+	"\x15initial_balance_cents\x18\b \x01(\x03R\x13initialBalanceCents\x12+\n" +
+	"\x0finitial_balance\x18\x03 \x01(\x01B\x02\x18\x01R\x0einitialBalance\x12\x14\n" +
+	"\x05draft\x18\x04 \x01(\bR\x05draft\x12\x1f\n" +
+	"\vholder_name\x18\x05 \x01(\tR\n" +
+	"holderName\x12!\n" +
+	"\fholder_email\x18\x06 \x01(\tR\vholderEmail\x12!\n" +
+	"\fholder_phone\x18\a \x01(\tR\vholderPhone\"\x89\x01\n" +
 	"\x15CreateAccountResponse\x12*\n" +
 	"\aaccount\x18\x01 \x01(\v2\x10.account.AccountR\aaccount\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"#\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12.\n" +
+	"\x13existing_account_id\x18\x03 \x01(\tR\x11existingAccountId\"M\n" +
 	"\x11GetAccountRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"V\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12(\n" +
+	"\x10max_staleness_ms\x18\x02 \x01(\x03R\x0emaxStalenessMs\"V\n" +
 	"\x12GetAccountResponse\x12*\n" +
 	"\aaccount\x18\x01 \x01(\v2\x10.account.AccountR\aaccount\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"r\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"p\n" +
+	"\x1bGetAccountByDocumentRequest\x12'\n" +
+	"\x0fdocument_number\x18\x01 \x01(\tR\x0edocumentNumber\x12(\n" +
+	"\x10max_staleness_ms\x18\x02 \x01(\x03R\x0emaxStalenessMs\"`\n" +
+	"\x1cGetAccountByDocumentResponse\x12*\n" +
+	"\aaccount\x18\x01 \x01(\v2\x10.account.AccountR\aaccount\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x84\x02\n" +
 	"\x14UpdateAccountRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
 	"\x0fdocument_number\x18\x02 \x01(\tR\x0edocumentNumber\x12!\n" +
-	"\faccount_type\x18\x03 \x01(\tR\vaccountType\"Y\n" +
+	"\faccount_type\x18\x03 \x01(\tR\vaccountType\x12)\n" +
+	"\x10expected_version\x18\x04 \x01(\x03R\x0fexpectedVersion\x12\x1f\n" +
+	"\vholder_name\x18\x05 \x01(\tR\n" +
+	"holderName\x12!\n" +
+	"\fholder_email\x18\x06 \x01(\tR\vholderEmail\x12!\n" +
+	"\fholder_phone\x18\a \x01(\tR\vholderPhone\"Y\n" +
 	"\x15UpdateAccountResponse\x12*\n" +
 	"\aaccount\x18\x01 \x01(\v2\x10.account.AccountR\aaccount\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\"&\n" +
 	"\x14DeleteAccountRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"G\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"h\n" +
 	"\x15DeleteAccountResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"2\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1f\n" +
+	"\vsnapshot_id\x18\x03 \x01(\tR\n" +
+	"snapshotId\"\\\n" +
 	"\x11GetBalanceRequest\x12\x1d\n" +
 	"\n" +
-	"account_id\x18\x01 \x01(\tR\taccountId\"D\n" +
-	"\x12GetBalanceResponse\x12\x18\n" +
-	"\abalance\x18\x01 \x01(\x01R\abalance\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"C\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12(\n" +
+	"\x10max_staleness_ms\x18\x02 \x01(\x03R\x0emaxStalenessMs\"m\n" +
+	"\x12GetBalanceResponse\x12#\n" +
+	"\rbalance_cents\x18\x03 \x01(\x03R\fbalanceCents\x12\x1c\n" +
+	"\abalance\x18\x01 \x01(\x01B\x02\x18\x01R\abalance\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"R\n" +
+	"\x13GetBalanceAtRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\"o\n" +
+	"\x14GetBalanceAtResponse\x12#\n" +
+	"\rbalance_cents\x18\x01 \x01(\x03R\fbalanceCents\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"]\n" +
+	"\x12GetBalancesRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12(\n" +
+	"\x10max_staleness_ms\x18\x02 \x01(\x03R\x0emaxStalenessMs\"\xf2\x01\n" +
+	"\x0fCurrencyBalance\x12\x1a\n" +
+	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12#\n" +
+	"\rbalance_cents\x18\x05 \x01(\x03R\fbalanceCents\x12\x1c\n" +
+	"\abalance\x18\x02 \x01(\x01B\x02\x18\x01R\abalance\x12\x1d\n" +
+	"\n" +
+	"held_cents\x18\x06 \x01(\x03R\theldCents\x12\x16\n" +
+	"\x04held\x18\x03 \x01(\x01B\x02\x18\x01R\x04held\x12'\n" +
+	"\x0favailable_cents\x18\a \x01(\x03R\x0eavailableCents\x12 \n" +
+	"\tavailable\x18\x04 \x01(\x01B\x02\x18\x01R\tavailable\"\x9a\x01\n" +
+	"\x12CreditAvailability\x12\x1f\n" +
+	"\vlimit_cents\x18\x03 \x01(\x03R\n" +
+	"limitCents\x12\x18\n" +
+	"\x05limit\x18\x01 \x01(\x01B\x02\x18\x01R\x05limit\x12'\n" +
+	"\x0favailable_cents\x18\x04 \x01(\x03R\x0eavailableCents\x12 \n" +
+	"\tavailable\x18\x02 \x01(\x01B\x02\x18\x01R\tavailable\"\xb5\x01\n" +
+	"\x13GetBalancesResponse\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x124\n" +
+	"\bbalances\x18\x02 \x03(\v2\x18.account.CurrencyBalanceR\bbalances\x123\n" +
+	"\x06credit\x18\x03 \x01(\v2\x1b.account.CreditAvailabilityR\x06credit\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\xbe\x03\n" +
 	"\x13ListAccountsRequest\x12\x14\n" +
 	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
-	"\x06offset\x18\x02 \x01(\x05R\x06offset\"p\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\x12!\n" +
+	"\fcreated_from\x18\x03 \x01(\x03R\vcreatedFrom\x12\x1d\n" +
+	"\n" +
+	"created_to\x18\x04 \x01(\x03R\tcreatedTo\x12/\n" +
+	"\x11min_balance_cents\x18\t \x01(\x03H\x00R\x0fminBalanceCents\x88\x01\x01\x12(\n" +
+	"\vmin_balance\x18\x05 \x01(\x01B\x02\x18\x01H\x01R\n" +
+	"minBalance\x88\x01\x01\x12/\n" +
+	"\x11max_balance_cents\x18\n" +
+	" \x01(\x03H\x02R\x0fmaxBalanceCents\x88\x01\x01\x12(\n" +
+	"\vmax_balance\x18\x06 \x01(\x01B\x02\x18\x01H\x03R\n" +
+	"maxBalance\x88\x01\x01\x12\x12\n" +
+	"\x04tags\x18\a \x03(\tR\x04tags\x12!\n" +
+	"\fexclude_tags\x18\b \x03(\tR\vexcludeTagsB\x14\n" +
+	"\x12_min_balance_centsB\x0e\n" +
+	"\f_min_balanceB\x14\n" +
+	"\x12_max_balance_centsB\x0e\n" +
+	"\f_max_balance\"p\n" +
 	"\x14ListAccountsResponse\x12,\n" +
 	"\baccounts\x18\x01 \x03(\v2\x10.account.AccountR\baccounts\x12\x14\n" +
 	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error2\xa1\x05\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"n\n" +
+	"\x15CreateAccountsFailure\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12'\n" +
+	"\x0fdocument_number\x18\x02 \x01(\tR\x0edocumentNumber\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x8e\x01\n" +
+	"\x16CreateAccountsResponse\x12\x18\n" +
+	"\acreated\x18\x01 \x01(\x05R\acreated\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\x02 \x01(\x05R\n" +
+	"duplicates\x12:\n" +
+	"\bfailures\x18\x03 \x03(\v2\x1e.account.CreateAccountsFailureR\bfailures\"Y\n" +
+	"\x15SearchAccountsRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05match\x18\x02 \x01(\tR\x05match\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\\\n" +
+	"\x16SearchAccountsResponse\x12,\n" +
+	"\baccounts\x18\x01 \x03(\v2\x10.account.AccountR\baccounts\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"^\n" +
+	"\aFeeRule\x12\x1f\n" +
+	"\vfixed_cents\x18\x03 \x01(\x03R\n" +
+	"fixedCents\x12\x18\n" +
+	"\x05fixed\x18\x01 \x01(\x01B\x02\x18\x01R\x05fixed\x12\x18\n" +
+	"\apercent\x18\x02 \x01(\x01R\apercent\"\xcf\x04\n" +
+	"\x0eTenantSettings\x12\x1a\n" +
+	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x126\n" +
+	"\x17allowed_operation_types\x18\x02 \x03(\tR\x15allowedOperationTypes\x12?\n" +
+	"\x1cmax_transaction_amount_cents\x18\n" +
+	" \x01(\x03R\x19maxTransactionAmountCents\x128\n" +
+	"\x16max_transaction_amount\x18\x03 \x01(\x01B\x02\x18\x01R\x14maxTransactionAmount\x12K\n" +
+	"\ffee_schedule\x18\x04 \x03(\v2(.account.TenantSettings.FeeScheduleEntryR\vfeeSchedule\x12%\n" +
+	"\x0ebalance_source\x18\x05 \x01(\tR\rbalanceSource\x128\n" +
+	"\tretention\x18\x06 \x01(\v2\x1a.account.RetentionSettingsR\tretention\x12-\n" +
+	"\x12reporting_currency\x18\a \x01(\tR\x11reportingCurrency\x12\x18\n" +
+	"\asandbox\x18\b \x01(\bR\asandbox\x12%\n" +
+	"\x0edocument_types\x18\t \x03(\tR\rdocumentTypes\x1aP\n" +
+	"\x10FeeScheduleEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12&\n" +
+	"\x05value\x18\x02 \x01(\v2\x10.account.FeeRuleR\x05value:\x028\x01\"\x81\x01\n" +
+	"\x11RetentionSettings\x12)\n" +
+	"\x10transaction_days\x18\x01 \x01(\x05R\x0ftransactionDays\x12A\n" +
+	"\x1danonymize_closed_account_days\x18\x02 \x01(\x05R\x1aanonymizeClosedAccountDays\"7\n" +
+	"\x18GetTenantSettingsRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\"\x88\x01\n" +
+	"\x19GetTenantSettingsResponse\x123\n" +
+	"\bsettings\x18\x01 \x01(\v2\x17.account.TenantSettingsR\bsettings\x12 \n" +
+	"\venvironment\x18\x02 \x01(\tR\venvironment\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"o\n" +
+	"\x1bUpdateTenantSettingsRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x123\n" +
+	"\bsettings\x18\x02 \x01(\v2\x17.account.TenantSettingsR\bsettings\"\x8b\x01\n" +
+	"\x1cUpdateTenantSettingsResponse\x123\n" +
+	"\bsettings\x18\x01 \x01(\v2\x17.account.TenantSettingsR\bsettings\x12 \n" +
+	"\venvironment\x18\x02 \x01(\tR\venvironment\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xd9\x03\n" +
+	"\x11BalanceAdjustment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12\x1c\n" +
+	"\tdirection\x18\x03 \x01(\tR\tdirection\x12!\n" +
+	"\famount_cents\x18\x0f \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\x06amount\x18\x04 \x01(\x01B\x02\x18\x01R\x06amount\x12\x1f\n" +
+	"\vreason_code\x18\x05 \x01(\tR\n" +
+	"reasonCode\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12!\n" +
+	"\frequested_by\x18\b \x01(\tR\vrequestedBy\x12!\n" +
+	"\frequested_at\x18\t \x01(\x03R\vrequestedAt\x12\x1f\n" +
+	"\vreviewed_by\x18\n" +
+	" \x01(\tR\n" +
+	"reviewedBy\x12\x1f\n" +
+	"\vreviewed_at\x18\v \x01(\x03R\n" +
+	"reviewedAt\x12\x1f\n" +
+	"\vreview_note\x18\f \x01(\tR\n" +
+	"reviewNote\x12\x16\n" +
+	"\x06source\x18\r \x01(\tR\x06source\x12\x1c\n" +
+	"\treference\x18\x0e \x01(\tR\treference\"\xe0\x01\n" +
+	"\x1fRequestBalanceAdjustmentRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1c\n" +
+	"\tdirection\x18\x02 \x01(\tR\tdirection\x12!\n" +
+	"\famount_cents\x18\x06 \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\x06amount\x18\x03 \x01(\x01B\x02\x18\x01R\x06amount\x12\x1f\n" +
+	"\vreason_code\x18\x04 \x01(\tR\n" +
+	"reasonCode\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\"\xf3\x01\n" +
+	"\x14AdjustBalanceRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1c\n" +
+	"\tdirection\x18\x02 \x01(\tR\tdirection\x12!\n" +
+	"\famount_cents\x18\a \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\x06amount\x18\x03 \x01(\x01B\x02\x18\x01R\x06amount\x12\x1f\n" +
+	"\vreason_code\x18\x04 \x01(\tR\n" +
+	"reasonCode\x12\x1c\n" +
+	"\treference\x18\x05 \x01(\tR\treference\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\"^\n" +
+	"\x1eReviewBalanceAdjustmentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aapprove\x18\x02 \x01(\bR\aapprove\x12\x12\n" +
+	"\x04note\x18\x03 \x01(\tR\x04note\"m\n" +
+	"\x19BalanceAdjustmentResponse\x12:\n" +
+	"\n" +
+	"adjustment\x18\x01 \x01(\v2\x1a.account.BalanceAdjustmentR\n" +
+	"adjustment\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"V\n" +
+	"\x1dListBalanceAdjustmentsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"t\n" +
+	"\x1eListBalanceAdjustmentsResponse\x12<\n" +
+	"\vadjustments\x18\x01 \x03(\v2\x1a.account.BalanceAdjustmentR\vadjustments\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xc7\x01\n" +
+	"\x1aUpdateAccountHolderRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1f\n" +
+	"\vholder_name\x18\x02 \x01(\tR\n" +
+	"holderName\x12!\n" +
+	"\fholder_email\x18\x03 \x01(\tR\vholderEmail\x12#\n" +
+	"\rkyc_reference\x18\x04 \x01(\tR\fkycReference\x12!\n" +
+	"\fholder_phone\x18\x05 \x01(\tR\vholderPhone\"_\n" +
+	"\x1bUpdateAccountHolderResponse\x12*\n" +
+	"\aaccount\x18\x01 \x01(\v2\x10.account.AccountR\aaccount\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"m\n" +
+	"\x18SetOverdraftLimitRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x122\n" +
+	"\x15overdraft_limit_cents\x18\x02 \x01(\x03R\x13overdraftLimitCents\"]\n" +
+	"\x19SetOverdraftLimitResponse\x12*\n" +
+	"\aaccount\x18\x01 \x01(\v2\x10.account.AccountR\aaccount\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"c\n" +
+	"\x18UpdateAccountTagsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x10\n" +
+	"\x03add\x18\x02 \x03(\tR\x03add\x12\x16\n" +
+	"\x06remove\x18\x03 \x03(\tR\x06remove\"]\n" +
+	"\x19UpdateAccountTagsResponse\x12*\n" +
+	"\aaccount\x18\x01 \x01(\v2\x10.account.AccountR\aaccount\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"Q\n" +
+	"\x18AdvanceOnboardingRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"]\n" +
+	"\x19AdvanceOnboardingResponse\x12*\n" +
+	"\aaccount\x18\x01 \x01(\v2\x10.account.AccountR\aaccount\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x95\x02\n" +
+	"\x0eAccessDecision\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\aservice\x18\x02 \x01(\tR\aservice\x12\x16\n" +
+	"\x06method\x18\x03 \x01(\tR\x06method\x12\x1c\n" +
+	"\tprincipal\x18\x04 \x01(\tR\tprincipal\x12\x12\n" +
+	"\x04role\x18\x05 \x01(\tR\x04role\x12\x1b\n" +
+	"\ttenant_id\x18\x06 \x01(\tR\btenantId\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\a \x01(\tR\trequestId\x12\x1a\n" +
+	"\bdecision\x18\b \x01(\tR\bdecision\x12\x16\n" +
+	"\x06reason\x18\t \x01(\tR\x06reason\x12\x1f\n" +
+	"\voccurred_at\x18\n" +
+	" \x01(\x03R\n" +
+	"occurredAt\"\xc5\x01\n" +
+	"\x1aListAccessDecisionsRequest\x12\x1c\n" +
+	"\tprincipal\x18\x01 \x01(\tR\tprincipal\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12\x1a\n" +
+	"\bdecision\x18\x03 \x01(\tR\bdecision\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\x03R\x02to\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\x12\x1b\n" +
+	"\tbefore_id\x18\a \x01(\x03R\bbeforeId\"\x90\x01\n" +
+	"\x1bListAccessDecisionsResponse\x125\n" +
+	"\tdecisions\x18\x01 \x03(\v2\x17.account.AccessDecisionR\tdecisions\x12$\n" +
+	"\x0enext_before_id\x18\x02 \x01(\x03R\fnextBeforeId\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xce\x03\n" +
+	"\rFxRevaluation\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12#\n" +
+	"\rbalance_cents\x18\b \x01(\x03R\fbalanceCents\x12\x1c\n" +
+	"\abalance\x18\x02 \x01(\x01B\x02\x18\x01R\abalance\x12!\n" +
+	"\fclosing_rate\x18\x03 \x01(\x01R\vclosingRate\x120\n" +
+	"\x14carrying_value_cents\x18\t \x01(\x03R\x12carryingValueCents\x12)\n" +
+	"\x0ecarrying_value\x18\x04 \x01(\x01B\x02\x18\x01R\rcarryingValue\x120\n" +
+	"\x14revalued_value_cents\x18\n" +
+	" \x01(\x03R\x12revaluedValueCents\x12)\n" +
+	"\x0erevalued_value\x18\x05 \x01(\x01B\x02\x18\x01R\rrevaluedValue\x122\n" +
+	"\x15unrealized_gain_cents\x18\v \x01(\x03R\x13unrealizedGainCents\x12+\n" +
+	"\x0funrealized_gain\x18\x06 \x01(\x01B\x02\x18\x01R\x0eunrealizedGain\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\"T\n" +
+	"\x1dGetFxRevaluationReportRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x16\n" +
+	"\x06period\x18\x02 \x01(\tR\x06period\"\xe9\x02\n" +
+	"\x1eGetFxRevaluationReportResponse\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x16\n" +
+	"\x06period\x18\x02 \x01(\tR\x06period\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12-\n" +
+	"\x12reporting_currency\x18\x04 \x01(\tR\x11reportingCurrency\x12:\n" +
+	"\frevaluations\x18\x05 \x03(\v2\x16.account.FxRevaluationR\frevaluations\x12=\n" +
+	"\x1btotal_unrealized_gain_cents\x18\b \x01(\x03R\x18totalUnrealizedGainCents\x126\n" +
+	"\x15total_unrealized_gain\x18\x06 \x01(\x01B\x02\x18\x01R\x13totalUnrealizedGain\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\xfc\x03\n" +
+	"\x0eDocumentChange\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x128\n" +
+	"\x18previous_document_number\x18\x03 \x01(\tR\x16previousDocumentNumber\x12'\n" +
+	"\x0fdocument_number\x18\x04 \x01(\tR\x0edocumentNumber\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\x12!\n" +
+	"\frequested_by\x18\a \x01(\tR\vrequestedBy\x12!\n" +
+	"\frequested_at\x18\b \x01(\x03R\vrequestedAt\x125\n" +
+	"\x16verification_reference\x18\t \x01(\tR\x15verificationReference\x12\x1f\n" +
+	"\vverified_by\x18\n" +
+	" \x01(\tR\n" +
+	"verifiedBy\x12\x1f\n" +
+	"\vverified_at\x18\v \x01(\x03R\n" +
+	"verifiedAt\x12+\n" +
+	"\x11verification_note\x18\f \x01(\tR\x10verificationNote\x12\x1d\n" +
+	"\n" +
+	"applied_by\x18\r \x01(\tR\tappliedBy\x12\x1d\n" +
+	"\n" +
+	"applied_at\x18\x0e \x01(\x03R\tappliedAt\"~\n" +
+	"\x1cRequestDocumentChangeRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12'\n" +
+	"\x0fdocument_number\x18\x02 \x01(\tR\x0edocumentNumber\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x92\x01\n" +
+	"\x1bVerifyDocumentChangeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aapprove\x18\x02 \x01(\bR\aapprove\x125\n" +
+	"\x16verification_reference\x18\x03 \x01(\tR\x15verificationReference\x12\x12\n" +
+	"\x04note\x18\x04 \x01(\tR\x04note\",\n" +
+	"\x1aApplyDocumentChangeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"_\n" +
+	"\x16DocumentChangeResponse\x12/\n" +
+	"\x06change\x18\x01 \x01(\v2\x17.account.DocumentChangeR\x06change\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"S\n" +
+	"\x1aListDocumentChangesRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"f\n" +
+	"\x1bListDocumentChangesResponse\x121\n" +
+	"\achanges\x18\x01 \x03(\v2\x17.account.DocumentChangeR\achanges\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xcc\x02\n" +
+	"\x12AccountAuditValues\x12'\n" +
+	"\x0fdocument_number\x18\x01 \x01(\tR\x0edocumentNumber\x12!\n" +
+	"\faccount_type\x18\x02 \x01(\tR\vaccountType\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1f\n" +
+	"\vholder_name\x18\x04 \x01(\tR\n" +
+	"holderName\x12!\n" +
+	"\fholder_email\x18\x05 \x01(\tR\vholderEmail\x12!\n" +
+	"\fholder_phone\x18\x06 \x01(\tR\vholderPhone\x12#\n" +
+	"\rkyc_reference\x18\a \x01(\tR\fkycReference\x122\n" +
+	"\x15overdraft_limit_cents\x18\b \x01(\x03R\x13overdraftLimitCents\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\"\x9e\x02\n" +
+	"\x11AccountAuditEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x12\x1d\n" +
+	"\n" +
+	"actor_role\x18\x05 \x01(\tR\tactorRole\x12\x1d\n" +
+	"\n" +
+	"changed_at\x18\x06 \x01(\x03R\tchangedAt\x127\n" +
+	"\bprevious\x18\a \x01(\v2\x1b.account.AccountAuditValuesR\bprevious\x125\n" +
+	"\aupdated\x18\b \x01(\v2\x1b.account.AccountAuditValuesR\aupdated\"M\n" +
+	"\x16GetAccountAuditRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"e\n" +
+	"\x17GetAccountAuditResponse\x124\n" +
+	"\aentries\x18\x01 \x03(\v2\x1a.account.AccountAuditEntryR\aentries\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xd3\x02\n" +
+	"\x0fAccountSnapshot\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12\x1c\n" +
+	"\toperation\x18\x03 \x01(\tR\toperation\x12\x19\n" +
+	"\btaken_by\x18\x04 \x01(\tR\atakenBy\x12\"\n" +
+	"\rtaken_by_role\x18\x05 \x01(\tR\vtakenByRole\x12\x19\n" +
+	"\btaken_at\x18\x06 \x01(\x03R\atakenAt\x12*\n" +
+	"\aaccount\x18\a \x01(\v2\x10.account.AccountR\aaccount\x12+\n" +
+	"\x11transaction_count\x18\b \x01(\x05R\x10transactionCount\x12\x1f\n" +
+	"\vrestored_at\x18\t \x01(\x03R\n" +
+	"restoredAt\x12\x1f\n" +
+	"\vrestored_by\x18\n" +
+	" \x01(\tR\n" +
+	"restoredBy\"<\n" +
+	"\x1bListAccountSnapshotsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\"l\n" +
+	"\x1cListAccountSnapshotsResponse\x126\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x18.account.AccountSnapshotR\tsnapshots\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"/\n" +
+	"\x1dRestoreAccountSnapshotRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x97\x01\n" +
+	"\x1eRestoreAccountSnapshotResponse\x12*\n" +
+	"\aaccount\x18\x01 \x01(\v2\x10.account.AccountR\aaccount\x123\n" +
+	"\x15restored_transactions\x18\x02 \x01(\x05R\x14restoredTransactions\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"q\n" +
+	"\x15StreamAccountsRequest\x12!\n" +
+	"\fcreated_from\x18\x01 \x01(\x03R\vcreatedFrom\x12\x1d\n" +
+	"\n" +
+	"created_to\x18\x02 \x01(\x03R\tcreatedTo\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"\\\n" +
+	"\x16StreamAccountsResponse\x12,\n" +
+	"\baccounts\x18\x01 \x03(\v2\x10.account.AccountR\baccounts\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x15\n" +
+	"\x13GetSLOStatusRequest\"\xa6\x01\n" +
+	"\x0fSLOWindowStatus\x12%\n" +
+	"\x0ewindow_seconds\x18\x01 \x01(\x03R\rwindowSeconds\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x10\n" +
+	"\x03bad\x18\x03 \x01(\x03R\x03bad\x12'\n" +
+	"\x0fsuccess_percent\x18\x04 \x01(\x01R\x0esuccessPercent\x12\x1b\n" +
+	"\tburn_rate\x18\x05 \x01(\x01R\bburnRate\"\xdf\x01\n" +
+	"\x0fMethodSLOStatus\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12/\n" +
+	"\x13availability_target\x18\x02 \x01(\x01R\x12availabilityTarget\x12*\n" +
+	"\x11latency_target_ms\x18\x03 \x01(\x03R\x0flatencyTargetMs\x122\n" +
+	"\awindows\x18\x04 \x03(\v2\x18.account.SLOWindowStatusR\awindows\x12#\n" +
+	"\rwithin_budget\x18\x05 \x01(\bR\fwithinBudget\"`\n" +
+	"\x14GetSLOStatusResponse\x122\n" +
+	"\amethods\x18\x01 \x03(\v2\x18.account.MethodSLOStatusR\amethods\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error2\xc8\x1f\n" +
 	"\x0eAccountService\x12k\n" +
 	"\rCreateAccount\x12\x1d.account.CreateAccountRequest\x1a\x1e.account.CreateAccountResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/api/v1/accounts\x12d\n" +
 	"\n" +
-	"GetAccount\x12\x1a.account.GetAccountRequest\x1a\x1b.account.GetAccountResponse\"\x1d\x82\xd3\xe4\x93\x02\x17\x12\x15/api/v1/accounts/{id}\x12p\n" +
+	"GetAccount\x12\x1a.account.GetAccountRequest\x1a\x1b.account.GetAccountResponse\"\x1d\x82\xd3\xe4\x93\x02\x17\x12\x15/api/v1/accounts/{id}\x12\x9b\x01\n" +
+	"\x14GetAccountByDocument\x12$.account.GetAccountByDocumentRequest\x1a%.account.GetAccountByDocumentResponse\"6\x82\xd3\xe4\x93\x020\x12./api/v1/accounts/by-document/{document_number}\x12p\n" +
 	"\rUpdateAccount\x12\x1d.account.UpdateAccountRequest\x1a\x1e.account.UpdateAccountResponse\" \x82\xd3\xe4\x93\x02\x1a:\x01*\x1a\x15/api/v1/accounts/{id}\x12m\n" +
 	"\rDeleteAccount\x12\x1d.account.DeleteAccountRequest\x1a\x1e.account.DeleteAccountResponse\"\x1d\x82\xd3\xe4\x93\x02\x17*\x15/api/v1/accounts/{id}\x12t\n" +
 	"\n" +
-	"GetBalance\x12\x1a.account.GetBalanceRequest\x1a\x1b.account.GetBalanceResponse\"-\x82\xd3\xe4\x93\x02'\x12%/api/v1/accounts/{account_id}/balance\x12e\n" +
-	"\fListAccounts\x12\x1c.account.ListAccountsRequest\x1a\x1d.account.ListAccountsResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/api/v1/accountsB\vZ\t./accountb\x06proto3"
+	"GetBalance\x12\x1a.account.GetBalanceRequest\x1a\x1b.account.GetBalanceResponse\"-\x82\xd3\xe4\x93\x02'\x12%/api/v1/accounts/{account_id}/balance\x12K\n" +
+	"\fGetBalanceAt\x12\x1c.account.GetBalanceAtRequest\x1a\x1d.account.GetBalanceAtResponse\x12x\n" +
+	"\vGetBalances\x12\x1b.account.GetBalancesRequest\x1a\x1c.account.GetBalancesResponse\".\x82\xd3\xe4\x93\x02(\x12&/api/v1/accounts/{account_id}/balances\x12e\n" +
+	"\fListAccounts\x12\x1c.account.ListAccountsRequest\x1a\x1d.account.ListAccountsResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/api/v1/accounts\x12R\n" +
+	"\x0eCreateAccounts\x12\x1d.account.CreateAccountRequest\x1a\x1f.account.CreateAccountsResponse(\x01\x12r\n" +
+	"\x0eSearchAccounts\x12\x1e.account.SearchAccountsRequest\x1a\x1f.account.SearchAccountsResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/api/v1/accounts/search\x12\x91\x01\n" +
+	"\x13UpdateAccountHolder\x12#.account.UpdateAccountHolderRequest\x1a$.account.UpdateAccountHolderResponse\"/\x82\xd3\xe4\x93\x02):\x01*\x1a$/api/v1/accounts/{account_id}/holder\x12\x8f\x01\n" +
+	"\x11AdvanceOnboarding\x12!.account.AdvanceOnboardingRequest\x1a\".account.AdvanceOnboardingResponse\"3\x82\xd3\xe4\x93\x02-:\x01*\"(/api/v1/accounts/{account_id}/onboarding\x12\x8e\x01\n" +
+	"\x11SetOverdraftLimit\x12!.account.SetOverdraftLimitRequest\x1a\".account.SetOverdraftLimitResponse\"2\x82\xd3\xe4\x93\x02,:\x01*\x1a'/api/v1/accounts/{account_id}/overdraft\x12\x89\x01\n" +
+	"\x11UpdateAccountTags\x12!.account.UpdateAccountTagsRequest\x1a\".account.UpdateAccountTagsResponse\"-\x82\xd3\xe4\x93\x02':\x01*2\"/api/v1/accounts/{account_id}/tags\x12\x88\x01\n" +
+	"\x11GetTenantSettings\x12!.account.GetTenantSettingsRequest\x1a\".account.GetTenantSettingsResponse\",\x82\xd3\xe4\x93\x02&\x12$/api/v1/tenants/{tenant_id}/settings\x12\x9b\x01\n" +
+	"\x14UpdateTenantSettings\x12$.account.UpdateTenantSettingsRequest\x1a%.account.UpdateTenantSettingsResponse\"6\x82\xd3\xe4\x93\x020:\bsettings\x1a$/api/v1/tenants/{tenant_id}/settings\x12\x9e\x01\n" +
+	"\x18RequestBalanceAdjustment\x12(.account.RequestBalanceAdjustmentRequest\x1a\".account.BalanceAdjustmentResponse\"4\x82\xd3\xe4\x93\x02.:\x01*\")/api/v1/accounts/{account_id}/adjustments\x12\x92\x01\n" +
+	"\x17ReviewBalanceAdjustment\x12'.account.ReviewBalanceAdjustmentRequest\x1a\".account.BalanceAdjustmentResponse\"*\x82\xd3\xe4\x93\x02$:\x01*\"\x1f/api/v1/adjustments/{id}/review\x12\x9c\x01\n" +
+	"\x16ListBalanceAdjustments\x12&.account.ListBalanceAdjustmentsRequest\x1a'.account.ListBalanceAdjustmentsResponse\"1\x82\xd3\xe4\x93\x02+\x12)/api/v1/accounts/{account_id}/adjustments\x12\x88\x01\n" +
+	"\x13ListAccessDecisions\x12#.account.ListAccessDecisionsRequest\x1a$.account.ListAccessDecisionsResponse\"&\x82\xd3\xe4\x93\x02 \x12\x1e/api/v1/admin/access-decisions\x12n\n" +
+	"\fGetSLOStatus\x12\x1c.account.GetSLOStatusRequest\x1a\x1d.account.GetSLOStatusResponse\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/api/v1/admin/slo/account\x12\xad\x01\n" +
+	"\x16GetFxRevaluationReport\x12&.account.GetFxRevaluationReportRequest\x1a'.account.GetFxRevaluationReportResponse\"B\x82\xd3\xe4\x93\x02<\x12:/api/v1/admin/tenants/{tenant_id}/fx-revaluations/{period}\x12\x9a\x01\n" +
+	"\x15RequestDocumentChange\x12%.account.RequestDocumentChangeRequest\x1a\x1f.account.DocumentChangeResponse\"9\x82\xd3\xe4\x93\x023:\x01*\"./api/v1/accounts/{account_id}/document-changes\x12\x8e\x01\n" +
+	"\x14VerifyDocumentChange\x12$.account.VerifyDocumentChangeRequest\x1a\x1f.account.DocumentChangeResponse\"/\x82\xd3\xe4\x93\x02):\x01*\"$/api/v1/document-changes/{id}/verify\x12\x8b\x01\n" +
+	"\x13ApplyDocumentChange\x12#.account.ApplyDocumentChangeRequest\x1a\x1f.account.DocumentChangeResponse\".\x82\xd3\xe4\x93\x02(:\x01*\"#/api/v1/document-changes/{id}/apply\x12\x98\x01\n" +
+	"\x13ListDocumentChanges\x12#.account.ListDocumentChangesRequest\x1a$.account.ListDocumentChangesResponse\"6\x82\xd3\xe4\x93\x020\x12./api/v1/accounts/{account_id}/document-changes\x12\x81\x01\n" +
+	"\x0fGetAccountAudit\x12\x1f.account.GetAccountAuditRequest\x1a .account.GetAccountAuditResponse\"+\x82\xd3\xe4\x93\x02%\x12#/api/v1/accounts/{account_id}/audit\x12\x94\x01\n" +
+	"\x14ListAccountSnapshots\x12$.account.ListAccountSnapshotsRequest\x1a%.account.ListAccountSnapshotsResponse\"/\x82\xd3\xe4\x93\x02)\x12'/api/v1/accounts/{account_id}/snapshots\x12\x99\x01\n" +
+	"\x16RestoreAccountSnapshot\x12&.account.RestoreAccountSnapshotRequest\x1a'.account.RestoreAccountSnapshotResponse\".\x82\xd3\xe4\x93\x02(\"&/api/v1/account-snapshots/{id}/restore2l\n" +
+	"\x16InternalAccountService\x12R\n" +
+	"\rAdjustBalance\x12\x1d.account.AdjustBalanceRequest\x1a\".account.BalanceAdjustmentResponse2n\n" +
+	"\x17AccountAnalyticsService\x12S\n" +
+	"\x0eStreamAccounts\x12\x1e.account.StreamAccountsRequest\x1a\x1f.account.StreamAccountsResponse0\x01B\vZ\t./accountb\x06proto3"
 
 var (
 	file_account_proto_rawDescOnce sync.Once
@@ -805,44 +5836,189 @@ func file_account_proto_rawDescGZIP() []byte {
 	return file_account_proto_rawDescData
 }
 
-var file_account_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_account_proto_msgTypes = make([]protoimpl.MessageInfo, 76)
 var file_account_proto_goTypes = []any{
-	(*Account)(nil),               // 0: account.Account
-	(*CreateAccountRequest)(nil),  // 1: account.CreateAccountRequest
-	(*CreateAccountResponse)(nil), // 2: account.CreateAccountResponse
-	(*GetAccountRequest)(nil),     // 3: account.GetAccountRequest
-	(*GetAccountResponse)(nil),    // 4: account.GetAccountResponse
-	(*UpdateAccountRequest)(nil),  // 5: account.UpdateAccountRequest
-	(*UpdateAccountResponse)(nil), // 6: account.UpdateAccountResponse
-	(*DeleteAccountRequest)(nil),  // 7: account.DeleteAccountRequest
-	(*DeleteAccountResponse)(nil), // 8: account.DeleteAccountResponse
-	(*GetBalanceRequest)(nil),     // 9: account.GetBalanceRequest
-	(*GetBalanceResponse)(nil),    // 10: account.GetBalanceResponse
-	(*ListAccountsRequest)(nil),   // 11: account.ListAccountsRequest
-	(*ListAccountsResponse)(nil),  // 12: account.ListAccountsResponse
+	(*Account)(nil),                         // 0: account.Account
+	(*CreateAccountRequest)(nil),            // 1: account.CreateAccountRequest
+	(*CreateAccountResponse)(nil),           // 2: account.CreateAccountResponse
+	(*GetAccountRequest)(nil),               // 3: account.GetAccountRequest
+	(*GetAccountResponse)(nil),              // 4: account.GetAccountResponse
+	(*GetAccountByDocumentRequest)(nil),     // 5: account.GetAccountByDocumentRequest
+	(*GetAccountByDocumentResponse)(nil),    // 6: account.GetAccountByDocumentResponse
+	(*UpdateAccountRequest)(nil),            // 7: account.UpdateAccountRequest
+	(*UpdateAccountResponse)(nil),           // 8: account.UpdateAccountResponse
+	(*DeleteAccountRequest)(nil),            // 9: account.DeleteAccountRequest
+	(*DeleteAccountResponse)(nil),           // 10: account.DeleteAccountResponse
+	(*GetBalanceRequest)(nil),               // 11: account.GetBalanceRequest
+	(*GetBalanceResponse)(nil),              // 12: account.GetBalanceResponse
+	(*GetBalanceAtRequest)(nil),             // 13: account.GetBalanceAtRequest
+	(*GetBalanceAtResponse)(nil),            // 14: account.GetBalanceAtResponse
+	(*GetBalancesRequest)(nil),              // 15: account.GetBalancesRequest
+	(*CurrencyBalance)(nil),                 // 16: account.CurrencyBalance
+	(*CreditAvailability)(nil),              // 17: account.CreditAvailability
+	(*GetBalancesResponse)(nil),             // 18: account.GetBalancesResponse
+	(*ListAccountsRequest)(nil),             // 19: account.ListAccountsRequest
+	(*ListAccountsResponse)(nil),            // 20: account.ListAccountsResponse
+	(*CreateAccountsFailure)(nil),           // 21: account.CreateAccountsFailure
+	(*CreateAccountsResponse)(nil),          // 22: account.CreateAccountsResponse
+	(*SearchAccountsRequest)(nil),           // 23: account.SearchAccountsRequest
+	(*SearchAccountsResponse)(nil),          // 24: account.SearchAccountsResponse
+	(*FeeRule)(nil),                         // 25: account.FeeRule
+	(*TenantSettings)(nil),                  // 26: account.TenantSettings
+	(*RetentionSettings)(nil),               // 27: account.RetentionSettings
+	(*GetTenantSettingsRequest)(nil),        // 28: account.GetTenantSettingsRequest
+	(*GetTenantSettingsResponse)(nil),       // 29: account.GetTenantSettingsResponse
+	(*UpdateTenantSettingsRequest)(nil),     // 30: account.UpdateTenantSettingsRequest
+	(*UpdateTenantSettingsResponse)(nil),    // 31: account.UpdateTenantSettingsResponse
+	(*BalanceAdjustment)(nil),               // 32: account.BalanceAdjustment
+	(*RequestBalanceAdjustmentRequest)(nil), // 33: account.RequestBalanceAdjustmentRequest
+	(*AdjustBalanceRequest)(nil),            // 34: account.AdjustBalanceRequest
+	(*ReviewBalanceAdjustmentRequest)(nil),  // 35: account.ReviewBalanceAdjustmentRequest
+	(*BalanceAdjustmentResponse)(nil),       // 36: account.BalanceAdjustmentResponse
+	(*ListBalanceAdjustmentsRequest)(nil),   // 37: account.ListBalanceAdjustmentsRequest
+	(*ListBalanceAdjustmentsResponse)(nil),  // 38: account.ListBalanceAdjustmentsResponse
+	(*UpdateAccountHolderRequest)(nil),      // 39: account.UpdateAccountHolderRequest
+	(*UpdateAccountHolderResponse)(nil),     // 40: account.UpdateAccountHolderResponse
+	(*SetOverdraftLimitRequest)(nil),        // 41: account.SetOverdraftLimitRequest
+	(*SetOverdraftLimitResponse)(nil),       // 42: account.SetOverdraftLimitResponse
+	(*UpdateAccountTagsRequest)(nil),        // 43: account.UpdateAccountTagsRequest
+	(*UpdateAccountTagsResponse)(nil),       // 44: account.UpdateAccountTagsResponse
+	(*AdvanceOnboardingRequest)(nil),        // 45: account.AdvanceOnboardingRequest
+	(*AdvanceOnboardingResponse)(nil),       // 46: account.AdvanceOnboardingResponse
+	(*AccessDecision)(nil),                  // 47: account.AccessDecision
+	(*ListAccessDecisionsRequest)(nil),      // 48: account.ListAccessDecisionsRequest
+	(*ListAccessDecisionsResponse)(nil),     // 49: account.ListAccessDecisionsResponse
+	(*FxRevaluation)(nil),                   // 50: account.FxRevaluation
+	(*GetFxRevaluationReportRequest)(nil),   // 51: account.GetFxRevaluationReportRequest
+	(*GetFxRevaluationReportResponse)(nil),  // 52: account.GetFxRevaluationReportResponse
+	(*DocumentChange)(nil),                  // 53: account.DocumentChange
+	(*RequestDocumentChangeRequest)(nil),    // 54: account.RequestDocumentChangeRequest
+	(*VerifyDocumentChangeRequest)(nil),     // 55: account.VerifyDocumentChangeRequest
+	(*ApplyDocumentChangeRequest)(nil),      // 56: account.ApplyDocumentChangeRequest
+	(*DocumentChangeResponse)(nil),          // 57: account.DocumentChangeResponse
+	(*ListDocumentChangesRequest)(nil),      // 58: account.ListDocumentChangesRequest
+	(*ListDocumentChangesResponse)(nil),     // 59: account.ListDocumentChangesResponse
+	(*AccountAuditValues)(nil),              // 60: account.AccountAuditValues
+	(*AccountAuditEntry)(nil),               // 61: account.AccountAuditEntry
+	(*GetAccountAuditRequest)(nil),          // 62: account.GetAccountAuditRequest
+	(*GetAccountAuditResponse)(nil),         // 63: account.GetAccountAuditResponse
+	(*AccountSnapshot)(nil),                 // 64: account.AccountSnapshot
+	(*ListAccountSnapshotsRequest)(nil),     // 65: account.ListAccountSnapshotsRequest
+	(*ListAccountSnapshotsResponse)(nil),    // 66: account.ListAccountSnapshotsResponse
+	(*RestoreAccountSnapshotRequest)(nil),   // 67: account.RestoreAccountSnapshotRequest
+	(*RestoreAccountSnapshotResponse)(nil),  // 68: account.RestoreAccountSnapshotResponse
+	(*StreamAccountsRequest)(nil),           // 69: account.StreamAccountsRequest
+	(*StreamAccountsResponse)(nil),          // 70: account.StreamAccountsResponse
+	(*GetSLOStatusRequest)(nil),             // 71: account.GetSLOStatusRequest
+	(*SLOWindowStatus)(nil),                 // 72: account.SLOWindowStatus
+	(*MethodSLOStatus)(nil),                 // 73: account.MethodSLOStatus
+	(*GetSLOStatusResponse)(nil),            // 74: account.GetSLOStatusResponse
+	nil,                                     // 75: account.TenantSettings.FeeScheduleEntry
 }
 var file_account_proto_depIdxs = []int32{
 	0,  // 0: account.CreateAccountResponse.account:type_name -> account.Account
 	0,  // 1: account.GetAccountResponse.account:type_name -> account.Account
-	0,  // 2: account.UpdateAccountResponse.account:type_name -> account.Account
-	0,  // 3: account.ListAccountsResponse.accounts:type_name -> account.Account
-	1,  // 4: account.AccountService.CreateAccount:input_type -> account.CreateAccountRequest
-	3,  // 5: account.AccountService.GetAccount:input_type -> account.GetAccountRequest
-	5,  // 6: account.AccountService.UpdateAccount:input_type -> account.UpdateAccountRequest
-	7,  // 7: account.AccountService.DeleteAccount:input_type -> account.DeleteAccountRequest
-	9,  // 8: account.AccountService.GetBalance:input_type -> account.GetBalanceRequest
-	11, // 9: account.AccountService.ListAccounts:input_type -> account.ListAccountsRequest
-	2,  // 10: account.AccountService.CreateAccount:output_type -> account.CreateAccountResponse
-	4,  // 11: account.AccountService.GetAccount:output_type -> account.GetAccountResponse
-	6,  // 12: account.AccountService.UpdateAccount:output_type -> account.UpdateAccountResponse
-	8,  // 13: account.AccountService.DeleteAccount:output_type -> account.DeleteAccountResponse
-	10, // 14: account.AccountService.GetBalance:output_type -> account.GetBalanceResponse
-	12, // 15: account.AccountService.ListAccounts:output_type -> account.ListAccountsResponse
-	10, // [10:16] is the sub-list for method output_type
-	4,  // [4:10] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	0,  // 2: account.GetAccountByDocumentResponse.account:type_name -> account.Account
+	0,  // 3: account.UpdateAccountResponse.account:type_name -> account.Account
+	16, // 4: account.GetBalancesResponse.balances:type_name -> account.CurrencyBalance
+	17, // 5: account.GetBalancesResponse.credit:type_name -> account.CreditAvailability
+	0,  // 6: account.ListAccountsResponse.accounts:type_name -> account.Account
+	21, // 7: account.CreateAccountsResponse.failures:type_name -> account.CreateAccountsFailure
+	0,  // 8: account.SearchAccountsResponse.accounts:type_name -> account.Account
+	75, // 9: account.TenantSettings.fee_schedule:type_name -> account.TenantSettings.FeeScheduleEntry
+	27, // 10: account.TenantSettings.retention:type_name -> account.RetentionSettings
+	26, // 11: account.GetTenantSettingsResponse.settings:type_name -> account.TenantSettings
+	26, // 12: account.UpdateTenantSettingsRequest.settings:type_name -> account.TenantSettings
+	26, // 13: account.UpdateTenantSettingsResponse.settings:type_name -> account.TenantSettings
+	32, // 14: account.BalanceAdjustmentResponse.adjustment:type_name -> account.BalanceAdjustment
+	32, // 15: account.ListBalanceAdjustmentsResponse.adjustments:type_name -> account.BalanceAdjustment
+	0,  // 16: account.UpdateAccountHolderResponse.account:type_name -> account.Account
+	0,  // 17: account.SetOverdraftLimitResponse.account:type_name -> account.Account
+	0,  // 18: account.UpdateAccountTagsResponse.account:type_name -> account.Account
+	0,  // 19: account.AdvanceOnboardingResponse.account:type_name -> account.Account
+	47, // 20: account.ListAccessDecisionsResponse.decisions:type_name -> account.AccessDecision
+	50, // 21: account.GetFxRevaluationReportResponse.revaluations:type_name -> account.FxRevaluation
+	53, // 22: account.DocumentChangeResponse.change:type_name -> account.DocumentChange
+	53, // 23: account.ListDocumentChangesResponse.changes:type_name -> account.DocumentChange
+	60, // 24: account.AccountAuditEntry.previous:type_name -> account.AccountAuditValues
+	60, // 25: account.AccountAuditEntry.updated:type_name -> account.AccountAuditValues
+	61, // 26: account.GetAccountAuditResponse.entries:type_name -> account.AccountAuditEntry
+	0,  // 27: account.AccountSnapshot.account:type_name -> account.Account
+	64, // 28: account.ListAccountSnapshotsResponse.snapshots:type_name -> account.AccountSnapshot
+	0,  // 29: account.RestoreAccountSnapshotResponse.account:type_name -> account.Account
+	0,  // 30: account.StreamAccountsResponse.accounts:type_name -> account.Account
+	72, // 31: account.MethodSLOStatus.windows:type_name -> account.SLOWindowStatus
+	73, // 32: account.GetSLOStatusResponse.methods:type_name -> account.MethodSLOStatus
+	25, // 33: account.TenantSettings.FeeScheduleEntry.value:type_name -> account.FeeRule
+	1,  // 34: account.AccountService.CreateAccount:input_type -> account.CreateAccountRequest
+	3,  // 35: account.AccountService.GetAccount:input_type -> account.GetAccountRequest
+	5,  // 36: account.AccountService.GetAccountByDocument:input_type -> account.GetAccountByDocumentRequest
+	7,  // 37: account.AccountService.UpdateAccount:input_type -> account.UpdateAccountRequest
+	9,  // 38: account.AccountService.DeleteAccount:input_type -> account.DeleteAccountRequest
+	11, // 39: account.AccountService.GetBalance:input_type -> account.GetBalanceRequest
+	13, // 40: account.AccountService.GetBalanceAt:input_type -> account.GetBalanceAtRequest
+	15, // 41: account.AccountService.GetBalances:input_type -> account.GetBalancesRequest
+	19, // 42: account.AccountService.ListAccounts:input_type -> account.ListAccountsRequest
+	1,  // 43: account.AccountService.CreateAccounts:input_type -> account.CreateAccountRequest
+	23, // 44: account.AccountService.SearchAccounts:input_type -> account.SearchAccountsRequest
+	39, // 45: account.AccountService.UpdateAccountHolder:input_type -> account.UpdateAccountHolderRequest
+	45, // 46: account.AccountService.AdvanceOnboarding:input_type -> account.AdvanceOnboardingRequest
+	41, // 47: account.AccountService.SetOverdraftLimit:input_type -> account.SetOverdraftLimitRequest
+	43, // 48: account.AccountService.UpdateAccountTags:input_type -> account.UpdateAccountTagsRequest
+	28, // 49: account.AccountService.GetTenantSettings:input_type -> account.GetTenantSettingsRequest
+	30, // 50: account.AccountService.UpdateTenantSettings:input_type -> account.UpdateTenantSettingsRequest
+	33, // 51: account.AccountService.RequestBalanceAdjustment:input_type -> account.RequestBalanceAdjustmentRequest
+	35, // 52: account.AccountService.ReviewBalanceAdjustment:input_type -> account.ReviewBalanceAdjustmentRequest
+	37, // 53: account.AccountService.ListBalanceAdjustments:input_type -> account.ListBalanceAdjustmentsRequest
+	48, // 54: account.AccountService.ListAccessDecisions:input_type -> account.ListAccessDecisionsRequest
+	71, // 55: account.AccountService.GetSLOStatus:input_type -> account.GetSLOStatusRequest
+	51, // 56: account.AccountService.GetFxRevaluationReport:input_type -> account.GetFxRevaluationReportRequest
+	54, // 57: account.AccountService.RequestDocumentChange:input_type -> account.RequestDocumentChangeRequest
+	55, // 58: account.AccountService.VerifyDocumentChange:input_type -> account.VerifyDocumentChangeRequest
+	56, // 59: account.AccountService.ApplyDocumentChange:input_type -> account.ApplyDocumentChangeRequest
+	58, // 60: account.AccountService.ListDocumentChanges:input_type -> account.ListDocumentChangesRequest
+	62, // 61: account.AccountService.GetAccountAudit:input_type -> account.GetAccountAuditRequest
+	65, // 62: account.AccountService.ListAccountSnapshots:input_type -> account.ListAccountSnapshotsRequest
+	67, // 63: account.AccountService.RestoreAccountSnapshot:input_type -> account.RestoreAccountSnapshotRequest
+	34, // 64: account.InternalAccountService.AdjustBalance:input_type -> account.AdjustBalanceRequest
+	69, // 65: account.AccountAnalyticsService.StreamAccounts:input_type -> account.StreamAccountsRequest
+	2,  // 66: account.AccountService.CreateAccount:output_type -> account.CreateAccountResponse
+	4,  // 67: account.AccountService.GetAccount:output_type -> account.GetAccountResponse
+	6,  // 68: account.AccountService.GetAccountByDocument:output_type -> account.GetAccountByDocumentResponse
+	8,  // 69: account.AccountService.UpdateAccount:output_type -> account.UpdateAccountResponse
+	10, // 70: account.AccountService.DeleteAccount:output_type -> account.DeleteAccountResponse
+	12, // 71: account.AccountService.GetBalance:output_type -> account.GetBalanceResponse
+	14, // 72: account.AccountService.GetBalanceAt:output_type -> account.GetBalanceAtResponse
+	18, // 73: account.AccountService.GetBalances:output_type -> account.GetBalancesResponse
+	20, // 74: account.AccountService.ListAccounts:output_type -> account.ListAccountsResponse
+	22, // 75: account.AccountService.CreateAccounts:output_type -> account.CreateAccountsResponse
+	24, // 76: account.AccountService.SearchAccounts:output_type -> account.SearchAccountsResponse
+	40, // 77: account.AccountService.UpdateAccountHolder:output_type -> account.UpdateAccountHolderResponse
+	46, // 78: account.AccountService.AdvanceOnboarding:output_type -> account.AdvanceOnboardingResponse
+	42, // 79: account.AccountService.SetOverdraftLimit:output_type -> account.SetOverdraftLimitResponse
+	44, // 80: account.AccountService.UpdateAccountTags:output_type -> account.UpdateAccountTagsResponse
+	29, // 81: account.AccountService.GetTenantSettings:output_type -> account.GetTenantSettingsResponse
+	31, // 82: account.AccountService.UpdateTenantSettings:output_type -> account.UpdateTenantSettingsResponse
+	36, // 83: account.AccountService.RequestBalanceAdjustment:output_type -> account.BalanceAdjustmentResponse
+	36, // 84: account.AccountService.ReviewBalanceAdjustment:output_type -> account.BalanceAdjustmentResponse
+	38, // 85: account.AccountService.ListBalanceAdjustments:output_type -> account.ListBalanceAdjustmentsResponse
+	49, // 86: account.AccountService.ListAccessDecisions:output_type -> account.ListAccessDecisionsResponse
+	74, // 87: account.AccountService.GetSLOStatus:output_type -> account.GetSLOStatusResponse
+	52, // 88: account.AccountService.GetFxRevaluationReport:output_type -> account.GetFxRevaluationReportResponse
+	57, // 89: account.AccountService.RequestDocumentChange:output_type -> account.DocumentChangeResponse
+	57, // 90: account.AccountService.VerifyDocumentChange:output_type -> account.DocumentChangeResponse
+	57, // 91: account.AccountService.ApplyDocumentChange:output_type -> account.DocumentChangeResponse
+	59, // 92: account.AccountService.ListDocumentChanges:output_type -> account.ListDocumentChangesResponse
+	63, // 93: account.AccountService.GetAccountAudit:output_type -> account.GetAccountAuditResponse
+	66, // 94: account.AccountService.ListAccountSnapshots:output_type -> account.ListAccountSnapshotsResponse
+	68, // 95: account.AccountService.RestoreAccountSnapshot:output_type -> account.RestoreAccountSnapshotResponse
+	36, // 96: account.InternalAccountService.AdjustBalance:output_type -> account.BalanceAdjustmentResponse
+	70, // 97: account.AccountAnalyticsService.StreamAccounts:output_type -> account.StreamAccountsResponse
+	66, // [66:98] is the sub-list for method output_type
+	34, // [34:66] is the sub-list for method input_type
+	34, // [34:34] is the sub-list for extension type_name
+	34, // [34:34] is the sub-list for extension extendee
+	0,  // [0:34] is the sub-list for field type_name
 }
 
 func init() { file_account_proto_init() }
@@ -850,15 +6026,16 @@ func file_account_proto_init() {
 	if File_account_proto != nil {
 		return
 	}
+	file_account_proto_msgTypes[19].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_account_proto_rawDesc), len(file_account_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   76,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   3,
 		},
 		GoTypes:           file_account_proto_goTypes,
 		DependencyIndexes: file_account_proto_depIdxs,
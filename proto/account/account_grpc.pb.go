@@ -19,12 +19,36 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AccountService_CreateAccount_FullMethodName = "/account.AccountService/CreateAccount"
-	AccountService_GetAccount_FullMethodName    = "/account.AccountService/GetAccount"
-	AccountService_UpdateAccount_FullMethodName = "/account.AccountService/UpdateAccount"
-	AccountService_DeleteAccount_FullMethodName = "/account.AccountService/DeleteAccount"
-	AccountService_GetBalance_FullMethodName    = "/account.AccountService/GetBalance"
-	AccountService_ListAccounts_FullMethodName  = "/account.AccountService/ListAccounts"
+	AccountService_CreateAccount_FullMethodName            = "/account.AccountService/CreateAccount"
+	AccountService_GetAccount_FullMethodName               = "/account.AccountService/GetAccount"
+	AccountService_GetAccountByDocument_FullMethodName     = "/account.AccountService/GetAccountByDocument"
+	AccountService_UpdateAccount_FullMethodName            = "/account.AccountService/UpdateAccount"
+	AccountService_DeleteAccount_FullMethodName            = "/account.AccountService/DeleteAccount"
+	AccountService_GetBalance_FullMethodName               = "/account.AccountService/GetBalance"
+	AccountService_GetBalanceAt_FullMethodName             = "/account.AccountService/GetBalanceAt"
+	AccountService_GetBalances_FullMethodName              = "/account.AccountService/GetBalances"
+	AccountService_ListAccounts_FullMethodName             = "/account.AccountService/ListAccounts"
+	AccountService_CreateAccounts_FullMethodName           = "/account.AccountService/CreateAccounts"
+	AccountService_SearchAccounts_FullMethodName           = "/account.AccountService/SearchAccounts"
+	AccountService_UpdateAccountHolder_FullMethodName      = "/account.AccountService/UpdateAccountHolder"
+	AccountService_AdvanceOnboarding_FullMethodName        = "/account.AccountService/AdvanceOnboarding"
+	AccountService_SetOverdraftLimit_FullMethodName        = "/account.AccountService/SetOverdraftLimit"
+	AccountService_UpdateAccountTags_FullMethodName        = "/account.AccountService/UpdateAccountTags"
+	AccountService_GetTenantSettings_FullMethodName        = "/account.AccountService/GetTenantSettings"
+	AccountService_UpdateTenantSettings_FullMethodName     = "/account.AccountService/UpdateTenantSettings"
+	AccountService_RequestBalanceAdjustment_FullMethodName = "/account.AccountService/RequestBalanceAdjustment"
+	AccountService_ReviewBalanceAdjustment_FullMethodName  = "/account.AccountService/ReviewBalanceAdjustment"
+	AccountService_ListBalanceAdjustments_FullMethodName   = "/account.AccountService/ListBalanceAdjustments"
+	AccountService_ListAccessDecisions_FullMethodName      = "/account.AccountService/ListAccessDecisions"
+	AccountService_GetSLOStatus_FullMethodName             = "/account.AccountService/GetSLOStatus"
+	AccountService_GetFxRevaluationReport_FullMethodName   = "/account.AccountService/GetFxRevaluationReport"
+	AccountService_RequestDocumentChange_FullMethodName    = "/account.AccountService/RequestDocumentChange"
+	AccountService_VerifyDocumentChange_FullMethodName     = "/account.AccountService/VerifyDocumentChange"
+	AccountService_ApplyDocumentChange_FullMethodName      = "/account.AccountService/ApplyDocumentChange"
+	AccountService_ListDocumentChanges_FullMethodName      = "/account.AccountService/ListDocumentChanges"
+	AccountService_GetAccountAudit_FullMethodName          = "/account.AccountService/GetAccountAudit"
+	AccountService_ListAccountSnapshots_FullMethodName     = "/account.AccountService/ListAccountSnapshots"
+	AccountService_RestoreAccountSnapshot_FullMethodName   = "/account.AccountService/RestoreAccountSnapshot"
 )
 
 // AccountServiceClient is the client API for AccountService service.
@@ -35,10 +59,51 @@ const (
 type AccountServiceClient interface {
 	CreateAccount(ctx context.Context, in *CreateAccountRequest, opts ...grpc.CallOption) (*CreateAccountResponse, error)
 	GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*GetAccountResponse, error)
+	// Lookup by the holder's document number, which is unique across accounts
+	GetAccountByDocument(ctx context.Context, in *GetAccountByDocumentRequest, opts ...grpc.CallOption) (*GetAccountByDocumentResponse, error)
 	UpdateAccount(ctx context.Context, in *UpdateAccountRequest, opts ...grpc.CallOption) (*UpdateAccountResponse, error)
 	DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*DeleteAccountResponse, error)
 	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error)
+	// Balance of an account at a past point in time, derived from its ledger
+	GetBalanceAt(ctx context.Context, in *GetBalanceAtRequest, opts ...grpc.CallOption) (*GetBalanceAtResponse, error)
+	GetBalances(ctx context.Context, in *GetBalancesRequest, opts ...grpc.CallOption) (*GetBalancesResponse, error)
 	ListAccounts(ctx context.Context, in *ListAccountsRequest, opts ...grpc.CallOption) (*ListAccountsResponse, error)
+	// Bulk account creation for migration tooling
+	CreateAccounts(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[CreateAccountRequest, CreateAccountsResponse], error)
+	// Partial document number search for support tooling
+	SearchAccounts(ctx context.Context, in *SearchAccountsRequest, opts ...grpc.CallOption) (*SearchAccountsResponse, error)
+	// Per-tenant (issuer) settings for the service's environment
+	// Holder and KYC data can only be changed while the account is a DRAFT
+	UpdateAccountHolder(ctx context.Context, in *UpdateAccountHolderRequest, opts ...grpc.CallOption) (*UpdateAccountHolderResponse, error)
+	// Moves an account along the onboarding workflow: DRAFT -> PENDING_KYC -> ACTIVE, or back to DRAFT if KYC fails
+	AdvanceOnboarding(ctx context.Context, in *AdvanceOnboardingRequest, opts ...grpc.CallOption) (*AdvanceOnboardingResponse, error)
+	// Sets how far below zero debits may take the balance of a checking account; admins only
+	SetOverdraftLimit(ctx context.Context, in *SetOverdraftLimitRequest, opts ...grpc.CallOption) (*SetOverdraftLimitResponse, error)
+	// Adds and removes tags of an account, e.g. VIP, TEST or COLLECTIONS; admins only
+	UpdateAccountTags(ctx context.Context, in *UpdateAccountTagsRequest, opts ...grpc.CallOption) (*UpdateAccountTagsResponse, error)
+	GetTenantSettings(ctx context.Context, in *GetTenantSettingsRequest, opts ...grpc.CallOption) (*GetTenantSettingsResponse, error)
+	UpdateTenantSettings(ctx context.Context, in *UpdateTenantSettingsRequest, opts ...grpc.CallOption) (*UpdateTenantSettingsResponse, error)
+	// Manual balance corrections; each needs a second operator's approval before it is posted
+	RequestBalanceAdjustment(ctx context.Context, in *RequestBalanceAdjustmentRequest, opts ...grpc.CallOption) (*BalanceAdjustmentResponse, error)
+	ReviewBalanceAdjustment(ctx context.Context, in *ReviewBalanceAdjustmentRequest, opts ...grpc.CallOption) (*BalanceAdjustmentResponse, error)
+	ListBalanceAdjustments(ctx context.Context, in *ListBalanceAdjustmentsRequest, opts ...grpc.CallOption) (*ListBalanceAdjustmentsResponse, error)
+	// Admin only; authorization decisions recorded by the account and transaction services, newest first
+	ListAccessDecisions(ctx context.Context, in *ListAccessDecisionsRequest, opts ...grpc.CallOption) (*ListAccessDecisionsResponse, error)
+	// Support or admin; SLO status of the methods with a target in the service's runtime config
+	GetSLOStatus(ctx context.Context, in *GetSLOStatusRequest, opts ...grpc.CallOption) (*GetSLOStatusResponse, error)
+	// Support or admin; month-end FX revaluation entries of a tenant for one period
+	GetFxRevaluationReport(ctx context.Context, in *GetFxRevaluationReportRequest, opts ...grpc.CallOption) (*GetFxRevaluationReportResponse, error)
+	// Document number changes: requested by support, verified by a second operator, then applied
+	RequestDocumentChange(ctx context.Context, in *RequestDocumentChangeRequest, opts ...grpc.CallOption) (*DocumentChangeResponse, error)
+	VerifyDocumentChange(ctx context.Context, in *VerifyDocumentChangeRequest, opts ...grpc.CallOption) (*DocumentChangeResponse, error)
+	ApplyDocumentChange(ctx context.Context, in *ApplyDocumentChangeRequest, opts ...grpc.CallOption) (*DocumentChangeResponse, error)
+	ListDocumentChanges(ctx context.Context, in *ListDocumentChangesRequest, opts ...grpc.CallOption) (*ListDocumentChangesResponse, error)
+	// Support or admin; changes to the attributes of an account for compliance review, newest first
+	GetAccountAudit(ctx context.Context, in *GetAccountAuditRequest, opts ...grpc.CallOption) (*GetAccountAuditResponse, error)
+	// Admin only; snapshots taken of an account before it was deleted or closed, newest first
+	ListAccountSnapshots(ctx context.Context, in *ListAccountSnapshotsRequest, opts ...grpc.CallOption) (*ListAccountSnapshotsResponse, error)
+	// Admin only; undoes the deletion or closing of an account from the snapshot taken before it
+	RestoreAccountSnapshot(ctx context.Context, in *RestoreAccountSnapshotRequest, opts ...grpc.CallOption) (*RestoreAccountSnapshotResponse, error)
 }
 
 type accountServiceClient struct {
@@ -69,6 +134,16 @@ func (c *accountServiceClient) GetAccount(ctx context.Context, in *GetAccountReq
 	return out, nil
 }
 
+func (c *accountServiceClient) GetAccountByDocument(ctx context.Context, in *GetAccountByDocumentRequest, opts ...grpc.CallOption) (*GetAccountByDocumentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAccountByDocumentResponse)
+	err := c.cc.Invoke(ctx, AccountService_GetAccountByDocument_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *accountServiceClient) UpdateAccount(ctx context.Context, in *UpdateAccountRequest, opts ...grpc.CallOption) (*UpdateAccountResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(UpdateAccountResponse)
@@ -99,6 +174,26 @@ func (c *accountServiceClient) GetBalance(ctx context.Context, in *GetBalanceReq
 	return out, nil
 }
 
+func (c *accountServiceClient) GetBalanceAt(ctx context.Context, in *GetBalanceAtRequest, opts ...grpc.CallOption) (*GetBalanceAtResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBalanceAtResponse)
+	err := c.cc.Invoke(ctx, AccountService_GetBalanceAt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) GetBalances(ctx context.Context, in *GetBalancesRequest, opts ...grpc.CallOption) (*GetBalancesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBalancesResponse)
+	err := c.cc.Invoke(ctx, AccountService_GetBalances_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *accountServiceClient) ListAccounts(ctx context.Context, in *ListAccountsRequest, opts ...grpc.CallOption) (*ListAccountsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListAccountsResponse)
@@ -109,6 +204,219 @@ func (c *accountServiceClient) ListAccounts(ctx context.Context, in *ListAccount
 	return out, nil
 }
 
+func (c *accountServiceClient) CreateAccounts(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[CreateAccountRequest, CreateAccountsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AccountService_ServiceDesc.Streams[0], AccountService_CreateAccounts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CreateAccountRequest, CreateAccountsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AccountService_CreateAccountsClient = grpc.ClientStreamingClient[CreateAccountRequest, CreateAccountsResponse]
+
+func (c *accountServiceClient) SearchAccounts(ctx context.Context, in *SearchAccountsRequest, opts ...grpc.CallOption) (*SearchAccountsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchAccountsResponse)
+	err := c.cc.Invoke(ctx, AccountService_SearchAccounts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) UpdateAccountHolder(ctx context.Context, in *UpdateAccountHolderRequest, opts ...grpc.CallOption) (*UpdateAccountHolderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateAccountHolderResponse)
+	err := c.cc.Invoke(ctx, AccountService_UpdateAccountHolder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) AdvanceOnboarding(ctx context.Context, in *AdvanceOnboardingRequest, opts ...grpc.CallOption) (*AdvanceOnboardingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdvanceOnboardingResponse)
+	err := c.cc.Invoke(ctx, AccountService_AdvanceOnboarding_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) SetOverdraftLimit(ctx context.Context, in *SetOverdraftLimitRequest, opts ...grpc.CallOption) (*SetOverdraftLimitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetOverdraftLimitResponse)
+	err := c.cc.Invoke(ctx, AccountService_SetOverdraftLimit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) UpdateAccountTags(ctx context.Context, in *UpdateAccountTagsRequest, opts ...grpc.CallOption) (*UpdateAccountTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateAccountTagsResponse)
+	err := c.cc.Invoke(ctx, AccountService_UpdateAccountTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) GetTenantSettings(ctx context.Context, in *GetTenantSettingsRequest, opts ...grpc.CallOption) (*GetTenantSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTenantSettingsResponse)
+	err := c.cc.Invoke(ctx, AccountService_GetTenantSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) UpdateTenantSettings(ctx context.Context, in *UpdateTenantSettingsRequest, opts ...grpc.CallOption) (*UpdateTenantSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateTenantSettingsResponse)
+	err := c.cc.Invoke(ctx, AccountService_UpdateTenantSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) RequestBalanceAdjustment(ctx context.Context, in *RequestBalanceAdjustmentRequest, opts ...grpc.CallOption) (*BalanceAdjustmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceAdjustmentResponse)
+	err := c.cc.Invoke(ctx, AccountService_RequestBalanceAdjustment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) ReviewBalanceAdjustment(ctx context.Context, in *ReviewBalanceAdjustmentRequest, opts ...grpc.CallOption) (*BalanceAdjustmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceAdjustmentResponse)
+	err := c.cc.Invoke(ctx, AccountService_ReviewBalanceAdjustment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) ListBalanceAdjustments(ctx context.Context, in *ListBalanceAdjustmentsRequest, opts ...grpc.CallOption) (*ListBalanceAdjustmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBalanceAdjustmentsResponse)
+	err := c.cc.Invoke(ctx, AccountService_ListBalanceAdjustments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) ListAccessDecisions(ctx context.Context, in *ListAccessDecisionsRequest, opts ...grpc.CallOption) (*ListAccessDecisionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAccessDecisionsResponse)
+	err := c.cc.Invoke(ctx, AccountService_ListAccessDecisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) GetSLOStatus(ctx context.Context, in *GetSLOStatusRequest, opts ...grpc.CallOption) (*GetSLOStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSLOStatusResponse)
+	err := c.cc.Invoke(ctx, AccountService_GetSLOStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) GetFxRevaluationReport(ctx context.Context, in *GetFxRevaluationReportRequest, opts ...grpc.CallOption) (*GetFxRevaluationReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFxRevaluationReportResponse)
+	err := c.cc.Invoke(ctx, AccountService_GetFxRevaluationReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) RequestDocumentChange(ctx context.Context, in *RequestDocumentChangeRequest, opts ...grpc.CallOption) (*DocumentChangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DocumentChangeResponse)
+	err := c.cc.Invoke(ctx, AccountService_RequestDocumentChange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) VerifyDocumentChange(ctx context.Context, in *VerifyDocumentChangeRequest, opts ...grpc.CallOption) (*DocumentChangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DocumentChangeResponse)
+	err := c.cc.Invoke(ctx, AccountService_VerifyDocumentChange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) ApplyDocumentChange(ctx context.Context, in *ApplyDocumentChangeRequest, opts ...grpc.CallOption) (*DocumentChangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DocumentChangeResponse)
+	err := c.cc.Invoke(ctx, AccountService_ApplyDocumentChange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) ListDocumentChanges(ctx context.Context, in *ListDocumentChangesRequest, opts ...grpc.CallOption) (*ListDocumentChangesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDocumentChangesResponse)
+	err := c.cc.Invoke(ctx, AccountService_ListDocumentChanges_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) GetAccountAudit(ctx context.Context, in *GetAccountAuditRequest, opts ...grpc.CallOption) (*GetAccountAuditResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAccountAuditResponse)
+	err := c.cc.Invoke(ctx, AccountService_GetAccountAudit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) ListAccountSnapshots(ctx context.Context, in *ListAccountSnapshotsRequest, opts ...grpc.CallOption) (*ListAccountSnapshotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAccountSnapshotsResponse)
+	err := c.cc.Invoke(ctx, AccountService_ListAccountSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountServiceClient) RestoreAccountSnapshot(ctx context.Context, in *RestoreAccountSnapshotRequest, opts ...grpc.CallOption) (*RestoreAccountSnapshotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreAccountSnapshotResponse)
+	err := c.cc.Invoke(ctx, AccountService_RestoreAccountSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AccountServiceServer is the server API for AccountService service.
 // All implementations must embed UnimplementedAccountServiceServer
 // for forward compatibility.
@@ -117,10 +425,51 @@ func (c *accountServiceClient) ListAccounts(ctx context.Context, in *ListAccount
 type AccountServiceServer interface {
 	CreateAccount(context.Context, *CreateAccountRequest) (*CreateAccountResponse, error)
 	GetAccount(context.Context, *GetAccountRequest) (*GetAccountResponse, error)
+	// Lookup by the holder's document number, which is unique across accounts
+	GetAccountByDocument(context.Context, *GetAccountByDocumentRequest) (*GetAccountByDocumentResponse, error)
 	UpdateAccount(context.Context, *UpdateAccountRequest) (*UpdateAccountResponse, error)
 	DeleteAccount(context.Context, *DeleteAccountRequest) (*DeleteAccountResponse, error)
 	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error)
+	// Balance of an account at a past point in time, derived from its ledger
+	GetBalanceAt(context.Context, *GetBalanceAtRequest) (*GetBalanceAtResponse, error)
+	GetBalances(context.Context, *GetBalancesRequest) (*GetBalancesResponse, error)
 	ListAccounts(context.Context, *ListAccountsRequest) (*ListAccountsResponse, error)
+	// Bulk account creation for migration tooling
+	CreateAccounts(grpc.ClientStreamingServer[CreateAccountRequest, CreateAccountsResponse]) error
+	// Partial document number search for support tooling
+	SearchAccounts(context.Context, *SearchAccountsRequest) (*SearchAccountsResponse, error)
+	// Per-tenant (issuer) settings for the service's environment
+	// Holder and KYC data can only be changed while the account is a DRAFT
+	UpdateAccountHolder(context.Context, *UpdateAccountHolderRequest) (*UpdateAccountHolderResponse, error)
+	// Moves an account along the onboarding workflow: DRAFT -> PENDING_KYC -> ACTIVE, or back to DRAFT if KYC fails
+	AdvanceOnboarding(context.Context, *AdvanceOnboardingRequest) (*AdvanceOnboardingResponse, error)
+	// Sets how far below zero debits may take the balance of a checking account; admins only
+	SetOverdraftLimit(context.Context, *SetOverdraftLimitRequest) (*SetOverdraftLimitResponse, error)
+	// Adds and removes tags of an account, e.g. VIP, TEST or COLLECTIONS; admins only
+	UpdateAccountTags(context.Context, *UpdateAccountTagsRequest) (*UpdateAccountTagsResponse, error)
+	GetTenantSettings(context.Context, *GetTenantSettingsRequest) (*GetTenantSettingsResponse, error)
+	UpdateTenantSettings(context.Context, *UpdateTenantSettingsRequest) (*UpdateTenantSettingsResponse, error)
+	// Manual balance corrections; each needs a second operator's approval before it is posted
+	RequestBalanceAdjustment(context.Context, *RequestBalanceAdjustmentRequest) (*BalanceAdjustmentResponse, error)
+	ReviewBalanceAdjustment(context.Context, *ReviewBalanceAdjustmentRequest) (*BalanceAdjustmentResponse, error)
+	ListBalanceAdjustments(context.Context, *ListBalanceAdjustmentsRequest) (*ListBalanceAdjustmentsResponse, error)
+	// Admin only; authorization decisions recorded by the account and transaction services, newest first
+	ListAccessDecisions(context.Context, *ListAccessDecisionsRequest) (*ListAccessDecisionsResponse, error)
+	// Support or admin; SLO status of the methods with a target in the service's runtime config
+	GetSLOStatus(context.Context, *GetSLOStatusRequest) (*GetSLOStatusResponse, error)
+	// Support or admin; month-end FX revaluation entries of a tenant for one period
+	GetFxRevaluationReport(context.Context, *GetFxRevaluationReportRequest) (*GetFxRevaluationReportResponse, error)
+	// Document number changes: requested by support, verified by a second operator, then applied
+	RequestDocumentChange(context.Context, *RequestDocumentChangeRequest) (*DocumentChangeResponse, error)
+	VerifyDocumentChange(context.Context, *VerifyDocumentChangeRequest) (*DocumentChangeResponse, error)
+	ApplyDocumentChange(context.Context, *ApplyDocumentChangeRequest) (*DocumentChangeResponse, error)
+	ListDocumentChanges(context.Context, *ListDocumentChangesRequest) (*ListDocumentChangesResponse, error)
+	// Support or admin; changes to the attributes of an account for compliance review, newest first
+	GetAccountAudit(context.Context, *GetAccountAuditRequest) (*GetAccountAuditResponse, error)
+	// Admin only; snapshots taken of an account before it was deleted or closed, newest first
+	ListAccountSnapshots(context.Context, *ListAccountSnapshotsRequest) (*ListAccountSnapshotsResponse, error)
+	// Admin only; undoes the deletion or closing of an account from the snapshot taken before it
+	RestoreAccountSnapshot(context.Context, *RestoreAccountSnapshotRequest) (*RestoreAccountSnapshotResponse, error)
 	mustEmbedUnimplementedAccountServiceServer()
 }
 
@@ -137,6 +486,9 @@ func (UnimplementedAccountServiceServer) CreateAccount(context.Context, *CreateA
 func (UnimplementedAccountServiceServer) GetAccount(context.Context, *GetAccountRequest) (*GetAccountResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAccount not implemented")
 }
+func (UnimplementedAccountServiceServer) GetAccountByDocument(context.Context, *GetAccountByDocumentRequest) (*GetAccountByDocumentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountByDocument not implemented")
+}
 func (UnimplementedAccountServiceServer) UpdateAccount(context.Context, *UpdateAccountRequest) (*UpdateAccountResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateAccount not implemented")
 }
@@ -146,9 +498,78 @@ func (UnimplementedAccountServiceServer) DeleteAccount(context.Context, *DeleteA
 func (UnimplementedAccountServiceServer) GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
 }
+func (UnimplementedAccountServiceServer) GetBalanceAt(context.Context, *GetBalanceAtRequest) (*GetBalanceAtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalanceAt not implemented")
+}
+func (UnimplementedAccountServiceServer) GetBalances(context.Context, *GetBalancesRequest) (*GetBalancesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalances not implemented")
+}
 func (UnimplementedAccountServiceServer) ListAccounts(context.Context, *ListAccountsRequest) (*ListAccountsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListAccounts not implemented")
 }
+func (UnimplementedAccountServiceServer) CreateAccounts(grpc.ClientStreamingServer[CreateAccountRequest, CreateAccountsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method CreateAccounts not implemented")
+}
+func (UnimplementedAccountServiceServer) SearchAccounts(context.Context, *SearchAccountsRequest) (*SearchAccountsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchAccounts not implemented")
+}
+func (UnimplementedAccountServiceServer) UpdateAccountHolder(context.Context, *UpdateAccountHolderRequest) (*UpdateAccountHolderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAccountHolder not implemented")
+}
+func (UnimplementedAccountServiceServer) AdvanceOnboarding(context.Context, *AdvanceOnboardingRequest) (*AdvanceOnboardingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdvanceOnboarding not implemented")
+}
+func (UnimplementedAccountServiceServer) SetOverdraftLimit(context.Context, *SetOverdraftLimitRequest) (*SetOverdraftLimitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetOverdraftLimit not implemented")
+}
+func (UnimplementedAccountServiceServer) UpdateAccountTags(context.Context, *UpdateAccountTagsRequest) (*UpdateAccountTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAccountTags not implemented")
+}
+func (UnimplementedAccountServiceServer) GetTenantSettings(context.Context, *GetTenantSettingsRequest) (*GetTenantSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTenantSettings not implemented")
+}
+func (UnimplementedAccountServiceServer) UpdateTenantSettings(context.Context, *UpdateTenantSettingsRequest) (*UpdateTenantSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTenantSettings not implemented")
+}
+func (UnimplementedAccountServiceServer) RequestBalanceAdjustment(context.Context, *RequestBalanceAdjustmentRequest) (*BalanceAdjustmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestBalanceAdjustment not implemented")
+}
+func (UnimplementedAccountServiceServer) ReviewBalanceAdjustment(context.Context, *ReviewBalanceAdjustmentRequest) (*BalanceAdjustmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReviewBalanceAdjustment not implemented")
+}
+func (UnimplementedAccountServiceServer) ListBalanceAdjustments(context.Context, *ListBalanceAdjustmentsRequest) (*ListBalanceAdjustmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBalanceAdjustments not implemented")
+}
+func (UnimplementedAccountServiceServer) ListAccessDecisions(context.Context, *ListAccessDecisionsRequest) (*ListAccessDecisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAccessDecisions not implemented")
+}
+func (UnimplementedAccountServiceServer) GetSLOStatus(context.Context, *GetSLOStatusRequest) (*GetSLOStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSLOStatus not implemented")
+}
+func (UnimplementedAccountServiceServer) GetFxRevaluationReport(context.Context, *GetFxRevaluationReportRequest) (*GetFxRevaluationReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFxRevaluationReport not implemented")
+}
+func (UnimplementedAccountServiceServer) RequestDocumentChange(context.Context, *RequestDocumentChangeRequest) (*DocumentChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestDocumentChange not implemented")
+}
+func (UnimplementedAccountServiceServer) VerifyDocumentChange(context.Context, *VerifyDocumentChangeRequest) (*DocumentChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyDocumentChange not implemented")
+}
+func (UnimplementedAccountServiceServer) ApplyDocumentChange(context.Context, *ApplyDocumentChangeRequest) (*DocumentChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyDocumentChange not implemented")
+}
+func (UnimplementedAccountServiceServer) ListDocumentChanges(context.Context, *ListDocumentChangesRequest) (*ListDocumentChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDocumentChanges not implemented")
+}
+func (UnimplementedAccountServiceServer) GetAccountAudit(context.Context, *GetAccountAuditRequest) (*GetAccountAuditResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountAudit not implemented")
+}
+func (UnimplementedAccountServiceServer) ListAccountSnapshots(context.Context, *ListAccountSnapshotsRequest) (*ListAccountSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAccountSnapshots not implemented")
+}
+func (UnimplementedAccountServiceServer) RestoreAccountSnapshot(context.Context, *RestoreAccountSnapshotRequest) (*RestoreAccountSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreAccountSnapshot not implemented")
+}
 func (UnimplementedAccountServiceServer) mustEmbedUnimplementedAccountServiceServer() {}
 func (UnimplementedAccountServiceServer) testEmbeddedByValue()                        {}
 
@@ -206,6 +627,24 @@ func _AccountService_GetAccount_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AccountService_GetAccountByDocument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountByDocumentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).GetAccountByDocument(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_GetAccountByDocument_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).GetAccountByDocument(ctx, req.(*GetAccountByDocumentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AccountService_UpdateAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateAccountRequest)
 	if err := dec(in); err != nil {
@@ -260,6 +699,42 @@ func _AccountService_GetBalance_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AccountService_GetBalanceAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceAtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).GetBalanceAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_GetBalanceAt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).GetBalanceAt(ctx, req.(*GetBalanceAtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_GetBalances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalancesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).GetBalances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_GetBalances_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).GetBalances(ctx, req.(*GetBalancesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AccountService_ListAccounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListAccountsRequest)
 	if err := dec(in); err != nil {
@@ -278,38 +753,730 @@ func _AccountService_ListAccounts_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
-// AccountService_ServiceDesc is the grpc.ServiceDesc for AccountService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var AccountService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "account.AccountService",
-	HandlerType: (*AccountServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "CreateAccount",
-			Handler:    _AccountService_CreateAccount_Handler,
-		},
-		{
-			MethodName: "GetAccount",
-			Handler:    _AccountService_GetAccount_Handler,
-		},
-		{
-			MethodName: "UpdateAccount",
-			Handler:    _AccountService_UpdateAccount_Handler,
-		},
-		{
-			MethodName: "DeleteAccount",
-			Handler:    _AccountService_DeleteAccount_Handler,
-		},
-		{
-			MethodName: "GetBalance",
+func _AccountService_CreateAccounts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AccountServiceServer).CreateAccounts(&grpc.GenericServerStream[CreateAccountRequest, CreateAccountsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AccountService_CreateAccountsServer = grpc.ClientStreamingServer[CreateAccountRequest, CreateAccountsResponse]
+
+func _AccountService_SearchAccounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchAccountsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).SearchAccounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_SearchAccounts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).SearchAccounts(ctx, req.(*SearchAccountsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_UpdateAccountHolder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAccountHolderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).UpdateAccountHolder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_UpdateAccountHolder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).UpdateAccountHolder(ctx, req.(*UpdateAccountHolderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_AdvanceOnboarding_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdvanceOnboardingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).AdvanceOnboarding(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_AdvanceOnboarding_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).AdvanceOnboarding(ctx, req.(*AdvanceOnboardingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_SetOverdraftLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetOverdraftLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).SetOverdraftLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_SetOverdraftLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).SetOverdraftLimit(ctx, req.(*SetOverdraftLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_UpdateAccountTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAccountTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).UpdateAccountTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_UpdateAccountTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).UpdateAccountTags(ctx, req.(*UpdateAccountTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_GetTenantSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTenantSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).GetTenantSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_GetTenantSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).GetTenantSettings(ctx, req.(*GetTenantSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_UpdateTenantSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTenantSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).UpdateTenantSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_UpdateTenantSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).UpdateTenantSettings(ctx, req.(*UpdateTenantSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_RequestBalanceAdjustment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestBalanceAdjustmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).RequestBalanceAdjustment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_RequestBalanceAdjustment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).RequestBalanceAdjustment(ctx, req.(*RequestBalanceAdjustmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_ReviewBalanceAdjustment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewBalanceAdjustmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).ReviewBalanceAdjustment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_ReviewBalanceAdjustment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).ReviewBalanceAdjustment(ctx, req.(*ReviewBalanceAdjustmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_ListBalanceAdjustments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBalanceAdjustmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).ListBalanceAdjustments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_ListBalanceAdjustments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).ListBalanceAdjustments(ctx, req.(*ListBalanceAdjustmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_ListAccessDecisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAccessDecisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).ListAccessDecisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_ListAccessDecisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).ListAccessDecisions(ctx, req.(*ListAccessDecisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_GetSLOStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSLOStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).GetSLOStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_GetSLOStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).GetSLOStatus(ctx, req.(*GetSLOStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_GetFxRevaluationReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFxRevaluationReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).GetFxRevaluationReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_GetFxRevaluationReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).GetFxRevaluationReport(ctx, req.(*GetFxRevaluationReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_RequestDocumentChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestDocumentChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).RequestDocumentChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_RequestDocumentChange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).RequestDocumentChange(ctx, req.(*RequestDocumentChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_VerifyDocumentChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyDocumentChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).VerifyDocumentChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_VerifyDocumentChange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).VerifyDocumentChange(ctx, req.(*VerifyDocumentChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_ApplyDocumentChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyDocumentChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).ApplyDocumentChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_ApplyDocumentChange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).ApplyDocumentChange(ctx, req.(*ApplyDocumentChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_ListDocumentChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDocumentChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).ListDocumentChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_ListDocumentChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).ListDocumentChanges(ctx, req.(*ListDocumentChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_GetAccountAudit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountAuditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).GetAccountAudit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_GetAccountAudit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).GetAccountAudit(ctx, req.(*GetAccountAuditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_ListAccountSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAccountSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).ListAccountSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_ListAccountSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).ListAccountSnapshots(ctx, req.(*ListAccountSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountService_RestoreAccountSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreAccountSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountServiceServer).RestoreAccountSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountService_RestoreAccountSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountServiceServer).RestoreAccountSnapshot(ctx, req.(*RestoreAccountSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AccountService_ServiceDesc is the grpc.ServiceDesc for AccountService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AccountService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "account.AccountService",
+	HandlerType: (*AccountServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateAccount",
+			Handler:    _AccountService_CreateAccount_Handler,
+		},
+		{
+			MethodName: "GetAccount",
+			Handler:    _AccountService_GetAccount_Handler,
+		},
+		{
+			MethodName: "GetAccountByDocument",
+			Handler:    _AccountService_GetAccountByDocument_Handler,
+		},
+		{
+			MethodName: "UpdateAccount",
+			Handler:    _AccountService_UpdateAccount_Handler,
+		},
+		{
+			MethodName: "DeleteAccount",
+			Handler:    _AccountService_DeleteAccount_Handler,
+		},
+		{
+			MethodName: "GetBalance",
 			Handler:    _AccountService_GetBalance_Handler,
 		},
+		{
+			MethodName: "GetBalanceAt",
+			Handler:    _AccountService_GetBalanceAt_Handler,
+		},
+		{
+			MethodName: "GetBalances",
+			Handler:    _AccountService_GetBalances_Handler,
+		},
 		{
 			MethodName: "ListAccounts",
 			Handler:    _AccountService_ListAccounts_Handler,
 		},
+		{
+			MethodName: "SearchAccounts",
+			Handler:    _AccountService_SearchAccounts_Handler,
+		},
+		{
+			MethodName: "UpdateAccountHolder",
+			Handler:    _AccountService_UpdateAccountHolder_Handler,
+		},
+		{
+			MethodName: "AdvanceOnboarding",
+			Handler:    _AccountService_AdvanceOnboarding_Handler,
+		},
+		{
+			MethodName: "SetOverdraftLimit",
+			Handler:    _AccountService_SetOverdraftLimit_Handler,
+		},
+		{
+			MethodName: "UpdateAccountTags",
+			Handler:    _AccountService_UpdateAccountTags_Handler,
+		},
+		{
+			MethodName: "GetTenantSettings",
+			Handler:    _AccountService_GetTenantSettings_Handler,
+		},
+		{
+			MethodName: "UpdateTenantSettings",
+			Handler:    _AccountService_UpdateTenantSettings_Handler,
+		},
+		{
+			MethodName: "RequestBalanceAdjustment",
+			Handler:    _AccountService_RequestBalanceAdjustment_Handler,
+		},
+		{
+			MethodName: "ReviewBalanceAdjustment",
+			Handler:    _AccountService_ReviewBalanceAdjustment_Handler,
+		},
+		{
+			MethodName: "ListBalanceAdjustments",
+			Handler:    _AccountService_ListBalanceAdjustments_Handler,
+		},
+		{
+			MethodName: "ListAccessDecisions",
+			Handler:    _AccountService_ListAccessDecisions_Handler,
+		},
+		{
+			MethodName: "GetSLOStatus",
+			Handler:    _AccountService_GetSLOStatus_Handler,
+		},
+		{
+			MethodName: "GetFxRevaluationReport",
+			Handler:    _AccountService_GetFxRevaluationReport_Handler,
+		},
+		{
+			MethodName: "RequestDocumentChange",
+			Handler:    _AccountService_RequestDocumentChange_Handler,
+		},
+		{
+			MethodName: "VerifyDocumentChange",
+			Handler:    _AccountService_VerifyDocumentChange_Handler,
+		},
+		{
+			MethodName: "ApplyDocumentChange",
+			Handler:    _AccountService_ApplyDocumentChange_Handler,
+		},
+		{
+			MethodName: "ListDocumentChanges",
+			Handler:    _AccountService_ListDocumentChanges_Handler,
+		},
+		{
+			MethodName: "GetAccountAudit",
+			Handler:    _AccountService_GetAccountAudit_Handler,
+		},
+		{
+			MethodName: "ListAccountSnapshots",
+			Handler:    _AccountService_ListAccountSnapshots_Handler,
+		},
+		{
+			MethodName: "RestoreAccountSnapshot",
+			Handler:    _AccountService_RestoreAccountSnapshot_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CreateAccounts",
+			Handler:       _AccountService_CreateAccounts_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "account.proto",
+}
+
+const (
+	InternalAccountService_AdjustBalance_FullMethodName = "/account.InternalAccountService/AdjustBalance"
+)
+
+// InternalAccountServiceClient is the client API for InternalAccountService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Operations for other services of the platform, such as the interest, fee and settlement workers.
+// Served on a separate port that is not exposed through the gateway; callers authenticate with a service token.
+type InternalAccountServiceClient interface {
+	// Posts a credit or debit to an account right away, recorded as an APPROVED balance adjustment
+	AdjustBalance(ctx context.Context, in *AdjustBalanceRequest, opts ...grpc.CallOption) (*BalanceAdjustmentResponse, error)
+}
+
+type internalAccountServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInternalAccountServiceClient(cc grpc.ClientConnInterface) InternalAccountServiceClient {
+	return &internalAccountServiceClient{cc}
+}
+
+func (c *internalAccountServiceClient) AdjustBalance(ctx context.Context, in *AdjustBalanceRequest, opts ...grpc.CallOption) (*BalanceAdjustmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceAdjustmentResponse)
+	err := c.cc.Invoke(ctx, InternalAccountService_AdjustBalance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InternalAccountServiceServer is the server API for InternalAccountService service.
+// All implementations must embed UnimplementedInternalAccountServiceServer
+// for forward compatibility.
+//
+// Operations for other services of the platform, such as the interest, fee and settlement workers.
+// Served on a separate port that is not exposed through the gateway; callers authenticate with a service token.
+type InternalAccountServiceServer interface {
+	// Posts a credit or debit to an account right away, recorded as an APPROVED balance adjustment
+	AdjustBalance(context.Context, *AdjustBalanceRequest) (*BalanceAdjustmentResponse, error)
+	mustEmbedUnimplementedInternalAccountServiceServer()
+}
+
+// UnimplementedInternalAccountServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedInternalAccountServiceServer struct{}
+
+func (UnimplementedInternalAccountServiceServer) AdjustBalance(context.Context, *AdjustBalanceRequest) (*BalanceAdjustmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdjustBalance not implemented")
+}
+func (UnimplementedInternalAccountServiceServer) mustEmbedUnimplementedInternalAccountServiceServer() {
+}
+func (UnimplementedInternalAccountServiceServer) testEmbeddedByValue() {}
+
+// UnsafeInternalAccountServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InternalAccountServiceServer will
+// result in compilation errors.
+type UnsafeInternalAccountServiceServer interface {
+	mustEmbedUnimplementedInternalAccountServiceServer()
+}
+
+func RegisterInternalAccountServiceServer(s grpc.ServiceRegistrar, srv InternalAccountServiceServer) {
+	// If the following call pancis, it indicates UnimplementedInternalAccountServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&InternalAccountService_ServiceDesc, srv)
+}
+
+func _InternalAccountService_AdjustBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdjustBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InternalAccountServiceServer).AdjustBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InternalAccountService_AdjustBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InternalAccountServiceServer).AdjustBalance(ctx, req.(*AdjustBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// InternalAccountService_ServiceDesc is the grpc.ServiceDesc for InternalAccountService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InternalAccountService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "account.InternalAccountService",
+	HandlerType: (*InternalAccountServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AdjustBalance",
+			Handler:    _InternalAccountService_AdjustBalance_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "account.proto",
 }
+
+const (
+	AccountAnalyticsService_StreamAccounts_FullMethodName = "/account.AccountAnalyticsService/StreamAccounts"
+)
+
+// AccountAnalyticsServiceClient is the client API for AccountAnalyticsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Read-only, streaming-only reads for analytics workloads such as reporting jobs and data pipelines.
+// Served on a separate port against a read replica, with its own rate limits; callers authenticate with an
+// analytics service token.
+type AccountAnalyticsServiceClient interface {
+	// Streams accounts oldest first, in batches; document numbers are masked and holder data is left out
+	StreamAccounts(ctx context.Context, in *StreamAccountsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamAccountsResponse], error)
+}
+
+type accountAnalyticsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAccountAnalyticsServiceClient(cc grpc.ClientConnInterface) AccountAnalyticsServiceClient {
+	return &accountAnalyticsServiceClient{cc}
+}
+
+func (c *accountAnalyticsServiceClient) StreamAccounts(ctx context.Context, in *StreamAccountsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamAccountsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AccountAnalyticsService_ServiceDesc.Streams[0], AccountAnalyticsService_StreamAccounts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamAccountsRequest, StreamAccountsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AccountAnalyticsService_StreamAccountsClient = grpc.ServerStreamingClient[StreamAccountsResponse]
+
+// AccountAnalyticsServiceServer is the server API for AccountAnalyticsService service.
+// All implementations must embed UnimplementedAccountAnalyticsServiceServer
+// for forward compatibility.
+//
+// Read-only, streaming-only reads for analytics workloads such as reporting jobs and data pipelines.
+// Served on a separate port against a read replica, with its own rate limits; callers authenticate with an
+// analytics service token.
+type AccountAnalyticsServiceServer interface {
+	// Streams accounts oldest first, in batches; document numbers are masked and holder data is left out
+	StreamAccounts(*StreamAccountsRequest, grpc.ServerStreamingServer[StreamAccountsResponse]) error
+	mustEmbedUnimplementedAccountAnalyticsServiceServer()
+}
+
+// UnimplementedAccountAnalyticsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAccountAnalyticsServiceServer struct{}
+
+func (UnimplementedAccountAnalyticsServiceServer) StreamAccounts(*StreamAccountsRequest, grpc.ServerStreamingServer[StreamAccountsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAccounts not implemented")
+}
+func (UnimplementedAccountAnalyticsServiceServer) mustEmbedUnimplementedAccountAnalyticsServiceServer() {
+}
+func (UnimplementedAccountAnalyticsServiceServer) testEmbeddedByValue() {}
+
+// UnsafeAccountAnalyticsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AccountAnalyticsServiceServer will
+// result in compilation errors.
+type UnsafeAccountAnalyticsServiceServer interface {
+	mustEmbedUnimplementedAccountAnalyticsServiceServer()
+}
+
+func RegisterAccountAnalyticsServiceServer(s grpc.ServiceRegistrar, srv AccountAnalyticsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAccountAnalyticsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AccountAnalyticsService_ServiceDesc, srv)
+}
+
+func _AccountAnalyticsService_StreamAccounts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamAccountsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AccountAnalyticsServiceServer).StreamAccounts(m, &grpc.GenericServerStream[StreamAccountsRequest, StreamAccountsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AccountAnalyticsService_StreamAccountsServer = grpc.ServerStreamingServer[StreamAccountsResponse]
+
+// AccountAnalyticsService_ServiceDesc is the grpc.ServiceDesc for AccountAnalyticsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AccountAnalyticsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "account.AccountAnalyticsService",
+	HandlerType: (*AccountAnalyticsServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamAccounts",
+			Handler:       _AccountAnalyticsService_StreamAccounts_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "account.proto",
+}
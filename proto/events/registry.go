@@ -0,0 +1,192 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Event types published by the services.
+const (
+	TransactionCreated     = "transaction.created"
+	TransactionCompleted   = "transaction.completed"
+	TransactionReversed    = "transaction.reversed"
+	AccountCreated         = "account.created"
+	AccountUpdated         = "account.updated"
+	AccountStatusChanged   = "account.status_changed"
+	AccountBalanceAdjusted = "account.balance_adjusted"
+	BudgetThresholdCrossed = "budget.threshold_crossed"
+	MessageTestSend        = "message.test_send"
+)
+
+// Schema is the payload message of one version of an event type.
+type Schema struct {
+	EventType string
+	Version   int32
+	Message   protoreflect.MessageType
+}
+
+// registry holds the schemas of each event type, ordered by version.
+var registry = map[string][]Schema{}
+
+func init() {
+	for _, schema := range []Schema{
+		{TransactionCreated, 1, (&TransactionCreatedV1{}).ProtoReflect().Type()},
+		{TransactionCompleted, 1, (&TransactionCompletedV1{}).ProtoReflect().Type()},
+		{TransactionReversed, 1, (&TransactionReversedV1{}).ProtoReflect().Type()},
+		{AccountCreated, 1, (&AccountCreatedV1{}).ProtoReflect().Type()},
+		{AccountUpdated, 1, (&AccountUpdatedV1{}).ProtoReflect().Type()},
+		{AccountStatusChanged, 1, (&AccountStatusChangedV1{}).ProtoReflect().Type()},
+		{AccountBalanceAdjusted, 1, (&AccountBalanceAdjustedV1{}).ProtoReflect().Type()},
+		{BudgetThresholdCrossed, 1, (&BudgetThresholdCrossedV1{}).ProtoReflect().Type()},
+		{MessageTestSend, 1, (&MessageTestSendV1{}).ProtoReflect().Type()},
+	} {
+		if err := register(schema); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// register adds the next version of an event type. Versions start at 1 and are added in order,
+// and each must be compatible with the previous one, so consumers on an older version can still
+// read the payloads of a newer one.
+func register(schema Schema) error {
+	versions := registry[schema.EventType]
+	if schema.Version != int32(len(versions))+1 {
+		return fmt.Errorf("%s: version %d registered out of order", schema.EventType, schema.Version)
+	}
+	if len(versions) > 0 {
+		previous := versions[len(versions)-1]
+		if err := CheckCompatible(previous.Message.Descriptor(), schema.Message.Descriptor()); err != nil {
+			return fmt.Errorf("%s: version %d is incompatible with version %d: %w", schema.EventType, schema.Version, previous.Version, err)
+		}
+	}
+	registry[schema.EventType] = append(versions, schema)
+	return nil
+}
+
+// Lookup returns the schema of one version of an event type.
+func Lookup(eventType string, version int32) (Schema, bool) {
+	versions := registry[eventType]
+	if version < 1 || int(version) > len(versions) {
+		return Schema{}, false
+	}
+	return versions[version-1], true
+}
+
+// Latest returns the newest schema of an event type, which producers publish.
+func Latest(eventType string) (Schema, bool) {
+	versions := registry[eventType]
+	if len(versions) == 0 {
+		return Schema{}, false
+	}
+	return versions[len(versions)-1], true
+}
+
+// Schemas returns every registered schema, ordered by event type and version.
+func Schemas() []Schema {
+	var schemas []Schema
+	for _, versions := range registry {
+		schemas = append(schemas, versions...)
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		if schemas[i].EventType != schemas[j].EventType {
+			return schemas[i].EventType < schemas[j].EventType
+		}
+		return schemas[i].Version < schemas[j].Version
+	})
+	return schemas
+}
+
+// NewEnvelope wraps a payload in an envelope for the latest version of its event type.
+// The payload must be the message registered for that version.
+func NewEnvelope(eventID, eventType string, occurredAt int64, tenantID, partitionKey string, payload proto.Message) (*EventEnvelope, error) {
+	schema, ok := Latest(eventType)
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %s", eventType)
+	}
+	if got := payload.ProtoReflect().Descriptor().FullName(); got != schema.Message.Descriptor().FullName() {
+		return nil, fmt.Errorf("%s v%d expects %s, got %s", eventType, schema.Version, schema.Message.Descriptor().FullName(), got)
+	}
+	data, err := proto.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+	return &EventEnvelope{
+		EventId:       eventID,
+		EventType:     eventType,
+		SchemaVersion: schema.Version,
+		OccurredAt:    occurredAt,
+		TenantId:      tenantID,
+		PartitionKey:  partitionKey,
+		Payload:       data,
+	}, nil
+}
+
+// DecodePayload unmarshals the payload of an envelope into the message registered for its event type and version.
+func DecodePayload(envelope *EventEnvelope) (proto.Message, error) {
+	schema, ok := Lookup(envelope.EventType, envelope.SchemaVersion)
+	if !ok {
+		return nil, fmt.Errorf("unknown schema %s v%d", envelope.EventType, envelope.SchemaVersion)
+	}
+	payload := schema.Message.New().Interface()
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		return nil, fmt.Errorf("invalid %s v%d payload: %w", envelope.EventType, envelope.SchemaVersion, err)
+	}
+	return payload, nil
+}
+
+// CheckCompatible reports whether payloads of next can be read by consumers of previous, in the protobuf
+// binary and JSON encodings. Fields may be added; fields of previous must keep their number, name, kind
+// and cardinality in next, or be removed with their number reserved. Nested messages are checked the same way.
+func CheckCompatible(previous, next protoreflect.MessageDescriptor) error {
+	return checkCompatible(previous, next, map[protoreflect.FullName]bool{})
+}
+
+func checkCompatible(previous, next protoreflect.MessageDescriptor, seen map[protoreflect.FullName]bool) error {
+	if seen[previous.FullName()] {
+		return nil
+	}
+	seen[previous.FullName()] = true
+
+	previousFields := previous.Fields()
+	for i := 0; i < previousFields.Len(); i++ {
+		old := previousFields.Get(i)
+		field := next.Fields().ByNumber(old.Number())
+		if field == nil {
+			if !next.ReservedRanges().Has(old.Number()) {
+				return fmt.Errorf("field %s (%d) removed without reserving its number", old.FullName(), old.Number())
+			}
+			continue
+		}
+		if field.Name() != old.Name() {
+			return fmt.Errorf("field %d renamed from %s to %s", old.Number(), old.Name(), field.Name())
+		}
+		if field.Kind() != old.Kind() || field.Cardinality() != old.Cardinality() || field.IsMap() != old.IsMap() {
+			return fmt.Errorf("field %s changed type", old.FullName())
+		}
+		if old.Kind() == protoreflect.EnumKind {
+			values := old.Enum().Values()
+			for j := 0; j < values.Len(); j++ {
+				if field.Enum().Values().ByNumber(values.Get(j).Number()) == nil {
+					return fmt.Errorf("field %s lost enum value %s", old.FullName(), values.Get(j).Name())
+				}
+			}
+		}
+		if old.Message() != nil {
+			if err := checkCompatible(old.Message(), field.Message(), seen); err != nil {
+				return err
+			}
+		}
+	}
+
+	nextFields := next.Fields()
+	for i := 0; i < nextFields.Len(); i++ {
+		if number := nextFields.Get(i).Number(); previous.ReservedRanges().Has(number) {
+			return fmt.Errorf("field %s reuses reserved number %d", nextFields.Get(i).FullName(), number)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package events
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// publishedSchemasFile lists every field of every published schema. Published fields must never change;
+// new fields and versions are appended to it.
+const publishedSchemasFile = "testdata/published_schemas.txt"
+
+// describeSchema returns one line per field of a schema, in the format of publishedSchemasFile.
+func describeSchema(schema Schema) []string {
+	var lines []string
+	fields := schema.Message.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		lines = append(lines, fmt.Sprintf("%s v%d %d %s %s %s",
+			schema.EventType, schema.Version, field.Number(), field.Name(), field.Kind(), field.Cardinality()))
+	}
+	return lines
+}
+
+func TestPublishedSchemasAreUnchanged(t *testing.T) {
+	file, err := os.Open(publishedSchemasFile)
+	require.NoError(t, err)
+	defer file.Close()
+
+	published := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			published[line] = true
+		}
+	}
+	require.NoError(t, scanner.Err())
+
+	current := make(map[string]bool)
+	for _, schema := range Schemas() {
+		for _, line := range describeSchema(schema) {
+			current[line] = true
+			assert.True(t, published[line], "unpublished field %q: add it to %s", line, publishedSchemasFile)
+		}
+	}
+	for line := range published {
+		assert.True(t, current[line], "published field %q was changed or removed", line)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	schema, ok := Latest(TransactionCreated)
+	require.True(t, ok)
+	assert.Equal(t, int32(1), schema.Version)
+	assert.Equal(t, protoreflect.FullName("events.TransactionCreatedV1"), schema.Message.Descriptor().FullName())
+
+	_, ok = Lookup(TransactionCreated, 2)
+	assert.False(t, ok)
+	_, ok = Latest("transaction.unknown")
+	assert.False(t, ok)
+
+	assert.Len(t, Schemas(), 9)
+	assert.Error(t, register(Schema{EventType: TransactionCreated, Version: 3, Message: schema.Message}))
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	payload := &TransactionCreatedV1{TransactionId: "tx1", AccountId: "acc-1", OperationType: "PAYMENT", Amount: 100, CreatedAt: 1700000000}
+
+	envelope, err := NewEnvelope("event-1", TransactionCreated, 1700000000, "issuer-a", "acc-1", payload)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), envelope.SchemaVersion)
+	assert.Equal(t, "acc-1", envelope.PartitionKey)
+
+	decoded, err := DecodePayload(envelope)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(payload, decoded))
+
+	_, err = NewEnvelope("event-2", AccountCreated, 1700000000, "", "acc-1", payload)
+	assert.EqualError(t, err, "account.created v1 expects events.AccountCreatedV1, got events.TransactionCreatedV1")
+
+	envelope.SchemaVersion = 9
+	_, err = DecodePayload(envelope)
+	assert.EqualError(t, err, "unknown schema transaction.created v9")
+}
+
+// testMessage builds a message descriptor named name from the given fields and reserved numbers.
+func testMessage(t *testing.T, name string, fields []*descriptorpb.FieldDescriptorProto, reserved ...int32) protoreflect.MessageDescriptor {
+	message := &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	for _, number := range reserved {
+		message.ReservedRange = append(message.ReservedRange, &descriptorpb.DescriptorProto_ReservedRange{
+			Start: proto.Int32(number), End: proto.Int32(number + 1),
+		})
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:        proto.String(name + ".proto"),
+		Package:     proto.String("test"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{message},
+	}, nil)
+	require.NoError(t, err)
+	return file.Messages().Get(0)
+}
+
+func testField(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{Name: proto.String(name), JsonName: proto.String(name), Number: proto.Int32(number), Type: kind.Enum(), Label: label.Enum()}
+}
+
+func TestCheckCompatible(t *testing.T) {
+	const (
+		str      = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		dbl      = descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+		optional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		repeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	)
+	previous := testMessage(t, "V1", []*descriptorpb.FieldDescriptorProto{
+		testField("id", 1, str, optional),
+		testField("amount", 2, dbl, optional),
+	}, 5)
+
+	tests := []struct {
+		name          string
+		next          protoreflect.MessageDescriptor
+		expectedError string
+	}{
+		{
+			name: "added field",
+			next: testMessage(t, "V2", []*descriptorpb.FieldDescriptorProto{
+				testField("id", 1, str, optional),
+				testField("amount", 2, dbl, optional),
+				testField("currency", 3, str, optional),
+			}),
+		},
+		{
+			name: "removed field with reserved number",
+			next: testMessage(t, "V2", []*descriptorpb.FieldDescriptorProto{
+				testField("id", 1, str, optional),
+			}, 2),
+		},
+		{
+			name: "removed field",
+			next: testMessage(t, "V2", []*descriptorpb.FieldDescriptorProto{
+				testField("id", 1, str, optional),
+			}),
+			expectedError: "field test.V1.amount (2) removed without reserving its number",
+		},
+		{
+			name: "renamed field",
+			next: testMessage(t, "V2", []*descriptorpb.FieldDescriptorProto{
+				testField("id", 1, str, optional),
+				testField("value", 2, dbl, optional),
+			}),
+			expectedError: "field 2 renamed from amount to value",
+		},
+		{
+			name: "changed type",
+			next: testMessage(t, "V2", []*descriptorpb.FieldDescriptorProto{
+				testField("id", 1, str, optional),
+				testField("amount", 2, str, optional),
+			}),
+			expectedError: "field test.V1.amount changed type",
+		},
+		{
+			name: "changed cardinality",
+			next: testMessage(t, "V2", []*descriptorpb.FieldDescriptorProto{
+				testField("id", 1, str, repeated),
+				testField("amount", 2, dbl, optional),
+			}),
+			expectedError: "field test.V1.id changed type",
+		},
+		{
+			name: "reused reserved number",
+			next: testMessage(t, "V2", []*descriptorpb.FieldDescriptorProto{
+				testField("id", 1, str, optional),
+				testField("amount", 2, dbl, optional),
+				testField("fee", 5, dbl, optional),
+			}),
+			expectedError: "field test.V2.fee reuses reserved number 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCompatible(previous, tt.next)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
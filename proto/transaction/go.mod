@@ -3,8 +3,14 @@ module github.com/YASHIRAI/pismo-task/proto/transaction
 go 1.24.0
 
 require (
+	google.golang.org/genproto/googleapis/api v0.0.0-20250922171735-9219d122eba9
 	google.golang.org/grpc v1.71.0
 	google.golang.org/protobuf v1.36.9
 )
 
-require google.golang.org/genproto/googleapis/api v0.0.0-20250922171735-9219d122eba9 // indirect
+require (
+	golang.org/x/net v0.37.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250908214217-97024824d090 // indirect
+)
@@ -7,6 +7,7 @@
 package transaction
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -27,12 +28,40 @@ type Transaction struct {
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	AccountId     string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
 	OperationType string                 `protobuf:"bytes,3,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
-	Amount        float64                `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
-	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
-	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Status        string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	AmountCents   int64                  `protobuf:"varint,19,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// Deprecated: use amount_cents
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	Amount      float64 `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	Description string  `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	CreatedAt   int64   `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Status      string  `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	// Reference assigned by the card network or acquirer; unique when set
+	ExternalId string            `protobuf:"bytes,8,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	Tags       []string          `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	Metadata   map[string]string `protobuf:"bytes,10,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Set on both transactions of a transfer between accounts
+	TransferId string `protobuf:"bytes,11,opt,name=transfer_id,json=transferId,proto3" json:"transfer_id,omitempty"`
+	// Set on a REVERSAL transaction to the transaction it reverses
+	OriginalTransactionId string `protobuf:"bytes,12,opt,name=original_transaction_id,json=originalTransactionId,proto3" json:"original_transaction_id,omitempty"`
+	// Remaining balance of a completed transaction: the part of a purchase or withdrawal not yet discharged
+	// by payments, or the part of a payment left over after discharging them. Returned by CreateTransaction and
+	// GetTransaction only
+	BalanceCents int64 `protobuf:"varint,13,opt,name=balance_cents,json=balanceCents,proto3" json:"balance_cents,omitempty"`
+	// Set on debits that took the account balance below zero, into its overdraft. Returned by CreateTransaction
+	// and GetTransaction only
+	Overdrawn bool `protobuf:"varint,14,opt,name=overdrawn,proto3" json:"overdrawn,omitempty"`
+	// Set on payments made in a currency other than the account's: the currency and amount paid, and the rate
+	// they were converted to the account's currency at. Returned by CreateTransaction, ProcessPayment and
+	// GetTransaction only
+	OriginalCurrency    string  `protobuf:"bytes,15,opt,name=original_currency,json=originalCurrency,proto3" json:"original_currency,omitempty"`
+	OriginalAmountCents int64   `protobuf:"varint,16,opt,name=original_amount_cents,json=originalAmountCents,proto3" json:"original_amount_cents,omitempty"`
+	FxRate              float64 `protobuf:"fixed64,17,opt,name=fx_rate,json=fxRate,proto3" json:"fx_rate,omitempty"`
+	// Set on the transactions of an installment purchase paid in several installments: the purchase's debit of its
+	// first installment, and the debits of the later ones. Returned by CreateTransaction and GetTransaction only
+	InstallmentPlan *InstallmentPlan `protobuf:"bytes,18,opt,name=installment_plan,json=installmentPlan,proto3" json:"installment_plan,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *Transaction) Reset() {
@@ -86,6 +115,14 @@ func (x *Transaction) GetOperationType() string {
 	return ""
 }
 
+func (x *Transaction) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
 func (x *Transaction) GetAmount() float64 {
 	if x != nil {
 		return x.Amount
@@ -114,20 +151,306 @@ func (x *Transaction) GetStatus() string {
 	return ""
 }
 
+func (x *Transaction) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+func (x *Transaction) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Transaction) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Transaction) GetTransferId() string {
+	if x != nil {
+		return x.TransferId
+	}
+	return ""
+}
+
+func (x *Transaction) GetOriginalTransactionId() string {
+	if x != nil {
+		return x.OriginalTransactionId
+	}
+	return ""
+}
+
+func (x *Transaction) GetBalanceCents() int64 {
+	if x != nil {
+		return x.BalanceCents
+	}
+	return 0
+}
+
+func (x *Transaction) GetOverdrawn() bool {
+	if x != nil {
+		return x.Overdrawn
+	}
+	return false
+}
+
+func (x *Transaction) GetOriginalCurrency() string {
+	if x != nil {
+		return x.OriginalCurrency
+	}
+	return ""
+}
+
+func (x *Transaction) GetOriginalAmountCents() int64 {
+	if x != nil {
+		return x.OriginalAmountCents
+	}
+	return 0
+}
+
+func (x *Transaction) GetFxRate() float64 {
+	if x != nil {
+		return x.FxRate
+	}
+	return 0
+}
+
+func (x *Transaction) GetInstallmentPlan() *InstallmentPlan {
+	if x != nil {
+		return x.InstallmentPlan
+	}
+	return nil
+}
+
+// The installments of an installment purchase. The first is debited by the purchase; each later one is debited
+// by a transaction of its own on its due date.
+type InstallmentPlan struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the purchase's transaction
+	PurchaseId       string `protobuf:"bytes,1,opt,name=purchase_id,json=purchaseId,proto3" json:"purchase_id,omitempty"`
+	InstallmentCount int32  `protobuf:"varint,2,opt,name=installment_count,json=installmentCount,proto3" json:"installment_count,omitempty"`
+	TotalAmountCents int64  `protobuf:"varint,3,opt,name=total_amount_cents,json=totalAmountCents,proto3" json:"total_amount_cents,omitempty"`
+	PaidCount        int32  `protobuf:"varint,4,opt,name=paid_count,json=paidCount,proto3" json:"paid_count,omitempty"`
+	// Total of the installments neither paid nor cancelled
+	RemainingCents int64 `protobuf:"varint,5,opt,name=remaining_cents,json=remainingCents,proto3" json:"remaining_cents,omitempty"`
+	// Ordered by number
+	Installments  []*Installment `protobuf:"bytes,6,rep,name=installments,proto3" json:"installments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstallmentPlan) Reset() {
+	*x = InstallmentPlan{}
+	mi := &file_transaction_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstallmentPlan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstallmentPlan) ProtoMessage() {}
+
+func (x *InstallmentPlan) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstallmentPlan.ProtoReflect.Descriptor instead.
+func (*InstallmentPlan) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *InstallmentPlan) GetPurchaseId() string {
+	if x != nil {
+		return x.PurchaseId
+	}
+	return ""
+}
+
+func (x *InstallmentPlan) GetInstallmentCount() int32 {
+	if x != nil {
+		return x.InstallmentCount
+	}
+	return 0
+}
+
+func (x *InstallmentPlan) GetTotalAmountCents() int64 {
+	if x != nil {
+		return x.TotalAmountCents
+	}
+	return 0
+}
+
+func (x *InstallmentPlan) GetPaidCount() int32 {
+	if x != nil {
+		return x.PaidCount
+	}
+	return 0
+}
+
+func (x *InstallmentPlan) GetRemainingCents() int64 {
+	if x != nil {
+		return x.RemainingCents
+	}
+	return 0
+}
+
+func (x *InstallmentPlan) GetInstallments() []*Installment {
+	if x != nil {
+		return x.Installments
+	}
+	return nil
+}
+
+type Installment struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 1-based
+	Number      int32 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	AmountCents int64 `protobuf:"varint,2,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	DueAt       int64 `protobuf:"varint,3,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	// PENDING, IN_REVIEW (its debit is held for review), PAID, OVERDUE (declined when due, retried daily) or
+	// CANCELLED (the purchase was declined or reversed)
+	Status string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	// The debit that paid the installment
+	TransactionId string `protobuf:"bytes,5,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	PaidAt        int64  `protobuf:"varint,6,opt,name=paid_at,json=paidAt,proto3" json:"paid_at,omitempty"`
+	// Declined attempts, and the error of the last one
+	Attempts      int32  `protobuf:"varint,7,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LastError     string `protobuf:"bytes,8,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Installment) Reset() {
+	*x = Installment{}
+	mi := &file_transaction_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Installment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Installment) ProtoMessage() {}
+
+func (x *Installment) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Installment.ProtoReflect.Descriptor instead.
+func (*Installment) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Installment) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Installment) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *Installment) GetDueAt() int64 {
+	if x != nil {
+		return x.DueAt
+	}
+	return 0
+}
+
+func (x *Installment) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Installment) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *Installment) GetPaidAt() int64 {
+	if x != nil {
+		return x.PaidAt
+	}
+	return 0
+}
+
+func (x *Installment) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Installment) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
 // Request/Response messages
 type CreateTransactionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
 	OperationType string                 `protobuf:"bytes,2,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
-	Amount        float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
-	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	AmountCents   int64                  `protobuf:"varint,11,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// Deprecated: use amount_cents
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	Amount      float64 `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Description string  `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	// Run all checks and return the would-be result without persisting anything
+	Simulate   bool   `protobuf:"varint,5,opt,name=simulate,proto3" json:"simulate,omitempty"`
+	ExternalId string `protobuf:"bytes,6,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	// Spending category, e.g. groceries; stored as the category metadata entry and counted against the account's budget for it
+	Category string `protobuf:"bytes,7,opt,name=category,proto3" json:"category,omitempty"`
+	// Batch the transaction belongs to and its 1-based position in it; an item is accepted at most once
+	BatchId   string `protobuf:"bytes,8,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`
+	BatchItem int32  `protobuf:"varint,9,opt,name=batch_item,json=batchItem,proto3" json:"batch_item,omitempty"`
+	// INSTALLMENT_PURCHASE only: number of monthly installments the amount is split into; the first is debited now,
+	// the others on the same day of the following months. 0 or 1 debits the whole amount now
+	InstallmentCount int32 `protobuf:"varint,10,opt,name=installment_count,json=installmentCount,proto3" json:"installment_count,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *CreateTransactionRequest) Reset() {
 	*x = CreateTransactionRequest{}
-	mi := &file_transaction_proto_msgTypes[1]
+	mi := &file_transaction_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -139,7 +462,7 @@ func (x *CreateTransactionRequest) String() string {
 func (*CreateTransactionRequest) ProtoMessage() {}
 
 func (x *CreateTransactionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_transaction_proto_msgTypes[1]
+	mi := &file_transaction_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -152,7 +475,7 @@ func (x *CreateTransactionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateTransactionRequest.ProtoReflect.Descriptor instead.
 func (*CreateTransactionRequest) Descriptor() ([]byte, []int) {
-	return file_transaction_proto_rawDescGZIP(), []int{1}
+	return file_transaction_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *CreateTransactionRequest) GetAccountId() string {
@@ -169,6 +492,14 @@ func (x *CreateTransactionRequest) GetOperationType() string {
 	return ""
 }
 
+func (x *CreateTransactionRequest) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
 func (x *CreateTransactionRequest) GetAmount() float64 {
 	if x != nil {
 		return x.Amount
@@ -183,17 +514,70 @@ func (x *CreateTransactionRequest) GetDescription() string {
 	return ""
 }
 
+func (x *CreateTransactionRequest) GetSimulate() bool {
+	if x != nil {
+		return x.Simulate
+	}
+	return false
+}
+
+func (x *CreateTransactionRequest) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
+func (x *CreateTransactionRequest) GetBatchItem() int32 {
+	if x != nil {
+		return x.BatchItem
+	}
+	return 0
+}
+
+func (x *CreateTransactionRequest) GetInstallmentCount() int32 {
+	if x != nil {
+		return x.InstallmentCount
+	}
+	return 0
+}
+
 type CreateTransactionResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Transaction *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Error       string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Set when the request was simulated; the transaction has no ID and nothing was stored
+	Simulated bool `protobuf:"varint,3,opt,name=simulated,proto3" json:"simulated,omitempty"`
+	// Account balance after the transaction; only set for simulations
+	BalanceAfterCents int64 `protobuf:"varint,6,opt,name=balance_after_cents,json=balanceAfterCents,proto3" json:"balance_after_cents,omitempty"`
+	// Deprecated: use balance_after_cents
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	BalanceAfter float64 `protobuf:"fixed64,4,opt,name=balance_after,json=balanceAfter,proto3" json:"balance_after,omitempty"`
+	// Outstanding purchases and withdrawals a payment discharges, oldest first; the transaction's balance is
+	// what is left of the payment
+	Discharges    []*Discharge `protobuf:"bytes,5,rep,name=discharges,proto3" json:"discharges,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateTransactionResponse) Reset() {
 	*x = CreateTransactionResponse{}
-	mi := &file_transaction_proto_msgTypes[2]
+	mi := &file_transaction_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -205,7 +589,7 @@ func (x *CreateTransactionResponse) String() string {
 func (*CreateTransactionResponse) ProtoMessage() {}
 
 func (x *CreateTransactionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_transaction_proto_msgTypes[2]
+	mi := &file_transaction_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -218,45 +602,6812 @@ func (x *CreateTransactionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateTransactionResponse.ProtoReflect.Descriptor instead.
 func (*CreateTransactionResponse) Descriptor() ([]byte, []int) {
-	return file_transaction_proto_rawDescGZIP(), []int{2}
+	return file_transaction_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateTransactionResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *CreateTransactionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CreateTransactionResponse) GetSimulated() bool {
+	if x != nil {
+		return x.Simulated
+	}
+	return false
+}
+
+func (x *CreateTransactionResponse) GetBalanceAfterCents() int64 {
+	if x != nil {
+		return x.BalanceAfterCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
+func (x *CreateTransactionResponse) GetBalanceAfter() float64 {
+	if x != nil {
+		return x.BalanceAfter
+	}
+	return 0
+}
+
+func (x *CreateTransactionResponse) GetDischarges() []*Discharge {
+	if x != nil {
+		return x.Discharges
+	}
+	return nil
+}
+
+// Part of a payment applied to an outstanding purchase or withdrawal
+type Discharge struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	AmountCents   int64                  `protobuf:"varint,2,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// What remains outstanding of the transaction afterwards
+	BalanceAfterCents int64 `protobuf:"varint,3,opt,name=balance_after_cents,json=balanceAfterCents,proto3" json:"balance_after_cents,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Discharge) Reset() {
+	*x = Discharge{}
+	mi := &file_transaction_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Discharge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Discharge) ProtoMessage() {}
+
+func (x *Discharge) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Discharge.ProtoReflect.Descriptor instead.
+func (*Discharge) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Discharge) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *Discharge) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *Discharge) GetBalanceAfterCents() int64 {
+	if x != nil {
+		return x.BalanceAfterCents
+	}
+	return 0
+}
+
+type GetTransactionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Exactly one of id and external_id identifies the transaction
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ExternalId    string `protobuf:"bytes,2,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionRequest) Reset() {
+	*x = GetTransactionRequest{}
+	mi := &file_transaction_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionRequest) ProtoMessage() {}
+
+func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetTransactionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetTransactionRequest) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+type GetTransactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionResponse) Reset() {
+	*x = GetTransactionResponse{}
+	mi := &file_transaction_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionResponse) ProtoMessage() {}
+
+func (x *GetTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionResponse.ProtoReflect.Descriptor instead.
+func (*GetTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetTransactionResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *GetTransactionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UpdateTransactionRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Tags        []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	Metadata    map[string]string      `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Fields to replace: description, tags and/or metadata. Named fields are replaced even when empty,
+	// so they can be cleared; without a mask only non-empty fields are replaced.
+	UpdateMask    []string `protobuf:"bytes,5,rep,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTransactionRequest) Reset() {
+	*x = UpdateTransactionRequest{}
+	mi := &file_transaction_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTransactionRequest) ProtoMessage() {}
+
+func (x *UpdateTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTransactionRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateTransactionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateTransactionRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UpdateTransactionRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UpdateTransactionRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *UpdateTransactionRequest) GetUpdateMask() []string {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type UpdateTransactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTransactionResponse) Reset() {
+	*x = UpdateTransactionResponse{}
+	mi := &file_transaction_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTransactionResponse) ProtoMessage() {}
+
+func (x *UpdateTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTransactionResponse.ProtoReflect.Descriptor instead.
+func (*UpdateTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateTransactionResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *UpdateTransactionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Values of the editable fields of a transaction
+type TransactionEditValues struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Description   string                 `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionEditValues) Reset() {
+	*x = TransactionEditValues{}
+	mi := &file_transaction_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionEditValues) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionEditValues) ProtoMessage() {}
+
+func (x *TransactionEditValues) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionEditValues.ProtoReflect.Descriptor instead.
+func (*TransactionEditValues) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TransactionEditValues) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *TransactionEditValues) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *TransactionEditValues) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type TransactionEdit struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Operator who made the edit
+	EditedBy      string                 `protobuf:"bytes,2,opt,name=edited_by,json=editedBy,proto3" json:"edited_by,omitempty"`
+	EditedAt      int64                  `protobuf:"varint,3,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"`
+	Previous      *TransactionEditValues `protobuf:"bytes,4,opt,name=previous,proto3" json:"previous,omitempty"`
+	Updated       *TransactionEditValues `protobuf:"bytes,5,opt,name=updated,proto3" json:"updated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionEdit) Reset() {
+	*x = TransactionEdit{}
+	mi := &file_transaction_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionEdit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionEdit) ProtoMessage() {}
+
+func (x *TransactionEdit) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionEdit.ProtoReflect.Descriptor instead.
+func (*TransactionEdit) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TransactionEdit) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TransactionEdit) GetEditedBy() string {
+	if x != nil {
+		return x.EditedBy
+	}
+	return ""
+}
+
+func (x *TransactionEdit) GetEditedAt() int64 {
+	if x != nil {
+		return x.EditedAt
+	}
+	return 0
+}
+
+func (x *TransactionEdit) GetPrevious() *TransactionEditValues {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *TransactionEdit) GetUpdated() *TransactionEditValues {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+// One entry of a transaction's timeline; the record matching the type is set
+type TimelineEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// CREATED, EDITED or DISPUTE_OPENED
+	Type          string           `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	OccurredAt    int64            `protobuf:"varint,2,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Edit          *TransactionEdit `protobuf:"bytes,3,opt,name=edit,proto3" json:"edit,omitempty"`
+	Dispute       *Dispute         `protobuf:"bytes,4,opt,name=dispute,proto3" json:"dispute,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimelineEvent) Reset() {
+	*x = TimelineEvent{}
+	mi := &file_transaction_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimelineEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimelineEvent) ProtoMessage() {}
+
+func (x *TimelineEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimelineEvent.ProtoReflect.Descriptor instead.
+func (*TimelineEvent) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TimelineEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TimelineEvent) GetOccurredAt() int64 {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return 0
+}
+
+func (x *TimelineEvent) GetEdit() *TransactionEdit {
+	if x != nil {
+		return x.Edit
+	}
+	return nil
+}
+
+func (x *TimelineEvent) GetDispute() *Dispute {
+	if x != nil {
+		return x.Dispute
+	}
+	return nil
+}
+
+type GetTransactionTimelineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionTimelineRequest) Reset() {
+	*x = GetTransactionTimelineRequest{}
+	mi := &file_transaction_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionTimelineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionTimelineRequest) ProtoMessage() {}
+
+func (x *GetTransactionTimelineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionTimelineRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionTimelineRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetTransactionTimelineRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetTransactionTimelineResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Transaction *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	// Oldest first, starting with the CREATED event
+	Events        []*TimelineEvent `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	Error         string           `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionTimelineResponse) Reset() {
+	*x = GetTransactionTimelineResponse{}
+	mi := &file_transaction_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionTimelineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionTimelineResponse) ProtoMessage() {}
+
+func (x *GetTransactionTimelineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionTimelineResponse.ProtoReflect.Descriptor instead.
+func (*GetTransactionTimelineResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetTransactionTimelineResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *GetTransactionTimelineResponse) GetEvents() []*TimelineEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *GetTransactionTimelineResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetTransactionHistoryRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Limit     int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Deprecated: use page_token; only honoured when page_token is empty
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	Offset int32 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Opaque token from a previous response's next_page_token
+	PageToken string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Optional case-insensitive search within the description, e.g. a merchant name; at least 3 characters
+	Search        string `protobuf:"bytes,5,opt,name=search,proto3" json:"search,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionHistoryRequest) Reset() {
+	*x = GetTransactionHistoryRequest{}
+	mi := &file_transaction_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionHistoryRequest) ProtoMessage() {}
+
+func (x *GetTransactionHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetTransactionHistoryRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetTransactionHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
+func (x *GetTransactionHistoryRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *GetTransactionHistoryRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *GetTransactionHistoryRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+type GetTransactionHistoryResponse struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Transactions []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	Total        int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Error        string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// Token for the next page; empty when there are no more results
+	NextPageToken string `protobuf:"bytes,4,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	// Page size used; below the requested limit for accounts whose pages are unusually large or slow
+	AppliedLimit  int32 `protobuf:"varint,5,opt,name=applied_limit,json=appliedLimit,proto3" json:"applied_limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionHistoryResponse) Reset() {
+	*x = GetTransactionHistoryResponse{}
+	mi := &file_transaction_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionHistoryResponse) ProtoMessage() {}
+
+func (x *GetTransactionHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetTransactionHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetTransactionHistoryResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *GetTransactionHistoryResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetTransactionHistoryResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetTransactionHistoryResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *GetTransactionHistoryResponse) GetAppliedLimit() int32 {
+	if x != nil {
+		return x.AppliedLimit
+	}
+	return 0
+}
+
+type AggregateTransactionsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Bucket size: day (default), week or month; buckets start at UTC midnight, weeks on Monday
+	GroupBy string `protobuf:"bytes,2,opt,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	// Time range as Unix seconds, from inclusive and to exclusive; defaults to a recent window for group_by
+	From          int64 `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            int64 `protobuf:"varint,4,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AggregateTransactionsRequest) Reset() {
+	*x = AggregateTransactionsRequest{}
+	mi := &file_transaction_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AggregateTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregateTransactionsRequest) ProtoMessage() {}
+
+func (x *AggregateTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregateTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*AggregateTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AggregateTransactionsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *AggregateTransactionsRequest) GetGroupBy() string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return ""
+}
+
+func (x *AggregateTransactionsRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *AggregateTransactionsRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+// Count and sum of the completed transactions of one operation type within one time bucket
+type TransactionBucket struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	BucketStart      int64                  `protobuf:"varint,1,opt,name=bucket_start,json=bucketStart,proto3" json:"bucket_start,omitempty"`
+	OperationType    string                 `protobuf:"bytes,2,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
+	Count            int64                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	TotalAmountCents int64                  `protobuf:"varint,5,opt,name=total_amount_cents,json=totalAmountCents,proto3" json:"total_amount_cents,omitempty"`
+	// Deprecated: use total_amount_cents
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	TotalAmount   float64 `protobuf:"fixed64,4,opt,name=total_amount,json=totalAmount,proto3" json:"total_amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionBucket) Reset() {
+	*x = TransactionBucket{}
+	mi := &file_transaction_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionBucket) ProtoMessage() {}
+
+func (x *TransactionBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionBucket.ProtoReflect.Descriptor instead.
+func (*TransactionBucket) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *TransactionBucket) GetBucketStart() int64 {
+	if x != nil {
+		return x.BucketStart
+	}
+	return 0
+}
+
+func (x *TransactionBucket) GetOperationType() string {
+	if x != nil {
+		return x.OperationType
+	}
+	return ""
+}
+
+func (x *TransactionBucket) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *TransactionBucket) GetTotalAmountCents() int64 {
+	if x != nil {
+		return x.TotalAmountCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
+func (x *TransactionBucket) GetTotalAmount() float64 {
+	if x != nil {
+		return x.TotalAmount
+	}
+	return 0
+}
+
+type AggregateTransactionsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Ordered by bucket_start, then operation_type; empty buckets are omitted
+	Buckets       []*TransactionBucket `protobuf:"bytes,1,rep,name=buckets,proto3" json:"buckets,omitempty"`
+	Error         string               `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AggregateTransactionsResponse) Reset() {
+	*x = AggregateTransactionsResponse{}
+	mi := &file_transaction_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AggregateTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregateTransactionsResponse) ProtoMessage() {}
+
+func (x *AggregateTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregateTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*AggregateTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AggregateTransactionsResponse) GetBuckets() []*TransactionBucket {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+func (x *AggregateTransactionsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ProcessPaymentRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	AccountId   string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	AmountCents int64                  `protobuf:"varint,5,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// Deprecated: use amount_cents
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	Amount      float64 `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Description string  `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// ISO 4217 currency of amount_cents; a payment in a currency other than the account's is converted to it.
+	// Empty means the account's currency
+	Currency      string `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessPaymentRequest) Reset() {
+	*x = ProcessPaymentRequest{}
+	mi := &file_transaction_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessPaymentRequest) ProtoMessage() {}
+
+func (x *ProcessPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessPaymentRequest.ProtoReflect.Descriptor instead.
+func (*ProcessPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ProcessPaymentRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ProcessPaymentRequest) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
+func (x *ProcessPaymentRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ProcessPaymentRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ProcessPaymentRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type ProcessPaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessPaymentResponse) Reset() {
+	*x = ProcessPaymentResponse{}
+	mi := &file_transaction_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessPaymentResponse) ProtoMessage() {}
+
+func (x *ProcessPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessPaymentResponse.ProtoReflect.Descriptor instead.
+func (*ProcessPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ProcessPaymentResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *ProcessPaymentResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ReverseTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReverseTransactionRequest) Reset() {
+	*x = ReverseTransactionRequest{}
+	mi := &file_transaction_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseTransactionRequest) ProtoMessage() {}
+
+func (x *ReverseTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseTransactionRequest.ProtoReflect.Descriptor instead.
+func (*ReverseTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ReverseTransactionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReverseTransactionRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ReverseTransactionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The reversed transaction, now REVERSED
+	Original *Transaction `protobuf:"bytes,1,opt,name=original,proto3" json:"original,omitempty"`
+	// The REVERSAL transaction compensating it
+	Reversal      *Transaction `protobuf:"bytes,2,opt,name=reversal,proto3" json:"reversal,omitempty"`
+	Error         string       `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReverseTransactionResponse) Reset() {
+	*x = ReverseTransactionResponse{}
+	mi := &file_transaction_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseTransactionResponse) ProtoMessage() {}
+
+func (x *ReverseTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseTransactionResponse.ProtoReflect.Descriptor instead.
+func (*ReverseTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ReverseTransactionResponse) GetOriginal() *Transaction {
+	if x != nil {
+		return x.Original
+	}
+	return nil
+}
+
+func (x *ReverseTransactionResponse) GetReversal() *Transaction {
+	if x != nil {
+		return x.Reversal
+	}
+	return nil
+}
+
+func (x *ReverseTransactionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type TransferRequest struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	SourceAccountId      string                 `protobuf:"bytes,1,opt,name=source_account_id,json=sourceAccountId,proto3" json:"source_account_id,omitempty"`
+	DestinationAccountId string                 `protobuf:"bytes,2,opt,name=destination_account_id,json=destinationAccountId,proto3" json:"destination_account_id,omitempty"`
+	// Positive amount moved from the source to the destination
+	AmountCents int64 `protobuf:"varint,5,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// Deprecated: use amount_cents
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	Amount        float64 `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Description   string  `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferRequest) Reset() {
+	*x = TransferRequest{}
+	mi := &file_transaction_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferRequest) ProtoMessage() {}
+
+func (x *TransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferRequest.ProtoReflect.Descriptor instead.
+func (*TransferRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TransferRequest) GetSourceAccountId() string {
+	if x != nil {
+		return x.SourceAccountId
+	}
+	return ""
+}
+
+func (x *TransferRequest) GetDestinationAccountId() string {
+	if x != nil {
+		return x.DestinationAccountId
+	}
+	return ""
+}
+
+func (x *TransferRequest) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
+func (x *TransferRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *TransferRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type TransferResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	TransferId string                 `protobuf:"bytes,1,opt,name=transfer_id,json=transferId,proto3" json:"transfer_id,omitempty"`
+	// TRANSFER_OUT transaction of the source account
+	Debit *Transaction `protobuf:"bytes,2,opt,name=debit,proto3" json:"debit,omitempty"`
+	// TRANSFER_IN transaction of the destination account
+	Credit        *Transaction `protobuf:"bytes,3,opt,name=credit,proto3" json:"credit,omitempty"`
+	Error         string       `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferResponse) Reset() {
+	*x = TransferResponse{}
+	mi := &file_transaction_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferResponse) ProtoMessage() {}
+
+func (x *TransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferResponse.ProtoReflect.Descriptor instead.
+func (*TransferResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *TransferResponse) GetTransferId() string {
+	if x != nil {
+		return x.TransferId
+	}
+	return ""
+}
+
+func (x *TransferResponse) GetDebit() *Transaction {
+	if x != nil {
+		return x.Debit
+	}
+	return nil
+}
+
+func (x *TransferResponse) GetCredit() *Transaction {
+	if x != nil {
+		return x.Credit
+	}
+	return nil
+}
+
+func (x *TransferResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type IngestTransactionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Transaction   *Transaction           `protobuf:"bytes,2,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IngestTransactionResult) Reset() {
+	*x = IngestTransactionResult{}
+	mi := &file_transaction_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IngestTransactionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IngestTransactionResult) ProtoMessage() {}
+
+func (x *IngestTransactionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IngestTransactionResult.ProtoReflect.Descriptor instead.
+func (*IngestTransactionResult) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *IngestTransactionResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *IngestTransactionResult) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *IngestTransactionResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// How transactions of one operation type are applied to the account balance
+type OperationRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OperationType string                 `protobuf:"bytes,1,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
+	// CREDIT adds the amount to the balance, DEBIT subtracts it
+	Direction              string `protobuf:"bytes,2,opt,name=direction,proto3" json:"direction,omitempty"`
+	RequiresPositiveAmount bool   `protobuf:"varint,3,opt,name=requires_positive_amount,json=requiresPositiveAmount,proto3" json:"requires_positive_amount,omitempty"`
+	// Account types the operation is allowed on; empty allows all
+	AllowedAccountTypes []string `protobuf:"bytes,4,rep,name=allowed_account_types,json=allowedAccountTypes,proto3" json:"allowed_account_types,omitempty"`
+	// NONE or TENANT_SCHEDULE
+	FeePolicy     string `protobuf:"bytes,5,opt,name=fee_policy,json=feePolicy,proto3" json:"fee_policy,omitempty"`
+	UpdatedAt     int64  `protobuf:"varint,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OperationRule) Reset() {
+	*x = OperationRule{}
+	mi := &file_transaction_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OperationRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OperationRule) ProtoMessage() {}
+
+func (x *OperationRule) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OperationRule.ProtoReflect.Descriptor instead.
+func (*OperationRule) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *OperationRule) GetOperationType() string {
+	if x != nil {
+		return x.OperationType
+	}
+	return ""
+}
+
+func (x *OperationRule) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *OperationRule) GetRequiresPositiveAmount() bool {
+	if x != nil {
+		return x.RequiresPositiveAmount
+	}
+	return false
+}
+
+func (x *OperationRule) GetAllowedAccountTypes() []string {
+	if x != nil {
+		return x.AllowedAccountTypes
+	}
+	return nil
+}
+
+func (x *OperationRule) GetFeePolicy() string {
+	if x != nil {
+		return x.FeePolicy
+	}
+	return ""
+}
+
+func (x *OperationRule) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type ListOperationRulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOperationRulesRequest) Reset() {
+	*x = ListOperationRulesRequest{}
+	mi := &file_transaction_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOperationRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOperationRulesRequest) ProtoMessage() {}
+
+func (x *ListOperationRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOperationRulesRequest.ProtoReflect.Descriptor instead.
+func (*ListOperationRulesRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{28}
+}
+
+type ListOperationRulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*OperationRule       `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOperationRulesResponse) Reset() {
+	*x = ListOperationRulesResponse{}
+	mi := &file_transaction_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOperationRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOperationRulesResponse) ProtoMessage() {}
+
+func (x *ListOperationRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOperationRulesResponse.ProtoReflect.Descriptor instead.
+func (*ListOperationRulesResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ListOperationRulesResponse) GetRules() []*OperationRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *ListOperationRulesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UpdateOperationRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OperationType string                 `protobuf:"bytes,1,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
+	Rule          *OperationRule         `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateOperationRuleRequest) Reset() {
+	*x = UpdateOperationRuleRequest{}
+	mi := &file_transaction_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateOperationRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOperationRuleRequest) ProtoMessage() {}
+
+func (x *UpdateOperationRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateOperationRuleRequest.ProtoReflect.Descriptor instead.
+func (*UpdateOperationRuleRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *UpdateOperationRuleRequest) GetOperationType() string {
+	if x != nil {
+		return x.OperationType
+	}
+	return ""
+}
+
+func (x *UpdateOperationRuleRequest) GetRule() *OperationRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type UpdateOperationRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          *OperationRule         `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateOperationRuleResponse) Reset() {
+	*x = UpdateOperationRuleResponse{}
+	mi := &file_transaction_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateOperationRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOperationRuleResponse) ProtoMessage() {}
+
+func (x *UpdateOperationRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateOperationRuleResponse.ProtoReflect.Descriptor instead.
+func (*UpdateOperationRuleResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UpdateOperationRuleResponse) GetRule() *OperationRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+func (x *UpdateOperationRuleResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Dispute struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TransactionId string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	AccountId     string                 `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	AmountCents   int64                  `protobuf:"varint,8,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// Deprecated: use amount_cents
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	Amount     float64 `protobuf:"fixed64,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	ReasonCode string  `protobuf:"bytes,5,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	// OPEN, WON or LOST
+	Status        string `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	OpenedAt      int64  `protobuf:"varint,7,opt,name=opened_at,json=openedAt,proto3" json:"opened_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Dispute) Reset() {
+	*x = Dispute{}
+	mi := &file_transaction_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Dispute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dispute) ProtoMessage() {}
+
+func (x *Dispute) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dispute.ProtoReflect.Descriptor instead.
+func (*Dispute) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *Dispute) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Dispute) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *Dispute) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *Dispute) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
+func (x *Dispute) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Dispute) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *Dispute) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Dispute) GetOpenedAt() int64 {
+	if x != nil {
+		return x.OpenedAt
+	}
+	return 0
+}
+
+type ImportChargebacksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Raw file contents
+	Content []byte `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	// csv (default) or fixed
+	Format        string `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportChargebacksRequest) Reset() {
+	*x = ImportChargebacksRequest{}
+	mi := &file_transaction_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportChargebacksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportChargebacksRequest) ProtoMessage() {}
+
+func (x *ImportChargebacksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportChargebacksRequest.ProtoReflect.Descriptor instead.
+func (*ImportChargebacksRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ImportChargebacksRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *ImportChargebacksRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type UnmatchedChargeback struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One-based line number in the file
+	Line          int32  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	ExternalId    string `protobuf:"bytes,2,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnmatchedChargeback) Reset() {
+	*x = UnmatchedChargeback{}
+	mi := &file_transaction_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnmatchedChargeback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnmatchedChargeback) ProtoMessage() {}
+
+func (x *UnmatchedChargeback) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnmatchedChargeback.ProtoReflect.Descriptor instead.
+func (*UnmatchedChargeback) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *UnmatchedChargeback) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *UnmatchedChargeback) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+func (x *UnmatchedChargeback) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ImportChargebacksResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Opened []*Dispute             `protobuf:"bytes,1,rep,name=opened,proto3" json:"opened,omitempty"`
+	// Matched lines whose transaction already had a dispute
+	AlreadyDisputed int32                  `protobuf:"varint,2,opt,name=already_disputed,json=alreadyDisputed,proto3" json:"already_disputed,omitempty"`
+	Unmatched       []*UnmatchedChargeback `protobuf:"bytes,3,rep,name=unmatched,proto3" json:"unmatched,omitempty"`
+	Error           string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ImportChargebacksResponse) Reset() {
+	*x = ImportChargebacksResponse{}
+	mi := &file_transaction_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportChargebacksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportChargebacksResponse) ProtoMessage() {}
+
+func (x *ImportChargebacksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportChargebacksResponse.ProtoReflect.Descriptor instead.
+func (*ImportChargebacksResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ImportChargebacksResponse) GetOpened() []*Dispute {
+	if x != nil {
+		return x.Opened
+	}
+	return nil
+}
+
+func (x *ImportChargebacksResponse) GetAlreadyDisputed() int32 {
+	if x != nil {
+		return x.AlreadyDisputed
+	}
+	return 0
+}
+
+func (x *ImportChargebacksResponse) GetUnmatched() []*UnmatchedChargeback {
+	if x != nil {
+		return x.Unmatched
+	}
+	return nil
+}
+
+func (x *ImportChargebacksResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ReconcileSettlementRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Raw CSV file contents
+	Content []byte `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	// Request a balance adjustment for every amount difference
+	AutoAdjust    bool `protobuf:"varint,2,opt,name=auto_adjust,json=autoAdjust,proto3" json:"auto_adjust,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconcileSettlementRequest) Reset() {
+	*x = ReconcileSettlementRequest{}
+	mi := &file_transaction_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconcileSettlementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileSettlementRequest) ProtoMessage() {}
+
+func (x *ReconcileSettlementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileSettlementRequest.ProtoReflect.Descriptor instead.
+func (*ReconcileSettlementRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ReconcileSettlementRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *ReconcileSettlementRequest) GetAutoAdjust() bool {
+	if x != nil {
+		return x.AutoAdjust
+	}
+	return false
+}
+
+// A settlement file line and the transaction with its external_id, if any
+type ReconciliationLine struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One-based line number in the file
+	Line        int32  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	ExternalId  string `protobuf:"bytes,2,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	AmountCents int64  `protobuf:"varint,3,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	// YYYY-MM-DD
+	Date          string `protobuf:"bytes,4,opt,name=date,proto3" json:"date,omitempty"`
+	TransactionId string `protobuf:"bytes,5,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	AccountId     string `protobuf:"bytes,6,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Why the line is mismatched or invalid
+	Reason        string `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconciliationLine) Reset() {
+	*x = ReconciliationLine{}
+	mi := &file_transaction_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconciliationLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconciliationLine) ProtoMessage() {}
+
+func (x *ReconciliationLine) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconciliationLine.ProtoReflect.Descriptor instead.
+func (*ReconciliationLine) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ReconciliationLine) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *ReconciliationLine) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+func (x *ReconciliationLine) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *ReconciliationLine) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *ReconciliationLine) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ReconciliationLine) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ReconciliationLine) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// A PENDING balance adjustment requested for the amount difference of a mismatched line
+type ReconciliationAdjustment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AccountId     string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	TransactionId string                 `protobuf:"bytes,3,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	// CREDIT or DEBIT
+	Direction     string `protobuf:"bytes,4,opt,name=direction,proto3" json:"direction,omitempty"`
+	AmountCents   int64  `protobuf:"varint,5,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconciliationAdjustment) Reset() {
+	*x = ReconciliationAdjustment{}
+	mi := &file_transaction_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconciliationAdjustment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconciliationAdjustment) ProtoMessage() {}
+
+func (x *ReconciliationAdjustment) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconciliationAdjustment.ProtoReflect.Descriptor instead.
+func (*ReconciliationAdjustment) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ReconciliationAdjustment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReconciliationAdjustment) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ReconciliationAdjustment) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ReconciliationAdjustment) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *ReconciliationAdjustment) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+type ReconcileSettlementResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Matched []*ReconciliationLine  `protobuf:"bytes,1,rep,name=matched,proto3" json:"matched,omitempty"`
+	// Lines whose transaction differs in amount, date or status
+	Mismatched []*ReconciliationLine `protobuf:"bytes,2,rep,name=mismatched,proto3" json:"mismatched,omitempty"`
+	// Lines without a transaction
+	Missing []*ReconciliationLine `protobuf:"bytes,3,rep,name=missing,proto3" json:"missing,omitempty"`
+	// Completed transactions with an external_id, created on the dates of the file but absent from it, oldest first
+	Extra []*Transaction `protobuf:"bytes,4,rep,name=extra,proto3" json:"extra,omitempty"`
+	// Set when extra was cut off at its limit
+	ExtraTruncated bool `protobuf:"varint,5,opt,name=extra_truncated,json=extraTruncated,proto3" json:"extra_truncated,omitempty"`
+	// Lines that could not be read
+	Invalid     []*ReconciliationLine       `protobuf:"bytes,6,rep,name=invalid,proto3" json:"invalid,omitempty"`
+	Adjustments []*ReconciliationAdjustment `protobuf:"bytes,7,rep,name=adjustments,proto3" json:"adjustments,omitempty"`
+	// Amount differences already adjusted by an earlier reconciliation
+	AlreadyAdjusted int32  `protobuf:"varint,8,opt,name=already_adjusted,json=alreadyAdjusted,proto3" json:"already_adjusted,omitempty"`
+	Error           string `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ReconcileSettlementResponse) Reset() {
+	*x = ReconcileSettlementResponse{}
+	mi := &file_transaction_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconcileSettlementResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileSettlementResponse) ProtoMessage() {}
+
+func (x *ReconcileSettlementResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileSettlementResponse.ProtoReflect.Descriptor instead.
+func (*ReconcileSettlementResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ReconcileSettlementResponse) GetMatched() []*ReconciliationLine {
+	if x != nil {
+		return x.Matched
+	}
+	return nil
+}
+
+func (x *ReconcileSettlementResponse) GetMismatched() []*ReconciliationLine {
+	if x != nil {
+		return x.Mismatched
+	}
+	return nil
+}
+
+func (x *ReconcileSettlementResponse) GetMissing() []*ReconciliationLine {
+	if x != nil {
+		return x.Missing
+	}
+	return nil
+}
+
+func (x *ReconcileSettlementResponse) GetExtra() []*Transaction {
+	if x != nil {
+		return x.Extra
+	}
+	return nil
+}
+
+func (x *ReconcileSettlementResponse) GetExtraTruncated() bool {
+	if x != nil {
+		return x.ExtraTruncated
+	}
+	return false
+}
+
+func (x *ReconcileSettlementResponse) GetInvalid() []*ReconciliationLine {
+	if x != nil {
+		return x.Invalid
+	}
+	return nil
+}
+
+func (x *ReconcileSettlementResponse) GetAdjustments() []*ReconciliationAdjustment {
+	if x != nil {
+		return x.Adjustments
+	}
+	return nil
+}
+
+func (x *ReconcileSettlementResponse) GetAlreadyAdjusted() int32 {
+	if x != nil {
+		return x.AlreadyAdjusted
+	}
+	return 0
+}
+
+func (x *ReconcileSettlementResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ExportTransactionHistoryRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Time range as Unix seconds, from inclusive and to exclusive; defaults to the whole history
+	From int64 `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+	To   int64 `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`
+	// File format; only csv (the default) is supported
+	Format        string `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportTransactionHistoryRequest) Reset() {
+	*x = ExportTransactionHistoryRequest{}
+	mi := &file_transaction_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportTransactionHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTransactionHistoryRequest) ProtoMessage() {}
+
+func (x *ExportTransactionHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTransactionHistoryRequest.ProtoReflect.Descriptor instead.
+func (*ExportTransactionHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ExportTransactionHistoryRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ExportTransactionHistoryRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *ExportTransactionHistoryRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *ExportTransactionHistoryRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+// A piece of the exported file; concatenating the data of all chunks in order yields the file
+type ExportTransactionHistoryChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Data  []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// Set on the last chunk when the export failed; the data received so far is incomplete
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportTransactionHistoryChunk) Reset() {
+	*x = ExportTransactionHistoryChunk{}
+	mi := &file_transaction_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportTransactionHistoryChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTransactionHistoryChunk) ProtoMessage() {}
+
+func (x *ExportTransactionHistoryChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTransactionHistoryChunk.ProtoReflect.Descriptor instead.
+func (*ExportTransactionHistoryChunk) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ExportTransactionHistoryChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ExportTransactionHistoryChunk) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListStuckTransactionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Minimum age in seconds of the listed PENDING transactions; defaults to 15 minutes
+	OlderThanSeconds int64 `protobuf:"varint,1,opt,name=older_than_seconds,json=olderThanSeconds,proto3" json:"older_than_seconds,omitempty"`
+	Limit            int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListStuckTransactionsRequest) Reset() {
+	*x = ListStuckTransactionsRequest{}
+	mi := &file_transaction_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStuckTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStuckTransactionsRequest) ProtoMessage() {}
+
+func (x *ListStuckTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStuckTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListStuckTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ListStuckTransactionsRequest) GetOlderThanSeconds() int64 {
+	if x != nil {
+		return x.OlderThanSeconds
+	}
+	return 0
+}
+
+func (x *ListStuckTransactionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListStuckTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStuckTransactionsResponse) Reset() {
+	*x = ListStuckTransactionsResponse{}
+	mi := &file_transaction_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStuckTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStuckTransactionsResponse) ProtoMessage() {}
+
+func (x *ListStuckTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStuckTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListStuckTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ListStuckTransactionsResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *ListStuckTransactionsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ResolveStuckTransactionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Ids   []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	// COMPLETE, FAIL or REVERSE
+	Action string `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	// Why the transactions are resolved by hand; kept in the audit trail
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveStuckTransactionsRequest) Reset() {
+	*x = ResolveStuckTransactionsRequest{}
+	mi := &file_transaction_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveStuckTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveStuckTransactionsRequest) ProtoMessage() {}
+
+func (x *ResolveStuckTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveStuckTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ResolveStuckTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ResolveStuckTransactionsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *ResolveStuckTransactionsRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ResolveStuckTransactionsRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Audit record of a stuck transaction resolved by an operator
+type TransactionResolution struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TransactionId  string                 `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Action         string                 `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	PreviousStatus string                 `protobuf:"bytes,4,opt,name=previous_status,json=previousStatus,proto3" json:"previous_status,omitempty"`
+	Status         string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Reason         string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	ResolvedBy     string                 `protobuf:"bytes,7,opt,name=resolved_by,json=resolvedBy,proto3" json:"resolved_by,omitempty"`
+	ResolvedAt     int64                  `protobuf:"varint,8,opt,name=resolved_at,json=resolvedAt,proto3" json:"resolved_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TransactionResolution) Reset() {
+	*x = TransactionResolution{}
+	mi := &file_transaction_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionResolution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionResolution) ProtoMessage() {}
+
+func (x *TransactionResolution) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionResolution.ProtoReflect.Descriptor instead.
+func (*TransactionResolution) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *TransactionResolution) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TransactionResolution) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *TransactionResolution) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *TransactionResolution) GetPreviousStatus() string {
+	if x != nil {
+		return x.PreviousStatus
+	}
+	return ""
+}
+
+func (x *TransactionResolution) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TransactionResolution) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *TransactionResolution) GetResolvedBy() string {
+	if x != nil {
+		return x.ResolvedBy
+	}
+	return ""
+}
+
+func (x *TransactionResolution) GetResolvedAt() int64 {
+	if x != nil {
+		return x.ResolvedAt
+	}
+	return 0
+}
+
+type ResolveStuckTransactionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	// Set when the transaction was resolved
+	Resolution *TransactionResolution `protobuf:"bytes,2,opt,name=resolution,proto3" json:"resolution,omitempty"`
+	// Why the transaction was left unchanged, e.g. because it is no longer PENDING
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveStuckTransactionResult) Reset() {
+	*x = ResolveStuckTransactionResult{}
+	mi := &file_transaction_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveStuckTransactionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveStuckTransactionResult) ProtoMessage() {}
+
+func (x *ResolveStuckTransactionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveStuckTransactionResult.ProtoReflect.Descriptor instead.
+func (*ResolveStuckTransactionResult) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ResolveStuckTransactionResult) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ResolveStuckTransactionResult) GetResolution() *TransactionResolution {
+	if x != nil {
+		return x.Resolution
+	}
+	return nil
+}
+
+func (x *ResolveStuckTransactionResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ResolveStuckTransactionsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per requested ID, in request order
+	Results       []*ResolveStuckTransactionResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Error         string                           `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveStuckTransactionsResponse) Reset() {
+	*x = ResolveStuckTransactionsResponse{}
+	mi := &file_transaction_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveStuckTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveStuckTransactionsResponse) ProtoMessage() {}
+
+func (x *ResolveStuckTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveStuckTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ResolveStuckTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ResolveStuckTransactionsResponse) GetResults() []*ResolveStuckTransactionResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *ResolveStuckTransactionsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// A transaction held for review by fraud scoring, with the review decision once made
+type FlaggedTransaction struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Transaction *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	RiskScore   int32                  `protobuf:"varint,2,opt,name=risk_score,json=riskScore,proto3" json:"risk_score,omitempty"`
+	// Risk factors that contributed to the score, e.g. LARGE_AMOUNT
+	RiskFactors []string `protobuf:"bytes,3,rep,name=risk_factors,json=riskFactors,proto3" json:"risk_factors,omitempty"`
+	FlaggedAt   int64    `protobuf:"varint,4,opt,name=flagged_at,json=flaggedAt,proto3" json:"flagged_at,omitempty"`
+	// APPROVE or DECLINE; empty while the transaction is under review
+	Decision      string `protobuf:"bytes,5,opt,name=decision,proto3" json:"decision,omitempty"`
+	Note          string `protobuf:"bytes,6,opt,name=note,proto3" json:"note,omitempty"`
+	ReviewedBy    string `protobuf:"bytes,7,opt,name=reviewed_by,json=reviewedBy,proto3" json:"reviewed_by,omitempty"`
+	ReviewedAt    int64  `protobuf:"varint,8,opt,name=reviewed_at,json=reviewedAt,proto3" json:"reviewed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlaggedTransaction) Reset() {
+	*x = FlaggedTransaction{}
+	mi := &file_transaction_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlaggedTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlaggedTransaction) ProtoMessage() {}
+
+func (x *FlaggedTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlaggedTransaction.ProtoReflect.Descriptor instead.
+func (*FlaggedTransaction) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *FlaggedTransaction) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *FlaggedTransaction) GetRiskScore() int32 {
+	if x != nil {
+		return x.RiskScore
+	}
+	return 0
+}
+
+func (x *FlaggedTransaction) GetRiskFactors() []string {
+	if x != nil {
+		return x.RiskFactors
+	}
+	return nil
+}
+
+func (x *FlaggedTransaction) GetFlaggedAt() int64 {
+	if x != nil {
+		return x.FlaggedAt
+	}
+	return 0
+}
+
+func (x *FlaggedTransaction) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+func (x *FlaggedTransaction) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *FlaggedTransaction) GetReviewedBy() string {
+	if x != nil {
+		return x.ReviewedBy
+	}
+	return ""
+}
+
+func (x *FlaggedTransaction) GetReviewedAt() int64 {
+	if x != nil {
+		return x.ReviewedAt
+	}
+	return 0
+}
+
+type ListFlaggedTransactionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only list the transactions of this account when set
+	AccountId     string `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Limit         int32  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFlaggedTransactionsRequest) Reset() {
+	*x = ListFlaggedTransactionsRequest{}
+	mi := &file_transaction_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFlaggedTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFlaggedTransactionsRequest) ProtoMessage() {}
+
+func (x *ListFlaggedTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFlaggedTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListFlaggedTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ListFlaggedTransactionsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ListFlaggedTransactionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListFlaggedTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*FlaggedTransaction  `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFlaggedTransactionsResponse) Reset() {
+	*x = ListFlaggedTransactionsResponse{}
+	mi := &file_transaction_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFlaggedTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFlaggedTransactionsResponse) ProtoMessage() {}
+
+func (x *ListFlaggedTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFlaggedTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListFlaggedTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ListFlaggedTransactionsResponse) GetTransactions() []*FlaggedTransaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *ListFlaggedTransactionsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ApproveFlaggedRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Analyst's note kept with the decision
+	Note          string `protobuf:"bytes,2,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveFlaggedRequest) Reset() {
+	*x = ApproveFlaggedRequest{}
+	mi := &file_transaction_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveFlaggedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveFlaggedRequest) ProtoMessage() {}
+
+func (x *ApproveFlaggedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveFlaggedRequest.ProtoReflect.Descriptor instead.
+func (*ApproveFlaggedRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *ApproveFlaggedRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ApproveFlaggedRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type ApproveFlaggedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *FlaggedTransaction    `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveFlaggedResponse) Reset() {
+	*x = ApproveFlaggedResponse{}
+	mi := &file_transaction_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveFlaggedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveFlaggedResponse) ProtoMessage() {}
+
+func (x *ApproveFlaggedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveFlaggedResponse.ProtoReflect.Descriptor instead.
+func (*ApproveFlaggedResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *ApproveFlaggedResponse) GetTransaction() *FlaggedTransaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *ApproveFlaggedResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DeclineFlaggedRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Analyst's note kept with the decision
+	Note          string `protobuf:"bytes,2,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeclineFlaggedRequest) Reset() {
+	*x = DeclineFlaggedRequest{}
+	mi := &file_transaction_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeclineFlaggedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeclineFlaggedRequest) ProtoMessage() {}
+
+func (x *DeclineFlaggedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeclineFlaggedRequest.ProtoReflect.Descriptor instead.
+func (*DeclineFlaggedRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *DeclineFlaggedRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeclineFlaggedRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type DeclineFlaggedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *FlaggedTransaction    `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeclineFlaggedResponse) Reset() {
+	*x = DeclineFlaggedResponse{}
+	mi := &file_transaction_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeclineFlaggedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeclineFlaggedResponse) ProtoMessage() {}
+
+func (x *DeclineFlaggedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeclineFlaggedResponse.ProtoReflect.Descriptor instead.
+func (*DeclineFlaggedResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *DeclineFlaggedResponse) GetTransaction() *FlaggedTransaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *DeclineFlaggedResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Monthly spending limit of one category of an account
+type Budget struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AccountId         string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Category          string                 `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`
+	MonthlyLimitCents int64                  `protobuf:"varint,7,opt,name=monthly_limit_cents,json=monthlyLimitCents,proto3" json:"monthly_limit_cents,omitempty"`
+	// Deprecated: use monthly_limit_cents
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	MonthlyLimit float64 `protobuf:"fixed64,3,opt,name=monthly_limit,json=monthlyLimit,proto3" json:"monthly_limit,omitempty"`
+	// Percentages of the limit; a budget.threshold_crossed event is published when the month's spending crosses one
+	Thresholds    []int32 `protobuf:"varint,4,rep,packed,name=thresholds,proto3" json:"thresholds,omitempty"`
+	CreatedAt     int64   `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     int64   `protobuf:"varint,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Budget) Reset() {
+	*x = Budget{}
+	mi := &file_transaction_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Budget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Budget) ProtoMessage() {}
+
+func (x *Budget) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Budget.ProtoReflect.Descriptor instead.
+func (*Budget) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *Budget) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *Budget) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Budget) GetMonthlyLimitCents() int64 {
+	if x != nil {
+		return x.MonthlyLimitCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
+func (x *Budget) GetMonthlyLimit() float64 {
+	if x != nil {
+		return x.MonthlyLimit
+	}
+	return 0
+}
+
+func (x *Budget) GetThresholds() []int32 {
+	if x != nil {
+		return x.Thresholds
+	}
+	return nil
+}
+
+func (x *Budget) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Budget) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type SetBudgetRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AccountId         string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Category          string                 `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`
+	MonthlyLimitCents int64                  `protobuf:"varint,5,opt,name=monthly_limit_cents,json=monthlyLimitCents,proto3" json:"monthly_limit_cents,omitempty"`
+	// Deprecated: use monthly_limit_cents
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	MonthlyLimit float64 `protobuf:"fixed64,3,opt,name=monthly_limit,json=monthlyLimit,proto3" json:"monthly_limit,omitempty"`
+	// Defaults to 80 and 100
+	Thresholds    []int32 `protobuf:"varint,4,rep,packed,name=thresholds,proto3" json:"thresholds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetBudgetRequest) Reset() {
+	*x = SetBudgetRequest{}
+	mi := &file_transaction_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetBudgetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBudgetRequest) ProtoMessage() {}
+
+func (x *SetBudgetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetBudgetRequest.ProtoReflect.Descriptor instead.
+func (*SetBudgetRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *SetBudgetRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *SetBudgetRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *SetBudgetRequest) GetMonthlyLimitCents() int64 {
+	if x != nil {
+		return x.MonthlyLimitCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
+func (x *SetBudgetRequest) GetMonthlyLimit() float64 {
+	if x != nil {
+		return x.MonthlyLimit
+	}
+	return 0
+}
+
+func (x *SetBudgetRequest) GetThresholds() []int32 {
+	if x != nil {
+		return x.Thresholds
+	}
+	return nil
+}
+
+type SetBudgetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Budget        *Budget                `protobuf:"bytes,1,opt,name=budget,proto3" json:"budget,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetBudgetResponse) Reset() {
+	*x = SetBudgetResponse{}
+	mi := &file_transaction_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetBudgetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetBudgetResponse) ProtoMessage() {}
+
+func (x *SetBudgetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetBudgetResponse.ProtoReflect.Descriptor instead.
+func (*SetBudgetResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *SetBudgetResponse) GetBudget() *Budget {
+	if x != nil {
+		return x.Budget
+	}
+	return nil
+}
+
+func (x *SetBudgetResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DeleteBudgetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Category      string                 `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBudgetRequest) Reset() {
+	*x = DeleteBudgetRequest{}
+	mi := &file_transaction_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBudgetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBudgetRequest) ProtoMessage() {}
+
+func (x *DeleteBudgetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBudgetRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBudgetRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *DeleteBudgetRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *DeleteBudgetRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+type DeleteBudgetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBudgetResponse) Reset() {
+	*x = DeleteBudgetResponse{}
+	mi := &file_transaction_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBudgetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBudgetResponse) ProtoMessage() {}
+
+func (x *DeleteBudgetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBudgetResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBudgetResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *DeleteBudgetResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Spending of one budget's category within a month: completed debits, including approved ones held for review
+type BudgetStatus struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Budget     *Budget                `protobuf:"bytes,1,opt,name=budget,proto3" json:"budget,omitempty"`
+	SpentCents int64                  `protobuf:"varint,6,opt,name=spent_cents,json=spentCents,proto3" json:"spent_cents,omitempty"`
+	// Deprecated: use spent_cents
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	Spent float64 `protobuf:"fixed64,2,opt,name=spent,proto3" json:"spent,omitempty"`
+	// monthly_limit - spent; negative once the budget is exceeded
+	RemainingCents int64 `protobuf:"varint,7,opt,name=remaining_cents,json=remainingCents,proto3" json:"remaining_cents,omitempty"`
+	// Deprecated: use remaining_cents
+	//
+	// Deprecated: Marked as deprecated in transaction.proto.
+	Remaining   float64 `protobuf:"fixed64,3,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	PercentUsed float64 `protobuf:"fixed64,4,opt,name=percent_used,json=percentUsed,proto3" json:"percent_used,omitempty"`
+	// Thresholds the spending has reached, ascending
+	CrossedThresholds []int32 `protobuf:"varint,5,rep,packed,name=crossed_thresholds,json=crossedThresholds,proto3" json:"crossed_thresholds,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *BudgetStatus) Reset() {
+	*x = BudgetStatus{}
+	mi := &file_transaction_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BudgetStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BudgetStatus) ProtoMessage() {}
+
+func (x *BudgetStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BudgetStatus.ProtoReflect.Descriptor instead.
+func (*BudgetStatus) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *BudgetStatus) GetBudget() *Budget {
+	if x != nil {
+		return x.Budget
+	}
+	return nil
+}
+
+func (x *BudgetStatus) GetSpentCents() int64 {
+	if x != nil {
+		return x.SpentCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
+func (x *BudgetStatus) GetSpent() float64 {
+	if x != nil {
+		return x.Spent
+	}
+	return 0
+}
+
+func (x *BudgetStatus) GetRemainingCents() int64 {
+	if x != nil {
+		return x.RemainingCents
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in transaction.proto.
+func (x *BudgetStatus) GetRemaining() float64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *BudgetStatus) GetPercentUsed() float64 {
+	if x != nil {
+		return x.PercentUsed
+	}
+	return 0
+}
+
+func (x *BudgetStatus) GetCrossedThresholds() []int32 {
+	if x != nil {
+		return x.CrossedThresholds
+	}
+	return nil
+}
+
+type GetBudgetStatusRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Month as YYYY-MM in UTC; defaults to the current month
+	Month         string `protobuf:"bytes,2,opt,name=month,proto3" json:"month,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBudgetStatusRequest) Reset() {
+	*x = GetBudgetStatusRequest{}
+	mi := &file_transaction_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBudgetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBudgetStatusRequest) ProtoMessage() {}
+
+func (x *GetBudgetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBudgetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetBudgetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *GetBudgetStatusRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetBudgetStatusRequest) GetMonth() string {
+	if x != nil {
+		return x.Month
+	}
+	return ""
+}
+
+type GetBudgetStatusResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Month string                 `protobuf:"bytes,1,opt,name=month,proto3" json:"month,omitempty"`
+	// Ordered by category
+	Budgets       []*BudgetStatus `protobuf:"bytes,2,rep,name=budgets,proto3" json:"budgets,omitempty"`
+	Error         string          `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBudgetStatusResponse) Reset() {
+	*x = GetBudgetStatusResponse{}
+	mi := &file_transaction_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBudgetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBudgetStatusResponse) ProtoMessage() {}
+
+func (x *GetBudgetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBudgetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetBudgetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *GetBudgetStatusResponse) GetMonth() string {
+	if x != nil {
+		return x.Month
+	}
+	return ""
+}
+
+func (x *GetBudgetStatusResponse) GetBudgets() []*BudgetStatus {
+	if x != nil {
+		return x.Budgets
+	}
+	return nil
+}
+
+func (x *GetBudgetStatusResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Delivery of an event to one subscriber
+type EventDelivery struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Subscriber string                 `protobuf:"bytes,1,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+	// PENDING (not attempted yet), FAILING (attempted, will be retried) or DELIVERED
+	Status   string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Attempts int32  `protobuf:"varint,3,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// Error of the last failed attempt; empty once delivered
+	LastError string `protobuf:"bytes,4,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	// Unix seconds; 0 until delivered
+	DeliveredAt   int64 `protobuf:"varint,5,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventDelivery) Reset() {
+	*x = EventDelivery{}
+	mi := &file_transaction_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventDelivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventDelivery) ProtoMessage() {}
+
+func (x *EventDelivery) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventDelivery.ProtoReflect.Descriptor instead.
+func (*EventDelivery) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *EventDelivery) GetSubscriber() string {
+	if x != nil {
+		return x.Subscriber
+	}
+	return ""
+}
+
+func (x *EventDelivery) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *EventDelivery) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *EventDelivery) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *EventDelivery) GetDeliveredAt() int64 {
+	if x != nil {
+		return x.DeliveredAt
+	}
+	return 0
+}
+
+// An event generated for an account
+type AccountEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	EventType     string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Deliveries    []*EventDelivery       `protobuf:"bytes,4,rep,name=deliveries,proto3" json:"deliveries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountEvent) Reset() {
+	*x = AccountEvent{}
+	mi := &file_transaction_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountEvent) ProtoMessage() {}
+
+func (x *AccountEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountEvent.ProtoReflect.Descriptor instead.
+func (*AccountEvent) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *AccountEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *AccountEvent) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *AccountEvent) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *AccountEvent) GetDeliveries() []*EventDelivery {
+	if x != nil {
+		return x.Deliveries
+	}
+	return nil
+}
+
+type ListEventDeliveriesRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Optional filters
+	EventType string `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	// PENDING, FAILING or DELIVERED
+	Status string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Limit  int32  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Opaque token from a previous response's next_page_token
+	PageToken     string `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEventDeliveriesRequest) Reset() {
+	*x = ListEventDeliveriesRequest{}
+	mi := &file_transaction_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEventDeliveriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEventDeliveriesRequest) ProtoMessage() {}
+
+func (x *ListEventDeliveriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEventDeliveriesRequest.ProtoReflect.Descriptor instead.
+func (*ListEventDeliveriesRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *ListEventDeliveriesRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ListEventDeliveriesRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *ListEventDeliveriesRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListEventDeliveriesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListEventDeliveriesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListEventDeliveriesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Ordered by creation time, newest first
+	Events []*AccountEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	Error  string          `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Token for the next page; empty when there are no more results
+	NextPageToken string `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEventDeliveriesResponse) Reset() {
+	*x = ListEventDeliveriesResponse{}
+	mi := &file_transaction_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEventDeliveriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEventDeliveriesResponse) ProtoMessage() {}
+
+func (x *ListEventDeliveriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEventDeliveriesResponse.ProtoReflect.Descriptor instead.
+func (*ListEventDeliveriesResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *ListEventDeliveriesResponse) GetEvents() []*AccountEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *ListEventDeliveriesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ListEventDeliveriesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// A secret event deliveries to a subscriber are signed with
+type WebhookSigningKey struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Subscriber string                 `protobuf:"bytes,1,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+	// Named in the X-Webhook-Signature header next to the signature made with the key
+	KeyId string `protobuf:"bytes,2,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	// Only set in the response that created the key
+	Secret string `protobuf:"bytes,3,opt,name=secret,proto3" json:"secret,omitempty"`
+	// ACTIVE or RETIRED
+	Status        string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedBy     string `protobuf:"bytes,5,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt     int64  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	RetiredBy     string `protobuf:"bytes,7,opt,name=retired_by,json=retiredBy,proto3" json:"retired_by,omitempty"`
+	RetiredAt     int64  `protobuf:"varint,8,opt,name=retired_at,json=retiredAt,proto3" json:"retired_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookSigningKey) Reset() {
+	*x = WebhookSigningKey{}
+	mi := &file_transaction_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookSigningKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookSigningKey) ProtoMessage() {}
+
+func (x *WebhookSigningKey) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookSigningKey.ProtoReflect.Descriptor instead.
+func (*WebhookSigningKey) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *WebhookSigningKey) GetSubscriber() string {
+	if x != nil {
+		return x.Subscriber
+	}
+	return ""
+}
+
+func (x *WebhookSigningKey) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *WebhookSigningKey) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *WebhookSigningKey) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *WebhookSigningKey) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *WebhookSigningKey) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *WebhookSigningKey) GetRetiredBy() string {
+	if x != nil {
+		return x.RetiredBy
+	}
+	return ""
+}
+
+func (x *WebhookSigningKey) GetRetiredAt() int64 {
+	if x != nil {
+		return x.RetiredAt
+	}
+	return 0
+}
+
+type ListWebhookSigningKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subscriber    string                 `protobuf:"bytes,1,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhookSigningKeysRequest) Reset() {
+	*x = ListWebhookSigningKeysRequest{}
+	mi := &file_transaction_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhookSigningKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhookSigningKeysRequest) ProtoMessage() {}
+
+func (x *ListWebhookSigningKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhookSigningKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListWebhookSigningKeysRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *ListWebhookSigningKeysRequest) GetSubscriber() string {
+	if x != nil {
+		return x.Subscriber
+	}
+	return ""
+}
+
+type ListWebhookSigningKeysResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first
+	Keys          []*WebhookSigningKey `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	Error         string               `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWebhookSigningKeysResponse) Reset() {
+	*x = ListWebhookSigningKeysResponse{}
+	mi := &file_transaction_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWebhookSigningKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWebhookSigningKeysResponse) ProtoMessage() {}
+
+func (x *ListWebhookSigningKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWebhookSigningKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListWebhookSigningKeysResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *ListWebhookSigningKeysResponse) GetKeys() []*WebhookSigningKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *ListWebhookSigningKeysResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CreateWebhookSigningKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subscriber    string                 `protobuf:"bytes,1,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWebhookSigningKeyRequest) Reset() {
+	*x = CreateWebhookSigningKeyRequest{}
+	mi := &file_transaction_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWebhookSigningKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWebhookSigningKeyRequest) ProtoMessage() {}
+
+func (x *CreateWebhookSigningKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWebhookSigningKeyRequest.ProtoReflect.Descriptor instead.
+func (*CreateWebhookSigningKeyRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *CreateWebhookSigningKeyRequest) GetSubscriber() string {
+	if x != nil {
+		return x.Subscriber
+	}
+	return ""
+}
+
+type RetireWebhookSigningKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subscriber    string                 `protobuf:"bytes,1,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+	KeyId         string                 `protobuf:"bytes,2,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetireWebhookSigningKeyRequest) Reset() {
+	*x = RetireWebhookSigningKeyRequest{}
+	mi := &file_transaction_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetireWebhookSigningKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetireWebhookSigningKeyRequest) ProtoMessage() {}
+
+func (x *RetireWebhookSigningKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetireWebhookSigningKeyRequest.ProtoReflect.Descriptor instead.
+func (*RetireWebhookSigningKeyRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *RetireWebhookSigningKeyRequest) GetSubscriber() string {
+	if x != nil {
+		return x.Subscriber
+	}
+	return ""
+}
+
+func (x *RetireWebhookSigningKeyRequest) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+type WebhookSigningKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           *WebhookSigningKey     `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebhookSigningKeyResponse) Reset() {
+	*x = WebhookSigningKeyResponse{}
+	mi := &file_transaction_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebhookSigningKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebhookSigningKeyResponse) ProtoMessage() {}
+
+func (x *WebhookSigningKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebhookSigningKeyResponse.ProtoReflect.Descriptor instead.
+func (*WebhookSigningKeyResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *WebhookSigningKeyResponse) GetKey() *WebhookSigningKey {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *WebhookSigningKeyResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type StreamTransactionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Time range as Unix seconds, from inclusive and to exclusive; defaults to all transactions
+	From int64 `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	To   int64 `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	// Optional filters
+	AccountId     string `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Status        string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	OperationType string `protobuf:"bytes,5,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTransactionsRequest) Reset() {
+	*x = StreamTransactionsRequest{}
+	mi := &file_transaction_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTransactionsRequest) ProtoMessage() {}
+
+func (x *StreamTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*StreamTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *StreamTransactionsRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *StreamTransactionsRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *StreamTransactionsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *StreamTransactionsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *StreamTransactionsRequest) GetOperationType() string {
+	if x != nil {
+		return x.OperationType
+	}
+	return ""
+}
+
+// A batch of streamed transactions. Request errors are reported in the first message and a failure part way
+// through in the last one; the transactions received so far are then incomplete.
+type StreamTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTransactionsResponse) Reset() {
+	*x = StreamTransactionsResponse{}
+	mi := &file_transaction_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTransactionsResponse) ProtoMessage() {}
+
+func (x *StreamTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*StreamTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *StreamTransactionsResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *StreamTransactionsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetSLOStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSLOStatusRequest) Reset() {
+	*x = GetSLOStatusRequest{}
+	mi := &file_transaction_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSLOStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSLOStatusRequest) ProtoMessage() {}
+
+func (x *GetSLOStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSLOStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetSLOStatusRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{75}
+}
+
+// Performance of a method over one window
+type SLOWindowStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WindowSeconds int64                  `protobuf:"varint,1,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// Calls that failed on the server's side or were slower than the latency target
+	Bad            int64   `protobuf:"varint,3,opt,name=bad,proto3" json:"bad,omitempty"`
+	SuccessPercent float64 `protobuf:"fixed64,4,opt,name=success_percent,json=successPercent,proto3" json:"success_percent,omitempty"`
+	// How fast the window spends the error budget; 1 spends exactly what the target allows
+	BurnRate      float64 `protobuf:"fixed64,5,opt,name=burn_rate,json=burnRate,proto3" json:"burn_rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SLOWindowStatus) Reset() {
+	*x = SLOWindowStatus{}
+	mi := &file_transaction_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SLOWindowStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SLOWindowStatus) ProtoMessage() {}
+
+func (x *SLOWindowStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SLOWindowStatus.ProtoReflect.Descriptor instead.
+func (*SLOWindowStatus) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *SLOWindowStatus) GetWindowSeconds() int64 {
+	if x != nil {
+		return x.WindowSeconds
+	}
+	return 0
+}
+
+func (x *SLOWindowStatus) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SLOWindowStatus) GetBad() int64 {
+	if x != nil {
+		return x.Bad
+	}
+	return 0
+}
+
+func (x *SLOWindowStatus) GetSuccessPercent() float64 {
+	if x != nil {
+		return x.SuccessPercent
+	}
+	return 0
+}
+
+func (x *SLOWindowStatus) GetBurnRate() float64 {
+	if x != nil {
+		return x.BurnRate
+	}
+	return 0
+}
+
+// SLO status of a method with a configured target
+type MethodSLOStatus struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Method             string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	AvailabilityTarget float64                `protobuf:"fixed64,2,opt,name=availability_target,json=availabilityTarget,proto3" json:"availability_target,omitempty"`
+	LatencyTargetMs    int64                  `protobuf:"varint,3,opt,name=latency_target_ms,json=latencyTargetMs,proto3" json:"latency_target_ms,omitempty"`
+	// 5 minute, 1 hour and 6 hour windows
+	Windows []*SLOWindowStatus `protobuf:"bytes,4,rep,name=windows,proto3" json:"windows,omitempty"`
+	// Whether the burn rate over the longest window is at most 1
+	WithinBudget  bool `protobuf:"varint,5,opt,name=within_budget,json=withinBudget,proto3" json:"within_budget,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MethodSLOStatus) Reset() {
+	*x = MethodSLOStatus{}
+	mi := &file_transaction_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MethodSLOStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MethodSLOStatus) ProtoMessage() {}
+
+func (x *MethodSLOStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MethodSLOStatus.ProtoReflect.Descriptor instead.
+func (*MethodSLOStatus) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *MethodSLOStatus) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *MethodSLOStatus) GetAvailabilityTarget() float64 {
+	if x != nil {
+		return x.AvailabilityTarget
+	}
+	return 0
+}
+
+func (x *MethodSLOStatus) GetLatencyTargetMs() int64 {
+	if x != nil {
+		return x.LatencyTargetMs
+	}
+	return 0
+}
+
+func (x *MethodSLOStatus) GetWindows() []*SLOWindowStatus {
+	if x != nil {
+		return x.Windows
+	}
+	return nil
+}
+
+func (x *MethodSLOStatus) GetWithinBudget() bool {
+	if x != nil {
+		return x.WithinBudget
+	}
+	return false
+}
+
+type GetSLOStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Methods       []*MethodSLOStatus     `protobuf:"bytes,1,rep,name=methods,proto3" json:"methods,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSLOStatusResponse) Reset() {
+	*x = GetSLOStatusResponse{}
+	mi := &file_transaction_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSLOStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSLOStatusResponse) ProtoMessage() {}
+
+func (x *GetSLOStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSLOStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetSLOStatusResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *GetSLOStatusResponse) GetMethods() []*MethodSLOStatus {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+func (x *GetSLOStatusResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// A group of transactions loaded together; counts and status are derived from its items
+type TransactionBatch struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Where the items come from, e.g. a file name or settlement run
+	Source        string `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	ExpectedCount int32  `protobuf:"varint,3,opt,name=expected_count,json=expectedCount,proto3" json:"expected_count,omitempty"`
+	Succeeded     int32  `protobuf:"varint,4,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	// Items whose latest attempt failed and that have not been accepted since
+	Failed int32 `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`
+	// OPEN, COMPLETED or COMPLETED_WITH_ERRORS
+	Status        string `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt     int64  `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransactionBatch) Reset() {
+	*x = TransactionBatch{}
+	mi := &file_transaction_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionBatch) ProtoMessage() {}
+
+func (x *TransactionBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionBatch.ProtoReflect.Descriptor instead.
+func (*TransactionBatch) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *TransactionBatch) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TransactionBatch) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *TransactionBatch) GetExpectedCount() int32 {
+	if x != nil {
+		return x.ExpectedCount
+	}
+	return 0
+}
+
+func (x *TransactionBatch) GetSucceeded() int32 {
+	if x != nil {
+		return x.Succeeded
+	}
+	return 0
+}
+
+func (x *TransactionBatch) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *TransactionBatch) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TransactionBatch) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+// The latest failed attempt of a batch item
+type BatchFailure struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Item          int32                  `protobuf:"varint,1,opt,name=item,proto3" json:"item,omitempty"`
+	AccountId     string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	ExternalId    string                 `protobuf:"bytes,3,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	FailedAt      int64                  `protobuf:"varint,5,opt,name=failed_at,json=failedAt,proto3" json:"failed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchFailure) Reset() {
+	*x = BatchFailure{}
+	mi := &file_transaction_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchFailure) ProtoMessage() {}
+
+func (x *BatchFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchFailure.ProtoReflect.Descriptor instead.
+func (*BatchFailure) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *BatchFailure) GetItem() int32 {
+	if x != nil {
+		return x.Item
+	}
+	return 0
+}
+
+func (x *BatchFailure) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *BatchFailure) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+func (x *BatchFailure) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *BatchFailure) GetFailedAt() int64 {
+	if x != nil {
+		return x.FailedAt
+	}
+	return 0
+}
+
+type CreateBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	ExpectedCount int32                  `protobuf:"varint,2,opt,name=expected_count,json=expectedCount,proto3" json:"expected_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBatchRequest) Reset() {
+	*x = CreateBatchRequest{}
+	mi := &file_transaction_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBatchRequest) ProtoMessage() {}
+
+func (x *CreateBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBatchRequest.ProtoReflect.Descriptor instead.
+func (*CreateBatchRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{81}
+}
+
+func (x *CreateBatchRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *CreateBatchRequest) GetExpectedCount() int32 {
+	if x != nil {
+		return x.ExpectedCount
+	}
+	return 0
+}
+
+type CreateBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Batch         *TransactionBatch      `protobuf:"bytes,1,opt,name=batch,proto3" json:"batch,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBatchResponse) Reset() {
+	*x = CreateBatchResponse{}
+	mi := &file_transaction_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBatchResponse) ProtoMessage() {}
+
+func (x *CreateBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBatchResponse.ProtoReflect.Descriptor instead.
+func (*CreateBatchResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *CreateBatchResponse) GetBatch() *TransactionBatch {
+	if x != nil {
+		return x.Batch
+	}
+	return nil
+}
+
+func (x *CreateBatchResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetBatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Failures are listed by item; defaults to 100, at most 1000
+	FailuresLimit     int32 `protobuf:"varint,2,opt,name=failures_limit,json=failuresLimit,proto3" json:"failures_limit,omitempty"`
+	FailuresAfterItem int32 `protobuf:"varint,3,opt,name=failures_after_item,json=failuresAfterItem,proto3" json:"failures_after_item,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GetBatchRequest) Reset() {
+	*x = GetBatchRequest{}
+	mi := &file_transaction_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBatchRequest) ProtoMessage() {}
+
+func (x *GetBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBatchRequest.ProtoReflect.Descriptor instead.
+func (*GetBatchRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *GetBatchRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetBatchRequest) GetFailuresLimit() int32 {
+	if x != nil {
+		return x.FailuresLimit
+	}
+	return 0
+}
+
+func (x *GetBatchRequest) GetFailuresAfterItem() int32 {
+	if x != nil {
+		return x.FailuresAfterItem
+	}
+	return 0
+}
+
+type GetBatchResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Batch    *TransactionBatch      `protobuf:"bytes,1,opt,name=batch,proto3" json:"batch,omitempty"`
+	Failures []*BatchFailure        `protobuf:"bytes,2,rep,name=failures,proto3" json:"failures,omitempty"`
+	// Set when more failures follow; pass as failures_after_item
+	NextFailuresAfterItem int32  `protobuf:"varint,3,opt,name=next_failures_after_item,json=nextFailuresAfterItem,proto3" json:"next_failures_after_item,omitempty"`
+	Error                 string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *GetBatchResponse) Reset() {
+	*x = GetBatchResponse{}
+	mi := &file_transaction_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBatchResponse) ProtoMessage() {}
+
+func (x *GetBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBatchResponse.ProtoReflect.Descriptor instead.
+func (*GetBatchResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{84}
+}
+
+func (x *GetBatchResponse) GetBatch() *TransactionBatch {
+	if x != nil {
+		return x.Batch
+	}
+	return nil
+}
+
+func (x *GetBatchResponse) GetFailures() []*BatchFailure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+func (x *GetBatchResponse) GetNextFailuresAfterItem() int32 {
+	if x != nil {
+		return x.NextFailuresAfterItem
+	}
+	return 0
+}
+
+func (x *GetBatchResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Totals of one operation type within a statement
+type StatementTotal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OperationType string                 `protobuf:"bytes,1,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	TotalCents    int64                  `protobuf:"varint,3,opt,name=total_cents,json=totalCents,proto3" json:"total_cents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatementTotal) Reset() {
+	*x = StatementTotal{}
+	mi := &file_transaction_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatementTotal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatementTotal) ProtoMessage() {}
+
+func (x *StatementTotal) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatementTotal.ProtoReflect.Descriptor instead.
+func (*StatementTotal) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{85}
+}
+
+func (x *StatementTotal) GetOperationType() string {
+	if x != nil {
+		return x.OperationType
+	}
+	return ""
+}
+
+func (x *StatementTotal) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *StatementTotal) GetTotalCents() int64 {
+	if x != nil {
+		return x.TotalCents
+	}
+	return 0
+}
+
+// Monthly statement of an account; amounts count completed transactions, reversed ones offset by their reversal
+type Statement struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Derived from the account and period, so it is the same wherever and whenever the statement is generated
+	Id        string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AccountId string `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Calendar month as YYYY-MM in UTC
+	Period string `protobuf:"bytes,3,opt,name=period,proto3" json:"period,omitempty"`
+	// Bounds of the month as Unix seconds, start inclusive and end exclusive
+	PeriodStart         int64 `protobuf:"varint,4,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"`
+	PeriodEnd           int64 `protobuf:"varint,5,opt,name=period_end,json=periodEnd,proto3" json:"period_end,omitempty"`
+	OpeningBalanceCents int64 `protobuf:"varint,6,opt,name=opening_balance_cents,json=openingBalanceCents,proto3" json:"opening_balance_cents,omitempty"`
+	ClosingBalanceCents int64 `protobuf:"varint,7,opt,name=closing_balance_cents,json=closingBalanceCents,proto3" json:"closing_balance_cents,omitempty"`
+	// Ordered by operation type
+	Totals           []*StatementTotal `protobuf:"bytes,8,rep,name=totals,proto3" json:"totals,omitempty"`
+	TransactionCount int64             `protobuf:"varint,9,opt,name=transaction_count,json=transactionCount,proto3" json:"transaction_count,omitempty"`
+	// Net balance adjustments approved within the month
+	AdjustmentsCents int64 `protobuf:"varint,10,opt,name=adjustments_cents,json=adjustmentsCents,proto3" json:"adjustments_cents,omitempty"`
+	GeneratedAt      int64 `protobuf:"varint,11,opt,name=generated_at,json=generatedAt,proto3" json:"generated_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Statement) Reset() {
+	*x = Statement{}
+	mi := &file_transaction_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Statement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Statement) ProtoMessage() {}
+
+func (x *Statement) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Statement.ProtoReflect.Descriptor instead.
+func (*Statement) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{86}
+}
+
+func (x *Statement) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Statement) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *Statement) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *Statement) GetPeriodStart() int64 {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return 0
+}
+
+func (x *Statement) GetPeriodEnd() int64 {
+	if x != nil {
+		return x.PeriodEnd
+	}
+	return 0
+}
+
+func (x *Statement) GetOpeningBalanceCents() int64 {
+	if x != nil {
+		return x.OpeningBalanceCents
+	}
+	return 0
+}
+
+func (x *Statement) GetClosingBalanceCents() int64 {
+	if x != nil {
+		return x.ClosingBalanceCents
+	}
+	return 0
+}
+
+func (x *Statement) GetTotals() []*StatementTotal {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+func (x *Statement) GetTransactionCount() int64 {
+	if x != nil {
+		return x.TransactionCount
+	}
+	return 0
+}
+
+func (x *Statement) GetAdjustmentsCents() int64 {
+	if x != nil {
+		return x.AdjustmentsCents
+	}
+	return 0
+}
+
+func (x *Statement) GetGeneratedAt() int64 {
+	if x != nil {
+		return x.GeneratedAt
+	}
+	return 0
+}
+
+type GenerateStatementRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Calendar month as YYYY-MM in UTC
+	Period        string `protobuf:"bytes,2,opt,name=period,proto3" json:"period,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateStatementRequest) Reset() {
+	*x = GenerateStatementRequest{}
+	mi := &file_transaction_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateStatementRequest) ProtoMessage() {}
+
+func (x *GenerateStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateStatementRequest.ProtoReflect.Descriptor instead.
+func (*GenerateStatementRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{87}
+}
+
+func (x *GenerateStatementRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GenerateStatementRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+// Identifies a statement either by id or by account_id and period
+type GetStatementRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Period        string                 `protobuf:"bytes,2,opt,name=period,proto3" json:"period,omitempty"`
+	Id            string                 `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatementRequest) Reset() {
+	*x = GetStatementRequest{}
+	mi := &file_transaction_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatementRequest) ProtoMessage() {}
+
+func (x *GetStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[88]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatementRequest.ProtoReflect.Descriptor instead.
+func (*GetStatementRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{88}
+}
+
+func (x *GetStatementRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *GetStatementRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *GetStatementRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type StatementResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Statement     *Statement             `protobuf:"bytes,1,opt,name=statement,proto3" json:"statement,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatementResponse) Reset() {
+	*x = StatementResponse{}
+	mi := &file_transaction_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatementResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatementResponse) ProtoMessage() {}
+
+func (x *StatementResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatementResponse.ProtoReflect.Descriptor instead.
+func (*StatementResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *StatementResponse) GetStatement() *Statement {
+	if x != nil {
+		return x.Statement
+	}
+	return nil
+}
+
+func (x *StatementResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// A UTC business day. Totals are set once the day is closed.
+type BusinessDay struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// YYYY-MM-DD
+	Date     string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	DayStart int64  `protobuf:"varint,2,opt,name=day_start,json=dayStart,proto3" json:"day_start,omitempty"`
+	DayEnd   int64  `protobuf:"varint,3,opt,name=day_end,json=dayEnd,proto3" json:"day_end,omitempty"`
+	// OPEN, AWAITING_CLOSE or CLOSED
+	Status           string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	ClosedAt         int64  `protobuf:"varint,5,opt,name=closed_at,json=closedAt,proto3" json:"closed_at,omitempty"`
+	ClosedBy         string `protobuf:"bytes,6,opt,name=closed_by,json=closedBy,proto3" json:"closed_by,omitempty"`
+	AccountCount     int64  `protobuf:"varint,7,opt,name=account_count,json=accountCount,proto3" json:"account_count,omitempty"`
+	TransactionCount int64  `protobuf:"varint,8,opt,name=transaction_count,json=transactionCount,proto3" json:"transaction_count,omitempty"`
+	NetAmountCents   int64  `protobuf:"varint,9,opt,name=net_amount_cents,json=netAmountCents,proto3" json:"net_amount_cents,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BusinessDay) Reset() {
+	*x = BusinessDay{}
+	mi := &file_transaction_proto_msgTypes[90]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BusinessDay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BusinessDay) ProtoMessage() {}
+
+func (x *BusinessDay) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[90]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BusinessDay.ProtoReflect.Descriptor instead.
+func (*BusinessDay) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{90}
+}
+
+func (x *BusinessDay) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *BusinessDay) GetDayStart() int64 {
+	if x != nil {
+		return x.DayStart
+	}
+	return 0
+}
+
+func (x *BusinessDay) GetDayEnd() int64 {
+	if x != nil {
+		return x.DayEnd
+	}
+	return 0
+}
+
+func (x *BusinessDay) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *BusinessDay) GetClosedAt() int64 {
+	if x != nil {
+		return x.ClosedAt
+	}
+	return 0
+}
+
+func (x *BusinessDay) GetClosedBy() string {
+	if x != nil {
+		return x.ClosedBy
+	}
+	return ""
+}
+
+func (x *BusinessDay) GetAccountCount() int64 {
+	if x != nil {
+		return x.AccountCount
+	}
+	return 0
+}
+
+func (x *BusinessDay) GetTransactionCount() int64 {
+	if x != nil {
+		return x.TransactionCount
+	}
+	return 0
+}
+
+func (x *BusinessDay) GetNetAmountCents() int64 {
+	if x != nil {
+		return x.NetAmountCents
+	}
+	return 0
+}
+
+// The balance of an account recorded by the close of a business day, and the completed transactions of the day
+type EndOfDayBalance struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	AccountId        string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Date             string                 `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	BalanceCents     int64                  `protobuf:"varint,3,opt,name=balance_cents,json=balanceCents,proto3" json:"balance_cents,omitempty"`
+	TransactionCount int64                  `protobuf:"varint,4,opt,name=transaction_count,json=transactionCount,proto3" json:"transaction_count,omitempty"`
+	NetAmountCents   int64                  `protobuf:"varint,5,opt,name=net_amount_cents,json=netAmountCents,proto3" json:"net_amount_cents,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *EndOfDayBalance) Reset() {
+	*x = EndOfDayBalance{}
+	mi := &file_transaction_proto_msgTypes[91]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndOfDayBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndOfDayBalance) ProtoMessage() {}
+
+func (x *EndOfDayBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[91]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EndOfDayBalance.ProtoReflect.Descriptor instead.
+func (*EndOfDayBalance) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{91}
+}
+
+func (x *EndOfDayBalance) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *EndOfDayBalance) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *EndOfDayBalance) GetBalanceCents() int64 {
+	if x != nil {
+		return x.BalanceCents
+	}
+	return 0
+}
+
+func (x *EndOfDayBalance) GetTransactionCount() int64 {
+	if x != nil {
+		return x.TransactionCount
+	}
+	return 0
+}
+
+func (x *EndOfDayBalance) GetNetAmountCents() int64 {
+	if x != nil {
+		return x.NetAmountCents
+	}
+	return 0
+}
+
+type GetBusinessDayStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// YYYY-MM-DD; the current day if empty
+	Date string `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	// Optional; returns the balance of the account recorded by the close
+	AccountId     string `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBusinessDayStatusRequest) Reset() {
+	*x = GetBusinessDayStatusRequest{}
+	mi := &file_transaction_proto_msgTypes[92]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBusinessDayStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBusinessDayStatusRequest) ProtoMessage() {}
+
+func (x *GetBusinessDayStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[92]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBusinessDayStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetBusinessDayStatusRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{92}
+}
+
+func (x *GetBusinessDayStatusRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *GetBusinessDayStatusRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+type BusinessDayStatusResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Day   *BusinessDay           `protobuf:"bytes,1,opt,name=day,proto3" json:"day,omitempty"`
+	// The last day closed, empty if none
+	LastClosedDate string           `protobuf:"bytes,2,opt,name=last_closed_date,json=lastClosedDate,proto3" json:"last_closed_date,omitempty"`
+	Balance        *EndOfDayBalance `protobuf:"bytes,3,opt,name=balance,proto3" json:"balance,omitempty"`
+	Error          string           `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BusinessDayStatusResponse) Reset() {
+	*x = BusinessDayStatusResponse{}
+	mi := &file_transaction_proto_msgTypes[93]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BusinessDayStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BusinessDayStatusResponse) ProtoMessage() {}
+
+func (x *BusinessDayStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[93]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BusinessDayStatusResponse.ProtoReflect.Descriptor instead.
+func (*BusinessDayStatusResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{93}
+}
+
+func (x *BusinessDayStatusResponse) GetDay() *BusinessDay {
+	if x != nil {
+		return x.Day
+	}
+	return nil
+}
+
+func (x *BusinessDayStatusResponse) GetLastClosedDate() string {
+	if x != nil {
+		return x.LastClosedDate
+	}
+	return ""
+}
+
+func (x *BusinessDayStatusResponse) GetBalance() *EndOfDayBalance {
+	if x != nil {
+		return x.Balance
+	}
+	return nil
+}
+
+func (x *BusinessDayStatusResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// A rule making a transaction on an account on a schedule. Each scheduled run makes one transaction, linked to the
+// rule and the run; a run whose transaction is declined, e.g. for an inactive account, is skipped with its error.
+type RecurringRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AccountId     string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	OperationType string                 `protobuf:"bytes,3,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
+	AmountCents   int64                  `protobuf:"varint,4,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	// DAILY, WEEKLY or MONTHLY; monthly runs on the day of the month of start_at, or the month's last day
+	Frequency string `protobuf:"bytes,6,opt,name=frequency,proto3" json:"frequency,omitempty"`
+	StartAt   int64  `protobuf:"varint,7,opt,name=start_at,json=startAt,proto3" json:"start_at,omitempty"`
+	// Optional; no run is made after it
+	EndAt int64 `protobuf:"varint,8,opt,name=end_at,json=endAt,proto3" json:"end_at,omitempty"`
+	// Zero once the rule is no longer active
+	NextRunAt int64 `protobuf:"varint,9,opt,name=next_run_at,json=nextRunAt,proto3" json:"next_run_at,omitempty"`
+	// ACTIVE, CANCELLED or COMPLETED
+	Status string `protobuf:"bytes,10,opt,name=status,proto3" json:"status,omitempty"`
+	// Runs that made a transaction, and runs skipped
+	RunCount          int32  `protobuf:"varint,11,opt,name=run_count,json=runCount,proto3" json:"run_count,omitempty"`
+	SkippedCount      int32  `protobuf:"varint,12,opt,name=skipped_count,json=skippedCount,proto3" json:"skipped_count,omitempty"`
+	LastRunAt         int64  `protobuf:"varint,13,opt,name=last_run_at,json=lastRunAt,proto3" json:"last_run_at,omitempty"`
+	LastTransactionId string `protobuf:"bytes,14,opt,name=last_transaction_id,json=lastTransactionId,proto3" json:"last_transaction_id,omitempty"`
+	// Error of the last skipped run, cleared by the next run that makes a transaction
+	LastError     string `protobuf:"bytes,15,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	CreatedBy     string `protobuf:"bytes,16,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt     int64  `protobuf:"varint,17,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CancelledAt   int64  `protobuf:"varint,18,opt,name=cancelled_at,json=cancelledAt,proto3" json:"cancelled_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecurringRule) Reset() {
+	*x = RecurringRule{}
+	mi := &file_transaction_proto_msgTypes[94]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecurringRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecurringRule) ProtoMessage() {}
+
+func (x *RecurringRule) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[94]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecurringRule.ProtoReflect.Descriptor instead.
+func (*RecurringRule) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{94}
+}
+
+func (x *RecurringRule) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RecurringRule) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *RecurringRule) GetOperationType() string {
+	if x != nil {
+		return x.OperationType
+	}
+	return ""
+}
+
+func (x *RecurringRule) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *RecurringRule) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *RecurringRule) GetFrequency() string {
+	if x != nil {
+		return x.Frequency
+	}
+	return ""
+}
+
+func (x *RecurringRule) GetStartAt() int64 {
+	if x != nil {
+		return x.StartAt
+	}
+	return 0
+}
+
+func (x *RecurringRule) GetEndAt() int64 {
+	if x != nil {
+		return x.EndAt
+	}
+	return 0
+}
+
+func (x *RecurringRule) GetNextRunAt() int64 {
+	if x != nil {
+		return x.NextRunAt
+	}
+	return 0
+}
+
+func (x *RecurringRule) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RecurringRule) GetRunCount() int32 {
+	if x != nil {
+		return x.RunCount
+	}
+	return 0
+}
+
+func (x *RecurringRule) GetSkippedCount() int32 {
+	if x != nil {
+		return x.SkippedCount
+	}
+	return 0
+}
+
+func (x *RecurringRule) GetLastRunAt() int64 {
+	if x != nil {
+		return x.LastRunAt
+	}
+	return 0
+}
+
+func (x *RecurringRule) GetLastTransactionId() string {
+	if x != nil {
+		return x.LastTransactionId
+	}
+	return ""
+}
+
+func (x *RecurringRule) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *RecurringRule) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *RecurringRule) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *RecurringRule) GetCancelledAt() int64 {
+	if x != nil {
+		return x.CancelledAt
+	}
+	return 0
+}
+
+type CreateRecurringRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	OperationType string                 `protobuf:"bytes,2,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
+	AmountCents   int64                  `protobuf:"varint,3,opt,name=amount_cents,json=amountCents,proto3" json:"amount_cents,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Frequency     string                 `protobuf:"bytes,5,opt,name=frequency,proto3" json:"frequency,omitempty"`
+	// Time of the first run; now if zero
+	StartAt       int64 `protobuf:"varint,6,opt,name=start_at,json=startAt,proto3" json:"start_at,omitempty"`
+	EndAt         int64 `protobuf:"varint,7,opt,name=end_at,json=endAt,proto3" json:"end_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateRecurringRuleRequest) Reset() {
+	*x = CreateRecurringRuleRequest{}
+	mi := &file_transaction_proto_msgTypes[95]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRecurringRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRecurringRuleRequest) ProtoMessage() {}
+
+func (x *CreateRecurringRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[95]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRecurringRuleRequest.ProtoReflect.Descriptor instead.
+func (*CreateRecurringRuleRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{95}
+}
+
+func (x *CreateRecurringRuleRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *CreateRecurringRuleRequest) GetOperationType() string {
+	if x != nil {
+		return x.OperationType
+	}
+	return ""
+}
+
+func (x *CreateRecurringRuleRequest) GetAmountCents() int64 {
+	if x != nil {
+		return x.AmountCents
+	}
+	return 0
+}
+
+func (x *CreateRecurringRuleRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateRecurringRuleRequest) GetFrequency() string {
+	if x != nil {
+		return x.Frequency
+	}
+	return ""
+}
+
+func (x *CreateRecurringRuleRequest) GetStartAt() int64 {
+	if x != nil {
+		return x.StartAt
+	}
+	return 0
+}
+
+func (x *CreateRecurringRuleRequest) GetEndAt() int64 {
+	if x != nil {
+		return x.EndAt
+	}
+	return 0
+}
+
+type ListRecurringRulesRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AccountId string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Optional; ACTIVE, CANCELLED or COMPLETED
+	Status        string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecurringRulesRequest) Reset() {
+	*x = ListRecurringRulesRequest{}
+	mi := &file_transaction_proto_msgTypes[96]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecurringRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecurringRulesRequest) ProtoMessage() {}
+
+func (x *ListRecurringRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[96]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecurringRulesRequest.ProtoReflect.Descriptor instead.
+func (*ListRecurringRulesRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{96}
+}
+
+func (x *ListRecurringRulesRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ListRecurringRulesRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListRecurringRulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*RecurringRule       `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecurringRulesResponse) Reset() {
+	*x = ListRecurringRulesResponse{}
+	mi := &file_transaction_proto_msgTypes[97]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecurringRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecurringRulesResponse) ProtoMessage() {}
+
+func (x *ListRecurringRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[97]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecurringRulesResponse.ProtoReflect.Descriptor instead.
+func (*ListRecurringRulesResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{97}
+}
+
+func (x *ListRecurringRulesResponse) GetRules() []*RecurringRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *ListRecurringRulesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CancelRecurringRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelRecurringRuleRequest) Reset() {
+	*x = CancelRecurringRuleRequest{}
+	mi := &file_transaction_proto_msgTypes[98]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelRecurringRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRecurringRuleRequest) ProtoMessage() {}
+
+func (x *CancelRecurringRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[98]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRecurringRuleRequest.ProtoReflect.Descriptor instead.
+func (*CancelRecurringRuleRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{98}
+}
+
+func (x *CancelRecurringRuleRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *CancelRecurringRuleRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RecurringRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          *RecurringRule         `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecurringRuleResponse) Reset() {
+	*x = RecurringRuleResponse{}
+	mi := &file_transaction_proto_msgTypes[99]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecurringRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecurringRuleResponse) ProtoMessage() {}
+
+func (x *RecurringRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[99]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecurringRuleResponse.ProtoReflect.Descriptor instead.
+func (*RecurringRuleResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{99}
+}
+
+func (x *RecurringRuleResponse) GetRule() *RecurringRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+func (x *RecurringRuleResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// A Go text/template of the messages rendered for the events of one type on one channel. Templates are read from
+// the runtime config for the whole platform and can be overridden per tenant; events carry the messages rendered
+// with the variables of their payload.
+type MessageTemplate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Empty for a platform template
+	TenantId string `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	// e.g. transaction.created
+	EventType string `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	// EMAIL or WEBHOOK
+	Channel string `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"`
+	// EMAIL only
+	Subject string `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	// For WEBHOOK, the JSON payload
+	Body string `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
+	// TENANT or PLATFORM
+	Source        string `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	UpdatedBy     string `protobuf:"bytes,7,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`
+	UpdatedAt     int64  `protobuf:"varint,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageTemplate) Reset() {
+	*x = MessageTemplate{}
+	mi := &file_transaction_proto_msgTypes[100]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageTemplate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageTemplate) ProtoMessage() {}
+
+func (x *MessageTemplate) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[100]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageTemplate.ProtoReflect.Descriptor instead.
+func (*MessageTemplate) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{100}
+}
+
+func (x *MessageTemplate) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *MessageTemplate) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *MessageTemplate) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *MessageTemplate) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *MessageTemplate) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *MessageTemplate) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *MessageTemplate) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *MessageTemplate) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type ListMessageTemplatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMessageTemplatesRequest) Reset() {
+	*x = ListMessageTemplatesRequest{}
+	mi := &file_transaction_proto_msgTypes[101]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMessageTemplatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMessageTemplatesRequest) ProtoMessage() {}
+
+func (x *ListMessageTemplatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[101]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMessageTemplatesRequest.ProtoReflect.Descriptor instead.
+func (*ListMessageTemplatesRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{101}
+}
+
+func (x *ListMessageTemplatesRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+type ListMessageTemplatesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// By event type, then channel
+	Templates     []*MessageTemplate `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+	Error         string             `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMessageTemplatesResponse) Reset() {
+	*x = ListMessageTemplatesResponse{}
+	mi := &file_transaction_proto_msgTypes[102]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMessageTemplatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMessageTemplatesResponse) ProtoMessage() {}
+
+func (x *ListMessageTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[102]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMessageTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*ListMessageTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{102}
+}
+
+func (x *ListMessageTemplatesResponse) GetTemplates() []*MessageTemplate {
+	if x != nil {
+		return x.Templates
+	}
+	return nil
+}
+
+func (x *ListMessageTemplatesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PutMessageTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	EventType     string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Channel       string                 `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"`
+	Subject       string                 `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	Body          string                 `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutMessageTemplateRequest) Reset() {
+	*x = PutMessageTemplateRequest{}
+	mi := &file_transaction_proto_msgTypes[103]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutMessageTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutMessageTemplateRequest) ProtoMessage() {}
+
+func (x *PutMessageTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[103]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutMessageTemplateRequest.ProtoReflect.Descriptor instead.
+func (*PutMessageTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{103}
+}
+
+func (x *PutMessageTemplateRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *PutMessageTemplateRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *PutMessageTemplateRequest) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *PutMessageTemplateRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *PutMessageTemplateRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type DeleteMessageTemplateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TenantId      string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	EventType     string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Channel       string                 `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMessageTemplateRequest) Reset() {
+	*x = DeleteMessageTemplateRequest{}
+	mi := &file_transaction_proto_msgTypes[104]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMessageTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMessageTemplateRequest) ProtoMessage() {}
+
+func (x *DeleteMessageTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[104]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMessageTemplateRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMessageTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{104}
+}
+
+func (x *DeleteMessageTemplateRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
 }
 
-func (x *CreateTransactionResponse) GetTransaction() *Transaction {
+func (x *DeleteMessageTemplateRequest) GetEventType() string {
 	if x != nil {
-		return x.Transaction
+		return x.EventType
 	}
-	return nil
+	return ""
 }
 
-func (x *CreateTransactionResponse) GetError() string {
+func (x *DeleteMessageTemplateRequest) GetChannel() string {
 	if x != nil {
-		return x.Error
+		return x.Channel
 	}
 	return ""
 }
 
-type GetTransactionRequest struct {
+type MessageTemplateResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Template      *MessageTemplate       `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTransactionRequest) Reset() {
-	*x = GetTransactionRequest{}
-	mi := &file_transaction_proto_msgTypes[3]
+func (x *MessageTemplateResponse) Reset() {
+	*x = MessageTemplateResponse{}
+	mi := &file_transaction_proto_msgTypes[105]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTransactionRequest) String() string {
+func (x *MessageTemplateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTransactionRequest) ProtoMessage() {}
+func (*MessageTemplateResponse) ProtoMessage() {}
 
-func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_transaction_proto_msgTypes[3]
+func (x *MessageTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[105]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -267,41 +7418,50 @@ func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTransactionRequest.ProtoReflect.Descriptor instead.
-func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
-	return file_transaction_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use MessageTemplateResponse.ProtoReflect.Descriptor instead.
+func (*MessageTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{105}
 }
 
-func (x *GetTransactionRequest) GetId() string {
+func (x *MessageTemplateResponse) GetTemplate() *MessageTemplate {
 	if x != nil {
-		return x.Id
+		return x.Template
+	}
+	return nil
+}
+
+func (x *MessageTemplateResponse) GetError() string {
+	if x != nil {
+		return x.Error
 	}
 	return ""
 }
 
-type GetTransactionResponse struct {
+// A message rendered from a template
+type RenderedMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Channel       string                 `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Subject       string                 `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	Body          string                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTransactionResponse) Reset() {
-	*x = GetTransactionResponse{}
-	mi := &file_transaction_proto_msgTypes[4]
+func (x *RenderedMessage) Reset() {
+	*x = RenderedMessage{}
+	mi := &file_transaction_proto_msgTypes[106]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTransactionResponse) String() string {
+func (x *RenderedMessage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTransactionResponse) ProtoMessage() {}
+func (*RenderedMessage) ProtoMessage() {}
 
-func (x *GetTransactionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_transaction_proto_msgTypes[4]
+func (x *RenderedMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[106]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -312,49 +7472,61 @@ func (x *GetTransactionResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTransactionResponse.ProtoReflect.Descriptor instead.
-func (*GetTransactionResponse) Descriptor() ([]byte, []int) {
-	return file_transaction_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use RenderedMessage.ProtoReflect.Descriptor instead.
+func (*RenderedMessage) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{106}
 }
 
-func (x *GetTransactionResponse) GetTransaction() *Transaction {
+func (x *RenderedMessage) GetChannel() string {
 	if x != nil {
-		return x.Transaction
+		return x.Channel
 	}
-	return nil
+	return ""
 }
 
-func (x *GetTransactionResponse) GetError() string {
+func (x *RenderedMessage) GetSubject() string {
 	if x != nil {
-		return x.Error
+		return x.Subject
 	}
 	return ""
 }
 
-type GetTransactionHistoryRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
-	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+func (x *RenderedMessage) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type PreviewMessageTemplateRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	TenantId  string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	EventType string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Channel   string                 `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"`
+	// Optional draft to render instead of the template in effect; set body to use one
+	Subject string `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	Body    string `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
+	// Optional event payload as JSON, with the field names of the event schema; a sample event if empty
+	PayloadJson   string `protobuf:"bytes,6,opt,name=payload_json,json=payloadJson,proto3" json:"payload_json,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTransactionHistoryRequest) Reset() {
-	*x = GetTransactionHistoryRequest{}
-	mi := &file_transaction_proto_msgTypes[5]
+func (x *PreviewMessageTemplateRequest) Reset() {
+	*x = PreviewMessageTemplateRequest{}
+	mi := &file_transaction_proto_msgTypes[107]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTransactionHistoryRequest) String() string {
+func (x *PreviewMessageTemplateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTransactionHistoryRequest) ProtoMessage() {}
+func (*PreviewMessageTemplateRequest) ProtoMessage() {}
 
-func (x *GetTransactionHistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_transaction_proto_msgTypes[5]
+func (x *PreviewMessageTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[107]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -365,56 +7537,78 @@ func (x *GetTransactionHistoryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTransactionHistoryRequest.ProtoReflect.Descriptor instead.
-func (*GetTransactionHistoryRequest) Descriptor() ([]byte, []int) {
-	return file_transaction_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use PreviewMessageTemplateRequest.ProtoReflect.Descriptor instead.
+func (*PreviewMessageTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{107}
 }
 
-func (x *GetTransactionHistoryRequest) GetAccountId() string {
+func (x *PreviewMessageTemplateRequest) GetTenantId() string {
 	if x != nil {
-		return x.AccountId
+		return x.TenantId
 	}
 	return ""
 }
 
-func (x *GetTransactionHistoryRequest) GetLimit() int32 {
+func (x *PreviewMessageTemplateRequest) GetEventType() string {
 	if x != nil {
-		return x.Limit
+		return x.EventType
 	}
-	return 0
+	return ""
 }
 
-func (x *GetTransactionHistoryRequest) GetOffset() int32 {
+func (x *PreviewMessageTemplateRequest) GetChannel() string {
 	if x != nil {
-		return x.Offset
+		return x.Channel
 	}
-	return 0
+	return ""
 }
 
-type GetTransactionHistoryResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
-	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
-	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+func (x *PreviewMessageTemplateRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *PreviewMessageTemplateRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *PreviewMessageTemplateRequest) GetPayloadJson() string {
+	if x != nil {
+		return x.PayloadJson
+	}
+	return ""
+}
+
+type PreviewMessageTemplateResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message *RenderedMessage       `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// The variables the template was rendered with
+	VariablesJson string `protobuf:"bytes,2,opt,name=variables_json,json=variablesJson,proto3" json:"variables_json,omitempty"`
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetTransactionHistoryResponse) Reset() {
-	*x = GetTransactionHistoryResponse{}
-	mi := &file_transaction_proto_msgTypes[6]
+func (x *PreviewMessageTemplateResponse) Reset() {
+	*x = PreviewMessageTemplateResponse{}
+	mi := &file_transaction_proto_msgTypes[108]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetTransactionHistoryResponse) String() string {
+func (x *PreviewMessageTemplateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetTransactionHistoryResponse) ProtoMessage() {}
+func (*PreviewMessageTemplateResponse) ProtoMessage() {}
 
-func (x *GetTransactionHistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_transaction_proto_msgTypes[6]
+func (x *PreviewMessageTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[108]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -425,56 +7619,61 @@ func (x *GetTransactionHistoryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetTransactionHistoryResponse.ProtoReflect.Descriptor instead.
-func (*GetTransactionHistoryResponse) Descriptor() ([]byte, []int) {
-	return file_transaction_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use PreviewMessageTemplateResponse.ProtoReflect.Descriptor instead.
+func (*PreviewMessageTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{108}
 }
 
-func (x *GetTransactionHistoryResponse) GetTransactions() []*Transaction {
+func (x *PreviewMessageTemplateResponse) GetMessage() *RenderedMessage {
 	if x != nil {
-		return x.Transactions
+		return x.Message
 	}
 	return nil
 }
 
-func (x *GetTransactionHistoryResponse) GetTotal() int32 {
+func (x *PreviewMessageTemplateResponse) GetVariablesJson() string {
 	if x != nil {
-		return x.Total
+		return x.VariablesJson
 	}
-	return 0
+	return ""
 }
 
-func (x *GetTransactionHistoryResponse) GetError() string {
+func (x *PreviewMessageTemplateResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-type ProcessPaymentRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
-	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+type TestSendMessageTemplateRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	TenantId    string                 `protobuf:"bytes,1,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	EventType   string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Channel     string                 `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"`
+	Subject     string                 `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	Body        string                 `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
+	PayloadJson string                 `protobuf:"bytes,6,opt,name=payload_json,json=payloadJson,proto3" json:"payload_json,omitempty"`
+	// An email address for EMAIL, an https URL for WEBHOOK
+	Recipient     string `protobuf:"bytes,7,opt,name=recipient,proto3" json:"recipient,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProcessPaymentRequest) Reset() {
-	*x = ProcessPaymentRequest{}
-	mi := &file_transaction_proto_msgTypes[7]
+func (x *TestSendMessageTemplateRequest) Reset() {
+	*x = TestSendMessageTemplateRequest{}
+	mi := &file_transaction_proto_msgTypes[109]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProcessPaymentRequest) String() string {
+func (x *TestSendMessageTemplateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProcessPaymentRequest) ProtoMessage() {}
+func (*TestSendMessageTemplateRequest) ProtoMessage() {}
 
-func (x *ProcessPaymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_transaction_proto_msgTypes[7]
+func (x *TestSendMessageTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[109]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -485,55 +7684,85 @@ func (x *ProcessPaymentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProcessPaymentRequest.ProtoReflect.Descriptor instead.
-func (*ProcessPaymentRequest) Descriptor() ([]byte, []int) {
-	return file_transaction_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use TestSendMessageTemplateRequest.ProtoReflect.Descriptor instead.
+func (*TestSendMessageTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{109}
 }
 
-func (x *ProcessPaymentRequest) GetAccountId() string {
+func (x *TestSendMessageTemplateRequest) GetTenantId() string {
 	if x != nil {
-		return x.AccountId
+		return x.TenantId
 	}
 	return ""
 }
 
-func (x *ProcessPaymentRequest) GetAmount() float64 {
+func (x *TestSendMessageTemplateRequest) GetEventType() string {
 	if x != nil {
-		return x.Amount
+		return x.EventType
 	}
-	return 0
+	return ""
 }
 
-func (x *ProcessPaymentRequest) GetDescription() string {
+func (x *TestSendMessageTemplateRequest) GetChannel() string {
 	if x != nil {
-		return x.Description
+		return x.Channel
 	}
 	return ""
 }
 
-type ProcessPaymentResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+func (x *TestSendMessageTemplateRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *TestSendMessageTemplateRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *TestSendMessageTemplateRequest) GetPayloadJson() string {
+	if x != nil {
+		return x.PayloadJson
+	}
+	return ""
+}
+
+func (x *TestSendMessageTemplateRequest) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+type TestSendMessageTemplateResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message *RenderedMessage       `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// ID of the message.test_send event, to follow its delivery
+	EventId       string `protobuf:"bytes,2,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProcessPaymentResponse) Reset() {
-	*x = ProcessPaymentResponse{}
-	mi := &file_transaction_proto_msgTypes[8]
+func (x *TestSendMessageTemplateResponse) Reset() {
+	*x = TestSendMessageTemplateResponse{}
+	mi := &file_transaction_proto_msgTypes[110]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProcessPaymentResponse) String() string {
+func (x *TestSendMessageTemplateResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProcessPaymentResponse) ProtoMessage() {}
+func (*TestSendMessageTemplateResponse) ProtoMessage() {}
 
-func (x *ProcessPaymentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_transaction_proto_msgTypes[8]
+func (x *TestSendMessageTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transaction_proto_msgTypes[110]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -544,19 +7773,26 @@ func (x *ProcessPaymentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProcessPaymentResponse.ProtoReflect.Descriptor instead.
-func (*ProcessPaymentResponse) Descriptor() ([]byte, []int) {
-	return file_transaction_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use TestSendMessageTemplateResponse.ProtoReflect.Descriptor instead.
+func (*TestSendMessageTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_transaction_proto_rawDescGZIP(), []int{110}
 }
 
-func (x *ProcessPaymentResponse) GetTransaction() *Transaction {
+func (x *TestSendMessageTemplateResponse) GetMessage() *RenderedMessage {
 	if x != nil {
-		return x.Transaction
+		return x.Message
 	}
 	return nil
 }
 
-func (x *ProcessPaymentResponse) GetError() string {
+func (x *TestSendMessageTemplateResponse) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *TestSendMessageTemplateResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
@@ -567,53 +7803,719 @@ var File_transaction_proto protoreflect.FileDescriptor
 
 const file_transaction_proto_rawDesc = "" +
 	"\n" +
-	"\x11transaction.proto\x12\vtransaction\"\xd4\x01\n" +
+	"\x11transaction.proto\x12\vtransaction\x1a\x1cgoogle/api/annotations.proto\"\x90\x06\n" +
 	"\vTransaction\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
 	"\n" +
 	"account_id\x18\x02 \x01(\tR\taccountId\x12%\n" +
-	"\x0eoperation_type\x18\x03 \x01(\tR\roperationType\x12\x16\n" +
-	"\x06amount\x18\x04 \x01(\x01R\x06amount\x12 \n" +
+	"\x0eoperation_type\x18\x03 \x01(\tR\roperationType\x12!\n" +
+	"\famount_cents\x18\x13 \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\x06amount\x18\x04 \x01(\x01B\x02\x18\x01R\x06amount\x12 \n" +
 	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1d\n" +
 	"\n" +
 	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x16\n" +
-	"\x06status\x18\a \x01(\tR\x06status\"\x9a\x01\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\x1f\n" +
+	"\vexternal_id\x18\b \x01(\tR\n" +
+	"externalId\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x12B\n" +
+	"\bmetadata\x18\n" +
+	" \x03(\v2&.transaction.Transaction.MetadataEntryR\bmetadata\x12\x1f\n" +
+	"\vtransfer_id\x18\v \x01(\tR\n" +
+	"transferId\x126\n" +
+	"\x17original_transaction_id\x18\f \x01(\tR\x15originalTransactionId\x12#\n" +
+	"\rbalance_cents\x18\r \x01(\x03R\fbalanceCents\x12\x1c\n" +
+	"\toverdrawn\x18\x0e \x01(\bR\toverdrawn\x12+\n" +
+	"\x11original_currency\x18\x0f \x01(\tR\x10originalCurrency\x122\n" +
+	"\x15original_amount_cents\x18\x10 \x01(\x03R\x13originalAmountCents\x12\x17\n" +
+	"\afx_rate\x18\x11 \x01(\x01R\x06fxRate\x12G\n" +
+	"\x10installment_plan\x18\x12 \x01(\v2\x1c.transaction.InstallmentPlanR\x0finstallmentPlan\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x93\x02\n" +
+	"\x0fInstallmentPlan\x12\x1f\n" +
+	"\vpurchase_id\x18\x01 \x01(\tR\n" +
+	"purchaseId\x12+\n" +
+	"\x11installment_count\x18\x02 \x01(\x05R\x10installmentCount\x12,\n" +
+	"\x12total_amount_cents\x18\x03 \x01(\x03R\x10totalAmountCents\x12\x1d\n" +
+	"\n" +
+	"paid_count\x18\x04 \x01(\x05R\tpaidCount\x12'\n" +
+	"\x0fremaining_cents\x18\x05 \x01(\x03R\x0eremainingCents\x12<\n" +
+	"\finstallments\x18\x06 \x03(\v2\x18.transaction.InstallmentR\finstallments\"\xf2\x01\n" +
+	"\vInstallment\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12!\n" +
+	"\famount_cents\x18\x02 \x01(\x03R\vamountCents\x12\x15\n" +
+	"\x06due_at\x18\x03 \x01(\x03R\x05dueAt\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12%\n" +
+	"\x0etransaction_id\x18\x05 \x01(\tR\rtransactionId\x12\x17\n" +
+	"\apaid_at\x18\x06 \x01(\x03R\x06paidAt\x12\x1a\n" +
+	"\battempts\x18\a \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\b \x01(\tR\tlastError\"\x81\x03\n" +
 	"\x18CreateTransactionRequest\x12\x1d\n" +
 	"\n" +
 	"account_id\x18\x01 \x01(\tR\taccountId\x12%\n" +
-	"\x0eoperation_type\x18\x02 \x01(\tR\roperationType\x12\x16\n" +
-	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12 \n" +
-	"\vdescription\x18\x04 \x01(\tR\vdescription\"m\n" +
+	"\x0eoperation_type\x18\x02 \x01(\tR\roperationType\x12!\n" +
+	"\famount_cents\x18\v \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\x06amount\x18\x03 \x01(\x01B\x02\x18\x01R\x06amount\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bsimulate\x18\x05 \x01(\bR\bsimulate\x12\x1f\n" +
+	"\vexternal_id\x18\x06 \x01(\tR\n" +
+	"externalId\x12\x1a\n" +
+	"\bcategory\x18\a \x01(\tR\bcategory\x12\x19\n" +
+	"\bbatch_id\x18\b \x01(\tR\abatchId\x12\x1d\n" +
+	"\n" +
+	"batch_item\x18\t \x01(\x05R\tbatchItem\x12+\n" +
+	"\x11installment_count\x18\n" +
+	" \x01(\x05R\x10installmentCount\"\x9c\x02\n" +
 	"\x19CreateTransactionResponse\x12:\n" +
 	"\vtransaction\x18\x01 \x01(\v2\x18.transaction.TransactionR\vtransaction\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"'\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1c\n" +
+	"\tsimulated\x18\x03 \x01(\bR\tsimulated\x12.\n" +
+	"\x13balance_after_cents\x18\x06 \x01(\x03R\x11balanceAfterCents\x12'\n" +
+	"\rbalance_after\x18\x04 \x01(\x01B\x02\x18\x01R\fbalanceAfter\x126\n" +
+	"\n" +
+	"discharges\x18\x05 \x03(\v2\x16.transaction.DischargeR\n" +
+	"discharges\"\x85\x01\n" +
+	"\tDischarge\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12!\n" +
+	"\famount_cents\x18\x02 \x01(\x03R\vamountCents\x12.\n" +
+	"\x13balance_after_cents\x18\x03 \x01(\x03R\x11balanceAfterCents\"H\n" +
 	"\x15GetTransactionRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"j\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vexternal_id\x18\x02 \x01(\tR\n" +
+	"externalId\"j\n" +
 	"\x16GetTransactionResponse\x12:\n" +
 	"\vtransaction\x18\x01 \x01(\v2\x18.transaction.TransactionR\vtransaction\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"k\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x8f\x02\n" +
+	"\x18UpdateTransactionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12O\n" +
+	"\bmetadata\x18\x04 \x03(\v23.transaction.UpdateTransactionRequest.MetadataEntryR\bmetadata\x12\x1f\n" +
+	"\vupdate_mask\x18\x05 \x03(\tR\n" +
+	"updateMask\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"m\n" +
+	"\x19UpdateTransactionResponse\x12:\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x18.transaction.TransactionR\vtransaction\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xd8\x01\n" +
+	"\x15TransactionEditValues\x12 \n" +
+	"\vdescription\x18\x01 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\x12L\n" +
+	"\bmetadata\x18\x03 \x03(\v20.transaction.TransactionEditValues.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd9\x01\n" +
+	"\x0fTransactionEdit\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tedited_by\x18\x02 \x01(\tR\beditedBy\x12\x1b\n" +
+	"\tedited_at\x18\x03 \x01(\x03R\beditedAt\x12>\n" +
+	"\bprevious\x18\x04 \x01(\v2\".transaction.TransactionEditValuesR\bprevious\x12<\n" +
+	"\aupdated\x18\x05 \x01(\v2\".transaction.TransactionEditValuesR\aupdated\"\xa6\x01\n" +
+	"\rTimelineEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1f\n" +
+	"\voccurred_at\x18\x02 \x01(\x03R\n" +
+	"occurredAt\x120\n" +
+	"\x04edit\x18\x03 \x01(\v2\x1c.transaction.TransactionEditR\x04edit\x12.\n" +
+	"\adispute\x18\x04 \x01(\v2\x14.transaction.DisputeR\adispute\"/\n" +
+	"\x1dGetTransactionTimelineRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xa6\x01\n" +
+	"\x1eGetTransactionTimelineResponse\x12:\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x18.transaction.TransactionR\vtransaction\x122\n" +
+	"\x06events\x18\x02 \x03(\v2\x1a.transaction.TimelineEventR\x06events\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xa6\x01\n" +
 	"\x1cGetTransactionHistoryRequest\x12\x1d\n" +
 	"\n" +
 	"account_id\x18\x01 \x01(\tR\taccountId\x12\x14\n" +
-	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
-	"\x06offset\x18\x03 \x01(\x05R\x06offset\"\x89\x01\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x1a\n" +
+	"\x06offset\x18\x03 \x01(\x05B\x02\x18\x01R\x06offset\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12\x16\n" +
+	"\x06search\x18\x05 \x01(\tR\x06search\"\xd6\x01\n" +
 	"\x1dGetTransactionHistoryResponse\x12<\n" +
 	"\ftransactions\x18\x01 \x03(\v2\x18.transaction.TransactionR\ftransactions\x12\x14\n" +
 	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error\"p\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12&\n" +
+	"\x0fnext_page_token\x18\x04 \x01(\tR\rnextPageToken\x12#\n" +
+	"\rapplied_limit\x18\x05 \x01(\x05R\fappliedLimit\"|\n" +
+	"\x1cAggregateTransactionsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x19\n" +
+	"\bgroup_by\x18\x02 \x01(\tR\agroupBy\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\x03R\x02to\"\xc8\x01\n" +
+	"\x11TransactionBucket\x12!\n" +
+	"\fbucket_start\x18\x01 \x01(\x03R\vbucketStart\x12%\n" +
+	"\x0eoperation_type\x18\x02 \x01(\tR\roperationType\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x03R\x05count\x12,\n" +
+	"\x12total_amount_cents\x18\x05 \x01(\x03R\x10totalAmountCents\x12%\n" +
+	"\ftotal_amount\x18\x04 \x01(\x01B\x02\x18\x01R\vtotalAmount\"o\n" +
+	"\x1dAggregateTransactionsResponse\x128\n" +
+	"\abuckets\x18\x01 \x03(\v2\x1e.transaction.TransactionBucketR\abuckets\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xb3\x01\n" +
 	"\x15ProcessPaymentRequest\x12\x1d\n" +
 	"\n" +
-	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
-	"\x06amount\x18\x02 \x01(\x01R\x06amount\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\"j\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12!\n" +
+	"\famount_cents\x18\x05 \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\x06amount\x18\x02 \x01(\x01B\x02\x18\x01R\x06amount\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\"j\n" +
 	"\x16ProcessPaymentResponse\x12:\n" +
 	"\vtransaction\x18\x01 \x01(\v2\x18.transaction.TransactionR\vtransaction\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error2\x9e\x03\n" +
-	"\x12TransactionService\x12b\n" +
-	"\x11CreateTransaction\x12%.transaction.CreateTransactionRequest\x1a&.transaction.CreateTransactionResponse\x12Y\n" +
-	"\x0eGetTransaction\x12\".transaction.GetTransactionRequest\x1a#.transaction.GetTransactionResponse\x12n\n" +
-	"\x15GetTransactionHistory\x12).transaction.GetTransactionHistoryRequest\x1a*.transaction.GetTransactionHistoryResponse\x12Y\n" +
-	"\x0eProcessPayment\x12\".transaction.ProcessPaymentRequest\x1a#.transaction.ProcessPaymentResponseB\x0fZ\r./transactionb\x06proto3"
+	"\x05error\x18\x02 \x01(\tR\x05error\"C\n" +
+	"\x19ReverseTransactionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x9e\x01\n" +
+	"\x1aReverseTransactionResponse\x124\n" +
+	"\boriginal\x18\x01 \x01(\v2\x18.transaction.TransactionR\boriginal\x124\n" +
+	"\breversal\x18\x02 \x01(\v2\x18.transaction.TransactionR\breversal\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xd4\x01\n" +
+	"\x0fTransferRequest\x12*\n" +
+	"\x11source_account_id\x18\x01 \x01(\tR\x0fsourceAccountId\x124\n" +
+	"\x16destination_account_id\x18\x02 \x01(\tR\x14destinationAccountId\x12!\n" +
+	"\famount_cents\x18\x05 \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\x06amount\x18\x03 \x01(\x01B\x02\x18\x01R\x06amount\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\"\xab\x01\n" +
+	"\x10TransferResponse\x12\x1f\n" +
+	"\vtransfer_id\x18\x01 \x01(\tR\n" +
+	"transferId\x12.\n" +
+	"\x05debit\x18\x02 \x01(\v2\x18.transaction.TransactionR\x05debit\x120\n" +
+	"\x06credit\x18\x03 \x01(\v2\x18.transaction.TransactionR\x06credit\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x81\x01\n" +
+	"\x17IngestTransactionResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12:\n" +
+	"\vtransaction\x18\x02 \x01(\v2\x18.transaction.TransactionR\vtransaction\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\x80\x02\n" +
+	"\rOperationRule\x12%\n" +
+	"\x0eoperation_type\x18\x01 \x01(\tR\roperationType\x12\x1c\n" +
+	"\tdirection\x18\x02 \x01(\tR\tdirection\x128\n" +
+	"\x18requires_positive_amount\x18\x03 \x01(\bR\x16requiresPositiveAmount\x122\n" +
+	"\x15allowed_account_types\x18\x04 \x03(\tR\x13allowedAccountTypes\x12\x1d\n" +
+	"\n" +
+	"fee_policy\x18\x05 \x01(\tR\tfeePolicy\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\x03R\tupdatedAt\"\x1b\n" +
+	"\x19ListOperationRulesRequest\"d\n" +
+	"\x1aListOperationRulesResponse\x120\n" +
+	"\x05rules\x18\x01 \x03(\v2\x1a.transaction.OperationRuleR\x05rules\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"s\n" +
+	"\x1aUpdateOperationRuleRequest\x12%\n" +
+	"\x0eoperation_type\x18\x01 \x01(\tR\roperationType\x12.\n" +
+	"\x04rule\x18\x02 \x01(\v2\x1a.transaction.OperationRuleR\x04rule\"c\n" +
+	"\x1bUpdateOperationRuleResponse\x12.\n" +
+	"\x04rule\x18\x01 \x01(\v2\x1a.transaction.OperationRuleR\x04rule\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xf4\x01\n" +
+	"\aDispute\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12%\n" +
+	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x03 \x01(\tR\taccountId\x12!\n" +
+	"\famount_cents\x18\b \x01(\x03R\vamountCents\x12\x1a\n" +
+	"\x06amount\x18\x04 \x01(\x01B\x02\x18\x01R\x06amount\x12\x1f\n" +
+	"\vreason_code\x18\x05 \x01(\tR\n" +
+	"reasonCode\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12\x1b\n" +
+	"\topened_at\x18\a \x01(\x03R\bopenedAt\"L\n" +
+	"\x18ImportChargebacksRequest\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\fR\acontent\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\"b\n" +
+	"\x13UnmatchedChargeback\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\x05R\x04line\x12\x1f\n" +
+	"\vexternal_id\x18\x02 \x01(\tR\n" +
+	"externalId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\xca\x01\n" +
+	"\x19ImportChargebacksResponse\x12,\n" +
+	"\x06opened\x18\x01 \x03(\v2\x14.transaction.DisputeR\x06opened\x12)\n" +
+	"\x10already_disputed\x18\x02 \x01(\x05R\x0falreadyDisputed\x12>\n" +
+	"\tunmatched\x18\x03 \x03(\v2 .transaction.UnmatchedChargebackR\tunmatched\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"W\n" +
+	"\x1aReconcileSettlementRequest\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\fR\acontent\x12\x1f\n" +
+	"\vauto_adjust\x18\x02 \x01(\bR\n" +
+	"autoAdjust\"\xde\x01\n" +
+	"\x12ReconciliationLine\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\x05R\x04line\x12\x1f\n" +
+	"\vexternal_id\x18\x02 \x01(\tR\n" +
+	"externalId\x12!\n" +
+	"\famount_cents\x18\x03 \x01(\x03R\vamountCents\x12\x12\n" +
+	"\x04date\x18\x04 \x01(\tR\x04date\x12%\n" +
+	"\x0etransaction_id\x18\x05 \x01(\tR\rtransactionId\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x06 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06reason\x18\a \x01(\tR\x06reason\"\xb1\x01\n" +
+	"\x18ReconciliationAdjustment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12%\n" +
+	"\x0etransaction_id\x18\x03 \x01(\tR\rtransactionId\x12\x1c\n" +
+	"\tdirection\x18\x04 \x01(\tR\tdirection\x12!\n" +
+	"\famount_cents\x18\x05 \x01(\x03R\vamountCents\"\xf2\x03\n" +
+	"\x1bReconcileSettlementResponse\x129\n" +
+	"\amatched\x18\x01 \x03(\v2\x1f.transaction.ReconciliationLineR\amatched\x12?\n" +
+	"\n" +
+	"mismatched\x18\x02 \x03(\v2\x1f.transaction.ReconciliationLineR\n" +
+	"mismatched\x129\n" +
+	"\amissing\x18\x03 \x03(\v2\x1f.transaction.ReconciliationLineR\amissing\x12.\n" +
+	"\x05extra\x18\x04 \x03(\v2\x18.transaction.TransactionR\x05extra\x12'\n" +
+	"\x0fextra_truncated\x18\x05 \x01(\bR\x0eextraTruncated\x129\n" +
+	"\ainvalid\x18\x06 \x03(\v2\x1f.transaction.ReconciliationLineR\ainvalid\x12G\n" +
+	"\vadjustments\x18\a \x03(\v2%.transaction.ReconciliationAdjustmentR\vadjustments\x12)\n" +
+	"\x10already_adjusted\x18\b \x01(\x05R\x0falreadyAdjusted\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\"|\n" +
+	"\x1fExportTransactionHistoryRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\x03R\x02to\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\"I\n" +
+	"\x1dExportTransactionHistoryChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"b\n" +
+	"\x1cListStuckTransactionsRequest\x12,\n" +
+	"\x12older_than_seconds\x18\x01 \x01(\x03R\x10olderThanSeconds\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"s\n" +
+	"\x1dListStuckTransactionsResponse\x12<\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x18.transaction.TransactionR\ftransactions\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"c\n" +
+	"\x1fResolveStuckTransactionsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x81\x02\n" +
+	"\x15TransactionResolution\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12%\n" +
+	"\x0etransaction_id\x18\x02 \x01(\tR\rtransactionId\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x12'\n" +
+	"\x0fprevious_status\x18\x04 \x01(\tR\x0epreviousStatus\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\x12\x1f\n" +
+	"\vresolved_by\x18\a \x01(\tR\n" +
+	"resolvedBy\x12\x1f\n" +
+	"\vresolved_at\x18\b \x01(\x03R\n" +
+	"resolvedAt\"\xa0\x01\n" +
+	"\x1dResolveStuckTransactionResult\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12B\n" +
+	"\n" +
+	"resolution\x18\x02 \x01(\v2\".transaction.TransactionResolutionR\n" +
+	"resolution\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"~\n" +
+	" ResolveStuckTransactionsResponse\x12D\n" +
+	"\aresults\x18\x01 \x03(\v2*.transaction.ResolveStuckTransactionResultR\aresults\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xa3\x02\n" +
+	"\x12FlaggedTransaction\x12:\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x18.transaction.TransactionR\vtransaction\x12\x1d\n" +
+	"\n" +
+	"risk_score\x18\x02 \x01(\x05R\triskScore\x12!\n" +
+	"\frisk_factors\x18\x03 \x03(\tR\vriskFactors\x12\x1d\n" +
+	"\n" +
+	"flagged_at\x18\x04 \x01(\x03R\tflaggedAt\x12\x1a\n" +
+	"\bdecision\x18\x05 \x01(\tR\bdecision\x12\x12\n" +
+	"\x04note\x18\x06 \x01(\tR\x04note\x12\x1f\n" +
+	"\vreviewed_by\x18\a \x01(\tR\n" +
+	"reviewedBy\x12\x1f\n" +
+	"\vreviewed_at\x18\b \x01(\x03R\n" +
+	"reviewedAt\"U\n" +
+	"\x1eListFlaggedTransactionsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"|\n" +
+	"\x1fListFlaggedTransactionsResponse\x12C\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x1f.transaction.FlaggedTransactionR\ftransactions\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\";\n" +
+	"\x15ApproveFlaggedRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04note\x18\x02 \x01(\tR\x04note\"q\n" +
+	"\x16ApproveFlaggedResponse\x12A\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x1f.transaction.FlaggedTransactionR\vtransaction\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\";\n" +
+	"\x15DeclineFlaggedRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04note\x18\x02 \x01(\tR\x04note\"q\n" +
+	"\x16DeclineFlaggedResponse\x12A\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x1f.transaction.FlaggedTransactionR\vtransaction\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xfa\x01\n" +
+	"\x06Budget\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1a\n" +
+	"\bcategory\x18\x02 \x01(\tR\bcategory\x12.\n" +
+	"\x13monthly_limit_cents\x18\a \x01(\x03R\x11monthlyLimitCents\x12'\n" +
+	"\rmonthly_limit\x18\x03 \x01(\x01B\x02\x18\x01R\fmonthlyLimit\x12\x1e\n" +
+	"\n" +
+	"thresholds\x18\x04 \x03(\x05R\n" +
+	"thresholds\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\x03R\tupdatedAt\"\xc6\x01\n" +
+	"\x10SetBudgetRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1a\n" +
+	"\bcategory\x18\x02 \x01(\tR\bcategory\x12.\n" +
+	"\x13monthly_limit_cents\x18\x05 \x01(\x03R\x11monthlyLimitCents\x12'\n" +
+	"\rmonthly_limit\x18\x03 \x01(\x01B\x02\x18\x01R\fmonthlyLimit\x12\x1e\n" +
+	"\n" +
+	"thresholds\x18\x04 \x03(\x05R\n" +
+	"thresholds\"V\n" +
+	"\x11SetBudgetResponse\x12+\n" +
+	"\x06budget\x18\x01 \x01(\v2\x13.transaction.BudgetR\x06budget\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"P\n" +
+	"\x13DeleteBudgetRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1a\n" +
+	"\bcategory\x18\x02 \x01(\tR\bcategory\",\n" +
+	"\x14DeleteBudgetResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\"\x93\x02\n" +
+	"\fBudgetStatus\x12+\n" +
+	"\x06budget\x18\x01 \x01(\v2\x13.transaction.BudgetR\x06budget\x12\x1f\n" +
+	"\vspent_cents\x18\x06 \x01(\x03R\n" +
+	"spentCents\x12\x18\n" +
+	"\x05spent\x18\x02 \x01(\x01B\x02\x18\x01R\x05spent\x12'\n" +
+	"\x0fremaining_cents\x18\a \x01(\x03R\x0eremainingCents\x12 \n" +
+	"\tremaining\x18\x03 \x01(\x01B\x02\x18\x01R\tremaining\x12!\n" +
+	"\fpercent_used\x18\x04 \x01(\x01R\vpercentUsed\x12-\n" +
+	"\x12crossed_thresholds\x18\x05 \x03(\x05R\x11crossedThresholds\"M\n" +
+	"\x16GetBudgetStatusRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x14\n" +
+	"\x05month\x18\x02 \x01(\tR\x05month\"z\n" +
+	"\x17GetBudgetStatusResponse\x12\x14\n" +
+	"\x05month\x18\x01 \x01(\tR\x05month\x123\n" +
+	"\abudgets\x18\x02 \x03(\v2\x19.transaction.BudgetStatusR\abudgets\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xa5\x01\n" +
+	"\rEventDelivery\x12\x1e\n" +
+	"\n" +
+	"subscriber\x18\x01 \x01(\tR\n" +
+	"subscriber\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1a\n" +
+	"\battempts\x18\x03 \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x04 \x01(\tR\tlastError\x12!\n" +
+	"\fdelivered_at\x18\x05 \x01(\x03R\vdeliveredAt\"\xa3\x01\n" +
+	"\fAccountEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\x12:\n" +
+	"\n" +
+	"deliveries\x18\x04 \x03(\v2\x1a.transaction.EventDeliveryR\n" +
+	"deliveries\"\xa7\x01\n" +
+	"\x1aListEventDeliveriesRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x05 \x01(\tR\tpageToken\"\x8e\x01\n" +
+	"\x1bListEventDeliveriesResponse\x121\n" +
+	"\x06events\x18\x01 \x03(\v2\x19.transaction.AccountEventR\x06events\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken\"\xf6\x01\n" +
+	"\x11WebhookSigningKey\x12\x1e\n" +
+	"\n" +
+	"subscriber\x18\x01 \x01(\tR\n" +
+	"subscriber\x12\x15\n" +
+	"\x06key_id\x18\x02 \x01(\tR\x05keyId\x12\x16\n" +
+	"\x06secret\x18\x03 \x01(\tR\x06secret\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x05 \x01(\tR\tcreatedBy\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"retired_by\x18\a \x01(\tR\tretiredBy\x12\x1d\n" +
+	"\n" +
+	"retired_at\x18\b \x01(\x03R\tretiredAt\"?\n" +
+	"\x1dListWebhookSigningKeysRequest\x12\x1e\n" +
+	"\n" +
+	"subscriber\x18\x01 \x01(\tR\n" +
+	"subscriber\"j\n" +
+	"\x1eListWebhookSigningKeysResponse\x122\n" +
+	"\x04keys\x18\x01 \x03(\v2\x1e.transaction.WebhookSigningKeyR\x04keys\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"@\n" +
+	"\x1eCreateWebhookSigningKeyRequest\x12\x1e\n" +
+	"\n" +
+	"subscriber\x18\x01 \x01(\tR\n" +
+	"subscriber\"W\n" +
+	"\x1eRetireWebhookSigningKeyRequest\x12\x1e\n" +
+	"\n" +
+	"subscriber\x18\x01 \x01(\tR\n" +
+	"subscriber\x12\x15\n" +
+	"\x06key_id\x18\x02 \x01(\tR\x05keyId\"c\n" +
+	"\x19WebhookSigningKeyResponse\x120\n" +
+	"\x03key\x18\x01 \x01(\v2\x1e.transaction.WebhookSigningKeyR\x03key\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x9d\x01\n" +
+	"\x19StreamTransactionsRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\x03R\x02to\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x03 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12%\n" +
+	"\x0eoperation_type\x18\x05 \x01(\tR\roperationType\"p\n" +
+	"\x1aStreamTransactionsResponse\x12<\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x18.transaction.TransactionR\ftransactions\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x15\n" +
+	"\x13GetSLOStatusRequest\"\xa6\x01\n" +
+	"\x0fSLOWindowStatus\x12%\n" +
+	"\x0ewindow_seconds\x18\x01 \x01(\x03R\rwindowSeconds\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x10\n" +
+	"\x03bad\x18\x03 \x01(\x03R\x03bad\x12'\n" +
+	"\x0fsuccess_percent\x18\x04 \x01(\x01R\x0esuccessPercent\x12\x1b\n" +
+	"\tburn_rate\x18\x05 \x01(\x01R\bburnRate\"\xe3\x01\n" +
+	"\x0fMethodSLOStatus\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12/\n" +
+	"\x13availability_target\x18\x02 \x01(\x01R\x12availabilityTarget\x12*\n" +
+	"\x11latency_target_ms\x18\x03 \x01(\x03R\x0flatencyTargetMs\x126\n" +
+	"\awindows\x18\x04 \x03(\v2\x1c.transaction.SLOWindowStatusR\awindows\x12#\n" +
+	"\rwithin_budget\x18\x05 \x01(\bR\fwithinBudget\"d\n" +
+	"\x14GetSLOStatusResponse\x126\n" +
+	"\amethods\x18\x01 \x03(\v2\x1c.transaction.MethodSLOStatusR\amethods\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xce\x01\n" +
+	"\x10TransactionBatch\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12%\n" +
+	"\x0eexpected_count\x18\x03 \x01(\x05R\rexpectedCount\x12\x1c\n" +
+	"\tsucceeded\x18\x04 \x01(\x05R\tsucceeded\x12\x16\n" +
+	"\x06failed\x18\x05 \x01(\x05R\x06failed\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\"\x95\x01\n" +
+	"\fBatchFailure\x12\x12\n" +
+	"\x04item\x18\x01 \x01(\x05R\x04item\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12\x1f\n" +
+	"\vexternal_id\x18\x03 \x01(\tR\n" +
+	"externalId\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x1b\n" +
+	"\tfailed_at\x18\x05 \x01(\x03R\bfailedAt\"S\n" +
+	"\x12CreateBatchRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12%\n" +
+	"\x0eexpected_count\x18\x02 \x01(\x05R\rexpectedCount\"`\n" +
+	"\x13CreateBatchResponse\x123\n" +
+	"\x05batch\x18\x01 \x01(\v2\x1d.transaction.TransactionBatchR\x05batch\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"x\n" +
+	"\x0fGetBatchRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12%\n" +
+	"\x0efailures_limit\x18\x02 \x01(\x05R\rfailuresLimit\x12.\n" +
+	"\x13failures_after_item\x18\x03 \x01(\x05R\x11failuresAfterItem\"\xcd\x01\n" +
+	"\x10GetBatchResponse\x123\n" +
+	"\x05batch\x18\x01 \x01(\v2\x1d.transaction.TransactionBatchR\x05batch\x125\n" +
+	"\bfailures\x18\x02 \x03(\v2\x19.transaction.BatchFailureR\bfailures\x127\n" +
+	"\x18next_failures_after_item\x18\x03 \x01(\x05R\x15nextFailuresAfterItem\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"n\n" +
+	"\x0eStatementTotal\x12%\n" +
+	"\x0eoperation_type\x18\x01 \x01(\tR\roperationType\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12\x1f\n" +
+	"\vtotal_cents\x18\x03 \x01(\x03R\n" +
+	"totalCents\"\xae\x03\n" +
+	"\tStatement\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06period\x18\x03 \x01(\tR\x06period\x12!\n" +
+	"\fperiod_start\x18\x04 \x01(\x03R\vperiodStart\x12\x1d\n" +
+	"\n" +
+	"period_end\x18\x05 \x01(\x03R\tperiodEnd\x122\n" +
+	"\x15opening_balance_cents\x18\x06 \x01(\x03R\x13openingBalanceCents\x122\n" +
+	"\x15closing_balance_cents\x18\a \x01(\x03R\x13closingBalanceCents\x123\n" +
+	"\x06totals\x18\b \x03(\v2\x1b.transaction.StatementTotalR\x06totals\x12+\n" +
+	"\x11transaction_count\x18\t \x01(\x03R\x10transactionCount\x12+\n" +
+	"\x11adjustments_cents\x18\n" +
+	" \x01(\x03R\x10adjustmentsCents\x12!\n" +
+	"\fgenerated_at\x18\v \x01(\x03R\vgeneratedAt\"Q\n" +
+	"\x18GenerateStatementRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06period\x18\x02 \x01(\tR\x06period\"\\\n" +
+	"\x13GetStatementRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06period\x18\x02 \x01(\tR\x06period\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\"_\n" +
+	"\x11StatementResponse\x124\n" +
+	"\tstatement\x18\x01 \x01(\v2\x16.transaction.StatementR\tstatement\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xa5\x02\n" +
+	"\vBusinessDay\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x1b\n" +
+	"\tday_start\x18\x02 \x01(\x03R\bdayStart\x12\x17\n" +
+	"\aday_end\x18\x03 \x01(\x03R\x06dayEnd\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1b\n" +
+	"\tclosed_at\x18\x05 \x01(\x03R\bclosedAt\x12\x1b\n" +
+	"\tclosed_by\x18\x06 \x01(\tR\bclosedBy\x12#\n" +
+	"\raccount_count\x18\a \x01(\x03R\faccountCount\x12+\n" +
+	"\x11transaction_count\x18\b \x01(\x03R\x10transactionCount\x12(\n" +
+	"\x10net_amount_cents\x18\t \x01(\x03R\x0enetAmountCents\"\xc0\x01\n" +
+	"\x0fEndOfDayBalance\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\x12#\n" +
+	"\rbalance_cents\x18\x03 \x01(\x03R\fbalanceCents\x12+\n" +
+	"\x11transaction_count\x18\x04 \x01(\x03R\x10transactionCount\x12(\n" +
+	"\x10net_amount_cents\x18\x05 \x01(\x03R\x0enetAmountCents\"P\n" +
+	"\x1bGetBusinessDayStatusRequest\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\"\xbf\x01\n" +
+	"\x19BusinessDayStatusResponse\x12*\n" +
+	"\x03day\x18\x01 \x01(\v2\x18.transaction.BusinessDayR\x03day\x12(\n" +
+	"\x10last_closed_date\x18\x02 \x01(\tR\x0elastClosedDate\x126\n" +
+	"\abalance\x18\x03 \x01(\v2\x1c.transaction.EndOfDayBalanceR\abalance\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\xc4\x04\n" +
+	"\rRecurringRule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12%\n" +
+	"\x0eoperation_type\x18\x03 \x01(\tR\roperationType\x12!\n" +
+	"\famount_cents\x18\x04 \x01(\x03R\vamountCents\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1c\n" +
+	"\tfrequency\x18\x06 \x01(\tR\tfrequency\x12\x19\n" +
+	"\bstart_at\x18\a \x01(\x03R\astartAt\x12\x15\n" +
+	"\x06end_at\x18\b \x01(\x03R\x05endAt\x12\x1e\n" +
+	"\vnext_run_at\x18\t \x01(\x03R\tnextRunAt\x12\x16\n" +
+	"\x06status\x18\n" +
+	" \x01(\tR\x06status\x12\x1b\n" +
+	"\trun_count\x18\v \x01(\x05R\brunCount\x12#\n" +
+	"\rskipped_count\x18\f \x01(\x05R\fskippedCount\x12\x1e\n" +
+	"\vlast_run_at\x18\r \x01(\x03R\tlastRunAt\x12.\n" +
+	"\x13last_transaction_id\x18\x0e \x01(\tR\x11lastTransactionId\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x0f \x01(\tR\tlastError\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\x10 \x01(\tR\tcreatedBy\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x11 \x01(\x03R\tcreatedAt\x12!\n" +
+	"\fcancelled_at\x18\x12 \x01(\x03R\vcancelledAt\"\xf7\x01\n" +
+	"\x1aCreateRecurringRuleRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12%\n" +
+	"\x0eoperation_type\x18\x02 \x01(\tR\roperationType\x12!\n" +
+	"\famount_cents\x18\x03 \x01(\x03R\vamountCents\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1c\n" +
+	"\tfrequency\x18\x05 \x01(\tR\tfrequency\x12\x19\n" +
+	"\bstart_at\x18\x06 \x01(\x03R\astartAt\x12\x15\n" +
+	"\x06end_at\x18\a \x01(\x03R\x05endAt\"R\n" +
+	"\x19ListRecurringRulesRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"d\n" +
+	"\x1aListRecurringRulesResponse\x120\n" +
+	"\x05rules\x18\x01 \x03(\v2\x1a.transaction.RecurringRuleR\x05rules\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"K\n" +
+	"\x1aCancelRecurringRuleRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"]\n" +
+	"\x15RecurringRuleResponse\x12.\n" +
+	"\x04rule\x18\x01 \x01(\v2\x1a.transaction.RecurringRuleR\x04rule\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xeb\x01\n" +
+	"\x0fMessageTemplate\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12\x18\n" +
+	"\achannel\x18\x03 \x01(\tR\achannel\x12\x18\n" +
+	"\asubject\x18\x04 \x01(\tR\asubject\x12\x12\n" +
+	"\x04body\x18\x05 \x01(\tR\x04body\x12\x16\n" +
+	"\x06source\x18\x06 \x01(\tR\x06source\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\a \x01(\tR\tupdatedBy\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\x03R\tupdatedAt\":\n" +
+	"\x1bListMessageTemplatesRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\"p\n" +
+	"\x1cListMessageTemplatesResponse\x12:\n" +
+	"\ttemplates\x18\x01 \x03(\v2\x1c.transaction.MessageTemplateR\ttemplates\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x9f\x01\n" +
+	"\x19PutMessageTemplateRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12\x18\n" +
+	"\achannel\x18\x03 \x01(\tR\achannel\x12\x18\n" +
+	"\asubject\x18\x04 \x01(\tR\asubject\x12\x12\n" +
+	"\x04body\x18\x05 \x01(\tR\x04body\"t\n" +
+	"\x1cDeleteMessageTemplateRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12\x18\n" +
+	"\achannel\x18\x03 \x01(\tR\achannel\"i\n" +
+	"\x17MessageTemplateResponse\x128\n" +
+	"\btemplate\x18\x01 \x01(\v2\x1c.transaction.MessageTemplateR\btemplate\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"Y\n" +
+	"\x0fRenderedMessage\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\tR\achannel\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\tR\x04body\"\xc6\x01\n" +
+	"\x1dPreviewMessageTemplateRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12\x18\n" +
+	"\achannel\x18\x03 \x01(\tR\achannel\x12\x18\n" +
+	"\asubject\x18\x04 \x01(\tR\asubject\x12\x12\n" +
+	"\x04body\x18\x05 \x01(\tR\x04body\x12!\n" +
+	"\fpayload_json\x18\x06 \x01(\tR\vpayloadJson\"\x95\x01\n" +
+	"\x1ePreviewMessageTemplateResponse\x126\n" +
+	"\amessage\x18\x01 \x01(\v2\x1c.transaction.RenderedMessageR\amessage\x12%\n" +
+	"\x0evariables_json\x18\x02 \x01(\tR\rvariablesJson\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xe5\x01\n" +
+	"\x1eTestSendMessageTemplateRequest\x12\x1b\n" +
+	"\ttenant_id\x18\x01 \x01(\tR\btenantId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12\x18\n" +
+	"\achannel\x18\x03 \x01(\tR\achannel\x12\x18\n" +
+	"\asubject\x18\x04 \x01(\tR\asubject\x12\x12\n" +
+	"\x04body\x18\x05 \x01(\tR\x04body\x12!\n" +
+	"\fpayload_json\x18\x06 \x01(\tR\vpayloadJson\x12\x1c\n" +
+	"\trecipient\x18\a \x01(\tR\trecipient\"\x8a\x01\n" +
+	"\x1fTestSendMessageTemplateResponse\x126\n" +
+	"\amessage\x18\x01 \x01(\v2\x1c.transaction.RenderedMessageR\amessage\x12\x19\n" +
+	"\bevent_id\x18\x02 \x01(\tR\aeventId\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error2\x952\n" +
+	"\x12TransactionService\x12\x83\x01\n" +
+	"\x11CreateTransaction\x12%.transaction.CreateTransactionRequest\x1a&.transaction.CreateTransactionResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/api/v1/transactions\x12|\n" +
+	"\x0eGetTransaction\x12\".transaction.GetTransactionRequest\x1a#.transaction.GetTransactionResponse\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/api/v1/transactions/{id}\x12\x88\x01\n" +
+	"\x11UpdateTransaction\x12%.transaction.UpdateTransactionRequest\x1a&.transaction.UpdateTransactionResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*2\x19/api/v1/transactions/{id}\x12\x9d\x01\n" +
+	"\x16GetTransactionTimeline\x12*.transaction.GetTransactionTimelineRequest\x1a+.transaction.GetTransactionTimelineResponse\"*\x82\xd3\xe4\x93\x02$\x12\"/api/v1/transactions/{id}/timeline\x12\x93\x01\n" +
+	"\x12ReverseTransaction\x12&.transaction.ReverseTransactionRequest\x1a'.transaction.ReverseTransactionResponse\",\x82\xd3\xe4\x93\x02&:\x01*\"!/api/v1/transactions/{id}/reverse\x12\xa2\x01\n" +
+	"\x15GetTransactionHistory\x12).transaction.GetTransactionHistoryRequest\x1a*.transaction.GetTransactionHistoryResponse\"2\x82\xd3\xe4\x93\x02,\x12*/api/v1/accounts/{account_id}/transactions\x12\xac\x01\n" +
+	"\x15AggregateTransactions\x12).transaction.AggregateTransactionsRequest\x1a*.transaction.AggregateTransactionsResponse\"<\x82\xd3\xe4\x93\x026\x124/api/v1/accounts/{account_id}/transactions/aggregate\x12v\n" +
+	"\x0eProcessPayment\x12\".transaction.ProcessPaymentRequest\x1a#.transaction.ProcessPaymentResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/api/v1/payments\x12e\n" +
+	"\bTransfer\x12\x1c.transaction.TransferRequest\x1a\x1d.transaction.TransferResponse\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/api/v1/transfers\x12\x86\x01\n" +
+	"\x12ListOperationRules\x12&.transaction.ListOperationRulesRequest\x1a'.transaction.ListOperationRulesResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/api/v1/operation-rules\x12\xa0\x01\n" +
+	"\x13UpdateOperationRule\x12'.transaction.UpdateOperationRuleRequest\x1a(.transaction.UpdateOperationRuleResponse\"6\x82\xd3\xe4\x93\x020:\x04rule\x1a(/api/v1/operation-rules/{operation_type}\x12\x89\x01\n" +
+	"\x11ImportChargebacks\x12%.transaction.ImportChargebacksRequest\x1a&.transaction.ImportChargebacksResponse\"%\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/api/v1/chargebacks/import\x12\x8c\x01\n" +
+	"\x13ReconcileSettlement\x12'.transaction.ReconcileSettlementRequest\x1a(.transaction.ReconcileSettlementResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/api/v1/reconciliations\x12e\n" +
+	"\x12IngestTransactions\x12%.transaction.CreateTransactionRequest\x1a$.transaction.IngestTransactionResult(\x010\x01\x12\xb1\x01\n" +
+	"\x18ExportTransactionHistory\x12,.transaction.ExportTransactionHistoryRequest\x1a*.transaction.ExportTransactionHistoryChunk\"9\x82\xd3\xe4\x93\x023\x121/api/v1/accounts/{account_id}/transactions/export0\x01\x12\x93\x01\n" +
+	"\x12StreamTransactions\x12&.transaction.StreamTransactionsRequest\x1a\x18.transaction.Transaction\"9\x82\xd3\xe4\x93\x023\x121/api/v1/accounts/{account_id}/transactions/stream0\x01\x12\x98\x01\n" +
+	"\x15ListStuckTransactions\x12).transaction.ListStuckTransactionsRequest\x1a*.transaction.ListStuckTransactionsResponse\"(\x82\xd3\xe4\x93\x02\"\x12 /api/v1/admin/transactions/stuck\x12\xac\x01\n" +
+	"\x18ResolveStuckTransactions\x12,.transaction.ResolveStuckTransactionsRequest\x1a-.transaction.ResolveStuckTransactionsResponse\"3\x82\xd3\xe4\x93\x02-:\x01*\"(/api/v1/admin/transactions/stuck/resolve\x12\xa0\x01\n" +
+	"\x17ListFlaggedTransactions\x12+.transaction.ListFlaggedTransactionsRequest\x1a,.transaction.ListFlaggedTransactionsResponse\"*\x82\xd3\xe4\x93\x02$\x12\"/api/v1/admin/transactions/flagged\x12\x95\x01\n" +
+	"\x0eApproveFlagged\x12\".transaction.ApproveFlaggedRequest\x1a#.transaction.ApproveFlaggedResponse\":\x82\xd3\xe4\x93\x024:\x01*\"//api/v1/admin/transactions/flagged/{id}/approve\x12\x95\x01\n" +
+	"\x0eDeclineFlagged\x12\".transaction.DeclineFlaggedRequest\x1a#.transaction.DeclineFlaggedResponse\":\x82\xd3\xe4\x93\x024:\x01*\"//api/v1/admin/transactions/flagged/{id}/decline\x12\x87\x01\n" +
+	"\tSetBudget\x12\x1d.transaction.SetBudgetRequest\x1a\x1e.transaction.SetBudgetResponse\";\x82\xd3\xe4\x93\x025:\x01*\x1a0/api/v1/accounts/{account_id}/budgets/{category}\x12\x8d\x01\n" +
+	"\fDeleteBudget\x12 .transaction.DeleteBudgetRequest\x1a!.transaction.DeleteBudgetResponse\"8\x82\xd3\xe4\x93\x022*0/api/v1/accounts/{account_id}/budgets/{category}\x12\x8b\x01\n" +
+	"\x0fGetBudgetStatus\x12#.transaction.GetBudgetStatusRequest\x1a$.transaction.GetBudgetStatusResponse\"-\x82\xd3\xe4\x93\x02'\x12%/api/v1/accounts/{account_id}/budgets\x12\xa1\x01\n" +
+	"\x13ListEventDeliveries\x12'.transaction.ListEventDeliveriesRequest\x1a(.transaction.ListEventDeliveriesResponse\"7\x82\xd3\xe4\x93\x021\x12//api/v1/accounts/{account_id}/events/deliveries\x12z\n" +
+	"\fGetSLOStatus\x12 .transaction.GetSLOStatusRequest\x1a!.transaction.GetSLOStatusResponse\"%\x82\xd3\xe4\x93\x02\x1f\x12\x1d/api/v1/admin/slo/transaction\x12\xa5\x01\n" +
+	"\x16ListWebhookSigningKeys\x12*.transaction.ListWebhookSigningKeysRequest\x1a+.transaction.ListWebhookSigningKeysResponse\"2\x82\xd3\xe4\x93\x02,\x12*/api/v1/webhooks/{subscriber}/signing-keys\x12\xa2\x01\n" +
+	"\x17CreateWebhookSigningKey\x12+.transaction.CreateWebhookSigningKeyRequest\x1a&.transaction.WebhookSigningKeyResponse\"2\x82\xd3\xe4\x93\x02,\"*/api/v1/webhooks/{subscriber}/signing-keys\x12\xb2\x01\n" +
+	"\x17RetireWebhookSigningKey\x12+.transaction.RetireWebhookSigningKeyRequest\x1a&.transaction.WebhookSigningKeyResponse\"B\x82\xd3\xe4\x93\x02<\":/api/v1/webhooks/{subscriber}/signing-keys/{key_id}/retire\x12l\n" +
+	"\vCreateBatch\x12\x1f.transaction.CreateBatchRequest\x1a .transaction.CreateBatchResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/api/v1/batches\x12e\n" +
+	"\bGetBatch\x12\x1c.transaction.GetBatchRequest\x1a\x1d.transaction.GetBatchResponse\"\x1c\x82\xd3\xe4\x93\x02\x16\x12\x14/api/v1/batches/{id}\x12\x95\x01\n" +
+	"\x11GenerateStatement\x12%.transaction.GenerateStatementRequest\x1a\x1e.transaction.StatementResponse\"9\x82\xd3\xe4\x93\x023\"1/api/v1/accounts/{account_id}/statements/{period}\x12\x8b\x01\n" +
+	"\fGetStatement\x12 .transaction.GetStatementRequest\x1a\x1e.transaction.StatementResponse\"9\x82\xd3\xe4\x93\x023\x121/api/v1/accounts/{account_id}/statements/{period}\x12\x8e\x01\n" +
+	"\x14GetBusinessDayStatus\x12(.transaction.GetBusinessDayStatusRequest\x1a&.transaction.BusinessDayStatusResponse\"$\x82\xd3\xe4\x93\x02\x1e\x12\x1c/api/v1/business-days/{date}\x12\x96\x01\n" +
+	"\x13CreateRecurringRule\x12'.transaction.CreateRecurringRuleRequest\x1a\".transaction.RecurringRuleResponse\"2\x82\xd3\xe4\x93\x02,:\x01*\"'/api/v1/accounts/{account_id}/recurring\x12\x96\x01\n" +
+	"\x12ListRecurringRules\x12&.transaction.ListRecurringRulesRequest\x1a'.transaction.ListRecurringRulesResponse\"/\x82\xd3\xe4\x93\x02)\x12'/api/v1/accounts/{account_id}/recurring\x12\x98\x01\n" +
+	"\x13CancelRecurringRule\x12'.transaction.CancelRecurringRuleRequest\x1a\".transaction.RecurringRuleResponse\"4\x82\xd3\xe4\x93\x02.*,/api/v1/accounts/{account_id}/recurring/{id}\x12\xa8\x01\n" +
+	"\x14ListMessageTemplates\x12(.transaction.ListMessageTemplatesRequest\x1a).transaction.ListMessageTemplatesResponse\";\x82\xd3\xe4\x93\x025\x123/api/v1/admin/tenants/{tenant_id}/message-templates\x12\xb9\x01\n" +
+	"\x12PutMessageTemplate\x12&.transaction.PutMessageTemplateRequest\x1a$.transaction.MessageTemplateResponse\"U\x82\xd3\xe4\x93\x02O:\x01*\x1aJ/api/v1/admin/tenants/{tenant_id}/message-templates/{event_type}/{channel}\x12\xbc\x01\n" +
+	"\x15DeleteMessageTemplate\x12).transaction.DeleteMessageTemplateRequest\x1a$.transaction.MessageTemplateResponse\"R\x82\xd3\xe4\x93\x02L*J/api/v1/admin/tenants/{tenant_id}/message-templates/{event_type}/{channel}\x12\xd0\x01\n" +
+	"\x16PreviewMessageTemplate\x12*.transaction.PreviewMessageTemplateRequest\x1a+.transaction.PreviewMessageTemplateResponse\"]\x82\xd3\xe4\x93\x02W:\x01*\"R/api/v1/admin/tenants/{tenant_id}/message-templates/{event_type}/{channel}/preview\x12\xd5\x01\n" +
+	"\x17TestSendMessageTemplate\x12+.transaction.TestSendMessageTemplateRequest\x1a,.transaction.TestSendMessageTemplateResponse\"_\x82\xd3\xe4\x93\x02Y:\x01*\"T/api/v1/admin/tenants/{tenant_id}/message-templates/{event_type}/{channel}/test-send2\x86\x01\n" +
+	"\x1bTransactionAnalyticsService\x12g\n" +
+	"\x12StreamTransactions\x12&.transaction.StreamTransactionsRequest\x1a'.transaction.StreamTransactionsResponse0\x01B\x0fZ\r./transactionb\x06proto3"
 
 var (
 	file_transaction_proto_rawDescOnce sync.Once
@@ -627,36 +8529,279 @@ func file_transaction_proto_rawDescGZIP() []byte {
 	return file_transaction_proto_rawDescData
 }
 
-var file_transaction_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_transaction_proto_msgTypes = make([]protoimpl.MessageInfo, 114)
 var file_transaction_proto_goTypes = []any{
-	(*Transaction)(nil),                   // 0: transaction.Transaction
-	(*CreateTransactionRequest)(nil),      // 1: transaction.CreateTransactionRequest
-	(*CreateTransactionResponse)(nil),     // 2: transaction.CreateTransactionResponse
-	(*GetTransactionRequest)(nil),         // 3: transaction.GetTransactionRequest
-	(*GetTransactionResponse)(nil),        // 4: transaction.GetTransactionResponse
-	(*GetTransactionHistoryRequest)(nil),  // 5: transaction.GetTransactionHistoryRequest
-	(*GetTransactionHistoryResponse)(nil), // 6: transaction.GetTransactionHistoryResponse
-	(*ProcessPaymentRequest)(nil),         // 7: transaction.ProcessPaymentRequest
-	(*ProcessPaymentResponse)(nil),        // 8: transaction.ProcessPaymentResponse
+	(*Transaction)(nil),                      // 0: transaction.Transaction
+	(*InstallmentPlan)(nil),                  // 1: transaction.InstallmentPlan
+	(*Installment)(nil),                      // 2: transaction.Installment
+	(*CreateTransactionRequest)(nil),         // 3: transaction.CreateTransactionRequest
+	(*CreateTransactionResponse)(nil),        // 4: transaction.CreateTransactionResponse
+	(*Discharge)(nil),                        // 5: transaction.Discharge
+	(*GetTransactionRequest)(nil),            // 6: transaction.GetTransactionRequest
+	(*GetTransactionResponse)(nil),           // 7: transaction.GetTransactionResponse
+	(*UpdateTransactionRequest)(nil),         // 8: transaction.UpdateTransactionRequest
+	(*UpdateTransactionResponse)(nil),        // 9: transaction.UpdateTransactionResponse
+	(*TransactionEditValues)(nil),            // 10: transaction.TransactionEditValues
+	(*TransactionEdit)(nil),                  // 11: transaction.TransactionEdit
+	(*TimelineEvent)(nil),                    // 12: transaction.TimelineEvent
+	(*GetTransactionTimelineRequest)(nil),    // 13: transaction.GetTransactionTimelineRequest
+	(*GetTransactionTimelineResponse)(nil),   // 14: transaction.GetTransactionTimelineResponse
+	(*GetTransactionHistoryRequest)(nil),     // 15: transaction.GetTransactionHistoryRequest
+	(*GetTransactionHistoryResponse)(nil),    // 16: transaction.GetTransactionHistoryResponse
+	(*AggregateTransactionsRequest)(nil),     // 17: transaction.AggregateTransactionsRequest
+	(*TransactionBucket)(nil),                // 18: transaction.TransactionBucket
+	(*AggregateTransactionsResponse)(nil),    // 19: transaction.AggregateTransactionsResponse
+	(*ProcessPaymentRequest)(nil),            // 20: transaction.ProcessPaymentRequest
+	(*ProcessPaymentResponse)(nil),           // 21: transaction.ProcessPaymentResponse
+	(*ReverseTransactionRequest)(nil),        // 22: transaction.ReverseTransactionRequest
+	(*ReverseTransactionResponse)(nil),       // 23: transaction.ReverseTransactionResponse
+	(*TransferRequest)(nil),                  // 24: transaction.TransferRequest
+	(*TransferResponse)(nil),                 // 25: transaction.TransferResponse
+	(*IngestTransactionResult)(nil),          // 26: transaction.IngestTransactionResult
+	(*OperationRule)(nil),                    // 27: transaction.OperationRule
+	(*ListOperationRulesRequest)(nil),        // 28: transaction.ListOperationRulesRequest
+	(*ListOperationRulesResponse)(nil),       // 29: transaction.ListOperationRulesResponse
+	(*UpdateOperationRuleRequest)(nil),       // 30: transaction.UpdateOperationRuleRequest
+	(*UpdateOperationRuleResponse)(nil),      // 31: transaction.UpdateOperationRuleResponse
+	(*Dispute)(nil),                          // 32: transaction.Dispute
+	(*ImportChargebacksRequest)(nil),         // 33: transaction.ImportChargebacksRequest
+	(*UnmatchedChargeback)(nil),              // 34: transaction.UnmatchedChargeback
+	(*ImportChargebacksResponse)(nil),        // 35: transaction.ImportChargebacksResponse
+	(*ReconcileSettlementRequest)(nil),       // 36: transaction.ReconcileSettlementRequest
+	(*ReconciliationLine)(nil),               // 37: transaction.ReconciliationLine
+	(*ReconciliationAdjustment)(nil),         // 38: transaction.ReconciliationAdjustment
+	(*ReconcileSettlementResponse)(nil),      // 39: transaction.ReconcileSettlementResponse
+	(*ExportTransactionHistoryRequest)(nil),  // 40: transaction.ExportTransactionHistoryRequest
+	(*ExportTransactionHistoryChunk)(nil),    // 41: transaction.ExportTransactionHistoryChunk
+	(*ListStuckTransactionsRequest)(nil),     // 42: transaction.ListStuckTransactionsRequest
+	(*ListStuckTransactionsResponse)(nil),    // 43: transaction.ListStuckTransactionsResponse
+	(*ResolveStuckTransactionsRequest)(nil),  // 44: transaction.ResolveStuckTransactionsRequest
+	(*TransactionResolution)(nil),            // 45: transaction.TransactionResolution
+	(*ResolveStuckTransactionResult)(nil),    // 46: transaction.ResolveStuckTransactionResult
+	(*ResolveStuckTransactionsResponse)(nil), // 47: transaction.ResolveStuckTransactionsResponse
+	(*FlaggedTransaction)(nil),               // 48: transaction.FlaggedTransaction
+	(*ListFlaggedTransactionsRequest)(nil),   // 49: transaction.ListFlaggedTransactionsRequest
+	(*ListFlaggedTransactionsResponse)(nil),  // 50: transaction.ListFlaggedTransactionsResponse
+	(*ApproveFlaggedRequest)(nil),            // 51: transaction.ApproveFlaggedRequest
+	(*ApproveFlaggedResponse)(nil),           // 52: transaction.ApproveFlaggedResponse
+	(*DeclineFlaggedRequest)(nil),            // 53: transaction.DeclineFlaggedRequest
+	(*DeclineFlaggedResponse)(nil),           // 54: transaction.DeclineFlaggedResponse
+	(*Budget)(nil),                           // 55: transaction.Budget
+	(*SetBudgetRequest)(nil),                 // 56: transaction.SetBudgetRequest
+	(*SetBudgetResponse)(nil),                // 57: transaction.SetBudgetResponse
+	(*DeleteBudgetRequest)(nil),              // 58: transaction.DeleteBudgetRequest
+	(*DeleteBudgetResponse)(nil),             // 59: transaction.DeleteBudgetResponse
+	(*BudgetStatus)(nil),                     // 60: transaction.BudgetStatus
+	(*GetBudgetStatusRequest)(nil),           // 61: transaction.GetBudgetStatusRequest
+	(*GetBudgetStatusResponse)(nil),          // 62: transaction.GetBudgetStatusResponse
+	(*EventDelivery)(nil),                    // 63: transaction.EventDelivery
+	(*AccountEvent)(nil),                     // 64: transaction.AccountEvent
+	(*ListEventDeliveriesRequest)(nil),       // 65: transaction.ListEventDeliveriesRequest
+	(*ListEventDeliveriesResponse)(nil),      // 66: transaction.ListEventDeliveriesResponse
+	(*WebhookSigningKey)(nil),                // 67: transaction.WebhookSigningKey
+	(*ListWebhookSigningKeysRequest)(nil),    // 68: transaction.ListWebhookSigningKeysRequest
+	(*ListWebhookSigningKeysResponse)(nil),   // 69: transaction.ListWebhookSigningKeysResponse
+	(*CreateWebhookSigningKeyRequest)(nil),   // 70: transaction.CreateWebhookSigningKeyRequest
+	(*RetireWebhookSigningKeyRequest)(nil),   // 71: transaction.RetireWebhookSigningKeyRequest
+	(*WebhookSigningKeyResponse)(nil),        // 72: transaction.WebhookSigningKeyResponse
+	(*StreamTransactionsRequest)(nil),        // 73: transaction.StreamTransactionsRequest
+	(*StreamTransactionsResponse)(nil),       // 74: transaction.StreamTransactionsResponse
+	(*GetSLOStatusRequest)(nil),              // 75: transaction.GetSLOStatusRequest
+	(*SLOWindowStatus)(nil),                  // 76: transaction.SLOWindowStatus
+	(*MethodSLOStatus)(nil),                  // 77: transaction.MethodSLOStatus
+	(*GetSLOStatusResponse)(nil),             // 78: transaction.GetSLOStatusResponse
+	(*TransactionBatch)(nil),                 // 79: transaction.TransactionBatch
+	(*BatchFailure)(nil),                     // 80: transaction.BatchFailure
+	(*CreateBatchRequest)(nil),               // 81: transaction.CreateBatchRequest
+	(*CreateBatchResponse)(nil),              // 82: transaction.CreateBatchResponse
+	(*GetBatchRequest)(nil),                  // 83: transaction.GetBatchRequest
+	(*GetBatchResponse)(nil),                 // 84: transaction.GetBatchResponse
+	(*StatementTotal)(nil),                   // 85: transaction.StatementTotal
+	(*Statement)(nil),                        // 86: transaction.Statement
+	(*GenerateStatementRequest)(nil),         // 87: transaction.GenerateStatementRequest
+	(*GetStatementRequest)(nil),              // 88: transaction.GetStatementRequest
+	(*StatementResponse)(nil),                // 89: transaction.StatementResponse
+	(*BusinessDay)(nil),                      // 90: transaction.BusinessDay
+	(*EndOfDayBalance)(nil),                  // 91: transaction.EndOfDayBalance
+	(*GetBusinessDayStatusRequest)(nil),      // 92: transaction.GetBusinessDayStatusRequest
+	(*BusinessDayStatusResponse)(nil),        // 93: transaction.BusinessDayStatusResponse
+	(*RecurringRule)(nil),                    // 94: transaction.RecurringRule
+	(*CreateRecurringRuleRequest)(nil),       // 95: transaction.CreateRecurringRuleRequest
+	(*ListRecurringRulesRequest)(nil),        // 96: transaction.ListRecurringRulesRequest
+	(*ListRecurringRulesResponse)(nil),       // 97: transaction.ListRecurringRulesResponse
+	(*CancelRecurringRuleRequest)(nil),       // 98: transaction.CancelRecurringRuleRequest
+	(*RecurringRuleResponse)(nil),            // 99: transaction.RecurringRuleResponse
+	(*MessageTemplate)(nil),                  // 100: transaction.MessageTemplate
+	(*ListMessageTemplatesRequest)(nil),      // 101: transaction.ListMessageTemplatesRequest
+	(*ListMessageTemplatesResponse)(nil),     // 102: transaction.ListMessageTemplatesResponse
+	(*PutMessageTemplateRequest)(nil),        // 103: transaction.PutMessageTemplateRequest
+	(*DeleteMessageTemplateRequest)(nil),     // 104: transaction.DeleteMessageTemplateRequest
+	(*MessageTemplateResponse)(nil),          // 105: transaction.MessageTemplateResponse
+	(*RenderedMessage)(nil),                  // 106: transaction.RenderedMessage
+	(*PreviewMessageTemplateRequest)(nil),    // 107: transaction.PreviewMessageTemplateRequest
+	(*PreviewMessageTemplateResponse)(nil),   // 108: transaction.PreviewMessageTemplateResponse
+	(*TestSendMessageTemplateRequest)(nil),   // 109: transaction.TestSendMessageTemplateRequest
+	(*TestSendMessageTemplateResponse)(nil),  // 110: transaction.TestSendMessageTemplateResponse
+	nil,                                      // 111: transaction.Transaction.MetadataEntry
+	nil,                                      // 112: transaction.UpdateTransactionRequest.MetadataEntry
+	nil,                                      // 113: transaction.TransactionEditValues.MetadataEntry
 }
 var file_transaction_proto_depIdxs = []int32{
-	0, // 0: transaction.CreateTransactionResponse.transaction:type_name -> transaction.Transaction
-	0, // 1: transaction.GetTransactionResponse.transaction:type_name -> transaction.Transaction
-	0, // 2: transaction.GetTransactionHistoryResponse.transactions:type_name -> transaction.Transaction
-	0, // 3: transaction.ProcessPaymentResponse.transaction:type_name -> transaction.Transaction
-	1, // 4: transaction.TransactionService.CreateTransaction:input_type -> transaction.CreateTransactionRequest
-	3, // 5: transaction.TransactionService.GetTransaction:input_type -> transaction.GetTransactionRequest
-	5, // 6: transaction.TransactionService.GetTransactionHistory:input_type -> transaction.GetTransactionHistoryRequest
-	7, // 7: transaction.TransactionService.ProcessPayment:input_type -> transaction.ProcessPaymentRequest
-	2, // 8: transaction.TransactionService.CreateTransaction:output_type -> transaction.CreateTransactionResponse
-	4, // 9: transaction.TransactionService.GetTransaction:output_type -> transaction.GetTransactionResponse
-	6, // 10: transaction.TransactionService.GetTransactionHistory:output_type -> transaction.GetTransactionHistoryResponse
-	8, // 11: transaction.TransactionService.ProcessPayment:output_type -> transaction.ProcessPaymentResponse
-	8, // [8:12] is the sub-list for method output_type
-	4, // [4:8] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	111, // 0: transaction.Transaction.metadata:type_name -> transaction.Transaction.MetadataEntry
+	1,   // 1: transaction.Transaction.installment_plan:type_name -> transaction.InstallmentPlan
+	2,   // 2: transaction.InstallmentPlan.installments:type_name -> transaction.Installment
+	0,   // 3: transaction.CreateTransactionResponse.transaction:type_name -> transaction.Transaction
+	5,   // 4: transaction.CreateTransactionResponse.discharges:type_name -> transaction.Discharge
+	0,   // 5: transaction.GetTransactionResponse.transaction:type_name -> transaction.Transaction
+	112, // 6: transaction.UpdateTransactionRequest.metadata:type_name -> transaction.UpdateTransactionRequest.MetadataEntry
+	0,   // 7: transaction.UpdateTransactionResponse.transaction:type_name -> transaction.Transaction
+	113, // 8: transaction.TransactionEditValues.metadata:type_name -> transaction.TransactionEditValues.MetadataEntry
+	10,  // 9: transaction.TransactionEdit.previous:type_name -> transaction.TransactionEditValues
+	10,  // 10: transaction.TransactionEdit.updated:type_name -> transaction.TransactionEditValues
+	11,  // 11: transaction.TimelineEvent.edit:type_name -> transaction.TransactionEdit
+	32,  // 12: transaction.TimelineEvent.dispute:type_name -> transaction.Dispute
+	0,   // 13: transaction.GetTransactionTimelineResponse.transaction:type_name -> transaction.Transaction
+	12,  // 14: transaction.GetTransactionTimelineResponse.events:type_name -> transaction.TimelineEvent
+	0,   // 15: transaction.GetTransactionHistoryResponse.transactions:type_name -> transaction.Transaction
+	18,  // 16: transaction.AggregateTransactionsResponse.buckets:type_name -> transaction.TransactionBucket
+	0,   // 17: transaction.ProcessPaymentResponse.transaction:type_name -> transaction.Transaction
+	0,   // 18: transaction.ReverseTransactionResponse.original:type_name -> transaction.Transaction
+	0,   // 19: transaction.ReverseTransactionResponse.reversal:type_name -> transaction.Transaction
+	0,   // 20: transaction.TransferResponse.debit:type_name -> transaction.Transaction
+	0,   // 21: transaction.TransferResponse.credit:type_name -> transaction.Transaction
+	0,   // 22: transaction.IngestTransactionResult.transaction:type_name -> transaction.Transaction
+	27,  // 23: transaction.ListOperationRulesResponse.rules:type_name -> transaction.OperationRule
+	27,  // 24: transaction.UpdateOperationRuleRequest.rule:type_name -> transaction.OperationRule
+	27,  // 25: transaction.UpdateOperationRuleResponse.rule:type_name -> transaction.OperationRule
+	32,  // 26: transaction.ImportChargebacksResponse.opened:type_name -> transaction.Dispute
+	34,  // 27: transaction.ImportChargebacksResponse.unmatched:type_name -> transaction.UnmatchedChargeback
+	37,  // 28: transaction.ReconcileSettlementResponse.matched:type_name -> transaction.ReconciliationLine
+	37,  // 29: transaction.ReconcileSettlementResponse.mismatched:type_name -> transaction.ReconciliationLine
+	37,  // 30: transaction.ReconcileSettlementResponse.missing:type_name -> transaction.ReconciliationLine
+	0,   // 31: transaction.ReconcileSettlementResponse.extra:type_name -> transaction.Transaction
+	37,  // 32: transaction.ReconcileSettlementResponse.invalid:type_name -> transaction.ReconciliationLine
+	38,  // 33: transaction.ReconcileSettlementResponse.adjustments:type_name -> transaction.ReconciliationAdjustment
+	0,   // 34: transaction.ListStuckTransactionsResponse.transactions:type_name -> transaction.Transaction
+	45,  // 35: transaction.ResolveStuckTransactionResult.resolution:type_name -> transaction.TransactionResolution
+	46,  // 36: transaction.ResolveStuckTransactionsResponse.results:type_name -> transaction.ResolveStuckTransactionResult
+	0,   // 37: transaction.FlaggedTransaction.transaction:type_name -> transaction.Transaction
+	48,  // 38: transaction.ListFlaggedTransactionsResponse.transactions:type_name -> transaction.FlaggedTransaction
+	48,  // 39: transaction.ApproveFlaggedResponse.transaction:type_name -> transaction.FlaggedTransaction
+	48,  // 40: transaction.DeclineFlaggedResponse.transaction:type_name -> transaction.FlaggedTransaction
+	55,  // 41: transaction.SetBudgetResponse.budget:type_name -> transaction.Budget
+	55,  // 42: transaction.BudgetStatus.budget:type_name -> transaction.Budget
+	60,  // 43: transaction.GetBudgetStatusResponse.budgets:type_name -> transaction.BudgetStatus
+	63,  // 44: transaction.AccountEvent.deliveries:type_name -> transaction.EventDelivery
+	64,  // 45: transaction.ListEventDeliveriesResponse.events:type_name -> transaction.AccountEvent
+	67,  // 46: transaction.ListWebhookSigningKeysResponse.keys:type_name -> transaction.WebhookSigningKey
+	67,  // 47: transaction.WebhookSigningKeyResponse.key:type_name -> transaction.WebhookSigningKey
+	0,   // 48: transaction.StreamTransactionsResponse.transactions:type_name -> transaction.Transaction
+	76,  // 49: transaction.MethodSLOStatus.windows:type_name -> transaction.SLOWindowStatus
+	77,  // 50: transaction.GetSLOStatusResponse.methods:type_name -> transaction.MethodSLOStatus
+	79,  // 51: transaction.CreateBatchResponse.batch:type_name -> transaction.TransactionBatch
+	79,  // 52: transaction.GetBatchResponse.batch:type_name -> transaction.TransactionBatch
+	80,  // 53: transaction.GetBatchResponse.failures:type_name -> transaction.BatchFailure
+	85,  // 54: transaction.Statement.totals:type_name -> transaction.StatementTotal
+	86,  // 55: transaction.StatementResponse.statement:type_name -> transaction.Statement
+	90,  // 56: transaction.BusinessDayStatusResponse.day:type_name -> transaction.BusinessDay
+	91,  // 57: transaction.BusinessDayStatusResponse.balance:type_name -> transaction.EndOfDayBalance
+	94,  // 58: transaction.ListRecurringRulesResponse.rules:type_name -> transaction.RecurringRule
+	94,  // 59: transaction.RecurringRuleResponse.rule:type_name -> transaction.RecurringRule
+	100, // 60: transaction.ListMessageTemplatesResponse.templates:type_name -> transaction.MessageTemplate
+	100, // 61: transaction.MessageTemplateResponse.template:type_name -> transaction.MessageTemplate
+	106, // 62: transaction.PreviewMessageTemplateResponse.message:type_name -> transaction.RenderedMessage
+	106, // 63: transaction.TestSendMessageTemplateResponse.message:type_name -> transaction.RenderedMessage
+	3,   // 64: transaction.TransactionService.CreateTransaction:input_type -> transaction.CreateTransactionRequest
+	6,   // 65: transaction.TransactionService.GetTransaction:input_type -> transaction.GetTransactionRequest
+	8,   // 66: transaction.TransactionService.UpdateTransaction:input_type -> transaction.UpdateTransactionRequest
+	13,  // 67: transaction.TransactionService.GetTransactionTimeline:input_type -> transaction.GetTransactionTimelineRequest
+	22,  // 68: transaction.TransactionService.ReverseTransaction:input_type -> transaction.ReverseTransactionRequest
+	15,  // 69: transaction.TransactionService.GetTransactionHistory:input_type -> transaction.GetTransactionHistoryRequest
+	17,  // 70: transaction.TransactionService.AggregateTransactions:input_type -> transaction.AggregateTransactionsRequest
+	20,  // 71: transaction.TransactionService.ProcessPayment:input_type -> transaction.ProcessPaymentRequest
+	24,  // 72: transaction.TransactionService.Transfer:input_type -> transaction.TransferRequest
+	28,  // 73: transaction.TransactionService.ListOperationRules:input_type -> transaction.ListOperationRulesRequest
+	30,  // 74: transaction.TransactionService.UpdateOperationRule:input_type -> transaction.UpdateOperationRuleRequest
+	33,  // 75: transaction.TransactionService.ImportChargebacks:input_type -> transaction.ImportChargebacksRequest
+	36,  // 76: transaction.TransactionService.ReconcileSettlement:input_type -> transaction.ReconcileSettlementRequest
+	3,   // 77: transaction.TransactionService.IngestTransactions:input_type -> transaction.CreateTransactionRequest
+	40,  // 78: transaction.TransactionService.ExportTransactionHistory:input_type -> transaction.ExportTransactionHistoryRequest
+	73,  // 79: transaction.TransactionService.StreamTransactions:input_type -> transaction.StreamTransactionsRequest
+	42,  // 80: transaction.TransactionService.ListStuckTransactions:input_type -> transaction.ListStuckTransactionsRequest
+	44,  // 81: transaction.TransactionService.ResolveStuckTransactions:input_type -> transaction.ResolveStuckTransactionsRequest
+	49,  // 82: transaction.TransactionService.ListFlaggedTransactions:input_type -> transaction.ListFlaggedTransactionsRequest
+	51,  // 83: transaction.TransactionService.ApproveFlagged:input_type -> transaction.ApproveFlaggedRequest
+	53,  // 84: transaction.TransactionService.DeclineFlagged:input_type -> transaction.DeclineFlaggedRequest
+	56,  // 85: transaction.TransactionService.SetBudget:input_type -> transaction.SetBudgetRequest
+	58,  // 86: transaction.TransactionService.DeleteBudget:input_type -> transaction.DeleteBudgetRequest
+	61,  // 87: transaction.TransactionService.GetBudgetStatus:input_type -> transaction.GetBudgetStatusRequest
+	65,  // 88: transaction.TransactionService.ListEventDeliveries:input_type -> transaction.ListEventDeliveriesRequest
+	75,  // 89: transaction.TransactionService.GetSLOStatus:input_type -> transaction.GetSLOStatusRequest
+	68,  // 90: transaction.TransactionService.ListWebhookSigningKeys:input_type -> transaction.ListWebhookSigningKeysRequest
+	70,  // 91: transaction.TransactionService.CreateWebhookSigningKey:input_type -> transaction.CreateWebhookSigningKeyRequest
+	71,  // 92: transaction.TransactionService.RetireWebhookSigningKey:input_type -> transaction.RetireWebhookSigningKeyRequest
+	81,  // 93: transaction.TransactionService.CreateBatch:input_type -> transaction.CreateBatchRequest
+	83,  // 94: transaction.TransactionService.GetBatch:input_type -> transaction.GetBatchRequest
+	87,  // 95: transaction.TransactionService.GenerateStatement:input_type -> transaction.GenerateStatementRequest
+	88,  // 96: transaction.TransactionService.GetStatement:input_type -> transaction.GetStatementRequest
+	92,  // 97: transaction.TransactionService.GetBusinessDayStatus:input_type -> transaction.GetBusinessDayStatusRequest
+	95,  // 98: transaction.TransactionService.CreateRecurringRule:input_type -> transaction.CreateRecurringRuleRequest
+	96,  // 99: transaction.TransactionService.ListRecurringRules:input_type -> transaction.ListRecurringRulesRequest
+	98,  // 100: transaction.TransactionService.CancelRecurringRule:input_type -> transaction.CancelRecurringRuleRequest
+	101, // 101: transaction.TransactionService.ListMessageTemplates:input_type -> transaction.ListMessageTemplatesRequest
+	103, // 102: transaction.TransactionService.PutMessageTemplate:input_type -> transaction.PutMessageTemplateRequest
+	104, // 103: transaction.TransactionService.DeleteMessageTemplate:input_type -> transaction.DeleteMessageTemplateRequest
+	107, // 104: transaction.TransactionService.PreviewMessageTemplate:input_type -> transaction.PreviewMessageTemplateRequest
+	109, // 105: transaction.TransactionService.TestSendMessageTemplate:input_type -> transaction.TestSendMessageTemplateRequest
+	73,  // 106: transaction.TransactionAnalyticsService.StreamTransactions:input_type -> transaction.StreamTransactionsRequest
+	4,   // 107: transaction.TransactionService.CreateTransaction:output_type -> transaction.CreateTransactionResponse
+	7,   // 108: transaction.TransactionService.GetTransaction:output_type -> transaction.GetTransactionResponse
+	9,   // 109: transaction.TransactionService.UpdateTransaction:output_type -> transaction.UpdateTransactionResponse
+	14,  // 110: transaction.TransactionService.GetTransactionTimeline:output_type -> transaction.GetTransactionTimelineResponse
+	23,  // 111: transaction.TransactionService.ReverseTransaction:output_type -> transaction.ReverseTransactionResponse
+	16,  // 112: transaction.TransactionService.GetTransactionHistory:output_type -> transaction.GetTransactionHistoryResponse
+	19,  // 113: transaction.TransactionService.AggregateTransactions:output_type -> transaction.AggregateTransactionsResponse
+	21,  // 114: transaction.TransactionService.ProcessPayment:output_type -> transaction.ProcessPaymentResponse
+	25,  // 115: transaction.TransactionService.Transfer:output_type -> transaction.TransferResponse
+	29,  // 116: transaction.TransactionService.ListOperationRules:output_type -> transaction.ListOperationRulesResponse
+	31,  // 117: transaction.TransactionService.UpdateOperationRule:output_type -> transaction.UpdateOperationRuleResponse
+	35,  // 118: transaction.TransactionService.ImportChargebacks:output_type -> transaction.ImportChargebacksResponse
+	39,  // 119: transaction.TransactionService.ReconcileSettlement:output_type -> transaction.ReconcileSettlementResponse
+	26,  // 120: transaction.TransactionService.IngestTransactions:output_type -> transaction.IngestTransactionResult
+	41,  // 121: transaction.TransactionService.ExportTransactionHistory:output_type -> transaction.ExportTransactionHistoryChunk
+	0,   // 122: transaction.TransactionService.StreamTransactions:output_type -> transaction.Transaction
+	43,  // 123: transaction.TransactionService.ListStuckTransactions:output_type -> transaction.ListStuckTransactionsResponse
+	47,  // 124: transaction.TransactionService.ResolveStuckTransactions:output_type -> transaction.ResolveStuckTransactionsResponse
+	50,  // 125: transaction.TransactionService.ListFlaggedTransactions:output_type -> transaction.ListFlaggedTransactionsResponse
+	52,  // 126: transaction.TransactionService.ApproveFlagged:output_type -> transaction.ApproveFlaggedResponse
+	54,  // 127: transaction.TransactionService.DeclineFlagged:output_type -> transaction.DeclineFlaggedResponse
+	57,  // 128: transaction.TransactionService.SetBudget:output_type -> transaction.SetBudgetResponse
+	59,  // 129: transaction.TransactionService.DeleteBudget:output_type -> transaction.DeleteBudgetResponse
+	62,  // 130: transaction.TransactionService.GetBudgetStatus:output_type -> transaction.GetBudgetStatusResponse
+	66,  // 131: transaction.TransactionService.ListEventDeliveries:output_type -> transaction.ListEventDeliveriesResponse
+	78,  // 132: transaction.TransactionService.GetSLOStatus:output_type -> transaction.GetSLOStatusResponse
+	69,  // 133: transaction.TransactionService.ListWebhookSigningKeys:output_type -> transaction.ListWebhookSigningKeysResponse
+	72,  // 134: transaction.TransactionService.CreateWebhookSigningKey:output_type -> transaction.WebhookSigningKeyResponse
+	72,  // 135: transaction.TransactionService.RetireWebhookSigningKey:output_type -> transaction.WebhookSigningKeyResponse
+	82,  // 136: transaction.TransactionService.CreateBatch:output_type -> transaction.CreateBatchResponse
+	84,  // 137: transaction.TransactionService.GetBatch:output_type -> transaction.GetBatchResponse
+	89,  // 138: transaction.TransactionService.GenerateStatement:output_type -> transaction.StatementResponse
+	89,  // 139: transaction.TransactionService.GetStatement:output_type -> transaction.StatementResponse
+	93,  // 140: transaction.TransactionService.GetBusinessDayStatus:output_type -> transaction.BusinessDayStatusResponse
+	99,  // 141: transaction.TransactionService.CreateRecurringRule:output_type -> transaction.RecurringRuleResponse
+	97,  // 142: transaction.TransactionService.ListRecurringRules:output_type -> transaction.ListRecurringRulesResponse
+	99,  // 143: transaction.TransactionService.CancelRecurringRule:output_type -> transaction.RecurringRuleResponse
+	102, // 144: transaction.TransactionService.ListMessageTemplates:output_type -> transaction.ListMessageTemplatesResponse
+	105, // 145: transaction.TransactionService.PutMessageTemplate:output_type -> transaction.MessageTemplateResponse
+	105, // 146: transaction.TransactionService.DeleteMessageTemplate:output_type -> transaction.MessageTemplateResponse
+	108, // 147: transaction.TransactionService.PreviewMessageTemplate:output_type -> transaction.PreviewMessageTemplateResponse
+	110, // 148: transaction.TransactionService.TestSendMessageTemplate:output_type -> transaction.TestSendMessageTemplateResponse
+	74,  // 149: transaction.TransactionAnalyticsService.StreamTransactions:output_type -> transaction.StreamTransactionsResponse
+	107, // [107:150] is the sub-list for method output_type
+	64,  // [64:107] is the sub-list for method input_type
+	64,  // [64:64] is the sub-list for extension type_name
+	64,  // [64:64] is the sub-list for extension extendee
+	0,   // [0:64] is the sub-list for field type_name
 }
 
 func init() { file_transaction_proto_init() }
@@ -670,9 +8815,9 @@ func file_transaction_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_transaction_proto_rawDesc), len(file_transaction_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   114,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_transaction_proto_goTypes,
 		DependencyIndexes: file_transaction_proto_depIdxs,
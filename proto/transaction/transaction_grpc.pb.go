@@ -19,10 +19,48 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TransactionService_CreateTransaction_FullMethodName     = "/transaction.TransactionService/CreateTransaction"
-	TransactionService_GetTransaction_FullMethodName        = "/transaction.TransactionService/GetTransaction"
-	TransactionService_GetTransactionHistory_FullMethodName = "/transaction.TransactionService/GetTransactionHistory"
-	TransactionService_ProcessPayment_FullMethodName        = "/transaction.TransactionService/ProcessPayment"
+	TransactionService_CreateTransaction_FullMethodName        = "/transaction.TransactionService/CreateTransaction"
+	TransactionService_GetTransaction_FullMethodName           = "/transaction.TransactionService/GetTransaction"
+	TransactionService_UpdateTransaction_FullMethodName        = "/transaction.TransactionService/UpdateTransaction"
+	TransactionService_GetTransactionTimeline_FullMethodName   = "/transaction.TransactionService/GetTransactionTimeline"
+	TransactionService_ReverseTransaction_FullMethodName       = "/transaction.TransactionService/ReverseTransaction"
+	TransactionService_GetTransactionHistory_FullMethodName    = "/transaction.TransactionService/GetTransactionHistory"
+	TransactionService_AggregateTransactions_FullMethodName    = "/transaction.TransactionService/AggregateTransactions"
+	TransactionService_ProcessPayment_FullMethodName           = "/transaction.TransactionService/ProcessPayment"
+	TransactionService_Transfer_FullMethodName                 = "/transaction.TransactionService/Transfer"
+	TransactionService_ListOperationRules_FullMethodName       = "/transaction.TransactionService/ListOperationRules"
+	TransactionService_UpdateOperationRule_FullMethodName      = "/transaction.TransactionService/UpdateOperationRule"
+	TransactionService_ImportChargebacks_FullMethodName        = "/transaction.TransactionService/ImportChargebacks"
+	TransactionService_ReconcileSettlement_FullMethodName      = "/transaction.TransactionService/ReconcileSettlement"
+	TransactionService_IngestTransactions_FullMethodName       = "/transaction.TransactionService/IngestTransactions"
+	TransactionService_ExportTransactionHistory_FullMethodName = "/transaction.TransactionService/ExportTransactionHistory"
+	TransactionService_StreamTransactions_FullMethodName       = "/transaction.TransactionService/StreamTransactions"
+	TransactionService_ListStuckTransactions_FullMethodName    = "/transaction.TransactionService/ListStuckTransactions"
+	TransactionService_ResolveStuckTransactions_FullMethodName = "/transaction.TransactionService/ResolveStuckTransactions"
+	TransactionService_ListFlaggedTransactions_FullMethodName  = "/transaction.TransactionService/ListFlaggedTransactions"
+	TransactionService_ApproveFlagged_FullMethodName           = "/transaction.TransactionService/ApproveFlagged"
+	TransactionService_DeclineFlagged_FullMethodName           = "/transaction.TransactionService/DeclineFlagged"
+	TransactionService_SetBudget_FullMethodName                = "/transaction.TransactionService/SetBudget"
+	TransactionService_DeleteBudget_FullMethodName             = "/transaction.TransactionService/DeleteBudget"
+	TransactionService_GetBudgetStatus_FullMethodName          = "/transaction.TransactionService/GetBudgetStatus"
+	TransactionService_ListEventDeliveries_FullMethodName      = "/transaction.TransactionService/ListEventDeliveries"
+	TransactionService_GetSLOStatus_FullMethodName             = "/transaction.TransactionService/GetSLOStatus"
+	TransactionService_ListWebhookSigningKeys_FullMethodName   = "/transaction.TransactionService/ListWebhookSigningKeys"
+	TransactionService_CreateWebhookSigningKey_FullMethodName  = "/transaction.TransactionService/CreateWebhookSigningKey"
+	TransactionService_RetireWebhookSigningKey_FullMethodName  = "/transaction.TransactionService/RetireWebhookSigningKey"
+	TransactionService_CreateBatch_FullMethodName              = "/transaction.TransactionService/CreateBatch"
+	TransactionService_GetBatch_FullMethodName                 = "/transaction.TransactionService/GetBatch"
+	TransactionService_GenerateStatement_FullMethodName        = "/transaction.TransactionService/GenerateStatement"
+	TransactionService_GetStatement_FullMethodName             = "/transaction.TransactionService/GetStatement"
+	TransactionService_GetBusinessDayStatus_FullMethodName     = "/transaction.TransactionService/GetBusinessDayStatus"
+	TransactionService_CreateRecurringRule_FullMethodName      = "/transaction.TransactionService/CreateRecurringRule"
+	TransactionService_ListRecurringRules_FullMethodName       = "/transaction.TransactionService/ListRecurringRules"
+	TransactionService_CancelRecurringRule_FullMethodName      = "/transaction.TransactionService/CancelRecurringRule"
+	TransactionService_ListMessageTemplates_FullMethodName     = "/transaction.TransactionService/ListMessageTemplates"
+	TransactionService_PutMessageTemplate_FullMethodName       = "/transaction.TransactionService/PutMessageTemplate"
+	TransactionService_DeleteMessageTemplate_FullMethodName    = "/transaction.TransactionService/DeleteMessageTemplate"
+	TransactionService_PreviewMessageTemplate_FullMethodName   = "/transaction.TransactionService/PreviewMessageTemplate"
+	TransactionService_TestSendMessageTemplate_FullMethodName  = "/transaction.TransactionService/TestSendMessageTemplate"
 )
 
 // TransactionServiceClient is the client API for TransactionService service.
@@ -33,8 +71,89 @@ const (
 type TransactionServiceClient interface {
 	CreateTransaction(ctx context.Context, in *CreateTransactionRequest, opts ...grpc.CallOption) (*CreateTransactionResponse, error)
 	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
+	// Edits the description, tags and metadata of a transaction; amounts and types never change
+	UpdateTransaction(ctx context.Context, in *UpdateTransactionRequest, opts ...grpc.CallOption) (*UpdateTransactionResponse, error)
+	// Support and admin only; the transaction with its edits and disputes in chronological order
+	GetTransactionTimeline(ctx context.Context, in *GetTransactionTimelineRequest, opts ...grpc.CallOption) (*GetTransactionTimelineResponse, error)
+	// Support and admin only; records a compensating REVERSAL transaction that gives the amount back and marks
+	// the original REVERSED. A transaction can only be reversed once
+	ReverseTransaction(ctx context.Context, in *ReverseTransactionRequest, opts ...grpc.CallOption) (*ReverseTransactionResponse, error)
 	GetTransactionHistory(ctx context.Context, in *GetTransactionHistoryRequest, opts ...grpc.CallOption) (*GetTransactionHistoryResponse, error)
+	AggregateTransactions(ctx context.Context, in *AggregateTransactionsRequest, opts ...grpc.CallOption) (*AggregateTransactionsResponse, error)
 	ProcessPayment(ctx context.Context, in *ProcessPaymentRequest, opts ...grpc.CallOption) (*ProcessPaymentResponse, error)
+	// Moves money between two accounts: debits the source and credits the destination atomically, recording
+	// a TRANSFER_OUT and a TRANSFER_IN transaction that share the transfer_id
+	Transfer(ctx context.Context, in *TransferRequest, opts ...grpc.CallOption) (*TransferResponse, error)
+	ListOperationRules(ctx context.Context, in *ListOperationRulesRequest, opts ...grpc.CallOption) (*ListOperationRulesResponse, error)
+	// Admin only; replaces the rule of an existing operation type
+	UpdateOperationRule(ctx context.Context, in *UpdateOperationRuleRequest, opts ...grpc.CallOption) (*UpdateOperationRuleResponse, error)
+	// Back-office import of a network chargeback file; opens a dispute for every matched transaction
+	ImportChargebacks(ctx context.Context, in *ImportChargebacksRequest, opts ...grpc.CallOption) (*ImportChargebacksResponse, error)
+	// Support or admin; matches a processor settlement file against transactions and reports the differences.
+	// With auto_adjust, an operator ID is required and every amount difference becomes a PENDING balance adjustment
+	ReconcileSettlement(ctx context.Context, in *ReconcileSettlementRequest, opts ...grpc.CallOption) (*ReconcileSettlementResponse, error)
+	// Streaming ingest for high-throughput integrations; one result per request, in order
+	IngestTransactions(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CreateTransactionRequest, IngestTransactionResult], error)
+	// Streams an account's transaction history as a file, oldest first
+	ExportTransactionHistory(ctx context.Context, in *ExportTransactionHistoryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportTransactionHistoryChunk], error)
+	// Streams an account's transactions oldest first, one message per transaction, for consumers pulling the full
+	// history without paging. account_id is required; errors are reported as the gRPC status of the stream, with
+	// INVALID_ARGUMENT for request errors, and a failure part way through leaves the transactions received incomplete
+	StreamTransactions(ctx context.Context, in *StreamTransactionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Transaction], error)
+	// Admin only; transactions still PENDING after a threshold, oldest first
+	ListStuckTransactions(ctx context.Context, in *ListStuckTransactionsRequest, opts ...grpc.CallOption) (*ListStuckTransactionsResponse, error)
+	// Admin only; completes, fails or reverses PENDING transactions in bulk, recording each resolution
+	ResolveStuckTransactions(ctx context.Context, in *ResolveStuckTransactionsRequest, opts ...grpc.CallOption) (*ResolveStuckTransactionsResponse, error)
+	// Support or admin; transactions held for manual review by fraud scoring, oldest first
+	ListFlaggedTransactions(ctx context.Context, in *ListFlaggedTransactionsRequest, opts ...grpc.CallOption) (*ListFlaggedTransactionsResponse, error)
+	// Support or admin with an operator ID; applies a held transaction to the balance and completes it
+	ApproveFlagged(ctx context.Context, in *ApproveFlaggedRequest, opts ...grpc.CallOption) (*ApproveFlaggedResponse, error)
+	// Support or admin with an operator ID; declines a held transaction, leaving the balance unchanged
+	DeclineFlagged(ctx context.Context, in *DeclineFlaggedRequest, opts ...grpc.CallOption) (*DeclineFlaggedResponse, error)
+	// Creates or replaces the monthly spending budget of one category of an account; budgets never decline a transaction
+	SetBudget(ctx context.Context, in *SetBudgetRequest, opts ...grpc.CallOption) (*SetBudgetResponse, error)
+	DeleteBudget(ctx context.Context, in *DeleteBudgetRequest, opts ...grpc.CallOption) (*DeleteBudgetResponse, error)
+	// Spending against each budget of an account within one month
+	GetBudgetStatus(ctx context.Context, in *GetBudgetStatusRequest, opts ...grpc.CallOption) (*GetBudgetStatusResponse, error)
+	// Events written to the outbox for an account, newest first, with their delivery status per subscriber
+	ListEventDeliveries(ctx context.Context, in *ListEventDeliveriesRequest, opts ...grpc.CallOption) (*ListEventDeliveriesResponse, error)
+	// Support or admin; SLO status of the methods with a target in the service's runtime config
+	GetSLOStatus(ctx context.Context, in *GetSLOStatusRequest, opts ...grpc.CallOption) (*GetSLOStatusResponse, error)
+	// Admin only; signing keys of event deliveries to a subscriber, without their secrets
+	ListWebhookSigningKeys(ctx context.Context, in *ListWebhookSigningKeysRequest, opts ...grpc.CallOption) (*ListWebhookSigningKeysResponse, error)
+	// Admin only; adds an active signing key, returned with its secret once
+	CreateWebhookSigningKey(ctx context.Context, in *CreateWebhookSigningKeyRequest, opts ...grpc.CallOption) (*WebhookSigningKeyResponse, error)
+	// Admin only; stops signing deliveries with a key; the last active key cannot be retired
+	RetireWebhookSigningKey(ctx context.Context, in *RetireWebhookSigningKeyRequest, opts ...grpc.CallOption) (*WebhookSigningKeyResponse, error)
+	// Opens a batch that groups the transactions of a bulk import or settlement run
+	CreateBatch(ctx context.Context, in *CreateBatchRequest, opts ...grpc.CallOption) (*CreateBatchResponse, error)
+	// Progress of a batch and the items that failed
+	GetBatch(ctx context.Context, in *GetBatchRequest, opts ...grpc.CallOption) (*GetBatchResponse, error)
+	// Generates the statement of an account for a calendar month that has ended; the statement of a month is
+	// generated once, later calls return it unchanged
+	GenerateStatement(ctx context.Context, in *GenerateStatementRequest, opts ...grpc.CallOption) (*StatementResponse, error)
+	GetStatement(ctx context.Context, in *GetStatementRequest, opts ...grpc.CallOption) (*StatementResponse, error)
+	// Whether a UTC business day has been closed by the end-of-day close, with its totals and, for an account,
+	// the balance recorded at its close
+	GetBusinessDayStatus(ctx context.Context, in *GetBusinessDayStatusRequest, opts ...grpc.CallOption) (*BusinessDayStatusResponse, error)
+	// Creates a rule that makes a transaction on an account on a schedule, e.g. a monthly payment of a fixed amount
+	CreateRecurringRule(ctx context.Context, in *CreateRecurringRuleRequest, opts ...grpc.CallOption) (*RecurringRuleResponse, error)
+	// Recurring rules of an account, newest first
+	ListRecurringRules(ctx context.Context, in *ListRecurringRulesRequest, opts ...grpc.CallOption) (*ListRecurringRulesResponse, error)
+	// Stops a rule from making further transactions; the transactions it made are kept
+	CancelRecurringRule(ctx context.Context, in *CancelRecurringRuleRequest, opts ...grpc.CallOption) (*RecurringRuleResponse, error)
+	// Admin only; the message templates in effect for a tenant: its own, else the platform's
+	ListMessageTemplates(ctx context.Context, in *ListMessageTemplatesRequest, opts ...grpc.CallOption) (*ListMessageTemplatesResponse, error)
+	// Admin only; sets the tenant's template of the messages of an event type on a channel, checked against a
+	// sample event first
+	PutMessageTemplate(ctx context.Context, in *PutMessageTemplateRequest, opts ...grpc.CallOption) (*MessageTemplateResponse, error)
+	// Admin only; removes the tenant's template, so the platform's applies again
+	DeleteMessageTemplate(ctx context.Context, in *DeleteMessageTemplateRequest, opts ...grpc.CallOption) (*MessageTemplateResponse, error)
+	// Admin only; renders a template, the one in effect or a draft, for a sample event or the payload given
+	PreviewMessageTemplate(ctx context.Context, in *PreviewMessageTemplateRequest, opts ...grpc.CallOption) (*PreviewMessageTemplateResponse, error)
+	// Admin only; renders a template like PreviewMessageTemplate and publishes a message.test_send event for
+	// notification consumers to deliver it to a test recipient
+	TestSendMessageTemplate(ctx context.Context, in *TestSendMessageTemplateRequest, opts ...grpc.CallOption) (*TestSendMessageTemplateResponse, error)
 }
 
 type transactionServiceClient struct {
@@ -65,6 +184,36 @@ func (c *transactionServiceClient) GetTransaction(ctx context.Context, in *GetTr
 	return out, nil
 }
 
+func (c *transactionServiceClient) UpdateTransaction(ctx context.Context, in *UpdateTransactionRequest, opts ...grpc.CallOption) (*UpdateTransactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateTransactionResponse)
+	err := c.cc.Invoke(ctx, TransactionService_UpdateTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetTransactionTimeline(ctx context.Context, in *GetTransactionTimelineRequest, opts ...grpc.CallOption) (*GetTransactionTimelineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTransactionTimelineResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetTransactionTimeline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ReverseTransaction(ctx context.Context, in *ReverseTransactionRequest, opts ...grpc.CallOption) (*ReverseTransactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReverseTransactionResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ReverseTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *transactionServiceClient) GetTransactionHistory(ctx context.Context, in *GetTransactionHistoryRequest, opts ...grpc.CallOption) (*GetTransactionHistoryResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetTransactionHistoryResponse)
@@ -72,140 +221,1365 @@ func (c *transactionServiceClient) GetTransactionHistory(ctx context.Context, in
 	if err != nil {
 		return nil, err
 	}
-	return out, nil
+	return out, nil
+}
+
+func (c *transactionServiceClient) AggregateTransactions(ctx context.Context, in *AggregateTransactionsRequest, opts ...grpc.CallOption) (*AggregateTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AggregateTransactionsResponse)
+	err := c.cc.Invoke(ctx, TransactionService_AggregateTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ProcessPayment(ctx context.Context, in *ProcessPaymentRequest, opts ...grpc.CallOption) (*ProcessPaymentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProcessPaymentResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ProcessPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) Transfer(ctx context.Context, in *TransferRequest, opts ...grpc.CallOption) (*TransferResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransferResponse)
+	err := c.cc.Invoke(ctx, TransactionService_Transfer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListOperationRules(ctx context.Context, in *ListOperationRulesRequest, opts ...grpc.CallOption) (*ListOperationRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOperationRulesResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListOperationRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) UpdateOperationRule(ctx context.Context, in *UpdateOperationRuleRequest, opts ...grpc.CallOption) (*UpdateOperationRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateOperationRuleResponse)
+	err := c.cc.Invoke(ctx, TransactionService_UpdateOperationRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ImportChargebacks(ctx context.Context, in *ImportChargebacksRequest, opts ...grpc.CallOption) (*ImportChargebacksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportChargebacksResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ImportChargebacks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ReconcileSettlement(ctx context.Context, in *ReconcileSettlementRequest, opts ...grpc.CallOption) (*ReconcileSettlementResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReconcileSettlementResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ReconcileSettlement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) IngestTransactions(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CreateTransactionRequest, IngestTransactionResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TransactionService_ServiceDesc.Streams[0], TransactionService_IngestTransactions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CreateTransactionRequest, IngestTransactionResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionService_IngestTransactionsClient = grpc.BidiStreamingClient[CreateTransactionRequest, IngestTransactionResult]
+
+func (c *transactionServiceClient) ExportTransactionHistory(ctx context.Context, in *ExportTransactionHistoryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportTransactionHistoryChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TransactionService_ServiceDesc.Streams[1], TransactionService_ExportTransactionHistory_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportTransactionHistoryRequest, ExportTransactionHistoryChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionService_ExportTransactionHistoryClient = grpc.ServerStreamingClient[ExportTransactionHistoryChunk]
+
+func (c *transactionServiceClient) StreamTransactions(ctx context.Context, in *StreamTransactionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Transaction], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TransactionService_ServiceDesc.Streams[2], TransactionService_StreamTransactions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamTransactionsRequest, Transaction]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionService_StreamTransactionsClient = grpc.ServerStreamingClient[Transaction]
+
+func (c *transactionServiceClient) ListStuckTransactions(ctx context.Context, in *ListStuckTransactionsRequest, opts ...grpc.CallOption) (*ListStuckTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStuckTransactionsResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListStuckTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ResolveStuckTransactions(ctx context.Context, in *ResolveStuckTransactionsRequest, opts ...grpc.CallOption) (*ResolveStuckTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveStuckTransactionsResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ResolveStuckTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListFlaggedTransactions(ctx context.Context, in *ListFlaggedTransactionsRequest, opts ...grpc.CallOption) (*ListFlaggedTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFlaggedTransactionsResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListFlaggedTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ApproveFlagged(ctx context.Context, in *ApproveFlaggedRequest, opts ...grpc.CallOption) (*ApproveFlaggedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApproveFlaggedResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ApproveFlagged_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) DeclineFlagged(ctx context.Context, in *DeclineFlaggedRequest, opts ...grpc.CallOption) (*DeclineFlaggedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeclineFlaggedResponse)
+	err := c.cc.Invoke(ctx, TransactionService_DeclineFlagged_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) SetBudget(ctx context.Context, in *SetBudgetRequest, opts ...grpc.CallOption) (*SetBudgetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetBudgetResponse)
+	err := c.cc.Invoke(ctx, TransactionService_SetBudget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) DeleteBudget(ctx context.Context, in *DeleteBudgetRequest, opts ...grpc.CallOption) (*DeleteBudgetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteBudgetResponse)
+	err := c.cc.Invoke(ctx, TransactionService_DeleteBudget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetBudgetStatus(ctx context.Context, in *GetBudgetStatusRequest, opts ...grpc.CallOption) (*GetBudgetStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBudgetStatusResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetBudgetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListEventDeliveries(ctx context.Context, in *ListEventDeliveriesRequest, opts ...grpc.CallOption) (*ListEventDeliveriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListEventDeliveriesResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListEventDeliveries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetSLOStatus(ctx context.Context, in *GetSLOStatusRequest, opts ...grpc.CallOption) (*GetSLOStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSLOStatusResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetSLOStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListWebhookSigningKeys(ctx context.Context, in *ListWebhookSigningKeysRequest, opts ...grpc.CallOption) (*ListWebhookSigningKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWebhookSigningKeysResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListWebhookSigningKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) CreateWebhookSigningKey(ctx context.Context, in *CreateWebhookSigningKeyRequest, opts ...grpc.CallOption) (*WebhookSigningKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebhookSigningKeyResponse)
+	err := c.cc.Invoke(ctx, TransactionService_CreateWebhookSigningKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) RetireWebhookSigningKey(ctx context.Context, in *RetireWebhookSigningKeyRequest, opts ...grpc.CallOption) (*WebhookSigningKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebhookSigningKeyResponse)
+	err := c.cc.Invoke(ctx, TransactionService_RetireWebhookSigningKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) CreateBatch(ctx context.Context, in *CreateBatchRequest, opts ...grpc.CallOption) (*CreateBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateBatchResponse)
+	err := c.cc.Invoke(ctx, TransactionService_CreateBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetBatch(ctx context.Context, in *GetBatchRequest, opts ...grpc.CallOption) (*GetBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBatchResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GenerateStatement(ctx context.Context, in *GenerateStatementRequest, opts ...grpc.CallOption) (*StatementResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatementResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GenerateStatement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetStatement(ctx context.Context, in *GetStatementRequest, opts ...grpc.CallOption) (*StatementResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatementResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetStatement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) GetBusinessDayStatus(ctx context.Context, in *GetBusinessDayStatusRequest, opts ...grpc.CallOption) (*BusinessDayStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BusinessDayStatusResponse)
+	err := c.cc.Invoke(ctx, TransactionService_GetBusinessDayStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) CreateRecurringRule(ctx context.Context, in *CreateRecurringRuleRequest, opts ...grpc.CallOption) (*RecurringRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecurringRuleResponse)
+	err := c.cc.Invoke(ctx, TransactionService_CreateRecurringRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListRecurringRules(ctx context.Context, in *ListRecurringRulesRequest, opts ...grpc.CallOption) (*ListRecurringRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRecurringRulesResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListRecurringRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) CancelRecurringRule(ctx context.Context, in *CancelRecurringRuleRequest, opts ...grpc.CallOption) (*RecurringRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecurringRuleResponse)
+	err := c.cc.Invoke(ctx, TransactionService_CancelRecurringRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListMessageTemplates(ctx context.Context, in *ListMessageTemplatesRequest, opts ...grpc.CallOption) (*ListMessageTemplatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMessageTemplatesResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListMessageTemplates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) PutMessageTemplate(ctx context.Context, in *PutMessageTemplateRequest, opts ...grpc.CallOption) (*MessageTemplateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MessageTemplateResponse)
+	err := c.cc.Invoke(ctx, TransactionService_PutMessageTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) DeleteMessageTemplate(ctx context.Context, in *DeleteMessageTemplateRequest, opts ...grpc.CallOption) (*MessageTemplateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MessageTemplateResponse)
+	err := c.cc.Invoke(ctx, TransactionService_DeleteMessageTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) PreviewMessageTemplate(ctx context.Context, in *PreviewMessageTemplateRequest, opts ...grpc.CallOption) (*PreviewMessageTemplateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PreviewMessageTemplateResponse)
+	err := c.cc.Invoke(ctx, TransactionService_PreviewMessageTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) TestSendMessageTemplate(ctx context.Context, in *TestSendMessageTemplateRequest, opts ...grpc.CallOption) (*TestSendMessageTemplateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TestSendMessageTemplateResponse)
+	err := c.cc.Invoke(ctx, TransactionService_TestSendMessageTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TransactionServiceServer is the server API for TransactionService service.
+// All implementations must embed UnimplementedTransactionServiceServer
+// for forward compatibility.
+//
+// Transaction service definition
+type TransactionServiceServer interface {
+	CreateTransaction(context.Context, *CreateTransactionRequest) (*CreateTransactionResponse, error)
+	GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error)
+	// Edits the description, tags and metadata of a transaction; amounts and types never change
+	UpdateTransaction(context.Context, *UpdateTransactionRequest) (*UpdateTransactionResponse, error)
+	// Support and admin only; the transaction with its edits and disputes in chronological order
+	GetTransactionTimeline(context.Context, *GetTransactionTimelineRequest) (*GetTransactionTimelineResponse, error)
+	// Support and admin only; records a compensating REVERSAL transaction that gives the amount back and marks
+	// the original REVERSED. A transaction can only be reversed once
+	ReverseTransaction(context.Context, *ReverseTransactionRequest) (*ReverseTransactionResponse, error)
+	GetTransactionHistory(context.Context, *GetTransactionHistoryRequest) (*GetTransactionHistoryResponse, error)
+	AggregateTransactions(context.Context, *AggregateTransactionsRequest) (*AggregateTransactionsResponse, error)
+	ProcessPayment(context.Context, *ProcessPaymentRequest) (*ProcessPaymentResponse, error)
+	// Moves money between two accounts: debits the source and credits the destination atomically, recording
+	// a TRANSFER_OUT and a TRANSFER_IN transaction that share the transfer_id
+	Transfer(context.Context, *TransferRequest) (*TransferResponse, error)
+	ListOperationRules(context.Context, *ListOperationRulesRequest) (*ListOperationRulesResponse, error)
+	// Admin only; replaces the rule of an existing operation type
+	UpdateOperationRule(context.Context, *UpdateOperationRuleRequest) (*UpdateOperationRuleResponse, error)
+	// Back-office import of a network chargeback file; opens a dispute for every matched transaction
+	ImportChargebacks(context.Context, *ImportChargebacksRequest) (*ImportChargebacksResponse, error)
+	// Support or admin; matches a processor settlement file against transactions and reports the differences.
+	// With auto_adjust, an operator ID is required and every amount difference becomes a PENDING balance adjustment
+	ReconcileSettlement(context.Context, *ReconcileSettlementRequest) (*ReconcileSettlementResponse, error)
+	// Streaming ingest for high-throughput integrations; one result per request, in order
+	IngestTransactions(grpc.BidiStreamingServer[CreateTransactionRequest, IngestTransactionResult]) error
+	// Streams an account's transaction history as a file, oldest first
+	ExportTransactionHistory(*ExportTransactionHistoryRequest, grpc.ServerStreamingServer[ExportTransactionHistoryChunk]) error
+	// Streams an account's transactions oldest first, one message per transaction, for consumers pulling the full
+	// history without paging. account_id is required; errors are reported as the gRPC status of the stream, with
+	// INVALID_ARGUMENT for request errors, and a failure part way through leaves the transactions received incomplete
+	StreamTransactions(*StreamTransactionsRequest, grpc.ServerStreamingServer[Transaction]) error
+	// Admin only; transactions still PENDING after a threshold, oldest first
+	ListStuckTransactions(context.Context, *ListStuckTransactionsRequest) (*ListStuckTransactionsResponse, error)
+	// Admin only; completes, fails or reverses PENDING transactions in bulk, recording each resolution
+	ResolveStuckTransactions(context.Context, *ResolveStuckTransactionsRequest) (*ResolveStuckTransactionsResponse, error)
+	// Support or admin; transactions held for manual review by fraud scoring, oldest first
+	ListFlaggedTransactions(context.Context, *ListFlaggedTransactionsRequest) (*ListFlaggedTransactionsResponse, error)
+	// Support or admin with an operator ID; applies a held transaction to the balance and completes it
+	ApproveFlagged(context.Context, *ApproveFlaggedRequest) (*ApproveFlaggedResponse, error)
+	// Support or admin with an operator ID; declines a held transaction, leaving the balance unchanged
+	DeclineFlagged(context.Context, *DeclineFlaggedRequest) (*DeclineFlaggedResponse, error)
+	// Creates or replaces the monthly spending budget of one category of an account; budgets never decline a transaction
+	SetBudget(context.Context, *SetBudgetRequest) (*SetBudgetResponse, error)
+	DeleteBudget(context.Context, *DeleteBudgetRequest) (*DeleteBudgetResponse, error)
+	// Spending against each budget of an account within one month
+	GetBudgetStatus(context.Context, *GetBudgetStatusRequest) (*GetBudgetStatusResponse, error)
+	// Events written to the outbox for an account, newest first, with their delivery status per subscriber
+	ListEventDeliveries(context.Context, *ListEventDeliveriesRequest) (*ListEventDeliveriesResponse, error)
+	// Support or admin; SLO status of the methods with a target in the service's runtime config
+	GetSLOStatus(context.Context, *GetSLOStatusRequest) (*GetSLOStatusResponse, error)
+	// Admin only; signing keys of event deliveries to a subscriber, without their secrets
+	ListWebhookSigningKeys(context.Context, *ListWebhookSigningKeysRequest) (*ListWebhookSigningKeysResponse, error)
+	// Admin only; adds an active signing key, returned with its secret once
+	CreateWebhookSigningKey(context.Context, *CreateWebhookSigningKeyRequest) (*WebhookSigningKeyResponse, error)
+	// Admin only; stops signing deliveries with a key; the last active key cannot be retired
+	RetireWebhookSigningKey(context.Context, *RetireWebhookSigningKeyRequest) (*WebhookSigningKeyResponse, error)
+	// Opens a batch that groups the transactions of a bulk import or settlement run
+	CreateBatch(context.Context, *CreateBatchRequest) (*CreateBatchResponse, error)
+	// Progress of a batch and the items that failed
+	GetBatch(context.Context, *GetBatchRequest) (*GetBatchResponse, error)
+	// Generates the statement of an account for a calendar month that has ended; the statement of a month is
+	// generated once, later calls return it unchanged
+	GenerateStatement(context.Context, *GenerateStatementRequest) (*StatementResponse, error)
+	GetStatement(context.Context, *GetStatementRequest) (*StatementResponse, error)
+	// Whether a UTC business day has been closed by the end-of-day close, with its totals and, for an account,
+	// the balance recorded at its close
+	GetBusinessDayStatus(context.Context, *GetBusinessDayStatusRequest) (*BusinessDayStatusResponse, error)
+	// Creates a rule that makes a transaction on an account on a schedule, e.g. a monthly payment of a fixed amount
+	CreateRecurringRule(context.Context, *CreateRecurringRuleRequest) (*RecurringRuleResponse, error)
+	// Recurring rules of an account, newest first
+	ListRecurringRules(context.Context, *ListRecurringRulesRequest) (*ListRecurringRulesResponse, error)
+	// Stops a rule from making further transactions; the transactions it made are kept
+	CancelRecurringRule(context.Context, *CancelRecurringRuleRequest) (*RecurringRuleResponse, error)
+	// Admin only; the message templates in effect for a tenant: its own, else the platform's
+	ListMessageTemplates(context.Context, *ListMessageTemplatesRequest) (*ListMessageTemplatesResponse, error)
+	// Admin only; sets the tenant's template of the messages of an event type on a channel, checked against a
+	// sample event first
+	PutMessageTemplate(context.Context, *PutMessageTemplateRequest) (*MessageTemplateResponse, error)
+	// Admin only; removes the tenant's template, so the platform's applies again
+	DeleteMessageTemplate(context.Context, *DeleteMessageTemplateRequest) (*MessageTemplateResponse, error)
+	// Admin only; renders a template, the one in effect or a draft, for a sample event or the payload given
+	PreviewMessageTemplate(context.Context, *PreviewMessageTemplateRequest) (*PreviewMessageTemplateResponse, error)
+	// Admin only; renders a template like PreviewMessageTemplate and publishes a message.test_send event for
+	// notification consumers to deliver it to a test recipient
+	TestSendMessageTemplate(context.Context, *TestSendMessageTemplateRequest) (*TestSendMessageTemplateResponse, error)
+	mustEmbedUnimplementedTransactionServiceServer()
+}
+
+// UnimplementedTransactionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTransactionServiceServer struct{}
+
+func (UnimplementedTransactionServiceServer) CreateTransaction(context.Context, *CreateTransactionRequest) (*CreateTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTransaction not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedTransactionServiceServer) UpdateTransaction(context.Context, *UpdateTransactionRequest) (*UpdateTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTransaction not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetTransactionTimeline(context.Context, *GetTransactionTimelineRequest) (*GetTransactionTimelineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionTimeline not implemented")
+}
+func (UnimplementedTransactionServiceServer) ReverseTransaction(context.Context, *ReverseTransactionRequest) (*ReverseTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReverseTransaction not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetTransactionHistory(context.Context, *GetTransactionHistoryRequest) (*GetTransactionHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionHistory not implemented")
+}
+func (UnimplementedTransactionServiceServer) AggregateTransactions(context.Context, *AggregateTransactionsRequest) (*AggregateTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AggregateTransactions not implemented")
+}
+func (UnimplementedTransactionServiceServer) ProcessPayment(context.Context, *ProcessPaymentRequest) (*ProcessPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessPayment not implemented")
+}
+func (UnimplementedTransactionServiceServer) Transfer(context.Context, *TransferRequest) (*TransferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transfer not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListOperationRules(context.Context, *ListOperationRulesRequest) (*ListOperationRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOperationRules not implemented")
+}
+func (UnimplementedTransactionServiceServer) UpdateOperationRule(context.Context, *UpdateOperationRuleRequest) (*UpdateOperationRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateOperationRule not implemented")
+}
+func (UnimplementedTransactionServiceServer) ImportChargebacks(context.Context, *ImportChargebacksRequest) (*ImportChargebacksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportChargebacks not implemented")
+}
+func (UnimplementedTransactionServiceServer) ReconcileSettlement(context.Context, *ReconcileSettlementRequest) (*ReconcileSettlementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconcileSettlement not implemented")
+}
+func (UnimplementedTransactionServiceServer) IngestTransactions(grpc.BidiStreamingServer[CreateTransactionRequest, IngestTransactionResult]) error {
+	return status.Errorf(codes.Unimplemented, "method IngestTransactions not implemented")
+}
+func (UnimplementedTransactionServiceServer) ExportTransactionHistory(*ExportTransactionHistoryRequest, grpc.ServerStreamingServer[ExportTransactionHistoryChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportTransactionHistory not implemented")
+}
+func (UnimplementedTransactionServiceServer) StreamTransactions(*StreamTransactionsRequest, grpc.ServerStreamingServer[Transaction]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTransactions not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListStuckTransactions(context.Context, *ListStuckTransactionsRequest) (*ListStuckTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStuckTransactions not implemented")
+}
+func (UnimplementedTransactionServiceServer) ResolveStuckTransactions(context.Context, *ResolveStuckTransactionsRequest) (*ResolveStuckTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveStuckTransactions not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListFlaggedTransactions(context.Context, *ListFlaggedTransactionsRequest) (*ListFlaggedTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFlaggedTransactions not implemented")
+}
+func (UnimplementedTransactionServiceServer) ApproveFlagged(context.Context, *ApproveFlaggedRequest) (*ApproveFlaggedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveFlagged not implemented")
+}
+func (UnimplementedTransactionServiceServer) DeclineFlagged(context.Context, *DeclineFlaggedRequest) (*DeclineFlaggedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeclineFlagged not implemented")
+}
+func (UnimplementedTransactionServiceServer) SetBudget(context.Context, *SetBudgetRequest) (*SetBudgetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBudget not implemented")
+}
+func (UnimplementedTransactionServiceServer) DeleteBudget(context.Context, *DeleteBudgetRequest) (*DeleteBudgetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBudget not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetBudgetStatus(context.Context, *GetBudgetStatusRequest) (*GetBudgetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBudgetStatus not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListEventDeliveries(context.Context, *ListEventDeliveriesRequest) (*ListEventDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEventDeliveries not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetSLOStatus(context.Context, *GetSLOStatusRequest) (*GetSLOStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSLOStatus not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListWebhookSigningKeys(context.Context, *ListWebhookSigningKeysRequest) (*ListWebhookSigningKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookSigningKeys not implemented")
+}
+func (UnimplementedTransactionServiceServer) CreateWebhookSigningKey(context.Context, *CreateWebhookSigningKeyRequest) (*WebhookSigningKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhookSigningKey not implemented")
+}
+func (UnimplementedTransactionServiceServer) RetireWebhookSigningKey(context.Context, *RetireWebhookSigningKeyRequest) (*WebhookSigningKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetireWebhookSigningKey not implemented")
+}
+func (UnimplementedTransactionServiceServer) CreateBatch(context.Context, *CreateBatchRequest) (*CreateBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBatch not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetBatch(context.Context, *GetBatchRequest) (*GetBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBatch not implemented")
+}
+func (UnimplementedTransactionServiceServer) GenerateStatement(context.Context, *GenerateStatementRequest) (*StatementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateStatement not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetStatement(context.Context, *GetStatementRequest) (*StatementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatement not implemented")
+}
+func (UnimplementedTransactionServiceServer) GetBusinessDayStatus(context.Context, *GetBusinessDayStatusRequest) (*BusinessDayStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBusinessDayStatus not implemented")
+}
+func (UnimplementedTransactionServiceServer) CreateRecurringRule(context.Context, *CreateRecurringRuleRequest) (*RecurringRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRecurringRule not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListRecurringRules(context.Context, *ListRecurringRulesRequest) (*ListRecurringRulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRecurringRules not implemented")
+}
+func (UnimplementedTransactionServiceServer) CancelRecurringRule(context.Context, *CancelRecurringRuleRequest) (*RecurringRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelRecurringRule not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListMessageTemplates(context.Context, *ListMessageTemplatesRequest) (*ListMessageTemplatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMessageTemplates not implemented")
+}
+func (UnimplementedTransactionServiceServer) PutMessageTemplate(context.Context, *PutMessageTemplateRequest) (*MessageTemplateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutMessageTemplate not implemented")
+}
+func (UnimplementedTransactionServiceServer) DeleteMessageTemplate(context.Context, *DeleteMessageTemplateRequest) (*MessageTemplateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMessageTemplate not implemented")
+}
+func (UnimplementedTransactionServiceServer) PreviewMessageTemplate(context.Context, *PreviewMessageTemplateRequest) (*PreviewMessageTemplateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreviewMessageTemplate not implemented")
+}
+func (UnimplementedTransactionServiceServer) TestSendMessageTemplate(context.Context, *TestSendMessageTemplateRequest) (*TestSendMessageTemplateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TestSendMessageTemplate not implemented")
+}
+func (UnimplementedTransactionServiceServer) mustEmbedUnimplementedTransactionServiceServer() {}
+func (UnimplementedTransactionServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeTransactionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TransactionServiceServer will
+// result in compilation errors.
+type UnsafeTransactionServiceServer interface {
+	mustEmbedUnimplementedTransactionServiceServer()
+}
+
+func RegisterTransactionServiceServer(s grpc.ServiceRegistrar, srv TransactionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTransactionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TransactionService_ServiceDesc, srv)
+}
+
+func _TransactionService_CreateTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).CreateTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_CreateTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).CreateTransaction(ctx, req.(*CreateTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_UpdateTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).UpdateTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_UpdateTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).UpdateTransaction(ctx, req.(*UpdateTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetTransactionTimeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionTimelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetTransactionTimeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetTransactionTimeline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetTransactionTimeline(ctx, req.(*GetTransactionTimelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ReverseTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReverseTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ReverseTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ReverseTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ReverseTransaction(ctx, req.(*ReverseTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetTransactionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetTransactionHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetTransactionHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetTransactionHistory(ctx, req.(*GetTransactionHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_AggregateTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AggregateTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).AggregateTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_AggregateTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).AggregateTransactions(ctx, req.(*AggregateTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ProcessPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ProcessPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ProcessPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ProcessPayment(ctx, req.(*ProcessPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_Transfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).Transfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_Transfer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).Transfer(ctx, req.(*TransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ListOperationRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOperationRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListOperationRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListOperationRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListOperationRules(ctx, req.(*ListOperationRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_UpdateOperationRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateOperationRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).UpdateOperationRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_UpdateOperationRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).UpdateOperationRule(ctx, req.(*UpdateOperationRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ImportChargebacks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportChargebacksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ImportChargebacks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ImportChargebacks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ImportChargebacks(ctx, req.(*ImportChargebacksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ReconcileSettlement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconcileSettlementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ReconcileSettlement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ReconcileSettlement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ReconcileSettlement(ctx, req.(*ReconcileSettlementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_IngestTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TransactionServiceServer).IngestTransactions(&grpc.GenericServerStream[CreateTransactionRequest, IngestTransactionResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionService_IngestTransactionsServer = grpc.BidiStreamingServer[CreateTransactionRequest, IngestTransactionResult]
+
+func _TransactionService_ExportTransactionHistory_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportTransactionHistoryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransactionServiceServer).ExportTransactionHistory(m, &grpc.GenericServerStream[ExportTransactionHistoryRequest, ExportTransactionHistoryChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionService_ExportTransactionHistoryServer = grpc.ServerStreamingServer[ExportTransactionHistoryChunk]
+
+func _TransactionService_StreamTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransactionServiceServer).StreamTransactions(m, &grpc.GenericServerStream[StreamTransactionsRequest, Transaction]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionService_StreamTransactionsServer = grpc.ServerStreamingServer[Transaction]
+
+func _TransactionService_ListStuckTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStuckTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListStuckTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListStuckTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListStuckTransactions(ctx, req.(*ListStuckTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ResolveStuckTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveStuckTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ResolveStuckTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ResolveStuckTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ResolveStuckTransactions(ctx, req.(*ResolveStuckTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ListFlaggedTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFlaggedTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListFlaggedTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListFlaggedTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListFlaggedTransactions(ctx, req.(*ListFlaggedTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ApproveFlagged_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveFlaggedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ApproveFlagged(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ApproveFlagged_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ApproveFlagged(ctx, req.(*ApproveFlaggedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_DeclineFlagged_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeclineFlaggedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).DeclineFlagged(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_DeclineFlagged_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).DeclineFlagged(ctx, req.(*DeclineFlaggedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_SetBudget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBudgetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).SetBudget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_SetBudget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).SetBudget(ctx, req.(*SetBudgetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_DeleteBudget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBudgetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).DeleteBudget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_DeleteBudget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).DeleteBudget(ctx, req.(*DeleteBudgetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetBudgetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBudgetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetBudgetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetBudgetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetBudgetStatus(ctx, req.(*GetBudgetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ListEventDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEventDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListEventDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListEventDeliveries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListEventDeliveries(ctx, req.(*ListEventDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetSLOStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSLOStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetSLOStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetSLOStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetSLOStatus(ctx, req.(*GetSLOStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ListWebhookSigningKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhookSigningKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListWebhookSigningKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListWebhookSigningKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListWebhookSigningKeys(ctx, req.(*ListWebhookSigningKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_CreateWebhookSigningKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookSigningKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).CreateWebhookSigningKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_CreateWebhookSigningKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).CreateWebhookSigningKey(ctx, req.(*CreateWebhookSigningKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_RetireWebhookSigningKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetireWebhookSigningKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).RetireWebhookSigningKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_RetireWebhookSigningKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).RetireWebhookSigningKey(ctx, req.(*RetireWebhookSigningKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_CreateBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).CreateBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_CreateBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).CreateBatch(ctx, req.(*CreateBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetBatch(ctx, req.(*GetBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GenerateStatement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateStatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GenerateStatement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GenerateStatement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GenerateStatement(ctx, req.(*GenerateStatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetStatement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetStatement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetStatement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetStatement(ctx, req.(*GetStatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_GetBusinessDayStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBusinessDayStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).GetBusinessDayStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_GetBusinessDayStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).GetBusinessDayStatus(ctx, req.(*GetBusinessDayStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *transactionServiceClient) ProcessPayment(ctx context.Context, in *ProcessPaymentRequest, opts ...grpc.CallOption) (*ProcessPaymentResponse, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(ProcessPaymentResponse)
-	err := c.cc.Invoke(ctx, TransactionService_ProcessPayment_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _TransactionService_CreateRecurringRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRecurringRuleRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
-}
-
-// TransactionServiceServer is the server API for TransactionService service.
-// All implementations must embed UnimplementedTransactionServiceServer
-// for forward compatibility.
-//
-// Transaction service definition
-type TransactionServiceServer interface {
-	CreateTransaction(context.Context, *CreateTransactionRequest) (*CreateTransactionResponse, error)
-	GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error)
-	GetTransactionHistory(context.Context, *GetTransactionHistoryRequest) (*GetTransactionHistoryResponse, error)
-	ProcessPayment(context.Context, *ProcessPaymentRequest) (*ProcessPaymentResponse, error)
-	mustEmbedUnimplementedTransactionServiceServer()
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).CreateRecurringRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_CreateRecurringRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).CreateRecurringRule(ctx, req.(*CreateRecurringRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// UnimplementedTransactionServiceServer must be embedded to have
-// forward compatible implementations.
-//
-// NOTE: this should be embedded by value instead of pointer to avoid a nil
-// pointer dereference when methods are called.
-type UnimplementedTransactionServiceServer struct{}
-
-func (UnimplementedTransactionServiceServer) CreateTransaction(context.Context, *CreateTransactionRequest) (*CreateTransactionResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateTransaction not implemented")
-}
-func (UnimplementedTransactionServiceServer) GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
-}
-func (UnimplementedTransactionServiceServer) GetTransactionHistory(context.Context, *GetTransactionHistoryRequest) (*GetTransactionHistoryResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionHistory not implemented")
-}
-func (UnimplementedTransactionServiceServer) ProcessPayment(context.Context, *ProcessPaymentRequest) (*ProcessPaymentResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ProcessPayment not implemented")
+func _TransactionService_ListRecurringRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecurringRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListRecurringRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListRecurringRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListRecurringRules(ctx, req.(*ListRecurringRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedTransactionServiceServer) mustEmbedUnimplementedTransactionServiceServer() {}
-func (UnimplementedTransactionServiceServer) testEmbeddedByValue()                            {}
 
-// UnsafeTransactionServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to TransactionServiceServer will
-// result in compilation errors.
-type UnsafeTransactionServiceServer interface {
-	mustEmbedUnimplementedTransactionServiceServer()
+func _TransactionService_CancelRecurringRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRecurringRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).CancelRecurringRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_CancelRecurringRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).CancelRecurringRule(ctx, req.(*CancelRecurringRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterTransactionServiceServer(s grpc.ServiceRegistrar, srv TransactionServiceServer) {
-	// If the following call pancis, it indicates UnimplementedTransactionServiceServer was
-	// embedded by pointer and is nil.  This will cause panics if an
-	// unimplemented method is ever invoked, so we test this at initialization
-	// time to prevent it from happening at runtime later due to I/O.
-	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
-		t.testEmbeddedByValue()
+func _TransactionService_ListMessageTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMessageTemplatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	s.RegisterService(&TransactionService_ServiceDesc, srv)
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListMessageTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListMessageTemplates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListMessageTemplates(ctx, req.(*ListMessageTemplatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _TransactionService_CreateTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateTransactionRequest)
+func _TransactionService_PutMessageTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutMessageTemplateRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TransactionServiceServer).CreateTransaction(ctx, in)
+		return srv.(TransactionServiceServer).PutMessageTemplate(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TransactionService_CreateTransaction_FullMethodName,
+		FullMethod: TransactionService_PutMessageTemplate_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TransactionServiceServer).CreateTransaction(ctx, req.(*CreateTransactionRequest))
+		return srv.(TransactionServiceServer).PutMessageTemplate(ctx, req.(*PutMessageTemplateRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TransactionService_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetTransactionRequest)
+func _TransactionService_DeleteMessageTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMessageTemplateRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TransactionServiceServer).GetTransaction(ctx, in)
+		return srv.(TransactionServiceServer).DeleteMessageTemplate(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TransactionService_GetTransaction_FullMethodName,
+		FullMethod: TransactionService_DeleteMessageTemplate_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TransactionServiceServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+		return srv.(TransactionServiceServer).DeleteMessageTemplate(ctx, req.(*DeleteMessageTemplateRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TransactionService_GetTransactionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetTransactionHistoryRequest)
+func _TransactionService_PreviewMessageTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreviewMessageTemplateRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TransactionServiceServer).GetTransactionHistory(ctx, in)
+		return srv.(TransactionServiceServer).PreviewMessageTemplate(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TransactionService_GetTransactionHistory_FullMethodName,
+		FullMethod: TransactionService_PreviewMessageTemplate_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TransactionServiceServer).GetTransactionHistory(ctx, req.(*GetTransactionHistoryRequest))
+		return srv.(TransactionServiceServer).PreviewMessageTemplate(ctx, req.(*PreviewMessageTemplateRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _TransactionService_ProcessPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ProcessPaymentRequest)
+func _TransactionService_TestSendMessageTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TestSendMessageTemplateRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(TransactionServiceServer).ProcessPayment(ctx, in)
+		return srv.(TransactionServiceServer).TestSendMessageTemplate(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: TransactionService_ProcessPayment_FullMethodName,
+		FullMethod: TransactionService_TestSendMessageTemplate_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TransactionServiceServer).ProcessPayment(ctx, req.(*ProcessPaymentRequest))
+		return srv.(TransactionServiceServer).TestSendMessageTemplate(ctx, req.(*TestSendMessageTemplateRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -225,15 +1599,288 @@ var TransactionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTransaction",
 			Handler:    _TransactionService_GetTransaction_Handler,
 		},
+		{
+			MethodName: "UpdateTransaction",
+			Handler:    _TransactionService_UpdateTransaction_Handler,
+		},
+		{
+			MethodName: "GetTransactionTimeline",
+			Handler:    _TransactionService_GetTransactionTimeline_Handler,
+		},
+		{
+			MethodName: "ReverseTransaction",
+			Handler:    _TransactionService_ReverseTransaction_Handler,
+		},
 		{
 			MethodName: "GetTransactionHistory",
 			Handler:    _TransactionService_GetTransactionHistory_Handler,
 		},
+		{
+			MethodName: "AggregateTransactions",
+			Handler:    _TransactionService_AggregateTransactions_Handler,
+		},
 		{
 			MethodName: "ProcessPayment",
 			Handler:    _TransactionService_ProcessPayment_Handler,
 		},
+		{
+			MethodName: "Transfer",
+			Handler:    _TransactionService_Transfer_Handler,
+		},
+		{
+			MethodName: "ListOperationRules",
+			Handler:    _TransactionService_ListOperationRules_Handler,
+		},
+		{
+			MethodName: "UpdateOperationRule",
+			Handler:    _TransactionService_UpdateOperationRule_Handler,
+		},
+		{
+			MethodName: "ImportChargebacks",
+			Handler:    _TransactionService_ImportChargebacks_Handler,
+		},
+		{
+			MethodName: "ReconcileSettlement",
+			Handler:    _TransactionService_ReconcileSettlement_Handler,
+		},
+		{
+			MethodName: "ListStuckTransactions",
+			Handler:    _TransactionService_ListStuckTransactions_Handler,
+		},
+		{
+			MethodName: "ResolveStuckTransactions",
+			Handler:    _TransactionService_ResolveStuckTransactions_Handler,
+		},
+		{
+			MethodName: "ListFlaggedTransactions",
+			Handler:    _TransactionService_ListFlaggedTransactions_Handler,
+		},
+		{
+			MethodName: "ApproveFlagged",
+			Handler:    _TransactionService_ApproveFlagged_Handler,
+		},
+		{
+			MethodName: "DeclineFlagged",
+			Handler:    _TransactionService_DeclineFlagged_Handler,
+		},
+		{
+			MethodName: "SetBudget",
+			Handler:    _TransactionService_SetBudget_Handler,
+		},
+		{
+			MethodName: "DeleteBudget",
+			Handler:    _TransactionService_DeleteBudget_Handler,
+		},
+		{
+			MethodName: "GetBudgetStatus",
+			Handler:    _TransactionService_GetBudgetStatus_Handler,
+		},
+		{
+			MethodName: "ListEventDeliveries",
+			Handler:    _TransactionService_ListEventDeliveries_Handler,
+		},
+		{
+			MethodName: "GetSLOStatus",
+			Handler:    _TransactionService_GetSLOStatus_Handler,
+		},
+		{
+			MethodName: "ListWebhookSigningKeys",
+			Handler:    _TransactionService_ListWebhookSigningKeys_Handler,
+		},
+		{
+			MethodName: "CreateWebhookSigningKey",
+			Handler:    _TransactionService_CreateWebhookSigningKey_Handler,
+		},
+		{
+			MethodName: "RetireWebhookSigningKey",
+			Handler:    _TransactionService_RetireWebhookSigningKey_Handler,
+		},
+		{
+			MethodName: "CreateBatch",
+			Handler:    _TransactionService_CreateBatch_Handler,
+		},
+		{
+			MethodName: "GetBatch",
+			Handler:    _TransactionService_GetBatch_Handler,
+		},
+		{
+			MethodName: "GenerateStatement",
+			Handler:    _TransactionService_GenerateStatement_Handler,
+		},
+		{
+			MethodName: "GetStatement",
+			Handler:    _TransactionService_GetStatement_Handler,
+		},
+		{
+			MethodName: "GetBusinessDayStatus",
+			Handler:    _TransactionService_GetBusinessDayStatus_Handler,
+		},
+		{
+			MethodName: "CreateRecurringRule",
+			Handler:    _TransactionService_CreateRecurringRule_Handler,
+		},
+		{
+			MethodName: "ListRecurringRules",
+			Handler:    _TransactionService_ListRecurringRules_Handler,
+		},
+		{
+			MethodName: "CancelRecurringRule",
+			Handler:    _TransactionService_CancelRecurringRule_Handler,
+		},
+		{
+			MethodName: "ListMessageTemplates",
+			Handler:    _TransactionService_ListMessageTemplates_Handler,
+		},
+		{
+			MethodName: "PutMessageTemplate",
+			Handler:    _TransactionService_PutMessageTemplate_Handler,
+		},
+		{
+			MethodName: "DeleteMessageTemplate",
+			Handler:    _TransactionService_DeleteMessageTemplate_Handler,
+		},
+		{
+			MethodName: "PreviewMessageTemplate",
+			Handler:    _TransactionService_PreviewMessageTemplate_Handler,
+		},
+		{
+			MethodName: "TestSendMessageTemplate",
+			Handler:    _TransactionService_TestSendMessageTemplate_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "IngestTransactions",
+			Handler:       _TransactionService_IngestTransactions_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportTransactionHistory",
+			Handler:       _TransactionService_ExportTransactionHistory_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamTransactions",
+			Handler:       _TransactionService_StreamTransactions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "transaction.proto",
+}
+
+const (
+	TransactionAnalyticsService_StreamTransactions_FullMethodName = "/transaction.TransactionAnalyticsService/StreamTransactions"
+)
+
+// TransactionAnalyticsServiceClient is the client API for TransactionAnalyticsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Read-only, streaming-only reads for analytics workloads such as reporting jobs and data pipelines.
+// Served on a separate port against a read replica, with its own rate limits; callers authenticate with an
+// analytics service token.
+type TransactionAnalyticsServiceClient interface {
+	// Streams transactions oldest first, in batches
+	StreamTransactions(ctx context.Context, in *StreamTransactionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamTransactionsResponse], error)
+}
+
+type transactionAnalyticsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTransactionAnalyticsServiceClient(cc grpc.ClientConnInterface) TransactionAnalyticsServiceClient {
+	return &transactionAnalyticsServiceClient{cc}
+}
+
+func (c *transactionAnalyticsServiceClient) StreamTransactions(ctx context.Context, in *StreamTransactionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamTransactionsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TransactionAnalyticsService_ServiceDesc.Streams[0], TransactionAnalyticsService_StreamTransactions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamTransactionsRequest, StreamTransactionsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionAnalyticsService_StreamTransactionsClient = grpc.ServerStreamingClient[StreamTransactionsResponse]
+
+// TransactionAnalyticsServiceServer is the server API for TransactionAnalyticsService service.
+// All implementations must embed UnimplementedTransactionAnalyticsServiceServer
+// for forward compatibility.
+//
+// Read-only, streaming-only reads for analytics workloads such as reporting jobs and data pipelines.
+// Served on a separate port against a read replica, with its own rate limits; callers authenticate with an
+// analytics service token.
+type TransactionAnalyticsServiceServer interface {
+	// Streams transactions oldest first, in batches
+	StreamTransactions(*StreamTransactionsRequest, grpc.ServerStreamingServer[StreamTransactionsResponse]) error
+	mustEmbedUnimplementedTransactionAnalyticsServiceServer()
+}
+
+// UnimplementedTransactionAnalyticsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTransactionAnalyticsServiceServer struct{}
+
+func (UnimplementedTransactionAnalyticsServiceServer) StreamTransactions(*StreamTransactionsRequest, grpc.ServerStreamingServer[StreamTransactionsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTransactions not implemented")
+}
+func (UnimplementedTransactionAnalyticsServiceServer) mustEmbedUnimplementedTransactionAnalyticsServiceServer() {
+}
+func (UnimplementedTransactionAnalyticsServiceServer) testEmbeddedByValue() {}
+
+// UnsafeTransactionAnalyticsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TransactionAnalyticsServiceServer will
+// result in compilation errors.
+type UnsafeTransactionAnalyticsServiceServer interface {
+	mustEmbedUnimplementedTransactionAnalyticsServiceServer()
+}
+
+func RegisterTransactionAnalyticsServiceServer(s grpc.ServiceRegistrar, srv TransactionAnalyticsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTransactionAnalyticsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TransactionAnalyticsService_ServiceDesc, srv)
+}
+
+func _TransactionAnalyticsService_StreamTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransactionAnalyticsServiceServer).StreamTransactions(m, &grpc.GenericServerStream[StreamTransactionsRequest, StreamTransactionsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TransactionAnalyticsService_StreamTransactionsServer = grpc.ServerStreamingServer[StreamTransactionsResponse]
+
+// TransactionAnalyticsService_ServiceDesc is the grpc.ServiceDesc for TransactionAnalyticsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TransactionAnalyticsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "transaction.TransactionAnalyticsService",
+	HandlerType: (*TransactionAnalyticsServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTransactions",
+			Handler:       _TransactionAnalyticsService_StreamTransactions_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "transaction.proto",
 }
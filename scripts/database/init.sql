@@ -5,31 +5,587 @@ CREATE TABLE IF NOT EXISTS accounts (
     id VARCHAR(36) PRIMARY KEY,
     document_number VARCHAR(20) NOT NULL UNIQUE,
     account_type VARCHAR(20) NOT NULL CHECK (account_type IN ('CHECKING', 'SAVINGS', 'CREDIT')),
-    balance DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    balance DECIMAL(15,2) NOT NULL DEFAULT 0,
     created_at BIGINT NOT NULL,
-    updated_at BIGINT NOT NULL
+    updated_at BIGINT NOT NULL,
+    -- Onboarding state; only ACTIVE accounts can transact. Accounts of tenants with retention settings are CLOSED instead of deleted
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('DRAFT', 'PENDING_KYC', 'ACTIVE', 'CLOSED')),
+    holder_name VARCHAR(200),
+    holder_email VARCHAR(200),
+    holder_phone VARCHAR(32),
+    -- Comma-separated uppercase tags, used to filter account lists and exclude accounts from reports
+    tags VARCHAR(500) NOT NULL DEFAULT '',
+    kyc_reference VARCHAR(100),
+    -- Balance at creation; with the account's transactions and approved adjustments it yields the ledger balance
+    opening_balance DECIMAL(15,2),
+    -- Incremented whenever the account's attributes change; used as the ETag for conditional updates
+    version BIGINT NOT NULL DEFAULT 1,
+    -- Tenant the account was opened for, whose retention settings apply to it
+    tenant_id VARCHAR(64),
+    closed_at BIGINT,
+    -- Set once the retention worker has anonymized the holder data of a closed account
+    anonymized_at BIGINT,
+    -- Checking accounts may be overdrawn up to their overdraft limit
+    overdraft_limit DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (overdraft_limit >= 0),
+    CONSTRAINT accounts_balance_check CHECK (balance >= -overdraft_limit)
 );
 
 CREATE TABLE IF NOT EXISTS transactions (
     id VARCHAR(36) PRIMARY KEY,
     account_id VARCHAR(36) NOT NULL,
-    operation_type VARCHAR(50) NOT NULL CHECK (operation_type IN ('CASH_PURCHASE', 'INSTALLMENT_PURCHASE', 'WITHDRAWAL', 'PAYMENT')),
+    operation_type VARCHAR(50) NOT NULL CHECK (operation_type IN ('CASH_PURCHASE', 'INSTALLMENT_PURCHASE', 'WITHDRAWAL', 'PAYMENT', 'TRANSFER_OUT', 'TRANSFER_IN', 'REVERSAL')),
     amount DECIMAL(15,2) NOT NULL,
     description TEXT,
     created_at BIGINT NOT NULL,
-    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'COMPLETED', 'FAILED', 'CANCELLED')),
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'UNDER_REVIEW', 'COMPLETED', 'DECLINED', 'FAILED', 'CANCELLED', 'REVERSED')),
+    -- Reference assigned by the card network or acquirer, used to match chargebacks
+    external_id VARCHAR(64),
+    -- Comma-separated tags and free-form metadata, editable after creation through UpdateTransaction
+    tags VARCHAR(500) NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    -- Shared by the TRANSFER_OUT and TRANSFER_IN transactions of a transfer between accounts
+    transfer_id VARCHAR(36),
+    -- Set on a REVERSAL transaction to the transaction it compensates, which can only be reversed once
+    original_transaction_id VARCHAR(36),
+    -- Part of a purchase or withdrawal not yet discharged by payments, or of a payment left as credit
+    balance DECIMAL(15,2) NOT NULL DEFAULT 0,
+    -- Debits that took the account balance below zero, into its overdraft
+    overdrawn BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Payments made in a currency other than the account's keep the amount paid and the rate applied
+    original_currency VARCHAR(3),
+    original_amount DECIMAL(15,2),
+    fx_rate DECIMAL(20,10),
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- Audit trail of edits to transaction descriptions, tags and metadata, with the values before and after
+CREATE TABLE IF NOT EXISTS transaction_edits (
+    id VARCHAR(36) PRIMARY KEY,
+    transaction_id VARCHAR(36) NOT NULL,
+    edited_by VARCHAR(100) NOT NULL,
+    edited_at BIGINT NOT NULL,
+    previous JSONB NOT NULL,
+    updated JSONB NOT NULL,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+-- Audit trail of stuck PENDING transactions completed, failed or reversed by an operator
+CREATE TABLE IF NOT EXISTS transaction_resolutions (
+    id VARCHAR(36) PRIMARY KEY,
+    transaction_id VARCHAR(36) NOT NULL,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('COMPLETE', 'FAIL', 'REVERSE')),
+    previous_status VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    resolved_by VARCHAR(100) NOT NULL,
+    resolved_at BIGINT NOT NULL,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+-- Risk scores of transactions held for review by fraud scoring, with the analyst's decision once made
+CREATE TABLE IF NOT EXISTS transaction_reviews (
+    transaction_id VARCHAR(36) PRIMARY KEY,
+    risk_score INTEGER NOT NULL,
+    risk_factors VARCHAR(200) NOT NULL,
+    flagged_at BIGINT NOT NULL,
+    decision VARCHAR(10) CHECK (decision IN ('APPROVE', 'DECLINE')),
+    note TEXT NOT NULL DEFAULT '',
+    reviewed_by VARCHAR(100),
+    reviewed_at BIGINT,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+-- Per-tenant (issuer) settings, one row per tenant and environment
+CREATE TABLE IF NOT EXISTS tenant_settings (
+    tenant_id VARCHAR(64) NOT NULL,
+    environment VARCHAR(32) NOT NULL,
+    settings JSONB NOT NULL DEFAULT '{}',
+    updated_at BIGINT NOT NULL,
+    PRIMARY KEY (tenant_id, environment)
+);
+
+-- Disputes opened against transactions, e.g. from imported network chargeback files; at most one per transaction
+CREATE TABLE IF NOT EXISTS disputes (
+    id VARCHAR(36) PRIMARY KEY,
+    transaction_id VARCHAR(36) NOT NULL UNIQUE,
+    account_id VARCHAR(36) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    reason_code VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'WON', 'LOST')),
+    source VARCHAR(20) NOT NULL,
+    opened_at BIGINT NOT NULL,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- Manual balance corrections; each row records its request and review (maker-checker)
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL,
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('CREDIT', 'DEBIT')),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    reason_code VARCHAR(50) NOT NULL,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED')),
+    requested_by VARCHAR(100) NOT NULL,
+    requested_at BIGINT NOT NULL,
+    reviewed_by VARCHAR(100),
+    reviewed_at BIGINT,
+    review_note TEXT,
+    -- Set on adjustments posted by internal services: the calling service and its reference for the adjustment
+    source VARCHAR(100),
+    reference VARCHAR(100),
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- Document number changes; each row records the request, its verification by a second operator and when it was applied
+CREATE TABLE IF NOT EXISTS document_changes (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL,
+    previous_document_number VARCHAR(20) NOT NULL,
+    document_number VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'VERIFIED', 'REJECTED', 'APPLIED')),
+    reason TEXT,
+    requested_by VARCHAR(100) NOT NULL,
+    requested_at BIGINT NOT NULL,
+    -- Reference of the document check the verifier relied on
+    verification_reference VARCHAR(100),
+    verified_by VARCHAR(100),
+    verified_at BIGINT,
+    verification_note TEXT,
+    applied_by VARCHAR(100),
+    applied_at BIGINT,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- Audit trail of changes to account attributes, with the values before and after. Rows are kept when the
+-- account is deleted, so there is no foreign key to accounts
+CREATE TABLE IF NOT EXISTS account_audit (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('CREATE', 'UPDATE', 'DELETE', 'STATUS_CHANGE')),
+    actor VARCHAR(100) NOT NULL,
+    actor_role VARCHAR(50) NOT NULL,
+    changed_at BIGINT NOT NULL,
+    previous JSONB,
+    updated JSONB
+);
+
+-- Copies of accounts and their most recent transactions taken before they are deleted or closed, so an
+-- operator can undo the operation. Like account_audit, rows outlive the account
+CREATE TABLE IF NOT EXISTS account_snapshots (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL,
+    operation VARCHAR(20) NOT NULL CHECK (operation IN ('DELETE', 'CLOSE')),
+    taken_by VARCHAR(100) NOT NULL,
+    taken_by_role VARCHAR(50) NOT NULL,
+    taken_at BIGINT NOT NULL,
+    account JSONB NOT NULL,
+    transactions JSONB NOT NULL,
+    restored_at BIGINT,
+    restored_by VARCHAR(100)
+);
+
+-- How each operation type is applied to the balance; loaded by transaction-mgr at startup and editable by admins
+CREATE TABLE IF NOT EXISTS operation_type_rules (
+    operation_type VARCHAR(50) PRIMARY KEY CHECK (operation_type IN ('CASH_PURCHASE', 'INSTALLMENT_PURCHASE', 'WITHDRAWAL', 'PAYMENT')),
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('CREDIT', 'DEBIT')),
+    requires_positive_amount BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Comma-separated account types the operation is allowed on; empty allows all
+    allowed_account_types VARCHAR(100) NOT NULL DEFAULT '',
+    fee_policy VARCHAR(20) NOT NULL DEFAULT 'NONE' CHECK (fee_policy IN ('NONE', 'TENANT_SCHEDULE')),
+    updated_at BIGINT NOT NULL
+);
+
+INSERT INTO operation_type_rules (operation_type, direction, requires_positive_amount, updated_at)
+VALUES
+    ('CASH_PURCHASE', 'DEBIT', FALSE, EXTRACT(EPOCH FROM NOW())::BIGINT),
+    ('INSTALLMENT_PURCHASE', 'DEBIT', FALSE, EXTRACT(EPOCH FROM NOW())::BIGINT),
+    ('WITHDRAWAL', 'DEBIT', FALSE, EXTRACT(EPOCH FROM NOW())::BIGINT),
+    ('PAYMENT', 'CREDIT', TRUE, EXTRACT(EPOCH FROM NOW())::BIGINT)
+ON CONFLICT (operation_type) DO NOTHING;
+
+-- What each run of a tenant retention policy removed, or would remove in dry-run mode
+CREATE TABLE IF NOT EXISTS retention_reports (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    policy VARCHAR(50) NOT NULL,
+    dry_run BOOLEAN NOT NULL,
+    affected BIGINT NOT NULL,
+    cutoff BIGINT NOT NULL,
+    run_at BIGINT NOT NULL
+);
+
+-- Authorization decisions of both services, for access reviews
+CREATE TABLE IF NOT EXISTS access_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    service VARCHAR(50) NOT NULL,
+    method VARCHAR(200) NOT NULL,
+    principal VARCHAR(100) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    tenant_id VARCHAR(64) NOT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    decision VARCHAR(5) NOT NULL CHECK (decision IN ('ALLOW', 'DENY')),
+    reason VARCHAR(200) NOT NULL,
+    occurred_at BIGINT NOT NULL
+);
+
+-- Daily FX rates: quote_currency per unit of base_currency, as of the day starting at rate_date
+CREATE TABLE IF NOT EXISTS fx_rates (
+    base_currency VARCHAR(3) NOT NULL,
+    quote_currency VARCHAR(3) NOT NULL,
+    rate_date BIGINT NOT NULL,
+    rate DECIMAL(20,10) NOT NULL CHECK (rate > 0),
+    PRIMARY KEY (base_currency, quote_currency, rate_date)
+);
+
+-- Month-end revaluation entries: unrealized FX gains and losses of account balances, in the tenant's reporting currency
+CREATE TABLE IF NOT EXISTS fx_revaluations (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id VARCHAR(64) NOT NULL,
+    account_id VARCHAR(36) NOT NULL,
+    -- Month revalued, as YYYY-MM
+    period VARCHAR(7) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    reporting_currency VARCHAR(3) NOT NULL,
+    balance DECIMAL(15,2) NOT NULL,
+    closing_rate DECIMAL(20,10) NOT NULL,
+    carrying_value DECIMAL(18,2) NOT NULL,
+    revalued_value DECIMAL(18,2) NOT NULL,
+    unrealized_gain DECIMAL(18,2) NOT NULL,
+    created_at BIGINT NOT NULL,
+    UNIQUE (account_id, period),
     FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
 );
 
+-- Monthly spending budgets per transaction category (the category metadata entry of a transaction).
+-- Budgets never decline a transaction; crossing a threshold publishes a budget.threshold_crossed event
+CREATE TABLE IF NOT EXISTS account_budgets (
+    account_id VARCHAR(36) NOT NULL,
+    category VARCHAR(32) NOT NULL,
+    monthly_limit DECIMAL(15,2) NOT NULL CHECK (monthly_limit > 0),
+    -- Comma-separated percentages of the limit, ascending
+    thresholds VARCHAR(100) NOT NULL,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+    PRIMARY KEY (account_id, category),
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- Events waiting to be published by the outbox relay, written in the same transaction as the change they describe
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    -- Deduplication key of the broker
+    event_id VARCHAR(36) NOT NULL UNIQUE,
+    event_type VARCHAR(64) NOT NULL,
+    partition_key VARCHAR(64) NOT NULL,
+    -- Serialized events.EventEnvelope
+    envelope BYTEA NOT NULL,
+    created_at BIGINT NOT NULL,
+    published_at BIGINT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT
+);
+
+-- Secrets outbox deliveries are signed with; every active key of a subscriber signs each delivery,
+-- so a subscriber can move to a new key before the old one is retired
+CREATE TABLE IF NOT EXISTS webhook_signing_keys (
+    subscriber VARCHAR(64) NOT NULL,
+    key_id VARCHAR(20) NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'RETIRED')),
+    created_by VARCHAR(100) NOT NULL,
+    created_at BIGINT NOT NULL,
+    retired_by VARCHAR(100),
+    retired_at BIGINT,
+    PRIMARY KEY (subscriber, key_id)
+);
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 CREATE INDEX IF NOT EXISTS idx_accounts_document_number ON accounts(document_number);
+-- Supports partial (prefix/suffix) document number search
+CREATE INDEX IF NOT EXISTS idx_accounts_document_number_trgm ON accounts USING GIN (document_number gin_trgm_ops);
 CREATE INDEX IF NOT EXISTS idx_accounts_account_type ON accounts(account_type);
 CREATE INDEX IF NOT EXISTS idx_accounts_created_at ON accounts(created_at);
+CREATE INDEX IF NOT EXISTS idx_accounts_balance ON accounts(balance);
+-- Accounts still being onboarded
+CREATE INDEX IF NOT EXISTS idx_accounts_onboarding ON accounts(status) WHERE status <> 'ACTIVE';
 
 CREATE INDEX IF NOT EXISTS idx_transactions_account_id ON transactions(account_id);
 CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions(created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_transactions_account_created ON transactions(account_id, created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_transactions_operation_type ON transactions(operation_type);
 CREATE INDEX IF NOT EXISTS idx_transactions_status ON transactions(status);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_external_id ON transactions(external_id) WHERE external_id IS NOT NULL;
+-- Supports case-insensitive description search in the transaction history
+CREATE INDEX IF NOT EXISTS idx_transactions_description_trgm ON transactions USING GIN (description gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_transactions_transfer_id ON transactions(transfer_id) WHERE transfer_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_original_transaction_id ON transactions(original_transaction_id) WHERE original_transaction_id IS NOT NULL;
+-- Outstanding purchases and withdrawals of an account, oldest first, for payment discharge
+CREATE INDEX IF NOT EXISTS idx_transactions_outstanding ON transactions(account_id, created_at) WHERE balance < 0;
+CREATE INDEX IF NOT EXISTS idx_disputes_account ON disputes(account_id, opened_at DESC);
+CREATE INDEX IF NOT EXISTS idx_transaction_edits_transaction ON transaction_edits(transaction_id, edited_at);
+CREATE INDEX IF NOT EXISTS idx_transaction_resolutions_transaction ON transaction_resolutions(transaction_id, resolved_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_pending ON transactions(created_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_transaction_reviews_open ON transaction_reviews(flagged_at) WHERE decision IS NULL;
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_account ON balance_adjustments(account_id, requested_at DESC);
+-- A service posts each of its references once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_balance_adjustments_reference ON balance_adjustments(source, reference) WHERE reference IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_document_changes_account ON document_changes(account_id, requested_at DESC);
+-- At most one change per account may be in progress
+CREATE UNIQUE INDEX IF NOT EXISTS idx_document_changes_open ON document_changes(account_id) WHERE status IN ('PENDING', 'VERIFIED');
+CREATE INDEX IF NOT EXISTS idx_event_outbox_unpublished ON event_outbox(id) WHERE published_at IS NULL;
+-- Event delivery history of an account
+CREATE INDEX IF NOT EXISTS idx_event_outbox_partition ON event_outbox(partition_key, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_accounts_tenant ON accounts(tenant_id) WHERE tenant_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_retention_reports_tenant ON retention_reports(tenant_id, run_at DESC);
+CREATE INDEX IF NOT EXISTS idx_access_audit_log_principal ON access_audit_log(principal, id DESC);
+CREATE INDEX IF NOT EXISTS idx_access_audit_log_occurred_at ON access_audit_log(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_fx_revaluations_tenant_period ON fx_revaluations(tenant_id, period);
+CREATE INDEX IF NOT EXISTS idx_transactions_category ON transactions(account_id, (metadata->>'category'), created_at) WHERE metadata ? 'category';
+CREATE INDEX IF NOT EXISTS idx_account_audit_account ON account_audit(account_id, changed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_account_snapshots_account ON account_snapshots(account_id, taken_at DESC);
+
+-- Daily per-account totals by operation type, maintained by trigger for reporting queries
+CREATE TABLE IF NOT EXISTS transaction_daily_rollups (
+    account_id VARCHAR(36) NOT NULL,
+    day_start BIGINT NOT NULL,
+    operation_type VARCHAR(50) NOT NULL,
+    txn_count BIGINT NOT NULL DEFAULT 0,
+    total_amount DECIMAL(18,2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (account_id, day_start, operation_type),
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- The end-of-day close reads the rollups of one day over all accounts
+CREATE INDEX IF NOT EXISTS idx_transaction_daily_rollups_day ON transaction_daily_rollups(day_start);
+
+CREATE OR REPLACE FUNCTION rollup_transaction() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.status IN ('COMPLETED', 'REVERSED') THEN
+        UPDATE transaction_daily_rollups
+        SET txn_count = txn_count - 1, total_amount = total_amount - OLD.amount
+        WHERE account_id = OLD.account_id
+            AND day_start = OLD.created_at - OLD.created_at % 86400
+            AND operation_type = OLD.operation_type;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.status IN ('COMPLETED', 'REVERSED') THEN
+        INSERT INTO transaction_daily_rollups (account_id, day_start, operation_type, txn_count, total_amount)
+        VALUES (NEW.account_id, NEW.created_at - NEW.created_at % 86400, NEW.operation_type, 1, NEW.amount)
+        ON CONFLICT (account_id, day_start, operation_type) DO UPDATE
+        SET txn_count = transaction_daily_rollups.txn_count + 1,
+            total_amount = transaction_daily_rollups.total_amount + EXCLUDED.total_amount;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER transactions_rollup
+AFTER INSERT OR UPDATE OR DELETE ON transactions
+FOR EACH ROW EXECUTE FUNCTION rollup_transaction();
+
+-- Per-account transaction counts by UTC month, maintained by trigger so deep histories are paged without scanning
+CREATE TABLE IF NOT EXISTS transaction_monthly_counts (
+    account_id VARCHAR(36) NOT NULL,
+    month_start BIGINT NOT NULL,
+    txn_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (account_id, month_start),
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+CREATE OR REPLACE FUNCTION count_transaction_month() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE transaction_monthly_counts
+        SET txn_count = txn_count - 1
+        WHERE account_id = OLD.account_id
+            AND month_start = EXTRACT(EPOCH FROM date_trunc('month', to_timestamp(OLD.created_at) AT TIME ZONE 'UTC'))::BIGINT;
+    ELSE
+        INSERT INTO transaction_monthly_counts (account_id, month_start, txn_count)
+        VALUES (NEW.account_id, EXTRACT(EPOCH FROM date_trunc('month', to_timestamp(NEW.created_at) AT TIME ZONE 'UTC'))::BIGINT, 1)
+        ON CONFLICT (account_id, month_start) DO UPDATE
+        SET txn_count = transaction_monthly_counts.txn_count + 1;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER transactions_monthly_count
+AFTER INSERT OR DELETE ON transactions
+FOR EACH ROW EXECUTE FUNCTION count_transaction_month();
+
+-- Batches group the transactions of a bulk import or settlement run. Accepted items are linked to their
+-- transaction; the latest failed attempt of each item is kept until the item is accepted
+CREATE TABLE IF NOT EXISTS transaction_batches (
+    id VARCHAR(36) PRIMARY KEY,
+    source VARCHAR(100) NOT NULL,
+    expected_count INTEGER NOT NULL CHECK (expected_count > 0),
+    created_by VARCHAR(100),
+    created_at BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transaction_batch_items (
+    batch_id VARCHAR(36) NOT NULL,
+    item INTEGER NOT NULL CHECK (item > 0),
+    transaction_id VARCHAR(36) NOT NULL UNIQUE,
+    PRIMARY KEY (batch_id, item),
+    FOREIGN KEY (batch_id) REFERENCES transaction_batches(id) ON DELETE CASCADE,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS transaction_batch_failures (
+    batch_id VARCHAR(36) NOT NULL,
+    item INTEGER NOT NULL CHECK (item > 0),
+    account_id VARCHAR(36),
+    external_id VARCHAR(64),
+    error VARCHAR(200) NOT NULL,
+    failed_at BIGINT NOT NULL,
+    PRIMARY KEY (batch_id, item),
+    FOREIGN KEY (batch_id) REFERENCES transaction_batches(id) ON DELETE CASCADE
+);
+
+-- Monthly statements, generated once per account and calendar month; totals is an array of the count and
+-- total amount of each operation type
+CREATE TABLE IF NOT EXISTS statements (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL,
+    period VARCHAR(7) NOT NULL,
+    period_start BIGINT NOT NULL,
+    period_end BIGINT NOT NULL,
+    opening_balance DECIMAL(18,2) NOT NULL,
+    closing_balance DECIMAL(18,2) NOT NULL,
+    adjustments DECIMAL(18,2) NOT NULL,
+    totals JSONB NOT NULL,
+    transaction_count BIGINT NOT NULL,
+    generated_at BIGINT NOT NULL,
+    UNIQUE (account_id, period),
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+-- UTC days sealed by the end-of-day close, with their totals, and the balance of each account at the end of a
+-- sealed day. End-of-day balances have no foreign key to accounts, so they outlive the accounts they were recorded for
+CREATE TABLE IF NOT EXISTS business_days (
+    day_start BIGINT PRIMARY KEY,
+    closed_at BIGINT NOT NULL,
+    closed_by VARCHAR(100) NOT NULL,
+    account_count BIGINT NOT NULL,
+    transaction_count BIGINT NOT NULL,
+    net_amount DECIMAL(18,2) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS end_of_day_balances (
+    account_id VARCHAR(36) NOT NULL,
+    day_start BIGINT NOT NULL,
+    balance DECIMAL(18,2) NOT NULL,
+    transaction_count BIGINT NOT NULL,
+    net_amount DECIMAL(18,2) NOT NULL,
+    PRIMARY KEY (account_id, day_start)
+);
+
+-- Refuses transactions dated before the end of the last sealed day, unless the database transaction opted out
+-- by setting pismo.allow_backdated
+CREATE OR REPLACE FUNCTION seal_business_days() RETURNS TRIGGER AS $$
+DECLARE
+    sealed_until BIGINT;
+BEGIN
+    IF current_setting('pismo.allow_backdated', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+    SELECT MAX(day_start) + 86400 INTO sealed_until FROM business_days;
+    IF NEW.created_at < sealed_until THEN
+        RAISE EXCEPTION 'business day closed'
+            USING ERRCODE = 'PB001', DETAIL = format('created_at %s is before the end of the last closed day, %s', NEW.created_at, sealed_until);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER transactions_seal
+BEFORE INSERT ON transactions
+FOR EACH ROW EXECUTE FUNCTION seal_business_days();
+
+-- Recurring rules make a transaction on an account on a schedule; next_run_at is the time of the next run
+-- while the rule is ACTIVE. Each run that made a transaction is linked to it, so a run is made at most once
+CREATE TABLE IF NOT EXISTS recurring_rules (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(36) NOT NULL,
+    tenant_id VARCHAR(64),
+    operation_type VARCHAR(50) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('DAILY', 'WEEKLY', 'MONTHLY')),
+    start_at BIGINT NOT NULL,
+    end_at BIGINT,
+    next_run_at BIGINT NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('ACTIVE', 'CANCELLED', 'COMPLETED')),
+    run_count INTEGER NOT NULL DEFAULT 0,
+    skipped_count INTEGER NOT NULL DEFAULT 0,
+    last_run_at BIGINT,
+    last_transaction_id VARCHAR(36),
+    last_error VARCHAR(200),
+    created_by VARCHAR(100),
+    created_at BIGINT NOT NULL,
+    cancelled_at BIGINT,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS recurring_rule_runs (
+    rule_id VARCHAR(36) NOT NULL,
+    run INTEGER NOT NULL CHECK (run > 0),
+    scheduled_at BIGINT NOT NULL,
+    transaction_id VARCHAR(36) NOT NULL UNIQUE,
+    PRIMARY KEY (rule_id, run),
+    FOREIGN KEY (rule_id) REFERENCES recurring_rules(id) ON DELETE CASCADE,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_rules_account ON recurring_rules(account_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_recurring_rules_due ON recurring_rules(next_run_at) WHERE status = 'ACTIVE';
+
+-- The installments of an installment purchase after the first, which the purchase debits; each is debited by a
+-- transaction of its own when due, on behalf of the purchase's tenant. Declined installments are OVERDUE and
+-- retried from next_attempt_at
+CREATE TABLE IF NOT EXISTS installments (
+    purchase_id VARCHAR(36) NOT NULL,
+    number INTEGER NOT NULL CHECK (number > 1),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    due_at BIGINT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'IN_REVIEW', 'PAID', 'OVERDUE', 'CANCELLED')),
+    next_attempt_at BIGINT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error VARCHAR(200),
+    transaction_id VARCHAR(36) UNIQUE,
+    paid_at BIGINT,
+    tenant_id VARCHAR(64),
+    PRIMARY KEY (purchase_id, number),
+    FOREIGN KEY (purchase_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_installments_due ON installments(next_attempt_at) WHERE status IN ('PENDING', 'OVERDUE');
+
+-- Per-tenant overrides of the platform message templates of the runtime config
+CREATE TABLE IF NOT EXISTS message_templates (
+    tenant_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    channel VARCHAR(10) NOT NULL CHECK (channel IN ('EMAIL', 'WEBHOOK')),
+    subject VARCHAR(200) NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    updated_by VARCHAR(100) NOT NULL,
+    updated_at BIGINT NOT NULL,
+    PRIMARY KEY (tenant_id, event_type, channel)
+);
+
+-- What each payment discharged from each purchase or withdrawal, so reversing the payment can reopen them
+CREATE TABLE IF NOT EXISTS payment_discharges (
+    payment_id VARCHAR(36) NOT NULL,
+    debt_id VARCHAR(36) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    PRIMARY KEY (payment_id, debt_id),
+    FOREIGN KEY (payment_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY (debt_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
 
 INSERT INTO accounts (id, document_number, account_type, balance, created_at, updated_at) VALUES
 ('test-account-1', '12345678901', 'CHECKING', 1000.00, EXTRACT(EPOCH FROM NOW()), EXTRACT(EPOCH FROM NOW())),
@@ -42,3 +598,11 @@ INSERT INTO transactions (id, account_id, operation_type, amount, description, c
 ('test-transaction-2', 'test-account-1', 'CASH_PURCHASE', -50.00, 'Test purchase', EXTRACT(EPOCH FROM NOW()), 'COMPLETED'),
 ('test-transaction-3', 'test-account-2', 'WITHDRAWAL', -200.00, 'Test withdrawal', EXTRACT(EPOCH FROM NOW()), 'COMPLETED')
 ON CONFLICT (id) DO NOTHING;
+
+-- Opening balances that reconcile the seeded balances with the seeded transactions
+UPDATE accounts a
+SET opening_balance = a.balance
+    - COALESCE((SELECT SUM(t.amount) FROM transactions t WHERE t.account_id = a.id AND t.status IN ('COMPLETED', 'REVERSED')), 0)
+    - COALESCE((SELECT SUM(CASE WHEN b.direction = 'CREDIT' THEN b.amount ELSE -b.amount END)
+        FROM balance_adjustments b WHERE b.account_id = a.id AND b.status = 'APPROVED'), 0)
+WHERE a.opening_balance IS NULL;
@@ -71,6 +71,14 @@ protoc \
     --grpc-gateway_opt=paths=source_relative \
     proto/health/health.proto
 
+# Event schemas
+echo "Generating event schemas..."
+protoc \
+    --proto_path=proto \
+    --go_out=. \
+    --go_opt=paths=source_relative \
+    proto/events/events.proto
+
 echo "Proto generation completed successfully!"
 echo ""
 echo "Generated files:"
@@ -83,3 +91,4 @@ echo "- proto/transaction/transaction.pb.gw.go (new gateway file)"
 echo "- proto/health/health.pb.go (updated)"
 echo "- proto/health/health_grpc.pb.go (updated)"
 echo "- proto/health/health.pb.gw.go (new gateway file)"
+echo "- proto/events/events.pb.go (updated)"